@@ -0,0 +1,48 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+// runConfig implements `otto config`, which prints the configuration reference generated
+// from the config types without starting the server.
+func runConfig(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("config", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	format := fs.String("format", "markdown", "output format: markdown or json")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, `usage: otto config [-format markdown|json] docs
+
+Commands:
+  docs  print every config.yaml and module setting with its type, default and description
+
+Flags:`)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 || fs.Arg(0) != "docs" || (*format != "markdown" && *format != "json") {
+		fs.Usage()
+		return 2
+	}
+
+	ref := internal.NewConfigReference(allModules())
+	var err error
+	if *format == "json" {
+		err = writeModulesJSON(stdout, ref)
+	} else {
+		err = ref.WriteMarkdown(stdout)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to write output: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,109 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+)
+
+// runDB implements `otto db migrate`, which migrates the schema of the database in
+// db_path, or with -dry-run prints what migrating it would change.
+func runDB(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("db migrate", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	dryRun := fs.Bool("dry-run", false, "print the SQL that would run and the schema diff without migrating")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, `usage: otto db migrate [-dry-run]
+
+Creates and updates the tables of Otto and of every module in the database in db_path, as
+Otto does when it starts. Run it before starting Otto with migrations.refuse_pending set.
+
+Flags:`)
+		fs.PrintDefaults()
+	}
+	if len(args) == 0 || args[0] != "migrate" {
+		fs.Usage()
+		return 2
+	}
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return 2
+	}
+
+	cfg, err := config.LoadFromFile(config.GetEnvOrDefault("OTTO_CONFIG", "config.yaml"))
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to load config: %v\n", err)
+		return 1
+	}
+	db, err := internal.NewDatabase(cfg.DBPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "%v\n", err)
+		return 1
+	}
+	defer db.Close()
+
+	migrations := append(internal.CoreMigrations(), internal.ModuleMigrations(allModules())...)
+	plan, err := internal.PlanMigrations(ctx, db.DB(), migrations)
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to plan migrations: %v\n", err)
+		return 1
+	}
+	if !plan.Pending() {
+		fmt.Fprintln(stdout, "The database is up to date.")
+		return 0
+	}
+	if *dryRun {
+		writeMigrationPlan(stdout, plan)
+		return 0
+	}
+	if err := internal.ApplyMigrations(db.DB(), migrations); err != nil {
+		fmt.Fprintf(stderr, "failed to migrate: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(stdout, "Ran %d statements, changing %d tables and indexes.\n",
+		len(plan.Statements), len(plan.Changes))
+	return 0
+}
+
+// writeMigrationPlan prints the statements a migration would run, then the schema diff.
+func writeMigrationPlan(w io.Writer, plan *internal.MigrationPlan) {
+	fmt.Fprintf(w, "-- Statements that would run (%d):\n\n", len(plan.Statements))
+	for _, s := range plan.Statements {
+		fmt.Fprintf(w, "-- %s\n", s.Migration)
+		writeSQLLines(w, "", strings.TrimSuffix(s.SQL, ";")+";")
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintf(w, "-- Schema diff (%d):\n", len(plan.Changes))
+	for _, c := range plan.Changes {
+		fmt.Fprintln(w)
+		if c.Before == "" {
+			fmt.Fprintf(w, "new %s %s\n", c.Type, c.Name)
+		} else {
+			fmt.Fprintf(w, "changed %s %s\n", c.Type, c.Name)
+			writeSQLLines(w, "- ", c.Before)
+		}
+		writeSQLLines(w, "+ ", c.After)
+	}
+}
+
+// writeSQLLines prints each line of a statement with a prefix, indenting the lines after
+// the first by two spaces whatever their indentation in the source.
+func writeSQLLines(w io.Writer, prefix, sql string) {
+	for i, line := range strings.Split(sql, "\n") {
+		line = strings.TrimSpace(line)
+		if i > 0 && !strings.HasPrefix(line, ")") {
+			line = "  " + line
+		}
+		fmt.Fprintf(w, "%s%s\n", prefix, line)
+	}
+}
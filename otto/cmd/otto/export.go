@@ -0,0 +1,72 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+)
+
+// runExport implements `otto export`, which writes the database and the effective
+// configuration to an archive that `otto import` restores.
+func runExport(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	out := fs.String("out", "state.tar.gz", "archive to write")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, `usage: otto export [-out state.tar.gz]
+
+Writes every table of the database in db_path (schedules, tasks, flags, the action audit
+log and module state) and the effective configuration to a tar.gz archive. Secrets are not
+included. The database can be exported while Otto is running.
+
+Flags:`)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return 2
+	}
+
+	cfg, err := config.LoadFromFile(config.GetEnvOrDefault("OTTO_CONFIG", "config.yaml"))
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to load config: %v\n", err)
+		return 1
+	}
+	db, err := internal.NewDatabase(cfg.DBPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "%v\n", err)
+		return 1
+	}
+	defer db.Close()
+
+	f, err := os.OpenFile(*out, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		fmt.Fprintf(stderr, "%v\n", err)
+		return 1
+	}
+	manifest, err := internal.ExportState(ctx, db.DB(), cfg, f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(*out)
+		fmt.Fprintf(stderr, "failed to export: %v\n", err)
+		return 1
+	}
+	rows := 0
+	for _, t := range manifest.Tables {
+		rows += t.Rows
+	}
+	fmt.Fprintf(stdout, "Exported %d tables (%d rows) to %s\n", len(manifest.Tables), rows, *out)
+	return 0
+}
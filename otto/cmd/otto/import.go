@@ -0,0 +1,196 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+	"github.com/open-telemetry/sig-project-infra/otto/modules"
+)
+
+// runImport implements `otto import`, which restores an archive written by `otto export`,
+// or converts the configuration of the bots and actions a repository used before Otto
+// into module settings.
+func runImport(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	from := fs.String("from", "", "source: prow, probot-settings, stale-action or stackoverflow-state")
+	replace := fs.Bool("replace", false, "overwrite tables that already hold data")
+	configOut := fs.String("config-out", "", "write the archive's effective configuration to this file")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, `usage: otto import [-replace] [-config-out <file>] <state.tar.gz>
+       otto import -from prow|probot-settings|stale-action <path>
+       otto import -from stackoverflow-state <state.txt>
+
+Without -from, restores the database in db_path from an archive written by otto export.
+Stop Otto first. Tables missing from the database are created; the import is rejected if
+the archive was written by a newer Otto or, without -replace, if tables already hold data.
+
+Sources:
+  prow             OWNERS and OWNERS_ALIASES files in the repository checkout at <path>
+  probot-settings  a probot/settings .github/settings.yml
+  stale-action     a workflow using actions/stale, or a probot .github/stale.yml
+
+Module settings are printed as YAML for config.yaml or .github/otto.yml; settings without
+an Otto equivalent are listed as warnings.
+
+With -from stackoverflow-state, the state.txt the Stack Overflow GitHub Action keeps in the
+Actions cache is imported into the database in db_path, so that the stackoverflow module
+posts only the questions the action has not.
+
+Flags:`)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+
+	var (
+		imported *modules.ImportedConfig
+		err      error
+	)
+	switch *from {
+	case "":
+		return importState(ctx, fs.Arg(0), *replace, *configOut, stdout, stderr)
+	case modules.ImportFromStackOverflowState:
+		return importStackOverflowState(fs.Arg(0), stdout, stderr)
+	}
+
+	switch *from {
+	case modules.ImportFromProw:
+		imported, err = modules.ImportProwOwners(os.DirFS(fs.Arg(0)))
+	case modules.ImportFromProbotSettings, modules.ImportFromStaleAction:
+		var data []byte
+		if data, err = os.ReadFile(fs.Arg(0)); err != nil {
+			break
+		}
+		if *from == modules.ImportFromProbotSettings {
+			imported, err = modules.ImportProbotSettings(data)
+		} else {
+			imported, err = modules.ImportStale(data)
+		}
+	default:
+		fs.Usage()
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to import %s: %v\n", fs.Arg(0), err)
+		return 1
+	}
+
+	out, err := imported.YAML()
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to write output: %v\n", err)
+		return 1
+	}
+	for _, w := range imported.Warnings {
+		fmt.Fprintf(stderr, "warning: %s\n", w)
+	}
+	if _, err := stdout.Write(out); err != nil {
+		fmt.Fprintf(stderr, "failed to write output: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// importStackOverflowState backfills the stackoverflow module's state in the configured
+// database from the Stack Overflow GitHub Action's state file.
+func importStackOverflowState(path string, stdout, stderr io.Writer) int {
+	cfg, err := config.LoadFromFile(config.GetEnvOrDefault("OTTO_CONFIG", "config.yaml"))
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to load config: %v\n", err)
+		return 1
+	}
+	mod := &modules.StackOverflowModule{}
+	soConfig := mod.ConfigSchema().(*modules.StackOverflowConfig)
+	if err := internal.DecodeModuleConfig(mod, mod.Name(), cfg.Modules[mod.Name()], soConfig); err != nil {
+		fmt.Fprintf(stderr, "%v\n", err)
+		return 1
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintf(stderr, "%v\n", err)
+		return 1
+	}
+	db, err := internal.NewDatabase(cfg.DBPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "%v\n", err)
+		return 1
+	}
+	defer db.Close()
+
+	from, err := modules.ImportStackOverflowState(db.DB(), soConfig.Site, data)
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to import %s: %v\n", path, err)
+		return 1
+	}
+	fmt.Fprintf(stdout, "Questions on %s are fetched from %s\n", soConfig.Site, from.Format(time.RFC3339))
+	return 0
+}
+
+// importState restores an archive written by `otto export` into the configured database.
+func importState(ctx context.Context, path string, replace bool, configOut string, stdout, stderr io.Writer) int {
+	cfg, err := config.LoadFromFile(config.GetEnvOrDefault("OTTO_CONFIG", "config.yaml"))
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to load config: %v\n", err)
+		return 1
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		fmt.Fprintf(stderr, "%v\n", err)
+		return 1
+	}
+	defer f.Close()
+	db, err := internal.NewDatabase(cfg.DBPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "%v\n", err)
+		return 1
+	}
+	defer db.Close()
+
+	manifest, configYAML, err := internal.ImportState(ctx, db.DB(), f, replace)
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to import %s: %v\n", path, err)
+		return 1
+	}
+	rows := 0
+	for _, t := range manifest.Tables {
+		rows += t.Rows
+	}
+	fmt.Fprintf(stdout, "Imported %d tables (%d rows) exported by Otto %s at %s into %s\n", len(manifest.Tables), rows,
+		manifest.Version, manifest.ExportedAt.Format("2006-01-02 15:04:05 MST"), cfg.DBPath)
+
+	if configOut != "" {
+		// Never overwrite a config file; the operator decides what to keep.
+		if err := writeNewFile(configOut, configYAML); err != nil {
+			fmt.Fprintf(stderr, "failed to write %s: %v\n", configOut, err)
+			return 1
+		}
+		fmt.Fprintf(stdout, "Wrote the effective configuration to %s\n", configOut)
+	}
+	return 0
+}
+
+// writeNewFile writes data to a file that must not exist yet.
+func writeNewFile(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
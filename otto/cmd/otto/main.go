@@ -5,10 +5,9 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"os"
-	"os/signal"
-	"syscall"
 	"time"
 
 	"github.com/open-telemetry/sig-project-infra/otto/internal"
@@ -17,6 +16,31 @@ import (
 )
 
 func main() {
+	// Admin subcommands run without starting the server
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "query":
+			os.Exit(runQuery(context.Background(), os.Args[2:], os.Stdout, os.Stderr))
+		case "module":
+			os.Exit(runModule(os.Args[2:], os.Stdout, os.Stderr))
+		case "config":
+			os.Exit(runConfig(os.Args[2:], os.Stdout, os.Stderr))
+		case "export":
+			os.Exit(runExport(context.Background(), os.Args[2:], os.Stdout, os.Stderr))
+		case "import":
+			os.Exit(runImport(context.Background(), os.Args[2:], os.Stdout, os.Stderr))
+		case "replay":
+			os.Exit(runReplay(context.Background(), os.Args[2:], os.Stdout, os.Stderr))
+		case "db":
+			os.Exit(runDB(context.Background(), os.Args[2:], os.Stdout, os.Stderr))
+		case "permissions":
+			os.Exit(runPermissions(context.Background(), os.Args[2:], os.Stdout, os.Stderr))
+		case "version":
+			fmt.Println(internal.BuildVersion())
+			os.Exit(0)
+		}
+	}
+
 	// Create root context
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -27,6 +51,8 @@ func main() {
 
 	// App will load the configuration internally
 
+	slog.Info("starting otto", "version", internal.BuildVersion())
+
 	// Create and initialize application
 	app, err := internal.NewApp(ctx, configPath, secretsPath)
 	if err != nil {
@@ -35,7 +61,9 @@ func main() {
 	}
 
 	// Register modules explicitly
-	app.RegisterModule(&modules.OnCallModule{})
+	for _, m := range allModules() {
+		app.RegisterModule(m)
+	}
 
 	// Start the application
 	if err := app.Start(ctx); err != nil {
@@ -43,17 +71,13 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Set up graceful shutdown
-	done := make(chan os.Signal, 1)
-	signal.Notify(done, syscall.SIGINT, syscall.SIGTERM)
-
-	// Wait for shutdown signal
+	// Wait for SIGINT or SIGTERM
+	app.HandleSignals()
 	slog.Info("otto is running, press Ctrl+C to stop")
-	<-done
-	slog.Info("shutdown signal received")
+	app.WaitForShutdown()
 
-	// Create shutdown context with timeout
-	ctxShutdown, cancelShutdown := context.WithTimeout(ctx, 10*time.Second)
+	// Allow the events in flight to drain, and the rest of the shutdown 10 seconds more
+	ctxShutdown, cancelShutdown := context.WithTimeout(ctx, app.Config.Dispatch.DrainTimeout+10*time.Second)
 	defer cancelShutdown()
 
 	// Gracefully shut down the application
@@ -63,3 +87,41 @@ func main() {
 
 	slog.Info("otto has been gracefully shut down")
 }
+
+// allModules returns a new instance of each module, in registration order.
+func allModules() []internal.Module {
+	return []internal.Module{
+		&modules.OnCallModule{},
+		&modules.DependencyModule{},
+		&modules.SLAModule{},
+		&modules.OnboardingModule{},
+		&modules.TemplateSyncModule{},
+		&modules.ChecklistModule{},
+		&modules.LinkedIssueModule{},
+		&modules.ChangelogModule{},
+		&modules.SignatureModule{},
+		&modules.ConfirmModule{},
+		&modules.HistoryModule{},
+		&modules.OwnersModule{},
+		&modules.BulkLabelModule{},
+		&modules.GoodFirstIssuesModule{},
+		&modules.DigestModule{},
+		&modules.InactivityModule{},
+		&modules.ApprovalModule{},
+		&modules.HoldModule{},
+		&modules.CoverageModule{},
+		&modules.SizeLimitModule{},
+		&modules.ConfigCheckModule{},
+		&modules.StatusModule{},
+		&modules.PathLabelsModule{},
+		&modules.AdvisoryModule{},
+		&modules.CIHealthModule{},
+		&modules.SummarizeModule{},
+		&modules.StackOverflowModule{},
+		&modules.TrackingModule{},
+		&modules.WorkflowModule{},
+		&modules.AnalyticsModule{},
+		&modules.TriageModule{},
+		&modules.AnnouncementsModule{},
+	}
+}
@@ -0,0 +1,164 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+// runModule implements `otto module`, which describes the events modules consume without
+// starting the server.
+func runModule(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("module", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	format := fs.String("format", "table", "output format: table or json")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, `usage: otto module [-format table|json] list
+       otto module [-format table|json] describe <name>
+
+Commands:
+  list      list the modules and the events each consumes
+  describe  show the events, actions and payload types a module consumes, and the GitHub
+            App permissions it needs
+
+Flags:`)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *format != "table" && *format != "json" {
+		fs.Usage()
+		return 2
+	}
+
+	var out any
+	switch {
+	case fs.NArg() == 1 && fs.Arg(0) == "list":
+		var all []internal.ModuleDescription
+		for _, m := range allModules() {
+			all = append(all, internal.DescribeModule(m))
+		}
+		out = all
+	case fs.NArg() == 2 && fs.Arg(0) == "describe":
+		for _, m := range allModules() {
+			if m.Name() == fs.Arg(1) {
+				out = internal.DescribeModule(m)
+			}
+		}
+		if out == nil {
+			fmt.Fprintf(stderr, "unknown module %q\n", fs.Arg(1))
+			return 1
+		}
+	default:
+		fs.Usage()
+		return 2
+	}
+
+	var err error
+	switch d := out.(type) {
+	case []internal.ModuleDescription:
+		if *format == "json" {
+			err = writeModulesJSON(stdout, d)
+		} else {
+			err = writeModulesTable(stdout, d)
+		}
+	case internal.ModuleDescription:
+		if *format == "json" {
+			err = writeModulesJSON(stdout, d)
+		} else {
+			err = writeModuleDescription(stdout, d)
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to write output: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// writeModulesTable prints one aligned row per module with the event types it consumes.
+func writeModulesTable(w io.Writer, modules []internal.ModuleDescription) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "MODULE\tEVENTS")
+	for _, d := range modules {
+		fmt.Fprintf(tw, "%s\t%s\n", d.Name, moduleEvents(d))
+	}
+	return tw.Flush()
+}
+
+// writeModuleDescription prints a module's events with their actions and payload types.
+func writeModuleDescription(w io.Writer, d internal.ModuleDescription) error {
+	fmt.Fprintf(w, "Module:   %s\n", d.Name)
+	if d.ConfigVersion > 0 {
+		fmt.Fprintf(w, "Config:   version %d\n", d.ConfigVersion)
+	}
+	fmt.Fprintf(w, "Payloads: %s\n", d.PayloadSchema)
+	if len(d.Permissions) > 0 {
+		var perms []string
+		for _, name := range slices.Sorted(maps.Keys(d.Permissions)) {
+			perms = append(perms, name+":"+d.Permissions[name])
+		}
+		fmt.Fprintf(w, "Access:   %s\n", strings.Join(perms, ", "))
+	}
+	if len(d.Commands) > 0 {
+		fmt.Fprintf(w, "Commands: /%s\n", strings.Join(d.Commands, ", /"))
+	}
+	if d.Normalized {
+		fmt.Fprintln(w, "Also handles normalized pull request and issue events from all sources.")
+	}
+	fmt.Fprintln(w)
+	if d.AllEvents {
+		_, err := fmt.Fprintln(w, "Declares no subscriptions, so it is handed every event.")
+		return err
+	}
+	if len(d.Events) == 0 {
+		_, err := fmt.Fprintln(w, "Consumes no events.")
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "EVENT\tACTIONS\tPAYLOAD")
+	for _, e := range d.Events {
+		actions := strings.Join(e.Actions, ", ")
+		if actions == "" {
+			actions = "*"
+		}
+		payload := e.Payload
+		if payload == "" {
+			payload = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", e.Event, actions, payload)
+	}
+	return tw.Flush()
+}
+
+// moduleEvents summarizes the event types a module consumes.
+func moduleEvents(d internal.ModuleDescription) string {
+	if d.AllEvents {
+		return "*"
+	}
+	if len(d.Events) == 0 {
+		return "-"
+	}
+	var events []string
+	for _, e := range d.Events {
+		events = append(events, e.Event)
+	}
+	return strings.Join(events, ", ")
+}
+
+// writeModulesJSON prints a value, such as a module description, as indented JSON.
+func writeModulesJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
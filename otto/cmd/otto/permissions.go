@@ -0,0 +1,121 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+)
+
+// runPermissions implements `otto permissions report`, which prints the smallest set of
+// GitHub App permissions covering the API calls Otto recorded over the past days.
+func runPermissions(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("permissions report", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	days := fs.Int("days", 30, "number of days of recorded calls to cover")
+	format := fs.String("format", "table", "output format: table or json")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, `usage: otto permissions report [-days 30] [-format table|json]
+
+Prints the GitHub App permissions needed by the GitHub API calls Otto made over the past
+days, as recorded in the database in db_path with api_usage enabled, next to those that
+modules declare. Calls whose permission cannot be told from the route, such as GraphQL
+queries, are listed to be checked by hand.
+
+Flags:`)
+		fs.PrintDefaults()
+	}
+	if len(args) == 0 || args[0] != "report" {
+		fs.Usage()
+		return 2
+	}
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+	if fs.NArg() != 0 || *days <= 0 || (*format != "table" && *format != "json") {
+		fs.Usage()
+		return 2
+	}
+
+	cfg, err := config.LoadFromFile(config.GetEnvOrDefault("OTTO_CONFIG", "config.yaml"))
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to load config: %v\n", err)
+		return 1
+	}
+	db, err := internal.NewDatabase(cfg.DBPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "%v\n", err)
+		return 1
+	}
+	defer db.Close()
+
+	usage, err := internal.NewAPIUsage(db.DB())
+	if err != nil {
+		fmt.Fprintf(stderr, "%v\n", err)
+		return 1
+	}
+	report, err := usage.Report(ctx, time.Now().AddDate(0, 0, -*days))
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to build the report: %v\n", err)
+		return 1
+	}
+	report.Declared = internal.DeclaredPermissions(allModules())
+
+	if *format == "json" {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(report)
+	} else {
+		err = writePermissionReport(stdout, report)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to write output: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// writePermissionReport prints the permissions needed next to those declared, then the
+// endpoints called and those whose permission is unknown.
+func writePermissionReport(w io.Writer, report *internal.PermissionReport) error {
+	fmt.Fprintf(w, "GitHub API calls since %s\n\n", report.Since.UTC().Format(time.DateOnly))
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "PERMISSION\tNEEDED\tDECLARED")
+	for _, name := range report.PermissionNames() {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", name, orDash(report.Permissions[name]), orDash(report.Declared[name]))
+	}
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "ENDPOINT\tCALLS\tPERMISSION\tMODULES")
+	for _, e := range report.Endpoints {
+		fmt.Fprintf(tw, "%s %s\t%d\t%s\t%s\n",
+			e.Method, e.Route, e.Calls, orDash(e.Permission), orDash(strings.Join(e.Modules, ",")))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if len(report.Unmapped) > 0 {
+		fmt.Fprintf(w, "\nCheck by hand, the permission cannot be told from the route (%d):\n",
+			len(report.Unmapped))
+		for _, e := range report.Unmapped {
+			fmt.Fprintf(w, "  %s %s (%d calls)\n", e.Method, e.Route, e.Calls)
+		}
+	}
+	return nil
+}
+
+// orDash returns s, or "-" if it is empty.
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
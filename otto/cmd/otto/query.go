@@ -0,0 +1,161 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+	"github.com/open-telemetry/sig-project-infra/otto/internal/secrets"
+)
+
+// queryEvent is the JSON form of a stored event.
+type queryEvent struct {
+	ID         int64           `json:"id"`
+	DeliveryID string          `json:"delivery_id,omitempty"`
+	Type       string          `json:"type"`
+	Action     string          `json:"action,omitempty"`
+	Repo       string          `json:"repo,omitempty"`
+	Sender     string          `json:"sender,omitempty"`
+	ReceivedAt time.Time       `json:"received_at"`
+	Payload    json.RawMessage `json:"payload,omitempty"`
+}
+
+// openEventStore opens the event store of the database, reading payloads offloaded to a
+// bucket back from it when one is configured.
+func openEventStore(cfg *config.AppConfig, db *internal.Database) (*internal.EventStore, error) {
+	store, err := internal.NewEventStore(db.DB())
+	if err != nil || cfg.EventPayloads.Backend == "" {
+		return store, err
+	}
+	secretsManager, err := secrets.LoadSecrets(config.GetEnvOrDefault("OTTO_SECRETS", "secrets.yaml"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load secrets: %w", err)
+	}
+	transport, err := internal.NewHTTPTransport(cfg.HTTP)
+	if err != nil {
+		return nil, err
+	}
+	accessKey := secretsManager.GetSecret(internal.PayloadAccessKeySecret)
+	secretKey := secretsManager.GetSecret(internal.PayloadSecretKeySecret)
+	payloads, err := internal.NewPayloadStore(cfg.EventPayloads, accessKey, secretKey,
+		&http.Client{Transport: transport, Timeout: cfg.EventPayloads.Timeout})
+	if err != nil {
+		return nil, err
+	}
+	return store.WithPayloadStore(payloads, cfg.EventPayloads.OffloadAbove), nil
+}
+
+// runQuery implements `otto query`, which searches the event store without starting the server.
+func runQuery(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("query", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	format := fs.String("format", "table", "output format: table or json")
+	payload := fs.Bool("payload", false, "include event payloads in JSON output")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, `usage: otto query [-format table|json] [-payload] "<query>"
+
+Example:
+  otto query "events where repo='collector' and type='issues' last 24h"
+
+Grammar:
+  events [where <field>=<value> [and ...]] [last <duration>] [since <time>] [until <time>] [limit <n>]
+  fields: repo, type, action, sender; durations like 30m, 24h or 7d; times in RFC 3339 or YYYY-MM-DD
+
+Flags:`)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 || (*format != "table" && *format != "json") {
+		fs.Usage()
+		return 2
+	}
+
+	q, err := internal.ParseEventQuery(strings.Join(fs.Args(), " "), time.Now())
+	if err != nil {
+		fmt.Fprintf(stderr, "invalid query: %v\n", err)
+		return 2
+	}
+
+	cfg, err := config.LoadFromFile(config.GetEnvOrDefault("OTTO_CONFIG", "config.yaml"))
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to load config: %v\n", err)
+		return 1
+	}
+	db, err := internal.NewDatabase(cfg.DBPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "%v\n", err)
+		return 1
+	}
+	defer db.Close()
+	store, err := openEventStore(cfg, db)
+	if err != nil {
+		fmt.Fprintf(stderr, "%v\n", err)
+		return 1
+	}
+	events, err := store.Query(ctx, q)
+	if err != nil {
+		fmt.Fprintf(stderr, "query failed: %v\n", err)
+		return 1
+	}
+
+	if *format == "json" {
+		err = writeEventsJSON(stdout, events, *payload)
+	} else {
+		err = writeEventsTable(stdout, events)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to write output: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// writeEventsTable prints one aligned row per event.
+func writeEventsTable(w io.Writer, events []internal.StoredEvent) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tRECEIVED\tTYPE\tACTION\tREPO\tSENDER")
+	for _, e := range events {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\n",
+			e.ID, e.ReceivedAt.UTC().Format(time.RFC3339), e.Type, e.Action, e.Repo, e.Sender)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "%d events\n", len(events))
+	return err
+}
+
+// writeEventsJSON prints the events as a JSON array.
+func writeEventsJSON(w io.Writer, events []internal.StoredEvent, withPayload bool) error {
+	out := make([]queryEvent, 0, len(events))
+	for _, e := range events {
+		qe := queryEvent{
+			ID:         e.ID,
+			DeliveryID: e.DeliveryID,
+			Type:       e.Type,
+			Action:     e.Action,
+			Repo:       e.Repo,
+			Sender:     e.Sender,
+			ReceivedAt: e.ReceivedAt.UTC(),
+		}
+		if withPayload && json.Valid(e.Payload) {
+			qe.Payload = e.Payload
+		}
+		out = append(out, qe)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
@@ -0,0 +1,184 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"text/tabwriter"
+	"time"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+	"github.com/open-telemetry/sig-project-infra/otto/internal/secrets"
+)
+
+// runReplay implements `otto replay`, which replays a repository's stored events through a
+// module in a sandbox and prints the actions it would have taken.
+func runReplay(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("replay", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	configPath := fs.String("config", config.GetEnvOrDefault("OTTO_CONFIG", "config.yaml"),
+		"config file; point it at an edited copy to try other module settings")
+	repo := fs.String("repo", "", "repository whose events are replayed, owner/name or name")
+	module := fs.String("module", "", "module the events are replayed through")
+	from := fs.String("from", "", "replay events received at or after this date (default: 7 days ago)")
+	until := fs.String("until", "", "replay events received before this date (default: now)")
+	sandbox := fs.Bool("sandbox", false, "replay the events; without it, only list them")
+	jobs := fs.Bool("jobs", false, "run the module's scheduled jobs once after the events")
+	format := fs.String("format", "table", "output format: table or json")
+	verbose := fs.Bool("v", false, "log what the module does")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, `usage: otto replay -repo <repo> -module <name> [-from DATE] [-until DATE] [-sandbox] [-jobs]
+
+Replays a repository's stored events, oldest first, through a module in a sandbox: a
+scratch database, and a GitHub client that reads from GitHub with the configured
+credentials but records writes instead of making them. Notifications are recorded too.
+Prints the actions the module would have taken and the event or job it took each for.
+Dates are RFC 3339 times or YYYY-MM-DD.
+
+Example:
+  otto replay -repo collector -module inactivity -from 2025-05-01 -sandbox -jobs
+
+Flags:`)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 0 || *repo == "" || *module == "" || (*format != "table" && *format != "json") {
+		fs.Usage()
+		return 2
+	}
+	q := internal.EventQuery{Repo: *repo, Since: time.Now().Add(-7 * 24 * time.Hour)}
+	for _, f := range []struct {
+		value string
+		t     *time.Time
+	}{{*from, &q.Since}, {*until, &q.Until}} {
+		if f.value == "" {
+			continue
+		}
+		t, err := parseReplayTime(f.value)
+		if err != nil {
+			fmt.Fprintf(stderr, "invalid date %q: use RFC 3339 or YYYY-MM-DD\n", f.value)
+			return 2
+		}
+		*f.t = t
+	}
+	var mod internal.Module
+	for _, m := range allModules() {
+		if m.Name() == *module {
+			mod = m
+		}
+	}
+	if mod == nil {
+		fmt.Fprintf(stderr, "unknown module %q\n", *module)
+		return 1
+	}
+
+	level := slog.LevelWarn
+	if *verbose {
+		level = slog.LevelInfo
+	}
+	slog.SetDefault(slog.New(slog.NewTextHandler(stderr, &slog.HandlerOptions{Level: level})))
+
+	cfg, err := config.LoadFromFile(*configPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to load config: %v\n", err)
+		return 1
+	}
+	db, err := internal.NewDatabase(cfg.DBPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "%v\n", err)
+		return 1
+	}
+	defer db.Close()
+	store, err := openEventStore(cfg, db)
+	if err != nil {
+		fmt.Fprintf(stderr, "%v\n", err)
+		return 1
+	}
+	events, err := store.Query(ctx, q)
+	if err != nil {
+		fmt.Fprintf(stderr, "query failed: %v\n", err)
+		return 1
+	}
+	if !*sandbox {
+		if err := writeEventsTable(stdout, events); err != nil {
+			fmt.Fprintf(stderr, "failed to write output: %v\n", err)
+			return 1
+		}
+		fmt.Fprintln(stdout, "Pass -sandbox to replay these events through", *module)
+		return 0
+	}
+
+	secretsManager, err := secrets.LoadSecrets(config.GetEnvOrDefault("OTTO_SECRETS", "secrets.yaml"))
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to load secrets: %v\n", err)
+		return 1
+	}
+	box, err := internal.NewSandbox(ctx, cfg, secretsManager, mod)
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to create sandbox: %v\n", err)
+		return 1
+	}
+	defer box.Close()
+	result := internal.ReplayResult{Actions: []internal.ReplayAction{}}
+	err = box.Replay(ctx, events, &result)
+	if err == nil && *jobs {
+		err = box.RunJobs(ctx, &result)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "replay failed: %v\n", err)
+		return 1
+	}
+
+	if *format == "json" {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(result)
+	} else {
+		err = writeReplayTable(stdout, result)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to write output: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// parseReplayTime parses an RFC 3339 time or a date.
+func parseReplayTime(s string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		t, err = time.Parse(time.DateOnly, s)
+	}
+	return t, err
+}
+
+// writeReplayTable prints one aligned row per action and a summary of the replay.
+func writeReplayTable(w io.Writer, result internal.ReplayResult) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "RECEIVED\tCAUSE\tACTION\tPATH")
+	for _, a := range result.Actions {
+		received, cause := "-", "job "+a.Job
+		if a.Job == "" {
+			received = a.ReceivedAt.UTC().Format(time.RFC3339)
+			cause = fmt.Sprintf("%s (event %d)", a.Event, a.EventID)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", received, cause, a.Action, a.Path)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	for _, e := range result.Errors {
+		fmt.Fprintf(w, "error: %s\n", e)
+	}
+	_, err := fmt.Fprintf(w, "%d events replayed, %d skipped, %d errors, %d actions\n",
+		result.Events, result.Skipped, len(result.Errors), len(result.Actions))
+	return err
+}
@@ -0,0 +1,187 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// actionsapi.go serves /api/v1/actions, where trusted systems such as release tooling
+// queue actions for Otto to carry out through the outbox.
+
+package internal
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+	"github.com/open-telemetry/sig-project-infra/otto/internal/secrets"
+)
+
+// IdempotencyKeyHeader carries the key that makes retried action requests safe.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// maxActionRequest bounds the size of an action request body.
+const maxActionRequest = 1 << 20
+
+// actionsClient is a configured client with its token.
+type actionsClient struct {
+	name    string
+	token   []byte
+	repos   []string
+	actions []string
+}
+
+// ActionsAPI authenticates clients and queues their actions in the outbox.
+type ActionsAPI struct {
+	outbox  *Outbox
+	clients []actionsClient
+}
+
+// NewActionsAPI reads the clients' tokens from their secrets. Clients whose secret is not
+// configured cannot authenticate. It returns nil without clients.
+func NewActionsAPI(cfg config.ActionsAPIConfig, secretsManager secrets.Manager, outbox *Outbox) (*ActionsAPI, error) {
+	if len(cfg.Clients) == 0 {
+		return nil, nil
+	}
+	api := &ActionsAPI{outbox: outbox}
+	for name, client := range cfg.Clients {
+		for _, kind := range client.Actions {
+			if !slices.Contains(ActionKinds, ActionKind(kind)) {
+				return nil, fmt.Errorf("actions_api: client %s allows unknown action %q", name, kind)
+			}
+		}
+		if client.Secret == "" {
+			return nil, fmt.Errorf("actions_api: client %s has no secret", name)
+		}
+		token := secretsManager.GetSecret(client.Secret)
+		if token == "" {
+			slog.Error("Actions API client disabled: its secret is not configured", "client", name,
+				"secret", client.Secret)
+			continue
+		}
+		api.clients = append(api.clients, actionsClient{
+			name: name, token: []byte(token), repos: client.Repos, actions: client.Actions,
+		})
+	}
+	return api, nil
+}
+
+// RegisterRoutes serves the API on the webhook port. A nil API serves nothing.
+func (a *ActionsAPI) RegisterRoutes(srv *Server) {
+	if a == nil || srv == nil {
+		return
+	}
+	srv.mux.HandleFunc("POST /api/v1/actions", a.handleCreate)
+	srv.mux.HandleFunc("GET /api/v1/actions/{id}", a.handleGet)
+}
+
+// authenticate returns the client whose token the request carries.
+func (a *ActionsAPI) authenticate(r *http.Request) *actionsClient {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return nil
+	}
+	for i := range a.clients {
+		if subtle.ConstantTimeCompare([]byte(token), a.clients[i].token) == 1 {
+			return &a.clients[i]
+		}
+	}
+	return nil
+}
+
+// source is the outbox source of the client's actions.
+func (c *actionsClient) source() string {
+	return "api:" + c.name
+}
+
+// handleCreate queues an action. A new action is answered with 202 Accepted, a retried
+// request with the action its idempotency key queued.
+func (a *ActionsAPI) handleCreate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	client := a.authenticate(r)
+	if client == nil {
+		writeProblem(ctx, w, r, http.StatusUnauthorized, "invalid-token", "missing or unknown bearer token")
+		return
+	}
+	key := r.Header.Get(IdempotencyKeyHeader)
+	if key == "" {
+		writeProblem(ctx, w, r, http.StatusBadRequest, "missing-idempotency-key",
+			"requests need an "+IdempotencyKeyHeader+" header")
+		return
+	}
+
+	var action Action
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxActionRequest))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&action); err != nil {
+		writeProblem(ctx, w, r, http.StatusBadRequest, "invalid-action", "invalid JSON: "+err.Error())
+		return
+	}
+	if len(client.actions) > 0 && !slices.Contains(client.actions, string(action.Kind)) ||
+		len(client.repos) > 0 && !slices.Contains(client.repos, action.Repo) {
+		slog.WarnContext(ctx, "Action denied", "client", client.name, "kind", action.Kind, "repo", action.Repo,
+			"idempotency_key", key)
+		writeProblem(ctx, w, r, http.StatusForbidden, "action-not-allowed",
+			fmt.Sprintf("client %s may not %s in %s", client.name, action.Kind, action.Repo))
+		return
+	}
+
+	// Only the request fields are the client's to set.
+	action = Action{
+		Source: client.source(), IdempotencyKey: key, Kind: action.Kind, Repo: action.Repo,
+		Number: action.Number, Title: action.Title, Body: action.Body, Labels: action.Labels,
+	}
+	if err := action.Validate(); err != nil {
+		writeProblem(ctx, w, r, http.StatusUnprocessableEntity, "invalid-action", err.Error())
+		return
+	}
+	queued, created, err := a.outbox.Enqueue(ctx, action)
+	if errors.Is(err, ErrIdempotencyConflict) {
+		writeProblem(ctx, w, r, http.StatusConflict, "idempotency-conflict",
+			fmt.Sprintf("%s %q was used for action %d", IdempotencyKeyHeader, key, queued.ID))
+		return
+	}
+	if err != nil {
+		writeProblem(ctx, w, r, http.StatusInternalServerError, "enqueue-failed", "the action could not be queued")
+		return
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusAccepted
+	}
+	w.Header().Set("Location", "/api/v1/actions/"+strconv.FormatInt(queued.ID, 10))
+	writeAction(w, status, queued)
+}
+
+// handleGet returns the status of one of the client's actions.
+func (a *ActionsAPI) handleGet(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	client := a.authenticate(r)
+	if client == nil {
+		writeProblem(ctx, w, r, http.StatusUnauthorized, "invalid-token", "missing or unknown bearer token")
+		return
+	}
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeProblem(ctx, w, r, http.StatusNotFound, "not-found", "no such action")
+		return
+	}
+	action, err := a.outbox.Get(ctx, id)
+	if err != nil || action.Source != client.source() {
+		writeProblem(ctx, w, r, http.StatusNotFound, "not-found", "no such action")
+		return
+	}
+	writeAction(w, http.StatusOK, action)
+}
+
+func writeAction(w http.ResponseWriter, status int, action Action) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(action); err != nil {
+		slog.Error("Failed to write JSON response", "error", err)
+	}
+}
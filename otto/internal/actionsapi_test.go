@@ -0,0 +1,113 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+	"github.com/open-telemetry/sig-project-infra/otto/internal/secrets"
+)
+
+func TestActionsAPI(t *testing.T) {
+	t.Setenv("OTTO_SECRET_RELEASE_TOKEN", "release-token")
+	t.Setenv("OTTO_SECRET_DASHBOARD_TOKEN", "dashboard-token")
+	outbox, err := NewOutbox(TestDB(t), nil, 3)
+	if err != nil {
+		t.Fatalf("NewOutbox failed: %v", err)
+	}
+	api, err := NewActionsAPI(config.ActionsAPIConfig{Clients: map[string]config.ActionsAPIClient{
+		"release":   {Secret: "release_token", Repos: []string{"org/a"}},
+		"dashboard": {Secret: "dashboard_token", Actions: []string{"label"}},
+		"unset":     {Secret: "unset_token"},
+	}}, secrets.NewEnvManager(), outbox)
+	if err != nil {
+		t.Fatalf("NewActionsAPI failed: %v", err)
+	}
+	srv := NewServerWithApp("0", secrets.NewEnvManager(), nil)
+	api.RegisterRoutes(srv)
+
+	comment := `{"kind": "comment", "repo": "org/a", "number": 1, "body": "v1.2.0 is out"}`
+	tests := []struct {
+		name       string
+		token, key string
+		body       string
+		wantStatus int
+		wantType   string
+	}{
+		{"no token", "", "k1", comment, http.StatusUnauthorized, "urn:otto:problem:invalid-token"},
+		{"unknown token", "guess", "k1", comment, http.StatusUnauthorized, "urn:otto:problem:invalid-token"},
+		{"no key", "release-token", "", comment, http.StatusBadRequest, "urn:otto:problem:missing-idempotency-key"},
+		{"queued", "release-token", "k1", comment, http.StatusAccepted, ""},
+		{"retried", "release-token", "k1", comment, http.StatusOK, ""},
+		{"key reused", "release-token", "k1", `{"kind": "comment", "repo": "org/a", "number": 2, "body": "x"}`,
+			http.StatusConflict, "urn:otto:problem:idempotency-conflict"},
+		{"other repo", "release-token", "k2", `{"kind": "comment", "repo": "org/b", "number": 1, "body": "x"}`,
+			http.StatusForbidden, "urn:otto:problem:action-not-allowed"},
+		{"other action", "dashboard-token", "k3", comment, http.StatusForbidden, "urn:otto:problem:action-not-allowed"},
+		{"invalid", "dashboard-token", "k4", `{"kind": "label", "repo": "org/b", "number": 1}`,
+			http.StatusUnprocessableEntity, "urn:otto:problem:invalid-action"},
+		{"unknown field", "release-token", "k5", `{"kind": "comment", "status": "done", "merge": true}`,
+			http.StatusBadRequest, "urn:otto:problem:invalid-action"},
+		{"same key, other client", "dashboard-token", "k1", `{"kind": "label", "repo": "org/b", "number": 1,
+			"labels": ["release"]}`, http.StatusAccepted, ""},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/actions", strings.NewReader(tt.body))
+		if tt.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+		}
+		if tt.key != "" {
+			req.Header.Set(IdempotencyKeyHeader, tt.key)
+		}
+		rr := httptest.NewRecorder()
+		srv.mux.ServeHTTP(rr, req)
+		if rr.Code != tt.wantStatus {
+			t.Errorf("%s: status = %d, want %d: %s", tt.name, rr.Code, tt.wantStatus, rr.Body.String())
+			continue
+		}
+		if tt.wantType != "" {
+			var p Problem
+			if err := json.NewDecoder(rr.Body).Decode(&p); err != nil || p.Type != tt.wantType {
+				t.Errorf("%s: problem = %+v, %v, want %s", tt.name, p, err, tt.wantType)
+			}
+			continue
+		}
+		var action Action
+		if err := json.NewDecoder(rr.Body).Decode(&action); err != nil || action.Status != ActionPending ||
+			rr.Header().Get("Location") != "/api/v1/actions/"+strconv.FormatInt(action.ID, 10) {
+			t.Errorf("%s: action = %+v, %v, location %q", tt.name, action, err, rr.Header().Get("Location"))
+		}
+	}
+
+	actions, err := outbox.List(t.Context(), "", "", 10)
+	if err != nil || len(actions) != 2 || actions[1].Source != "api:release" || actions[0].Source != "api:dashboard" {
+		t.Fatalf("queued actions = %+v, %v", actions, err)
+	}
+
+	// Clients see only their own actions.
+	for token, want := range map[string]int{"release-token": http.StatusOK, "dashboard-token": http.StatusNotFound} {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/actions/"+strconv.FormatInt(actions[1].ID, 10), nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := httptest.NewRecorder()
+		srv.mux.ServeHTTP(rr, req)
+		if rr.Code != want {
+			t.Errorf("GET with %s: status = %d, want %d", token, rr.Code, want)
+		}
+	}
+
+	if api, err := NewActionsAPI(config.ActionsAPIConfig{}, secrets.NewEnvManager(), outbox); api != nil || err != nil {
+		t.Errorf("NewActionsAPI without clients = %v, %v", api, err)
+	}
+	for _, client := range []config.ActionsAPIClient{{}, {Secret: "release_token", Actions: []string{"merge"}}} {
+		cfg := config.ActionsAPIConfig{Clients: map[string]config.ActionsAPIClient{"bad": client}}
+		if _, err := NewActionsAPI(cfg, secrets.NewEnvManager(), outbox); err == nil {
+			t.Errorf("expected error for client %+v", client)
+		}
+	}
+}
@@ -0,0 +1,432 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// adminlogin.go signs people in to the admin API with GitHub OAuth or an OIDC provider,
+// so they need not share the admin token, which machine clients keep using. A person's
+// role comes from the GitHub teams they are in and is kept in a signed session cookie.
+
+package internal
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v71/github"
+	"golang.org/x/oauth2"
+	githuboauth "golang.org/x/oauth2/github"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+)
+
+const (
+	// AdminLoginSecret is the secret holding the OAuth client secret of admin sign-in.
+	AdminLoginSecret = "admin_login_client_secret"
+	// AdminSessionKeySecret is the secret admin session cookies are signed with. Without
+	// it, a random key is used and sessions end when Otto restarts.
+	AdminSessionKeySecret = "admin_session_key"
+)
+
+const (
+	sessionCookie    = "otto_session"
+	loginStateCookie = "otto_login"
+	loginStateTTL    = 10 * time.Minute
+	// defaultLoginReturn is where people land after signing in without a page to return to.
+	defaultLoginReturn = "/admin/whoami"
+)
+
+// AdminIdentity is who made an admin API request: a person signed in, or a machine
+// client with the admin token, which has no login.
+type AdminIdentity struct {
+	Login   string    `json:"login,omitempty"`
+	Role    string    `json:"role"`
+	Method  string    `json:"method"` // "token" or "session"
+	Expires time.Time `json:"expires,omitzero"`
+}
+
+type adminIdentityKey struct{}
+
+// AdminUser returns who made an admin API request, if it passed the admin check.
+func AdminUser(ctx context.Context) (AdminIdentity, bool) {
+	id, ok := ctx.Value(adminIdentityKey{}).(AdminIdentity)
+	return id, ok
+}
+
+// adminLogin signs people in to the admin API.
+type adminLogin struct {
+	config config.AdminLoginConfig
+	secret string
+	key    []byte         // signs session cookies
+	client *http.Client   // requests to the sign-in provider
+	github *github.Client // the app's client, checking the team membership of OIDC users
+	apiURL string         // GitHub API base URL for the tokens of people; empty for api.github.com
+	now    func() time.Time
+
+	mu          sync.Mutex
+	endpoint    oauth2.Endpoint // of the OIDC provider, once discovered
+	userinfoURL string
+}
+
+// newAdminLogin creates the sign-in of the admin API. OIDC users are looked up in the
+// configured teams with gh, since the provider knows nothing about them.
+func newAdminLogin(cfg config.AdminLoginConfig, clientSecret, sessionKey string, client *http.Client,
+	gh *github.Client,
+) (*adminLogin, error) {
+	if clientSecret == "" {
+		return nil, fmt.Errorf("the %s secret is not configured", AdminLoginSecret)
+	}
+	if cfg.Provider == config.LoginProviderOIDC && gh == nil {
+		return nil, errors.New("OIDC sign-in needs GitHub App credentials to check team membership")
+	}
+	l := &adminLogin{config: cfg, secret: clientSecret, key: []byte(sessionKey), client: client, github: gh,
+		now: time.Now}
+	if len(l.key) == 0 {
+		slog.Warn("The admin_session_key secret is not set; admin sessions end when Otto restarts")
+		l.key = make([]byte, 32)
+		if _, err := rand.Read(l.key); err != nil {
+			return nil, fmt.Errorf("failed to generate session key: %w", err)
+		}
+	}
+	if cfg.Provider == config.LoginProviderGitHub {
+		l.endpoint = githuboauth.Endpoint
+	}
+	return l, nil
+}
+
+// RegisterRoutes serves the sign-in pages on mux.
+func (l *adminLogin) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /admin/login", l.handleLogin)
+	mux.HandleFunc("GET /admin/login/callback", l.handleCallback)
+	mux.HandleFunc("/admin/logout", l.handleLogout)
+}
+
+// oauthConfig returns the OAuth client of the provider, discovering the endpoints of an
+// OIDC provider on first use.
+func (l *adminLogin) oauthConfig(ctx context.Context) (*oauth2.Config, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	cfg := &oauth2.Config{
+		ClientID:     l.config.ClientID,
+		ClientSecret: l.secret,
+		RedirectURL:  l.config.RedirectURL,
+		Scopes:       []string{"read:user", "read:org"},
+	}
+	if l.config.Provider == config.LoginProviderOIDC {
+		cfg.Scopes = []string{"openid", "profile"}
+		if l.endpoint.TokenURL == "" {
+			if err := l.discover(ctx); err != nil {
+				return nil, err
+			}
+		}
+	}
+	cfg.Endpoint = l.endpoint
+	return cfg, nil
+}
+
+// discover reads the endpoints of the OIDC provider from its discovery document.
+func (l *adminLogin) discover(ctx context.Context) error {
+	u := strings.TrimSuffix(l.config.Issuer, "/") + "/.well-known/openid-configuration"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to discover OIDC provider %s: %w", l.config.Issuer, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to discover OIDC provider %s: %s", l.config.Issuer, resp.Status)
+	}
+	var doc struct {
+		AuthorizationEndpoint string `json:"authorization_endpoint"`
+		TokenEndpoint         string `json:"token_endpoint"`
+		UserinfoEndpoint      string `json:"userinfo_endpoint"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return fmt.Errorf("failed to decode OIDC discovery document: %w", err)
+	}
+	if doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" || doc.UserinfoEndpoint == "" {
+		return errors.New("OIDC discovery document lacks the authorization, token or userinfo endpoint")
+	}
+	l.endpoint = oauth2.Endpoint{AuthURL: doc.AuthorizationEndpoint, TokenURL: doc.TokenEndpoint}
+	l.userinfoURL = doc.UserinfoEndpoint
+	return nil
+}
+
+// handleLogin sends people to the provider to sign in, and back to ?return= afterwards.
+func (l *adminLogin) handleLogin(w http.ResponseWriter, r *http.Request) {
+	cfg, err := l.oauthConfig(r.Context())
+	if err != nil {
+		slog.Error("Failed to start admin sign-in", "error", err)
+		http.Error(w, "sign-in is unavailable", http.StatusBadGateway)
+		return
+	}
+	state := make([]byte, 16)
+	if _, err := rand.Read(state); err != nil {
+		http.Error(w, "failed to start sign-in", http.StatusInternalServerError)
+		return
+	}
+	verifier := oauth2.GenerateVerifier()
+	value := hex.EncodeToString(state) + "." + verifier + "." +
+		base64.RawURLEncoding.EncodeToString([]byte(loginReturn(r.URL.Query().Get("return"))))
+	http.SetCookie(w, l.cookie(loginStateCookie, value, loginStateTTL))
+	http.Redirect(w, r, cfg.AuthCodeURL(hex.EncodeToString(state), oauth2.S256ChallengeOption(verifier)),
+		http.StatusFound)
+}
+
+// handleCallback completes a sign-in: it exchanges the code for a token, looks up the
+// person's role and starts their session.
+func (l *adminLogin) handleCallback(w http.ResponseWriter, r *http.Request) {
+	if e := r.URL.Query().Get("error"); e != "" {
+		http.Error(w, "sign-in failed: "+e, http.StatusUnauthorized)
+		return
+	}
+	cookie, err := r.Cookie(loginStateCookie)
+	if err != nil {
+		http.Error(w, "sign-in expired, please try again", http.StatusBadRequest)
+		return
+	}
+	http.SetCookie(w, l.cookie(loginStateCookie, "", -1))
+	parts := strings.Split(cookie.Value, ".")
+	if len(parts) != 3 || parts[0] != r.URL.Query().Get("state") {
+		http.Error(w, "sign-in state does not match", http.StatusBadRequest)
+		return
+	}
+	returnTo, _ := base64.RawURLEncoding.DecodeString(parts[2])
+
+	ctx := context.WithValue(r.Context(), oauth2.HTTPClient, l.client)
+	cfg, err := l.oauthConfig(ctx)
+	if err != nil {
+		slog.Error("Failed to complete admin sign-in", "error", err)
+		http.Error(w, "sign-in is unavailable", http.StatusBadGateway)
+		return
+	}
+	token, err := cfg.Exchange(ctx, r.URL.Query().Get("code"), oauth2.VerifierOption(parts[1]))
+	if err != nil {
+		slog.Warn("Failed to exchange admin sign-in code", "error", err)
+		http.Error(w, "sign-in failed", http.StatusUnauthorized)
+		return
+	}
+	login, role, err := l.identify(ctx, cfg, token)
+	if errors.Is(err, errUnmappedSubject) {
+		slog.Warn("Refused admin sign-in of a person with no GitHub login", "error", err)
+		http.Error(w, "your account is not mapped to a GitHub login", http.StatusForbidden)
+		return
+	}
+	if err != nil {
+		slog.Error("Failed to look up admin sign-in", "error", err)
+		http.Error(w, "failed to look up your teams", http.StatusBadGateway)
+		return
+	}
+	if role == "" {
+		slog.Warn("Refused admin sign-in of a person in none of the teams", "login", login)
+		http.Error(w, login+" is not in any of the teams allowed to sign in", http.StatusForbidden)
+		return
+	}
+
+	session := AdminIdentity{Login: login, Role: role, Method: "session",
+		Expires: l.now().Add(l.config.SessionTTL).Truncate(time.Second)}
+	http.SetCookie(w, l.cookie(sessionCookie, l.sign(session), l.config.SessionTTL))
+	slog.Info("Admin signed in", "login", login, "role", role)
+	http.Redirect(w, r, loginReturn(string(returnTo)), http.StatusFound)
+}
+
+// handleLogout ends the session.
+func (l *adminLogin) handleLogout(w http.ResponseWriter, r *http.Request) {
+	http.SetCookie(w, l.cookie(sessionCookie, "", -1))
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = w.Write([]byte("Signed out.\n"))
+}
+
+// identify returns the login of the person a token belongs to and their role, which is
+// empty if they are in none of the configured teams.
+func (l *adminLogin) identify(ctx context.Context, cfg *oauth2.Config, token *oauth2.Token) (string, string, error) {
+	if l.config.Provider == config.LoginProviderOIDC {
+		login, err := l.userinfoLogin(ctx, cfg, token)
+		if err != nil {
+			return "", "", err
+		}
+		var teams []string
+		for team := range l.config.Teams {
+			org, slug, _ := strings.Cut(team, "/")
+			membership, _, err := l.github.Teams.GetTeamMembershipBySlug(ctx, org, slug, login)
+			var errResp *github.ErrorResponse
+			if errors.As(err, &errResp) && errResp.Response.StatusCode == http.StatusNotFound {
+				continue
+			}
+			if err != nil {
+				return "", "", fmt.Errorf("failed to get membership of %s in %s: %w", login, team, err)
+			}
+			if membership.GetState() == "active" {
+				teams = append(teams, team)
+			}
+		}
+		return login, l.role(teams), nil
+	}
+
+	gh := github.NewClient(cfg.Client(ctx, token))
+	if l.apiURL != "" {
+		var err error
+		if gh, err = gh.WithEnterpriseURLs(l.apiURL, l.apiURL); err != nil {
+			return "", "", err
+		}
+	}
+	user, _, err := gh.Users.Get(ctx, "")
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get the signed-in user: %w", err)
+	}
+	var teams []string
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		page, resp, err := gh.Teams.ListUserTeams(ctx, opts)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to list teams of %s: %w", user.GetLogin(), err)
+		}
+		for _, team := range page {
+			teams = append(teams, team.GetOrganization().GetLogin()+"/"+team.GetSlug())
+		}
+		if resp.NextPage == 0 {
+			return user.GetLogin(), l.role(teams), nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// errUnmappedSubject is returned for OIDC users whose subject is not in the configured logins.
+var errUnmappedSubject = errors.New("OIDC subject is not mapped to a GitHub login")
+
+// userinfoLogin returns the GitHub login of the person the OIDC userinfo is about: the one
+// configured for their subject, or the one in the login claim, which the provider is
+// trusted to set to the person's own GitHub login.
+func (l *adminLogin) userinfoLogin(ctx context.Context, cfg *oauth2.Config, token *oauth2.Token) (string, error) {
+	l.mu.Lock()
+	userinfoURL := l.userinfoURL
+	l.mu.Unlock()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, userinfoURL, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := cfg.Client(ctx, token).Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to get OIDC userinfo: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to get OIDC userinfo: %s", resp.Status)
+	}
+	var claims map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&claims); err != nil {
+		return "", fmt.Errorf("failed to decode OIDC userinfo: %w", err)
+	}
+	if len(l.config.Logins) > 0 {
+		sub, _ := claims["sub"].(string)
+		login, ok := l.config.Logins[sub]
+		if sub == "" || !ok {
+			return "", fmt.Errorf("%w: %q", errUnmappedSubject, sub)
+		}
+		return login, nil
+	}
+	login, _ := claims[l.config.LoginClaim].(string)
+	if login == "" {
+		return "", fmt.Errorf("OIDC userinfo has no %s claim", l.config.LoginClaim)
+	}
+	return login, nil
+}
+
+// role returns the highest role of the configured teams among teams, or "".
+func (l *adminLogin) role(teams []string) string {
+	role := ""
+	for _, team := range teams {
+		for configured, r := range l.config.Teams {
+			if !strings.EqualFold(configured, team) {
+				continue
+			}
+			if r == config.AdminRoleAdmin {
+				return r
+			}
+			role = r
+		}
+	}
+	return role
+}
+
+// sign encodes a session as a cookie value with its signature.
+func (l *adminLogin) sign(session AdminIdentity) string {
+	payload, _ := json.Marshal(session)
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	mac := hmac.New(sha256.New, l.key)
+	mac.Write([]byte(encoded))
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// session returns the session of a request's cookie, if it is signed and not expired.
+func (l *adminLogin) session(r *http.Request) (AdminIdentity, bool) {
+	cookie, err := r.Cookie(sessionCookie)
+	if err != nil {
+		return AdminIdentity{}, false
+	}
+	encoded, signature, ok := strings.Cut(cookie.Value, ".")
+	if !ok {
+		return AdminIdentity{}, false
+	}
+	mac := hmac.New(sha256.New, l.key)
+	mac.Write([]byte(encoded))
+	if sig, err := base64.RawURLEncoding.DecodeString(signature); err != nil || !hmac.Equal(sig, mac.Sum(nil)) {
+		return AdminIdentity{}, false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return AdminIdentity{}, false
+	}
+	var session AdminIdentity
+	if err := json.Unmarshal(payload, &session); err != nil || !l.now().Before(session.Expires) {
+		return AdminIdentity{}, false
+	}
+	return session, true
+}
+
+// cookie returns a cookie scoped to the admin API; a negative ttl deletes it.
+func (l *adminLogin) cookie(name, value string, ttl time.Duration) *http.Cookie {
+	maxAge := int(ttl.Seconds())
+	if ttl < 0 {
+		maxAge = -1
+	}
+	return &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   strings.HasPrefix(l.config.RedirectURL, "https://"),
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+// loginReturn returns the local path to go to after signing in, ignoring anything that
+// could send people to another site.
+func loginReturn(path string) string {
+	u, err := url.Parse(path)
+	if err != nil || !strings.HasPrefix(path, "/") || strings.HasPrefix(path, "//") || strings.Contains(path, `\`) ||
+		u.Host != "" {
+		return defaultLoginReturn
+	}
+	return path
+}
+
+// wantsLogin reports whether an unauthenticated request comes from a browser that can be
+// sent to sign in.
+func wantsLogin(r *http.Request) bool {
+	return r.Method == http.MethodGet && strings.Contains(r.Header.Get("Accept"), "text/html")
+}
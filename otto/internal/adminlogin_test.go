@@ -0,0 +1,257 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+)
+
+// signIn goes through the sign-in of l and returns the session cookie and the page the
+// callback redirected to, failing the test unless the callback returns wantStatus.
+func signIn(t *testing.T, l *adminLogin, returnTo string, wantStatus int) (*http.Cookie, string) {
+	t.Helper()
+	rr := httptest.NewRecorder()
+	l.handleLogin(rr, httptest.NewRequest(http.MethodGet, "/admin/login?return="+url.QueryEscape(returnTo), nil))
+	if rr.Code != http.StatusFound {
+		t.Fatalf("login status = %d, want 302: %s", rr.Code, rr.Body)
+	}
+	authorize, err := url.Parse(rr.Header().Get("Location"))
+	if err != nil {
+		t.Fatalf("invalid authorize URL: %v", err)
+	}
+	if authorize.Query().Get("code_challenge") == "" || authorize.Query().Get("client_id") != "client" {
+		t.Errorf("authorize URL = %s", authorize)
+	}
+
+	callback := "/admin/login/callback?code=abc&state=" + authorize.Query().Get("state")
+	req := httptest.NewRequest(http.MethodGet, callback, nil)
+	for _, c := range rr.Result().Cookies() {
+		req.AddCookie(c)
+	}
+	rr = httptest.NewRecorder()
+	l.handleCallback(rr, req)
+	if rr.Code != wantStatus {
+		t.Fatalf("callback status = %d, want %d: %s", rr.Code, wantStatus, rr.Body)
+	}
+	for _, c := range rr.Result().Cookies() {
+		if c.Name == sessionCookie {
+			return c, rr.Header().Get("Location")
+		}
+	}
+	return nil, rr.Header().Get("Location")
+}
+
+// tokenEndpoint answers an OAuth code exchange with the access token "tok".
+func tokenEndpoint(t *testing.T, w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("code") != "abc" || r.PostForm.Get("code_verifier") == "" {
+		t.Errorf("token request form = %v", r.PostForm)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write([]byte(`{"access_token": "tok", "token_type": "bearer"}`))
+}
+
+func TestAdminLoginGitHub(t *testing.T) {
+	teams := `[{"slug": "maintainers", "organization": {"login": "org"}}]`
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			tokenEndpoint(t, w, r)
+		case "/api/v3/user":
+			_, _ = w.Write([]byte(`{"login": "alice"}`))
+		case "/api/v3/user/teams":
+			_, _ = w.Write([]byte(teams))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(provider.Close)
+
+	cfg := config.AdminLoginConfig{
+		Provider:    config.LoginProviderGitHub,
+		ClientID:    "client",
+		RedirectURL: "https://otto.example.com/admin/login/callback",
+		Teams:       map[string]string{"org/maintainers": config.AdminRoleViewer, "org/admins": config.AdminRoleAdmin},
+		SessionTTL:  time.Hour,
+	}
+	l, err := newAdminLogin(cfg, "secret", "key", provider.Client(), nil)
+	if err != nil {
+		t.Fatalf("newAdminLogin failed: %v", err)
+	}
+	l.endpoint = oauth2.Endpoint{AuthURL: provider.URL + "/authorize", TokenURL: provider.URL + "/token"}
+	l.apiURL = provider.URL + "/"
+
+	session, location := signIn(t, l, "/admin/modules", http.StatusFound)
+	if session == nil || !session.Secure || !session.HttpOnly {
+		t.Fatalf("session cookie = %+v", session)
+	}
+	if location != "/admin/modules" {
+		t.Errorf("redirected to %q, want /admin/modules", location)
+	}
+
+	srv := &Server{mux: http.NewServeMux(), adminToken: []byte("token"), login: l}
+	for _, pattern := range []string{"GET /admin/ping", "POST /admin/ping"} {
+		srv.HandleAdmin(pattern, func(w http.ResponseWriter, r *http.Request) {
+			id, _ := AdminUser(r.Context())
+			_ = json.NewEncoder(w).Encode(id)
+		})
+	}
+	request := func(method string, cookie *http.Cookie, header ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/admin/ping", nil)
+		if cookie != nil {
+			req.AddCookie(cookie)
+		}
+		for i := 0; i < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		rr := httptest.NewRecorder()
+		srv.mux.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := request(http.MethodGet, session)
+	var id AdminIdentity
+	if err := json.Unmarshal(rr.Body.Bytes(), &id); err != nil || id.Login != "alice" || id.Role != "viewer" {
+		t.Errorf("GET with session = %d %s, want alice as viewer", rr.Code, rr.Body)
+	}
+	if rr := request(http.MethodPost, session); rr.Code != http.StatusForbidden {
+		t.Errorf("POST by viewer: status = %d, want 403", rr.Code)
+	}
+	if rr := request(http.MethodPost, nil, "Authorization", "Bearer token"); rr.Code != http.StatusOK ||
+		!strings.Contains(rr.Body.String(), `"method":"token"`) {
+		t.Errorf("POST with token = %d %s", rr.Code, rr.Body)
+	}
+	if rr := request(http.MethodGet, session, "Authorization", "Bearer wrong"); rr.Code != http.StatusUnauthorized {
+		t.Errorf("wrong token with session: status = %d, want 401", rr.Code)
+	}
+	if rr := request(http.MethodGet, nil, "Accept", "text/html"); rr.Code != http.StatusFound ||
+		rr.Header().Get("Location") != "/admin/login?return=%2Fadmin%2Fping" {
+		t.Errorf("browser without session = %d to %q", rr.Code, rr.Header().Get("Location"))
+	}
+	if rr := request(http.MethodGet, nil); rr.Code != http.StatusUnauthorized {
+		t.Errorf("without credentials: status = %d, want 401", rr.Code)
+	}
+	tampered := *session
+	tampered.Value = strings.Replace(tampered.Value, tampered.Value[:4], "eyJs", 1) + "x"
+	if rr := request(http.MethodGet, &tampered); rr.Code != http.StatusUnauthorized {
+		t.Errorf("tampered session: status = %d, want 401", rr.Code)
+	}
+	l.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	if rr := request(http.MethodGet, session); rr.Code != http.StatusUnauthorized {
+		t.Errorf("expired session: status = %d, want 401", rr.Code)
+	}
+	l.now = time.Now
+
+	// Admins outrank viewers; people in no team are refused.
+	teams = `[{"slug": "maintainers", "organization": {"login": "org"}},
+		{"slug": "admins", "organization": {"login": "org"}}]`
+	session, location = signIn(t, l, "https://evil.example.com/", http.StatusFound)
+	if location != defaultLoginReturn {
+		t.Errorf("redirected to %q, want %q", location, defaultLoginReturn)
+	}
+	if rr := request(http.MethodPost, session); rr.Code != http.StatusOK {
+		t.Errorf("POST by admin: status = %d, want 200", rr.Code)
+	}
+	teams = `[{"slug": "other", "organization": {"login": "org"}}]`
+	if session, _ := signIn(t, l, "", http.StatusForbidden); session != nil {
+		t.Errorf("session started for a person in no team")
+	}
+}
+
+func TestAdminLoginOIDC(t *testing.T) {
+	var provider *httptest.Server
+	provider = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			_ = json.NewEncoder(w).Encode(map[string]string{
+				"authorization_endpoint": provider.URL + "/authorize",
+				"token_endpoint":         provider.URL + "/token",
+				"userinfo_endpoint":      provider.URL + "/userinfo",
+			})
+		case "/token":
+			tokenEndpoint(t, w, r)
+		case "/userinfo":
+			if r.Header.Get("Authorization") != "Bearer tok" {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(`{"sub": "123", "github_login": "bob"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(provider.Close)
+	gh := TestGitHubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/orgs/org/teams/admins/memberships/bob" {
+			_, _ = w.Write([]byte(`{"state": "active"}`))
+			return
+		}
+		http.NotFound(w, r)
+	}))
+
+	cfg := config.AdminLoginConfig{
+		Provider:    config.LoginProviderOIDC,
+		ClientID:    "client",
+		Issuer:      provider.URL + "/",
+		LoginClaim:  "github_login",
+		RedirectURL: "http://localhost:9090/admin/login/callback",
+		Teams:       map[string]string{"org/viewers": config.AdminRoleViewer, "org/admins": config.AdminRoleAdmin},
+		SessionTTL:  time.Hour,
+	}
+	if _, err := newAdminLogin(cfg, "secret", "", provider.Client(), nil); err == nil {
+		t.Error("newAdminLogin succeeded without a GitHub client to check teams with")
+	}
+	l, err := newAdminLogin(cfg, "secret", "", provider.Client(), gh)
+	if err != nil {
+		t.Fatalf("newAdminLogin failed: %v", err)
+	}
+	session, _ := signIn(t, l, "/admin/events", http.StatusFound)
+	if session == nil || session.Secure {
+		t.Fatalf("session cookie = %+v", session)
+	}
+	req := httptest.NewRequest(http.MethodGet, "/admin/ping", nil)
+	req.AddCookie(session)
+	if id, ok := l.session(req); !ok || id.Login != "bob" || id.Role != config.AdminRoleAdmin {
+		t.Errorf("session = %+v, %v; want bob as admin", id, ok)
+	}
+
+	// Without a trusted claim, logins come from the subjects configured, and no one else's.
+	cfg.LoginClaim, cfg.Logins = "", map[string]string{"123": "bob"}
+	if l, err = newAdminLogin(cfg, "secret", "", provider.Client(), gh); err != nil {
+		t.Fatalf("newAdminLogin failed: %v", err)
+	}
+	if session, _ := signIn(t, l, "/admin/events", http.StatusFound); session == nil {
+		t.Error("mapped subject was not signed in")
+	}
+	cfg.Logins = map[string]string{"456": "bob"}
+	if l, err = newAdminLogin(cfg, "secret", "", provider.Client(), gh); err != nil {
+		t.Fatalf("newAdminLogin failed: %v", err)
+	}
+	if session, _ := signIn(t, l, "/admin/events", http.StatusForbidden); session != nil {
+		t.Errorf("unmapped subject got session %+v", session)
+	}
+}
+
+func TestLoginReturn(t *testing.T) {
+	for path, want := range map[string]string{
+		"/admin/events?type=push": "/admin/events?type=push",
+		"":                        defaultLoginReturn,
+		"//evil.example.com":      defaultLoginReturn,
+		"/\\evil.example.com":     defaultLoginReturn,
+		"https://evil.example":    defaultLoginReturn,
+		"admin/events":            defaultLoginReturn,
+	} {
+		if got := loginReturn(path); got != want {
+			t.Errorf("loginReturn(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
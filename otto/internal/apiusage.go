@@ -0,0 +1,309 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// apiusage.go records which GitHub API endpoints Otto calls, per day and module, and maps
+// them to the GitHub App permissions they need. The permission report built from the
+// record shows the smallest set of permissions the installation has to grant, so that
+// operators can shrink the permissions they granted without breaking modules.
+
+package internal
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// APIUsagePruneJobName is the scheduler name of the job that deletes expired API usage.
+const APIUsagePruneJobName = "github_api_usage_prune"
+
+// APIUsage reads and writes the github_api_usage table, which counts calls per day,
+// module, method and route.
+type APIUsage struct {
+	db  *sql.DB
+	now func() time.Time
+}
+
+// NewAPIUsage creates the record of GitHub API calls, creating its table if needed.
+func NewAPIUsage(db *sql.DB) (*APIUsage, error) {
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS github_api_usage (
+			day TEXT NOT NULL,
+			module TEXT NOT NULL DEFAULT '',
+			method TEXT NOT NULL,
+			route TEXT NOT NULL,
+			calls INTEGER NOT NULL DEFAULT 0,
+			PRIMARY KEY (day, module, method, route)
+		);`,
+	}
+	for _, s := range stmts {
+		if _, err := db.Exec(s); err != nil {
+			return nil, fmt.Errorf("failed migration: %w (SQL: %s)", err, s)
+		}
+	}
+	return &APIUsage{db: db, now: time.Now}, nil
+}
+
+// Record counts a call, logging rather than returning errors so recording never fails the
+// call.
+func (u *APIUsage) Record(ctx context.Context, module, method, route string) {
+	_, err := u.db.ExecContext(context.WithoutCancel(ctx),
+		`INSERT INTO github_api_usage (day, module, method, route, calls) VALUES (?, ?, ?, ?, 1)
+		ON CONFLICT (day, module, method, route) DO UPDATE SET calls = calls + 1`,
+		u.now().UTC().Format(time.DateOnly), module, method, route)
+	if err != nil {
+		slog.Error("Failed to record GitHub API call", "module", module, "route", route, "error", err)
+	}
+}
+
+// Transport wraps base so that the calls made through it are recorded. A nil record
+// returns base unchanged.
+func (u *APIUsage) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if u == nil {
+		return base
+	}
+	return &apiUsageTransport{usage: u, base: base}
+}
+
+type apiUsageTransport struct {
+	usage *APIUsage
+	base  http.RoundTripper
+}
+
+func (t *apiUsageTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err == nil {
+		t.usage.Record(req.Context(), ModuleFromContext(req.Context()), req.Method, APIRoute(req.URL.Path))
+	}
+	return resp, err
+}
+
+// EndpointUsage is how often an endpoint was called, and the permission it needs.
+type EndpointUsage struct {
+	Method     string   `json:"method"`
+	Route      string   `json:"route"`
+	Calls      int64    `json:"calls"`
+	Modules    []string `json:"modules,omitempty"`    // empty for calls outside modules
+	Permission string   `json:"permission,omitempty"` // "name:level"; empty if none is needed
+}
+
+// PermissionReport is the smallest set of GitHub App permissions the calls made since a
+// time needed.
+type PermissionReport struct {
+	Since       time.Time         `json:"since"`
+	Permissions map[string]string `json:"permissions"` // name -> highest level needed
+	Endpoints   []EndpointUsage   `json:"endpoints"`   // by route and method
+	Unmapped    []EndpointUsage   `json:"unmapped"`    // whose permission is unknown, e.g. GraphQL
+	Declared    map[string]string `json:"declared"`    // what modules declare they need, to compare
+}
+
+// Report builds the permission report of the calls recorded since the day of since.
+func (u *APIUsage) Report(ctx context.Context, since time.Time) (*PermissionReport, error) {
+	rows, err := u.db.QueryContext(ctx, `SELECT method, route, module, SUM(calls) FROM github_api_usage
+		WHERE day >= ? GROUP BY method, route, module ORDER BY route, method, module`,
+		since.UTC().Format(time.DateOnly))
+	if err != nil {
+		return nil, LogAndWrapError(err, ErrorTypeDatabase, "report_api_usage", nil)
+	}
+	defer rows.Close()
+
+	report := &PermissionReport{Since: since, Permissions: make(map[string]string)}
+	var endpoints []EndpointUsage
+	for rows.Next() {
+		var e EndpointUsage
+		var module string
+		if err := rows.Scan(&e.Method, &e.Route, &module, &e.Calls); err != nil {
+			return nil, err
+		}
+		if n := len(endpoints); n > 0 && endpoints[n-1].Method == e.Method && endpoints[n-1].Route == e.Route {
+			endpoints[n-1].Calls += e.Calls
+			e = endpoints[n-1]
+			endpoints = endpoints[:n-1]
+		}
+		if module != "" {
+			e.Modules = append(e.Modules, module)
+		}
+		endpoints = append(endpoints, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, e := range endpoints {
+		name, level, known := EndpointPermission(e.Method, e.Route)
+		if !known {
+			report.Unmapped = append(report.Unmapped, e)
+			continue
+		}
+		if name != "" {
+			e.Permission = name + ":" + level
+			if permissionLevels[level] > permissionLevels[report.Permissions[name]] {
+				report.Permissions[name] = level
+			}
+		}
+		report.Endpoints = append(report.Endpoints, e)
+	}
+	return report, nil
+}
+
+// PermissionNames returns the names of the permissions needed or declared, sorted.
+func (r *PermissionReport) PermissionNames() []string {
+	names := slices.Collect(maps.Keys(r.Permissions))
+	for name := range r.Declared {
+		if _, ok := r.Permissions[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Prune deletes the days before the day of before and returns how many rows it deleted.
+func (u *APIUsage) Prune(ctx context.Context, before time.Time) (int64, error) {
+	res, err := u.db.ExecContext(ctx, `DELETE FROM github_api_usage WHERE day < ?`,
+		before.UTC().Format(time.DateOnly))
+	if err != nil {
+		return 0, LogAndWrapError(err, ErrorTypeDatabase, "prune_api_usage", nil)
+	}
+	return res.RowsAffected()
+}
+
+// Job returns the scheduler job that deletes usage older than retention, once a day.
+func (u *APIUsage) Job(retention time.Duration) Job {
+	return Job{
+		Name:       APIUsagePruneJobName,
+		Interval:   24 * time.Hour,
+		Deferrable: true,
+		Run: func(ctx context.Context) error {
+			n, err := u.Prune(ctx, u.now().Add(-retention))
+			if n > 0 {
+				slog.Info("Pruned GitHub API usage", "rows", n)
+			}
+			return err
+		},
+	}
+}
+
+// namedSegments are path segments followed by a name rather than a number, such as a
+// label or a login, which APIRoute replaces with a placeholder.
+var namedSegments = map[string]string{
+	"labels": "{name}", "users": "{login}", "orgs": "{org}", "teams": "{team}", "members": "{login}",
+	"memberships": "{login}", "collaborators": "{login}", "branches": "{branch}", "commits": "{ref}",
+	"statuses": "{ref}", "tags": "{tag}", "compare": "{basehead}", "workflows": "{workflow}",
+	"environments": "{environment}", "secrets": "{name}", "variables": "{name}",
+	"security-advisories": "{ghsa}", "advisories": "{ghsa}",
+}
+
+// restSegments are path segments followed by a path of any depth, such as a file.
+var restSegments = map[string]string{"contents": "{path}", "refs": "{ref}", "ref": "{ref}", "trees": "{ref}"}
+
+// APIRoute returns the route of a GitHub API path, with the repository, numbers and names
+// replaced by placeholders, e.g. "/repos/{owner}/{repo}/issues/{n}/labels/{name}".
+func APIRoute(p string) string {
+	_, route := shadowRoute(strings.TrimPrefix(p, "/api/v3"))
+	segments := strings.Split(route, "/")
+	for i := 0; i+1 < len(segments); i++ {
+		s, next := segments[i], segments[i+1]
+		if next == "" || strings.HasPrefix(next, "{") {
+			continue
+		}
+		if placeholder, ok := restSegments[s]; ok {
+			segments = append(segments[:i+1], placeholder)
+			break
+		}
+		if placeholder, ok := namedSegments[s]; ok {
+			segments[i+1] = placeholder
+			i++
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// repoPermissions maps the resources of a repository, the path segment after
+// /repos/{owner}/{repo}, to the permission that covers them.
+var repoPermissions = map[string]string{
+	"issues": "issues", "labels": "issues", "milestones": "issues", "assignees": "issues", "pulls": "pull_requests",
+	"contents": "contents", "commits": "contents", "branches": "contents", "git": "contents",
+	"compare": "contents", "readme": "contents", "releases": "contents", "tarball": "contents",
+	"zipball": "contents", "merges": "contents", "tags": "contents", "dispatches": "contents",
+	"dependency-graph": "contents", "statuses": "statuses", "check-runs": "checks", "check-suites": "checks",
+	"actions": "actions", "hooks": "repository_hooks", "deployments": "deployments",
+	"environments": "environments", "pages": "pages", "projects": "repository_projects",
+	"discussions": "discussions", "security-advisories": "repository_advisories",
+	"dependabot": "vulnerability_alerts", "vulnerability-alerts": "vulnerability_alerts",
+	"code-scanning": "security_events", "secret-scanning": "secret_scanning_alerts",
+	"collaborators": "metadata", "topics": "metadata", "languages": "metadata",
+	"contributors": "metadata", "stargazers": "metadata", "teams": "metadata", "events": "metadata",
+}
+
+// publicRoutes are the first segments of routes that need no installation permission.
+var publicRoutes = []string{
+	"app", "installation", "users", "user", "rate_limit", "meta", "markdown", "emojis", "licenses",
+	"advisories", "zen", "octocat",
+}
+
+// EndpointPermission returns the GitHub App permission and level a call needs: "read" for
+// reads and "write" for others. name is "" for calls that need none. known is false if
+// the permission cannot be told from the route, as for GraphQL.
+func EndpointPermission(method, route string) (name, level string, known bool) {
+	level = "write"
+	if method == http.MethodGet || method == http.MethodHead {
+		level = "read"
+	}
+	segments := strings.Split(strings.Trim(route, "/"), "/")
+	switch {
+	case len(segments) >= 3 && segments[0] == "repos":
+		if len(segments) == 3 {
+			if level == "read" {
+				return "metadata", level, true
+			}
+			return "administration", level, true
+		}
+		resource := segments[3]
+		switch {
+		case resource == "installation":
+			return "", "", true
+		case resource == "commits" && len(segments) >= 6:
+			switch segments[5] {
+			case "check-runs", "check-suites":
+				return "checks", level, true
+			case "status", "statuses":
+				return "statuses", level, true
+			case "pulls":
+				return "pull_requests", level, true
+			}
+		case resource == "branches" && slices.Contains(segments, "protection"):
+			return "administration", level, true
+		case repoPermissions[resource] == "metadata" && level == "write":
+			return "administration", level, true
+		}
+		if name, ok := repoPermissions[resource]; ok {
+			return name, level, true
+		}
+		return "", "", false
+	case len(segments) >= 3 && segments[0] == "orgs":
+		switch segments[2] {
+		case "members", "memberships", "teams", "invitations":
+			return "members", level, true
+		case "repos":
+			if level == "read" {
+				return "metadata", level, true
+			}
+			return "administration", level, true
+		}
+		return "", "", false
+	case segments[0] == "orgs" || segments[0] == "search":
+		return "metadata", "read", true
+	case slices.Contains(publicRoutes, segments[0]):
+		return "", "", true
+	}
+	return "", "", false
+}
@@ -0,0 +1,135 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"maps"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v71/github"
+)
+
+func TestAPIRoute(t *testing.T) {
+	tests := []struct {
+		path, want string
+	}{
+		{"/repos/org/repo/issues/7/comments", "/repos/{owner}/{repo}/issues/{n}/comments"},
+		{"/api/v3/repos/org/repo/issues/7/labels/good%20first%20issue",
+			"/repos/{owner}/{repo}/issues/{n}/labels/{name}"},
+		{"/repos/org/repo/contents/docs/README.md", "/repos/{owner}/{repo}/contents/{path}"},
+		{"/repos/org/repo/commits/abc123/check-runs", "/repos/{owner}/{repo}/commits/{ref}/check-runs"},
+		{"/repos/org/repo/git/refs/heads/main", "/repos/{owner}/{repo}/git/refs/{ref}"},
+		{"/orgs/open-telemetry/teams/maintainers/members", "/orgs/{org}/teams/{team}/members"},
+		{"/users/octocat", "/users/{login}"},
+		{"/graphql", "/graphql"},
+	}
+	for _, tt := range tests {
+		if got := APIRoute(tt.path); got != tt.want {
+			t.Errorf("APIRoute(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestEndpointPermission(t *testing.T) {
+	tests := []struct {
+		method, route string
+		want          string // "name:level", "" for none, "?" for unknown
+	}{
+		{"GET", "/repos/{owner}/{repo}", "metadata:read"},
+		{"PATCH", "/repos/{owner}/{repo}", "administration:write"},
+		{"POST", "/repos/{owner}/{repo}/issues/{n}/comments", "issues:write"},
+		{"GET", "/repos/{owner}/{repo}/pulls/{n}/files", "pull_requests:read"},
+		{"POST", "/repos/{owner}/{repo}/check-runs", "checks:write"},
+		{"GET", "/repos/{owner}/{repo}/commits/{ref}/status", "statuses:read"},
+		{"GET", "/repos/{owner}/{repo}/commits/{ref}", "contents:read"},
+		{"PUT", "/repos/{owner}/{repo}/branches/{branch}/protection", "administration:write"},
+		{"GET", "/repos/{owner}/{repo}/collaborators/{login}/permission", "metadata:read"},
+		{"PUT", "/repos/{owner}/{repo}/collaborators/{login}", "administration:write"},
+		{"GET", "/orgs/{org}/teams/{team}/members", "members:read"},
+		{"GET", "/search/issues", "metadata:read"},
+		{"GET", "/repos/{owner}/{repo}/installation", ""},
+		{"GET", "/users/{login}", ""},
+		{"POST", "/graphql", "?"},
+		{"GET", "/repos/{owner}/{repo}/interaction-limits", "?"},
+	}
+	for _, tt := range tests {
+		name, level, known := EndpointPermission(tt.method, tt.route)
+		got := "?"
+		switch {
+		case known && name != "":
+			got = name + ":" + level
+		case known:
+			got = ""
+		}
+		if got != tt.want {
+			t.Errorf("EndpointPermission(%s %s) = %q, want %q", tt.method, tt.route, got, tt.want)
+		}
+	}
+}
+
+func TestAPIUsageReport(t *testing.T) {
+	usage, err := NewAPIUsage(TestDB(t))
+	if err != nil {
+		t.Fatalf("NewAPIUsage failed: %v", err)
+	}
+	server := TestGitHubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	client := github.NewClient(&http.Client{Transport: usage.Transport(nil)})
+	client.BaseURL = server.BaseURL
+
+	triage := WithModule(t.Context(), "triage")
+	sla := WithModule(t.Context(), "sla")
+	body := "Thanks!"
+	for _, n := range []int{1, 2} {
+		if _, _, err := client.Issues.Get(triage, "org", "repo", n); err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+	}
+	if _, _, err := client.Issues.Get(sla, "org", "other", 3); err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	comment := &github.IssueComment{Body: &body}
+	if _, _, err := client.Issues.CreateComment(triage, "org", "repo", 1, comment); err != nil {
+		t.Fatalf("CreateComment failed: %v", err)
+	}
+	if _, _, err := client.Repositories.Get(t.Context(), "org", "repo"); err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	usage.Record(sla, "sla", http.MethodPost, "/graphql")
+
+	report, err := usage.Report(t.Context(), time.Now().AddDate(0, 0, -30))
+	if err != nil {
+		t.Fatalf("Report failed: %v", err)
+	}
+	want := map[string]string{"issues": "write", "metadata": "read"}
+	if !maps.Equal(report.Permissions, want) {
+		t.Errorf("Permissions = %v, want %v", report.Permissions, want)
+	}
+	if len(report.Endpoints) != 3 {
+		t.Fatalf("Endpoints = %+v, want 3", report.Endpoints)
+	}
+	get := report.Endpoints[1]
+	if get.Method != "GET" || get.Route != "/repos/{owner}/{repo}/issues/{n}" || get.Calls != 3 ||
+		len(get.Modules) != 2 || get.Modules[0] != "sla" || get.Permission != "issues:read" {
+		t.Errorf("Endpoints[1] = %+v", get)
+	}
+	if len(report.Unmapped) != 1 || report.Unmapped[0].Route != "/graphql" {
+		t.Errorf("Unmapped = %+v", report.Unmapped)
+	}
+
+	report.Declared = map[string]string{"issues": "write", "checks": "write"}
+	if names := report.PermissionNames(); len(names) != 3 || names[0] != "checks" {
+		t.Errorf("PermissionNames = %v", names)
+	}
+
+	n, err := usage.Prune(t.Context(), time.Now().AddDate(0, 0, 1))
+	if err != nil || n != 5 {
+		t.Errorf("Prune = %d, %v; want 5 rows", n, err)
+	}
+	if (*APIUsage)(nil).Transport(http.DefaultTransport) != http.DefaultTransport {
+		t.Error("nil usage wrapped the transport")
+	}
+}
@@ -5,14 +5,26 @@ package internal
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
 	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
 
 	"github.com/google/go-github/v71/github"
 	"github.com/jferrl/go-githubauth"
 	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
 	"github.com/open-telemetry/sig-project-infra/otto/internal/secrets"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/oauth2"
 )
 
@@ -26,8 +38,64 @@ type App struct {
 	Addr           string
 	GitHubClient   *github.Client // GitHub API client for interacting with GitHub
 	ModuleRegistry *ModuleRegistry
+	Scheduler      *Scheduler
+	Events         *EventStore          // persisted webhook events
+	Repos          *RepoRegistry        // onboarded repositories and their enabled modules
+	Slack          *SlackClient         // nil unless a Slack bot token is configured
+	Budgets        *APIBudgets          // per-module GitHub API budgets
+	Pacer          *MutationPacer       // nil unless pacing is enabled
+	RateLimits     *RateLimits          // GitHub rate limits seen in responses
+	APIUsage       *APIUsage            // GitHub API calls per day and endpoint; nil if disabled
+	ContentFilter  *ContentFilter       // blocks posts containing secrets or banned phrases; nil if disabled
+	Errors         *ErrorReports        // nil unless the sentry_dsn secret is set
+	RepoGroups     *RepoGroups          // named repository lists modules' settings refer to
+	Confirmations  *Confirmations       // pending destructive actions awaiting /confirm
+	Limiter        *ConcurrencyLimiter  // per-module event handling limits
+	Commands       *CommandHistory      // executed slash commands
+	GitHubStatus   *GitHubStatus        // nil unless github_status polling is enabled
+	Notifications  *Notifications       // routes module notifications to channels
+	QuietHours     *QuietHours          // holds non-urgent notifications overnight; nil without quiet hours
+	Outbox         *Outbox              // GitHub actions carried out in the background, with retries
+	ActionsAPI     *ActionsAPI          // actions queued by trusted systems; nil without clients
+	Flags          *FeatureFlags        // feature flags evaluated per repository and module
+	Transport      *http.Transport      // outbound requests, with the proxy and TLS settings of the http config
+	Router         *CommandRouter       // applies command aliases and disabled commands
+	Dispatch       *DispatchPool        // worker pool handing events to modules by priority
+	Queue          *EventQueue          // webhook deliveries persisted until handled; nil if disabled
+	Watchdog       *Watchdog            // tracks running module handlers; nil if disabled
+	FileClasses    *FileClassifier      // classifies changed files as generated, vendored or docs
+	Cache          Cache                // lookups shared by modules, in memory or in Redis
+	Payloads       *PayloadChecker      // reports webhook fields go-github does not parse; nil unless strict_parse
+	Permissions    *PermissionCheck     // modules lacking GitHub App permissions; nil without app credentials
+	Rollups        *MetricRollups       // daily rollups of key metrics; nil if disabled
+	Decisions      *DecisionLog         // why modules acted or not; nil unless decisions are recorded
+	Logs           *LogBuffer           // recent log records for the admin API; nil if disabled
+	Shadow         *ShadowLog           // GitHub writes of modules in shadow mode; nil without shadowed modules
+	Bus            *EventBus            // domain events modules publish to each other
+	appClient      *github.Client       // authenticated as the GitHub App rather than the installation
+	appTokens      oauth2.TokenSource   // the GitHub App's own tokens; nil without app credentials
+	installations  *installationClients // clients of installations other than the configured one
+	shadowModule   string               // the module in shadow mode a module view of the app is for
 	server         *Server
 	shutdownSignal chan struct{}
+	lifecycle      *lifecycle // shared with the module views of the app; nil in tests
+}
+
+// lifecycle is the shutdown state of an App.
+type lifecycle struct {
+	signalOnce sync.Once
+	draining   atomic.Bool    // set once shutdown begins; new webhooks are turned away
+	inFlight   sync.WaitGroup // events submitted for dispatch and not yet handled
+}
+
+// begin records an event submitted for dispatch and returns the func to call once it has
+// been handled.
+func (l *lifecycle) begin() (done func()) {
+	if l == nil {
+		return func() {}
+	}
+	l.inFlight.Add(1)
+	return l.inFlight.Done
 }
 
 // NewApp creates and initializes a new application instance.
@@ -56,15 +124,17 @@ func NewApp(ctx context.Context, configPath, secretsPath string) (*App, error) {
 		Addr:           appConfig.Port,
 		ModuleRegistry: NewModuleRegistry(),
 		shutdownSignal: make(chan struct{}),
+		lifecycle:      &lifecycle{},
 	}
 
-	// Initialize GitHub client
-	if err := app.initializeGitHubClient(ctx); err != nil {
-		return nil, fmt.Errorf("failed to initialize GitHub client: %w", err)
+	// Build the transport for outbound requests
+	app.Transport, err = NewHTTPTransport(app.Config.HTTP)
+	if err != nil {
+		return nil, err
 	}
 
 	// Initialize telemetry
-	app.Telemetry, err = NewTelemetryManager(ctx)
+	app.Telemetry, err = NewTelemetryManager(ctx, app.Config.InstanceID, app.Config.Telemetry, app.Transport)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize telemetry: %w", err)
 	}
@@ -72,25 +142,365 @@ func NewApp(ctx context.Context, configPath, secretsPath string) (*App, error) {
 	// Get logger from telemetry
 	app.Logger = app.Telemetry.Logger
 
+	// Keep recent logs in memory so operators can tail them on the admin API
+	if *app.Config.LogStream.Enabled {
+		var level slog.Level
+		_ = level.UnmarshalText([]byte(app.Config.LogStream.Level))
+		app.Logs = NewLogBuffer(app.Config.LogStream.Buffer, level)
+		app.Logger = slog.New(app.Logs.Handler(app.Logger.Handler()))
+		slog.SetDefault(app.Logger)
+	}
+
+	// Initialize per-module GitHub API budgets
+	app.Budgets, err = NewAPIBudgets(app.Config.APIBudgets, app.Telemetry)
+	if err != nil {
+		return nil, err
+	}
+
+	// Follow GitHub's rate limits and retry requests hit by secondary limits
+	app.RateLimits, err = NewRateLimits(app.Config.RateLimit, app.Telemetry)
+	if err != nil {
+		return nil, err
+	}
+
+	// Spread out GitHub writes made by background work
+	if *app.Config.Pacing.Enabled {
+		p := app.Config.Pacing
+		app.Pacer = NewMutationPacer(p.MutationsPerMinute, p.Burst, p.Jitter)
+	}
+
+	// Keep secrets and banned phrases out of what Otto posts
+	if *app.Config.ContentFilter.Enabled {
+		app.ContentFilter, err = NewContentFilter(app.Config.ContentFilter, app.ownSecrets(), app.Telemetry)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Follow the GitHub status page so retries and background jobs back off during incidents
+	if *app.Config.GitHubStatus.Enabled {
+		app.GitHubStatus = NewGitHubStatus(app.Config.GitHubStatus.URL).WithHTTPClient(app.HTTPClient(10 * time.Second))
+	}
+
 	// Initialize database
-	app.Database, err = NewDatabase(app.Config.DBPath)
+	app.Database, err = NewInstrumentedDatabase(app.Config.DBPath, app.Telemetry)
+	if err != nil {
+		return nil, err
+	}
+	// Leave the schema alone when it is migrated out-of-band
+	if app.Config.Migrations.RefusePending {
+		if err := checkMigrations(ctx, app.Database.DB(), CoreMigrations()); err != nil {
+			return nil, err
+		}
+	}
+
+	// Record the GitHub API endpoints called, to report the permissions they need
+	if *app.Config.APIUsage.Enabled {
+		app.APIUsage, err = NewAPIUsage(app.Database.DB())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Initialize GitHub client
+	if err := app.initializeGitHubClient(ctx); err != nil {
+		return nil, fmt.Errorf("failed to initialize GitHub client: %w", err)
+	}
+
+	// Cache lookups in memory, or in Redis so replicas share them
+	app.Cache, err = NewCache(app.Config.Cache, app.Secrets.GetSecret(RedisPasswordSecret))
+	if err != nil {
+		return nil, err
+	}
+	app.FileClasses = NewFileClassifier(app.Config.FileClasses, app.GitHubClient).WithCache(app.Cache)
+
+	// Initialize event store, offloading large payloads to a bucket if configured
+	app.Events, err = NewEventStore(app.Database.DB())
+	if err != nil {
+		return nil, err
+	}
+	payloads, err := NewPayloadStore(app.Config.EventPayloads, app.Secrets.GetSecret(PayloadAccessKeySecret),
+		app.Secrets.GetSecret(PayloadSecretKeySecret), app.HTTPClient(app.Config.EventPayloads.Timeout))
+	if err != nil {
+		return nil, err
+	}
+	app.Events.WithPayloadStore(payloads, app.Config.EventPayloads.OffloadAbove)
+
+	// Initialize repository registry
+	app.Repos, err = NewRepoRegistry(app.Database.DB())
 	if err != nil {
 		return nil, err
 	}
+	app.RepoGroups, err = NewRepoGroups(app.Config.RepoGroups, app.Repos)
+	if err != nil {
+		return nil, err
+	}
+	if err := app.checkModuleRoutes(); err != nil {
+		return nil, err
+	}
+
+	// Initialize per-module concurrency limits for event handling
+	app.Limiter = NewConcurrencyLimiter(app.Config.Concurrency)
+
+	// Initialize slash command history
+	app.Commands, err = NewCommandHistory(app.Database.DB())
+	if err != nil {
+		return nil, err
+	}
+
+	// Apply command aliases and disabled commands before modules see comments
+	app.Router = NewCommandRouter(app.Config.Commands)
+
+	// Hand events to modules on a worker pool, slash commands first
+	app.Dispatch = NewDispatchPool(app.Config.Dispatch, app.Telemetry)
+	if *app.Config.Watchdog.Enabled {
+		app.Watchdog = NewWatchdog(app.Config.Watchdog, app.Telemetry)
+	}
+
+	// Report payload fields go-github drops, an early sign that it needs updating
+	if app.Config.StrictParse {
+		app.Payloads = NewPayloadChecker(app.Telemetry)
+	}
+
+	// Initialize confirmation store for destructive commands
+	app.Confirmations, err = NewConfirmations(app.Database.DB())
+	if err != nil {
+		return nil, err
+	}
+
+	// Initialize feature flags
+	app.Flags, err = NewFeatureFlags(app.Config.FeatureFlags, app.Database.DB(),
+		app.Secrets.GetSecret(FeatureFlagsTokenSecret), app.HTTPClient(app.Config.FeatureFlags.Timeout), app.Telemetry)
+	if err != nil {
+		return nil, err
+	}
+
+	// Report panics and module errors to Sentry if configured
+	if dsn := app.Secrets.GetSecret(SentryDSNSecret); dsn != "" {
+		sentry, err := NewSentryReporter(dsn, app.Config.Errors.Environment, app.Config.InstanceID,
+			app.HTTPClient(10*time.Second))
+		if err != nil {
+			return nil, err
+		}
+		app.Errors = NewErrorReports(sentry)
+	}
+
+	// Let modules react to each other's domain events
+	app.Bus = NewEventBus(app.ModuleEnabled, app.Errors)
+
+	// Initialize Slack client if configured
+	if token := app.Secrets.GetSecret(SlackBotTokenSecret); token != "" {
+		client := app.HTTPClient(10 * time.Second)
+		client.Transport = app.ContentFilter.Transport(client.Transport)
+		app.Slack = NewSlackClient(token).WithHTTPClient(client)
+	}
+
+	// Initialize notification routing and the available backends
+	app.Notifications, err = NewNotifications(app.Config.Notifications, app.Telemetry)
+	if err != nil {
+		return nil, err
+	}
+	app.Notifications.Register(&WebhookNotifier{Client: app.HTTPClient(10 * time.Second)})
+	app.Notifications.Register(&GitHubNotifier{Client: app.GitHubClient})
+	if app.Slack != nil {
+		app.Notifications.Register(&SlackNotifier{Client: app.Slack})
+	}
+	if smtpConfig := app.Config.Notifications.SMTP; smtpConfig.Addr != "" {
+		app.Notifications.Register(NewEmailNotifier(smtpConfig, app.Secrets.GetSecret(SMTPPasswordSecret)))
+	}
+	if qh := app.Config.Notifications.QuietHours; qh.Start != "" || qh.End != "" || len(qh.Repos) > 0 {
+		app.QuietHours, err = NewQuietHours(qh, app.Database.DB())
+		if err != nil {
+			return nil, err
+		}
+		app.Notifications.HoldDuringQuietHours(app.QuietHours)
+	}
+	app.Notifications.FilterContent(app.ContentFilter)
+	app.ContentFilter.AlertTo(app.Notifications)
+	slog.Info("notifications configured",
+		"backends", app.Notifications.Backends(),
+		"routes", len(app.Config.Notifications.Routes),
+		"quiet_hours", app.QuietHours != nil)
+
+	// Initialize background job scheduler
+	app.Scheduler = NewScheduler(app.Telemetry)
+	app.Scheduler.ReportErrors(app.Errors)
+	if app.GitHubStatus != nil {
+		app.Scheduler.PauseDeferrable(app.GitHubStatus.Degraded)
+		app.Scheduler.Register(Job{
+			Name:     "github_status",
+			Interval: app.Config.GitHubStatus.Interval,
+			Run:      app.GitHubStatus.Poll,
+		})
+	}
+	if app.APIUsage != nil {
+		app.Scheduler.Register(app.APIUsage.Job(app.Config.APIUsage.Retention))
+	}
+	app.Scheduler.Register(Job{
+		Name:     "expire_confirmations",
+		Interval: time.Minute,
+		Run:      app.Confirmations.Expire,
+	})
+	if app.QuietHours != nil {
+		app.Scheduler.Register(app.QuietHours.Job(app.Notifications, app.Config.Notifications.QuietHours.Interval))
+	}
+	if *app.Config.SelfUpdate.Enabled {
+		checker := NewUpdateChecker(app.GitHubClient, app.Notifications, app.Config.SelfUpdate, BuildVersion())
+		app.Scheduler.Register(Job{
+			Name:       SelfUpdateJobName,
+			Interval:   app.Config.SelfUpdate.Interval,
+			Run:        checker.Check,
+			Deferrable: true,
+		})
+	}
+	if *app.Config.DBMaintenance.Enabled {
+		app.Scheduler.Register(NewDBMaintenanceJob(app.Database, app.Telemetry, DBMaintenanceOptions{
+			Interval: app.Config.DBMaintenance.Interval,
+			Vacuum:   *app.Config.DBMaintenance.Vacuum,
+			Analyze:  *app.Config.DBMaintenance.Analyze,
+		}))
+	}
+
+	// Probe the webhook pipeline end to end with synthetic events
+	if *app.Config.Probe.Enabled {
+		secret := app.Secrets.GetWebhookSecret()
+		for _, endpoint := range app.Config.Webhooks {
+			if endpoint.Path == app.Config.Probe.Path && endpoint.Secret != "" {
+				secret = app.Secrets.GetSecret(endpoint.Secret)
+			}
+		}
+		prober := NewProber("http://localhost:"+app.Config.Port+app.Config.Probe.Path, []byte(secret),
+			app.Config.Probe.SLO, app.Telemetry, app.Notifications)
+		app.RegisterModule(prober)
+		app.Scheduler.Register(Job{Name: ProbeJobName, Interval: app.Config.Probe.Interval, Run: prober.Probe})
+	}
+
+	// Check that the installation grants the permissions modules need
+	if *app.Config.Permissions.Enabled && app.appClient != nil {
+		fetch := InstallationPermissions(app.appClient, app.Secrets.GetGitHubInstallationID())
+		app.Permissions, err = NewPermissionCheck(fetch, app.ModuleRegistry, app.Telemetry)
+		if err != nil {
+			return nil, err
+		}
+		app.Scheduler.Register(Job{
+			Name:       PermissionCheckJobName,
+			Interval:   app.Config.Permissions.Interval,
+			Run:        app.Permissions.Check,
+			Deferrable: true,
+		})
+	}
+
+	// Roll up key metrics per day for trends beyond the metrics backend's retention
+	if *app.Config.Rollups.Enabled {
+		app.Rollups, err = NewMetricRollups(app.Database.DB(), app.ModuleRegistry, app.Config.Rollups.Backfill)
+		if err != nil {
+			return nil, err
+		}
+		app.Scheduler.Register(app.Rollups.Job(app.Config.Rollups.Interval))
+	}
+
+	// Persist webhook deliveries until handled, retrying the modules that fail
+	if *app.Config.EventQueue.Enabled {
+		app.Queue, err = NewEventQueue(app.Database.DB(), app.Config.EventQueue)
+		if err != nil {
+			return nil, err
+		}
+		app.Scheduler.Register(app.Queue.Job(app.redispatch))
+	}
+
+	// Queue GitHub actions from modules and trusted systems
+	app.Outbox, err = NewOutbox(app.Database.DB(), app.GitHubClient, app.Config.Outbox.MaxAttempts)
+	if err != nil {
+		return nil, err
+	}
+	app.Scheduler.Register(app.Outbox.Job(app.Config.Outbox.Interval))
+	app.ActionsAPI, err = NewActionsAPI(app.Config.ActionsAPI, app.Secrets, app.Outbox)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep module decisions beyond the traces they are span events in
+	if *app.Config.Decisions.Record {
+		app.Decisions, err = NewDecisionLog(app.Database.DB())
+		if err != nil {
+			return nil, err
+		}
+		app.Scheduler.Register(app.Decisions.Job(app.Config.Decisions.Retention))
+	}
+
+	// Record the GitHub writes of modules in shadow mode rather than making them
+	if len(app.Config.Shadow.Modules) > 0 {
+		app.Shadow, err = NewShadowLog(app.Database.DB(), app.Config.Shadow.Modules)
+		if err != nil {
+			return nil, err
+		}
+		app.Scheduler.Register(app.Shadow.Job(app.Config.Shadow.Retention))
+	}
 
 	// Create HTTP server with app reference
 	app.server = NewServerWithApp(app.Addr, app.Secrets, app)
+	app.Flags.RegisterAdminRoutes(app.server)
+	app.Watchdog.RegisterAdminRoutes(app.server)
+	app.ModuleRegistry.RegisterAdminRoutes(app.server)
+	app.Events.RegisterAdminRoutes(app.server)
+	app.Rollups.RegisterAdminRoutes(app.server)
+	app.Decisions.RegisterAdminRoutes(app.server)
+	app.Logs.RegisterAdminRoutes(app.server)
+	app.Shadow.RegisterAdminRoutes(app.server)
+	app.Outbox.RegisterAdminRoutes(app.server)
+	app.Queue.RegisterAdminRoutes(app.server)
+	app.Bus.RegisterAdminRoutes(app.server)
+	if app.Events != nil {
+		app.server.HandleAdmin("POST /admin/replay", app.handleReplay)
+	}
+	app.ActionsAPI.RegisterRoutes(app.server)
 
 	return app, nil
 }
 
 // Start begins all application services.
 func (a *App) Start(ctx context.Context) error {
+	// Modules migrate their tables as they are initialized
+	if a.Config != nil && a.Config.Migrations.RefusePending {
+		var modules []Module
+		for _, m := range a.ModuleRegistry.GetModules() {
+			modules = append(modules, m)
+		}
+		if err := checkMigrations(ctx, a.Database.DB(), ModuleMigrations(modules)); err != nil {
+			return err
+		}
+	}
+
 	// Initialize and start all modules
 	if err := a.initializeModules(ctx); err != nil {
 		return err
 	}
 
+	// Report modules the installation lacks permissions for before they fail at runtime
+	if a.Permissions != nil {
+		if err := a.Permissions.Check(ctx); err != nil {
+			a.Logger.Warn("Failed to check GitHub App permissions", "err", err)
+		}
+	}
+
+	// Start scheduled jobs, including any registered by modules
+	a.Scheduler.Start(ctx)
+
+	// Start the dispatch workers before webhooks arrive, with the deliveries that were in
+	// flight when Otto last stopped
+	if a.Queue != nil {
+		if n, err := a.Queue.Recover(ctx); err != nil {
+			a.Logger.Error("Failed to recover queued events", "err", err)
+		} else if n > 0 {
+			a.Logger.Info("Recovered events in flight at the last shutdown", "count", n)
+			if err := a.Queue.Process(ctx, a.redispatch); err != nil {
+				a.Logger.Error("Failed to dispatch recovered events", "err", err)
+			}
+		}
+	}
+	a.Dispatch.Start()
+	a.Watchdog.Start(ctx)
+
 	// Start HTTP server (non-blocking)
 	go func() {
 		if err := a.server.Start(); err != nil {
@@ -102,18 +512,41 @@ func (a *App) Start(ctx context.Context) error {
 	return nil
 }
 
-// Shutdown gracefully stops all application services.
+// Shutdown gracefully stops all application services. Webhooks are turned away from the
+// start, and the events already received are handled for up to dispatch.drain_timeout
+// before modules, telemetry and the database are shut down.
 func (a *App) Shutdown(ctx context.Context) error {
+	if a.lifecycle != nil {
+		a.lifecycle.draining.Store(true)
+	}
+
+	// End log streams, which would otherwise hold up the server shutdown
+	a.Logs.Close()
+
 	// Shutdown server
 	if err := a.server.Shutdown(ctx); err != nil {
 		a.Logger.Error("Error during server shutdown", "err", err)
 	}
 
+	// Stop scheduled jobs
+	if a.Scheduler != nil {
+		a.Scheduler.Stop()
+	}
+
+	// Handle the events that are already queued or running
+	if err := a.drain(ctx); err != nil {
+		a.Logger.Error("Error draining dispatch queues", "err", err)
+	}
+	a.Watchdog.Stop()
+
 	// Shutdown modules
 	if err := a.shutdownModules(ctx); err != nil {
 		a.Logger.Error("Error during module shutdown", "err", err)
 	}
 
+	// Send the error reports still in flight
+	a.Errors.Close()
+
 	// Shutdown telemetry
 	if a.Telemetry != nil {
 		if err := a.Telemetry.Shutdown(ctx); err != nil {
@@ -121,6 +554,13 @@ func (a *App) Shutdown(ctx context.Context) error {
 		}
 	}
 
+	// Close cache connections
+	if c, ok := a.Cache.(io.Closer); ok {
+		if err := c.Close(); err != nil {
+			a.Logger.Error("Error closing cache", "err", err)
+		}
+	}
+
 	// Close database
 	if a.Database != nil {
 		if err := a.Database.Close(); err != nil {
@@ -131,21 +571,88 @@ func (a *App) Shutdown(ctx context.Context) error {
 	return nil
 }
 
+// drain waits until the events submitted for dispatch have been handled, or until
+// dispatch.drain_timeout has passed or ctx is done.
+func (a *App) drain(ctx context.Context) error {
+	if a.Config != nil && a.Config.Dispatch.DrainTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, a.Config.Dispatch.DrainTimeout)
+		defer cancel()
+	}
+	if a.Dispatch != nil {
+		if err := a.Dispatch.Stop(ctx); err != nil {
+			return err
+		}
+	}
+	if a.lifecycle == nil {
+		return nil
+	}
+	done := make(chan struct{})
+	go func() {
+		a.lifecycle.inFlight.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Draining reports whether the application is shutting down and no longer takes webhooks.
+func (a *App) Draining() bool {
+	return a.lifecycle != nil && a.lifecycle.draining.Load()
+}
+
 // WaitForShutdown blocks until the application is signaled to shut down.
 func (a *App) WaitForShutdown() {
 	<-a.shutdownSignal
 }
 
-// SignalShutdown triggers the application to begin shutting down.
+// SignalShutdown triggers the application to begin shutting down. Calling it again has
+// no effect.
 func (a *App) SignalShutdown() {
-	close(a.shutdownSignal)
+	if a.lifecycle == nil {
+		close(a.shutdownSignal)
+		return
+	}
+	a.lifecycle.signalOnce.Do(func() { close(a.shutdownSignal) })
 }
 
-// RegisterModule registers a module with this app instance.
+// HandleSignals signals shutdown on the first SIGINT or SIGTERM. Later signals are left
+// to their default behavior, so a second Ctrl+C stops a shutdown that hangs.
+func (a *App) HandleSignals() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		sig := <-signals
+		signal.Stop(signals)
+		a.Logger.Info("shutdown signal received", "signal", sig.String())
+		a.SignalShutdown()
+	}()
+}
+
+// RegisterModule registers a module with this app instance, unless its config section
+// disables it.
 func (a *App) RegisterModule(m Module) {
+	if a.ModuleDisabled(m.Name()) {
+		slog.Info("module disabled in config", "name", m.Name())
+		return
+	}
 	a.ModuleRegistry.RegisterModule(m)
 }
 
+// HandleAdmin registers an admin API handler protected by the admin token.
+// Modules typically call this from Initialize.
+func (a *App) HandleAdmin(pattern string, handler http.HandlerFunc) {
+	if a.server == nil {
+		slog.Warn("no server available, admin handler not registered", "pattern", pattern)
+		return
+	}
+	a.server.HandleAdmin(pattern, handler)
+}
+
 // GetModules returns all registered modules for this app instance.
 func (a *App) GetModules() map[string]Module {
 	return a.ModuleRegistry.GetModules()
@@ -155,22 +662,60 @@ func (a *App) GetModules() map[string]Module {
 func (a *App) initializeModules(ctx context.Context) error {
 	// Get all registered modules
 	modules := a.ModuleRegistry.GetModules()
+	for name := range a.Config.Shadow.Modules {
+		if modules[name] == nil {
+			a.Logger.Warn("Shadow mode configured for an unknown module", "module", name)
+		}
+	}
 
 	for name, mod := range modules {
 		if initializer, ok := mod.(ModuleInitializer); ok {
-			if err := initializer.Initialize(ctx, a); err != nil {
+			if err := initializer.Initialize(ctx, a.moduleView(name)); err != nil {
 				a.Logger.Error("Failed to initialize module", "name", name, "err", err)
 				return err
 			}
 		}
 	}
+	// Once all are initialized, so that every subscriber sees every module
+	for name := range modules {
+		a.Bus.Publish(ctx, DomainEvent{Name: EventModuleInitialized, Module: name})
+	}
 	return nil
 }
 
-// shutdownModules gracefully shuts down all modules.
+// moduleView returns the app a module is initialized with: a copy whose database is the
+// module's view of it, so its statements are attributed to it. A module in shadow mode
+// also gets GitHub clients, those of InstallationClient included, that record its writes.
+func (a *App) moduleView(module string) *App {
+	database := a.Database
+	if database != nil {
+		database = a.Database.ForModule(module)
+	}
+	shadowed := a.Shadow.Shadowed(module)
+	if !shadowed && database == a.Database {
+		return a
+	}
+	view := *a
+	view.Database = database
+	if shadowed {
+		view.shadowModule = module
+		if a.GitHubClient != nil {
+			view.GitHubClient = a.Shadow.Client(a.GitHubClient, module)
+		}
+		a.Logger.Info("Module runs in shadow mode", "module", module, "repos", a.Config.Shadow.Modules[module])
+	}
+	return &view
+}
+
+// shutdownModules gracefully shuts down all modules, once the handlers of their stopping
+// events and of earlier domain events have returned.
 func (a *App) shutdownModules(ctx context.Context) error {
 	// Get all registered modules
 	modules := a.ModuleRegistry.GetModules()
+	for name := range modules {
+		a.Bus.Publish(ctx, DomainEvent{Name: EventModuleStopping, Module: name})
+	}
+	a.Bus.Wait()
 
 	var wg sync.WaitGroup
 	errors := make(chan error, len(modules))
@@ -199,20 +744,283 @@ func (a *App) shutdownModules(ctx context.Context) error {
 	return nil
 }
 
-// Command handling has been removed since commands are processed through events
-
-// DispatchEvent hands an event to all modules.
+// DispatchEvent queues an event on the dispatch pool by its priority, after applying
+// command aliases and disabled commands to comments (raw is left unchanged).
 func (a *App) DispatchEvent(eventType string, event any, raw []byte) {
-	// Get all registered modules
+	a.DispatchDelivery("", 0, eventType, event, raw)
+}
+
+// DispatchDelivery is DispatchEvent for a webhook delivery, whose ID labels the handlers
+// in the watchdog and in profiles. With the event queue, the delivery is persisted first and
+// retried for the modules that fail to handle it; stored is its ID in the event store, if it
+// was recorded there, which the queue refers to instead of keeping the payload again.
+func (a *App) DispatchDelivery(delivery string, stored int64, eventType string, event any, raw []byte) {
+	var id int64
+	if a.Queue != nil {
+		var err error
+		if id, err = a.Queue.Enqueue(context.Background(), delivery, eventType, stored, raw); err != nil {
+			a.Logger.Error("Failed to queue event, dispatching it without retries", "event", eventType,
+				"delivery", delivery, "err", err)
+		}
+	}
+	a.submitEvent(id, delivery, eventType, a.Router.Route(event), raw, nil)
+}
+
+// submitEvent queues an event on the dispatch pool for modules, or all modules if nil,
+// and records the outcome in the event queue if id is set.
+func (a *App) submitEvent(id int64, delivery, eventType string, event any, raw []byte, modules []string) {
+	done := a.lifecycle.begin()
+	a.Dispatch.Submit(a.Dispatch.Priority(eventType, event), func() {
+		defer done()
+		failed := a.handleEvent(delivery, eventType, event, raw, modules)
+		if id == 0 {
+			return
+		}
+		if err := a.Queue.Complete(context.Background(), id, failed); err != nil {
+			a.Logger.Error("Failed to record event outcome", "id", id, "event", eventType, "err", err)
+		}
+	})
+}
+
+// redispatch hands a delivery from the event queue to the modules still to handle it,
+// reading its payload from the event store if the queue refers to it there.
+func (a *App) redispatch(e QueuedEvent) {
+	var (
+		event any
+		err   error
+	)
+	if e.Payload == nil && e.EventID != 0 {
+		e.Payload, err = a.storedPayload(context.Background(), e.EventID)
+	}
+	if err == nil {
+		event, err = ParseWebHook(e.Type, e.Payload)
+	}
+	if err != nil {
+		a.Logger.Error("Failed to parse queued event", "id", e.ID, "event", e.Type, "err", err)
+		if err := a.Queue.GiveUp(context.Background(), e.ID, err); err != nil {
+			a.Logger.Error("Failed to give up on queued event", "id", e.ID, "err", err)
+		}
+		return
+	}
+	a.submitEvent(e.ID, e.DeliveryID, e.Type, a.Router.Route(event), e.Payload, e.Modules)
+}
+
+// storedPayload returns the payload of an event in the event store.
+func (a *App) storedPayload(ctx context.Context, id int64) ([]byte, error) {
+	if a.Events == nil {
+		return nil, fmt.Errorf("stored event %d: the event store is not available", id)
+	}
+	stored, err := a.Events.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("stored event %d: %w", id, err)
+	}
+	return stored.Payload, nil
+}
+
+// handleEvent hands an event to the modules enabled for the event's repository that consume
+// it, and waits until they are done; only, if not nil, limits it to those modules, for a
+// retry or a replay. Repository events first update the repository registry. Each module
+// handles it in its own goroutine, once the module's concurrency limit allows, as do the
+// slash commands of a module that is a ModuleCommandHandler. Slash commands are not
+// idempotent, so they only run on the first dispatch of an event, each recorded in the
+// command history with its outcome, and are not retried. It returns the modules whose event handlers
+// failed with their errors.
+func (a *App) handleEvent(delivery, eventType string, event any, raw []byte, only []string) map[string]error {
 	modules := a.ModuleRegistry.GetModules()
+	if only != nil {
+		retried := make(map[string]Module, len(only))
+		for _, name := range only {
+			if m, ok := modules[name]; ok {
+				retried[name] = m
+			}
+		}
+		modules = retried
+	} else if e, ok := event.(*github.RepositoryEvent); ok {
+		a.handleRepositoryEvent(context.Background(), e)
+	}
+	repo := eventRepo(raw)
+	normalized := NormalizeGitHubEvent(event)
 
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		failed = map[string]error{}
+	)
+	commandFail := func(module string, err error) {
+		a.Logger.Error("Event handling error", "module", module, "event", eventType, "err", err)
+	}
+	fail := func(module string, err error) {
+		commandFail(module, err)
+		mu.Lock()
+		failed[module] = errors.Join(failed[module], err)
+		mu.Unlock()
+	}
 	for name, mod := range modules {
+		if !a.ModuleEnabled(repo, name) {
+			continue
+		}
+		if _, ok := mod.(NormalizedEventHandler); !Subscribed(mod, eventType, event) && (!ok || normalized == nil) {
+			continue
+		}
+		wg.Add(1)
 		go func(n string, m Module) {
-			if err := m.HandleEvent(eventType, event, raw); err != nil {
-				a.Logger.Error("Event handling error", "module", n, "event", eventType, "err", err)
+			defer wg.Done()
+			release := a.Limiter.Acquire(n, repo)
+			defer release()
+			var err error
+			a.Watchdog.Run(n, eventType, delivery, repo, func() {
+				err = a.callModule(n, m, delivery, eventType, repo, event, raw, normalized)
+			})
+			if err != nil {
+				fail(n, err)
 			}
 		}(name, mod)
 	}
+	if only == nil {
+		a.dispatchCommands(delivery, repo, event, modules, &wg, commandFail)
+	}
+	wg.Wait()
+	return failed
+}
+
+// callModule hands an event, and its normalized form if the module handles those, to a
+// module in the module's handler span. A panic in the module is returned as an error, and
+// errors are sent to the error reporter.
+func (a *App) callModule(name string, m Module, delivery, eventType, repo string, event any, raw []byte,
+	normalized *NormalizedEvent) (err error) {
+	ctx, span := a.startHandlerSpan(name, eventType, delivery, repo)
+	defer span.End()
+	if TruncatedFields(event) != nil {
+		span.SetAttributes(attribute.Bool("payload_truncated", true))
+	}
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			a.Errors.Report(ErrorReport{Err: err, Module: name, Event: eventType, Delivery: delivery, Repo: repo})
+		}
+	}()
+	defer RecoverPanic(name, &err)
+	if h, ok := m.(ModuleContextHandler); ok {
+		err = h.HandleEventContext(ctx, eventType, event, raw)
+	} else {
+		err = m.HandleEvent(eventType, event, raw)
+	}
+	if h, ok := m.(NormalizedEventHandler); ok && normalized != nil && err == nil {
+		err = h.HandleNormalizedEvent(normalized)
+	}
+	return err
+}
+
+// startHandlerSpan starts the span of a module handling an event, whose context carries
+// the decision scope AddDecision attributes decisions to.
+func (a *App) startHandlerSpan(module, eventType, delivery, repo string) (context.Context, trace.Span) {
+	ctx := WithDecisionScope(context.Background(), a.Decisions, module, eventType, delivery, repo)
+	if a.Telemetry == nil {
+		return ctx, trace.SpanFromContext(ctx)
+	}
+	return a.Telemetry.Tracer().Start(ctx, "module."+module+".handle_"+eventType, trace.WithAttributes(
+		attribute.String("otto.module", module),
+		attribute.String("github.event", eventType),
+		attribute.String("github.delivery", delivery),
+		attribute.String("repo", repo),
+	))
+}
+
+// DispatchNormalizedEvent queues an event from a source other than GitHub on the dispatch
+// pool. The enabled modules that handle normalized events each handle it in their own
+// goroutine.
+func (a *App) DispatchNormalizedEvent(event *NormalizedEvent) {
+	done := a.lifecycle.begin()
+	a.Dispatch.Submit(PriorityNormal, func() {
+		defer done()
+		var wg sync.WaitGroup
+		for name, mod := range a.ModuleRegistry.GetModules() {
+			h, ok := mod.(NormalizedEventHandler)
+			if !ok || !a.ModuleEnabled(event.Repo, name) {
+				continue
+			}
+			wg.Add(1)
+			go func(n string, h NormalizedEventHandler) {
+				defer wg.Done()
+				release := a.Limiter.Acquire(n, event.Repo)
+				defer release()
+				var err error
+				a.Watchdog.Run(n, event.Kind, "", event.Repo, func() {
+					defer RecoverPanic(n, &err)
+					err = h.HandleNormalizedEvent(event)
+				})
+				if err != nil {
+					a.Errors.Report(ErrorReport{Err: err, Module: n, Event: event.Kind, Repo: event.Repo})
+					a.Logger.Error("Event handling error", "module", n, "source", event.Source,
+						"event", event.Kind, "err", err)
+				}
+			}(name, h)
+		}
+		wg.Wait()
+	})
+}
+
+// ModuleFlag is the feature flag that turns a module on or off, per repository if the
+// flag provider targets on the repo attribute.
+func ModuleFlag(module string) string {
+	return "module." + module
+}
+
+// ModuleEnabled reports whether a module is enabled for a repository by its feature flag,
+// the route of its config section and in the registry. Events without a repository, and
+// registry errors, fall back to enabled.
+func (a *App) ModuleEnabled(repo, module string) bool {
+	if !a.Flags.Enabled(context.Background(), ModuleFlag(module), repo, module, true) {
+		return false
+	}
+	route, err := a.ModuleRoute(module)
+	if err != nil {
+		slog.Error("Failed to read module route", "module", module, "err", err)
+	} else if !route.Includes(a.RepoGroups, repo) {
+		return false
+	}
+	if a.Repos == nil || repo == "" {
+		return true
+	}
+	enabled, err := a.Repos.ModuleEnabled(context.Background(), repo, module)
+	if err != nil {
+		slog.Error("Failed to check module enablement", "repo", repo, "module", module, "err", err)
+		return true
+	}
+	return enabled
+}
+
+// eventRepo extracts the repository full name from a webhook payload.
+func eventRepo(raw []byte) string {
+	var env eventEnvelope
+	if err := json.Unmarshal(raw, &env); err != nil {
+		return ""
+	}
+	return env.Repository.FullName
+}
+
+// ownSecrets returns the values of the secrets Otto holds, by name, for the content filter.
+func (a *App) ownSecrets() map[string]string {
+	values := map[string]string{
+		"webhook_secret": a.Secrets.GetWebhookSecret(),
+		"admin_token":    a.Secrets.GetAdminToken(),
+	}
+	for _, name := range []string{
+		SlackBotTokenSecret, SMTPPasswordSecret, RedisPasswordSecret, SentryDSNSecret, FeatureFlagsTokenSecret,
+		PayloadSecretKeySecret,
+	} {
+		values[name] = a.Secrets.GetSecret(name)
+	}
+	for _, endpoint := range a.Config.Webhooks {
+		if endpoint.Secret != "" {
+			values[endpoint.Secret] = a.Secrets.GetSecret(endpoint.Secret)
+		}
+	}
+	for _, client := range a.Config.ActionsAPI.Clients {
+		values[client.Secret] = a.Secrets.GetSecret(client.Secret)
+	}
+	return values
 }
 
 // initializeGitHubClient sets up the GitHub API client with proper authentication.
@@ -234,21 +1042,70 @@ func (a *App) initializeGitHubClient(ctx context.Context) error {
 			return fmt.Errorf("failed to create GitHub app token source: %w", err)
 		}
 
-		installationTokenSource := githubauth.NewInstallationTokenSource(installID, appTokenSource)
-
-		// Create an HTTP client that uses the installation token
-		httpClient := oauth2.NewClient(ctx, installationTokenSource)
+		a.appTokens = appTokenSource
+		a.installations = &installationClients{clients: make(map[int64]*github.Client)}
+		a.GitHubClient = a.installationClient(ctx, installID)
 
-		// Create a new GitHub client with the custom HTTP client
-		a.GitHubClient = github.NewClient(httpClient)
+		// Reading the installation's permissions needs the app's own token
+		appHTTPClient := oauth2.NewClient(context.WithValue(ctx, oauth2.HTTPClient, a.HTTPClient(0)), appTokenSource)
+		a.appClient = github.NewClient(appHTTPClient)
 		slog.Info("GitHub client initialized with GitHub App authentication",
 			"app_id", appID,
 			"installation_id", installID)
 	} else {
 		// If no authentication configured, use unauthenticated client
-		a.GitHubClient = github.NewClient(nil)
+		a.GitHubClient = github.NewClient(&http.Client{Transport: a.githubTransport(a.HTTPClient(0).Transport)})
 		slog.Info("GitHub client initialized (no auth)")
 	}
 
 	return nil
 }
+
+// githubTransport wraps the transport of a GitHub client in the app's content filter,
+// status tracking, API budgets, pacing, rate limit tracking and usage record, outermost
+// first.
+func (a *App) githubTransport(base http.RoundTripper) http.RoundTripper {
+	transport := a.Pacer.Transport(a.RateLimits.Transport(a.APIUsage.Transport(base)))
+	return a.ContentFilter.Transport(a.GitHubStatus.Transport(a.Budgets.Transport(transport)))
+}
+
+// installationClient creates a client authenticated as an installation of the GitHub App,
+// whose tokens are refreshed before they expire.
+func (a *App) installationClient(ctx context.Context, installationID int64) *github.Client {
+	tokens := githubauth.NewInstallationTokenSource(installationID, a.appTokens,
+		githubauth.WithHTTPClient(a.HTTPClient(0)))
+	httpClient := oauth2.NewClient(context.WithValue(ctx, oauth2.HTTPClient, a.HTTPClient(0)), tokens)
+	httpClient.Transport = a.githubTransport(httpClient.Transport)
+	return github.NewClient(httpClient)
+}
+
+// InstallationClient returns a client acting as an installation of the GitHub App, such as
+// one in another organization, with that installation's permissions. The configured
+// installation gets GitHubClient; clients of others are created on first use and kept.
+// Modules in shadow mode get clients that record their writes like GitHubClient does.
+func (a *App) InstallationClient(ctx context.Context, installationID int64) (*github.Client, error) {
+	if a.Secrets != nil && installationID == a.Secrets.GetGitHubInstallationID() && a.GitHubClient != nil {
+		return a.GitHubClient, nil
+	}
+	if a.appTokens == nil || a.installations == nil {
+		return nil, fmt.Errorf("no GitHub App credentials to act as installation %d", installationID)
+	}
+	a.installations.mu.Lock()
+	defer a.installations.mu.Unlock()
+	client, ok := a.installations.clients[installationID]
+	if !ok {
+		client = a.installationClient(context.WithoutCancel(ctx), installationID)
+		a.installations.clients[installationID] = client
+	}
+	if a.shadowModule != "" {
+		return a.Shadow.Client(client, a.shadowModule), nil
+	}
+	return client, nil
+}
+
+// installationClients are the clients of installations made by InstallationClient, shared
+// by the copies of the app that modules in shadow mode get.
+type installationClients struct {
+	mu      sync.Mutex
+	clients map[int64]*github.Client
+}
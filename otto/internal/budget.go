@@ -0,0 +1,143 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// budget.go limits how many GitHub API calls each module may make per hour, so
+// background work cannot use up the rate limit interactive commands depend on.
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// ErrAPIBudgetExhausted is returned for GitHub API calls made after a module used up its hourly budget.
+var ErrAPIBudgetExhausted = errors.New("github API budget exhausted")
+
+// apiBudgetWindow is the period budgets are measured over.
+const apiBudgetWindow = time.Hour
+
+type moduleContextKey struct{}
+
+// WithModule attributes GitHub API calls made with ctx to a module's budget.
+func WithModule(ctx context.Context, module string) context.Context {
+	return context.WithValue(ctx, moduleContextKey{}, module)
+}
+
+// ModuleFromContext returns the module set by WithModule, or "".
+func ModuleFromContext(ctx context.Context) string {
+	module, _ := ctx.Value(moduleContextKey{}).(string)
+	return module
+}
+
+// APIBudgets tracks per-module GitHub API usage in fixed hourly windows.
+type APIBudgets struct {
+	mu        sync.Mutex
+	limits    map[string]int
+	used      map[string]int
+	window    time.Time
+	telemetry *TelemetryManager
+	now       func() time.Time
+}
+
+// NewAPIBudgets creates budgets from module -> calls per hour. Modules without a
+// limit, and calls not attributed to a module, are unlimited. Telemetry may be nil.
+func NewAPIBudgets(limits map[string]int, telemetry *TelemetryManager) (*APIBudgets, error) {
+	b := &APIBudgets{
+		limits:    limits,
+		used:      make(map[string]int),
+		telemetry: telemetry,
+		now:       time.Now,
+	}
+	if telemetry != nil && telemetry.MeterProvider != nil {
+		_, err := telemetry.Meter().Int64ObservableGauge(
+			"otto.github.api_budget_remaining",
+			metric.WithDescription("GitHub API calls a module may still make in the current hour"),
+			metric.WithInt64Callback(func(_ context.Context, o metric.Int64Observer) error {
+				for module, remaining := range b.Remaining() {
+					o.Observe(int64(remaining), telemetry.attrs(attribute.String("module", module)))
+				}
+				return nil
+			}),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create api budget gauge: %w", err)
+		}
+	}
+	return b, nil
+}
+
+// Allow consumes one call from a module's budget, returning ErrAPIBudgetExhausted when
+// none is left.
+func (b *APIBudgets) Allow(ctx context.Context, module string) error {
+	b.mu.Lock()
+	b.roll()
+	limit, limited := b.limits[module]
+	allowed := !limited || b.used[module] < limit
+	if allowed {
+		b.used[module]++
+	}
+	b.mu.Unlock()
+
+	if b.telemetry != nil && module != "" {
+		outcome := "allowed"
+		if !allowed {
+			outcome = "rejected"
+		}
+		b.telemetry.IncGitHubAPICall(ctx, module, outcome)
+	}
+	if !allowed {
+		return fmt.Errorf("%w: module %s used %d calls this hour", ErrAPIBudgetExhausted, module, limit)
+	}
+	return nil
+}
+
+// Remaining returns the calls left this hour for every module with a budget.
+func (b *APIBudgets) Remaining() map[string]int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.roll()
+	out := make(map[string]int, len(b.limits))
+	for module, limit := range b.limits {
+		out[module] = max(limit-b.used[module], 0)
+	}
+	return out
+}
+
+// roll starts a new window once the current one has passed. Must be called with b.mu held.
+func (b *APIBudgets) roll() {
+	now := b.now()
+	if now.Sub(b.window) < apiBudgetWindow {
+		return
+	}
+	b.window = now.Truncate(apiBudgetWindow)
+	clear(b.used)
+}
+
+// Transport wraps base so requests count against the budget of the module in their context.
+func (b *APIBudgets) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &budgetTransport{budgets: b, base: base}
+}
+
+type budgetTransport struct {
+	budgets *APIBudgets
+	base    http.RoundTripper
+}
+
+func (t *budgetTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if module := ModuleFromContext(req.Context()); module != "" {
+		if err := t.budgets.Allow(req.Context(), module); err != nil {
+			return nil, err
+		}
+	}
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,102 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestAPIBudgetTransport(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	}))
+	defer server.Close()
+
+	reader := sdkmetric.NewManualReader()
+	budgets, err := NewAPIBudgets(map[string]int{"sla": 2}, TestTelemetry(t, reader))
+	if err != nil {
+		t.Fatalf("NewAPIBudgets failed: %v", err)
+	}
+	now := time.Date(2025, 5, 1, 10, 15, 0, 0, time.UTC)
+	budgets.now = func() time.Time { return now }
+	client := &http.Client{Transport: budgets.Transport(nil)}
+
+	get := func(ctx context.Context) error {
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		resp, err := client.Do(req)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+
+	sla := WithModule(t.Context(), "sla")
+	for i := range 2 {
+		if err := get(sla); err != nil {
+			t.Fatalf("call %d rejected: %v", i, err)
+		}
+	}
+	if err := get(sla); !errors.Is(err, ErrAPIBudgetExhausted) {
+		t.Fatalf("third call err = %v, want ErrAPIBudgetExhausted", err)
+	}
+	if calls != 2 {
+		t.Errorf("server saw %d calls, want 2", calls)
+	}
+
+	// Unattributed calls and modules without a budget are not limited.
+	if err := get(t.Context()); err != nil {
+		t.Errorf("unattributed call rejected: %v", err)
+	}
+	if err := get(WithModule(t.Context(), "checklist")); err != nil {
+		t.Errorf("unbudgeted module rejected: %v", err)
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(t.Context(), &rm); err != nil {
+		t.Fatalf("failed to collect metrics: %v", err)
+	}
+	var remaining int64 = -1
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if g, ok := m.Data.(metricdata.Gauge[int64]); ok && m.Name == "otto.github.api_budget_remaining" {
+				remaining = g.DataPoints[0].Value
+			}
+		}
+	}
+	if remaining != 0 {
+		t.Errorf("remaining budget gauge = %d, want 0", remaining)
+	}
+
+	// The budget refills in the next hour.
+	now = now.Add(time.Hour)
+	if err := get(sla); err != nil {
+		t.Errorf("call in new window rejected: %v", err)
+	}
+}
+
+func TestSchedulerAttributesJobsToModules(t *testing.T) {
+	s := NewScheduler(nil)
+	var got string
+	s.Register(Job{
+		Name:     "scan",
+		Module:   "sla",
+		Interval: time.Hour,
+		Run: func(ctx context.Context) error {
+			got = ModuleFromContext(ctx)
+			return nil
+		},
+	})
+	s.RunNow(t.Context(), "scan")
+	if got != "sla" {
+		t.Errorf("job context module = %q, want sla", got)
+	}
+}
@@ -0,0 +1,177 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// bus.go is an in-process bus for domain events that modules publish, such as an on-call
+// rotation advancing, so that other modules can react to them without calling into each
+// other. The app publishes module lifecycle events on it too.
+
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"path"
+	"sync"
+	"time"
+)
+
+// Module lifecycle events, published by the app with the module they are about as Module.
+const (
+	EventModuleInitialized = "module.initialized" // once every module is initialized
+	EventModuleStopping    = "module.stopping"    // before the module's Shutdown is called
+)
+
+// DomainEvent is something that happened in a module, named "<module>.<what happened>",
+// e.g. "oncall.rotation_advanced".
+type DomainEvent struct {
+	Name   string    `json:"name"`
+	Module string    `json:"module"`         // publisher, or the module a lifecycle event is about
+	Repo   string    `json:"repo,omitempty"` // empty if the event is not about a repository
+	Data   any       `json:"data,omitempty"` // defined by the publisher next to the event name
+	At     time.Time `json:"at"`
+}
+
+// DomainEventHandler handles a domain event a module subscribed to.
+type DomainEventHandler func(ctx context.Context, e DomainEvent) error
+
+// BusSubscription is a module's subscription to the domain events whose names match a
+// path.Match pattern, such as "oncall.*".
+type BusSubscription struct {
+	Module  string `json:"module"`
+	Pattern string `json:"pattern"`
+
+	handler DomainEventHandler
+}
+
+// EventBus delivers domain events to the modules subscribed to them. Each subscriber
+// handles an event in its own goroutine, so publishers do not wait for subscribers;
+// subscribers disabled for the event's repository are skipped. Errors and panics of
+// handlers are logged and reported. A nil EventBus drops events.
+type EventBus struct {
+	enabled func(repo, module string) bool
+	errors  *ErrorReports
+
+	mu        sync.RWMutex
+	subs      []BusSubscription
+	published map[string]int64 // by event name
+	wg        sync.WaitGroup
+}
+
+// NewEventBus creates an event bus. enabled reports whether a module is enabled for a
+// repository; nil enables every module.
+func NewEventBus(enabled func(repo, module string) bool, errors *ErrorReports) *EventBus {
+	return &EventBus{enabled: enabled, errors: errors, published: make(map[string]int64)}
+}
+
+// Subscribe has module's handler called for the events whose names match pattern.
+// Modules typically subscribe in Initialize.
+func (b *EventBus) Subscribe(module, pattern string, handler DomainEventHandler) error {
+	if b == nil {
+		return nil
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return fmt.Errorf("invalid event pattern %q: %w", pattern, err)
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subs = append(b.subs, BusSubscription{Module: module, Pattern: pattern, handler: handler})
+	return nil
+}
+
+// Subscriptions returns the subscriptions in the order they were made.
+func (b *EventBus) Subscriptions() []BusSubscription {
+	if b == nil {
+		return nil
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	subs := make([]BusSubscription, len(b.subs))
+	copy(subs, b.subs)
+	return subs
+}
+
+// Publish hands an event to its subscribers and returns without waiting for them. Handlers
+// get ctx without its cancellation, so they may outlive the publisher's request.
+func (b *EventBus) Publish(ctx context.Context, e DomainEvent) {
+	if b == nil {
+		return
+	}
+	if e.At.IsZero() {
+		e.At = time.Now()
+	}
+	ctx = context.WithoutCancel(ctx)
+
+	b.mu.Lock()
+	b.published[e.Name]++
+	subs := make([]BusSubscription, 0, len(b.subs))
+	for _, s := range b.subs {
+		if ok, _ := path.Match(s.Pattern, e.Name); ok {
+			subs = append(subs, s)
+		}
+	}
+	b.mu.Unlock()
+
+	for _, s := range subs {
+		if b.enabled != nil && !b.enabled(e.Repo, s.Module) {
+			continue
+		}
+		b.wg.Add(1)
+		go func() {
+			defer b.wg.Done()
+			if err := b.deliver(ctx, s, e); err != nil {
+				slog.Error("Domain event handling error", "module", s.Module, "event", e.Name, "err", err)
+				b.errors.Report(ErrorReport{Err: err, Module: s.Module, Event: e.Name, Repo: e.Repo})
+			}
+		}()
+	}
+}
+
+// deliver calls a subscriber's handler, turning a panic into an error.
+func (b *EventBus) deliver(ctx context.Context, s BusSubscription, e DomainEvent) (err error) {
+	defer RecoverPanic(s.Module, &err)
+	return s.handler(ctx, e)
+}
+
+// Wait waits for the handlers of the events published so far to return.
+func (b *EventBus) Wait() {
+	if b != nil {
+		b.wg.Wait()
+	}
+}
+
+// Published returns how many events of each name were published.
+func (b *EventBus) Published() map[string]int64 {
+	if b == nil {
+		return nil
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return maps.Clone(b.published)
+}
+
+// RegisterAdminRoutes exposes the subscriptions and the published event counts on the
+// admin API.
+func (b *EventBus) RegisterAdminRoutes(srv *Server) {
+	if b == nil || srv == nil {
+		return
+	}
+	srv.HandleAdmin("GET /admin/bus", b.handleStatus)
+}
+
+// handleStatus serves the subscriptions and published event counts as JSON.
+func (b *EventBus) handleStatus(rw http.ResponseWriter, r *http.Request) {
+	status := struct {
+		Subscriptions []BusSubscription `json:"subscriptions"`
+		Published     map[string]int64  `json:"published"`
+	}{b.Subscriptions(), b.Published()}
+	if status.Subscriptions == nil {
+		status.Subscriptions = []BusSubscription{}
+	}
+	rw.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(rw).Encode(status); err != nil {
+		slog.Error("Failed to write event bus status", "error", err)
+	}
+}
@@ -0,0 +1,87 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestEventBus(t *testing.T) {
+	bus := NewEventBus(func(repo, module string) bool { return repo != "org/private" || module != "digest" }, nil)
+
+	var (
+		mu       sync.Mutex
+		received []string
+	)
+	record := func(module string) DomainEventHandler {
+		return func(ctx context.Context, e DomainEvent) error {
+			if e.At.IsZero() {
+				t.Errorf("%s: event %s without a time", module, e.Name)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			received = append(received, module+" "+e.Name+" "+e.Repo)
+			return nil
+		}
+	}
+	for _, s := range []struct{ module, pattern string }{
+		{"digest", "oncall.rotation_advanced"},
+		{"status", "oncall.*"},
+		{"audit", "*"},
+	} {
+		if err := bus.Subscribe(s.module, s.pattern, record(s.module)); err != nil {
+			t.Fatalf("Subscribe(%q) failed: %v", s.pattern, err)
+		}
+	}
+	if err := bus.Subscribe("broken", "oncall.[", record("broken")); err == nil {
+		t.Error("Subscribe with an invalid pattern succeeded")
+	}
+	// Handlers that fail or panic do not affect the others.
+	_ = bus.Subscribe("failing", "pr.*", func(context.Context, DomainEvent) error { return errors.New("boom") })
+	_ = bus.Subscribe("panicking", "pr.*", func(context.Context, DomainEvent) error { panic("boom") })
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	bus.Publish(ctx, DomainEvent{Name: "oncall.rotation_advanced", Module: "oncall"})
+	bus.Publish(ctx, DomainEvent{Name: "oncall.rotation_advanced", Module: "oncall", Repo: "org/private"})
+	bus.Publish(ctx, DomainEvent{Name: "pr.automerged", Module: "merge", Repo: "org/a"})
+	bus.Wait()
+
+	slices.Sort(received)
+	want := []string{
+		"audit oncall.rotation_advanced ",
+		"audit oncall.rotation_advanced org/private",
+		"audit pr.automerged org/a",
+		"digest oncall.rotation_advanced ",
+		"status oncall.rotation_advanced ",
+		"status oncall.rotation_advanced org/private",
+	}
+	if !slices.Equal(received, want) {
+		t.Errorf("received = %q, want %q", received, want)
+	}
+	if got := bus.Published(); got["oncall.rotation_advanced"] != 2 || got["pr.automerged"] != 1 {
+		t.Errorf("published = %v", got)
+	}
+
+	rr := httptest.NewRecorder()
+	bus.handleStatus(rr, httptest.NewRequest(http.MethodGet, "/admin/bus", nil))
+	if body := rr.Body.String(); !strings.Contains(body, `{"module":"status","pattern":"oncall.*"}`) ||
+		!strings.Contains(body, `"pr.automerged":1`) {
+		t.Errorf("status = %s", body)
+	}
+
+	// A nil bus drops events.
+	var none *EventBus
+	none.Publish(t.Context(), DomainEvent{Name: "oncall.rotation_advanced"})
+	none.Wait()
+	if err := none.Subscribe("status", "oncall.*", record("status")); err != nil {
+		t.Errorf("Subscribe on a nil bus = %v", err)
+	}
+}
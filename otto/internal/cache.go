@@ -0,0 +1,134 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// cache.go caches lookups of GitHub data, such as team members and repository files, in
+// memory or in Redis so that replicas share them and spend less rate limit.
+
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+)
+
+// RedisPasswordSecret is the secret holding the Redis password, if the server requires one.
+const RedisPasswordSecret = "redis_password"
+
+// Cache stores values for a limited time. Implementations are safe for concurrent use.
+type Cache interface {
+	// Get returns the value stored under key, or false if there is none or it expired.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set stores value under key for ttl.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Delete removes key.
+	Delete(ctx context.Context, key string) error
+}
+
+// NewCache creates the configured cache backend.
+func NewCache(cfg config.CacheConfig, password string) (Cache, error) {
+	switch cfg.Backend {
+	case "", "memory":
+		return NewMemoryCache(), nil
+	case "redis":
+		return NewRedisCache(cfg.Redis, password), nil
+	default:
+		return nil, fmt.Errorf("unsupported cache backend %q", cfg.Backend)
+	}
+}
+
+// GetCached decodes the JSON value stored under key. Cache errors are logged and treated
+// as misses, so a failing cache only costs extra lookups. A nil cache always misses.
+func GetCached[T any](ctx context.Context, c Cache, key string) (T, bool) {
+	var v T
+	if c == nil {
+		return v, false
+	}
+	data, ok, err := c.Get(ctx, key)
+	if err != nil {
+		slog.WarnContext(ctx, "Cache lookup failed", "key", key, "error", err)
+		return v, false
+	}
+	if !ok {
+		return v, false
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		slog.WarnContext(ctx, "Ignoring undecodable cache entry", "key", key, "error", err)
+		return v, false
+	}
+	return v, true
+}
+
+// SetCached stores v under key as JSON for ttl. Errors are logged. A nil cache stores nothing.
+func SetCached[T any](ctx context.Context, c Cache, key string, v T, ttl time.Duration) {
+	if c == nil {
+		return
+	}
+	data, err := json.Marshal(v)
+	if err == nil {
+		err = c.Set(ctx, key, data, ttl)
+	}
+	if err != nil {
+		slog.WarnContext(ctx, "Cache update failed", "key", key, "error", err)
+	}
+}
+
+// memoryEntry is a value in a MemoryCache.
+type memoryEntry struct {
+	value   []byte
+	expires time.Time
+}
+
+// MemoryCache is a Cache local to this instance.
+type MemoryCache struct {
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+}
+
+// NewMemoryCache creates an empty in-memory cache.
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{now: time.Now, entries: make(map[string]memoryEntry)}
+}
+
+// Get implements Cache.
+func (c *MemoryCache) Get(_ context.Context, key string) ([]byte, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	if !c.now().Before(e.expires) {
+		delete(c.entries, key)
+		return nil, false, nil
+	}
+	return e.value, true, nil
+}
+
+// Set implements Cache. Expired entries are pruned as new ones are added.
+func (c *MemoryCache) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	for k, e := range c.entries {
+		if !now.Before(e.expires) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = memoryEntry{value: value, expires: now.Add(ttl)}
+	return nil
+}
+
+// Delete implements Cache.
+func (c *MemoryCache) Delete(_ context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+	return nil
+}
@@ -0,0 +1,101 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+)
+
+func TestMemoryCache(t *testing.T) {
+	now := time.Now()
+	cache := NewMemoryCache()
+	cache.now = func() time.Time { return now }
+
+	if err := cache.Set(t.Context(), "a", []byte("1"), time.Minute); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if v, ok, err := cache.Get(t.Context(), "a"); err != nil || !ok || string(v) != "1" {
+		t.Errorf("Get(a) = %q, %v, %v; want 1", v, ok, err)
+	}
+	now = now.Add(time.Minute)
+	if _, ok, _ := cache.Get(t.Context(), "a"); ok {
+		t.Error("expired entry was returned")
+	}
+
+	_ = cache.Set(t.Context(), "b", []byte("2"), time.Minute)
+	if err := cache.Delete(t.Context(), "b"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, ok, _ := cache.Get(t.Context(), "b"); ok {
+		t.Error("deleted entry was returned")
+	}
+}
+
+// failingCache fails every operation.
+type failingCache struct{}
+
+func (failingCache) Get(context.Context, string) ([]byte, bool, error) {
+	return nil, false, errors.New("unavailable")
+}
+
+func (failingCache) Set(context.Context, string, []byte, time.Duration) error {
+	return errors.New("unavailable")
+}
+
+func (failingCache) Delete(context.Context, string) error { return errors.New("unavailable") }
+
+func TestCachedValues(t *testing.T) {
+	cache := NewMemoryCache()
+	SetCached(t.Context(), cache, "team", []string{"alice", "bob"}, time.Minute)
+	if got, ok := GetCached[[]string](t.Context(), cache, "team"); !ok || !slices.Equal(got, []string{"alice", "bob"}) {
+		t.Errorf("GetCached() = %v, %v", got, ok)
+	}
+	if _, ok := GetCached[[]string](t.Context(), cache, "missing"); ok {
+		t.Error("GetCached() hit a missing key")
+	}
+	_ = cache.Set(t.Context(), "bad", []byte("{"), time.Minute)
+	if _, ok := GetCached[[]string](t.Context(), cache, "bad"); ok {
+		t.Error("GetCached() hit an undecodable entry")
+	}
+
+	// Failing and missing caches behave as misses.
+	SetCached(t.Context(), failingCache{}, "team", []string{"alice"}, time.Minute)
+	if _, ok := GetCached[[]string](t.Context(), failingCache{}, "team"); ok {
+		t.Error("GetCached() hit on a failing cache")
+	}
+	SetCached[[]string](t.Context(), nil, "team", nil, time.Minute)
+	if _, ok := GetCached[[]string](t.Context(), nil, "team"); ok {
+		t.Error("GetCached() hit on a nil cache")
+	}
+}
+
+func TestNewCache(t *testing.T) {
+	tests := []struct {
+		backend string
+		want    string
+		wantErr bool
+	}{
+		{backend: "", want: "*internal.MemoryCache"},
+		{backend: "memory", want: "*internal.MemoryCache"},
+		{backend: "redis", want: "*internal.RedisCache"},
+		{backend: "memcached", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.backend, func(t *testing.T) {
+			cache, err := NewCache(config.CacheConfig{Backend: tt.backend}, "")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewCache() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := fmt.Sprintf("%T", cache); !tt.wantErr && got != tt.want {
+				t.Errorf("NewCache() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
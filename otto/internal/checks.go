@@ -0,0 +1,60 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// checks.go publishes GitHub check runs on pull request commits.
+
+package internal
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/go-github/v71/github"
+)
+
+// Check run statuses and conclusions used by Otto.
+const (
+	CheckStatusInProgress = "in_progress"
+	CheckStatusCompleted  = "completed"
+
+	CheckConclusionSuccess = "success"
+	CheckConclusionFailure = "failure"
+	CheckConclusionNeutral = "neutral"
+	CheckConclusionSkipped = "skipped"
+)
+
+// CheckRun describes a check run to publish on a commit.
+type CheckRun struct {
+	Name       string
+	HeadSHA    string
+	Status     string // CheckStatusInProgress or CheckStatusCompleted
+	Conclusion string // required when Status is CheckStatusCompleted
+	Title      string
+	Summary    string // markdown
+}
+
+// PublishCheckRun creates a check run. GitHub shows the most recent run for
+// each name, so re-publishing replaces the previous result in the UI.
+func PublishCheckRun(ctx context.Context, client *github.Client, repo string, run CheckRun) error {
+	owner, name, err := SplitRepo(repo)
+	if err != nil {
+		return err
+	}
+	opts := github.CreateCheckRunOptions{
+		Name:    run.Name,
+		HeadSHA: run.HeadSHA,
+		Status:  github.Ptr(run.Status),
+		Output: &github.CheckRunOutput{
+			Title:   github.Ptr(run.Title),
+			Summary: github.Ptr(run.Summary),
+		},
+	}
+	if run.Status == CheckStatusCompleted {
+		opts.Conclusion = github.Ptr(run.Conclusion)
+		opts.CompletedAt = &github.Timestamp{Time: time.Now()}
+	}
+	if _, _, err := client.Checks.CreateCheckRun(ctx, owner, name, opts); err != nil {
+		return fmt.Errorf("failed to publish check run %s on %s: %w", run.Name, repo, err)
+	}
+	return nil
+}
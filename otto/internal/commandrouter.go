@@ -0,0 +1,111 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// commandrouter.go applies command aliases and disabled commands to comments before
+// modules see them, so modules only ever handle canonical command names.
+
+package internal
+
+import (
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/google/go-github/v71/github"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+)
+
+// CommandRouter rewrites slash commands in comments according to the commands config.
+type CommandRouter struct {
+	cfg config.CommandsConfig
+}
+
+// NewCommandRouter creates a router for the given aliases and disabled commands.
+func NewCommandRouter(cfg config.CommandsConfig) *CommandRouter {
+	return &CommandRouter{cfg: cfg}
+}
+
+// settings merges the global and per-repository settings; the repository's aliases win.
+func (r *CommandRouter) settings(repo string) config.CommandSettings {
+	merged := config.CommandSettings{
+		Aliases:  make(map[string]string),
+		Disabled: slices.Clone(r.cfg.Disabled),
+	}
+	repoSettings := r.cfg.Repos[repo]
+	for _, aliases := range []map[string]string{r.cfg.Aliases, repoSettings.Aliases} {
+		for alias, target := range aliases {
+			merged.Aliases[strings.ToLower(strings.TrimPrefix(alias, "/"))] = strings.TrimPrefix(target, "/")
+		}
+	}
+	merged.Disabled = append(merged.Disabled, repoSettings.Disabled...)
+	for i, name := range merged.Disabled {
+		merged.Disabled[i] = strings.ToLower(strings.TrimPrefix(name, "/"))
+	}
+	return merged
+}
+
+// Rewrite expands aliases in the command lines of body and removes disabled commands,
+// returning the new body and the names of the commands that were removed. Aliases are
+// expanded once, so an alias cannot refer to another alias.
+func (r *CommandRouter) Rewrite(repo, body string) (string, []string) {
+	if r == nil {
+		return body, nil
+	}
+	settings := r.settings(repo)
+	if len(settings.Aliases) == 0 && len(settings.Disabled) == 0 {
+		return body, nil
+	}
+	var (
+		lines    = strings.Split(body, "\n")
+		out      = make([]string, 0, len(lines))
+		disabled []string
+		inFence  bool
+	)
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if !isCommandLine(trimmed, &inFence) {
+			out = append(out, line)
+			continue
+		}
+		name, args, _ := strings.Cut(strings.TrimPrefix(trimmed, "/"), " ")
+		name = strings.ToLower(name)
+		if slices.Contains(settings.Disabled, name) {
+			disabled = append(disabled, name)
+			continue
+		}
+		target, ok := settings.Aliases[name]
+		if !ok {
+			out = append(out, line)
+			continue
+		}
+		if targetName, _, _ := strings.Cut(target, " "); slices.Contains(settings.Disabled, strings.ToLower(targetName)) {
+			disabled = append(disabled, name)
+			continue
+		}
+		out = append(out, strings.TrimSpace("/"+target+" "+args))
+	}
+	return strings.Join(out, "\n"), disabled
+}
+
+// Route returns event with its comment rewritten by Rewrite. Events other than new issue
+// comments, and comments without changes, are returned as they are; the event passed in
+// is never modified.
+func (r *CommandRouter) Route(event any) any {
+	e, ok := event.(*github.IssueCommentEvent)
+	if r == nil || !ok || e.Comment == nil {
+		return event
+	}
+	repo := e.GetRepo().GetFullName()
+	body, disabled := r.Rewrite(repo, e.GetComment().GetBody())
+	if len(disabled) > 0 {
+		slog.Info("Ignoring disabled slash commands", "repo", repo, "issue", e.GetIssue().GetNumber(),
+			"user", e.GetComment().GetUser().GetLogin(), "commands", disabled)
+	}
+	if body == e.GetComment().GetBody() {
+		return event
+	}
+	routed, comment := *e, *e.Comment
+	comment.Body = &body
+	routed.Comment = &comment
+	return &routed
+}
@@ -0,0 +1,87 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/go-github/v71/github"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+)
+
+func TestCommandRouterRewrite(t *testing.T) {
+	router := NewCommandRouter(config.CommandsConfig{
+		CommandSettings: config.CommandSettings{
+			Aliases:  map[string]string{"/lgtm": "/approve", "ack": "oncall ack", "ok": "approve"},
+			Disabled: []string{"nuke"},
+		},
+		Repos: map[string]config.CommandSettings{
+			"org/strict": {Aliases: map[string]string{"lgtm": "review"}, Disabled: []string{"/approve"}},
+		},
+	})
+
+	tests := []struct {
+		name         string
+		repo         string
+		body         string
+		want         string
+		wantDisabled []string
+	}{
+		{"alias", "org/repo", "/lgtm", "/approve", nil},
+		{"alias case-insensitive", "org/repo", "/LGTM", "/approve", nil},
+		{"alias with args", "org/repo", "/ack now", "/oncall ack now", nil},
+		{"unknown command", "org/repo", "/echo hi", "/echo hi", nil},
+		{"text kept", "org/repo", "Looks good\n/lgtm\nthanks", "Looks good\n/approve\nthanks", nil},
+		{"fenced code untouched", "org/repo", "```\n/lgtm\n```", "```\n/lgtm\n```", nil},
+		{"disabled", "org/repo", "/nuke all\n/echo hi", "/echo hi", []string{"nuke"}},
+		{"repo alias wins", "org/strict", "/lgtm", "/review", nil},
+		{"repo disabled", "org/strict", "/approve", "", []string{"approve"}},
+		{"global disabled applies to repo", "org/strict", "/nuke", "", []string{"nuke"}},
+		{"alias to disabled", "org/strict", "/ok\n/lgtm", "/review", []string{"ok"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, disabled := router.Rewrite(tt.repo, tt.body)
+			if got != tt.want {
+				t.Errorf("Rewrite() body = %q, want %q", got, tt.want)
+			}
+			if !reflect.DeepEqual(disabled, tt.wantDisabled) {
+				t.Errorf("Rewrite() disabled = %v, want %v", disabled, tt.wantDisabled)
+			}
+		})
+	}
+}
+
+func TestCommandRouterRoute(t *testing.T) {
+	router := NewCommandRouter(config.CommandsConfig{
+		CommandSettings: config.CommandSettings{Aliases: map[string]string{"lgtm": "approve"}},
+	})
+	event := &github.IssueCommentEvent{
+		Repo:    &github.Repository{FullName: github.Ptr("org/repo")},
+		Comment: &github.IssueComment{Body: github.Ptr("/lgtm")},
+	}
+
+	routed, ok := router.Route(event).(*github.IssueCommentEvent)
+	if !ok {
+		t.Fatalf("Route() returned %T, want *github.IssueCommentEvent", routed)
+	}
+	if got := routed.GetComment().GetBody(); got != "/approve" {
+		t.Errorf("routed body = %q, want %q", got, "/approve")
+	}
+	if got := event.GetComment().GetBody(); got != "/lgtm" {
+		t.Errorf("original body = %q, want it unchanged", got)
+	}
+
+	other := &github.PushEvent{}
+	if got := router.Route(other); got != any(other) {
+		t.Errorf("Route() changed a push event")
+	}
+
+	var nilRouter *CommandRouter
+	if got := nilRouter.Route(event); got != any(event) {
+		t.Errorf("nil router changed the event")
+	}
+}
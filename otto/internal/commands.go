@@ -1,17 +1,23 @@
 // SPDX-License-Identifier: Apache-2.0
 
-// commands.go provides utilities for parsing slash commands from comment text.
-// Note: Direct command dispatch has been removed. Each module should parse and handle
-// commands in their HandleEvent implementation.
+// commands.go parses slash commands from comment text and hands them to the modules that
+// handle them. Modules implementing ModuleCommandHandler get their commands parsed, traced
+// and counted by the app; ModuleCommandObservers see every command, whoever handles it.
 
 package internal
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/google/go-github/v71/github"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
 )
 
@@ -27,6 +33,75 @@ func IsSlashCommand(body string) bool {
 	return false
 }
 
+// SlashCommand is a single slash command parsed from a comment line.
+type SlashCommand struct {
+	Name string   // command name without the leading slash, e.g. "blocked-by"
+	Args []string // arguments; double-quoted arguments may contain spaces
+}
+
+// ParseSlashCommands extracts every slash command from a comment body, one per line.
+// Lines inside fenced code blocks and quoted lines are ignored so that quoting or
+// documenting a command does not execute it.
+func ParseSlashCommands(body string) []SlashCommand {
+	var commands []SlashCommand
+	inFence := false
+	for _, line := range strings.Split(body, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if !isCommandLine(trimmed, &inFence) {
+			continue
+		}
+		fields := splitCommandArgs(strings.TrimPrefix(trimmed, "/"))
+		if len(fields) == 0 {
+			continue
+		}
+		commands = append(commands, SlashCommand{
+			Name: strings.ToLower(fields[0]),
+			Args: fields[1:],
+		})
+	}
+	return commands
+}
+
+// isCommandLine reports whether a trimmed comment line holds a slash command. inFence tracks
+// whether the line is inside a fenced code block and is updated on fence lines.
+func isCommandLine(trimmed string, inFence *bool) bool {
+	if strings.HasPrefix(trimmed, "```") {
+		*inFence = !*inFence
+		return false
+	}
+	return !*inFence && strings.HasPrefix(trimmed, "/") && !strings.HasPrefix(trimmed, "//")
+}
+
+// splitCommandArgs splits on whitespace, keeping double-quoted sections together.
+func splitCommandArgs(s string) []string {
+	var (
+		args    []string
+		current strings.Builder
+		inQuote bool
+		hasArg  bool
+	)
+	for _, r := range s {
+		switch {
+		case r == '"':
+			inQuote = !inQuote
+			hasArg = true
+		case !inQuote && (r == ' ' || r == '\t'):
+			if hasArg {
+				args = append(args, current.String())
+				current.Reset()
+				hasArg = false
+			}
+		default:
+			current.WriteRune(r)
+			hasArg = true
+		}
+	}
+	if hasArg {
+		args = append(args, current.String())
+	}
+	return args
+}
+
 // LogSlashCommand logs information about a detected slash command for tracing purposes.
 func LogSlashCommand(
 	ctx context.Context,
@@ -59,3 +134,184 @@ func LogSlashCommand(
 		"repo", repo,
 		"issue", issueNum)
 }
+
+// userComment returns event if it is a newly created comment by a user, whose slash
+// commands are executed; comments by bots are not.
+func userComment(event any) (*github.IssueCommentEvent, bool) {
+	e, ok := event.(*github.IssueCommentEvent)
+	if !ok || e.GetAction() != "created" || e.GetComment().GetUser().GetType() == "Bot" {
+		return nil, false
+	}
+	return e, true
+}
+
+// commandContexts returns a CommandContext for each slash command in a newly created comment
+// by a user, in the order of the comment's lines.
+func (a *App) commandContexts(event any) []*CommandContext {
+	e, ok := userComment(event)
+	if !ok {
+		return nil
+	}
+	var cmds []*CommandContext
+	for _, cmd := range ParseSlashCommands(e.GetComment().GetBody()) {
+		cmds = append(cmds, &CommandContext{
+			Command:  cmd.Name,
+			Args:     cmd.Args,
+			Issuer:   e.GetComment().GetUser().GetLogin(),
+			Repo:     e.GetRepo().GetFullName(),
+			IssueNum: e.GetIssue().GetNumber(),
+			RawBody:  e.GetComment().GetBody(),
+			App:      a,
+			Event:    e,
+		})
+	}
+	return cmds
+}
+
+// dispatchCommands hands each slash command in event to the module among modules that
+// handles it, the first registered to declare it, if that module is enabled for repo, and
+// all of them to the ModuleCommandObservers among modules enabled for repo. Each module's
+// commands run in their own goroutine, counted by wg, in the order of the comment, once the
+// module's concurrency limit allows, and is recorded in the command history with its
+// outcome; errors are passed to fail. Commands no module handles are not recorded.
+func (a *App) dispatchCommands(delivery, repo string, event any, modules map[string]Module, wg *sync.WaitGroup,
+	fail func(module string, err error)) {
+	cmds := a.commandContexts(event)
+	if len(cmds) == 0 {
+		return
+	}
+	handlers := make(map[string]string)
+	for command, name := range a.ModuleRegistry.CommandHandlers() {
+		if _, ok := modules[name].(ModuleCommandHandler); ok && a.ModuleEnabled(repo, name) {
+			handlers[command] = name
+		}
+	}
+	byModule := make(map[string][]*CommandContext)
+	for _, cmd := range cmds {
+		if name, ok := commandModule(handlers, cmd.Command, cmd.Args); ok {
+			byModule[name] = append(byModule[name], cmd)
+		}
+	}
+	run := func(name string, fn func() error) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release := a.Limiter.Acquire(name, repo)
+			defer release()
+			var err error
+			a.Watchdog.Run(name, "issue_comment", delivery, repo, func() {
+				err = fn()
+			})
+			if err != nil {
+				fail(name, err)
+			}
+		}()
+	}
+	// Observers get their copies before the handlers run, which set the commands' contexts.
+	for name, m := range modules {
+		if o, ok := m.(ModuleCommandObserver); ok && a.ModuleEnabled(repo, name) {
+			observed := observedCommands(name, cmds)
+			run(name, func() (err error) {
+				defer RecoverPanic(name, &err)
+				return o.ObserveCommands(observed)
+			})
+		}
+	}
+	for name, cmds := range byModule {
+		h := modules[name].(ModuleCommandHandler)
+		run(name, func() error {
+			var errs []error
+			for _, cmd := range cmds {
+				err := a.callCommand(name, h, delivery, cmd)
+				a.recordCommand(name, cmd, err)
+				errs = append(errs, err)
+			}
+			return errors.Join(errs...)
+		})
+	}
+}
+
+// recordCommand records a command handled by a module in the command history, with the
+// error the module returned, if any.
+func (a *App) recordCommand(module string, cmd *CommandContext, err error) {
+	if a.Commands == nil {
+		return
+	}
+	r := CommandRecord{
+		Repo:       cmd.Repo,
+		Issue:      cmd.IssueNum,
+		User:       cmd.Issuer,
+		Command:    cmd.Command,
+		Args:       cmd.Args,
+		Outcome:    CommandOK,
+		ExecutedAt: time.Now(),
+	}
+	if err != nil {
+		r.Outcome, r.Error = CommandError, fmt.Sprintf("%s: %v", module, err)
+	}
+	if err := a.Commands.Record(context.Background(), r); err != nil {
+		a.Logger.Error("Failed to record command", "module", module, "command", r.Command, "err", err)
+	}
+}
+
+// observedCommands returns copies of cmds for an observing module, whose contexts name
+// the module; the commands themselves get the contexts of their handlers' spans.
+func observedCommands(module string, cmds []*CommandContext) []*CommandContext {
+	ctx := WithModule(context.Background(), module)
+	observed := make([]*CommandContext, len(cmds))
+	for i, cmd := range cmds {
+		c := *cmd
+		c.Context = ctx
+		observed[i] = &c
+	}
+	return observed
+}
+
+// commandModule returns the module handling a command, by the command and its first
+// argument for commands declared with a subcommand, else by the command alone.
+func commandModule(handlers map[string]string, command string, args []string) (string, bool) {
+	if len(args) > 0 {
+		if module, ok := handlers[command+" "+strings.ToLower(args[0])]; ok {
+			return module, true
+		}
+	}
+	module, ok := handlers[command]
+	return module, ok
+}
+
+// callCommand calls a module's command handler in the command's span and counts the
+// command. A panic in the module is returned as an error, and errors are counted and sent
+// to the error reporter.
+func (a *App) callCommand(name string, h ModuleCommandHandler, delivery string, cmd *CommandContext) (err error) {
+	ctx := WithDecisionScope(context.Background(), a.Decisions, name, "issue_comment", delivery, cmd.Repo)
+	ctx = WithModule(ctx, name)
+	span := trace.SpanFromContext(ctx)
+	if a.Telemetry != nil {
+		ctx, span = a.Telemetry.StartModuleCommandSpan(ctx, name, cmd.Command)
+		span.SetAttributes(
+			attribute.String("otto.module", name),
+			attribute.String("repo", cmd.Repo),
+			attribute.Int("issue_num", cmd.IssueNum),
+			attribute.String("issuer", cmd.Issuer),
+			attribute.String("command.name", cmd.Command),
+			attribute.Int("command.args_count", len(cmd.Args)),
+		)
+		a.Telemetry.IncModuleCommand(ctx, name, cmd.Command)
+	}
+	defer span.End()
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			if a.Telemetry != nil {
+				a.Telemetry.IncModuleError(ctx, name, "command")
+			}
+			a.Errors.Report(ErrorReport{
+				Err: err, Module: name, Event: "issue_comment", Delivery: delivery, Repo: cmd.Repo,
+			})
+		}
+	}()
+	defer RecoverPanic(name, &err)
+	cmd.Context = ctx
+	return h.HandleCommand(cmd)
+}
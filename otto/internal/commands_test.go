@@ -3,7 +3,17 @@
 package internal
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"reflect"
+	"slices"
+	"sync"
 	"testing"
+
+	"github.com/google/go-github/v71/github"
 )
 
 func TestIsSlashCommand(t *testing.T) {
@@ -26,3 +36,176 @@ func TestIsSlashCommand(t *testing.T) {
 		}
 	}
 }
+
+func TestParseSlashCommands(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []SlashCommand
+	}{
+		{"none", "just a comment", nil},
+		{"single", "/blocked-by #123", []SlashCommand{{Name: "blocked-by", Args: []string{"#123"}}}},
+		{
+			"multiple lines",
+			"Thanks!\n/Blocks #4 #5\n/hold",
+			[]SlashCommand{{Name: "blocks", Args: []string{"#4", "#5"}}, {Name: "hold", Args: []string{}}},
+		},
+		{
+			"quoted args",
+			`/label-all query:"is:open label:bug" add:p2`,
+			[]SlashCommand{{Name: "label-all", Args: []string{"query:is:open label:bug", "add:p2"}}},
+		},
+		{"fenced code is ignored", "```\n/hold\n```", nil},
+		{"comments are ignored", "// not a command", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseSlashCommands(tt.body)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseSlashCommands(%q) = %#v, want %#v", tt.body, got, tt.want)
+			}
+		})
+	}
+}
+
+// commandHandlerModule is a module whose slash commands the app dispatches to it.
+type commandHandlerModule struct {
+	name     string
+	commands []string
+	mu       sync.Mutex
+	handled  []string
+}
+
+func (m *commandHandlerModule) Name() string { return m.name }
+
+func (m *commandHandlerModule) HandleEvent(string, any, json.RawMessage) error { return nil }
+
+func (m *commandHandlerModule) SlashCommands() []string { return m.commands }
+
+func (m *commandHandlerModule) HandleCommand(cmd *CommandContext) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.handled = append(m.handled, fmt.Sprintf("%s %v by %s on %s#%d as %s", cmd.Command, cmd.Args,
+		cmd.Issuer, cmd.Repo, cmd.IssueNum, ModuleFromContext(cmd.Context)))
+	if cmd.Command == "fail" {
+		return errors.New("boom")
+	}
+	return nil
+}
+
+// commandObserverModule is a module that observes every slash command in a comment.
+type commandObserverModule struct {
+	mu       sync.Mutex
+	observed [][]string
+}
+
+func (m *commandObserverModule) Name() string { return "analytics" }
+
+func (m *commandObserverModule) HandleEvent(string, any, json.RawMessage) error { return nil }
+
+func (m *commandObserverModule) ObserveCommands(cmds []*CommandContext) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var observed []string
+	for _, cmd := range cmds {
+		observed = append(observed, fmt.Sprintf("%s as %s", cmd.Command, ModuleFromContext(cmd.Context)))
+	}
+	m.observed = append(m.observed, observed)
+	return nil
+}
+
+func TestDispatchCommands(t *testing.T) {
+	history, err := NewCommandHistory(TestDB(t))
+	if err != nil {
+		t.Fatalf("NewCommandHistory failed: %v", err)
+	}
+	app := &App{ModuleRegistry: NewModuleRegistry(), Commands: history, Logger: slog.Default()}
+	status := &commandHandlerModule{name: "status", commands: []string{"otto status", "fail"}}
+	holds := &commandHandlerModule{name: "holds", commands: []string{"hold"}}
+	app.RegisterModule(status)
+	app.RegisterModule(holds)
+	observer := &commandObserverModule{}
+	app.RegisterModule(observer)
+
+	event := &github.IssueCommentEvent{
+		Action: github.Ptr("created"),
+		Repo:   &github.Repository{FullName: github.Ptr("org/repo")},
+		Issue:  &github.Issue{Number: github.Ptr(7)},
+		Comment: &github.IssueComment{
+			Body: github.Ptr("/otto status\n/OTTO history\n/hold \"until Friday\"\n```\n/hold\n```\n/fail"),
+			User: &github.User{Login: github.Ptr("alice")},
+		},
+	}
+	app.handleEvent("delivery-1", "issue_comment", event, nil, nil)
+
+	// A module's commands are handled one at a time, in the order of the comment.
+	want := []string{"otto [status] by alice on org/repo#7 as status", "fail [] by alice on org/repo#7 as status"}
+	if !slices.Equal(status.handled, want) {
+		t.Errorf("status handled %v, want %v", status.handled, want)
+	}
+	if want := []string{"hold [until Friday] by alice on org/repo#7 as holds"}; !slices.Equal(holds.handled, want) {
+		t.Errorf("holds handled %v, want %v", holds.handled, want)
+	}
+	observed := []string{"otto as analytics", "otto as analytics", "hold as analytics", "fail as analytics"}
+	if len(observer.observed) != 1 || !slices.Equal(observer.observed[0], observed) {
+		t.Errorf("observer observed %v, want [%v]", observer.observed, observed)
+	}
+	// Each handled command is recorded with its own outcome; /otto history has no handler.
+	records, err := history.Query(t.Context(), CommandQuery{Repo: "org/repo"})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	var recorded []string
+	for _, r := range records {
+		recorded = append(recorded, fmt.Sprintf("%s %s %s", r, r.Outcome, r.Error))
+	}
+	slices.Sort(recorded)
+	want = []string{"/fail error status: boom", "/hold until Friday ok ", "/otto status ok "}
+	if !slices.Equal(recorded, want) {
+		t.Errorf("recorded %q, want %q", recorded, want)
+	}
+
+	// Retries hand the event to the modules that failed, but do not run its commands again.
+	app.handleEvent("delivery-1", "issue_comment", event, nil, []string{"status", "holds"})
+	if len(status.handled) != 2 || len(holds.handled) != 1 {
+		t.Errorf("a retry ran commands again: status %v, holds %v", status.handled, holds.handled)
+	}
+
+	// Commands in comments by bots are not dispatched.
+	bot := *event
+	bot.Comment = &github.IssueComment{
+		Body: github.Ptr("/hold"),
+		User: &github.User{Login: github.Ptr("otto[bot]"), Type: github.Ptr("Bot")},
+	}
+	app.handleEvent("delivery-2", "issue_comment", &bot, nil, nil)
+	if len(holds.handled) != 1 || len(observer.observed) != 1 {
+		t.Errorf("a bot's command was dispatched: holds %v, observer %v", holds.handled, observer.observed)
+	}
+}
+
+func TestDuplicateSlashCommands(t *testing.T) {
+	app := &App{ModuleRegistry: NewModuleRegistry(), Logger: slog.Default()}
+	holds := &commandHandlerModule{name: "holds", commands: []string{"hold"}}
+	freeze := &commandHandlerModule{name: "freeze", commands: []string{"hold", "freeze"}}
+	app.RegisterModule(holds)
+	app.RegisterModule(freeze)
+
+	want := map[string]string{"hold": "holds", "freeze": "freeze"}
+	if got := app.ModuleRegistry.CommandHandlers(); !maps.Equal(got, want) {
+		t.Errorf("CommandHandlers() = %v, want %v", got, want)
+	}
+	event := &github.IssueCommentEvent{
+		Action:  github.Ptr("created"),
+		Repo:    &github.Repository{FullName: github.Ptr("org/repo")},
+		Issue:   &github.Issue{Number: github.Ptr(7)},
+		Comment: &github.IssueComment{Body: github.Ptr("/hold"), User: &github.User{Login: github.Ptr("alice")}},
+	}
+	// The module registered first handles the command every time, whatever the map order.
+	for range 10 {
+		app.handleEvent("delivery-1", "issue_comment", event, nil, nil)
+	}
+	if len(holds.handled) != 10 || len(freeze.handled) != 0 {
+		t.Errorf("holds handled %d, freeze handled %d; want 10 and 0", len(holds.handled), len(freeze.handled))
+	}
+}
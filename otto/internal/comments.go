@@ -0,0 +1,114 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// comments.go provides helpers for posting and maintaining bot comments on GitHub.
+
+package internal
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v71/github"
+)
+
+// SplitRepo splits an "owner/repo" full name into its parts.
+func SplitRepo(fullName string) (owner, repo string, err error) {
+	parts := strings.Split(fullName, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid repository format: %s, expected owner/repo", fullName)
+	}
+	return parts[0], parts[1], nil
+}
+
+// PostComment adds a plain comment to an issue or pull request.
+func PostComment(ctx context.Context, client *github.Client, repo string, number int, body string) error {
+	owner, name, err := SplitRepo(repo)
+	if err != nil {
+		return err
+	}
+	_, _, err = client.Issues.CreateComment(ctx, owner, name, number, &github.IssueComment{
+		Body: github.Ptr(body),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to post comment: %w", err)
+	}
+	return nil
+}
+
+// ManagedCommentMarker returns the hidden HTML marker that identifies a managed comment.
+func ManagedCommentMarker(key string) string {
+	return "<!-- otto:" + key + " -->"
+}
+
+// UpsertManagedComment keeps exactly one bot comment per key on an issue or pull request.
+// The first call creates the comment; later calls edit it in place instead of adding noise.
+func UpsertManagedComment(
+	ctx context.Context,
+	client *github.Client,
+	repo string,
+	number int,
+	key, body string,
+) (*github.IssueComment, error) {
+	owner, name, err := SplitRepo(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	marker := ManagedCommentMarker(key)
+	existing, err := FindManagedComment(ctx, client, repo, number, key)
+	if err != nil {
+		return nil, err
+	}
+
+	content := marker + "\n" + body
+	if existing != nil {
+		comment, _, err := client.Issues.EditComment(ctx, owner, name, existing.GetID(), &github.IssueComment{
+			Body: github.Ptr(content),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to update managed comment: %w", err)
+		}
+		return comment, nil
+	}
+
+	comment, _, err := client.Issues.CreateComment(ctx, owner, name, number, &github.IssueComment{
+		Body: github.Ptr(content),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create managed comment: %w", err)
+	}
+	return comment, nil
+}
+
+// FindManagedComment returns the managed comment for key, or nil if none exists yet.
+func FindManagedComment(
+	ctx context.Context,
+	client *github.Client,
+	repo string,
+	number int,
+	key string,
+) (*github.IssueComment, error) {
+	owner, name, err := SplitRepo(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	marker := ManagedCommentMarker(key)
+	opts := &github.IssueListCommentsOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		comments, resp, err := client.Issues.ListComments(ctx, owner, name, number, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list comments: %w", err)
+		}
+		for _, c := range comments {
+			if strings.HasPrefix(c.GetBody(), marker) {
+				return c, nil
+			}
+		}
+		if resp.NextPage == 0 {
+			return nil, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
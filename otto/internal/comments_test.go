@@ -0,0 +1,64 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v71/github"
+)
+
+func TestUpsertManagedComment(t *testing.T) {
+	var created, edited int
+	var comments []*github.IssueComment
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/org/repo/issues/7/comments", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(comments)
+	})
+	mux.HandleFunc("POST /repos/org/repo/issues/7/comments", func(w http.ResponseWriter, r *http.Request) {
+		var c github.IssueComment
+		_ = json.NewDecoder(r.Body).Decode(&c)
+		c.ID = github.Ptr(int64(99))
+		comments = append(comments, &github.IssueComment{ID: github.Ptr(int64(1)), Body: github.Ptr("unrelated")}, &c)
+		created++
+		_ = json.NewEncoder(w).Encode(c)
+	})
+	mux.HandleFunc("PATCH /repos/org/repo/issues/comments/99", func(w http.ResponseWriter, r *http.Request) {
+		var c github.IssueComment
+		_ = json.NewDecoder(r.Body).Decode(&c)
+		comments[1].Body = c.Body
+		edited++
+		_ = json.NewEncoder(w).Encode(comments[1])
+	})
+	client := TestGitHubClient(t, mux)
+
+	if _, err := UpsertManagedComment(t.Context(), client, "org/repo", 7, "status", "first"); err != nil {
+		t.Fatalf("first upsert failed: %v", err)
+	}
+	if _, err := UpsertManagedComment(t.Context(), client, "org/repo", 7, "status", "second"); err != nil {
+		t.Fatalf("second upsert failed: %v", err)
+	}
+
+	if created != 1 || edited != 1 {
+		t.Errorf("expected 1 create and 1 edit, got %d creates and %d edits", created, edited)
+	}
+	body := comments[1].GetBody()
+	if !strings.HasPrefix(body, ManagedCommentMarker("status")) || !strings.HasSuffix(body, "second") {
+		t.Errorf("unexpected managed comment body: %q", body)
+	}
+}
+
+func TestSplitRepo(t *testing.T) {
+	if owner, repo, err := SplitRepo("open-telemetry/otel-go"); err != nil || owner != "open-telemetry" || repo != "otel-go" {
+		t.Errorf("SplitRepo returned %q, %q, %v", owner, repo, err)
+	}
+	for _, bad := range []string{"", "noslash", "a/b/c", "/repo"} {
+		if _, _, err := SplitRepo(bad); err == nil {
+			t.Errorf("SplitRepo(%q) expected error", bad)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// concurrency.go bounds how many events each module handles at once, so work such as
+// merging a pull request or advancing a rotation never runs twice in parallel.
+
+package internal
+
+import (
+	"sync"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+)
+
+// ConcurrencyLimiter hands out per-module (or per-module and repository) slots to the
+// event dispatcher. Modules without a limit run unbounded.
+type ConcurrencyLimiter struct {
+	mu     sync.Mutex
+	limits map[string]config.ConcurrencyLimit
+	slots  map[string]chan struct{}
+}
+
+// NewConcurrencyLimiter creates a limiter from module -> limit.
+func NewConcurrencyLimiter(limits map[string]config.ConcurrencyLimit) *ConcurrencyLimiter {
+	return &ConcurrencyLimiter{
+		limits: limits,
+		slots:  make(map[string]chan struct{}),
+	}
+}
+
+// Acquire blocks until module may handle an event for repo and returns the function
+// that releases the slot. A nil limiter imposes no limits.
+func (l *ConcurrencyLimiter) Acquire(module, repo string) func() {
+	if l == nil {
+		return func() {}
+	}
+	limit, ok := l.limits[module]
+	if !ok {
+		return func() {}
+	}
+	key := module
+	if limit.PerRepo && repo != "" {
+		key = module + "/" + repo
+	}
+
+	l.mu.Lock()
+	slot, ok := l.slots[key]
+	if !ok {
+		slot = make(chan struct{}, max(limit.Max, 1))
+		l.slots[key] = slot
+	}
+	l.mu.Unlock()
+
+	slot <- struct{}{}
+	return func() { <-slot }
+}
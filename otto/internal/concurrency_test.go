@@ -0,0 +1,69 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+)
+
+func TestConcurrencyLimiter(t *testing.T) {
+	limiter := NewConcurrencyLimiter(map[string]config.ConcurrencyLimit{
+		"oncall":    {Max: 1},
+		"automerge": {PerRepo: true},
+		"sla":       {Max: 3},
+	})
+
+	tests := []struct {
+		name    string
+		module  string
+		repos   []string
+		wantMax int32
+	}{
+		{"module-wide limit", "oncall", []string{"org/a", "org/b", "org/a", "org/b"}, 1},
+		{"per-repo limit", "automerge", []string{"org/a", "org/a", "org/b", "org/b"}, 2},
+		{"higher limit", "sla", []string{"org/a", "org/a", "org/a", "org/a", "org/a", "org/a"}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var running, peak int32
+			var wg sync.WaitGroup
+			start := make(chan struct{})
+			for _, repo := range tt.repos {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					<-start
+					release := limiter.Acquire(tt.module, repo)
+					defer release()
+					n := atomic.AddInt32(&running, 1)
+					for {
+						p := atomic.LoadInt32(&peak)
+						if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+							break
+						}
+					}
+					time.Sleep(20 * time.Millisecond)
+					atomic.AddInt32(&running, -1)
+				}()
+			}
+			close(start)
+			wg.Wait()
+			if peak > tt.wantMax {
+				t.Errorf("peak concurrency = %d, want at most %d", peak, tt.wantMax)
+			}
+		})
+	}
+
+	// Modules without a limit, and a nil limiter, never block.
+	releaseA := limiter.Acquire("dependencies", "org/a")
+	releaseB := limiter.Acquire("dependencies", "org/a")
+	releaseA()
+	releaseB()
+	var nilLimiter *ConcurrencyLimiter
+	nilLimiter.Acquire("oncall", "org/a")()
+}
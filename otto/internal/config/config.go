@@ -4,19 +4,420 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
+	"net"
+	"net/url"
 	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
 // AppConfig contains non-secret application configuration.
 type AppConfig struct {
-	Port    string         `yaml:"port"`
-	DBPath  string         `yaml:"db_path"`
-	Log     map[string]any `yaml:"log"`
-	Modules map[string]any `yaml:"modules"`
+	Port          string                      `yaml:"port" doc:"webhook listen port"`
+	Admin         AdminConfig                 `yaml:"admin" doc:"separate listener of the admin API and health checks"`
+	InstanceID    string                      `yaml:"instance_id" doc:"identifies this replica; default: hostname"`
+	DBPath        string                      `yaml:"db_path" doc:"SQLite database file"`
+	DBMaintenance DBMaintenanceConfig         `yaml:"db_maintenance" doc:"scheduled integrity check, VACUUM and ANALYZE"`
+	Migrations    MigrationsConfig            `yaml:"migrations" doc:"schema migrations of the database"`
+	EventPayloads EventPayloadsConfig         `yaml:"event_payloads" doc:"bucket large webhook payloads are kept in"`
+	Log           map[string]any              `yaml:"log" doc:"log settings, e.g. level and format"`
+	Telemetry     TelemetryConfig             `yaml:"telemetry" doc:"exporters of traces, metrics and logs"`
+	LogStream     LogStreamConfig             `yaml:"log_stream" doc:"recent logs kept in memory for the admin API"`
+	APIBudgets    map[string]int              `yaml:"api_budgets" doc:"module -> GitHub API calls per hour"`
+	APIUsage      APIUsageConfig              `yaml:"api_usage" doc:"record of the GitHub API endpoints Otto calls"`
+	Pacing        PacingConfig                `yaml:"pacing" doc:"rate at which background work writes to GitHub"`
+	ContentFilter ContentFilterConfig         `yaml:"content_filter" doc:"secrets and banned phrases kept out of posts"`
+	Concurrency   map[string]ConcurrencyLimit `yaml:"concurrency" doc:"module -> concurrent event handlers"`
+	Dispatch      DispatchConfig              `yaml:"dispatch" doc:"worker pool handing events to modules"`
+	EventQueue    EventQueueConfig            `yaml:"event_queue" doc:"webhook deliveries kept until handled"`
+	Watchdog      WatchdogConfig              `yaml:"watchdog" doc:"reports module handlers that run too long"`
+	Debug         DebugConfig                 `yaml:"debug" doc:"pprof and expvar endpoints"`
+	Probe         ProbeConfig                 `yaml:"probe" doc:"synthetic end-to-end probe of the webhook pipeline"`
+	GitHubStatus  GitHubStatusConfig          `yaml:"github_status" doc:"polling of the GitHub status page"`
+	RateLimit     RateLimitConfig             `yaml:"rate_limit" doc:"GitHub rate limit tracking and retries"`
+	Permissions   PermissionsConfig           `yaml:"permissions" doc:"check of the GitHub App permissions modules need"`
+	Rollups       RollupsConfig               `yaml:"rollups" doc:"daily rollups of key metrics kept for long-term trends"`
+	Decisions     DecisionsConfig             `yaml:"decisions" doc:"log of why modules acted or not on events"`
+	Shadow        ShadowConfig                `yaml:"shadow" doc:"modules whose GitHub writes are recorded, not made"`
+	Notifications NotificationsConfig         `yaml:"notifications" doc:"notification channels and routes"`
+	Errors        ErrorReportingConfig        `yaml:"error_reporting" doc:"reporting of panics and module errors"`
+	Outbox        OutboxConfig                `yaml:"outbox" doc:"queue of GitHub actions carried out in the background"`
+	ActionsAPI    ActionsAPIConfig            `yaml:"actions_api" doc:"clients of the /api/v1/actions endpoint"`
+	SelfUpdate    SelfUpdateConfig            `yaml:"self_update" doc:"check for newer Otto releases"`
+	FeatureFlags  FeatureFlagsConfig          `yaml:"feature_flags" doc:"OpenFeature provider of feature flags"`
+	HTTP          HTTPConfig                  `yaml:"http" doc:"outbound requests"`
+	Webhooks      []WebhookEndpoint           `yaml:"webhooks" doc:"webhook endpoints; default: /webhook for GitHub"`
+	StrictParse   bool                        `yaml:"strict_parse" doc:"report payload fields go-github does not parse"`
+	Commands      CommandsConfig              `yaml:"commands" doc:"slash command aliases and disabled commands"`
+	FileClasses   FileClassesConfig           `yaml:"file_classes" doc:"generated, vendored and docs file patterns"`
+	Cache         CacheConfig                 `yaml:"cache" doc:"cache backend"`
+	RepoGroups    map[string][]string         `yaml:"repo_groups" doc:"name -> repos or patterns, listed as @name"`
+	Modules       map[string]any              `yaml:"modules" doc:"module name -> module settings"`
+}
+
+// Telemetry exporters, selected per signal.
+const (
+	ExporterOTLPHTTP = "otlp-http"
+	ExporterOTLPGRPC = "otlp-grpc"
+	ExporterStdout   = "stdout"
+	ExporterNone     = "none"
+)
+
+// TelemetryConfig selects where traces, metrics and logs are exported, so Otto can run
+// without a collector. OTLP exporters are configured by the standard OTEL_EXPORTER_OTLP_*
+// environment variables, e.g. OTEL_EXPORTER_OTLP_ENDPOINT.
+type TelemetryConfig struct {
+	Traces      string   `yaml:"traces" doc:"trace exporter: otlp-http, otlp-grpc, stdout or none"`
+	Metrics     string   `yaml:"metrics" doc:"metric exporter: otlp-http, otlp-grpc, stdout or none"`
+	Logs        string   `yaml:"logs" doc:"log exporter: otlp-http, otlp-grpc, stdout or none (logs to stderr)"`
+	SampleRatio *float64 `yaml:"sample_ratio" doc:"fraction of traces sampled, from 0 to 1"`
+}
+
+// LogStreamConfig controls the in-memory buffer of recent log records that the admin API
+// serves at /admin/logs and streams at /admin/logs/stream.
+type LogStreamConfig struct {
+	Enabled *bool  `yaml:"enabled" doc:"keep recent logs for the admin API"`
+	Buffer  int    `yaml:"buffer" doc:"log records kept"`
+	Level   string `yaml:"level" doc:"lowest level kept: debug, info, warn or error"`
+}
+
+// ShadowConfig selects modules that run in shadow mode, e.g. while a new module version
+// is validated: on their shadow repositories, their GitHub API writes are recorded instead
+// of made. Their writes elsewhere are made and recorded, for the comparison report.
+type ShadowConfig struct {
+	Modules   map[string][]string `yaml:"modules" doc:"module -> repositories it runs in shadow on, e.g. org/*"`
+	Retention time.Duration       `yaml:"retention" doc:"how long recorded actions are kept"`
+}
+
+// DispatchConfig sizes the worker pool that hands events to modules, with a queue for
+// each priority: interactive (slash commands), normal and background.
+type DispatchConfig struct {
+	Workers          int      `yaml:"workers" doc:"events handled at once"`
+	QueueSize        int      `yaml:"queue_size" doc:"events waiting per priority before webhooks are held"`
+	BackgroundEvents []string `yaml:"background_events" doc:"event types handled after all others"`
+	StarvationLimit  int      `yaml:"starvation_limit" doc:"times a waiting priority is passed over before it goes next"`
+	// DrainTimeout bounds how long shutdown waits for the events already received.
+	DrainTimeout time.Duration `yaml:"drain_timeout" doc:"how long shutdown waits for queued and running events"`
+}
+
+// EventQueueConfig controls the queue webhook deliveries are persisted in until the modules
+// have handled them. Modules that fail to handle a delivery are handed it again with
+// exponential backoff, and deliveries in flight when Otto stops are handled after a restart.
+type EventQueueConfig struct {
+	Enabled     *bool         `yaml:"enabled" doc:"persist deliveries and retry failed modules"`
+	MaxAttempts int           `yaml:"max_attempts" doc:"attempts before a delivery is given up"`
+	Backoff     time.Duration `yaml:"backoff" doc:"wait before the first retry, doubled for each further one"`
+	MaxBackoff  time.Duration `yaml:"max_backoff" doc:"longest wait between retries"`
+	Interval    time.Duration `yaml:"interval" doc:"how often deliveries due for a retry are looked for"`
+	Retention   time.Duration `yaml:"retention" doc:"how long handled and given-up deliveries are kept"`
+}
+
+// WatchdogConfig controls the watchdog that reports module handlers running too long.
+type WatchdogConfig struct {
+	Enabled    *bool                    `yaml:"enabled" doc:"run the watchdog"`
+	Deadline   time.Duration            `yaml:"deadline" doc:"how long a handler is expected to run at most"`
+	Deadlines  map[string]time.Duration `yaml:"deadlines" doc:"module -> deadline, overriding deadline"`
+	DumpFactor int                      `yaml:"dump_factor" doc:"goroutine stacks are logged after this many deadlines"`
+	Interval   time.Duration            `yaml:"interval" doc:"how often running handlers are checked"`
+}
+
+// AdminConfig moves the admin API, health checks and debug endpoints off the webhook port, and
+// serves /healthz and /metrics on its listener.
+type AdminConfig struct {
+	Addr  string           `yaml:"addr" doc:"listen address, e.g. localhost:9090; empty serves them on port"`
+	Login AdminLoginConfig `yaml:"login" doc:"sign-in of people to the admin API with GitHub or OIDC"`
+}
+
+// Sign-in providers of the admin API.
+const (
+	LoginProviderGitHub = "github"
+	LoginProviderOIDC   = "oidc"
+)
+
+// Roles of people signed in to the admin API. Viewers may only make GET requests.
+const (
+	AdminRoleAdmin  = "admin"
+	AdminRoleViewer = "viewer"
+)
+
+// AdminLoginConfig lets people sign in to the admin API with GitHub OAuth or an OIDC
+// provider instead of sharing the admin token, which machine clients keep using. Their
+// role comes from the GitHub teams they are in; people in none of the teams are refused.
+// OIDC users' GitHub logins are trusted as the provider states them, so they come from
+// LoginClaim only if the provider guarantees the claim holds the person's GitHub login,
+// and otherwise from Logins, mapping the provider's subjects explicitly.
+type AdminLoginConfig struct {
+	Provider    string            `yaml:"provider" doc:"github or oidc; empty disables sign-in"`
+	ClientID    string            `yaml:"client_id" doc:"OAuth client ID; its secret is admin_login_client_secret"`
+	Issuer      string            `yaml:"issuer" doc:"OIDC issuer URL, for provider oidc"`
+	LoginClaim  string            `yaml:"login_claim" doc:"OIDC claim the provider sets to the GitHub login"`
+	Logins      map[string]string `yaml:"logins" doc:"OIDC subject -> GitHub login, instead of login_claim"`
+	RedirectURL string            `yaml:"redirect_url" doc:"public URL of /admin/login/callback"`
+	Teams       map[string]string `yaml:"teams" doc:"org/team -> role: admin or viewer"`
+	SessionTTL  time.Duration     `yaml:"session_ttl" doc:"how long a sign-in lasts"`
+}
+
+// DebugConfig controls the pprof and expvar endpoints, served with the admin token on
+// the admin listener, or on their own one rather than the webhook port.
+type DebugConfig struct {
+	Enabled *bool  `yaml:"enabled" doc:"serve the debug endpoints"`
+	Addr    string `yaml:"addr" doc:"listen address without admin.addr, e.g. localhost:6060"`
+}
+
+// ProbeConfig controls the synthetic probe that posts a signed event to Otto's own webhook
+// endpoint and checks that it reaches dispatch within the SLO.
+type ProbeConfig struct {
+	Enabled  *bool         `yaml:"enabled" doc:"run the probe"`
+	Interval time.Duration `yaml:"interval" doc:"how often the probe runs"`
+	SLO      time.Duration `yaml:"slo" doc:"how long the event may take to reach the canary module"`
+	Path     string        `yaml:"path" doc:"GitHub webhook endpoint probed; default: the first one"`
+}
+
+// PacingConfig controls how GitHub writes made by background work are spread out, so
+// bursts do not trip GitHub's secondary rate limit.
+type PacingConfig struct {
+	Enabled            *bool         `yaml:"enabled" doc:"pace GitHub writes"`
+	MutationsPerMinute int           `yaml:"mutations_per_minute" doc:"steady rate of GitHub writes"`
+	Burst              int           `yaml:"burst" doc:"writes allowed at once before pacing starts"`
+	Jitter             time.Duration `yaml:"jitter" doc:"up to this much random delay is added to paced writes"`
+}
+
+// APIUsageConfig controls the record of GitHub API calls per day, module and endpoint,
+// which `otto permissions report` derives the GitHub App permissions needed from.
+type APIUsageConfig struct {
+	Enabled   *bool         `yaml:"enabled" doc:"record GitHub API calls"`
+	Retention time.Duration `yaml:"retention" doc:"how long the record is kept"`
+}
+
+// ContentFilterConfig controls the check of the text Otto posts, on GitHub and in
+// notifications, for secrets and banned phrases. A post that fails it is blocked and the
+// operators are alerted.
+type ContentFilterConfig struct {
+	Enabled       *bool             `yaml:"enabled" doc:"block posts containing secrets or banned phrases"`
+	Patterns      map[string]string `yaml:"patterns" doc:"name -> regular expression of further secrets"`
+	BannedPhrases []string          `yaml:"banned_phrases" doc:"phrases never posted, matched regardless of case"`
+}
+
+// ErrorReportingConfig controls reporting of panics and module errors to Sentry, which is
+// enabled by the sentry_dsn secret.
+type ErrorReportingConfig struct {
+	Environment string `yaml:"environment" doc:"environment errors are tagged with, e.g. production"`
+}
+
+// GitHubStatusConfig controls polling of the GitHub status page.
+type GitHubStatusConfig struct {
+	Enabled  *bool         `yaml:"enabled" doc:"poll the status page"`
+	URL      string        `yaml:"url" doc:"Statuspage summary.json"`
+	Interval time.Duration `yaml:"interval" doc:"how often the status page is polled"`
+}
+
+// RateLimitConfig controls how Otto follows GitHub's rate limits: requests hit by a
+// secondary rate limit are retried, and bulk work waits when little quota is left.
+type RateLimitConfig struct {
+	MaxRetries int           `yaml:"max_retries" doc:"retries of a request hit by a secondary rate limit"`
+	Backoff    time.Duration `yaml:"backoff" doc:"first wait without a Retry-After header; doubles per retry"`
+	MaxWait    time.Duration `yaml:"max_wait" doc:"longest wait before a retry; a longer Retry-After fails the request"`
+	Reserve    int           `yaml:"reserve" doc:"calls left in the hour at which bulk work waits for the reset"`
+}
+
+// PermissionsConfig controls the check that the GitHub App installation grants the
+// permissions modules need.
+type PermissionsConfig struct {
+	Enabled  *bool         `yaml:"enabled" doc:"check at startup and on the interval"`
+	Interval time.Duration `yaml:"interval" doc:"how often permissions are checked again"`
+}
+
+// RollupsConfig controls the daily rollups of key metrics stored in the database.
+type RollupsConfig struct {
+	Enabled  *bool         `yaml:"enabled" doc:"roll up each complete day"`
+	Interval time.Duration `yaml:"interval" doc:"how often complete days are looked for"`
+	Backfill int           `yaml:"backfill" doc:"days rolled up from stored events on the first run"`
+}
+
+// DecisionsConfig controls the database log of module decisions. Decisions are always added
+// to the module handler spans; the log keeps them beyond the traces' retention.
+type DecisionsConfig struct {
+	Record    *bool         `yaml:"record" doc:"store decisions in the database"`
+	Retention time.Duration `yaml:"retention" doc:"how long stored decisions are kept"`
+}
+
+// OutboxConfig controls how queued GitHub actions are carried out.
+type OutboxConfig struct {
+	Interval    time.Duration `yaml:"interval" doc:"how often queued actions are carried out"`
+	MaxAttempts int           `yaml:"max_attempts" doc:"attempts before an action fails"`
+}
+
+// ActionsAPIConfig names the trusted systems that may queue actions on /api/v1/actions.
+// The endpoint is not served without clients.
+type ActionsAPIConfig struct {
+	Clients map[string]ActionsAPIClient `yaml:"clients" doc:"client name -> credentials and permissions"`
+}
+
+// ActionsAPIClient is a system that authenticates with the bearer token in a named secret.
+type ActionsAPIClient struct {
+	Secret  string   `yaml:"secret" doc:"named secret holding the client's bearer token"`
+	Repos   []string `yaml:"repos" doc:"repositories the client may act on; empty means all"`
+	Actions []string `yaml:"actions" doc:"comment, label, unlabel or create_issue; empty means all"`
+}
+
+// SelfUpdateConfig controls the check for newer Otto releases.
+type SelfUpdateConfig struct {
+	Enabled    *bool         `yaml:"enabled" doc:"check for releases"`
+	Repo       string        `yaml:"repo" doc:"repository publishing Otto releases"`
+	TagPrefix  string        `yaml:"tag_prefix" doc:"release tags are prefix + version, e.g. otto/v0.4.0"`
+	Interval   time.Duration `yaml:"interval" doc:"how often releases are checked"`
+	MaxBehind  int           `yaml:"max_behind" doc:"releases an instance may lag before operators are notified"`
+	Highlights int           `yaml:"highlights" doc:"changelog lines included per missed release"`
+}
+
+// FeatureFlagsConfig selects the OpenFeature provider that evaluates feature flags.
+type FeatureFlagsConfig struct {
+	Provider string        `yaml:"provider" doc:"database, ofrep or none"`
+	URL      string        `yaml:"url" doc:"OFREP base URL of the org's flag system"`
+	Timeout  time.Duration `yaml:"timeout" doc:"per-evaluation timeout for remote providers"`
+}
+
+// WebhookEndpoint is a path that receives webhook deliveries signed with its own secret.
+type WebhookEndpoint struct {
+	Path   string `yaml:"path" doc:"e.g. /webhook/github"`
+	Source string `yaml:"source" doc:"github or gitlab"`
+	Secret string `yaml:"secret" doc:"named secret verifying deliveries; default: the webhook secret"`
+}
+
+// CommandsConfig renames and disables slash commands in all repositories, with
+// per-repository additions.
+type CommandsConfig struct {
+	CommandSettings `yaml:",inline"`
+	Repos           map[string]CommandSettings `yaml:"repos" doc:"owner/name -> settings"`
+}
+
+// CommandSettings renames and disables slash commands.
+type CommandSettings struct {
+	Aliases  map[string]string `yaml:"aliases" doc:"alias -> command, optionally with arguments, e.g. lgtm: approve"`
+	Disabled []string          `yaml:"disabled" doc:"command names that are ignored"`
+}
+
+// FileClassesConfig classifies changed files as generated, vendored or documentation, so
+// modules can leave them out. Patterns follow .gitattributes syntax.
+type FileClassesConfig struct {
+	Generated     []string      `yaml:"generated" doc:"patterns of generated files"`
+	Vendored      []string      `yaml:"vendored" doc:"patterns of vendored files"`
+	Docs          []string      `yaml:"docs" doc:"patterns of documentation files"`
+	GitAttributes *bool         `yaml:"gitattributes" doc:"apply linguist attributes from repositories' .gitattributes"`
+	CacheTTL      time.Duration `yaml:"cache_ttl" doc:"how long a repository's .gitattributes is reused"`
+}
+
+// CacheConfig selects where lookups such as team members and repository files are cached.
+type CacheConfig struct {
+	Backend string      `yaml:"backend" doc:"memory or redis"`
+	Redis   RedisConfig `yaml:"redis" doc:"settings of the redis backend"`
+}
+
+// RedisConfig connects to the Redis server of the redis cache backend, which replicas
+// share. The password is the redis_password secret.
+type RedisConfig struct {
+	Addr      string        `yaml:"addr" doc:"host:port"`
+	DB        int           `yaml:"db" doc:"database number"`
+	KeyPrefix string        `yaml:"key_prefix" doc:"prepended to every key"`
+	TLS       bool          `yaml:"tls" doc:"connect over TLS"`
+	Timeout   time.Duration `yaml:"timeout" doc:"per command, including connecting"`
+	PoolSize  int           `yaml:"pool_size" doc:"idle connections kept open"`
+}
+
+// EventPayloadsConfig offloads webhook payloads above a size from the events table to an
+// S3 or GCS bucket; the database keeps the event's metadata and the object's key, and
+// payloads are read back transparently, e.g. on replay. The credentials are the
+// event_payloads_access_key_id and event_payloads_secret_access_key secrets: an AWS access
+// key, or an HMAC key of a GCS service account.
+type EventPayloadsConfig struct {
+	Backend      string        `yaml:"backend" doc:"s3 or gcs; default: payloads stay in the database"`
+	Bucket       string        `yaml:"bucket" doc:"bucket the payloads are written to"`
+	Prefix       string        `yaml:"prefix" doc:"prepended to every object key"`
+	Region       string        `yaml:"region" doc:"region of the bucket; default: us-east-1 for s3, auto for gcs"`
+	Endpoint     string        `yaml:"endpoint" doc:"base URL, e.g. of an S3-compatible store; default: the backend's"`
+	OffloadAbove int           `yaml:"offload_above" doc:"payloads larger than this many bytes are offloaded"`
+	Timeout      time.Duration `yaml:"timeout" doc:"per request to the object store"`
+}
+
+// HTTPConfig configures outbound HTTP requests: the GitHub API, OTLP exporters, Slack and
+// other integrations.
+type HTTPConfig struct {
+	ProxyURL      string `yaml:"proxy_url" doc:"default: HTTPS_PROXY and HTTP_PROXY from the environment"`
+	NoProxy       string `yaml:"no_proxy" doc:"comma-separated hosts that bypass proxy_url, like NO_PROXY"`
+	CAFile        string `yaml:"ca_file" doc:"PEM bundle trusted in addition to the system roots"`
+	TLSMinVersion string `yaml:"tls_min_version" doc:"1.2 or 1.3"`
+}
+
+// NotificationsConfig names notification channels and routes notifications to them.
+type NotificationsConfig struct {
+	Channels   map[string]NotificationChannel `yaml:"channels" doc:"channel name -> destination"`
+	Routes     []NotificationRoute            `yaml:"routes" doc:"routes matching notifications to channels"`
+	Retries    int                            `yaml:"retries" doc:"extra attempts per channel"`
+	SMTP       SMTPConfig                     `yaml:"smtp" doc:"mail server of the email backend"`
+	QuietHours QuietHoursConfig               `yaml:"quiet_hours" doc:"hours in which non-urgent notifications are held"`
+}
+
+// QuietHoursConfig holds notifications below critical severity during quiet hours and
+// delivers them as one summary per channel when quiet hours end.
+type QuietHoursConfig struct {
+	QuietHoursWindow `yaml:",inline"`
+	Repos            map[string]QuietHoursWindow `yaml:"repos" doc:"owner/name -> window replacing the default"`
+	Interval         time.Duration               `yaml:"interval" doc:"how often held notifications are released"`
+}
+
+// QuietHoursWindow is a daily period of quiet hours. An empty window has no quiet hours.
+type QuietHoursWindow struct {
+	Start    string `yaml:"start" doc:"HH:MM, e.g. 22:00"`
+	End      string `yaml:"end" doc:"HH:MM, e.g. 08:00; before start to span midnight"`
+	Timezone string `yaml:"timezone" doc:"IANA zone, e.g. 'Europe/Berlin'; default: UTC"`
+}
+
+// NotificationChannel is a destination on one notifier backend.
+type NotificationChannel struct {
+	Backend string `yaml:"backend" doc:"slack, email, webhook or github"`
+	Target  string `yaml:"target" doc:"Slack channel, email address, URL, or owner/repo#issue"`
+}
+
+// NotificationRoute sends notifications matching all of its filters to its channels.
+// Empty filters match everything.
+type NotificationRoute struct {
+	Severity string   `yaml:"severity" doc:"minimum severity: info, warning or critical"`
+	Modules  []string `yaml:"modules" doc:"modules whose notifications match"`
+	Repos    []string `yaml:"repos" doc:"repositories whose notifications match"`
+	Channels []string `yaml:"channels" doc:"channel names the notifications are sent to"`
+}
+
+// SMTPConfig configures the email notifier. The password is the smtp_password secret.
+type SMTPConfig struct {
+	Addr     string `yaml:"addr" doc:"host:port"`
+	From     string `yaml:"from" doc:"sender address"`
+	Username string `yaml:"username" doc:"login, if the server requires one"`
+}
+
+// ConcurrencyLimit bounds how many events a module handles at once.
+type ConcurrencyLimit struct {
+	Max     int  `yaml:"max" doc:"concurrent handlers; values below 1 mean 1"`
+	PerRepo bool `yaml:"per_repo" doc:"apply the limit to each repository separately"`
+}
+
+// DBMaintenanceConfig controls the scheduled database maintenance job.
+type DBMaintenanceConfig struct {
+	Enabled  *bool         `yaml:"enabled" doc:"run the maintenance job"`
+	Interval time.Duration `yaml:"interval" doc:"how often the job runs"`
+	Vacuum   *bool         `yaml:"vacuum" doc:"reclaim free pages with VACUUM"`
+	Analyze  *bool         `yaml:"analyze" doc:"refresh query planner statistics with ANALYZE"`
+}
+
+// MigrationsConfig controls how the database schema is migrated.
+type MigrationsConfig struct {
+	RefusePending bool `yaml:"refuse_pending" doc:"refuse to start while migrations are pending, applied out-of-band"`
 }
 
 // Load reads YAML config from path and returns an AppConfig.
@@ -40,6 +441,9 @@ func LoadFromFile(path string) (*AppConfig, error) {
 
 	// Apply defaults
 	ApplyDefaults(config)
+	if err := Validate(config); err != nil {
+		return nil, err
+	}
 
 	// Log configuration summary
 	LogSummary(config)
@@ -49,7 +453,168 @@ func LoadFromFile(path string) (*AppConfig, error) {
 
 // Validate checks that all required config fields are present and valid.
 func Validate(config *AppConfig) error {
-	// No required fields in non-secret config
+	paths := make(map[string]bool)
+	for _, endpoint := range config.Webhooks {
+		if !strings.HasPrefix(endpoint.Path, "/") {
+			return fmt.Errorf("webhooks: path %q must start with /", endpoint.Path)
+		}
+		if paths[endpoint.Path] {
+			return fmt.Errorf("webhooks: path %q is configured twice", endpoint.Path)
+		}
+		paths[endpoint.Path] = true
+		if endpoint.Source != "github" && endpoint.Source != "gitlab" {
+			return fmt.Errorf("webhooks: unsupported source %q for %s", endpoint.Source, endpoint.Path)
+		}
+	}
+	if config.Probe.Enabled != nil && *config.Probe.Enabled {
+		if config.Probe.SLO <= 0 || config.Probe.SLO >= config.Probe.Interval {
+			return fmt.Errorf("probe: slo %s must be positive and shorter than interval %s",
+				config.Probe.SLO, config.Probe.Interval)
+		}
+		if !slices.ContainsFunc(config.Webhooks, func(e WebhookEndpoint) bool {
+			return e.Path == config.Probe.Path && e.Source == "github"
+		}) {
+			return fmt.Errorf("probe: path %q is not a GitHub webhook endpoint", config.Probe.Path)
+		}
+	}
+	if config.Admin.Addr != "" {
+		_, port, err := net.SplitHostPort(config.Admin.Addr)
+		if err != nil {
+			return fmt.Errorf("admin: invalid addr %q: %w", config.Admin.Addr, err)
+		}
+		if port == config.Port {
+			return fmt.Errorf("admin: addr %q uses the webhook port", config.Admin.Addr)
+		}
+	}
+	if err := validateAdminLogin(config.Admin.Login); err != nil {
+		return fmt.Errorf("admin: login: %w", err)
+	}
+	if config.Pacing.MutationsPerMinute < 0 || config.Pacing.Burst < 0 || config.Pacing.Jitter < 0 {
+		return fmt.Errorf("pacing: mutations_per_minute, burst and jitter must not be negative")
+	}
+	for signal, exporter := range map[string]string{
+		"traces":  config.Telemetry.Traces,
+		"metrics": config.Telemetry.Metrics,
+		"logs":    config.Telemetry.Logs,
+	} {
+		switch exporter {
+		case "", ExporterOTLPHTTP, ExporterOTLPGRPC, ExporterStdout, ExporterNone:
+		default:
+			return fmt.Errorf("telemetry: unsupported %s exporter %q", signal, exporter)
+		}
+	}
+	if r := config.Telemetry.SampleRatio; r != nil && (*r < 0 || *r > 1) {
+		return fmt.Errorf("telemetry: sample_ratio %v must be between 0 and 1", *r)
+	}
+	if r := config.RateLimit; r.MaxRetries < 0 || r.Backoff < 0 || r.MaxWait < 0 || r.Reserve < 0 {
+		return fmt.Errorf("rate_limit: max_retries, backoff, max_wait and reserve must not be negative")
+	}
+	for name, pattern := range config.ContentFilter.Patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("content_filter: pattern %s: %w", name, err)
+		}
+	}
+	switch strings.ToLower(config.LogStream.Level) {
+	case "", "debug", "info", "warn", "error":
+	default:
+		return fmt.Errorf("log_stream: unsupported level %q", config.LogStream.Level)
+	}
+	switch config.Cache.Backend {
+	case "", "memory":
+	case "redis":
+		if config.Cache.Redis.Addr == "" {
+			return errors.New("cache: redis.addr is required for the redis backend")
+		}
+	default:
+		return fmt.Errorf("cache: unsupported backend %q", config.Cache.Backend)
+	}
+	for name, section := range config.Modules {
+		settings, ok := section.(map[string]any)
+		if !ok {
+			continue
+		}
+		if enabled, ok := settings["enabled"]; ok {
+			if _, ok := enabled.(bool); !ok {
+				return fmt.Errorf("modules: %s: enabled must be true or false, got %v", name, enabled)
+			}
+		}
+		if route, ok := settings["route"]; ok {
+			if _, ok := route.(map[string]any); !ok {
+				return fmt.Errorf("modules: %s: route must be a mapping of repos and orgs, got %v", name, route)
+			}
+		}
+	}
+	switch payloads := config.EventPayloads; payloads.Backend {
+	case "":
+	case "s3", "gcs":
+		if payloads.Bucket == "" {
+			return fmt.Errorf("event_payloads: bucket is required for the %s backend", payloads.Backend)
+		}
+		if payloads.Endpoint != "" {
+			if u, err := url.Parse(payloads.Endpoint); err != nil || u.Scheme == "" || u.Host == "" {
+				return fmt.Errorf("event_payloads: invalid endpoint %q", payloads.Endpoint)
+			}
+		}
+		if payloads.OffloadAbove < 0 {
+			return fmt.Errorf("event_payloads: offload_above must not be negative, got %d", payloads.OffloadAbove)
+		}
+	default:
+		return fmt.Errorf("event_payloads: unsupported backend %q", payloads.Backend)
+	}
+	return nil
+}
+
+// userChosenClaims are standard OIDC claims people can usually set to anything, such as
+// someone else's GitHub login, so they cannot be the login_claim.
+var userChosenClaims = map[string]bool{
+	"preferred_username": true,
+	"nickname":           true,
+	"name":               true,
+	"email":              true,
+}
+
+// validateAdminLogin checks the sign-in settings of the admin API, if sign-in is enabled.
+func validateAdminLogin(login AdminLoginConfig) error {
+	switch login.Provider {
+	case "":
+		return nil
+	case LoginProviderGitHub:
+	case LoginProviderOIDC:
+		if u, err := url.Parse(login.Issuer); err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("invalid issuer %q", login.Issuer)
+		}
+		switch {
+		case login.LoginClaim != "" && len(login.Logins) > 0:
+			return errors.New("set login_claim or logins, not both")
+		case login.LoginClaim == "" && len(login.Logins) == 0:
+			return errors.New("login_claim or logins is required to tell the GitHub login of people")
+		case userChosenClaims[login.LoginClaim]:
+			return fmt.Errorf("login_claim %q is chosen by people themselves at most providers; "+
+				"use a claim the provider sets to the GitHub login, or logins", login.LoginClaim)
+		}
+	default:
+		return fmt.Errorf("unsupported provider %q", login.Provider)
+	}
+	if login.ClientID == "" {
+		return errors.New("client_id is required")
+	}
+	if u, err := url.Parse(login.RedirectURL); err != nil || u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("invalid redirect_url %q", login.RedirectURL)
+	}
+	if len(login.Teams) == 0 {
+		return errors.New("teams must map at least one org/team to a role")
+	}
+	for team, role := range login.Teams {
+		if org, slug, ok := strings.Cut(team, "/"); !ok || org == "" || slug == "" {
+			return fmt.Errorf("team %q must be org/team", team)
+		}
+		if role != AdminRoleAdmin && role != AdminRoleViewer {
+			return fmt.Errorf("team %s: role must be admin or viewer, got %q", team, role)
+		}
+	}
+	if login.SessionTTL < 0 {
+		return fmt.Errorf("session_ttl must not be negative, got %s", login.SessionTTL)
+	}
 	return nil
 }
 
@@ -63,6 +628,313 @@ func ApplyDefaults(config *AppConfig) {
 		config.DBPath = "data.db"
 	}
 
+	if config.InstanceID == "" {
+		config.InstanceID = GetEnvOrDefault("OTTO_INSTANCE_ID", "")
+	}
+	if config.InstanceID == "" {
+		if hostname, err := os.Hostname(); err == nil && hostname != "" {
+			config.InstanceID = hostname
+		} else {
+			config.InstanceID = "otto"
+		}
+	}
+
+	for _, exporter := range []*string{&config.Telemetry.Traces, &config.Telemetry.Metrics, &config.Telemetry.Logs} {
+		if *exporter == "" {
+			*exporter = ExporterOTLPHTTP
+		}
+	}
+	if config.Telemetry.SampleRatio == nil {
+		ratio := 1.0
+		config.Telemetry.SampleRatio = &ratio
+	}
+
+	if config.Admin.Login.Provider != "" {
+		if config.Admin.Login.SessionTTL == 0 {
+			config.Admin.Login.SessionTTL = 12 * time.Hour
+		}
+	}
+
+	if config.LogStream.Enabled == nil {
+		config.LogStream.Enabled = boolPtr(true)
+	}
+	if config.LogStream.Buffer == 0 {
+		config.LogStream.Buffer = 1000
+	}
+	if config.LogStream.Level == "" {
+		config.LogStream.Level = "info"
+	}
+
+	if config.DBMaintenance.Enabled == nil {
+		config.DBMaintenance.Enabled = boolPtr(true)
+	}
+	if config.DBMaintenance.Interval == 0 {
+		config.DBMaintenance.Interval = 24 * time.Hour
+	}
+	if config.DBMaintenance.Vacuum == nil {
+		config.DBMaintenance.Vacuum = boolPtr(true)
+	}
+	if config.DBMaintenance.Analyze == nil {
+		config.DBMaintenance.Analyze = boolPtr(true)
+	}
+
+	if config.Pacing.Enabled == nil {
+		config.Pacing.Enabled = boolPtr(true)
+	}
+	if config.Pacing.MutationsPerMinute == 0 {
+		config.Pacing.MutationsPerMinute = 60
+	}
+	if config.Pacing.Burst == 0 {
+		config.Pacing.Burst = 10
+	}
+	if config.Pacing.Jitter == 0 {
+		config.Pacing.Jitter = time.Second
+	}
+
+	if config.APIUsage.Enabled == nil {
+		config.APIUsage.Enabled = boolPtr(true)
+	}
+	if config.APIUsage.Retention == 0 {
+		config.APIUsage.Retention = 90 * 24 * time.Hour
+	}
+
+	if config.ContentFilter.Enabled == nil {
+		config.ContentFilter.Enabled = boolPtr(true)
+	}
+
+	if config.GitHubStatus.Enabled == nil {
+		config.GitHubStatus.Enabled = boolPtr(false)
+	}
+	if config.GitHubStatus.URL == "" {
+		config.GitHubStatus.URL = "https://www.githubstatus.com/api/v2/summary.json"
+	}
+	if config.GitHubStatus.Interval == 0 {
+		config.GitHubStatus.Interval = time.Minute
+	}
+
+	if config.RateLimit.MaxRetries == 0 {
+		config.RateLimit.MaxRetries = 3
+	}
+	if config.RateLimit.Backoff == 0 {
+		config.RateLimit.Backoff = time.Minute
+	}
+	if config.RateLimit.MaxWait == 0 {
+		config.RateLimit.MaxWait = 5 * time.Minute
+	}
+	if config.RateLimit.Reserve == 0 {
+		config.RateLimit.Reserve = 200
+	}
+
+	if config.Permissions.Enabled == nil {
+		config.Permissions.Enabled = boolPtr(true)
+	}
+	if config.Permissions.Interval == 0 {
+		config.Permissions.Interval = 6 * time.Hour
+	}
+
+	if config.Rollups.Enabled == nil {
+		config.Rollups.Enabled = boolPtr(true)
+	}
+	if config.Rollups.Interval == 0 {
+		config.Rollups.Interval = time.Hour
+	}
+	if config.Rollups.Backfill == 0 {
+		config.Rollups.Backfill = 30
+	}
+
+	if config.Notifications.Retries == 0 {
+		config.Notifications.Retries = 2
+	}
+	if config.Notifications.QuietHours.Interval == 0 {
+		config.Notifications.QuietHours.Interval = 5 * time.Minute
+	}
+
+	if config.EventQueue.Enabled == nil {
+		config.EventQueue.Enabled = boolPtr(true)
+	}
+	if config.EventQueue.MaxAttempts == 0 {
+		config.EventQueue.MaxAttempts = 5
+	}
+	if config.EventQueue.Backoff == 0 {
+		config.EventQueue.Backoff = time.Minute
+	}
+	if config.EventQueue.MaxBackoff == 0 {
+		config.EventQueue.MaxBackoff = time.Hour
+	}
+	if config.EventQueue.Interval == 0 {
+		config.EventQueue.Interval = 30 * time.Second
+	}
+	if config.EventQueue.Retention == 0 {
+		config.EventQueue.Retention = 7 * 24 * time.Hour
+	}
+
+	if config.Outbox.Interval == 0 {
+		config.Outbox.Interval = 10 * time.Second
+	}
+	if config.Outbox.MaxAttempts == 0 {
+		config.Outbox.MaxAttempts = 5
+	}
+
+	if config.Decisions.Record == nil {
+		config.Decisions.Record = boolPtr(false)
+	}
+	if config.Decisions.Retention == 0 {
+		config.Decisions.Retention = 7 * 24 * time.Hour
+	}
+
+	if config.Shadow.Retention == 0 {
+		config.Shadow.Retention = 14 * 24 * time.Hour
+	}
+
+	if config.SelfUpdate.Enabled == nil {
+		config.SelfUpdate.Enabled = boolPtr(false)
+	}
+	if config.SelfUpdate.Repo == "" {
+		config.SelfUpdate.Repo = "open-telemetry/sig-project-infra"
+	}
+	if config.SelfUpdate.TagPrefix == "" {
+		config.SelfUpdate.TagPrefix = "otto/"
+	}
+	if config.SelfUpdate.Interval == 0 {
+		config.SelfUpdate.Interval = 24 * time.Hour
+	}
+	if config.SelfUpdate.MaxBehind == 0 {
+		config.SelfUpdate.MaxBehind = 2
+	}
+	if config.SelfUpdate.Highlights == 0 {
+		config.SelfUpdate.Highlights = 3
+	}
+
+	if config.FeatureFlags.Provider == "" {
+		config.FeatureFlags.Provider = "database"
+	}
+	if config.FeatureFlags.Timeout == 0 {
+		config.FeatureFlags.Timeout = 2 * time.Second
+	}
+
+	if len(config.Webhooks) == 0 {
+		config.Webhooks = []WebhookEndpoint{{Path: "/webhook"}}
+	}
+	for i := range config.Webhooks {
+		if config.Webhooks[i].Source == "" {
+			config.Webhooks[i].Source = "github"
+		}
+	}
+
+	if config.Probe.Enabled == nil {
+		config.Probe.Enabled = boolPtr(false)
+	}
+	if config.Probe.Interval == 0 {
+		config.Probe.Interval = 5 * time.Minute
+	}
+	if config.Probe.SLO == 0 {
+		config.Probe.SLO = 30 * time.Second
+	}
+	if config.Probe.Path == "" {
+		for _, endpoint := range config.Webhooks {
+			if endpoint.Source == "github" {
+				config.Probe.Path = endpoint.Path
+				break
+			}
+		}
+	}
+
+	if config.FileClasses.Generated == nil {
+		config.FileClasses.Generated = []string{"*.pb.go", "*.pb.gw.go", "*_generated.go", "zz_generated*.go",
+			"*.gen.go", "go.sum", "package-lock.json"}
+	}
+	if config.FileClasses.Vendored == nil {
+		config.FileClasses.Vendored = []string{"vendor/", "third_party/", "node_modules/"}
+	}
+	if config.FileClasses.Docs == nil {
+		config.FileClasses.Docs = []string{"docs/", "*.md"}
+	}
+	if config.FileClasses.GitAttributes == nil {
+		config.FileClasses.GitAttributes = boolPtr(true)
+	}
+	if config.Dispatch.Workers == 0 {
+		config.Dispatch.Workers = 8
+	}
+	if config.Dispatch.QueueSize == 0 {
+		config.Dispatch.QueueSize = 1000
+	}
+	if config.Dispatch.BackgroundEvents == nil {
+		config.Dispatch.BackgroundEvents = []string{"push", "check_run", "check_suite", "status", "workflow_run",
+			"workflow_job", "deployment_status"}
+	}
+	if config.Dispatch.StarvationLimit == 0 {
+		config.Dispatch.StarvationLimit = 10
+	}
+	if config.Dispatch.DrainTimeout == 0 {
+		config.Dispatch.DrainTimeout = 30 * time.Second
+	}
+
+	if config.Watchdog.Enabled == nil {
+		config.Watchdog.Enabled = boolPtr(true)
+	}
+	if config.Watchdog.Deadline == 0 {
+		config.Watchdog.Deadline = 2 * time.Minute
+	}
+	if config.Watchdog.DumpFactor == 0 {
+		config.Watchdog.DumpFactor = 5
+	}
+	if config.Watchdog.Interval == 0 {
+		config.Watchdog.Interval = 15 * time.Second
+	}
+
+	if config.Debug.Enabled == nil {
+		config.Debug.Enabled = boolPtr(false)
+	}
+	if config.Debug.Addr == "" {
+		config.Debug.Addr = "localhost:6060"
+	}
+
+	if config.Cache.Backend == "" {
+		config.Cache.Backend = "memory"
+	}
+	if config.Cache.Redis.KeyPrefix == "" {
+		config.Cache.Redis.KeyPrefix = "otto:"
+	}
+	if config.Cache.Redis.Timeout == 0 {
+		config.Cache.Redis.Timeout = 2 * time.Second
+	}
+	if config.Cache.Redis.PoolSize == 0 {
+		config.Cache.Redis.PoolSize = 4
+	}
+
+	if config.EventPayloads.Prefix == "" {
+		config.EventPayloads.Prefix = "events/"
+	}
+	if config.EventPayloads.OffloadAbove == 0 {
+		config.EventPayloads.OffloadAbove = 256 << 10
+	}
+	if config.EventPayloads.Timeout == 0 {
+		config.EventPayloads.Timeout = 10 * time.Second
+	}
+	switch {
+	case config.EventPayloads.Region != "":
+	case config.EventPayloads.Backend == "gcs":
+		config.EventPayloads.Region = "auto"
+	default:
+		config.EventPayloads.Region = "us-east-1"
+	}
+	if config.EventPayloads.Endpoint == "" {
+		switch config.EventPayloads.Backend {
+		case "s3":
+			config.EventPayloads.Endpoint = "https://s3." + config.EventPayloads.Region + ".amazonaws.com"
+		case "gcs":
+			config.EventPayloads.Endpoint = "https://storage.googleapis.com"
+		}
+	}
+
+	if config.FileClasses.CacheTTL == 0 {
+		config.FileClasses.CacheTTL = 10 * time.Minute
+	}
+
+	if config.HTTP.TLSMinVersion == "" {
+		config.HTTP.TLSMinVersion = "1.2"
+	}
+
 	if config.Log == nil {
 		config.Log = map[string]any{
 			"level":  "info",
@@ -71,10 +943,17 @@ func ApplyDefaults(config *AppConfig) {
 	}
 }
 
+// boolPtr returns a pointer to b, for optional boolean config fields.
+func boolPtr(b bool) *bool {
+	return &b
+}
+
 // LogSummary logs a sanitized summary of the loaded configuration.
 func LogSummary(config *AppConfig) {
 	slog.Info("configuration loaded",
 		"port", config.Port,
+		"admin_addr", config.Admin.Addr,
+		"instance_id", config.InstanceID,
 		"db_path", config.DBPath,
 		"log_level", config.Log["level"],
 		"modules_configured", len(config.Modules))
@@ -4,7 +4,9 @@ package config
 
 import (
 	"os"
+	"slices"
 	"testing"
+	"time"
 )
 
 func TestLoadFromFile(t *testing.T) {
@@ -22,6 +24,9 @@ db_path: "test.db"
 log:
   level: "debug"
   format: "json"
+db_maintenance:
+  interval: 6h
+  vacuum: false
 modules:
   test: true
 `
@@ -51,6 +56,12 @@ modules:
 	if config.Log["format"] != "json" {
 		t.Errorf("Expected log format json, got %s", config.Log["format"])
 	}
+	if config.DBMaintenance.Interval != 6*time.Hour {
+		t.Errorf("Expected db maintenance interval 6h, got %s", config.DBMaintenance.Interval)
+	}
+	if *config.DBMaintenance.Vacuum {
+		t.Errorf("Expected db maintenance vacuum to be disabled")
+	}
 	if _, ok := config.Modules["test"]; !ok {
 		t.Errorf("Expected modules to contain test")
 	}
@@ -70,12 +81,309 @@ func TestApplyDefaults(t *testing.T) {
 	if config.DBPath != "data.db" {
 		t.Errorf("Expected default db_path data.db, got %s", config.DBPath)
 	}
+	if hostname, _ := os.Hostname(); hostname != "" && config.InstanceID != hostname {
+		t.Errorf("Expected default instance_id %s, got %s", hostname, config.InstanceID)
+	}
 	if config.Log["level"] != "info" {
 		t.Errorf("Expected default log level info, got %s", config.Log["level"])
 	}
 	if config.Log["format"] != "json" {
 		t.Errorf("Expected default log format json, got %s", config.Log["format"])
 	}
+	if tc := config.Telemetry; tc.Traces != ExporterOTLPHTTP || tc.Metrics != ExporterOTLPHTTP ||
+		tc.Logs != ExporterOTLPHTTP || *tc.SampleRatio != 1 {
+		t.Errorf("Expected telemetry defaults, got %+v", tc)
+	}
+	if !*config.DBMaintenance.Enabled {
+		t.Errorf("Expected db maintenance to be enabled by default")
+	}
+	if config.DBMaintenance.Interval != 24*time.Hour {
+		t.Errorf("Expected default db maintenance interval 24h, got %s", config.DBMaintenance.Interval)
+	}
+	if *config.GitHubStatus.Enabled || config.GitHubStatus.Interval != time.Minute {
+		t.Errorf("Expected GitHub status polling to be disabled by default, got %+v", config.GitHubStatus)
+	}
+	if *config.SelfUpdate.Enabled || config.SelfUpdate.TagPrefix != "otto/" || config.SelfUpdate.MaxBehind != 2 {
+		t.Errorf("Expected self update defaults, got %+v", config.SelfUpdate)
+	}
+	if config.FeatureFlags.Provider != "database" || config.FeatureFlags.Timeout != 2*time.Second {
+		t.Errorf("Expected feature flag defaults, got %+v", config.FeatureFlags)
+	}
+	if config.HTTP.TLSMinVersion != "1.2" || config.HTTP.ProxyURL != "" {
+		t.Errorf("Expected HTTP defaults, got %+v", config.HTTP)
+	}
+	if len(config.Webhooks) != 1 || config.Webhooks[0] != (WebhookEndpoint{Path: "/webhook", Source: "github"}) {
+		t.Errorf("Expected the default webhook endpoint, got %+v", config.Webhooks)
+	}
+	if !*config.FileClasses.GitAttributes || len(config.FileClasses.Vendored) == 0 ||
+		!slices.Contains(config.FileClasses.Generated, "*.pb.go") {
+		t.Errorf("Expected file class defaults, got %+v", config.FileClasses)
+	}
+	if config.Dispatch.Workers != 8 || config.Dispatch.QueueSize != 1000 ||
+		!slices.Contains(config.Dispatch.BackgroundEvents, "check_run") {
+		t.Errorf("Expected dispatch defaults, got %+v", config.Dispatch)
+	}
+	if !*config.Watchdog.Enabled || config.Watchdog.Deadline != 2*time.Minute || config.Watchdog.DumpFactor != 5 {
+		t.Errorf("Expected watchdog defaults, got %+v", config.Watchdog)
+	}
+	if !*config.Permissions.Enabled || config.Permissions.Interval != 6*time.Hour {
+		t.Errorf("Expected permissions defaults, got %+v", config.Permissions)
+	}
+	if !*config.Rollups.Enabled || config.Rollups.Interval != time.Hour || config.Rollups.Backfill != 30 {
+		t.Errorf("Expected rollups defaults, got %+v", config.Rollups)
+	}
+	if p := config.Pacing; !*p.Enabled || p.MutationsPerMinute != 60 || p.Burst != 10 || p.Jitter != time.Second {
+		t.Errorf("Expected pacing defaults, got %+v", p)
+	}
+	if !*config.APIUsage.Enabled || config.APIUsage.Retention != 90*24*time.Hour {
+		t.Errorf("Expected API usage defaults, got %+v", config.APIUsage)
+	}
+	if !*config.ContentFilter.Enabled {
+		t.Errorf("Expected the content filter to be enabled by default")
+	}
+	if ls := config.LogStream; !*ls.Enabled || ls.Buffer != 1000 || ls.Level != "info" {
+		t.Errorf("Expected log stream defaults, got %+v", ls)
+	}
+	if *config.Decisions.Record || config.Decisions.Retention != 7*24*time.Hour {
+		t.Errorf("Expected decisions defaults, got %+v", config.Decisions)
+	}
+	if config.Shadow.Modules != nil || config.Shadow.Retention != 14*24*time.Hour {
+		t.Errorf("Expected shadow defaults, got %+v", config.Shadow)
+	}
+	if qh := config.Notifications.QuietHours; qh.Start != "" || qh.Interval != 5*time.Minute {
+		t.Errorf("Expected quiet hours defaults, got %+v", qh)
+	}
+	if config.Outbox.Interval != 10*time.Second || config.Outbox.MaxAttempts != 5 {
+		t.Errorf("Expected outbox defaults, got %+v", config.Outbox)
+	}
+	if *config.Debug.Enabled || config.Debug.Addr != "localhost:6060" {
+		t.Errorf("Expected debug defaults, got %+v", config.Debug)
+	}
+	if *config.Probe.Enabled || config.Probe.Interval != 5*time.Minute || config.Probe.SLO != 30*time.Second ||
+		config.Probe.Path != "/webhook" {
+		t.Errorf("Expected probe defaults, got %+v", config.Probe)
+	}
+	if config.Cache.Backend != "memory" || config.Cache.Redis.KeyPrefix != "otto:" || config.Cache.Redis.PoolSize != 4 {
+		t.Errorf("Expected cache defaults, got %+v", config.Cache)
+	}
+}
+
+func TestValidateWebhooks(t *testing.T) {
+	tests := []struct {
+		name     string
+		webhooks []WebhookEndpoint
+		wantErr  bool
+	}{
+		{
+			name: "multiple endpoints",
+			webhooks: []WebhookEndpoint{
+				{Path: "/webhook", Source: "github"},
+				{Path: "/webhook/github-mirror", Source: "github", Secret: "mirror_webhook_secret"},
+			},
+		},
+		{name: "relative path", webhooks: []WebhookEndpoint{{Path: "webhook", Source: "github"}}, wantErr: true},
+		{
+			name:     "duplicate path",
+			webhooks: []WebhookEndpoint{{Path: "/webhook", Source: "github"}, {Path: "/webhook", Source: "github"}},
+			wantErr:  true,
+		},
+		{name: "gitlab source", webhooks: []WebhookEndpoint{{Path: "/webhook/gitlab-mirror", Source: "gitlab"}}},
+		{name: "unknown source", webhooks: []WebhookEndpoint{{Path: "/gitea", Source: "gitea"}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Validate(&AppConfig{Webhooks: tt.webhooks}); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateAdmin(t *testing.T) {
+	tests := []struct {
+		name    string
+		addr    string
+		wantErr bool
+	}{
+		{name: "unset"},
+		{name: "localhost", addr: "localhost:9090"},
+		{name: "all interfaces", addr: ":9090"},
+		{name: "missing port", addr: "localhost", wantErr: true},
+		{name: "webhook port", addr: "localhost:8080", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(&AppConfig{Port: "8080", Admin: AdminConfig{Addr: tt.addr}})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateLogStream(t *testing.T) {
+	for level, wantErr := range map[string]bool{"": false, "debug": false, "WARN": false, "verbose": true} {
+		err := Validate(&AppConfig{LogStream: LogStreamConfig{Level: level}})
+		if (err != nil) != wantErr {
+			t.Errorf("Validate(level %q) error = %v, wantErr %v", level, err, wantErr)
+		}
+	}
+}
+
+func TestValidateModuleEnabled(t *testing.T) {
+	for enabled, wantErr := range map[any]bool{true: false, false: false, "no": true} {
+		err := Validate(&AppConfig{Modules: map[string]any{"triage": map[string]any{"enabled": enabled}}})
+		if (err != nil) != wantErr {
+			t.Errorf("Validate(enabled %v) error = %v, wantErr %v", enabled, err, wantErr)
+		}
+	}
+}
+
+func TestValidateModuleRoute(t *testing.T) {
+	for _, tc := range []struct {
+		route   any
+		wantErr bool
+	}{
+		{map[string]any{"repos": []any{"open-telemetry/opentelemetry-go"}}, false},
+		{"open-telemetry/opentelemetry-go", true},
+		{[]any{"open-telemetry/opentelemetry-go"}, true},
+	} {
+		err := Validate(&AppConfig{Modules: map[string]any{"oncall": map[string]any{"route": tc.route}}})
+		if (err != nil) != tc.wantErr {
+			t.Errorf("Validate(route %v) error = %v, wantErr %v", tc.route, err, tc.wantErr)
+		}
+	}
+}
+
+func TestValidateContentFilter(t *testing.T) {
+	if err := Validate(&AppConfig{ContentFilter: ContentFilterConfig{
+		Patterns: map[string]string{"internal token": `itk_[a-z0-9]{32}`},
+	}}); err != nil {
+		t.Errorf("valid pattern rejected: %v", err)
+	}
+	if err := Validate(&AppConfig{ContentFilter: ContentFilterConfig{
+		Patterns: map[string]string{"broken": `itk_[a-z`},
+	}}); err == nil {
+		t.Error("invalid pattern accepted")
+	}
+}
+
+func TestValidateTelemetry(t *testing.T) {
+	ratio := func(r float64) *float64 { return &r }
+	for _, tc := range []struct {
+		telemetry TelemetryConfig
+		wantErr   bool
+	}{
+		{TelemetryConfig{Traces: ExporterOTLPGRPC, Metrics: ExporterStdout, Logs: ExporterNone}, false},
+		{TelemetryConfig{SampleRatio: ratio(0.25)}, false},
+		{TelemetryConfig{Traces: "jaeger"}, true},
+		{TelemetryConfig{Logs: "otlp"}, true},
+		{TelemetryConfig{SampleRatio: ratio(1.5)}, true},
+	} {
+		if err := Validate(&AppConfig{Telemetry: tc.telemetry}); (err != nil) != tc.wantErr {
+			t.Errorf("Validate(%+v) error = %v, wantErr %v", tc.telemetry, err, tc.wantErr)
+		}
+	}
+}
+
+func TestValidateAdminLogin(t *testing.T) {
+	valid := AdminLoginConfig{
+		Provider:    LoginProviderGitHub,
+		ClientID:    "client",
+		RedirectURL: "https://otto.example.com/admin/login/callback",
+		Teams:       map[string]string{"org/maintainers": AdminRoleAdmin},
+	}
+	with := func(change func(*AdminLoginConfig)) AdminLoginConfig {
+		login := valid
+		change(&login)
+		return login
+	}
+	oidc := func(change func(*AdminLoginConfig)) AdminLoginConfig {
+		return with(func(l *AdminLoginConfig) {
+			l.Provider, l.Issuer = LoginProviderOIDC, "https://id.example.com"
+			change(l)
+		})
+	}
+	for _, tc := range []struct {
+		login   AdminLoginConfig
+		wantErr bool
+	}{
+		{AdminLoginConfig{}, false},
+		{valid, false},
+		{oidc(func(l *AdminLoginConfig) { l.LoginClaim = "github_login" }), false},
+		{oidc(func(l *AdminLoginConfig) { l.Logins = map[string]string{"00u1": "alice"} }), false},
+		{oidc(func(l *AdminLoginConfig) {}), true},
+		{oidc(func(l *AdminLoginConfig) { l.LoginClaim = "preferred_username" }), true},
+		{oidc(func(l *AdminLoginConfig) {
+			l.LoginClaim, l.Logins = "github_login", map[string]string{"00u1": "alice"}
+		}), true},
+		{with(func(l *AdminLoginConfig) { l.Provider, l.LoginClaim = LoginProviderOIDC, "github_login" }), true},
+		{with(func(l *AdminLoginConfig) { l.Provider = "saml" }), true},
+		{with(func(l *AdminLoginConfig) { l.ClientID = "" }), true},
+		{with(func(l *AdminLoginConfig) { l.RedirectURL = "/admin/login/callback" }), true},
+		{with(func(l *AdminLoginConfig) { l.Teams = nil }), true},
+		{with(func(l *AdminLoginConfig) { l.Teams = map[string]string{"maintainers": AdminRoleAdmin} }), true},
+		{with(func(l *AdminLoginConfig) { l.Teams = map[string]string{"org/maintainers": "owner"} }), true},
+	} {
+		if err := Validate(&AppConfig{Admin: AdminConfig{Login: tc.login}}); (err != nil) != tc.wantErr {
+			t.Errorf("Validate(%+v) error = %v, wantErr %v", tc.login, err, tc.wantErr)
+		}
+	}
+}
+
+func TestValidatePacing(t *testing.T) {
+	if err := Validate(&AppConfig{Pacing: PacingConfig{MutationsPerMinute: 30, Burst: 5}}); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+	if err := Validate(&AppConfig{Pacing: PacingConfig{Jitter: -time.Second}}); err == nil {
+		t.Error("expected error for a negative jitter")
+	}
+}
+
+func TestValidateProbe(t *testing.T) {
+	webhooks := []WebhookEndpoint{{Path: "/webhook", Source: "github"}, {Path: "/gitlab", Source: "gitlab"}}
+	tests := []struct {
+		name    string
+		probe   ProbeConfig
+		wantErr bool
+	}{
+		{name: "disabled", probe: ProbeConfig{Enabled: boolPtr(false)}},
+		{name: "enabled", probe: ProbeConfig{Enabled: boolPtr(true), Interval: time.Minute, SLO: 10 * time.Second,
+			Path: "/webhook"}},
+		{name: "slo not shorter than interval", probe: ProbeConfig{Enabled: boolPtr(true), Interval: time.Minute,
+			SLO: time.Minute, Path: "/webhook"}, wantErr: true},
+		{name: "gitlab endpoint", probe: ProbeConfig{Enabled: boolPtr(true), Interval: time.Minute,
+			SLO: 10 * time.Second, Path: "/gitlab"}, wantErr: true},
+		{name: "unknown endpoint", probe: ProbeConfig{Enabled: boolPtr(true), Interval: time.Minute,
+			SLO: 10 * time.Second, Path: "/hooks"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Validate(&AppConfig{Webhooks: webhooks, Probe: tt.probe}); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateCache(t *testing.T) {
+	tests := []struct {
+		name    string
+		cache   CacheConfig
+		wantErr bool
+	}{
+		{name: "memory", cache: CacheConfig{Backend: "memory"}},
+		{name: "redis", cache: CacheConfig{Backend: "redis", Redis: RedisConfig{Addr: "redis:6379"}}},
+		{name: "redis without address", cache: CacheConfig{Backend: "redis"}, wantErr: true},
+		{name: "unknown backend", cache: CacheConfig{Backend: "memcached"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Validate(&AppConfig{Cache: tt.cache}); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
 }
 
 func TestGetEnvOrDefault(t *testing.T) {
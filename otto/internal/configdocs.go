@@ -0,0 +1,224 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// configdocs.go generates the configuration reference from the config types: every key of
+// config.yaml and of the module sections, with its type, default and the description in
+// the field's doc tag, so the reference cannot drift from the code.
+
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+)
+
+// ConfigField documents a config key.
+type ConfigField struct {
+	Key         string `json:"key"` // dotted path; [] marks list items and .<name> map entries
+	Type        string `json:"type"`
+	Default     string `json:"default,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// ModuleConfigReference documents the keys of a module's config section.
+type ModuleConfigReference struct {
+	Module string        `json:"module"`
+	Fields []ConfigField `json:"fields"`
+}
+
+// ConfigReference documents config.yaml and the config sections of modules.
+type ConfigReference struct {
+	App     []ConfigField           `json:"app"`
+	Modules []ModuleConfigReference `json:"modules"`
+}
+
+// NewConfigReference documents config.yaml and the sections of the modules with
+// configuration, sorted by name.
+func NewConfigReference(modules []Module) ConfigReference {
+	app := &config.AppConfig{}
+	config.ApplyDefaults(app)
+	ref := ConfigReference{App: ConfigFields(app), Modules: []ModuleConfigReference{}}
+	for _, m := range modules {
+		schema, ok := m.(ModuleConfigSchema)
+		if !ok {
+			continue
+		}
+		common := []ConfigField{{
+			Key:         ModuleEnabledKey,
+			Type:        "bool",
+			Default:     "true",
+			Description: "false turns the module off",
+		}, {
+			Key:         ConfigVersionKey,
+			Type:        "int",
+			Default:     "1",
+			Description: fmt.Sprintf("format the section is written for; current: %d", ModuleConfigVersion(m)),
+		}}
+		ref.Modules = append(ref.Modules, ModuleConfigReference{
+			Module: m.Name(),
+			Fields: append(common, ConfigFields(schema.ConfigSchema())...),
+		})
+	}
+	slices.SortFunc(ref.Modules, func(a, b ModuleConfigReference) int { return strings.Compare(a.Module, b.Module) })
+	return ref
+}
+
+// ConfigFields documents the keys of a config struct, or a pointer to one, taking the
+// values it holds as the defaults.
+func ConfigFields(v any) []ConfigField {
+	var fields []ConfigField
+	collectConfigFields(&fields, "", reflect.Indirect(reflect.ValueOf(v)))
+	return fields
+}
+
+var durationType = reflect.TypeFor[time.Duration]()
+
+// collectConfigFields appends the keys of struct value v, prefixed with prefix, and
+// descends into nested structs, list items and map entries of struct type.
+func collectConfigFields(fields *[]ConfigField, prefix string, v reflect.Value) {
+	t := v.Type()
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() && !f.Anonymous {
+			continue
+		}
+		name, opts, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if name == "-" {
+			continue
+		}
+		value := v.Field(i)
+		if slices.Contains(strings.Split(opts, ","), "inline") {
+			collectConfigFields(fields, prefix, value)
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		key := prefix + name
+		field := ConfigField{Key: key, Type: configTypeName(f.Type), Description: f.Tag.Get("doc")}
+		if !strings.Contains(field.Description, "default:") {
+			// Defaults computed at startup, such as the hostname, are described instead.
+			field.Default = configDefault(value)
+		}
+
+		ft := f.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		switch {
+		case ft.Kind() == reflect.Struct:
+			field.Default = ""
+			*fields = append(*fields, field)
+			if value.Kind() == reflect.Pointer {
+				if value.IsNil() {
+					value = reflect.New(ft)
+				}
+				value = value.Elem()
+			}
+			collectConfigFields(fields, key+".", value)
+		case ft.Kind() == reflect.Slice && ft.Elem().Kind() == reflect.Struct:
+			*fields = append(*fields, field)
+			collectConfigFields(fields, key+"[].", reflect.New(ft.Elem()).Elem())
+		case ft.Kind() == reflect.Map && ft.Elem().Kind() == reflect.Struct:
+			*fields = append(*fields, field)
+			collectConfigFields(fields, key+".<name>.", reflect.New(ft.Elem()).Elem())
+		default:
+			*fields = append(*fields, field)
+		}
+	}
+}
+
+// configTypeName names a config type the way it is written in YAML.
+func configTypeName(t reflect.Type) string {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == durationType {
+		return "duration"
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		return "object"
+	case reflect.Slice:
+		return "list of " + configTypeName(t.Elem())
+	case reflect.Map:
+		return "map of " + configTypeName(t.Elem())
+	case reflect.Interface:
+		return "any"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "int"
+	case reflect.Float32, reflect.Float64:
+		return "float"
+	default:
+		return t.Kind().String()
+	}
+}
+
+// configDefault formats a default value, or returns "" for zero values other than set
+// pointers: durations like 1h0m0s, lists of structs by their length, other lists and maps
+// as JSON and other values as is.
+func configDefault(v reflect.Value) string {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	} else if v.IsZero() || (v.Kind() == reflect.Slice || v.Kind() == reflect.Map) && v.Len() == 0 {
+		return ""
+	}
+	if v.Type() == durationType {
+		return time.Duration(v.Int()).String()
+	}
+	if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Struct {
+		return fmt.Sprintf("%d built-in entries", v.Len())
+	}
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		b, err := json.Marshal(v.Interface())
+		if err != nil {
+			return ""
+		}
+		return string(b)
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
+
+// WriteMarkdown writes the reference as Markdown tables, one for config.yaml and one per
+// module section.
+func (r ConfigReference) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("# Otto configuration reference\n\n")
+	b.WriteString("<!-- Generated by `otto config docs`; do not edit. -->\n\n")
+	b.WriteString("## config.yaml\n\n")
+	writeConfigTable(&b, r.App)
+	b.WriteString("## Modules\n\n")
+	b.WriteString("Module settings are set under `modules.<name>` in config.yaml.\n\n")
+	for _, m := range r.Modules {
+		fmt.Fprintf(&b, "### %s\n\n", m.Module)
+		writeConfigTable(&b, m.Fields)
+	}
+	_, err := io.WriteString(w, strings.TrimSuffix(b.String(), "\n"))
+	return err
+}
+
+// writeConfigTable writes fields as a Markdown table.
+func writeConfigTable(b *strings.Builder, fields []ConfigField) {
+	cell := func(s string) string { return strings.ReplaceAll(s, "|", `\|`) }
+	b.WriteString("| Key | Type | Default | Description |\n|---|---|---|---|\n")
+	for _, f := range fields {
+		def := f.Default
+		if def != "" {
+			def = "`" + cell(def) + "`"
+		}
+		fmt.Fprintf(b, "| `%s` | %s | %s | %s |\n", f.Key, f.Type, def, cell(f.Description))
+	}
+	b.WriteString("\n")
+}
@@ -0,0 +1,98 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+)
+
+type docsInner struct {
+	Name string `yaml:"name" doc:"entry name"`
+}
+
+type docsConfig struct {
+	docsInner `yaml:",inline"`
+	Interval  time.Duration        `yaml:"interval" doc:"how often"`
+	Enabled   *bool                `yaml:"enabled"`
+	Labels    []string             `yaml:"labels"`
+	Entries   []docsInner          `yaml:"entries"`
+	Groups    map[string]docsInner `yaml:"groups"`
+	Host      string               `yaml:"host" doc:"default: the hostname"`
+	Ignored   string               `yaml:"-"`
+}
+
+func TestConfigFields(t *testing.T) {
+	enabled := false
+	got := ConfigFields(&docsConfig{
+		docsInner: docsInner{Name: "otto"},
+		Interval:  time.Hour,
+		Enabled:   &enabled,
+		Labels:    []string{"bug"},
+		Entries:   []docsInner{{Name: "a"}, {Name: "b"}},
+		Host:      "vm",
+	})
+	want := []ConfigField{
+		{Key: "name", Type: "string", Default: "otto", Description: "entry name"},
+		{Key: "interval", Type: "duration", Default: "1h0m0s", Description: "how often"},
+		{Key: "enabled", Type: "bool", Default: "false"},
+		{Key: "labels", Type: "list of string", Default: `["bug"]`},
+		{Key: "entries", Type: "list of object", Default: "2 built-in entries"},
+		{Key: "entries[].name", Type: "string", Description: "entry name"},
+		{Key: "groups", Type: "map of object"},
+		{Key: "groups.<name>.name", Type: "string", Description: "entry name"},
+		{Key: "host", Type: "string", Description: "default: the hostname"},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("ConfigFields =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestAppConfigDocumented(t *testing.T) {
+	app := &config.AppConfig{}
+	config.ApplyDefaults(app)
+	for _, f := range ConfigFields(app) {
+		if f.Description == "" {
+			t.Errorf("config key %s has no doc tag", f.Key)
+		}
+	}
+}
+
+func TestConfigReferenceMarkdown(t *testing.T) {
+	ref := NewConfigReference([]Module{
+		&mockModule{name: "plain"},
+		&schemaModule{mockModule: mockModule{name: "labels"}},
+	})
+	if len(ref.Modules) != 1 || ref.Modules[0].Module != "labels" {
+		t.Fatalf("modules = %+v, want only labels", ref.Modules)
+	}
+	fields := ref.Modules[0].Fields
+	if fields[0].Key != ModuleEnabledKey || fields[1].Key != ConfigVersionKey || fields[1].Default != "1" {
+		t.Errorf("first fields = %+v, want enabled and config_version", fields[:2])
+	}
+
+	var buf bytes.Buffer
+	if err := ref.WriteMarkdown(&buf); err != nil {
+		t.Fatalf("WriteMarkdown failed: %v", err)
+	}
+	for _, want := range []string{
+		"| `port` | string | `8080` | webhook listen port |",
+		"### labels",
+		"| `interval` | duration | `1h0m0s` | how often |",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("reference is missing %q:\n%s", want, buf.String())
+		}
+	}
+}
+
+type schemaModule struct {
+	mockModule
+}
+
+func (m *schemaModule) ConfigSchema() any { return &docsConfig{Interval: time.Hour} }
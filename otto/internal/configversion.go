@@ -0,0 +1,69 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// configversion.go upgrades module config sections written for older config formats, so
+// deployments keep working when a module changes its settings.
+
+package internal
+
+import (
+	"fmt"
+	"log/slog"
+	"maps"
+)
+
+// ConfigVersionKey is the setting that records which format a module config section was
+// written for. Sections without it are version 1.
+const ConfigVersionKey = "config_version"
+
+// ModuleConfigVersion returns the current config format version of a module: the one its
+// ModuleConfigMigrator reports, or 1.
+func ModuleConfigVersion(m Module) int {
+	if migrator, ok := m.(ModuleConfigMigrator); ok {
+		return max(migrator.ConfigVersion(), 1)
+	}
+	return 1
+}
+
+// ConfigSectionVersion returns the config_version of a module config section, or 1 if it
+// has none.
+func ConfigSectionVersion(section map[string]any) (int, error) {
+	value, ok := section[ConfigVersionKey]
+	if !ok {
+		return 1, nil
+	}
+	version, ok := value.(int)
+	if !ok || version < 1 {
+		return 0, fmt.Errorf("%s must be a positive integer, got %v", ConfigVersionKey, value)
+	}
+	return version, nil
+}
+
+// MigrateModuleConfig returns a module config section in the module's current format,
+// without its config_version. Sections written for an older version are migrated one
+// version at a time; sections written for a newer version than the module supports are
+// rejected. The section itself is not modified.
+func MigrateModuleConfig(m Module, name string, section map[string]any) (map[string]any, error) {
+	version, err := ConfigSectionVersion(section)
+	if err != nil {
+		return nil, err
+	}
+	current := ModuleConfigVersion(m)
+	if version > current {
+		return nil, fmt.Errorf("%s %d is newer than the supported version %d", ConfigVersionKey, version, current)
+	}
+	migrated := maps.Clone(section)
+	delete(migrated, ConfigVersionKey)
+	if version == current {
+		return migrated, nil
+	}
+
+	migrator := m.(ModuleConfigMigrator)
+	for from := version; from < current; from++ {
+		if migrated, err = migrator.MigrateConfig(migrated, from); err != nil {
+			return nil, fmt.Errorf("failed to migrate config from version %d: %w", from, err)
+		}
+	}
+	slog.Warn("Module config migrated from an older format; update it and set config_version",
+		"module", name, "from_version", version, "config_version", current)
+	return migrated, nil
+}
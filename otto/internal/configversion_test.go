@@ -0,0 +1,104 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// renamingModule is at config version 3: version 2 renamed user to users, and version 3
+// turned users into a list.
+type renamingModule struct{}
+
+func (renamingModule) Name() string                                   { return "renaming" }
+func (renamingModule) HandleEvent(string, any, json.RawMessage) error { return nil }
+func (renamingModule) ConfigVersion() int                             { return 3 }
+
+func (renamingModule) MigrateConfig(old map[string]any, fromVersion int) (map[string]any, error) {
+	switch fromVersion {
+	case 1:
+		old["users"] = old["user"]
+		delete(old, "user")
+	case 2:
+		users, ok := old["users"].(string)
+		if !ok {
+			return nil, errors.New("users must be a string")
+		}
+		old["users"] = []any{users}
+	}
+	return old, nil
+}
+
+func TestMigrateModuleConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		module  Module
+		section map[string]any
+		want    map[string]any
+		wantErr bool
+	}{
+		{
+			name:    "unversioned",
+			module:  renamingModule{},
+			section: map[string]any{"user": "alice", "label": "hold"},
+			want:    map[string]any{"users": []any{"alice"}, "label": "hold"},
+		},
+		{
+			name:    "older version",
+			module:  renamingModule{},
+			section: map[string]any{ConfigVersionKey: 2, "users": "alice"},
+			want:    map[string]any{"users": []any{"alice"}},
+		},
+		{
+			name:    "current version",
+			module:  renamingModule{},
+			section: map[string]any{ConfigVersionKey: 3, "users": []any{"alice"}},
+			want:    map[string]any{"users": []any{"alice"}},
+		},
+		{
+			name:    "newer version",
+			module:  renamingModule{},
+			section: map[string]any{ConfigVersionKey: 4},
+			wantErr: true,
+		},
+		{
+			name:    "invalid version",
+			module:  renamingModule{},
+			section: map[string]any{ConfigVersionKey: "two"},
+			wantErr: true,
+		},
+		{
+			name:    "failed migration",
+			module:  renamingModule{},
+			section: map[string]any{ConfigVersionKey: 2, "users": 42},
+			wantErr: true,
+		},
+		{
+			name:    "module without migrations",
+			module:  nil,
+			section: map[string]any{ConfigVersionKey: 1, "label": "hold"},
+			want:    map[string]any{"label": "hold"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := make(map[string]any)
+			for k, v := range tt.section {
+				original[k] = v
+			}
+			got, err := MigrateModuleConfig(tt.module, "renaming", tt.section)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("MigrateModuleConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MigrateModuleConfig() = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(tt.section, original) {
+				t.Errorf("section modified: %v", tt.section)
+			}
+		})
+	}
+}
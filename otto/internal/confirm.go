@@ -0,0 +1,177 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// confirm.go implements two-step confirmation for destructive commands: a command
+// records a pending action and the issuer must reply `/confirm <token>` before it expires.
+
+package internal
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// ConfirmationTTL is how long a pending action waits for `/confirm`.
+const ConfirmationTTL = 10 * time.Minute
+
+// ErrConfirmationNotFound is returned when a token is unknown, expired, or belongs to
+// another user or issue.
+var ErrConfirmationNotFound = errors.New("no pending action for this token")
+
+// PendingAction is a destructive command awaiting confirmation.
+type PendingAction struct {
+	Token     string
+	Kind      string // handler name, e.g. "sla.close-all-stale"
+	Repo      string
+	Issue     int
+	User      string // only this user may confirm
+	Summary   string
+	Payload   json.RawMessage
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+// ConfirmFunc performs a confirmed action and returns a message for the issuer.
+type ConfirmFunc func(ctx context.Context, action PendingAction) (string, error)
+
+// Confirmations persists pending actions and runs them once confirmed.
+type Confirmations struct {
+	db       *sql.DB
+	mu       sync.RWMutex
+	handlers map[string]ConfirmFunc
+	now      func() time.Time
+}
+
+// NewConfirmations creates the pending action store, creating its table if needed.
+func NewConfirmations(db *sql.DB) (*Confirmations, error) {
+	stmt := `CREATE TABLE IF NOT EXISTS pending_actions (
+		token TEXT PRIMARY KEY,
+		kind TEXT NOT NULL,
+		repo TEXT NOT NULL,
+		issue_num INTEGER NOT NULL,
+		user TEXT NOT NULL,
+		summary TEXT,
+		payload BLOB,
+		created_at TIMESTAMP NOT NULL,
+		expires_at TIMESTAMP NOT NULL
+	);`
+	if _, err := db.Exec(stmt); err != nil {
+		return nil, fmt.Errorf("failed migration: %w (SQL: %s)", err, stmt)
+	}
+	return &Confirmations{db: db, handlers: make(map[string]ConfirmFunc), now: time.Now}, nil
+}
+
+// Handle registers the function that performs confirmed actions of a kind.
+func (c *Confirmations) Handle(kind string, fn ConfirmFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.handlers[kind] = fn
+}
+
+// Request records a pending action and returns it with its confirmation token.
+func (c *Confirmations) Request(
+	ctx context.Context,
+	kind, repo string,
+	issue int,
+	user, summary string,
+	payload any,
+) (*PendingAction, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode pending action: %w", err)
+	}
+	token, err := newConfirmationToken()
+	if err != nil {
+		return nil, err
+	}
+	now := c.now()
+	action := &PendingAction{
+		Token:     token,
+		Kind:      kind,
+		Repo:      repo,
+		Issue:     issue,
+		User:      user,
+		Summary:   summary,
+		Payload:   data,
+		CreatedAt: now,
+		ExpiresAt: now.Add(ConfirmationTTL),
+	}
+	_, err = c.db.ExecContext(ctx,
+		`INSERT INTO pending_actions (token, kind, repo, issue_num, user, summary, payload, created_at, expires_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		action.Token, action.Kind, action.Repo, action.Issue, action.User, action.Summary, []byte(action.Payload),
+		action.CreatedAt, action.ExpiresAt,
+	)
+	if err != nil {
+		return nil, LogAndWrapError(err, ErrorTypeDatabase, "request_confirmation", map[string]any{"kind": kind})
+	}
+	return action, nil
+}
+
+// Confirm consumes the pending action for token and runs its handler. The action
+// must have been requested by user on the same issue and must not have expired.
+func (c *Confirmations) Confirm(ctx context.Context, token, repo string, issue int, user string) (string, error) {
+	row := c.db.QueryRowContext(ctx,
+		`SELECT token, kind, repo, issue_num, user, summary, payload, created_at, expires_at
+		 FROM pending_actions WHERE token = ? AND repo = ? AND issue_num = ? AND user = ? AND expires_at > ?`,
+		token, repo, issue, user, c.now(),
+	)
+	var (
+		a       PendingAction
+		summary sql.NullString
+		payload []byte
+	)
+	err := row.Scan(&a.Token, &a.Kind, &a.Repo, &a.Issue, &a.User, &summary, &payload, &a.CreatedAt, &a.ExpiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrConfirmationNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	a.Summary, a.Payload = summary.String, payload
+
+	// Delete first so a token can only be used once, even by concurrent deliveries.
+	res, err := c.db.ExecContext(ctx, `DELETE FROM pending_actions WHERE token = ?`, token)
+	if err != nil {
+		return "", err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return "", ErrConfirmationNotFound
+	}
+
+	c.mu.RLock()
+	fn := c.handlers[a.Kind]
+	c.mu.RUnlock()
+	if fn == nil {
+		return "", fmt.Errorf("no handler registered for %s", a.Kind)
+	}
+	return fn(ctx, a)
+}
+
+// Expire deletes actions whose confirmation window has passed.
+func (c *Confirmations) Expire(ctx context.Context) error {
+	res, err := c.db.ExecContext(ctx, `DELETE FROM pending_actions WHERE expires_at <= ?`, c.now())
+	if err != nil {
+		return LogAndWrapError(err, ErrorTypeDatabase, "expire_confirmations", nil)
+	}
+	if n, _ := res.RowsAffected(); n > 0 {
+		slog.Info("Expired unconfirmed actions", "count", n)
+	}
+	return nil
+}
+
+// newConfirmationToken returns a short random token that is easy to type.
+func newConfirmationToken() (string, error) {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
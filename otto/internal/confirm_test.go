@@ -0,0 +1,79 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestConfirmations(t *testing.T) {
+	c, err := NewConfirmations(TestDB(t))
+	if err != nil {
+		t.Fatalf("NewConfirmations failed: %v", err)
+	}
+	now := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+	ctx := t.Context()
+
+	var ran []int
+	c.Handle("test.delete", func(_ context.Context, a PendingAction) (string, error) {
+		ran = append(ran, a.Issue)
+		return "deleted " + string(a.Payload), nil
+	})
+
+	action, err := c.Request(ctx, "test.delete", "org/repo", 1, "alice", "delete things", []int{4, 5})
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if len(action.Token) != 8 || !action.ExpiresAt.Equal(now.Add(ConfirmationTTL)) {
+		t.Fatalf("unexpected action: %+v", action)
+	}
+
+	tests := []struct {
+		name  string
+		repo  string
+		issue int
+		user  string
+	}{
+		{"other user", "org/repo", 1, "mallory"},
+		{"other issue", "org/repo", 2, "alice"},
+		{"other repo", "org/other", 1, "alice"},
+	}
+	for _, tt := range tests {
+		if _, err := c.Confirm(ctx, action.Token, tt.repo, tt.issue, tt.user); !errors.Is(err, ErrConfirmationNotFound) {
+			t.Errorf("%s: Confirm err = %v, want ErrConfirmationNotFound", tt.name, err)
+		}
+	}
+
+	result, err := c.Confirm(ctx, action.Token, "org/repo", 1, "alice")
+	if err != nil || result != "deleted [4,5]" || len(ran) != 1 {
+		t.Fatalf("Confirm = %q, %v (ran %v)", result, err, ran)
+	}
+	// Tokens are single use.
+	if _, err := c.Confirm(ctx, action.Token, "org/repo", 1, "alice"); !errors.Is(err, ErrConfirmationNotFound) {
+		t.Errorf("second Confirm err = %v, want ErrConfirmationNotFound", err)
+	}
+
+	// Expired actions cannot be confirmed and are removed by Expire.
+	stale, err := c.Request(ctx, "test.delete", "org/repo", 1, "alice", "delete more", nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	now = now.Add(ConfirmationTTL)
+	if _, err := c.Confirm(ctx, stale.Token, "org/repo", 1, "alice"); !errors.Is(err, ErrConfirmationNotFound) {
+		t.Errorf("expired Confirm err = %v, want ErrConfirmationNotFound", err)
+	}
+	if err := c.Expire(ctx); err != nil {
+		t.Fatalf("Expire failed: %v", err)
+	}
+	var remaining int
+	if err := c.db.QueryRow(`SELECT COUNT(*) FROM pending_actions`).Scan(&remaining); err != nil || remaining != 0 {
+		t.Errorf("pending actions after Expire = %d, %v; want 0", remaining, err)
+	}
+	if len(ran) != 1 {
+		t.Errorf("handler ran %d times, want 1", len(ran))
+	}
+}
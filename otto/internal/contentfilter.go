@@ -0,0 +1,216 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// contentfilter.go checks the text Otto posts, on GitHub, on Slack and in notifications,
+// for secrets and banned phrases before it leaves, so that a token echoed from a log or a
+// config file is never published. It fails safe: a post that fails the check is not made,
+// the module sees an error and the operators are alerted.
+
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+)
+
+// ErrContentBlocked is returned for posts the content filter blocked.
+var ErrContentBlocked = errors.New("post blocked by the content filter")
+
+// ContentFilterModule is the module the content filter's alerts are sent as, so routes can
+// select them.
+const ContentFilterModule = "content_filter"
+
+// minFilteredSecret is the length below which Otto's own secrets are not looked for, as
+// they would match ordinary text.
+const minFilteredSecret = 8
+
+// contentPattern is a kind of secret, found by a regular expression.
+type contentPattern struct {
+	name string
+	re   *regexp.Regexp
+}
+
+// secretPatterns are the formats of well-known credentials.
+var secretPatterns = []contentPattern{
+	{"GitHub token", regexp.MustCompile(`\b(gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})`)},
+	{"AWS access key", regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"Slack token", regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`)},
+	{"Google API key", regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}`)},
+	{"private key", regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`)},
+}
+
+// ContentFilter blocks posts containing well-known credential formats, configured patterns,
+// the values of Otto's own secrets or banned phrases. A nil ContentFilter allows everything.
+type ContentFilter struct {
+	patterns  []contentPattern
+	secrets   []contentSecret
+	phrases   []string // lower case
+	telemetry *TelemetryManager
+	alerts    *Notifications
+}
+
+// contentSecret is one of Otto's own secrets.
+type contentSecret struct {
+	name, value string
+}
+
+// contentAlertKey marks the context of an alert, whose delivery is not alerted about again
+// if it is blocked.
+type contentAlertKey struct{}
+
+// NewContentFilter creates a filter for the content_filter config, which also blocks the
+// values of secrets, by name. Telemetry may be nil.
+func NewContentFilter(
+	cfg config.ContentFilterConfig,
+	secrets map[string]string,
+	telemetry *TelemetryManager,
+) (*ContentFilter, error) {
+	f := &ContentFilter{patterns: slices.Clone(secretPatterns), telemetry: telemetry}
+	for _, name := range slices.Sorted(maps.Keys(cfg.Patterns)) {
+		re, err := regexp.Compile(cfg.Patterns[name])
+		if err != nil {
+			return nil, fmt.Errorf("content_filter: pattern %s: %w", name, err)
+		}
+		f.patterns = append(f.patterns, contentPattern{name, re})
+	}
+	for _, name := range slices.Sorted(maps.Keys(secrets)) {
+		if value := secrets[name]; len(value) >= minFilteredSecret {
+			f.secrets = append(f.secrets, contentSecret{name, value})
+		}
+	}
+	for _, phrase := range cfg.BannedPhrases {
+		if phrase = strings.TrimSpace(phrase); phrase != "" {
+			f.phrases = append(f.phrases, strings.ToLower(phrase))
+		}
+	}
+	return f, nil
+}
+
+// AlertTo has blocked posts alerted about as critical notifications. It is set up before
+// anything is posted.
+func (f *ContentFilter) AlertTo(notifications *Notifications) {
+	if f != nil {
+		f.alerts = notifications
+	}
+}
+
+// Check returns why text must not be posted, such as "GitHub token", or "" if it may be.
+// The reason never contains the offending text.
+func (f *ContentFilter) Check(text string) string {
+	if f == nil {
+		return ""
+	}
+	for _, p := range f.patterns {
+		if p.re.MatchString(text) {
+			return p.name
+		}
+	}
+	for _, s := range f.secrets {
+		if strings.Contains(text, s.value) {
+			return "secret " + s.name
+		}
+	}
+	lower := strings.ToLower(text)
+	for _, phrase := range f.phrases {
+		if strings.Contains(lower, phrase) {
+			return "banned phrase"
+		}
+	}
+	return ""
+}
+
+// block logs, counts and alerts about a blocked post by module to target, and returns the
+// error the poster gets.
+func (f *ContentFilter) block(ctx context.Context, module, repo, target, reason string) error {
+	slog.ErrorContext(ctx, "Content filter blocked a post", "module", module, "repo", repo, "target", target,
+		"reason", reason)
+	if f.telemetry != nil {
+		f.telemetry.IncContentBlocked(ctx, module, reason)
+	}
+	if ctx.Value(contentAlertKey{}) == nil && f.alerts != nil {
+		by := ""
+		if module != "" {
+			by = " by " + module
+		}
+		alert := Notification{
+			Module:   ContentFilterModule,
+			Repo:     repo,
+			Severity: SeverityCritical,
+			Title:    fmt.Sprintf("Blocked a post%s containing a %s", by, reason),
+			Body:     fmt.Sprintf("The post to %s was not made. Check what the module posts before it leaks.", target),
+		}
+		if err := f.alerts.Notify(context.WithValue(ctx, contentAlertKey{}, true), alert); err != nil {
+			slog.ErrorContext(ctx, "Failed to alert about a blocked post", "error", err)
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrContentBlocked, reason)
+}
+
+// Transport wraps base so that writes whose text fails the check are not sent. A nil
+// filter returns base unchanged.
+func (f *ContentFilter) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if f == nil {
+		return base
+	}
+	return &contentFilterTransport{filter: f, base: base}
+}
+
+type contentFilterTransport struct {
+	filter *ContentFilter
+	base   http.RoundTripper
+}
+
+func (t *contentFilterTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, write, err := githubWrite(req)
+	if err != nil {
+		return nil, err
+	}
+	if write && len(body) > 0 {
+		if reason := t.filter.Check(postedText(body)); reason != "" {
+			repo, route := shadowRoute(req.URL.Path)
+			target := req.Method + " " + req.URL.Host + route
+			return nil, t.filter.block(req.Context(), ModuleFromContext(req.Context()), repo, target, reason)
+		}
+	}
+	return t.base.RoundTrip(req)
+}
+
+// postedText returns the strings of a JSON request body, one per line, or the body itself
+// if it is not JSON, so escaped characters do not hide what is posted.
+func postedText(body []byte) string {
+	var v any
+	if err := json.Unmarshal(body, &v); err != nil {
+		return string(body)
+	}
+	var b strings.Builder
+	var walk func(v any)
+	walk = func(v any) {
+		switch v := v.(type) {
+		case string:
+			b.WriteString(v)
+			b.WriteByte('\n')
+		case []any:
+			for _, e := range v {
+				walk(e)
+			}
+		case map[string]any:
+			for _, e := range v {
+				walk(e)
+			}
+		}
+	}
+	walk(v)
+	return b.String()
+}
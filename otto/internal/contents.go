@@ -0,0 +1,119 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// contents.go provides helpers for reading and writing repository files and
+// branches through the GitHub API.
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/go-github/v71/github"
+)
+
+// RepoFile is a file read from a repository.
+type RepoFile struct {
+	Path    string
+	Content string
+	SHA     string // blob SHA, required when updating the file
+}
+
+// GetFile reads a file at ref (branch, tag, or SHA; empty for the default branch).
+// It returns nil and no error if the file does not exist.
+func GetFile(ctx context.Context, client *github.Client, repo, path, ref string) (*RepoFile, error) {
+	owner, name, err := SplitRepo(repo)
+	if err != nil {
+		return nil, err
+	}
+	file, _, resp, err := client.Repositories.GetContents(ctx, owner, name, path,
+		&github.RepositoryContentGetOptions{Ref: ref})
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s from %s: %w", path, repo, err)
+	}
+	if file == nil {
+		return nil, fmt.Errorf("%s in %s is a directory", path, repo)
+	}
+	content, err := file.GetContent()
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s from %s: %w", path, repo, err)
+	}
+	return &RepoFile{Path: path, Content: content, SHA: file.GetSHA()}, nil
+}
+
+// PutFile creates or updates a file on a branch. Pass the existing blob SHA to
+// update a file, or "" to create it.
+func PutFile(ctx context.Context, client *github.Client, repo, branch, path, message, content, sha string) error {
+	owner, name, err := SplitRepo(repo)
+	if err != nil {
+		return err
+	}
+	opts := &github.RepositoryContentFileOptions{
+		Message: github.Ptr(message),
+		Content: []byte(content),
+		Branch:  github.Ptr(branch),
+	}
+	if sha != "" {
+		opts.SHA = github.Ptr(sha)
+	}
+	if _, _, err := client.Repositories.CreateFile(ctx, owner, name, path, opts); err != nil {
+		return fmt.Errorf("failed to write %s to %s@%s: %w", path, repo, branch, err)
+	}
+	return nil
+}
+
+// EnsureBranch creates branch from the head of base if it does not already exist.
+func EnsureBranch(ctx context.Context, client *github.Client, repo, branch, base string) error {
+	owner, name, err := SplitRepo(repo)
+	if err != nil {
+		return err
+	}
+	_, resp, err := client.Git.GetRef(ctx, owner, name, "heads/"+branch)
+	if err == nil {
+		return nil
+	}
+	if resp == nil || resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("failed to get branch %s of %s: %w", branch, repo, err)
+	}
+
+	baseRef, _, err := client.Git.GetRef(ctx, owner, name, "heads/"+base)
+	if err != nil {
+		return fmt.Errorf("failed to get base branch %s of %s: %w", base, repo, err)
+	}
+	if baseRef.GetObject().GetSHA() == "" {
+		return errors.New("base branch has no commit SHA")
+	}
+	_, _, err = client.Git.CreateRef(ctx, owner, name, &github.Reference{
+		Ref:    github.Ptr("refs/heads/" + branch),
+		Object: &github.GitObject{SHA: baseRef.GetObject().SHA},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create branch %s in %s: %w", branch, repo, err)
+	}
+	return nil
+}
+
+// FindOpenPullRequest returns the open pull request from head branch into the
+// repository, or nil if there is none.
+func FindOpenPullRequest(ctx context.Context, client *github.Client, repo, branch string) (*github.PullRequest, error) {
+	owner, name, err := SplitRepo(repo)
+	if err != nil {
+		return nil, err
+	}
+	prs, _, err := client.PullRequests.List(ctx, owner, name, &github.PullRequestListOptions{
+		State: "open",
+		Head:  owner + ":" + branch,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pull requests for %s: %w", repo, err)
+	}
+	if len(prs) == 0 {
+		return nil, nil
+	}
+	return prs[0], nil
+}
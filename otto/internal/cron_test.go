@@ -0,0 +1,49 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronNext(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("no time zone data: %v", err)
+	}
+	from := time.Date(2025, 3, 5, 10, 30, 0, 0, time.UTC) // a Wednesday
+	tests := []struct {
+		expr string
+		from time.Time
+		want time.Time
+	}{
+		{"*/15 * * * *", from, time.Date(2025, 3, 5, 10, 45, 0, 0, time.UTC)},
+		{"0 9 * * MON", from, time.Date(2025, 3, 10, 9, 0, 0, 0, time.UTC)},
+		{"0 9 * * 1-5", from, time.Date(2025, 3, 6, 9, 0, 0, 0, time.UTC)},
+		{"30 10 * * *", from, time.Date(2025, 3, 6, 10, 30, 0, 0, time.UTC)},
+		{"0 0 1 jan,jul *", from, time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 12 15 * 7", from, time.Date(2025, 3, 9, 12, 0, 0, 0, time.UTC)}, // Sunday or the 15th
+		{"0 0 30 2 *", from, time.Time{}},
+		// The local time holds across the switch to daylight saving time on March 9.
+		{"0 9 * * MON", from.In(newYork), time.Date(2025, 3, 10, 9, 0, 0, 0, newYork)},
+	}
+	for _, tt := range tests {
+		c, err := ParseCron(tt.expr)
+		if err != nil {
+			t.Fatalf("ParseCron(%q) failed: %v", tt.expr, err)
+		}
+		if got := c.Next(tt.from); !got.Equal(tt.want) {
+			t.Errorf("ParseCron(%q).Next(%v) = %v, want %v", tt.expr, tt.from, got, tt.want)
+		}
+	}
+
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* * * * MON-XYZ", "5-1 * * * *", "*/0 * * * *"} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("ParseCron(%q) accepted an invalid expression", expr)
+		}
+	}
+	if !(Cron{}).IsZero() || !(Cron{}).Next(from).IsZero() {
+		t.Error("the zero Cron matched")
+	}
+}
@@ -1,25 +1,100 @@
 // SPDX-License-Identifier: Apache-2.0
 
-// db.go sets up otto's shared SQLite connection.
+// db.go sets up otto's shared SQLite connections. Besides the general pool returned by
+// DB, a Database has a single-connection writer and a read-only reader pool, because
+// SQLite allows one writer at a time but many concurrent readers in WAL mode. Statements
+// run through Exec, Query, QueryRow and WithTx are prepared once per handle and retried
+// while the database is busy. Modules get views of it from ForModule, which share its
+// pools and statements but attribute the statements run through them to the module.
 
 package internal
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"strings"
+	"sync"
+	"time"
 
-	// Import sqlite driver for database/sql.
-	_ "github.com/mattn/go-sqlite3"
+	"github.com/mattn/go-sqlite3"
 )
 
+// Defaults for retrying statements that fail because the database is busy or locked.
+const (
+	dbBusyRetries = 5
+	dbBusyBackoff = 10 * time.Millisecond
+)
+
+// dbBusyTimeoutMillis is how long SQLite itself waits for a lock before reporting busy.
+const dbBusyTimeoutMillis = 5000
+
 // Database encapsulates database connection management.
 type Database struct {
-	db *sql.DB
+	db     *sql.DB
+	writer *sql.DB // at most one connection; nil means db
+	reader *sql.DB // read-only connections; nil means db
+
+	retries int
+	backoff time.Duration
+
+	instrumented bool
+	module       string    // set for the view of a module by ForModule
+	root         *Database // the database a module view shares pools and statements with
+
+	mu      sync.Mutex
+	stmts   map[stmtKey]*sql.Stmt
+	modules map[string]*Database // views by ForModule
+}
+
+// stmtKey identifies a cached prepared statement.
+type stmtKey struct {
+	write bool
+	query string
 }
 
 // NewDatabase creates a new database connection with the provided path.
 func NewDatabase(dbPath string) (*Database, error) {
-	db, err := sql.Open("sqlite3", dbPath)
+	return NewInstrumentedDatabase(dbPath, nil)
+}
+
+// NewInstrumentedDatabase creates a database connection whose statements are traced and
+// timed with telemetry. A nil telemetry opens an uninstrumented connection.
+func NewInstrumentedDatabase(dbPath string, telemetry *TelemetryManager) (*Database, error) {
+	d := &Database{retries: dbBusyRetries, backoff: dbBusyBackoff, instrumented: telemetry != nil}
+	busyTimeout := fmt.Sprintf("_busy_timeout=%d", dbBusyTimeoutMillis)
+
+	var err error
+	if d.db, err = openSQLite(sqliteDSN(dbPath, busyTimeout), telemetry); err != nil {
+		return nil, err
+	}
+	// Every connection to an in-memory database is a separate database, so it cannot
+	// be split into readers and a writer.
+	if !isMemoryDSN(dbPath) {
+		d.writer, err = openSQLite(sqliteDSN(dbPath, busyTimeout, "_journal_mode=WAL", "_txlock=immediate"),
+			telemetry)
+		if err == nil {
+			d.writer.SetMaxOpenConns(1)
+			d.reader, err = openSQLite(sqliteDSN(dbPath, busyTimeout, "mode=ro"), telemetry)
+		}
+		if err != nil {
+			d.Close()
+			return nil, err
+		}
+	}
+	return d, nil
+}
+
+// openSQLite opens and pings a SQLite connection pool, instrumented when telemetry is set.
+func openSQLite(dsn string, telemetry *TelemetryManager) (*sql.DB, error) {
+	var db *sql.DB
+	var err error
+	if telemetry != nil {
+		db, err = openInstrumentedSQLite(dsn, telemetry)
+	} else {
+		db, err = sql.Open("sqlite3", dsn)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
@@ -29,23 +104,280 @@ func NewDatabase(dbPath string) (*Database, error) {
 		db.Close()
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
+	return db, nil
+}
+
+// sqliteDSN turns a database path into a URI with connection parameters. go-sqlite3
+// only passes parameters such as mode=ro to SQLite for "file:" URIs.
+func sqliteDSN(path string, params ...string) string {
+	if !strings.HasPrefix(path, "file:") {
+		path = "file:" + path
+	}
+	sep := "?"
+	if strings.Contains(path, "?") {
+		sep = "&"
+	}
+	return path + sep + strings.Join(params, "&")
+}
+
+func isMemoryDSN(path string) bool {
+	return path == "" || strings.HasPrefix(path, ":memory:") || strings.Contains(path, "mode=memory")
+}
 
-	return &Database{db: db}, nil
+// NewDatabaseFromDB wraps an existing connection, e.g. one opened by a test. It is used
+// for reads and writes alike.
+func NewDatabaseFromDB(db *sql.DB) *Database {
+	return &Database{db: db}
 }
 
-// Close closes the database connection.
+// ForModule returns the view of the database a module uses. It shares the database's
+// connection pools and prepared statements, so there is still a single writer, but the
+// statements run through its Exec, Query, QueryRow and WithTx are attributed to the module
+// unless their context names another. Statements on the pools themselves, from DB, Writer
+// and Reader, are attributed to the module on their context only. Uninstrumented databases
+// are shared as they are.
+func (d *Database) ForModule(module string) *Database {
+	if d.root != nil {
+		return d.root.ForModule(module)
+	}
+	if !d.instrumented {
+		return d
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if view, ok := d.modules[module]; ok {
+		return view
+	}
+	view := &Database{
+		db:           d.db,
+		writer:       d.writer,
+		reader:       d.reader,
+		retries:      d.retries,
+		backoff:      d.backoff,
+		instrumented: true,
+		module:       module,
+		root:         d,
+	}
+	if d.modules == nil {
+		d.modules = make(map[string]*Database)
+	}
+	d.modules[module] = view
+	return view
+}
+
+// withModule returns ctx naming the module of a module view, unless it names one already.
+func (d *Database) withModule(ctx context.Context) context.Context {
+	if d.module == "" || ModuleFromContext(ctx) != "" {
+		return ctx
+	}
+	return WithModule(ctx, d.module)
+}
+
+// Close closes the cached statements and all connections. Closing the view of a module
+// does nothing: the database it shares them with owns them.
 func (d *Database) Close() error {
-	if d.db != nil {
-		return d.db.Close()
+	if d.root != nil {
+		return nil
 	}
-	return nil
+	d.mu.Lock()
+	for _, stmt := range d.stmts {
+		stmt.Close()
+	}
+	d.stmts = nil
+	d.modules = nil
+	d.mu.Unlock()
+
+	var errs []error
+	for _, db := range []*sql.DB{d.reader, d.writer, d.db} {
+		if db != nil {
+			errs = append(errs, db.Close())
+		}
+	}
+	return errors.Join(errs...)
 }
 
-// DB returns the underlying database connection.
+// DB returns the general connection pool, which allows reads and writes on any
+// connection. Prefer Reader and Writer, or Exec, Query and WithTx, in new code.
 func (d *Database) DB() *sql.DB {
 	return d.db
 }
 
+// Writer returns the single-connection handle for writes. Holding a transaction or an
+// open *sql.Rows from it blocks every other writer, so never query Writer while
+// iterating its rows.
+func (d *Database) Writer() *sql.DB {
+	if d.writer != nil {
+		return d.writer
+	}
+	return d.db
+}
+
+// Reader returns the read-only handle. Reads never wait for the writer.
+func (d *Database) Reader() *sql.DB {
+	if d.reader != nil {
+		return d.reader
+	}
+	return d.db
+}
+
+// Exec runs a write statement on the writer, retrying while the database is busy.
+func (d *Database) Exec(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	ctx = d.withModule(ctx)
+	var result sql.Result
+	err := d.retryBusy(ctx, func() error {
+		stmt, err := d.prepare(ctx, true, query)
+		if err != nil {
+			return err
+		}
+		result, err = stmt.ExecContext(ctx, args...)
+		return err
+	})
+	return result, err
+}
+
+// Query runs a read statement on the reader, retrying while the database is busy.
+func (d *Database) Query(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	ctx = d.withModule(ctx)
+	var rows *sql.Rows
+	err := d.retryBusy(ctx, func() error {
+		stmt, err := d.prepare(ctx, false, query)
+		if err != nil {
+			return err
+		}
+		rows, err = stmt.QueryContext(ctx, args...)
+		return err
+	})
+	return rows, err
+}
+
+// QueryRow runs a read statement on the reader that returns at most one row. Errors,
+// including failing to prepare the statement, are deferred to Scan.
+func (d *Database) QueryRow(ctx context.Context, query string, args ...any) *sql.Row {
+	ctx = d.withModule(ctx)
+	stmt, err := d.prepare(ctx, false, query)
+	if err != nil {
+		// Let the reader report the preparation error from Scan.
+		return d.Reader().QueryRowContext(ctx, query, args...)
+	}
+	return stmt.QueryRowContext(ctx, args...)
+}
+
+// WithTx runs fn in a write transaction, committing when fn returns nil. The whole
+// transaction is retried while the database is busy, so fn must not have side effects
+// outside the transaction.
+func (d *Database) WithTx(ctx context.Context, fn func(*sql.Tx) error) error {
+	ctx = d.withModule(ctx)
+	return d.retryBusy(ctx, func() error {
+		tx, err := d.Writer().BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = tx.Rollback() }()
+		if err := fn(tx); err != nil {
+			return err
+		}
+		return tx.Commit()
+	})
+}
+
+// prepare returns the cached prepared statement for query on the writer or reader. Module
+// views share the cache of their database.
+func (d *Database) prepare(ctx context.Context, write bool, query string) (*sql.Stmt, error) {
+	if d.root != nil {
+		return d.root.prepare(ctx, write, query)
+	}
+	key := stmtKey{write: write, query: query}
+	d.mu.Lock()
+	stmt, ok := d.stmts[key]
+	d.mu.Unlock()
+	if ok {
+		return stmt, nil
+	}
+
+	// Prepare without holding the lock: the writer may be busy with a transaction.
+	handle := d.Reader()
+	if write {
+		handle = d.Writer()
+	}
+	stmt, err := handle.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if cached, ok := d.stmts[key]; ok {
+		stmt.Close()
+		return cached, nil
+	}
+	if d.stmts == nil {
+		d.stmts = make(map[stmtKey]*sql.Stmt)
+	}
+	d.stmts[key] = stmt
+	return stmt, nil
+}
+
+// retryBusy runs fn until it succeeds, fails with an error other than busy, or runs out
+// of retries, backing off exponentially between attempts.
+func (d *Database) retryBusy(ctx context.Context, fn func() error) error {
+	delay := d.backoff
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || !isBusy(err) || attempt >= d.retries {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// isBusy reports whether err means another connection holds a conflicting lock.
+func isBusy(err error) bool {
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr) && (sqliteErr.Code == sqlite3.ErrBusy || sqliteErr.Code == sqlite3.ErrLocked)
+}
+
+// IntegrityCheck runs PRAGMA integrity_check and returns any reported problems.
+// An empty result means the database is healthy.
+func (d *Database) IntegrityCheck(ctx context.Context) ([]string, error) {
+	rows, err := d.db.QueryContext(ctx, "PRAGMA integrity_check")
+	if err != nil {
+		return nil, fmt.Errorf("failed to run integrity check: %w", err)
+	}
+	defer rows.Close()
+
+	var problems []string
+	for rows.Next() {
+		var result string
+		if err := rows.Scan(&result); err != nil {
+			return nil, fmt.Errorf("failed to scan integrity check result: %w", err)
+		}
+		if result != "ok" {
+			problems = append(problems, result)
+		}
+	}
+	return problems, rows.Err()
+}
+
+// Vacuum rebuilds the database file, reclaiming free pages.
+func (d *Database) Vacuum(ctx context.Context) error {
+	if _, err := d.db.ExecContext(ctx, "VACUUM"); err != nil {
+		return fmt.Errorf("failed to vacuum database: %w", err)
+	}
+	return nil
+}
+
+// Analyze refreshes the query planner statistics.
+func (d *Database) Analyze(ctx context.Context) error {
+	if _, err := d.db.ExecContext(ctx, "ANALYZE"); err != nil {
+		return fmt.Errorf("failed to analyze database: %w", err)
+	}
+	return nil
+}
+
 // OpenDB opens a new database connection with the given path.
 // Use this for tests or when you need a separate connection.
 // Deprecated: Use NewDatabase instead.
@@ -56,3 +388,29 @@ func OpenDB(dbPath string) (*sql.DB, error) {
 	}
 	return database.DB(), nil
 }
+
+// ensureColumn adds a column to an existing table if it is missing.
+func ensureColumn(db *sql.DB, table, column, definition string) error {
+	rows, err := db.Query(fmt.Sprintf("SELECT name FROM pragma_table_info('%s')", table))
+	if err != nil {
+		return fmt.Errorf("failed to inspect table %s: %w", table, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return err
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	stmt := fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition)
+	if _, err := db.Exec(stmt); err != nil {
+		return fmt.Errorf("failed migration: %w (SQL: %s)", err, stmt)
+	}
+	return nil
+}
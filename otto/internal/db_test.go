@@ -0,0 +1,152 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+func TestSQLiteDSN(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "data.db", want: "file:data.db?_busy_timeout=1&mode=ro"},
+		{path: "file:/var/otto.db", want: "file:/var/otto.db?_busy_timeout=1&mode=ro"},
+		{path: "file:otto.db?cache=shared", want: "file:otto.db?cache=shared&_busy_timeout=1&mode=ro"},
+	}
+	for _, tt := range tests {
+		if got := sqliteDSN(tt.path, "_busy_timeout=1", "mode=ro"); got != tt.want {
+			t.Errorf("sqliteDSN(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+	for path, want := range map[string]bool{":memory:": true, "file:x?mode=memory": true, "data.db": false} {
+		if got := isMemoryDSN(path); got != want {
+			t.Errorf("isMemoryDSN(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestDatabaseHandles(t *testing.T) {
+	database, err := NewDatabase(filepath.Join(t.TempDir(), "otto.db"))
+	if err != nil {
+		t.Fatalf("NewDatabase failed: %v", err)
+	}
+	defer database.Close()
+	ctx := t.Context()
+
+	if database.Writer() == database.DB() || database.Reader() == database.DB() {
+		t.Fatal("file databases should have separate reader and writer handles")
+	}
+	if got := database.Writer().Stats().MaxOpenConnections; got != 1 {
+		t.Errorf("writer allows %d connections, want 1", got)
+	}
+	if _, err := database.Exec(ctx, `CREATE TABLE items (id INTEGER PRIMARY KEY, name TEXT)`); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	if _, err := database.Reader().ExecContext(ctx, `INSERT INTO items (name) VALUES ('x')`); err == nil {
+		t.Error("reader accepted a write")
+	}
+
+	// Concurrent writers are serialized instead of failing with SQLITE_BUSY.
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := database.Exec(ctx, `INSERT INTO items (name) VALUES (?)`, "a"); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("concurrent insert failed: %v", err)
+	}
+
+	err = database.WithTx(ctx, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, `INSERT INTO items (name) VALUES ('b')`)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("WithTx failed: %v", err)
+	}
+	rollback := errors.New("rollback")
+	err = database.WithTx(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO items (name) VALUES ('c')`); err != nil {
+			return err
+		}
+		return rollback
+	})
+	if !errors.Is(err, rollback) {
+		t.Fatalf("WithTx error = %v, want %v", err, rollback)
+	}
+
+	var count int
+	if err := database.QueryRow(ctx, `SELECT COUNT(*) FROM items`).Scan(&count); err != nil || count != 21 {
+		t.Errorf("count = %d, %v; want 21", count, err)
+	}
+	rows, err := database.Query(ctx, `SELECT name FROM items WHERE name = ?`, "b")
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	rows.Close()
+
+	// One prepared statement per query and handle.
+	database.mu.Lock()
+	cached := len(database.stmts)
+	database.mu.Unlock()
+	if cached != 4 {
+		t.Errorf("cached %d statements, want 4", cached)
+	}
+}
+
+func TestDatabaseFromDBUsesOneHandle(t *testing.T) {
+	db := TestDB(t)
+	database := NewDatabaseFromDB(db)
+	if database.Reader() != db || database.Writer() != db {
+		t.Error("wrapped connection should serve reads and writes")
+	}
+}
+
+func TestRetryBusy(t *testing.T) {
+	database := &Database{retries: 2, backoff: time.Millisecond}
+	busy := sqlite3.Error{Code: sqlite3.ErrBusy}
+
+	tests := []struct {
+		name      string
+		failures  int
+		err       error
+		wantCalls int
+		wantErr   bool
+	}{
+		{name: "succeeds after busy", failures: 2, err: busy, wantCalls: 3},
+		{name: "gives up", failures: 5, err: busy, wantCalls: 3, wantErr: true},
+		{name: "other errors are not retried", failures: 5, err: errors.New("boom"), wantCalls: 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			err := database.retryBusy(context.Background(), func() error {
+				calls++
+				if calls <= tt.failures {
+					return tt.err
+				}
+				return nil
+			})
+			if calls != tt.wantCalls || (err != nil) != tt.wantErr {
+				t.Errorf("calls = %d, err = %v; want %d calls, error %v", calls, err, tt.wantCalls, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// dbtrace.go instruments SQLite connection pools with otelsql, so every statement produces
+// a span and a latency measurement attributed to the module that issued it.
+
+package internal
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+
+	"github.com/XSAM/otelsql"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// dbCoreModule attributes queries made by Otto's own stores rather than a module.
+const dbCoreModule = "otto"
+
+// openInstrumentedSQLite opens a SQLite connection pool whose statements are traced and
+// timed, and attributed to the module set on their context with WithModule.
+func openInstrumentedSQLite(dsn string, telemetry *TelemetryManager) (*sql.DB, error) {
+	attrs := []attribute.KeyValue{semconv.DBSystemSqlite}
+	if telemetry.InstanceID != "" {
+		attrs = append(attrs, semconv.ServiceInstanceID(telemetry.InstanceID))
+	}
+	moduleAttrs := func(ctx context.Context, _ otelsql.Method, _ string, _ []driver.NamedValue) []attribute.KeyValue {
+		return []attribute.KeyValue{attribute.String("otto.module", queryModule(ctx))}
+	}
+	opts := []otelsql.Option{
+		otelsql.WithAttributes(attrs...),
+		otelsql.WithAttributesGetter(moduleAttrs),
+		otelsql.WithInstrumentAttributesGetter(moduleAttrs),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{
+			DisableErrSkip:       true,
+			OmitConnResetSession: true,
+			OmitConnectorConnect: true,
+			OmitRows:             true,
+		}),
+	}
+	if telemetry.TracerProvider != nil {
+		opts = append(opts, otelsql.WithTracerProvider(telemetry.TracerProvider))
+	}
+	if telemetry.MeterProvider != nil {
+		opts = append(opts, otelsql.WithMeterProvider(telemetry.MeterProvider))
+	}
+	return otelsql.Open("sqlite3", dsn, opts...)
+}
+
+// queryModule returns the module a statement is attributed to: the module set on ctx,
+// else Otto itself.
+func queryModule(ctx context.Context) string {
+	if module := ModuleFromContext(ctx); module != "" {
+		return module
+	}
+	return dbCoreModule
+}
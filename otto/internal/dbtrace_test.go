@@ -0,0 +1,116 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"path/filepath"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestInstrumentedDatabase(t *testing.T) {
+	t.Setenv("OTEL_SEMCONV_STABILITY_OPT_IN", "") // otelsql's default conventions
+	reader := sdkmetric.NewManualReader()
+	telemetry := TestTelemetry(t, reader)
+	spans := tracetest.NewSpanRecorder()
+	telemetry.TracerProvider = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))
+
+	database, err := NewInstrumentedDatabase(filepath.Join(t.TempDir(), "otto.db"), telemetry)
+	if err != nil {
+		t.Fatalf("NewInstrumentedDatabase failed: %v", err)
+	}
+	defer database.Close()
+	db := database.DB()
+	sla, oncall := database.ForModule("sla"), database.ForModule("oncall")
+	if again := database.ForModule("sla"); again != sla || sla.DB() != db || sla.Writer() != database.Writer() {
+		t.Error("ForModule did not return one view sharing the database's pools")
+	}
+
+	// Statements on the pools are attributed to the module on their context, e.g. a job's.
+	if _, err := db.ExecContext(WithModule(t.Context(), "oncall"),
+		"CREATE TABLE t (id INTEGER PRIMARY KEY, name TEXT)"); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	// Statements through a module's view are attributed to it without one, also when they
+	// reuse a statement another module prepared.
+	if _, err := sla.Exec(t.Context(), "INSERT INTO t (name) VALUES (?)", "a"); err != nil {
+		t.Fatalf("insert failed: %v", err)
+	}
+	if _, err := oncall.Exec(t.Context(), "INSERT INTO t (name) VALUES (?)", "b"); err != nil {
+		t.Fatalf("insert failed: %v", err)
+	}
+	var count int
+	if err := db.QueryRowContext(t.Context(), "SELECT COUNT(*) FROM t").Scan(&count); err != nil || count != 2 {
+		t.Fatalf("count = %d, %v; want 2", count, err)
+	}
+	if _, err := sla.Exec(t.Context(), "INSERT INTO missing VALUES (1)"); err == nil {
+		t.Fatal("expected an error for a missing table")
+	}
+
+	// Opening the databases pings them, which is not traced.
+	ended := spans.Ended()
+	wantSpans := []struct {
+		name   string
+		query  string
+		module string
+		status codes.Code
+	}{
+		{"sql.conn.exec", "CREATE TABLE t (id INTEGER PRIMARY KEY, name TEXT)", "oncall", codes.Unset},
+		{"sql.conn.prepare", "INSERT INTO t (name) VALUES (?)", "sla", codes.Unset},
+		{"sql.stmt.exec", "INSERT INTO t (name) VALUES (?)", "sla", codes.Unset},
+		{"sql.stmt.exec", "INSERT INTO t (name) VALUES (?)", "oncall", codes.Unset},
+		{"sql.conn.query", "SELECT COUNT(*) FROM t", dbCoreModule, codes.Unset},
+		{"sql.conn.prepare", "INSERT INTO missing VALUES (1)", "sla", codes.Error},
+	}
+	if len(ended) != len(wantSpans) {
+		t.Fatalf("got %d spans, want %d", len(ended), len(wantSpans))
+	}
+	for i, want := range wantSpans {
+		span := ended[i]
+		attrs := attribute.NewSet(span.Attributes()...)
+		query, _ := attrs.Value("db.statement")
+		module, _ := attrs.Value("otto.module")
+		if span.Name() != want.name || query.AsString() != want.query || module.AsString() != want.module ||
+			span.Status().Code != want.status {
+			t.Errorf("span %d = %s %q module=%s status=%v; want %+v",
+				i, span.Name(), query.AsString(), module.AsString(), span.Status().Code, want)
+		}
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(t.Context(), &rm); err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+	counts := make(map[string]uint64)
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != "db.sql.latency" {
+				continue
+			}
+			for _, dp := range m.Data.(metricdata.Histogram[float64]).DataPoints {
+				module, _ := dp.Attributes.Value("otto.module")
+				method, _ := dp.Attributes.Value("method")
+				status, _ := dp.Attributes.Value("status")
+				counts[module.AsString()+"/"+method.AsString()+"/"+status.AsString()] += dp.Count
+			}
+		}
+	}
+	want := map[string]uint64{
+		"oncall/sql.conn.exec/ok":    1,
+		"sla/sql.stmt.exec/ok":       1,
+		"oncall/sql.stmt.exec/ok":    1,
+		"otto/sql.conn.query/ok":     1,
+		"sla/sql.conn.prepare/error": 1,
+	}
+	for key, n := range want {
+		if counts[key] != n {
+			t.Errorf("%s = %d, want %d (all: %v)", key, counts[key], n, counts)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// debug.go serves net/http/pprof and expvar for profiling live instances. The endpoints
+// require the admin token and are never served on the webhook port: they share the admin
+// listener if there is one, and get their own otherwise.
+
+package internal
+
+import (
+	"expvar"
+	"net/http"
+	"net/http/pprof"
+	"time"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+)
+
+// registerDebugRoutes routes the pprof and expvar endpoints on mux through protect.
+func registerDebugRoutes(mux *http.ServeMux, protect func(http.Handler) http.Handler) {
+	mux.Handle("/debug/pprof/", protect(http.HandlerFunc(pprof.Index)))
+	mux.Handle("/debug/pprof/cmdline", protect(http.HandlerFunc(pprof.Cmdline)))
+	mux.Handle("/debug/pprof/profile", protect(http.HandlerFunc(pprof.Profile)))
+	mux.Handle("/debug/pprof/symbol", protect(http.HandlerFunc(pprof.Symbol)))
+	mux.Handle("/debug/pprof/trace", protect(http.HandlerFunc(pprof.Trace)))
+	mux.Handle("/debug/vars", protect(expvar.Handler()))
+}
+
+// newDebugServer registers the debug endpoints on the admin listener if there is one and
+// returns nil, or creates their own listener. It returns nil if debug endpoints are
+// disabled. Profiles stream for up to their seconds parameter, so writes have no timeout.
+func (s *Server) newDebugServer(cfg config.DebugConfig) *http.Server {
+	if cfg.Enabled == nil || !*cfg.Enabled {
+		return nil
+	}
+	if s.adminMux != nil {
+		registerDebugRoutes(s.adminMux, s.requireAdmin)
+		return nil
+	}
+	mux := http.NewServeMux()
+	registerDebugRoutes(mux, s.requireAdmin)
+	return &http.Server{
+		Addr:              cfg.Addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+}
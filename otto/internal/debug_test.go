@@ -0,0 +1,74 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+)
+
+func TestDebugServer(t *testing.T) {
+	enabled, disabled := true, false
+	srv := &Server{mux: http.NewServeMux(), adminToken: []byte("token")}
+	if debug := srv.newDebugServer(config.DebugConfig{Enabled: &disabled}); debug != nil {
+		t.Fatal("debug server created while disabled")
+	}
+	debug := srv.newDebugServer(config.DebugConfig{Enabled: &enabled, Addr: "localhost:6060"})
+	if debug == nil || debug.Addr != "localhost:6060" {
+		t.Fatalf("unexpected debug server: %+v", debug)
+	}
+
+	tests := []struct {
+		path  string
+		token string
+		want  int
+		body  string
+	}{
+		{"/debug/pprof/", "token", http.StatusOK, "goroutine"},
+		{"/debug/pprof/goroutine?debug=1", "token", http.StatusOK, "goroutine profile"},
+		{"/debug/pprof/cmdline", "token", http.StatusOK, ""},
+		{"/debug/vars", "token", http.StatusOK, `"memstats"`},
+		{"/debug/pprof/", "", http.StatusUnauthorized, ""},
+		{"/debug/vars", "wrong", http.StatusUnauthorized, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path+" "+tt.token, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			rec := httptest.NewRecorder()
+			debug.Handler.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.want)
+			}
+			if !strings.Contains(rec.Body.String(), tt.body) {
+				t.Errorf("body does not contain %q:\n%s", tt.body, rec.Body.String())
+			}
+		})
+	}
+
+	// The webhook port never serves the debug endpoints.
+	req := httptest.NewRequest(http.MethodGet, "/debug/pprof/", nil)
+	req.Header.Set("Authorization", "Bearer token")
+	rec := httptest.NewRecorder()
+	srv.mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("GET /debug/pprof/ on the main mux status = %d, want 404", rec.Code)
+	}
+}
+
+func TestDebugServerWithoutToken(t *testing.T) {
+	enabled := true
+	srv := &Server{mux: http.NewServeMux()}
+	debug := srv.newDebugServer(config.DebugConfig{Enabled: &enabled})
+	rec := httptest.NewRecorder()
+	debug.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/vars", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status without admin token = %d, want 404", rec.Code)
+	}
+}
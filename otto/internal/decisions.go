@@ -0,0 +1,240 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// decisions.go records why a module did or did not act on an event. Decisions are span
+// events on the module's handler span, so "why didn't the bot do X" can be answered from
+// the trace of the delivery, and are optionally stored in the database as well.
+
+package internal
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// DecisionEventName is the name of the span events AddDecision adds.
+const DecisionEventName = "otto.decision"
+
+// DecisionPruneJobName is the scheduler name of the job that deletes expired decisions.
+const DecisionPruneJobName = "decision_log_prune"
+
+// Decision is a module's reason for acting or not acting on an event.
+type Decision struct {
+	ID       int64     `json:"id"`
+	At       time.Time `json:"at"`
+	Module   string    `json:"module,omitempty"`
+	Event    string    `json:"event,omitempty"`
+	Delivery string    `json:"delivery,omitempty"`
+	Repo     string    `json:"repo,omitempty"`
+	Outcome  string    `json:"outcome,omitempty"` // e.g. "skipped", from "skipped: PR is draft"
+	Reason   string    `json:"reason"`
+	TraceID  string    `json:"trace_id,omitempty"`
+}
+
+// decisionScope is what the app knows about the handler a decision is made in.
+type decisionScope struct {
+	log                           *DecisionLog
+	module, event, delivery, repo string
+}
+
+type decisionScopeKey struct{}
+
+// WithDecisionScope attributes the decisions made with the returned context to a module
+// handling an event. Decisions are stored in log unless it is nil.
+func WithDecisionScope(ctx context.Context, log *DecisionLog, module, event, delivery, repo string) context.Context {
+	return context.WithValue(ctx, decisionScopeKey{}, decisionScope{
+		log: log, module: module, event: event, delivery: delivery, repo: repo,
+	})
+}
+
+// deliveryFromContext returns the webhook delivery a module is handling with ctx, or "".
+func deliveryFromContext(ctx context.Context) string {
+	scope, _ := ctx.Value(decisionScopeKey{}).(decisionScope)
+	return scope.delivery
+}
+
+// AddDecision records why a module did or did not act, e.g. "skipped: PR is draft". A
+// leading word followed by a colon is the decision's outcome and the rest its reason. The
+// decision is added as an event to the span in ctx and, when the app stores decisions,
+// to the decision log.
+func AddDecision(ctx context.Context, decision string) {
+	d := Decision{Reason: decision}
+	if outcome, reason, ok := strings.Cut(decision, ":"); ok && outcome != "" && !strings.ContainsAny(outcome, " \t") {
+		d.Outcome, d.Reason = outcome, strings.TrimSpace(reason)
+	}
+	scope, _ := ctx.Value(decisionScopeKey{}).(decisionScope)
+	d.Module, d.Event, d.Delivery, d.Repo = scope.module, scope.event, scope.delivery, scope.repo
+
+	span := trace.SpanFromContext(ctx)
+	attrs := []attribute.KeyValue{attribute.String("otto.decision.reason", d.Reason)}
+	if d.Outcome != "" {
+		attrs = append(attrs, attribute.String("otto.decision.outcome", d.Outcome))
+	}
+	if d.Module != "" {
+		attrs = append(attrs, attribute.String("otto.module", d.Module))
+	}
+	span.AddEvent(DecisionEventName, trace.WithAttributes(attrs...))
+	slog.DebugContext(ctx, "Module decision", "module", d.Module, "event", d.Event, "repo", d.Repo,
+		"outcome", d.Outcome, "reason", d.Reason)
+
+	if scope.log == nil {
+		return
+	}
+	if sc := span.SpanContext(); sc.HasTraceID() {
+		d.TraceID = sc.TraceID().String()
+	}
+	if err := scope.log.Record(ctx, d); err != nil {
+		slog.ErrorContext(ctx, "Failed to record decision", "module", d.Module, "error", err)
+	}
+}
+
+// DecisionQuery filters decisions returned by DecisionLog.Query. Zero values match everything.
+type DecisionQuery struct {
+	Repo     string
+	Module   string
+	Delivery string
+	Since    time.Time
+	Limit    int // default 100
+}
+
+// DecisionLog reads and writes the module_decisions table.
+type DecisionLog struct {
+	db  *sql.DB
+	now func() time.Time
+}
+
+// NewDecisionLog creates the decision log, creating its table if needed.
+func NewDecisionLog(db *sql.DB) (*DecisionLog, error) {
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS module_decisions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			at TIMESTAMP NOT NULL,
+			module TEXT NOT NULL DEFAULT '',
+			event TEXT NOT NULL DEFAULT '',
+			delivery TEXT NOT NULL DEFAULT '',
+			repo TEXT NOT NULL DEFAULT '',
+			outcome TEXT NOT NULL DEFAULT '',
+			reason TEXT NOT NULL,
+			trace_id TEXT NOT NULL DEFAULT ''
+		);`,
+		`CREATE INDEX IF NOT EXISTS idx_module_decisions_repo ON module_decisions (repo, at);`,
+		`CREATE INDEX IF NOT EXISTS idx_module_decisions_at ON module_decisions (at);`,
+	}
+	for _, s := range stmts {
+		if _, err := db.Exec(s); err != nil {
+			return nil, fmt.Errorf("failed migration: %w (SQL: %s)", err, s)
+		}
+	}
+	return &DecisionLog{db: db, now: time.Now}, nil
+}
+
+// Record stores a decision, made now unless At is set.
+func (l *DecisionLog) Record(ctx context.Context, d Decision) error {
+	if d.At.IsZero() {
+		d.At = l.now()
+	}
+	_, err := l.db.ExecContext(ctx,
+		`INSERT INTO module_decisions (at, module, event, delivery, repo, outcome, reason, trace_id)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		d.At.UTC(), d.Module, d.Event, d.Delivery, d.Repo, d.Outcome, d.Reason, d.TraceID)
+	return err
+}
+
+// Query returns the decisions matching q, newest first.
+func (l *DecisionLog) Query(ctx context.Context, q DecisionQuery) ([]Decision, error) {
+	query := `SELECT ` + decisionColumns + ` FROM module_decisions WHERE 1=1`
+	var args []any
+	for _, f := range []struct{ column, value string }{
+		{"repo", q.Repo}, {"module", q.Module}, {"delivery", q.Delivery},
+	} {
+		if f.value != "" {
+			query += " AND " + f.column + " = ?"
+			args = append(args, f.value)
+		}
+	}
+	if !q.Since.IsZero() {
+		query += " AND at >= ?"
+		args = append(args, q.Since.UTC())
+	}
+	limit := q.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+	query += " ORDER BY at DESC, id DESC LIMIT ?"
+	args = append(args, limit)
+
+	return l.query(ctx, query, args...)
+}
+
+// decisionListSchema describes decisions to the admin API's list query layer.
+var decisionListSchema = ListSchema{
+	Fields: ListColumns("id", "at", "module", "event", "delivery", "repo", "outcome", "reason", "trace_id"),
+	Time:   "at",
+	Sort:   "-at",
+	Key:    "id",
+}
+
+// Select returns the decisions matching a list query.
+func (l *DecisionLog) Select(ctx context.Context, q ListQuery) ([]Decision, error) {
+	query, args := q.SQL(`SELECT ` + decisionColumns + ` FROM module_decisions`)
+	return l.query(ctx, query, args...)
+}
+
+const decisionColumns = `id, at, module, event, delivery, repo, outcome, reason, trace_id`
+
+func (l *DecisionLog) query(ctx context.Context, query string, args ...any) ([]Decision, error) {
+	rows, err := l.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, LogAndWrapError(err, ErrorTypeDatabase, "query_decisions", nil)
+	}
+	defer rows.Close()
+	decisions := []Decision{}
+	for rows.Next() {
+		var d Decision
+		if err := rows.Scan(&d.ID, &d.At, &d.Module, &d.Event, &d.Delivery, &d.Repo, &d.Outcome, &d.Reason,
+			&d.TraceID); err != nil {
+			return nil, err
+		}
+		decisions = append(decisions, d)
+	}
+	return decisions, rows.Err()
+}
+
+// Prune deletes the decisions made before the given time and returns how many it deleted.
+func (l *DecisionLog) Prune(ctx context.Context, before time.Time) (int64, error) {
+	res, err := l.db.ExecContext(ctx, `DELETE FROM module_decisions WHERE at < ?`, before.UTC())
+	if err != nil {
+		return 0, LogAndWrapError(err, ErrorTypeDatabase, "prune_decisions", nil)
+	}
+	return res.RowsAffected()
+}
+
+// Job returns the scheduler job that deletes decisions older than retention, once an hour.
+func (l *DecisionLog) Job(retention time.Duration) Job {
+	return Job{
+		Name:       DecisionPruneJobName,
+		Interval:   time.Hour,
+		Deferrable: true,
+		Run: func(ctx context.Context) error {
+			n, err := l.Prune(ctx, l.now().Add(-retention))
+			if n > 0 {
+				slog.Info("Pruned module decisions", "count", n)
+			}
+			return err
+		},
+	}
+}
+
+// RegisterAdminRoutes serves the decision log on the admin API. A nil log serves nothing.
+func (l *DecisionLog) RegisterAdminRoutes(srv *Server) {
+	if l == nil || srv == nil {
+		return
+	}
+	srv.HandleAdmin("GET /admin/decisions", ListHandler(decisionListSchema, l.Select))
+}
@@ -0,0 +1,152 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v71/github"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// decidingModule explains why it does not act on the events it is handed.
+type decidingModule struct {
+	mockModule
+}
+
+func (m *decidingModule) HandleEventContext(ctx context.Context, _ string, _ any, _ json.RawMessage) error {
+	AddDecision(ctx, "skipped: issue is locked")
+	return nil
+}
+
+func TestAddDecision(t *testing.T) {
+	db := TestDB(t)
+	log, err := NewDecisionLog(db)
+	if err != nil {
+		t.Fatalf("NewDecisionLog failed: %v", err)
+	}
+	recorder := tracetest.NewSpanRecorder()
+	telemetry := TestTelemetry(t, nil)
+	telemetry.TracerProvider = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	app := &App{ModuleRegistry: NewModuleRegistry(), Telemetry: telemetry, Decisions: log, Logger: slog.Default()}
+	app.RegisterModule(&decidingModule{mockModule{name: "sla"}})
+
+	raw := []byte(`{"action": "opened", "repository": {"full_name": "org/repo"}}`)
+	event := &github.IssuesEvent{Action: github.Ptr("opened"), Issue: &github.Issue{Number: github.Ptr(1)}}
+	app.handleEvent("delivery-1", "issues", event, raw, nil)
+
+	spans := recorder.Ended()
+	if len(spans) != 1 || spans[0].Name() != "module.sla.handle_issues" {
+		t.Fatalf("spans = %v, want the sla handler span", spans)
+	}
+	events := spans[0].Events()
+	if len(events) != 1 || events[0].Name != DecisionEventName {
+		t.Fatalf("span events = %+v", events)
+	}
+	attrs := map[string]string{}
+	for _, kv := range events[0].Attributes {
+		attrs[string(kv.Key)] = kv.Value.AsString()
+	}
+	if attrs["otto.decision.outcome"] != "skipped" || attrs["otto.decision.reason"] != "issue is locked" ||
+		attrs["otto.module"] != "sla" {
+		t.Errorf("decision attributes = %v", attrs)
+	}
+
+	decisions, err := log.Query(t.Context(), DecisionQuery{Repo: "org/repo"})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	want := Decision{
+		Module: "sla", Event: "issues", Delivery: "delivery-1", Repo: "org/repo",
+		Outcome: "skipped", Reason: "issue is locked", TraceID: spans[0].SpanContext().TraceID().String(),
+	}
+	if len(decisions) != 1 {
+		t.Fatalf("decisions = %+v", decisions)
+	}
+	got := decisions[0]
+	got.ID, got.At = 0, time.Time{}
+	if got != want {
+		t.Errorf("decision = %+v, want %+v", got, want)
+	}
+}
+
+func TestAddDecisionWithoutScope(t *testing.T) {
+	// Decisions outside of a handler, or without telemetry, are only logged.
+	AddDecision(context.Background(), "acted")
+	AddDecision(WithDecisionScope(context.Background(), nil, "sla", "issues", "", "org/repo"), "note: no timer")
+}
+
+func TestDecisionLogQueryAndPrune(t *testing.T) {
+	log, err := NewDecisionLog(TestDB(t))
+	if err != nil {
+		t.Fatalf("NewDecisionLog failed: %v", err)
+	}
+	now := time.Date(2025, 5, 10, 12, 0, 0, 0, time.UTC)
+	log.now = func() time.Time { return now }
+	ctx := t.Context()
+	for _, d := range []Decision{
+		{At: now.AddDate(0, 0, -10), Module: "sla", Repo: "org/a", Reason: "old"},
+		{At: now.Add(-2 * time.Hour), Module: "sla", Repo: "org/a", Reason: "a"},
+		{At: now.Add(-time.Hour), Module: "holds", Repo: "org/a", Reason: "b"},
+		{At: now.Add(-time.Hour), Module: "sla", Repo: "org/b", Reason: "c"},
+	} {
+		if err := log.Record(ctx, d); err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+	}
+
+	tests := []struct {
+		query      string
+		wantStatus int
+		want       []string
+	}{
+		{"", http.StatusOK, []string{"c", "b", "a", "old"}},
+		{"?repo=org/a&module=sla", http.StatusOK, []string{"a", "old"}},
+		{"?since=2025-05-09", http.StatusOK, []string{"c", "b", "a"}},
+		{"?since=2025-05-10T10:30:00Z&limit=1", http.StatusOK, []string{"c"}},
+		{"?module=holds,sla&repo=org/a&sort=reason", http.StatusOK, []string{"a", "b", "old"}},
+		{"?since=yesterday", http.StatusBadRequest, nil},
+		{"?reason_contains=a", http.StatusBadRequest, nil},
+		{"?limit=0", http.StatusBadRequest, nil},
+	}
+	list := ListHandler(decisionListSchema, log.Select)
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			list(rr, httptest.NewRequest(http.MethodGet, "/admin/decisions"+tt.query, nil))
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rr.Code, tt.wantStatus, rr.Body.String())
+			}
+			if tt.want == nil {
+				return
+			}
+			var decisions []Decision
+			if err := json.NewDecoder(rr.Body).Decode(&decisions); err != nil {
+				t.Fatalf("decode failed: %v", err)
+			}
+			var reasons []string
+			for _, d := range decisions {
+				reasons = append(reasons, d.Reason)
+			}
+			if !slices.Equal(reasons, tt.want) {
+				t.Errorf("reasons = %v, want %v", reasons, tt.want)
+			}
+		})
+	}
+
+	if err := log.Job(7 * 24 * time.Hour).Run(ctx); err != nil {
+		t.Fatalf("prune job failed: %v", err)
+	}
+	decisions, err := log.Query(ctx, DecisionQuery{})
+	if err != nil || len(decisions) != 3 {
+		t.Errorf("decisions after pruning = %+v, %v", decisions, err)
+	}
+}
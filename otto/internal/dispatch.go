@@ -0,0 +1,221 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// dispatch.go runs event dispatch on a bounded pool of workers with a queue per priority,
+// so slash commands are answered promptly while bursts of CI events wait their turn.
+
+package internal
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v71/github"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+)
+
+// Priority orders events in the dispatch pool; lower values are handled first.
+type Priority int
+
+// Event priorities.
+const (
+	PriorityInteractive Priority = iota // new comments with slash commands
+	PriorityNormal                      // everything not interactive or background
+	PriorityBackground                  // bulk events such as push and check_run
+	numPriorities
+)
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityInteractive:
+		return "interactive"
+	case PriorityNormal:
+		return "normal"
+	default:
+		return "background"
+	}
+}
+
+// dispatchTask is an event waiting for a worker.
+type dispatchTask struct {
+	run    func()
+	queued time.Time
+}
+
+// DispatchPool hands events to a fixed number of workers, taking interactive events before
+// normal ones and normal ones before background ones. A class that has been passed over
+// StarvationLimit times while it had events waiting is served next, so background events
+// are delayed but never starved.
+type DispatchPool struct {
+	cfg        config.DispatchConfig
+	telemetry  *TelemetryManager
+	background map[string]bool
+
+	mu       sync.Mutex
+	cond     *sync.Cond // signalled when tasks are queued or taken, and on Stop
+	queues   [numPriorities][]dispatchTask
+	skipped  [numPriorities]int
+	started  bool
+	stopping bool
+	wg       sync.WaitGroup
+}
+
+// NewDispatchPool creates a pool for the dispatch config. Call Start to run its workers.
+func NewDispatchPool(cfg config.DispatchConfig, telemetry *TelemetryManager) *DispatchPool {
+	p := &DispatchPool{cfg: cfg, telemetry: telemetry, background: make(map[string]bool)}
+	for _, eventType := range cfg.BackgroundEvents {
+		p.background[eventType] = true
+	}
+	p.cond = sync.NewCond(&p.mu)
+	return p
+}
+
+// Priority classifies an event: new comments with slash commands are interactive, and
+// the configured background event types are background. Commands in the comment are
+// read after aliases are applied, so pass the routed event.
+func (p *DispatchPool) Priority(eventType string, event any) Priority {
+	if e, ok := event.(*github.IssueCommentEvent); ok && e.GetAction() == "created" &&
+		len(ParseSlashCommands(e.GetComment().GetBody())) > 0 {
+		return PriorityInteractive
+	}
+	if p != nil && p.background[eventType] {
+		return PriorityBackground
+	}
+	return PriorityNormal
+}
+
+// Start runs the workers.
+func (p *DispatchPool) Start() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.started {
+		return
+	}
+	p.started = true
+	for range max(p.cfg.Workers, 1) {
+		p.wg.Add(1)
+		go p.work()
+	}
+}
+
+// Stop stops accepting events and waits until the workers have handled the queued ones,
+// or ctx is done.
+func (p *DispatchPool) Stop(ctx context.Context) error {
+	p.mu.Lock()
+	p.stopping = true
+	p.cond.Broadcast()
+	p.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Submit queues run with priority, blocking while that priority's queue is full. Without
+// a pool, or once the pool is stopping, run starts in its own goroutine.
+func (p *DispatchPool) Submit(priority Priority, run func()) {
+	if p == nil {
+		go run()
+		return
+	}
+	p.mu.Lock()
+	for !p.stopping && p.cfg.QueueSize > 0 && len(p.queues[priority]) >= p.cfg.QueueSize {
+		p.cond.Wait()
+	}
+	if p.stopping {
+		p.mu.Unlock()
+		go run()
+		return
+	}
+	p.queues[priority] = append(p.queues[priority], dispatchTask{run: run, queued: time.Now()})
+	p.cond.Broadcast()
+	p.mu.Unlock()
+	if p.telemetry != nil {
+		p.telemetry.AddDispatchQueued(context.Background(), priority.String(), 1)
+	}
+}
+
+// Depth returns the number of events waiting with priority.
+func (p *DispatchPool) Depth(priority Priority) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.queues[priority])
+}
+
+// work runs queued tasks until the pool is stopping and the queues are empty.
+func (p *DispatchPool) work() {
+	defer p.wg.Done()
+	for {
+		priority, task, ok := p.next()
+		if !ok {
+			return
+		}
+		if p.telemetry != nil {
+			ctx := context.Background()
+			p.telemetry.AddDispatchQueued(ctx, priority.String(), -1)
+			p.telemetry.RecordDispatchWait(ctx, priority.String(), float64(time.Since(task.queued).Milliseconds()))
+		}
+		task.run()
+	}
+}
+
+// next blocks until a task is queued and takes the one to run next.
+func (p *DispatchPool) next() (Priority, dispatchTask, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for {
+		if priority, ok := p.pick(); ok {
+			task := p.queues[priority][0]
+			p.queues[priority][0] = dispatchTask{}
+			p.queues[priority] = p.queues[priority][1:]
+			p.cond.Broadcast()
+			return priority, task, true
+		}
+		if p.stopping {
+			return 0, dispatchTask{}, false
+		}
+		p.cond.Wait()
+	}
+}
+
+// pick chooses the priority to serve: the lowest one that has been passed over
+// StarvationLimit times, or else the highest one with queued tasks. Classes with queued
+// tasks below the chosen one count as passed over. The caller holds p.mu.
+func (p *DispatchPool) pick() (Priority, bool) {
+	chosen := numPriorities
+	if p.cfg.StarvationLimit > 0 {
+		for c := numPriorities - 1; c > PriorityInteractive; c-- {
+			if len(p.queues[c]) > 0 && p.skipped[c] >= p.cfg.StarvationLimit {
+				chosen = c
+				break
+			}
+		}
+	}
+	if chosen == numPriorities {
+		for c := range numPriorities {
+			if len(p.queues[c]) > 0 {
+				chosen = c
+				break
+			}
+		}
+	}
+	if chosen == numPriorities {
+		return 0, false
+	}
+	p.skipped[chosen] = 0
+	for c := chosen + 1; c < numPriorities; c++ {
+		if len(p.queues[c]) > 0 {
+			p.skipped[c]++
+		}
+	}
+	return chosen, true
+}
@@ -0,0 +1,174 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v71/github"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+)
+
+func TestDispatchPriority(t *testing.T) {
+	pool := NewDispatchPool(config.DispatchConfig{BackgroundEvents: []string{"push", "check_run"}}, nil)
+	comment := func(action, body string) *github.IssueCommentEvent {
+		return &github.IssueCommentEvent{
+			Action:  github.Ptr(action),
+			Comment: &github.IssueComment{Body: github.Ptr(body)},
+		}
+	}
+	tests := []struct {
+		name      string
+		eventType string
+		event     any
+		want      Priority
+	}{
+		{"slash command", "issue_comment", comment("created", "/approve"), PriorityInteractive},
+		{"comment without command", "issue_comment", comment("created", "looks good"), PriorityNormal},
+		{"edited command", "issue_comment", comment("edited", "/approve"), PriorityNormal},
+		{"issue", "issues", &github.IssuesEvent{}, PriorityNormal},
+		{"push", "push", &github.PushEvent{}, PriorityBackground},
+		{"check run", "check_run", &github.CheckRunEvent{}, PriorityBackground},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pool.Priority(tt.eventType, tt.event); got != tt.want {
+				t.Errorf("Priority() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDispatchPoolOrder(t *testing.T) {
+	pool := NewDispatchPool(config.DispatchConfig{Workers: 1, StarvationLimit: 2}, nil)
+	var (
+		mu    sync.Mutex
+		order []string
+	)
+	submit := func(priority Priority, name string) {
+		pool.Submit(priority, func() {
+			mu.Lock()
+			order = append(order, name)
+			mu.Unlock()
+		})
+	}
+	for _, name := range []string{"b1", "b2", "b3"} {
+		submit(PriorityBackground, name)
+	}
+	submit(PriorityNormal, "n1")
+	for _, name := range []string{"i1", "i2", "i3", "i4"} {
+		submit(PriorityInteractive, name)
+	}
+	if got := pool.Depth(PriorityInteractive); got != 4 {
+		t.Errorf("Depth(interactive) = %d, want 4", got)
+	}
+
+	// Interactive events go first, but a priority passed over twice goes next.
+	pool.Start()
+	if err := pool.Stop(t.Context()); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+	want := []string{"i1", "i2", "b1", "n1", "i3", "b2", "i4", "b3"}
+	if !slices.Equal(order, want) {
+		t.Errorf("order = %v, want %v", order, want)
+	}
+}
+
+func TestDispatchPoolBackpressure(t *testing.T) {
+	pool := NewDispatchPool(config.DispatchConfig{Workers: 1, QueueSize: 1}, nil)
+	pool.Submit(PriorityBackground, func() {})
+
+	submitted := make(chan struct{})
+	go func() {
+		pool.Submit(PriorityBackground, func() {})
+		close(submitted)
+	}()
+	// Other priorities have their own queues.
+	pool.Submit(PriorityInteractive, func() {})
+	select {
+	case <-submitted:
+		t.Fatal("Submit did not wait for room in a full queue")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	pool.Start()
+	select {
+	case <-submitted:
+	case <-time.After(time.Second):
+		t.Fatal("Submit still blocked after workers started")
+	}
+	if err := pool.Stop(t.Context()); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+}
+
+func TestDispatchPoolStop(t *testing.T) {
+	pool := NewDispatchPool(config.DispatchConfig{Workers: 1}, nil)
+	release := make(chan struct{})
+	pool.Submit(PriorityNormal, func() { <-release })
+	pool.Start()
+
+	ctx, cancel := context.WithTimeout(t.Context(), 20*time.Millisecond)
+	defer cancel()
+	if err := pool.Stop(ctx); err == nil {
+		t.Error("Stop returned before the running event finished")
+	}
+	close(release)
+	if err := pool.Stop(t.Context()); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+
+	// Events submitted while stopping still run.
+	ran := make(chan struct{})
+	pool.Submit(PriorityInteractive, func() { close(ran) })
+	select {
+	case <-ran:
+	case <-time.After(time.Second):
+		t.Fatal("event submitted after Stop did not run")
+	}
+}
+
+func TestDispatchPoolMetrics(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	pool := NewDispatchPool(config.DispatchConfig{Workers: 1}, TestTelemetry(t, reader))
+	pool.Submit(PriorityInteractive, func() {})
+	pool.Submit(PriorityBackground, func() {})
+
+	depths := func() map[string]int64 {
+		var rm metricdata.ResourceMetrics
+		if err := reader.Collect(t.Context(), &rm); err != nil {
+			t.Fatalf("failed to collect metrics: %v", err)
+		}
+		out := make(map[string]int64)
+		for _, sm := range rm.ScopeMetrics {
+			for _, m := range sm.Metrics {
+				sum, ok := m.Data.(metricdata.Sum[int64])
+				if m.Name != "otto.dispatch.queue_depth" || !ok {
+					continue
+				}
+				for _, dp := range sum.DataPoints {
+					priority, _ := dp.Attributes.Value("priority")
+					out[priority.AsString()] = dp.Value
+				}
+			}
+		}
+		return out
+	}
+	if got := depths(); got["interactive"] != 1 || got["background"] != 1 {
+		t.Errorf("queue depths before start = %v", got)
+	}
+	pool.Start()
+	if err := pool.Stop(t.Context()); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+	if got := depths(); got["interactive"] != 0 || got["background"] != 0 {
+		t.Errorf("queue depths after draining = %v", got)
+	}
+}
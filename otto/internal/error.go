@@ -97,6 +97,14 @@ func logAppError(err *AppError) {
 		attrs = append(attrs, k, v)
 	}
 
+	// Module and command failures during a known GitHub incident are expected; log them
+	// with the incident instead of as new problems.
+	incident := KnownGitHubIncident()
+	if incident != "" && (err.Type == ErrorTypeModule || err.Type == ErrorTypeCommand) {
+		slog.Info(err.Error(), append(attrs, "github_incident", incident)...)
+		return
+	}
+
 	// Determine appropriate log level based on error type
 	switch err.Type {
 	case ErrorTypeConfig, ErrorTypeDatabase, ErrorTypeServer:
@@ -0,0 +1,258 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// errorreport.go sends panics and module errors to an error tracking service such as
+// Sentry, with the module, job and webhook delivery they happened in, so crashes are
+// grouped and counted somewhere actionable instead of only showing up in logs.
+
+package internal
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v71/github"
+)
+
+// SentryDSNSecret is the secrets name of the Sentry DSN errors are reported to.
+const SentryDSNSecret = "sentry_dsn"
+
+// ErrorReport is a panic or module error, with where it happened.
+type ErrorReport struct {
+	Err      error // a *PanicError for panics
+	Module   string
+	Job      string
+	Event    string // webhook event type or normalized event kind
+	Delivery string
+	Repo     string
+}
+
+// Panic returns the recovered panic the report is for, or nil.
+func (r ErrorReport) Panic() *PanicError {
+	var p *PanicError
+	errors.As(r.Err, &p)
+	return p
+}
+
+// PanicError is a panic recovered by RecoverPanic.
+type PanicError struct {
+	Value any
+	Stack []byte // the stack of the goroutine that panicked
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// ErrorReporter sends error reports to an error tracking service.
+type ErrorReporter interface {
+	Report(ctx context.Context, report ErrorReport) error
+}
+
+// ErrorReports hands panics and module errors to a reporter without blocking the handler
+// they happened in. Errors that may go away on their own, such as rate limits and GitHub
+// server errors, are not reported. A nil ErrorReports reports nothing.
+type ErrorReports struct {
+	reporter ErrorReporter
+	timeout  time.Duration
+	wg       sync.WaitGroup
+}
+
+// NewErrorReports creates error reporting to reporter.
+func NewErrorReports(reporter ErrorReporter) *ErrorReports {
+	return &ErrorReports{reporter: reporter, timeout: 10 * time.Second}
+}
+
+// Report sends a report in the background.
+func (r *ErrorReports) Report(report ErrorReport) {
+	if r == nil || report.Err == nil || (report.Panic() == nil && transientError(report.Err)) {
+		return
+	}
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+		defer cancel()
+		if err := r.reporter.Report(ctx, report); err != nil {
+			slog.Warn("Failed to report error", "module", report.Module, "error", err)
+		}
+	}()
+}
+
+// RecoverPanic is deferred by module handlers and jobs to turn a panic into a *PanicError
+// in *err, logged with its stack, for the caller to report like other errors.
+func RecoverPanic(module string, err *error) {
+	v := recover()
+	if v == nil {
+		return
+	}
+	p := &PanicError{Value: v, Stack: debug.Stack()}
+	slog.Error("Recovered from panic", "module", module, "panic", v, "stack", string(p.Stack))
+	*err = p
+}
+
+// Close waits for reports still being sent.
+func (r *ErrorReports) Close() {
+	if r != nil {
+		r.wg.Wait()
+	}
+}
+
+// transientError reports whether an error is likely to go away on its own: cancellation,
+// exhausted budgets and rate limits, network errors, GitHub server errors, and any error
+// during a known GitHub incident.
+func transientError(err error) bool {
+	if KnownGitHubIncident() != "" || errors.Is(err, context.Canceled) ||
+		errors.Is(err, context.DeadlineExceeded) || errors.Is(err, ErrAPIBudgetExhausted) {
+		return true
+	}
+	var (
+		rateLimit  *github.RateLimitError
+		abuseLimit *github.AbuseRateLimitError
+		netErr     net.Error
+		respErr    *github.ErrorResponse
+	)
+	if errors.As(err, &rateLimit) || errors.As(err, &abuseLimit) || errors.As(err, &netErr) {
+		return true
+	}
+	return errors.As(err, &respErr) && respErr.Response != nil && respErr.Response.StatusCode >= 500
+}
+
+// SentryReporter sends error reports to Sentry as events in envelopes, the format of
+// Sentry's ingestion API.
+type SentryReporter struct {
+	dsn         string
+	endpoint    string // the project's envelope endpoint
+	key         string // the DSN's public key
+	environment string
+	serverName  string
+	client      *http.Client
+}
+
+// NewSentryReporter creates a reporter for a Sentry DSN,
+// https://<key>@<host>/<project>, sending events with client. Events are tagged with
+// environment and serverName.
+func NewSentryReporter(dsn, environment, serverName string, client *http.Client) (*SentryReporter, error) {
+	u, err := url.Parse(dsn)
+	if err != nil || u.User == nil || u.User.Username() == "" || u.Host == "" {
+		return nil, errors.New("invalid Sentry DSN: want https://<key>@<host>/<project>")
+	}
+	prefix, project := "", strings.Trim(u.Path, "/")
+	if i := strings.LastIndex(project, "/"); i >= 0 {
+		prefix, project = project[:i+1], project[i+1:]
+	}
+	if project == "" {
+		return nil, errors.New("invalid Sentry DSN: no project")
+	}
+	return &SentryReporter{
+		dsn:         dsn,
+		endpoint:    fmt.Sprintf("%s://%s/%sapi/%s/envelope/", u.Scheme, u.Host, prefix, project),
+		key:         u.User.Username(),
+		environment: environment,
+		serverName:  serverName,
+		client:      client,
+	}, nil
+}
+
+// sentryEvent is the subset of the Sentry event payload Otto sends.
+type sentryEvent struct {
+	EventID     string            `json:"event_id"`
+	Timestamp   time.Time         `json:"timestamp"`
+	Platform    string            `json:"platform"`
+	Level       string            `json:"level"`
+	Logger      string            `json:"logger"`
+	Release     string            `json:"release"`
+	Environment string            `json:"environment,omitempty"`
+	ServerName  string            `json:"server_name,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	Extra       map[string]string `json:"extra,omitempty"`
+	Exception   struct {
+		Values []sentryException `json:"values"`
+	} `json:"exception"`
+}
+
+type sentryException struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// Report sends one report as a Sentry event.
+func (s *SentryReporter) Report(ctx context.Context, report ErrorReport) error {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	event := sentryEvent{
+		EventID:     hex.EncodeToString(id),
+		Timestamp:   time.Now().UTC(),
+		Platform:    "go",
+		Level:       "error",
+		Logger:      "otto",
+		Release:     "otto@" + BuildVersion(),
+		Environment: s.environment,
+		ServerName:  s.serverName,
+		Tags:        make(map[string]string),
+	}
+	for tag, value := range map[string]string{
+		"module": report.Module, "job": report.Job, "event": report.Event,
+		"delivery": report.Delivery, "repo": report.Repo,
+	} {
+		if value != "" {
+			event.Tags[tag] = value
+		}
+	}
+	// Sentry groups events by exception type and value; the innermost error's type is
+	// the most specific.
+	root := report.Err
+	for errors.Unwrap(root) != nil {
+		root = errors.Unwrap(root)
+	}
+	exception := sentryException{Type: fmt.Sprintf("%T", root), Value: report.Err.Error()}
+	if p := report.Panic(); p != nil {
+		event.Level = "fatal"
+		exception.Type = "panic"
+		event.Extra = map[string]string{"stack": string(p.Stack)}
+	}
+	event.Exception.Values = []sentryException{exception}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	header, _ := json.Marshal(map[string]any{"event_id": event.EventID, "sent_at": event.Timestamp, "dsn": s.dsn})
+	item, _ := json.Marshal(map[string]any{"type": "event", "length": len(payload)})
+	var body bytes.Buffer
+	for _, line := range [][]byte{header, item, payload} {
+		body.Write(line)
+		body.WriteByte('\n')
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-sentry-envelope")
+	req.Header.Set("X-Sentry-Auth", fmt.Sprintf("Sentry sentry_version=7, sentry_client=otto/%s, sentry_key=%s",
+		BuildVersion(), s.key))
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send Sentry event: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("sentry returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v71/github"
+)
+
+// recordingReporter keeps the reports it is sent.
+type recordingReporter struct {
+	mu      sync.Mutex
+	reports []ErrorReport
+}
+
+func (r *recordingReporter) Report(_ context.Context, report ErrorReport) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reports = append(r.reports, report)
+	return nil
+}
+
+func TestSentryReporter(t *testing.T) {
+	var (
+		path, auth string
+		lines      []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, auth = r.URL.Path, r.Header.Get("X-Sentry-Auth")
+		scanner := bufio.NewScanner(r.Body)
+		scanner.Buffer(nil, 1<<20)
+		for scanner.Scan() {
+			lines = append(lines, scanner.Text())
+		}
+	}))
+	defer srv.Close()
+
+	dsn := strings.Replace(srv.URL, "http://", "http://public@", 1) + "/sentry/42"
+	sentry, err := NewSentryReporter(dsn, "production", "otto-1", srv.Client())
+	if err != nil {
+		t.Fatalf("NewSentryReporter failed: %v", err)
+	}
+	var panicErr error
+	func() {
+		defer RecoverPanic("sla", &panicErr)
+		var m map[string]int
+		m["x"]++
+	}()
+	report := ErrorReport{Err: panicErr, Module: "sla", Event: "issues", Delivery: "d-1", Repo: "org/a"}
+	if err := sentry.Report(t.Context(), report); err != nil {
+		t.Fatalf("Report failed: %v", err)
+	}
+
+	if path != "/sentry/api/42/envelope/" || !strings.Contains(auth, "sentry_key=public") {
+		t.Errorf("path = %q, auth = %q", path, auth)
+	}
+	if len(lines) != 3 {
+		t.Fatalf("envelope has %d lines, want 3", len(lines))
+	}
+	var event sentryEvent
+	if err := json.Unmarshal([]byte(lines[2]), &event); err != nil {
+		t.Fatalf("invalid event: %v", err)
+	}
+	if event.Level != "fatal" || event.Environment != "production" || event.ServerName != "otto-1" ||
+		event.Tags["module"] != "sla" || event.Tags["delivery"] != "d-1" || event.Tags["job"] != "" {
+		t.Errorf("event = %+v", event)
+	}
+	if e := event.Exception.Values; len(e) != 1 || e[0].Type != "panic" || !strings.Contains(e[0].Value, "nil map") {
+		t.Errorf("exception = %+v", e)
+	}
+	if !strings.Contains(event.Extra["stack"], "TestSentryReporter") {
+		t.Errorf("stack missing from %v", event.Extra)
+	}
+
+	for _, dsn := range []string{"https://sentry.io/1", "https://key@sentry.io/", "::"} {
+		if _, err := NewSentryReporter(dsn, "", "", nil); err == nil {
+			t.Errorf("NewSentryReporter(%q) succeeded, want error", dsn)
+		}
+	}
+}
+
+func TestErrorReports(t *testing.T) {
+	reporter := &recordingReporter{}
+	reports := NewErrorReports(reporter)
+	serverErr := &github.ErrorResponse{Response: &http.Response{StatusCode: http.StatusBadGateway}}
+	for _, err := range []error{
+		nil,
+		context.Canceled,
+		fmt.Errorf("label: %w", serverErr),
+		&github.RateLimitError{},
+		fmt.Errorf("sla: %w", ErrAPIBudgetExhausted),
+	} {
+		reports.Report(ErrorReport{Err: err, Module: "sla"})
+	}
+	notFound := &github.ErrorResponse{Response: &http.Response{StatusCode: http.StatusNotFound}}
+	reports.Report(ErrorReport{Err: fmt.Errorf("comment: %w", notFound), Module: "sla"})
+	reports.Close()
+	if len(reporter.reports) != 1 || !errors.Is(reporter.reports[0].Err, notFound) {
+		t.Errorf("reports = %+v, want only the 404", reporter.reports)
+	}
+
+	var none *ErrorReports
+	none.Report(ErrorReport{Err: errors.New("boom")})
+	none.Close()
+}
+
+// panickingModule panics on every event.
+type panickingModule struct{}
+
+func (panickingModule) Name() string { return "broken" }
+
+func (panickingModule) HandleEvent(string, any, json.RawMessage) error { panic("boom") }
+
+func TestCallModuleRecoversPanics(t *testing.T) {
+	reporter := &recordingReporter{}
+	app := &App{Errors: NewErrorReports(reporter)}
+	err := app.callModule("broken", panickingModule{}, "d-7", "issues", "org/a", nil, nil, nil)
+	var p *PanicError
+	if !errors.As(err, &p) || p.Value != "boom" {
+		t.Fatalf("callModule = %v, want a recovered panic", err)
+	}
+	app.Errors.Close()
+	if len(reporter.reports) != 1 || reporter.reports[0].Delivery != "d-7" || reporter.reports[0].Panic() == nil {
+		t.Errorf("reports = %+v", reporter.reports)
+	}
+
+	// Panicking jobs are recovered and reported by the scheduler.
+	scheduler := NewScheduler(nil)
+	scheduler.ReportErrors(app.Errors)
+	scheduler.runJob(t.Context(), Job{Name: "sweep", Module: "broken", Interval: time.Hour,
+		Run: func(context.Context) error { panic("job boom") }})
+	app.Errors.Close()
+	if len(reporter.reports) != 2 || reporter.reports[1].Job != "sweep" || reporter.reports[1].Panic() == nil {
+		t.Errorf("reports = %+v", reporter.reports)
+	}
+}
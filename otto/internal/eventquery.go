@@ -0,0 +1,185 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// eventquery.go parses the small query language used by `otto query`, e.g.
+//
+//	events where repo='collector' and type='issues' last 24h
+//
+// into an EventQuery.
+
+package internal
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ParseEventQuery parses a query of the form
+//
+//	events [where <field>=<value> [and ...]] [last <duration>] [since <time>] [until <time>] [limit <n>]
+//
+// Fields are repo, type, action and sender. Values may be quoted with single or double
+// quotes. Durations accept Go syntax plus a "d" suffix for days; times are RFC 3339 or
+// YYYY-MM-DD. now anchors "last".
+func ParseEventQuery(input string, now time.Time) (EventQuery, error) {
+	tokens, err := tokenizeEventQuery(input)
+	if err != nil {
+		return EventQuery{}, err
+	}
+	p := &eventQueryParser{tokens: tokens}
+	var q EventQuery
+
+	if tok, ok := p.next(); !ok || !strings.EqualFold(tok, "events") {
+		return q, fmt.Errorf("query must start with \"events\"")
+	}
+	for {
+		keyword, ok := p.next()
+		if !ok {
+			return q, nil
+		}
+		switch strings.ToLower(keyword) {
+		case "where", "and":
+			if err := p.condition(&q); err != nil {
+				return q, err
+			}
+		case "last":
+			d, err := p.duration()
+			if err != nil {
+				return q, err
+			}
+			q.Since = now.Add(-d)
+		case "since":
+			if q.Since, err = p.time(); err != nil {
+				return q, err
+			}
+		case "until":
+			if q.Until, err = p.time(); err != nil {
+				return q, err
+			}
+		case "limit":
+			v, ok := p.next()
+			n, err := strconv.Atoi(v)
+			if !ok || err != nil || n <= 0 {
+				return q, fmt.Errorf("limit needs a positive number")
+			}
+			q.Limit = n
+		default:
+			return q, fmt.Errorf("unexpected %q", keyword)
+		}
+	}
+}
+
+type eventQueryParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *eventQueryParser) next() (string, bool) {
+	if p.pos >= len(p.tokens) {
+		return "", false
+	}
+	p.pos++
+	return p.tokens[p.pos-1], true
+}
+
+// condition parses field=value.
+func (p *eventQueryParser) condition(q *EventQuery) error {
+	field, ok := p.next()
+	if !ok {
+		return fmt.Errorf("expected a condition after \"where\"/\"and\"")
+	}
+	if op, ok := p.next(); !ok || op != "=" {
+		return fmt.Errorf("expected = after %q", field)
+	}
+	value, ok := p.next()
+	if !ok {
+		return fmt.Errorf("expected a value for %q", field)
+	}
+	switch strings.ToLower(field) {
+	case "repo":
+		q.Repo = value
+	case "type":
+		q.Type = value
+	case "action":
+		q.Action = value
+	case "sender":
+		q.Sender = value
+	default:
+		return fmt.Errorf("unknown field %q (use repo, type, action or sender)", field)
+	}
+	return nil
+}
+
+// duration parses a Go duration or a number of days ("7d").
+func (p *eventQueryParser) duration() (time.Duration, error) {
+	v, ok := p.next()
+	if !ok {
+		return 0, fmt.Errorf("expected a duration after \"last\"")
+	}
+	if days, found := strings.CutSuffix(v, "d"); found {
+		if n, err := strconv.Atoi(days); err == nil && n > 0 {
+			return time.Duration(n) * 24 * time.Hour, nil
+		}
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid duration %q", v)
+	}
+	return d, nil
+}
+
+// time parses an RFC 3339 timestamp or a date.
+func (p *eventQueryParser) time() (time.Time, error) {
+	v, ok := p.next()
+	if !ok {
+		return time.Time{}, fmt.Errorf("expected a time")
+	}
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if t, err := time.Parse(layout, v); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q (use RFC 3339 or YYYY-MM-DD)", v)
+}
+
+// tokenizeEventQuery splits a query into words, "=" and quoted strings.
+func tokenizeEventQuery(input string) ([]string, error) {
+	var (
+		tokens []string
+		cur    strings.Builder
+	)
+	flush := func() {
+		if cur.Len() > 0 {
+			tokens = append(tokens, cur.String())
+			cur.Reset()
+		}
+	}
+	runes := []rune(input)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			flush()
+		case r == '=':
+			flush()
+			tokens = append(tokens, "=")
+		case r == '\'' || r == '"':
+			flush()
+			end := i + 1
+			for end < len(runes) && runes[end] != r {
+				end++
+			}
+			if end == len(runes) {
+				return nil, fmt.Errorf("unterminated quote")
+			}
+			tokens = append(tokens, string(runes[i+1:end]))
+			i = end
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	flush()
+	return tokens, nil
+}
@@ -0,0 +1,60 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseEventQuery(t *testing.T) {
+	now := time.Date(2025, 5, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		input   string
+		want    EventQuery
+		wantErr bool
+	}{
+		{"all events", "events", EventQuery{}, false},
+		{
+			"conditions and window",
+			"events where repo='collector' and type='issues' last 24h",
+			EventQuery{Repo: "collector", Type: "issues", Since: now.Add(-24 * time.Hour)},
+			false,
+		},
+		{
+			"unspaced and double quoted",
+			`EVENTS WHERE action="opened" AND sender=alice last 7d limit 5`,
+			EventQuery{Action: "opened", Sender: "alice", Since: now.Add(-7 * 24 * time.Hour), Limit: 5},
+			false,
+		},
+		{
+			"absolute range",
+			"events where repo='org/repo' since 2025-05-01 until 2025-05-02T06:00:00Z",
+			EventQuery{
+				Repo:  "org/repo",
+				Since: time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC),
+				Until: time.Date(2025, 5, 2, 6, 0, 0, 0, time.UTC),
+			},
+			false,
+		},
+		{"missing events keyword", "issues where repo='x'", EventQuery{}, true},
+		{"unknown field", "events where author='x'", EventQuery{}, true},
+		{"missing operator", "events where repo 'x'", EventQuery{}, true},
+		{"bad duration", "events last soon", EventQuery{}, true},
+		{"bad limit", "events limit -1", EventQuery{}, true},
+		{"unterminated quote", "events where repo='x", EventQuery{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseEventQuery(tt.input, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,319 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// eventqueue.go persists webhook deliveries until the modules have handled them, so that a
+// failing module or a restart does not lose an event. Deliveries are handled on the
+// dispatch pool; modules that fail are handed the delivery again with exponential backoff.
+
+package internal
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+)
+
+// EventQueueJobName is the scheduler name of the job that retries failed deliveries.
+const EventQueueJobName = "event_queue"
+
+// Queued delivery statuses.
+const (
+	QueuedDispatched = "dispatched" // handed to the dispatch pool
+	QueuedRetry      = "retry"      // waiting for its next attempt
+	QueuedDone       = "done"       // handled by every module
+	QueuedFailed     = "failed"     // given up after its last attempt
+)
+
+// QueuedEvent is a webhook delivery in the event queue.
+type QueuedEvent struct {
+	ID            int64     `json:"id"`
+	DeliveryID    string    `json:"delivery_id,omitempty"`
+	Type          string    `json:"type"`
+	Repo          string    `json:"repo,omitempty"`
+	EventID       int64     `json:"event_id,omitempty"` // the delivery in the event store, if recorded there
+	Payload       []byte    `json:"-"`                  // kept only for deliveries not in the event store
+	Status        string    `json:"status"`
+	Attempts      int       `json:"attempts"`
+	Modules       []string  `json:"modules,omitempty"` // modules still to handle it; nil for all
+	Error         string    `json:"error,omitempty"`
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// EventQueue reads and writes the event_queue table.
+type EventQueue struct {
+	db  *sql.DB
+	cfg config.EventQueueConfig
+	now func() time.Time
+}
+
+// NewEventQueue creates the event queue, creating its table if needed.
+func NewEventQueue(db *sql.DB, cfg config.EventQueueConfig) (*EventQueue, error) {
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS event_queue (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			delivery_id TEXT NOT NULL DEFAULT '',
+			event_type TEXT NOT NULL,
+			repo TEXT NOT NULL DEFAULT '',
+			payload BLOB,
+			status TEXT NOT NULL,
+			attempts INTEGER NOT NULL DEFAULT 0,
+			modules TEXT,
+			error TEXT NOT NULL DEFAULT '',
+			next_attempt_at TIMESTAMP NOT NULL,
+			created_at TIMESTAMP NOT NULL,
+			updated_at TIMESTAMP NOT NULL
+		);`,
+		`CREATE INDEX IF NOT EXISTS idx_event_queue_status ON event_queue (status, next_attempt_at);`,
+	}
+	for _, s := range stmts {
+		if _, err := db.Exec(s); err != nil {
+			return nil, fmt.Errorf("failed migration: %w (SQL: %s)", err, s)
+		}
+	}
+	if err := ensureColumn(db, "event_queue", "event_id", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return nil, err
+	}
+	return &EventQueue{db: db, cfg: cfg, now: time.Now}, nil
+}
+
+const queuedEventColumns = `id, delivery_id, event_type, repo, event_id, payload, status, attempts, modules,
+	error, next_attempt_at, created_at, updated_at`
+
+func scanQueuedEvent(row interface{ Scan(...any) error }) (QueuedEvent, error) {
+	var (
+		e       QueuedEvent
+		modules sql.NullString
+	)
+	err := row.Scan(&e.ID, &e.DeliveryID, &e.Type, &e.Repo, &e.EventID, &e.Payload, &e.Status, &e.Attempts,
+		&modules, &e.Error, &e.NextAttemptAt, &e.CreatedAt, &e.UpdatedAt)
+	if err != nil {
+		return e, err
+	}
+	if modules.Valid {
+		if err := json.Unmarshal([]byte(modules.String), &e.Modules); err != nil {
+			return e, fmt.Errorf("queued event %d: invalid modules: %w", e.ID, err)
+		}
+	}
+	return e, nil
+}
+
+// Enqueue persists a delivery that is being handed to the dispatch pool and returns its ID.
+// A delivery recorded in the event store, under eventID, is referred to there; the payload
+// is kept in the queue only for one that is not (eventID 0).
+func (q *EventQueue) Enqueue(ctx context.Context, delivery, eventType string, eventID int64,
+	payload []byte) (int64, error) {
+	now := q.now().UTC()
+	kept := payload
+	if eventID != 0 {
+		kept = nil
+	}
+	res, err := q.db.ExecContext(ctx,
+		`INSERT INTO event_queue (delivery_id, event_type, repo, event_id, payload, status, next_attempt_at,
+			created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		delivery, eventType, eventRepo(payload), eventID, kept, QueuedDispatched, now, now, now)
+	if err != nil {
+		return 0, LogAndWrapError(err, ErrorTypeDatabase, "enqueue_event", map[string]any{
+			"event_type": eventType,
+			"delivery":   delivery,
+		})
+	}
+	return res.LastInsertId()
+}
+
+// Get returns a queued delivery by ID, or sql.ErrNoRows.
+func (q *EventQueue) Get(ctx context.Context, id int64) (QueuedEvent, error) {
+	return scanQueuedEvent(q.db.QueryRowContext(ctx,
+		`SELECT `+queuedEventColumns+` FROM event_queue WHERE id = ?`, id))
+}
+
+// Complete records the outcome of an attempt at a delivery: failed maps the modules that
+// failed to their errors. Without failures, the delivery is done and its payload dropped.
+// Otherwise it is retried for the failed modules after a backoff, or given up once it has
+// run out of attempts.
+func (q *EventQueue) Complete(ctx context.Context, id int64, failed map[string]error) error {
+	e, err := q.Get(ctx, id)
+	if err != nil {
+		return LogAndWrapError(err, ErrorTypeDatabase, "get_queued_event", map[string]any{"id": id})
+	}
+	now := q.now().UTC()
+	e.Attempts++
+	e.Modules = slices.Sorted(maps.Keys(failed))
+	errs := make([]string, len(e.Modules))
+	for i, module := range e.Modules {
+		errs[i] = fmt.Sprintf("%s: %v", module, failed[module])
+	}
+	e.Error = strings.Join(errs, "; ")
+
+	switch {
+	case len(failed) == 0:
+		e.Status, e.Payload = QueuedDone, nil
+	case e.Attempts >= q.cfg.MaxAttempts:
+		e.Status = QueuedFailed
+		slog.ErrorContext(ctx, "Giving up on event", "id", e.ID, "event", e.Type, "delivery", e.DeliveryID,
+			"repo", e.Repo, "attempts", e.Attempts, "modules", e.Modules, "error", e.Error)
+	default:
+		e.Status, e.NextAttemptAt = QueuedRetry, now.Add(q.backoff(e.Attempts))
+		slog.WarnContext(ctx, "Event handling failed, will retry", "id", e.ID, "event", e.Type,
+			"delivery", e.DeliveryID, "repo", e.Repo, "attempts", e.Attempts, "modules", e.Modules,
+			"next_attempt_at", e.NextAttemptAt)
+	}
+	var modules any
+	if e.Modules != nil {
+		data, err := json.Marshal(e.Modules)
+		if err != nil {
+			return err
+		}
+		modules = string(data)
+	}
+	if _, err := q.db.ExecContext(ctx,
+		`UPDATE event_queue SET payload = ?, status = ?, attempts = ?, modules = ?, error = ?, next_attempt_at = ?,
+			updated_at = ? WHERE id = ?`,
+		e.Payload, e.Status, e.Attempts, modules, e.Error, e.NextAttemptAt, now, e.ID); err != nil {
+		return LogAndWrapError(err, ErrorTypeDatabase, "update_queued_event", map[string]any{"id": id})
+	}
+	return nil
+}
+
+// GiveUp gives up on a delivery without retrying it, e.g. one that cannot be parsed.
+func (q *EventQueue) GiveUp(ctx context.Context, id int64, reason error) error {
+	if _, err := q.db.ExecContext(ctx, `UPDATE event_queue SET status = ?, error = ?, updated_at = ? WHERE id = ?`,
+		QueuedFailed, reason.Error(), q.now().UTC(), id); err != nil {
+		return LogAndWrapError(err, ErrorTypeDatabase, "update_queued_event", map[string]any{"id": id})
+	}
+	return nil
+}
+
+// backoff returns the wait before the attempt after a number of failed ones.
+func (q *EventQueue) backoff(attempts int) time.Duration {
+	d := q.cfg.Backoff
+	for i := 1; i < attempts && d < q.cfg.MaxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, q.cfg.MaxBackoff)
+}
+
+// Recover marks the deliveries that were handed to the dispatch pool, but not completed,
+// when Otto last stopped as due for a retry. Call it before new deliveries are queued.
+func (q *EventQueue) Recover(ctx context.Context) (int64, error) {
+	now := q.now().UTC()
+	res, err := q.db.ExecContext(ctx,
+		`UPDATE event_queue SET status = ?, next_attempt_at = ?, updated_at = ? WHERE status = ?`,
+		QueuedRetry, now, now, QueuedDispatched)
+	if err != nil {
+		return 0, LogAndWrapError(err, ErrorTypeDatabase, "recover_queued_events", nil)
+	}
+	return res.RowsAffected()
+}
+
+// claimDue returns the deliveries due for a retry, marking them dispatched.
+func (q *EventQueue) claimDue(ctx context.Context, limit int) ([]QueuedEvent, error) {
+	now := q.now().UTC()
+	events, err := q.query(ctx, `SELECT `+queuedEventColumns+` FROM event_queue
+		 WHERE status = ? AND next_attempt_at <= ? ORDER BY next_attempt_at, id LIMIT ?`, QueuedRetry, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	for i := range events {
+		if _, err := q.db.ExecContext(ctx, `UPDATE event_queue SET status = ?, updated_at = ? WHERE id = ?`,
+			QueuedDispatched, now, events[i].ID); err != nil {
+			return nil, LogAndWrapError(err, ErrorTypeDatabase, "claim_queued_event", map[string]any{
+				"id": events[i].ID,
+			})
+		}
+		events[i].Status = QueuedDispatched
+	}
+	return events, nil
+}
+
+// Prune deletes the deliveries that were done or given up before a time.
+func (q *EventQueue) Prune(ctx context.Context, before time.Time) (int64, error) {
+	res, err := q.db.ExecContext(ctx, `DELETE FROM event_queue WHERE status IN (?, ?) AND updated_at < ?`,
+		QueuedDone, QueuedFailed, before.UTC())
+	if err != nil {
+		return 0, LogAndWrapError(err, ErrorTypeDatabase, "prune_queued_events", nil)
+	}
+	return res.RowsAffected()
+}
+
+// Job returns the scheduler job that hands the deliveries due for a retry to redispatch
+// and prunes old ones. It is deferred while GitHub is degraded.
+func (q *EventQueue) Job(redispatch func(QueuedEvent)) Job {
+	return Job{
+		Name:       EventQueueJobName,
+		Interval:   q.cfg.Interval,
+		Deferrable: true,
+		Run: func(ctx context.Context) error {
+			return q.Process(ctx, redispatch)
+		},
+	}
+}
+
+// Process hands the deliveries due for a retry to redispatch, oldest due first, and
+// prunes the ones kept longer than the retention.
+func (q *EventQueue) Process(ctx context.Context, redispatch func(QueuedEvent)) error {
+	due, err := q.claimDue(ctx, 100)
+	if err != nil {
+		return err
+	}
+	for _, e := range due {
+		redispatch(e)
+	}
+	_, err = q.Prune(ctx, q.now().Add(-q.cfg.Retention))
+	return err
+}
+
+// queuedEventListSchema describes queued deliveries to the admin API's list query layer.
+// Given-up deliveries, status=failed, are the queue's dead letters.
+var queuedEventListSchema = ListSchema{
+	Fields: slices.Concat(
+		ListColumns("id", "delivery_id"),
+		[]ListField{{Name: "type", Column: "event_type"}},
+		ListColumns("repo", "status", "attempts"),
+		[]ListField{{Name: "modules"}},
+		ListColumns("error", "next_attempt_at", "created_at", "updated_at"),
+	),
+	Time: "created_at",
+	Sort: "-id",
+	Key:  "id",
+}
+
+// Select returns the queued deliveries matching a list query.
+func (q *EventQueue) Select(ctx context.Context, lq ListQuery) ([]QueuedEvent, error) {
+	query, args := lq.SQL(`SELECT ` + queuedEventColumns + ` FROM event_queue`)
+	return q.query(ctx, query, args...)
+}
+
+func (q *EventQueue) query(ctx context.Context, query string, args ...any) ([]QueuedEvent, error) {
+	rows, err := q.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, LogAndWrapError(err, ErrorTypeDatabase, "list_queued_events", nil)
+	}
+	defer rows.Close()
+	events := []QueuedEvent{}
+	for rows.Next() {
+		e, err := scanQueuedEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// RegisterAdminRoutes serves the queued deliveries on the admin API. A nil queue serves
+// nothing.
+func (q *EventQueue) RegisterAdminRoutes(srv *Server) {
+	if q == nil || srv == nil {
+		return
+	}
+	srv.HandleAdmin("GET /admin/event-queue", ListHandler(queuedEventListSchema, q.Select))
+}
@@ -0,0 +1,194 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+)
+
+func newTestEventQueue(t *testing.T, now *time.Time) *EventQueue {
+	t.Helper()
+	queue, err := NewEventQueue(TestDB(t), config.EventQueueConfig{
+		MaxAttempts: 3,
+		Backoff:     time.Minute,
+		MaxBackoff:  90 * time.Second,
+		Retention:   24 * time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("NewEventQueue failed: %v", err)
+	}
+	queue.now = func() time.Time { return *now }
+	return queue
+}
+
+func TestEventQueue(t *testing.T) {
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	queue := newTestEventQueue(t, &now)
+	ctx := t.Context()
+	payload := []byte(`{"action":"opened","repository":{"full_name":"org/repo"}}`)
+
+	id, err := queue.Enqueue(ctx, "d1", "issues", 0, payload)
+	if err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+	failed := map[string]error{"triage": errors.New("boom"), "sla": errors.New("timeout")}
+	if err := queue.Complete(ctx, id, failed); err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+	e, err := queue.Get(ctx, id)
+	if err != nil || e.Status != QueuedRetry || e.Attempts != 1 || e.Repo != "org/repo" ||
+		!slices.Equal(e.Modules, []string{"sla", "triage"}) || e.Error != "sla: timeout; triage: boom" ||
+		!e.NextAttemptAt.Equal(now.Add(time.Minute)) {
+		t.Fatalf("after a failure: %+v, %v", e, err)
+	}
+
+	// Not due before its backoff has passed, and claimed once it is.
+	var redispatched []QueuedEvent
+	redispatch := func(e QueuedEvent) { redispatched = append(redispatched, e) }
+	if err := queue.Process(ctx, redispatch); err != nil || len(redispatched) != 0 {
+		t.Fatalf("Process before the backoff = %v, %v", redispatched, err)
+	}
+	now = now.Add(time.Minute)
+	if err := queue.Process(ctx, redispatch); err != nil || len(redispatched) != 1 ||
+		string(redispatched[0].Payload) != string(payload) || redispatched[0].Status != QueuedDispatched {
+		t.Fatalf("Process after the backoff = %+v, %v", redispatched, err)
+	}
+	if err := queue.Process(ctx, redispatch); err != nil || len(redispatched) != 1 {
+		t.Fatalf("claimed delivery was handed out again: %+v, %v", redispatched, err)
+	}
+
+	// The backoff doubles up to the maximum, and the delivery is given up after the last attempt.
+	if err := queue.Complete(ctx, id, map[string]error{"sla": errors.New("timeout")}); err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+	if e, _ := queue.Get(ctx, id); !e.NextAttemptAt.Equal(now.Add(90*time.Second)) ||
+		!slices.Equal(e.Modules, []string{"sla"}) {
+		t.Errorf("after the second failure: %+v", e)
+	}
+	if err := queue.Complete(ctx, id, map[string]error{"sla": errors.New("timeout")}); err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+	if e, _ := queue.Get(ctx, id); e.Status != QueuedFailed || e.Attempts != 3 {
+		t.Errorf("after the last attempt: %+v", e)
+	}
+
+	// A delivery without failures is done and its payload dropped.
+	done, _ := queue.Enqueue(ctx, "d2", "push", 0, payload)
+	if err := queue.Complete(ctx, done, nil); err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+	if e, _ := queue.Get(ctx, done); e.Status != QueuedDone || e.Payload != nil || e.Modules != nil {
+		t.Errorf("done delivery: %+v", e)
+	}
+
+	// A delivery recorded in the event store is referred to there, not kept again.
+	stored, err := queue.Enqueue(ctx, "d4", "issues", 42, payload)
+	if err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+	if e, _ := queue.Get(ctx, stored); e.EventID != 42 || e.Payload != nil || e.Repo != "org/repo" {
+		t.Errorf("delivery in the event store: %+v", e)
+	}
+	if err := queue.Complete(ctx, stored, nil); err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+
+	// Deliveries in flight at a restart are due right away.
+	inFlight, _ := queue.Enqueue(ctx, "d3", "issues", 0, payload)
+	if n, err := queue.Recover(ctx); err != nil || n != 1 {
+		t.Fatalf("Recover = %d, %v; want 1", n, err)
+	}
+	if e, _ := queue.Get(ctx, inFlight); e.Status != QueuedRetry || e.Attempts != 0 || !e.NextAttemptAt.Equal(now) {
+		t.Errorf("recovered delivery: %+v", e)
+	}
+
+	now = now.Add(25 * time.Hour)
+	if n, err := queue.Prune(ctx, now.Add(-24*time.Hour)); err != nil || n != 3 {
+		t.Errorf("Prune = %d, %v; want the done and failed deliveries", n, err)
+	}
+}
+
+// flakyModule fails the first events it handles.
+type flakyModule struct {
+	name     string
+	failures int
+	mu       sync.Mutex
+	handled  int
+}
+
+func (m *flakyModule) Name() string { return m.name }
+
+func (m *flakyModule) HandleEvent(string, any, json.RawMessage) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.handled++
+	if m.handled <= m.failures {
+		return errors.New("temporarily unavailable")
+	}
+	return nil
+}
+
+func TestDispatchDeliveryRetriesFailedModules(t *testing.T) {
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	app := &App{
+		ModuleRegistry: NewModuleRegistry(),
+		Logger:         slog.Default(),
+		Database:       NewDatabaseFromDB(TestDB(t)),
+		Queue:          newTestEventQueue(t, &now),
+	}
+	steady := &flakyModule{name: "steady"}
+	flaky := &flakyModule{name: "flaky", failures: 1}
+	app.RegisterModule(steady)
+	app.RegisterModule(flaky)
+
+	// drain runs the dispatch pool until the events submitted in between are handled.
+	drain := func(submit func()) {
+		t.Helper()
+		app.Dispatch = NewDispatchPool(config.DispatchConfig{Workers: 1}, nil)
+		app.Dispatch.Start()
+		submit()
+		if err := app.Dispatch.Stop(t.Context()); err != nil {
+			t.Fatalf("Stop failed: %v", err)
+		}
+	}
+	payload := []byte(`{"action":"opened","issue":{"number":1},"repository":{"full_name":"org/repo"}}`)
+	event, err := ParseWebHook("issues", payload)
+	if err != nil {
+		t.Fatalf("ParseWebHook failed: %v", err)
+	}
+	if app.Events, err = NewEventStore(app.Database.DB()); err != nil {
+		t.Fatalf("NewEventStore failed: %v", err)
+	}
+	stored, err := app.Events.Record(t.Context(), NewStoredEvent("d1", "issues", payload))
+	if err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	drain(func() { app.DispatchDelivery("d1", stored, "issues", event, payload) })
+	queued, err := app.Queue.query(t.Context(), `SELECT `+queuedEventColumns+` FROM event_queue`)
+	if err != nil || len(queued) != 1 || queued[0].Status != QueuedRetry ||
+		!slices.Equal(queued[0].Modules, []string{"flaky"}) || queued[0].EventID != stored || queued[0].Payload != nil {
+		t.Fatalf("queued = %+v, %v", queued, err)
+	}
+
+	// Only the module that failed is handed the delivery again.
+	now = now.Add(time.Minute)
+	drain(func() {
+		if err := app.Queue.Process(t.Context(), app.redispatch); err != nil {
+			t.Fatalf("Process failed: %v", err)
+		}
+	})
+	if steady.handled != 1 || flaky.handled != 2 {
+		t.Errorf("handled steady %d and flaky %d times, want 1 and 2", steady.handled, flaky.handled)
+	}
+	if e, _ := app.Queue.Get(t.Context(), queued[0].ID); e.Status != QueuedDone || e.Attempts != 2 {
+		t.Errorf("delivery after the retry: %+v", e)
+	}
+}
@@ -0,0 +1,163 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// eventschema.go records which webhook events and actions each module consumes, as the
+// modules declare them, and the go-github payload types they are parsed into. Events are
+// only handed to the modules that consume them, and the registry is served on the admin
+// API and by `otto module describe` for auditing automation coverage.
+
+package internal
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"runtime/debug"
+	"slices"
+	"strings"
+
+	"github.com/google/go-github/v71/github"
+)
+
+// EventSubscription is a webhook event type a module consumes, narrowed to some of its
+// actions.
+type EventSubscription struct {
+	Event   string   `json:"event"`
+	Actions []string `json:"actions,omitempty"` // empty for every action
+}
+
+// Subscribe returns the subscription to an event type's actions, or to all of them if none
+// are given.
+func Subscribe(event string, actions ...string) EventSubscription {
+	return EventSubscription{Event: event, Actions: actions}
+}
+
+// ModuleEventSubscriber is an optional interface for modules that declare the events they
+// consume. Such modules are only handed those events; other modules are handed every event.
+type ModuleEventSubscriber interface {
+	EventSubscriptions() []EventSubscription
+}
+
+// EventSchema is a subscription with the payload type its events are parsed into.
+type EventSchema struct {
+	EventSubscription
+	Payload string `json:"payload,omitempty"` // go-github type, e.g. github.IssuesEvent
+}
+
+// ModuleDescription describes the events a module consumes, the slash commands it handles
+// and the GitHub App permissions it needs.
+type ModuleDescription struct {
+	Name          string            `json:"name"`
+	AllEvents     bool              `json:"all_events"` // no subscriptions are declared, so every event is handed over
+	Events        []EventSchema     `json:"events"`
+	Normalized    bool              `json:"normalized"` // also handles normalized pull request and issue events
+	ConfigVersion int               `json:"config_version,omitempty"`
+	Permissions   map[string]string `json:"permissions,omitempty"` // GitHub App permission -> level needed
+	Commands      []string          `json:"commands,omitempty"`    // slash commands handled, without the slash
+	PayloadSchema string            `json:"payload_schema"`        // go-github module and version parsing payloads
+}
+
+// DescribeModule describes the events a module consumes.
+func DescribeModule(m Module) ModuleDescription {
+	d := ModuleDescription{
+		Name:          m.Name(),
+		Events:        []EventSchema{},
+		ConfigVersion: ModuleConfigVersion(m),
+		PayloadSchema: PayloadSchemaVersion(),
+	}
+	_, d.Normalized = m.(NormalizedEventHandler)
+	if r, ok := m.(ModulePermissionRequirer); ok {
+		d.Permissions = r.GitHubPermissions()
+	}
+	if c, ok := m.(ModuleCommander); ok {
+		d.Commands = c.SlashCommands()
+	}
+	subscriber, ok := m.(ModuleEventSubscriber)
+	if !ok {
+		d.AllEvents = true
+		return d
+	}
+	for _, s := range subscriber.EventSubscriptions() {
+		schema := EventSchema{EventSubscription: s}
+		if payload := EventForType(s.Event); payload != nil {
+			schema.Payload = strings.TrimPrefix(reflect.TypeOf(payload).String(), "*")
+		}
+		d.Events = append(d.Events, schema)
+	}
+	return d
+}
+
+// Describe describes the registered modules, sorted by name.
+func (r *ModuleRegistry) Describe() []ModuleDescription {
+	modules := r.GetModules()
+	out := make([]ModuleDescription, 0, len(modules))
+	for _, m := range modules {
+		out = append(out, DescribeModule(m))
+	}
+	slices.SortFunc(out, func(a, b ModuleDescription) int { return strings.Compare(a.Name, b.Name) })
+	return out
+}
+
+// Subscribed reports whether a module consumes an event: whether it subscribes to the event
+// type and the event's action, or declares no subscriptions.
+func Subscribed(m Module, eventType string, event any) bool {
+	subscriber, ok := m.(ModuleEventSubscriber)
+	if !ok {
+		return true
+	}
+	var action string
+	if e, ok := event.(interface{ GetAction() string }); ok {
+		action = e.GetAction()
+	}
+	for _, s := range subscriber.EventSubscriptions() {
+		if s.Event == eventType && (len(s.Actions) == 0 || slices.Contains(s.Actions, action)) {
+			return true
+		}
+	}
+	return false
+}
+
+// PayloadSchemaVersion returns the go-github module and version that webhook payloads are
+// parsed with, e.g. "github.com/google/go-github/v71 v71.0.0", which pins the payload
+// fields modules can see. The version is omitted if the build does not record it.
+func PayloadSchemaVersion() string {
+	path := strings.TrimSuffix(reflect.TypeFor[github.IssuesEvent]().PkgPath(), "/github")
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, dep := range info.Deps {
+			if dep.Path == path {
+				return path + " " + dep.Version
+			}
+		}
+	}
+	return path
+}
+
+// RegisterAdminRoutes exposes the module descriptions on the admin API.
+func (r *ModuleRegistry) RegisterAdminRoutes(srv *Server) {
+	if r == nil || srv == nil {
+		return
+	}
+	srv.HandleAdmin("GET /admin/modules", r.handleList)
+	srv.HandleAdmin("GET /admin/modules/{name}", r.handleDescribe)
+}
+
+// handleList serves the descriptions of all modules as JSON.
+func (r *ModuleRegistry) handleList(w http.ResponseWriter, req *http.Request) {
+	writeModuleJSON(w, r.Describe())
+}
+
+// handleDescribe serves the description of the module named in the path as JSON.
+func (r *ModuleRegistry) handleDescribe(w http.ResponseWriter, req *http.Request) {
+	m, ok := r.GetModules()[req.PathValue("name")]
+	if !ok {
+		http.Error(w, "module not registered", http.StatusNotFound)
+		return
+	}
+	writeModuleJSON(w, DescribeModule(m))
+}
+
+func writeModuleJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
@@ -0,0 +1,129 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/go-github/v71/github"
+)
+
+type subscribingModule struct {
+	mockModule
+	subscriptions []EventSubscription
+}
+
+func (m *subscribingModule) EventSubscriptions() []EventSubscription { return m.subscriptions }
+
+func TestSubscribed(t *testing.T) {
+	mod := &subscribingModule{subscriptions: []EventSubscription{
+		Subscribe("issue_comment", "created"),
+		Subscribe("push"),
+	}}
+	comment := func(action string) *github.IssueCommentEvent {
+		return &github.IssueCommentEvent{Action: github.Ptr(action)}
+	}
+	tests := []struct {
+		module    Module
+		eventType string
+		event     any
+		want      bool
+	}{
+		{mod, "issue_comment", comment("created"), true},
+		{mod, "issue_comment", comment("edited"), false},
+		{mod, "push", &github.PushEvent{}, true},
+		{mod, "issues", &github.IssuesEvent{Action: github.Ptr("opened")}, false},
+		{&mockModule{}, "issues", &github.IssuesEvent{Action: github.Ptr("opened")}, true},
+	}
+	for _, tt := range tests {
+		if got := Subscribed(tt.module, tt.eventType, tt.event); got != tt.want {
+			t.Errorf("Subscribed(%s, %v) = %v, want %v", tt.eventType, tt.event, got, tt.want)
+		}
+	}
+}
+
+func TestDescribeModule(t *testing.T) {
+	d := DescribeModule(&subscribingModule{
+		mockModule:    mockModule{name: "labels"},
+		subscriptions: []EventSubscription{Subscribe("pull_request", "opened"), Subscribe("comment")},
+	})
+	if d.Name != "labels" || d.AllEvents || len(d.Events) != 2 {
+		t.Fatalf("unexpected description: %+v", d)
+	}
+	if d.Events[0].Payload != "github.PullRequestEvent" || d.Events[0].Actions[0] != "opened" {
+		t.Errorf("events[0] = %+v, want pull_request opened with github.PullRequestEvent", d.Events[0])
+	}
+	if d.Events[1].Payload != "" {
+		t.Errorf("unknown event type has payload %q", d.Events[1].Payload)
+	}
+	if d.PayloadSchema == "" {
+		t.Error("payload schema not set")
+	}
+
+	if d := DescribeModule(&mockModule{name: "any"}); !d.AllEvents || len(d.Events) != 0 {
+		t.Errorf("module without subscriptions = %+v, want all events", d)
+	}
+}
+
+func TestDispatchSkipsUnsubscribedModules(t *testing.T) {
+	var wg sync.WaitGroup
+	subscribed := &subscribingModule{
+		mockModule:    mockModule{name: "subscribed", eventWG: &wg},
+		subscriptions: []EventSubscription{Subscribe("issues", "opened")},
+	}
+	other := &subscribingModule{
+		mockModule:    mockModule{name: "other"},
+		subscriptions: []EventSubscription{Subscribe("issues", "closed")},
+	}
+	app := &App{ModuleRegistry: NewModuleRegistry()}
+	app.RegisterModule(subscribed)
+	app.RegisterModule(other)
+
+	wg.Add(1)
+	app.DispatchEvent("issues", &github.IssuesEvent{Action: github.Ptr("opened"), Issue: &github.Issue{}}, nil)
+	wg.Wait()
+
+	if atomic.LoadInt32(&subscribed.handled) != 1 || atomic.LoadInt32(&other.handled) != 0 {
+		t.Errorf("handled = %d and %d, want 1 and 0", subscribed.handled, other.handled)
+	}
+}
+
+func TestModuleAdminRoutes(t *testing.T) {
+	registry := NewModuleRegistry()
+	registry.RegisterModule(&subscribingModule{
+		mockModule:    mockModule{name: "labels"},
+		subscriptions: []EventSubscription{Subscribe("pull_request", "opened")},
+	})
+	registry.RegisterModule(&mockModule{name: "any"})
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /admin/modules", registry.handleList)
+	mux.HandleFunc("GET /admin/modules/{name}", registry.handleDescribe)
+	get := func(target string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+		return w
+	}
+
+	w := get("/admin/modules")
+	var all []ModuleDescription
+	if err := json.Unmarshal(w.Body.Bytes(), &all); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("GET /admin/modules = %d %s", w.Code, w.Body.String())
+	}
+	if len(all) != 2 || all[0].Name != "any" || all[1].Name != "labels" {
+		t.Errorf("modules = %+v, want any and labels", all)
+	}
+
+	w = get("/admin/modules/labels")
+	var d ModuleDescription
+	if err := json.Unmarshal(w.Body.Bytes(), &d); err != nil || len(d.Events) != 1 || d.Events[0].Event != "pull_request" {
+		t.Errorf("GET /admin/modules/labels = %d %s", w.Code, w.Body.String())
+	}
+	if w := get("/admin/modules/missing"); w.Code != http.StatusNotFound {
+		t.Errorf("GET unknown module = %d, want 404", w.Code)
+	}
+}
@@ -0,0 +1,309 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// eventstore.go persists received webhook events so modules and operators can
+// look back at repository activity.
+
+package internal
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+)
+
+// StoredEvent is a webhook delivery persisted in the event store.
+type StoredEvent struct {
+	ID         int64           `json:"id"`
+	DeliveryID string          `json:"delivery_id,omitempty"`
+	Type       string          `json:"type"`
+	Action     string          `json:"action,omitempty"`
+	Repo       string          `json:"repo,omitempty"`
+	Sender     string          `json:"sender,omitempty"`
+	Payload    json.RawMessage `json:"payload,omitempty"`
+	ReceivedAt time.Time       `json:"received_at"`
+}
+
+// EventQuery filters events returned by EventStore.Query. Zero values match everything.
+type EventQuery struct {
+	Repo   string // owner/name, or a bare name matching that repository in any owner
+	Type   string
+	Action string
+	Sender string
+	Since  time.Time
+	Until  time.Time
+	Limit  int
+	Offset int
+}
+
+// EventStore reads and writes the events table. With a payload store, payloads above a
+// size are kept there and only their key in the table.
+type EventStore struct {
+	db           *sql.DB
+	payloads     PayloadStore
+	offloadAbove int
+}
+
+// NewEventStore creates the event store, creating its table if needed.
+func NewEventStore(db *sql.DB) (*EventStore, error) {
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS events (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			delivery_id TEXT,
+			event_type TEXT NOT NULL,
+			action TEXT,
+			repo TEXT,
+			sender TEXT,
+			payload BLOB,
+			payload_ref TEXT,
+			received_at TIMESTAMP NOT NULL
+		);`,
+		`CREATE INDEX IF NOT EXISTS idx_events_repo_type ON events (repo, event_type, received_at);`,
+		`CREATE INDEX IF NOT EXISTS idx_events_received_at ON events (received_at);`,
+	}
+	for _, s := range stmts {
+		if _, err := db.Exec(s); err != nil {
+			return nil, fmt.Errorf("failed migration: %w (SQL: %s)", err, s)
+		}
+	}
+	if err := ensureColumn(db, "events", "payload_ref", "TEXT"); err != nil {
+		return nil, err
+	}
+	return &EventStore{db: db}, nil
+}
+
+// WithPayloadStore offloads payloads larger than offloadAbove bytes to store, and reads
+// offloaded payloads back from it. A nil store keeps payloads in the database.
+func (s *EventStore) WithPayloadStore(store PayloadStore, offloadAbove int) *EventStore {
+	s.payloads, s.offloadAbove = store, offloadAbove
+	return s
+}
+
+// eventEnvelope holds the fields common to GitHub webhook payloads.
+type eventEnvelope struct {
+	Action     string `json:"action"`
+	Repository struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+	Sender struct {
+		Login string `json:"login"`
+	} `json:"sender"`
+}
+
+// NewStoredEvent builds a StoredEvent from a raw payload, extracting the common fields.
+func NewStoredEvent(deliveryID, eventType string, payload []byte) StoredEvent {
+	var env eventEnvelope
+	_ = json.Unmarshal(payload, &env) // best effort; unknown shapes still get stored
+	return StoredEvent{
+		DeliveryID: deliveryID,
+		Type:       eventType,
+		Action:     env.Action,
+		Repo:       env.Repository.FullName,
+		Sender:     env.Sender.Login,
+		Payload:    payload,
+		ReceivedAt: time.Now(),
+	}
+}
+
+// Record stores an event and returns its ID.
+func (s *EventStore) Record(ctx context.Context, e StoredEvent) (int64, error) {
+	if e.ReceivedAt.IsZero() {
+		e.ReceivedAt = time.Now()
+	}
+	payload, ref := []byte(e.Payload), sql.NullString{}
+	if s.payloads != nil && len(payload) > s.offloadAbove {
+		key := payloadKey(e)
+		if err := s.payloads.Put(ctx, key, payload); err != nil {
+			// Keeping the payload in the database beats losing the event.
+			slog.WarnContext(ctx, "Failed to offload event payload, storing it in the database",
+				"event_type", e.Type, "delivery", e.DeliveryID, "size", len(payload), "error", err)
+		} else {
+			payload, ref = nil, sql.NullString{String: key, Valid: true}
+		}
+	}
+	res, err := s.db.ExecContext(ctx,
+		`INSERT INTO events (delivery_id, event_type, action, repo, sender, payload, payload_ref, received_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		e.DeliveryID, e.Type, e.Action, e.Repo, e.Sender, payload, ref, e.ReceivedAt,
+	)
+	if err != nil {
+		return 0, LogAndWrapError(err, ErrorTypeDatabase, "record_event", map[string]any{
+			"event_type": e.Type,
+			"delivery":   e.DeliveryID,
+		})
+	}
+	return res.LastInsertId()
+}
+
+// Get returns a stored event by ID, with its payload, or sql.ErrNoRows.
+func (s *EventStore) Get(ctx context.Context, id int64) (StoredEvent, error) {
+	events, err := s.query(ctx, true, `SELECT `+eventColumns+` FROM events WHERE id = ?`, id)
+	if err != nil {
+		return StoredEvent{}, err
+	}
+	if len(events) == 0 {
+		return StoredEvent{}, sql.ErrNoRows
+	}
+	return events[0], nil
+}
+
+// payloadKey returns the key an event's payload is offloaded under: by day received, then
+// delivery ID, or a random name for events without one.
+func payloadKey(e StoredEvent) string {
+	name := e.DeliveryID
+	if name == "" {
+		b := make([]byte, 8)
+		_, _ = rand.Read(b)
+		name = hex.EncodeToString(b)
+	}
+	return e.ReceivedAt.UTC().Format("2006/01/02") + "/" + e.Type + "-" + name + ".json"
+}
+
+// Query returns events matching q, oldest first.
+func (s *EventStore) Query(ctx context.Context, q EventQuery) ([]StoredEvent, error) {
+	var (
+		where []string
+		args  []any
+	)
+	switch {
+	case strings.Contains(q.Repo, "/"):
+		where = append(where, "repo = ?")
+		args = append(args, q.Repo)
+	case q.Repo != "":
+		where = append(where, "repo LIKE ?")
+		args = append(args, "%/"+q.Repo)
+	}
+	if q.Type != "" {
+		where = append(where, "event_type = ?")
+		args = append(args, q.Type)
+	}
+	if q.Action != "" {
+		where = append(where, "action = ?")
+		args = append(args, q.Action)
+	}
+	if q.Sender != "" {
+		where = append(where, "sender = ?")
+		args = append(args, q.Sender)
+	}
+	if !q.Since.IsZero() {
+		where = append(where, "received_at >= ?")
+		args = append(args, q.Since)
+	}
+	if !q.Until.IsZero() {
+		where = append(where, "received_at < ?")
+		args = append(args, q.Until)
+	}
+
+	query := `SELECT ` + eventColumns + ` FROM events`
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	limit := q.Limit
+	if limit <= 0 {
+		limit = -1 // SQLite: no limit
+	}
+	query += " ORDER BY received_at ASC, id ASC LIMIT ? OFFSET ?"
+	args = append(args, limit, q.Offset)
+	return s.query(ctx, true, query, args...)
+}
+
+// eventListSchema describes events to the admin API's list query layer. Payloads are
+// only included when asked for with fields.
+var eventListSchema = ListSchema{
+	Fields: []ListField{
+		{Name: "id", Column: "id"},
+		{Name: "delivery_id", Column: "delivery_id"},
+		{Name: "type", Column: "event_type"},
+		{Name: "action", Column: "action"},
+		{Name: "repo", Column: "repo"},
+		{Name: "sender", Column: "sender"},
+		{Name: "payload", Hidden: true},
+		{Name: "received_at", Column: "received_at"},
+	},
+	Time: "received_at",
+	Sort: "-received_at",
+	Key:  "id",
+}
+
+// Select returns the events matching a list query. Payloads that are not valid JSON are
+// left out, and offloaded ones are only read back when the query asks for payloads.
+func (s *EventStore) Select(ctx context.Context, q ListQuery) ([]StoredEvent, error) {
+	query, args := q.SQL(`SELECT ` + eventColumns + ` FROM events`)
+	events, err := s.query(ctx, slices.Contains(q.Fields, "payload"), query, args...)
+	for i := range events {
+		if !json.Valid(events[i].Payload) {
+			events[i].Payload = nil
+		}
+	}
+	return events, err
+}
+
+// RegisterAdminRoutes serves the stored events on the admin API. A nil store serves nothing.
+func (s *EventStore) RegisterAdminRoutes(srv *Server) {
+	if s == nil || srv == nil {
+		return
+	}
+	srv.HandleAdmin("GET /admin/events", ListHandler(eventListSchema, s.Select))
+}
+
+const eventColumns = `id, delivery_id, event_type, action, repo, sender, payload, payload_ref, received_at`
+
+// query returns the events a query selects, reading offloaded payloads back from the
+// payload store if hydrate is set.
+func (s *EventStore) query(ctx context.Context, hydrate bool, query string, args ...any) ([]StoredEvent, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, LogAndWrapError(err, ErrorTypeDatabase, "query_events", nil)
+	}
+	defer rows.Close()
+
+	var (
+		events []StoredEvent
+		refs   = map[int]string{} // index in events -> key of the offloaded payload
+	)
+	for rows.Next() {
+		var (
+			e                                   StoredEvent
+			delivery, action, repo, sender, ref sql.NullString
+			payload                             []byte
+		)
+		err := rows.Scan(&e.ID, &delivery, &e.Type, &action, &repo, &sender, &payload, &ref, &e.ReceivedAt)
+		if err != nil {
+			return nil, err
+		}
+		e.DeliveryID, e.Action, e.Repo, e.Sender = delivery.String, action.String, repo.String, sender.String
+		e.Payload = payload
+		if ref.Valid {
+			refs[len(events)] = ref.String
+		}
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close() // release the connection before calling out to the payload store
+	if !hydrate {
+		return events, nil
+	}
+	for i, key := range refs {
+		if s.payloads == nil {
+			return nil, fmt.Errorf("payload of event %d is offloaded to %s, but no event payload store is configured",
+				events[i].ID, key)
+		}
+		payload, err := s.payloads.Get(ctx, key)
+		if err != nil {
+			return nil, LogAndWrapError(err, ErrorTypeGeneral, "load_event_payload", map[string]any{
+				"event": events[i].ID,
+				"key":   key,
+			})
+		}
+		events[i].Payload = payload
+	}
+	return events, nil
+}
@@ -0,0 +1,131 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEventStoreRecordAndQuery(t *testing.T) {
+	store, err := NewEventStore(TestDB(t))
+	if err != nil {
+		t.Fatalf("NewEventStore failed: %v", err)
+	}
+
+	base := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	payloads := []struct {
+		eventType string
+		body      string
+		at        time.Time
+	}{
+		{"issues", `{"action":"opened","repository":{"full_name":"org/a"},"sender":{"login":"alice"}}`, base},
+		{"issues", `{"action":"closed","repository":{"full_name":"org/a"},"sender":{"login":"bob"}}`, base.Add(time.Hour)},
+		{"issues", `{"action":"opened","repository":{"full_name":"org/b"},"sender":{"login":"carol"}}`, base.Add(2 * time.Hour)},
+		{"push", `{"repository":{"full_name":"org/a"}}`, base.Add(3 * time.Hour)},
+	}
+	for i, p := range payloads {
+		e := NewStoredEvent("delivery", p.eventType, []byte(p.body))
+		e.ReceivedAt = p.at
+		if _, err := store.Record(t.Context(), e); err != nil {
+			t.Fatalf("Record(%d) failed: %v", i, err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		query   EventQuery
+		senders []string
+	}{
+		{"all", EventQuery{}, []string{"alice", "bob", "carol", ""}},
+		{"by type and action", EventQuery{Type: "issues", Action: "opened"}, []string{"alice", "carol"}},
+		{"by repo", EventQuery{Repo: "org/a", Type: "issues"}, []string{"alice", "bob"}},
+		{"by repo name", EventQuery{Repo: "b"}, []string{"carol"}},
+		{"by sender", EventQuery{Sender: "bob"}, []string{"bob"}},
+		{"time window", EventQuery{Since: base.Add(time.Hour), Until: base.Add(3 * time.Hour)}, []string{"bob", "carol"}},
+		{"paged", EventQuery{Limit: 2, Offset: 1}, []string{"bob", "carol"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, err := store.Query(t.Context(), tt.query)
+			if err != nil {
+				t.Fatalf("Query failed: %v", err)
+			}
+			if len(events) != len(tt.senders) {
+				t.Fatalf("got %d events, want %d", len(events), len(tt.senders))
+			}
+			for i, e := range events {
+				if e.Sender != tt.senders[i] {
+					t.Errorf("event %d sender = %q, want %q", i, e.Sender, tt.senders[i])
+				}
+			}
+		})
+	}
+}
+
+func TestEventStoreOffloadsPayloads(t *testing.T) {
+	db := TestDB(t)
+	// A table from before payloads could be offloaded gains the column.
+	if _, err := db.Exec(`CREATE TABLE events (id INTEGER PRIMARY KEY AUTOINCREMENT, delivery_id TEXT,
+		event_type TEXT NOT NULL, action TEXT, repo TEXT, sender TEXT, payload BLOB, received_at TIMESTAMP NOT NULL)`,
+	); err != nil {
+		t.Fatalf("creating old table failed: %v", err)
+	}
+	store, err := NewEventStore(db)
+	if err != nil {
+		t.Fatalf("NewEventStore failed: %v", err)
+	}
+	objects, bucket := newTestObjectStore(t)
+	store.WithPayloadStore(objects, 64)
+
+	small := `{"action":"opened","repository":{"full_name":"org/a"}}`
+	large := `{"action":"synchronize","repository":{"full_name":"org/a"},"diff":"` + strings.Repeat("x", 100) + `"}`
+	for _, p := range []struct{ delivery, eventType, body string }{
+		{"d1", "issues", small},
+		{"d2", "pull_request", large},
+	} {
+		e := NewStoredEvent(p.delivery, p.eventType, []byte(p.body))
+		e.ReceivedAt = time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+		if _, err := store.Record(t.Context(), e); err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+	}
+	var inline, ref []string
+	rows, err := db.Query(`SELECT COALESCE(payload, ''), COALESCE(payload_ref, '') FROM events ORDER BY id`)
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var p, r string
+		if err := rows.Scan(&p, &r); err != nil {
+			t.Fatalf("Scan failed: %v", err)
+		}
+		inline, ref = append(inline, p), append(ref, r)
+	}
+	if inline[0] != small || ref[0] != "" || inline[1] != "" || ref[1] != "2025/05/01/pull_request-d2.json" {
+		t.Fatalf("stored payloads = %q, refs = %q", inline, ref)
+	}
+	if len(bucket.objects) != 1 {
+		t.Errorf("objects = %v, want the large payload", bucket.objects)
+	}
+
+	events, err := store.Query(t.Context(), EventQuery{Repo: "org/a"})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(events) != 2 || string(events[0].Payload) != small || string(events[1].Payload) != large {
+		t.Fatalf("events = %+v", events)
+	}
+	listed, err := store.Select(t.Context(), ListQuery{Limit: 10, Fields: []string{"id", "type"}})
+	if err != nil || len(listed) != 2 || listed[1].Payload != nil {
+		t.Errorf("Select without payloads = %+v, %v", listed, err)
+	}
+
+	// Without the store, offloaded payloads cannot be read back.
+	store.WithPayloadStore(nil, 0)
+	if _, err := store.Query(t.Context(), EventQuery{Type: "pull_request"}); err == nil {
+		t.Error("Query succeeded without the payload store")
+	}
+}
@@ -0,0 +1,218 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// fileclass.go classifies changed files as generated, vendored or documentation, from
+// configured patterns and the linguist attributes in a repository's .gitattributes, so
+// modules ignore the same noise in diffs.
+
+package internal
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/google/go-github/v71/github"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+)
+
+// File classes.
+const (
+	FileClassGenerated = "generated"
+	FileClassVendored  = "vendored"
+	FileClassDocs      = "docs"
+)
+
+// linguistAttributes maps linguist attributes to the file classes they set.
+var linguistAttributes = map[string]string{
+	"linguist-generated":     FileClassGenerated,
+	"linguist-vendored":      FileClassVendored,
+	"linguist-documentation": FileClassDocs,
+}
+
+// fileClassRule sets or unsets a class on the paths matching a pattern.
+type fileClassRule struct {
+	pattern *regexp.Regexp
+	class   string
+	set     bool
+}
+
+// FileClasses classifies the paths of one repository.
+type FileClasses struct {
+	rules []fileClassRule
+}
+
+// Classes returns the classes of a path, sorted. Later rules override earlier ones, so a
+// repository's .gitattributes can unset a class given by the configured patterns.
+func (f *FileClasses) Classes(path string) []string {
+	if f == nil {
+		return nil
+	}
+	var classes []string
+	for _, r := range f.rules {
+		if !r.pattern.MatchString(path) {
+			continue
+		}
+		i := slices.Index(classes, r.class)
+		switch {
+		case r.set && i < 0:
+			classes = append(classes, r.class)
+		case !r.set && i >= 0:
+			classes = slices.Delete(classes, i, i+1)
+		}
+	}
+	if len(classes) == 0 {
+		return nil
+	}
+	slices.Sort(classes)
+	return classes
+}
+
+// Is reports whether a path has a class.
+func (f *FileClasses) Is(path, class string) bool {
+	return slices.Contains(f.Classes(path), class)
+}
+
+// Noise reports whether a path is generated or vendored, i.e. not written by hand in the
+// repository.
+func (f *FileClasses) Noise(path string) bool {
+	classes := f.Classes(path)
+	return slices.Contains(classes, FileClassGenerated) || slices.Contains(classes, FileClassVendored)
+}
+
+// Without returns the paths that have none of the given classes.
+func (f *FileClasses) Without(paths []string, classes ...string) []string {
+	var kept []string
+	for _, p := range paths {
+		if !slices.ContainsFunc(f.Classes(p), func(c string) bool { return slices.Contains(classes, c) }) {
+			kept = append(kept, p)
+		}
+	}
+	return kept
+}
+
+// FileClassifier builds the FileClasses of repositories, caching their .gitattributes.
+type FileClassifier struct {
+	cfg    config.FileClassesConfig
+	client *github.Client
+	cache  Cache
+	base   []fileClassRule
+}
+
+// NewFileClassifier creates a classifier for the configured patterns. With a nil client,
+// .gitattributes files are not read.
+func NewFileClassifier(cfg config.FileClassesConfig, client *github.Client) *FileClassifier {
+	c := &FileClassifier{cfg: cfg, client: client, cache: NewMemoryCache()}
+	for class, patterns := range map[string][]string{
+		FileClassGenerated: cfg.Generated,
+		FileClassVendored:  cfg.Vendored,
+		FileClassDocs:      cfg.Docs,
+	} {
+		for _, p := range patterns {
+			c.base = append(c.base, fileClassRule{pattern: CompileFilePattern(p), class: class, set: true})
+		}
+	}
+	return c
+}
+
+// WithCache caches .gitattributes files in cache instead of in memory.
+func (c *FileClassifier) WithCache(cache Cache) *FileClassifier {
+	if cache != nil {
+		c.cache = cache
+	}
+	return c
+}
+
+// ForRepo returns the classification of a repository's files: the configured patterns,
+// followed by the linguist attributes of the .gitattributes on its default branch. A nil
+// classifier classifies nothing.
+func (c *FileClassifier) ForRepo(ctx context.Context, repo string) (*FileClasses, error) {
+	if c == nil {
+		return &FileClasses{}, nil
+	}
+	if c.client == nil || c.cfg.GitAttributes == nil || !*c.cfg.GitAttributes {
+		return &FileClasses{rules: c.base}, nil
+	}
+
+	// An empty string is cached for repositories without a .gitattributes.
+	key := "gitattributes:" + repo
+	content, ok := GetCached[string](ctx, c.cache, key)
+	if !ok {
+		file, err := GetFile(ctx, c.client, repo, ".gitattributes", "")
+		if err != nil {
+			return nil, err
+		}
+		if file != nil {
+			content = file.Content
+		}
+		SetCached(ctx, c.cache, key, content, c.cfg.CacheTTL)
+	}
+	classes := &FileClasses{rules: slices.Clone(c.base)}
+	classes.rules = append(classes.rules, parseGitAttributes(content)...)
+	return classes, nil
+}
+
+// parseGitAttributes returns the rules for the linguist attributes in a .gitattributes file.
+// "attr" and "attr=true" set a class; "-attr", "!attr" and "attr=false" unset it.
+func parseGitAttributes(content string) []fileClassRule {
+	var rules []fileClassRule
+	for line := range strings.Lines(content) {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		pattern := CompileFilePattern(fields[0])
+		for _, attr := range fields[1:] {
+			set := true
+			if trimmed := strings.TrimLeft(attr, "-!"); trimmed != attr {
+				attr, set = trimmed, false
+			}
+			name, value, hasValue := strings.Cut(attr, "=")
+			class, ok := linguistAttributes[name]
+			if !ok {
+				continue
+			}
+			if hasValue {
+				set = value == "true" || value == "1"
+			}
+			rules = append(rules, fileClassRule{pattern: pattern, class: class, set: set})
+		}
+	}
+	return rules
+}
+
+// CompileFilePattern converts a .gitattributes pattern to a regular expression. Patterns
+// without a slash match a name at any depth, other patterns match from the repository
+// root, "**" matches across directories, and a pattern matching a directory also matches
+// everything below it.
+func CompileFilePattern(pattern string) *regexp.Regexp {
+	pattern = strings.TrimSuffix(pattern, "/")
+	anchored := strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+
+	var b strings.Builder
+	if anchored {
+		b.WriteString("^")
+	} else {
+		b.WriteString("^(?:.*/)?")
+	}
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case pattern[i] == '*':
+			b.WriteString("[^/]*")
+		case pattern[i] == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	b.WriteString("(?:/.*)?$")
+	return regexp.MustCompile(b.String())
+}
@@ -0,0 +1,122 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v71/github"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+)
+
+func TestCompileFilePattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"*.pb.go", "pdata/internal/trace.pb.go", true},
+		{"*.pb.go", "trace.pb.go.txt", false},
+		{"vendor/", "vendor/github.com/x/y.go", true},
+		{"vendor/", "internal/vendor/y.go", true},
+		{"vendor/", "vendored.go", false},
+		{"/docs", "docs/index.md", true},
+		{"/docs", "pkg/docs/index.md", false},
+		{"internal/gen/*.go", "internal/gen/a.go", true},
+		{"internal/gen/*.go", "internal/gen/sub/a.go", false},
+		{"**/testdata/**", "pkg/a/testdata/golden.json", true},
+		{"**/testdata/**", "testdata/golden.json", true},
+		{"file?.txt", "file1.txt", true},
+		{"file?.txt", "dir/file10.txt", false},
+		{"a+b.txt", "a+b.txt", true},
+	}
+	for _, tt := range tests {
+		if got := CompileFilePattern(tt.pattern).MatchString(tt.path); got != tt.want {
+			t.Errorf("pattern %q on %q = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestFileClassifier(t *testing.T) {
+	attributes := "# generated clients\n" +
+		"client/*.go linguist-generated=true\n" +
+		"*.pb.go -linguist-generated\n" +
+		"third_party/** linguist-vendored linguist-documentation\n" +
+		"api.proto linguist-generated=false\n"
+	var fetches atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/org/repo/contents/.gitattributes", func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		_ = json.NewEncoder(w).Encode(&github.RepositoryContent{
+			Type:     github.Ptr("file"),
+			Encoding: github.Ptr("base64"),
+			Content:  github.Ptr(base64.StdEncoding.EncodeToString([]byte(attributes))),
+		})
+	})
+	classifier := NewFileClassifier(config.FileClassesConfig{
+		Generated:     []string{"*.pb.go"},
+		Vendored:      []string{"vendor/"},
+		Docs:          []string{"*.md"},
+		GitAttributes: github.Ptr(true),
+		CacheTTL:      time.Minute,
+	}, TestGitHubClient(t, mux))
+
+	classes, err := classifier.ForRepo(t.Context(), "org/repo")
+	if err != nil {
+		t.Fatalf("ForRepo failed: %v", err)
+	}
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{"client/api.go", []string{FileClassGenerated}},
+		{"model/trace.pb.go", nil}, // unset by .gitattributes
+		{"vendor/x/y.go", []string{FileClassVendored}},
+		{"third_party/lib/README.md", []string{FileClassDocs, FileClassVendored}},
+		{"main.go", nil},
+	}
+	for _, tt := range tests {
+		if got := classes.Classes(tt.path); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Classes(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+	if !classes.Noise("client/api.go") || classes.Noise("README.md") {
+		t.Error("Noise should report generated and vendored files only")
+	}
+	paths := []string{"main.go", "client/api.go", "README.md", "vendor/x/y.go"}
+	if got := classes.Without(paths, FileClassGenerated, FileClassVendored); !reflect.DeepEqual(got,
+		[]string{"main.go", "README.md"}) {
+		t.Errorf("Without() = %v", got)
+	}
+
+	if _, err := classifier.ForRepo(t.Context(), "org/repo"); err != nil {
+		t.Fatalf("ForRepo failed: %v", err)
+	}
+	if n := fetches.Load(); n != 1 {
+		t.Errorf(".gitattributes fetched %d times, want 1 (cached)", n)
+	}
+}
+
+func TestFileClassifierWithoutGitAttributes(t *testing.T) {
+	classifier := NewFileClassifier(config.FileClassesConfig{Generated: []string{"*.pb.go"}}, nil)
+	classes, err := classifier.ForRepo(t.Context(), "org/repo")
+	if err != nil {
+		t.Fatalf("ForRepo failed: %v", err)
+	}
+	if !classes.Is("a/b.pb.go", FileClassGenerated) {
+		t.Error("configured pattern not applied")
+	}
+
+	var none *FileClassifier
+	classes, err = none.ForRepo(t.Context(), "org/repo")
+	if err != nil || classes.Noise("a/b.pb.go") {
+		t.Errorf("nil classifier classified a file: %v", err)
+	}
+}
@@ -0,0 +1,175 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// flags.go evaluates feature flags through OpenFeature, so flags can live in Otto's
+// database or in the org's existing flag system behind the same API.
+
+package internal
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+	"sync/atomic"
+
+	"github.com/open-feature/go-sdk/openfeature"
+	flagtelemetry "github.com/open-feature/go-sdk/openfeature/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+)
+
+// FeatureFlagsTokenSecret is the secrets name of the bearer token for a remote flag provider.
+const FeatureFlagsTokenSecret = "feature_flags_token"
+
+// Evaluation context attributes set for every flag evaluation.
+const (
+	FlagRepoAttribute   = "repo"
+	FlagModuleAttribute = "module"
+)
+
+// flagDomains numbers the OpenFeature domains of FeatureFlags instances. The OpenFeature
+// API is global, so each instance binds its provider to its own domain.
+var flagDomains atomic.Int64
+
+// FeatureFlags evaluates feature flags per repository and module.
+type FeatureFlags struct {
+	client *openfeature.Client
+	tracer trace.Tracer
+	store  *FlagStore // nil unless flags are stored in the database
+}
+
+// NewFeatureFlags sets up the configured provider: "database" stores flags in db and
+// manages them through the admin API, "ofrep" evaluates them remotely in the org's flag
+// system over the OpenFeature Remote Evaluation Protocol using client, and "none" always
+// returns defaults. Telemetry may be nil.
+func NewFeatureFlags(
+	cfg config.FeatureFlagsConfig,
+	db *sql.DB,
+	token string,
+	client *http.Client,
+	telemetry *TelemetryManager,
+) (*FeatureFlags, error) {
+	flags := &FeatureFlags{tracer: noop.NewTracerProvider().Tracer("otto")}
+	if telemetry != nil && telemetry.TracerProvider != nil {
+		flags.tracer = telemetry.Tracer()
+	}
+
+	var provider openfeature.FeatureProvider
+	switch cfg.Provider {
+	case "database":
+		store, err := NewFlagStore(db)
+		if err != nil {
+			return nil, err
+		}
+		flags.store, provider = store, store
+	case "ofrep":
+		if cfg.URL == "" {
+			return nil, errors.New("feature_flags: the ofrep provider needs a url")
+		}
+		provider = NewOFREPProvider(cfg.URL, token, client)
+	case "none":
+		provider = openfeature.NoopProvider{}
+	default:
+		return nil, fmt.Errorf("feature_flags: unknown provider %q (use database, ofrep or none)", cfg.Provider)
+	}
+
+	domain := fmt.Sprintf("otto-%d", flagDomains.Add(1))
+	if err := openfeature.SetNamedProviderAndWait(domain, provider); err != nil {
+		return nil, fmt.Errorf("feature_flags: failed to initialize %s provider: %w", cfg.Provider, err)
+	}
+	flags.client = openfeature.NewClient(domain)
+	flags.client.AddHooks(flagEvaluationHook{})
+	return flags, nil
+}
+
+// RenameRepo moves the flag values stored for a repository to its new name. Flags that are
+// not stored in the database are left to the flag system, and a nil FeatureFlags does nothing.
+func (f *FeatureFlags) RenameRepo(ctx context.Context, from, to string) error {
+	if f == nil || f.store == nil {
+		return nil
+	}
+	return f.store.RenameRepo(ctx, from, to)
+}
+
+// Enabled evaluates a boolean flag for a repository and module, either of which may be
+// empty. It returns defaultValue if the flag is not set or cannot be evaluated. A nil
+// FeatureFlags always returns defaultValue.
+func (f *FeatureFlags) Enabled(ctx context.Context, flag, repo, module string, defaultValue bool) bool {
+	if f == nil {
+		return defaultValue
+	}
+	ctx, span := f.tracer.Start(ctx, "feature_flag.evaluate",
+		trace.WithAttributes(attribute.String(flagtelemetry.FlagKey, flag)))
+	defer span.End()
+
+	evalCtx := openfeature.NewEvaluationContext(flagTargetingKey(repo, module), map[string]any{
+		FlagRepoAttribute:   repo,
+		FlagModuleAttribute: module,
+	})
+	value, err := f.client.BooleanValue(ctx, flag, defaultValue, evalCtx)
+	if err != nil {
+		slog.Debug("Feature flag evaluation failed; using default",
+			"flag", flag, "repo", repo, "module", module, "default", defaultValue, "error", err)
+	}
+	return value
+}
+
+// flagTargetingKey identifies what a flag is evaluated for: repo/module, or whichever is set.
+func flagTargetingKey(repo, module string) string {
+	switch {
+	case repo != "" && module != "":
+		return repo + "/" + module
+	case repo != "":
+		return repo
+	}
+	return module
+}
+
+// RegisterAdminRoutes exposes the flag store on the admin API when flags are stored in
+// the database.
+func (f *FeatureFlags) RegisterAdminRoutes(srv *Server) {
+	if f == nil || f.store == nil || srv == nil {
+		return
+	}
+	srv.HandleAdmin("GET /admin/flags", f.store.handleList)
+	srv.HandleAdmin("PUT /admin/flags/{flag}", f.store.handleSet)
+	srv.HandleAdmin("DELETE /admin/flags/{flag}", f.store.handleDelete)
+}
+
+// flagEvaluationHook records every evaluation as a feature_flag.evaluation event on the
+// current span, following the OpenTelemetry semantic conventions for feature flags.
+type flagEvaluationHook struct {
+	openfeature.UnimplementedHook
+}
+
+func (flagEvaluationHook) Finally(
+	ctx context.Context,
+	hookContext openfeature.HookContext,
+	details openfeature.InterfaceEvaluationDetails,
+	_ openfeature.HookHints,
+) {
+	event := flagtelemetry.CreateEvaluationEvent(hookContext, details)
+	attrs := make([]attribute.KeyValue, 0, len(event.Attributes))
+	for _, key := range slices.Sorted(maps.Keys(event.Attributes)) {
+		switch v := event.Attributes[key].(type) {
+		case string:
+			attrs = append(attrs, attribute.String(key, v))
+		case bool:
+			attrs = append(attrs, attribute.Bool(key, v))
+		case int64:
+			attrs = append(attrs, attribute.Int64(key, v))
+		case float64:
+			attrs = append(attrs, attribute.Float64(key, v))
+		default:
+			attrs = append(attrs, attribute.String(key, fmt.Sprint(v)))
+		}
+	}
+	trace.SpanFromContext(ctx).AddEvent(event.Name, trace.WithAttributes(attrs...))
+}
@@ -0,0 +1,67 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+)
+
+func TestFeatureFlags(t *testing.T) {
+	telemetry := TestTelemetry(t, nil)
+	spans := tracetest.NewSpanRecorder()
+	telemetry.TracerProvider = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))
+	flags, err := NewFeatureFlags(config.FeatureFlagsConfig{Provider: "database"}, TestDB(t), "", nil, telemetry)
+	if err != nil {
+		t.Fatalf("NewFeatureFlags failed: %v", err)
+	}
+	if err := flags.store.Set(t.Context(), FlagSetting{Flag: "module.sla", Repo: "org/off", Enabled: false}); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+
+	if !flags.Enabled(t.Context(), "module.sla", "org/on", "sla", true) {
+		t.Error("flag should fall back to the default for other repositories")
+	}
+	if flags.Enabled(t.Context(), "module.sla", "org/off", "sla", true) {
+		t.Error("flag should be disabled for org/off")
+	}
+	var nilFlags *FeatureFlags
+	if !nilFlags.Enabled(t.Context(), "module.sla", "org/off", "sla", true) {
+		t.Error("nil FeatureFlags should return the default")
+	}
+
+	ended := spans.Ended()
+	if len(ended) != 2 {
+		t.Fatalf("recorded %d spans, want 2", len(ended))
+	}
+	events := ended[1].Events()
+	if len(events) != 1 || events[0].Name != "feature_flag.evaluation" {
+		t.Fatalf("unexpected span events: %+v", events)
+	}
+	attrs := map[string]string{}
+	for _, kv := range events[0].Attributes {
+		attrs[string(kv.Key)] = kv.Value.Emit()
+	}
+	for key, want := range map[string]string{
+		"feature_flag.key":            "module.sla",
+		"feature_flag.result.variant": "false",
+		"feature_flag.result.reason":  "targeting_match",
+		"feature_flag.provider.name":  "otto-database",
+		"feature_flag.context.id":     "org/off/sla",
+	} {
+		if attrs[key] != want {
+			t.Errorf("event attribute %s = %q, want %q (all: %v)", key, attrs[key], want, attrs)
+		}
+	}
+
+	if _, err := NewFeatureFlags(config.FeatureFlagsConfig{Provider: "ofrep"}, nil, "", nil, nil); err == nil {
+		t.Error("expected an error for the ofrep provider without a url")
+	}
+	if _, err := NewFeatureFlags(config.FeatureFlagsConfig{Provider: "launchdarkly"}, nil, "", nil, nil); err == nil {
+		t.Error("expected an error for an unknown provider")
+	}
+}
@@ -0,0 +1,237 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// flagstore.go keeps boolean feature flags in the database and serves them as an
+// OpenFeature provider, with optional overrides per repository and module.
+
+package internal
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/open-feature/go-sdk/openfeature"
+)
+
+// FlagSetting is a stored flag value. Empty Repo or Module match every repository or module.
+type FlagSetting struct {
+	Flag      string    `json:"flag"`
+	Repo      string    `json:"repo,omitempty"`
+	Module    string    `json:"module,omitempty"`
+	Enabled   bool      `json:"enabled"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// FlagStore reads and writes the feature_flags table.
+type FlagStore struct {
+	db *sql.DB
+}
+
+// NewFlagStore creates the flag store, creating its table if needed.
+func NewFlagStore(db *sql.DB) (*FlagStore, error) {
+	stmt := `CREATE TABLE IF NOT EXISTS feature_flags (
+		flag TEXT NOT NULL,
+		repo TEXT NOT NULL DEFAULT '',
+		module TEXT NOT NULL DEFAULT '',
+		enabled BOOLEAN NOT NULL,
+		updated_at TIMESTAMP NOT NULL,
+		PRIMARY KEY (flag, repo, module)
+	);`
+	if _, err := db.Exec(stmt); err != nil {
+		return nil, fmt.Errorf("failed migration: %w (SQL: %s)", err, stmt)
+	}
+	return &FlagStore{db: db}, nil
+}
+
+// Set stores a flag value for a repository and module, either of which may be empty.
+func (s *FlagStore) Set(ctx context.Context, setting FlagSetting) error {
+	if setting.UpdatedAt.IsZero() {
+		setting.UpdatedAt = time.Now()
+	}
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO feature_flags (flag, repo, module, enabled, updated_at) VALUES (?, ?, ?, ?, ?)
+		 ON CONFLICT (flag, repo, module) DO UPDATE SET enabled = excluded.enabled, updated_at = excluded.updated_at`,
+		setting.Flag, setting.Repo, setting.Module, setting.Enabled, setting.UpdatedAt,
+	)
+	if err != nil {
+		return LogAndWrapError(err, ErrorTypeDatabase, "set_flag", map[string]any{"flag": setting.Flag})
+	}
+	return nil
+}
+
+// Delete removes a flag value, reporting whether it existed.
+func (s *FlagStore) Delete(ctx context.Context, flag, repo, module string) (bool, error) {
+	res, err := s.db.ExecContext(ctx,
+		`DELETE FROM feature_flags WHERE flag = ? AND repo = ? AND module = ?`, flag, repo, module)
+	if err != nil {
+		return false, LogAndWrapError(err, ErrorTypeDatabase, "delete_flag", map[string]any{"flag": flag})
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// RenameRepo moves the flag values of a renamed or transferred repository to its new name.
+func (s *FlagStore) RenameRepo(ctx context.Context, from, to string) error {
+	return RenameRepoIn(ctx, s.db, from, to, "feature_flags.repo")
+}
+
+// List returns all stored flag values ordered by flag, repository and module.
+func (s *FlagStore) List(ctx context.Context) ([]FlagSetting, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT flag, repo, module, enabled, updated_at FROM feature_flags ORDER BY flag, repo, module`)
+	if err != nil {
+		return nil, LogAndWrapError(err, ErrorTypeDatabase, "list_flags", nil)
+	}
+	defer rows.Close()
+	settings := []FlagSetting{}
+	for rows.Next() {
+		var f FlagSetting
+		if err := rows.Scan(&f.Flag, &f.Repo, &f.Module, &f.Enabled, &f.UpdatedAt); err != nil {
+			return nil, err
+		}
+		settings = append(settings, f)
+	}
+	return settings, rows.Err()
+}
+
+// lookup returns the most specific value stored for a flag: repository and module, then
+// repository, then module, then the global value. ok is false if none is stored.
+func (s *FlagStore) lookup(ctx context.Context, flag, repo, module string) (setting FlagSetting, ok bool, err error) {
+	err = s.db.QueryRowContext(ctx,
+		`SELECT flag, repo, module, enabled, updated_at FROM feature_flags
+		 WHERE flag = ? AND repo IN (?, '') AND module IN (?, '')
+		 ORDER BY repo != '' DESC, module != '' DESC LIMIT 1`,
+		flag, repo, module,
+	).Scan(&setting.Flag, &setting.Repo, &setting.Module, &setting.Enabled, &setting.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return setting, false, nil
+	}
+	return setting, err == nil, err
+}
+
+// Metadata implements openfeature.FeatureProvider.
+func (s *FlagStore) Metadata() openfeature.Metadata {
+	return openfeature.Metadata{Name: "otto-database"}
+}
+
+// Hooks implements openfeature.FeatureProvider.
+func (s *FlagStore) Hooks() []openfeature.Hook { return nil }
+
+// BooleanEvaluation resolves a flag from the table for the repo and module in the
+// evaluation context.
+func (s *FlagStore) BooleanEvaluation(
+	ctx context.Context,
+	flag string,
+	defaultValue bool,
+	flatCtx openfeature.FlattenedContext,
+) openfeature.BoolResolutionDetail {
+	repo, _ := flatCtx[FlagRepoAttribute].(string)
+	module, _ := flatCtx[FlagModuleAttribute].(string)
+	setting, ok, err := s.lookup(ctx, flag, repo, module)
+	var detail openfeature.ProviderResolutionDetail
+	switch {
+	case err != nil:
+		detail.ResolutionError, detail.Reason = openfeature.NewGeneralResolutionError(err.Error()), openfeature.ErrorReason
+		return openfeature.BoolResolutionDetail{Value: defaultValue, ProviderResolutionDetail: detail}
+	case !ok:
+		detail.ResolutionError = openfeature.NewFlagNotFoundResolutionError(fmt.Sprintf("flag %q is not set", flag))
+		detail.Reason = openfeature.DefaultReason
+		return openfeature.BoolResolutionDetail{Value: defaultValue, ProviderResolutionDetail: detail}
+	}
+	detail.Reason, detail.Variant = openfeature.StaticReason, strconv.FormatBool(setting.Enabled)
+	if setting.Repo != "" || setting.Module != "" {
+		detail.Reason = openfeature.TargetingMatchReason
+	}
+	return openfeature.BoolResolutionDetail{Value: setting.Enabled, ProviderResolutionDetail: detail}
+}
+
+// typeMismatch is returned for non-boolean evaluations; database flags are on or off.
+func typeMismatch() openfeature.ProviderResolutionDetail {
+	return openfeature.ProviderResolutionDetail{
+		ResolutionError: openfeature.NewTypeMismatchResolutionError("database flags are boolean"),
+		Reason:          openfeature.ErrorReason,
+	}
+}
+
+// StringEvaluation implements openfeature.FeatureProvider.
+func (s *FlagStore) StringEvaluation(
+	_ context.Context, _ string, defaultValue string, _ openfeature.FlattenedContext,
+) openfeature.StringResolutionDetail {
+	return openfeature.StringResolutionDetail{Value: defaultValue, ProviderResolutionDetail: typeMismatch()}
+}
+
+// FloatEvaluation implements openfeature.FeatureProvider.
+func (s *FlagStore) FloatEvaluation(
+	_ context.Context, _ string, defaultValue float64, _ openfeature.FlattenedContext,
+) openfeature.FloatResolutionDetail {
+	return openfeature.FloatResolutionDetail{Value: defaultValue, ProviderResolutionDetail: typeMismatch()}
+}
+
+// IntEvaluation implements openfeature.FeatureProvider.
+func (s *FlagStore) IntEvaluation(
+	_ context.Context, _ string, defaultValue int64, _ openfeature.FlattenedContext,
+) openfeature.IntResolutionDetail {
+	return openfeature.IntResolutionDetail{Value: defaultValue, ProviderResolutionDetail: typeMismatch()}
+}
+
+// ObjectEvaluation implements openfeature.FeatureProvider.
+func (s *FlagStore) ObjectEvaluation(
+	_ context.Context, _ string, defaultValue any, _ openfeature.FlattenedContext,
+) openfeature.InterfaceResolutionDetail {
+	return openfeature.InterfaceResolutionDetail{Value: defaultValue, ProviderResolutionDetail: typeMismatch()}
+}
+
+// handleList writes all stored flag values as JSON.
+func (s *FlagStore) handleList(w http.ResponseWriter, r *http.Request) {
+	settings, err := s.List(r.Context())
+	if err != nil {
+		http.Error(w, "failed to list flags", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(settings); err != nil {
+		slog.Error("Failed to write JSON response", "error", err)
+	}
+}
+
+// handleSet stores a flag value from a JSON body like {"repo": "org/repo", "enabled": false}.
+func (s *FlagStore) handleSet(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Repo    string `json:"repo"`
+		Module  string `json:"module"`
+		Enabled *bool  `json:"enabled"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Enabled == nil {
+		http.Error(w, `body must be JSON with "enabled" and optional "repo" and "module"`, http.StatusBadRequest)
+		return
+	}
+	setting := FlagSetting{Flag: r.PathValue("flag"), Repo: body.Repo, Module: body.Module, Enabled: *body.Enabled}
+	if err := s.Set(r.Context(), setting); err != nil {
+		http.Error(w, "failed to set flag", http.StatusInternalServerError)
+		return
+	}
+	slog.Info("Feature flag set", "flag", setting.Flag, "repo", setting.Repo, "module", setting.Module,
+		"enabled", setting.Enabled)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleDelete removes the flag value selected by the repo and module query parameters.
+func (s *FlagStore) handleDelete(w http.ResponseWriter, r *http.Request) {
+	flag, query := r.PathValue("flag"), r.URL.Query()
+	found, err := s.Delete(r.Context(), flag, query.Get("repo"), query.Get("module"))
+	if err != nil {
+		http.Error(w, "failed to delete flag", http.StatusInternalServerError)
+		return
+	}
+	if !found {
+		http.Error(w, "flag not set", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,100 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/open-feature/go-sdk/openfeature"
+)
+
+func TestFlagStoreEvaluation(t *testing.T) {
+	store, err := NewFlagStore(TestDB(t))
+	if err != nil {
+		t.Fatalf("NewFlagStore failed: %v", err)
+	}
+	for _, s := range []FlagSetting{
+		{Flag: "module.sla", Enabled: true},
+		{Flag: "module.sla", Module: "sla", Enabled: false},
+		{Flag: "module.sla", Repo: "org/a", Enabled: true},
+		{Flag: "module.sla", Repo: "org/b", Module: "sla", Enabled: true},
+	} {
+		if err := store.Set(t.Context(), s); err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		flag       string
+		repo       string
+		module     string
+		want       bool
+		wantReason openfeature.Reason
+	}{
+		{name: "global", flag: "module.sla", want: true, wantReason: openfeature.StaticReason},
+		{name: "module override", flag: "module.sla", repo: "org/c", module: "sla", want: false,
+			wantReason: openfeature.TargetingMatchReason},
+		{name: "repo beats module", flag: "module.sla", repo: "org/a", module: "sla", want: true,
+			wantReason: openfeature.TargetingMatchReason},
+		{name: "repo and module", flag: "module.sla", repo: "org/b", module: "sla", want: true,
+			wantReason: openfeature.TargetingMatchReason},
+		{name: "unset", flag: "module.owners", repo: "org/a", want: true, wantReason: openfeature.DefaultReason},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := store.BooleanEvaluation(t.Context(), tt.flag, true, openfeature.FlattenedContext{
+				FlagRepoAttribute: tt.repo, FlagModuleAttribute: tt.module,
+			})
+			if got.Value != tt.want || got.Reason != tt.wantReason {
+				t.Errorf("BooleanEvaluation() = %v (%s), want %v (%s)", got.Value, got.Reason, tt.want, tt.wantReason)
+			}
+		})
+	}
+
+	if found, err := store.Delete(t.Context(), "module.sla", "", "sla"); err != nil || !found {
+		t.Fatalf("Delete = %v, %v; want true", found, err)
+	}
+	settings, err := store.List(t.Context())
+	if err != nil || len(settings) != 3 {
+		t.Errorf("List = %d settings, %v; want 3", len(settings), err)
+	}
+}
+
+func TestFlagStoreAdminHandlers(t *testing.T) {
+	store, err := NewFlagStore(TestDB(t))
+	if err != nil {
+		t.Fatalf("NewFlagStore failed: %v", err)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /admin/flags", store.handleList)
+	mux.HandleFunc("PUT /admin/flags/{flag}", store.handleSet)
+	mux.HandleFunc("DELETE /admin/flags/{flag}", store.handleDelete)
+	do := func(method, target, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(method, target, strings.NewReader(body)))
+		return w
+	}
+
+	if w := do(http.MethodPut, "/admin/flags/module.sla", `{"repo":"org/a"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("PUT without enabled = %d, want 400", w.Code)
+	}
+	w := do(http.MethodPut, "/admin/flags/module.sla", `{"repo":"org/a","enabled":false}`)
+	if w.Code != http.StatusNoContent {
+		t.Errorf("PUT = %d, want 204", w.Code)
+	}
+	w = do(http.MethodGet, "/admin/flags", "")
+	if w.Code != http.StatusOK ||
+		!strings.Contains(w.Body.String(), `"flag":"module.sla","repo":"org/a","enabled":false`) {
+		t.Errorf("GET = %d %s", w.Code, w.Body.String())
+	}
+	if w := do(http.MethodDelete, "/admin/flags/module.sla?repo=org/a", ""); w.Code != http.StatusNoContent {
+		t.Errorf("DELETE = %d, want 204", w.Code)
+	}
+	if w := do(http.MethodDelete, "/admin/flags/module.sla?repo=org/a", ""); w.Code != http.StatusNotFound {
+		t.Errorf("second DELETE = %d, want 404", w.Code)
+	}
+}
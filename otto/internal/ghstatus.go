@@ -0,0 +1,217 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// ghstatus.go follows githubstatus.com so Otto can back off while the GitHub API is
+// degraded: deferrable jobs pause, retries wait longer, and errors are logged with the
+// incident rather than as new failures.
+
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// githubAPIComponent is the status page component covering the REST API.
+const githubAPIComponent = "API Requests"
+
+// knownIncident holds the GitHub incident reported by the latest status poll, so errors
+// logged anywhere in the process can be attributed to it.
+var knownIncident atomic.Pointer[string]
+
+// KnownGitHubIncident describes the ongoing GitHub API incident, or returns "".
+func KnownGitHubIncident() string {
+	if p := knownIncident.Load(); p != nil {
+		return *p
+	}
+	return ""
+}
+
+// statusSummary is the subset of the Statuspage summary.json Otto uses.
+type statusSummary struct {
+	Components []struct {
+		Name   string `json:"name"`
+		Status string `json:"status"` // operational, degraded_performance, partial_outage, major_outage
+	} `json:"components"`
+	Incidents []struct {
+		Name      string `json:"name"`
+		Status    string `json:"status"`
+		Impact    string `json:"impact"`
+		Shortlink string `json:"shortlink"`
+	} `json:"incidents"`
+}
+
+// GitHubStatus polls the GitHub status page and reports whether the API is degraded.
+type GitHubStatus struct {
+	url    string
+	client *http.Client
+
+	mu       sync.RWMutex
+	degraded bool
+	incident string
+
+	// backoff is the first retry delay; it doubles per attempt and quadruples while degraded.
+	backoff    time.Duration
+	maxRetries int
+}
+
+// NewGitHubStatus creates a status poller for a Statuspage summary URL.
+func NewGitHubStatus(url string) *GitHubStatus {
+	return &GitHubStatus{
+		url:        url,
+		client:     &http.Client{Timeout: 10 * time.Second},
+		backoff:    time.Second,
+		maxRetries: 2,
+	}
+}
+
+// WithHTTPClient overrides the client used to poll the status page.
+func (s *GitHubStatus) WithHTTPClient(client *http.Client) *GitHubStatus {
+	s.client = client
+	return s
+}
+
+// Poll fetches the status page and updates the degraded state. It runs on the scheduler.
+func (s *GitHubStatus) Poll(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch GitHub status: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch GitHub status: unexpected status %s", resp.Status)
+	}
+	var summary statusSummary
+	if err := json.NewDecoder(resp.Body).Decode(&summary); err != nil {
+		return fmt.Errorf("failed to decode GitHub status: %w", err)
+	}
+
+	degraded, incident := false, ""
+	for _, c := range summary.Components {
+		if c.Name == githubAPIComponent && c.Status != "operational" {
+			degraded, incident = true, fmt.Sprintf("GitHub API %s", c.Status)
+		}
+	}
+	if degraded {
+		for _, i := range summary.Incidents {
+			if i.Status != "resolved" && i.Status != "postmortem" {
+				incident = fmt.Sprintf("%s (%s impact) %s", i.Name, i.Impact, i.Shortlink)
+				break
+			}
+		}
+	}
+	s.set(degraded, incident)
+	return nil
+}
+
+// set records the degraded state, logging transitions.
+func (s *GitHubStatus) set(degraded bool, incident string) {
+	s.mu.Lock()
+	changed := s.degraded != degraded
+	s.degraded, s.incident = degraded, incident
+	s.mu.Unlock()
+
+	if degraded {
+		knownIncident.Store(&incident)
+	} else {
+		knownIncident.Store(nil)
+	}
+	switch {
+	case changed && degraded:
+		slog.Warn("GitHub API degraded; pausing deferrable jobs", "incident", incident)
+	case changed:
+		slog.Info("GitHub API recovered; resuming deferrable jobs")
+	}
+}
+
+// Degraded reports whether the GitHub API was degraded at the last poll. A nil status
+// is never degraded.
+func (s *GitHubStatus) Degraded() bool {
+	if s == nil {
+		return false
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.degraded
+}
+
+// Incident describes the ongoing incident, or returns "" when the API is operational.
+func (s *GitHubStatus) Incident() string {
+	if s == nil {
+		return ""
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.incident
+}
+
+// Transport wraps base so idempotent requests that fail with a server error are retried,
+// waiting longer between attempts while GitHub reports an incident. A nil status
+// returns base unchanged.
+func (s *GitHubStatus) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if s == nil {
+		return base
+	}
+	return &retryTransport{status: s, base: base}
+}
+
+type retryTransport struct {
+	status *GitHubStatus
+	base   http.RoundTripper
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return t.base.RoundTrip(req)
+	}
+	delay := t.status.backoff
+	for attempt := 0; ; attempt++ {
+		resp, err := t.base.RoundTrip(req)
+		if attempt >= t.status.maxRetries || !retryable(resp, err) {
+			return resp, err
+		}
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		wait := delay
+		if t.status.Degraded() {
+			wait *= 4
+		}
+		slog.Debug("Retrying GitHub request", "url", req.URL.Path, "attempt", attempt+1, "wait", wait)
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(wait):
+		}
+		delay *= 2
+	}
+}
+
+// retryable reports whether a request may succeed if sent again.
+func retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, ErrAPIBudgetExhausted) &&
+			!errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,155 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const degradedSummary = `{
+	"components": [
+		{"name": "Git Operations", "status": "operational"},
+		{"name": "API Requests", "status": "partial_outage"}
+	],
+	"incidents": [
+		{"name": "Incident with API Requests", "status": "investigating", "impact": "major",
+		 "shortlink": "https://stspg.io/abc"}
+	]
+}`
+
+const operationalSummary = `{
+	"components": [{"name": "API Requests", "status": "operational"}],
+	"incidents": [{"name": "Incident with Pages", "status": "investigating", "impact": "minor"}]
+}`
+
+func TestGitHubStatusPoll(t *testing.T) {
+	var body atomic.Value
+	body.Store(degradedSummary)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(body.Load().(string)))
+	}))
+	defer srv.Close()
+
+	status := NewGitHubStatus(srv.URL)
+	if err := status.Poll(t.Context()); err != nil {
+		t.Fatalf("Poll failed: %v", err)
+	}
+	want := "Incident with API Requests (major impact) https://stspg.io/abc"
+	if !status.Degraded() || status.Incident() != want || KnownGitHubIncident() != want {
+		t.Errorf("degraded = %v, incident = %q; want true, %q", status.Degraded(), status.Incident(), want)
+	}
+
+	// Incidents on other components do not count.
+	body.Store(operationalSummary)
+	if err := status.Poll(t.Context()); err != nil {
+		t.Fatalf("Poll failed: %v", err)
+	}
+	if status.Degraded() || status.Incident() != "" || KnownGitHubIncident() != "" {
+		t.Errorf("still degraded after recovery: %q", status.Incident())
+	}
+
+	var nilStatus *GitHubStatus
+	if nilStatus.Degraded() {
+		t.Error("nil status reports degraded")
+	}
+}
+
+func TestGitHubStatusRetryTransport(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := calls.Add(1)
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/missing"):
+			w.WriteHeader(http.StatusNotFound)
+			return
+		case n < 3:
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	status := NewGitHubStatus(srv.URL)
+	status.backoff = time.Millisecond
+	client := &http.Client{Transport: status.Transport(nil)}
+
+	tests := []struct {
+		name      string
+		method    string
+		path      string
+		degraded  bool
+		wantCode  int
+		wantCalls int32
+		minWait   time.Duration
+	}{
+		{"retries server errors", http.MethodGet, "/repos", false, http.StatusOK, 3, 3 * time.Millisecond},
+		{"waits longer while degraded", http.MethodGet, "/repos", true, http.StatusOK, 3, 12 * time.Millisecond},
+		{"does not retry client errors", http.MethodGet, "/missing", false, http.StatusNotFound, 1, 0},
+		{"does not retry writes", http.MethodPost, "/repos", false, http.StatusBadGateway, 1, 0},
+	}
+	for _, tt := range tests {
+		calls.Store(0)
+		status.set(tt.degraded, "test incident")
+		req, _ := http.NewRequestWithContext(context.Background(), tt.method, srv.URL+tt.path, nil)
+		start := time.Now()
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("%s: request failed: %v", tt.name, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tt.wantCode || calls.Load() != tt.wantCalls {
+			t.Errorf("%s: status %d after %d calls, want %d after %d",
+				tt.name, resp.StatusCode, calls.Load(), tt.wantCode, tt.wantCalls)
+		}
+		if elapsed := time.Since(start); elapsed < tt.minWait {
+			t.Errorf("%s: took %v, want at least %v", tt.name, elapsed, tt.minWait)
+		}
+	}
+	status.set(false, "")
+}
+
+func TestSchedulerDefersJobsWhilePaused(t *testing.T) {
+	scheduler := NewScheduler(nil)
+	var paused atomic.Bool
+	paused.Store(true)
+	scheduler.PauseDeferrable(paused.Load)
+
+	var deferrable, essential atomic.Int32
+	scheduler.Register(Job{
+		Name:       "deferrable",
+		Interval:   5 * time.Millisecond,
+		Deferrable: true,
+		Run:        func(context.Context) error { deferrable.Add(1); return nil },
+	})
+	scheduler.Register(Job{
+		Name:     "essential",
+		Interval: 5 * time.Millisecond,
+		Run:      func(context.Context) error { essential.Add(1); return nil },
+	})
+	scheduler.Start(t.Context())
+	defer scheduler.Stop()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for essential.Load() < 3 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if essential.Load() < 3 || deferrable.Load() != 0 {
+		t.Fatalf("while paused: essential ran %d times, deferrable %d; want >= 3 and 0",
+			essential.Load(), deferrable.Load())
+	}
+
+	paused.Store(false)
+	for deferrable.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if deferrable.Load() == 0 {
+		t.Error("deferrable job did not resume")
+	}
+}
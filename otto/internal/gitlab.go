@@ -0,0 +1,121 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// gitlab.go verifies GitLab webhook deliveries and normalizes their merge request and
+// issue events, for projects mirrored on GitLab.
+
+package internal
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// GitLab webhook headers.
+const (
+	gitLabTokenHeader = "X-Gitlab-Token"
+	gitLabEventHeader = "X-Gitlab-Event"
+	gitLabUUIDHeader  = "X-Gitlab-Event-UUID"
+)
+
+// gitLabActions maps GitLab object actions to the actions of normalized events.
+var gitLabActions = map[string]string{
+	"open":   "opened",
+	"update": "edited",
+	"close":  "closed",
+	"reopen": "reopened",
+	"merge":  "merged",
+}
+
+// gitLabStates maps GitLab object states to the states of normalized events.
+var gitLabStates = map[string]string{
+	"opened": "open",
+	"closed": "closed",
+	"locked": "closed",
+	"merged": "merged",
+}
+
+// gitLabPayload holds the fields of GitLab merge request and issue hooks used by Otto.
+type gitLabPayload struct {
+	ObjectKind string `json:"object_kind"`
+	User       struct {
+		Username string `json:"username"`
+	} `json:"user"`
+	Project struct {
+		PathWithNamespace string `json:"path_with_namespace"`
+	} `json:"project"`
+	ObjectAttributes struct {
+		IID          int    `json:"iid"`
+		Title        string `json:"title"`
+		Description  string `json:"description"`
+		URL          string `json:"url"`
+		State        string `json:"state"`
+		Action       string `json:"action"`
+		Draft        bool   `json:"draft"`
+		SourceBranch string `json:"source_branch"`
+		TargetBranch string `json:"target_branch"`
+	} `json:"object_attributes"`
+	Labels []struct {
+		Title string `json:"title"`
+	} `json:"labels"`
+}
+
+// verifyGitLabToken checks the secret token GitLab sends with every delivery. An empty
+// secret rejects all deliveries, since GitLab omits the header when no token is set.
+func verifyGitLabToken(secret []byte, r *http.Request) bool {
+	token := r.Header.Get(gitLabTokenHeader)
+	return len(secret) > 0 && subtle.ConstantTimeCompare([]byte(token), secret) == 1
+}
+
+// gitLabEventType names a GitLab delivery in the event store and telemetry after its
+// event header, e.g. "Merge Request Hook" is stored as gitlab.merge_request.
+func gitLabEventType(r *http.Request) string {
+	hook := strings.TrimSuffix(r.Header.Get(gitLabEventHeader), " Hook")
+	return "gitlab." + strings.ReplaceAll(strings.ToLower(hook), " ", "_")
+}
+
+// ParseGitLabEvent normalizes a GitLab merge request or issue hook. It returns nil
+// without an error for other kinds of events.
+func ParseGitLabEvent(payload []byte) (*NormalizedEvent, error) {
+	var p gitLabPayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return nil, fmt.Errorf("failed to parse GitLab event: %w", err)
+	}
+	n := &NormalizedEvent{Source: SourceGitLab}
+	switch p.ObjectKind {
+	case "merge_request":
+		n.Kind = NormalizedPullRequest
+		n.Draft = p.ObjectAttributes.Draft
+		n.SourceBranch = p.ObjectAttributes.SourceBranch
+		n.TargetBranch = p.ObjectAttributes.TargetBranch
+	case "issue":
+		n.Kind = NormalizedIssue
+	default:
+		return nil, nil
+	}
+	attrs := p.ObjectAttributes
+	n.Repo = p.Project.PathWithNamespace
+	n.Number = attrs.IID
+	n.Title = attrs.Title
+	n.Body = attrs.Description
+	n.URL = attrs.URL
+	n.Sender = p.User.Username
+	n.Action = attrs.Action
+	if action, ok := gitLabActions[attrs.Action]; ok {
+		n.Action = action
+	}
+	n.State = attrs.State
+	if state, ok := gitLabStates[attrs.State]; ok {
+		n.State = state
+	}
+	// Hooks only name the author by ID, but whoever opens an object is its author.
+	if n.Action == "opened" {
+		n.Author = n.Sender
+	}
+	for _, l := range p.Labels {
+		n.Labels = append(n.Labels, l.Title)
+	}
+	return n, nil
+}
@@ -0,0 +1,151 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+	"github.com/open-telemetry/sig-project-infra/otto/internal/secrets"
+)
+
+const gitLabMergeRequestHook = `{
+	"object_kind": "merge_request",
+	"user": {"username": "alice"},
+	"project": {"path_with_namespace": "mirrors/collector"},
+	"object_attributes": {
+		"iid": 7, "title": "Add exporter", "description": "Adds an exporter.",
+		"url": "https://gitlab.example.com/mirrors/collector/-/merge_requests/7",
+		"state": "opened", "action": "open", "draft": true,
+		"source_branch": "exporter", "target_branch": "main"
+	},
+	"labels": [{"title": "enhancement"}]
+}`
+
+func TestParseGitLabEvent(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		want    *NormalizedEvent
+		wantErr bool
+	}{
+		{
+			name:    "merge request opened",
+			payload: gitLabMergeRequestHook,
+			want: &NormalizedEvent{
+				Source: SourceGitLab, Kind: NormalizedPullRequest, Action: "opened", Repo: "mirrors/collector",
+				Number: 7, Title: "Add exporter", Body: "Adds an exporter.",
+				URL:   "https://gitlab.example.com/mirrors/collector/-/merge_requests/7",
+				State: "open", Author: "alice", Sender: "alice", Labels: []string{"enhancement"}, Draft: true,
+				SourceBranch: "exporter", TargetBranch: "main",
+			},
+		},
+		{
+			name: "issue closed",
+			payload: `{"object_kind":"issue","user":{"username":"bob"},"project":{"path_with_namespace":"g/p"},
+				"object_attributes":{"iid":3,"title":"Crash","state":"closed","action":"close"}}`,
+			want: &NormalizedEvent{
+				Source: SourceGitLab, Kind: NormalizedIssue, Action: "closed", Repo: "g/p", Number: 3,
+				Title: "Crash", State: "closed", Sender: "bob",
+			},
+		},
+		{
+			name: "merge request approved",
+			payload: `{"object_kind":"merge_request","user":{"username":"carol"},"project":{"path_with_namespace":"g/p"},
+				"object_attributes":{"iid":4,"state":"opened","action":"approved"}}`,
+			want: &NormalizedEvent{
+				Source: SourceGitLab, Kind: NormalizedPullRequest, Action: "approved", Repo: "g/p", Number: 4,
+				State: "open", Sender: "carol",
+			},
+		},
+		{name: "pipeline ignored", payload: `{"object_kind":"pipeline"}`},
+		{name: "invalid JSON", payload: `{`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseGitLabEvent([]byte(tt.payload))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseGitLabEvent() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseGitLabEvent() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+type normalizedModule struct {
+	mockModule
+	events chan *NormalizedEvent
+}
+
+func (m *normalizedModule) HandleNormalizedEvent(event *NormalizedEvent) error {
+	m.events <- event
+	return nil
+}
+
+func TestGitLabWebhook(t *testing.T) {
+	t.Setenv("OTTO_SECRET_GITLAB_WEBHOOK_TOKEN", "gl-token")
+	events, err := NewEventStore(TestDB(t))
+	if err != nil {
+		t.Fatalf("NewEventStore failed: %v", err)
+	}
+	app := &App{
+		Config: &config.AppConfig{Webhooks: []config.WebhookEndpoint{
+			{Path: "/webhook/gitlab-mirror", Source: "gitlab", Secret: "gitlab_webhook_token"},
+		}},
+		Telemetry:      TestTelemetry(t, nil),
+		Logger:         slog.Default(),
+		ModuleRegistry: NewModuleRegistry(),
+		Events:         events,
+	}
+	normalized := &normalizedModule{mockModule: mockModule{name: "normalized"}, events: make(chan *NormalizedEvent, 1)}
+	githubOnly := &mockModule{name: "github-only"}
+	app.RegisterModule(normalized)
+	app.RegisterModule(githubOnly)
+	srv := NewServerWithApp("0", secrets.NewEnvManager(), app)
+
+	post := func(token string) int {
+		req := httptest.NewRequest(http.MethodPost, "/webhook/gitlab-mirror", strings.NewReader(gitLabMergeRequestHook))
+		req.Header.Set("X-Gitlab-Event", "Merge Request Hook")
+		req.Header.Set("X-Gitlab-Token", token)
+		req.Header.Set("X-Gitlab-Event-UUID", "uuid-1")
+		rr := httptest.NewRecorder()
+		srv.mux.ServeHTTP(rr, req)
+		return rr.Code
+	}
+	if code := post("wrong"); code != http.StatusUnauthorized {
+		t.Errorf("wrong token: status = %d, want 401", code)
+	}
+	if code := post("gl-token"); code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", code)
+	}
+
+	select {
+	case event := <-normalized.events:
+		if event.Repo != "mirrors/collector" || event.Kind != NormalizedPullRequest || event.Number != 7 {
+			t.Errorf("unexpected normalized event: %+v", event)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("normalized event was not dispatched")
+	}
+	if atomic.LoadInt32(&githubOnly.handled) != 0 {
+		t.Error("modules without HandleNormalizedEvent should not receive GitLab events")
+	}
+
+	stored, err := events.Query(t.Context(), EventQuery{Type: "gitlab.merge_request"})
+	if err != nil || len(stored) != 1 {
+		t.Fatalf("stored events = %v, %v; want 1", stored, err)
+	}
+	if s := stored[0]; s.Repo != "mirrors/collector" || s.Action != "opened" || s.Sender != "alice" ||
+		s.DeliveryID != "uuid-1" {
+		t.Errorf("unexpected stored event: %+v", s)
+	}
+}
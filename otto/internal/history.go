@@ -0,0 +1,193 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// history.go records every slash command Otto executes, so maintainers can see what
+// automation was already attempted on an issue.
+
+package internal
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Command outcomes.
+const (
+	CommandOK    = "ok"
+	CommandError = "error"
+)
+
+// CommandRecord is one executed slash command.
+type CommandRecord struct {
+	ID         int64     `json:"id"`
+	Repo       string    `json:"repo"`
+	Issue      int       `json:"issue"`
+	User       string    `json:"user"`
+	Command    string    `json:"command"`
+	Args       []string  `json:"args"`
+	Outcome    string    `json:"outcome"`
+	Error      string    `json:"error,omitempty"`
+	ExecutedAt time.Time `json:"executed_at"`
+}
+
+// String renders the command as it was typed.
+func (r CommandRecord) String() string {
+	return strings.TrimSpace("/" + r.Command + " " + strings.Join(r.Args, " "))
+}
+
+// CommandQuery filters records returned by CommandHistory.Query. Zero values match everything.
+type CommandQuery struct {
+	Repo    string
+	Issue   int
+	User    string
+	Command string
+	Since   time.Time
+	Limit   int
+}
+
+// CommandHistory reads and writes the command_history table.
+type CommandHistory struct {
+	db *sql.DB
+}
+
+// NewCommandHistory creates the command history, creating its table if needed.
+func NewCommandHistory(db *sql.DB) (*CommandHistory, error) {
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS command_history (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			repo TEXT NOT NULL,
+			issue_num INTEGER NOT NULL,
+			user TEXT NOT NULL,
+			command TEXT NOT NULL,
+			args TEXT,
+			outcome TEXT NOT NULL,
+			error TEXT,
+			executed_at TIMESTAMP NOT NULL
+		);`,
+		`CREATE INDEX IF NOT EXISTS idx_command_history_issue ON command_history (repo, issue_num, executed_at);`,
+	}
+	for _, s := range stmts {
+		if _, err := db.Exec(s); err != nil {
+			return nil, fmt.Errorf("failed migration: %w (SQL: %s)", err, s)
+		}
+	}
+	return &CommandHistory{db: db}, nil
+}
+
+// Record stores an executed command.
+func (h *CommandHistory) Record(ctx context.Context, r CommandRecord) error {
+	if r.ExecutedAt.IsZero() {
+		r.ExecutedAt = time.Now()
+	}
+	args, err := json.Marshal(r.Args)
+	if err != nil {
+		return err
+	}
+	_, err = h.db.ExecContext(ctx,
+		`INSERT INTO command_history (repo, issue_num, user, command, args, outcome, error, executed_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		r.Repo, r.Issue, r.User, r.Command, string(args), r.Outcome, r.Error, r.ExecutedAt,
+	)
+	if err != nil {
+		return LogAndWrapError(err, ErrorTypeDatabase, "record_command", map[string]any{
+			"repo":    r.Repo,
+			"command": r.Command,
+		})
+	}
+	return nil
+}
+
+// Query returns commands matching q, newest first.
+func (h *CommandHistory) Query(ctx context.Context, q CommandQuery) ([]CommandRecord, error) {
+	var (
+		where []string
+		args  []any
+	)
+	if q.Repo != "" {
+		where = append(where, "repo = ?")
+		args = append(args, q.Repo)
+	}
+	if q.Issue > 0 {
+		where = append(where, "issue_num = ?")
+		args = append(args, q.Issue)
+	}
+	if q.User != "" {
+		where = append(where, "user = ?")
+		args = append(args, q.User)
+	}
+	if q.Command != "" {
+		where = append(where, "command = ?")
+		args = append(args, q.Command)
+	}
+	if !q.Since.IsZero() {
+		where = append(where, "executed_at >= ?")
+		args = append(args, q.Since)
+	}
+
+	query := `SELECT ` + commandColumns + ` FROM command_history`
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	limit := q.Limit
+	if limit <= 0 {
+		limit = -1 // SQLite: no limit
+	}
+	query += " ORDER BY executed_at DESC, id DESC LIMIT ?"
+	args = append(args, limit)
+	return h.query(ctx, query, args...)
+}
+
+// CommandListSchema describes commands to the admin API's list query layer.
+var CommandListSchema = ListSchema{
+	Fields: []ListField{
+		{Name: "id", Column: "id"},
+		{Name: "repo", Column: "repo"},
+		{Name: "issue", Column: "issue_num"},
+		{Name: "user", Column: "user"},
+		{Name: "command", Column: "command"},
+		{Name: "args"},
+		{Name: "outcome", Column: "outcome"},
+		{Name: "error", Column: "error"},
+		{Name: "executed_at", Column: "executed_at"},
+	},
+	Time: "executed_at",
+	Sort: "-executed_at",
+	Key:  "id",
+}
+
+// Select returns the commands matching a list query.
+func (h *CommandHistory) Select(ctx context.Context, q ListQuery) ([]CommandRecord, error) {
+	query, args := q.SQL(`SELECT ` + commandColumns + ` FROM command_history`)
+	return h.query(ctx, query, args...)
+}
+
+const commandColumns = `id, repo, issue_num, user, command, args, outcome, error, executed_at`
+
+func (h *CommandHistory) query(ctx context.Context, query string, args ...any) ([]CommandRecord, error) {
+	rows, err := h.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, LogAndWrapError(err, ErrorTypeDatabase, "query_commands", nil)
+	}
+	defer rows.Close()
+
+	var records []CommandRecord
+	for rows.Next() {
+		var (
+			r             CommandRecord
+			argsJSON, msg sql.NullString
+		)
+		if err := rows.Scan(&r.ID, &r.Repo, &r.Issue, &r.User, &r.Command, &argsJSON, &r.Outcome, &msg,
+			&r.ExecutedAt); err != nil {
+			return nil, err
+		}
+		if argsJSON.Valid {
+			_ = json.Unmarshal([]byte(argsJSON.String), &r.Args)
+		}
+		r.Error = msg.String
+		records = append(records, r)
+	}
+	return records, rows.Err()
+}
@@ -0,0 +1,114 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v71/github"
+)
+
+type failingModule struct{ name string }
+
+func (m *failingModule) Name() string { return m.name }
+func (m *failingModule) HandleEvent(eventType string, event any, raw json.RawMessage) error {
+	return errors.New("boom")
+}
+
+func TestCommandHistory(t *testing.T) {
+	history, err := NewCommandHistory(TestDB(t))
+	if err != nil {
+		t.Fatalf("NewCommandHistory failed: %v", err)
+	}
+	ctx := t.Context()
+	start := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	records := []CommandRecord{
+		{Repo: "org/a", Issue: 1, User: "alice", Command: "oncall", Args: []string{"who"}, Outcome: CommandOK},
+		{Repo: "org/a", Issue: 1, User: "bob", Command: "close-all-stale", Outcome: CommandError, Error: "sla: boom"},
+		{Repo: "org/a", Issue: 2, User: "alice", Command: "changelog", Args: []string{"added:", "x"}, Outcome: CommandOK},
+		{Repo: "org/b", Issue: 1, User: "alice", Command: "oncall", Args: []string{"ooo", "clear"}, Outcome: CommandOK},
+	}
+	for i, r := range records {
+		r.ExecutedAt = start.Add(time.Duration(i) * time.Hour)
+		if err := history.Record(ctx, r); err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		query CommandQuery
+		want  []string
+	}{
+		{"issue", CommandQuery{Repo: "org/a", Issue: 1}, []string{"/close-all-stale", "/oncall who"}},
+		{
+			"user across repos", CommandQuery{User: "alice"},
+			[]string{"/oncall ooo clear", "/changelog added: x", "/oncall who"},
+		},
+		{"command", CommandQuery{Command: "oncall"}, []string{"/oncall ooo clear", "/oncall who"}},
+		{"since", CommandQuery{Since: start.Add(2 * time.Hour)}, []string{"/oncall ooo clear", "/changelog added: x"}},
+		{"limit", CommandQuery{Limit: 1}, []string{"/oncall ooo clear"}},
+	}
+	for _, tt := range tests {
+		got, err := history.Query(ctx, tt.query)
+		if err != nil {
+			t.Fatalf("%s: Query failed: %v", tt.name, err)
+		}
+		var commands []string
+		for _, r := range got {
+			commands = append(commands, r.String())
+		}
+		if !slices.Equal(commands, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, commands, tt.want)
+		}
+	}
+
+	got, _ := history.Query(ctx, CommandQuery{Command: "close-all-stale"})
+	if len(got) != 1 || got[0].Outcome != CommandError || got[0].Error != "sla: boom" || got[0].User != "bob" {
+		t.Errorf("unexpected error record: %+v", got)
+	}
+}
+
+func TestDispatchRecordsCommands(t *testing.T) {
+	history, err := NewCommandHistory(TestDB(t))
+	if err != nil {
+		t.Fatalf("NewCommandHistory failed: %v", err)
+	}
+	app := &App{ModuleRegistry: NewModuleRegistry(), Commands: history, Logger: slog.Default()}
+	app.RegisterModule(&failingModule{name: "broken"})
+	app.RegisterModule(&commandHandlerModule{name: "oncall", commands: []string{"oncall who"}})
+
+	event := &github.IssueCommentEvent{
+		Action: github.Ptr("created"),
+		Repo:   &github.Repository{FullName: github.Ptr("org/repo")},
+		Issue:  &github.Issue{Number: github.Ptr(7)},
+		Comment: &github.IssueComment{
+			Body: github.Ptr("please\n/oncall who\n/otto history 5"),
+			User: &github.User{Login: github.Ptr("alice")},
+		},
+	}
+	app.handleEvent("", "issue_comment", event, nil, nil)
+
+	// Bot comments and other events are not commands.
+	bot := *event
+	bot.Comment = &github.IssueComment{
+		Body: github.Ptr("/confirm abc"),
+		User: &github.User{Login: github.Ptr("otto[bot]"), Type: github.Ptr("Bot")},
+	}
+	app.handleEvent("", "issue_comment", &bot, nil, nil)
+
+	// Only the command a module handles is recorded, with its own outcome, not with the
+	// errors of other modules handling the event.
+	records, err := history.Query(t.Context(), CommandQuery{Repo: "org/repo", Issue: 7})
+	if err != nil || len(records) != 1 {
+		t.Fatalf("got %+v, %v; want 1 record", records, err)
+	}
+	if r := records[0]; r.String() != "/oncall who" || r.User != "alice" || r.Outcome != CommandOK || r.Error != "" {
+		t.Errorf("unexpected record: %+v", r)
+	}
+}
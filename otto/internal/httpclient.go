@@ -0,0 +1,84 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// httpclient.go builds the transport for outbound requests, so deployments behind an
+// egress proxy or a TLS-intercepting gateway can reach GitHub, Slack and the collector.
+
+package internal
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"golang.org/x/net/http/httpproxy"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+)
+
+// tlsVersions maps tls_min_version values to crypto/tls versions.
+var tlsVersions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// NewHTTPTransport returns a copy of http.DefaultTransport that uses the configured proxy,
+// trusts the configured CA bundle in addition to the system roots, and requires the
+// configured minimum TLS version.
+func NewHTTPTransport(cfg config.HTTPConfig) (*http.Transport, error) {
+	tlsConfig, err := NewTLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	if cfg.ProxyURL != "" {
+		if _, err := url.Parse(cfg.ProxyURL); err != nil {
+			return nil, fmt.Errorf("http: invalid proxy_url: %w", err)
+		}
+		proxy := (&httpproxy.Config{HTTPProxy: cfg.ProxyURL, HTTPSProxy: cfg.ProxyURL, NoProxy: cfg.NoProxy}).ProxyFunc()
+		transport.Proxy = func(req *http.Request) (*url.URL, error) {
+			return proxy(req.URL)
+		}
+	}
+	return transport, nil
+}
+
+// NewTLSConfig returns the TLS settings for outbound connections.
+func NewTLSConfig(cfg config.HTTPConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if cfg.TLSMinVersion != "" {
+		version, ok := tlsVersions[cfg.TLSMinVersion]
+		if !ok {
+			return nil, fmt.Errorf("http: unsupported tls_min_version %q (use 1.2 or 1.3)", cfg.TLSMinVersion)
+		}
+		tlsConfig.MinVersion = version
+	}
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("http: failed to read ca_file: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("http: no certificates found in ca_file %s", cfg.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	return tlsConfig, nil
+}
+
+// HTTPClient returns a client for outbound requests through the configured transport.
+func (a *App) HTTPClient(timeout time.Duration) *http.Client {
+	client := &http.Client{Timeout: timeout}
+	if a != nil && a.Transport != nil {
+		client.Transport = a.Transport
+	}
+	return client
+}
@@ -0,0 +1,102 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"crypto/tls"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+)
+
+func TestHTTPTransportCAFile(t *testing.T) {
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	srv.TLS = &tls.Config{MaxVersion: tls.VersionTLS12}
+	srv.StartTLS()
+	defer srv.Close()
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(caFile, certPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		cfg     config.HTTPConfig
+		wantErr bool
+	}{
+		{name: "system roots only", cfg: config.HTTPConfig{TLSMinVersion: "1.2"}, wantErr: true},
+		{name: "custom CA", cfg: config.HTTPConfig{CAFile: caFile, TLSMinVersion: "1.2"}},
+		{name: "TLS 1.3 required", cfg: config.HTTPConfig{CAFile: caFile, TLSMinVersion: "1.3"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport, err := NewHTTPTransport(tt.cfg)
+			if err != nil {
+				t.Fatalf("NewHTTPTransport failed: %v", err)
+			}
+			client := &http.Client{Transport: transport, Timeout: 5 * time.Second}
+			resp, err := client.Get(srv.URL)
+			if err == nil {
+				resp.Body.Close()
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GET error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHTTPTransportProxy(t *testing.T) {
+	var proxied []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r.URL.String())
+	}))
+	defer proxy.Close()
+
+	transport, err := NewHTTPTransport(config.HTTPConfig{ProxyURL: proxy.URL, NoProxy: "internal.example.com"})
+	if err != nil {
+		t.Fatalf("NewHTTPTransport failed: %v", err)
+	}
+	for _, target := range []string{"http://api.example.com/events", "http://collector.internal.example.com/"} {
+		req, _ := http.NewRequest(http.MethodGet, target, nil)
+		proxyURL, err := transport.Proxy(req)
+		if err != nil {
+			t.Fatalf("Proxy(%s) failed: %v", target, err)
+		}
+		if proxyURL != nil {
+			resp, err := (&http.Client{Transport: transport}).Do(req)
+			if err != nil {
+				t.Fatalf("GET %s failed: %v", target, err)
+			}
+			resp.Body.Close()
+		}
+	}
+	if len(proxied) != 1 || proxied[0] != "http://api.example.com/events" {
+		t.Errorf("proxied requests = %v, want only api.example.com", proxied)
+	}
+}
+
+func TestHTTPTransportErrors(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing.pem")
+	empty := filepath.Join(t.TempDir(), "empty.pem")
+	if err := os.WriteFile(empty, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	for _, cfg := range []config.HTTPConfig{
+		{TLSMinVersion: "1.1"},
+		{CAFile: missing},
+		{CAFile: empty},
+		{ProxyURL: "http://proxy.example.com:port"},
+	} {
+		if _, err := NewHTTPTransport(cfg); err == nil {
+			t.Errorf("NewHTTPTransport(%+v) succeeded, want an error", cfg)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// issueforms.go parses issue bodies created from GitHub issue forms (YAML issue
+// templates), which render each field as a "### Label" heading followed by its value.
+
+package internal
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// issueFormNoResponse is what GitHub renders for an optional field left empty.
+const issueFormNoResponse = "_No response_"
+
+// issueFormCheckbox matches a rendered checkboxes option, e.g. "- [X] I searched existing issues".
+var issueFormCheckbox = regexp.MustCompile(`^[-*]\s+\[([ xX])\]\s+(.*)$`)
+
+// IssueFormField is one field of a submitted issue form.
+type IssueFormField struct {
+	Label   string
+	Value   string   // trimmed text; "" when left empty
+	Checked []string // selected options of a checkboxes field
+}
+
+// IssueForm is a parsed issue form body. Field lookups ignore case.
+type IssueForm struct {
+	Fields []IssueFormField
+}
+
+// ParseIssueForm splits an issue form body into its fields, in order. Bodies that were
+// not created from a form have no fields.
+func ParseIssueForm(body string) IssueForm {
+	var (
+		form    IssueForm
+		current *IssueFormField
+		lines   []string
+		inFence bool
+	)
+	flush := func() {
+		if current == nil {
+			return
+		}
+		current.Value = strings.TrimSpace(strings.Join(lines, "\n"))
+		if current.Value == issueFormNoResponse {
+			current.Value = ""
+		}
+		for _, line := range lines {
+			if m := issueFormCheckbox.FindStringSubmatch(strings.TrimSpace(line)); m != nil && m[1] != " " {
+				current.Checked = append(current.Checked, strings.TrimSpace(m[2]))
+			}
+		}
+		form.Fields = append(form.Fields, *current)
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(body, "\r\n", "\n"), "\n") {
+		// Textarea fields with a render type are wrapped in code fences that may contain "###".
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+		}
+		if label, ok := strings.CutPrefix(line, "### "); ok && !inFence {
+			flush()
+			current, lines = &IssueFormField{Label: strings.TrimSpace(label)}, nil
+			continue
+		}
+		if current != nil {
+			lines = append(lines, line)
+		}
+	}
+	flush()
+	return form
+}
+
+// Field returns the field with the given label.
+func (f IssueForm) Field(label string) (IssueFormField, bool) {
+	for _, field := range f.Fields {
+		if strings.EqualFold(field.Label, label) {
+			return field, true
+		}
+	}
+	return IssueFormField{}, false
+}
+
+// Value returns a field's value, or "" when the field is missing or empty.
+func (f IssueForm) Value(label string) string {
+	field, _ := f.Field(label)
+	return field.Value
+}
+
+// Missing returns the required labels whose fields are absent or empty.
+func (f IssueForm) Missing(required []string) []string {
+	var missing []string
+	for _, label := range required {
+		if f.Value(label) == "" {
+			missing = append(missing, label)
+		}
+	}
+	return missing
+}
+
+// Labels maps dropdown selections to issue labels. rules maps a field label to
+// option -> label; options match case-insensitively. Multi-select dropdowns render
+// their selections comma separated, and each selection is mapped.
+func (f IssueForm) Labels(rules map[string]map[string]string) []string {
+	var labels []string
+	for fieldLabel, options := range rules {
+		value := f.Value(fieldLabel)
+		if value == "" {
+			continue
+		}
+		for _, selected := range strings.Split(value, ",") {
+			for option, label := range options {
+				if strings.EqualFold(strings.TrimSpace(selected), option) && !slices.Contains(labels, label) {
+					labels = append(labels, label)
+				}
+			}
+		}
+	}
+	slices.Sort(labels)
+	return labels
+}
@@ -0,0 +1,67 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"slices"
+	"testing"
+)
+
+const bugReportForm = "### Component(s)\r\n\r\nreceiver/otlp, exporter/debug\r\n\r\n" +
+	"### What happened?\r\n\r\nThe collector crashed.\r\n\r\nSteps:\r\n1. start it\r\n\r\n" +
+	"### Collector version\r\n\r\nv0.98.0\r\n\r\n" +
+	"### Environment\r\n\r\n_No response_\r\n\r\n" +
+	"### Log output\r\n\r\n```shell\r\n### not a heading\r\npanic: nil map\r\n```\r\n\r\n" +
+	"### Tip\r\n\r\n- [X] I have searched existing issues\r\n- [ ] I want to work on this\r\n- [x] I agree to the CoC\r\n"
+
+func TestParseIssueForm(t *testing.T) {
+	form := ParseIssueForm(bugReportForm)
+
+	var labels []string
+	for _, f := range form.Fields {
+		labels = append(labels, f.Label)
+	}
+	wantLabels := []string{"Component(s)", "What happened?", "Collector version", "Environment", "Log output", "Tip"}
+	if !slices.Equal(labels, wantLabels) {
+		t.Fatalf("labels = %q, want %q", labels, wantLabels)
+	}
+
+	tests := []struct {
+		label string
+		want  string
+	}{
+		{"collector version", "v0.98.0"},
+		{"What happened?", "The collector crashed.\n\nSteps:\n1. start it"},
+		{"Environment", ""},
+		{"Log output", "```shell\n### not a heading\npanic: nil map\n```"},
+		{"Not a field", ""},
+	}
+	for _, tt := range tests {
+		if got := form.Value(tt.label); got != tt.want {
+			t.Errorf("Value(%q) = %q, want %q", tt.label, got, tt.want)
+		}
+	}
+
+	tip, _ := form.Field("Tip")
+	if want := []string{"I have searched existing issues", "I agree to the CoC"}; !slices.Equal(tip.Checked, want) {
+		t.Errorf("Checked = %q, want %q", tip.Checked, want)
+	}
+
+	missing := form.Missing([]string{"Collector version", "Environment", "OS"})
+	if !slices.Equal(missing, []string{"Environment", "OS"}) {
+		t.Errorf("Missing = %v", missing)
+	}
+
+	rules := map[string]map[string]string{
+		"Component(s)":      {"receiver/otlp": "receiver/otlp", "exporter/debug": "exporter/debug", "other": "needs-triage"},
+		"Collector version": {"v0.98.0": "release:v0.98"},
+		"Environment":       {"linux": "os:linux"},
+	}
+	if got := form.Labels(rules); !slices.Equal(got, []string{"exporter/debug", "receiver/otlp", "release:v0.98"}) {
+		t.Errorf("Labels = %v", got)
+	}
+
+	if plain := ParseIssueForm("Just a description\nwith no form headings."); len(plain.Fields) != 0 {
+		t.Errorf("plain body parsed as form: %+v", plain.Fields)
+	}
+}
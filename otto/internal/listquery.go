@@ -0,0 +1,346 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// listquery.go is the query layer shared by the admin API's list endpoints, so that the
+// same parameters filter, sort and page every resource, and every list can be fetched as
+// JSON or CSV:
+//
+//	<field>=a,b     rows whose field is a or b
+//	since, until    rows in [since, until): RFC 3339 times, dates, or durations before now such as 24h;
+//	                until is the last day included for daily rows
+//	sort=-field     order by a field, descending with "-"
+//	limit, offset   page (default limit 100, at most 1000)
+//	fields=a,b      fields included, in this order
+//	format=csv      CSV instead of JSON
+
+package internal
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultListLimit = 100
+	maxListLimit     = 1000
+)
+
+// ListField is a field of a listed resource, named as in the resource's JSON form.
+type ListField struct {
+	Name   string
+	Column string // SQL expression the field is filtered and sorted by; empty if it cannot be
+	Hidden bool   // only included when requested with fields, e.g. large payloads
+}
+
+// ListColumns returns fields whose column has the field's name.
+func ListColumns(names ...string) []ListField {
+	fields := make([]ListField, len(names))
+	for i, name := range names {
+		fields[i] = ListField{Name: name, Column: name}
+	}
+	return fields
+}
+
+// ListSchema describes the resource of a list endpoint.
+type ListSchema struct {
+	Fields []ListField
+	Time   string // column since and until apply to; empty if none
+	Dates  bool   // Time holds YYYY-MM-DD dates rather than timestamps
+	Sort   string // default order: a field name, with "-" for descending
+	Key    string // unique columns, comma-separated, that order rows with equal sort values
+}
+
+// field returns a field by name.
+func (s ListSchema) field(name string) (ListField, bool) {
+	i := slices.IndexFunc(s.Fields, func(f ListField) bool { return f.Name == name })
+	if i < 0 {
+		return ListField{}, false
+	}
+	return s.Fields[i], true
+}
+
+// ListQuery is a parsed request to a list endpoint.
+type ListQuery struct {
+	Since, Until  time.Time
+	Limit, Offset int
+	Fields        []string // fields included, in order
+	Format        string   // "json" or "csv"
+
+	schema  ListSchema
+	filters []listFilter
+	sort    string // column
+	desc    bool
+}
+
+type listFilter struct {
+	column string
+	values []string
+}
+
+// ParseListQuery reads the parameters of a list request. Unknown parameters are errors, so
+// a mistyped filter does not silently return every row.
+func ParseListQuery(params url.Values, schema ListSchema) (ListQuery, error) {
+	q := ListQuery{Limit: defaultListLimit, Format: "json", schema: schema}
+	sort := schema.Sort
+	for name, values := range params {
+		value := values[len(values)-1]
+		var err error
+		switch name {
+		case "since", "until":
+			if schema.Time == "" {
+				return q, fmt.Errorf("%s is not supported here", name)
+			}
+			dst := &q.Since
+			if name == "until" {
+				dst = &q.Until
+			}
+			if *dst, err = parseListTime(value); err != nil {
+				return q, fmt.Errorf("invalid %s %q: use RFC 3339, YYYY-MM-DD or a duration such as 24h", name, value)
+			}
+		case "limit":
+			if q.Limit, err = strconv.Atoi(value); err != nil || q.Limit <= 0 || q.Limit > maxListLimit {
+				return q, fmt.Errorf("invalid limit %q: must be between 1 and %d", value, maxListLimit)
+			}
+		case "offset":
+			if q.Offset, err = strconv.Atoi(value); err != nil || q.Offset < 0 {
+				return q, fmt.Errorf("invalid offset %q", value)
+			}
+		case "sort":
+			sort = value
+		case "fields":
+			for _, f := range strings.Split(value, ",") {
+				f = strings.TrimSpace(f)
+				if _, ok := schema.field(f); !ok {
+					return q, fmt.Errorf("unknown field %q", f)
+				}
+				q.Fields = append(q.Fields, f)
+			}
+		case "format":
+			if value != "json" && value != "csv" {
+				return q, fmt.Errorf("invalid format %q: use json or csv", value)
+			}
+			q.Format = value
+		default:
+			f, ok := schema.field(name)
+			if !ok || f.Column == "" {
+				return q, fmt.Errorf("unknown parameter %q", name)
+			}
+			q.filters = append(q.filters, listFilter{column: f.Column, values: strings.Split(value, ",")})
+		}
+	}
+	// Filters are applied in a fixed order, so the same request builds the same statement.
+	slices.SortFunc(q.filters, func(a, b listFilter) int { return strings.Compare(a.column, b.column) })
+
+	if sort != "" {
+		name, desc := strings.CutPrefix(sort, "-")
+		f, ok := schema.field(name)
+		if !ok || f.Column == "" {
+			return q, fmt.Errorf("cannot sort by %q", name)
+		}
+		q.sort, q.desc = f.Column, desc
+	}
+	if len(q.Fields) == 0 {
+		for _, f := range schema.Fields {
+			if !f.Hidden {
+				q.Fields = append(q.Fields, f.Name)
+			}
+		}
+	}
+	return q, nil
+}
+
+// parseListTime parses an RFC 3339 time, a date, or a duration before now.
+func parseListTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return time.Time{}, errors.New("invalid time")
+	}
+	return time.Now().Add(-d), nil
+}
+
+// SQL completes base, a SELECT without WHERE or ORDER BY clauses, with the query's
+// filters, order and page. It asks for one row more than the page, for Page to tell
+// whether another page follows.
+func (q ListQuery) SQL(base string) (string, []any) {
+	var (
+		where []string
+		args  []any
+	)
+	for _, f := range q.filters {
+		where = append(where, f.column+" IN ("+strings.TrimSuffix(strings.Repeat("?, ", len(f.values)), ", ")+")")
+		for _, v := range f.values {
+			args = append(args, v)
+		}
+	}
+	for _, bound := range []struct {
+		op string
+		t  time.Time
+	}{{">=", q.Since}, {"<", q.Until}} {
+		if q.schema.Dates && bound.op == "<" {
+			bound.op = "<="
+		}
+		if bound.t.IsZero() {
+			continue
+		}
+		where = append(where, q.schema.Time+" "+bound.op+" ?")
+		if q.schema.Dates {
+			args = append(args, bound.t.UTC().Format(time.DateOnly))
+		} else {
+			args = append(args, bound.t.UTC())
+		}
+	}
+	query := base
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	direction := " ASC"
+	if q.desc {
+		direction = " DESC"
+	}
+	var order []string
+	if q.sort != "" {
+		order = append(order, q.sort+direction)
+	}
+	for _, key := range strings.Split(q.schema.Key, ",") {
+		if key = strings.TrimSpace(key); key != "" && key != q.sort {
+			order = append(order, key+direction)
+		}
+	}
+	if len(order) > 0 {
+		query += " ORDER BY " + strings.Join(order, ", ")
+	}
+	query += " LIMIT ? OFFSET ?"
+	return query, append(args, q.Limit+1, q.Offset)
+}
+
+// Page trims the extra row fetched by a query built with SQL and reports whether another
+// page follows.
+func Page[T any](rows []T, q ListQuery) ([]T, bool) {
+	if len(rows) > q.Limit {
+		return rows[:q.Limit], true
+	}
+	return rows, false
+}
+
+// ListHandler serves a list endpoint. list returns the rows of a query built with
+// ListQuery.SQL. Paths ending in .csv default to CSV.
+func ListHandler[T any](schema ListSchema, list func(context.Context, ListQuery) ([]T, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q, err := ParseListQuery(r.URL.Query(), schema)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if strings.HasSuffix(r.URL.Path, ".csv") && !r.URL.Query().Has("format") {
+			q.Format = "csv"
+		}
+		rows, err := list(r.Context(), q)
+		if err != nil {
+			slog.Error("Failed to list rows", "path", r.URL.Path, "error", err)
+			http.Error(w, "failed to list "+path.Base(r.URL.Path), http.StatusInternalServerError)
+			return
+		}
+		page, more := Page(rows, q)
+		WriteList(w, q, page, more)
+	}
+}
+
+// WriteList writes rows with the query's fields, as JSON or CSV. When another page follows,
+// the X-Next-Offset header holds its offset.
+func WriteList[T any](w http.ResponseWriter, q ListQuery, rows []T, more bool) {
+	if more {
+		w.Header().Set("X-Next-Offset", strconv.Itoa(q.Offset+len(rows)))
+	}
+	values := make([]map[string]json.RawMessage, 0, len(rows))
+	for _, row := range rows {
+		data, err := json.Marshal(row)
+		var v map[string]json.RawMessage
+		if err == nil {
+			err = json.Unmarshal(data, &v)
+		}
+		if err != nil {
+			slog.Error("Failed to encode list row", "error", err)
+			http.Error(w, "failed to encode rows", http.StatusInternalServerError)
+			return
+		}
+		values = append(values, v)
+	}
+	if q.Format == "csv" {
+		writeListCSV(w, q.Fields, values)
+		return
+	}
+
+	// Objects are written field by field to keep the requested order.
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	for i, v := range values {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteByte('{')
+		first := true
+		for _, f := range q.Fields {
+			raw, ok := v[f]
+			if !ok {
+				continue // omitted when empty
+			}
+			if !first {
+				buf.WriteByte(',')
+			}
+			first = false
+			name, _ := json.Marshal(f)
+			buf.Write(name)
+			buf.WriteByte(':')
+			buf.Write(raw)
+		}
+		buf.WriteByte('}')
+	}
+	buf.WriteString("]\n")
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		slog.Error("Failed to write JSON response", "error", err)
+	}
+}
+
+// writeListCSV writes rows as CSV with a header line. Strings are written as they are,
+// missing and null values as empty cells, and other values as JSON.
+func writeListCSV(w http.ResponseWriter, fields []string, rows []map[string]json.RawMessage) {
+	w.Header().Set("Content-Type", "text/csv")
+	cw := csv.NewWriter(w)
+	records := [][]string{fields}
+	for _, row := range rows {
+		record := make([]string, len(fields))
+		for i, f := range fields {
+			raw := row[f]
+			var s string
+			switch {
+			case len(raw) == 0 || string(raw) == "null":
+			case json.Unmarshal(raw, &s) == nil:
+				record[i] = s
+			default:
+				record[i] = string(raw)
+			}
+		}
+		records = append(records, record)
+	}
+	if err := cw.WriteAll(records); err != nil {
+		slog.Error("Failed to write CSV response", "error", err)
+	}
+}
@@ -0,0 +1,112 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseListQuery(t *testing.T) {
+	tests := []struct {
+		query      string
+		wantSQL    string
+		wantArgs   []any
+		wantFields []string
+	}{
+		{"", `SELECT * FROM events ORDER BY received_at DESC, id DESC LIMIT ? OFFSET ?`,
+			[]any{101, 0}, []string{"id", "delivery_id", "type", "action", "repo", "sender", "received_at"}},
+		{"type=issues,push&repo=org/a&sort=id&limit=10&offset=20&fields=repo,payload",
+			`SELECT * FROM events WHERE event_type IN (?, ?) AND repo IN (?) ORDER BY id ASC LIMIT ? OFFSET ?`,
+			[]any{"issues", "push", "org/a", 11, 20}, []string{"repo", "payload"}},
+		{"since=2025-05-01&until=2025-05-02T12:00:00Z",
+			`SELECT * FROM events WHERE received_at >= ? AND received_at < ? ` +
+				`ORDER BY received_at DESC, id DESC LIMIT ? OFFSET ?`,
+			[]any{time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 5, 2, 12, 0, 0, 0, time.UTC), 101, 0},
+			nil},
+	}
+	for _, tt := range tests {
+		params, _ := url.ParseQuery(tt.query)
+		q, err := ParseListQuery(params, eventListSchema)
+		if err != nil {
+			t.Fatalf("ParseListQuery(%q) failed: %v", tt.query, err)
+		}
+		query, args := q.SQL(`SELECT * FROM events`)
+		if query != tt.wantSQL || !reflect.DeepEqual(args, tt.wantArgs) {
+			t.Errorf("%q: SQL = %s %v, want %s %v", tt.query, query, args, tt.wantSQL, tt.wantArgs)
+		}
+		if tt.wantFields != nil && !reflect.DeepEqual(q.Fields, tt.wantFields) {
+			t.Errorf("%q: fields = %v, want %v", tt.query, q.Fields, tt.wantFields)
+		}
+	}
+
+	// Daily rows include the day of until.
+	params, _ := url.ParseQuery("until=2025-01-31")
+	q, err := ParseListQuery(params, rollupListSchema)
+	if err != nil {
+		t.Fatalf("ParseListQuery failed: %v", err)
+	}
+	if query, args := q.SQL(`SELECT * FROM metric_rollups`); query !=
+		`SELECT * FROM metric_rollups WHERE day <= ? ORDER BY day ASC, metric ASC, key ASC LIMIT ? OFFSET ?` ||
+		args[0] != "2025-01-31" {
+		t.Errorf("SQL = %s %v", query, args)
+	}
+
+	for _, query := range []string{
+		"colour=red", "payload=x", "sort=payload", "fields=id,colour", "limit=0", "limit=5000", "offset=-1",
+		"format=xml", "since=yesterday",
+	} {
+		params, _ := url.ParseQuery(query)
+		if _, err := ParseListQuery(params, eventListSchema); err == nil {
+			t.Errorf("ParseListQuery(%q) succeeded, want error", query)
+		}
+	}
+}
+
+func TestListHandlerEvents(t *testing.T) {
+	store, err := NewEventStore(TestDB(t))
+	if err != nil {
+		t.Fatalf("NewEventStore failed: %v", err)
+	}
+	base := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	for i, body := range []string{
+		`{"action":"opened","repository":{"full_name":"org/a"},"sender":{"login":"alice"}}`,
+		`{"action":"closed","repository":{"full_name":"org/a"},"sender":{"login":"bob, jr"}}`,
+		`{"action":"opened","repository":{"full_name":"org/b"},"sender":{"login":"carol"}}`,
+	} {
+		e := NewStoredEvent("d", "issues", []byte(body))
+		e.ReceivedAt = base.Add(time.Duration(i) * time.Hour)
+		if _, err := store.Record(t.Context(), e); err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+	}
+	list := ListHandler(eventListSchema, store.Select)
+
+	tests := []struct {
+		path, want, nextOffset string
+		status                 int
+	}{
+		{"/admin/events?fields=sender,id&limit=2", `[{"sender":"carol","id":3},{"sender":"bob, jr","id":2}]` + "\n",
+			"2", http.StatusOK},
+		{"/admin/events?fields=sender,id&limit=2&offset=2", `[{"sender":"alice","id":1}]` + "\n", "", http.StatusOK},
+		{"/admin/events?action=closed&fields=id,payload", `[{"id":2,"payload":{"action":"closed",` +
+			`"repository":{"full_name":"org/a"},"sender":{"login":"bob, jr"}}}]` + "\n", "", http.StatusOK},
+		{"/admin/events?repo=org/a&sort=id&fields=id,sender,received_at&format=csv",
+			"id,sender,received_at\n1,alice,2025-05-01T12:00:00Z\n2,\"bob, jr\",2025-05-01T13:00:00Z\n", "",
+			http.StatusOK},
+		{"/admin/events?repo=org/c", "[]\n", "", http.StatusOK},
+		{"/admin/events?status=done", "unknown parameter \"status\"\n", "", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		rr := httptest.NewRecorder()
+		list(rr, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if rr.Code != tt.status || rr.Body.String() != tt.want || rr.Header().Get("X-Next-Offset") != tt.nextOffset {
+			t.Errorf("%s: %d %q (next %q), want %d %q (next %q)", tt.path, rr.Code, rr.Body.String(),
+				rr.Header().Get("X-Next-Offset"), tt.status, tt.want, tt.nextOffset)
+		}
+	}
+}
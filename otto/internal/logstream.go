@@ -0,0 +1,362 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// logstream.go keeps Otto's recent log records in a ring buffer and streams them on the
+// admin API as server-sent events, so operators can tail the logs during incidents
+// without access to the container runtime.
+
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// logStreamKeepAlive is how often an idle stream sends a comment to keep proxies from
+// closing it.
+const logStreamKeepAlive = 15 * time.Second
+
+// LogEntry is a log record kept by the LogBuffer.
+type LogEntry struct {
+	Seq     uint64         `json:"seq"`
+	Time    time.Time      `json:"time"`
+	Level   string         `json:"level"`
+	Module  string         `json:"module,omitempty"`
+	Message string         `json:"msg"`
+	Attrs   map[string]any `json:"attrs,omitempty"`
+}
+
+// LogFilter selects log entries by minimum level and module. Zero values match everything.
+type LogFilter struct {
+	Level  slog.Level
+	Module string
+}
+
+func (f LogFilter) match(e LogEntry, level slog.Level) bool {
+	return level >= f.Level && (f.Module == "" || e.Module == f.Module)
+}
+
+// logSubscriber receives new entries; entries it is too slow for are counted and dropped.
+type logSubscriber struct {
+	ch      chan LogEntry
+	dropped int
+}
+
+// LogBuffer is a ring buffer of the most recent log records with live subscribers.
+type LogBuffer struct {
+	level slog.Level
+
+	mu          sync.Mutex
+	entries     []LogEntry // ring of cap(entries) entries, oldest at next once full
+	levels      []slog.Level
+	next        int
+	seq         uint64
+	subscribers map[*logSubscriber]struct{}
+	closed      chan struct{}
+}
+
+// NewLogBuffer creates a buffer that keeps the last size records at or above level.
+func NewLogBuffer(size int, level slog.Level) *LogBuffer {
+	return &LogBuffer{
+		level:       level,
+		entries:     make([]LogEntry, 0, max(size, 1)),
+		levels:      make([]slog.Level, 0, max(size, 1)),
+		subscribers: make(map[*logSubscriber]struct{}),
+		closed:      make(chan struct{}),
+	}
+}
+
+// Handler returns a slog handler that records into the buffer and passes every record on
+// to next.
+func (b *LogBuffer) Handler(next slog.Handler) slog.Handler {
+	return &logBufferHandler{buf: b, next: next}
+}
+
+// add appends an entry, overwriting the oldest once the buffer is full, and hands it to
+// the subscribers.
+func (b *LogBuffer) add(e LogEntry, level slog.Level) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.seq++
+	e.Seq = b.seq
+	if len(b.entries) < cap(b.entries) {
+		b.entries = append(b.entries, e)
+		b.levels = append(b.levels, level)
+	} else {
+		b.entries[b.next], b.levels[b.next] = e, level
+		b.next = (b.next + 1) % len(b.entries)
+	}
+	for sub := range b.subscribers {
+		select {
+		case sub.ch <- e:
+		default:
+			sub.dropped++
+		}
+	}
+}
+
+// Recent returns the buffered entries matching f that come after the entry with sequence
+// number after, oldest first, at most limit of the newest of them if limit is positive.
+func (b *LogBuffer) Recent(f LogFilter, after uint64, limit int) []LogEntry {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.recentLocked(f, after, limit)
+}
+
+func (b *LogBuffer) recentLocked(f LogFilter, after uint64, limit int) []LogEntry {
+	out := []LogEntry{}
+	for i := range b.entries {
+		j := (b.next + i) % len(b.entries)
+		if e := b.entries[j]; e.Seq > after && f.match(e, b.levels[j]) {
+			out = append(out, e)
+		}
+	}
+	if limit > 0 && len(out) > limit {
+		out = out[len(out)-limit:]
+	}
+	return out
+}
+
+// subscribe returns the buffered entries matching f after the given sequence number and
+// a subscription to new entries, atomically so no entry is missed or sent twice.
+func (b *LogBuffer) subscribe(f LogFilter, after uint64, limit int) ([]LogEntry, *logSubscriber) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	sub := &logSubscriber{ch: make(chan LogEntry, 256)}
+	b.subscribers[sub] = struct{}{}
+	return b.recentLocked(f, after, limit), sub
+}
+
+// unsubscribe ends a subscription and returns how many entries it dropped.
+func (b *LogBuffer) unsubscribe(sub *logSubscriber) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.subscribers, sub)
+	return sub.dropped
+}
+
+// Close ends all streams, so they do not hold up server shutdown. A nil buffer is ignored.
+func (b *LogBuffer) Close() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	select {
+	case <-b.closed:
+	default:
+		close(b.closed)
+	}
+}
+
+// logBufferHandler is the slog handler of a LogBuffer.
+type logBufferHandler struct {
+	buf    *LogBuffer
+	next   slog.Handler
+	attrs  []slog.Attr
+	groups []string
+}
+
+func (h *logBufferHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.buf.level || h.next.Enabled(ctx, level)
+}
+
+func (h *logBufferHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level >= h.buf.level {
+		e := LogEntry{Time: r.Time, Level: r.Level.String(), Message: r.Message, Attrs: map[string]any{}}
+		prefix := strings.Join(h.groups, ".")
+		for _, a := range h.attrs {
+			addLogAttr(e.Attrs, "", a)
+		}
+		r.Attrs(func(a slog.Attr) bool {
+			addLogAttr(e.Attrs, prefix, a)
+			return true
+		})
+		if m, ok := e.Attrs["module"].(string); ok {
+			e.Module = m
+		}
+		h.buf.add(e, r.Level)
+	}
+	if h.next.Enabled(ctx, r.Level) {
+		return h.next.Handle(ctx, r)
+	}
+	return nil
+}
+
+func (h *logBufferHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	prefix := strings.Join(h.groups, ".")
+	grouped := make([]slog.Attr, 0, len(h.attrs)+len(attrs))
+	grouped = append(grouped, h.attrs...)
+	for _, a := range attrs {
+		if prefix != "" {
+			a.Key = prefix + "." + a.Key
+		}
+		grouped = append(grouped, a)
+	}
+	return &logBufferHandler{buf: h.buf, next: h.next.WithAttrs(attrs), attrs: grouped, groups: h.groups}
+}
+
+func (h *logBufferHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	groups := append(append([]string{}, h.groups...), name)
+	return &logBufferHandler{buf: h.buf, next: h.next.WithGroup(name), attrs: h.attrs, groups: groups}
+}
+
+// addLogAttr flattens an attribute into attrs, joining group names with dots.
+func addLogAttr(attrs map[string]any, prefix string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	key := a.Key
+	if prefix != "" {
+		key = prefix + "." + key
+	}
+	switch a.Value.Kind() {
+	case slog.KindGroup:
+		if a.Key == "" {
+			key = prefix
+		}
+		for _, ga := range a.Value.Group() {
+			addLogAttr(attrs, key, ga)
+		}
+	case slog.KindAny:
+		if err, ok := a.Value.Any().(error); ok {
+			attrs[key] = err.Error()
+		} else {
+			attrs[key] = fmt.Sprint(a.Value.Any())
+		}
+	default:
+		if a.Key != "" {
+			attrs[key] = a.Value.Any()
+		}
+	}
+}
+
+// RegisterAdminRoutes serves the buffer on the admin API. A nil buffer serves nothing.
+func (b *LogBuffer) RegisterAdminRoutes(srv *Server) {
+	if b == nil || srv == nil {
+		return
+	}
+	srv.HandleAdmin("GET /admin/logs", b.handleList)
+	srv.HandleAdmin("GET /admin/logs/stream", b.handleStream)
+}
+
+// parseLogQuery reads the level, module and limit query parameters.
+func parseLogQuery(r *http.Request) (LogFilter, int, error) {
+	params := r.URL.Query()
+	var f LogFilter
+	if s := params.Get("level"); s != "" {
+		if err := f.Level.UnmarshalText([]byte(s)); err != nil {
+			return f, 0, fmt.Errorf("level must be debug, info, warn or error")
+		}
+	} else {
+		f.Level = slog.LevelDebug
+	}
+	f.Module = params.Get("module")
+	limit := 100
+	if s := params.Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			return f, 0, fmt.Errorf("limit must be a number")
+		}
+		limit = n
+	}
+	return f, limit, nil
+}
+
+// handleList returns the buffered entries matching the level and module query parameters,
+// the newest limit (default 100) of them.
+func (b *LogBuffer) handleList(w http.ResponseWriter, r *http.Request) {
+	f, limit, err := parseLogQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(b.Recent(f, 0, limit)); err != nil {
+		slog.Error("Failed to write JSON response", "error", err)
+	}
+}
+
+// handleStream streams entries matching the level and module query parameters as
+// server-sent events, starting with the newest limit (default 100) buffered ones. A client
+// reconnecting with Last-Event-ID resumes after the last entry it received.
+func (b *LogBuffer) handleStream(w http.ResponseWriter, r *http.Request) {
+	f, limit, err := parseLogQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+	var after uint64
+	if id := r.Header.Get("Last-Event-ID"); id != "" {
+		after, _ = strconv.ParseUint(id, 10, 64)
+		limit = 0
+	}
+
+	backlog, sub := b.subscribe(f, after, limit)
+	defer func() {
+		if dropped := b.unsubscribe(sub); dropped > 0 {
+			slog.Warn("Log stream fell behind and skipped entries", "dropped", dropped)
+		}
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	send := func(e LogEntry) bool {
+		data, err := json.Marshal(e)
+		if err != nil {
+			return true
+		}
+		_, err = fmt.Fprintf(w, "id: %d\nevent: log\ndata: %s\n\n", e.Seq, data)
+		return err == nil
+	}
+	for _, e := range backlog {
+		if !send(e) {
+			return
+		}
+	}
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(logStreamKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-b.closed:
+			return
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		case e := <-sub.ch:
+			if e.Seq <= after || !f.match(e, logLevel(e.Level)) {
+				continue
+			}
+			if !send(e) {
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
+
+// logLevel parses the level of an entry.
+func logLevel(s string) slog.Level {
+	var level slog.Level
+	_ = level.UnmarshalText([]byte(s))
+	return level
+}
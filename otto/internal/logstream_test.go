@@ -0,0 +1,155 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogBufferHandler(t *testing.T) {
+	var next strings.Builder
+	buf := NewLogBuffer(3, slog.LevelInfo)
+	logger := slog.New(buf.Handler(slog.NewTextHandler(&next, &slog.HandlerOptions{Level: slog.LevelWarn})))
+
+	logger.Debug("too verbose")
+	logger.Info("first", "module", "triage")
+	logger.With("module", "sla").WithGroup("req").Warn("second", "id", 7, "err", errors.New("boom"))
+	logger.Error("third", slog.Group("repo", "owner", "org", "name", "a"))
+	logger.Info("fourth", "module", "triage")
+
+	entries := buf.Recent(LogFilter{Level: slog.LevelDebug}, 0, 0)
+	if len(entries) != 3 || entries[0].Message != "second" || entries[2].Message != "fourth" {
+		t.Fatalf("entries = %+v, want the last three", entries)
+	}
+	second := entries[0]
+	if second.Seq != 2 || second.Module != "sla" || second.Level != "WARN" ||
+		second.Attrs["req.id"] != int64(7) || second.Attrs["req.err"] != "boom" {
+		t.Errorf("second = %+v", second)
+	}
+	if entries[1].Attrs["repo.owner"] != "org" || entries[1].Attrs["repo.name"] != "a" {
+		t.Errorf("third attrs = %v", entries[1].Attrs)
+	}
+	// The next handler keeps its own level.
+	if got := next.String(); strings.Contains(got, "first") || !strings.Contains(got, "second") {
+		t.Errorf("next handler got %q", got)
+	}
+
+	tests := []struct {
+		name   string
+		filter LogFilter
+		after  uint64
+		limit  int
+		want   []string
+	}{
+		{"level", LogFilter{Level: slog.LevelWarn}, 0, 0, []string{"second", "third"}},
+		{"module", LogFilter{Module: "triage"}, 0, 0, []string{"fourth"}},
+		{"after", LogFilter{}, 2, 0, []string{"third", "fourth"}},
+		{"limit", LogFilter{}, 0, 1, []string{"fourth"}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, e := range buf.Recent(tt.filter, tt.after, tt.limit) {
+			got = append(got, e.Message)
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLogBufferAdminList(t *testing.T) {
+	buf := NewLogBuffer(10, slog.LevelDebug)
+	logger := slog.New(buf.Handler(slog.DiscardHandler))
+	logger.Info("started")
+	logger.Warn("slow handler", "module", "triage")
+	logger.Debug("details", "module", "triage")
+
+	for query, want := range map[string]int{"": 3, "?level=warn": 1, "?module=triage": 2, "?limit=1": 1} {
+		rr := httptest.NewRecorder()
+		buf.handleList(rr, httptest.NewRequest(http.MethodGet, "/admin/logs"+query, nil))
+		var entries []LogEntry
+		if err := json.NewDecoder(rr.Body).Decode(&entries); err != nil || len(entries) != want {
+			t.Errorf("%q: got %d entries (%v), want %d", query, len(entries), err, want)
+		}
+	}
+	rr := httptest.NewRecorder()
+	buf.handleList(rr, httptest.NewRequest(http.MethodGet, "/admin/logs?level=verbose", nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("invalid level: status = %d, want 400", rr.Code)
+	}
+}
+
+func TestLogBufferStream(t *testing.T) {
+	buf := NewLogBuffer(10, slog.LevelDebug)
+	logger := slog.New(buf.Handler(slog.DiscardHandler))
+	logger.Info("before", "module", "triage")
+	logger.Info("skipped", "module", "sla")
+
+	srv := httptest.NewServer(http.HandlerFunc(buf.handleStream))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(t.Context(), 5*time.Second)
+	defer cancel()
+
+	// read returns the data of the next n events.
+	read := func(scanner *bufio.Scanner, n int) []LogEntry {
+		var entries []LogEntry
+		for len(entries) < n && scanner.Scan() {
+			if data, ok := strings.CutPrefix(scanner.Text(), "data: "); ok {
+				var e LogEntry
+				if err := json.Unmarshal([]byte(data), &e); err != nil {
+					t.Fatalf("invalid event data %q: %v", data, err)
+				}
+				entries = append(entries, e)
+			}
+		}
+		return entries
+	}
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"?module=triage", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("stream request failed: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+	scanner := bufio.NewScanner(resp.Body)
+	if got := read(scanner, 1); len(got) != 1 || got[0].Message != "before" {
+		t.Fatalf("backlog = %+v", got)
+	}
+	logger.Info("other module", "module", "sla")
+	logger.Warn("live", "module", "triage")
+	if got := read(scanner, 1); len(got) != 1 || got[0].Message != "live" || got[0].Seq != 4 {
+		t.Fatalf("live entries = %+v", got)
+	}
+
+	// A reconnecting client resumes after the last entry it received.
+	req, _ = http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	req.Header.Set("Last-Event-ID", "3")
+	resumed, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("resumed request failed: %v", err)
+	}
+	defer func() { _ = resumed.Body.Close() }()
+	if got := read(bufio.NewScanner(resumed.Body), 1); len(got) != 1 || got[0].Seq != 4 {
+		t.Fatalf("resumed entries = %+v", got)
+	}
+
+	// Closing the buffer ends the streams.
+	buf.Close()
+	for scanner.Scan() {
+	}
+	if err := scanner.Err(); err != nil {
+		t.Errorf("stream did not end cleanly: %v", err)
+	}
+}
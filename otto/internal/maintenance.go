@@ -0,0 +1,75 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// maintenance.go keeps the SQLite database healthy with periodic integrity checks,
+// VACUUM and ANALYZE.
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// DBMaintenanceJobName is the scheduler name of the database maintenance job.
+const DBMaintenanceJobName = "db_maintenance"
+
+// errDBCorrupt is returned when the integrity check reports problems.
+var errDBCorrupt = errors.New("database integrity check failed")
+
+// NewDBMaintenanceJob returns a scheduler job that checks database integrity and,
+// if the database is healthy, optionally vacuums and analyzes it.
+func NewDBMaintenanceJob(db *Database, telemetry *TelemetryManager, cfg DBMaintenanceOptions) Job {
+	return Job{
+		Name:     DBMaintenanceJobName,
+		Interval: cfg.Interval,
+		Run: func(ctx context.Context) error {
+			return runDBMaintenance(ctx, db, telemetry, cfg)
+		},
+	}
+}
+
+// DBMaintenanceOptions controls which maintenance steps run.
+type DBMaintenanceOptions struct {
+	Interval time.Duration
+	Vacuum   bool
+	Analyze  bool
+}
+
+// runDBMaintenance performs a single maintenance pass.
+func runDBMaintenance(
+	ctx context.Context,
+	db *Database,
+	telemetry *TelemetryManager,
+	cfg DBMaintenanceOptions,
+) error {
+	problems, err := db.IntegrityCheck(ctx)
+	if err != nil {
+		return LogAndWrapError(err, ErrorTypeDatabase, "integrity_check", nil)
+	}
+	if len(problems) > 0 {
+		if telemetry != nil {
+			telemetry.IncDBIntegrityFailure(ctx)
+		}
+		// Skip VACUUM on a corrupt database; it can make recovery harder.
+		return LogAndWrapError(errDBCorrupt, ErrorTypeDatabase, "integrity_check", map[string]any{
+			"problems": strings.Join(problems, "; "),
+		})
+	}
+
+	if cfg.Vacuum {
+		if err := db.Vacuum(ctx); err != nil {
+			return LogAndWrapError(err, ErrorTypeDatabase, "vacuum", nil)
+		}
+	}
+	if cfg.Analyze {
+		if err := db.Analyze(ctx); err != nil {
+			return LogAndWrapError(err, ErrorTypeDatabase, "analyze", nil)
+		}
+	}
+
+	slog.Info("database maintenance completed", "vacuum", cfg.Vacuum, "analyze", cfg.Analyze)
+	return nil
+}
@@ -0,0 +1,59 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"testing"
+	"time"
+
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestDBMaintenanceJob(t *testing.T) {
+	database := &Database{db: TestDB(t)}
+	defer database.Close()
+
+	if _, err := database.DB().Exec(`CREATE TABLE items (id INTEGER PRIMARY KEY, name TEXT)`); err != nil {
+		t.Fatalf("failed to create table: %v", err)
+	}
+
+	reader := sdkmetric.NewManualReader()
+	job := NewDBMaintenanceJob(database, TestTelemetry(t, reader), DBMaintenanceOptions{
+		Interval: time.Hour,
+		Vacuum:   true,
+		Analyze:  true,
+	})
+
+	if job.Name != DBMaintenanceJobName {
+		t.Errorf("unexpected job name %q", job.Name)
+	}
+	if err := job.Run(t.Context()); err != nil {
+		t.Fatalf("maintenance failed on healthy database: %v", err)
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(t.Context(), &rm); err != nil {
+		t.Fatalf("failed to collect metrics: %v", err)
+	}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name == "otto.db.integrity_failures_total" {
+				t.Errorf("integrity failure recorded for healthy database")
+			}
+		}
+	}
+}
+
+func TestIntegrityCheckHealthy(t *testing.T) {
+	database := &Database{db: TestDB(t)}
+	defer database.Close()
+
+	problems, err := database.IntegrityCheck(t.Context())
+	if err != nil {
+		t.Fatalf("IntegrityCheck failed: %v", err)
+	}
+	if len(problems) != 0 {
+		t.Errorf("expected no problems, got %v", problems)
+	}
+}
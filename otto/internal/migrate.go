@@ -0,0 +1,266 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// migrate.go plans and applies the schema migrations of Otto's stores and modules. Stores
+// migrate their tables when they are created, with statements such as CREATE TABLE IF NOT
+// EXISTS that do nothing once applied. A migration plan runs them against an in-memory copy
+// of the schema, so the statements that would change it and the resulting schema diff can
+// be shown, and startup refused while any are pending, without touching the database.
+// Changes that cannot be written that way are versioned migrations (schema_migrations.go).
+
+package internal
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/mattn/go-sqlite3"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+)
+
+// ErrMigrationsPending is returned at startup when migrations.refuse_pending is set and
+// the database is not fully migrated.
+var ErrMigrationsPending = errors.New("database has pending migrations; run 'otto db migrate'")
+
+// Migration creates or updates the tables of one store or module.
+type Migration struct {
+	Name    string
+	Migrate func(db *sql.DB) error
+}
+
+// ModuleMigrator is implemented by modules with tables of their own, so that their
+// migrations can be planned and applied without initializing the module.
+type ModuleMigrator interface {
+	Migrate(db *sql.DB) error
+}
+
+// CoreMigrations returns the migrations of Otto's own stores, including those of optional
+// features that are turned off.
+func CoreMigrations() []Migration {
+	return []Migration{
+		{"events", func(db *sql.DB) error { _, err := NewEventStore(db); return err }},
+		{"repos", func(db *sql.DB) error { _, err := NewRepoRegistry(db); return err }},
+		{"commands", func(db *sql.DB) error { _, err := NewCommandHistory(db); return err }},
+		{"confirmations", func(db *sql.DB) error { _, err := NewConfirmations(db); return err }},
+		{"feature_flags", func(db *sql.DB) error { _, err := NewFlagStore(db); return err }},
+		{"quiet_hours", func(db *sql.DB) error { _, err := NewQuietHours(config.QuietHoursConfig{}, db); return err }},
+		{"rollups", func(db *sql.DB) error { _, err := NewMetricRollups(db, nil, 0); return err }},
+		{"outbox", func(db *sql.DB) error { _, err := NewOutbox(db, nil, 0); return err }},
+		{"decisions", func(db *sql.DB) error { _, err := NewDecisionLog(db); return err }},
+		{"shadow", func(db *sql.DB) error { _, err := NewShadowLog(db, nil); return err }},
+		{"github_api_usage", func(db *sql.DB) error { _, err := NewAPIUsage(db); return err }},
+	}
+}
+
+// ModuleMigrations returns the migrations of the modules with tables, in order of name.
+func ModuleMigrations(modules []Module) []Migration {
+	modules = slices.Clone(modules)
+	slices.SortFunc(modules, func(a, b Module) int { return strings.Compare(a.Name(), b.Name()) })
+	var migrations []Migration
+	for _, m := range modules {
+		if migrator, ok := m.(ModuleMigrator); ok {
+			migrations = append(migrations, Migration{Name: m.Name(), Migrate: migrator.Migrate})
+		}
+	}
+	return migrations
+}
+
+// ApplyMigrations runs migrations against db.
+func ApplyMigrations(db *sql.DB, migrations []Migration) error {
+	for _, m := range migrations {
+		if err := m.Migrate(db); err != nil {
+			return fmt.Errorf("%s: %w", m.Name, err)
+		}
+	}
+	return nil
+}
+
+// MigrationPlan is what applying migrations would change in a database.
+type MigrationPlan struct {
+	Statements []PlannedStatement // in the order they would run
+	Changes    []SchemaChange
+}
+
+// PlannedStatement is a statement that would change the schema.
+type PlannedStatement struct {
+	Migration string
+	SQL       string
+}
+
+// SchemaChange is a table, index, view or trigger that would be created or changed.
+type SchemaChange struct {
+	Type   string // "table", "index", "view" or "trigger"
+	Name   string
+	Before string // CREATE statement; empty for new objects
+	After  string
+}
+
+// Pending reports whether applying the migrations would change anything.
+func (p *MigrationPlan) Pending() bool {
+	return len(p.Statements) > 0
+}
+
+// schemaObject is a row of sqlite_master.
+type schemaObject struct {
+	Type, Name, SQL string
+}
+
+// PlanMigrations runs migrations against an in-memory copy of db's schema and returns the
+// statements that changed it and how. db is not modified.
+func PlanMigrations(ctx context.Context, db *sql.DB, migrations []Migration) (*MigrationPlan, error) {
+	before, err := schemaObjects(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	planner := &migrationPlanner{plan: &MigrationPlan{}}
+	scratch := sql.OpenDB(planner)
+	// Every connection to an in-memory database is a separate database.
+	scratch.SetMaxOpenConns(1)
+	defer scratch.Close()
+
+	for _, o := range before {
+		if _, err := scratch.ExecContext(ctx, o.SQL); err != nil {
+			return nil, fmt.Errorf("failed to copy schema of %s %s: %w", o.Type, o.Name, err)
+		}
+	}
+	versioned := func(o schemaObject) bool { return o.Type == "table" && o.Name == "schema_migrations" }
+	if slices.ContainsFunc(before, versioned) {
+		if err := copyAppliedMigrations(ctx, db, scratch); err != nil {
+			return nil, err
+		}
+	}
+	for _, m := range migrations {
+		planner.migration = m.Name
+		if err := m.Migrate(scratch); err != nil {
+			return nil, fmt.Errorf("%s: %w", m.Name, err)
+		}
+	}
+	planner.migration = ""
+
+	after, err := schemaObjects(ctx, scratch)
+	if err != nil {
+		return nil, err
+	}
+	plan := planner.plan
+	for _, o := range after {
+		i := slices.IndexFunc(before, func(b schemaObject) bool { return b.Type == o.Type && b.Name == o.Name })
+		switch {
+		case i < 0:
+			plan.Changes = append(plan.Changes, SchemaChange{Type: o.Type, Name: o.Name, After: o.SQL})
+		case before[i].SQL != o.SQL:
+			plan.Changes = append(plan.Changes,
+				SchemaChange{Type: o.Type, Name: o.Name, Before: before[i].SQL, After: o.SQL})
+		}
+	}
+	return plan, nil
+}
+
+// schemaObjects returns the tables, indexes, views and triggers of a database, tables
+// first, leaving out SQLite's internal tables and the indexes of constraints.
+func schemaObjects(ctx context.Context, db *sql.DB) ([]schemaObject, error) {
+	rows, err := db.QueryContext(ctx, `SELECT type, name, sql FROM sqlite_master
+		WHERE sql IS NOT NULL AND name NOT LIKE 'sqlite_%' ORDER BY type != 'table', rowid`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema: %w", err)
+	}
+	defer rows.Close()
+	var objects []schemaObject
+	for rows.Next() {
+		var o schemaObject
+		if err := rows.Scan(&o.Type, &o.Name, &o.SQL); err != nil {
+			return nil, err
+		}
+		objects = append(objects, o)
+	}
+	return objects, rows.Err()
+}
+
+// migrationPlanner opens the in-memory database a plan is made on and records the
+// statements run by migrations that change its schema.
+type migrationPlanner struct {
+	plan      *MigrationPlan
+	migration string // running; empty while the schema is copied
+}
+
+func (p *migrationPlanner) Connect(context.Context) (driver.Conn, error) {
+	conn, err := p.Driver().Open(":memory:")
+	if err != nil {
+		return nil, err
+	}
+	sc, ok := conn.(*sqlite3.SQLiteConn)
+	if !ok {
+		conn.Close()
+		return nil, fmt.Errorf("unexpected SQLite connection type %T", conn)
+	}
+	return &planningConn{SQLiteConn: sc, planner: p}, nil
+}
+
+func (p *migrationPlanner) Driver() driver.Driver {
+	return &sqlite3.SQLiteDriver{}
+}
+
+// planningConn is a connection whose statements are recorded if they change the schema,
+// which SQLite counts in PRAGMA schema_version.
+type planningConn struct {
+	*sqlite3.SQLiteConn
+	planner *migrationPlanner
+}
+
+func (c *planningConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	before, err := c.schemaVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.SQLiteConn.ExecContext(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+	after, err := c.schemaVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if after != before && c.planner.migration != "" {
+		c.planner.plan.Statements = append(c.planner.plan.Statements,
+			PlannedStatement{Migration: c.planner.migration, SQL: strings.TrimSpace(query)})
+	}
+	return res, nil
+}
+
+func (c *planningConn) schemaVersion(ctx context.Context) (int64, error) {
+	rows, err := c.SQLiteConn.QueryContext(ctx, "PRAGMA schema_version", nil)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+	dest := make([]driver.Value, 1)
+	if err := rows.Next(dest); err != nil {
+		return 0, err
+	}
+	version, _ := dest[0].(int64)
+	return version, nil
+}
+
+// checkMigrations returns ErrMigrationsPending, listing the pending migrations, if applying
+// migrations would change the database.
+func checkMigrations(ctx context.Context, db *sql.DB, migrations []Migration) error {
+	plan, err := PlanMigrations(ctx, db, migrations)
+	if err != nil {
+		return fmt.Errorf("failed to plan migrations: %w", err)
+	}
+	if !plan.Pending() {
+		return nil
+	}
+	var names []string
+	for _, s := range plan.Statements {
+		if !slices.Contains(names, s.Migration) {
+			names = append(names, s.Migration)
+		}
+	}
+	return fmt.Errorf("%w (%d statements of %s)", ErrMigrationsPending, len(plan.Statements),
+		strings.Join(names, ", "))
+}
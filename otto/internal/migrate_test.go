@@ -0,0 +1,86 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestPlanMigrations(t *testing.T) {
+	ctx := t.Context()
+	db := TestDB(t)
+
+	plan, err := PlanMigrations(ctx, db, CoreMigrations())
+	if err != nil {
+		t.Fatalf("PlanMigrations failed: %v", err)
+	}
+	if !plan.Pending() || plan.Statements[0].Migration != "events" ||
+		!strings.HasPrefix(plan.Statements[0].SQL, "CREATE TABLE IF NOT EXISTS events") {
+		t.Fatalf("plan for an empty database = %+v", plan.Statements)
+	}
+	if objects, err := schemaObjects(ctx, db); err != nil || len(objects) != 0 {
+		t.Fatalf("planning changed the database: %v, %v", objects, err)
+	}
+	if err := checkMigrations(ctx, db, CoreMigrations()); !errors.Is(err, ErrMigrationsPending) {
+		t.Errorf("checkMigrations = %v, want ErrMigrationsPending", err)
+	}
+
+	if err := ApplyMigrations(db, CoreMigrations()); err != nil {
+		t.Fatalf("ApplyMigrations failed: %v", err)
+	}
+	plan, err = PlanMigrations(ctx, db, CoreMigrations())
+	if err != nil || plan.Pending() || len(plan.Changes) != 0 {
+		t.Fatalf("plan after migrating = %+v, %v", plan, err)
+	}
+	if err := checkMigrations(ctx, db, CoreMigrations()); err != nil {
+		t.Errorf("checkMigrations after migrating = %v", err)
+	}
+
+	// A new column and index on an existing table.
+	addColumn := Migration{Name: "notes", Migrate: func(db *sql.DB) error {
+		var n int
+		err := db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('repos') WHERE name = 'notes'`).Scan(&n)
+		if err != nil {
+			return err
+		}
+		stmts := []string{`CREATE INDEX IF NOT EXISTS idx_repos_onboarded_by ON repos (onboarded_by)`}
+		if n == 0 {
+			stmts = append([]string{`ALTER TABLE repos ADD COLUMN notes TEXT`}, stmts...)
+		}
+		for _, s := range stmts {
+			if _, err := db.Exec(s); err != nil {
+				return err
+			}
+		}
+		return nil
+	}}
+	plan, err = PlanMigrations(ctx, db, append(CoreMigrations(), addColumn))
+	if err != nil {
+		t.Fatalf("PlanMigrations failed: %v", err)
+	}
+	if len(plan.Statements) != 2 || plan.Statements[0].SQL != `ALTER TABLE repos ADD COLUMN notes TEXT` {
+		t.Errorf("statements = %+v", plan.Statements)
+	}
+	if len(plan.Changes) != 2 {
+		t.Fatalf("changes = %+v", plan.Changes)
+	}
+	if c := plan.Changes[0]; c.Type != "table" || c.Name != "repos" || strings.Contains(c.Before, "notes") ||
+		!strings.Contains(c.After, "notes TEXT") {
+		t.Errorf("table change = %+v", c)
+	}
+	if c := plan.Changes[1]; c.Type != "index" || c.Name != "idx_repos_onboarded_by" || c.Before != "" {
+		t.Errorf("index change = %+v", c)
+	}
+
+	failing := Migration{Name: "broken", Migrate: func(db *sql.DB) error {
+		_, err := db.Exec(`CREATE TABLE repos (id INTEGER)`)
+		return err
+	}}
+	_, err = PlanMigrations(ctx, db, []Migration{failing})
+	if err == nil || !strings.HasPrefix(err.Error(), "broken:") {
+		t.Errorf("PlanMigrations with a failing migration = %v", err)
+	}
+}
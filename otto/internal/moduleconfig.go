@@ -0,0 +1,143 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// moduleconfig.go decodes the sections of the modules config into modules' typed config
+// structs, and reads the enabled and route settings every section accepts.
+
+package internal
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ModuleEnabledKey is the setting of a module config section that turns the module off
+// without rebuilding Otto. Sections without it leave the module on.
+const ModuleEnabledKey = "enabled"
+
+// ModuleRouteKey is the setting of a module config section that limits the module to the
+// events of some repositories. Sections without it route every repository's events to it.
+const ModuleRouteKey = "route"
+
+// ModuleRoute limits a module to the events of the listed repositories and organizations.
+// Events without a repository reach the module regardless.
+type ModuleRoute struct {
+	Repos []string `yaml:"repos"` // owner/name, patterns such as owner/name-*, or @groups
+	Orgs  []string `yaml:"orgs"`
+}
+
+// Includes reports whether the route passes the events of repo.
+func (r ModuleRoute) Includes(groups *RepoGroups, repo string) bool {
+	if repo == "" || len(r.Repos) == 0 && len(r.Orgs) == 0 {
+		return true
+	}
+	owner, _, _ := strings.Cut(repo, "/")
+	return slices.ContainsFunc(r.Orgs, func(org string) bool { return strings.EqualFold(org, owner) }) ||
+		groups.Match(r.Repos, repo)
+}
+
+// ModuleConfigValidator is implemented by module config structs that check their values
+// once decoded, e.g. that a window is positive.
+type ModuleConfigValidator interface {
+	Validate() error
+}
+
+// ModuleConfig decodes the named module's section of the modules config into out, a
+// pointer to the module's config struct. See DecodeModuleConfig. A nil app leaves out
+// untouched.
+func (a *App) ModuleConfig(name string, out any) error {
+	if a == nil || a.Config == nil {
+		return nil
+	}
+	var m Module
+	if a.ModuleRegistry != nil {
+		m = a.GetModules()[name]
+	}
+	return DecodeModuleConfig(m, name, a.Config.Modules[name], out)
+}
+
+// DecodeModuleConfig decodes a module config section into out, after migrating it from
+// the format its config_version names to the module's current one, and then validates out
+// if it is a ModuleConfigValidator. The enabled and route settings are not decoded. A nil section
+// leaves out untouched, so callers should pre-populate defaults.
+func DecodeModuleConfig(m Module, name string, section, out any) error {
+	if section == nil {
+		return nil
+	}
+	if settings, ok := section.(map[string]any); ok {
+		migrated, err := MigrateModuleConfig(m, name, settings)
+		if err != nil {
+			return fmt.Errorf("invalid %s module config: %w", name, err)
+		}
+		delete(migrated, ModuleEnabledKey)
+		delete(migrated, ModuleRouteKey)
+		section = migrated
+	}
+	data, err := yaml.Marshal(section)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s module config: %w", name, err)
+	}
+	if err := yaml.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("invalid %s module config: %w", name, err)
+	}
+	if v, ok := out.(ModuleConfigValidator); ok {
+		if err := v.Validate(); err != nil {
+			return fmt.Errorf("invalid %s module config: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// ModuleDisabled reports whether the named module's config section sets enabled: false.
+func (a *App) ModuleDisabled(name string) bool {
+	if a == nil || a.Config == nil {
+		return false
+	}
+	settings, ok := a.Config.Modules[name].(map[string]any)
+	if !ok {
+		return false
+	}
+	enabled, ok := settings[ModuleEnabledKey].(bool)
+	return ok && !enabled
+}
+
+// ModuleRoute returns the route the named module's config section sets, if any.
+func (a *App) ModuleRoute(name string) (ModuleRoute, error) {
+	var route ModuleRoute
+	if a == nil || a.Config == nil {
+		return route, nil
+	}
+	settings, _ := a.Config.Modules[name].(map[string]any)
+	section, ok := settings[ModuleRouteKey]
+	if !ok {
+		return route, nil
+	}
+	data, err := yaml.Marshal(section)
+	if err != nil {
+		return route, fmt.Errorf("failed to encode %s module route: %w", name, err)
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&route); err != nil {
+		return route, fmt.Errorf("invalid %s module route: %w", name, err)
+	}
+	return route, nil
+}
+
+// checkModuleRoutes validates the route of every module config section, whose repository
+// lists may refer to the app's repository groups.
+func (a *App) checkModuleRoutes() error {
+	for name := range a.Config.Modules {
+		route, err := a.ModuleRoute(name)
+		if err != nil {
+			return err
+		}
+		if err := a.RepoGroups.Check(route.Repos); err != nil {
+			return fmt.Errorf("invalid %s module route: repos: %w", name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"errors"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v71/github"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+)
+
+type renamingConfig struct {
+	Users   []string `yaml:"users"`
+	Enabled *bool    `yaml:"enabled"`
+}
+
+func (c *renamingConfig) Validate() error {
+	if len(c.Users) == 0 {
+		return errors.New("users must not be empty")
+	}
+	return nil
+}
+
+func TestModuleConfig(t *testing.T) {
+	app := &App{ModuleRegistry: NewModuleRegistry(), Logger: slog.Default(), Config: &config.AppConfig{
+		Modules: map[string]any{
+			"renaming": map[string]any{ModuleEnabledKey: true, ConfigVersionKey: 2, "users": "alice"},
+			"invalid":  map[string]any{"users": []any{}},
+		},
+	}}
+	app.RegisterModule(renamingModule{})
+
+	var cfg renamingConfig
+	if err := app.ModuleConfig("renaming", &cfg); err != nil {
+		t.Fatalf("ModuleConfig failed: %v", err)
+	}
+	if want := (renamingConfig{Users: []string{"alice"}}); !reflect.DeepEqual(cfg, want) {
+		t.Errorf("config = %+v, want %+v migrated without enabled", cfg, want)
+	}
+
+	err := app.ModuleConfig("invalid", &renamingConfig{})
+	if err == nil || !strings.Contains(err.Error(), "invalid invalid module config: users must not be empty") {
+		t.Errorf("ModuleConfig of an invalid section = %v", err)
+	}
+
+	defaults := renamingConfig{Users: []string{"bob"}}
+	if err := app.ModuleConfig("missing", &defaults); err != nil || defaults.Users[0] != "bob" {
+		t.Errorf("ModuleConfig of a missing section = %+v, %v; want the defaults kept", defaults, err)
+	}
+}
+
+func TestModuleDisabled(t *testing.T) {
+	app := &App{ModuleRegistry: NewModuleRegistry(), Logger: slog.Default(), Config: &config.AppConfig{
+		Modules: map[string]any{
+			"off": map[string]any{ModuleEnabledKey: false},
+			"on":  map[string]any{ModuleEnabledKey: true},
+		},
+	}}
+	for name, want := range map[string]bool{"off": true, "on": false, "unset": false} {
+		if got := app.ModuleDisabled(name); got != want {
+			t.Errorf("ModuleDisabled(%q) = %v, want %v", name, got, want)
+		}
+	}
+
+	app.RegisterModule(&mockModule{name: "off"})
+	app.RegisterModule(&mockModule{name: "on"})
+	if _, ok := app.GetModules()["off"]; ok {
+		t.Error("disabled module was registered")
+	}
+	if _, ok := app.GetModules()["on"]; !ok {
+		t.Error("enabled module was not registered")
+	}
+}
+
+func TestModuleRoute(t *testing.T) {
+	groups, err := NewRepoGroups(map[string][]string{"java": {"open-telemetry/opentelemetry-java*"}}, nil)
+	if err != nil {
+		t.Fatalf("NewRepoGroups failed: %v", err)
+	}
+	app := &App{
+		ModuleRegistry: NewModuleRegistry(),
+		Logger:         slog.Default(),
+		Telemetry:      TestTelemetry(t, nil),
+		RepoGroups:     groups,
+		Config: &config.AppConfig{Modules: map[string]any{
+			"oncall": map[string]any{ModuleRouteKey: map[string]any{
+				"repos": []any{"open-telemetry/opentelemetry-go", "@java"},
+				"orgs":  []any{"otel-contrib"},
+			}},
+			"typo": map[string]any{ModuleRouteKey: map[string]any{"repo": []any{"org/repo"}}},
+		}},
+	}
+	for repo, want := range map[string]bool{
+		"open-telemetry/opentelemetry-go":                   true,
+		"open-telemetry/opentelemetry-java-instrumentation": true,
+		"OTel-Contrib/anything":                             true,
+		"open-telemetry/opentelemetry-python":               false,
+		"":                                                  true,
+	} {
+		if got := app.ModuleEnabled(repo, "oncall"); got != want {
+			t.Errorf("ModuleEnabled(%q, oncall) = %v, want %v", repo, got, want)
+		}
+	}
+	if _, err := app.ModuleRoute("typo"); err == nil {
+		t.Error("ModuleRoute accepted an unknown setting")
+	}
+	delete(app.Config.Modules, "typo")
+	if err := app.checkModuleRoutes(); err != nil {
+		t.Errorf("checkModuleRoutes failed: %v", err)
+	}
+	app.Config.Modules["unknown"] = map[string]any{ModuleRouteKey: map[string]any{"repos": []any{"@python"}}}
+	if err := app.checkModuleRoutes(); err == nil || !strings.Contains(err.Error(), `unknown repo group "python"`) {
+		t.Errorf("checkModuleRoutes with an unknown group = %v", err)
+	}
+	delete(app.Config.Modules, "unknown")
+
+	// Only the modules whose route includes an event's repository handle it.
+	routed, other := &mockModule{name: "oncall"}, &mockModule{name: "sla"}
+	app.RegisterModule(routed)
+	app.RegisterModule(other)
+	for _, repo := range []string{"open-telemetry/opentelemetry-go", "open-telemetry/opentelemetry-python"} {
+		event := &github.IssuesEvent{
+			Action: github.Ptr("opened"),
+			Repo:   &github.Repository{FullName: github.Ptr(repo)},
+			Issue:  &github.Issue{Number: github.Ptr(1)},
+		}
+		raw := []byte(`{"action": "opened", "repository": {"full_name": "` + repo + `"}}`)
+		app.handleEvent("delivery-"+repo, "issues", event, raw, nil)
+	}
+	if routed.handled != 1 || other.handled != 2 {
+		t.Errorf("handled: oncall %d, sla %d; want 1 and 2", routed.handled, other.handled)
+	}
+}
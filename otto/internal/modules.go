@@ -8,7 +8,10 @@ import (
 	"context"
 	"encoding/json"
 	"log/slog"
+	"maps"
 	"sync"
+
+	"github.com/google/go-github/v71/github"
 )
 
 // CommandContext represents a slash command invocation.
@@ -21,6 +24,32 @@ type CommandContext struct {
 	IssueNum int
 	RawBody  string // raw comment body, if needed
 	App      *App   // reference to the app instance
+
+	Event *github.IssueCommentEvent // the comment, e.g. for its author association
+}
+
+// ModuleCommander is an optional interface for modules that handle slash commands, so
+// executed commands can be attributed to them. Commands are named without the slash, and
+// subcommands of a shared command with their first argument, e.g. "otto status".
+type ModuleCommander interface {
+	SlashCommands() []string
+}
+
+// ModuleCommandHandler is an optional interface for modules whose slash commands the app
+// parses and hands to them, instead of the module parsing comments in HandleEvent. The app
+// calls HandleCommand for each command in a new comment by a user that is one of the
+// module's SlashCommands, in a span of its own and counted per command. A module's
+// commands in one comment are handled one at a time, in the order of the comment.
+type ModuleCommandHandler interface {
+	ModuleCommander
+	HandleCommand(cmd *CommandContext) error
+}
+
+// ModuleCommandObserver is an optional interface for modules that observe the slash
+// commands in new comments by users, whichever module handles them, e.g. to count them.
+// The app calls ObserveCommands once per comment with all its commands, in order.
+type ModuleCommandObserver interface {
+	ObserveCommands(cmds []*CommandContext) error
 }
 
 // Module is the Otto feature/module interface.
@@ -29,6 +58,13 @@ type Module interface {
 	HandleEvent(eventType string, event any, raw json.RawMessage) error
 }
 
+// ModuleContextHandler is an optional interface for modules that handle events with the
+// context of their handler span, e.g. to record decisions with AddDecision. The app calls
+// HandleEventContext instead of HandleEvent.
+type ModuleContextHandler interface {
+	HandleEventContext(ctx context.Context, eventType string, event any, raw json.RawMessage) error
+}
+
 // ModuleInitializer is an optional interface that modules can implement
 // for initialization logic.
 type ModuleInitializer interface {
@@ -41,20 +77,40 @@ type ModuleShutdowner interface {
 	Shutdown(ctx context.Context) error
 }
 
+// ModuleConfigSchema is an optional interface for modules with configuration. It returns
+// a pointer to a new value of the module's config type, holding the module's defaults,
+// which repositories' Otto config files are checked against and the config reference
+// is generated from.
+type ModuleConfigSchema interface {
+	ConfigSchema() any
+}
+
+// ModuleConfigMigrator is an optional interface for modules whose config format has
+// changed. ConfigVersion returns the current format version, starting at 1. MigrateConfig
+// rewrites a config section written for fromVersion into the format of fromVersion+1;
+// it is called once per version step, so each format change needs a single step.
+type ModuleConfigMigrator interface {
+	ConfigVersion() int
+	MigrateConfig(old map[string]any, fromVersion int) (map[string]any, error)
+}
+
 // ModuleRegistry manages the registration and retrieval of modules.
 type ModuleRegistry struct {
 	modulesMu sync.RWMutex
 	modules   map[string]Module
+	commands  map[string]string // slash commands of ModuleCommandHandlers, to the module handling them
 }
 
 // NewModuleRegistry creates a new module registry.
 func NewModuleRegistry() *ModuleRegistry {
 	return &ModuleRegistry{
-		modules: make(map[string]Module),
+		modules:  make(map[string]Module),
+		commands: make(map[string]string),
 	}
 }
 
-// RegisterModule adds a module to the registry.
+// RegisterModule adds a module to the registry. A slash command the module handles that a
+// module registered before it already handles stays with the earlier module.
 func (r *ModuleRegistry) RegisterModule(m Module) {
 	r.modulesMu.Lock()
 	defer r.modulesMu.Unlock()
@@ -63,9 +119,26 @@ func (r *ModuleRegistry) RegisterModule(m Module) {
 		return
 	}
 	r.modules[m.Name()] = m
+	if h, ok := m.(ModuleCommandHandler); ok {
+		for _, command := range h.SlashCommands() {
+			if owner, taken := r.commands[command]; taken {
+				slog.Error("slash command declared by two modules; ignoring the later one",
+					"command", command, "module", owner, "ignored", m.Name())
+				continue
+			}
+			r.commands[command] = m.Name()
+		}
+	}
 	slog.Info("module registered", "name", m.Name())
 }
 
+// CommandHandlers returns a copy of the map of slash commands to the modules handling them.
+func (r *ModuleRegistry) CommandHandlers() map[string]string {
+	r.modulesMu.RLock()
+	defer r.modulesMu.RUnlock()
+	return maps.Clone(r.commands)
+}
+
 // GetModules returns a copy of the registered modules map.
 func (r *ModuleRegistry) GetModules() map[string]Module {
 	r.modulesMu.RLock()
@@ -3,10 +3,17 @@
 package internal
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"sync"
 	"sync/atomic"
 	"testing"
+	"time"
+
+	"github.com/google/go-github/v71/github"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
 )
 
 type mockModule struct {
@@ -47,3 +54,65 @@ func TestRegisterModuleAndDispatch(t *testing.T) {
 		t.Fatalf("module did not handle the event")
 	}
 }
+
+func TestDispatchNormalizedGitHubEvent(t *testing.T) {
+	mod := &normalizedModule{mockModule: mockModule{name: "normalized"}, events: make(chan *NormalizedEvent, 1)}
+	app := &App{ModuleRegistry: NewModuleRegistry()}
+	app.RegisterModule(mod)
+
+	action, repo := "opened", "org/repo"
+	app.DispatchEvent("issues", &github.IssuesEvent{
+		Action: &action,
+		Repo:   &github.Repository{FullName: &repo},
+		Issue:  &github.Issue{Number: github.Ptr(3)},
+	}, nil)
+
+	event := <-mod.events
+	if event.Kind != NormalizedIssue || event.Repo != repo || event.Number != 3 || event.Action != action {
+		t.Errorf("unexpected normalized event: %+v", event)
+	}
+	if atomic.LoadInt32(&mod.handled) != 1 {
+		t.Error("HandleEvent should still receive the GitHub event")
+	}
+}
+
+type blockingModule struct {
+	mockModule
+	release chan struct{}
+}
+
+func (m *blockingModule) HandleEvent(eventType string, event any, raw json.RawMessage) error {
+	<-m.release
+	return m.mockModule.HandleEvent(eventType, event, raw)
+}
+
+func TestShutdownDrainsEvents(t *testing.T) {
+	mod := &blockingModule{mockModule: mockModule{name: "slow"}, release: make(chan struct{})}
+	app := &App{
+		Config:         &config.AppConfig{Dispatch: config.DispatchConfig{DrainTimeout: 20 * time.Millisecond}},
+		ModuleRegistry: NewModuleRegistry(),
+		shutdownSignal: make(chan struct{}),
+		lifecycle:      &lifecycle{},
+	}
+	app.RegisterModule(mod)
+	app.DispatchEvent("fake", struct{}{}, nil)
+
+	if err := app.drain(t.Context()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("drain with a running handler = %v, want the drain timeout", err)
+	}
+	close(mod.release)
+	if err := app.drain(t.Context()); err != nil {
+		t.Fatalf("drain = %v", err)
+	}
+	if atomic.LoadInt32(&mod.handled) != 1 {
+		t.Errorf("drain returned before the event was handled")
+	}
+
+	app.SignalShutdown()
+	app.SignalShutdown() // a second signal must not panic
+	select {
+	case <-app.shutdownSignal:
+	default:
+		t.Error("SignalShutdown did not signal")
+	}
+}
@@ -0,0 +1,103 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// normalized.go defines Otto's source-independent view of pull request and issue events,
+// so modules can handle GitHub and mirrored GitLab projects with the same code.
+
+package internal
+
+import (
+	"github.com/google/go-github/v71/github"
+)
+
+// Event sources.
+const (
+	SourceGitHub = "github"
+	SourceGitLab = "gitlab"
+)
+
+// Kinds of normalized events.
+const (
+	NormalizedPullRequest = "pull_request" // GitHub pull requests and GitLab merge requests
+	NormalizedIssue       = "issue"
+)
+
+// NormalizedEvent is a pull request or issue event from any source.
+type NormalizedEvent struct {
+	Source       string // SourceGitHub or SourceGitLab
+	Kind         string // NormalizedPullRequest or NormalizedIssue
+	Action       string // opened, edited, closed, reopened, merged, or the source's own action
+	Repo         string // owner/name on GitHub, the project path on GitLab
+	Number       int    // number on GitHub, IID on GitLab
+	Title        string
+	Body         string
+	URL          string // web URL of the pull request or issue
+	State        string // open, closed or merged
+	Author       string // login of the author; empty if the source does not say
+	Sender       string // login of the user who triggered the event
+	Labels       []string
+	Draft        bool
+	SourceBranch string // pull requests only
+	TargetBranch string // pull requests only
+}
+
+// NormalizedEventHandler is an optional interface for modules that handle normalized
+// events. Such modules receive pull request and issue events from every source, in
+// addition to the GitHub events passed to HandleEvent.
+type NormalizedEventHandler interface {
+	HandleNormalizedEvent(event *NormalizedEvent) error
+}
+
+// NormalizeGitHubEvent returns the normalized form of a GitHub pull request or issue
+// event, or nil for other events.
+func NormalizeGitHubEvent(event any) *NormalizedEvent {
+	switch e := event.(type) {
+	case *github.PullRequestEvent:
+		pr := e.GetPullRequest()
+		n := &NormalizedEvent{
+			Source:       SourceGitHub,
+			Kind:         NormalizedPullRequest,
+			Action:       e.GetAction(),
+			Repo:         e.GetRepo().GetFullName(),
+			Number:       pr.GetNumber(),
+			Title:        pr.GetTitle(),
+			Body:         pr.GetBody(),
+			URL:          pr.GetHTMLURL(),
+			State:        pr.GetState(),
+			Author:       pr.GetUser().GetLogin(),
+			Sender:       e.GetSender().GetLogin(),
+			Draft:        pr.GetDraft(),
+			SourceBranch: pr.GetHead().GetRef(),
+			TargetBranch: pr.GetBase().GetRef(),
+		}
+		if pr.GetMerged() {
+			n.State = "merged"
+			if n.Action == "closed" {
+				n.Action = "merged"
+			}
+		}
+		for _, l := range pr.Labels {
+			n.Labels = append(n.Labels, l.GetName())
+		}
+		return n
+	case *github.IssuesEvent:
+		issue := e.GetIssue()
+		n := &NormalizedEvent{
+			Source: SourceGitHub,
+			Kind:   NormalizedIssue,
+			Action: e.GetAction(),
+			Repo:   e.GetRepo().GetFullName(),
+			Number: issue.GetNumber(),
+			Title:  issue.GetTitle(),
+			Body:   issue.GetBody(),
+			URL:    issue.GetHTMLURL(),
+			State:  issue.GetState(),
+			Author: issue.GetUser().GetLogin(),
+			Sender: e.GetSender().GetLogin(),
+		}
+		for _, l := range issue.Labels {
+			n.Labels = append(n.Labels, l.GetName())
+		}
+		return n
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/go-github/v71/github"
+)
+
+func TestNormalizeGitHubEvent(t *testing.T) {
+	payload := `{"action":"closed","repository":{"full_name":"org/repo"},"sender":{"login":"bob"},
+		"pull_request":{"number":5,"title":"Fix","state":"closed","merged":true,"user":{"login":"alice"},
+		"head":{"ref":"fix"},"base":{"ref":"main"},"labels":[{"name":"bug"}]}}`
+	parsed, err := github.ParseWebHook("pull_request", []byte(payload))
+	if err != nil {
+		t.Fatalf("ParseWebHook failed: %v", err)
+	}
+	got := NormalizeGitHubEvent(parsed)
+	want := &NormalizedEvent{
+		Source: SourceGitHub, Kind: NormalizedPullRequest, Action: "merged", Repo: "org/repo", Number: 5,
+		Title: "Fix", State: "merged", Author: "alice", Sender: "bob", Labels: []string{"bug"},
+		SourceBranch: "fix", TargetBranch: "main",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("NormalizeGitHubEvent() = %+v, want %+v", got, want)
+	}
+	if NormalizeGitHubEvent(struct{}{}) != nil {
+		t.Error("other events should not be normalized")
+	}
+}
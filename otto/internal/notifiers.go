@@ -0,0 +1,138 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// notifiers.go implements the built-in notifier backends.
+
+package internal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v71/github"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+)
+
+// SMTPPasswordSecret is the secrets name of the email notifier's SMTP password.
+const SMTPPasswordSecret = "smtp_password"
+
+// SlackNotifier posts notifications to Slack channels or users.
+type SlackNotifier struct {
+	Client *SlackClient
+}
+
+func (s *SlackNotifier) Backend() string { return "slack" }
+
+// Notify posts to a channel name or ID, or DMs a Slack user ID.
+func (s *SlackNotifier) Notify(ctx context.Context, target string, n Notification) error {
+	return s.Client.PostMessage(ctx, target, n.Text())
+}
+
+// WebhookNotifier POSTs notifications as JSON.
+type WebhookNotifier struct {
+	Client *http.Client
+}
+
+// NewWebhookNotifier creates a webhook notifier with a request timeout.
+func NewWebhookNotifier() *WebhookNotifier {
+	return &WebhookNotifier{Client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (w *WebhookNotifier) Backend() string { return "webhook" }
+
+// Notify POSTs n to the target URL and expects a 2xx response.
+func (w *WebhookNotifier) Notify(ctx context.Context, target string, n Notification) error {
+	body, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := w.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post webhook notification: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// GitHubNotifier comments notifications on an issue.
+type GitHubNotifier struct {
+	Client *github.Client
+}
+
+func (g *GitHubNotifier) Backend() string { return "github" }
+
+// Notify comments on the "owner/repo#123" target, or on the notification's own issue
+// when the target is empty.
+func (g *GitHubNotifier) Notify(ctx context.Context, target string, n Notification) error {
+	repo, issue := n.Repo, n.Issue
+	if target != "" {
+		var err error
+		if repo, issue, err = splitIssueTarget(target); err != nil {
+			return err
+		}
+	}
+	if repo == "" || issue == 0 {
+		return fmt.Errorf("notification has no issue to comment on")
+	}
+	body := fmt.Sprintf("**[%s] %s**", n.Severity, n.Title)
+	if n.Body != "" {
+		body += "\n\n" + n.Body
+	}
+	if n.URL != "" {
+		body += "\n\n" + n.URL
+	}
+	return PostComment(ctx, g.Client, repo, issue, body)
+}
+
+// EmailNotifier sends notifications by SMTP.
+type EmailNotifier struct {
+	addr string
+	from string
+	auth smtp.Auth
+	send func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// NewEmailNotifier creates an email notifier. Without a username, mail is sent unauthenticated.
+func NewEmailNotifier(cfg config.SMTPConfig, password string) *EmailNotifier {
+	e := &EmailNotifier{addr: cfg.Addr, from: cfg.From, send: smtp.SendMail}
+	if cfg.Username != "" {
+		host, _, _ := strings.Cut(cfg.Addr, ":")
+		e.auth = smtp.PlainAuth("", cfg.Username, password, host)
+	}
+	return e
+}
+
+func (e *EmailNotifier) Backend() string { return "email" }
+
+// Notify mails n to the target address; separate several addresses with commas.
+func (e *EmailNotifier) Notify(ctx context.Context, target string, n Notification) error {
+	to := strings.Split(target, ",")
+	for i := range to {
+		to[i] = strings.TrimSpace(to[i])
+	}
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", e.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&msg, "Subject: [otto/%s] %s\r\n", n.Severity, n.Title)
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(n.Text(), "\n", "\r\n"))
+	msg.WriteString("\r\n")
+	if err := e.send(e.addr, e.auth, e.from, to, msg.Bytes()); err != nil {
+		return fmt.Errorf("failed to send email notification: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+)
+
+func TestWebhookNotifier(t *testing.T) {
+	var got Notification
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	n := Notification{Module: "sla", Severity: SeverityWarning, Title: "stale issues"}
+	if err := NewWebhookNotifier().Notify(t.Context(), srv.URL, n); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+	if got != n {
+		t.Errorf("webhook received %+v, want %+v", got, n)
+	}
+	status = http.StatusInternalServerError
+	if err := NewWebhookNotifier().Notify(t.Context(), srv.URL, n); err == nil {
+		t.Error("expected error for 500 response")
+	}
+}
+
+func TestGitHubNotifier(t *testing.T) {
+	var paths, bodies []string
+	client := TestGitHubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		paths, bodies = append(paths, r.URL.Path), append(bodies, string(body))
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	notifier := &GitHubNotifier{Client: client}
+	n := Notification{Repo: "org/repo", Issue: 4, Severity: SeverityCritical, Title: "escalation", Body: "details"}
+
+	tests := []struct {
+		target   string
+		wantPath string
+		wantErr  bool
+	}{
+		{"", "/repos/org/repo/issues/4/comments", false},
+		{"org/tracking#12", "/repos/org/tracking/issues/12/comments", false},
+		{"org/tracking", "", true},
+	}
+	for _, tt := range tests {
+		paths = nil
+		err := notifier.Notify(t.Context(), tt.target, n)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("target %q: err = %v, wantErr %v", tt.target, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && (len(paths) != 1 || paths[0] != tt.wantPath) {
+			t.Errorf("target %q: requests %v, want %s", tt.target, paths, tt.wantPath)
+		}
+	}
+	if !strings.Contains(bodies[0], "**[critical] escalation**") {
+		t.Errorf("unexpected comment body: %s", bodies[0])
+	}
+}
+
+func TestEmailNotifier(t *testing.T) {
+	notifier := NewEmailNotifier(config.SMTPConfig{Addr: "smtp.example.com:587", From: "otto@example.com"}, "")
+	var (
+		gotTo  []string
+		gotMsg string
+	)
+	notifier.send = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		gotTo, gotMsg = to, string(msg)
+		return nil
+	}
+	n := Notification{Severity: SeverityInfo, Title: "handoff", Body: "alice → bob"}
+	if err := notifier.Notify(t.Context(), "ops@example.com, lead@example.com", n); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+	if !slices.Equal(gotTo, []string{"ops@example.com", "lead@example.com"}) {
+		t.Errorf("recipients = %v", gotTo)
+	}
+	if !strings.Contains(gotMsg, "Subject: [otto/info] handoff\r\n") || !strings.Contains(gotMsg, "alice → bob") {
+		t.Errorf("unexpected message:\n%s", gotMsg)
+	}
+}
+
+func TestSlackNotifier(t *testing.T) {
+	var got map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	defer srv.Close()
+
+	notifier := &SlackNotifier{Client: NewSlackClient("token").WithBaseURL(srv.URL)}
+	err := notifier.Notify(t.Context(), "#oncall", Notification{Severity: SeverityWarning, Title: "t", URL: "https://x"})
+	if err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+	if got["channel"] != "#oncall" || got["text"] != "[warning] t\nhttps://x" {
+		t.Errorf("slack received %v", got)
+	}
+}
@@ -0,0 +1,304 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// notify.go routes notifications from modules to configured channels on pluggable
+// notifier backends (Slack, email, webhooks, GitHub comments).
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+)
+
+// Severity ranks notifications for routing.
+type Severity string
+
+// Notification severities, from least to most severe.
+const (
+	SeverityInfo     Severity = "info"
+	SeverityWarning  Severity = "warning"
+	SeverityCritical Severity = "critical"
+)
+
+// rank orders severities; unknown severities rank as info.
+func (s Severity) rank() int {
+	switch s {
+	case SeverityWarning:
+		return 1
+	case SeverityCritical:
+		return 2
+	}
+	return 0
+}
+
+// Notification is a message from a module to whoever its routes deliver to.
+type Notification struct {
+	Module   string   `json:"module"`
+	Repo     string   `json:"repo,omitempty"`
+	Issue    int      `json:"issue,omitempty"`
+	Severity Severity `json:"severity"`
+	Title    string   `json:"title"`
+	Body     string   `json:"body,omitempty"`
+	URL      string   `json:"url,omitempty"`
+}
+
+// Text renders the notification as plain text.
+func (n Notification) Text() string {
+	text := fmt.Sprintf("[%s] %s", n.Severity, n.Title)
+	if n.Body != "" {
+		text += "\n" + n.Body
+	}
+	if n.URL != "" {
+		text += "\n" + n.URL
+	}
+	return text
+}
+
+// Notifier delivers notifications to targets on one backend.
+type Notifier interface {
+	// Backend is the name channels use to select this notifier, e.g. "slack".
+	Backend() string
+	// Notify delivers n to target, whose format depends on the backend.
+	Notify(ctx context.Context, target string, n Notification) error
+}
+
+// Notifications fans notifications out to the channels their routes select.
+type Notifications struct {
+	config    config.NotificationsConfig
+	telemetry *TelemetryManager
+	backoff   time.Duration
+	quiet     *QuietHours
+	filter    *ContentFilter
+
+	mu       sync.RWMutex
+	backends map[string]Notifier
+}
+
+// NewNotifications validates the routing config. Telemetry may be nil.
+func NewNotifications(cfg config.NotificationsConfig, telemetry *TelemetryManager) (*Notifications, error) {
+	for i, route := range cfg.Routes {
+		switch Severity(route.Severity) {
+		case "", SeverityInfo, SeverityWarning, SeverityCritical:
+		default:
+			return nil, fmt.Errorf("notifications: route %d has unknown severity %q", i, route.Severity)
+		}
+		for _, name := range route.Channels {
+			if _, ok := cfg.Channels[name]; !ok {
+				return nil, fmt.Errorf("notifications: route %d references unknown channel %q", i, name)
+			}
+		}
+	}
+	return &Notifications{
+		config:    cfg,
+		telemetry: telemetry,
+		backoff:   time.Second,
+		backends:  make(map[string]Notifier),
+	}, nil
+}
+
+// Register adds a notifier backend, replacing any with the same name.
+func (n *Notifications) Register(notifier Notifier) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.backends[notifier.Backend()] = notifier
+}
+
+// HoldDuringQuietHours holds notifications below critical severity during the quiet hours
+// of their repository. It is set up before notifications are sent.
+func (n *Notifications) HoldDuringQuietHours(quiet *QuietHours) {
+	n.quiet = quiet
+}
+
+// FilterContent blocks notifications whose text fails filter's check, other than the
+// filter's own alerts. It is set up before notifications are sent.
+func (n *Notifications) FilterContent(filter *ContentFilter) {
+	n.filter = filter
+}
+
+// checkContent returns ErrContentBlocked for a notification the content filter blocks.
+func (n *Notifications) checkContent(ctx context.Context, notification Notification) error {
+	if notification.Module == ContentFilterModule {
+		return nil
+	}
+	if reason := n.filter.Check(notification.Text()); reason != "" {
+		return n.filter.block(ctx, notification.Module, notification.Repo, "notification", reason)
+	}
+	return nil
+}
+
+// Route returns the channels a notification is delivered to, in config order.
+func (n *Notifications) Route(notification Notification) []string {
+	var channels []string
+	for _, route := range n.config.Routes {
+		if notification.Severity.rank() < Severity(route.Severity).rank() {
+			continue
+		}
+		if len(route.Modules) > 0 && !slices.Contains(route.Modules, notification.Module) {
+			continue
+		}
+		if len(route.Repos) > 0 && !slices.Contains(route.Repos, notification.Repo) {
+			continue
+		}
+		for _, c := range route.Channels {
+			if !slices.Contains(channels, c) {
+				channels = append(channels, c)
+			}
+		}
+	}
+	return channels
+}
+
+// Notify delivers a notification to every routed channel concurrently, retrying failed
+// deliveries, or holds it for the end of quiet hours. It returns the errors of channels
+// that could not be reached. A nil Notifications drops everything.
+func (n *Notifications) Notify(ctx context.Context, notification Notification) error {
+	if n == nil {
+		return nil
+	}
+	if notification.Severity == "" {
+		notification.Severity = SeverityInfo
+	}
+	channels := n.Route(notification)
+	if len(channels) == 0 {
+		slog.Debug("Notification matched no routes", "module", notification.Module, "title", notification.Title)
+		return nil
+	}
+	if err := n.checkContent(ctx, notification); err != nil {
+		return err
+	}
+
+	errs := make([]error, len(channels))
+	var wg sync.WaitGroup
+	for i, name := range channels {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := n.dispatch(ctx, n.config.Channels[name], notification); err != nil {
+				errs[i] = fmt.Errorf("channel %s: %w", name, err)
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// Send delivers a notification to one target on a backend, ignoring routes, with the same
+// retries and quiet hours as Notify. Modules use it for messages that must reach only a destination of
+// their own, such as undisclosed security reports.
+func (n *Notifications) Send(ctx context.Context, backend, target string, notification Notification) error {
+	if n == nil {
+		return fmt.Errorf("notifier backend %q is not configured", backend)
+	}
+	if notification.Severity == "" {
+		notification.Severity = SeverityInfo
+	}
+	if err := n.checkContent(ctx, notification); err != nil {
+		return err
+	}
+	return n.dispatch(ctx, config.NotificationChannel{Backend: backend, Target: target}, notification)
+}
+
+// dispatch delivers a notification to a channel, or holds it until the quiet hours of its
+// repository end unless it is critical. A notification that cannot be held is delivered.
+func (n *Notifications) dispatch(
+	ctx context.Context,
+	channel config.NotificationChannel,
+	notification Notification,
+) error {
+	if n.quiet == nil || notification.Severity == SeverityCritical {
+		return n.deliver(ctx, channel, notification)
+	}
+	n.mu.RLock()
+	_, available := n.backends[channel.Backend]
+	n.mu.RUnlock()
+	until, quiet := n.quiet.Until(notification.Repo, n.quiet.now())
+	if !quiet || !available {
+		return n.deliver(ctx, channel, notification)
+	}
+	if channel.Backend == "github" && channel.Target == "" && notification.Repo != "" {
+		// Comments on the notification's own issue are summarized per issue.
+		channel.Target = fmt.Sprintf("%s#%d", notification.Repo, notification.Issue)
+	}
+	if err := n.quiet.hold(ctx, channel, notification, until); err != nil {
+		slog.Error("Failed to hold notification for quiet hours", "backend", channel.Backend, "error", err)
+		return n.deliver(ctx, channel, notification)
+	}
+	n.record(ctx, channel.Backend, "held")
+	return nil
+}
+
+// deliver sends a notification to one channel, retrying with exponential backoff.
+func (n *Notifications) deliver(
+	ctx context.Context,
+	channel config.NotificationChannel,
+	notification Notification,
+) error {
+	n.mu.RLock()
+	notifier := n.backends[channel.Backend]
+	n.mu.RUnlock()
+	if notifier == nil {
+		n.record(ctx, channel.Backend, "unavailable")
+		return fmt.Errorf("notifier backend %q is not configured", channel.Backend)
+	}
+
+	delay := n.backoff
+	for attempt := 0; ; attempt++ {
+		err := notifier.Notify(ctx, channel.Target, notification)
+		if err == nil {
+			n.record(ctx, channel.Backend, "success")
+			return nil
+		}
+		if attempt >= n.config.Retries {
+			n.record(ctx, channel.Backend, "failure")
+			slog.Error("Notification delivery failed", "backend", channel.Backend, "target", channel.Target,
+				"attempts", attempt+1, "error", err)
+			return err
+		}
+		if n.telemetry != nil {
+			n.telemetry.IncNotificationRetry(ctx, channel.Backend)
+		}
+		select {
+		case <-ctx.Done():
+			n.record(ctx, channel.Backend, "failure")
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+func (n *Notifications) record(ctx context.Context, backend, outcome string) {
+	if n.telemetry != nil {
+		n.telemetry.RecordNotification(ctx, backend, outcome)
+	}
+}
+
+// Backends returns the names of the registered notifier backends.
+func (n *Notifications) Backends() []string {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	names := make([]string, 0, len(n.backends))
+	for name := range n.backends {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// splitIssueTarget parses an "owner/repo#123" notification target.
+func splitIssueTarget(target string) (string, int, error) {
+	repo, num, found := strings.Cut(target, "#")
+	var issue int
+	if _, err := fmt.Sscanf(num, "%d", &issue); !found || err != nil || !strings.Contains(repo, "/") {
+		return "", 0, fmt.Errorf("invalid issue target %q: want owner/repo#123", target)
+	}
+	return repo, issue, nil
+}
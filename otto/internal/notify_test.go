@@ -0,0 +1,182 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+)
+
+// recordingNotifier records deliveries and fails the first failures calls.
+type recordingNotifier struct {
+	name     string
+	failures int
+
+	mu        sync.Mutex
+	calls     int
+	delivered []string
+}
+
+func (r *recordingNotifier) Backend() string { return r.name }
+
+func (r *recordingNotifier) Notify(ctx context.Context, target string, n Notification) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls++
+	if r.calls <= r.failures {
+		return errors.New("unavailable")
+	}
+	r.delivered = append(r.delivered, target+": "+n.Title)
+	return nil
+}
+
+var testNotificationsConfig = config.NotificationsConfig{
+	Channels: map[string]config.NotificationChannel{
+		"oncall-slack": {Backend: "slack", Target: "#oncall"},
+		"ops-email":    {Backend: "email", Target: "ops@example.com"},
+		"audit":        {Backend: "webhook", Target: "https://audit.example.com"},
+	},
+	Routes: []config.NotificationRoute{
+		{Severity: "critical", Channels: []string{"ops-email"}},
+		{Modules: []string{"oncall"}, Channels: []string{"oncall-slack"}},
+		{Severity: "warning", Repos: []string{"org/a"}, Channels: []string{"audit", "oncall-slack"}},
+	},
+	Retries: 2,
+}
+
+func TestNotificationRouting(t *testing.T) {
+	n, err := NewNotifications(testNotificationsConfig, nil)
+	if err != nil {
+		t.Fatalf("NewNotifications failed: %v", err)
+	}
+	tests := []struct {
+		name         string
+		notification Notification
+		want         []string
+	}{
+		{"info from oncall", Notification{Module: "oncall", Severity: SeverityInfo}, []string{"oncall-slack"}},
+		{"info elsewhere", Notification{Module: "sla", Repo: "org/a", Severity: SeverityInfo}, nil},
+		{"warning in repo", Notification{Module: "sla", Repo: "org/a", Severity: SeverityWarning},
+			[]string{"audit", "oncall-slack"}},
+		{"critical from oncall", Notification{Module: "oncall", Repo: "org/a", Severity: SeverityCritical},
+			[]string{"ops-email", "oncall-slack", "audit"}},
+	}
+	for _, tt := range tests {
+		if got := n.Route(tt.notification); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: Route = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	for _, cfg := range []config.NotificationsConfig{
+		{Routes: []config.NotificationRoute{{Channels: []string{"missing"}}}},
+		{Routes: []config.NotificationRoute{{Severity: "urgent"}}},
+	} {
+		if _, err := NewNotifications(cfg, nil); err == nil {
+			t.Errorf("expected error for config %+v", cfg)
+		}
+	}
+}
+
+func TestNotifyFanOutAndRetry(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	n, err := NewNotifications(testNotificationsConfig, TestTelemetry(t, reader))
+	if err != nil {
+		t.Fatalf("NewNotifications failed: %v", err)
+	}
+	n.backoff = time.Millisecond
+
+	slack := &recordingNotifier{name: "slack", failures: 1}
+	email := &recordingNotifier{name: "email", failures: 5}
+	n.Register(slack)
+	n.Register(email)
+	// No webhook backend is registered, so the audit channel is unavailable.
+
+	err = n.Notify(t.Context(), Notification{Module: "oncall", Repo: "org/a", Severity: SeverityCritical, Title: "down"})
+	if err == nil || !strings.Contains(err.Error(), "channel ops-email") ||
+		!strings.Contains(err.Error(), "channel audit") {
+		t.Fatalf("Notify error = %v, want failures for ops-email and audit", err)
+	}
+	if !slices.Equal(slack.delivered, []string{"#oncall: down"}) || slack.calls != 2 {
+		t.Errorf("slack delivered %v in %d calls, want one delivery after a retry", slack.delivered, slack.calls)
+	}
+	if email.calls != 3 {
+		t.Errorf("email attempted %d times, want 3", email.calls)
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(t.Context(), &rm); err != nil {
+		t.Fatalf("failed to collect metrics: %v", err)
+	}
+	outcomes := map[string]int64{}
+	retries := map[string]int64{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			sum, ok := m.Data.(metricdata.Sum[int64])
+			if !ok {
+				continue
+			}
+			for _, dp := range sum.DataPoints {
+				backend, _ := dp.Attributes.Value("backend")
+				switch m.Name {
+				case "otto.notifications_total":
+					outcome, _ := dp.Attributes.Value("outcome")
+					outcomes[backend.AsString()+"/"+outcome.AsString()] += dp.Value
+				case "otto.notification_retries_total":
+					retries[backend.AsString()] += dp.Value
+				}
+			}
+		}
+	}
+	wantOutcomes := map[string]int64{"slack/success": 1, "email/failure": 1, "webhook/unavailable": 1}
+	for k, v := range wantOutcomes {
+		if outcomes[k] != v {
+			t.Errorf("notifications_total[%s] = %d, want %d (all: %v)", k, outcomes[k], v, outcomes)
+		}
+	}
+	if retries["slack"] != 1 || retries["email"] != 2 {
+		t.Errorf("retries = %v, want slack 1, email 2", retries)
+	}
+
+	var nilNotifications *Notifications
+	if err := nilNotifications.Notify(t.Context(), Notification{Title: "dropped"}); err != nil {
+		t.Errorf("nil Notify = %v", err)
+	}
+}
+
+func TestNotificationSend(t *testing.T) {
+	n, err := NewNotifications(testNotificationsConfig, nil)
+	if err != nil {
+		t.Fatalf("NewNotifications failed: %v", err)
+	}
+	n.backoff = time.Millisecond
+	slack := &recordingNotifier{name: "slack", failures: 1}
+	email := &recordingNotifier{name: "email"}
+	n.Register(slack)
+	n.Register(email)
+
+	// The notification matches the critical route to ops-email, which Send ignores.
+	notification := Notification{Module: "oncall", Severity: SeverityCritical, Title: "private"}
+	if err := n.Send(t.Context(), "slack", "#maintainers", notification); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if !slices.Equal(slack.delivered, []string{"#maintainers: private"}) || len(email.delivered) != 0 {
+		t.Errorf("delivered slack %v, email %v", slack.delivered, email.delivered)
+	}
+	if err := n.Send(t.Context(), "webhook", "https://example.com", notification); err == nil {
+		t.Error("expected an error for an unregistered backend")
+	}
+	var nilNotifications *Notifications
+	if err := nilNotifications.Send(t.Context(), "slack", "#maintainers", notification); err == nil {
+		t.Error("expected an error without notifications")
+	}
+}
@@ -0,0 +1,199 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// ofrep.go evaluates flags remotely over the OpenFeature Remote Evaluation Protocol
+// (OFREP), which flagd, GO Feature Flag and other flag systems serve.
+
+package internal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/open-feature/go-sdk/openfeature"
+)
+
+// OFREPProvider is an OpenFeature provider backed by an OFREP service.
+type OFREPProvider struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+}
+
+// NewOFREPProvider creates a provider for the OFREP service at baseURL. token is sent as
+// a bearer token if set; the client's timeout bounds each evaluation.
+func NewOFREPProvider(baseURL, token string, client *http.Client) *OFREPProvider {
+	return &OFREPProvider{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		token:      token,
+		httpClient: client,
+	}
+}
+
+// ofrepResult is a successful OFREP evaluation response.
+type ofrepResult struct {
+	Value    any            `json:"value"`
+	Reason   string         `json:"reason"`
+	Variant  string         `json:"variant"`
+	Metadata map[string]any `json:"metadata"`
+}
+
+// ofrepError is an OFREP evaluation error response.
+type ofrepError struct {
+	ErrorCode    string `json:"errorCode"`
+	ErrorDetails string `json:"errorDetails"`
+}
+
+// evaluate asks the service to evaluate a flag. The returned detail carries the
+// resolution error, if any.
+func (p *OFREPProvider) evaluate(
+	ctx context.Context,
+	flag string,
+	flatCtx openfeature.FlattenedContext,
+) (any, openfeature.ProviderResolutionDetail) {
+	fail := func(err openfeature.ResolutionError) (any, openfeature.ProviderResolutionDetail) {
+		return nil, openfeature.ProviderResolutionDetail{ResolutionError: err, Reason: openfeature.ErrorReason}
+	}
+	body, err := json.Marshal(map[string]any{"context": flatCtx})
+	if err != nil {
+		return fail(openfeature.NewGeneralResolutionError(err.Error()))
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		p.baseURL+"/ofrep/v1/evaluate/flags/"+url.PathEscape(flag), bytes.NewReader(body))
+	if err != nil {
+		return fail(openfeature.NewGeneralResolutionError(err.Error()))
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.token)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return fail(openfeature.NewGeneralResolutionError(fmt.Sprintf("failed to evaluate flag: %v", err)))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var e ofrepError
+		_ = json.NewDecoder(resp.Body).Decode(&e)
+		if e.ErrorDetails == "" {
+			e.ErrorDetails = fmt.Sprintf("OFREP service returned status %d", resp.StatusCode)
+		}
+		switch {
+		case e.ErrorCode == string(openfeature.FlagNotFoundCode) || resp.StatusCode == http.StatusNotFound:
+			return fail(openfeature.NewFlagNotFoundResolutionError(e.ErrorDetails))
+		case e.ErrorCode == string(openfeature.TypeMismatchCode):
+			return fail(openfeature.NewTypeMismatchResolutionError(e.ErrorDetails))
+		case e.ErrorCode == string(openfeature.ParseErrorCode):
+			return fail(openfeature.NewParseErrorResolutionError(e.ErrorDetails))
+		case e.ErrorCode == string(openfeature.TargetingKeyMissingCode):
+			return fail(openfeature.NewTargetingKeyMissingResolutionError(e.ErrorDetails))
+		case e.ErrorCode == string(openfeature.InvalidContextCode):
+			return fail(openfeature.NewInvalidContextResolutionError(e.ErrorDetails))
+		}
+		return fail(openfeature.NewGeneralResolutionError(e.ErrorDetails))
+	}
+
+	var result ofrepResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fail(openfeature.NewParseErrorResolutionError(fmt.Sprintf("failed to decode OFREP response: %v", err)))
+	}
+	return result.Value, openfeature.ProviderResolutionDetail{
+		Reason:       openfeature.Reason(result.Reason),
+		Variant:      result.Variant,
+		FlagMetadata: openfeature.FlagMetadata(result.Metadata),
+	}
+}
+
+// mismatch returns a type mismatch detail for a flag value of the wrong type.
+func mismatch(flag string, value any) openfeature.ProviderResolutionDetail {
+	return openfeature.ProviderResolutionDetail{
+		ResolutionError: openfeature.NewTypeMismatchResolutionError(fmt.Sprintf("flag %q has value %v", flag, value)),
+		Reason:          openfeature.ErrorReason,
+	}
+}
+
+// Metadata implements openfeature.FeatureProvider.
+func (p *OFREPProvider) Metadata() openfeature.Metadata {
+	return openfeature.Metadata{Name: "otto-ofrep"}
+}
+
+// Hooks implements openfeature.FeatureProvider.
+func (p *OFREPProvider) Hooks() []openfeature.Hook { return nil }
+
+// BooleanEvaluation implements openfeature.FeatureProvider.
+func (p *OFREPProvider) BooleanEvaluation(
+	ctx context.Context, flag string, defaultValue bool, flatCtx openfeature.FlattenedContext,
+) openfeature.BoolResolutionDetail {
+	value, detail := p.evaluate(ctx, flag, flatCtx)
+	if detail.ResolutionError != (openfeature.ResolutionError{}) {
+		return openfeature.BoolResolutionDetail{Value: defaultValue, ProviderResolutionDetail: detail}
+	}
+	v, ok := value.(bool)
+	if !ok {
+		return openfeature.BoolResolutionDetail{Value: defaultValue, ProviderResolutionDetail: mismatch(flag, value)}
+	}
+	return openfeature.BoolResolutionDetail{Value: v, ProviderResolutionDetail: detail}
+}
+
+// StringEvaluation implements openfeature.FeatureProvider.
+func (p *OFREPProvider) StringEvaluation(
+	ctx context.Context, flag string, defaultValue string, flatCtx openfeature.FlattenedContext,
+) openfeature.StringResolutionDetail {
+	value, detail := p.evaluate(ctx, flag, flatCtx)
+	if detail.ResolutionError != (openfeature.ResolutionError{}) {
+		return openfeature.StringResolutionDetail{Value: defaultValue, ProviderResolutionDetail: detail}
+	}
+	v, ok := value.(string)
+	if !ok {
+		return openfeature.StringResolutionDetail{Value: defaultValue, ProviderResolutionDetail: mismatch(flag, value)}
+	}
+	return openfeature.StringResolutionDetail{Value: v, ProviderResolutionDetail: detail}
+}
+
+// FloatEvaluation implements openfeature.FeatureProvider.
+func (p *OFREPProvider) FloatEvaluation(
+	ctx context.Context, flag string, defaultValue float64, flatCtx openfeature.FlattenedContext,
+) openfeature.FloatResolutionDetail {
+	value, detail := p.evaluate(ctx, flag, flatCtx)
+	if detail.ResolutionError != (openfeature.ResolutionError{}) {
+		return openfeature.FloatResolutionDetail{Value: defaultValue, ProviderResolutionDetail: detail}
+	}
+	v, ok := value.(float64)
+	if !ok {
+		return openfeature.FloatResolutionDetail{Value: defaultValue, ProviderResolutionDetail: mismatch(flag, value)}
+	}
+	return openfeature.FloatResolutionDetail{Value: v, ProviderResolutionDetail: detail}
+}
+
+// IntEvaluation implements openfeature.FeatureProvider. JSON numbers decode as float64, so
+// whole numbers are accepted.
+func (p *OFREPProvider) IntEvaluation(
+	ctx context.Context, flag string, defaultValue int64, flatCtx openfeature.FlattenedContext,
+) openfeature.IntResolutionDetail {
+	value, detail := p.evaluate(ctx, flag, flatCtx)
+	if detail.ResolutionError != (openfeature.ResolutionError{}) {
+		return openfeature.IntResolutionDetail{Value: defaultValue, ProviderResolutionDetail: detail}
+	}
+	v, ok := value.(float64)
+	if !ok || v != float64(int64(v)) {
+		return openfeature.IntResolutionDetail{Value: defaultValue, ProviderResolutionDetail: mismatch(flag, value)}
+	}
+	return openfeature.IntResolutionDetail{Value: int64(v), ProviderResolutionDetail: detail}
+}
+
+// ObjectEvaluation implements openfeature.FeatureProvider.
+func (p *OFREPProvider) ObjectEvaluation(
+	ctx context.Context, flag string, defaultValue any, flatCtx openfeature.FlattenedContext,
+) openfeature.InterfaceResolutionDetail {
+	value, detail := p.evaluate(ctx, flag, flatCtx)
+	if detail.ResolutionError != (openfeature.ResolutionError{}) {
+		return openfeature.InterfaceResolutionDetail{Value: defaultValue, ProviderResolutionDetail: detail}
+	}
+	return openfeature.InterfaceResolutionDetail{Value: value, ProviderResolutionDetail: detail}
+}
@@ -0,0 +1,72 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/open-feature/go-sdk/openfeature"
+)
+
+func TestOFREPProvider(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer flag-token" {
+			t.Errorf("unexpected auth %q", r.Header.Get("Authorization"))
+		}
+		var body struct {
+			Context map[string]any `json:"context"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/ofrep/v1/evaluate/flags/module.sla":
+			enabled := body.Context[FlagRepoAttribute] != "org/off"
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"key": "module.sla", "value": enabled, "reason": "TARGETING_MATCH", "variant": "on",
+			})
+		case "/ofrep/v1/evaluate/flags/limit":
+			_, _ = w.Write([]byte(`{"key":"limit","value":25,"reason":"STATIC"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"key":"missing","errorCode":"FLAG_NOT_FOUND","errorDetails":"no such flag"}`))
+		}
+	}))
+	defer srv.Close()
+	provider := NewOFREPProvider(srv.URL+"/", "flag-token", &http.Client{Timeout: time.Second})
+
+	tests := []struct {
+		name       string
+		flag       string
+		repo       string
+		want       bool
+		wantReason openfeature.Reason
+		wantCode   openfeature.ErrorCode
+	}{
+		{name: "enabled", flag: "module.sla", repo: "org/on", want: true, wantReason: openfeature.TargetingMatchReason},
+		{name: "disabled", flag: "module.sla", repo: "org/off", want: false, wantReason: openfeature.TargetingMatchReason},
+		{name: "not found", flag: "missing", want: true, wantReason: openfeature.ErrorReason,
+			wantCode: openfeature.FlagNotFoundCode},
+		{name: "wrong type", flag: "limit", want: true, wantReason: openfeature.ErrorReason,
+			wantCode: openfeature.TypeMismatchCode},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := provider.BooleanEvaluation(t.Context(), tt.flag, true, openfeature.FlattenedContext{
+				openfeature.TargetingKey: tt.repo, FlagRepoAttribute: tt.repo,
+			})
+			code := got.ResolutionDetail().ErrorCode
+			if got.Value != tt.want || got.Reason != tt.wantReason || code != tt.wantCode {
+				t.Errorf("BooleanEvaluation() = %v (%s, %q), want %v (%s, %q)",
+					got.Value, got.Reason, code, tt.want, tt.wantReason, tt.wantCode)
+			}
+		})
+	}
+
+	if got := provider.IntEvaluation(t.Context(), "limit", 10, nil); got.Value != 25 {
+		t.Errorf("IntEvaluation() = %d, want 25", got.Value)
+	}
+}
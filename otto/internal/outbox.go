@@ -0,0 +1,492 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// outbox.go queues GitHub actions (comments, labels, new issues) in the database and
+// carries them out in the background with retries. Every action is kept as an audit record
+// of who asked for it and what came of it. Modules post comments and add labels through
+// it with App.PostComment, App.AddLabels and App.RemoveLabels; trusted systems through the
+// actions API.
+
+package internal
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v71/github"
+)
+
+// OutboxJobName is the scheduler name of the job that carries out queued actions.
+const OutboxJobName = "action_outbox"
+
+// ActionKind is what an action does on GitHub.
+type ActionKind string
+
+// Actions the outbox carries out.
+const (
+	ActionComment     ActionKind = "comment"      // comment Body on issue or pull request Number
+	ActionLabel       ActionKind = "label"        // add Labels to issue or pull request Number
+	ActionUnlabel     ActionKind = "unlabel"      // remove Labels from issue or pull request Number
+	ActionCreateIssue ActionKind = "create_issue" // open an issue with Title, Body and Labels
+)
+
+// ActionKinds lists the kinds of actions the outbox carries out.
+var ActionKinds = []ActionKind{ActionComment, ActionLabel, ActionUnlabel, ActionCreateIssue}
+
+// Action statuses.
+const (
+	ActionPending = "pending"
+	ActionDone    = "done"
+	ActionFailed  = "failed"
+)
+
+// maxActionBody is GitHub's limit on the length of issue and comment bodies.
+const maxActionBody = 65536
+
+// ErrIdempotencyConflict is returned when an idempotency key is reused for a different action.
+var ErrIdempotencyConflict = errors.New("idempotency key was used for a different action")
+
+// Action is a queued GitHub action and its outcome.
+type Action struct {
+	ID             int64      `json:"id"`
+	Source         string     `json:"source"` // module name, or "api:<client>"
+	IdempotencyKey string     `json:"idempotency_key,omitempty"`
+	Kind           ActionKind `json:"kind"`
+	Repo           string     `json:"repo"`
+	Number         int        `json:"number,omitempty"`
+	Title          string     `json:"title,omitempty"`
+	Body           string     `json:"body,omitempty"`
+	Labels         []string   `json:"labels,omitempty"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	Error          string     `json:"error,omitempty"`
+	URL            string     `json:"url,omitempty"` // of the comment or issue created
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// Validate reports what is missing or wrong in a requested action.
+func (a Action) Validate() error {
+	if _, _, err := SplitRepo(a.Repo); err != nil {
+		return err
+	}
+	if len(a.Body) > maxActionBody {
+		return fmt.Errorf("body is longer than %d characters", maxActionBody)
+	}
+	switch a.Kind {
+	case ActionComment:
+		if a.Number <= 0 || a.Body == "" {
+			return errors.New("a comment needs a number and a body")
+		}
+	case ActionLabel, ActionUnlabel:
+		if a.Number <= 0 || len(a.Labels) == 0 {
+			return errors.New("a label action needs a number and labels")
+		}
+	case ActionCreateIssue:
+		if a.Title == "" {
+			return errors.New("an issue needs a title")
+		}
+	default:
+		return fmt.Errorf("unknown action kind %q", a.Kind)
+	}
+	if slices.Contains(a.Labels, "") {
+		return errors.New("labels cannot be empty")
+	}
+	return nil
+}
+
+// sameRequest reports whether two actions ask for the same thing.
+func (a Action) sameRequest(b Action) bool {
+	return a.Kind == b.Kind && a.Repo == b.Repo && a.Number == b.Number && a.Title == b.Title &&
+		a.Body == b.Body && slices.Equal(a.Labels, b.Labels)
+}
+
+// Outbox reads and writes the action_outbox table and carries out queued actions.
+type Outbox struct {
+	db          *sql.DB
+	client      *github.Client
+	maxAttempts int
+	now         func() time.Time
+	mu          sync.Mutex // held while carrying out actions, so none is carried out twice
+}
+
+// NewOutbox creates the outbox, creating its table if needed. Actions that fail
+// maxAttempts times are given up.
+func NewOutbox(db *sql.DB, client *github.Client, maxAttempts int) (*Outbox, error) {
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS action_outbox (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			source TEXT NOT NULL,
+			idempotency_key TEXT,
+			kind TEXT NOT NULL,
+			repo TEXT NOT NULL,
+			number INTEGER NOT NULL DEFAULT 0,
+			title TEXT NOT NULL DEFAULT '',
+			body TEXT NOT NULL DEFAULT '',
+			labels TEXT NOT NULL DEFAULT '[]',
+			status TEXT NOT NULL,
+			attempts INTEGER NOT NULL DEFAULT 0,
+			error TEXT NOT NULL DEFAULT '',
+			url TEXT NOT NULL DEFAULT '',
+			created_at TIMESTAMP NOT NULL,
+			updated_at TIMESTAMP NOT NULL,
+			UNIQUE (source, idempotency_key)
+		);`,
+		`CREATE INDEX IF NOT EXISTS idx_action_outbox_status ON action_outbox (status, id);`,
+	}
+	for _, s := range stmts {
+		if _, err := db.Exec(s); err != nil {
+			return nil, fmt.Errorf("failed migration: %w (SQL: %s)", err, s)
+		}
+	}
+	return &Outbox{db: db, client: client, maxAttempts: maxAttempts, now: time.Now}, nil
+}
+
+const actionColumns = `id, source, COALESCE(idempotency_key, ''), kind, repo, number, title, body, labels, status,
+	attempts, error, url, created_at, updated_at`
+
+func scanAction(row interface{ Scan(...any) error }) (Action, error) {
+	var a Action
+	var labels string
+	err := row.Scan(&a.ID, &a.Source, &a.IdempotencyKey, &a.Kind, &a.Repo, &a.Number, &a.Title, &a.Body, &labels,
+		&a.Status, &a.Attempts, &a.Error, &a.URL, &a.CreatedAt, &a.UpdatedAt)
+	if err != nil {
+		return a, err
+	}
+	if err := json.Unmarshal([]byte(labels), &a.Labels); err != nil {
+		return a, fmt.Errorf("action %d: invalid labels: %w", a.ID, err)
+	}
+	return a, nil
+}
+
+// Enqueue validates an action and queues it. An action whose idempotency key its source
+// already used is not queued again: Enqueue returns the existing action and false, or
+// ErrIdempotencyConflict if the two differ.
+func (o *Outbox) Enqueue(ctx context.Context, a Action) (Action, bool, error) {
+	if err := a.Validate(); err != nil {
+		return Action{}, false, err
+	}
+	if a.IdempotencyKey != "" {
+		existing, err := o.byKey(ctx, a.Source, a.IdempotencyKey)
+		if err == nil {
+			return replayAction(existing, a)
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return Action{}, false, err
+		}
+	}
+
+	labels, err := json.Marshal(a.Labels)
+	if err != nil {
+		return Action{}, false, err
+	}
+	if a.Labels == nil {
+		labels = []byte("[]")
+	}
+	var key any
+	if a.IdempotencyKey != "" {
+		key = a.IdempotencyKey
+	}
+	now := o.now().UTC()
+	res, err := o.db.ExecContext(ctx,
+		`INSERT INTO action_outbox (source, idempotency_key, kind, repo, number, title, body, labels, status,
+			created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		a.Source, key, a.Kind, a.Repo, a.Number, a.Title, a.Body, string(labels), ActionPending, now, now)
+	if err != nil {
+		if a.IdempotencyKey != "" {
+			// A concurrent request with the same key won the race.
+			if existing, lookupErr := o.byKey(ctx, a.Source, a.IdempotencyKey); lookupErr == nil {
+				return replayAction(existing, a)
+			}
+		}
+		return Action{}, false, LogAndWrapError(err, ErrorTypeDatabase, "enqueue_action", nil)
+	}
+	a.ID, _ = res.LastInsertId()
+	a.Status, a.Attempts, a.Error, a.URL, a.CreatedAt, a.UpdatedAt = ActionPending, 0, "", "", now, now
+	slog.InfoContext(ctx, "Action queued", "id", a.ID, "source", a.Source, "kind", a.Kind, "repo", a.Repo,
+		"number", a.Number, "idempotency_key", a.IdempotencyKey)
+	return a, true, nil
+}
+
+// replayAction answers a repeated request with the action queued for its idempotency key.
+func replayAction(existing, requested Action) (Action, bool, error) {
+	if !existing.sameRequest(requested) {
+		return existing, false, ErrIdempotencyConflict
+	}
+	return existing, false, nil
+}
+
+func (o *Outbox) byKey(ctx context.Context, source, key string) (Action, error) {
+	return scanAction(o.db.QueryRowContext(ctx,
+		`SELECT `+actionColumns+` FROM action_outbox WHERE source = ? AND idempotency_key = ?`, source, key))
+}
+
+// Get returns an action by ID, or sql.ErrNoRows.
+func (o *Outbox) Get(ctx context.Context, id int64) (Action, error) {
+	return scanAction(o.db.QueryRowContext(ctx, `SELECT `+actionColumns+` FROM action_outbox WHERE id = ?`, id))
+}
+
+// List returns the most recent actions, newest first, optionally only those of one
+// source or with one status.
+func (o *Outbox) List(ctx context.Context, source, status string, limit int) ([]Action, error) {
+	return o.query(ctx, `SELECT `+actionColumns+` FROM action_outbox
+		 WHERE (? = '' OR source = ?) AND (? = '' OR status = ?) ORDER BY id DESC LIMIT ?`,
+		source, source, status, status, limit)
+}
+
+// actionListSchema describes actions to the admin API's list query layer. Failed actions,
+// status=failed, are the outbox's dead letters.
+var actionListSchema = ListSchema{
+	Fields: slices.Concat(
+		ListColumns("id", "source", "idempotency_key", "kind", "repo", "number", "title"),
+		[]ListField{{Name: "body", Column: "body", Hidden: true}, {Name: "labels"}},
+		ListColumns("status", "attempts", "error", "url", "created_at", "updated_at"),
+	),
+	Time: "created_at",
+	Sort: "-id",
+	Key:  "id",
+}
+
+// Select returns the actions matching a list query.
+func (o *Outbox) Select(ctx context.Context, q ListQuery) ([]Action, error) {
+	query, args := q.SQL(`SELECT ` + actionColumns + ` FROM action_outbox`)
+	return o.query(ctx, query, args...)
+}
+
+// pending returns the oldest pending actions.
+func (o *Outbox) pending(ctx context.Context, limit int) ([]Action, error) {
+	return o.query(ctx, `SELECT `+actionColumns+` FROM action_outbox WHERE status = ? ORDER BY id LIMIT ?`,
+		ActionPending, limit)
+}
+
+func (o *Outbox) query(ctx context.Context, query string, args ...any) ([]Action, error) {
+	rows, err := o.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, LogAndWrapError(err, ErrorTypeDatabase, "list_actions", nil)
+	}
+	defer rows.Close()
+	actions := []Action{}
+	for rows.Next() {
+		a, err := scanAction(rows)
+		if err != nil {
+			return nil, err
+		}
+		actions = append(actions, a)
+	}
+	return actions, rows.Err()
+}
+
+// Job returns the scheduler job that carries out queued actions. It is deferred while
+// GitHub is degraded.
+func (o *Outbox) Job(interval time.Duration) Job {
+	return Job{
+		Name:       OutboxJobName,
+		Interval:   interval,
+		Deferrable: true,
+		Run:        o.Process,
+	}
+}
+
+// Process carries out the pending actions in the order they were queued. Actions that
+// fail with a server error are retried on the next run until they run out of attempts;
+// actions GitHub rejects fail right away.
+func (o *Outbox) Process(ctx context.Context) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	pending, err := o.pending(ctx, 100)
+	if err != nil {
+		return err
+	}
+	for _, a := range pending {
+		if _, err := o.attempt(ctx, a); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Send queues an action like Enqueue and carries it out right away, unless its idempotency
+// key was used already. An action that fails with a server error is left to the outbox's
+// job to retry; one GitHub rejects is returned as an error.
+func (o *Outbox) Send(ctx context.Context, a Action) (Action, error) {
+	a, created, err := o.Enqueue(ctx, a)
+	if err != nil || !created {
+		return a, err
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	// The job may have carried it out in the meantime.
+	if a, err = o.Get(ctx, a.ID); err != nil || a.Status != ActionPending {
+		return a, err
+	}
+	if a, err = o.attempt(ctx, a); err != nil {
+		return a, err
+	}
+	if a.Status == ActionFailed {
+		return a, fmt.Errorf("%s action %d failed: %s", a.Kind, a.ID, a.Error)
+	}
+	return a, nil
+}
+
+// attempt carries out a pending action once and records the outcome. o.mu must be held.
+func (o *Outbox) attempt(ctx context.Context, a Action) (Action, error) {
+	url, err := o.perform(ctx, a)
+	a.Attempts++
+	switch {
+	case err == nil:
+		a.Status, a.Error, a.URL = ActionDone, "", url
+		slog.InfoContext(ctx, "Action carried out", "id", a.ID, "source", a.Source, "kind", a.Kind,
+			"repo", a.Repo, "number", a.Number, "url", url)
+	case ctx.Err() != nil:
+		return a, ctx.Err()
+	case retryableActionError(err) && a.Attempts < o.maxAttempts:
+		a.Error = err.Error()
+		slog.WarnContext(ctx, "Action failed, will retry", "id", a.ID, "source", a.Source, "kind", a.Kind,
+			"repo", a.Repo, "attempts", a.Attempts, "error", err)
+	default:
+		a.Status, a.Error = ActionFailed, err.Error()
+		slog.ErrorContext(ctx, "Action failed", "id", a.ID, "source", a.Source, "kind", a.Kind,
+			"repo", a.Repo, "attempts", a.Attempts, "error", err)
+	}
+	if _, err := o.db.ExecContext(ctx,
+		`UPDATE action_outbox SET status = ?, attempts = ?, error = ?, url = ?, updated_at = ? WHERE id = ?`,
+		a.Status, a.Attempts, a.Error, a.URL, o.now().UTC(), a.ID); err != nil {
+		return a, LogAndWrapError(err, ErrorTypeDatabase, "update_action", nil)
+	}
+	return a, nil
+}
+
+// perform carries out an action on GitHub and returns the URL of what it created.
+func (o *Outbox) perform(ctx context.Context, a Action) (string, error) {
+	if o.client == nil {
+		return "", errors.New("no GitHub client configured")
+	}
+	owner, repo, err := SplitRepo(a.Repo)
+	if err != nil {
+		return "", err
+	}
+	switch a.Kind {
+	case ActionComment:
+		comment, _, err := o.client.Issues.CreateComment(ctx, owner, repo, a.Number,
+			&github.IssueComment{Body: github.Ptr(a.Body)})
+		return comment.GetHTMLURL(), err
+	case ActionLabel:
+		_, _, err := o.client.Issues.AddLabelsToIssue(ctx, owner, repo, a.Number, a.Labels)
+		return "", err
+	case ActionUnlabel:
+		return "", removeLabels(ctx, o.client, owner, repo, a.Number, a.Labels)
+	case ActionCreateIssue:
+		req := &github.IssueRequest{Title: github.Ptr(a.Title), Body: github.Ptr(a.Body)}
+		if len(a.Labels) > 0 {
+			req.Labels = &a.Labels
+		}
+		issue, _, err := o.client.Issues.Create(ctx, owner, repo, req)
+		return issue.GetHTMLURL(), err
+	}
+	return "", fmt.Errorf("unknown action kind %q", a.Kind)
+}
+
+// removeLabels removes labels from an issue or pull request. Removing a label that is not
+// applied is not an error. go-github does not escape label names in paths.
+func removeLabels(ctx context.Context, client *github.Client, owner, repo string, number int,
+	labels []string) error {
+	for _, label := range labels {
+		resp, err := client.Issues.RemoveLabelForIssue(ctx, owner, repo, number, url.PathEscape(label))
+		if err != nil && (resp == nil || resp.StatusCode != http.StatusNotFound) {
+			return err
+		}
+	}
+	return nil
+}
+
+// retryableActionError reports whether GitHub may accept the action on a later attempt.
+func retryableActionError(err error) bool {
+	var ghErr *github.ErrorResponse
+	if errors.As(err, &ghErr) && ghErr.Response != nil {
+		status := ghErr.Response.StatusCode
+		return status >= http.StatusInternalServerError || status == http.StatusTooManyRequests
+	}
+	// Network errors and rate limits.
+	return true
+}
+
+// RegisterAdminRoutes serves the audit log of queued actions on the admin API.
+func (o *Outbox) RegisterAdminRoutes(srv *Server) {
+	if o == nil || srv == nil {
+		return
+	}
+	srv.HandleAdmin("GET /admin/actions", ListHandler(actionListSchema, o.Select))
+}
+
+// PostComment posts a comment by module on an issue or pull request through the outbox,
+// which retries it in the background if GitHub fails to take it. When ctx is that of a
+// module handling a webhook delivery, the comment is keyed to the delivery, so a retry of
+// the delivery does not post it twice. Without an outbox, and for modules in shadow mode,
+// whose writes are only recorded, the comment is posted directly.
+func (a *App) PostComment(ctx context.Context, module, repo string, number int, body string) error {
+	if a.Outbox == nil || a.shadowModule != "" {
+		return PostComment(ctx, a.GitHubClient, repo, number, body)
+	}
+	return a.sendAction(ctx, Action{Source: module, Kind: ActionComment, Repo: repo, Number: number, Body: body})
+}
+
+// AddLabels adds labels by module to an issue or pull request through the outbox, like
+// PostComment.
+func (a *App) AddLabels(ctx context.Context, module, repo string, number int, labels []string) error {
+	if a.Outbox == nil || a.shadowModule != "" {
+		owner, name, err := SplitRepo(repo)
+		if err != nil {
+			return err
+		}
+		if _, _, err := a.GitHubClient.Issues.AddLabelsToIssue(ctx, owner, name, number, labels); err != nil {
+			return fmt.Errorf("failed to add labels: %w", err)
+		}
+		return nil
+	}
+	return a.sendAction(ctx, Action{Source: module, Kind: ActionLabel, Repo: repo, Number: number, Labels: labels})
+}
+
+// RemoveLabels removes labels by module from an issue or pull request through the outbox,
+// like PostComment. Removing a label that is not applied is not an error.
+func (a *App) RemoveLabels(ctx context.Context, module, repo string, number int, labels []string) error {
+	if a.Outbox == nil || a.shadowModule != "" {
+		owner, name, err := SplitRepo(repo)
+		if err != nil {
+			return err
+		}
+		if err := removeLabels(ctx, a.GitHubClient, owner, name, number, labels); err != nil {
+			return fmt.Errorf("failed to remove labels: %w", err)
+		}
+		return nil
+	}
+	return a.sendAction(ctx, Action{Source: module, Kind: ActionUnlabel, Repo: repo, Number: number, Labels: labels})
+}
+
+// sendAction sends an action of a module through the outbox, keyed to the webhook delivery
+// the module is handling, if any.
+func (a *App) sendAction(ctx context.Context, action Action) error {
+	if delivery := deliveryFromContext(ctx); delivery != "" {
+		action.IdempotencyKey = moduleActionKey(delivery, action)
+	}
+	_, err := a.Outbox.Send(ctx, action)
+	return err
+}
+
+// moduleActionKey returns the idempotency key of a module's action while handling a
+// delivery: the delivery, and what the action does, so that its distinct actions differ.
+func moduleActionKey(delivery string, a Action) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%s\x00%d\x00%s\x00%s", a.Kind, a.Repo, a.Number, a.Body,
+		strings.Join(a.Labels, "\x00"))))
+	return delivery + "/" + hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,258 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestActionValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		action  Action
+		wantErr string
+	}{
+		{"comment", Action{Kind: ActionComment, Repo: "org/a", Number: 1, Body: "hi"}, ""},
+		{"label", Action{Kind: ActionLabel, Repo: "org/a", Number: 1, Labels: []string{"release"}}, ""},
+		{"unlabel", Action{Kind: ActionUnlabel, Repo: "org/a", Number: 1, Labels: []string{"stale"}}, ""},
+		{"issue", Action{Kind: ActionCreateIssue, Repo: "org/a", Title: "Release v1.2.0"}, ""},
+		{"bad repo", Action{Kind: ActionComment, Repo: "a", Number: 1, Body: "hi"}, "invalid repository"},
+		{"comment without number", Action{Kind: ActionComment, Repo: "org/a", Body: "hi"}, "needs a number"},
+		{"no labels", Action{Kind: ActionLabel, Repo: "org/a", Number: 1}, "needs a number and labels"},
+		{"empty label", Action{Kind: ActionLabel, Repo: "org/a", Number: 1, Labels: []string{""}}, "cannot be empty"},
+		{"issue without title", Action{Kind: ActionCreateIssue, Repo: "org/a"}, "needs a title"},
+		{"long body", Action{Kind: ActionCreateIssue, Repo: "org/a", Title: "t", Body: strings.Repeat("x", 70000)},
+			"longer than"},
+		{"unknown", Action{Kind: "merge", Repo: "org/a", Number: 1}, "unknown action kind"},
+	}
+	for _, tt := range tests {
+		err := tt.action.Validate()
+		if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("%s: Validate = %v, want %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestOutboxEnqueueIdempotency(t *testing.T) {
+	outbox, err := NewOutbox(TestDB(t), nil, 3)
+	if err != nil {
+		t.Fatalf("NewOutbox failed: %v", err)
+	}
+	ctx := t.Context()
+	action := Action{Source: "api:release", IdempotencyKey: "k1", Kind: ActionComment, Repo: "org/a", Number: 1,
+		Body: "released"}
+	first, created, err := outbox.Enqueue(ctx, action)
+	if err != nil || !created || first.Status != ActionPending {
+		t.Fatalf("Enqueue = %+v, %v, %v", first, created, err)
+	}
+	again, created, err := outbox.Enqueue(ctx, action)
+	if err != nil || created || again.ID != first.ID {
+		t.Errorf("repeated Enqueue = %+v, %v, %v, want action %d", again, created, err, first.ID)
+	}
+	changed := action
+	changed.Body = "something else"
+	if _, _, err := outbox.Enqueue(ctx, changed); !errors.Is(err, ErrIdempotencyConflict) {
+		t.Errorf("Enqueue with a reused key = %v, want ErrIdempotencyConflict", err)
+	}
+	// Keys are scoped to their source, and actions without a key are always queued.
+	other := action
+	other.Source = "api:dashboard"
+	if _, created, err := outbox.Enqueue(ctx, other); err != nil || !created {
+		t.Errorf("Enqueue from another source = %v, %v", created, err)
+	}
+	module := Action{Source: "sla", Kind: ActionLabel, Repo: "org/a", Number: 2, Labels: []string{"stale"}}
+	for range 2 {
+		if _, created, err := outbox.Enqueue(ctx, module); err != nil || !created {
+			t.Errorf("Enqueue without a key = %v, %v", created, err)
+		}
+	}
+	if actions, err := outbox.List(ctx, "sla", "", 10); err != nil || len(actions) != 2 {
+		t.Errorf("List = %+v, %v", actions, err)
+	}
+}
+
+func TestOutboxProcess(t *testing.T) {
+	var mu sync.Mutex
+	var requests []string
+	failures := map[string]int{"/repos/org/flaky/issues/3/comments": 1}
+	client := TestGitHubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		if failures[r.URL.Path] > 0 {
+			failures[r.URL.Path]--
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		switch r.URL.Path {
+		case "/repos/org/a/issues/1/comments", "/repos/org/flaky/issues/3/comments":
+			_ = json.NewEncoder(w).Encode(map[string]any{"html_url": "https://github.com/org/a/issues/1#c1"})
+		case "/repos/org/a/issues":
+			_ = json.NewEncoder(w).Encode(map[string]any{"html_url": "https://github.com/org/a/issues/9"})
+		case "/repos/org/a/issues/2/labels":
+			_, _ = w.Write([]byte(`[]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message": "Not Found"}`))
+		}
+	}))
+	outbox, err := NewOutbox(TestDB(t), client, 3)
+	if err != nil {
+		t.Fatalf("NewOutbox failed: %v", err)
+	}
+	ctx := t.Context()
+	for _, a := range []Action{
+		{Source: "api:release", Kind: ActionComment, Repo: "org/a", Number: 1, Body: "released"},
+		{Source: "api:release", Kind: ActionCreateIssue, Repo: "org/a", Title: "Release", Labels: []string{"release"}},
+		{Source: "sla", Kind: ActionLabel, Repo: "org/a", Number: 2, Labels: []string{"stale"}},
+		{Source: "api:release", Kind: ActionComment, Repo: "org/flaky", Number: 3, Body: "retry me"},
+		{Source: "api:release", Kind: ActionComment, Repo: "org/missing", Number: 4, Body: "gone"},
+	} {
+		if _, _, err := outbox.Enqueue(ctx, a); err != nil {
+			t.Fatalf("Enqueue failed: %v", err)
+		}
+	}
+
+	if err := outbox.Job(0).Run(ctx); err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	want := map[int64]struct {
+		status, url string
+		attempts    int
+	}{
+		1: {ActionDone, "https://github.com/org/a/issues/1#c1", 1},
+		2: {ActionDone, "https://github.com/org/a/issues/9", 1},
+		3: {ActionDone, "", 1},
+		4: {ActionPending, "", 1}, // a server error is retried
+		5: {ActionFailed, "", 1},  // a missing repository is not
+	}
+	for id, w := range want {
+		a, err := outbox.Get(ctx, id)
+		if err != nil || a.Status != w.status || a.URL != w.url || a.Attempts != w.attempts {
+			t.Errorf("action %d = %+v, %v, want %+v", id, a, err, w)
+		}
+	}
+
+	if err := outbox.Process(ctx); err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if a, _ := outbox.Get(ctx, 4); a.Status != ActionDone || a.Attempts != 2 || a.Error != "" {
+		t.Errorf("retried action = %+v", a)
+	}
+	if len(requests) != 6 {
+		t.Errorf("requests = %v, want each action once and one retry", requests)
+	}
+}
+
+func TestModuleActions(t *testing.T) {
+	var mu sync.Mutex
+	var requests []string
+	failures := map[string]int{"/repos/org/flaky/issues/3/comments": 1}
+	client := TestGitHubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		if failures[r.URL.Path] > 0 {
+			failures[r.URL.Path]--
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		switch r.URL.Path {
+		case "/repos/org/a/issues/1/comments", "/repos/org/flaky/issues/3/comments":
+			_ = json.NewEncoder(w).Encode(map[string]any{"html_url": "https://github.com/org/a/issues/1#c1"})
+		case "/repos/org/a/issues/2/labels":
+			_, _ = w.Write([]byte(`[]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message": "Not Found"}`))
+		}
+	}))
+	outbox, err := NewOutbox(TestDB(t), client, 3)
+	if err != nil {
+		t.Fatalf("NewOutbox failed: %v", err)
+	}
+	app := &App{GitHubClient: client, Outbox: outbox}
+
+	// Writes while handling a delivery are keyed to it, so a retry does not repeat them.
+	ctx := WithDecisionScope(t.Context(), nil, "sla", "issues", "d1", "org/a")
+	for range 2 {
+		if err := app.PostComment(ctx, "sla", "org/a", 1, "Still waiting"); err != nil {
+			t.Fatalf("PostComment failed: %v", err)
+		}
+	}
+	if err := app.AddLabels(ctx, "sla", "org/a", 2, []string{"stale"}); err != nil {
+		t.Fatalf("AddLabels failed: %v", err)
+	}
+	// Removing a label that is not applied is not an error.
+	if err := app.RemoveLabels(ctx, "sla", "org/a", 2, []string{"needs/info"}); err != nil {
+		t.Fatalf("RemoveLabels failed: %v", err)
+	}
+	// A server error leaves the action to the job; a rejected one is returned.
+	if err := app.PostComment(t.Context(), "sla", "org/flaky", 3, "retry me"); err != nil {
+		t.Fatalf("PostComment of a retried comment failed: %v", err)
+	}
+	if err := app.PostComment(t.Context(), "sla", "org/missing", 4, "gone"); err == nil {
+		t.Error("PostComment to a missing repository succeeded")
+	}
+	if err := outbox.Process(t.Context()); err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	actions, err := outbox.List(t.Context(), "sla", "", 10)
+	if err != nil || len(actions) != 5 {
+		t.Fatalf("List = %+v, %v; want 5 actions", actions, err)
+	}
+	var statuses []string
+	for _, a := range actions {
+		statuses = append(statuses, string(a.Kind)+" "+a.Repo+" "+a.Status)
+	}
+	want := []string{
+		"comment org/missing failed", "comment org/flaky done", "unlabel org/a done", "label org/a done",
+		"comment org/a done",
+	}
+	if !slices.Equal(statuses, want) || !strings.HasPrefix(actions[4].IdempotencyKey, "d1/") {
+		t.Errorf("actions = %v (key %q), want %v", statuses, actions[4].IdempotencyKey, want)
+	}
+
+	// Writes of modules in shadow mode go to their recording client, not the outbox.
+	shadowed := &App{GitHubClient: client, Outbox: outbox, shadowModule: "triage"}
+	if err := shadowed.PostComment(t.Context(), "triage", "org/a", 1, "Triaged"); err != nil {
+		t.Fatalf("PostComment failed: %v", err)
+	}
+	if actions, _ := outbox.List(t.Context(), "triage", "", 10); len(actions) != 0 {
+		t.Errorf("a shadowed module's comment was queued: %+v", actions)
+	}
+	if len(requests) != 7 {
+		t.Errorf("requests = %v, want each action once, one retry and the shadowed comment", requests)
+	}
+}
+
+func TestOutboxAdminList(t *testing.T) {
+	outbox, err := NewOutbox(TestDB(t), nil, 3)
+	if err != nil {
+		t.Fatalf("NewOutbox failed: %v", err)
+	}
+	for _, source := range []string{"api:release", "sla", "api:release"} {
+		a := Action{Source: source, Kind: ActionCreateIssue, Repo: "org/a", Title: source}
+		if _, _, err := outbox.Enqueue(t.Context(), a); err != nil {
+			t.Fatalf("Enqueue failed: %v", err)
+		}
+	}
+	list := ListHandler(actionListSchema, outbox.Select)
+	for query, want := range map[string]int{
+		"": 3, "?source=api:release": 2, "?status=done": 0, "?status=pending,failed": 3, "?limit=1": 1,
+	} {
+		rr := httptest.NewRecorder()
+		list(rr, httptest.NewRequest(http.MethodGet, "/admin/actions"+query, nil))
+		var actions []Action
+		if err := json.NewDecoder(rr.Body).Decode(&actions); err != nil || len(actions) != want {
+			t.Errorf("%q: got %d actions (%v), want %d", query, len(actions), err, want)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// pacing.go spreads GitHub writes out over time. A burst of mutations, such as a label
+// sync touching hundreds of repositories, trips GitHub's secondary rate limit, which
+// locks out every write for minutes, including replies to slash commands.
+
+package internal
+
+import (
+	"context"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// MutationPacer limits GitHub writes to a steady rate, allowing a short burst. Writes made
+// for a module's background work (contexts set with WithModule) wait for their turn, with
+// jitter so that waiting callers do not wake up together. Other writes, such as those
+// answering events and commands, are never delayed but use up turns like any other.
+type MutationPacer struct {
+	mu       sync.Mutex
+	interval time.Duration // time between writes at the paced rate
+	burst    int
+	jitter   time.Duration
+	next     time.Time // when a write would be due if no burst were allowed
+
+	now   func() time.Time
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// NewMutationPacer creates a pacer allowing perMinute writes a minute, bursts of up to
+// burst writes, and up to jitter of extra random delay for writes that have to wait.
+func NewMutationPacer(perMinute, burst int, jitter time.Duration) *MutationPacer {
+	return &MutationPacer{
+		interval: time.Minute / time.Duration(max(perMinute, 1)),
+		burst:    max(burst, 1),
+		jitter:   jitter,
+		now:      time.Now,
+		sleep:    sleepContext,
+	}
+}
+
+// reserve takes the next turn and returns how long until it comes.
+func (p *MutationPacer) reserve() time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.now()
+	if p.next.Before(now) {
+		p.next = now
+	}
+	wait := p.next.Sub(now) - time.Duration(p.burst-1)*p.interval
+	p.next = p.next.Add(p.interval)
+	return max(wait, 0)
+}
+
+// Wait takes a turn for a write, blocking until it comes if background is set. It returns
+// early with the context's error if ctx is done first.
+func (p *MutationPacer) Wait(ctx context.Context, background bool) error {
+	wait := p.reserve()
+	if !background || wait == 0 {
+		return nil
+	}
+	if p.jitter > 0 {
+		wait += rand.N(p.jitter)
+	}
+	slog.DebugContext(ctx, "Pacing GitHub write", "module", ModuleFromContext(ctx), "wait", wait)
+	return p.sleep(ctx, wait)
+}
+
+// sleepContext waits for d, or until ctx is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Transport wraps base so writes are paced. A nil pacer returns base unchanged.
+func (p *MutationPacer) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if p == nil {
+		return base
+	}
+	return &pacingTransport{pacer: p, base: base}
+}
+
+type pacingTransport struct {
+	pacer *MutationPacer
+	base  http.RoundTripper
+}
+
+func (t *pacingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	_, write, err := githubWrite(req)
+	if err != nil {
+		return nil, err
+	}
+	if write {
+		if err := t.pacer.Wait(req.Context(), ModuleFromContext(req.Context()) != ""); err != nil {
+			return nil, err
+		}
+	}
+	return t.base.RoundTrip(req)
+}
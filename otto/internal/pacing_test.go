@@ -0,0 +1,105 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMutationPacer(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	}))
+	defer server.Close()
+
+	pacer := NewMutationPacer(60, 2, 0)
+	now := time.Date(2025, 5, 1, 10, 0, 0, 0, time.UTC)
+	var waits []time.Duration
+	pacer.now = func() time.Time { return now }
+	pacer.sleep = func(_ context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return nil
+	}
+	client := &http.Client{Transport: pacer.Transport(nil)}
+	do := func(ctx context.Context, method, body string) {
+		t.Helper()
+		req, _ := http.NewRequestWithContext(ctx, method, server.URL+"/graphql", strings.NewReader(body))
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("%s failed: %v", method, err)
+		}
+		resp.Body.Close()
+	}
+
+	// The burst goes through at once; then background writes wait a second each.
+	job := WithModule(t.Context(), "labels")
+	for range 4 {
+		do(job, http.MethodPost, `{"query": "mutation { x }"}`)
+	}
+	if want := []time.Duration{time.Second, 2 * time.Second}; len(waits) != 2 || waits[0] != want[0] ||
+		waits[1] != want[1] {
+		t.Fatalf("waits = %v, want %v", waits, want)
+	}
+
+	// Reads are not paced, and writes for events use up turns without waiting.
+	waits = nil
+	do(job, http.MethodGet, "")
+	do(job, http.MethodPost, `{"query": "query { viewer { login } }"}`)
+	do(t.Context(), http.MethodPost, `{"query": "mutation { y }"}`)
+	if len(waits) != 0 {
+		t.Errorf("unexpected waits %v", waits)
+	}
+	do(job, http.MethodPost, `{"query": "mutation { z }"}`)
+	if len(waits) != 1 || waits[0] != 4*time.Second {
+		t.Errorf("waits = %v, want [4s]", waits)
+	}
+
+	// Turns not taken while idle do not pile up beyond the burst.
+	now = now.Add(time.Hour)
+	waits = nil
+	for range 3 {
+		do(job, http.MethodPost, `{"query": "mutation { x }"}`)
+	}
+	if len(waits) != 1 || waits[0] != time.Second {
+		t.Errorf("waits after idle = %v, want [1s]", waits)
+	}
+	if calls != 11 {
+		t.Errorf("server saw %d calls, want 11", calls)
+	}
+}
+
+func TestMutationPacerJitterAndCancel(t *testing.T) {
+	pacer := NewMutationPacer(60, 1, 500*time.Millisecond)
+	now := time.Now()
+	pacer.now = func() time.Time { return now }
+	var wait time.Duration
+	pacer.sleep = func(_ context.Context, d time.Duration) error {
+		wait = d
+		return nil
+	}
+	ctx := WithModule(t.Context(), "labels")
+	if err := pacer.Wait(ctx, true); err != nil || wait != 0 {
+		t.Fatalf("first write waited %v (%v)", wait, err)
+	}
+	if err := pacer.Wait(ctx, true); err != nil || wait < time.Second || wait >= 1500*time.Millisecond {
+		t.Errorf("second write waited %v (%v), want 1s plus jitter", wait, err)
+	}
+
+	pacer.sleep = sleepContext
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := pacer.Wait(cancelled, true); err != context.Canceled {
+		t.Errorf("Wait with a cancelled context = %v, want context.Canceled", err)
+	}
+
+	var nilPacer *MutationPacer
+	if nilPacer.Transport(http.DefaultTransport) != http.DefaultTransport {
+		t.Error("nil pacer wrapped the transport")
+	}
+}
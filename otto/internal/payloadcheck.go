@@ -0,0 +1,153 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// payloadcheck.go compares webhook payloads with the go-github structs they are parsed
+// into. Fields GitHub sends that the structs do not know are dropped silently, so reporting
+// them gives early warning that the vendored go-github version is falling behind the API.
+
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// unmarshalerType is implemented by types that decode their own JSON, e.g. github.Timestamp.
+var unmarshalerType = reflect.TypeFor[json.Unmarshaler]()
+
+// PayloadChecker reports payload fields that go-github does not parse. Each field is
+// logged once per event type; every occurrence is counted in metrics.
+type PayloadChecker struct {
+	telemetry *TelemetryManager
+
+	mu     sync.Mutex
+	logged map[string]bool // event type + field path
+}
+
+// NewPayloadChecker creates a checker that records unknown fields in telemetry.
+func NewPayloadChecker(telemetry *TelemetryManager) *PayloadChecker {
+	return &PayloadChecker{telemetry: telemetry, logged: make(map[string]bool)}
+}
+
+// Check compares raw with the parsed event and reports the fields it does not know,
+// returning their paths. A nil checker checks nothing.
+func (c *PayloadChecker) Check(ctx context.Context, eventType string, raw []byte, event any) []string {
+	if c == nil {
+		return nil
+	}
+	var payload any
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return nil
+	}
+	fields := unknownFields(payload, reflect.TypeOf(event), "")
+	slices.Sort(fields)
+	fields = slices.Compact(fields)
+	for _, field := range fields {
+		if c.telemetry != nil {
+			c.telemetry.IncWebhookUnknownField(ctx, eventType, field)
+		}
+		c.mu.Lock()
+		first := !c.logged[eventType+" "+field]
+		c.logged[eventType+" "+field] = true
+		c.mu.Unlock()
+		if first {
+			slog.WarnContext(ctx, "Webhook payload has a field go-github does not parse; go-github may be outdated",
+				"type", eventType, "field", field)
+		}
+	}
+	return fields
+}
+
+// unknownFields returns the paths of object keys in value that t has no field for, e.g.
+// pull_request.head.new_field. Array elements share their array's path.
+func unknownFields(value any, t reflect.Type, path string) []string {
+	if t == nil {
+		return nil
+	}
+	for t.Kind() == reflect.Pointer {
+		if t.Implements(unmarshalerType) {
+			return nil
+		}
+		t = t.Elem()
+	}
+	if t.Implements(unmarshalerType) || reflect.PointerTo(t).Implements(unmarshalerType) {
+		return nil
+	}
+	var unknown []string
+	switch t.Kind() {
+	case reflect.Struct:
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		fields := jsonFields(t)
+		for key, v := range object {
+			child := key
+			if path != "" {
+				child = path + "." + key
+			}
+			field, ok := fields[key]
+			if !ok {
+				field, ok = fields[strings.ToLower(key)]
+			}
+			if !ok {
+				unknown = append(unknown, child)
+				continue
+			}
+			unknown = append(unknown, unknownFields(v, field, child)...)
+		}
+	case reflect.Slice, reflect.Array:
+		items, _ := value.([]any)
+		for _, item := range items {
+			unknown = append(unknown, unknownFields(item, t.Elem(), path)...)
+		}
+	case reflect.Map:
+		object, _ := value.(map[string]any)
+		for key, v := range object {
+			unknown = append(unknown, unknownFields(v, t.Elem(), path+"."+key)...)
+		}
+	}
+	return unknown
+}
+
+// jsonFields maps the JSON names of t's fields, and their lowercase forms since
+// encoding/json matches names case-insensitively, to the fields' types. Fields of
+// untagged embedded structs are promoted like encoding/json does.
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for i := range t.NumField() {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" {
+			embedded := f.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				for k, v := range jsonFields(embedded) {
+					if _, ok := fields[k]; !ok {
+						fields[k] = v
+					}
+				}
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = f.Type
+		fields[strings.ToLower(name)] = f.Type
+	}
+	return fields
+}
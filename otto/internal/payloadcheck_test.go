@@ -0,0 +1,103 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/google/go-github/v71/github"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestPayloadCheckerCheck(t *testing.T) {
+	tests := []struct {
+		name      string
+		eventType string
+		payload   string
+		want      []string
+	}{
+		{
+			name:      "known fields",
+			eventType: "issue_comment",
+			payload: `{"action":"created","issue":{"number":1,"title":"t","labels":[{"name":"bug"}]},
+				"comment":{"body":"hi","created_at":"2024-01-01T00:00:00Z","user":{"login":"alice"}}}`,
+		},
+		{
+			name:      "unknown top-level field",
+			eventType: "issues",
+			payload:   `{"action":"opened","issue":{"number":1},"brand_new":true}`,
+			want:      []string{"brand_new"},
+		},
+		{
+			name:      "unknown nested fields",
+			eventType: "pull_request",
+			payload: `{"action":"opened","pull_request":{"number":2,"head":{"ref":"x","renamed_sha":"abc"},
+				"labels":[{"name":"a","color_v2":"red"},{"name":"b","color_v2":"blue"}]}}`,
+			want: []string{"pull_request.head.renamed_sha", "pull_request.labels.color_v2"},
+		},
+		{
+			name:      "field names match case-insensitively",
+			eventType: "issues",
+			payload:   `{"Action":"opened","issue":{"Number":1}}`,
+		},
+		{
+			name:      "free-form maps",
+			eventType: "repository_dispatch",
+			payload:   `{"action":"run","client_payload":{"anything":{"goes":1}}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event, err := github.ParseWebHook(tt.eventType, []byte(tt.payload))
+			if err != nil {
+				t.Fatalf("ParseWebHook() error = %v", err)
+			}
+			checker := NewPayloadChecker(nil)
+			if got := checker.Check(t.Context(), tt.eventType, []byte(tt.payload), event); !slices.Equal(got, tt.want) {
+				t.Errorf("Check() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPayloadCheckerMetrics(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	checker := NewPayloadChecker(TestTelemetry(t, reader))
+	payload := []byte(`{"action":"opened","issue":{"number":1,"brand_new":true}}`)
+	event, err := github.ParseWebHook("issues", payload)
+	if err != nil {
+		t.Fatalf("ParseWebHook() error = %v", err)
+	}
+	checker.Check(t.Context(), "issues", payload, event)
+	checker.Check(t.Context(), "issues", payload, event)
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(t.Context(), &rm); err != nil {
+		t.Fatalf("failed to collect metrics: %v", err)
+	}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != "otto.webhook.unknown_fields_total" {
+				continue
+			}
+			sum, ok := m.Data.(metricdata.Sum[int64])
+			if !ok || len(sum.DataPoints) != 1 || sum.DataPoints[0].Value != 2 {
+				t.Fatalf("unexpected unknown fields metric: %#v", m.Data)
+			}
+			if v, _ := sum.DataPoints[0].Attributes.Value("field"); v.AsString() != "issue.brand_new" {
+				t.Errorf("field attribute = %q, want issue.brand_new", v.AsString())
+			}
+			return
+		}
+	}
+	t.Error("unknown fields metric was not recorded")
+}
+
+func TestPayloadCheckerNil(t *testing.T) {
+	var checker *PayloadChecker
+	if got := checker.Check(t.Context(), "issues", []byte(`{"brand_new":1}`), &github.IssuesEvent{}); got != nil {
+		t.Errorf("nil checker reported %v", got)
+	}
+}
@@ -0,0 +1,198 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// payloadstore.go keeps large webhook payloads in an S3 or GCS bucket instead of the
+// events table. Requests are signed with AWS Signature Version 4, which GCS also accepts
+// with HMAC keys on its XML API, so a minimal client serves both backends.
+
+package internal
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+)
+
+// Secrets holding the credentials of the event payload bucket.
+const (
+	PayloadAccessKeySecret = "event_payloads_access_key_id"
+	PayloadSecretKeySecret = "event_payloads_secret_access_key"
+)
+
+// ErrPayloadNotFound is returned by PayloadStore.Get for a key that holds no payload.
+var ErrPayloadNotFound = errors.New("payload not found")
+
+// PayloadStore stores event payloads by key. Implementations are safe for concurrent use.
+type PayloadStore interface {
+	// Put stores data under key, replacing what it held.
+	Put(ctx context.Context, key string, data []byte) error
+	// Get returns the data stored under key, or ErrPayloadNotFound.
+	Get(ctx context.Context, key string) ([]byte, error)
+}
+
+// NewPayloadStore creates the configured payload store, or returns nil if payloads stay in
+// the database.
+func NewPayloadStore(cfg config.EventPayloadsConfig, accessKey, secretKey string, client *http.Client) (
+	PayloadStore, error,
+) {
+	switch cfg.Backend {
+	case "":
+		return nil, nil
+	case "s3", "gcs":
+		if accessKey == "" || secretKey == "" {
+			return nil, fmt.Errorf("event_payloads: the %s and %s secrets are required for the %s backend",
+				PayloadAccessKeySecret, PayloadSecretKeySecret, cfg.Backend)
+		}
+		endpoint, err := url.Parse(cfg.Endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("event_payloads: invalid endpoint: %w", err)
+		}
+		return &ObjectStore{
+			cfg:       cfg,
+			endpoint:  endpoint,
+			accessKey: accessKey,
+			secretKey: secretKey,
+			client:    client,
+			now:       time.Now,
+		}, nil
+	default:
+		return nil, fmt.Errorf("event_payloads: unsupported backend %q", cfg.Backend)
+	}
+}
+
+// ObjectStore is a PayloadStore on an S3-compatible bucket, addressed path-style.
+type ObjectStore struct {
+	cfg       config.EventPayloadsConfig
+	endpoint  *url.URL
+	accessKey string
+	secretKey string
+	client    *http.Client
+	now       func() time.Time
+}
+
+// Put implements PayloadStore.
+func (s *ObjectStore) Put(ctx context.Context, key string, data []byte) error {
+	resp, err := s.do(ctx, http.MethodPut, key, data)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return s.check(resp, http.MethodPut, key)
+}
+
+// Get implements PayloadStore.
+func (s *ObjectStore) Get(ctx context.Context, key string) ([]byte, error) {
+	resp, err := s.do(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%s/%s: %w", s.cfg.Bucket, s.cfg.Prefix+key, ErrPayloadNotFound)
+	}
+	if err := s.check(resp, http.MethodGet, key); err != nil {
+		return nil, err
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// check returns an error for a response that is not a success, with the start of the
+// error document the store sent.
+func (s *ObjectStore) check(resp *http.Response, method, key string) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("%s %s/%s: %s: %s", method, s.cfg.Bucket, s.cfg.Prefix+key, resp.Status,
+		strings.TrimSpace(string(body)))
+}
+
+// do sends a signed request for the object holding key. The client's timeout bounds it,
+// including reading the response.
+func (s *ObjectStore) do(ctx context.Context, method, key string, body []byte) (*http.Response, error) {
+	u := *s.endpoint
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + s.cfg.Bucket + "/" + s.cfg.Prefix + key
+	u.RawPath = uriEncodePath(u.Path)
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	s.sign(req, body)
+	return s.client.Do(req)
+}
+
+// sign adds the Signature Version 4 headers to a request without a query string.
+func (s *ObjectStore) sign(req *http.Request, body []byte) {
+	now := s.now().UTC()
+	stamp, date := now.Format("20060102T150405Z"), now.Format("20060102")
+	payloadHash := sha256Hex(body)
+	req.Header.Set("X-Amz-Date", stamp)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	const signedHeaders = "host;x-amz-content-sha256;x-amz-date"
+	canonical := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		"", // query string
+		"host:" + req.URL.Host,
+		"x-amz-content-sha256:" + payloadHash,
+		"x-amz-date:" + stamp,
+		"",
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := date + "/" + s.cfg.Region + "/s3/aws4_request"
+	toSign := "AWS4-HMAC-SHA256\n" + stamp + "\n" + scope + "\n" + sha256Hex([]byte(canonical))
+	signature := hex.EncodeToString(hmacSHA256(sigV4Key(s.secretKey, date, s.cfg.Region, "s3"), toSign))
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.accessKey, scope, signedHeaders, signature))
+}
+
+// sigV4Key derives the Signature Version 4 signing key of a day, region and service.
+func sigV4Key(secretKey, date, region, service string) []byte {
+	key := hmacSHA256([]byte("AWS4"+secretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	return hmacSHA256(key, "aws4_request")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// uriEncodePath percent-encodes every byte of a path except unreserved characters and
+// slashes, as Signature Version 4 expects of object keys.
+func uriEncodePath(p string) string {
+	var b strings.Builder
+	for i := 0; i < len(p); i++ {
+		c := p[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~', c == '/':
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
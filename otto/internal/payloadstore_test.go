@@ -0,0 +1,107 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+)
+
+// fakeBucket serves the objects of an S3-compatible bucket from memory.
+type fakeBucket struct {
+	mu      sync.Mutex
+	objects map[string][]byte // escaped path -> data
+	auth    []string
+}
+
+func (b *fakeBucket) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.auth = append(b.auth, r.Header.Get("Authorization"))
+	switch r.Method {
+	case http.MethodPut:
+		data, _ := io.ReadAll(r.Body)
+		if r.Header.Get("X-Amz-Content-Sha256") != sha256Hex(data) {
+			http.Error(w, "<Error><Code>XAmzContentSHA256Mismatch</Code></Error>", http.StatusBadRequest)
+			return
+		}
+		b.objects[r.URL.EscapedPath()] = data
+	case http.MethodGet:
+		data, ok := b.objects[r.URL.EscapedPath()]
+		if !ok {
+			http.Error(w, "<Error><Code>NoSuchKey</Code></Error>", http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(data)
+	}
+}
+
+// newTestObjectStore returns an object store on a fake bucket.
+func newTestObjectStore(t *testing.T) (*ObjectStore, *fakeBucket) {
+	t.Helper()
+	bucket := &fakeBucket{objects: map[string][]byte{}}
+	server := httptest.NewServer(bucket)
+	t.Cleanup(server.Close)
+	cfg := config.EventPayloadsConfig{
+		Backend:  "s3",
+		Bucket:   "otto-events",
+		Prefix:   "prod/",
+		Region:   "eu-west-1",
+		Endpoint: server.URL,
+	}
+	store, err := NewPayloadStore(cfg, "AKIDEXAMPLE", "secret", server.Client())
+	if err != nil {
+		t.Fatalf("NewPayloadStore failed: %v", err)
+	}
+	objects := store.(*ObjectStore)
+	objects.now = func() time.Time { return time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC) }
+	return objects, bucket
+}
+
+func TestObjectStore(t *testing.T) {
+	store, bucket := newTestObjectStore(t)
+	data := []byte(`{"action":"opened"}`)
+	if err := store.Put(t.Context(), "2025/05/01/issues 1.json", data); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	if _, ok := bucket.objects["/otto-events/prod/2025/05/01/issues%201.json"]; !ok {
+		t.Fatalf("objects = %v", bucket.objects)
+	}
+	got, err := store.Get(t.Context(), "2025/05/01/issues 1.json")
+	if err != nil || string(got) != string(data) {
+		t.Fatalf("Get = %q, %v", got, err)
+	}
+	wantAuth := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20250501/eu-west-1/s3/aws4_request, " +
+		"SignedHeaders=host;x-amz-content-sha256;x-amz-date, Signature="
+	if !strings.HasPrefix(bucket.auth[0], wantAuth) || bucket.auth[0] == bucket.auth[1] {
+		t.Errorf("Authorization = %q", bucket.auth)
+	}
+
+	if _, err := store.Get(t.Context(), "missing.json"); !errors.Is(err, ErrPayloadNotFound) {
+		t.Errorf("Get(missing) error = %v, want ErrPayloadNotFound", err)
+	}
+	if _, err := NewPayloadStore(config.EventPayloadsConfig{Backend: "gcs"}, "", "", nil); err == nil {
+		t.Error("NewPayloadStore accepted missing credentials")
+	}
+	if store, err := NewPayloadStore(config.EventPayloadsConfig{}, "", "", nil); store != nil || err != nil {
+		t.Errorf("NewPayloadStore() = %v, %v; want no store", store, err)
+	}
+}
+
+func TestSigV4Key(t *testing.T) {
+	// The example of the Signature Version 4 documentation.
+	key := sigV4Key("wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", "20120215", "us-east-1", "iam")
+	want := "f4780e2d9f65fa895f9c67b32ce1baf0b0d8a43505a000a1a9e090d414db404d"
+	if got := hex.EncodeToString(key); got != want {
+		t.Errorf("sigV4Key = %s, want %s", got, want)
+	}
+}
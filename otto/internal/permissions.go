@@ -0,0 +1,182 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// permissions.go verifies that the GitHub App installation grants the permissions modules
+// declare they need. Modules missing one are reported as degraded in logs, metrics and
+// `/otto status` at startup, rather than failing with 403s when they first act.
+
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/google/go-github/v71/github"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// PermissionCheckJobName is the scheduler name of the periodic permission check.
+const PermissionCheckJobName = "github_permissions"
+
+// ModulePermissionRequirer is an optional interface for modules that need GitHub App
+// permissions. Permissions are named as GitHub reports them for installations, e.g.
+// "issues" or "checks", and map to the level needed: "read", "write" or "admin".
+type ModulePermissionRequirer interface {
+	GitHubPermissions() map[string]string
+}
+
+// PermissionFetcher returns the permissions granted to the installation, by name.
+type PermissionFetcher func(ctx context.Context) (map[string]string, error)
+
+// InstallationPermissions fetches the permissions of a GitHub App installation. client must
+// be authenticated as the app, since installations cannot read their own permissions.
+func InstallationPermissions(client *github.Client, installationID int64) PermissionFetcher {
+	return func(ctx context.Context) (map[string]string, error) {
+		installation, _, err := client.Apps.GetInstallation(ctx, installationID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get installation %d: %w", installationID, err)
+		}
+		b, err := json.Marshal(installation.GetPermissions())
+		if err != nil {
+			return nil, err
+		}
+		granted := make(map[string]string)
+		if err := json.Unmarshal(b, &granted); err != nil {
+			return nil, err
+		}
+		return granted, nil
+	}
+}
+
+// permissionLevels orders permission levels; unknown levels rank as none.
+var permissionLevels = map[string]int{"read": 1, "write": 2, "admin": 3}
+
+// MissingPermissions returns the required permissions that are not granted at the level
+// needed, as sorted "name:level" strings.
+func MissingPermissions(granted, required map[string]string) []string {
+	var missing []string
+	for name, level := range required {
+		if permissionLevels[granted[name]] < permissionLevels[level] {
+			missing = append(missing, name+":"+level)
+		}
+	}
+	slices.Sort(missing)
+	return missing
+}
+
+// DeclaredPermissions returns the highest level of each permission that modules declare
+// they need.
+func DeclaredPermissions(modules []Module) map[string]string {
+	declared := make(map[string]string)
+	for _, m := range modules {
+		r, ok := m.(ModulePermissionRequirer)
+		if !ok {
+			continue
+		}
+		for name, level := range r.GitHubPermissions() {
+			if permissionLevels[level] > permissionLevels[declared[name]] {
+				declared[name] = level
+			}
+		}
+	}
+	return declared
+}
+
+// PermissionCheck compares the permissions registered modules need with those granted.
+type PermissionCheck struct {
+	fetch    PermissionFetcher
+	registry *ModuleRegistry
+
+	mu      sync.Mutex
+	missing map[string][]string // module -> missing permissions, for modules that declare any
+}
+
+// NewPermissionCheck creates a check of the modules in registry against the permissions
+// fetch returns, exporting the missing permissions per module as a gauge. Telemetry may
+// be nil.
+func NewPermissionCheck(fetch PermissionFetcher, registry *ModuleRegistry, telemetry *TelemetryManager,
+) (*PermissionCheck, error) {
+	p := &PermissionCheck{fetch: fetch, registry: registry, missing: make(map[string][]string)}
+	if telemetry != nil && telemetry.MeterProvider != nil {
+		_, err := telemetry.Meter().Int64ObservableGauge(
+			"otto.github.permissions_missing",
+			metric.WithDescription("GitHub App permissions a module needs but the installation does not grant"),
+			metric.WithInt64Callback(func(_ context.Context, o metric.Int64Observer) error {
+				p.mu.Lock()
+				defer p.mu.Unlock()
+				for module, missing := range p.missing {
+					o.Observe(int64(len(missing)), telemetry.attrs(attribute.String("module", module)))
+				}
+				return nil
+			}),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create permissions gauge: %w", err)
+		}
+	}
+	return p, nil
+}
+
+// Check fetches the granted permissions and records what each module is missing, logging
+// modules that became degraded or recovered. It runs at startup and on the scheduler.
+func (p *PermissionCheck) Check(ctx context.Context) error {
+	granted, err := p.fetch(ctx)
+	if err != nil {
+		return err
+	}
+	missing := make(map[string][]string)
+	for name, m := range p.registry.GetModules() {
+		if r, ok := m.(ModulePermissionRequirer); ok {
+			missing[name] = MissingPermissions(granted, r.GitHubPermissions())
+		}
+	}
+
+	p.mu.Lock()
+	previous := p.missing
+	p.missing = missing
+	p.mu.Unlock()
+
+	for _, name := range slices.Sorted(maps.Keys(missing)) {
+		switch {
+		case len(missing[name]) > 0 && !slices.Equal(missing[name], previous[name]):
+			slog.Warn("Module lacks GitHub App permissions and is degraded; grant them to the app",
+				"module", name, "missing", missing[name])
+		case len(missing[name]) == 0 && len(previous[name]) > 0:
+			slog.Info("Module has the GitHub App permissions it needs again", "module", name)
+		}
+	}
+	return nil
+}
+
+// Missing returns the permissions a module lacked at the last check. A nil check reports
+// nothing missing.
+func (p *PermissionCheck) Missing(module string) []string {
+	if p == nil {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return slices.Clone(p.missing[module])
+}
+
+// Degraded returns the modules that lacked permissions at the last check, with the
+// missing permissions. A nil check reports no module.
+func (p *PermissionCheck) Degraded() map[string][]string {
+	if p == nil {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	out := make(map[string][]string)
+	for module, missing := range p.missing {
+		if len(missing) > 0 {
+			out[module] = slices.Clone(missing)
+		}
+	}
+	return out
+}
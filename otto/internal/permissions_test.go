@@ -0,0 +1,120 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"testing"
+
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+type permissionModule struct {
+	mockModule
+	permissions map[string]string
+}
+
+func (m *permissionModule) GitHubPermissions() map[string]string { return m.permissions }
+
+func TestMissingPermissions(t *testing.T) {
+	granted := map[string]string{"issues": "write", "checks": "read", "administration": "admin"}
+	tests := []struct {
+		required map[string]string
+		want     []string
+	}{
+		{map[string]string{"issues": "write"}, nil},
+		{map[string]string{"issues": "read", "administration": "write"}, nil},
+		{map[string]string{"checks": "write"}, []string{"checks:write"}},
+		{map[string]string{"contents": "read", "checks": "write"}, []string{"checks:write", "contents:read"}},
+	}
+	for _, tt := range tests {
+		if got := MissingPermissions(granted, tt.required); !slices.Equal(got, tt.want) {
+			t.Errorf("MissingPermissions(%v) = %v, want %v", tt.required, got, tt.want)
+		}
+	}
+}
+
+func TestPermissionCheck(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	registry := NewModuleRegistry()
+	registry.RegisterModule(&permissionModule{
+		mockModule:  mockModule{name: "sizelimit"},
+		permissions: map[string]string{"checks": "write", "pull_requests": "read"},
+	})
+	registry.RegisterModule(&permissionModule{
+		mockModule:  mockModule{name: "history"},
+		permissions: map[string]string{"issues": "write"},
+	})
+	registry.RegisterModule(&mockModule{name: "plain"})
+
+	granted := map[string]string{"issues": "write", "pull_requests": "read"}
+	check, err := NewPermissionCheck(func(context.Context) (map[string]string, error) {
+		return granted, nil
+	}, registry, TestTelemetry(t, reader))
+	if err != nil {
+		t.Fatalf("NewPermissionCheck failed: %v", err)
+	}
+	if err := check.Check(t.Context()); err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	if got := check.Missing("sizelimit"); !slices.Equal(got, []string{"checks:write"}) {
+		t.Errorf("sizelimit missing %v, want checks:write", got)
+	}
+	if degraded := check.Degraded(); len(degraded) != 1 || degraded["sizelimit"] == nil {
+		t.Errorf("degraded = %v, want only sizelimit", degraded)
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(t.Context(), &rm); err != nil {
+		t.Fatalf("failed to collect metrics: %v", err)
+	}
+	missing := map[string]int64{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if g, ok := m.Data.(metricdata.Gauge[int64]); ok && m.Name == "otto.github.permissions_missing" {
+				for _, dp := range g.DataPoints {
+					module, _ := dp.Attributes.Value("module")
+					missing[module.AsString()] = dp.Value
+				}
+			}
+		}
+	}
+	if len(missing) != 2 || missing["sizelimit"] != 1 || missing["history"] != 0 {
+		t.Errorf("permissions gauge = %v, want sizelimit 1 and history 0", missing)
+	}
+
+	// Granting the permission clears the degraded state at the next check.
+	granted["checks"] = "write"
+	if err := check.Check(t.Context()); err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	if degraded := check.Degraded(); len(degraded) != 0 {
+		t.Errorf("degraded = %v after granting checks:write", degraded)
+	}
+
+	var none *PermissionCheck
+	if none.Missing("sizelimit") != nil || none.Degraded() != nil {
+		t.Error("nil check reports missing permissions")
+	}
+}
+
+func TestInstallationPermissions(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /app/installations/42", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id": 42, "permissions": {"issues": "write", "checks": "read", "metadata": "read"}}`))
+	})
+	fetch := InstallationPermissions(TestGitHubClient(t, mux), 42)
+	granted, err := fetch(t.Context())
+	if err != nil {
+		t.Fatalf("fetch failed: %v", err)
+	}
+	if granted["issues"] != "write" || granted["checks"] != "read" || len(granted) != 3 {
+		t.Errorf("granted = %v", granted)
+	}
+	if _, err := InstallationPermissions(TestGitHubClient(t, mux), 7)(t.Context()); err == nil {
+		t.Error("expected an error for an unknown installation")
+	}
+}
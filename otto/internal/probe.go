@@ -0,0 +1,197 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// probe.go checks the webhook pipeline end to end: on a schedule it posts a signed ping
+// event to Otto's own webhook endpoint and waits for dispatch to hand it to a canary
+// module, so a pipeline that silently stopped delivering events to modules is noticed.
+
+package internal
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v71/github"
+)
+
+// ProbeJobName is the scheduler name of the synthetic webhook probe.
+const ProbeJobName = "webhook_probe"
+
+// probeZenPrefix marks the ping events sent by the probe; the probe ID follows it.
+const probeZenPrefix = "otto-probe "
+
+// Prober posts synthetic events to the webhook endpoint and is the canary module that
+// receives them.
+type Prober struct {
+	client        *http.Client
+	url           string
+	secret        []byte
+	slo           time.Duration
+	telemetry     *TelemetryManager
+	notifications *Notifications
+
+	mu      sync.Mutex
+	pending map[string]chan struct{} // probe ID -> closed when the event is dispatched
+	failing bool                     // the last probe failed and operators were told
+}
+
+// NewProber creates a probe of the webhook endpoint at url, whose deliveries are signed
+// with secret. Telemetry and notifications may be nil.
+func NewProber(url string, secret []byte, slo time.Duration, telemetry *TelemetryManager,
+	notifications *Notifications,
+) *Prober {
+	return &Prober{
+		client:        &http.Client{Timeout: slo},
+		url:           url,
+		secret:        secret,
+		slo:           slo,
+		telemetry:     telemetry,
+		notifications: notifications,
+		pending:       make(map[string]chan struct{}),
+	}
+}
+
+func (p *Prober) Name() string { return "probe" }
+
+// EventSubscriptions implements the ModuleEventSubscriber interface.
+func (p *Prober) EventSubscriptions() []EventSubscription {
+	return []EventSubscription{Subscribe("ping")}
+}
+
+// HandleEvent marks the probe a ping event belongs to as dispatched. Other pings, such as
+// the one GitHub sends when a webhook is created, are ignored.
+func (p *Prober) HandleEvent(eventType string, event any, raw json.RawMessage) error {
+	ping, ok := event.(*github.PingEvent)
+	if !ok {
+		return nil
+	}
+	id, ok := strings.CutPrefix(ping.GetZen(), probeZenPrefix)
+	if !ok {
+		return nil
+	}
+	p.mu.Lock()
+	arrived := p.pending[id]
+	delete(p.pending, id)
+	p.mu.Unlock()
+	if arrived != nil {
+		close(arrived)
+	}
+	return nil
+}
+
+// Probe sends a synthetic event and waits up to the SLO for it to be dispatched. It records
+// the outcome, notifies operators when probes start failing and when they recover, and
+// returns the failure. It runs on the scheduler.
+func (p *Prober) Probe(ctx context.Context) error {
+	start := time.Now()
+	err := p.probe(ctx)
+	outcome := "success"
+	if err != nil {
+		outcome = "failure"
+	}
+	if p.telemetry != nil {
+		p.telemetry.RecordProbe(ctx, outcome, float64(time.Since(start).Milliseconds()))
+	}
+	p.report(ctx, err)
+	return err
+}
+
+// probe posts a ping event and waits for the canary to receive it.
+func (p *Prober) probe(ctx context.Context) error {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	id := hex.EncodeToString(b)
+	arrived := make(chan struct{})
+	p.mu.Lock()
+	p.pending[id] = arrived
+	p.mu.Unlock()
+	defer func() {
+		p.mu.Lock()
+		delete(p.pending, id)
+		p.mu.Unlock()
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, p.slo)
+	defer cancel()
+	payload, err := json.Marshal(&github.PingEvent{Zen: github.Ptr(probeZenPrefix + id), HookID: github.Ptr(int64(0))})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(github.EventTypeHeader, "ping")
+	req.Header.Set(github.DeliveryIDHeader, "otto-probe-"+id)
+	req.Header.Set(github.SHA256SignatureHeader, webhookSignature(p.secret, payload))
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("probe webhook request failed: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("probe webhook rejected with %s", resp.Status)
+	}
+
+	select {
+	case <-arrived:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("probe event was accepted but not dispatched within %s", p.slo)
+	}
+}
+
+// report logs failures and notifies operators when probes start failing and when they
+// pass again.
+func (p *Prober) report(ctx context.Context, err error) {
+	p.mu.Lock()
+	wasFailing := p.failing
+	p.failing = err != nil
+	p.mu.Unlock()
+
+	var n Notification
+	switch {
+	case err != nil:
+		slog.Error("Webhook probe failed", "url", p.url, "err", err)
+		if wasFailing {
+			return
+		}
+		n = Notification{
+			Module:   "probe",
+			Severity: SeverityCritical,
+			Title:    "Otto's webhook pipeline probe is failing",
+			Body:     fmt.Sprintf("A synthetic event posted to %s did not reach the modules: %v", p.url, err),
+		}
+	case wasFailing:
+		slog.Info("Webhook probe recovered", "url", p.url)
+		n = Notification{
+			Module:   "probe",
+			Severity: SeverityInfo,
+			Title:    "Otto's webhook pipeline probe recovered",
+			Body:     fmt.Sprintf("Synthetic events posted to %s reach the modules again.", p.url),
+		}
+	default:
+		return
+	}
+	if err := p.notifications.Notify(ctx, n); err != nil {
+		slog.Error("Failed to notify about webhook probe", "err", err)
+	}
+}
+
+// webhookSignature signs a payload like GitHub does in the X-Hub-Signature-256 header.
+func webhookSignature(secret, payload []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
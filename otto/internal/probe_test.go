@@ -0,0 +1,93 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"log/slog"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+	"github.com/open-telemetry/sig-project-infra/otto/internal/secrets"
+)
+
+func TestProbe(t *testing.T) {
+	t.Setenv("OTTO_WEBHOOK_SECRET", "secret")
+	reader := sdkmetric.NewManualReader()
+	telemetry := TestTelemetry(t, reader)
+	app := &App{
+		Config:         &config.AppConfig{},
+		Telemetry:      telemetry,
+		Logger:         slog.Default(),
+		ModuleRegistry: NewModuleRegistry(),
+	}
+	ts := httptest.NewServer(NewServerWithApp("0", secrets.NewEnvManager(), app).mux)
+	defer ts.Close()
+
+	notifications, err := NewNotifications(config.NotificationsConfig{
+		Channels: map[string]config.NotificationChannel{"ops": {Backend: "test", Target: "#ops"}},
+		Routes:   []config.NotificationRoute{{Modules: []string{"probe"}, Channels: []string{"ops"}}},
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewNotifications failed: %v", err)
+	}
+	notifier := &recordingNotifier{name: "test"}
+	notifications.Register(notifier)
+
+	prober := NewProber(ts.URL+"/webhook", []byte("secret"), 5*time.Second, telemetry, notifications)
+	app.RegisterModule(prober)
+	if err := prober.Probe(t.Context()); err != nil {
+		t.Fatalf("Probe failed: %v", err)
+	}
+
+	// A rejected event fails the probe, notifying once however often it fails, and the next
+	// passing probe notifies of the recovery.
+	prober.secret = []byte("wrong")
+	for range 2 {
+		if err := prober.Probe(t.Context()); err == nil || !strings.Contains(err.Error(), "401") {
+			t.Errorf("Probe with the wrong secret = %v, want rejection", err)
+		}
+	}
+	prober.secret = []byte("secret")
+	if err := prober.Probe(t.Context()); err != nil {
+		t.Errorf("Probe failed after recovery: %v", err)
+	}
+	want := []string{"#ops: Otto's webhook pipeline probe is failing", "#ops: Otto's webhook pipeline probe recovered"}
+	if !slices.Equal(notifier.delivered, want) {
+		t.Errorf("notified %q, want %q", notifier.delivered, want)
+	}
+
+	// An accepted event that no canary receives fails once the SLO has passed.
+	app.ModuleRegistry = NewModuleRegistry()
+	prober.slo = 50 * time.Millisecond
+	if err := prober.Probe(t.Context()); err == nil || !strings.Contains(err.Error(), "not dispatched") {
+		t.Errorf("Probe without canary = %v, want dispatch timeout", err)
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(t.Context(), &rm); err != nil {
+		t.Fatalf("failed to collect metrics: %v", err)
+	}
+	runs := map[string]int64{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			sum, ok := m.Data.(metricdata.Sum[int64])
+			if !ok || m.Name != "otto.probe.runs_total" {
+				continue
+			}
+			for _, dp := range sum.DataPoints {
+				outcome, _ := dp.Attributes.Value("outcome")
+				runs[outcome.AsString()] += dp.Value
+			}
+		}
+	}
+	if runs["success"] != 2 || runs["failure"] != 3 {
+		t.Errorf("probe runs = %v, want 2 successes and 3 failures", runs)
+	}
+}
@@ -0,0 +1,102 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// problem.go writes RFC 7807 problem details for rejected webhook deliveries and API
+// requests, with a correlation ID that ties the response shown in GitHub's delivery log
+// to Otto's logs and traces.
+
+package internal
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// CorrelationIDHeader carries the correlation ID of an error response.
+const CorrelationIDHeader = "X-Correlation-ID"
+
+// problemTypeBase prefixes the type URIs of Otto's problems, e.g. urn:otto:problem:invalid-signature.
+const problemTypeBase = "urn:otto:problem:"
+
+// Problem is an RFC 7807 problem details object.
+type Problem struct {
+	Type          string `json:"type"`
+	Title         string `json:"title"`
+	Status        int    `json:"status"`
+	Detail        string `json:"detail,omitempty"`
+	Instance      string `json:"instance,omitempty"`
+	CorrelationID string `json:"correlation_id"`
+	DeliveryID    string `json:"delivery_id,omitempty"`
+}
+
+// correlationID returns the trace ID of the span in ctx, so the ID can be looked up in the
+// tracing backend, or a random ID if the request is not traced.
+func correlationID(ctx context.Context) string {
+	if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+		return sc.TraceID().String()
+	}
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// writeProblem rejects a request with a problem+json body. code is a short kebab-case
+// identifier of the problem, e.g. "invalid-signature". The problem is logged and recorded
+// on the current span with the same correlation ID.
+func writeProblem(ctx context.Context, w http.ResponseWriter, r *http.Request, status int, code, detail string) {
+	writeProblemError(ctx, w, r, status, code, detail, nil)
+}
+
+// writeProblemError is writeProblem for problems caused by err, which is logged and
+// recorded on the span but left out of the response: callers may be unauthenticated, and
+// detail should be the same for every request with the problem.
+func writeProblemError(ctx context.Context, w http.ResponseWriter, r *http.Request, status int, code, detail string,
+	err error,
+) {
+	p := Problem{
+		Type:          problemTypeBase + code,
+		Title:         http.StatusText(status),
+		Status:        status,
+		Detail:        detail,
+		Instance:      r.URL.Path,
+		CorrelationID: correlationID(ctx),
+		DeliveryID:    r.Header.Get("X-GitHub-Delivery"),
+	}
+	if p.DeliveryID == "" {
+		p.DeliveryID = r.Header.Get(gitLabUUIDHeader)
+	}
+
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(
+		attribute.String("otto.correlation_id", p.CorrelationID),
+		attribute.String("otto.problem", code),
+	)
+	span.SetStatus(codes.Error, detail)
+	attrs := []any{
+		"status", status,
+		"problem", code,
+		"detail", detail,
+		"path", p.Instance,
+		"delivery", p.DeliveryID,
+		"correlation_id", p.CorrelationID,
+	}
+	if err != nil {
+		span.RecordError(err)
+		attrs = append(attrs, "error", err)
+	}
+	slog.WarnContext(ctx, "Request rejected", attrs...)
+
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.Header().Set(CorrelationIDHeader, p.CorrelationID)
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(p); err != nil {
+		slog.Error("Failed to write problem response", "error", err)
+	}
+}
@@ -0,0 +1,106 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+	"github.com/open-telemetry/sig-project-infra/otto/internal/secrets"
+)
+
+func TestWebhookProblems(t *testing.T) {
+	t.Setenv("OTTO_WEBHOOK_SECRET", "default-secret")
+	t.Setenv("OTTO_SECRET_GITLAB_WEBHOOK_TOKEN", "gl-token")
+	telemetry := TestTelemetry(t, nil)
+	spans := tracetest.NewSpanRecorder()
+	telemetry.TracerProvider = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))
+	app := &App{
+		Config: &config.AppConfig{Webhooks: []config.WebhookEndpoint{
+			{Path: "/webhook", Source: "github"},
+			{Path: "/webhook/gitlab", Source: "gitlab", Secret: "gitlab_webhook_token"},
+		}},
+		Telemetry:      telemetry,
+		Logger:         slog.Default(),
+		ModuleRegistry: NewModuleRegistry(),
+	}
+	srv := NewServerWithApp("0", secrets.NewEnvManager(), app)
+
+	tests := []struct {
+		name     string
+		path     string
+		headers  map[string]string
+		body     string
+		status   int
+		wantType string
+		detail   string
+	}{
+		{
+			name:     "bad signature",
+			path:     "/webhook",
+			headers:  map[string]string{"X-GitHub-Event": "ping", "X-Hub-Signature-256": "sha256=00"},
+			body:     `{}`,
+			status:   http.StatusUnauthorized,
+			wantType: "urn:otto:problem:invalid-signature",
+		},
+		{
+			name:     "unparsable event",
+			path:     "/webhook",
+			headers:  map[string]string{"X-GitHub-Event": "issues", "X-Hub-Signature-256": signPayload("default-secret", []byte(`{`))},
+			body:     `{`,
+			status:   http.StatusBadRequest,
+			wantType: "urn:otto:problem:unparsable-event",
+			detail:   "could not parse the event payload", // not the parser's error
+		},
+		{
+			name:     "bad GitLab token",
+			path:     "/webhook/gitlab",
+			headers:  map[string]string{"X-Gitlab-Event": "Issue Hook", "X-Gitlab-Token": "nope"},
+			body:     `{}`,
+			status:   http.StatusUnauthorized,
+			wantType: "urn:otto:problem:invalid-token",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tc.path, strings.NewReader(tc.body))
+			req.Header.Set("X-GitHub-Delivery", "delivery-1")
+			for k, v := range tc.headers {
+				req.Header.Set(k, v)
+			}
+			rr := httptest.NewRecorder()
+			srv.mux.ServeHTTP(rr, req)
+
+			if rr.Code != tc.status || rr.Header().Get("Content-Type") != "application/problem+json" {
+				t.Fatalf("response = %d %s, want %d application/problem+json",
+					rr.Code, rr.Header().Get("Content-Type"), tc.status)
+			}
+			var p Problem
+			if err := json.Unmarshal(rr.Body.Bytes(), &p); err != nil {
+				t.Fatalf("invalid problem body %q: %v", rr.Body.String(), err)
+			}
+			if p.Type != tc.wantType || p.Status != tc.status || p.Instance != tc.path ||
+				p.DeliveryID != "delivery-1" || p.Detail == "" {
+				t.Errorf("unexpected problem: %+v", p)
+			}
+			if tc.detail != "" && p.Detail != tc.detail {
+				t.Errorf("detail = %q, want %q", p.Detail, tc.detail)
+			}
+			if p.CorrelationID == "" || rr.Header().Get(CorrelationIDHeader) != p.CorrelationID {
+				t.Errorf("correlation ID header %q, body %q", rr.Header().Get(CorrelationIDHeader), p.CorrelationID)
+			}
+			ended := spans.Ended()
+			if span := ended[len(ended)-1]; span.SpanContext().TraceID().String() != p.CorrelationID {
+				t.Errorf("correlation ID %s is not the trace ID %s", p.CorrelationID, span.SpanContext().TraceID())
+			}
+		})
+	}
+}
@@ -0,0 +1,265 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// quiethours.go holds non-urgent notifications during a repository's quiet hours and
+// releases them as one summary per channel when quiet hours end, so maintainers get a
+// single morning message instead of a night of pings.
+
+package internal
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+)
+
+// QuietHoursJobName is the scheduler name of the job that releases held notifications.
+const QuietHoursJobName = "notification_quiet_hours"
+
+// quietWindow is a parsed daily period of quiet hours, in minutes since local midnight.
+type quietWindow struct {
+	start, end int
+	loc        *time.Location
+}
+
+// parseQuietWindow parses a window; an empty window returns nil.
+func parseQuietWindow(w config.QuietHoursWindow) (*quietWindow, error) {
+	if w.Start == "" && w.End == "" {
+		return nil, nil
+	}
+	var minutes [2]int
+	for i, s := range []string{w.Start, w.End} {
+		t, err := time.Parse("15:04", s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time %q: want HH:MM", s)
+		}
+		minutes[i] = t.Hour()*60 + t.Minute()
+	}
+	if minutes[0] == minutes[1] {
+		return nil, fmt.Errorf("start and end are both %s", w.Start)
+	}
+	loc := time.UTC
+	if w.Timezone != "" {
+		var err error
+		if loc, err = time.LoadLocation(w.Timezone); err != nil {
+			return nil, fmt.Errorf("invalid timezone %q: %w", w.Timezone, err)
+		}
+	}
+	return &quietWindow{start: minutes[0], end: minutes[1], loc: loc}, nil
+}
+
+// until returns when the quiet hours in effect at t end, or false if t is not in them.
+func (w *quietWindow) until(t time.Time) (time.Time, bool) {
+	local := t.In(w.loc)
+	year, month, day := local.Date()
+	minute := local.Hour()*60 + local.Minute()
+	end := func(days int) time.Time {
+		return time.Date(year, month, day+days, w.end/60, w.end%60, 0, 0, w.loc)
+	}
+	switch {
+	case w.start < w.end && minute >= w.start && minute < w.end:
+		return end(0), true
+	case w.start > w.end && minute >= w.start:
+		return end(1), true
+	case w.start > w.end && minute < w.end:
+		return end(0), true
+	}
+	return time.Time{}, false
+}
+
+// QuietHours decides when notifications are held and keeps them in the held_notifications
+// table until they are released.
+type QuietHours struct {
+	db     *sql.DB
+	window *quietWindow
+	repos  map[string]*quietWindow
+	now    func() time.Time
+}
+
+// NewQuietHours validates the quiet hours config and creates the table of held notifications.
+func NewQuietHours(cfg config.QuietHoursConfig, db *sql.DB) (*QuietHours, error) {
+	q := &QuietHours{db: db, repos: make(map[string]*quietWindow), now: time.Now}
+	var err error
+	if q.window, err = parseQuietWindow(cfg.QuietHoursWindow); err != nil {
+		return nil, fmt.Errorf("notifications: quiet_hours: %w", err)
+	}
+	for repo, w := range cfg.Repos {
+		if q.repos[repo], err = parseQuietWindow(w); err != nil {
+			return nil, fmt.Errorf("notifications: quiet_hours for %s: %w", repo, err)
+		}
+	}
+
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS held_notifications (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			backend TEXT NOT NULL,
+			target TEXT NOT NULL,
+			notification TEXT NOT NULL,
+			held_at TIMESTAMP NOT NULL,
+			release_at TIMESTAMP NOT NULL
+		);`,
+		`CREATE INDEX IF NOT EXISTS idx_held_notifications_release ON held_notifications (release_at);`,
+	}
+	for _, s := range stmts {
+		if _, err := db.Exec(s); err != nil {
+			return nil, fmt.Errorf("failed migration: %w (SQL: %s)", err, s)
+		}
+	}
+	return q, nil
+}
+
+// Until returns when the quiet hours of a repository in effect at t end, or false if the
+// repository has no quiet hours at t. Notifications without a repository use the default
+// window. Modules can use it to hold back non-urgent comments of their own.
+func (q *QuietHours) Until(repo string, t time.Time) (time.Time, bool) {
+	if q == nil {
+		return time.Time{}, false
+	}
+	window := q.window
+	if w, ok := q.repos[repo]; ok {
+		window = w
+	}
+	if window == nil {
+		return time.Time{}, false
+	}
+	return window.until(t)
+}
+
+// heldNotification is a notification held for one channel.
+type heldNotification struct {
+	id           int64
+	channel      config.NotificationChannel
+	notification Notification
+}
+
+// hold stores a notification for a channel until the given time.
+func (q *QuietHours) hold(
+	ctx context.Context,
+	channel config.NotificationChannel,
+	notification Notification,
+	until time.Time,
+) error {
+	data, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+	_, err = q.db.ExecContext(ctx,
+		`INSERT INTO held_notifications (backend, target, notification, held_at, release_at) VALUES (?, ?, ?, ?, ?)`,
+		channel.Backend, channel.Target, string(data), q.now().UTC(), until.UTC())
+	if err != nil {
+		return LogAndWrapError(err, ErrorTypeDatabase, "hold_notification", nil)
+	}
+	return nil
+}
+
+// due returns the held notifications whose quiet hours ended by now, oldest first.
+func (q *QuietHours) due(ctx context.Context) ([]heldNotification, error) {
+	rows, err := q.db.QueryContext(ctx,
+		`SELECT id, backend, target, notification FROM held_notifications WHERE release_at <= ? ORDER BY id`,
+		q.now().UTC())
+	if err != nil {
+		return nil, LogAndWrapError(err, ErrorTypeDatabase, "query_held_notifications", nil)
+	}
+	defer rows.Close()
+	var held []heldNotification
+	for rows.Next() {
+		var h heldNotification
+		var data string
+		if err := rows.Scan(&h.id, &h.channel.Backend, &h.channel.Target, &data); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(data), &h.notification); err != nil {
+			return nil, fmt.Errorf("held notification %d: %w", h.id, err)
+		}
+		held = append(held, h)
+	}
+	return held, rows.Err()
+}
+
+// release deletes held notifications once they have been delivered.
+func (q *QuietHours) release(ctx context.Context, held []heldNotification) error {
+	for _, h := range held {
+		if _, err := q.db.ExecContext(ctx, `DELETE FROM held_notifications WHERE id = ?`, h.id); err != nil {
+			return LogAndWrapError(err, ErrorTypeDatabase, "release_notification", nil)
+		}
+	}
+	return nil
+}
+
+// Job returns the scheduler job that delivers the notifications held for each channel as
+// one summary once their quiet hours end. Channels that fail keep their notifications
+// for the next run.
+func (q *QuietHours) Job(n *Notifications, interval time.Duration) Job {
+	return Job{
+		Name:     QuietHoursJobName,
+		Interval: interval,
+		Run: func(ctx context.Context) error {
+			held, err := q.due(ctx)
+			if err != nil {
+				return err
+			}
+			var channels []config.NotificationChannel
+			byChannel := make(map[config.NotificationChannel][]heldNotification)
+			for _, h := range held {
+				if _, ok := byChannel[h.channel]; !ok {
+					channels = append(channels, h.channel)
+				}
+				byChannel[h.channel] = append(byChannel[h.channel], h)
+			}
+			var errs []error
+			for _, channel := range channels {
+				batch := byChannel[channel]
+				notifications := make([]Notification, len(batch))
+				for i, h := range batch {
+					notifications[i] = h.notification
+				}
+				if err := n.deliver(ctx, channel, summarizeHeld(notifications)); err != nil {
+					errs = append(errs, fmt.Errorf("%s %s: %w", channel.Backend, channel.Target, err))
+					continue
+				}
+				if err := q.release(ctx, batch); err != nil {
+					return err
+				}
+				slog.Info("Released notifications held for quiet hours", "backend", channel.Backend,
+					"target", channel.Target, "count", len(batch))
+			}
+			return errors.Join(errs...)
+		},
+	}
+}
+
+// summarizeHeld combines the notifications held for a channel into one. A single
+// notification is delivered as it is.
+func summarizeHeld(held []Notification) Notification {
+	if len(held) == 1 {
+		return held[0]
+	}
+	summary := held[0]
+	summary.Title = fmt.Sprintf("%d notifications during quiet hours", len(held))
+	summary.URL = ""
+	var lines []string
+	for _, n := range held {
+		if n.Severity.rank() > summary.Severity.rank() {
+			summary.Severity = n.Severity
+		}
+		if n.Module != summary.Module {
+			summary.Module = "otto"
+		}
+		if n.Repo != summary.Repo || n.Issue != summary.Issue {
+			summary.Repo, summary.Issue = "", 0
+		}
+		line := fmt.Sprintf("- [%s] %s", n.Severity, n.Title)
+		if n.URL != "" {
+			line += " " + n.URL
+		}
+		lines = append(lines, line)
+	}
+	summary.Body = strings.Join(lines, "\n")
+	return summary
+}
@@ -0,0 +1,136 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+)
+
+func TestQuietHoursUntil(t *testing.T) {
+	q, err := NewQuietHours(config.QuietHoursConfig{
+		QuietHoursWindow: config.QuietHoursWindow{Start: "22:00", End: "08:00", Timezone: "Europe/Berlin"},
+		Repos: map[string]config.QuietHoursWindow{
+			"org/lunch": {Start: "12:00", End: "13:30"},
+			"org/loud":  {},
+		},
+	}, TestDB(t))
+	if err != nil {
+		t.Fatalf("NewQuietHours failed: %v", err)
+	}
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	tests := []struct {
+		name  string
+		repo  string
+		at    time.Time
+		until time.Time
+	}{
+		{"evening", "org/a", time.Date(2025, 3, 29, 23, 15, 0, 0, berlin), time.Date(2025, 3, 30, 8, 0, 0, 0, berlin)},
+		{"early morning", "", time.Date(2025, 3, 30, 7, 59, 0, 0, berlin), time.Date(2025, 3, 30, 8, 0, 0, 0, berlin)},
+		{"morning", "org/a", time.Date(2025, 3, 30, 8, 0, 0, 0, berlin), time.Time{}},
+		{"day in UTC", "org/a", time.Date(2025, 3, 30, 20, 30, 0, 0, time.UTC), time.Date(2025, 3, 31, 8, 0, 0, 0, berlin)},
+		{"repo window", "org/lunch", time.Date(2025, 3, 30, 12, 45, 0, 0, time.UTC),
+			time.Date(2025, 3, 30, 13, 30, 0, 0, time.UTC)},
+		{"outside repo window", "org/lunch", time.Date(2025, 3, 30, 23, 0, 0, 0, berlin), time.Time{}},
+		{"repo without quiet hours", "org/loud", time.Date(2025, 3, 30, 23, 0, 0, 0, berlin), time.Time{}},
+	}
+	for _, tt := range tests {
+		until, quiet := q.Until(tt.repo, tt.at)
+		if quiet != !tt.until.IsZero() || !until.Equal(tt.until) {
+			t.Errorf("%s: Until = %v, %v, want %v", tt.name, until, quiet, tt.until)
+		}
+	}
+
+	var nilQuietHours *QuietHours
+	if _, quiet := nilQuietHours.Until("org/a", time.Now()); quiet {
+		t.Error("nil quiet hours are never quiet")
+	}
+	for _, w := range []config.QuietHoursWindow{
+		{Start: "22:00"},
+		{Start: "10pm", End: "08:00"},
+		{Start: "08:00", End: "08:00"},
+		{Start: "22:00", End: "08:00", Timezone: "Mars/Olympus"},
+	} {
+		if _, err := NewQuietHours(config.QuietHoursConfig{QuietHoursWindow: w}, TestDB(t)); err == nil {
+			t.Errorf("expected error for window %+v", w)
+		}
+	}
+}
+
+func TestQuietHoursHoldAndRelease(t *testing.T) {
+	n, err := NewNotifications(testNotificationsConfig, nil)
+	if err != nil {
+		t.Fatalf("NewNotifications failed: %v", err)
+	}
+	q, err := NewQuietHours(config.QuietHoursConfig{
+		QuietHoursWindow: config.QuietHoursWindow{Start: "22:00", End: "08:00"},
+	}, TestDB(t))
+	if err != nil {
+		t.Fatalf("NewQuietHours failed: %v", err)
+	}
+	now := time.Date(2025, 5, 9, 23, 0, 0, 0, time.UTC)
+	q.now = func() time.Time { return now }
+	n.HoldDuringQuietHours(q)
+	slack := &recordingNotifier{name: "slack"}
+	email := &recordingNotifier{name: "email"}
+	n.Register(slack)
+	n.Register(email)
+
+	ctx := t.Context()
+	for _, notification := range []Notification{
+		{Module: "oncall", Title: "handoff"},
+		{Module: "oncall", Repo: "org/a", Severity: SeverityWarning, Title: "unacknowledged"},
+		{Module: "oncall", Severity: SeverityCritical, Title: "outage"},
+	} {
+		// No webhook backend is registered, so the audit channel fails right away.
+		if err := n.Notify(ctx, notification); err != nil && !strings.Contains(err.Error(), "channel audit") {
+			t.Fatalf("Notify failed: %v", err)
+		}
+	}
+	if err := n.Send(ctx, "slack", "#maintainers", Notification{Module: "advisories", Title: "reminder"}); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	// Critical notifications are urgent and delivered right away.
+	if !slices.Equal(slack.delivered, []string{"#oncall: outage"}) ||
+		!slices.Equal(email.delivered, []string{"ops@example.com: outage"}) {
+		t.Fatalf("delivered during quiet hours: slack %v, email %v", slack.delivered, email.delivered)
+	}
+
+	job := q.Job(n, time.Minute)
+	if err := job.Run(ctx); err != nil {
+		t.Fatalf("job failed during quiet hours: %v", err)
+	}
+	if len(slack.delivered) != 1 {
+		t.Fatalf("released before quiet hours ended: %v", slack.delivered)
+	}
+
+	now = time.Date(2025, 5, 10, 8, 5, 0, 0, time.UTC)
+	if err := job.Run(ctx); err != nil {
+		t.Fatalf("job failed: %v", err)
+	}
+	want := []string{"#oncall: outage", "#oncall: 2 notifications during quiet hours", "#maintainers: reminder"}
+	if !slices.Equal(slack.delivered, want) {
+		t.Errorf("slack delivered %v, want %v", slack.delivered, want)
+	}
+	if err := job.Run(ctx); err != nil || len(slack.delivered) != len(want) {
+		t.Errorf("released notifications again: %v, %v", slack.delivered, err)
+	}
+}
+
+func TestSummarizeHeld(t *testing.T) {
+	got := summarizeHeld([]Notification{
+		{Module: "sla", Repo: "org/a", Severity: SeverityInfo, Title: "first", URL: "https://example.com/1"},
+		{Module: "holds", Repo: "org/a", Severity: SeverityWarning, Title: "second"},
+	})
+	want := Notification{
+		Module: "otto", Repo: "org/a", Severity: SeverityWarning, Title: "2 notifications during quiet hours",
+		Body: "- [info] first https://example.com/1\n- [warning] second",
+	}
+	if got != want {
+		t.Errorf("summary = %+v, want %+v", got, want)
+	}
+}
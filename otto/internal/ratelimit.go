@@ -0,0 +1,238 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// ratelimit.go follows GitHub's rate limits from the X-RateLimit headers of every response.
+// Requests hit by a secondary rate limit are retried once GitHub allows, and modules doing
+// bulk work call WaitIfLimited to pause before they use up the hourly quota that replies
+// to commands depend on.
+
+package internal
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+)
+
+// coreRateLimit is the rate limit resource of REST API calls.
+const coreRateLimit = "core"
+
+// RateLimit is the state of one of GitHub's rate limits, as of the latest response.
+type RateLimit struct {
+	Limit     int       `json:"limit"`
+	Remaining int       `json:"remaining"`
+	Reset     time.Time `json:"reset"`
+}
+
+// RateLimits tracks GitHub's rate limits per resource (core, search, graphql, ...).
+type RateLimits struct {
+	mu     sync.Mutex
+	limits map[string]RateLimit
+	cfg    config.RateLimitConfig
+	// retries counts retries after secondary rate limits; nil without telemetry.
+	retries   metric.Int64Counter
+	telemetry *TelemetryManager
+
+	now   func() time.Time
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// NewRateLimits creates a tracker of GitHub's rate limits. With telemetry, the remaining
+// quota of each resource is reported as a gauge and retries are counted. Telemetry may be
+// nil.
+func NewRateLimits(cfg config.RateLimitConfig, telemetry *TelemetryManager) (*RateLimits, error) {
+	r := &RateLimits{
+		limits:    make(map[string]RateLimit),
+		cfg:       cfg,
+		telemetry: telemetry,
+		now:       time.Now,
+		sleep:     sleepContext,
+	}
+	if telemetry == nil || telemetry.MeterProvider == nil {
+		return r, nil
+	}
+	meter := telemetry.Meter()
+	_, err := meter.Int64ObservableGauge(
+		"otto.github.rate_limit_remaining",
+		metric.WithDescription("GitHub API calls left until the rate limit resets, by resource"),
+		metric.WithInt64Callback(func(_ context.Context, o metric.Int64Observer) error {
+			for resource, limit := range r.Snapshot() {
+				o.Observe(int64(limit.Remaining), telemetry.attrs(attribute.String("resource", resource)))
+			}
+			return nil
+		}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create rate limit gauge: %w", err)
+	}
+	r.retries, err = meter.Int64Counter(
+		"otto.github.rate_limit_retries_total",
+		metric.WithDescription("GitHub requests retried after a secondary rate limit"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create rate limit retries counter: %w", err)
+	}
+	return r, nil
+}
+
+// Observe records the rate limit reported in the headers of a response. Responses
+// without rate limit headers are ignored.
+func (r *RateLimits) Observe(resp *http.Response) {
+	if r == nil || resp == nil {
+		return
+	}
+	remaining, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+	limit, _ := strconv.Atoi(resp.Header.Get("X-RateLimit-Limit"))
+	reset, _ := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64)
+	resource := resp.Header.Get("X-RateLimit-Resource")
+	if resource == "" {
+		resource = coreRateLimit
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.limits[resource] = RateLimit{Limit: limit, Remaining: remaining, Reset: time.Unix(reset, 0).UTC()}
+}
+
+// Get returns the latest state of a resource's rate limit, and whether one was seen.
+func (r *RateLimits) Get(resource string) (RateLimit, bool) {
+	if r == nil {
+		return RateLimit{}, false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	limit, ok := r.limits[resource]
+	return limit, ok
+}
+
+// Snapshot returns the latest state of every rate limit seen, by resource.
+func (r *RateLimits) Snapshot() map[string]RateLimit {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return maps.Clone(r.limits)
+}
+
+// WaitIfLimited blocks until a resource's rate limit resets if no more than the
+// configured reserve of calls is left, so bulk work leaves the rest to interactive
+// commands. It returns early with the context's error if ctx is done first. A nil
+// tracker never waits.
+func (r *RateLimits) WaitIfLimited(ctx context.Context, resource string) error {
+	limit, ok := r.Get(resource)
+	if !ok || limit.Remaining > r.cfg.Reserve {
+		return nil
+	}
+	wait := limit.Reset.Sub(r.now())
+	if wait <= 0 {
+		return nil
+	}
+	slog.InfoContext(ctx, "Waiting for the GitHub rate limit to reset", "module", ModuleFromContext(ctx),
+		"resource", resource, "remaining", limit.Remaining, "reset", limit.Reset, "wait", wait.Round(time.Second))
+	return r.sleep(ctx, wait)
+}
+
+// WaitIfLimited blocks until the REST API rate limit resets if little of it is left; see
+// RateLimits.WaitIfLimited. Modules call it between the calls of bulk operations.
+func (a *App) WaitIfLimited(ctx context.Context) error {
+	return a.RateLimits.WaitIfLimited(ctx, coreRateLimit)
+}
+
+// Transport wraps base so rate limits are recorded from every response, and requests hit
+// by a secondary rate limit are sent again once GitHub allows. A nil tracker returns base
+// unchanged.
+func (r *RateLimits) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if r == nil {
+		return base
+	}
+	return &rateLimitTransport{limits: r, base: base}
+}
+
+type rateLimitTransport struct {
+	limits *RateLimits
+	base   http.RoundTripper
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	delay := t.limits.cfg.Backoff
+	for attempt := 0; ; attempt++ {
+		resp, err := t.base.RoundTrip(req)
+		if err != nil {
+			return resp, err
+		}
+		t.limits.Observe(resp)
+		wait, limited := secondaryRateLimit(resp, delay)
+		// Requests whose body cannot be sent again are not retried.
+		if !limited || attempt >= t.limits.cfg.MaxRetries || wait > t.limits.cfg.MaxWait ||
+			req.Body != nil && req.GetBody == nil {
+			return resp, nil
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		slog.WarnContext(ctx, "GitHub secondary rate limit hit, retrying", "module", ModuleFromContext(ctx),
+			"method", req.Method, "url", req.URL.Path, "attempt", attempt+1, "wait", wait)
+		if t.limits.retries != nil {
+			t.limits.retries.Add(ctx, 1, t.limits.telemetry.attrs(attribute.String("method", req.Method)))
+		}
+		if err := t.limits.sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(ctx)
+			req.Body = body
+		}
+		delay *= 2
+	}
+}
+
+// secondaryRateLimit reports whether a response rejects the request for a secondary
+// (abuse) rate limit, and how long to wait before sending it again: the Retry-After
+// header's delay, or backoff without one. Exhausting the primary rate limit is not a
+// secondary limit, since retrying cannot help until it resets.
+func secondaryRateLimit(resp *http.Response, backoff time.Duration) (time.Duration, bool) {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		return 0, false
+	}
+	// Without Retry-After, only the message tells a secondary limit from a permission error.
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return 0, false
+	}
+	message := strings.ToLower(string(body))
+	if strings.Contains(message, "secondary rate limit") || strings.Contains(message, "abuse detection") {
+		return backoff, true
+	}
+	return 0, false
+}
@@ -0,0 +1,155 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+)
+
+func TestRateLimitTransport(t *testing.T) {
+	reset := time.Date(2025, 5, 1, 11, 0, 0, 0, time.UTC)
+	// Each path fails as listed once, then succeeds with the rate limit headers.
+	responses := map[string][]func(w http.ResponseWriter){
+		"/retry-after": {
+			func(w http.ResponseWriter) {
+				w.Header().Set("Retry-After", "30")
+				w.WriteHeader(http.StatusForbidden)
+			},
+		},
+		"/abuse": {
+			func(w http.ResponseWriter) {
+				w.WriteHeader(http.StatusForbidden)
+				_, _ = io.WriteString(w, `{"message": "You have exceeded a secondary rate limit."}`)
+			},
+		},
+		"/exhausted": {
+			func(w http.ResponseWriter) {
+				w.Header().Set("X-RateLimit-Remaining", "0")
+				w.WriteHeader(http.StatusForbidden)
+			},
+		},
+		"/forbidden": {
+			func(w http.ResponseWriter) {
+				w.WriteHeader(http.StatusForbidden)
+				_, _ = io.WriteString(w, `{"message": "Resource not accessible by integration"}`)
+			},
+		},
+	}
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, r.URL.Path+" "+string(body))
+		if queue := responses[r.URL.Path]; len(queue) > 0 {
+			responses[r.URL.Path] = queue[1:]
+			queue[0](w)
+			return
+		}
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", "150")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+		w.Header().Set("X-RateLimit-Resource", "core")
+	}))
+	defer server.Close()
+
+	reader := sdkmetric.NewManualReader()
+	limits, err := NewRateLimits(config.RateLimitConfig{MaxRetries: 2, Backoff: time.Minute, MaxWait: 5 * time.Minute,
+		Reserve: 200}, TestTelemetry(t, reader))
+	if err != nil {
+		t.Fatalf("NewRateLimits failed: %v", err)
+	}
+	var waits []time.Duration
+	limits.sleep = func(_ context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return nil
+	}
+	limits.now = func() time.Time { return reset.Add(-10 * time.Minute) }
+	client := &http.Client{Transport: limits.Transport(nil)}
+	do := func(path string) int {
+		t.Helper()
+		req, _ := http.NewRequestWithContext(t.Context(), http.MethodPost, server.URL+path, strings.NewReader("x"))
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("POST %s failed: %v", path, err)
+		}
+		defer resp.Body.Close()
+		if _, err := io.ReadAll(resp.Body); err != nil {
+			t.Fatalf("reading %s failed: %v", path, err)
+		}
+		return resp.StatusCode
+	}
+
+	// An exhausted rate limit and other 403s are not retried.
+	for _, path := range []string{"/exhausted", "/forbidden"} {
+		if status := do(path); status != http.StatusForbidden {
+			t.Errorf("POST %s = %d, want 403", path, status)
+		}
+	}
+	if len(waits) != 0 {
+		t.Errorf("unexpected retries after %v", waits)
+	}
+
+	// Secondary limits are retried with the request body, after Retry-After or the backoff.
+	for _, path := range []string{"/retry-after", "/abuse"} {
+		if status := do(path); status != http.StatusOK {
+			t.Errorf("POST %s = %d, want 200 after a retry", path, status)
+		}
+	}
+	if want := []time.Duration{30 * time.Second, time.Minute}; len(waits) != 2 || waits[0] != want[0] ||
+		waits[1] != want[1] {
+		t.Errorf("waits = %v, want %v", waits, want)
+	}
+	if want := "/abuse x"; bodies[len(bodies)-1] != want {
+		t.Errorf("retried request = %q, want %q", bodies[len(bodies)-1], want)
+	}
+
+	limit, ok := limits.Get("core")
+	if !ok || limit.Remaining != 150 || limit.Limit != 5000 || !limit.Reset.Equal(reset) {
+		t.Errorf("core rate limit = %+v, %v", limit, ok)
+	}
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(t.Context(), &rm); err != nil {
+		t.Fatalf("failed to collect metrics: %v", err)
+	}
+	var remaining, retries int64
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			switch data := m.Data.(type) {
+			case metricdata.Gauge[int64]:
+				if m.Name == "otto.github.rate_limit_remaining" {
+					remaining = data.DataPoints[0].Value
+				}
+			case metricdata.Sum[int64]:
+				if m.Name == "otto.github.rate_limit_retries_total" {
+					retries = data.DataPoints[0].Value
+				}
+			}
+		}
+	}
+	if remaining != 150 || retries != 2 {
+		t.Errorf("remaining gauge = %d, retries = %d; want 150 and 2", remaining, retries)
+	}
+
+	// Below the reserve, bulk work waits for the reset.
+	app := &App{RateLimits: limits}
+	if err := app.WaitIfLimited(t.Context()); err != nil {
+		t.Fatalf("WaitIfLimited failed: %v", err)
+	}
+	if len(waits) != 3 || waits[2] != 10*time.Minute {
+		t.Errorf("waits = %v, want a wait of 10m", waits)
+	}
+	if err := (&App{}).WaitIfLimited(t.Context()); err != nil {
+		t.Errorf("WaitIfLimited without a tracker = %v", err)
+	}
+}
@@ -0,0 +1,163 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+)
+
+// fakeRedis is a Redis server that understands AUTH, SELECT, GET, SET and DEL, ignoring
+// expiry, and records the commands it receives.
+type fakeRedis struct {
+	addr string
+
+	mu       sync.Mutex
+	data     map[string]string
+	commands [][]string
+}
+
+func newFakeRedis(t *testing.T, password string) *fakeRedis {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	f := &fakeRedis{addr: ln.Addr().String(), data: make(map[string]string)}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go f.serve(conn, password)
+		}
+	}()
+	return f
+}
+
+func (f *fakeRedis) serve(conn net.Conn, password string) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	authed := password == ""
+	for {
+		reply, err := readRedisReply(r)
+		if err != nil {
+			return
+		}
+		var args []string
+		for _, item := range reply.([]any) {
+			args = append(args, string(item.([]byte)))
+		}
+		f.mu.Lock()
+		f.commands = append(f.commands, args)
+		var out string
+		switch cmd := strings.ToUpper(args[0]); {
+		case cmd == "AUTH":
+			authed = args[1] == password
+			out = "+OK\r\n"
+			if !authed {
+				out = "-WRONGPASS invalid password\r\n"
+			}
+		case !authed:
+			out = "-NOAUTH Authentication required\r\n"
+		case cmd == "SELECT":
+			out = "+OK\r\n"
+		case cmd == "GET":
+			if v, ok := f.data[args[1]]; ok {
+				out = fmt.Sprintf("$%d\r\n%s\r\n", len(v), v)
+			} else {
+				out = "$-1\r\n"
+			}
+		case cmd == "SET":
+			f.data[args[1]] = args[2]
+			out = "+OK\r\n"
+		case cmd == "DEL":
+			_, ok := f.data[args[1]]
+			delete(f.data, args[1])
+			out = ":0\r\n"
+			if ok {
+				out = ":1\r\n"
+			}
+		default:
+			out = "-ERR unknown command\r\n"
+		}
+		f.mu.Unlock()
+		if _, err := conn.Write([]byte(out)); err != nil {
+			return
+		}
+	}
+}
+
+func (f *fakeRedis) commandNames() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var names []string
+	for _, c := range f.commands {
+		names = append(names, c[0])
+	}
+	return names
+}
+
+func TestRedisCache(t *testing.T) {
+	server := newFakeRedis(t, "secret")
+	cache := NewRedisCache(config.RedisConfig{
+		Addr:      server.addr,
+		DB:        2,
+		KeyPrefix: "otto:",
+		Timeout:   time.Second,
+		PoolSize:  1,
+	}, "secret")
+	t.Cleanup(func() { cache.Close() })
+
+	if _, ok, err := cache.Get(t.Context(), "team:org/a"); err != nil || ok {
+		t.Fatalf("Get(missing) = %v, %v; want miss", ok, err)
+	}
+	value := "[\"alice\",\r\n\"bob\"]"
+	if err := cache.Set(t.Context(), "team:org/a", []byte(value), time.Minute); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if v, ok, err := cache.Get(t.Context(), "team:org/a"); err != nil || !ok || string(v) != value {
+		t.Errorf("Get() = %q, %v, %v; want %q", v, ok, err, value)
+	}
+	if err := cache.Delete(t.Context(), "team:org/a"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, ok, _ := cache.Get(t.Context(), "team:org/a"); ok {
+		t.Error("deleted key was returned")
+	}
+
+	// One connection is authenticated, selects the database and is then reused.
+	want := []string{"AUTH", "SELECT", "GET", "SET", "GET", "DEL", "GET"}
+	if got := server.commandNames(); !slices.Equal(got, want) {
+		t.Errorf("commands = %v, want %v", got, want)
+	}
+	server.mu.Lock()
+	set := server.commands[3]
+	server.mu.Unlock()
+	if !slices.Equal(set, []string{"SET", "otto:team:org/a", value, "PX", "60000"}) {
+		t.Errorf("SET = %q", set)
+	}
+}
+
+func TestRedisCacheErrors(t *testing.T) {
+	server := newFakeRedis(t, "secret")
+	cache := NewRedisCache(config.RedisConfig{Addr: server.addr, Timeout: time.Second, PoolSize: 1}, "wrong")
+	if _, _, err := cache.Get(t.Context(), "key"); err == nil || !strings.Contains(err.Error(), "WRONGPASS") {
+		t.Errorf("Get() with a wrong password error = %v", err)
+	}
+
+	unreachable := NewRedisCache(config.RedisConfig{Addr: "127.0.0.1:1", Timeout: time.Second}, "")
+	if err := unreachable.Set(t.Context(), "key", []byte("v"), time.Minute); err == nil {
+		t.Error("Set() on an unreachable server succeeded")
+	}
+}
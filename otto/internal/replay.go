@@ -0,0 +1,243 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// replay.go replays a repository's stored events through a module in a sandbox: an app
+// with a scratch database whose GitHub client reads from GitHub but only records writes.
+// The actions the module would have taken show how a rule change, such as a different
+// stale threshold, would have played out on past activity.
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+	"github.com/open-telemetry/sig-project-infra/otto/internal/secrets"
+)
+
+// ReplayAction is a write or notification a module would have made, with the event or
+// job it made it for.
+type ReplayAction struct {
+	EventID    int64     `json:"event_id,omitempty"`
+	Event      string    `json:"event,omitempty"` // type and action, e.g. "issues.opened"
+	ReceivedAt time.Time `json:"received_at,omitzero"`
+	Job        string    `json:"job,omitempty"`
+	ModuleAction
+}
+
+// ReplayResult is what a module did with replayed events.
+type ReplayResult struct {
+	Events  int            `json:"events"`  // events the module handled
+	Skipped int            `json:"skipped"` // events it does not subscribe to or that could not be parsed
+	Errors  []string       `json:"errors,omitempty"`
+	Actions []ReplayAction `json:"actions"`
+}
+
+// Sandbox is an app running one module offline, for replays. Its GitHub writes and
+// notifications are recorded in its scratch database instead of being made.
+type Sandbox struct {
+	App        *App
+	module     Module
+	dir        string
+	lastAction int64 // the last recorded action collected into a result
+}
+
+// NewSandbox creates a sandbox for module with the app's config and secrets. Its scratch
+// database is removed by Close.
+func NewSandbox(ctx context.Context, cfg *config.AppConfig, secretsManager secrets.Manager,
+	module Module) (*Sandbox, error) {
+	dir, err := os.MkdirTemp("", "otto-sandbox-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sandbox directory: %w", err)
+	}
+	s := &Sandbox{module: module, dir: dir}
+	sandboxCfg := *cfg
+	sandboxCfg.DBPath = filepath.Join(dir, "sandbox.db")
+	sandboxCfg.Shadow = config.ShadowConfig{} // the sandbox records every write anyway
+	s.App = &App{
+		Config:         &sandboxCfg,
+		Secrets:        secretsManager,
+		Logger:         slog.Default(),
+		ModuleRegistry: NewModuleRegistry(),
+		Router:         NewCommandRouter(cfg.Commands),
+		Cache:          NewMemoryCache(),
+		Scheduler:      NewScheduler(nil),
+		shutdownSignal: make(chan struct{}),
+		lifecycle:      &lifecycle{},
+	}
+	if err := s.init(ctx); err != nil {
+		_ = s.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// init sets up the sandbox app's components, like NewApp does for the server.
+func (s *Sandbox) init(ctx context.Context) error {
+	a, name := s.App, s.module.Name()
+	var err error
+	if a.Transport, err = NewHTTPTransport(a.Config.HTTP); err != nil {
+		return err
+	}
+	if a.Budgets, err = NewAPIBudgets(nil, nil); err != nil {
+		return err
+	}
+	if err := a.initializeGitHubClient(ctx); err != nil {
+		return fmt.Errorf("failed to initialize GitHub client: %w", err)
+	}
+	if a.Database, err = NewDatabase(a.Config.DBPath); err != nil {
+		return err
+	}
+	db := a.Database.DB()
+	if a.Shadow, err = NewShadowLog(db, nil); err != nil {
+		return err
+	}
+	a.Shadow.all = true
+	a.GitHubClient = a.Shadow.Client(a.GitHubClient, name)
+	a.FileClasses = NewFileClassifier(a.Config.FileClasses, a.GitHubClient).WithCache(a.Cache)
+
+	if a.Events, err = NewEventStore(db); err != nil {
+		return err
+	}
+	if a.Repos, err = NewRepoRegistry(db); err != nil {
+		return err
+	}
+	// The scratch registry is empty, so patterns in repo groups match no repository.
+	if a.RepoGroups, err = NewRepoGroups(a.Config.RepoGroups, a.Repos); err != nil {
+		return err
+	}
+	if a.Commands, err = NewCommandHistory(db); err != nil {
+		return err
+	}
+	if a.Confirmations, err = NewConfirmations(db); err != nil {
+		return err
+	}
+	// Production flag values are not copied: the module is on everywhere.
+	a.Flags, err = NewFeatureFlags(config.FeatureFlagsConfig{Provider: "database"}, db, "", a.HTTPClient(0), nil)
+	if err != nil {
+		return err
+	}
+	if a.Notifications, err = NewNotifications(a.Config.Notifications, nil); err != nil {
+		return err
+	}
+	a.Notifications.Register(&GitHubNotifier{Client: a.GitHubClient})
+	for _, backend := range []string{"slack", "email", "webhook"} {
+		a.Notifications.Register(&sandboxNotifier{backend: backend, module: name, log: a.Shadow})
+	}
+
+	// Admin routes and slash commands are registered on a server that never listens.
+	a.server = NewServerWithApp("0", a.Secrets, a)
+	// The module is replayed even if the config disables it.
+	a.ModuleRegistry.RegisterModule(s.module)
+	return a.initializeModules(ctx)
+}
+
+// Replay hands the events to the module in order, as if they were being delivered, and
+// adds what it did to result. The events are also stored in the sandbox's event store, so
+// the module sees them as history. Events the module does not subscribe to are skipped.
+func (s *Sandbox) Replay(ctx context.Context, events []StoredEvent, result *ReplayResult) error {
+	name := s.module.Name()
+	for _, e := range events {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if _, err := s.App.Events.Record(ctx, e); err != nil {
+			return err
+		}
+		event, err := ParseWebHook(e.Type, e.Payload)
+		if err != nil {
+			result.Skipped++
+			continue
+		}
+		event = s.App.Router.Route(event)
+		normalized := NormalizeGitHubEvent(event)
+		_, handlesNormalized := s.module.(NormalizedEventHandler)
+		if !Subscribed(s.module, e.Type, event) && (!handlesNormalized || normalized == nil) {
+			result.Skipped++
+			continue
+		}
+		result.Events++
+		cause := ReplayAction{EventID: e.ID, Event: e.Type, ReceivedAt: e.ReceivedAt}
+		if e.Action != "" {
+			cause.Event += "." + e.Action
+		}
+		err = s.App.callModule(name, s.module, e.DeliveryID, e.Type, e.Repo, event, e.Payload, normalized)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("event %d (%s): %v", e.ID, cause.Event, err))
+		}
+		if err := s.collect(ctx, cause, result); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RunJobs runs each of the module's scheduled jobs once, in the order they were
+// registered, and adds what they did to result.
+func (s *Sandbox) RunJobs(ctx context.Context, result *ReplayResult) error {
+	s.App.Scheduler.mu.Lock()
+	jobs := slices.Clone(s.App.Scheduler.jobs)
+	s.App.Scheduler.mu.Unlock()
+	for _, job := range jobs {
+		runCtx := ctx
+		if job.Module != "" {
+			runCtx = WithModule(ctx, job.Module)
+		}
+		if err := job.Run(runCtx); err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("job %s: %v", job.Name, err))
+		}
+		if err := s.collect(ctx, ReplayAction{Job: job.Name}, result); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// collect adds the actions recorded since the last call to result, attributed to cause.
+func (s *Sandbox) collect(ctx context.Context, cause ReplayAction, result *ReplayResult) error {
+	actions, err := s.App.Shadow.Query(ctx, ModuleActionQuery{AfterID: s.lastAction, Limit: -1})
+	if err != nil {
+		return err
+	}
+	slices.Reverse(actions)
+	for _, a := range actions {
+		cause.ModuleAction = a
+		result.Actions = append(result.Actions, cause)
+		s.lastAction = a.ID
+	}
+	return nil
+}
+
+// Close shuts the module down and removes the scratch database.
+func (s *Sandbox) Close() error {
+	var errs []error
+	if s.App.Database != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		errs = append(errs, s.App.shutdownModules(ctx), s.App.Database.Close())
+	}
+	errs = append(errs, os.RemoveAll(s.dir))
+	return errors.Join(errs...)
+}
+
+// sandboxNotifier records notifications to a backend as actions of the sandboxed module.
+type sandboxNotifier struct {
+	backend, module string
+	log             *ShadowLog
+}
+
+func (n *sandboxNotifier) Backend() string { return n.backend }
+
+func (n *sandboxNotifier) Notify(ctx context.Context, target string, notification Notification) error {
+	n.log.record(ctx, ModuleAction{
+		Module: n.module, Mode: ShadowModeShadow, Repo: notification.Repo, Action: "NOTIFY " + n.backend,
+		Path: target, Body: notification.Text(),
+	})
+	return nil
+}
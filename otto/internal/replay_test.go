@@ -0,0 +1,129 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v71/github"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+	"github.com/open-telemetry/sig-project-infra/otto/internal/secrets"
+)
+
+// staleTestModule labels opened issues, notifies about closed ones and comments from a job.
+type staleTestModule struct {
+	app *App
+}
+
+func (m *staleTestModule) Name() string { return "stale" }
+
+func (m *staleTestModule) EventSubscriptions() []EventSubscription {
+	return []EventSubscription{{Event: "issues", Actions: []string{"opened", "closed"}}}
+}
+
+func (m *staleTestModule) Initialize(_ context.Context, app *App) error {
+	m.app = app
+	app.Scheduler.Register(Job{Name: "stale_sweep", Interval: time.Hour, Module: m.Name(), Run: m.sweep})
+	return nil
+}
+
+func (m *staleTestModule) HandleEvent(eventType string, event any, _ json.RawMessage) error {
+	e := event.(*github.IssuesEvent)
+	owner, name := e.GetRepo().GetOwner().GetLogin(), e.GetRepo().GetName()
+	switch e.GetAction() {
+	case "opened":
+		_, _, err := m.app.GitHubClient.Issues.AddLabelsToIssue(context.Background(), owner, name,
+			e.GetIssue().GetNumber(), []string{"triage"})
+		return err
+	case "closed":
+		return m.app.Notifications.Send(context.Background(), "slack", "#triage", Notification{
+			Module: m.Name(), Repo: e.GetRepo().GetFullName(), Title: "closed", Severity: SeverityInfo,
+		})
+	}
+	return nil
+}
+
+func (m *staleTestModule) sweep(ctx context.Context) error {
+	events, err := m.app.Events.Query(ctx, EventQuery{Type: "issues"})
+	if err != nil {
+		return err
+	}
+	for _, e := range events {
+		if e.Action == "opened" {
+			_, _, err := m.app.GitHubClient.Issues.CreateComment(ctx, "org", "a", 1,
+				&github.IssueComment{Body: github.Ptr("still open?")})
+			return err
+		}
+	}
+	return nil
+}
+
+func TestSandboxReplay(t *testing.T) {
+	cfg := &config.AppConfig{}
+	config.ApplyDefaults(cfg)
+	cfg.DBPath = "production.db" // never opened by the sandbox
+	box, err := NewSandbox(t.Context(), cfg, secrets.NewEnvManager(), &staleTestModule{})
+	if err != nil {
+		t.Fatalf("NewSandbox failed: %v", err)
+	}
+	received := time.Date(2025, 5, 1, 10, 0, 0, 0, time.UTC)
+	issue := func(id int64, action string) StoredEvent {
+		payload := fmt.Sprintf(`{"action": %q, "issue": {"number": 1},
+			"repository": {"name": "a", "full_name": "org/a", "owner": {"login": "org"}}}`, action)
+		e := NewStoredEvent(fmt.Sprint(id), "issues", []byte(payload))
+		e.ID, e.ReceivedAt = id, received.Add(time.Duration(id)*time.Hour)
+		return e
+	}
+	events := []StoredEvent{
+		issue(1, "opened"),
+		issue(2, "edited"),
+		NewStoredEvent("3", "push", []byte(`{"ref": "refs/heads/main"}`)),
+		issue(4, "closed"),
+	}
+
+	var result ReplayResult
+	if err := box.Replay(t.Context(), events, &result); err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	if err := box.RunJobs(t.Context(), &result); err != nil {
+		t.Fatalf("RunJobs failed: %v", err)
+	}
+	if result.Events != 2 || result.Skipped != 2 || len(result.Errors) != 0 {
+		t.Errorf("result = %+v", result)
+	}
+	want := []struct{ cause, action string }{
+		{"issues.opened", "POST /repos/{owner}/{repo}/issues/{n}/labels"},
+		{"issues.closed", "NOTIFY slack"},
+		{"job stale_sweep", "POST /repos/{owner}/{repo}/issues/{n}/comments"},
+	}
+	if len(result.Actions) != len(want) {
+		t.Fatalf("actions = %+v, want %d", result.Actions, len(want))
+	}
+	for i, w := range want {
+		a := result.Actions[i]
+		cause := a.Event
+		if a.Job != "" {
+			cause = "job " + a.Job
+		}
+		if cause != w.cause || a.Action != w.action || a.Mode != ShadowModeShadow {
+			t.Errorf("action %d = %s %s, want %s %s", i, cause, a.Action, w.cause, w.action)
+		}
+	}
+	if a := result.Actions[0]; a.EventID != 1 || !a.ReceivedAt.Equal(events[0].ReceivedAt) || a.Repo != "org/a" {
+		t.Errorf("first action = %+v", a)
+	}
+
+	dir := box.dir
+	if err := box.Close(); err != nil {
+		t.Errorf("Close failed: %v", err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("scratch directory %s was not removed: %v", dir, err)
+	}
+}
@@ -0,0 +1,109 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// repoevents.go keeps Otto's records in step with repository events: archived and deleted
+// repositories are recorded so no module acts on them, and the records of renamed and
+// transferred repositories move to the new name.
+
+package internal
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/google/go-github/v71/github"
+)
+
+// ModuleRepoRenamer is an optional interface for modules that store repository names, such
+// as timers or tasks. RenameRepo moves the module's records from a repository's old full
+// name to its new one after the repository was renamed or transferred.
+type ModuleRepoRenamer interface {
+	RenameRepo(ctx context.Context, from, to string) error
+}
+
+// handleRepositoryEvent updates the repository registry, and for renames the records of
+// modules and feature flags, before modules see a repository event.
+func (a *App) handleRepositoryEvent(ctx context.Context, e *github.RepositoryEvent) {
+	repo := e.GetRepo().GetFullName()
+	if a.Repos == nil || repo == "" {
+		return
+	}
+	var err error
+	switch e.GetAction() {
+	case "archived":
+		err = a.Repos.SetStatus(ctx, repo, RepoArchived)
+	case "unarchived":
+		err = a.Repos.SetStatus(ctx, repo, RepoActive)
+	case "deleted":
+		err = a.Repos.SetStatus(ctx, repo, RepoDeleted)
+	case "renamed", "transferred":
+		if from := previousRepoName(e); from != "" && from != repo {
+			a.renameRepo(ctx, from, repo)
+		}
+		return
+	default:
+		return
+	}
+	if err != nil {
+		slog.Error("Failed to record repository status", "repo", repo, "action", e.GetAction(), "err", err)
+		return
+	}
+	slog.Info("Repository status changed", "repo", repo, "action", e.GetAction())
+}
+
+// renameRepo moves the registration, feature flags and module records of a repository to
+// its new name. Failures are logged and do not stop the other records from moving.
+func (a *App) renameRepo(ctx context.Context, from, to string) {
+	if err := a.Repos.Rename(ctx, from, to); err != nil {
+		slog.Error("Failed to rename repository in the registry", "from", from, "to", to, "err", err)
+	}
+	if err := a.Flags.RenameRepo(ctx, from, to); err != nil {
+		slog.Error("Failed to rename repository in feature flags", "from", from, "to", to, "err", err)
+	}
+	for name, mod := range a.ModuleRegistry.GetModules() {
+		renamer, ok := mod.(ModuleRepoRenamer)
+		if !ok {
+			continue
+		}
+		if err := renamer.RenameRepo(ctx, from, to); err != nil {
+			slog.Error("Failed to rename repository in module records", "module", name, "from", from, "to", to,
+				"err", err)
+		}
+	}
+	slog.Info("Repository renamed", "from", from, "to", to)
+}
+
+// previousRepoName returns the full name a renamed or transferred repository had before.
+func previousRepoName(e *github.RepositoryEvent) string {
+	owner, name, err := SplitRepo(e.GetRepo().GetFullName())
+	if err != nil {
+		return ""
+	}
+	changes := e.GetChanges()
+	if from := changes.GetRepo().GetName().GetFrom(); from != "" {
+		name = from
+	}
+	if info := changes.GetOwner().GetOwnerInfo(); info != nil {
+		switch {
+		case info.GetOrg().GetLogin() != "":
+			owner = info.GetOrg().GetLogin()
+		case info.GetUser().GetLogin() != "":
+			owner = info.GetUser().GetLogin()
+		}
+	}
+	return owner + "/" + name
+}
+
+// RepoActive reports whether a repository is neither archived nor deleted, for scheduled
+// jobs to skip the others. Without a registry, or if it cannot be read, repositories are
+// active.
+func (a *App) RepoActive(repo string) bool {
+	if a == nil || a.Repos == nil {
+		return true
+	}
+	status, err := a.Repos.Status(context.Background(), repo)
+	if err != nil {
+		slog.Error("Failed to check repository status", "repo", repo, "err", err)
+		return true
+	}
+	return status == RepoActive
+}
@@ -0,0 +1,104 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-github/v71/github"
+)
+
+type renamerModule struct {
+	mockModule
+	from, to string
+}
+
+func (m *renamerModule) RenameRepo(_ context.Context, from, to string) error {
+	m.from, m.to = from, to
+	return nil
+}
+
+func repositoryEvent(action, fullName string, changes *github.EditChange) *github.RepositoryEvent {
+	return &github.RepositoryEvent{
+		Action:  github.Ptr(action),
+		Repo:    &github.Repository{FullName: github.Ptr(fullName)},
+		Changes: changes,
+	}
+}
+
+func TestHandleRepositoryEvent(t *testing.T) {
+	registry, err := NewRepoRegistry(TestDB(t))
+	if err != nil {
+		t.Fatalf("NewRepoRegistry failed: %v", err)
+	}
+	ctx := t.Context()
+	if err := registry.Register(ctx, "org/repo", "alice", []string{"sla"}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	mod := &renamerModule{mockModule: mockModule{name: "renamer"}}
+	app := &App{ModuleRegistry: NewModuleRegistry(), Repos: registry}
+	app.RegisterModule(mod)
+
+	app.handleRepositoryEvent(ctx, repositoryEvent("archived", "org/repo", nil))
+	if app.RepoActive("org/repo") {
+		t.Error("repo should be inactive after archived event")
+	}
+	app.handleRepositoryEvent(ctx, repositoryEvent("unarchived", "org/repo", nil))
+	if !app.RepoActive("org/repo") {
+		t.Error("repo should be active after unarchived event")
+	}
+
+	app.handleRepositoryEvent(ctx, repositoryEvent("renamed", "org/new", &github.EditChange{
+		Repo: &github.EditRepo{Name: &github.RepoName{From: github.Ptr("repo")}},
+	}))
+	if mod.from != "org/repo" || mod.to != "org/new" {
+		t.Errorf("module renamed %q to %q, want org/repo to org/new", mod.from, mod.to)
+	}
+	if repo, _ := registry.Get(ctx, "org/new"); repo == nil {
+		t.Error("registration should move to the new name")
+	}
+
+	app.handleRepositoryEvent(ctx, repositoryEvent("deleted", "org/new", nil))
+	if app.RepoActive("org/new") {
+		t.Error("repo should be inactive after deleted event")
+	}
+}
+
+func TestPreviousRepoName(t *testing.T) {
+	tests := []struct {
+		name    string
+		repo    string
+		changes *github.EditChange
+		want    string
+	}{
+		{"no changes", "org/repo", nil, "org/repo"},
+		{
+			"renamed", "org/new",
+			&github.EditChange{Repo: &github.EditRepo{Name: &github.RepoName{From: github.Ptr("old")}}},
+			"org/old",
+		},
+		{
+			"transferred from org", "neworg/repo",
+			&github.EditChange{Owner: &github.EditOwner{OwnerInfo: &github.OwnerInfo{
+				Org: &github.User{Login: github.Ptr("oldorg")},
+			}}},
+			"oldorg/repo",
+		},
+		{
+			"transferred from user", "org/repo",
+			&github.EditChange{Owner: &github.EditOwner{OwnerInfo: &github.OwnerInfo{
+				User: &github.User{Login: github.Ptr("alice")},
+			}}},
+			"alice/repo",
+		},
+		{"invalid name", "repo", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := previousRepoName(repositoryEvent("renamed", tt.repo, tt.changes)); got != tt.want {
+				t.Errorf("previousRepoName = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// repogroups.go resolves the repository lists in module settings. Besides full names, a
+// list may hold path.Match patterns and references to the named groups of repo_groups,
+// so modules that work across repositories share one definition of, say, the collector
+// repositories instead of each repeating it.
+
+package internal
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+)
+
+// repoGroupPrefix marks a reference to a repository group in a repository list.
+const repoGroupPrefix = "@"
+
+// RepoGroups resolves repository lists whose entries are full names (owner/name),
+// patterns (open-telemetry/opentelemetry-collector*) or group references
+// (@collector-repos). Groups may refer to other groups. A nil RepoGroups has no groups.
+type RepoGroups struct {
+	groups map[string][]string
+	repos  *RepoRegistry // patterns are matched against its active repositories; may be nil
+}
+
+// NewRepoGroups creates groups from group name -> entries, checking that patterns are
+// valid and that references name a group and do not loop.
+func NewRepoGroups(groups map[string][]string, repos *RepoRegistry) (*RepoGroups, error) {
+	g := &RepoGroups{groups: groups, repos: repos}
+	for name, entries := range groups {
+		if name == "" || strings.HasPrefix(name, repoGroupPrefix) {
+			return nil, fmt.Errorf("repo_groups: invalid group name %q", name)
+		}
+		if err := g.check(entries, []string{name}); err != nil {
+			return nil, fmt.Errorf("repo_groups: %s: %w", name, err)
+		}
+	}
+	return g, nil
+}
+
+// Check validates a repository list, for modules to call on their settings.
+func (g *RepoGroups) Check(entries []string) error {
+	return g.check(entries, nil)
+}
+
+// check validates entries; within is the chain of groups they are part of.
+func (g *RepoGroups) check(entries, within []string) error {
+	for _, entry := range entries {
+		name, isGroup := strings.CutPrefix(entry, repoGroupPrefix)
+		if !isGroup {
+			if _, err := path.Match(entry, ""); err != nil {
+				return fmt.Errorf("invalid repository pattern %q: %w", entry, err)
+			}
+			continue
+		}
+		members, ok := g.group(name)
+		if !ok {
+			return fmt.Errorf("unknown repo group %q", name)
+		}
+		if slices.Contains(within, name) {
+			return fmt.Errorf("repo group %q includes itself", name)
+		}
+		if err := g.check(members, append(slices.Clip(within), name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// group returns a group's entries.
+func (g *RepoGroups) group(name string) ([]string, bool) {
+	if g == nil {
+		return nil, false
+	}
+	entries, ok := g.groups[name]
+	return entries, ok
+}
+
+// Match reports whether repo is in the list, directly, by a pattern or through a group.
+func (g *RepoGroups) Match(entries []string, repo string) bool {
+	for _, entry := range entries {
+		if name, isGroup := strings.CutPrefix(entry, repoGroupPrefix); isGroup {
+			members, _ := g.group(name)
+			if g.Match(members, repo) {
+				return true
+			}
+		} else if ok, _ := path.Match(entry, repo); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolve returns the repositories in the list, in order and without duplicates. Full
+// names are returned as they are; patterns are matched against the registered
+// repositories that are not archived or deleted.
+func (g *RepoGroups) Resolve(ctx context.Context, entries []string) ([]string, error) {
+	var registered []string
+	loaded := false
+	var repos []string
+	var resolve func(entries []string) error
+	resolve = func(entries []string) error {
+		for _, entry := range entries {
+			if name, isGroup := strings.CutPrefix(entry, repoGroupPrefix); isGroup {
+				members, ok := g.group(name)
+				if !ok {
+					return fmt.Errorf("unknown repo group %q", name)
+				}
+				if err := resolve(members); err != nil {
+					return err
+				}
+				continue
+			}
+			if !strings.ContainsAny(entry, `*?[\`) {
+				if !slices.Contains(repos, entry) {
+					repos = append(repos, entry)
+				}
+				continue
+			}
+			if !loaded {
+				var err error
+				if registered, err = g.activeRepos(ctx); err != nil {
+					return err
+				}
+				loaded = true
+			}
+			for _, repo := range registered {
+				if ok, _ := path.Match(entry, repo); ok && !slices.Contains(repos, repo) {
+					repos = append(repos, repo)
+				}
+			}
+		}
+		return nil
+	}
+	if err := resolve(entries); err != nil {
+		return nil, err
+	}
+	return repos, nil
+}
+
+// activeRepos returns the registered repositories that are not archived or deleted.
+func (g *RepoGroups) activeRepos(ctx context.Context) ([]string, error) {
+	if g == nil || g.repos == nil {
+		return nil, nil
+	}
+	managed, err := g.repos.List(ctx)
+	if err != nil {
+		return nil, LogAndWrapError(err, ErrorTypeDatabase, "list_repos", nil)
+	}
+	var repos []string
+	for _, r := range managed {
+		if r.Status == RepoActive {
+			repos = append(repos, r.FullName)
+		}
+	}
+	return repos, nil
+}
@@ -0,0 +1,82 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRepoGroups(t *testing.T) {
+	ctx := t.Context()
+	registry, err := NewRepoRegistry(TestDB(t))
+	if err != nil {
+		t.Fatalf("NewRepoRegistry failed: %v", err)
+	}
+	for _, repo := range []string{"org/collector", "org/collector-contrib", "org/collector-old", "org/java"} {
+		if err := registry.Register(ctx, repo, "admin", nil); err != nil {
+			t.Fatalf("Register failed: %v", err)
+		}
+	}
+	if err := registry.SetStatus(ctx, "org/collector-old", RepoArchived); err != nil {
+		t.Fatalf("SetStatus failed: %v", err)
+	}
+	groups, err := NewRepoGroups(map[string][]string{
+		"collector-repos":       {"org/collector*", "other/collector-releases"},
+		"instrumentation-repos": {"org/java", "org/python"},
+		"sig-repos":             {"@collector-repos", "@instrumentation-repos", "org/java"},
+	}, registry)
+	if err != nil {
+		t.Fatalf("NewRepoGroups failed: %v", err)
+	}
+
+	tests := []struct {
+		entries []string
+		want    []string
+	}{
+		{[]string{"@collector-repos"}, []string{"org/collector", "org/collector-contrib", "other/collector-releases"}},
+		{[]string{"@sig-repos"}, []string{
+			"org/collector", "org/collector-contrib", "other/collector-releases", "org/java", "org/python",
+		}},
+		{[]string{"org/docs", "org/j*"}, []string{"org/docs", "org/java"}},
+		{nil, nil},
+	}
+	for _, tt := range tests {
+		got, err := groups.Resolve(ctx, tt.entries)
+		if err != nil || !slices.Equal(got, tt.want) {
+			t.Errorf("Resolve(%v) = %v, %v, want %v", tt.entries, got, err, tt.want)
+		}
+	}
+	if _, err := groups.Resolve(ctx, []string{"@missing"}); err == nil {
+		t.Error("expected error for an unknown group")
+	}
+
+	// Matching does not need the repository to be registered.
+	for repo, want := range map[string]bool{"org/collector-new": true, "org/python": true, "org/go": false} {
+		if got := groups.Match([]string{"@sig-repos"}, repo); got != want {
+			t.Errorf("Match(@sig-repos, %s) = %v, want %v", repo, got, want)
+		}
+	}
+
+	if err := groups.Check([]string{"@sig-repos", "org/*"}); err != nil {
+		t.Errorf("Check failed: %v", err)
+	}
+	if err := groups.Check([]string{"@collectors"}); err == nil {
+		t.Error("expected error for an unknown group")
+	}
+	var none *RepoGroups
+	if none.Match([]string{"@sig-repos"}, "org/java") || !none.Match([]string{"org/java"}, "org/java") {
+		t.Error("nil groups matched unexpectedly")
+	}
+
+	for name, groups := range map[string]map[string][]string{
+		"unknown reference": {"a": {"@b"}},
+		"cycle":             {"a": {"@b"}, "b": {"org/x", "@a"}},
+		"invalid pattern":   {"a": {"org/["}},
+		"invalid name":      {"@a": {"org/x"}},
+	} {
+		if _, err := NewRepoGroups(groups, nil); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
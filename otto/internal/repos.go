@@ -0,0 +1,255 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// repos.go records the repositories Otto manages, which modules are enabled for each, and
+// the repositories that were archived or deleted.
+
+package internal
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Repository states recorded from repository events.
+const (
+	RepoActive   = "active"
+	RepoArchived = "archived"
+	RepoDeleted  = "deleted"
+)
+
+// ManagedRepo is a repository registered with Otto.
+type ManagedRepo struct {
+	FullName    string
+	OnboardedBy string
+	OnboardedAt time.Time
+	Modules     []string
+	Status      string // RepoActive or RepoArchived
+}
+
+// RepoRegistry reads and writes the repos, repo_modules and repo_states tables.
+type RepoRegistry struct {
+	db *sql.DB
+}
+
+// NewRepoRegistry creates the registry, creating its tables if needed.
+func NewRepoRegistry(db *sql.DB) (*RepoRegistry, error) {
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS repos (
+			full_name TEXT PRIMARY KEY,
+			onboarded_by TEXT,
+			onboarded_at TIMESTAMP NOT NULL
+		);`,
+		`CREATE TABLE IF NOT EXISTS repo_modules (
+			repo TEXT NOT NULL,
+			module TEXT NOT NULL,
+			PRIMARY KEY (repo, module),
+			FOREIGN KEY(repo) REFERENCES repos(full_name)
+		);`,
+		`CREATE TABLE IF NOT EXISTS repo_states (
+			full_name TEXT PRIMARY KEY,
+			status TEXT NOT NULL,
+			changed_at TIMESTAMP NOT NULL
+		);`,
+	}
+	for _, s := range stmts {
+		if _, err := db.Exec(s); err != nil {
+			return nil, fmt.Errorf("failed migration: %w (SQL: %s)", err, s)
+		}
+	}
+	return &RepoRegistry{db: db}, nil
+}
+
+// Register records a repository and replaces its enabled module set.
+func (r *RepoRegistry) Register(ctx context.Context, fullName, by string, modules []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return LogAndWrapError(err, ErrorTypeDatabase, "register_repo", map[string]any{"repo": fullName})
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO repos (full_name, onboarded_by, onboarded_at) VALUES (?, ?, ?)
+		 ON CONFLICT(full_name) DO UPDATE SET onboarded_by = excluded.onboarded_by, onboarded_at = excluded.onboarded_at`,
+		fullName, by, time.Now(),
+	); err != nil {
+		return LogAndWrapError(err, ErrorTypeDatabase, "register_repo", map[string]any{"repo": fullName})
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM repo_modules WHERE repo = ?`, fullName); err != nil {
+		return LogAndWrapError(err, ErrorTypeDatabase, "register_repo", map[string]any{"repo": fullName})
+	}
+	for _, m := range modules {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT OR IGNORE INTO repo_modules (repo, module) VALUES (?, ?)`, fullName, m,
+		); err != nil {
+			return LogAndWrapError(err, ErrorTypeDatabase, "register_repo", map[string]any{"repo": fullName})
+		}
+	}
+	return tx.Commit()
+}
+
+// Get returns a registered repository, or nil if it is not registered.
+func (r *RepoRegistry) Get(ctx context.Context, fullName string) (*ManagedRepo, error) {
+	repo := ManagedRepo{FullName: fullName}
+	var by sql.NullString
+	err := r.db.QueryRowContext(ctx,
+		`SELECT r.onboarded_by, r.onboarded_at, COALESCE(s.status, 'active')
+		 FROM repos r LEFT JOIN repo_states s ON s.full_name = r.full_name WHERE r.full_name = ?`, fullName,
+	).Scan(&by, &repo.OnboardedAt, &repo.Status)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	repo.OnboardedBy = by.String
+	repo.Modules, err = r.modules(ctx, fullName)
+	if err != nil {
+		return nil, err
+	}
+	return &repo, nil
+}
+
+// List returns all registered repositories, archived ones included, ordered by name.
+func (r *RepoRegistry) List(ctx context.Context) ([]ManagedRepo, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT r.full_name, r.onboarded_by, r.onboarded_at, COALESCE(s.status, 'active')
+		 FROM repos r LEFT JOIN repo_states s ON s.full_name = r.full_name ORDER BY r.full_name ASC`)
+	if err != nil {
+		return nil, err
+	}
+	var repos []ManagedRepo
+	for rows.Next() {
+		var (
+			repo ManagedRepo
+			by   sql.NullString
+		)
+		if err := rows.Scan(&repo.FullName, &by, &repo.OnboardedAt, &repo.Status); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		repo.OnboardedBy = by.String
+		repos = append(repos, repo)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for i := range repos {
+		if repos[i].Modules, err = r.modules(ctx, repos[i].FullName); err != nil {
+			return nil, err
+		}
+	}
+	return repos, nil
+}
+
+// ModuleEnabled reports whether a module should handle events for a repository.
+// Repositories that were never registered keep every module enabled, and archived or
+// deleted ones have none.
+func (r *RepoRegistry) ModuleEnabled(ctx context.Context, fullName, module string) (bool, error) {
+	var registered, enabled, inactive bool
+	err := r.db.QueryRowContext(ctx,
+		`SELECT EXISTS(SELECT 1 FROM repos WHERE full_name = ?),
+		        EXISTS(SELECT 1 FROM repo_modules WHERE repo = ? AND module = ?),
+		        EXISTS(SELECT 1 FROM repo_states WHERE full_name = ? AND status != 'active')`,
+		fullName, fullName, module, fullName,
+	).Scan(&registered, &enabled, &inactive)
+	if err != nil {
+		return false, err
+	}
+	return !inactive && (!registered || enabled), nil
+}
+
+// Status returns whether a repository is active, archived or deleted. Repositories are
+// active unless a repository event said otherwise, whether or not they are registered.
+func (r *RepoRegistry) Status(ctx context.Context, fullName string) (string, error) {
+	status := RepoActive
+	err := r.db.QueryRowContext(ctx,
+		`SELECT status FROM repo_states WHERE full_name = ?`, fullName,
+	).Scan(&status)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return "", err
+	}
+	return status, nil
+}
+
+// SetStatus records that a repository was archived, unarchived or deleted. Deleting a
+// repository also removes its registration.
+func (r *RepoRegistry) SetStatus(ctx context.Context, fullName, status string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return LogAndWrapError(err, ErrorTypeDatabase, "set_repo_status", map[string]any{"repo": fullName})
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if status == RepoActive {
+		_, err = tx.ExecContext(ctx, `DELETE FROM repo_states WHERE full_name = ?`, fullName)
+	} else {
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO repo_states (full_name, status, changed_at) VALUES (?, ?, ?)
+			 ON CONFLICT(full_name) DO UPDATE SET status = excluded.status, changed_at = excluded.changed_at`,
+			fullName, status, time.Now(),
+		)
+	}
+	if err == nil && status == RepoDeleted {
+		if _, err = tx.ExecContext(ctx, `DELETE FROM repo_modules WHERE repo = ?`, fullName); err == nil {
+			_, err = tx.ExecContext(ctx, `DELETE FROM repos WHERE full_name = ?`, fullName)
+		}
+	}
+	if err != nil {
+		return LogAndWrapError(err, ErrorTypeDatabase, "set_repo_status", map[string]any{"repo": fullName})
+	}
+	return tx.Commit()
+}
+
+// Rename moves a repository's registration, enabled modules and state to its new full
+// name after it was renamed or transferred.
+func (r *RepoRegistry) Rename(ctx context.Context, from, to string) error {
+	return RenameRepoIn(ctx, r.db, from, to, "repos.full_name", "repo_modules.repo", "repo_states.full_name")
+}
+
+// RenameRepoIn replaces a repository's old full name with its new one in the given
+// table.column references, in one transaction. Rows already stored under the new name
+// are replaced. Modules use it to implement ModuleRepoRenamer.
+func RenameRepoIn(ctx context.Context, db *sql.DB, from, to string, columns ...string) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return LogAndWrapError(err, ErrorTypeDatabase, "rename_repo", map[string]any{"from": from, "to": to})
+	}
+	defer func() { _ = tx.Rollback() }()
+	for _, ref := range columns {
+		table, column, ok := strings.Cut(ref, ".")
+		if !ok {
+			return fmt.Errorf("rename_repo: %q is not a table.column reference", ref)
+		}
+		stmt := fmt.Sprintf(`UPDATE OR REPLACE %s SET %s = ? WHERE %s = ?`, table, column, column)
+		if _, err := tx.ExecContext(ctx, stmt, to, from); err != nil {
+			return LogAndWrapError(err, ErrorTypeDatabase, "rename_repo", map[string]any{
+				"from": from, "to": to, "table": table,
+			})
+		}
+	}
+	return tx.Commit()
+}
+
+// modules returns the enabled modules of a repository, ordered by name.
+func (r *RepoRegistry) modules(ctx context.Context, fullName string) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT module FROM repo_modules WHERE repo = ? ORDER BY module ASC`, fullName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var modules []string
+	for rows.Next() {
+		var m string
+		if err := rows.Scan(&m); err != nil {
+			return nil, err
+		}
+		modules = append(modules, m)
+	}
+	return modules, rows.Err()
+}
@@ -0,0 +1,121 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRepoRegistry(t *testing.T) {
+	registry, err := NewRepoRegistry(TestDB(t))
+	if err != nil {
+		t.Fatalf("NewRepoRegistry failed: %v", err)
+	}
+	ctx := t.Context()
+
+	if err := registry.Register(ctx, "org/repo", "alice", []string{"sla", "oncall"}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	repo, err := registry.Get(ctx, "org/repo")
+	if err != nil || repo == nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if repo.OnboardedBy != "alice" || !slices.Equal(repo.Modules, []string{"oncall", "sla"}) {
+		t.Errorf("unexpected repo: %+v", repo)
+	}
+
+	// Re-registering replaces the module set.
+	if err := registry.Register(ctx, "org/repo", "bob", []string{"dependencies"}); err != nil {
+		t.Fatalf("second Register failed: %v", err)
+	}
+	repos, err := registry.List(ctx)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(repos) != 1 || repos[0].OnboardedBy != "bob" || !slices.Equal(repos[0].Modules, []string{"dependencies"}) {
+		t.Errorf("unexpected repos after re-register: %+v", repos)
+	}
+
+	tests := []struct {
+		repo, module string
+		want         bool
+	}{
+		{"org/repo", "dependencies", true},
+		{"org/repo", "sla", false},
+		{"org/unregistered", "sla", true},
+	}
+	for _, tt := range tests {
+		got, err := registry.ModuleEnabled(ctx, tt.repo, tt.module)
+		if err != nil {
+			t.Fatalf("ModuleEnabled failed: %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("ModuleEnabled(%s, %s) = %v, want %v", tt.repo, tt.module, got, tt.want)
+		}
+	}
+
+	if missing, err := registry.Get(ctx, "org/unregistered"); err != nil || missing != nil {
+		t.Errorf("Get(unregistered) = %v, %v; want nil, nil", missing, err)
+	}
+}
+
+func TestRepoRegistryStatus(t *testing.T) {
+	db := TestDB(t)
+	registry, err := NewRepoRegistry(db)
+	if err != nil {
+		t.Fatalf("NewRepoRegistry failed: %v", err)
+	}
+	ctx := t.Context()
+	if err := registry.Register(ctx, "org/repo", "alice", []string{"sla"}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	if err := registry.SetStatus(ctx, "org/repo", RepoArchived); err != nil {
+		t.Fatalf("SetStatus failed: %v", err)
+	}
+	if status, err := registry.Status(ctx, "org/repo"); err != nil || status != RepoArchived {
+		t.Errorf("Status = %q, %v; want archived", status, err)
+	}
+	if repo, _ := registry.Get(ctx, "org/repo"); repo == nil || repo.Status != RepoArchived {
+		t.Errorf("Get after archive = %+v", repo)
+	}
+	if enabled, _ := registry.ModuleEnabled(ctx, "org/repo", "sla"); enabled {
+		t.Error("modules should be disabled for an archived repo")
+	}
+
+	if err := registry.SetStatus(ctx, "org/repo", RepoActive); err != nil {
+		t.Fatalf("SetStatus failed: %v", err)
+	}
+	if enabled, _ := registry.ModuleEnabled(ctx, "org/repo", "sla"); !enabled {
+		t.Error("modules should be enabled again after unarchiving")
+	}
+
+	if err := registry.Rename(ctx, "org/repo", "org/renamed"); err != nil {
+		t.Fatalf("Rename failed: %v", err)
+	}
+	repo, err := registry.Get(ctx, "org/renamed")
+	if err != nil || repo == nil || !slices.Equal(repo.Modules, []string{"sla"}) {
+		t.Errorf("Get after rename = %+v, %v", repo, err)
+	}
+	if old, _ := registry.Get(ctx, "org/repo"); old != nil {
+		t.Errorf("old name still registered: %+v", old)
+	}
+
+	if err := registry.SetStatus(ctx, "org/renamed", RepoDeleted); err != nil {
+		t.Fatalf("SetStatus failed: %v", err)
+	}
+	if repo, _ := registry.Get(ctx, "org/renamed"); repo != nil {
+		t.Errorf("deleted repo still registered: %+v", repo)
+	}
+	if status, _ := registry.Status(ctx, "org/renamed"); status != RepoDeleted {
+		t.Errorf("Status after delete = %q", status)
+	}
+	// Unregistered repositories are tracked too.
+	if err := registry.SetStatus(ctx, "org/other", RepoArchived); err != nil {
+		t.Fatalf("SetStatus failed: %v", err)
+	}
+	if enabled, _ := registry.ModuleEnabled(ctx, "org/other", "sla"); enabled {
+		t.Error("modules should be disabled for an archived unregistered repo")
+	}
+}
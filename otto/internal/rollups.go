@@ -0,0 +1,350 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// rollups.go rolls key metrics up into daily totals stored in the database: events per
+// repository, commands per module, and whatever modules contribute, such as on-call ack
+// latency. Unlike the metrics backend, which expires data after its retention, the
+// rollups are kept for long-term trends and served on the admin API and in digests.
+
+package internal
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"strings"
+	"time"
+)
+
+// RollupJobName is the scheduler name of the job that rolls up complete days.
+const RollupJobName = "metric_rollups"
+
+// Metrics rolled up for every instance; modules add their own.
+const (
+	RollupEvents   = "events"   // webhook events received, by repository
+	RollupCommands = "commands" // slash commands executed, by the module handling them
+)
+
+// Rollup is the value of a metric on a UTC day, for one key such as a repository.
+type Rollup struct {
+	Day    string  `json:"day"` // UTC date, e.g. 2025-01-31
+	Metric string  `json:"metric"`
+	Key    string  `json:"key,omitempty"`
+	Value  float64 `json:"value"`
+}
+
+// ModuleRollupSource is an optional interface for modules that contribute metrics to the
+// daily rollups. Rollups returns the values of [start, end), a UTC day; Day may be empty.
+type ModuleRollupSource interface {
+	Rollups(ctx context.Context, start, end time.Time) ([]Rollup, error)
+}
+
+// RollupQuery filters rollups returned by MetricRollups.Query. Zero values match everything.
+type RollupQuery struct {
+	Metric string
+	Key    string
+	Since  time.Time // first day included
+	Until  time.Time // last day included
+}
+
+// MetricRollups reads and writes the metric_rollups and metric_rollup_days tables.
+type MetricRollups struct {
+	db       *sql.DB
+	registry *ModuleRegistry
+	backfill int
+	now      func() time.Time
+}
+
+// NewMetricRollups creates the rollups of the modules in registry, creating the tables if
+// needed. The first run rolls up the backfill days before today.
+func NewMetricRollups(db *sql.DB, registry *ModuleRegistry, backfill int) (*MetricRollups, error) {
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS metric_rollups (
+			day TEXT NOT NULL,
+			metric TEXT NOT NULL,
+			key TEXT NOT NULL DEFAULT '',
+			value REAL NOT NULL,
+			PRIMARY KEY (day, metric, key)
+		);`,
+		`CREATE INDEX IF NOT EXISTS idx_metric_rollups_metric ON metric_rollups (metric, key, day);`,
+		`CREATE TABLE IF NOT EXISTS metric_rollup_days (
+			day TEXT PRIMARY KEY,
+			rolled_up_at TIMESTAMP NOT NULL
+		);`,
+	}
+	for _, s := range stmts {
+		if _, err := db.Exec(s); err != nil {
+			return nil, fmt.Errorf("failed migration: %w (SQL: %s)", err, s)
+		}
+	}
+	return &MetricRollups{db: db, registry: registry, backfill: backfill, now: time.Now}, nil
+}
+
+// Job returns the scheduler job that rolls up complete days.
+func (r *MetricRollups) Job(interval time.Duration) Job {
+	return Job{Name: RollupJobName, Interval: interval, Run: r.Run}
+}
+
+// Run rolls up every complete UTC day since the last one rolled up, or the backfill days
+// before today on the first run.
+func (r *MetricRollups) Run(ctx context.Context) error {
+	today := utcDay(r.now())
+	day := today.AddDate(0, 0, -r.backfill)
+	var last sql.NullString
+	if err := r.db.QueryRowContext(ctx, `SELECT MAX(day) FROM metric_rollup_days`).Scan(&last); err != nil {
+		return LogAndWrapError(err, ErrorTypeDatabase, "last_rollup_day", nil)
+	}
+	if last.Valid {
+		t, err := time.Parse(time.DateOnly, last.String)
+		if err != nil {
+			return fmt.Errorf("invalid rollup day %q: %w", last.String, err)
+		}
+		day = t.AddDate(0, 0, 1)
+	}
+	for ; day.Before(today); day = day.AddDate(0, 0, 1) {
+		if err := r.RollupDay(ctx, day); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RollupDay computes the rollups of the UTC day containing day and replaces those stored.
+// Metrics modules fail to contribute are logged and left out.
+func (r *MetricRollups) RollupDay(ctx context.Context, day time.Time) error {
+	start := utcDay(day)
+	end := start.AddDate(0, 0, 1)
+	rollups, err := r.compute(ctx, start, end)
+	if err != nil {
+		return err
+	}
+
+	date := start.Format(time.DateOnly)
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return LogAndWrapError(err, ErrorTypeDatabase, "store_rollups", map[string]any{"day": date})
+	}
+	defer func() { _ = tx.Rollback() }()
+	if _, err := tx.ExecContext(ctx, `DELETE FROM metric_rollups WHERE day = ?`, date); err != nil {
+		return LogAndWrapError(err, ErrorTypeDatabase, "store_rollups", map[string]any{"day": date})
+	}
+	for _, ru := range rollups {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT OR REPLACE INTO metric_rollups (day, metric, key, value) VALUES (?, ?, ?, ?)`,
+			date, ru.Metric, ru.Key, ru.Value,
+		); err != nil {
+			return LogAndWrapError(err, ErrorTypeDatabase, "store_rollups", map[string]any{
+				"day": date, "metric": ru.Metric,
+			})
+		}
+	}
+	if _, err := tx.ExecContext(ctx,
+		`INSERT OR REPLACE INTO metric_rollup_days (day, rolled_up_at) VALUES (?, ?)`, date, r.now(),
+	); err != nil {
+		return LogAndWrapError(err, ErrorTypeDatabase, "store_rollups", map[string]any{"day": date})
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	slog.Debug("Rolled up metrics", "day", date, "rollups", len(rollups))
+	return nil
+}
+
+// compute returns the rollups of [start, end).
+func (r *MetricRollups) compute(ctx context.Context, start, end time.Time) ([]Rollup, error) {
+	var rollups []Rollup
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT repo, COUNT(*) FROM events
+		 WHERE received_at >= ? AND received_at < ? AND repo IS NOT NULL AND repo != ''
+		 GROUP BY repo`, start, end)
+	if err != nil {
+		return nil, LogAndWrapError(err, ErrorTypeDatabase, "rollup_events", nil)
+	}
+	for rows.Next() {
+		ru := Rollup{Metric: RollupEvents}
+		if err := rows.Scan(&ru.Key, &ru.Value); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		rollups = append(rollups, ru)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	commands, err := r.commandsPerModule(ctx, start, end)
+	if err != nil {
+		return nil, err
+	}
+	for module, n := range commands {
+		rollups = append(rollups, Rollup{Metric: RollupCommands, Key: module, Value: float64(n)})
+	}
+
+	for name, m := range r.registry.GetModules() {
+		source, ok := m.(ModuleRollupSource)
+		if !ok {
+			continue
+		}
+		contributed, err := source.Rollups(ctx, start, end)
+		if err != nil {
+			slog.Error("Failed to roll up module metrics", "module", name, "day", start.Format(time.DateOnly),
+				"err", err)
+			continue
+		}
+		rollups = append(rollups, contributed...)
+	}
+	return rollups, nil
+}
+
+// commandsPerModule counts the commands executed in [start, end) by the module handling
+// them. Commands no module declares, such as other bots' commands, are not counted.
+func (r *MetricRollups) commandsPerModule(ctx context.Context, start, end time.Time) (map[string]int, error) {
+	handlers := make(map[string]string)
+	for name, m := range r.registry.GetModules() {
+		if c, ok := m.(ModuleCommander); ok {
+			for _, command := range c.SlashCommands() {
+				handlers[command] = name
+			}
+		}
+	}
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT command, args FROM command_history WHERE executed_at >= ? AND executed_at < ?`, start, end)
+	if err != nil {
+		return nil, LogAndWrapError(err, ErrorTypeDatabase, "rollup_commands", nil)
+	}
+	defer rows.Close()
+	counts := make(map[string]int)
+	for rows.Next() {
+		var (
+			command  string
+			argsJSON sql.NullString
+			args     []string
+		)
+		if err := rows.Scan(&command, &argsJSON); err != nil {
+			return nil, err
+		}
+		if argsJSON.Valid {
+			_ = json.Unmarshal([]byte(argsJSON.String), &args)
+		}
+		if module, ok := commandModule(handlers, command, args); ok {
+			counts[module]++
+		}
+	}
+	return counts, rows.Err()
+}
+
+// Query returns rollups matching q, ordered by day, metric and key.
+func (r *MetricRollups) Query(ctx context.Context, q RollupQuery) ([]Rollup, error) {
+	var (
+		where []string
+		args  []any
+	)
+	if q.Metric != "" {
+		where = append(where, "metric = ?")
+		args = append(args, q.Metric)
+	}
+	if q.Key != "" {
+		where = append(where, "key = ?")
+		args = append(args, q.Key)
+	}
+	if !q.Since.IsZero() {
+		where = append(where, "day >= ?")
+		args = append(args, q.Since.UTC().Format(time.DateOnly))
+	}
+	if !q.Until.IsZero() {
+		where = append(where, "day <= ?")
+		args = append(args, q.Until.UTC().Format(time.DateOnly))
+	}
+	query := `SELECT day, metric, key, value FROM metric_rollups`
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	return r.query(ctx, query+" ORDER BY day, metric, key", args...)
+}
+
+// rollupListSchema describes rollups to the admin API's list query layer.
+var rollupListSchema = ListSchema{
+	Fields: ListColumns("day", "metric", "key", "value"),
+	Time:   "day",
+	Dates:  true,
+	Sort:   "day",
+	Key:    "metric, key",
+}
+
+// Select returns the rollups matching a list query.
+func (r *MetricRollups) Select(ctx context.Context, q ListQuery) ([]Rollup, error) {
+	query, args := q.SQL(`SELECT day, metric, key, value FROM metric_rollups`)
+	return r.query(ctx, query, args...)
+}
+
+// selectRecent is Select with since defaulting to 30 days ago.
+func (r *MetricRollups) selectRecent(ctx context.Context, q ListQuery) ([]Rollup, error) {
+	if q.Since.IsZero() {
+		q.Since = r.now().AddDate(0, 0, -30)
+	}
+	return r.Select(ctx, q)
+}
+
+func (r *MetricRollups) query(ctx context.Context, query string, args ...any) ([]Rollup, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, LogAndWrapError(err, ErrorTypeDatabase, "query_rollups", nil)
+	}
+	defer rows.Close()
+	rollups := []Rollup{}
+	for rows.Next() {
+		var ru Rollup
+		if err := rows.Scan(&ru.Day, &ru.Metric, &ru.Key, &ru.Value); err != nil {
+			return nil, err
+		}
+		rollups = append(rollups, ru)
+	}
+	return rollups, rows.Err()
+}
+
+// Total sums a metric's values for a key over the days from start up to, but excluding,
+// the day of end. ok is false if none of those days was rolled up yet.
+func (r *MetricRollups) Total(ctx context.Context, metric, key string, start, end time.Time) (float64, bool, error) {
+	from, to := utcDay(start).Format(time.DateOnly), utcDay(end).Format(time.DateOnly)
+	var (
+		days  int
+		total float64
+	)
+	err := r.db.QueryRowContext(ctx,
+		`SELECT (SELECT COUNT(*) FROM metric_rollup_days WHERE day >= ? AND day < ?),
+		        (SELECT COALESCE(SUM(value), 0) FROM metric_rollups
+		         WHERE metric = ? AND key = ? AND day >= ? AND day < ?)`,
+		from, to, metric, key, from, to,
+	).Scan(&days, &total)
+	if err != nil {
+		return 0, false, LogAndWrapError(err, ErrorTypeDatabase, "total_rollups", map[string]any{"metric": metric})
+	}
+	return total, days > 0, nil
+}
+
+// Percentile returns the p-th percentile (0 < p <= 100) of values by the nearest-rank
+// method, or 0 without values. values must be sorted.
+func Percentile(values []float64, p float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(values))))
+	return values[min(max(rank, 1), len(values))-1]
+}
+
+// utcDay returns the start of the UTC day containing t.
+func utcDay(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
+
+// RegisterAdminRoutes exposes the rollups on the admin API, by default those of the last 30
+// days. A nil MetricRollups registers nothing.
+func (r *MetricRollups) RegisterAdminRoutes(srv *Server) {
+	if r == nil {
+		return
+	}
+	srv.HandleAdmin("GET /admin/rollups", ListHandler(rollupListSchema, r.selectRecent))
+}
@@ -0,0 +1,181 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+)
+
+type rollupModule struct {
+	mockModule
+}
+
+func (m *rollupModule) SlashCommands() []string { return []string{"hold", "otto status"} }
+
+func (m *rollupModule) Rollups(_ context.Context, start, _ time.Time) ([]Rollup, error) {
+	return []Rollup{{Metric: "latency_p50", Value: float64(start.Day())}}, nil
+}
+
+func TestMetricRollups(t *testing.T) {
+	db := TestDB(t)
+	events, err := NewEventStore(db)
+	if err != nil {
+		t.Fatalf("NewEventStore failed: %v", err)
+	}
+	commands, err := NewCommandHistory(db)
+	if err != nil {
+		t.Fatalf("NewCommandHistory failed: %v", err)
+	}
+	registry := NewModuleRegistry()
+	registry.RegisterModule(&rollupModule{mockModule{name: "holds"}})
+	rollups, err := NewMetricRollups(db, registry, 2)
+	if err != nil {
+		t.Fatalf("NewMetricRollups failed: %v", err)
+	}
+	today := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	rollups.now = func() time.Time { return today }
+	ctx := t.Context()
+
+	yesterday := today.AddDate(0, 0, -1)
+	for _, e := range []StoredEvent{
+		{Type: "issues", Repo: "org/a", ReceivedAt: yesterday},
+		{Type: "issues", Repo: "org/a", ReceivedAt: yesterday},
+		{Type: "issues", Repo: "org/b", ReceivedAt: yesterday.AddDate(0, 0, -1)},
+		{Type: "issues", Repo: "org/a", ReceivedAt: today}, // not complete yet
+		{Type: "installation", ReceivedAt: yesterday},      // no repository
+	} {
+		if _, err := events.Record(ctx, e); err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+	}
+	for _, r := range []CommandRecord{
+		{Repo: "org/a", Command: "hold", Outcome: CommandOK, ExecutedAt: yesterday},
+		{Repo: "org/a", Command: "otto", Args: []string{"Status"}, Outcome: CommandOK, ExecutedAt: yesterday},
+		{Repo: "org/a", Command: "otto", Args: []string{"history"}, Outcome: CommandOK, ExecutedAt: yesterday},
+		{Repo: "org/a", Command: "unknown", Outcome: CommandOK, ExecutedAt: yesterday},
+	} {
+		if err := commands.Record(ctx, r); err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+	}
+
+	if err := rollups.Run(ctx); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	got, err := rollups.Query(ctx, RollupQuery{})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	want := []Rollup{
+		{Day: "2025-03-08", Metric: RollupEvents, Key: "org/b", Value: 1},
+		{Day: "2025-03-08", Metric: "latency_p50", Value: 8},
+		{Day: "2025-03-09", Metric: RollupCommands, Key: "holds", Value: 2},
+		{Day: "2025-03-09", Metric: RollupEvents, Key: "org/a", Value: 2},
+		{Day: "2025-03-09", Metric: "latency_p50", Value: 9},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("rollups = %+v, want %+v", got, want)
+	}
+
+	// Days already rolled up are not rolled up again.
+	if _, err := events.Record(ctx, StoredEvent{Type: "issues", Repo: "org/a", ReceivedAt: yesterday}); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	if err := rollups.Run(ctx); err != nil {
+		t.Fatalf("second Run failed: %v", err)
+	}
+	total, ok, err := rollups.Total(ctx, RollupEvents, "org/a", yesterday.AddDate(0, 0, -1), today)
+	if err != nil || !ok || total != 2 {
+		t.Errorf("Total = %v, %v, %v; want 2, true", total, ok, err)
+	}
+	if _, ok, _ := rollups.Total(ctx, RollupEvents, "org/a", today, today.AddDate(0, 0, 1)); ok {
+		t.Error("Total should report days that were not rolled up")
+	}
+
+	// Rolling up a day again replaces its rollups.
+	if err := rollups.RollupDay(ctx, yesterday); err != nil {
+		t.Fatalf("RollupDay failed: %v", err)
+	}
+	got, _ = rollups.Query(ctx, RollupQuery{Metric: RollupEvents, Key: "org/a"})
+	if len(got) != 1 || got[0].Value != 3 {
+		t.Errorf("rollups after RollupDay = %+v", got)
+	}
+}
+
+func TestMetricRollupsAdmin(t *testing.T) {
+	db := TestDB(t)
+	rollups, err := NewMetricRollups(db, NewModuleRegistry(), 0)
+	if err != nil {
+		t.Fatalf("NewMetricRollups failed: %v", err)
+	}
+	_, err = db.Exec(`INSERT INTO metric_rollups (day, metric, key, value) VALUES
+		('2025-01-01', 'events', 'org/a', 3), ('2025-02-01', 'events', 'org/a', 5), ('2025-02-01', 'commands', 'sla', 1)`)
+	if err != nil {
+		t.Fatalf("insert failed: %v", err)
+	}
+
+	tests := []struct {
+		query      string
+		wantStatus int
+		wantValues []float64
+	}{
+		{"?metric=events&since=2025-01-01", http.StatusOK, []float64{3, 5}},
+		{"?metric=events&since=2025-01-01&until=2025-01-31", http.StatusOK, []float64{3}},
+		{"?key=sla&since=2025-01-01", http.StatusOK, []float64{1}},
+		{"?since=2025-01-01&sort=-value&limit=2", http.StatusOK, []float64{5, 3}},
+		{"?metric=events", http.StatusOK, []float64{}}, // since defaults to 30 days ago
+		{"?since=yesterday", http.StatusBadRequest, nil},
+	}
+	list := ListHandler(rollupListSchema, rollups.selectRecent)
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			list(rr, httptest.NewRequest(http.MethodGet, "/admin/rollups"+tt.query, nil))
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rr.Code, tt.wantStatus, rr.Body.String())
+			}
+			if tt.wantValues == nil {
+				return
+			}
+			var got []Rollup
+			if err := json.NewDecoder(rr.Body).Decode(&got); err != nil {
+				t.Fatalf("decode failed: %v", err)
+			}
+			values := []float64{}
+			for _, r := range got {
+				values = append(values, r.Value)
+			}
+			if !slices.Equal(values, tt.wantValues) {
+				t.Errorf("values = %v, want %v", values, tt.wantValues)
+			}
+		})
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	values := []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	tests := []struct {
+		p    float64
+		want float64
+	}{
+		{50, 5},
+		{90, 9},
+		{99, 10},
+		{100, 10},
+		{1, 1},
+	}
+	for _, tt := range tests {
+		if got := Percentile(values, tt.p); got != tt.want {
+			t.Errorf("Percentile(%v) = %v, want %v", tt.p, got, tt.want)
+		}
+	}
+	if got := Percentile(nil, 50); got != 0 {
+		t.Errorf("Percentile(nil) = %v, want 0", got)
+	}
+}
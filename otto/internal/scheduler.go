@@ -0,0 +1,197 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// scheduler.go runs Otto's periodic background jobs.
+
+package internal
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Job is a named unit of background work that runs on a fixed interval.
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+	// Module, if set, attributes the job's GitHub API calls to that module's budget.
+	Module string
+	// Deferrable jobs are skipped while the scheduler is paused, e.g. during a GitHub incident.
+	Deferrable bool
+}
+
+// Scheduler runs registered jobs on their configured intervals.
+type Scheduler struct {
+	mu        sync.Mutex
+	jobs      []Job
+	telemetry *TelemetryManager
+	paused    func() bool
+	reports   *ErrorReports
+	ctx       context.Context
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+}
+
+// NewScheduler creates a scheduler. Telemetry may be nil.
+func NewScheduler(telemetry *TelemetryManager) *Scheduler {
+	return &Scheduler{telemetry: telemetry}
+}
+
+// Register adds a job to the scheduler. Jobs registered after Start begin immediately.
+func (s *Scheduler) Register(job Job) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if job.Interval <= 0 {
+		slog.Error("job has no interval, not scheduling", "job", job.Name)
+		return
+	}
+	s.jobs = append(s.jobs, job)
+	if s.ctx != nil {
+		s.startJob(job)
+	}
+	slog.Info("job scheduled", "job", job.Name, "interval", job.Interval)
+}
+
+// PauseDeferrable makes the scheduler skip deferrable jobs whenever paused returns true.
+func (s *Scheduler) PauseDeferrable(paused func() bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.paused = paused
+}
+
+// ReportErrors sends job errors and panics to reports.
+func (s *Scheduler) ReportErrors(reports *ErrorReports) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reports = reports
+}
+
+// Jobs returns the names of all registered jobs.
+func (s *Scheduler) Jobs() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	names := make([]string, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		names = append(names, job.Name)
+	}
+	return names
+}
+
+// Start launches a goroutine per registered job.
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ctx != nil {
+		return
+	}
+	s.ctx, s.cancel = context.WithCancel(ctx)
+	for _, job := range s.jobs {
+		s.startJob(job)
+	}
+}
+
+// Stop cancels all running jobs and waits for them to return.
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	cancel := s.cancel
+	s.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+	s.wg.Wait()
+}
+
+// RunNow executes the named job once, synchronously.
+func (s *Scheduler) RunNow(ctx context.Context, name string) bool {
+	s.mu.Lock()
+	var found *Job
+	for i := range s.jobs {
+		if s.jobs[i].Name == name {
+			found = &s.jobs[i]
+			break
+		}
+	}
+	s.mu.Unlock()
+
+	if found == nil {
+		return false
+	}
+	s.runJob(ctx, *found)
+	return true
+}
+
+// startJob must be called with s.mu held.
+func (s *Scheduler) startJob(job Job) {
+	ctx := s.ctx
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(job.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if s.deferred(job) {
+					slog.Info("scheduled job deferred", "job", job.Name, "github_incident", KnownGitHubIncident())
+					if s.telemetry != nil {
+						s.telemetry.RecordJobRun(ctx, job.Name, "deferred", 0)
+					}
+					continue
+				}
+				s.runJob(ctx, job)
+			}
+		}
+	}()
+}
+
+// deferred reports whether a job should be skipped because the scheduler is paused.
+func (s *Scheduler) deferred(job Job) bool {
+	if !job.Deferrable {
+		return false
+	}
+	s.mu.Lock()
+	paused := s.paused
+	s.mu.Unlock()
+	return paused != nil && paused()
+}
+
+// runRecovered runs a job, recovering from a panic in it.
+func runRecovered(ctx context.Context, job Job) (err error) {
+	defer RecoverPanic(job.Module, &err)
+	return job.Run(ctx)
+}
+
+// runJob executes a job once and records its outcome.
+func (s *Scheduler) runJob(ctx context.Context, job Job) {
+	start := time.Now()
+	status := "success"
+	runCtx := ctx
+	if job.Module != "" {
+		runCtx = WithModule(ctx, job.Module)
+	}
+	s.mu.Lock()
+	reports := s.reports
+	s.mu.Unlock()
+	if err := runRecovered(runCtx, job); err != nil {
+		status = "error"
+		reports.Report(ErrorReport{Err: err, Module: job.Module, Job: job.Name})
+		if incident := KnownGitHubIncident(); incident != "" {
+			slog.Warn("scheduled job failed during GitHub incident", "job", job.Name, "err", err,
+				"github_incident", incident)
+		} else {
+			slog.Error("scheduled job failed", "job", job.Name, "err", err)
+		}
+	}
+	if s.telemetry != nil {
+		s.telemetry.RecordJobRun(ctx, job.Name, status, float64(time.Since(start).Milliseconds()))
+	}
+}
@@ -0,0 +1,73 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSchedulerRunsJobs(t *testing.T) {
+	scheduler := NewScheduler(TestTelemetry(t, nil))
+
+	var runs int32
+	done := make(chan struct{})
+	scheduler.Register(Job{
+		Name:     "tick",
+		Interval: 5 * time.Millisecond,
+		Run: func(ctx context.Context) error {
+			if atomic.AddInt32(&runs, 1) == 3 {
+				close(done)
+			}
+			return nil
+		},
+	})
+
+	scheduler.Start(t.Context())
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("job did not run three times")
+	}
+	scheduler.Stop()
+
+	after := atomic.LoadInt32(&runs)
+	time.Sleep(20 * time.Millisecond)
+	if got := atomic.LoadInt32(&runs); got != after {
+		t.Errorf("job kept running after Stop: %d runs, then %d", after, got)
+	}
+}
+
+func TestSchedulerRegisterValidation(t *testing.T) {
+	scheduler := NewScheduler(nil)
+	scheduler.Register(Job{Name: "no-interval", Run: func(context.Context) error { return nil }})
+	if jobs := scheduler.Jobs(); len(jobs) != 0 {
+		t.Errorf("expected job without interval to be rejected, got %v", jobs)
+	}
+}
+
+func TestSchedulerRunNow(t *testing.T) {
+	scheduler := NewScheduler(nil)
+	var ran bool
+	scheduler.Register(Job{
+		Name:     "once",
+		Interval: time.Hour,
+		Run: func(context.Context) error {
+			ran = true
+			return errors.New("boom")
+		},
+	})
+
+	if !scheduler.RunNow(t.Context(), "once") {
+		t.Fatal("RunNow did not find registered job")
+	}
+	if !ran {
+		t.Error("job was not executed")
+	}
+	if scheduler.RunNow(t.Context(), "missing") {
+		t.Error("RunNow reported success for unknown job")
+	}
+}
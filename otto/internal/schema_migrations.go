@@ -0,0 +1,182 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// schema_migrations.go applies versioned migrations: numbered SQL files, usually embedded
+// with go:embed, that each run once and are recorded in the schema_migrations table. They
+// suit changes that cannot be written to do nothing once applied, such as rebuilding a
+// table or moving data, and run as part of a store's or module's Migration, so they are
+// planned, applied and refused like any other.
+
+package internal
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const schemaMigrationsTable = `CREATE TABLE IF NOT EXISTS schema_migrations (
+	scope TEXT NOT NULL,
+	version INTEGER NOT NULL,
+	name TEXT NOT NULL,
+	checksum TEXT NOT NULL,
+	applied_at TIMESTAMP NOT NULL,
+	PRIMARY KEY (scope, version)
+);`
+
+// VersionedMigration is a SQL file of a versioned migration.
+type VersionedMigration struct {
+	Version  int
+	Name     string // file name, e.g. "0002_index_tasks.sql"
+	SQL      string
+	Checksum string // SHA-256 of SQL
+}
+
+// AppliedMigration is a row of schema_migrations.
+type AppliedMigration struct {
+	Scope     string
+	Version   int
+	Name      string
+	Checksum  string
+	AppliedAt time.Time
+}
+
+// VersionedMigrations returns a Migrate function that applies the SQL files in fsys named
+// like 0001_create_tables.sql which are not yet recorded under scope, e.g. a module's name,
+// in order of version. Each file runs in a transaction with the row recording it. A file
+// changed after it was applied is an error; recorded versions without a file, such as
+// those of a newer release, are left alone.
+func VersionedMigrations(scope string, fsys fs.FS) func(db *sql.DB) error {
+	return func(db *sql.DB) error {
+		migrations, err := ReadVersionedMigrations(fsys)
+		if err != nil {
+			return err
+		}
+		if _, err := db.Exec(schemaMigrationsTable); err != nil {
+			return fmt.Errorf("failed migration: %w (SQL: %s)", err, schemaMigrationsTable)
+		}
+		applied, err := AppliedMigrations(db, scope)
+		if err != nil {
+			return err
+		}
+		for _, m := range migrations {
+			i := slices.IndexFunc(applied, func(a AppliedMigration) bool { return a.Version == m.Version })
+			if i >= 0 {
+				if applied[i].Checksum != m.Checksum {
+					return fmt.Errorf("migration %s of %s was changed after it was applied", m.Name, scope)
+				}
+				continue
+			}
+			if err := applyVersionedMigration(db, scope, m); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// ReadVersionedMigrations returns the SQL files anywhere in fsys in order of version.
+func ReadVersionedMigrations(fsys fs.FS) ([]VersionedMigration, error) {
+	var migrations []VersionedMigration
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || path.Ext(p) != ".sql" {
+			return err
+		}
+		name := path.Base(p)
+		prefix, _, _ := strings.Cut(name, "_")
+		version, err := strconv.Atoi(prefix)
+		if err != nil || version <= 0 {
+			return fmt.Errorf("migration %s: name does not start with a version, e.g. 0001_", p)
+		}
+		if i := slices.IndexFunc(migrations, func(m VersionedMigration) bool { return m.Version == version }); i >= 0 {
+			return fmt.Errorf("migrations %s and %s have the same version", migrations[i].Name, name)
+		}
+		data, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(data)
+		migrations = append(migrations, VersionedMigration{
+			Version:  version,
+			Name:     name,
+			SQL:      string(data),
+			Checksum: hex.EncodeToString(sum[:]),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(migrations, func(a, b VersionedMigration) int { return a.Version - b.Version })
+	return migrations, nil
+}
+
+// AppliedMigrations returns the versioned migrations recorded under scope, in order of
+// version, or none if no versioned migration was ever applied.
+func AppliedMigrations(db *sql.DB, scope string) ([]AppliedMigration, error) {
+	rows, err := db.Query(`SELECT scope, version, name, checksum, applied_at FROM schema_migrations
+		WHERE scope = ? ORDER BY version`, scope)
+	if err != nil {
+		if strings.Contains(err.Error(), "no such table") {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+	defer rows.Close()
+	var applied []AppliedMigration
+	for rows.Next() {
+		var a AppliedMigration
+		if err := rows.Scan(&a.Scope, &a.Version, &a.Name, &a.Checksum, &a.AppliedAt); err != nil {
+			return nil, err
+		}
+		applied = append(applied, a)
+	}
+	return applied, rows.Err()
+}
+
+func applyVersionedMigration(db *sql.DB, scope string, m VersionedMigration) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(m.SQL); err != nil {
+		return fmt.Errorf("failed migration %s of %s: %w", m.Name, scope, err)
+	}
+	_, err = tx.Exec(`INSERT INTO schema_migrations (scope, version, name, checksum, applied_at)
+		VALUES (?, ?, ?, ?, ?)`, scope, m.Version, m.Name, m.Checksum, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("failed to record migration %s of %s: %w", m.Name, scope, err)
+	}
+	return tx.Commit()
+}
+
+// copyAppliedMigrations copies the rows of schema_migrations from db to the in-memory copy
+// of its schema a plan is made on, so that applied versions are not planned again.
+func copyAppliedMigrations(ctx context.Context, db, scratch *sql.DB) error {
+	rows, err := db.QueryContext(ctx,
+		`SELECT scope, version, name, checksum, applied_at FROM schema_migrations`)
+	if err != nil {
+		return fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var a AppliedMigration
+		if err := rows.Scan(&a.Scope, &a.Version, &a.Name, &a.Checksum, &a.AppliedAt); err != nil {
+			return err
+		}
+		_, err := scratch.ExecContext(ctx, `INSERT INTO schema_migrations (scope, version, name, checksum, applied_at)
+			VALUES (?, ?, ?, ?, ?)`, a.Scope, a.Version, a.Name, a.Checksum, a.AppliedAt)
+		if err != nil {
+			return fmt.Errorf("failed to copy applied migrations: %w", err)
+		}
+	}
+	return rows.Err()
+}
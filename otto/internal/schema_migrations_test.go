@@ -0,0 +1,79 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestVersionedMigrations(t *testing.T) {
+	ctx := t.Context()
+	db := TestDB(t)
+	fsys := fstest.MapFS{
+		"0002_add_notes.sql": {Data: []byte(`ALTER TABLE widgets ADD COLUMN notes TEXT;
+			UPDATE widgets SET notes = 'none';`)},
+		"0001_create_widgets.sql": {Data: []byte(`CREATE TABLE widgets (id INTEGER PRIMARY KEY);
+			INSERT INTO widgets (id) VALUES (1);`)},
+		"README.md": {Data: []byte("not a migration")},
+	}
+	migrations := []Migration{{Name: "widgets", Migrate: VersionedMigrations("widgets", fsys)}}
+
+	plan, err := PlanMigrations(ctx, db, migrations)
+	if err != nil {
+		t.Fatalf("PlanMigrations failed: %v", err)
+	}
+	if len(plan.Statements) != 3 || !strings.HasPrefix(plan.Statements[1].SQL, "CREATE TABLE widgets") {
+		t.Fatalf("statements = %+v", plan.Statements)
+	}
+
+	for range 2 {
+		if err := ApplyMigrations(db, migrations); err != nil {
+			t.Fatalf("ApplyMigrations failed: %v", err)
+		}
+	}
+	var notes string
+	if err := db.QueryRow(`SELECT notes FROM widgets WHERE id = 1`).Scan(&notes); err != nil || notes != "none" {
+		t.Errorf("notes = %q, %v", notes, err)
+	}
+	applied, err := AppliedMigrations(db, "widgets")
+	if err != nil || len(applied) != 2 || applied[0].Version != 1 || applied[1].Name != "0002_add_notes.sql" {
+		t.Fatalf("applied = %+v, %v", applied, err)
+	}
+	plan, err = PlanMigrations(ctx, db, migrations)
+	if err != nil || plan.Pending() {
+		t.Errorf("plan after migrating = %+v, %v", plan, err)
+	}
+
+	// A migration that fails is rolled back and not recorded.
+	fsys["0003_broken.sql"] = &fstest.MapFile{Data: []byte(`CREATE TABLE gadgets (id INTEGER);
+		INSERT INTO missing VALUES (1);`)}
+	err = ApplyMigrations(db, migrations)
+	if err == nil || !strings.Contains(err.Error(), "0003_broken.sql") {
+		t.Errorf("ApplyMigrations with a failing migration = %v", err)
+	}
+	if applied, _ := AppliedMigrations(db, "widgets"); len(applied) != 2 {
+		t.Errorf("failing migration was recorded: %+v", applied)
+	}
+	if objects, _ := schemaObjects(ctx, db); strings.Contains(objects[len(objects)-1].SQL, "gadgets") {
+		t.Error("failing migration was not rolled back")
+	}
+	delete(fsys, "0003_broken.sql")
+
+	fsys["0001_create_widgets.sql"] = &fstest.MapFile{Data: []byte(`CREATE TABLE widgets (id INTEGER);`)}
+	if err := ApplyMigrations(db, migrations); err == nil || !strings.Contains(err.Error(), "changed after") {
+		t.Errorf("ApplyMigrations with an edited migration = %v", err)
+	}
+}
+
+func TestReadVersionedMigrations(t *testing.T) {
+	for name, fsys := range map[string]fstest.MapFS{
+		"unversioned": {"create_widgets.sql": {}},
+		"duplicate":   {"0001_a.sql": {}, "1_b.sql": {}},
+	} {
+		if _, err := ReadVersionedMigrations(fsys); err == nil {
+			t.Errorf("%s: ReadVersionedMigrations accepted invalid migrations", name)
+		}
+	}
+}
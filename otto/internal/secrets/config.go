@@ -14,18 +14,22 @@ import (
 
 // FileConfig represents the secrets configuration in a YAML file.
 type FileConfig struct {
-	WebhookSecret        string `yaml:"webhook_secret"`
-	GitHubAppID          int64  `yaml:"github_app_id"`
-	GitHubInstallationID int64  `yaml:"github_installation_id"`
-	GitHubPrivateKeyPath string `yaml:"github_private_key_path"`
+	WebhookSecret        string            `yaml:"webhook_secret"`
+	GitHubAppID          int64             `yaml:"github_app_id"`
+	GitHubInstallationID int64             `yaml:"github_installation_id"`
+	GitHubPrivateKeyPath string            `yaml:"github_private_key_path"`
+	AdminToken           string            `yaml:"admin_token"`
+	Secrets              map[string]string `yaml:"secrets"` // named secrets for optional integrations
 }
 
 // OnePasswordConfig represents the 1Password secrets configuration in a YAML file.
 type OnePasswordConfig struct {
-	WebhookSecretRef string `yaml:"webhook_secret_ref"`
-	AppIDRef         string `yaml:"github_app_id_ref"`
-	InstallIDRef     string `yaml:"github_installation_id_ref"`
-	PrivateKeyRef    string `yaml:"github_private_key_ref"`
+	WebhookSecretRef string            `yaml:"webhook_secret_ref"`
+	AppIDRef         string            `yaml:"github_app_id_ref"`
+	InstallIDRef     string            `yaml:"github_installation_id_ref"`
+	PrivateKeyRef    string            `yaml:"github_private_key_ref"`
+	AdminTokenRef    string            `yaml:"admin_token_ref"`
+	SecretRefs       map[string]string `yaml:"secret_refs"` // named secret references for optional integrations
 }
 
 // Manager implementations provide access to sensitive configuration.
@@ -88,6 +92,8 @@ func loadFileConfig(path string) (*FileManager, error) {
 		config.GitHubPrivateKeyPath,
 		nil, // Private key will be loaded below
 	)
+	manager.AdminToken = config.AdminToken
+	manager.Secrets = config.Secrets
 
 	// Load private key from file if path is specified
 	if config.GitHubPrivateKeyPath != "" {
@@ -131,6 +137,8 @@ func loadOnePasswordConfig(path string) (*OnePasswordManager, error) {
 	if err != nil {
 		return nil, err
 	}
+	manager.adminTokenRef = config.AdminTokenRef
+	manager.secretRefs = config.SecretRefs
 
 	slog.Info("1Password secrets configured successfully")
 	return manager, nil
@@ -8,6 +8,7 @@ import (
 	"log/slog"
 	"os"
 	"strconv"
+	"strings"
 )
 
 // Manager is an interface for accessing secrets.
@@ -23,6 +24,19 @@ type Manager interface {
 
 	// GetGitHubPrivateKey returns the GitHub App private key.
 	GetGitHubPrivateKey() []byte
+
+	// GetAdminToken returns the bearer token protecting admin endpoints.
+	GetAdminToken() string
+
+	// GetSecret returns a named secret used by an optional integration
+	// (e.g. "slack_bot_token"), or "" if it is not configured.
+	GetSecret(name string) string
+}
+
+// SecretEnvVar returns the environment variable that overrides a named secret,
+// e.g. "slack_bot_token" -> "OTTO_SECRET_SLACK_BOT_TOKEN".
+func SecretEnvVar(name string) string {
+	return "OTTO_SECRET_" + strings.ToUpper(name)
 }
 
 // EnvManager implements the Manager interface using environment variables.
@@ -31,12 +45,14 @@ type EnvManager struct {
 	gitHubAppID    int64
 	installationID int64
 	privateKey     []byte
+	adminToken     string
 }
 
 // NewEnvManager creates a new EnvManager that reads from environment variables once.
 func NewEnvManager() *EnvManager {
 	e := &EnvManager{
 		webhookSecret: os.Getenv("OTTO_WEBHOOK_SECRET"),
+		adminToken:    os.Getenv("OTTO_ADMIN_TOKEN"),
 	}
 
 	if appIDStr := os.Getenv("OTTO_GITHUB_APP_ID"); appIDStr != "" {
@@ -80,12 +96,24 @@ func (e *EnvManager) GetGitHubPrivateKey() []byte {
 	return e.privateKey
 }
 
+// GetAdminToken returns the admin API token from environment variable.
+func (e *EnvManager) GetAdminToken() string {
+	return e.adminToken
+}
+
+// GetSecret returns a named secret from its OTTO_SECRET_<NAME> environment variable.
+func (e *EnvManager) GetSecret(name string) string {
+	return os.Getenv(SecretEnvVar(name))
+}
+
 // FileManager implements the Manager interface using a local file.
 type FileManager struct {
 	WebhookSecret        string
 	GitHubAppID          int64
 	GitHubInstallationID int64
 	GitHubPrivateKeyPath string
+	AdminToken           string
+	Secrets              map[string]string
 	privateKey           []byte
 
 	// Environment values take precedence and are cached during initialization
@@ -93,10 +121,12 @@ type FileManager struct {
 	envGitHubAppID    int64
 	envInstallationID int64
 	envPrivateKey     []byte
+	envAdminToken     string
 	hasEnvWebhook     bool
 	hasEnvAppID       bool
 	hasEnvInstallID   bool
 	hasEnvPrivateKey  bool
+	hasEnvAdminToken  bool
 }
 
 // NewFileManager creates a new FileManager with the given values.
@@ -141,6 +171,11 @@ func NewFileManager(
 		fm.hasEnvPrivateKey = true
 	}
 
+	if envVal := os.Getenv("OTTO_ADMIN_TOKEN"); envVal != "" {
+		fm.envAdminToken = envVal
+		fm.hasEnvAdminToken = true
+	}
+
 	return fm
 }
 
@@ -176,6 +211,22 @@ func (f *FileManager) GetGitHubPrivateKey() []byte {
 	return f.privateKey
 }
 
+// GetAdminToken returns the admin API token, with environment variable fallback.
+func (f *FileManager) GetAdminToken() string {
+	if f.hasEnvAdminToken {
+		return f.envAdminToken
+	}
+	return f.AdminToken
+}
+
+// GetSecret returns a named secret, with environment variable fallback.
+func (f *FileManager) GetSecret(name string) string {
+	if envVal := os.Getenv(SecretEnvVar(name)); envVal != "" {
+		return envVal
+	}
+	return f.Secrets[name]
+}
+
 // ValidateFileManager checks that all required fields are present and valid.
 func ValidateFileManager(secrets *FileManager) error {
 	// Skip validation if we have webhook secret from environment
@@ -265,6 +316,32 @@ func (c *Chain) GetGitHubPrivateKey() []byte {
 	return nil
 }
 
+// GetAdminToken returns the admin API token from the first manager that returns a non-empty value.
+func (c *Chain) GetAdminToken() string {
+	for _, m := range c.managers {
+		if m == nil {
+			continue
+		}
+		if v := m.GetAdminToken(); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// GetSecret returns a named secret from the first manager that returns a non-empty value.
+func (c *Chain) GetSecret(name string) string {
+	for _, m := range c.managers {
+		if m == nil {
+			continue
+		}
+		if v := m.GetSecret(name); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
 // LoadFileConfig loads secret configuration from a file.
 func LoadFileConfig(path string) (*FileManager, error) {
 	// Function implementation will be moved from config.go
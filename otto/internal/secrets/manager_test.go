@@ -12,6 +12,7 @@ func TestEnvManager(t *testing.T) {
 	t.Setenv("OTTO_GITHUB_APP_ID", "54321")
 	t.Setenv("OTTO_GITHUB_INSTALLATION_ID", "98765")
 	t.Setenv("OTTO_GITHUB_PRIVATE_KEY", "test-private-key")
+	t.Setenv("OTTO_ADMIN_TOKEN", "test-admin-token")
 
 	// Create env manager
 	envManager := NewEnvManager()
@@ -35,6 +36,11 @@ func TestEnvManager(t *testing.T) {
 	if got := string(envManager.GetGitHubPrivateKey()); got != "test-private-key" {
 		t.Errorf("GetGitHubPrivateKey() = %v, want %v", got, "test-private-key")
 	}
+
+	// Test admin token
+	if got := envManager.GetAdminToken(); got != "test-admin-token" {
+		t.Errorf("GetAdminToken() = %v, want %v", got, "test-admin-token")
+	}
 }
 
 func TestFileManager(t *testing.T) {
@@ -206,3 +212,23 @@ func TestChain(t *testing.T) {
 		t.Errorf("GetWebhookSecret() for chain3 = %v, want %v", got, "file-webhook-secret")
 	}
 }
+
+func TestGetSecret(t *testing.T) {
+	fileManager := NewFileManager("webhook", 0, 0, "", nil)
+	fileManager.Secrets = map[string]string{"slack_bot_token": "file-token", "other": "file-other"}
+
+	t.Setenv("OTTO_SECRET_SLACK_BOT_TOKEN", "env-token")
+
+	if got := fileManager.GetSecret("slack_bot_token"); got != "env-token" {
+		t.Errorf("GetSecret(slack_bot_token) = %v, want env override", got)
+	}
+	if got := fileManager.GetSecret("other"); got != "file-other" {
+		t.Errorf("GetSecret(other) = %v, want file-other", got)
+	}
+	if got := fileManager.GetSecret("missing"); got != "" {
+		t.Errorf("GetSecret(missing) = %v, want empty", got)
+	}
+	if got := NewChain(NewEnvManager(), fileManager).GetSecret("other"); got != "file-other" {
+		t.Errorf("Chain.GetSecret(other) = %v, want file-other", got)
+	}
+}
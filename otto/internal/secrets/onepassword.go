@@ -20,6 +20,8 @@ type OnePasswordManager struct {
 	appIDRef         string
 	installIDRef     string
 	privateKeyRef    string
+	adminTokenRef    string
+	secretRefs       map[string]string
 	refs             map[string]string
 	cachedValues     map[string]string
 
@@ -28,10 +30,12 @@ type OnePasswordManager struct {
 	envGitHubAppID    int64
 	envInstallationID int64
 	envPrivateKey     []byte
+	envAdminToken     string
 	hasEnvWebhook     bool
 	hasEnvAppID       bool
 	hasEnvInstallID   bool
 	hasEnvPrivateKey  bool
+	hasEnvAdminToken  bool
 }
 
 // NewOnePasswordManager creates a new OnePasswordManager with the given references.
@@ -92,6 +96,11 @@ func NewOnePasswordManager(
 		manager.hasEnvPrivateKey = true
 	}
 
+	if envVal := os.Getenv("OTTO_ADMIN_TOKEN"); envVal != "" {
+		manager.envAdminToken = envVal
+		manager.hasEnvAdminToken = true
+	}
+
 	// Validate references
 	if err := manager.validateReferences(); err != nil {
 		return nil, err
@@ -243,6 +252,44 @@ func (o *OnePasswordManager) GetGitHubPrivateKey() []byte {
 	return nil
 }
 
+// GetAdminToken returns the admin API token.
+func (o *OnePasswordManager) GetAdminToken() string {
+	// Check cached environment variable first
+	if o.hasEnvAdminToken {
+		return o.envAdminToken
+	}
+
+	// Get the admin token from 1Password
+	if o.adminTokenRef != "" {
+		val, err := o.resolveReference(context.Background(), o.adminTokenRef)
+		if err != nil {
+			slog.Error("Failed to retrieve admin token from 1Password", "error", err)
+			return ""
+		}
+		return val
+	}
+
+	return ""
+}
+
+// GetSecret returns a named secret, preferring its environment variable.
+func (o *OnePasswordManager) GetSecret(name string) string {
+	if envVal := os.Getenv(SecretEnvVar(name)); envVal != "" {
+		return envVal
+	}
+
+	if ref := o.secretRefs[name]; ref != "" {
+		val, err := o.resolveReference(context.Background(), ref)
+		if err != nil {
+			slog.Error("Failed to retrieve secret from 1Password", "name", name, "error", err)
+			return ""
+		}
+		return val
+	}
+
+	return ""
+}
+
 // LoadOnePasswordConfig loads 1Password configuration from the given path.
 func LoadOnePasswordConfig(path string) (*OnePasswordManager, error) {
 	// Read the configuration file
@@ -0,0 +1,153 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// selfupdate.go checks for newer Otto releases and notifies operators when the
+// running instance falls too far behind.
+
+package internal
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/google/go-github/v71/github"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+)
+
+// SelfUpdateJobName is the scheduler name of the release check.
+const SelfUpdateJobName = "self_update_check"
+
+// UpdateChecker compares the running build with the published Otto releases.
+type UpdateChecker struct {
+	client        *github.Client
+	notifications *Notifications
+	config        config.SelfUpdateConfig
+	current       string
+
+	mu       sync.Mutex
+	notified string // newest release operators were already told about
+}
+
+// NewUpdateChecker creates a release checker for the running version current.
+func NewUpdateChecker(
+	client *github.Client,
+	notifications *Notifications,
+	cfg config.SelfUpdateConfig,
+	current string,
+) *UpdateChecker {
+	return &UpdateChecker{client: client, notifications: notifications, config: cfg, current: current}
+}
+
+// newerRelease is a published release newer than the running build.
+type newerRelease struct {
+	version [3]int
+	release *github.RepositoryRelease
+}
+
+// Check lists the releases and notifies once per newest release while the instance is
+// more than MaxBehind releases behind. It runs on the scheduler.
+func (u *UpdateChecker) Check(ctx context.Context) error {
+	current, ok := parseVersion(u.current)
+	if !ok {
+		slog.Debug("Skipping release check for unversioned build", "version", u.current)
+		return nil
+	}
+	owner, repo, found := strings.Cut(u.config.Repo, "/")
+	if !found {
+		return fmt.Errorf("self_update: invalid repo %q", u.config.Repo)
+	}
+	releases, _, err := u.client.Repositories.ListReleases(ctx, owner, repo, &github.ListOptions{PerPage: 100})
+	if err != nil {
+		return fmt.Errorf("failed to list %s releases: %w", u.config.Repo, err)
+	}
+
+	var newer []newerRelease
+	for _, r := range releases {
+		if r.GetDraft() || r.GetPrerelease() {
+			continue
+		}
+		tag, ok := strings.CutPrefix(r.GetTagName(), u.config.TagPrefix)
+		if !ok {
+			continue
+		}
+		if v, ok := parseVersion(tag); ok && compareVersions(v, current) > 0 {
+			newer = append(newer, newerRelease{version: v, release: r})
+		}
+	}
+	slices.SortFunc(newer, func(a, b newerRelease) int { return compareVersions(b.version, a.version) })
+	if len(newer) <= u.config.MaxBehind {
+		slog.Debug("Otto is up to date enough", "version", u.current, "releases_behind", len(newer))
+		return nil
+	}
+
+	latest := newer[0].release
+	u.mu.Lock()
+	already := u.notified == latest.GetTagName()
+	u.mu.Unlock()
+	if already {
+		return nil
+	}
+	slog.Warn("Otto is behind the latest release", "version", u.current, "latest", latest.GetTagName(),
+		"releases_behind", len(newer))
+	err = u.notifications.Notify(ctx, Notification{
+		Module:   "self_update",
+		Severity: SeverityWarning,
+		Title: fmt.Sprintf("Otto %s is %d releases behind %s", u.current, len(newer),
+			strings.TrimPrefix(latest.GetTagName(), u.config.TagPrefix)),
+		Body: u.changelog(newer),
+		URL:  latest.GetHTMLURL(),
+	})
+	if err != nil {
+		return err
+	}
+	u.mu.Lock()
+	u.notified = latest.GetTagName()
+	u.mu.Unlock()
+	return nil
+}
+
+// changelog summarizes the missed releases, newest first.
+func (u *UpdateChecker) changelog(newer []newerRelease) string {
+	var b strings.Builder
+	for _, r := range newer {
+		fmt.Fprintf(&b, "%s:\n", strings.TrimPrefix(r.release.GetTagName(), u.config.TagPrefix))
+		for _, line := range releaseHighlights(r.release.GetBody(), u.config.Highlights) {
+			fmt.Fprintf(&b, "  - %s\n", line)
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// releaseHighlights returns the first n bullet points of release notes, or the first
+// line of prose when the notes have no bullets.
+func releaseHighlights(body string, n int) []string {
+	var bullets []string
+	prose := ""
+	for _, line := range strings.Split(strings.ReplaceAll(body, "\r\n", "\n"), "\n") {
+		line = strings.TrimSpace(line)
+		if item, ok := cutBullet(line); ok && item != "" {
+			if len(bullets) < n {
+				bullets = append(bullets, item)
+			}
+		} else if prose == "" && line != "" && !strings.HasPrefix(line, "#") {
+			prose = line
+		}
+	}
+	if len(bullets) == 0 && prose != "" && n > 0 {
+		return []string{prose}
+	}
+	return bullets
+}
+
+func cutBullet(line string) (string, bool) {
+	for _, marker := range []string{"- ", "* "} {
+		if item, ok := strings.CutPrefix(line, marker); ok {
+			return strings.TrimSpace(item), true
+		}
+	}
+	return "", false
+}
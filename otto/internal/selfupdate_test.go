@@ -0,0 +1,154 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/go-github/v71/github"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+)
+
+// captureNotifier keeps every notification it is sent.
+type captureNotifier struct {
+	mu   sync.Mutex
+	sent []Notification
+}
+
+func (c *captureNotifier) Backend() string { return "capture" }
+
+func (c *captureNotifier) Notify(ctx context.Context, target string, n Notification) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sent = append(c.sent, n)
+	return nil
+}
+
+func TestUpdateCheckerCheck(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		releases = []*github.RepositoryRelease{
+			{TagName: github.Ptr("otto/v0.3.0"), Body: github.Ptr("## Changes\n- Add owners\n- Add history\n")},
+			{TagName: github.Ptr("otto/v0.4.0-rc.1"), Prerelease: github.Ptr(true)},
+			{TagName: github.Ptr("otto/v0.2.1"), Body: github.Ptr("Fix a crash on startup.")},
+			{TagName: github.Ptr("collector-tools/v9.0.0")},
+			{TagName: github.Ptr("otto/v0.2.0")},
+		}
+	)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/open-telemetry/sig-project-infra/releases", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		_ = json.NewEncoder(w).Encode(releases)
+	})
+	client := TestGitHubClient(t, mux)
+
+	capture := &captureNotifier{}
+	notifications, err := NewNotifications(config.NotificationsConfig{
+		Channels: map[string]config.NotificationChannel{"ops": {Backend: "capture"}},
+		Routes:   []config.NotificationRoute{{Channels: []string{"ops"}}},
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewNotifications failed: %v", err)
+	}
+	notifications.Register(capture)
+	cfg := config.SelfUpdateConfig{
+		Repo:       "open-telemetry/sig-project-infra",
+		TagPrefix:  "otto/",
+		MaxBehind:  1,
+		Highlights: 1,
+	}
+
+	tests := []struct {
+		name    string
+		current string
+		want    int
+	}{
+		{name: "unversioned build", current: "dev", want: 0},
+		{name: "within max behind", current: "v0.2.1", want: 0},
+		{name: "too far behind", current: "v0.1.0", want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			capture.sent = nil
+			checker := NewUpdateChecker(client, notifications, cfg, tt.current)
+			if err := checker.Check(t.Context()); err != nil {
+				t.Fatalf("Check failed: %v", err)
+			}
+			if len(capture.sent) != tt.want {
+				t.Fatalf("got %d notifications, want %d: %+v", len(capture.sent), tt.want, capture.sent)
+			}
+			// Repeated checks do not notify again for the same release.
+			if err := checker.Check(t.Context()); err != nil {
+				t.Fatalf("Check failed: %v", err)
+			}
+			if len(capture.sent) != tt.want {
+				t.Errorf("notified again for the same release: %+v", capture.sent)
+			}
+		})
+	}
+
+	n := capture.sent[0]
+	if n.Title != "Otto v0.1.0 is 3 releases behind v0.3.0" || n.Severity != SeverityWarning {
+		t.Errorf("unexpected notification: %+v", n)
+	}
+	wantBody := "v0.3.0:\n  - Add owners\nv0.2.1:\n  - Fix a crash on startup.\nv0.2.0:"
+	if n.Body != wantBody {
+		t.Errorf("body = %q, want %q", n.Body, wantBody)
+	}
+
+	// A newer release is reported even after an earlier one was.
+	checker := NewUpdateChecker(client, notifications, cfg, "v0.1.0")
+	if err := checker.Check(t.Context()); err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	mu.Lock()
+	releases = append(releases, &github.RepositoryRelease{TagName: github.Ptr("otto/v0.4.0")})
+	mu.Unlock()
+	if err := checker.Check(t.Context()); err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	if got := capture.sent[len(capture.sent)-1].Title; !strings.Contains(got, "4 releases behind v0.4.0") {
+		t.Errorf("unexpected title after new release: %q", got)
+	}
+}
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		in   string
+		want [3]int
+		ok   bool
+	}{
+		{in: "v1.2.3", want: [3]int{1, 2, 3}, ok: true},
+		{in: "0.10.0", want: [3]int{0, 10, 0}, ok: true},
+		{in: "v1.2.3-rc.1", ok: false},
+		{in: "v1.2", ok: false},
+		{in: "dev", ok: false},
+	}
+	for _, tt := range tests {
+		got, ok := parseVersion(tt.in)
+		if ok != tt.ok || (ok && got != tt.want) {
+			t.Errorf("parseVersion(%q) = %v, %v; want %v, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+	if compareVersions([3]int{0, 10, 0}, [3]int{0, 9, 9}) != 1 {
+		t.Error("v0.10.0 should be newer than v0.9.9")
+	}
+}
+
+func TestReleaseHighlights(t *testing.T) {
+	body := "## What's changed\r\n* First\r\n- Second\r\n- Third\r\n"
+	if got := releaseHighlights(body, 2); !slices.Equal(got, []string{"First", "Second"}) {
+		t.Errorf("releaseHighlights = %v", got)
+	}
+	if got := releaseHighlights("", 2); got != nil {
+		t.Errorf("releaseHighlights of empty notes = %v", got)
+	}
+}
@@ -10,24 +10,43 @@ import (
 	"crypto/sha256"
 	"crypto/subtle"
 	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 
 	"github.com/google/go-github/v71/github"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
 	"github.com/open-telemetry/sig-project-infra/otto/internal/secrets"
 )
 
 type Server struct {
-	webhookSecret []byte // from secrets config
-	mux           *http.ServeMux
-	server        *http.Server
-	app           *App // Reference to the app for dispatching events
+	adminToken []byte         // bearer token for /admin endpoints; empty disables them
+	login      *adminLogin    // signs people in to the admin API; nil if disabled
+	mux        *http.ServeMux // webhooks, and the admin routes unless they have their own listener
+	adminMux   *http.ServeMux // admin API, health checks and debug endpoints; nil serves them on mux
+	server     *http.Server
+	admin      *http.Server // listener for adminMux; nil unless admin.addr is set
+	debug      *http.Server // pprof and expvar; nil unless enabled without an admin listener
+	app        *App         // Reference to the app for dispatching events
+}
+
+// webhookEndpoint is a webhook path and the secret its deliveries are signed with.
+type webhookEndpoint struct {
+	config.WebhookEndpoint
+	secret []byte
 }
 
+// defaultWebhooks is the endpoint served when no webhooks are configured.
+var defaultWebhooks = []config.WebhookEndpoint{{Path: "/webhook", Source: "github"}}
+
 // NewServer creates a new server with the provided webhook secret and address.
 func NewServer(addr string, secretsManager secrets.Manager) *Server {
 	return NewServerWithApp(addr, secretsManager, nil)
@@ -37,8 +56,8 @@ func NewServer(addr string, secretsManager secrets.Manager) *Server {
 func NewServerWithApp(addr string, secretsManager secrets.Manager, app *App) *Server {
 	mux := http.NewServeMux()
 	srv := &Server{
-		webhookSecret: []byte(secretsManager.GetWebhookSecret()),
-		mux:           mux,
+		adminToken: []byte(secretsManager.GetAdminToken()),
+		mux:        mux,
 		server: &http.Server{
 			Addr:              fmt.Sprintf(":%v", addr),
 			Handler:           mux,
@@ -46,15 +65,129 @@ func NewServerWithApp(addr string, secretsManager secrets.Manager, app *App) *Se
 		},
 		app: app,
 	}
-	mux.HandleFunc("/webhook", srv.handleWebhook)
+
+	if app != nil && app.Config != nil {
+		if addr := app.Config.Admin.Addr; addr != "" {
+			srv.adminMux = http.NewServeMux()
+			srv.admin = &http.Server{
+				Addr:              addr,
+				Handler:           srv.adminMux,
+				ReadHeaderTimeout: 10 * time.Second,
+			}
+		}
+		srv.debug = srv.newDebugServer(app.Config.Debug)
+
+		if cfg := app.Config.Admin.Login; cfg.Provider != "" {
+			login, err := newAdminLogin(cfg, secretsManager.GetSecret(AdminLoginSecret),
+				secretsManager.GetSecret(AdminSessionKeySecret), app.HTTPClient(30*time.Second), app.GitHubClient)
+			if err != nil {
+				slog.Error("Admin sign-in disabled", "provider", cfg.Provider, "error", err)
+			} else {
+				srv.login = login
+				login.RegisterRoutes(srv.adminRoutes())
+			}
+		}
+	}
+
+	// Each webhook endpoint verifies deliveries with its own secret
+	webhooks := defaultWebhooks
+	if app != nil && app.Config != nil && len(app.Config.Webhooks) > 0 {
+		webhooks = app.Config.Webhooks
+	}
+	for _, cfg := range webhooks {
+		endpoint := webhookEndpoint{WebhookEndpoint: cfg, secret: []byte(secretsManager.GetWebhookSecret())}
+		if cfg.Secret != "" {
+			endpoint.secret = []byte(secretsManager.GetSecret(cfg.Secret))
+			if len(endpoint.secret) == 0 {
+				slog.Error("Webhook endpoint disabled: its secret is not configured", "path", cfg.Path, "secret", cfg.Secret)
+				continue
+			}
+		}
+		handle := srv.handleWebhook
+		if cfg.Source == SourceGitLab {
+			handle = srv.handleGitLabWebhook
+		}
+		mux.HandleFunc(cfg.Path, func(w http.ResponseWriter, r *http.Request) {
+			handle(w, r, endpoint)
+		})
+	}
 
 	// Health check endpoints
-	mux.HandleFunc("/check/liveness", srv.handleLivenessCheck)   // Kubernetes liveness probe
-	mux.HandleFunc("/check/readiness", srv.handleReadinessCheck) // Kubernetes readiness probe
+	admin := srv.adminRoutes()
+	admin.HandleFunc("/check/liveness", srv.handleLivenessCheck)   // Kubernetes liveness probe
+	admin.HandleFunc("/check/readiness", srv.handleReadinessCheck) // Kubernetes readiness probe
+	if srv.adminMux != nil {
+		// Only the admin listener serves these, so they are never exposed with the webhooks
+		srv.adminMux.HandleFunc("/healthz", srv.handleLivenessCheck)
+		if app.Telemetry != nil && app.Telemetry.MetricsHandler != nil {
+			srv.adminMux.Handle("GET /metrics", app.Telemetry.MetricsHandler)
+		}
+	}
+	srv.HandleAdmin("GET /admin/whoami", srv.handleWhoami)
 
 	return srv
 }
 
+// HandleAdmin registers a handler that requires the admin bearer token or a signed-in person.
+// Patterns follow net/http.ServeMux syntax, e.g. "GET /admin/oncall/schedules.json".
+func (s *Server) HandleAdmin(pattern string, handler http.HandlerFunc) {
+	s.adminRoutes().Handle(pattern, s.requireAdmin(handler))
+}
+
+// adminRoutes returns the mux for the admin API and health checks: the admin listener's
+// if admin.addr is set, or else the webhook port's.
+func (s *Server) adminRoutes() *http.ServeMux {
+	if s.adminMux != nil {
+		return s.adminMux
+	}
+	return s.mux
+}
+
+// requireAdmin rejects requests that carry neither the configured admin token nor the
+// session of a signed-in person. Viewers may only make GET requests, and browsers without
+// a session are sent to sign in.
+func (s *Server) requireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(s.adminToken) == 0 && s.login == nil {
+			http.Error(w, "admin API disabled", http.StatusNotFound)
+			return
+		}
+		if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+			if len(s.adminToken) == 0 || subtle.ConstantTimeCompare([]byte(token), s.adminToken) != 1 {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			id := AdminIdentity{Role: config.AdminRoleAdmin, Method: "token"}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), adminIdentityKey{}, id)))
+			return
+		}
+		if s.login != nil {
+			if session, ok := s.login.session(r); ok {
+				if session.Role != config.AdminRoleAdmin && r.Method != http.MethodGet && r.Method != http.MethodHead {
+					http.Error(w, "forbidden: viewers may only read", http.StatusForbidden)
+					return
+				}
+				next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), adminIdentityKey{}, session)))
+				return
+			}
+			if wantsLogin(r) {
+				http.Redirect(w, r, "/admin/login?return="+url.QueryEscape(r.URL.RequestURI()), http.StatusFound)
+				return
+			}
+		}
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+	})
+}
+
+// handleWhoami returns who made the request and their role.
+func (s *Server) handleWhoami(w http.ResponseWriter, r *http.Request) {
+	id, _ := AdminUser(r.Context())
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(id); err != nil {
+		slog.Error("Failed to write JSON response", "error", err)
+	}
+}
+
 // handleLivenessCheck implements a Kubernetes liveness probe.
 // It returns healthy if the server is running and can accept requests.
 func (s *Server) handleLivenessCheck(w http.ResponseWriter, r *http.Request) {
@@ -80,6 +213,16 @@ func (s *Server) handleReadinessCheck(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Stop traffic to an instance that is shutting down
+	if s.app.Draining() {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		if _, err := w.Write([]byte(`{"status":"DOWN","details":"Shutting down"}`)); err != nil {
+			slog.Error("Failed to write readiness failure response", "error", err)
+		}
+		return
+	}
+
 	// Check database connectivity if database exists
 	if s.app.Database != nil {
 		err := s.app.Database.DB().Ping()
@@ -105,14 +248,16 @@ func (s *Server) handleReadinessCheck(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// handleWebhook verifies signature and decodes GitHub webhook request.
-func (s *Server) handleWebhook(w http.ResponseWriter, r *http.Request) {
+// handleWebhook verifies signature and decodes GitHub webhook request for an endpoint.
+func (s *Server) handleWebhook(w http.ResponseWriter, r *http.Request, endpoint webhookEndpoint) {
 	start := time.Now()
 	eventType := github.WebHookType(r)
 	ctx, span := s.app.Telemetry.StartServerEventSpan(r.Context(), eventType)
 	defer span.End()
 	s.app.Telemetry.IncServerRequest(ctx, "webhook")
-	s.app.Telemetry.IncServerWebhook(ctx, eventType)
+	if s.refuseWhileDraining(ctx, w, r) {
+		return
+	}
 
 	payload, err := io.ReadAll(r.Body)
 	if err != nil {
@@ -122,25 +267,30 @@ func (s *Server) handleWebhook(w http.ResponseWriter, r *http.Request) {
 			"webhook",
 			float64(time.Since(start).Milliseconds()),
 		)
-		http.Error(w, "could not read body", http.StatusBadRequest)
+		writeProblemError(ctx, w, r, http.StatusBadRequest, "unreadable-body", "could not read the request body", err)
 		return
 	}
 	defer r.Body.Close()
 
 	sig := r.Header.Get("X-Hub-Signature-256")
-	if !s.verifySignature(payload, sig) {
+	if !verifySignature(endpoint.secret, payload, sig) {
 		s.app.Telemetry.IncServerError(ctx, "webhook", "badSig")
 		s.app.Telemetry.RecordServerLatency(
 			ctx,
 			"webhook",
 			float64(time.Since(start).Milliseconds()),
 		)
-		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		writeProblem(ctx, w, r, http.StatusUnauthorized, "invalid-signature",
+			"X-Hub-Signature-256 does not match the payload signed with this endpoint's webhook secret")
 		return
 	}
 
+	// Count webhooks once they are signed, so the action is not an arbitrary attribute value
+	stored := NewStoredEvent(github.DeliveryID(r), eventType, payload)
+	s.app.Telemetry.IncServerWebhook(ctx, eventType, stored.Action, stored.Repo)
+
 	eventType = github.WebHookType(r)
-	event, err := github.ParseWebHook(eventType, payload)
+	event, err := ParseWebHook(eventType, payload)
 	if err != nil {
 		s.app.Telemetry.IncServerError(ctx, "webhook", "parseEvent")
 		s.app.Telemetry.RecordServerLatency(
@@ -148,17 +298,33 @@ func (s *Server) handleWebhook(w http.ResponseWriter, r *http.Request) {
 			"webhook",
 			float64(time.Since(start).Milliseconds()),
 		)
-		http.Error(w, "could not parse event", http.StatusBadRequest)
+		writeProblemError(ctx, w, r, http.StatusBadRequest, "unparsable-event", "could not parse the event payload",
+			err)
 		return
 	}
 
 	slog.Info("received event",
 		"type", eventType,
-		"struct", fmt.Sprintf("%T", event))
+		"struct", fmt.Sprintf("%T", event),
+		"endpoint", endpoint.Path)
+
+	if s.app != nil {
+		s.app.Payloads.Check(ctx, eventType, payload, event)
+		truncated := s.app.reportTruncation(ctx, eventType, event)
+		span.SetAttributes(attribute.Bool("payload_truncated", len(truncated) > 0))
+	}
+
+	// Persist the event before dispatch so modules can query recent activity
+	var storedID int64
+	if s.app != nil && s.app.Events != nil {
+		if storedID, err = s.app.Events.Record(ctx, stored); err != nil {
+			s.app.Telemetry.IncServerError(ctx, "webhook", "recordEvent")
+		}
+	}
 
 	// Dispatch event to all modules
 	if s.app != nil {
-		s.app.DispatchEvent(eventType, event, payload)
+		s.app.DispatchDelivery(github.DeliveryID(r), storedID, eventType, event, payload)
 	} else {
 		slog.Error("No app reference in server, event dispatch failed")
 	}
@@ -167,13 +333,82 @@ func (s *Server) handleWebhook(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
+// handleGitLabWebhook verifies the token of a GitLab webhook request, stores the event and
+// dispatches its normalized form to the modules that handle normalized events.
+func (s *Server) handleGitLabWebhook(w http.ResponseWriter, r *http.Request, endpoint webhookEndpoint) {
+	start := time.Now()
+	eventType := gitLabEventType(r)
+	ctx, span := s.app.Telemetry.StartServerEventSpan(r.Context(), eventType)
+	defer span.End()
+	s.app.Telemetry.IncServerRequest(ctx, "webhook")
+	defer func() {
+		s.app.Telemetry.RecordServerLatency(ctx, "webhook", float64(time.Since(start).Milliseconds()))
+	}()
+	if s.refuseWhileDraining(ctx, w, r) {
+		return
+	}
+
+	if !verifyGitLabToken(endpoint.secret, r) {
+		s.app.Telemetry.IncServerError(ctx, "webhook", "badToken")
+		writeProblem(ctx, w, r, http.StatusUnauthorized, "invalid-token",
+			"X-Gitlab-Token does not match this endpoint's secret token")
+		return
+	}
+	payload, err := io.ReadAll(r.Body)
+	if err != nil {
+		s.app.Telemetry.IncServerError(ctx, "webhook", "readBody")
+		writeProblemError(ctx, w, r, http.StatusBadRequest, "unreadable-body", "could not read the request body", err)
+		return
+	}
+	defer r.Body.Close()
+
+	event, err := ParseGitLabEvent(payload)
+	if err != nil {
+		s.app.Telemetry.IncServerError(ctx, "webhook", "parseEvent")
+		writeProblemError(ctx, w, r, http.StatusBadRequest, "unparsable-event", "could not parse the event payload",
+			err)
+		return
+	}
+	if event == nil {
+		s.app.Telemetry.IncServerWebhook(ctx, eventType, "", "")
+		slog.Debug("Ignoring GitLab event", "type", eventType, "endpoint", endpoint.Path)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	s.app.Telemetry.IncServerWebhook(ctx, eventType, event.Action, event.Repo)
+	slog.Info("received event", "type", eventType, "repo", event.Repo, "endpoint", endpoint.Path)
+
+	if s.app.Events != nil {
+		stored := NewStoredEvent(r.Header.Get(gitLabUUIDHeader), eventType, payload)
+		stored.Action, stored.Repo, stored.Sender = event.Action, event.Repo, event.Sender
+		if _, err := s.app.Events.Record(ctx, stored); err != nil {
+			s.app.Telemetry.IncServerError(ctx, "webhook", "recordEvent")
+		}
+	}
+	s.app.DispatchNormalizedEvent(event)
+	w.WriteHeader(http.StatusOK)
+}
+
+// refuseWhileDraining answers a webhook with 503 Service Unavailable, so that it can be
+// redelivered, once Otto is shutting down, and reports whether it did.
+func (s *Server) refuseWhileDraining(ctx context.Context, w http.ResponseWriter, r *http.Request) bool {
+	if s.app == nil || !s.app.Draining() {
+		return false
+	}
+	s.app.Telemetry.IncServerError(ctx, "webhook", "draining")
+	w.Header().Set("Retry-After", "60")
+	writeProblem(ctx, w, r, http.StatusServiceUnavailable, "shutting-down",
+		"Otto is shutting down and no longer takes webhooks; redeliver this one later")
+	return true
+}
+
 // verifySignature checks the request payload using the shared secret (GitHub webhook HMAC SHA256).
-func (s *Server) verifySignature(payload []byte, sig string) bool {
+func verifySignature(secret, payload []byte, sig string) bool {
 	if !strings.HasPrefix(sig, "sha256=") {
 		return false
 	}
 	sig = strings.TrimPrefix(sig, "sha256=")
-	mac := hmac.New(sha256.New, s.webhookSecret)
+	mac := hmac.New(sha256.New, secret)
 	mac.Write(payload)
 	expectedMAC := mac.Sum(nil)
 	receivedMAC, err := hex.DecodeString(sig)
@@ -183,13 +418,39 @@ func (s *Server) verifySignature(payload []byte, sig string) bool {
 	return subtle.ConstantTimeCompare(receivedMAC, expectedMAC) == 1
 }
 
-// Start runs the HTTP server (blocking).
+// Start runs the HTTP server (blocking), and the admin and debug servers in the background.
 func (s *Server) Start() error {
+	if s.debug != nil && len(s.adminToken) == 0 {
+		slog.Warn("Debug endpoints are enabled but reject every request: no admin token is configured")
+	}
+	serveBackground("admin", s.admin)
+	serveBackground("debug", s.debug)
 	slog.Info("starting server", "addr", s.server.Addr)
 	return s.server.ListenAndServe()
 }
 
-// Shutdown gracefully stops the server.
+// serveBackground runs a secondary server until it is shut down. A nil server is skipped.
+func serveBackground(name string, hs *http.Server) {
+	if hs == nil {
+		return
+	}
+	go func() {
+		slog.Info("starting "+name+" server", "addr", hs.Addr)
+		if err := hs.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("Server error", "server", name, "err", err)
+		}
+	}()
+}
+
+// Shutdown gracefully stops the server and the admin and debug servers.
 func (s *Server) Shutdown(ctx context.Context) error {
+	for name, hs := range map[string]*http.Server{"admin": s.admin, "debug": s.debug} {
+		if hs == nil {
+			continue
+		}
+		if err := hs.Shutdown(ctx); err != nil {
+			slog.Error("Error during server shutdown", "server", name, "err", err)
+		}
+	}
 	return s.server.Shutdown(ctx)
 }
@@ -3,10 +3,16 @@
 package internal
 
 import (
+	"bytes"
 	"encoding/json"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+	"github.com/open-telemetry/sig-project-infra/otto/internal/secrets"
 )
 
 func TestHealthEndpoints(t *testing.T) {
@@ -110,3 +116,175 @@ func TestReadinessWithApp(t *testing.T) {
 			actualResponse["status"], expectedResponse["status"])
 	}
 }
+
+func TestAdminAuth(t *testing.T) {
+	tests := []struct {
+		name           string
+		adminToken     string
+		authHeader     string
+		expectedStatus int
+	}{
+		{"disabled without token", "", "Bearer anything", http.StatusNotFound},
+		{"missing header", "s3cret", "", http.StatusUnauthorized},
+		{"wrong token", "s3cret", "Bearer nope", http.StatusUnauthorized},
+		{"valid token", "s3cret", "Bearer s3cret", http.StatusOK},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := &Server{mux: http.NewServeMux(), adminToken: []byte(tc.adminToken)}
+			srv.HandleAdmin("GET /admin/ping", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/admin/ping", nil)
+			if tc.authHeader != "" {
+				req.Header.Set("Authorization", tc.authHeader)
+			}
+			rr := httptest.NewRecorder()
+			srv.mux.ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedStatus {
+				t.Errorf("status = %d, want %d", rr.Code, tc.expectedStatus)
+			}
+		})
+	}
+}
+
+func TestWebhookEndpoints(t *testing.T) {
+	t.Setenv("OTTO_WEBHOOK_SECRET", "default-secret")
+	t.Setenv("OTTO_SECRET_MIRROR_WEBHOOK_SECRET", "mirror-secret")
+	app := &App{
+		Config: &config.AppConfig{Webhooks: []config.WebhookEndpoint{
+			{Path: "/webhook/github", Source: "github"},
+			{Path: "/webhook/github-mirror", Source: "github", Secret: "mirror_webhook_secret"},
+			{Path: "/webhook/unconfigured", Source: "github", Secret: "missing_webhook_secret"},
+		}},
+		Telemetry:      TestTelemetry(t, nil),
+		Logger:         slog.Default(),
+		ModuleRegistry: NewModuleRegistry(),
+	}
+	srv := NewServerWithApp("0", secrets.NewEnvManager(), app)
+
+	payload := []byte(`{"zen":"Keep it logically awesome."}`)
+	tests := []struct {
+		name   string
+		path   string
+		secret string
+		want   int
+	}{
+		{"default secret", "/webhook/github", "default-secret", http.StatusOK},
+		{"named secret", "/webhook/github-mirror", "mirror-secret", http.StatusOK},
+		{"other endpoint's secret", "/webhook/github-mirror", "default-secret", http.StatusUnauthorized},
+		{"missing secret disables the endpoint", "/webhook/unconfigured", "", http.StatusNotFound},
+		{"default path not served", "/webhook", "default-secret", http.StatusNotFound},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tc.path, bytes.NewReader(payload))
+			req.Header.Set("X-GitHub-Event", "ping")
+			req.Header.Set("X-Hub-Signature-256", signPayload(tc.secret, payload))
+			rr := httptest.NewRecorder()
+			srv.mux.ServeHTTP(rr, req)
+			if rr.Code != tc.want {
+				t.Errorf("status = %d, want %d", rr.Code, tc.want)
+			}
+		})
+	}
+
+	// Once shutdown begins, webhooks are turned away to be redelivered.
+	app.lifecycle = &lifecycle{}
+	app.lifecycle.draining.Store(true)
+	req := httptest.NewRequest(http.MethodPost, "/webhook/github", bytes.NewReader(payload))
+	req.Header.Set("X-GitHub-Event", "ping")
+	req.Header.Set("X-Hub-Signature-256", signPayload("default-secret", payload))
+	rr := httptest.NewRecorder()
+	srv.mux.ServeHTTP(rr, req)
+	if rr.Code != http.StatusServiceUnavailable || rr.Header().Get("Retry-After") == "" {
+		t.Errorf("webhook while draining: status = %d, Retry-After = %q", rr.Code, rr.Header().Get("Retry-After"))
+	}
+}
+
+func TestAdminListener(t *testing.T) {
+	t.Setenv("OTTO_WEBHOOK_SECRET", "secret")
+	t.Setenv("OTTO_ADMIN_TOKEN", "token")
+	enabled := true
+	app := &App{
+		Config: &config.AppConfig{
+			Admin: config.AdminConfig{Addr: "localhost:9090"},
+			Debug: config.DebugConfig{Enabled: &enabled, Addr: "localhost:6060"},
+		},
+		Telemetry:      TestTelemetry(t, nil),
+		Logger:         slog.Default(),
+		ModuleRegistry: NewModuleRegistry(),
+	}
+	srv := NewServerWithApp("8080", secrets.NewEnvManager(), app)
+	if srv.admin == nil || srv.admin.Addr != "localhost:9090" {
+		t.Fatalf("unexpected admin server: %+v", srv.admin)
+	}
+	if srv.debug != nil {
+		t.Error("debug endpoints got their own listener despite the admin listener")
+	}
+	srv.HandleAdmin("GET /admin/ping", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		path       string
+		public     int
+		adminPort  int
+		authorized bool
+	}{
+		{"/check/liveness", http.StatusNotFound, http.StatusOK, false},
+		{"/healthz", http.StatusNotFound, http.StatusOK, false},
+		{"/metrics", http.StatusNotFound, http.StatusOK, false},
+		{"/admin/ping", http.StatusNotFound, http.StatusOK, true},
+		{"/debug/vars", http.StatusNotFound, http.StatusOK, true},
+		{"/debug/pprof/", http.StatusNotFound, http.StatusUnauthorized, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.path, func(t *testing.T) {
+			for _, mux := range []struct {
+				handler http.Handler
+				want    int
+			}{{srv.server.Handler, tc.public}, {srv.admin.Handler, tc.adminPort}} {
+				req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+				if tc.authorized {
+					req.Header.Set("Authorization", "Bearer token")
+				}
+				rr := httptest.NewRecorder()
+				mux.handler.ServeHTTP(rr, req)
+				if rr.Code != mux.want {
+					t.Errorf("status = %d, want %d", rr.Code, mux.want)
+				}
+			}
+		})
+	}
+
+	// Webhooks stay on the public port only.
+	payload := []byte(`{"zen":"Keep it logically awesome."}`)
+	webhooks := map[http.Handler]int{srv.server.Handler: http.StatusOK, srv.admin.Handler: http.StatusNotFound}
+	for handler, want := range webhooks {
+		req := httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewReader(payload))
+		req.Header.Set("X-GitHub-Event", "ping")
+		req.Header.Set("X-Hub-Signature-256", signPayload("secret", payload))
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		if rr.Code != want {
+			t.Errorf("POST /webhook status = %d, want %d", rr.Code, want)
+		}
+	}
+
+	// The admin port's scrape shows the requests the public port served.
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	rr := httptest.NewRecorder()
+	srv.admin.Handler.ServeHTTP(rr, req)
+	if !strings.Contains(rr.Body.String(), "webhook") {
+		t.Errorf("/metrics does not show the webhook requests:\n%s", rr.Body.String())
+	}
+}
+
+// signPayload returns the X-Hub-Signature-256 header GitHub sends for payload.
+func signPayload(secret string, payload []byte) string {
+	return webhookSignature([]byte(secret), payload)
+}
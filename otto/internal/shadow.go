@@ -0,0 +1,436 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// shadow.go runs modules in shadow mode, to roll out a new module version safely against
+// production traffic. A shadowed module handles events and runs its jobs as usual, but on
+// its shadow repositories the GitHub API writes it makes are recorded instead of made. Its
+// writes elsewhere are made and recorded too, so the report can compare what the module
+// would do in shadow with what it does live.
+
+package internal
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v71/github"
+)
+
+// ShadowPruneJobName is the scheduler name of the job that deletes expired module actions.
+const ShadowPruneJobName = "module_actions_prune"
+
+// Modes of recorded module actions.
+const (
+	ShadowModeLive   = "live"   // the write was made
+	ShadowModeShadow = "shadow" // the write was recorded instead
+)
+
+// maxShadowBody bounds the request body kept for a recorded action.
+const maxShadowBody = 64 << 10
+
+// ModuleAction is a GitHub API write of a module running in shadow mode.
+type ModuleAction struct {
+	ID     int64     `json:"id"`
+	At     time.Time `json:"at"`
+	Module string    `json:"module"`
+	Mode   string    `json:"mode"`
+	Repo   string    `json:"repo,omitempty"`
+	Action string    `json:"action"` // method and route, e.g. "POST /repos/{owner}/{repo}/issues/{n}/comments"
+	Path   string    `json:"path"`
+	Body   string    `json:"body,omitempty"`
+	Status int       `json:"status,omitempty"` // response status of live writes
+}
+
+// ShadowComparison counts an action in both modes.
+type ShadowComparison struct {
+	Action      string `json:"action"`
+	Live        int    `json:"live"`
+	LiveRepos   int    `json:"live_repos"`
+	Shadow      int    `json:"shadow"`
+	ShadowRepos int    `json:"shadow_repos"`
+}
+
+// ShadowReport compares a module's actions in shadow mode with its live actions. Repos
+// count the repositories with actions in each mode, so rates per repository can be
+// compared when the module runs in shadow on some repositories and live on others.
+type ShadowReport struct {
+	Module      string             `json:"module"`
+	Since       time.Time          `json:"since"`
+	LiveRepos   int                `json:"live_repos"`
+	ShadowRepos int                `json:"shadow_repos"`
+	Actions     []ShadowComparison `json:"actions"`
+}
+
+// ShadowLog reads and writes the module_actions table.
+type ShadowLog struct {
+	db      *sql.DB
+	modules map[string][]string // module -> repository patterns it runs in shadow on
+	all     bool                // every module runs in shadow everywhere, as in a sandbox
+	now     func() time.Time
+}
+
+// NewShadowLog creates the log of shadowed modules' actions, creating its table if needed.
+// modules maps each shadowed module to the repositories it runs in shadow on, as path.Match
+// patterns such as "org/*".
+func NewShadowLog(db *sql.DB, modules map[string][]string) (*ShadowLog, error) {
+	for module, patterns := range modules {
+		for _, pattern := range patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("shadow: invalid repository pattern %q of %s: %w", pattern, module, err)
+			}
+		}
+	}
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS module_actions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			at TIMESTAMP NOT NULL,
+			module TEXT NOT NULL,
+			mode TEXT NOT NULL,
+			repo TEXT NOT NULL DEFAULT '',
+			action TEXT NOT NULL,
+			path TEXT NOT NULL,
+			body TEXT NOT NULL DEFAULT '',
+			status INTEGER NOT NULL DEFAULT 0
+		);`,
+		`CREATE INDEX IF NOT EXISTS idx_module_actions_module ON module_actions (module, at);`,
+		`CREATE INDEX IF NOT EXISTS idx_module_actions_at ON module_actions (at);`,
+	}
+	for _, s := range stmts {
+		if _, err := db.Exec(s); err != nil {
+			return nil, fmt.Errorf("failed migration: %w (SQL: %s)", err, s)
+		}
+	}
+	return &ShadowLog{db: db, modules: modules, now: time.Now}, nil
+}
+
+// Shadowed reports whether a module runs in shadow mode on any repository. A nil log
+// shadows nothing.
+func (l *ShadowLog) Shadowed(module string) bool {
+	if l == nil {
+		return false
+	}
+	_, ok := l.modules[module]
+	return ok
+}
+
+// InShadow reports whether a module runs in shadow mode on a repository.
+func (l *ShadowLog) InShadow(module, repo string) bool {
+	if l == nil {
+		return false
+	}
+	if l.all {
+		return true
+	}
+	if repo == "" {
+		return false
+	}
+	for _, pattern := range l.modules[module] {
+		if ok, _ := path.Match(pattern, repo); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Client returns a copy of client whose writes are recorded for module, and only recorded
+// on the module's shadow repositories.
+func (l *ShadowLog) Client(client *github.Client, module string) *github.Client {
+	base := client.Client().Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	shadowed := github.NewClient(&http.Client{Transport: &shadowTransport{log: l, module: module, base: base}})
+	shadowed.BaseURL, shadowed.UploadURL = client.BaseURL, client.UploadURL
+	return shadowed
+}
+
+// shadowTransport records the writes of a module and makes only those outside its shadow
+// repositories.
+type shadowTransport struct {
+	log    *ShadowLog
+	module string
+	base   http.RoundTripper
+}
+
+func (t *shadowTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, write, err := githubWrite(req)
+	if err != nil {
+		return nil, err
+	}
+	if !write {
+		return t.base.RoundTrip(req)
+	}
+	if len(body) > maxShadowBody {
+		body = body[:maxShadowBody]
+	}
+	repo, route := shadowRoute(req.URL.Path)
+	a := ModuleAction{
+		Module: t.module, Repo: repo, Action: req.Method + " " + route, Path: req.URL.Path, Body: string(body),
+	}
+	// Writes outside a repository, e.g. GraphQL mutations, are only ever recorded.
+	if repo != "" && !t.log.InShadow(t.module, repo) {
+		resp, err := t.base.RoundTrip(req)
+		if err == nil {
+			a.Mode, a.Status = ShadowModeLive, resp.StatusCode
+			t.log.record(req.Context(), a)
+		}
+		return resp, err
+	}
+
+	a.Mode = ShadowModeShadow
+	t.log.record(req.Context(), a)
+	slog.InfoContext(req.Context(), "Shadow mode: GitHub write recorded, not made", "module", t.module,
+		"repo", repo, "action", a.Action)
+	// Report success without a body, so callers see zero values for what would have been created.
+	status := http.StatusOK
+	if req.Method == http.MethodDelete {
+		status = http.StatusNoContent
+	}
+	return &http.Response{
+		Status:     strconv.Itoa(status) + " " + http.StatusText(status),
+		StatusCode: status,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     http.Header{},
+		Body:       http.NoBody,
+		Request:    req,
+	}, nil
+}
+
+// githubWrite reports whether a request changes anything on GitHub and returns its body,
+// which it leaves readable. GraphQL queries and Markdown rendering only read.
+func githubWrite(req *http.Request) ([]byte, bool, error) {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return nil, false, nil
+	}
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, false, err
+		}
+		_ = req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	switch {
+	case strings.HasSuffix(req.URL.Path, "/markdown"):
+		return body, false, nil
+	case strings.HasSuffix(req.URL.Path, "/graphql"):
+		var q struct {
+			Query string `json:"query"`
+		}
+		_ = json.Unmarshal(body, &q)
+		return body, strings.HasPrefix(strings.TrimSpace(q.Query), "mutation"), nil
+	}
+	return body, true, nil
+}
+
+// shadowRoute returns the repository of an API path and the path with the repository and
+// numbers replaced by placeholders, which groups the writes of the same action.
+func shadowRoute(p string) (repo, route string) {
+	segments := strings.Split(p, "/")
+	for i, s := range segments {
+		if s == "repos" && i+2 < len(segments) && repo == "" {
+			repo = segments[i+1] + "/" + segments[i+2]
+			segments[i+1], segments[i+2] = "{owner}", "{repo}"
+			continue
+		}
+		if _, err := strconv.ParseInt(s, 10, 64); err == nil {
+			segments[i] = "{n}"
+		}
+	}
+	return repo, strings.Join(segments, "/")
+}
+
+// record stores an action, logging rather than returning errors so recording never fails
+// the module's request.
+func (l *ShadowLog) record(ctx context.Context, a ModuleAction) {
+	_, err := l.db.ExecContext(context.WithoutCancel(ctx),
+		`INSERT INTO module_actions (at, module, mode, repo, action, path, body, status) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		l.now().UTC(), a.Module, a.Mode, a.Repo, a.Action, a.Path, a.Body, a.Status)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to record module action", "module", a.Module, "error", err)
+	}
+}
+
+// ModuleActionQuery filters actions returned by ShadowLog.Query. Zero values match everything.
+type ModuleActionQuery struct {
+	Module  string
+	Mode    string
+	Repo    string
+	Since   time.Time
+	AfterID int64
+	Limit   int // default 100; negative for no limit
+}
+
+// Query returns the actions matching q, newest first.
+func (l *ShadowLog) Query(ctx context.Context, q ModuleActionQuery) ([]ModuleAction, error) {
+	query := `SELECT ` + moduleActionColumns + ` FROM module_actions WHERE 1=1`
+	var args []any
+	for _, f := range []struct{ column, value string }{{"module", q.Module}, {"mode", q.Mode}, {"repo", q.Repo}} {
+		if f.value != "" {
+			query += " AND " + f.column + " = ?"
+			args = append(args, f.value)
+		}
+	}
+	if !q.Since.IsZero() {
+		query += " AND at >= ?"
+		args = append(args, q.Since.UTC())
+	}
+	if q.AfterID > 0 {
+		query += " AND id > ?"
+		args = append(args, q.AfterID)
+	}
+	limit := q.Limit
+	if limit == 0 {
+		limit = 100
+	}
+	query += " ORDER BY at DESC, id DESC LIMIT ?"
+	args = append(args, limit)
+
+	return l.query(ctx, query, args...)
+}
+
+// moduleActionListSchema describes recorded actions to the admin API's list query layer.
+var moduleActionListSchema = ListSchema{
+	Fields: slices.Concat(
+		ListColumns("id", "at", "module", "mode", "repo", "action", "path"),
+		[]ListField{{Name: "body", Column: "body", Hidden: true}, {Name: "status", Column: "status"}},
+	),
+	Time: "at",
+	Sort: "-at",
+	Key:  "id",
+}
+
+// Select returns the actions matching a list query.
+func (l *ShadowLog) Select(ctx context.Context, q ListQuery) ([]ModuleAction, error) {
+	query, args := q.SQL(`SELECT ` + moduleActionColumns + ` FROM module_actions`)
+	return l.query(ctx, query, args...)
+}
+
+const moduleActionColumns = `id, at, module, mode, repo, action, path, body, status`
+
+func (l *ShadowLog) query(ctx context.Context, query string, args ...any) ([]ModuleAction, error) {
+	rows, err := l.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, LogAndWrapError(err, ErrorTypeDatabase, "query_module_actions", nil)
+	}
+	defer rows.Close()
+	actions := []ModuleAction{}
+	for rows.Next() {
+		var a ModuleAction
+		if err := rows.Scan(&a.ID, &a.At, &a.Module, &a.Mode, &a.Repo, &a.Action, &a.Path, &a.Body,
+			&a.Status); err != nil {
+			return nil, err
+		}
+		actions = append(actions, a)
+	}
+	return actions, rows.Err()
+}
+
+// Report compares a module's actions in shadow mode with its live actions since the given time.
+func (l *ShadowLog) Report(ctx context.Context, module string, since time.Time) (ShadowReport, error) {
+	report := ShadowReport{Module: module, Since: since, Actions: []ShadowComparison{}}
+	err := l.db.QueryRowContext(ctx,
+		`SELECT COUNT(DISTINCT CASE WHEN mode = ? THEN repo END), COUNT(DISTINCT CASE WHEN mode = ? THEN repo END)
+		 FROM module_actions WHERE module = ? AND at >= ?`,
+		ShadowModeLive, ShadowModeShadow, module, since.UTC()).Scan(&report.LiveRepos, &report.ShadowRepos)
+	if err != nil {
+		return report, LogAndWrapError(err, ErrorTypeDatabase, "report_module_actions", nil)
+	}
+	rows, err := l.db.QueryContext(ctx,
+		`SELECT action,
+			SUM(mode = ?), COUNT(DISTINCT CASE WHEN mode = ? THEN repo END),
+			SUM(mode = ?), COUNT(DISTINCT CASE WHEN mode = ? THEN repo END)
+		 FROM module_actions WHERE module = ? AND at >= ?
+		 GROUP BY action ORDER BY action`,
+		ShadowModeLive, ShadowModeLive, ShadowModeShadow, ShadowModeShadow, module, since.UTC())
+	if err != nil {
+		return report, LogAndWrapError(err, ErrorTypeDatabase, "report_module_actions", nil)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var c ShadowComparison
+		if err := rows.Scan(&c.Action, &c.Live, &c.LiveRepos, &c.Shadow, &c.ShadowRepos); err != nil {
+			return report, err
+		}
+		report.Actions = append(report.Actions, c)
+	}
+	return report, rows.Err()
+}
+
+// Prune deletes the actions recorded before the given time and returns how many it deleted.
+func (l *ShadowLog) Prune(ctx context.Context, before time.Time) (int64, error) {
+	res, err := l.db.ExecContext(ctx, `DELETE FROM module_actions WHERE at < ?`, before.UTC())
+	if err != nil {
+		return 0, LogAndWrapError(err, ErrorTypeDatabase, "prune_module_actions", nil)
+	}
+	return res.RowsAffected()
+}
+
+// Job returns the scheduler job that deletes actions older than retention, once an hour.
+func (l *ShadowLog) Job(retention time.Duration) Job {
+	return Job{
+		Name:       ShadowPruneJobName,
+		Interval:   time.Hour,
+		Deferrable: true,
+		Run: func(ctx context.Context) error {
+			n, err := l.Prune(ctx, l.now().Add(-retention))
+			if n > 0 {
+				slog.Info("Pruned module actions", "count", n)
+			}
+			return err
+		},
+	}
+}
+
+// RegisterAdminRoutes serves the recorded actions and the comparison report on the admin
+// API. A nil log serves nothing.
+func (l *ShadowLog) RegisterAdminRoutes(srv *Server) {
+	if l == nil || srv == nil {
+		return
+	}
+	srv.HandleAdmin("GET /admin/shadow/actions", ListHandler(moduleActionListSchema, l.Select))
+	srv.HandleAdmin("GET /admin/shadow/report", l.handleReport)
+}
+
+// handleReport returns the comparison report of the module query parameter, over the
+// actions since the since parameter (default: the last 7 days).
+func (l *ShadowLog) handleReport(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	module := params.Get("module")
+	if module == "" {
+		http.Error(w, "module is required", http.StatusBadRequest)
+		return
+	}
+	since := l.now().Add(-7 * 24 * time.Hour)
+	if s := params.Get("since"); s != "" {
+		t, err := parseListTime(s)
+		if err != nil {
+			http.Error(w, "since must be an RFC 3339 time, a date or a duration such as 24h", http.StatusBadRequest)
+			return
+		}
+		since = t
+	}
+	report, err := l.Report(r.Context(), module, since)
+	if err != nil {
+		http.Error(w, "failed to report module actions", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		slog.Error("Failed to write JSON response", "error", err)
+	}
+}
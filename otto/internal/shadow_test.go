@@ -0,0 +1,230 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v71/github"
+	"golang.org/x/oauth2"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+)
+
+func TestShadowRoute(t *testing.T) {
+	tests := []struct {
+		path, repo, route string
+	}{
+		{"/repos/org/a/issues/12/comments", "org/a", "/repos/{owner}/{repo}/issues/{n}/comments"},
+		{"/api/v3/repos/org/a/issues/3/labels/stale", "org/a", "/api/v3/repos/{owner}/{repo}/issues/{n}/labels/stale"},
+		{"/graphql", "", "/graphql"},
+	}
+	for _, tt := range tests {
+		if repo, route := shadowRoute(tt.path); repo != tt.repo || route != tt.route {
+			t.Errorf("shadowRoute(%q) = %q, %q, want %q, %q", tt.path, repo, route, tt.repo, tt.route)
+		}
+	}
+}
+
+func TestShadowClient(t *testing.T) {
+	var mu sync.Mutex
+	var requests []string
+	client := TestGitHubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		mu.Unlock()
+		switch r.URL.Path {
+		case "/repos/live/a/issues/1/comments":
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": 7})
+		case "/repos/canary/a/issues/1/labels":
+			_ = json.NewEncoder(w).Encode([]map[string]any{{"name": "triage"}})
+		case "/graphql":
+			_, _ = w.Write([]byte(`{"data": {}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	log, err := NewShadowLog(TestDB(t), map[string][]string{"triage": {"canary/*"}})
+	if err != nil {
+		t.Fatalf("NewShadowLog failed: %v", err)
+	}
+	shadowed := log.Client(client, "triage")
+	ctx := t.Context()
+
+	// Reads are made everywhere; writes are made outside the shadow repositories.
+	if _, _, err := shadowed.Issues.ListLabelsByIssue(ctx, "canary", "a", 1, nil); err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+	comment := &github.IssueComment{Body: github.Ptr("hi")}
+	if c, _, err := shadowed.Issues.CreateComment(ctx, "live", "a", 1, comment); err != nil || c.GetID() != 7 {
+		t.Fatalf("live write = %v, %v", c, err)
+	}
+	labels, _, err := shadowed.Issues.AddLabelsToIssue(ctx, "canary", "a", 1, []string{"stale"})
+	if err != nil || len(labels) != 0 {
+		t.Fatalf("shadow write = %v, %v", labels, err)
+	}
+	if _, err := shadowed.Issues.RemoveLabelForIssue(ctx, "canary", "b", 2, "stale"); err != nil {
+		t.Fatalf("shadow delete failed: %v", err)
+	}
+	query, _ := shadowed.NewRequest(http.MethodPost, "graphql", map[string]any{"query": "query { viewer { login } }"})
+	if _, err := shadowed.Do(ctx, query, nil); err != nil {
+		t.Fatalf("GraphQL query failed: %v", err)
+	}
+	mutation, _ := shadowed.NewRequest(http.MethodPost, "graphql", map[string]any{"query": "mutation { x }"})
+	if _, err := shadowed.Do(ctx, mutation, nil); err != nil {
+		t.Fatalf("GraphQL mutation failed: %v", err)
+	}
+
+	want := []string{"GET /repos/canary/a/issues/1/labels", "POST /repos/live/a/issues/1/comments", "POST /graphql"}
+	if len(requests) != len(want) {
+		t.Fatalf("requests = %v, want %v", requests, want)
+	}
+	for i := range want {
+		if requests[i] != want[i] {
+			t.Errorf("request %d = %s, want %s", i, requests[i], want[i])
+		}
+	}
+
+	actions, err := log.Query(ctx, ModuleActionQuery{Module: "triage"})
+	if err != nil || len(actions) != 4 {
+		t.Fatalf("actions = %+v, %v", actions, err)
+	}
+	live := actions[3]
+	if live.Mode != ShadowModeLive || live.Status != http.StatusCreated || live.Repo != "live/a" ||
+		live.Action != "POST /repos/{owner}/{repo}/issues/{n}/comments" || live.Body == "" {
+		t.Errorf("live action = %+v", live)
+	}
+	if a := actions[2]; a.Mode != ShadowModeShadow || a.Repo != "canary/a" || a.Body != `["stale"]`+"\n" {
+		t.Errorf("shadow action = %+v", a)
+	}
+	if a := actions[0]; a.Mode != ShadowModeShadow || a.Action != "POST /graphql" {
+		t.Errorf("mutation action = %+v", a)
+	}
+	if !log.Shadowed("triage") || log.Shadowed("sla") || !log.InShadow("triage", "canary/x") ||
+		log.InShadow("triage", "live/a") {
+		t.Error("unexpected shadow membership")
+	}
+}
+
+func TestShadowReport(t *testing.T) {
+	log, err := NewShadowLog(TestDB(t), map[string][]string{"sla": {"org/b"}})
+	if err != nil {
+		t.Fatalf("NewShadowLog failed: %v", err)
+	}
+	ctx := t.Context()
+	now := time.Date(2025, 6, 2, 12, 0, 0, 0, time.UTC)
+	log.now = func() time.Time { return now.Add(-30 * 24 * time.Hour) }
+	log.record(ctx, ModuleAction{Module: "sla", Mode: ShadowModeLive, Repo: "org/a", Action: "POST /old", Path: "/old"})
+	log.now = func() time.Time { return now }
+	comment := "POST /repos/{owner}/{repo}/issues/{n}/comments"
+	for _, a := range []ModuleAction{
+		{Module: "sla", Mode: ShadowModeLive, Repo: "org/a", Action: comment},
+		{Module: "sla", Mode: ShadowModeLive, Repo: "org/c", Action: comment},
+		{Module: "sla", Mode: ShadowModeShadow, Repo: "org/b", Action: comment},
+		{Module: "sla", Mode: ShadowModeShadow, Repo: "org/b", Action: comment},
+		{Module: "sla", Mode: ShadowModeShadow, Repo: "org/b", Action: "DELETE /repos/{owner}/{repo}/labels/x"},
+		{Module: "triage", Mode: ShadowModeShadow, Repo: "org/b", Action: comment},
+	} {
+		log.record(ctx, a)
+	}
+
+	rr := httptest.NewRecorder()
+	log.handleReport(rr, httptest.NewRequest(http.MethodGet, "/admin/shadow/report?module=sla", nil))
+	var report ShadowReport
+	if err := json.NewDecoder(rr.Body).Decode(&report); err != nil {
+		t.Fatalf("invalid report: %v", err)
+	}
+	if report.LiveRepos != 2 || report.ShadowRepos != 1 || len(report.Actions) != 2 {
+		t.Fatalf("report = %+v", report)
+	}
+	want := ShadowComparison{Action: comment, Live: 2, LiveRepos: 2, Shadow: 2, ShadowRepos: 1}
+	if report.Actions[1] != want {
+		t.Errorf("comment comparison = %+v, want %+v", report.Actions[1], want)
+	}
+	if c := report.Actions[0]; c.Live != 0 || c.Shadow != 1 {
+		t.Errorf("delete comparison = %+v", c)
+	}
+
+	rr = httptest.NewRecorder()
+	log.handleReport(rr, httptest.NewRequest(http.MethodGet, "/admin/shadow/report", nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("report without module: status = %d, want 400", rr.Code)
+	}
+
+	list := ListHandler(moduleActionListSchema, log.Select)
+	queries := map[string]int{
+		"": 7, "?module=sla&mode=shadow": 3, "?repo=org/a": 2, "?since=2025-06-01": 6, "?until=2025-06-01": 1,
+	}
+	for query, want := range queries {
+		rr := httptest.NewRecorder()
+		list(rr, httptest.NewRequest(http.MethodGet, "/admin/shadow/actions"+query, nil))
+		var actions []ModuleAction
+		if err := json.NewDecoder(rr.Body).Decode(&actions); err != nil || len(actions) != want {
+			t.Errorf("%q: got %d actions (%v), want %d", query, len(actions), err, want)
+		}
+	}
+
+	if n, err := log.Prune(ctx, now.Add(-24*time.Hour)); err != nil || n != 1 {
+		t.Errorf("Prune = %d, %v, want 1", n, err)
+	}
+	if _, err := NewShadowLog(TestDB(t), map[string][]string{"sla": {"org/["}}); err == nil {
+		t.Error("expected error for an invalid repository pattern")
+	}
+}
+
+func TestShadowInstallationClient(t *testing.T) {
+	var mu sync.Mutex
+	var writes []string
+	installation := TestGitHubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		writes = append(writes, r.Method+" "+r.URL.Path)
+		mu.Unlock()
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	modules := map[string][]string{"triage": {"canary/*"}}
+	shadow, err := NewShadowLog(TestDB(t), modules)
+	if err != nil {
+		t.Fatalf("NewShadowLog failed: %v", err)
+	}
+	app := &App{
+		Config:        &config.AppConfig{Shadow: config.ShadowConfig{Modules: modules}},
+		Logger:        slog.New(slog.DiscardHandler),
+		Shadow:        shadow,
+		appTokens:     oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "app"}),
+		installations: &installationClients{clients: map[int64]*github.Client{7: installation}},
+	}
+	ctx := t.Context()
+	comment := &github.IssueComment{Body: github.Ptr("hi")}
+
+	view := app.moduleView("triage")
+	client, err := view.InstallationClient(ctx, 7)
+	if err != nil {
+		t.Fatalf("InstallationClient failed: %v", err)
+	}
+	if _, _, err := client.Issues.CreateComment(ctx, "canary", "a", 1, comment); err != nil {
+		t.Fatalf("CreateComment failed: %v", err)
+	}
+	if len(writes) != 0 {
+		t.Errorf("writes = %v, want the shadowed write only recorded", writes)
+	}
+	actions, err := shadow.Query(ctx, ModuleActionQuery{Module: "triage"})
+	if err != nil || len(actions) != 1 || actions[0].Mode != ShadowModeShadow || actions[0].Repo != "canary/a" {
+		t.Errorf("actions = %+v, %v", actions, err)
+	}
+
+	if view = app.moduleView("sla"); view != app {
+		t.Fatalf("moduleView of a live module = %p; want the app", view)
+	}
+	client, err = view.InstallationClient(ctx, 7)
+	if err != nil || client != installation {
+		t.Fatalf("InstallationClient of a live module = %p, %v; want the installation's client", client, err)
+	}
+}
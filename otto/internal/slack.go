@@ -0,0 +1,81 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// slack.go posts messages to Slack using a bot token.
+
+package internal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// SlackBotTokenSecret is the secrets name of the Slack bot token.
+const SlackBotTokenSecret = "slack_bot_token"
+
+const defaultSlackAPIURL = "https://slack.com/api"
+
+// SlackClient sends messages through the Slack Web API.
+type SlackClient struct {
+	token      string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewSlackClient creates a client authenticated with the given bot token.
+func NewSlackClient(token string) *SlackClient {
+	return &SlackClient{
+		token:      token,
+		baseURL:    defaultSlackAPIURL,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// WithBaseURL overrides the Slack API base URL, for tests.
+func (c *SlackClient) WithBaseURL(url string) *SlackClient {
+	c.baseURL = url
+	return c
+}
+
+// WithHTTPClient overrides the client used to call the Slack API.
+func (c *SlackClient) WithHTTPClient(client *http.Client) *SlackClient {
+	c.httpClient = client
+	return c
+}
+
+// PostMessage posts text to a channel. Passing a Slack user ID as the channel
+// sends a direct message from the bot.
+func (c *SlackClient) PostMessage(ctx context.Context, channel, text string) error {
+	body, err := json.Marshal(map[string]string{"channel": channel, "text": text})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/chat.postMessage", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Authorization", "Bearer "+c.token)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post Slack message: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Slack reports most failures with HTTP 200 and ok=false.
+	var result struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to decode Slack response (status %d): %w", resp.StatusCode, err)
+	}
+	if !result.OK {
+		return fmt.Errorf("slack API error: %s", result.Error)
+	}
+	return nil
+}
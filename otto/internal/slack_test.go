@@ -0,0 +1,38 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSlackPostMessage(t *testing.T) {
+	var got map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/chat.postMessage" || r.Header.Get("Authorization") != "Bearer xoxb-test" {
+			t.Errorf("unexpected request %s with auth %q", r.URL.Path, r.Header.Get("Authorization"))
+		}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		if got["channel"] == "bad" {
+			_, _ = w.Write([]byte(`{"ok":false,"error":"channel_not_found"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	defer srv.Close()
+
+	client := NewSlackClient("xoxb-test").WithBaseURL(srv.URL)
+
+	if err := client.PostMessage(t.Context(), "U123", "hello"); err != nil {
+		t.Fatalf("PostMessage failed: %v", err)
+	}
+	if got["channel"] != "U123" || got["text"] != "hello" {
+		t.Errorf("unexpected message body: %v", got)
+	}
+	if err := client.PostMessage(t.Context(), "bad", "hello"); err == nil {
+		t.Error("expected error for ok=false response")
+	}
+}
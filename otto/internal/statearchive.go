@@ -0,0 +1,488 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// statearchive.go exports the database and the effective configuration to a tar.gz
+// archive and restores it, so an instance can be rebuilt after losing its volume or
+// cloned to staging. Values keep their SQLite storage class, so a restored database reads
+// back exactly as the exported one.
+
+package internal
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+)
+
+// StateArchiveFormat is the version of the archive layout ExportState writes. ImportState
+// rejects archives with a newer format.
+const StateArchiveFormat = 1
+
+// Archive entries, in the order they are written.
+const (
+	stateManifestFile = "manifest.json"
+	stateConfigFile   = "config.yaml"
+	stateTablesDir    = "tables/"
+)
+
+// StateManifest describes an exported archive.
+type StateManifest struct {
+	Format     int          `json:"format"`
+	Version    string       `json:"otto_version"`
+	ExportedAt time.Time    `json:"exported_at"`
+	Tables     []StateTable `json:"tables"`
+}
+
+// StateTable is an exported table: its schema and how many rows the archive holds.
+type StateTable struct {
+	Name    string   `json:"name"`
+	Schema  string   `json:"schema"`
+	Indexes []string `json:"indexes,omitempty"`
+	Columns []string `json:"columns"`
+	Rows    int      `json:"rows"`
+}
+
+// quoteIdent quotes an SQLite identifier.
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// queryer is satisfied by *sql.DB and *sql.Tx.
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// tableColumns returns the columns of a table in order, or none if it does not exist.
+func tableColumns(ctx context.Context, q queryer, table string) ([]string, error) {
+	rows, err := q.QueryContext(ctx, `SELECT name FROM pragma_table_info(?) ORDER BY cid`, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var columns []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		columns = append(columns, name)
+	}
+	return columns, rows.Err()
+}
+
+// ExportState writes every table of the database and the effective configuration to w
+// as a tar.gz archive. The tables are read in one transaction, so the archive is a
+// consistent snapshot of a running instance.
+func ExportState(ctx context.Context, db *sql.DB, cfg *config.AppConfig, w io.Writer) (*StateManifest, error) {
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	manifest := &StateManifest{Format: StateArchiveFormat, Version: BuildVersion(), ExportedAt: time.Now().UTC()}
+	rows, err := tx.QueryContext(ctx, `SELECT name, sql FROM sqlite_master
+		WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var t StateTable
+		if err := rows.Scan(&t.Name, &t.Schema); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		manifest.Tables = append(manifest.Tables, t)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Tables are buffered so the manifest, which is read first on import, can hold the
+	// row counts.
+	data := make([][]byte, len(manifest.Tables))
+	for i := range manifest.Tables {
+		t := &manifest.Tables[i]
+		if t.Indexes, err = tableIndexes(ctx, tx, t.Name); err != nil {
+			return nil, fmt.Errorf("table %s: %w", t.Name, err)
+		}
+		if t.Columns, err = tableColumns(ctx, tx, t.Name); err != nil {
+			return nil, fmt.Errorf("table %s: %w", t.Name, err)
+		}
+		if data[i], t.Rows, err = exportTable(ctx, tx, t.Name, t.Columns); err != nil {
+			return nil, fmt.Errorf("table %s: %w", t.Name, err)
+		}
+	}
+
+	manifestJSON, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	var configYAML bytes.Buffer
+	enc := yaml.NewEncoder(&configYAML)
+	enc.SetIndent(2)
+	if err := enc.Encode(configSnapshot(reflect.ValueOf(cfg))); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	write := func(name string, content []byte) error {
+		hdr := &tar.Header{Name: name, Mode: 0o600, Size: int64(len(content)), ModTime: manifest.ExportedAt}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		_, err := tw.Write(content)
+		return err
+	}
+	if err := write(stateManifestFile, manifestJSON); err != nil {
+		return nil, err
+	}
+	if err := write(stateConfigFile, configYAML.Bytes()); err != nil {
+		return nil, err
+	}
+	for i, t := range manifest.Tables {
+		if err := write(stateTablesDir+t.Name+".jsonl", data[i]); err != nil {
+			return nil, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// tableIndexes returns the CREATE INDEX statements of a table's explicit indexes.
+func tableIndexes(ctx context.Context, q queryer, table string) ([]string, error) {
+	rows, err := q.QueryContext(ctx, `SELECT sql FROM sqlite_master
+		WHERE type = 'index' AND tbl_name = ? AND sql IS NOT NULL ORDER BY name`, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var indexes []string
+	for rows.Next() {
+		var s string
+		if err := rows.Scan(&s); err != nil {
+			return nil, err
+		}
+		indexes = append(indexes, s)
+	}
+	return indexes, rows.Err()
+}
+
+// exportTable encodes the rows of a table as JSON arrays, one per line. Integers and text
+// are JSON numbers and strings; reals and blobs are {"real": n} and {"blob": base64}
+// objects so they keep their storage class.
+func exportTable(ctx context.Context, q queryer, table string, columns []string) ([]byte, int, error) {
+	// Selecting typeof(c) and +c rather than c keeps the driver from converting values
+	// by the column's declared type, e.g. TIMESTAMP text into time.Time.
+	exprs := make([]string, 0, 2*len(columns))
+	for _, c := range columns {
+		exprs = append(exprs, "typeof("+quoteIdent(c)+")", "+"+quoteIdent(c))
+	}
+	rows, err := q.QueryContext(ctx, "SELECT "+strings.Join(exprs, ", ")+" FROM "+quoteIdent(table)+" ORDER BY rowid")
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var out []byte
+	count := 0
+	scanned := make([]any, 2*len(columns))
+	dest := make([]any, len(scanned))
+	for i := range scanned {
+		dest[i] = &scanned[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return nil, 0, err
+		}
+		values := make([]any, len(columns))
+		for i := range columns {
+			kind, _ := scanned[2*i].(string)
+			values[i] = encodeStateValue(kind, scanned[2*i+1])
+		}
+		line, err := json.Marshal(values)
+		if err != nil {
+			return nil, 0, err
+		}
+		out = append(append(out, line...), '\n')
+		count++
+	}
+	return out, count, rows.Err()
+}
+
+func encodeStateValue(kind string, v any) any {
+	switch kind {
+	case "null":
+		return nil
+	case "real":
+		return map[string]any{"real": v}
+	case "blob":
+		b, _ := v.([]byte)
+		return map[string]string{"blob": base64.StdEncoding.EncodeToString(b)}
+	case "text":
+		if b, ok := v.([]byte); ok {
+			return string(b)
+		}
+	}
+	return v
+}
+
+func decodeStateValue(v any) (any, error) {
+	switch v := v.(type) {
+	case nil, string:
+		return v, nil
+	case json.Number:
+		return v.Int64()
+	case map[string]any:
+		if r, ok := v["real"].(json.Number); ok {
+			return r.Float64()
+		}
+		if b, ok := v["blob"].(string); ok {
+			return base64.StdEncoding.DecodeString(b)
+		}
+	}
+	return nil, fmt.Errorf("unexpected value %v", v)
+}
+
+// ImportState restores the tables of an archive written by ExportState into db, in one
+// transaction, and returns its manifest and effective configuration. Tables the database
+// lacks are created; tables it has must have every exported column, or the archive is
+// from a newer Otto and is rejected. Tables that already hold rows are rejected unless
+// replace is set, in which case their rows are deleted first.
+func ImportState(ctx context.Context, db *sql.DB, r io.Reader, replace bool) (*StateManifest, []byte, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("not a state archive: %w", err)
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+	next := func(want string) ([]byte, error) {
+		hdr, err := tr.Next()
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", want, err)
+		}
+		if hdr.Name != want {
+			return nil, fmt.Errorf("found %s where %s was expected", hdr.Name, want)
+		}
+		return io.ReadAll(tr)
+	}
+
+	data, err := next(stateManifestFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	var manifest StateManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+	if manifest.Format < 1 || manifest.Format > StateArchiveFormat {
+		return nil, nil, fmt.Errorf("archive format %d is not supported (this Otto reads up to %d)",
+			manifest.Format, StateArchiveFormat)
+	}
+	configYAML, err := next(stateConfigFile)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+	if err := prepareStateTables(ctx, tx, &manifest, replace); err != nil {
+		return nil, nil, err
+	}
+	for _, t := range manifest.Tables {
+		data, err := next(stateTablesDir + t.Name + ".jsonl")
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := importTable(ctx, tx, t, data); err != nil {
+			return nil, nil, fmt.Errorf("table %s: %w", t.Name, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, nil, err
+	}
+	return &manifest, configYAML, nil
+}
+
+// prepareStateTables checks the database's schema against the manifest, creates missing
+// tables and empties the tables being replaced.
+func prepareStateTables(ctx context.Context, tx *sql.Tx, manifest *StateManifest, replace bool) error {
+	var occupied []string
+	for _, t := range manifest.Tables {
+		columns, err := tableColumns(ctx, tx, t.Name)
+		if err != nil {
+			return err
+		}
+		if len(columns) == 0 {
+			if !strings.HasPrefix(t.Schema, "CREATE TABLE") {
+				return fmt.Errorf("table %s: unexpected schema %q", t.Name, t.Schema)
+			}
+			stmts := append([]string{t.Schema}, t.Indexes...)
+			for _, s := range stmts {
+				if !strings.HasPrefix(s, "CREATE ") {
+					return fmt.Errorf("table %s: unexpected schema %q", t.Name, s)
+				}
+				if _, err := tx.ExecContext(ctx, s); err != nil {
+					return fmt.Errorf("table %s: %w", t.Name, err)
+				}
+			}
+			continue
+		}
+		for _, c := range t.Columns {
+			if !slices.Contains(columns, c) {
+				return fmt.Errorf("table %s has no column %s: the archive was exported by a newer Otto (%s)",
+					t.Name, c, manifest.Version)
+			}
+		}
+		var n int
+		if err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+quoteIdent(t.Name)).Scan(&n); err != nil {
+			return err
+		}
+		if n > 0 {
+			occupied = append(occupied, t.Name)
+		}
+	}
+	if len(occupied) > 0 && !replace {
+		return fmt.Errorf("tables already hold data, import with replace to overwrite them: %s",
+			strings.Join(occupied, ", "))
+	}
+	for _, name := range occupied {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM "+quoteIdent(name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// importTable inserts the rows exportTable encoded.
+func importTable(ctx context.Context, tx *sql.Tx, t StateTable, data []byte) error {
+	columns := make([]string, len(t.Columns))
+	for i, c := range t.Columns {
+		columns[i] = quoteIdent(c)
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ")
+	stmt, err := tx.PrepareContext(ctx, "INSERT INTO "+quoteIdent(t.Name)+" ("+strings.Join(columns, ", ")+
+		") VALUES ("+placeholders+")")
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	count := 0
+	for {
+		var values []any
+		if err := dec.Decode(&values); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return fmt.Errorf("row %d: %w", count+1, err)
+		}
+		if len(values) != len(columns) {
+			return fmt.Errorf("row %d has %d values, want %d", count+1, len(values), len(columns))
+		}
+		for i, v := range values {
+			if values[i], err = decodeStateValue(v); err != nil {
+				return fmt.Errorf("row %d, column %s: %w", count+1, t.Columns[i], err)
+			}
+		}
+		if _, err := stmt.ExecContext(ctx, values...); err != nil {
+			return fmt.Errorf("row %d: %w", count+1, err)
+		}
+		count++
+	}
+	if count != t.Rows {
+		return fmt.Errorf("archive holds %d rows, the manifest %d", count, t.Rows)
+	}
+	return nil
+}
+
+// configSnapshot converts a config value into YAML-ready maps keyed by the yaml tags,
+// with durations written as strings such as "5m0s" so the snapshot reads like config.yaml.
+func configSnapshot(v reflect.Value) any {
+	switch {
+	case !v.IsValid():
+		return nil
+	case v.Type() == durationType:
+		return time.Duration(v.Int()).String()
+	}
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return configSnapshot(v.Elem())
+	case reflect.Struct:
+		out := map[string]any{}
+		for i := range v.NumField() {
+			f := v.Type().Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			name, opts, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+			if name == "-" {
+				continue
+			}
+			value := configSnapshot(v.Field(i))
+			if opts == "inline" {
+				if inline, ok := value.(map[string]any); ok {
+					for k, v := range inline {
+						out[k] = v
+					}
+				}
+				continue
+			}
+			if name == "" {
+				name = strings.ToLower(f.Name)
+			}
+			out[name] = value
+		}
+		return out
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		out := map[string]any{}
+		for _, k := range v.MapKeys() {
+			out[fmt.Sprint(k.Interface())] = configSnapshot(v.MapIndex(k))
+		}
+		return out
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		out := make([]any, v.Len())
+		for i := range out {
+			out[i] = configSnapshot(v.Index(i))
+		}
+		return out
+	}
+	return v.Interface()
+}
@@ -0,0 +1,150 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+)
+
+// seedStateDB creates tables with every SQLite storage class and an index.
+func seedStateDB(t *testing.T) *sql.DB {
+	db := TestDB(t)
+	for _, s := range []string{
+		`CREATE TABLE flags (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT NOT NULL, enabled BOOLEAN,
+			ratio REAL, data BLOB, updated_at TIMESTAMP)`,
+		`CREATE INDEX idx_flags_name ON flags (name)`,
+		`CREATE TABLE "odd ""name""" (value TEXT)`,
+	} {
+		if _, err := db.Exec(s); err != nil {
+			t.Fatalf("seed failed: %v", err)
+		}
+	}
+	at := time.Date(2025, 5, 10, 12, 30, 0, 0, time.UTC)
+	for _, args := range [][]any{
+		{"sla", true, 0.5, []byte{0, 1, 2}, at},
+		{"holds", false, 2.0, nil, nil},
+		{"quoted 'text'\n", nil, nil, []byte{}, at.Add(time.Hour)},
+	} {
+		if _, err := db.Exec(`INSERT INTO flags (name, enabled, ratio, data, updated_at) VALUES (?, ?, ?, ?, ?)`,
+			args...); err != nil {
+			t.Fatalf("insert failed: %v", err)
+		}
+	}
+	if _, err := db.Exec(`DELETE FROM flags WHERE name = 'holds'`); err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+	return db
+}
+
+// dumpFlags renders the flags table with the storage class of every value.
+func dumpFlags(t *testing.T, db *sql.DB) string {
+	rows, err := db.Query(`SELECT name, typeof(enabled), enabled, typeof(ratio), ratio, typeof(data), hex(data),
+		typeof(updated_at), updated_at FROM flags ORDER BY id`)
+	if err != nil {
+		t.Fatalf("query failed: %v", err)
+	}
+	defer rows.Close()
+	var b strings.Builder
+	for rows.Next() {
+		var name, enabledType, ratioType, dataType, data, updatedAtType string
+		var enabled sql.NullBool
+		var ratio sql.NullFloat64
+		var updatedAt sql.NullTime
+		if err := rows.Scan(&name, &enabledType, &enabled, &ratioType, &ratio, &dataType, &data, &updatedAtType,
+			&updatedAt); err != nil {
+			t.Fatalf("scan failed: %v", err)
+		}
+		fmt.Fprintf(&b, "%q|%s %v|%s %v|%s %s|%s %v\n", name, enabledType, enabled, ratioType, ratio, dataType, data,
+			updatedAtType, updatedAt)
+	}
+	return b.String()
+}
+
+func TestStateExportImport(t *testing.T) {
+	ctx := context.Background()
+	source := seedStateDB(t)
+	cfg := &config.AppConfig{DBPath: "otto.db"}
+	config.ApplyDefaults(cfg)
+
+	var archive bytes.Buffer
+	manifest, err := ExportState(ctx, source, cfg, &archive)
+	if err != nil {
+		t.Fatalf("ExportState failed: %v", err)
+	}
+	if len(manifest.Tables) != 2 || manifest.Tables[0].Name != "flags" || manifest.Tables[0].Rows != 2 ||
+		len(manifest.Tables[0].Indexes) != 1 || manifest.Format != StateArchiveFormat {
+		t.Fatalf("manifest = %+v", manifest)
+	}
+
+	target := TestDB(t)
+	imported, configYAML, err := ImportState(ctx, target, bytes.NewReader(archive.Bytes()), false)
+	if err != nil {
+		t.Fatalf("ImportState failed: %v", err)
+	}
+	if len(imported.Tables) != 2 {
+		t.Errorf("imported manifest = %+v", imported)
+	}
+	if got, want := dumpFlags(t, target), dumpFlags(t, source); got != want {
+		t.Errorf("imported rows:\n%s\nwant:\n%s", got, want)
+	}
+	// The next AUTOINCREMENT id continues after the exported ones.
+	if _, err := target.Exec(`INSERT INTO flags (name) VALUES ('new')`); err != nil {
+		t.Fatalf("insert failed: %v", err)
+	}
+	var maxID int
+	if err := target.QueryRow(`SELECT MAX(id) FROM flags`).Scan(&maxID); err != nil || maxID != 4 {
+		t.Errorf("next id = %d, %v, want 4", maxID, err)
+	}
+	for _, want := range []string{"db_path: otto.db", "interval: 5m0s", "record: false"} {
+		if !strings.Contains(string(configYAML), want) {
+			t.Errorf("config snapshot lacks %q:\n%s", want, configYAML)
+		}
+	}
+
+	// Tables holding data are only overwritten with replace.
+	if _, _, err := ImportState(ctx, target, bytes.NewReader(archive.Bytes()), false); err == nil ||
+		!strings.Contains(err.Error(), "already hold data") {
+		t.Errorf("import into a used database = %v", err)
+	}
+	if _, _, err := ImportState(ctx, target, bytes.NewReader(archive.Bytes()), true); err != nil {
+		t.Fatalf("import with replace failed: %v", err)
+	}
+	if got, want := dumpFlags(t, target), dumpFlags(t, source); got != want {
+		t.Errorf("replaced rows:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestStateImportSchemaChecks(t *testing.T) {
+	ctx := context.Background()
+	var archive bytes.Buffer
+	if _, err := ExportState(ctx, seedStateDB(t), &config.AppConfig{}, &archive); err != nil {
+		t.Fatalf("ExportState failed: %v", err)
+	}
+
+	// A database of an older Otto that does not have the ratio column yet.
+	older := TestDB(t)
+	if _, err := older.Exec(`CREATE TABLE flags (id INTEGER PRIMARY KEY, name TEXT, enabled BOOLEAN, data BLOB,
+		updated_at TIMESTAMP)`); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	_, _, err := ImportState(ctx, older, bytes.NewReader(archive.Bytes()), true)
+	if err == nil || !strings.Contains(err.Error(), "has no column ratio") {
+		t.Errorf("import into an older schema = %v", err)
+	}
+	var n int
+	if err := older.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE name LIKE 'odd%'`).Scan(&n); err != nil || n != 0 {
+		t.Errorf("a failed import left tables behind: %d, %v", n, err)
+	}
+
+	if _, _, err := ImportState(ctx, TestDB(t), strings.NewReader("not gzip"), false); err == nil {
+		t.Error("expected error for an invalid archive")
+	}
+}
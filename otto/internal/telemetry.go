@@ -8,6 +8,11 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
@@ -17,14 +22,16 @@ import (
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"go.opentelemetry.io/otel/trace"
 
-	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
-	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
-	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
 	"go.opentelemetry.io/otel/log/global"
 	"go.opentelemetry.io/otel/sdk/resource"
 	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.opentelemetry.io/contrib/bridges/otelslog"
+	otelprometheus "go.opentelemetry.io/otel/exporters/prometheus"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
 )
 
 // InitMetrics initializes all metrics for the TelemetryManager.
@@ -47,12 +54,24 @@ func (t *TelemetryManager) InitMetrics() error {
 
 	t.ServerWebhooks, err = meter.Int64Counter(
 		"otto.server.webhooks_total",
-		metric.WithDescription("Webhooks received"),
+		metric.WithDescription("Authenticated webhooks received, by event type and action"),
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create server webhooks counter: %w", err)
 	}
 
+	t.ServerWebhookRepos, err = meter.Int64ObservableGauge(
+		"otto.server.webhook_repos",
+		metric.WithDescription("Distinct repositories that sent webhooks in the last hour"),
+		metric.WithInt64Callback(func(_ context.Context, o metric.Int64Observer) error {
+			o.Observe(int64(t.webhookRepos.count(time.Now())), t.attrs())
+			return nil
+		}),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create server webhook repos gauge: %w", err)
+	}
+
 	t.ServerErrors, err = meter.Int64Counter(
 		"otto.server.errors_total",
 		metric.WithDescription("Server errors"),
@@ -94,18 +113,226 @@ func (t *TelemetryManager) InitMetrics() error {
 		return fmt.Errorf("failed to create module ack latency histogram: %w", err)
 	}
 
+	t.ModuleOverdue, err = meter.Int64Counter(
+		"otto.module.overdue_handlers_total",
+		metric.WithDescription("Module handlers that ran past their watchdog deadline"),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create module overdue handlers counter: %w", err)
+	}
+
+	// Dispatch metrics
+	t.DispatchQueueDepth, err = meter.Int64UpDownCounter(
+		"otto.dispatch.queue_depth",
+		metric.WithDescription("Events waiting for a dispatch worker, by priority"),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create dispatch queue depth counter: %w", err)
+	}
+
+	t.DispatchQueueWait, err = meter.Float64Histogram(
+		"otto.dispatch.queue_wait_ms",
+		metric.WithDescription("Time events waited for a dispatch worker (ms), by priority"),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create dispatch queue wait histogram: %w", err)
+	}
+
+	// Scheduler metrics
+	t.JobRuns, err = meter.Int64Counter(
+		"otto.scheduler.job_runs_total",
+		metric.WithDescription("Scheduled job executions"),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create job runs counter: %w", err)
+	}
+
+	t.JobLatency, err = meter.Float64Histogram(
+		"otto.scheduler.job_duration_ms",
+		metric.WithDescription("Scheduled job duration (ms)"),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create job duration histogram: %w", err)
+	}
+
+	// Database metrics
+	t.DBIntegrityFailures, err = meter.Int64Counter(
+		"otto.db.integrity_failures_total",
+		metric.WithDescription("Database integrity check failures"),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create db integrity failures counter: %w", err)
+	}
+
+	t.WebhookUnknownFields, err = meter.Int64Counter(
+		"otto.webhook.unknown_fields_total",
+		metric.WithDescription("Webhook payload fields that go-github does not parse, by event type and field"),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create webhook unknown fields counter: %w", err)
+	}
+
+	t.WebhookTruncated, err = meter.Int64Counter(
+		"otto.webhook.truncated_total",
+		metric.WithDescription("Webhook payload fields that GitHub truncated, by event type and field"),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create webhook truncated counter: %w", err)
+	}
+
+	// GitHub API metrics
+	t.GitHubAPICalls, err = meter.Int64Counter(
+		"otto.github.api_calls_total",
+		metric.WithDescription("GitHub API calls attributed to a module, by budget outcome"),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create github api calls counter: %w", err)
+	}
+
+	// Notification metrics
+	t.Notifications, err = meter.Int64Counter(
+		"otto.notifications_total",
+		metric.WithDescription("Notification deliveries per backend, by outcome"),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create notifications counter: %w", err)
+	}
+
+	t.NotificationRetries, err = meter.Int64Counter(
+		"otto.notification_retries_total",
+		metric.WithDescription("Notification delivery retries per backend"),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create notification retries counter: %w", err)
+	}
+
+	// Content filter metrics
+	t.ContentBlocked, err = meter.Int64Counter(
+		"otto.content_filter.blocked_total",
+		metric.WithDescription("Posts blocked by the content filter, by module and reason"),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create content filter blocked counter: %w", err)
+	}
+
+	// Probe metrics
+	t.ProbeRuns, err = meter.Int64Counter(
+		"otto.probe.runs_total",
+		metric.WithDescription("Synthetic webhook probes, by outcome"),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create probe runs counter: %w", err)
+	}
+
+	t.ProbeLatency, err = meter.Float64Histogram(
+		"otto.probe.latency_ms",
+		metric.WithDescription("Time from posting a synthetic webhook to its dispatch to the canary module (ms)"),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create probe latency histogram: %w", err)
+	}
+
+	t.ModuleCommandsWeekly, err = meter.Int64ObservableGauge(
+		"otto.module.commands_weekly",
+		metric.WithDescription("Slash commands used in the last complete week, by command, repo and user role"),
+		metric.WithInt64Callback(func(_ context.Context, o metric.Int64Observer) error {
+			if usage := t.weeklyCommands.Load(); usage != nil {
+				for _, u := range *usage {
+					o.Observe(u.Count, t.attrs(attribute.String("command", u.Command),
+						attribute.String("repo", u.Repo), attribute.String("role", u.Role)))
+				}
+			}
+			return nil
+		}),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create weekly commands gauge: %w", err)
+	}
+
+	// Instance metrics
+	t.InstanceLeader, err = meter.Int64ObservableGauge(
+		"otto.instance.leader",
+		metric.WithDescription("1 if this instance runs scheduled jobs, 0 otherwise"),
+		metric.WithInt64Callback(func(_ context.Context, o metric.Int64Observer) error {
+			var v int64
+			if t.IsLeader() {
+				v = 1
+			}
+			o.Observe(v, t.attrs())
+			return nil
+		}),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create instance leader gauge: %w", err)
+	}
+
 	t.metricsInitialized = true
 	return nil
 }
 
+// attrs adds the instance identifier to metric attributes so replicas can be told apart
+// in backends that drop resource attributes.
+func (t *TelemetryManager) attrs(kv ...attribute.KeyValue) metric.MeasurementOption {
+	if t.InstanceID != "" {
+		kv = append(kv, semconv.ServiceInstanceID(t.InstanceID))
+	}
+	return metric.WithAttributes(kv...)
+}
+
+// SetLeader records whether this instance currently runs scheduled jobs.
+func (t *TelemetryManager) SetLeader(leader bool) {
+	t.follower.Store(!leader)
+}
+
+// IsLeader reports whether this instance currently runs scheduled jobs.
+func (t *TelemetryManager) IsLeader() bool {
+	return !t.follower.Load()
+}
+
 // IncServerRequest records an HTTP request in server metrics.
 func (t *TelemetryManager) IncServerRequest(ctx context.Context, handler string) {
-	t.ServerRequests.Add(ctx, 1, metric.WithAttributes(attribute.String("handler", handler)))
+	t.ServerRequests.Add(ctx, 1, t.attrs(attribute.String("handler", handler)))
+}
+
+// IncServerWebhook records a webhook event in server metrics, with its action, e.g.
+// "opened", if it has one, and the repository it came from, if any, in the count of
+// distinct repositories.
+func (t *TelemetryManager) IncServerWebhook(ctx context.Context, eventType, action, repo string) {
+	kv := []attribute.KeyValue{attribute.String("event_type", eventType)}
+	if action != "" {
+		kv = append(kv, attribute.String("action", action))
+	}
+	t.ServerWebhooks.Add(ctx, 1, t.attrs(kv...))
+	if repo != "" {
+		t.webhookRepos.see(repo, time.Now())
+	}
+}
+
+// recentRepos tracks when each repository last sent a webhook.
+type recentRepos struct {
+	mu   sync.Mutex
+	seen map[string]time.Time
 }
 
-// IncServerWebhook records a webhook event in server metrics.
-func (t *TelemetryManager) IncServerWebhook(ctx context.Context, eventType string) {
-	t.ServerWebhooks.Add(ctx, 1, metric.WithAttributes(attribute.String("event_type", eventType)))
+func (r *recentRepos) see(repo string, now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.seen == nil {
+		r.seen = make(map[string]time.Time)
+	}
+	r.seen[repo] = now
+}
+
+// count returns the number of repositories seen in the hour before now, forgetting the rest.
+func (r *recentRepos) count(now time.Time) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for repo, at := range r.seen {
+		if now.Sub(at) > time.Hour {
+			delete(r.seen, repo)
+		}
+	}
+	return len(r.seen)
 }
 
 // IncServerError records a server error in metrics.
@@ -113,7 +340,7 @@ func (t *TelemetryManager) IncServerError(ctx context.Context, handler string, e
 	t.ServerErrors.Add(
 		ctx,
 		1,
-		metric.WithAttributes(
+		t.attrs(
 			attribute.String("handler", handler),
 			attribute.String("err_type", errType),
 		),
@@ -125,7 +352,7 @@ func (t *TelemetryManager) RecordServerLatency(ctx context.Context, handler stri
 	t.ServerLatencyHistogram.Record(
 		ctx,
 		ms,
-		metric.WithAttributes(attribute.String("handler", handler)),
+		t.attrs(attribute.String("handler", handler)),
 	)
 }
 
@@ -134,7 +361,7 @@ func (t *TelemetryManager) IncModuleCommand(ctx context.Context, module, command
 	t.ModuleCommands.Add(
 		ctx,
 		1,
-		metric.WithAttributes(
+		t.attrs(
 			attribute.String("module", module),
 			attribute.String("command", command),
 		),
@@ -146,7 +373,7 @@ func (t *TelemetryManager) IncModuleError(ctx context.Context, module, errType s
 	t.ModuleErrors.Add(
 		ctx,
 		1,
-		metric.WithAttributes(
+		t.attrs(
 			attribute.String("module", module),
 			attribute.String("err_type", errType),
 		),
@@ -155,7 +382,87 @@ func (t *TelemetryManager) IncModuleError(ctx context.Context, module, errType s
 
 // RecordAckLatency records module acknowledgment latency.
 func (t *TelemetryManager) RecordAckLatency(ctx context.Context, module string, ms float64) {
-	t.ModuleAckLatency.Record(ctx, ms, metric.WithAttributes(attribute.String("module", module)))
+	t.ModuleAckLatency.Record(ctx, ms, t.attrs(attribute.String("module", module)))
+}
+
+// CommandUsage is how often a slash command was used in a repository by users of a role.
+type CommandUsage struct {
+	Command string
+	Repo    string
+	Role    string
+	Count   int64
+}
+
+// SetWeeklyCommandUsage replaces the command usage otto.module.commands_weekly reports.
+func (t *TelemetryManager) SetWeeklyCommandUsage(usage []CommandUsage) {
+	t.weeklyCommands.Store(&usage)
+}
+
+// RecordJobRun records a scheduled job execution and its duration.
+func (t *TelemetryManager) RecordJobRun(ctx context.Context, job, status string, ms float64) {
+	attrs := t.attrs(attribute.String("job", job), attribute.String("status", status))
+	t.JobRuns.Add(ctx, 1, attrs)
+	t.JobLatency.Record(ctx, ms, t.attrs(attribute.String("job", job)))
+}
+
+// IncDBIntegrityFailure records a failed database integrity check.
+func (t *TelemetryManager) IncDBIntegrityFailure(ctx context.Context) {
+	t.DBIntegrityFailures.Add(ctx, 1, t.attrs())
+}
+
+// IncWebhookUnknownField records a webhook payload field that go-github does not parse.
+func (t *TelemetryManager) IncWebhookUnknownField(ctx context.Context, eventType, field string) {
+	t.WebhookUnknownFields.Add(ctx, 1,
+		t.attrs(attribute.String("event_type", eventType), attribute.String("field", field)))
+}
+
+// IncWebhookTruncated records a webhook payload field that GitHub truncated.
+func (t *TelemetryManager) IncWebhookTruncated(ctx context.Context, eventType, field string) {
+	t.WebhookTruncated.Add(ctx, 1,
+		t.attrs(attribute.String("event_type", eventType), attribute.String("field", field)))
+}
+
+// IncModuleOverdueHandler records a module handler that ran past its watchdog deadline.
+func (t *TelemetryManager) IncModuleOverdueHandler(ctx context.Context, module string) {
+	t.ModuleOverdue.Add(ctx, 1, t.attrs(attribute.String("module", module)))
+}
+
+// AddDispatchQueued adjusts the number of events waiting for a dispatch worker.
+func (t *TelemetryManager) AddDispatchQueued(ctx context.Context, priority string, delta int64) {
+	t.DispatchQueueDepth.Add(ctx, delta, t.attrs(attribute.String("priority", priority)))
+}
+
+// RecordDispatchWait records how long an event waited for a dispatch worker.
+func (t *TelemetryManager) RecordDispatchWait(ctx context.Context, priority string, ms float64) {
+	t.DispatchQueueWait.Record(ctx, ms, t.attrs(attribute.String("priority", priority)))
+}
+
+// IncGitHubAPICall records a module's GitHub API call and whether its budget allowed it.
+func (t *TelemetryManager) IncGitHubAPICall(ctx context.Context, module, outcome string) {
+	t.GitHubAPICalls.Add(ctx, 1, t.attrs(attribute.String("module", module), attribute.String("outcome", outcome)))
+}
+
+// RecordNotification records a notification delivery attempt's final outcome on a backend.
+func (t *TelemetryManager) RecordNotification(ctx context.Context, backend, outcome string) {
+	t.Notifications.Add(ctx, 1, t.attrs(attribute.String("backend", backend), attribute.String("outcome", outcome)))
+}
+
+// IncNotificationRetry records a retried notification delivery on a backend.
+func (t *TelemetryManager) IncNotificationRetry(ctx context.Context, backend string) {
+	t.NotificationRetries.Add(ctx, 1, t.attrs(attribute.String("backend", backend)))
+}
+
+// IncContentBlocked records a post the content filter blocked.
+func (t *TelemetryManager) IncContentBlocked(ctx context.Context, module, reason string) {
+	t.ContentBlocked.Add(ctx, 1, t.attrs(attribute.String("module", module), attribute.String("reason", reason)))
+}
+
+// RecordProbe records a synthetic webhook probe's outcome and, if it succeeded, its latency.
+func (t *TelemetryManager) RecordProbe(ctx context.Context, outcome string, ms float64) {
+	t.ProbeRuns.Add(ctx, 1, t.attrs(attribute.String("outcome", outcome)))
+	if outcome == "success" {
+		t.ProbeLatency.Record(ctx, ms, t.attrs())
+	}
 }
 
 // StartServerEventSpan creates a new tracing span for server event handling.
@@ -181,29 +488,87 @@ type TelemetryManager struct {
 	LoggerProvider *sdklog.LoggerProvider
 	Logger         *slog.Logger
 
+	// MetricsHandler serves the metrics in the Prometheus text format, for scrapers of the
+	// admin listener's /metrics; nil if telemetry was not set up by NewTelemetryManager.
+	MetricsHandler http.Handler
+
 	// Server metrics
 	ServerRequests         metric.Int64Counter
 	ServerWebhooks         metric.Int64Counter
+	ServerWebhookRepos     metric.Int64ObservableGauge
 	ServerErrors           metric.Int64Counter
 	ServerLatencyHistogram metric.Float64Histogram
+	WebhookUnknownFields   metric.Int64Counter
+	WebhookTruncated       metric.Int64Counter
 
 	// Module metrics
-	ModuleCommands   metric.Int64Counter
-	ModuleErrors     metric.Int64Counter
-	ModuleAckLatency metric.Float64Histogram
+	ModuleCommands       metric.Int64Counter
+	ModuleErrors         metric.Int64Counter
+	ModuleAckLatency     metric.Float64Histogram
+	ModuleOverdue        metric.Int64Counter
+	ModuleCommandsWeekly metric.Int64ObservableGauge
+
+	// Dispatch metrics
+	DispatchQueueDepth metric.Int64UpDownCounter
+	DispatchQueueWait  metric.Float64Histogram
+
+	// Scheduler metrics
+	JobRuns    metric.Int64Counter
+	JobLatency metric.Float64Histogram
+
+	// Database metrics
+	DBIntegrityFailures metric.Int64Counter
+
+	// GitHub API metrics
+	GitHubAPICalls metric.Int64Counter
+
+	// Notification metrics
+	Notifications       metric.Int64Counter
+	NotificationRetries metric.Int64Counter
+
+	// Content filter metrics
+	ContentBlocked metric.Int64Counter
+
+	// Probe metrics
+	ProbeRuns    metric.Int64Counter
+	ProbeLatency metric.Float64Histogram
+
+	// Instance metrics
+	InstanceLeader metric.Int64ObservableGauge
+
+	// InstanceID identifies this replica in the resource and in metric attributes.
+	InstanceID string
 
+	follower           atomic.Bool // inverted so the zero value means leader
+	webhookRepos       recentRepos
+	weeklyCommands     atomic.Pointer[[]CommandUsage]
 	metricsInitialized bool
 }
 
-// NewTelemetryManager creates a new telemetry manager with OpenTelemetry components.
-func NewTelemetryManager(ctx context.Context) (*TelemetryManager, error) {
+// NewTelemetryManager creates a new telemetry manager with OpenTelemetry components,
+// exporting each signal as cfg selects. instanceID is reported as service.instance.id. The
+// newMetricsScraper returns a metric reader collecting into its own Prometheus registry,
+// and the handler that serves that registry to scrapers.
+func newMetricsScraper() (sdkmetric.Reader, http.Handler, error) {
+	registry := prometheus.NewRegistry()
+	reader, err := otelprometheus.New(otelprometheus.WithRegisterer(registry))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create prometheus exporter: %w", err)
+	}
+	return reader, promhttp.HandlerFor(registry, promhttp.HandlerOpts{}), nil
+}
+
+// OTLP/HTTP exporters use the proxy and TLS settings of transport if it is not nil.
+func NewTelemetryManager(ctx context.Context, instanceID string, cfg config.TelemetryConfig,
+	transport *http.Transport) (*TelemetryManager, error) {
 	// Create resource
 	res, err := resource.Merge(
 		resource.Default(),
 		resource.NewWithAttributes(
 			semconv.SchemaURL,
 			semconv.ServiceName("otto"),
-			semconv.ServiceVersion("dev"), // TODO: wire in a build flag for version
+			semconv.ServiceVersion(BuildVersion()),
+			semconv.ServiceInstanceID(instanceID),
 		),
 	)
 	if err != nil {
@@ -211,47 +576,64 @@ func NewTelemetryManager(ctx context.Context) (*TelemetryManager, error) {
 	}
 
 	// Create trace components
-	traceExporter, err := otlptracehttp.New(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create otlp trace exporter: %w", err)
+	ratio := 1.0
+	if cfg.SampleRatio != nil {
+		ratio = *cfg.SampleRatio
 	}
-	traceProcessor := sdktrace.NewBatchSpanProcessor(traceExporter)
-	tracerProvider := sdktrace.NewTracerProvider(
+	traceOpts := []sdktrace.TracerProviderOption{
 		sdktrace.WithResource(res),
-		sdktrace.WithSpanProcessor(traceProcessor),
-	)
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+	}
+	traceExporter, err := newSpanExporter(ctx, cfg.Traces, transport)
+	if err != nil {
+		return nil, err
+	}
+	if traceExporter != nil {
+		traceOpts = append(traceOpts, sdktrace.WithSpanProcessor(sdktrace.NewBatchSpanProcessor(traceExporter)))
+	}
+	tracerProvider := sdktrace.NewTracerProvider(traceOpts...)
 
-	// Create metric components
-	metricExporter, err := otlpmetrichttp.New(ctx)
+	// Create metric components; metrics are always served for scraping, and pushed to the
+	// exporter if there is one
+	scraper, metricsHandler, err := newMetricsScraper()
 	if err != nil {
-		return nil, fmt.Errorf("failed to create otlp metric exporter: %w", err)
+		return nil, err
 	}
-	metricProcessor := sdkmetric.NewPeriodicReader(metricExporter)
-	meterProvider := sdkmetric.NewMeterProvider(
-		sdkmetric.WithResource(res),
-		sdkmetric.WithReader(metricProcessor),
-	)
+	metricOpts := []sdkmetric.Option{sdkmetric.WithResource(res), sdkmetric.WithReader(scraper)}
+	metricExporter, err := newMetricExporter(ctx, cfg.Metrics, transport)
+	if err != nil {
+		return nil, err
+	}
+	if metricExporter != nil {
+		metricOpts = append(metricOpts, sdkmetric.WithReader(sdkmetric.NewPeriodicReader(metricExporter)))
+	}
+	meterProvider := sdkmetric.NewMeterProvider(metricOpts...)
 
-	// Create log components
-	logExporter, err := otlploghttp.New(ctx)
+	// Create log components; without an exporter, slog writes to stderr
+	logExporter, err := newLogExporter(ctx, cfg.Logs, transport)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create otlp log exporter: %w", err)
+		return nil, err
 	}
-	loggerProcessor := sdklog.NewBatchProcessor(logExporter)
-	loggerProvider := sdklog.NewLoggerProvider(
-		sdklog.WithResource(res),
-		sdklog.WithProcessor(loggerProcessor),
+	var (
+		loggerProvider *sdklog.LoggerProvider
+		logger         *slog.Logger
 	)
+	if logExporter != nil {
+		loggerProvider = sdklog.NewLoggerProvider(
+			sdklog.WithResource(res),
+			sdklog.WithProcessor(sdklog.NewBatchProcessor(logExporter)),
+		)
+		global.SetLoggerProvider(loggerProvider)
+		// Create slog bridge
+		logger = slog.New(otelslog.NewHandler("otto"))
+	} else {
+		logger = slog.New(slog.NewTextHandler(os.Stderr, nil))
+	}
+	slog.SetDefault(logger)
 
 	// Use the global provider registry for OpenTelemetry itself
 	otel.SetTracerProvider(tracerProvider)
 	otel.SetMeterProvider(meterProvider)
-	global.SetLoggerProvider(loggerProvider)
-
-	// Create slog bridge
-	handler := otelslog.NewHandler("otto")
-	logger := slog.New(handler)
-	slog.SetDefault(logger)
 
 	// Create telemetry manager
 	telemetry := &TelemetryManager{
@@ -259,6 +641,8 @@ func NewTelemetryManager(ctx context.Context) (*TelemetryManager, error) {
 		MeterProvider:  meterProvider,
 		LoggerProvider: loggerProvider,
 		Logger:         logger,
+		MetricsHandler: metricsHandler,
+		InstanceID:     instanceID,
 	}
 
 	// Initialize metrics
@@ -266,7 +650,8 @@ func NewTelemetryManager(ctx context.Context) (*TelemetryManager, error) {
 		return nil, fmt.Errorf("failed to initialize metrics: %w", err)
 	}
 
-	slog.Info("[otto] OpenTelemetry (trace, metric, log+slog bridge) initialized")
+	slog.Info("[otto] OpenTelemetry initialized", "traces", cfg.Traces, "metrics", cfg.Metrics, "logs", cfg.Logs,
+		"sample_ratio", ratio)
 	return telemetry, nil
 }
 
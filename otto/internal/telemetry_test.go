@@ -0,0 +1,103 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"maps"
+	"testing"
+	"time"
+
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+func TestInstanceMetrics(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	telemetry := TestTelemetry(t, reader)
+	telemetry.InstanceID = "otto-1"
+
+	telemetry.IncServerWebhook(t.Context(), "issues", "opened", "org/repo")
+
+	collect := func() map[string]metricdata.Aggregation {
+		var rm metricdata.ResourceMetrics
+		if err := reader.Collect(t.Context(), &rm); err != nil {
+			t.Fatalf("failed to collect metrics: %v", err)
+		}
+		out := make(map[string]metricdata.Aggregation)
+		for _, sm := range rm.ScopeMetrics {
+			for _, m := range sm.Metrics {
+				out[m.Name] = m.Data
+			}
+		}
+		return out
+	}
+
+	metrics := collect()
+	webhooks, ok := metrics["otto.server.webhooks_total"].(metricdata.Sum[int64])
+	if !ok || len(webhooks.DataPoints) != 1 {
+		t.Fatalf("unexpected webhooks metric: %#v", metrics["otto.server.webhooks_total"])
+	}
+	if v, ok := webhooks.DataPoints[0].Attributes.Value(semconv.ServiceInstanceIDKey); !ok || v.AsString() != "otto-1" {
+		t.Errorf("webhooks metric missing instance id, attributes %v", webhooks.DataPoints[0].Attributes.ToSlice())
+	}
+
+	leader := func() int64 {
+		gauge, ok := collect()["otto.instance.leader"].(metricdata.Gauge[int64])
+		if !ok || len(gauge.DataPoints) != 1 {
+			t.Fatalf("unexpected leader gauge")
+		}
+		return gauge.DataPoints[0].Value
+	}
+	if got := leader(); got != 1 {
+		t.Errorf("leader = %d, want 1 by default", got)
+	}
+	telemetry.SetLeader(false)
+	if got := leader(); got != 0 {
+		t.Errorf("leader = %d after SetLeader(false), want 0", got)
+	}
+}
+
+func TestWebhookMetrics(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	telemetry := TestTelemetry(t, reader)
+
+	telemetry.IncServerWebhook(t.Context(), "issues", "opened", "org/a")
+	telemetry.IncServerWebhook(t.Context(), "issues", "opened", "org/b")
+	telemetry.IncServerWebhook(t.Context(), "issues", "closed", "org/a")
+	telemetry.IncServerWebhook(t.Context(), "push", "", "org/c")
+	telemetry.webhookRepos.see("org/old", time.Now().Add(-2*time.Hour))
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(t.Context(), &rm); err != nil {
+		t.Fatalf("failed to collect metrics: %v", err)
+	}
+	counts := make(map[string]int64)
+	var repos int64 = -1
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			switch data := m.Data.(type) {
+			case metricdata.Sum[int64]:
+				if m.Name != "otto.server.webhooks_total" {
+					continue
+				}
+				for _, dp := range data.DataPoints {
+					eventType, _ := dp.Attributes.Value("event_type")
+					action, _ := dp.Attributes.Value("action")
+					counts[eventType.AsString()+"/"+action.AsString()] = dp.Value
+				}
+			case metricdata.Gauge[int64]:
+				if m.Name == "otto.server.webhook_repos" {
+					repos = data.DataPoints[0].Value
+				}
+			}
+		}
+	}
+	want := map[string]int64{"issues/opened": 2, "issues/closed": 1, "push/": 1}
+	if !maps.Equal(counts, want) {
+		t.Errorf("webhooks by type and action = %v, want %v", counts, want)
+	}
+	if repos != 3 {
+		t.Errorf("repos in the last hour = %d, want 3", repos)
+	}
+}
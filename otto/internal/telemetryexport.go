@@ -0,0 +1,114 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// telemetryexport.go creates the exporter of each telemetry signal that the telemetry
+// config selects: OTLP over HTTP or gRPC, stdout for local development, or none.
+
+package internal
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdoutlog"
+	"go.opentelemetry.io/otel/exporters/stdout/stdoutmetric"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+)
+
+// newSpanExporter returns the trace exporter named by exporter, or nil for none. The
+// OTLP/HTTP exporter uses the proxy and TLS settings of transport if it is not nil.
+func newSpanExporter(ctx context.Context, exporter string, transport *http.Transport) (sdktrace.SpanExporter, error) {
+	var (
+		exp sdktrace.SpanExporter
+		err error
+	)
+	switch exporter {
+	case config.ExporterNone:
+		return nil, nil
+	case config.ExporterStdout:
+		exp, err = stdouttrace.New()
+	case config.ExporterOTLPGRPC:
+		exp, err = otlptracegrpc.New(ctx)
+	default:
+		var opts []otlptracehttp.Option
+		if transport != nil {
+			opts = append(opts,
+				otlptracehttp.WithProxy(otlptracehttp.HTTPTransportProxyFunc(transport.Proxy)),
+				otlptracehttp.WithTLSClientConfig(transport.TLSClientConfig))
+		}
+		exp, err = otlptracehttp.New(ctx, opts...)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s trace exporter: %w", exporter, err)
+	}
+	return exp, nil
+}
+
+// newMetricExporter returns the metric exporter named by exporter, or nil for none. The
+// OTLP/HTTP exporter uses the proxy and TLS settings of transport if it is not nil.
+func newMetricExporter(ctx context.Context, exporter string, transport *http.Transport) (sdkmetric.Exporter, error) {
+	var (
+		exp sdkmetric.Exporter
+		err error
+	)
+	switch exporter {
+	case config.ExporterNone:
+		return nil, nil
+	case config.ExporterStdout:
+		exp, err = stdoutmetric.New()
+	case config.ExporterOTLPGRPC:
+		exp, err = otlpmetricgrpc.New(ctx)
+	default:
+		var opts []otlpmetrichttp.Option
+		if transport != nil {
+			opts = append(opts,
+				otlpmetrichttp.WithProxy(otlpmetrichttp.HTTPTransportProxyFunc(transport.Proxy)),
+				otlpmetrichttp.WithTLSClientConfig(transport.TLSClientConfig))
+		}
+		exp, err = otlpmetrichttp.New(ctx, opts...)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s metric exporter: %w", exporter, err)
+	}
+	return exp, nil
+}
+
+// newLogExporter returns the log exporter named by exporter, or nil for none. The
+// OTLP/HTTP exporter uses the proxy and TLS settings of transport if it is not nil.
+func newLogExporter(ctx context.Context, exporter string, transport *http.Transport) (sdklog.Exporter, error) {
+	var (
+		exp sdklog.Exporter
+		err error
+	)
+	switch exporter {
+	case config.ExporterNone:
+		return nil, nil
+	case config.ExporterStdout:
+		exp, err = stdoutlog.New()
+	case config.ExporterOTLPGRPC:
+		exp, err = otlploggrpc.New(ctx)
+	default:
+		var opts []otlploghttp.Option
+		if transport != nil {
+			opts = append(opts,
+				otlploghttp.WithProxy(otlploghttp.HTTPTransportProxyFunc(transport.Proxy)),
+				otlploghttp.WithTLSClientConfig(transport.TLSClientConfig))
+		}
+		exp, err = otlploghttp.New(ctx, opts...)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s log exporter: %w", exporter, err)
+	}
+	return exp, nil
+}
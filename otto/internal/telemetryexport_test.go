@@ -0,0 +1,68 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+)
+
+func TestNewExporters(t *testing.T) {
+	for _, exporter := range []string{config.ExporterOTLPHTTP, config.ExporterOTLPGRPC, config.ExporterStdout,
+		config.ExporterNone} {
+		spans, err := newSpanExporter(t.Context(), exporter, nil)
+		if err != nil {
+			t.Fatalf("%s trace exporter: %v", exporter, err)
+		}
+		metrics, err := newMetricExporter(t.Context(), exporter, nil)
+		if err != nil {
+			t.Fatalf("%s metric exporter: %v", exporter, err)
+		}
+		logs, err := newLogExporter(t.Context(), exporter, nil)
+		if err != nil {
+			t.Fatalf("%s log exporter: %v", exporter, err)
+		}
+		if none := exporter == config.ExporterNone; (spans == nil) != none || (metrics == nil) != none ||
+			(logs == nil) != none {
+			t.Errorf("%s exporters = %T, %T, %T", exporter, spans, metrics, logs)
+			continue
+		}
+		if spans != nil {
+			_ = spans.Shutdown(t.Context())
+			_ = metrics.Shutdown(t.Context())
+			_ = logs.Shutdown(t.Context())
+		}
+	}
+}
+
+func TestNewTelemetryManagerWithoutExporters(t *testing.T) {
+	defaultLogger := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(defaultLogger) })
+	ratio := 0.0
+	telemetry, err := NewTelemetryManager(t.Context(), "otto-1", config.TelemetryConfig{
+		Traces:      config.ExporterNone,
+		Metrics:     config.ExporterNone,
+		Logs:        config.ExporterNone,
+		SampleRatio: &ratio,
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewTelemetryManager failed: %v", err)
+	}
+	defer func() {
+		if err := telemetry.Shutdown(t.Context()); err != nil {
+			t.Errorf("Shutdown failed: %v", err)
+		}
+	}()
+	if telemetry.LoggerProvider != nil {
+		t.Error("logs are exported without a log exporter")
+	}
+	_, span := telemetry.Tracer().Start(t.Context(), "test")
+	defer span.End()
+	if span.SpanContext().IsSampled() {
+		t.Error("span sampled with a sample ratio of 0")
+	}
+	// Metrics are still recorded, just not exported.
+	telemetry.IncServerWebhook(t.Context(), "issues", "opened", "org/repo")
+}
@@ -5,12 +5,21 @@ package internal
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
 	"strings"
 	"testing"
 
+	"github.com/google/go-github/v71/github"
+
 	// Import sqlite driver for database/sql.
 	_ "github.com/mattn/go-sqlite3"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 )
 
 // TestApp creates a test application with mock dependencies.
@@ -71,12 +80,15 @@ github_private_key_path: ""
 	return app
 }
 
-// TestDB creates an in-memory SQLite database for testing.
+// TestDB creates an in-memory SQLite database for testing. Every connection to ":memory:"
+// opens a database of its own, so the pool is capped at one connection for all queries,
+// concurrent ones included, to see the same tables.
 func TestDB(t *testing.T) *sql.DB {
 	db, err := sql.Open("sqlite3", ":memory:")
 	if err != nil {
 		t.Fatalf("Failed to open test database: %v", err)
 	}
+	db.SetMaxOpenConns(1)
 
 	// Ensure the connection works
 	if err := db.Ping(); err != nil {
@@ -87,6 +99,74 @@ func TestDB(t *testing.T) *sql.DB {
 	return db
 }
 
+// TestTelemetry creates a telemetry manager with in-process providers and no exporters.
+// Pass a reader to inspect recorded metrics, or nil to discard them; its MetricsHandler
+// serves them either way.
+func TestTelemetry(t *testing.T, reader sdkmetric.Reader) *TelemetryManager {
+	scraper, metricsHandler, err := newMetricsScraper()
+	if err != nil {
+		t.Fatalf("Failed to create test metrics scraper: %v", err)
+	}
+	opts := []sdkmetric.Option{sdkmetric.WithReader(scraper)}
+	if reader != nil {
+		opts = append(opts, sdkmetric.WithReader(reader))
+	}
+	telemetry := &TelemetryManager{
+		TracerProvider: sdktrace.NewTracerProvider(),
+		MeterProvider:  sdkmetric.NewMeterProvider(opts...),
+		Logger:         slog.Default(),
+		MetricsHandler: metricsHandler,
+	}
+	if err := telemetry.InitMetrics(); err != nil {
+		t.Fatalf("Failed to initialize test metrics: %v", err)
+	}
+	return telemetry
+}
+
+// TestGitHubClient returns a GitHub client whose API requests are served by handler.
+func TestGitHubClient(t *testing.T, handler http.Handler) *github.Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client := github.NewClient(nil)
+	baseURL, err := url.Parse(server.URL + "/")
+	if err != nil {
+		t.Fatalf("Failed to parse test server URL: %v", err)
+	}
+	client.BaseURL = baseURL
+	client.UploadURL = baseURL
+	return client
+}
+
+// TestCommands hands the slash commands in a comment event to a module's HandleCommand as
+// the app does, one at a time in the order of the comment, and returns their errors.
+func TestCommands(t *testing.T, h ModuleCommandHandler, event any) error {
+	t.Helper()
+	handlers := make(map[string]string)
+	for _, command := range h.SlashCommands() {
+		handlers[command] = command
+	}
+	var errs []error
+	for _, cmd := range (*App)(nil).commandContexts(event) {
+		if _, ok := commandModule(handlers, cmd.Command, cmd.Args); ok {
+			cmd.Context = t.Context()
+			errs = append(errs, h.HandleCommand(cmd))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// TestObserveCommands hands the slash commands in a comment event to a module's
+// ObserveCommands as the app does, all at once in the order of the comment.
+func TestObserveCommands(t *testing.T, o ModuleCommandObserver, event any) error {
+	t.Helper()
+	cmds := (*App)(nil).commandContexts(event)
+	if len(cmds) == 0 {
+		return nil
+	}
+	return o.ObserveCommands(cmds)
+}
+
 // TestRepository creates a repository with an in-memory database for testing.
 func TestRepository(t *testing.T) Repository {
 	db := TestDB(t)
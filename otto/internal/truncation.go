@@ -0,0 +1,95 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// truncation.go detects webhook payloads that GitHub cut short. A push payload lists at
+// most 2048 commits, and the Events API at most 20 along with the push's real size.
+// Truncated payloads are counted and marked on their spans so data-quality issues are
+// visible, and modules that need the complete data fetch it from the API.
+
+package internal
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/go-github/v71/github"
+)
+
+// maxPushCommits is the most commits GitHub lists in a push webhook payload.
+const maxPushCommits = 2048
+
+// TruncatedFields returns the paths of the fields GitHub truncated in an event's payload,
+// e.g. "commits" for a push with more commits than the payload lists.
+func TruncatedFields(event any) []string {
+	switch e := event.(type) {
+	case *github.PushEvent:
+		if pushTruncated(e) {
+			return []string{"commits"}
+		}
+	}
+	return nil
+}
+
+// pushTruncated reports whether a push payload lists fewer commits than the push has.
+func pushTruncated(e *github.PushEvent) bool {
+	return len(e.Commits) >= maxPushCommits || e.GetSize() > len(e.Commits)
+}
+
+// reportTruncation counts the fields GitHub truncated in a webhook payload and logs them,
+// returning their paths.
+func (a *App) reportTruncation(ctx context.Context, eventType string, event any) []string {
+	fields := TruncatedFields(event)
+	if a.Telemetry != nil {
+		for _, field := range fields {
+			a.Telemetry.IncWebhookTruncated(ctx, eventType, field)
+		}
+	}
+	if len(fields) > 0 {
+		slog.WarnContext(ctx, "Webhook payload was truncated by GitHub", "type", eventType, "fields", fields)
+	}
+	return fields
+}
+
+// PushCommits returns the commits of a push. When GitHub truncated the payload, they are
+// fetched by comparing the commits before and after the push; those carry no file lists.
+// Pushes creating or deleting a branch have nothing to compare, so their payload commits
+// are returned as they are.
+func PushCommits(ctx context.Context, client *github.Client, e *github.PushEvent) ([]*github.HeadCommit, error) {
+	if !pushTruncated(e) || e.GetCreated() || e.GetDeleted() || client == nil {
+		return e.Commits, nil
+	}
+	owner, repo, err := SplitRepo(e.GetRepo().GetFullName())
+	if err != nil {
+		return nil, err
+	}
+	var commits []*github.HeadCommit
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		comparison, resp, err := client.Repositories.CompareCommits(ctx, owner, repo, e.GetBefore(), e.GetAfter(),
+			opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compare %s...%s: %w", e.GetBefore(), e.GetAfter(), err)
+		}
+		for _, c := range comparison.Commits {
+			commit := c.GetCommit()
+			head := &github.HeadCommit{
+				ID:        c.SHA,
+				Message:   commit.Message,
+				URL:       c.HTMLURL,
+				Author:    commit.Author,
+				Committer: commit.Committer,
+			}
+			if commit.GetTree() != nil {
+				head.TreeID = commit.Tree.SHA
+			}
+			if commit.GetAuthor() != nil {
+				head.Timestamp = commit.Author.Date
+			}
+			commits = append(commits, head)
+		}
+		if resp.NextPage == 0 {
+			return commits, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
@@ -0,0 +1,114 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/google/go-github/v71/github"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func pushEvent(commits, size int) *github.PushEvent {
+	e := &github.PushEvent{
+		Repo:   &github.PushEventRepository{FullName: github.Ptr("org/repo")},
+		Before: github.Ptr("aaa"),
+		After:  github.Ptr("bbb"),
+	}
+	if size > 0 {
+		e.Size = github.Ptr(size)
+	}
+	for i := range commits {
+		e.Commits = append(e.Commits, &github.HeadCommit{ID: github.Ptr(fmt.Sprintf("c%d", i))})
+	}
+	return e
+}
+
+func TestTruncatedFields(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		event any
+		want  []string
+	}{
+		{"small push", pushEvent(3, 0), nil},
+		{"webhook limit", pushEvent(maxPushCommits, 0), []string{"commits"}},
+		{"events API size", pushEvent(20, 45), []string{"commits"}},
+		{"complete events API push", pushEvent(2, 2), nil},
+		{"other event", &github.IssuesEvent{}, nil},
+	} {
+		if got := TruncatedFields(tc.event); !slices.Equal(got, tc.want) {
+			t.Errorf("%s: TruncatedFields = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+
+	reader := sdkmetric.NewManualReader()
+	app := &App{Telemetry: TestTelemetry(t, reader)}
+	app.reportTruncation(t.Context(), "push", pushEvent(20, 45))
+	app.reportTruncation(t.Context(), "push", pushEvent(1, 0))
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(t.Context(), &rm); err != nil {
+		t.Fatalf("failed to collect metrics: %v", err)
+	}
+	var truncated int64
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if sum, ok := m.Data.(metricdata.Sum[int64]); ok && m.Name == "otto.webhook.truncated_total" {
+				for _, dp := range sum.DataPoints {
+					truncated += dp.Value
+				}
+			}
+		}
+	}
+	if truncated != 1 {
+		t.Errorf("truncated payloads counted %d times, want 1", truncated)
+	}
+}
+
+func TestPushCommits(t *testing.T) {
+	var requests []string
+	client := TestGitHubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path+"?"+r.URL.RawQuery)
+		if r.URL.Query().Get("page") == "" {
+			w.Header().Set("Link", `<`+r.URL.Path+`?page=2>; rel="next"`)
+			_, _ = w.Write([]byte(`{"commits": [{"sha": "c1", "commit": {"message": "one",
+				"author": {"name": "A"}}}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"commits": [{"sha": "c2", "commit": {"message": "two"}}]}`))
+	}))
+
+	// Complete payloads need no API calls.
+	commits, err := PushCommits(t.Context(), client, pushEvent(2, 0))
+	if err != nil || len(commits) != 2 || len(requests) != 0 {
+		t.Fatalf("PushCommits = %d commits, %v; requests %q", len(commits), err, requests)
+	}
+
+	commits, err = PushCommits(t.Context(), client, pushEvent(20, 45))
+	if err != nil {
+		t.Fatalf("PushCommits failed: %v", err)
+	}
+	var got []string
+	for _, c := range commits {
+		got = append(got, c.GetID()+" "+c.GetMessage())
+	}
+	if want := []string{"c1 one", "c2 two"}; !slices.Equal(got, want) {
+		t.Errorf("commits = %q, want %q", got, want)
+	}
+	if commits[0].GetAuthor().GetName() != "A" {
+		t.Errorf("author = %+v, want A", commits[0].GetAuthor())
+	}
+	if len(requests) != 2 || requests[0] != "/repos/org/repo/compare/aaa...bbb?per_page=100" {
+		t.Errorf("requests = %q", requests)
+	}
+
+	// New branches have nothing to compare with.
+	created := pushEvent(20, 45)
+	created.Created = github.Ptr(true)
+	if commits, err := PushCommits(t.Context(), client, created); err != nil || len(commits) != 20 {
+		t.Errorf("PushCommits of a new branch = %d commits, %v", len(commits), err)
+	}
+}
@@ -0,0 +1,60 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// version.go identifies the running build and compares release versions.
+
+package internal
+
+import (
+	"runtime/debug"
+	"strconv"
+	"strings"
+)
+
+// Version is the release this binary was built from, set at build time with
+//
+//	-ldflags "-X github.com/open-telemetry/sig-project-infra/otto/internal.Version=v0.4.0"
+//
+// Builds without it report the module version from the build info, or "dev".
+var Version = "dev"
+
+// BuildVersion returns the version of the running binary.
+func BuildVersion() string {
+	if Version != "dev" {
+		return Version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	return Version
+}
+
+// parseVersion parses a "vMAJOR.MINOR.PATCH" release version. Pre-release and build
+// suffixes are not releases and do not parse.
+func parseVersion(v string) ([3]int, bool) {
+	var parsed [3]int
+	parts := strings.Split(strings.TrimPrefix(v, "v"), ".")
+	if len(parts) != 3 {
+		return parsed, false
+	}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return parsed, false
+		}
+		parsed[i] = n
+	}
+	return parsed, true
+}
+
+// compareVersions returns -1, 0 or 1 as a is older than, equal to or newer than b.
+func compareVersions(a, b [3]int) int {
+	for i := range a {
+		switch {
+		case a[i] < b[i]:
+			return -1
+		case a[i] > b[i]:
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,246 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// watchdog.go tracks module handlers while they run, warns about handlers that overrun
+// their deadline and logs the goroutine stacks of handlers that look stuck. Handlers run
+// with pprof labels, so their goroutines can be found in profiles and dumps.
+
+package internal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"runtime/pprof"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+)
+
+// InflightHandler is a module handler that is running.
+type InflightHandler struct {
+	ID        uint64        `json:"id"`
+	Module    string        `json:"module"`
+	EventType string        `json:"event_type"`
+	Delivery  string        `json:"delivery,omitempty"`
+	Repo      string        `json:"repo,omitempty"`
+	Started   time.Time     `json:"started"`
+	Running   time.Duration `json:"running_ns"`
+	Deadline  time.Duration `json:"deadline_ns"`
+	Overdue   bool          `json:"overdue"`
+	Stacks    string        `json:"stacks,omitempty"`
+
+	warned, dumped bool
+}
+
+// Watchdog tracks running module handlers. A handler that runs longer than its module's
+// deadline is logged once; one that runs DumpFactor times longer is logged with the stacks
+// of its goroutines, once.
+type Watchdog struct {
+	cfg       config.WatchdogConfig
+	telemetry *TelemetryManager
+	now       func() time.Time
+	stacks    func() string // goroutine dump with labels
+
+	mu       sync.Mutex
+	nextID   uint64
+	inflight map[uint64]*InflightHandler
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+// NewWatchdog creates a watchdog for the watchdog config. Call Start to check handlers
+// periodically.
+func NewWatchdog(cfg config.WatchdogConfig, telemetry *TelemetryManager) *Watchdog {
+	return &Watchdog{
+		cfg:       cfg,
+		telemetry: telemetry,
+		now:       time.Now,
+		stacks:    goroutineDump,
+		inflight:  make(map[uint64]*InflightHandler),
+	}
+}
+
+// goroutineDump returns the stacks of all goroutines, grouped, with their pprof labels.
+func goroutineDump() string {
+	var buf bytes.Buffer
+	if err := pprof.Lookup("goroutine").WriteTo(&buf, 1); err != nil {
+		return ""
+	}
+	return buf.String()
+}
+
+// deadline returns how long a module's handlers are expected to run at most.
+func (w *Watchdog) deadline(module string) time.Duration {
+	if d, ok := w.cfg.Deadlines[module]; ok && d > 0 {
+		return d
+	}
+	return w.cfg.Deadline
+}
+
+// Run runs a module's handler for an event, tracked and with the pprof labels module,
+// event, delivery and handler. A nil watchdog just runs it.
+func (w *Watchdog) Run(module, eventType, delivery, repo string, handler func()) {
+	if w == nil {
+		handler()
+		return
+	}
+	w.mu.Lock()
+	w.nextID++
+	h := &InflightHandler{
+		ID:        w.nextID,
+		Module:    module,
+		EventType: eventType,
+		Delivery:  delivery,
+		Repo:      repo,
+		Started:   w.now(),
+		Deadline:  w.deadline(module),
+	}
+	w.inflight[h.ID] = h
+	w.mu.Unlock()
+	defer func() {
+		w.mu.Lock()
+		delete(w.inflight, h.ID)
+		w.mu.Unlock()
+	}()
+
+	labels := pprof.Labels("module", module, "event", eventType, "delivery", delivery,
+		"handler", strconv.FormatUint(h.ID, 10))
+	pprof.Do(context.Background(), labels, func(context.Context) { handler() })
+}
+
+// Start checks the running handlers every interval until Stop.
+func (w *Watchdog) Start(ctx context.Context) {
+	if w == nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	w.mu.Lock()
+	w.cancel = cancel
+	w.mu.Unlock()
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		ticker := time.NewTicker(w.cfg.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				w.Check(ctx)
+			}
+		}
+	}()
+}
+
+// Stop stops the periodic checks.
+func (w *Watchdog) Stop() {
+	if w == nil {
+		return
+	}
+	w.mu.Lock()
+	cancel := w.cancel
+	w.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+	w.wg.Wait()
+}
+
+// Check logs the handlers that have overrun their deadline since the last check, and the
+// stacks of those that have overrun it DumpFactor times.
+func (w *Watchdog) Check(ctx context.Context) {
+	now := w.now()
+	var warn, dump []InflightHandler
+	w.mu.Lock()
+	for _, h := range w.inflight {
+		running := now.Sub(h.Started)
+		if h.Deadline <= 0 || running <= h.Deadline {
+			continue
+		}
+		if !h.warned {
+			h.warned = true
+			warn = append(warn, *h)
+		}
+		if !h.dumped && w.cfg.DumpFactor > 0 && running > time.Duration(w.cfg.DumpFactor)*h.Deadline {
+			h.dumped = true
+			dump = append(dump, *h)
+		}
+	}
+	w.mu.Unlock()
+
+	for _, h := range warn {
+		slog.WarnContext(ctx, "Module handler exceeded its deadline", "module", h.Module, "event", h.EventType,
+			"delivery", h.Delivery, "repo", h.Repo, "running", now.Sub(h.Started), "deadline", h.Deadline)
+		if w.telemetry != nil {
+			w.telemetry.IncModuleOverdueHandler(ctx, h.Module)
+		}
+	}
+	if len(dump) == 0 {
+		return
+	}
+	all := w.stacks()
+	for _, h := range dump {
+		slog.ErrorContext(ctx, "Module handler appears stuck", "module", h.Module, "event", h.EventType,
+			"delivery", h.Delivery, "repo", h.Repo, "running", now.Sub(h.Started), "deadline", h.Deadline,
+			"stacks", handlerStacks(all, h.ID))
+	}
+}
+
+// Inflight returns the running handlers, longest-running first.
+func (w *Watchdog) Inflight() []InflightHandler {
+	now := w.now()
+	w.mu.Lock()
+	handlers := make([]InflightHandler, 0, len(w.inflight))
+	for _, h := range w.inflight {
+		c := *h
+		c.Running = now.Sub(c.Started)
+		c.Overdue = c.Deadline > 0 && c.Running > c.Deadline
+		handlers = append(handlers, c)
+	}
+	w.mu.Unlock()
+	slices.SortFunc(handlers, func(a, b InflightHandler) int { return a.Started.Compare(b.Started) })
+	return handlers
+}
+
+// handlerStacks returns the goroutine groups of a dump that carry the handler's label.
+func handlerStacks(dump string, id uint64) string {
+	label := `"handler":"` + strconv.FormatUint(id, 10) + `"`
+	var groups []string
+	for group := range strings.SplitSeq(dump, "\n\n") {
+		if strings.Contains(group, label) {
+			groups = append(groups, strings.TrimSpace(group))
+		}
+	}
+	return strings.Join(groups, "\n\n")
+}
+
+// RegisterAdminRoutes exposes the running handlers on the admin API.
+func (w *Watchdog) RegisterAdminRoutes(srv *Server) {
+	if w == nil || srv == nil {
+		return
+	}
+	srv.HandleAdmin("GET /debug/handlers", w.handleList)
+}
+
+// handleList serves the running handlers as JSON, with their goroutine stacks if the
+// stacks query parameter is set.
+func (w *Watchdog) handleList(rw http.ResponseWriter, r *http.Request) {
+	handlers := w.Inflight()
+	if r.URL.Query().Get("stacks") != "" && len(handlers) > 0 {
+		all := w.stacks()
+		for i := range handlers {
+			handlers[i].Stacks = handlerStacks(all, handlers[i].ID)
+		}
+	}
+	rw.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(rw).Encode(handlers); err != nil {
+		slog.Error("Failed to write in-flight handlers", "error", err)
+	}
+}
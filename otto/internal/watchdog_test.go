@@ -0,0 +1,136 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+)
+
+// runBlocked starts a handler on w that runs until the returned function is called.
+func runBlocked(t *testing.T, w *Watchdog, module, delivery string) func() {
+	t.Helper()
+	started, release := make(chan struct{}), make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		w.Run(module, "issue_comment", delivery, "org/repo", func() {
+			close(started)
+			<-release
+		})
+	}()
+	<-started
+	var once sync.Once
+	stop := func() {
+		once.Do(func() { close(release) })
+		wg.Wait()
+	}
+	t.Cleanup(stop)
+	return stop
+}
+
+func TestWatchdogCheck(t *testing.T) {
+	var logs bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+	t.Cleanup(func() { slog.SetDefault(prev) })
+
+	now := time.Now()
+	w := NewWatchdog(config.WatchdogConfig{
+		Deadline:   time.Minute,
+		Deadlines:  map[string]time.Duration{"coverage": 10 * time.Minute},
+		DumpFactor: 5,
+	}, nil)
+	w.now = func() time.Time { return now }
+	stop := runBlocked(t, w, "approvals", "delivery-1")
+	runBlocked(t, w, "coverage", "delivery-1")
+
+	now = now.Add(2 * time.Minute)
+	w.Check(t.Context())
+	if got := strings.Count(logs.String(), "exceeded its deadline"); got != 1 ||
+		!strings.Contains(logs.String(), "module=approvals") {
+		t.Fatalf("expected one deadline warning for approvals, got:\n%s", logs.String())
+	}
+	w.Check(t.Context())
+	if got := strings.Count(logs.String(), "exceeded its deadline"); got != 1 {
+		t.Errorf("deadline warning logged %d times, want once", got)
+	}
+
+	// Past five deadlines, the handler's own goroutine stacks are logged, once.
+	now = now.Add(4 * time.Minute)
+	w.Check(t.Context())
+	w.Check(t.Context())
+	out := logs.String()
+	if got := strings.Count(out, "appears stuck"); got != 1 {
+		t.Fatalf("stuck handler logged %d times, want once:\n%s", got, out)
+	}
+	if !strings.Contains(out, "runBlocked") || !strings.Contains(out, `\"module\":\"approvals\"`) ||
+		strings.Contains(out, `\"module\":\"coverage\"`) {
+		t.Errorf("expected the approvals handler's stacks only, got:\n%s", out)
+	}
+
+	stop()
+	if handlers := w.Inflight(); len(handlers) != 1 || handlers[0].Module != "coverage" || handlers[0].Overdue {
+		t.Errorf("Inflight() after approvals finished = %+v", handlers)
+	}
+}
+
+func TestWatchdogHandlers(t *testing.T) {
+	now := time.Now()
+	w := NewWatchdog(config.WatchdogConfig{Deadline: time.Minute}, nil)
+	w.now = func() time.Time { return now }
+	runBlocked(t, w, "approvals", "delivery-1")
+	now = now.Add(90 * time.Second)
+	runBlocked(t, w, "holds", "delivery-2")
+
+	srv := &Server{mux: http.NewServeMux(), adminToken: []byte("token")}
+	w.RegisterAdminRoutes(srv)
+	for _, query := range []string{"", "?stacks=1"} {
+		req := httptest.NewRequest(http.MethodGet, "/debug/handlers"+query, nil)
+		req.Header.Set("Authorization", "Bearer token")
+		rec := httptest.NewRecorder()
+		srv.mux.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET /debug/handlers%s status = %d", query, rec.Code)
+		}
+		var handlers []InflightHandler
+		if err := json.Unmarshal(rec.Body.Bytes(), &handlers); err != nil {
+			t.Fatalf("invalid response: %v", err)
+		}
+		if len(handlers) != 2 || handlers[0].Module != "approvals" || !handlers[0].Overdue ||
+			handlers[0].Running != 90*time.Second || handlers[1].Delivery != "delivery-2" || handlers[1].Overdue {
+			t.Errorf("unexpected handlers: %+v", handlers)
+		}
+		if withStacks := handlers[0].Stacks != ""; withStacks != (query != "") {
+			t.Errorf("stacks included = %v for query %q", withStacks, query)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/debug/handlers", nil)
+	rec := httptest.NewRecorder()
+	srv.mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("GET /debug/handlers without token status = %d, want 401", rec.Code)
+	}
+}
+
+func TestWatchdogNil(t *testing.T) {
+	var w *Watchdog
+	ran := false
+	w.Run("approvals", "issues", "", "", func() { ran = true })
+	if !ran {
+		t.Error("nil watchdog did not run the handler")
+	}
+	w.Start(t.Context())
+	w.Stop()
+}
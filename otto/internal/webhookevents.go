@@ -0,0 +1,77 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// webhookevents.go parses webhook payloads, including events the vendored go-github
+// version does not know yet.
+
+package internal
+
+import (
+	"encoding/json"
+
+	"github.com/google/go-github/v71/github"
+)
+
+// RepositoryAdvisoryEvent is triggered when a repository security advisory is published or
+// privately reported. go-github v71 has no type for it; its advisory has the shape of a
+// global security advisory.
+type RepositoryAdvisoryEvent struct {
+	Action             *string                  `json:"action,omitempty"`
+	RepositoryAdvisory *github.SecurityAdvisory `json:"repository_advisory,omitempty"`
+	Enterprise         *github.Enterprise       `json:"enterprise,omitempty"`
+	Installation       *github.Installation     `json:"installation,omitempty"`
+	Organization       *github.Organization     `json:"organization,omitempty"`
+	Repo               *github.Repository       `json:"repository,omitempty"`
+	Sender             *github.User             `json:"sender,omitempty"`
+}
+
+// GetAction returns the Action field if it's non-nil, zero value otherwise.
+func (e *RepositoryAdvisoryEvent) GetAction() string {
+	if e == nil || e.Action == nil {
+		return ""
+	}
+	return *e.Action
+}
+
+// GetRepositoryAdvisory returns the RepositoryAdvisory field.
+func (e *RepositoryAdvisoryEvent) GetRepositoryAdvisory() *github.SecurityAdvisory {
+	if e == nil {
+		return nil
+	}
+	return e.RepositoryAdvisory
+}
+
+// GetRepo returns the Repo field.
+func (e *RepositoryAdvisoryEvent) GetRepo() *github.Repository {
+	if e == nil {
+		return nil
+	}
+	return e.Repo
+}
+
+// GetSender returns the Sender field.
+func (e *RepositoryAdvisoryEvent) GetSender() *github.User {
+	if e == nil {
+		return nil
+	}
+	return e.Sender
+}
+
+// EventForType returns an empty payload struct for a webhook event type, or nil if the
+// type is unknown. It knows the events go-github parses and those defined here.
+func EventForType(eventType string) any {
+	switch eventType {
+	case "repository_advisory":
+		return &RepositoryAdvisoryEvent{}
+	}
+	return github.EventForType(eventType)
+}
+
+// ParseWebHook parses a webhook payload into the struct for its event type, like
+// github.ParseWebHook, for the events EventForType knows.
+func ParseWebHook(eventType string, payload []byte) (any, error) {
+	event := EventForType(eventType)
+	if _, ok := event.(*RepositoryAdvisoryEvent); !ok {
+		return github.ParseWebHook(eventType, payload)
+	}
+	return event, json.Unmarshal(payload, event)
+}
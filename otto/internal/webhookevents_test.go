@@ -0,0 +1,42 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"testing"
+
+	"github.com/google/go-github/v71/github"
+)
+
+func TestParseWebHook(t *testing.T) {
+	event, err := ParseWebHook("repository_advisory", []byte(`{
+		"action": "reported",
+		"repository_advisory": {"ghsa_id": "GHSA-abcd-efgh-ijkl", "severity": "high", "state": "triage"},
+		"repository": {"full_name": "org/repo"}
+	}`))
+	if err != nil {
+		t.Fatalf("ParseWebHook failed: %v", err)
+	}
+	advisory, ok := event.(*RepositoryAdvisoryEvent)
+	if !ok {
+		t.Fatalf("event = %T, want *RepositoryAdvisoryEvent", event)
+	}
+	if advisory.GetAction() != "reported" || advisory.GetRepo().GetFullName() != "org/repo" ||
+		advisory.GetRepositoryAdvisory().GetGHSAID() != "GHSA-abcd-efgh-ijkl" {
+		t.Errorf("event = %+v", advisory)
+	}
+
+	event, err = ParseWebHook("issues", []byte(`{"action": "opened"}`))
+	if err != nil {
+		t.Fatalf("ParseWebHook failed: %v", err)
+	}
+	if _, ok := event.(*github.IssuesEvent); !ok {
+		t.Errorf("event = %T, want *github.IssuesEvent", event)
+	}
+	if _, err := ParseWebHook("no_such_event", []byte(`{}`)); err == nil {
+		t.Error("expected an error for an unknown event type")
+	}
+	if _, err := ParseWebHook("repository_advisory", []byte(`{`)); err == nil {
+		t.Error("expected an error for an invalid payload")
+	}
+}
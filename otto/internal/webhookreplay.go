@@ -0,0 +1,131 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// webhookreplay.go hands a stored webhook delivery to the modules again, so operators can
+// recover from a module bug once it is fixed without asking GitHub to redeliver. Unlike
+// `otto replay`, the delivery is handled for real, by the running app.
+
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+)
+
+var (
+	// ErrDeliveryNotFound is returned when no stored event has a delivery ID.
+	ErrDeliveryNotFound = errors.New("delivery not found")
+	// ErrUnknownModule is returned when a delivery is replayed to a module that is not registered.
+	ErrUnknownModule = errors.New("unknown module")
+)
+
+// WebhookReplay is the outcome of handing a stored delivery to the modules again.
+type WebhookReplay struct {
+	EventID    int64             `json:"event_id"`
+	DeliveryID string            `json:"delivery_id"`
+	Type       string            `json:"type"`
+	Repo       string            `json:"repo,omitempty"`
+	Modules    []string          `json:"modules"`          // modules the delivery was offered to
+	Failed     map[string]string `json:"failed,omitempty"` // module -> error
+}
+
+// Delivery returns the stored event of a webhook delivery, the latest one if GitHub
+// delivered it more than once, or ErrDeliveryNotFound.
+func (s *EventStore) Delivery(ctx context.Context, deliveryID string) (StoredEvent, error) {
+	events, err := s.query(ctx, true,
+		`SELECT `+eventColumns+` FROM events WHERE delivery_id = ? ORDER BY id DESC LIMIT 1`, deliveryID)
+	if err != nil {
+		return StoredEvent{}, err
+	}
+	if len(events) == 0 {
+		return StoredEvent{}, ErrDeliveryNotFound
+	}
+	return events[0], nil
+}
+
+// ReplayDelivery hands a stored delivery to modules, or to every registered module if
+// none are given, and waits until they are done. Modules handle it as they would a new
+// delivery, subject to their subscriptions, routes and flags, but its slash commands are
+// not run or recorded in the command history again.
+func (a *App) ReplayDelivery(ctx context.Context, deliveryID string, modules []string) (WebhookReplay, error) {
+	if a.Events == nil {
+		return WebhookReplay{}, errors.New("the event store is not available")
+	}
+	stored, err := a.Events.Delivery(ctx, deliveryID)
+	if err != nil {
+		return WebhookReplay{}, err
+	}
+	registered := a.ModuleRegistry.GetModules()
+	if len(modules) == 0 {
+		modules = slices.Sorted(maps.Keys(registered))
+	}
+	for _, name := range modules {
+		if _, ok := registered[name]; !ok {
+			return WebhookReplay{}, fmt.Errorf("%w %q", ErrUnknownModule, name)
+		}
+	}
+	event, err := ParseWebHook(stored.Type, stored.Payload)
+	if err != nil {
+		return WebhookReplay{}, fmt.Errorf("failed to parse stored %s event %d: %w", stored.Type, stored.ID, err)
+	}
+
+	slog.InfoContext(ctx, "Replaying webhook delivery", "delivery", deliveryID, "event", stored.Type,
+		"repo", stored.Repo, "modules", modules)
+	done := a.lifecycle.begin()
+	failed := a.handleEvent(deliveryID, stored.Type, a.Router.Route(event), stored.Payload, modules)
+	done()
+
+	replay := WebhookReplay{
+		EventID:    stored.ID,
+		DeliveryID: deliveryID,
+		Type:       stored.Type,
+		Repo:       stored.Repo,
+		Modules:    modules,
+	}
+	if len(failed) > 0 {
+		replay.Failed = make(map[string]string, len(failed))
+		for name, err := range failed {
+			replay.Failed[name] = err.Error()
+		}
+	}
+	return replay, nil
+}
+
+// handleReplay replays the delivery given in a JSON body like
+// {"delivery_id": "72d3162e-...", "modules": ["sla"]}; modules is optional.
+func (a *App) handleReplay(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		DeliveryID string   `json:"delivery_id"`
+		Modules    []string `json:"modules"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.DeliveryID == "" {
+		http.Error(w, `body must be JSON with "delivery_id" and optional "modules"`, http.StatusBadRequest)
+		return
+	}
+	if a.Draining() {
+		http.Error(w, "shutting down", http.StatusServiceUnavailable)
+		return
+	}
+	replay, err := a.ReplayDelivery(r.Context(), body.DeliveryID, body.Modules)
+	switch {
+	case errors.Is(err, ErrDeliveryNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case errors.Is(err, ErrUnknownModule):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		slog.Error("Failed to replay webhook delivery", "delivery", body.DeliveryID, "error", err)
+		http.Error(w, "failed to replay delivery: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(replay); err != nil {
+		slog.Error("Failed to write JSON response", "error", err)
+	}
+}
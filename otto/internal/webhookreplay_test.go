@@ -0,0 +1,71 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestReplayDelivery(t *testing.T) {
+	events, err := NewEventStore(TestDB(t))
+	if err != nil {
+		t.Fatalf("NewEventStore failed: %v", err)
+	}
+	app := &App{ModuleRegistry: NewModuleRegistry(), Logger: slog.Default(), Events: events}
+	steady := &flakyModule{name: "steady"}
+	flaky := &flakyModule{name: "flaky", failures: 1}
+	app.RegisterModule(steady)
+	app.RegisterModule(flaky)
+	payload := []byte(`{"action":"opened","issue":{"number":1},"repository":{"full_name":"org/repo"}}`)
+	if _, err := events.Record(t.Context(), NewStoredEvent("d1", "issues", payload)); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+
+	replay := func(body string) (int, WebhookReplay) {
+		t.Helper()
+		rr := httptest.NewRecorder()
+		app.handleReplay(rr, httptest.NewRequest(http.MethodPost, "/admin/replay", strings.NewReader(body)))
+		var result WebhookReplay
+		if rr.Code == http.StatusOK {
+			if err := json.Unmarshal(rr.Body.Bytes(), &result); err != nil {
+				t.Fatalf("failed to decode %q: %v", rr.Body.String(), err)
+			}
+		}
+		return rr.Code, result
+	}
+
+	// Every module handles the delivery again, and failures are reported.
+	status, result := replay(`{"delivery_id": "d1"}`)
+	if status != http.StatusOK || result.Type != "issues" || result.Repo != "org/repo" ||
+		!slices.Equal(result.Modules, []string{"flaky", "steady"}) || len(result.Failed) != 1 ||
+		result.Failed["flaky"] == "" {
+		t.Fatalf("replay = %d, %+v", status, result)
+	}
+	// Once fixed, only the failed module is given it again.
+	status, result = replay(`{"delivery_id": "d1", "modules": ["flaky"]}`)
+	if status != http.StatusOK || len(result.Failed) != 0 {
+		t.Fatalf("replay to flaky = %d, %+v", status, result)
+	}
+	if steady.handled != 1 || flaky.handled != 2 {
+		t.Errorf("handled steady %d and flaky %d times, want 1 and 2", steady.handled, flaky.handled)
+	}
+
+	for _, tc := range []struct {
+		body   string
+		status int
+	}{
+		{`{"delivery_id": "missing"}`, http.StatusNotFound},
+		{`{"delivery_id": "d1", "modules": ["nope"]}`, http.StatusBadRequest},
+		{`{}`, http.StatusBadRequest},
+	} {
+		if status, _ := replay(tc.body); status != tc.status {
+			t.Errorf("replay %s = %d, want %d", tc.body, status, tc.status)
+		}
+	}
+}
@@ -0,0 +1,357 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/google/go-github/v71/github"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+// AdvisoryConfig configures the intake of security advisories.
+type AdvisoryConfig struct {
+	Backend          string        `yaml:"backend" doc:"backend of the private channel: slack, email or webhook"`
+	TargetSecret     string        `yaml:"target_secret" doc:"secret holding the private channel, e.g. a Slack channel ID"`
+	EmbargoDays      int           `yaml:"embargo_days" doc:"embargo set on newly reported advisories; 0 sets none"`
+	RemindBeforeDays []int         `yaml:"remind_before_days" doc:"days before an embargo ends to remind maintainers"`
+	CheckInterval    time.Duration `yaml:"check_interval" doc:"how often embargoes are checked for reminders"`
+}
+
+// AdvisoryModule tracks privately reported repository security advisories until they are
+// published. Reports and embargo reminders go only to the maintainers' private channel,
+// whose destination is kept with the secrets rather than in the config; the module never
+// comments, labels or otherwise writes to GitHub, so nothing about an undisclosed
+// vulnerability becomes public through it.
+type AdvisoryModule struct {
+	app      *internal.App
+	database *internal.Database
+	config   AdvisoryConfig
+	target   string // the private channel, read from the secrets
+	now      func() time.Time
+}
+
+func (m *AdvisoryModule) Name() string { return "advisories" }
+
+// ConfigSchema implements the ModuleConfigSchema interface.
+func (m *AdvisoryModule) ConfigSchema() any {
+	c := defaultAdvisoryConfig()
+	return &c
+}
+
+// defaultAdvisoryConfig returns the advisories module's defaults. GitHub expects
+// vulnerabilities to be disclosed within 90 days of a report.
+func defaultAdvisoryConfig() AdvisoryConfig {
+	return AdvisoryConfig{
+		Backend:          "slack",
+		TargetSecret:     "security_channel",
+		EmbargoDays:      90,
+		RemindBeforeDays: []int{14, 7, 1},
+		CheckInterval:    time.Hour,
+	}
+}
+
+// EventSubscriptions implements the ModuleEventSubscriber interface.
+func (m *AdvisoryModule) EventSubscriptions() []internal.EventSubscription {
+	return []internal.EventSubscription{
+		internal.Subscribe("repository_advisory", "reported", "published"),
+		internal.Subscribe("security_advisory", "published", "withdrawn"),
+	}
+}
+
+// GitHubPermissions implements the ModulePermissionRequirer interface.
+func (m *AdvisoryModule) GitHubPermissions() map[string]string {
+	return map[string]string{"repository_advisories": "read"}
+}
+
+// Initialize implements the ModuleInitializer interface.
+func (m *AdvisoryModule) Initialize(ctx context.Context, app *internal.App) error {
+	m.app = app
+	m.database = app.Database
+	if m.now == nil {
+		m.now = time.Now
+	}
+	m.config = defaultAdvisoryConfig()
+	if err := loadModuleConfig(app, m.Name(), &m.config); err != nil {
+		return err
+	}
+	switch m.config.Backend {
+	case "slack", "email", "webhook":
+	case "github":
+		return errors.New("advisories: the github backend comments publicly; use slack, email or webhook")
+	default:
+		return fmt.Errorf("advisories: unknown backend %q", m.config.Backend)
+	}
+	if app.Secrets != nil && m.config.TargetSecret != "" {
+		m.target = app.Secrets.GetSecret(m.config.TargetSecret)
+	}
+	if m.target == "" {
+		slog.Warn("No private channel for security advisories; advisories are tracked without notifications",
+			"secret", m.config.TargetSecret)
+	}
+	if err := AutoMigrateAdvisories(m.database.DB()); err != nil {
+		return err
+	}
+	if app.Scheduler != nil && len(m.config.RemindBeforeDays) > 0 {
+		app.Scheduler.Register(internal.Job{
+			Name:       "advisory_embargo_reminders",
+			Module:     m.Name(),
+			Deferrable: true,
+			Interval:   m.config.CheckInterval,
+			Run:        m.RemindEmbargoes,
+		})
+	}
+	app.HandleAdmin("GET /admin/advisories", m.handleList)
+	app.HandleAdmin("PUT /admin/advisories/{ghsa}/embargo", m.handleSetEmbargo)
+	return nil
+}
+
+func (m *AdvisoryModule) HandleEvent(eventType string, event any, raw json.RawMessage) error {
+	ctx := context.Background()
+	switch eventType {
+	case "repository_advisory":
+		advisoryEvent, ok := event.(*internal.RepositoryAdvisoryEvent)
+		if !ok {
+			return nil
+		}
+		return m.handleRepositoryAdvisory(ctx, advisoryEvent)
+	case "security_advisory":
+		advisoryEvent, ok := event.(*github.SecurityAdvisoryEvent)
+		if !ok {
+			return nil
+		}
+		return m.handleSecurityAdvisory(ctx, advisoryEvent)
+	}
+	return nil
+}
+
+// handleRepositoryAdvisory starts tracking a reported advisory, with the default embargo,
+// and ends the embargo of a published one.
+func (m *AdvisoryModule) handleRepositoryAdvisory(ctx context.Context, event *internal.RepositoryAdvisoryEvent) error {
+	ga := event.GetRepositoryAdvisory()
+	if ga.GetGHSAID() == "" {
+		return nil
+	}
+	now := m.now()
+	a := Advisory{
+		GHSAID:     ga.GetGHSAID(),
+		Repo:       event.GetRepo().GetFullName(),
+		Severity:   ga.GetSeverity(),
+		Summary:    ga.GetSummary(),
+		State:      ga.GetState(),
+		URL:        ga.GetHTMLURL(),
+		ReportedAt: now,
+	}
+	if a.State == "" {
+		a.State = "triage"
+	}
+	published := event.GetAction() == "published"
+	if published {
+		a.State = "published"
+		at := ga.GetPublishedAt().Time
+		if at.IsZero() {
+			at = now
+		}
+		a.PublishedAt = &at
+	} else if m.config.EmbargoDays > 0 {
+		until := now.AddDate(0, 0, m.config.EmbargoDays)
+		a.EmbargoUntil = &until
+	}
+	db := m.database.DB()
+	existing, err := GetAdvisory(db, a.GHSAID)
+	if err != nil {
+		return m.wrapDB(err, "get_advisory", a.Repo, a.GHSAID)
+	}
+	added, err := TrackAdvisory(db, a)
+	if err != nil {
+		return m.wrapDB(err, "track_advisory", a.Repo, a.GHSAID)
+	}
+
+	switch {
+	case published && (existing == nil || existing.PublishedAt == nil):
+		m.notify(ctx, a, internal.SeverityInfo, fmt.Sprintf("Security advisory %s in %s was published",
+			a.GHSAID, a.Repo), "The embargo has ended; the advisory is public.")
+	case added:
+		body := "Severity: " + orUnknown(a.Severity)
+		if a.EmbargoUntil != nil {
+			body += fmt.Sprintf("\nEmbargo until %s; do not discuss it in public issues, pull requests or channels.",
+				a.EmbargoUntil.Format(time.DateOnly))
+		}
+		if a.Summary != "" {
+			body = a.Summary + "\n" + body
+		}
+		m.notify(ctx, a, internal.SeverityWarning, fmt.Sprintf("Security advisory %s reported in %s",
+			a.GHSAID, a.Repo), body)
+	}
+	return nil
+}
+
+// handleSecurityAdvisory follows tracked advisories in the GitHub advisory database: they
+// are published there once reviewed, and may be withdrawn later.
+func (m *AdvisoryModule) handleSecurityAdvisory(ctx context.Context, event *github.SecurityAdvisoryEvent) error {
+	ghsaID := event.GetSecurityAdvisory().GetGHSAID()
+	db := m.database.DB()
+	a, err := GetAdvisory(db, ghsaID)
+	if err != nil || a == nil {
+		return m.wrapDB(err, "get_advisory", "", ghsaID)
+	}
+	switch event.GetAction() {
+	case "published":
+		if a.PublishedAt != nil {
+			return nil
+		}
+		now := m.now()
+		a.State, a.PublishedAt = "published", &now
+	case "withdrawn":
+		a.State = "withdrawn"
+	default:
+		return nil
+	}
+	if _, err := TrackAdvisory(db, *a); err != nil {
+		return m.wrapDB(err, "track_advisory", a.Repo, a.GHSAID)
+	}
+	if a.State == "withdrawn" {
+		m.notify(ctx, *a, internal.SeverityWarning, fmt.Sprintf("Security advisory %s in %s was withdrawn",
+			a.GHSAID, a.Repo), "")
+	}
+	return nil
+}
+
+// RemindEmbargoes reminds maintainers of advisories whose embargo ends within one of the
+// configured numbers of days, and of advisories still unpublished once it has ended.
+func (m *AdvisoryModule) RemindEmbargoes(ctx context.Context) error {
+	advisories, err := ListAdvisories(m.database.DB())
+	if err != nil {
+		return fmt.Errorf("failed to list advisories: %w", err)
+	}
+	thresholds := slices.Sorted(slices.Values(m.config.RemindBeforeDays))
+	now := m.now()
+	for _, a := range advisories {
+		if a.EmbargoUntil == nil || a.PublishedAt != nil || a.State == "closed" || a.State == "withdrawn" {
+			continue
+		}
+		left := int(math.Ceil(a.EmbargoUntil.Sub(now).Hours() / 24))
+		days, due := 0, left <= 0
+		for _, t := range thresholds {
+			if !due && left <= t {
+				days, due = t, true
+			}
+		}
+		if !due || (a.RemindedDays != nil && *a.RemindedDays <= days) {
+			continue
+		}
+		severity := internal.SeverityWarning
+		title := fmt.Sprintf("Embargo of security advisory %s in %s ends on %s",
+			a.GHSAID, a.Repo, a.EmbargoUntil.Format(time.DateOnly))
+		body := fmt.Sprintf("Days left: %d\nSeverity: %s", left, orUnknown(a.Severity))
+		if left <= 0 {
+			severity = internal.SeverityCritical
+			title = fmt.Sprintf("Embargo of security advisory %s in %s ended on %s and it is not published",
+				a.GHSAID, a.Repo, a.EmbargoUntil.Format(time.DateOnly))
+			body = "Severity: " + orUnknown(a.Severity)
+		}
+		if err := m.notify(ctx, a, severity, title, body); err != nil {
+			continue
+		}
+		if err := MarkAdvisoryReminded(m.database.DB(), a.GHSAID, days); err != nil {
+			slog.Error("Failed to record embargo reminder", "ghsa_id", a.GHSAID, "error", err)
+		}
+	}
+	return nil
+}
+
+// notify sends a notification to the private channel only, never through the notification
+// routes, which may include public destinations. Logs name the advisory but not its content.
+func (m *AdvisoryModule) notify(ctx context.Context, a Advisory, severity internal.Severity, title, body string) error {
+	if m.target == "" || m.app == nil {
+		slog.Info("Security advisory notification not sent (no private channel configured)", "ghsa_id", a.GHSAID)
+		return nil
+	}
+	err := m.app.Notifications.Send(ctx, m.config.Backend, m.target, internal.Notification{
+		Module:   m.Name(),
+		Repo:     a.Repo,
+		Severity: severity,
+		Title:    title,
+		Body:     body,
+		URL:      a.URL,
+	})
+	if err != nil {
+		slog.Error("Failed to notify maintainers of security advisory", "ghsa_id", a.GHSAID, "error", err)
+	}
+	return err
+}
+
+// handleList lists the tracked advisories.
+func (m *AdvisoryModule) handleList(w http.ResponseWriter, r *http.Request) {
+	advisories, err := ListAdvisories(m.database.DB())
+	if err != nil {
+		slog.Error("Failed to list advisories", "error", err)
+		http.Error(w, "failed to list advisories", http.StatusInternalServerError)
+		return
+	}
+	if advisories == nil {
+		advisories = []Advisory{}
+	}
+	writeJSON(w, advisories)
+}
+
+// handleSetEmbargo sets the embargo of an advisory from {"until": "2025-07-01"}, or lifts it
+// with {"until": null}.
+func (m *AdvisoryModule) handleSetEmbargo(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Until *string `json:"until"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, `body must be JSON with "until" as YYYY-MM-DD or null`, http.StatusBadRequest)
+		return
+	}
+	var until *time.Time
+	if body.Until != nil {
+		t, err := time.Parse(time.DateOnly, *body.Until)
+		if err != nil {
+			http.Error(w, `"until" must be a date as YYYY-MM-DD`, http.StatusBadRequest)
+			return
+		}
+		until = &t
+	}
+	ghsaID := r.PathValue("ghsa")
+	found, err := SetAdvisoryEmbargo(m.database.DB(), ghsaID, until)
+	if err != nil {
+		slog.Error("Failed to set advisory embargo", "ghsa_id", ghsaID, "error", err)
+		http.Error(w, "failed to set embargo", http.StatusInternalServerError)
+		return
+	}
+	if !found {
+		http.Error(w, "advisory not tracked", http.StatusNotFound)
+		return
+	}
+	slog.Info("Advisory embargo set", "ghsa_id", ghsaID, "until", until)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func orUnknown(s string) string {
+	if s == "" {
+		return "unknown"
+	}
+	return s
+}
+
+func (m *AdvisoryModule) wrapDB(err error, op, repo, ghsaID string) error {
+	if err == nil {
+		return nil
+	}
+	return internal.LogAndWrapError(err, internal.ErrorTypeDatabase, op, map[string]any{
+		"module":  m.Name(),
+		"repo":    repo,
+		"ghsa_id": ghsaID,
+	})
+}
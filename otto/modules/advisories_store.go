@@ -0,0 +1,149 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+// Advisory is the private tracking record of a repository security advisory.
+type Advisory struct {
+	GHSAID       string     `json:"ghsa_id"`
+	Repo         string     `json:"repo"`
+	Severity     string     `json:"severity,omitempty"`
+	Summary      string     `json:"summary,omitempty"`
+	State        string     `json:"state"` // GitHub's state: triage, draft, published, closed or withdrawn
+	URL          string     `json:"url,omitempty"`
+	ReportedAt   time.Time  `json:"reported_at"`
+	EmbargoUntil *time.Time `json:"embargo_until,omitempty"`
+	PublishedAt  *time.Time `json:"published_at,omitempty"`
+	RemindedDays *int       `json:"reminded_days,omitempty"` // days before the embargo ended of the last reminder
+}
+
+func AutoMigrateAdvisories(db *sql.DB) error {
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS security_advisories (
+			ghsa_id TEXT PRIMARY KEY,
+			repo TEXT NOT NULL,
+			severity TEXT NOT NULL DEFAULT '',
+			summary TEXT NOT NULL DEFAULT '',
+			state TEXT NOT NULL,
+			url TEXT NOT NULL DEFAULT '',
+			reported_at TIMESTAMP NOT NULL,
+			embargo_until TIMESTAMP,
+			published_at TIMESTAMP,
+			reminded_days INTEGER
+		);`,
+		`CREATE INDEX IF NOT EXISTS idx_security_advisories_repo ON security_advisories(repo);`,
+	}
+	for _, s := range stmts {
+		if _, err := db.Exec(s); err != nil {
+			return fmt.Errorf("failed migration: %w (SQL: %s)", err, s)
+		}
+	}
+	return nil
+}
+
+// Migrate implements the ModuleMigrator interface.
+func (m *AdvisoryModule) Migrate(db *sql.DB) error {
+	return AutoMigrateAdvisories(db)
+}
+
+// TrackAdvisory records an advisory and reports whether it is new. An advisory already
+// tracked keeps its embargo and reminders; its other fields are updated.
+func TrackAdvisory(db *sql.DB, a Advisory) (bool, error) {
+	existing, err := GetAdvisory(db, a.GHSAID)
+	if err != nil || existing != nil {
+		if err == nil {
+			_, err = db.Exec(
+				`UPDATE security_advisories SET repo = ?, severity = ?, summary = ?, state = ?, url = ?,
+				 published_at = COALESCE(published_at, ?) WHERE ghsa_id = ?`,
+				a.Repo, a.Severity, a.Summary, a.State, a.URL, a.PublishedAt, a.GHSAID,
+			)
+		}
+		return false, err
+	}
+	_, err = db.Exec(
+		`INSERT INTO security_advisories
+		 (ghsa_id, repo, severity, summary, state, url, reported_at, embargo_until, published_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		a.GHSAID, a.Repo, a.Severity, a.Summary, a.State, a.URL, a.ReportedAt, a.EmbargoUntil, a.PublishedAt,
+	)
+	return err == nil, err
+}
+
+const advisoryColumns = `ghsa_id, repo, severity, summary, state, url, reported_at, embargo_until, published_at,
+	reminded_days`
+
+func scanAdvisory(row interface{ Scan(...any) error }) (Advisory, error) {
+	var a Advisory
+	var reminded sql.NullInt64
+	err := row.Scan(&a.GHSAID, &a.Repo, &a.Severity, &a.Summary, &a.State, &a.URL, &a.ReportedAt,
+		&a.EmbargoUntil, &a.PublishedAt, &reminded)
+	if reminded.Valid {
+		days := int(reminded.Int64)
+		a.RemindedDays = &days
+	}
+	return a, err
+}
+
+// GetAdvisory returns a tracked advisory, or nil if it is not tracked.
+func GetAdvisory(db *sql.DB, ghsaID string) (*Advisory, error) {
+	a, err := scanAdvisory(db.QueryRow(
+		`SELECT `+advisoryColumns+` FROM security_advisories WHERE ghsa_id = ?`, ghsaID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// ListAdvisories returns the tracked advisories, oldest report first.
+func ListAdvisories(db *sql.DB) ([]Advisory, error) {
+	rows, err := db.Query(`SELECT ` + advisoryColumns + ` FROM security_advisories ORDER BY reported_at`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var advisories []Advisory
+	for rows.Next() {
+		a, err := scanAdvisory(rows)
+		if err != nil {
+			return nil, err
+		}
+		advisories = append(advisories, a)
+	}
+	return advisories, rows.Err()
+}
+
+// SetAdvisoryEmbargo sets or, with a nil until, lifts an advisory's embargo and forgets the
+// reminders sent for the previous one. It reports whether the advisory is tracked.
+func SetAdvisoryEmbargo(db *sql.DB, ghsaID string, until *time.Time) (bool, error) {
+	res, err := db.Exec(
+		`UPDATE security_advisories SET embargo_until = ?, reminded_days = NULL WHERE ghsa_id = ?`, until, ghsaID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// MarkAdvisoryReminded records the reminder sent the given number of days before an
+// advisory's embargo ends.
+func MarkAdvisoryReminded(db *sql.DB, ghsaID string, days int) error {
+	_, err := db.Exec(`UPDATE security_advisories SET reminded_days = ? WHERE ghsa_id = ?`, days, ghsaID)
+	return err
+}
+
+// RenameRepo implements the ModuleRepoRenamer interface.
+func (m *AdvisoryModule) RenameRepo(ctx context.Context, from, to string) error {
+	return internal.RenameRepoIn(ctx, m.database.DB(), from, to, "security_advisories.repo")
+}
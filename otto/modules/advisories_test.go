@@ -0,0 +1,288 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v71/github"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+	"github.com/open-telemetry/sig-project-infra/otto/internal/secrets"
+)
+
+var advisoryNow = time.Date(2025, time.March, 3, 9, 0, 0, 0, time.UTC)
+
+// advisoryNotifier records notifications delivered on one backend.
+type advisoryNotifier struct {
+	backend string
+
+	mu    sync.Mutex
+	sent  []string // target: title
+	texts []string
+}
+
+func (n *advisoryNotifier) Backend() string { return n.backend }
+
+func (n *advisoryNotifier) Notify(_ context.Context, target string, notification internal.Notification) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.sent = append(n.sent, target+": "+notification.Title)
+	n.texts = append(n.texts, notification.Text())
+	return nil
+}
+
+type advisoryTestEnv struct {
+	mod    *AdvisoryModule
+	now    time.Time
+	slack  *advisoryNotifier // the private channel
+	public *advisoryNotifier // routed notifications
+}
+
+// newAdvisoryTestEnv returns an advisories module whose GitHub client fails the test on any
+// request and whose notification routes send everything to a public channel.
+func newAdvisoryTestEnv(t *testing.T) *advisoryTestEnv {
+	t.Helper()
+	db := internal.TestDB(t)
+	if err := AutoMigrateAdvisories(db); err != nil {
+		t.Fatalf("AutoMigrateAdvisories failed: %v", err)
+	}
+	notifications, err := internal.NewNotifications(config.NotificationsConfig{
+		Channels: map[string]config.NotificationChannel{"public": {Backend: "github", Target: "org/community#1"}},
+		Routes:   []config.NotificationRoute{{Channels: []string{"public"}}},
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewNotifications failed: %v", err)
+	}
+	env := &advisoryTestEnv{
+		now:    advisoryNow,
+		slack:  &advisoryNotifier{backend: "slack"},
+		public: &advisoryNotifier{backend: "github"},
+	}
+	notifications.Register(env.slack)
+	notifications.Register(env.public)
+	client := internal.TestGitHubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected GitHub request %s %s", r.Method, r.URL.Path)
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	env.mod = &AdvisoryModule{
+		app:      &internal.App{GitHubClient: client, Notifications: notifications},
+		database: internal.NewDatabaseFromDB(db),
+		config:   defaultAdvisoryConfig(),
+		target:   "C0SECURITY",
+		now:      func() time.Time { return env.now },
+	}
+	t.Cleanup(func() {
+		if len(env.public.sent) > 0 {
+			t.Errorf("notifications went through public routes: %q", env.public.sent)
+		}
+	})
+	return env
+}
+
+func repositoryAdvisoryEvent(action, ghsaID string) *internal.RepositoryAdvisoryEvent {
+	return &internal.RepositoryAdvisoryEvent{
+		Action: github.Ptr(action),
+		RepositoryAdvisory: &github.SecurityAdvisory{
+			GHSAID:   github.Ptr(ghsaID),
+			Summary:  github.Ptr("Token leaked in debug logs"),
+			Severity: github.Ptr("high"),
+			State:    github.Ptr("triage"),
+			HTMLURL:  github.Ptr("https://github.com/org/repo/security/advisories/" + ghsaID),
+		},
+		Repo: &github.Repository{FullName: github.Ptr("org/repo")},
+	}
+}
+
+func TestAdvisoryIntake(t *testing.T) {
+	env := newAdvisoryTestEnv(t)
+	mod := env.mod
+	for range 2 { // a redelivered report is not announced again
+		if err := mod.HandleEvent("repository_advisory", repositoryAdvisoryEvent("reported", "GHSA-1"), nil); err != nil {
+			t.Fatalf("HandleEvent failed: %v", err)
+		}
+	}
+	a, err := GetAdvisory(mod.database.DB(), "GHSA-1")
+	if err != nil || a == nil {
+		t.Fatalf("GetAdvisory = %v, %v", a, err)
+	}
+	if a.Repo != "org/repo" || a.Severity != "high" || a.State != "triage" || a.EmbargoUntil == nil ||
+		!a.EmbargoUntil.Equal(advisoryNow.AddDate(0, 0, 90)) {
+		t.Errorf("advisory = %+v", a)
+	}
+	if !slices.Equal(env.slack.sent, []string{"C0SECURITY: Security advisory GHSA-1 reported in org/repo"}) {
+		t.Fatalf("sent = %q", env.slack.sent)
+	}
+	if text := env.slack.texts[0]; !strings.Contains(text, "Token leaked in debug logs") ||
+		!strings.Contains(text, "Embargo until 2025-06-01") {
+		t.Errorf("notification = %q", text)
+	}
+
+	// Embargo reminders follow the configured days, once each.
+	tests := []struct {
+		at       time.Time
+		wantSent string
+	}{
+		{advisoryNow.AddDate(0, 0, 60), ""},
+		{advisoryNow.AddDate(0, 0, 77), "Embargo of security advisory GHSA-1 in org/repo ends on 2025-06-01"},
+		{advisoryNow.AddDate(0, 0, 78), ""},
+		{advisoryNow.AddDate(0, 0, 89), "Embargo of security advisory GHSA-1 in org/repo ends on 2025-06-01"},
+		{
+			advisoryNow.AddDate(0, 0, 91),
+			"Embargo of security advisory GHSA-1 in org/repo ended on 2025-06-01 and it is not published",
+		},
+		{advisoryNow.AddDate(0, 0, 92), ""},
+	}
+	for _, tt := range tests {
+		env.now = tt.at
+		before := len(env.slack.sent)
+		if err := mod.RemindEmbargoes(t.Context()); err != nil {
+			t.Fatalf("RemindEmbargoes failed: %v", err)
+		}
+		var got string
+		if len(env.slack.sent) > before {
+			got = strings.TrimPrefix(env.slack.sent[len(env.slack.sent)-1], "C0SECURITY: ")
+		}
+		if len(env.slack.sent) > before+1 || got != tt.wantSent {
+			t.Errorf("at %s sent %q, want %q", tt.at.Format(time.DateOnly), env.slack.sent[before:], tt.wantSent)
+		}
+	}
+
+	// Publishing ends the embargo.
+	if err := mod.HandleEvent("repository_advisory", repositoryAdvisoryEvent("published", "GHSA-1"), nil); err != nil {
+		t.Fatalf("HandleEvent failed: %v", err)
+	}
+	a, _ = GetAdvisory(mod.database.DB(), "GHSA-1")
+	if a.State != "published" || a.PublishedAt == nil {
+		t.Errorf("advisory after publishing = %+v", a)
+	}
+	last := env.slack.sent[len(env.slack.sent)-1]
+	if last != "C0SECURITY: Security advisory GHSA-1 in org/repo was published" {
+		t.Errorf("last sent = %q", last)
+	}
+	sent := len(env.slack.sent)
+	if _, err := SetAdvisoryEmbargo(mod.database.DB(), "GHSA-1", &advisoryNow); err != nil {
+		t.Fatalf("SetAdvisoryEmbargo failed: %v", err)
+	}
+	if err := mod.RemindEmbargoes(t.Context()); err != nil {
+		t.Fatalf("RemindEmbargoes failed: %v", err)
+	}
+	if len(env.slack.sent) != sent {
+		t.Errorf("published advisories should not be reminded of: %q", env.slack.sent[sent:])
+	}
+}
+
+func TestAdvisoryWithdrawn(t *testing.T) {
+	env := newAdvisoryTestEnv(t)
+	mod := env.mod
+	withdrawn := func(ghsaID string) *github.SecurityAdvisoryEvent {
+		return &github.SecurityAdvisoryEvent{
+			Action:           github.Ptr("withdrawn"),
+			SecurityAdvisory: &github.SecurityAdvisory{GHSAID: github.Ptr(ghsaID)},
+		}
+	}
+	// Advisories of other projects in the advisory database are ignored.
+	if err := mod.HandleEvent("security_advisory", withdrawn("GHSA-other"), nil); err != nil {
+		t.Fatalf("HandleEvent failed: %v", err)
+	}
+	if err := mod.HandleEvent("repository_advisory", repositoryAdvisoryEvent("reported", "GHSA-2"), nil); err != nil {
+		t.Fatalf("HandleEvent failed: %v", err)
+	}
+	if err := mod.HandleEvent("security_advisory", withdrawn("GHSA-2"), nil); err != nil {
+		t.Fatalf("HandleEvent failed: %v", err)
+	}
+	advisories, err := ListAdvisories(mod.database.DB())
+	if err != nil || len(advisories) != 1 || advisories[0].State != "withdrawn" {
+		t.Fatalf("advisories = %+v, %v", advisories, err)
+	}
+	want := []string{
+		"C0SECURITY: Security advisory GHSA-2 reported in org/repo",
+		"C0SECURITY: Security advisory GHSA-2 in org/repo was withdrawn",
+	}
+	if !slices.Equal(env.slack.sent, want) {
+		t.Errorf("sent = %q", env.slack.sent)
+	}
+	env.now = advisoryNow.AddDate(0, 0, 100)
+	if err := mod.RemindEmbargoes(t.Context()); err != nil || len(env.slack.sent) != 2 {
+		t.Errorf("withdrawn advisories should not be reminded of: %v, %q", err, env.slack.sent)
+	}
+}
+
+func TestAdvisoryEmbargoAdmin(t *testing.T) {
+	env := newAdvisoryTestEnv(t)
+	mod := env.mod
+	if err := mod.HandleEvent("repository_advisory", repositoryAdvisoryEvent("reported", "GHSA-3"), nil); err != nil {
+		t.Fatalf("HandleEvent failed: %v", err)
+	}
+	if err := MarkAdvisoryReminded(mod.database.DB(), "GHSA-3", 7); err != nil {
+		t.Fatalf("MarkAdvisoryReminded failed: %v", err)
+	}
+
+	tests := []struct {
+		ghsa       string
+		body       string
+		wantStatus int
+		wantUntil  string
+	}{
+		{"GHSA-3", `{"until": "2025-04-01"}`, http.StatusNoContent, "2025-04-01"},
+		{"GHSA-3", `{"until": null}`, http.StatusNoContent, ""},
+		{"GHSA-3", `{"until": "April"}`, http.StatusBadRequest, ""},
+		{"GHSA-9", `{"until": "2025-04-01"}`, http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodPut, "/admin/advisories/"+tt.ghsa+"/embargo", strings.NewReader(tt.body))
+		r.SetPathValue("ghsa", tt.ghsa)
+		rr := httptest.NewRecorder()
+		mod.handleSetEmbargo(rr, r)
+		if rr.Code != tt.wantStatus {
+			t.Fatalf("%s %s: status = %d, want %d", tt.ghsa, tt.body, rr.Code, tt.wantStatus)
+		}
+		if tt.wantStatus != http.StatusNoContent {
+			continue
+		}
+		a, _ := GetAdvisory(mod.database.DB(), tt.ghsa)
+		var until string
+		if a.EmbargoUntil != nil {
+			until = a.EmbargoUntil.Format(time.DateOnly)
+		}
+		if until != tt.wantUntil || a.RemindedDays != nil {
+			t.Errorf("%s: embargo %q, reminded %v; want %q and no reminders", tt.body, until, a.RemindedDays, tt.wantUntil)
+		}
+	}
+
+	rr := httptest.NewRecorder()
+	mod.handleList(rr, httptest.NewRequest(http.MethodGet, "/admin/advisories", nil))
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `"ghsa_id":"GHSA-3"`) {
+		t.Errorf("list = %d %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestAdvisoryInitialize(t *testing.T) {
+	newApp := func(backend string) *internal.App {
+		manager := secrets.NewFileManager("webhook", 1, 1, "", nil)
+		manager.Secrets = map[string]string{"security_channel": "C0SECURITY"}
+		return &internal.App{
+			Config:   &config.AppConfig{Modules: map[string]any{"advisories": map[string]any{"backend": backend}}},
+			Secrets:  manager,
+			Database: internal.NewDatabaseFromDB(internal.TestDB(t)),
+		}
+	}
+	mod := &AdvisoryModule{}
+	if err := mod.Initialize(t.Context(), newApp("slack")); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	if mod.target != "C0SECURITY" {
+		t.Errorf("target = %q, want the secret's value", mod.target)
+	}
+	if err := (&AdvisoryModule{}).Initialize(t.Context(), newApp("github")); err == nil {
+		t.Error("Initialize should reject the public github backend")
+	}
+}
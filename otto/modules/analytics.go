@@ -0,0 +1,276 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+// User roles command usage is broken down by.
+const (
+	RoleMaintainer  = "maintainer"
+	RoleContributor = "contributor"
+	RoleOther       = "other"
+)
+
+// contributorAssociations are author associations of users who contributed to a repository
+// without maintaining it.
+var contributorAssociations = map[string]bool{
+	"CONTRIBUTOR":            true,
+	"FIRST_TIME_CONTRIBUTOR": true,
+	"FIRST_TIMER":            true,
+}
+
+// AnalyticsConfig configures the command usage analytics.
+type AnalyticsConfig struct {
+	ReportWeeks   int `yaml:"report_weeks" doc:"weeks the admin report covers without an explicit ?weeks="`
+	RetentionDays int `yaml:"retention_days" doc:"days weekly usage is kept before it is pruned"`
+}
+
+// Validate implements the ModuleConfigValidator interface.
+func (c *AnalyticsConfig) Validate() error {
+	if c.ReportWeeks <= 0 {
+		return fmt.Errorf("analytics: report_weeks must be positive, got %d", c.ReportWeeks)
+	}
+	if c.RetentionDays < 7*c.ReportWeeks {
+		return fmt.Errorf("analytics: retention_days must cover the %d report weeks, got %d",
+			c.ReportWeeks, c.RetentionDays)
+	}
+	return nil
+}
+
+// AnalyticsModule counts the slash commands of other modules used per week, by command,
+// repository and role of the user, so the SIG can see which automations are used and
+// retire the rest. The last complete week is reported as the otto.module.commands_weekly
+// gauge, and the admin API reports the usage of every declared command, unused ones
+// included.
+type AnalyticsModule struct {
+	app      *internal.App
+	database *internal.Database
+	config   AnalyticsConfig
+	now      func() time.Time
+}
+
+func (m *AnalyticsModule) Name() string { return "analytics" }
+
+// ConfigSchema implements the ModuleConfigSchema interface.
+func (m *AnalyticsModule) ConfigSchema() any {
+	c := defaultAnalyticsConfig()
+	return &c
+}
+
+// defaultAnalyticsConfig returns the analytics module's defaults.
+func defaultAnalyticsConfig() AnalyticsConfig {
+	return AnalyticsConfig{ReportWeeks: 12, RetentionDays: 365}
+}
+
+// EventSubscriptions implements the ModuleEventSubscriber interface. The module consumes
+// no events: the app hands it the commands of every comment through ObserveCommands.
+func (m *AnalyticsModule) EventSubscriptions() []internal.EventSubscription {
+	return nil
+}
+
+// GitHubPermissions implements the ModulePermissionRequirer interface.
+func (m *AnalyticsModule) GitHubPermissions() map[string]string {
+	return map[string]string{"issues": "read"}
+}
+
+// Initialize implements the ModuleInitializer interface.
+func (m *AnalyticsModule) Initialize(ctx context.Context, app *internal.App) error {
+	m.app = app
+	m.database = app.Database
+	if m.now == nil {
+		m.now = time.Now
+	}
+	m.config = defaultAnalyticsConfig()
+	if err := loadModuleConfig(app, m.Name(), &m.config); err != nil {
+		return err
+	}
+	if err := AutoMigrateAnalytics(m.database.DB()); err != nil {
+		return err
+	}
+	app.HandleAdmin("GET /admin/analytics/commands", m.handleReport)
+	if app.Scheduler != nil {
+		app.Scheduler.Register(internal.Job{
+			Name:     "command_usage_weekly",
+			Module:   m.Name(),
+			Interval: time.Hour,
+			Run:      m.RefreshWeeklyUsage,
+		})
+	}
+	return nil
+}
+
+func (m *AnalyticsModule) HandleEvent(eventType string, event any, raw json.RawMessage) error {
+	return nil
+}
+
+// ObserveCommands implements the ModuleCommandObserver interface. It counts the commands
+// modules declare, by repository and the role of their issuer.
+func (m *AnalyticsModule) ObserveCommands(cmds []*internal.CommandContext) error {
+	handlers := m.declaredCommands()
+	for _, cmd := range cmds {
+		command, ok := declaredCommand(handlers, cmd.Command, cmd.Args)
+		if !ok {
+			continue
+		}
+		role := commandRole(cmd.Event.GetComment().GetAuthorAssociation())
+		if err := RecordCommandUsage(m.database.DB(), m.now(), cmd.Repo, command, role); err != nil {
+			return internal.LogAndWrapError(err, internal.ErrorTypeModule, "record_command_usage", map[string]any{
+				"repo":    cmd.Repo,
+				"command": command,
+			})
+		}
+	}
+	return nil
+}
+
+// commandRole maps a comment's author association to the role its command is counted for.
+func commandRole(association string) string {
+	switch {
+	case maintainerAssociations[association]:
+		return RoleMaintainer
+	case contributorAssociations[association]:
+		return RoleContributor
+	default:
+		return RoleOther
+	}
+}
+
+// declaredCommands returns the module declaring each slash command of the registered modules.
+func (m *AnalyticsModule) declaredCommands() map[string]string {
+	handlers := make(map[string]string)
+	for name, mod := range m.app.GetModules() {
+		if c, ok := mod.(internal.ModuleCommander); ok {
+			for _, command := range c.SlashCommands() {
+				handlers[command] = name
+			}
+		}
+	}
+	return handlers
+}
+
+// declaredCommand returns the declared command a comment's command is counted as: the
+// command and its first argument for commands declared with a subcommand, else the command
+// alone. Commands no module declares, such as other bots' commands, are not counted.
+func declaredCommand(handlers map[string]string, name string, args []string) (string, bool) {
+	if len(args) > 0 {
+		if command := name + " " + strings.ToLower(args[0]); handlers[command] != "" {
+			return command, true
+		}
+	}
+	return name, handlers[name] != ""
+}
+
+// RefreshWeeklyUsage reports the usage of the last complete week on the weekly commands
+// gauge, and prunes usage older than the retention.
+func (m *AnalyticsModule) RefreshWeeklyUsage(ctx context.Context) error {
+	now := m.now()
+	lastWeek := weekStart(now).AddDate(0, 0, -7)
+	rows, err := ListCommandUsage(m.database.DB(), lastWeek)
+	if err != nil {
+		return internal.LogAndWrapError(err, internal.ErrorTypeModule, "list_command_usage", nil)
+	}
+	var usage []internal.CommandUsage
+	for _, row := range rows {
+		if row.Week.Equal(lastWeek) {
+			usage = append(usage, row.CommandUsage)
+		}
+	}
+	if m.app.Telemetry != nil {
+		m.app.Telemetry.SetWeeklyCommandUsage(usage)
+	}
+	pruned, err := PruneCommandUsage(m.database.DB(), now.AddDate(0, 0, -m.config.RetentionDays))
+	if err != nil {
+		return internal.LogAndWrapError(err, internal.ErrorTypeModule, "prune_command_usage", nil)
+	}
+	if pruned > 0 {
+		slog.InfoContext(ctx, "Pruned command usage", "rows", pruned)
+	}
+	return nil
+}
+
+// CommandUsageReport is how a declared slash command was used over the weeks of a report.
+type CommandUsageReport struct {
+	Command  string           `json:"command"`
+	Module   string           `json:"module"`
+	Total    int64            `json:"total"`
+	Roles    map[string]int64 `json:"roles"`
+	Repos    map[string]int64 `json:"repos"`
+	LastUsed *time.Time       `json:"last_used,omitempty"` // week it was last used in
+}
+
+// UsageReport is the command usage the admin API reports.
+type UsageReport struct {
+	Since    time.Time            `json:"since"`
+	Commands []CommandUsageReport `json:"commands"` // most used first
+	Unused   []string             `json:"unused"`   // declared commands not used since
+}
+
+// Report returns the usage of every declared command in the weeks from the one since is in.
+func (m *AnalyticsModule) Report(since time.Time) (UsageReport, error) {
+	rows, err := ListCommandUsage(m.database.DB(), since)
+	if err != nil {
+		return UsageReport{}, err
+	}
+	handlers := m.declaredCommands()
+	byCommand := make(map[string]*CommandUsageReport)
+	for _, row := range rows {
+		r, ok := byCommand[row.Command]
+		if !ok {
+			r = &CommandUsageReport{Command: row.Command, Module: handlers[row.Command],
+				Roles: make(map[string]int64), Repos: make(map[string]int64)}
+			byCommand[row.Command] = r
+		}
+		r.Total += row.Count
+		r.Roles[row.Role] += row.Count
+		r.Repos[row.Repo] += row.Count
+		week := row.Week
+		r.LastUsed = &week
+	}
+	report := UsageReport{Since: weekStart(since), Commands: []CommandUsageReport{}, Unused: []string{}}
+	for _, r := range byCommand {
+		report.Commands = append(report.Commands, *r)
+	}
+	slices.SortFunc(report.Commands, func(a, b CommandUsageReport) int {
+		return cmp.Or(cmp.Compare(b.Total, a.Total), strings.Compare(a.Command, b.Command))
+	})
+	for _, command := range slices.Sorted(maps.Keys(handlers)) {
+		if _, ok := byCommand[command]; !ok {
+			report.Unused = append(report.Unused, command)
+		}
+	}
+	return report, nil
+}
+
+// handleReport serves the command usage of the last ?weeks= weeks, the current one included.
+func (m *AnalyticsModule) handleReport(w http.ResponseWriter, r *http.Request) {
+	weeks := m.config.ReportWeeks
+	if v := r.URL.Query().Get("weeks"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			http.Error(w, "weeks must be a positive number", http.StatusBadRequest)
+			return
+		}
+		weeks = n
+	}
+	report, err := m.Report(m.now().AddDate(0, 0, -7*(weeks-1)))
+	if err != nil {
+		slog.Error("Failed to report command usage", "error", err)
+		http.Error(w, "failed to report command usage", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, report)
+}
@@ -0,0 +1,93 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"io/fs"
+	"time"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+//go:embed migrations/analytics/*.sql
+var analyticsMigrations embed.FS
+
+// CommandUsageRow is how often a slash command was used in a week.
+type CommandUsageRow struct {
+	Week time.Time // Monday the week starts on, UTC
+	internal.CommandUsage
+}
+
+func AutoMigrateAnalytics(db *sql.DB) error {
+	migrations, err := fs.Sub(analyticsMigrations, "migrations/analytics")
+	if err != nil {
+		return err
+	}
+	return internal.VersionedMigrations("analytics", migrations)(db)
+}
+
+// Migrate implements the ModuleMigrator interface.
+func (m *AnalyticsModule) Migrate(db *sql.DB) error {
+	return AutoMigrateAnalytics(db)
+}
+
+// weekStart returns the Monday, at midnight UTC, starting the week t is in.
+func weekStart(t time.Time) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+}
+
+// RecordCommandUsage counts one use of a command in repo by a user of role, in the week of at.
+func RecordCommandUsage(db *sql.DB, at time.Time, repo, command, role string) error {
+	_, err := db.Exec(
+		`INSERT INTO command_usage (week, repo, command, role, count) VALUES (?, ?, ?, ?, 1)
+		 ON CONFLICT (week, repo, command, role) DO UPDATE SET count = count + 1`,
+		weekStart(at).Format(time.DateOnly), repo, command, role,
+	)
+	return err
+}
+
+// ListCommandUsage returns the command usage of the weeks from the one since is in, oldest
+// week first.
+func ListCommandUsage(db *sql.DB, since time.Time) ([]CommandUsageRow, error) {
+	rows, err := db.Query(
+		`SELECT week, repo, command, role, count FROM command_usage WHERE week >= ?
+		 ORDER BY week ASC, command ASC, repo ASC, role ASC`,
+		weekStart(since).Format(time.DateOnly),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var usage []CommandUsageRow
+	for rows.Next() {
+		var row CommandUsageRow
+		var week string
+		if err := rows.Scan(&week, &row.Repo, &row.Command, &row.Role, &row.Count); err != nil {
+			return nil, err
+		}
+		if row.Week, err = time.Parse(time.DateOnly, week); err != nil {
+			return nil, err
+		}
+		usage = append(usage, row)
+	}
+	return usage, rows.Err()
+}
+
+// PruneCommandUsage deletes the usage of the weeks that started before before.
+func PruneCommandUsage(db *sql.DB, before time.Time) (int64, error) {
+	res, err := db.Exec(`DELETE FROM command_usage WHERE week < ?`, weekStart(before).Format(time.DateOnly))
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// RenameRepo implements the ModuleRepoRenamer interface.
+func (m *AnalyticsModule) RenameRepo(ctx context.Context, from, to string) error {
+	return internal.RenameRepoIn(ctx, m.database.DB(), from, to, "command_usage.repo")
+}
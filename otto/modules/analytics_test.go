@@ -0,0 +1,151 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v71/github"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+func TestAnalyticsCommandUsage(t *testing.T) {
+	db := internal.TestDB(t)
+	reader := sdkmetric.NewManualReader()
+	// Wednesday of the week starting Monday, April 28.
+	now := time.Date(2025, time.April, 30, 9, 0, 0, 0, time.UTC)
+	mod := &AnalyticsModule{now: func() time.Time { return now }}
+	app := &internal.App{
+		ModuleRegistry: internal.NewModuleRegistry(),
+		Database:       internal.NewDatabaseFromDB(db),
+		Telemetry:      internal.TestTelemetry(t, reader),
+	}
+	app.RegisterModule(mod)
+	app.RegisterModule(&HistoryModule{})
+	app.RegisterModule(&WorkflowModule{})
+	if err := mod.Initialize(t.Context(), app); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	comment := func(repo, association, body string) {
+		t.Helper()
+		event := commentEvent(repo, 1, "user", body)
+		event.Comment.AuthorAssociation = github.Ptr(association)
+		if err := internal.TestObserveCommands(t, mod, event); err != nil {
+			t.Fatalf("ObserveCommands(%q) failed: %v", body, err)
+		}
+	}
+	comment("org/a", "MEMBER", "/trigger-workflow release version=1.0.0\n/otto history")
+	comment("org/a", "CONTRIBUTOR", "/trigger-workflow release")
+	comment("org/b", "NONE", "/otto status\n/assign @bob")
+	bot := commentEvent("org/a", 1, "ci[bot]", "/trigger-workflow release")
+	bot.Comment.User.Type = github.Ptr("Bot")
+	if err := internal.TestObserveCommands(t, mod, bot); err != nil {
+		t.Fatalf("ObserveCommands failed: %v", err)
+	}
+	// A week later, the previous week is complete.
+	now = now.AddDate(0, 0, 7)
+	comment("org/b", "OWNER", "/trigger-workflow sync")
+
+	rows, err := ListCommandUsage(db, now.AddDate(0, 0, -7))
+	if err != nil {
+		t.Fatalf("ListCommandUsage failed: %v", err)
+	}
+	var got []string
+	for _, r := range rows {
+		got = append(got, fmt.Sprintf("%s %s %s %s %d", r.Week.Format(time.DateOnly), r.Repo, r.Command, r.Role,
+			r.Count))
+	}
+	want := []string{
+		"2025-04-28 org/a otto history maintainer 1",
+		"2025-04-28 org/a trigger-workflow contributor 1",
+		"2025-04-28 org/a trigger-workflow maintainer 1",
+		"2025-05-05 org/b trigger-workflow maintainer 1",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("usage = %q, want %q", got, want)
+	}
+
+	if err := mod.RefreshWeeklyUsage(t.Context()); err != nil {
+		t.Fatalf("RefreshWeeklyUsage failed: %v", err)
+	}
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(t.Context(), &rm); err != nil {
+		t.Fatalf("failed to collect metrics: %v", err)
+	}
+	var weekly int64
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if g, ok := m.Data.(metricdata.Gauge[int64]); ok && m.Name == "otto.module.commands_weekly" {
+				for _, dp := range g.DataPoints {
+					weekly += dp.Value
+				}
+			}
+		}
+	}
+	if weekly != 3 {
+		t.Errorf("weekly commands gauge = %d, want the 3 commands of the last complete week", weekly)
+	}
+
+	rr := httptest.NewRecorder()
+	mod.handleReport(rr, httptest.NewRequest(http.MethodGet, "/admin/analytics/commands?weeks=2", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rr.Code)
+	}
+	var report UsageReport
+	if err := json.Unmarshal(rr.Body.Bytes(), &report); err != nil {
+		t.Fatalf("failed to decode report: %v", err)
+	}
+	if len(report.Commands) != 2 || report.Commands[0].Command != "trigger-workflow" {
+		t.Fatalf("report commands = %+v", report.Commands)
+	}
+	top := report.Commands[0]
+	if top.Module != "workflows" || top.Total != 3 || top.Roles[RoleMaintainer] != 2 || top.Repos["org/b"] != 1 ||
+		!top.LastUsed.Equal(time.Date(2025, time.May, 5, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("trigger-workflow usage = %+v", top)
+	}
+	if !slices.Equal(report.Unused, []string{}) {
+		t.Errorf("unused commands = %q, want none", report.Unused)
+	}
+
+	// Only the current week: /otto history went unused.
+	rr = httptest.NewRecorder()
+	mod.handleReport(rr, httptest.NewRequest(http.MethodGet, "/admin/analytics/commands?weeks=1", nil))
+	report = UsageReport{}
+	if err := json.Unmarshal(rr.Body.Bytes(), &report); err != nil {
+		t.Fatalf("failed to decode report: %v", err)
+	}
+	if !slices.Equal(report.Unused, []string{"otto history"}) {
+		t.Errorf("unused commands = %q, want [otto history]", report.Unused)
+	}
+
+	rr = httptest.NewRecorder()
+	mod.handleReport(rr, httptest.NewRequest(http.MethodGet, "/admin/analytics/commands?weeks=0", nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("weeks=0: status = %d, want 400", rr.Code)
+	}
+}
+
+func TestAnalyticsConfigValidate(t *testing.T) {
+	for _, tc := range []struct {
+		config  AnalyticsConfig
+		wantErr bool
+	}{
+		{defaultAnalyticsConfig(), false},
+		{AnalyticsConfig{ReportWeeks: 0, RetentionDays: 365}, true},
+		{AnalyticsConfig{ReportWeeks: 12, RetentionDays: 30}, true},
+	} {
+		if err := tc.config.Validate(); (err != nil) != tc.wantErr {
+			t.Errorf("Validate(%+v) error = %v, wantErr %v", tc.config, err, tc.wantErr)
+		}
+	}
+}
@@ -0,0 +1,556 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/google/go-github/v71/github"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+// maxAnnouncementRequest bounds the size of an announcement request body.
+const maxAnnouncementRequest = 1 << 20
+
+// AnnouncementsConfig configures announcement broadcasts.
+type AnnouncementsConfig struct {
+	Category string        `yaml:"category" doc:"discussion category used when a request names none"`
+	Delay    time.Duration `yaml:"delay" doc:"pause between repositories to spread out API calls"`
+	Interval time.Duration `yaml:"interval" doc:"how often queued announcements are posted or rolled back"`
+	MaxRepos int           `yaml:"max_repos" doc:"announcements reaching more repositories are refused"`
+	Exclude  []string      `yaml:"exclude" doc:"repositories, patterns or @groups never announced to"`
+	OptOut   bool          `yaml:"opt_out" doc:"set in a repository's .github/otto.yml to receive no announcements"`
+}
+
+// Validate implements the ModuleConfigValidator interface.
+func (c *AnnouncementsConfig) Validate() error {
+	if c.Delay < 0 {
+		return fmt.Errorf("announcements: delay must not be negative, got %s", c.Delay)
+	}
+	if c.Interval <= 0 {
+		return fmt.Errorf("announcements: interval must be positive, got %s", c.Interval)
+	}
+	if c.MaxRepos <= 0 {
+		return fmt.Errorf("announcements: max_repos must be positive, got %d", c.MaxRepos)
+	}
+	return nil
+}
+
+// announcementRequest is the body of `POST /admin/announcements`.
+type announcementRequest struct {
+	Title    string            `json:"title"`
+	Body     string            `json:"body"`
+	Repos    []string          `json:"repos"`
+	Kind     string            `json:"kind"`
+	Category string            `json:"category"`
+	Pin      bool              `json:"pin"`
+	Labels   []string          `json:"labels"`
+	Vars     map[string]string `json:"vars"`
+	DryRun   bool              `json:"dry_run"`
+}
+
+// announcementData is what announcement templates are executed with.
+type announcementData struct {
+	Repo  string // full name
+	Owner string
+	Name  string
+	Vars  map[string]string
+}
+
+// announcementPreview is a post of a dry run.
+type announcementPreview struct {
+	Repo   string `json:"repo"`
+	Title  string `json:"title"`
+	Body   string `json:"body"`
+	Status string `json:"status"` // PostPending or PostSkipped
+	Reason string `json:"reason,omitempty"`
+}
+
+// AnnouncementsModule broadcasts an announcement, such as a moved SIG meeting, as an issue
+// or a discussion in every repository of a list or @group, through the admin API. Titles
+// and bodies are templates executed for each repository. Announcements are posted in the
+// background at a limited rate, skipping excluded repositories and those that opted out
+// in their .github/otto.yml, and a rollback closes every post an announcement created.
+type AnnouncementsModule struct {
+	app      *internal.App
+	database *internal.Database
+	config   AnnouncementsConfig
+	now      func() time.Time
+}
+
+func (m *AnnouncementsModule) Name() string { return "announcements" }
+
+// ConfigSchema implements the ModuleConfigSchema interface.
+func (m *AnnouncementsModule) ConfigSchema() any {
+	c := defaultAnnouncementsConfig()
+	return &c
+}
+
+// defaultAnnouncementsConfig returns the announcements module's defaults.
+func defaultAnnouncementsConfig() AnnouncementsConfig {
+	return AnnouncementsConfig{
+		Category: "Announcements",
+		Delay:    2 * time.Second,
+		Interval: time.Minute,
+		MaxRepos: 200,
+	}
+}
+
+// GitHubPermissions implements the ModulePermissionRequirer interface.
+func (m *AnnouncementsModule) GitHubPermissions() map[string]string {
+	return map[string]string{"issues": "write", "discussions": "write", "contents": "read"}
+}
+
+// Initialize implements the ModuleInitializer interface.
+func (m *AnnouncementsModule) Initialize(ctx context.Context, app *internal.App) error {
+	m.app = app
+	m.database = app.Database
+	if m.now == nil {
+		m.now = time.Now
+	}
+	m.config = defaultAnnouncementsConfig()
+	if err := loadModuleConfig(app, m.Name(), &m.config); err != nil {
+		return err
+	}
+	if err := AutoMigrateAnnouncements(m.database.DB()); err != nil {
+		return err
+	}
+	app.HandleAdmin("POST /admin/announcements", m.handleCreate)
+	app.HandleAdmin("GET /admin/announcements", m.handleList)
+	app.HandleAdmin("GET /admin/announcements/{id}", m.handleGet)
+	app.HandleAdmin("POST /admin/announcements/{id}/rollback", m.handleRollback)
+	if app.Scheduler != nil {
+		app.Scheduler.Register(internal.Job{
+			Name:       "announcements",
+			Module:     m.Name(),
+			Interval:   m.config.Interval,
+			Run:        m.ProcessAnnouncements,
+			Deferrable: true,
+		})
+	}
+	return nil
+}
+
+// HandleEvent implements the Module interface. Announcements are made through the admin
+// API, so no events are handled.
+func (m *AnnouncementsModule) HandleEvent(eventType string, event any, raw json.RawMessage) error {
+	return nil
+}
+
+// handleCreate queues an announcement, or previews its posts with "dry_run": true.
+func (m *AnnouncementsModule) handleCreate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var req announcementRequest
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxAnnouncementRequest))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		http.Error(w, "invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := m.validateRequest(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	repos, err := repoGroups(m.app).Resolve(ctx, req.Repos)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(repos) == 0 {
+		http.Error(w, "repos match no repository", http.StatusBadRequest)
+		return
+	}
+	if len(repos) > m.config.MaxRepos {
+		http.Error(w, fmt.Sprintf("repos match %d repositories, more than the %d allowed", len(repos),
+			m.config.MaxRepos), http.StatusBadRequest)
+		return
+	}
+	posts, err := renderAnnouncement(req, repos)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	for i := range posts {
+		if reason := m.skipReason(ctx, posts[i].Repo, req.DryRun); reason != "" {
+			posts[i].Status, posts[i].Error = PostSkipped, reason
+		}
+	}
+
+	if req.DryRun {
+		previews := make([]announcementPreview, len(posts))
+		for i, p := range posts {
+			previews[i] = announcementPreview{
+				Repo: p.Repo, Title: p.Title, Body: p.Body, Status: p.Status, Reason: p.Error,
+			}
+		}
+		writeJSON(w, previews)
+		return
+	}
+	a := Announcement{
+		Title:     req.Title,
+		Body:      req.Body,
+		Kind:      req.Kind,
+		Category:  req.Category,
+		Pin:       req.Pin,
+		Labels:    req.Labels,
+		Vars:      req.Vars,
+		Status:    AnnouncementPosting,
+		CreatedAt: m.now(),
+	}
+	if id, ok := internal.AdminUser(ctx); ok {
+		a.CreatedBy = cmp.Or(id.Login, id.Method)
+	}
+	if err := CreateAnnouncement(m.database.DB(), &a, posts); err != nil {
+		slog.Error("Failed to store announcement", "title", req.Title, "error", err)
+		http.Error(w, "failed to store announcement", http.StatusInternalServerError)
+		return
+	}
+	slog.Info("Announcement queued", "id", a.ID, "title", a.Title, "repos", len(posts), "created_by", a.CreatedBy)
+	created, err := GetAnnouncement(m.database.DB(), a.ID)
+	if err != nil || created == nil {
+		http.Error(w, "failed to load announcement", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Location", "/admin/announcements/"+strconv.FormatInt(a.ID, 10))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	if err := json.NewEncoder(w).Encode(created); err != nil {
+		slog.Error("Failed to write JSON response", "error", err)
+	}
+}
+
+// validateRequest checks an announcement request, defaulting its kind and category.
+func (m *AnnouncementsModule) validateRequest(req *announcementRequest) error {
+	if strings.TrimSpace(req.Title) == "" || strings.TrimSpace(req.Body) == "" {
+		return errors.New("title and body are required")
+	}
+	if len(req.Repos) == 0 {
+		return errors.New("repos are required, e.g. [\"@collector\"]")
+	}
+	switch req.Kind {
+	case "", AnnouncementIssue:
+		req.Kind = AnnouncementIssue
+		req.Category = ""
+	case AnnouncementDiscussion:
+		if req.Pin || len(req.Labels) > 0 {
+			return errors.New("pin and labels only apply to issues: GitHub's API cannot pin or label discussions")
+		}
+		if req.Category == "" {
+			req.Category = m.config.Category
+		}
+	default:
+		return fmt.Errorf("kind must be %q or %q, got %q", AnnouncementIssue, AnnouncementDiscussion, req.Kind)
+	}
+	return nil
+}
+
+// renderAnnouncement executes the title and body templates of req for each repository.
+func renderAnnouncement(req announcementRequest, repos []string) ([]AnnouncementPost, error) {
+	title, err := template.New("title").Option("missingkey=error").Parse(req.Title)
+	if err != nil {
+		return nil, fmt.Errorf("invalid title template: %w", err)
+	}
+	body, err := template.New("body").Option("missingkey=error").Parse(req.Body)
+	if err != nil {
+		return nil, fmt.Errorf("invalid body template: %w", err)
+	}
+	posts := make([]AnnouncementPost, 0, len(repos))
+	for _, repo := range repos {
+		owner, name, err := internal.SplitRepo(repo)
+		if err != nil {
+			return nil, err
+		}
+		data := announcementData{Repo: repo, Owner: owner, Name: name, Vars: req.Vars}
+		var t, b strings.Builder
+		if err := title.Execute(&t, data); err != nil {
+			return nil, fmt.Errorf("failed to render the title for %s: %w", repo, err)
+		}
+		if err := body.Execute(&b, data); err != nil {
+			return nil, fmt.Errorf("failed to render the body for %s: %w", repo, err)
+		}
+		posts = append(posts, AnnouncementPost{
+			Repo: repo, Title: strings.TrimSpace(t.String()), Body: b.String(), Status: PostPending,
+		})
+	}
+	return posts, nil
+}
+
+// skipReason returns why repo gets no announcement, or "" if it does. Opt-outs are only
+// looked up when checkOptOut is set, as they are checked again when posting.
+func (m *AnnouncementsModule) skipReason(ctx context.Context, repo string, checkOptOut bool) string {
+	if len(m.config.Exclude) > 0 && repoGroups(m.app).Match(m.config.Exclude, repo) {
+		return "excluded"
+	}
+	if !checkOptOut {
+		return ""
+	}
+	cfg := m.config
+	if err := loadRepoModuleConfig(ctx, m.app, repo, m.Name(), &cfg); err != nil {
+		slog.Warn("Failed to load repository config, assuming no opt-out", "repo", repo, "error", err)
+		return ""
+	}
+	if cfg.OptOut {
+		return "opted out"
+	}
+	return ""
+}
+
+func (m *AnnouncementsModule) handleList(w http.ResponseWriter, r *http.Request) {
+	announcements, err := ListAnnouncements(m.database.DB())
+	if err != nil {
+		slog.Error("Failed to list announcements", "error", err)
+		http.Error(w, "failed to list announcements", http.StatusInternalServerError)
+		return
+	}
+	if announcements == nil {
+		announcements = []Announcement{}
+	}
+	writeJSON(w, announcements)
+}
+
+// handleGet returns an announcement with the progress of its post in each repository.
+func (m *AnnouncementsModule) handleGet(w http.ResponseWriter, r *http.Request) {
+	a, ok := m.announcement(w, r)
+	if ok {
+		writeJSON(w, a)
+	}
+}
+
+// handleRollback closes every post of an announcement, in the background, and stops
+// posting the rest.
+func (m *AnnouncementsModule) handleRollback(w http.ResponseWriter, r *http.Request) {
+	a, ok := m.announcement(w, r)
+	if !ok {
+		return
+	}
+	moved, err := SetAnnouncementStatus(m.database.DB(), a.ID, AnnouncementRollingBack, m.now(),
+		AnnouncementPosting, AnnouncementDone)
+	if err != nil {
+		slog.Error("Failed to roll back announcement", "id", a.ID, "error", err)
+		http.Error(w, "failed to roll back announcement", http.StatusInternalServerError)
+		return
+	}
+	if !moved {
+		http.Error(w, fmt.Sprintf("announcement is already %s", a.Status), http.StatusConflict)
+		return
+	}
+	rolledBackBy := ""
+	if id, ok := internal.AdminUser(r.Context()); ok {
+		rolledBackBy = cmp.Or(id.Login, id.Method)
+	}
+	slog.Info("Announcement rollback requested", "id", a.ID, "title", a.Title, "requested_by", rolledBackBy)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// announcement loads the announcement of the request's {id}, answering the request if
+// it cannot.
+func (m *AnnouncementsModule) announcement(w http.ResponseWriter, r *http.Request) (*Announcement, bool) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "no such announcement", http.StatusNotFound)
+		return nil, false
+	}
+	a, err := GetAnnouncement(m.database.DB(), id)
+	if err != nil {
+		slog.Error("Failed to load announcement", "id", id, "error", err)
+		http.Error(w, "failed to load announcement", http.StatusInternalServerError)
+		return nil, false
+	}
+	if a == nil {
+		http.Error(w, "no such announcement", http.StatusNotFound)
+		return nil, false
+	}
+	return a, true
+}
+
+// ProcessAnnouncements posts the pending posts of the announcements being posted, and
+// closes the posts of those being rolled back.
+func (m *AnnouncementsModule) ProcessAnnouncements(ctx context.Context) error {
+	if m.app == nil || m.app.GitHubClient == nil {
+		return nil
+	}
+	announcements, err := ListAnnouncements(m.database.DB(), AnnouncementPosting, AnnouncementRollingBack)
+	if err != nil {
+		return m.wrap(err, "list_announcements", 0)
+	}
+	// Oldest first, so announcements go out in the order they were made.
+	slices.Reverse(announcements)
+	for _, a := range announcements {
+		if a.Status == AnnouncementPosting {
+			err = m.post(ctx, a)
+		} else {
+			err = m.rollBack(ctx, a)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// post posts the pending posts of an announcement, stopping if it is rolled back
+// meanwhile, and marks it done once none are pending.
+func (m *AnnouncementsModule) post(ctx context.Context, a Announcement) error {
+	db := m.database.DB()
+	posts, err := ListAnnouncementPosts(db, a.ID, PostPending)
+	if err != nil {
+		return m.wrap(err, "list_announcement_posts", a.ID)
+	}
+	for i, p := range posts {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(m.config.Delay):
+			}
+		}
+		if err := m.app.WaitIfLimited(ctx); err != nil {
+			return err
+		}
+		status, err := AnnouncementStatus(db, a.ID)
+		if err != nil {
+			return m.wrap(err, "get_announcement_status", a.ID)
+		}
+		if status != AnnouncementPosting {
+			return nil
+		}
+
+		if reason := m.skipReason(ctx, p.Repo, true); reason != "" {
+			p.Status, p.Error = PostSkipped, reason
+		} else if err := m.publish(ctx, a, &p); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			slog.Error("Failed to post announcement", "id", a.ID, "repo", p.Repo, "error", err)
+			p.Status, p.Error = PostFailed, err.Error()
+		}
+		if err := UpdateAnnouncementPost(db, p, m.now()); err != nil {
+			return m.wrap(err, "update_announcement_post", a.ID)
+		}
+	}
+	if _, err := SetAnnouncementStatus(db, a.ID, AnnouncementDone, m.now(), AnnouncementPosting); err != nil {
+		return m.wrap(err, "finish_announcement", a.ID)
+	}
+	slog.Info("Announcement posted", "id", a.ID, "title", a.Title)
+	return nil
+}
+
+// publish creates the issue or discussion of a post and records it in p. A pin that
+// fails leaves the issue posted, with the error noted.
+func (m *AnnouncementsModule) publish(ctx context.Context, a Announcement, p *AnnouncementPost) error {
+	client := m.app.GitHubClient
+	if a.Kind == AnnouncementDiscussion {
+		d, err := createDiscussion(ctx, client, p.Repo, a.Category, p.Title, p.Body)
+		if err != nil {
+			return err
+		}
+		p.Status, p.Number, p.NodeID, p.URL = PostPosted, d.Number, d.ID, d.URL
+		return nil
+	}
+
+	owner, name, err := internal.SplitRepo(p.Repo)
+	if err != nil {
+		return err
+	}
+	req := &github.IssueRequest{Title: github.Ptr(p.Title), Body: github.Ptr(p.Body)}
+	if len(a.Labels) > 0 {
+		req.Labels = &a.Labels
+	}
+	issue, _, err := client.Issues.Create(ctx, owner, name, req)
+	if err != nil {
+		return err
+	}
+	p.Status, p.Number, p.NodeID, p.URL = PostPosted, issue.GetNumber(), issue.GetNodeID(), issue.GetHTMLURL()
+	if a.Pin {
+		if err := pinIssue(ctx, client, p.NodeID); err != nil {
+			slog.Warn("Failed to pin announcement", "id", a.ID, "repo", p.Repo, "issue", p.Number, "error", err)
+			p.Error = "failed to pin: " + err.Error()
+		}
+	}
+	return nil
+}
+
+// rollBack closes the posts of an announcement and skips those not posted yet. Posts
+// that fail to close are retried on the next run.
+func (m *AnnouncementsModule) rollBack(ctx context.Context, a Announcement) error {
+	db := m.database.DB()
+	if err := SkipPendingAnnouncementPosts(db, a.ID, "rolled back", m.now()); err != nil {
+		return m.wrap(err, "skip_announcement_posts", a.ID)
+	}
+	posts, err := ListAnnouncementPosts(db, a.ID, PostPosted)
+	if err != nil {
+		return m.wrap(err, "list_announcement_posts", a.ID)
+	}
+	failed := 0
+	for i, p := range posts {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(m.config.Delay):
+			}
+		}
+		if err := m.app.WaitIfLimited(ctx); err != nil {
+			return err
+		}
+		if err := m.closePost(ctx, a, p); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			slog.Error("Failed to close announcement", "id", a.ID, "repo", p.Repo, "error", err)
+			p.Error = "failed to close: " + err.Error()
+			failed++
+		} else {
+			p.Status, p.Error = PostClosed, ""
+		}
+		if err := UpdateAnnouncementPost(db, p, m.now()); err != nil {
+			return m.wrap(err, "update_announcement_post", a.ID)
+		}
+	}
+	if failed > 0 {
+		return nil
+	}
+	if _, err := SetAnnouncementStatus(db, a.ID, AnnouncementRolledBack, m.now(), AnnouncementRollingBack); err != nil {
+		return m.wrap(err, "finish_announcement_rollback", a.ID)
+	}
+	slog.Info("Announcement rolled back", "id", a.ID, "title", a.Title, "closed", len(posts))
+	return nil
+}
+
+// closePost closes the issue of a post as not planned, or its discussion as outdated.
+func (m *AnnouncementsModule) closePost(ctx context.Context, a Announcement, p AnnouncementPost) error {
+	if a.Kind == AnnouncementDiscussion {
+		return closeDiscussion(ctx, m.app.GitHubClient, p.NodeID)
+	}
+	owner, name, err := internal.SplitRepo(p.Repo)
+	if err != nil {
+		return err
+	}
+	_, _, err = m.app.GitHubClient.Issues.Edit(ctx, owner, name, p.Number, &github.IssueRequest{
+		State:       github.Ptr("closed"),
+		StateReason: github.Ptr("not_planned"),
+	})
+	return err
+}
+
+func (m *AnnouncementsModule) wrap(err error, op string, id int64) error {
+	if err == nil {
+		return nil
+	}
+	return internal.LogAndWrapError(err, internal.ErrorTypeModule, op, map[string]any{
+		"module":          m.Name(),
+		"announcement_id": id,
+	})
+}
@@ -0,0 +1,301 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"strings"
+	"time"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+//go:embed migrations/announcements/*.sql
+var announcementMigrations embed.FS
+
+// Kinds of announcement.
+const (
+	AnnouncementIssue      = "issue"
+	AnnouncementDiscussion = "discussion"
+)
+
+// Statuses of an announcement.
+const (
+	AnnouncementPosting     = "posting"
+	AnnouncementDone        = "done"
+	AnnouncementRollingBack = "rolling_back"
+	AnnouncementRolledBack  = "rolled_back"
+)
+
+// Statuses of an announcement's post in one repository.
+const (
+	PostPending = "pending"
+	PostPosted  = "posted"
+	PostSkipped = "skipped" // excluded, opted out or rolled back before it was posted
+	PostFailed  = "failed"
+	PostClosed  = "closed" // posted, then closed by a rollback
+)
+
+// Announcement is an issue or discussion broadcast to a set of repositories.
+type Announcement struct {
+	ID        int64              `json:"id"`
+	Title     string             `json:"title"` // template
+	Body      string             `json:"body"`  // template
+	Kind      string             `json:"kind"`  // AnnouncementIssue or AnnouncementDiscussion
+	Category  string             `json:"category,omitempty"`
+	Pin       bool               `json:"pin,omitempty"`
+	Labels    []string           `json:"labels,omitempty"`
+	Vars      map[string]string  `json:"vars,omitempty"`
+	CreatedBy string             `json:"created_by,omitempty"`
+	Status    string             `json:"status"`
+	CreatedAt time.Time          `json:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at"`
+	Progress  map[string]int     `json:"progress,omitempty"` // post status -> posts
+	Posts     []AnnouncementPost `json:"posts,omitempty"`
+}
+
+// AnnouncementPost is an announcement rendered for one repository.
+type AnnouncementPost struct {
+	AnnouncementID int64     `json:"-"`
+	Repo           string    `json:"repo"`
+	Title          string    `json:"title"`
+	Body           string    `json:"-"`
+	Status         string    `json:"status"`
+	Number         int       `json:"number,omitempty"`
+	NodeID         string    `json:"-"` // GraphQL ID, to pin and close the post with
+	URL            string    `json:"url,omitempty"`
+	Error          string    `json:"error,omitempty"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+func AutoMigrateAnnouncements(db *sql.DB) error {
+	migrations, err := fs.Sub(announcementMigrations, "migrations/announcements")
+	if err != nil {
+		return err
+	}
+	return internal.VersionedMigrations("announcements", migrations)(db)
+}
+
+// Migrate implements the ModuleMigrator interface.
+func (m *AnnouncementsModule) Migrate(db *sql.DB) error {
+	return AutoMigrateAnnouncements(db)
+}
+
+// CreateAnnouncement stores an announcement with its posts and sets its ID.
+func CreateAnnouncement(db *sql.DB, a *Announcement, posts []AnnouncementPost) error {
+	labels, err := json.Marshal(a.Labels)
+	if err != nil {
+		return err
+	}
+	vars, err := json.Marshal(a.Vars)
+	if err != nil {
+		return err
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+	res, err := tx.Exec(
+		`INSERT INTO announcements (title, body, kind, category, pin, labels, vars, created_by, status, created_at,
+		 updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		a.Title, a.Body, a.Kind, a.Category, a.Pin, string(labels), string(vars), a.CreatedBy, a.Status,
+		a.CreatedAt.UTC(), a.CreatedAt.UTC(),
+	)
+	if err != nil {
+		return err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	for _, p := range posts {
+		_, err := tx.Exec(
+			`INSERT INTO announcement_posts (announcement_id, repo, title, body, status, error, updated_at)
+			 VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			id, p.Repo, p.Title, p.Body, p.Status, p.Error, a.CreatedAt.UTC(),
+		)
+		if err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	a.ID = id
+	a.UpdatedAt = a.CreatedAt
+	return nil
+}
+
+// ListAnnouncements returns the announcements, newest first, with their progress. With
+// statuses, only announcements in one of them are returned.
+func ListAnnouncements(db *sql.DB, statuses ...string) ([]Announcement, error) {
+	filter, args := statusFilter(statuses)
+	rows, err := db.Query(
+		`SELECT id, title, body, kind, category, pin, labels, vars, created_by, status, created_at, updated_at
+		 FROM announcements WHERE `+filter+` ORDER BY id DESC`, args...)
+	if err != nil {
+		return nil, err
+	}
+	var announcements []Announcement
+	for rows.Next() {
+		a, err := scanAnnouncement(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		announcements = append(announcements, a)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for i := range announcements {
+		if announcements[i].Progress, err = announcementProgress(db, announcements[i].ID); err != nil {
+			return nil, err
+		}
+	}
+	return announcements, nil
+}
+
+// GetAnnouncement returns an announcement with its progress and posts, or nil if there
+// is none with the ID.
+func GetAnnouncement(db *sql.DB, id int64) (*Announcement, error) {
+	a, err := scanAnnouncement(db.QueryRow(
+		`SELECT id, title, body, kind, category, pin, labels, vars, created_by, status, created_at, updated_at
+		 FROM announcements WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if a.Progress, err = announcementProgress(db, id); err != nil {
+		return nil, err
+	}
+	if a.Posts, err = ListAnnouncementPosts(db, id); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// AnnouncementStatus returns the status of an announcement.
+func AnnouncementStatus(db *sql.DB, id int64) (string, error) {
+	var status string
+	err := db.QueryRow(`SELECT status FROM announcements WHERE id = ?`, id).Scan(&status)
+	return status, err
+}
+
+func scanAnnouncement(row interface{ Scan(...any) error }) (Announcement, error) {
+	var a Announcement
+	var labels, vars string
+	err := row.Scan(&a.ID, &a.Title, &a.Body, &a.Kind, &a.Category, &a.Pin, &labels, &vars, &a.CreatedBy, &a.Status,
+		&a.CreatedAt, &a.UpdatedAt)
+	if err != nil {
+		return a, err
+	}
+	if err := json.Unmarshal([]byte(labels), &a.Labels); err != nil {
+		return a, err
+	}
+	return a, json.Unmarshal([]byte(vars), &a.Vars)
+}
+
+// announcementProgress counts the posts of an announcement by status.
+func announcementProgress(db *sql.DB, id int64) (map[string]int, error) {
+	rows, err := db.Query(`SELECT status, COUNT(*) FROM announcement_posts WHERE announcement_id = ? GROUP BY status`,
+		id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	progress := map[string]int{}
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, err
+		}
+		progress[status] = count
+	}
+	return progress, rows.Err()
+}
+
+// ListAnnouncementPosts returns the posts of an announcement, by repository. With
+// statuses, only posts in one of them are returned.
+func ListAnnouncementPosts(db *sql.DB, id int64, statuses ...string) ([]AnnouncementPost, error) {
+	filter, args := statusFilter(statuses)
+	rows, err := db.Query(
+		`SELECT announcement_id, repo, title, body, status, number, node_id, url, error, updated_at
+		 FROM announcement_posts WHERE announcement_id = ? AND `+filter+` ORDER BY repo ASC`,
+		append([]any{id}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var posts []AnnouncementPost
+	for rows.Next() {
+		var p AnnouncementPost
+		if err := rows.Scan(&p.AnnouncementID, &p.Repo, &p.Title, &p.Body, &p.Status, &p.Number, &p.NodeID, &p.URL,
+			&p.Error, &p.UpdatedAt); err != nil {
+			return nil, err
+		}
+		posts = append(posts, p)
+	}
+	return posts, rows.Err()
+}
+
+// UpdateAnnouncementPost stores the status, GitHub references and error of a post.
+func UpdateAnnouncementPost(db *sql.DB, p AnnouncementPost, now time.Time) error {
+	_, err := db.Exec(
+		`UPDATE announcement_posts SET status = ?, number = ?, node_id = ?, url = ?, error = ?, updated_at = ?
+		 WHERE announcement_id = ? AND repo = ?`,
+		p.Status, p.Number, p.NodeID, p.URL, p.Error, now.UTC(), p.AnnouncementID, p.Repo,
+	)
+	return err
+}
+
+// SkipPendingAnnouncementPosts marks the posts of an announcement not posted yet as skipped
+// for a reason.
+func SkipPendingAnnouncementPosts(db *sql.DB, id int64, reason string, now time.Time) error {
+	_, err := db.Exec(
+		`UPDATE announcement_posts SET status = ?, error = ?, updated_at = ? WHERE announcement_id = ? AND status = ?`,
+		PostSkipped, reason, now.UTC(), id, PostPending,
+	)
+	return err
+}
+
+// SetAnnouncementStatus moves an announcement to status if it is in one of from, and
+// reports whether it did.
+func SetAnnouncementStatus(db *sql.DB, id int64, status string, now time.Time, from ...string) (bool, error) {
+	filter, args := statusFilter(from)
+	res, err := db.Exec(`UPDATE announcements SET status = ?, updated_at = ? WHERE id = ? AND `+filter,
+		append([]any{status, now.UTC(), id}, args...)...)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// statusFilter returns the condition matching rows in one of statuses, and its arguments.
+// Without statuses, every row matches.
+func statusFilter(statuses []string) (string, []any) {
+	if len(statuses) == 0 {
+		return "1 = 1", nil
+	}
+	args := make([]any, len(statuses))
+	for i, status := range statuses {
+		args[i] = status
+	}
+	return "status IN (" + strings.TrimSuffix(strings.Repeat("?, ", len(statuses)), ", ") + ")", args
+}
+
+// RenameRepo implements the ModuleRepoRenamer interface.
+func (m *AnnouncementsModule) RenameRepo(ctx context.Context, from, to string) error {
+	return internal.RenameRepoIn(ctx, m.database.DB(), from, to, "announcement_posts.repo")
+}
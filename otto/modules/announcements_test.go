@@ -0,0 +1,188 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+)
+
+func newAnnouncementsTestModule(t *testing.T) (*AnnouncementsModule, *fakeGitHub) {
+	t.Helper()
+	fake := newFakeGitHub()
+	fake.setFile("org/c", fakeDefaultBranch, ".github/otto.yml", "modules:\n  announcements:\n    opt_out: true\n")
+	groups, err := internal.NewRepoGroups(map[string][]string{"sig": {"org/a", "org/b", "org/c", "org/d"}}, nil)
+	if err != nil {
+		t.Fatalf("NewRepoGroups failed: %v", err)
+	}
+	now := time.Date(2025, time.June, 2, 9, 0, 0, 0, time.UTC)
+	mod := &AnnouncementsModule{now: func() time.Time { return now }}
+	app := &internal.App{
+		Config: &config.AppConfig{Modules: map[string]any{
+			"announcements": map[string]any{"delay": "0s", "max_repos": 4, "exclude": []any{"org/d"}},
+		}},
+		GitHubClient: fake.client(t),
+		Database:     internal.NewDatabaseFromDB(internal.TestDB(t)),
+		RepoGroups:   groups,
+		Cache:        internal.NewMemoryCache(),
+	}
+	if err := mod.Initialize(t.Context(), app); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	return mod, fake
+}
+
+// announce sends a request to one of the module's admin handlers.
+func announce(t *testing.T, handler http.HandlerFunc, method, id, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	path := "/admin/announcements"
+	if id != "" {
+		path += "/" + id
+	}
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.SetPathValue("id", id)
+	rr := httptest.NewRecorder()
+	handler(rr, req)
+	return rr
+}
+
+func TestAnnouncementIssues(t *testing.T) {
+	mod, fake := newAnnouncementsTestModule(t)
+	request := `{"title": "SIG meeting moved ({{.Name}})", "body": "Meets {{.Vars.when}} from now on.",
+		"repos": ["@sig"], "pin": true, "labels": ["announcement"], "vars": {"when": "Tuesdays"}`
+
+	rr := announce(t, mod.handleCreate, http.MethodPost, "", request+`, "dry_run": true}`)
+	var previews []announcementPreview
+	if err := json.Unmarshal(rr.Body.Bytes(), &previews); err != nil || len(previews) != 4 {
+		t.Fatalf("dry run = %d %s", rr.Code, rr.Body)
+	}
+	if previews[0].Title != "SIG meeting moved (a)" || previews[0].Body != "Meets Tuesdays from now on." ||
+		previews[2].Reason != "opted out" || previews[3].Reason != "excluded" {
+		t.Errorf("previews = %+v", previews)
+	}
+	if stored, err := ListAnnouncements(mod.database.DB()); err != nil || stored != nil {
+		t.Errorf("announcements after a dry run = %+v, %v; want none", stored, err)
+	}
+
+	rr = announce(t, mod.handleCreate, http.MethodPost, "", request+"}")
+	if rr.Code != http.StatusAccepted || rr.Header().Get("Location") != "/admin/announcements/1" {
+		t.Fatalf("create = %d %s", rr.Code, rr.Body)
+	}
+	if err := mod.ProcessAnnouncements(t.Context()); err != nil {
+		t.Fatalf("ProcessAnnouncements failed: %v", err)
+	}
+	for _, repo := range []string{"org/a", "org/b"} {
+		opened := fake.openedIssues(repo)
+		if len(opened) != 1 || opened[0].GetTitle() != "SIG meeting moved ("+strings.TrimPrefix(repo, "org/")+")" ||
+			!slices.Equal(opened[0].GetLabels(), []string{"announcement"}) {
+			t.Errorf("issues opened in %s = %+v", repo, opened)
+		}
+	}
+	if len(fake.openedIssues("org/c")) != 0 || len(fake.openedIssues("org/d")) != 0 {
+		t.Error("announced to a repository that opted out or is excluded")
+	}
+	if !slices.Equal(fake.pinned, []string{"I_org/a#1001", "I_org/b#1001"}) {
+		t.Errorf("pinned = %q", fake.pinned)
+	}
+
+	rr = announce(t, mod.handleGet, http.MethodGet, "1", "")
+	var a Announcement
+	if err := json.Unmarshal(rr.Body.Bytes(), &a); err != nil {
+		t.Fatalf("get = %d %s", rr.Code, rr.Body)
+	}
+	if a.Status != AnnouncementDone || a.Progress[PostPosted] != 2 || a.Progress[PostSkipped] != 2 ||
+		a.Posts[0].URL != "https://github.com/org/a/issues/1001" {
+		t.Errorf("announcement = %+v", a)
+	}
+
+	// A rollback closes the issues, once.
+	if rr := announce(t, mod.handleRollback, http.MethodPost, "1", ""); rr.Code != http.StatusAccepted {
+		t.Fatalf("rollback = %d %s", rr.Code, rr.Body)
+	}
+	if rr := announce(t, mod.handleRollback, http.MethodPost, "1", ""); rr.Code != http.StatusConflict {
+		t.Errorf("second rollback = %d, want 409", rr.Code)
+	}
+	if err := mod.ProcessAnnouncements(t.Context()); err != nil {
+		t.Fatalf("ProcessAnnouncements failed: %v", err)
+	}
+	if fake.states["org/a#1001"] != "closed" || fake.states["org/b#1001"] != "closed" {
+		t.Errorf("states = %v, want the announcements closed", fake.states)
+	}
+	if a, _ := GetAnnouncement(mod.database.DB(), 1); a.Status != AnnouncementRolledBack ||
+		a.Progress[PostClosed] != 2 {
+		t.Errorf("announcement after rollback = %+v", a)
+	}
+	if rr := announce(t, mod.handleGet, http.MethodGet, "2", ""); rr.Code != http.StatusNotFound {
+		t.Errorf("unknown announcement = %d, want 404", rr.Code)
+	}
+}
+
+func TestAnnouncementDiscussions(t *testing.T) {
+	mod, fake := newAnnouncementsTestModule(t)
+	rr := announce(t, mod.handleCreate, http.MethodPost, "",
+		`{"title": "Release freeze", "body": "Freeze in {{.Repo}}.", "repos": ["org/a", "org/b"],
+		"kind": "discussion"}`)
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("create = %d %s", rr.Code, rr.Body)
+	}
+	// Rolling back before anything is posted skips every post.
+	rr = announce(t, mod.handleCreate, http.MethodPost, "",
+		`{"title": "Oops", "body": "Wrong.", "repos": ["org/a"], "kind": "discussion"}`)
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("create = %d %s", rr.Code, rr.Body)
+	}
+	if rr := announce(t, mod.handleRollback, http.MethodPost, "2", ""); rr.Code != http.StatusAccepted {
+		t.Fatalf("rollback = %d %s", rr.Code, rr.Body)
+	}
+	if err := mod.ProcessAnnouncements(t.Context()); err != nil {
+		t.Fatalf("ProcessAnnouncements failed: %v", err)
+	}
+
+	discussions := fake.createdDiscussions()
+	if len(discussions) != 2 || discussions[0]["categoryId"] != "DIC_announcements" ||
+		discussions[1]["body"] != "Freeze in org/b." {
+		t.Fatalf("discussions = %v", discussions)
+	}
+	if a, _ := GetAnnouncement(mod.database.DB(), 2); a.Status != AnnouncementRolledBack ||
+		a.Progress[PostSkipped] != 1 {
+		t.Errorf("rolled back announcement = %+v", a)
+	}
+
+	if rr := announce(t, mod.handleRollback, http.MethodPost, "1", ""); rr.Code != http.StatusAccepted {
+		t.Fatalf("rollback = %d %s", rr.Code, rr.Body)
+	}
+	if err := mod.ProcessAnnouncements(t.Context()); err != nil {
+		t.Fatalf("ProcessAnnouncements failed: %v", err)
+	}
+	if !slices.Equal(fake.closed, []string{"D_org/a#1", "D_org/b#2"}) {
+		t.Errorf("closed discussions = %q", fake.closed)
+	}
+}
+
+func TestAnnouncementRequestValidation(t *testing.T) {
+	mod, _ := newAnnouncementsTestModule(t)
+	for _, tc := range []struct{ body, want string }{
+		{`{"title": "T", "body": "B"}`, "repos are required"},
+		{`{"title": "", "body": "B", "repos": ["org/a"]}`, "title and body are required"},
+		{`{"title": "T", "body": "B", "repos": ["org/a"], "kind": "post"}`, "kind must be"},
+		{`{"title": "T", "body": "B", "repos": ["org/a"], "kind": "discussion", "pin": true}`, "only apply to issues"},
+		{`{"title": "T", "body": "B", "repos": ["@nope"]}`, `unknown repo group "nope"`},
+		{`{"title": "T", "body": "B", "repos": ["@sig", "org/e"]}`, "more than the 4 allowed"},
+		{`{"title": "T", "body": "{{.Vars.missing}}", "repos": ["org/a"]}`, "failed to render the body"},
+		{`{"title": "{{", "body": "B", "repos": ["org/a"]}`, "invalid title template"},
+		{`{"title": "T", "body": "B", "repos": ["org/a"], "draft": true}`, "unknown field"},
+	} {
+		rr := announce(t, mod.handleCreate, http.MethodPost, "", tc.body)
+		if rr.Code != http.StatusBadRequest || !strings.Contains(rr.Body.String(), tc.want) {
+			t.Errorf("%s: %d %q, want 400 with %q", tc.body, rr.Code, rr.Body, tc.want)
+		}
+	}
+}
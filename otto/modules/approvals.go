@@ -0,0 +1,295 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v71/github"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+// ApprovalConfig configures `/approve` and `/lgtm`.
+type ApprovalConfig struct {
+	Approvers     []string `yaml:"approvers" doc:"logins or org/team that may /approve; default: maintainers"`
+	Reviewers     []string `yaml:"reviewers" doc:"logins or org/team that may /lgtm; approvers always may"`
+	ApprovedLabel string   `yaml:"approved_label" doc:"applied while a pull request has an /approve"`
+	LGTMLabel     string   `yaml:"lgtm_label" doc:"applied while a pull request has an /lgtm"`
+	ResetOnPush   []string `yaml:"reset_on_push" doc:"kinds (approve, lgtm) withdrawn when commits are pushed"`
+}
+
+// ApprovalModule tracks `/approve` and `/lgtm` from authorized reviewers on pull requests,
+// labels approved pull requests, and withdraws approvals when new commits are pushed.
+// `/approve cancel` and `/lgtm cancel` withdraw one's own approval. As a MergeGate it
+// blocks pull requests until they are both approved and LGTM'd.
+type ApprovalModule struct {
+	app      *internal.App
+	database *internal.Database
+	config   ApprovalConfig
+	now      func() time.Time
+}
+
+func (m *ApprovalModule) Name() string { return "approvals" }
+
+// ConfigSchema implements the ModuleConfigSchema interface.
+func (m *ApprovalModule) ConfigSchema() any {
+	c := defaultApprovalConfig()
+	return &c
+}
+
+// defaultApprovalConfig returns the approvals module's defaults.
+func defaultApprovalConfig() ApprovalConfig {
+	return ApprovalConfig{
+		ApprovedLabel: "approved",
+		LGTMLabel:     "lgtm",
+		ResetOnPush:   []string{ApprovalLGTM},
+	}
+}
+
+// EventSubscriptions implements the ModuleEventSubscriber interface.
+func (m *ApprovalModule) EventSubscriptions() []internal.EventSubscription {
+	return []internal.EventSubscription{
+		internal.Subscribe("pull_request", "synchronize"),
+	}
+}
+
+// GitHubPermissions implements the ModulePermissionRequirer interface.
+func (m *ApprovalModule) GitHubPermissions() map[string]string {
+	return map[string]string{"issues": "write", "pull_requests": "read"}
+}
+
+// SlashCommands implements the ModuleCommander interface.
+func (m *ApprovalModule) SlashCommands() []string {
+	return []string{ApprovalApprove, ApprovalLGTM}
+}
+
+// Initialize implements the ModuleInitializer interface.
+func (m *ApprovalModule) Initialize(ctx context.Context, app *internal.App) error {
+	m.app = app
+	m.database = app.Database
+	if m.now == nil {
+		m.now = time.Now
+	}
+	m.config = defaultApprovalConfig()
+	if err := loadModuleConfig(app, m.Name(), &m.config); err != nil {
+		return err
+	}
+	for _, kind := range m.config.ResetOnPush {
+		if kind != ApprovalApprove && kind != ApprovalLGTM {
+			return fmt.Errorf("approvals: reset_on_push must list approve or lgtm, got %q", kind)
+		}
+	}
+	return AutoMigrateApprovals(m.database.DB())
+}
+
+func (m *ApprovalModule) HandleEvent(eventType string, event any, raw json.RawMessage) error {
+	ctx := context.Background()
+	switch eventType {
+	case "pull_request":
+		prEvent, ok := event.(*github.PullRequestEvent)
+		if !ok || prEvent.GetAction() != "synchronize" {
+			return nil
+		}
+		return m.handlePush(ctx, prEvent)
+	}
+	return nil
+}
+
+// HandleCommand implements the ModuleCommandHandler interface.
+func (m *ApprovalModule) HandleCommand(cmd *internal.CommandContext) error {
+	return m.handleCommand(cmd.Context, cmd.Event, internal.SlashCommand{Name: cmd.Command, Args: cmd.Args})
+}
+
+// handleCommand records or withdraws an `/approve` or `/lgtm`.
+func (m *ApprovalModule) handleCommand(ctx context.Context, event *github.IssueCommentEvent,
+	cmd internal.SlashCommand) error {
+	repo := event.GetRepo().GetFullName()
+	num := event.GetIssue().GetNumber()
+	login := event.GetComment().GetUser().GetLogin()
+	op := "record_" + cmd.Name
+	if !event.GetIssue().IsPullRequest() {
+		return m.wrap(m.comment(ctx, repo, num, fmt.Sprintf("⚠️ `/%s` only works on pull requests.", cmd.Name)),
+			op, repo, num)
+	}
+	cancel := len(cmd.Args) > 0 && strings.EqualFold(cmd.Args[0], "cancel")
+
+	if !cancel {
+		allowed, err := m.authorized(ctx, cmd.Name, login, event.GetComment().GetAuthorAssociation())
+		if err != nil {
+			return m.wrap(err, op, repo, num)
+		}
+		var msg string
+		switch {
+		case !allowed && cmd.Name == ApprovalApprove:
+			msg = fmt.Sprintf("⚠️ @%s only approvers can use `/approve`.", login)
+		case !allowed:
+			msg = fmt.Sprintf("⚠️ @%s only reviewers can use `/lgtm`.", login)
+		case cmd.Name == ApprovalLGTM && strings.EqualFold(login, event.GetIssue().GetUser().GetLogin()):
+			msg = fmt.Sprintf("⚠️ @%s you cannot `/lgtm` your own pull request.", login)
+		}
+		if msg != "" {
+			return m.wrap(m.comment(ctx, repo, num, msg), op, repo, num)
+		}
+	}
+
+	db := m.database.DB()
+	var err error
+	if cancel {
+		err = DeleteApproval(db, repo, num, cmd.Name, strings.ToLower(login))
+	} else {
+		err = RecordApproval(db, repo, num, cmd.Name, strings.ToLower(login), m.now())
+	}
+	if err != nil {
+		return internal.LogAndWrapError(err, internal.ErrorTypeDatabase, op, map[string]any{
+			"module": m.Name(),
+			"repo":   repo,
+			"issue":  num,
+		})
+	}
+	return m.wrap(m.syncLabels(ctx, repo, num), "sync_labels", repo, num)
+}
+
+// handlePush withdraws the approvals in ResetOnPush when commits are pushed to a pull request.
+func (m *ApprovalModule) handlePush(ctx context.Context, event *github.PullRequestEvent) error {
+	repo := event.GetRepo().GetFullName()
+	num := event.GetPullRequest().GetNumber()
+	var withdrawn []string
+	for _, kind := range m.config.ResetOnPush {
+		n, err := ResetApprovals(m.database.DB(), repo, num, kind)
+		if err != nil {
+			return internal.LogAndWrapError(err, internal.ErrorTypeDatabase, "reset_approvals", map[string]any{
+				"module": m.Name(),
+				"repo":   repo,
+				"issue":  num,
+			})
+		}
+		if n > 0 {
+			withdrawn = append(withdrawn, "`/"+kind+"`")
+		}
+	}
+	if len(withdrawn) == 0 {
+		return nil
+	}
+	if err := m.syncLabels(ctx, repo, num); err != nil {
+		return m.wrap(err, "sync_labels", repo, num)
+	}
+	msg := fmt.Sprintf("New commits were pushed, so %s was withdrawn. Please take another look.",
+		strings.Join(withdrawn, " and "))
+	return m.wrap(m.comment(ctx, repo, num, msg), "reset_approvals", repo, num)
+}
+
+// MergeBlockers implements MergeGate: a pull request needs an `/approve` and an `/lgtm`.
+func (m *ApprovalModule) MergeBlockers(ctx context.Context, repo string, number int) ([]string, error) {
+	approvals, err := GetApprovals(m.database.DB(), repo, number)
+	if err != nil {
+		return nil, err
+	}
+	var blockers []string
+	if !slices.ContainsFunc(approvals, func(a Approval) bool { return a.Kind == ApprovalApprove }) {
+		blockers = append(blockers, "not approved: needs `/approve` from an approver")
+	}
+	if !slices.ContainsFunc(approvals, func(a Approval) bool { return a.Kind == ApprovalLGTM }) {
+		blockers = append(blockers, "not reviewed: needs `/lgtm` from a reviewer")
+	}
+	return blockers, nil
+}
+
+// authorized reports whether login may give an approval of kind. Approvers may also /lgtm.
+// Without configured approvers, maintainers (by author association) are approvers.
+func (m *ApprovalModule) authorized(ctx context.Context, kind, login, association string) (bool, error) {
+	if len(m.config.Approvers) == 0 {
+		if maintainerAssociations[association] {
+			return true, nil
+		}
+	} else if ok, err := loginListed(ctx, m.app, m.config.Approvers, login); ok || err != nil {
+		return ok, err
+	}
+	if kind != ApprovalLGTM {
+		return false, nil
+	}
+	return loginListed(ctx, m.app, m.config.Reviewers, login)
+}
+
+// loginListed reports whether login is in a list of logins and org/team names. Teams are
+// skipped without a GitHub client.
+func loginListed(ctx context.Context, app *internal.App, list []string, login string) (bool, error) {
+	for _, entry := range list {
+		entry = strings.TrimPrefix(strings.TrimSpace(entry), "@")
+		org, slug, isTeam := strings.Cut(entry, "/")
+		if !isTeam {
+			if strings.EqualFold(entry, login) {
+				return true, nil
+			}
+			continue
+		}
+		if app == nil || app.GitHubClient == nil {
+			continue
+		}
+		members, err := teamMembers(ctx, app, org, slug)
+		if err != nil {
+			return false, err
+		}
+		if slices.ContainsFunc(members, func(member string) bool { return strings.EqualFold(member, login) }) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// syncLabels applies the approved and lgtm labels to match the recorded approvals.
+func (m *ApprovalModule) syncLabels(ctx context.Context, repo string, num int) error {
+	approvals, err := GetApprovals(m.database.DB(), repo, num)
+	if err != nil {
+		return err
+	}
+	for kind, label := range map[string]string{ApprovalApprove: m.config.ApprovedLabel, ApprovalLGTM: m.config.LGTMLabel} {
+		if label == "" {
+			continue
+		}
+		has := slices.ContainsFunc(approvals, func(a Approval) bool { return a.Kind == kind })
+		if err := m.setLabel(ctx, repo, num, label, has); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setLabel adds or removes a label on an issue.
+func (m *ApprovalModule) setLabel(ctx context.Context, repo string, num int, label string, on bool) error {
+	if m.app == nil || m.app.GitHubClient == nil {
+		slog.Info("GitHub label would be changed (no GitHub client available)",
+			"repo", repo, "issue_num", num, "label", label, "applied", on)
+		return nil
+	}
+	if on {
+		return m.app.AddLabels(ctx, m.Name(), repo, num, []string{label})
+	}
+	return m.app.RemoveLabels(ctx, m.Name(), repo, num, []string{label})
+}
+
+func (m *ApprovalModule) comment(ctx context.Context, repo string, num int, body string) error {
+	if m.app == nil || m.app.GitHubClient == nil {
+		slog.Info("GitHub comment would be posted (no GitHub client available)",
+			"repo", repo, "issue_num", num, "message", body)
+		return nil
+	}
+	return m.app.PostComment(ctx, m.Name(), repo, num, body)
+}
+
+func (m *ApprovalModule) wrap(err error, op, repo string, num int) error {
+	if err == nil {
+		return nil
+	}
+	return internal.LogAndWrapError(err, internal.ErrorTypeModule, op, map[string]any{
+		"module": m.Name(),
+		"repo":   repo,
+		"issue":  num,
+	})
+}
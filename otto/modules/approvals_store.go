@@ -0,0 +1,100 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+// Approval kinds, named after the commands that give them.
+const (
+	ApprovalApprove = "approve"
+	ApprovalLGTM    = "lgtm"
+)
+
+// Approval is an `/approve` or `/lgtm` given on a pull request.
+type Approval struct {
+	Kind  string
+	Login string
+	At    time.Time
+}
+
+func AutoMigrateApprovals(db *sql.DB) error {
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS pr_approvals (
+			repo TEXT NOT NULL,
+			number INTEGER NOT NULL,
+			kind TEXT NOT NULL,
+			login TEXT NOT NULL,
+			approved_at TIMESTAMP NOT NULL,
+			PRIMARY KEY (repo, number, kind, login)
+		);`,
+	}
+	for _, s := range stmts {
+		if _, err := db.Exec(s); err != nil {
+			return fmt.Errorf("failed migration: %w (SQL: %s)", err, s)
+		}
+	}
+	return nil
+}
+
+// Migrate implements the ModuleMigrator interface.
+func (m *ApprovalModule) Migrate(db *sql.DB) error {
+	return AutoMigrateApprovals(db)
+}
+
+// RecordApproval records an approval of a kind by login, keeping the time of the first one.
+func RecordApproval(db *sql.DB, repo string, number int, kind, login string, at time.Time) error {
+	_, err := db.Exec(
+		`INSERT INTO pr_approvals (repo, number, kind, login, approved_at) VALUES (?, ?, ?, ?, ?)
+		 ON CONFLICT (repo, number, kind, login) DO NOTHING`,
+		repo, number, kind, login, at,
+	)
+	return err
+}
+
+// DeleteApproval withdraws login's approval of a kind.
+func DeleteApproval(db *sql.DB, repo string, number int, kind, login string) error {
+	_, err := db.Exec(`DELETE FROM pr_approvals WHERE repo = ? AND number = ? AND kind = ? AND login = ?`,
+		repo, number, kind, login)
+	return err
+}
+
+// ResetApprovals withdraws all approvals of a kind and returns how many there were.
+func ResetApprovals(db *sql.DB, repo string, number int, kind string) (int64, error) {
+	res, err := db.Exec(`DELETE FROM pr_approvals WHERE repo = ? AND number = ? AND kind = ?`, repo, number, kind)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// GetApprovals returns the approvals of a pull request, oldest first.
+func GetApprovals(db *sql.DB, repo string, number int) ([]Approval, error) {
+	rows, err := db.Query(
+		`SELECT kind, login, approved_at FROM pr_approvals WHERE repo = ? AND number = ?
+		 ORDER BY approved_at, login`, repo, number)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var approvals []Approval
+	for rows.Next() {
+		var a Approval
+		if err := rows.Scan(&a.Kind, &a.Login, &a.At); err != nil {
+			return nil, err
+		}
+		approvals = append(approvals, a)
+	}
+	return approvals, rows.Err()
+}
+
+// RenameRepo implements the ModuleRepoRenamer interface.
+func (m *ApprovalModule) RenameRepo(ctx context.Context, from, to string) error {
+	return internal.RenameRepoIn(ctx, m.database.DB(), from, to, "pr_approvals.repo")
+}
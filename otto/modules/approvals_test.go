@@ -0,0 +1,194 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v71/github"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+func newApprovalTestModule(t *testing.T, fake *fakeGitHub) *ApprovalModule {
+	t.Helper()
+	db := internal.TestDB(t)
+	if err := AutoMigrateApprovals(db); err != nil {
+		t.Fatalf("AutoMigrateApprovals failed: %v", err)
+	}
+	return &ApprovalModule{
+		app:      &internal.App{GitHubClient: fake.client(t)},
+		database: internal.NewDatabaseFromDB(db),
+		config: ApprovalConfig{
+			Approvers:     []string{"alice", "@org/approvers"},
+			Reviewers:     []string{"org/reviewers"},
+			ApprovedLabel: "approved",
+			LGTMLabel:     "lgtm",
+			ResetOnPush:   []string{ApprovalLGTM},
+		},
+		now: func() time.Time { return time.Date(2025, time.June, 2, 9, 0, 0, 0, time.UTC) },
+	}
+}
+
+// prCommentEvent is a comment on pull request number, opened by "author".
+func prCommentEvent(repo string, number int, user, body string) *github.IssueCommentEvent {
+	event := commentEvent(repo, number, user, body)
+	event.Issue.User = &github.User{Login: github.Ptr("author")}
+	event.Issue.PullRequestLinks = &github.PullRequestLinks{URL: github.Ptr("https://api.github.com/pulls/1")}
+	return event
+}
+
+func TestApprovalCommands(t *testing.T) {
+	tests := []struct {
+		name        string
+		comments    [][2]string // user, body
+		wantLabels  []string
+		wantComment string
+	}{
+		{
+			name:       "approver approves",
+			comments:   [][2]string{{"alice", "/approve"}},
+			wantLabels: []string{"approved"},
+		},
+		{
+			name:       "team approver approves and reviews",
+			comments:   [][2]string{{"bob", "/approve\n/lgtm"}},
+			wantLabels: []string{"approved", "lgtm"},
+		},
+		{
+			name:       "reviewer lgtm",
+			comments:   [][2]string{{"carol", "/LGTM"}},
+			wantLabels: []string{"lgtm"},
+		},
+		{
+			name:        "reviewer cannot approve",
+			comments:    [][2]string{{"carol", "/approve"}},
+			wantComment: "only approvers can use `/approve`",
+		},
+		{
+			name:        "outsider cannot lgtm",
+			comments:    [][2]string{{"mallory", "/lgtm"}},
+			wantComment: "only reviewers can use `/lgtm`",
+		},
+		{
+			name:        "author cannot lgtm",
+			comments:    [][2]string{{"author", "/lgtm"}},
+			wantComment: "cannot `/lgtm` your own pull request",
+		},
+		{
+			name:       "cancel withdraws own approval only",
+			comments:   [][2]string{{"alice", "/approve"}, {"bob", "/approve"}, {"alice", "/approve cancel"}},
+			wantLabels: []string{"approved"},
+		},
+		{
+			name:       "last cancel removes label",
+			comments:   [][2]string{{"carol", "/lgtm"}, {"carol", "/lgtm cancel"}},
+			wantLabels: []string{},
+		},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeGitHub()
+			fake.setTeam("org/approvers", "bob")
+			fake.setTeam("org/reviewers", "carol", "author")
+			mod := newApprovalTestModule(t, fake)
+			for _, c := range tt.comments {
+				if err := internal.TestCommands(t, mod, prCommentEvent("org/repo", i+1, c[0], c[1])); err != nil {
+					t.Fatalf("HandleCommand failed: %v", err)
+				}
+			}
+			labels := fake.labelsOn("org/repo", i+1)
+			slices.Sort(labels)
+			if tt.wantLabels != nil && !slices.Equal(labels, tt.wantLabels) {
+				t.Errorf("labels = %v, want %v", labels, tt.wantLabels)
+			}
+			comments := fake.commentsOn("org/repo", i+1)
+			switch {
+			case tt.wantComment == "" && len(comments) > 0:
+				t.Errorf("unexpected comments %v", comments)
+			case tt.wantComment != "" && (len(comments) != 1 || !strings.Contains(comments[0], tt.wantComment)):
+				t.Errorf("comments = %v, want one containing %q", comments, tt.wantComment)
+			}
+		})
+	}
+}
+
+func TestApprovalIssueComment(t *testing.T) {
+	fake := newFakeGitHub()
+	mod := newApprovalTestModule(t, fake)
+	if err := internal.TestCommands(t, mod, commentEvent("org/repo", 1, "alice", "/approve")); err != nil {
+		t.Fatalf("HandleCommand failed: %v", err)
+	}
+	if labels := fake.labelsOn("org/repo", 1); len(labels) != 0 {
+		t.Errorf("issue was labeled %v", labels)
+	}
+	if comments := fake.commentsOn("org/repo", 1); len(comments) != 1 ||
+		!strings.Contains(comments[0], "only works on pull requests") {
+		t.Errorf("comments = %v", comments)
+	}
+}
+
+func TestApprovalResetOnPush(t *testing.T) {
+	fake := newFakeGitHub()
+	fake.setTeam("org/approvers")
+	fake.setTeam("org/reviewers", "carol")
+	mod := newApprovalTestModule(t, fake)
+	for _, c := range [][2]string{{"alice", "/approve"}, {"carol", "/lgtm"}} {
+		if err := internal.TestCommands(t, mod, prCommentEvent("org/repo", 1, c[0], c[1])); err != nil {
+			t.Fatalf("HandleCommand failed: %v", err)
+		}
+	}
+	if blockers, err := mod.MergeBlockers(context.Background(), "org/repo", 1); err != nil || len(blockers) != 0 {
+		t.Fatalf("MergeBlockers = %v, %v; want none", blockers, err)
+	}
+
+	if err := mod.HandleEvent("pull_request", pullRequestEvent("synchronize", "org/repo", 1, ""), nil); err != nil {
+		t.Fatalf("HandleEvent failed: %v", err)
+	}
+	if labels := fake.labelsOn("org/repo", 1); !slices.Equal(labels, []string{"approved"}) {
+		t.Errorf("labels after push = %v, want [approved]", labels)
+	}
+	comments := fake.commentsOn("org/repo", 1)
+	if len(comments) != 1 || !strings.Contains(comments[0], "`/lgtm` was withdrawn") {
+		t.Errorf("comments = %v", comments)
+	}
+	blockers, err := mod.MergeBlockers(context.Background(), "org/repo", 1)
+	if err != nil {
+		t.Fatalf("MergeBlockers failed: %v", err)
+	}
+	if len(blockers) != 1 || !strings.Contains(blockers[0], "/lgtm") {
+		t.Errorf("MergeBlockers = %v, want the missing lgtm", blockers)
+	}
+
+	// A second push has nothing left to withdraw.
+	if err := mod.HandleEvent("pull_request", pullRequestEvent("synchronize", "org/repo", 1, ""), nil); err != nil {
+		t.Fatalf("HandleEvent failed: %v", err)
+	}
+	if comments := fake.commentsOn("org/repo", 1); len(comments) != 1 {
+		t.Errorf("expected no new comment, got %v", comments)
+	}
+}
+
+func TestApprovalMaintainersByDefault(t *testing.T) {
+	fake := newFakeGitHub()
+	mod := newApprovalTestModule(t, fake)
+	mod.config.Approvers = nil
+	mod.config.Reviewers = nil
+	event := prCommentEvent("org/repo", 1, "dave", "/approve")
+	event.Comment.AuthorAssociation = github.Ptr("MEMBER")
+	if err := internal.TestCommands(t, mod, event); err != nil {
+		t.Fatalf("HandleCommand failed: %v", err)
+	}
+	event = prCommentEvent("org/repo", 1, "erin", "/lgtm")
+	event.Comment.AuthorAssociation = github.Ptr("CONTRIBUTOR")
+	if err := internal.TestCommands(t, mod, event); err != nil {
+		t.Fatalf("HandleCommand failed: %v", err)
+	}
+	if labels := fake.labelsOn("org/repo", 1); !slices.Equal(labels, []string{"approved"}) {
+		t.Errorf("labels = %v, want [approved]", labels)
+	}
+}
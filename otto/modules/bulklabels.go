@@ -0,0 +1,406 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v71/github"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+// bulkLabelConfirmKind is the confirmation kind for `/label-all`.
+const bulkLabelConfirmKind = "bulklabels.label-all"
+
+// bulkLabelCommentKey identifies the managed progress comment of a `/label-all` run.
+const bulkLabelCommentKey = "label-all"
+
+// BulkLabelConfig configures `/label-all`.
+type BulkLabelConfig struct {
+	Delay         time.Duration `yaml:"delay" doc:"pause between issues to spread out API calls"`
+	MaxIssues     int           `yaml:"max_issues" doc:"search results beyond this are left alone"`
+	ProgressEvery int           `yaml:"progress_every" doc:"issues between progress comment updates"`
+}
+
+// bulkLabelRequest is a confirmed `/label-all` operation.
+type bulkLabelRequest struct {
+	Query  string   `json:"query"`
+	Add    []string `json:"add,omitempty"`
+	Remove []string `json:"remove,omitempty"`
+	Issues []int    `json:"issues"`
+}
+
+// bulkLabelRun is a `/label-all` operation in progress.
+type bulkLabelRun struct {
+	issue  int
+	user   string
+	cancel context.CancelFunc
+}
+
+// BulkLabelModule applies label changes to every issue matching a search with
+// `/label-all query:"..." add:a,b remove:c`. Maintainers confirm the change before it
+// runs, changes are applied at a limited rate with progress in a managed comment, and
+// `/label-all abort` stops a run.
+type BulkLabelModule struct {
+	app    *internal.App
+	config BulkLabelConfig
+
+	mu      sync.Mutex
+	running map[string]*bulkLabelRun // key: repository
+	wg      sync.WaitGroup
+}
+
+func (m *BulkLabelModule) Name() string { return "bulklabels" }
+
+// ConfigSchema implements the ModuleConfigSchema interface.
+func (m *BulkLabelModule) ConfigSchema() any {
+	c := defaultBulkLabelConfig()
+	return &c
+}
+
+// defaultBulkLabelConfig returns the bulklabels module's defaults.
+func defaultBulkLabelConfig() BulkLabelConfig {
+	return BulkLabelConfig{
+		Delay:         time.Second,
+		MaxIssues:     500,
+		ProgressEvery: 10,
+	}
+}
+
+// EventSubscriptions implements the ModuleEventSubscriber interface. The module consumes
+// no events: the app hands it `/label-all` commands through HandleCommand.
+func (m *BulkLabelModule) EventSubscriptions() []internal.EventSubscription {
+	return nil
+}
+
+// GitHubPermissions implements the ModulePermissionRequirer interface.
+func (m *BulkLabelModule) GitHubPermissions() map[string]string {
+	return map[string]string{"issues": "write"}
+}
+
+// SlashCommands implements the ModuleCommander interface.
+func (m *BulkLabelModule) SlashCommands() []string {
+	return []string{"label-all"}
+}
+
+// Initialize implements the ModuleInitializer interface.
+func (m *BulkLabelModule) Initialize(ctx context.Context, app *internal.App) error {
+	m.app = app
+	m.running = make(map[string]*bulkLabelRun)
+	m.config = defaultBulkLabelConfig()
+	if err := loadModuleConfig(app, m.Name(), &m.config); err != nil {
+		return err
+	}
+	if app.Confirmations != nil {
+		app.Confirmations.Handle(bulkLabelConfirmKind, m.confirmLabelAll)
+	}
+	return nil
+}
+
+func (m *BulkLabelModule) HandleEvent(eventType string, event any, raw json.RawMessage) error {
+	return nil
+}
+
+// HandleCommand implements the ModuleCommandHandler interface.
+func (m *BulkLabelModule) HandleCommand(cmd *internal.CommandContext) error {
+	return m.handleLabelAll(cmd.Context, cmd.Event, cmd.Args)
+}
+
+func (m *BulkLabelModule) handleLabelAll(ctx context.Context, event *github.IssueCommentEvent, args []string) error {
+	repo := event.GetRepo().GetFullName()
+	num := event.GetIssue().GetNumber()
+	login := event.GetComment().GetUser().GetLogin()
+	if !maintainerAssociations[event.GetComment().GetAuthorAssociation()] {
+		return m.wrap(m.comment(ctx, repo, num, "⚠️ Only maintainers can use `/label-all`."), "label_all", repo, num)
+	}
+	if len(args) == 1 && args[0] == "abort" {
+		return m.wrap(m.comment(ctx, repo, num, m.abort(repo, login)), "label_all_abort", repo, num)
+	}
+	if m.app == nil || m.app.Confirmations == nil || m.app.GitHubClient == nil {
+		return m.wrap(m.comment(ctx, repo, num, "⚠️ `/label-all` is not available: confirmations are disabled."),
+			"label_all", repo, num)
+	}
+	req, err := parseBulkLabelArgs(args)
+	if err != nil {
+		return m.wrap(m.comment(ctx, repo, num, fmt.Sprintf("⚠️ %v\n\nUsage: `/label-all query:\"is:open label:bug\" "+
+			"add:priority-p2 [remove:needs-triage]` or `/label-all abort`.", err)), "label_all", repo, num)
+	}
+	if run := m.runningIn(repo); run != nil {
+		return m.wrap(m.comment(ctx, repo, num, fmt.Sprintf("⚠️ A `/label-all` started by @%s in #%d is still "+
+			"running in this repository.", run.user, run.issue)), "label_all", repo, num)
+	}
+
+	issues, truncated, err := m.search(ctx, repo, req.Query)
+	if err != nil {
+		return m.wrap(err, "search_issues", repo, num)
+	}
+	if len(issues) == 0 {
+		return m.wrap(m.comment(ctx, repo, num, fmt.Sprintf("No issues match `%s`.", req.Query)),
+			"label_all", repo, num)
+	}
+	for _, issue := range issues {
+		req.Issues = append(req.Issues, issue.GetNumber())
+	}
+
+	change := describeLabelChange(req)
+	summary := fmt.Sprintf("%s on %d issues matching %q", change, len(issues), req.Query)
+	action, err := m.app.Confirmations.Request(ctx, bulkLabelConfirmKind, repo, num, login, summary, req)
+	if err != nil {
+		return m.wrap(err, "request_confirmation", repo, num)
+	}
+
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "⚠️ @%s this will %s on %d issues matching `%s`:\n\n", login, change, len(issues), req.Query)
+	const listed = 10
+	for i, issue := range issues {
+		if i == listed {
+			fmt.Fprintf(&msg, "- … and %d more\n", len(issues)-listed)
+			break
+		}
+		fmt.Fprintf(&msg, "- #%d %s\n", issue.GetNumber(), issue.GetTitle())
+	}
+	if truncated {
+		fmt.Fprintf(&msg, "\nOnly the first %d results will be changed.\n", m.config.MaxIssues)
+	}
+	fmt.Fprintf(&msg, "\nReply `/confirm %s` within %d minutes to proceed. Once started, "+
+		"`/label-all abort` stops the run.", action.Token, int(internal.ConfirmationTTL.Minutes()))
+	return m.wrap(m.comment(ctx, repo, num, msg.String()), "label_all", repo, num)
+}
+
+// parseBulkLabelArgs reads `query:<search>`, `add:<labels>` and `remove:<labels>`
+// arguments; label lists are comma separated.
+func parseBulkLabelArgs(args []string) (bulkLabelRequest, error) {
+	var req bulkLabelRequest
+	for _, arg := range args {
+		key, value, _ := strings.Cut(arg, ":")
+		switch key {
+		case "query":
+			req.Query = strings.TrimSpace(value)
+		case "add":
+			req.Add = append(req.Add, splitLabelList(value)...)
+		case "remove":
+			req.Remove = append(req.Remove, splitLabelList(value)...)
+		default:
+			return req, fmt.Errorf("unknown argument `%s`", arg)
+		}
+	}
+	if req.Query == "" {
+		return req, errors.New("a search `query:` is required")
+	}
+	if len(req.Add) == 0 && len(req.Remove) == 0 {
+		return req, errors.New("nothing to do: give labels to `add:` or `remove:`")
+	}
+	return req, nil
+}
+
+func splitLabelList(value string) []string {
+	var labels []string
+	for _, l := range strings.Split(value, ",") {
+		if l = strings.TrimSpace(l); l != "" {
+			labels = append(labels, l)
+		}
+	}
+	return labels
+}
+
+// describeLabelChange renders the label change, e.g. "add `a` and remove `b`".
+func describeLabelChange(req bulkLabelRequest) string {
+	quote := func(labels []string) string { return "`" + strings.Join(labels, "`, `") + "`" }
+	var parts []string
+	if len(req.Add) > 0 {
+		parts = append(parts, "add "+quote(req.Add))
+	}
+	if len(req.Remove) > 0 {
+		parts = append(parts, "remove "+quote(req.Remove))
+	}
+	return strings.Join(parts, " and ")
+}
+
+// search returns the issues and pull requests in repo matching query, up to MaxIssues,
+// and whether more matched.
+func (m *BulkLabelModule) search(ctx context.Context, repo, query string) ([]*github.Issue, bool, error) {
+	opts := &github.SearchOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	var issues []*github.Issue
+	for {
+		result, resp, err := m.app.GitHubClient.Search.Issues(ctx, fmt.Sprintf("repo:%s %s", repo, query), opts)
+		if err != nil {
+			return nil, false, err
+		}
+		for _, issue := range result.Issues {
+			if len(issues) == m.config.MaxIssues {
+				return issues, true, nil
+			}
+			issues = append(issues, issue)
+		}
+		if resp.NextPage == 0 {
+			return issues, false, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// confirmLabelAll starts a confirmed run in the background.
+func (m *BulkLabelModule) confirmLabelAll(ctx context.Context, action internal.PendingAction) (string, error) {
+	var req bulkLabelRequest
+	if err := json.Unmarshal(action.Payload, &req); err != nil {
+		return "", fmt.Errorf("invalid label-all payload: %w", err)
+	}
+
+	runCtx, cancel := context.WithCancel(internal.WithModule(context.Background(), m.Name()))
+	m.mu.Lock()
+	if run := m.running[action.Repo]; run != nil {
+		m.mu.Unlock()
+		cancel()
+		return fmt.Sprintf("⚠️ A `/label-all` started by @%s in #%d is still running in this repository.",
+			run.user, run.issue), nil
+	}
+	m.running[action.Repo] = &bulkLabelRun{issue: action.Issue, user: action.User, cancel: cancel}
+	m.mu.Unlock()
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		defer cancel()
+		m.run(runCtx, action, req)
+	}()
+	return fmt.Sprintf("🏷️ Started: %s on %d issues. Progress is tracked in a separate comment; "+
+		"`/label-all abort` stops the run.", describeLabelChange(req), len(req.Issues)), nil
+}
+
+// run applies the label change to each issue, pausing between issues and updating the
+// progress comment as it goes.
+func (m *BulkLabelModule) run(ctx context.Context, action internal.PendingAction, req bulkLabelRequest) {
+	defer func() {
+		m.mu.Lock()
+		delete(m.running, action.Repo)
+		m.mu.Unlock()
+	}()
+
+	done, failed := 0, 0
+	progress := func(state string) {
+		body := fmt.Sprintf("%s `/label-all` by @%s: %s on issues matching `%s`.\n\n%d of %d issues done",
+			state, action.User, describeLabelChange(req), req.Query, done, len(req.Issues))
+		if failed > 0 {
+			body += fmt.Sprintf(", %d failed", failed)
+		}
+		body += "."
+		// The run may have been aborted; progress updates still go out.
+		_, err := internal.UpsertManagedComment(context.WithoutCancel(ctx), m.app.GitHubClient,
+			action.Repo, action.Issue, bulkLabelCommentKey, body)
+		if err != nil {
+			slog.Error("Failed to update label-all progress", "repo", action.Repo, "issue", action.Issue, "error", err)
+		}
+	}
+
+	progress("⏳")
+	for i, num := range req.Issues {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				progress("🛑 Aborted")
+				return
+			case <-time.After(m.config.Delay):
+			}
+		}
+		if err := m.app.WaitIfLimited(ctx); err != nil {
+			progress("🛑 Aborted")
+			return
+		}
+		if err := m.apply(ctx, action.Repo, num, req); err != nil {
+			if ctx.Err() != nil {
+				progress("🛑 Aborted")
+				return
+			}
+			failed++
+			slog.Error("Failed to change labels", "repo", action.Repo, "issue", num, "error", err)
+		} else {
+			done++
+		}
+		if (i+1)%m.config.ProgressEvery == 0 && i+1 < len(req.Issues) {
+			progress("⏳")
+		}
+	}
+	progress("✅ Finished")
+}
+
+// apply adds and removes the requested labels on one issue. Removing a label that is
+// not applied is not an error.
+func (m *BulkLabelModule) apply(ctx context.Context, repo string, num int, req bulkLabelRequest) error {
+	if len(req.Add) > 0 {
+		if err := m.app.AddLabels(ctx, m.Name(), repo, num, req.Add); err != nil {
+			return err
+		}
+	}
+	if len(req.Remove) > 0 {
+		return m.app.RemoveLabels(ctx, m.Name(), repo, num, req.Remove)
+	}
+	return nil
+}
+
+// abort stops the run in repo and returns a reply for the user.
+func (m *BulkLabelModule) abort(repo, user string) string {
+	m.mu.Lock()
+	run := m.running[repo]
+	m.mu.Unlock()
+	if run == nil {
+		return "There is no `/label-all` running in this repository."
+	}
+	run.cancel()
+	slog.Info("label-all aborted", "repo", repo, "started_by", run.user, "aborted_by", user)
+	return fmt.Sprintf("🛑 Stopping the `/label-all` started by @%s in #%d.", run.user, run.issue)
+}
+
+// Shutdown implements the ModuleShutdowner interface, aborting running operations.
+func (m *BulkLabelModule) Shutdown(ctx context.Context) error {
+	m.mu.Lock()
+	for _, run := range m.running {
+		run.cancel()
+	}
+	m.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (m *BulkLabelModule) runningIn(repo string) *bulkLabelRun {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.running[repo]
+}
+
+func (m *BulkLabelModule) comment(ctx context.Context, repo string, num int, body string) error {
+	if m.app == nil || m.app.GitHubClient == nil {
+		slog.Info("GitHub comment would be posted (no GitHub client available)",
+			"repo", repo, "issue_num", num, "message", body)
+		return nil
+	}
+	return m.app.PostComment(ctx, m.Name(), repo, num, body)
+}
+
+func (m *BulkLabelModule) wrap(err error, op, repo string, num int) error {
+	if err == nil {
+		return nil
+	}
+	return internal.LogAndWrapError(err, internal.ErrorTypeModule, op, map[string]any{
+		"module": m.Name(),
+		"repo":   repo,
+		"issue":  num,
+	})
+}
@@ -0,0 +1,186 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v71/github"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+)
+
+type bulkLabelTestEnv struct {
+	mod     *BulkLabelModule
+	confirm *ConfirmModule
+	fake    *fakeGitHub
+}
+
+func newBulkLabelTestEnv(t *testing.T, delay time.Duration) *bulkLabelTestEnv {
+	t.Helper()
+	db := internal.TestDB(t)
+	env := &bulkLabelTestEnv{mod: &BulkLabelModule{}, confirm: &ConfirmModule{}, fake: newFakeGitHub()}
+	app := &internal.App{
+		Config: &config.AppConfig{Modules: map[string]any{
+			"bulklabels": map[string]any{"delay": delay.String(), "progress_every": 2},
+		}},
+		Database:     internal.NewDatabaseFromDB(db),
+		GitHubClient: env.fake.client(t),
+	}
+	var err error
+	if app.Confirmations, err = internal.NewConfirmations(db); err != nil {
+		t.Fatalf("NewConfirmations failed: %v", err)
+	}
+	for _, m := range []internal.ModuleInitializer{env.mod, env.confirm} {
+		if err := m.Initialize(t.Context(), app); err != nil {
+			t.Fatalf("Initialize failed: %v", err)
+		}
+	}
+	return env
+}
+
+// command runs a maintainer's slash command on issue 100 and returns the last reply.
+func (env *bulkLabelTestEnv) command(t *testing.T, module internal.ModuleCommandHandler, body string) string {
+	t.Helper()
+	event := commentEvent("org/repo", 100, "alice", body)
+	event.Comment.AuthorAssociation = github.Ptr("MEMBER")
+	if err := internal.TestCommands(t, module, event); err != nil {
+		t.Fatalf("HandleCommand failed: %v", err)
+	}
+	comments := env.fake.commentsOn("org/repo", 100)
+	if len(comments) == 0 {
+		t.Fatalf("no reply to %q", body)
+	}
+	return comments[len(comments)-1]
+}
+
+// requestToken asks for a bulk label change and returns its confirmation token.
+func (env *bulkLabelTestEnv) requestToken(t *testing.T, body string) string {
+	t.Helper()
+	reply := env.command(t, env.mod, body)
+	match := regexp.MustCompile("/confirm ([0-9a-f]+)").FindStringSubmatch(reply)
+	if match == nil {
+		t.Fatalf("no token in reply: %q", reply)
+	}
+	return match[1]
+}
+
+func TestLabelAll(t *testing.T) {
+	env := newBulkLabelTestEnv(t, 0)
+	for _, n := range []int{1, 2, 3} {
+		env.fake.setLabels("org/repo", n, "bug", "needs-triage")
+	}
+	env.fake.setLabels("org/repo", 4, "enhancement")
+	env.fake.setLabels("org/repo", 5, "bug")
+	env.fake.states["org/repo#5"] = "closed"
+
+	token := env.requestToken(t, `/label-all query:"is:open label:bug" add:priority-p2 remove:needs-triage`)
+	summary := env.fake.commentsOn("org/repo", 100)[0]
+	if !strings.Contains(summary, "add `priority-p2` and remove `needs-triage` on 3 issues") ||
+		!strings.Contains(summary, "- #3") || strings.Contains(summary, "#5") {
+		t.Fatalf("unexpected summary: %q", summary)
+	}
+	if labels := env.fake.labelsOn("org/repo", 1); slices.Contains(labels, "priority-p2") {
+		t.Fatal("labels changed before confirmation")
+	}
+
+	if reply := env.command(t, env.confirm, "/confirm "+token); !strings.Contains(reply, "Started") {
+		t.Fatalf("unexpected confirmation reply: %q", reply)
+	}
+	env.mod.wg.Wait()
+
+	for _, n := range []int{1, 2, 3} {
+		if labels := env.fake.labelsOn("org/repo", n); !slices.Equal(labels, []string{"bug", "priority-p2"}) {
+			t.Errorf("issue %d labels = %v", n, labels)
+		}
+	}
+	if labels := env.fake.labelsOn("org/repo", 4); !slices.Equal(labels, []string{"enhancement"}) {
+		t.Errorf("unmatched issue changed: %v", labels)
+	}
+	var progress []string
+	for _, c := range env.fake.commentsOn("org/repo", 100) {
+		if strings.HasPrefix(c, internal.ManagedCommentMarker(bulkLabelCommentKey)) {
+			progress = append(progress, c)
+		}
+	}
+	if len(progress) != 1 || !strings.Contains(progress[0], "✅ Finished") ||
+		!strings.Contains(progress[0], "3 of 3 issues done.") {
+		t.Errorf("unexpected progress comments: %q", progress)
+	}
+}
+
+func TestLabelAllAbort(t *testing.T) {
+	env := newBulkLabelTestEnv(t, time.Hour)
+	for _, n := range []int{1, 2, 3} {
+		env.fake.setLabels("org/repo", n, "bug")
+	}
+	token := env.requestToken(t, `/label-all query:label:bug add:stale`)
+	env.command(t, env.confirm, "/confirm "+token)
+
+	// The first issue is labeled right away; the rest wait for the delay.
+	deadline := time.Now().Add(5 * time.Second)
+	for !slices.Contains(env.fake.labelsOn("org/repo", 1), "stale") {
+		if time.Now().After(deadline) {
+			t.Fatal("first issue was never labeled")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	reply := env.command(t, env.mod, `/label-all query:label:bug add:other`)
+	if !strings.Contains(reply, "still running") {
+		t.Errorf("second run was not refused: %q", reply)
+	}
+	if reply := env.command(t, env.mod, "/label-all abort"); !strings.Contains(reply, "Stopping") {
+		t.Fatalf("unexpected abort reply: %q", reply)
+	}
+	env.mod.wg.Wait()
+
+	if slices.Contains(env.fake.labelsOn("org/repo", 2), "stale") {
+		t.Error("labels changed after abort")
+	}
+	comments := env.fake.commentsOn("org/repo", 100)
+	if !slices.ContainsFunc(comments, func(c string) bool {
+		return strings.Contains(c, "🛑 Aborted") && strings.Contains(c, "1 of 3 issues done")
+	}) {
+		t.Errorf("no aborted progress comment: %q", comments)
+	}
+	if reply := env.command(t, env.mod, "/label-all abort"); !strings.Contains(reply, "no `/label-all` running") {
+		t.Errorf("unexpected reply with nothing running: %q", reply)
+	}
+}
+
+func TestLabelAllRejected(t *testing.T) {
+	env := newBulkLabelTestEnv(t, 0)
+	tests := []struct {
+		name       string
+		body       string
+		maintainer bool
+		want       string
+	}{
+		{name: "not a maintainer", body: "/label-all query:label:bug add:x", want: "Only maintainers"},
+		{name: "missing query", body: "/label-all add:x", maintainer: true, want: "query:` is required"},
+		{name: "no labels", body: "/label-all query:label:bug", maintainer: true, want: "nothing to do"},
+		{name: "unknown argument", body: "/label-all query:label:bug add:x color:red", maintainer: true,
+			want: "unknown argument `color:red`"},
+		{name: "no matches", body: "/label-all query:label:nothing add:x", maintainer: true, want: "No issues match"},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := commentEvent("org/repo", i+1, "bob", tt.body)
+			if tt.maintainer {
+				event.Comment.AuthorAssociation = github.Ptr("OWNER")
+			}
+			if err := internal.TestCommands(t, env.mod, event); err != nil {
+				t.Fatalf("HandleCommand failed: %v", err)
+			}
+			comments := env.fake.commentsOn("org/repo", i+1)
+			if len(comments) != 1 || !strings.Contains(comments[0], tt.want) {
+				t.Errorf("comments = %q, want one containing %q", comments, tt.want)
+			}
+		})
+	}
+}
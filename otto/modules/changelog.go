@@ -0,0 +1,305 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+// Changelog fragment formats.
+const (
+	// ChangelogFormatTowncrier writes one markdown file per entry: <dir>/<pr>.<kind>.md.
+	ChangelogFormatTowncrier = "towncrier"
+	// ChangelogFormatChloggen writes OpenTelemetry chloggen YAML: <dir>/pr-<pr>.yaml.
+	ChangelogFormatChloggen = "chloggen"
+)
+
+// changelogEntryPattern matches "kind: description" or "kind(component): description".
+var changelogEntryPattern = regexp.MustCompile(`^([\w-]+)(?:\(([^)]+)\))?:\s*(.+)$`)
+
+// chloggenChangeTypes maps accepted kinds to chloggen change_type values.
+var chloggenChangeTypes = map[string]string{
+	"breaking":      "breaking",
+	"deprecation":   "deprecation",
+	"new_component": "new_component",
+	"enhancement":   "enhancement",
+	"bug_fix":       "bug_fix",
+	"added":         "enhancement",
+	"changed":       "enhancement",
+	"deprecated":    "deprecation",
+	"removed":       "breaking",
+	"fixed":         "bug_fix",
+}
+
+// ChangelogConfig configures changelog fragments.
+type ChangelogConfig struct {
+	ChangelogFormat `yaml:",inline"`
+	Repos           map[string]ChangelogFormat `yaml:"repos" doc:"per-repository overrides"`
+}
+
+// ChangelogFormat describes where and how fragments are written.
+type ChangelogFormat struct {
+	Format    string   `yaml:"format" doc:"towncrier or chloggen"`
+	Directory string   `yaml:"directory" doc:"directory fragments are written to"`
+	Kinds     []string `yaml:"kinds" doc:"allowed kinds for the towncrier format"`
+}
+
+// ChangelogEntry is a parsed /changelog command.
+type ChangelogEntry struct {
+	Kind        string
+	Component   string
+	Description string
+}
+
+// ChangelogModule writes changelog fragments to pull request branches from `/changelog` commands.
+type ChangelogModule struct {
+	app    *internal.App
+	config ChangelogConfig
+}
+
+func (m *ChangelogModule) Name() string { return "changelog" }
+
+// ConfigSchema implements the ModuleConfigSchema interface.
+func (m *ChangelogModule) ConfigSchema() any {
+	c := defaultChangelogConfig()
+	return &c
+}
+
+// defaultChangelogConfig returns the changelog module's defaults.
+func defaultChangelogConfig() ChangelogConfig {
+	return ChangelogConfig{
+		ChangelogFormat: ChangelogFormat{
+			Format:    ChangelogFormatTowncrier,
+			Directory: "changelog.d",
+			Kinds:     []string{"added", "changed", "deprecated", "removed", "fixed", "security"},
+		},
+	}
+}
+
+// EventSubscriptions implements the ModuleEventSubscriber interface. The module consumes
+// no events: the app hands it `/changelog` commands through HandleCommand.
+func (m *ChangelogModule) EventSubscriptions() []internal.EventSubscription {
+	return nil
+}
+
+// GitHubPermissions implements the ModulePermissionRequirer interface.
+func (m *ChangelogModule) GitHubPermissions() map[string]string {
+	return map[string]string{"contents": "write", "issues": "write", "pull_requests": "read"}
+}
+
+// SlashCommands implements the ModuleCommander interface.
+func (m *ChangelogModule) SlashCommands() []string {
+	return []string{"changelog"}
+}
+
+// Initialize implements the ModuleInitializer interface.
+func (m *ChangelogModule) Initialize(ctx context.Context, app *internal.App) error {
+	m.app = app
+	m.config = defaultChangelogConfig()
+	if err := loadModuleConfig(app, m.Name(), &m.config); err != nil {
+		return err
+	}
+	for repo, f := range m.formats() {
+		if f.Format != ChangelogFormatTowncrier && f.Format != ChangelogFormatChloggen {
+			return fmt.Errorf("changelog: unknown format %q for %s", f.Format, repo)
+		}
+	}
+	return nil
+}
+
+// formats returns the default format (keyed "") and every per-repository override.
+func (m *ChangelogModule) formats() map[string]ChangelogFormat {
+	out := map[string]ChangelogFormat{"": m.config.ChangelogFormat}
+	for repo := range m.config.Repos {
+		out[repo] = m.formatFor(repo)
+	}
+	return out
+}
+
+// formatFor returns the fragment format of a repository, filling unset fields from the default.
+func (m *ChangelogModule) formatFor(repo string) ChangelogFormat {
+	f, ok := m.config.Repos[repo]
+	if !ok {
+		return m.config.ChangelogFormat
+	}
+	if f.Format == "" {
+		f.Format = m.config.Format
+	}
+	if f.Directory == "" {
+		f.Directory = m.config.Directory
+	}
+	if len(f.Kinds) == 0 {
+		f.Kinds = m.config.Kinds
+	}
+	return f
+}
+
+func (m *ChangelogModule) HandleEvent(eventType string, event any, raw json.RawMessage) error {
+	return nil
+}
+
+// HandleCommand implements the ModuleCommandHandler interface.
+func (m *ChangelogModule) HandleCommand(cmd *internal.CommandContext) error {
+	if !cmd.Event.GetIssue().IsPullRequest() {
+		return nil
+	}
+	ctx := cmd.Context
+	repo := cmd.Repo
+	number := cmd.IssueNum
+
+	if cmd.Issuer != cmd.Event.GetIssue().GetUser().GetLogin() &&
+		!maintainerAssociations[cmd.Event.GetComment().GetAuthorAssociation()] {
+		m.comment(ctx, repo, number, "⚠️ Only the pull request author or maintainers can run `/changelog`.")
+		return nil
+	}
+
+	format := m.formatFor(repo)
+	entry, err := parseChangelogEntry(strings.Join(cmd.Args, " "), format)
+	if err != nil {
+		m.comment(ctx, repo, number, "⚠️ "+err.Error())
+		return nil
+	}
+
+	path, err := m.WriteFragment(ctx, repo, number, entry)
+	if err != nil {
+		m.comment(ctx, repo, number, fmt.Sprintf("❌ Could not write changelog entry: %v", err))
+		return internal.LogAndWrapError(err, internal.ErrorTypeModule, "write_changelog", map[string]any{
+			"repo": repo,
+			"pr":   number,
+		})
+	}
+	m.comment(ctx, repo, number, fmt.Sprintf("📝 Changelog entry written to `%s`.", path))
+	return nil
+}
+
+// parseChangelogEntry parses "kind[(component)]: description" and validates the kind.
+func parseChangelogEntry(text string, format ChangelogFormat) (ChangelogEntry, error) {
+	match := changelogEntryPattern.FindStringSubmatch(strings.TrimSpace(text))
+	if match == nil {
+		return ChangelogEntry{}, fmt.Errorf("usage: `/changelog <kind>: <description>`, e.g. `/changelog %s: short description`",
+			format.kinds()[0])
+	}
+	entry := ChangelogEntry{Kind: strings.ToLower(match[1]), Component: match[2], Description: match[3]}
+	if !slices.Contains(format.kinds(), entry.Kind) {
+		return ChangelogEntry{}, fmt.Errorf("unknown changelog kind %q; use one of: %s",
+			entry.Kind, strings.Join(format.kinds(), ", "))
+	}
+	return entry, nil
+}
+
+// kinds returns the kinds accepted by the format.
+func (f ChangelogFormat) kinds() []string {
+	if f.Format == ChangelogFormatChloggen {
+		kinds := make([]string, 0, len(chloggenChangeTypes))
+		for k := range chloggenChangeTypes {
+			kinds = append(kinds, k)
+		}
+		slices.Sort(kinds)
+		return kinds
+	}
+	return f.Kinds
+}
+
+// WriteFragment creates or updates the changelog fragment for a pull request on
+// its head branch and returns the fragment path.
+func (m *ChangelogModule) WriteFragment(ctx context.Context, repo string, number int, entry ChangelogEntry) (string, error) {
+	format := m.formatFor(repo)
+	path, content, err := renderChangelogFragment(format, number, entry)
+	if err != nil {
+		return "", err
+	}
+	if m.app == nil || m.app.GitHubClient == nil {
+		slog.Info("Changelog fragment would be written (no GitHub client available)",
+			"repo", repo, "pr", number, "path", path)
+		return path, nil
+	}
+	client := m.app.GitHubClient
+
+	owner, name, err := internal.SplitRepo(repo)
+	if err != nil {
+		return "", err
+	}
+	pr, _, err := client.PullRequests.Get(ctx, owner, name, number)
+	if err != nil {
+		return "", fmt.Errorf("failed to get pull request: %w", err)
+	}
+	headRepo := pr.GetHead().GetRepo().GetFullName()
+	if headRepo == "" {
+		headRepo = repo
+	}
+	if headRepo != repo && !pr.GetMaintainerCanModify() {
+		return "", fmt.Errorf("the pull request branch is in a fork that does not allow edits from maintainers")
+	}
+	branch := pr.GetHead().GetRef()
+
+	// Updating an existing fragment needs its blob SHA.
+	existing, err := internal.GetFile(ctx, client, headRepo, path, branch)
+	if err != nil {
+		return "", err
+	}
+	sha := ""
+	if existing != nil {
+		if existing.Content == content {
+			return path, nil
+		}
+		sha = existing.SHA
+	}
+	message := fmt.Sprintf("Add changelog entry for #%d", number)
+	if err := internal.PutFile(ctx, client, headRepo, branch, path, message, content, sha); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// renderChangelogFragment returns the fragment path and content for an entry.
+func renderChangelogFragment(format ChangelogFormat, number int, entry ChangelogEntry) (string, string, error) {
+	dir := strings.TrimSuffix(format.Directory, "/")
+	switch format.Format {
+	case ChangelogFormatChloggen:
+		fragment := struct {
+			ChangeType string `yaml:"change_type"`
+			Component  string `yaml:"component"`
+			Note       string `yaml:"note"`
+			Issues     []int  `yaml:"issues"`
+			Subtext    string `yaml:"subtext"`
+		}{
+			ChangeType: chloggenChangeTypes[entry.Kind],
+			Component:  entry.Component,
+			Note:       entry.Description,
+			Issues:     []int{number},
+		}
+		data, err := yaml.Marshal(fragment)
+		if err != nil {
+			return "", "", err
+		}
+		return fmt.Sprintf("%s/pr-%d.yaml", dir, number), string(data), nil
+	default:
+		content := entry.Description
+		if entry.Component != "" {
+			content = fmt.Sprintf("`%s`: %s", entry.Component, content)
+		}
+		return fmt.Sprintf("%s/%d.%s.md", dir, number, entry.Kind), content + "\n", nil
+	}
+}
+
+// comment posts a plain comment, logging instead when no GitHub client is configured.
+func (m *ChangelogModule) comment(ctx context.Context, repo string, number int, body string) {
+	if m.app == nil || m.app.GitHubClient == nil {
+		slog.Info("GitHub comment would be posted (no GitHub client available)",
+			"repo", repo, "issue", number, "message", body)
+		return
+	}
+	if err := m.app.PostComment(ctx, m.Name(), repo, number, body); err != nil {
+		slog.Error("Failed to post changelog comment", "repo", repo, "issue", number, "error", err)
+	}
+}
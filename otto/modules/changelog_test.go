@@ -0,0 +1,109 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v71/github"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+func TestParseChangelogEntry(t *testing.T) {
+	towncrier := ChangelogFormat{Format: ChangelogFormatTowncrier, Kinds: []string{"added", "fixed"}}
+	chloggen := ChangelogFormat{Format: ChangelogFormatChloggen}
+
+	tests := []struct {
+		name    string
+		text    string
+		format  ChangelogFormat
+		want    ChangelogEntry
+		wantErr bool
+	}{
+		{"simple", "added: New flag", towncrier, ChangelogEntry{Kind: "added", Description: "New flag"}, false},
+		{"component", "Fixed(receiver/otlp): Crash", towncrier,
+			ChangelogEntry{Kind: "fixed", Component: "receiver/otlp", Description: "Crash"}, false},
+		{"unknown kind", "security: Patch", towncrier, ChangelogEntry{}, true},
+		{"missing colon", "added New flag", towncrier, ChangelogEntry{}, true},
+		{"chloggen native kind", "bug_fix: Crash", chloggen, ChangelogEntry{Kind: "bug_fix", Description: "Crash"}, false},
+		{"chloggen alias", "added: Flag", chloggen, ChangelogEntry{Kind: "added", Description: "Flag"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseChangelogEntry(tt.text, tt.format)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChangelogCommand(t *testing.T) {
+	fake := newFakeGitHub()
+	app := &internal.App{GitHubClient: fake.client(t)}
+	mod := &ChangelogModule{}
+	if err := mod.Initialize(t.Context(), app); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	mod.config.Repos = map[string]ChangelogFormat{"org/collector": {Format: ChangelogFormatChloggen, Directory: ".chloggen"}}
+
+	fake.addPull("org/repo", &github.PullRequest{
+		Number: github.Ptr(7),
+		Head:   &github.PullRequestBranch{Ref: github.Ptr("feature"), Repo: &github.Repository{FullName: github.Ptr("org/repo")}},
+	})
+	fake.addPull("org/collector", &github.PullRequest{
+		Number: github.Ptr(8),
+		Head:   &github.PullRequestBranch{Ref: github.Ptr("fix"), Repo: &github.Repository{FullName: github.Ptr("org/collector")}},
+	})
+
+	prComment := func(repo string, number int, user, body string) *github.IssueCommentEvent {
+		event := commentEvent(repo, number, user, body)
+		event.Issue.User = &github.User{Login: github.Ptr("author")}
+		event.Issue.PullRequestLinks = &github.PullRequestLinks{URL: github.Ptr("https://api.github.com/pulls/1")}
+		return event
+	}
+
+	event := prComment("org/repo", 7, "author", "/changelog added: Support `--verbose`")
+	if err := internal.TestCommands(t, mod, event); err != nil {
+		t.Fatalf("HandleCommand failed: %v", err)
+	}
+	if got, ok := fake.fileOn("org/repo", "feature", "changelog.d/7.added.md"); !ok || got != "Support `--verbose`\n" {
+		t.Errorf("unexpected towncrier fragment: %q", got)
+	}
+
+	// Running the command again updates the fragment in place.
+	event = prComment("org/repo", 7, "author", "/changelog added: Support `-v`")
+	if err := internal.TestCommands(t, mod, event); err != nil {
+		t.Fatalf("HandleCommand failed: %v", err)
+	}
+	if got, _ := fake.fileOn("org/repo", "feature", "changelog.d/7.added.md"); got != "Support `-v`\n" {
+		t.Errorf("fragment not updated: %q", got)
+	}
+
+	event = prComment("org/collector", 8, "author", "/changelog fixed(receiver/otlp): Fix crash")
+	if err := internal.TestCommands(t, mod, event); err != nil {
+		t.Fatalf("HandleCommand failed: %v", err)
+	}
+	got, ok := fake.fileOn("org/collector", "fix", ".chloggen/pr-8.yaml")
+	if !ok || !strings.Contains(got, "change_type: bug_fix") || !strings.Contains(got, "component: receiver/otlp") ||
+		!strings.Contains(got, "- 8") {
+		t.Errorf("unexpected chloggen fragment: %q", got)
+	}
+
+	// Other users cannot write to the branch.
+	if err := internal.TestCommands(t, mod, prComment("org/repo", 7, "mallory", "/changelog added: spam")); err != nil {
+		t.Fatalf("HandleCommand failed: %v", err)
+	}
+	if got, _ := fake.fileOn("org/repo", "feature", "changelog.d/7.added.md"); got != "Support `-v`\n" {
+		t.Errorf("fragment modified by non-author: %q", got)
+	}
+	comments := fake.commentsOn("org/repo", 7)
+	if len(comments) != 3 || !strings.Contains(comments[2], "Only the pull request author") {
+		t.Errorf("unexpected comments: %v", comments)
+	}
+}
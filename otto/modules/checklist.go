@@ -0,0 +1,197 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+
+	"github.com/google/go-github/v71/github"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+// ChecklistConfig configures pull request description validation.
+type ChecklistConfig struct {
+	CheckName string          `yaml:"check_name" doc:"name of the check run"`
+	SkipLabel string          `yaml:"skip_label" doc:"PRs with this label get a skipped check"`
+	Items     []ChecklistItem `yaml:"items" doc:"requirements the PR description must meet"`
+}
+
+// ChecklistItem is one requirement of the PR description.
+type ChecklistItem struct {
+	Name        string `yaml:"name" doc:"item name shown in the check"`
+	Description string `yaml:"description" doc:"what the item asks for"`
+	Pattern     string `yaml:"pattern" doc:"regular expression the PR body must match"`
+}
+
+// ChecklistResult is the evaluation of one checklist item.
+type ChecklistResult struct {
+	Item   ChecklistItem
+	Passed bool
+}
+
+// closingIssuePattern matches GitHub closing keywords followed by an issue reference.
+const closingIssuePattern = `(?i)\b(?:fix(?:es|ed)?|close[sd]?|resolve[sd]?)\s+(?:[\w.-]+/[\w.-]+)?#\d+`
+
+// defaultChecklistItems mirror the standard SIG pull request template.
+var defaultChecklistItems = []ChecklistItem{
+	{
+		Name:        "changelog",
+		Description: "Changelog entry added (checked box mentioning the changelog)",
+		Pattern:     `(?im)^\s*[-*]\s*\[[xX]\].*changelog`,
+	},
+	{
+		Name:        "tests",
+		Description: "Tests added or updated (checked box mentioning tests)",
+		Pattern:     `(?im)^\s*[-*]\s*\[[xX]\].*\btests?\b`,
+	},
+	{
+		Name:        "linked-issue",
+		Description: "Linked issue (`Fixes #123`)",
+		Pattern:     closingIssuePattern,
+	},
+}
+
+// ChecklistModule validates pull request descriptions against a configured checklist.
+type ChecklistModule struct {
+	app      *internal.App
+	config   ChecklistConfig
+	patterns []*regexp.Regexp
+}
+
+func (m *ChecklistModule) Name() string { return "checklist" }
+
+// ConfigSchema implements the ModuleConfigSchema interface.
+func (m *ChecklistModule) ConfigSchema() any {
+	c := defaultChecklistConfig()
+	return &c
+}
+
+// defaultChecklistConfig returns the checklist module's defaults.
+func defaultChecklistConfig() ChecklistConfig {
+	return ChecklistConfig{
+		CheckName: "otto/pr-checklist",
+		SkipLabel: "skip-checklist",
+		Items:     defaultChecklistItems,
+	}
+}
+
+// EventSubscriptions implements the ModuleEventSubscriber interface.
+func (m *ChecklistModule) EventSubscriptions() []internal.EventSubscription {
+	return []internal.EventSubscription{
+		internal.Subscribe("pull_request", "opened", "edited", "reopened", "synchronize", "labeled", "unlabeled"),
+	}
+}
+
+// GitHubPermissions implements the ModulePermissionRequirer interface.
+func (m *ChecklistModule) GitHubPermissions() map[string]string {
+	return map[string]string{"checks": "write"}
+}
+
+// Initialize implements the ModuleInitializer interface.
+func (m *ChecklistModule) Initialize(ctx context.Context, app *internal.App) error {
+	m.app = app
+	m.config = defaultChecklistConfig()
+	if err := loadModuleConfig(app, m.Name(), &m.config); err != nil {
+		return err
+	}
+	return m.compile()
+}
+
+// compile builds the item patterns.
+func (m *ChecklistModule) compile() error {
+	m.patterns = make([]*regexp.Regexp, 0, len(m.config.Items))
+	for _, item := range m.config.Items {
+		re, err := regexp.Compile(item.Pattern)
+		if err != nil {
+			return fmt.Errorf("checklist: invalid pattern for item %q: %w", item.Name, err)
+		}
+		m.patterns = append(m.patterns, re)
+	}
+	return nil
+}
+
+func (m *ChecklistModule) HandleEvent(eventType string, event any, raw json.RawMessage) error {
+	if eventType != "pull_request" {
+		return nil
+	}
+	prEvent, ok := event.(*github.PullRequestEvent)
+	if !ok {
+		return nil
+	}
+	switch prEvent.GetAction() {
+	case "opened", "edited", "reopened", "synchronize", "labeled", "unlabeled":
+	default:
+		return nil
+	}
+
+	pr := prEvent.GetPullRequest()
+	repo := prEvent.GetRepo().GetFullName()
+	run := m.checkRun(pr)
+
+	if m.app == nil || m.app.GitHubClient == nil {
+		slog.Info("Check run would be published (no GitHub client available)",
+			"repo", repo, "pr", pr.GetNumber(), "conclusion", run.Conclusion)
+		return nil
+	}
+	if err := internal.PublishCheckRun(context.Background(), m.app.GitHubClient, repo, run); err != nil {
+		return internal.LogAndWrapError(err, internal.ErrorTypeModule, "publish_checklist", map[string]any{
+			"repo": repo,
+			"pr":   pr.GetNumber(),
+		})
+	}
+	return nil
+}
+
+// Evaluate checks a PR body against every checklist item.
+func (m *ChecklistModule) Evaluate(body string) []ChecklistResult {
+	results := make([]ChecklistResult, 0, len(m.config.Items))
+	for i, item := range m.config.Items {
+		results = append(results, ChecklistResult{Item: item, Passed: m.patterns[i].MatchString(body)})
+	}
+	return results
+}
+
+// checkRun builds the check run reporting the checklist result for a pull request.
+func (m *ChecklistModule) checkRun(pr *github.PullRequest) internal.CheckRun {
+	run := internal.CheckRun{
+		Name:    m.config.CheckName,
+		HeadSHA: pr.GetHead().GetSHA(),
+		Status:  internal.CheckStatusCompleted,
+	}
+	if m.config.SkipLabel != "" && hasLabel(pr.Labels, m.config.SkipLabel) {
+		run.Conclusion = internal.CheckConclusionSkipped
+		run.Title = "Checklist skipped"
+		run.Summary = fmt.Sprintf("The `%s` label is applied.", m.config.SkipLabel)
+		return run
+	}
+
+	results := m.Evaluate(pr.GetBody())
+	failed := 0
+	var b strings.Builder
+	b.WriteString("| Item | Result |\n|------|--------|\n")
+	for _, r := range results {
+		mark := "✅"
+		if !r.Passed {
+			mark = "❌"
+			failed++
+		}
+		fmt.Fprintf(&b, "| %s | %s |\n", r.Item.Description, mark)
+	}
+	run.Summary = b.String()
+
+	if failed == 0 {
+		run.Conclusion = internal.CheckConclusionSuccess
+		run.Title = "All checklist items satisfied"
+	} else {
+		run.Conclusion = internal.CheckConclusionFailure
+		run.Title = fmt.Sprintf("%d of %d checklist items missing", failed, len(results))
+		run.Summary += "\nUpdate the pull request description; the check re-runs when it is edited.\n"
+	}
+	return run
+}
@@ -0,0 +1,101 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v71/github"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+func newChecklistTestModule(t *testing.T) (*ChecklistModule, *fakeGitHub) {
+	fake := newFakeGitHub()
+	mod := &ChecklistModule{}
+	if err := mod.Initialize(t.Context(), &internal.App{GitHubClient: fake.client(t)}); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	return mod, fake
+}
+
+func pullRequestEvent(action, repo string, number int, body string, labels ...string) *github.PullRequestEvent {
+	pr := &github.PullRequest{
+		Number: github.Ptr(number),
+		Body:   github.Ptr(body),
+		Head:   &github.PullRequestBranch{SHA: github.Ptr("abc123")},
+	}
+	for _, l := range labels {
+		pr.Labels = append(pr.Labels, &github.Label{Name: github.Ptr(l)})
+	}
+	return &github.PullRequestEvent{
+		Action:      github.Ptr(action),
+		Repo:        &github.Repository{FullName: github.Ptr(repo)},
+		PullRequest: pr,
+	}
+}
+
+func TestChecklistEvaluate(t *testing.T) {
+	mod, _ := newChecklistTestModule(t)
+
+	tests := []struct {
+		name string
+		body string
+		want map[string]bool
+	}{
+		{
+			name: "complete",
+			body: "Fixes #12\n\n- [x] CHANGELOG entry added\n- [X] Tests added",
+			want: map[string]bool{"changelog": true, "tests": true, "linked-issue": true},
+		},
+		{
+			name: "unchecked boxes",
+			body: "Closes open-telemetry/repo#3\n- [ ] changelog\n- [ ] tests",
+			want: map[string]bool{"changelog": false, "tests": false, "linked-issue": true},
+		},
+		{
+			name: "mention without keyword",
+			body: "Related to #12\n- [x] changelog",
+			want: map[string]bool{"changelog": true, "tests": false, "linked-issue": false},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, r := range mod.Evaluate(tt.body) {
+				if r.Passed != tt.want[r.Item.Name] {
+					t.Errorf("item %s passed = %v, want %v", r.Item.Name, r.Passed, tt.want[r.Item.Name])
+				}
+			}
+		})
+	}
+}
+
+func TestChecklistCheckRun(t *testing.T) {
+	mod, fake := newChecklistTestModule(t)
+
+	if err := mod.HandleEvent("pull_request", pullRequestEvent("opened", "org/repo", 1, "no checklist"), nil); err != nil {
+		t.Fatalf("HandleEvent failed: %v", err)
+	}
+	body := "Fixes #1\n- [x] changelog\n- [x] tests"
+	if err := mod.HandleEvent("pull_request", pullRequestEvent("edited", "org/repo", 1, body), nil); err != nil {
+		t.Fatalf("HandleEvent failed: %v", err)
+	}
+	if err := mod.HandleEvent("pull_request", pullRequestEvent("opened", "org/repo", 2, "", "skip-checklist"), nil); err != nil {
+		t.Fatalf("HandleEvent failed: %v", err)
+	}
+
+	runs := fake.checkRunsFor("org/repo")
+	if len(runs) != 3 {
+		t.Fatalf("got %d check runs, want 3", len(runs))
+	}
+	want := []string{"failure", "success", "skipped"}
+	for i, run := range runs {
+		if run.GetConclusion() != want[i] || run.HeadSHA != "abc123" || run.Name != "otto/pr-checklist" {
+			t.Errorf("run %d: conclusion %q, want %q", i, run.GetConclusion(), want[i])
+		}
+	}
+	if !strings.Contains(runs[0].Output.GetTitle(), "3 of 3") {
+		t.Errorf("unexpected failure title: %q", runs[0].Output.GetTitle())
+	}
+}
@@ -0,0 +1,314 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v71/github"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+// CIHealthConfig configures the CI failure budget of default branches.
+type CIHealthConfig struct {
+	Window         time.Duration `yaml:"window" doc:"rolling window the failure rate is computed over"`
+	MinRuns        int           `yaml:"min_runs" doc:"runs in the window before the failure rate is judged"`
+	MaxFailureRate float64       `yaml:"max_failure_rate" doc:"failure budget: share of runs that may fail, e.g. 0.2"`
+	SustainedFor   time.Duration `yaml:"sustained_for" doc:"how long the budget is exceeded before alerting"`
+	Workflows      []string      `yaml:"workflows" doc:"names of the workflows counted; default: all"`
+	Labels         []string      `yaml:"labels" doc:"labels of the CI health issue"`
+}
+
+// CIHealthModule tracks the failure rate of workflow runs on each repository's default
+// branch over a rolling window. When more runs fail than the failure budget allows for
+// longer than the sustained window, it opens a "CI health" issue, kept up to date as runs
+// complete, and alerts the operators; once the rate is back within the budget, the issue
+// is closed.
+type CIHealthModule struct {
+	app    *internal.App
+	config CIHealthConfig
+	now    func() time.Time
+}
+
+func (m *CIHealthModule) Name() string { return "cihealth" }
+
+// ConfigSchema implements the ModuleConfigSchema interface.
+func (m *CIHealthModule) ConfigSchema() any {
+	c := defaultCIHealthConfig()
+	return &c
+}
+
+// Validate implements the internal.ModuleConfigValidator interface.
+func (c *CIHealthConfig) Validate() error {
+	switch {
+	case c.Window <= 0:
+		return fmt.Errorf("window must be positive, got %s", c.Window)
+	case c.MinRuns <= 0:
+		return fmt.Errorf("min_runs must be positive, got %d", c.MinRuns)
+	case c.MaxFailureRate <= 0 || c.MaxFailureRate >= 1:
+		return fmt.Errorf("max_failure_rate must be between 0 and 1, got %g", c.MaxFailureRate)
+	case c.SustainedFor < 0:
+		return fmt.Errorf("sustained_for must not be negative, got %s", c.SustainedFor)
+	}
+	return nil
+}
+
+// defaultCIHealthConfig returns the CI health module's defaults.
+func defaultCIHealthConfig() CIHealthConfig {
+	return CIHealthConfig{
+		Window:         7 * 24 * time.Hour,
+		MinRuns:        10,
+		MaxFailureRate: 0.2,
+		SustainedFor:   24 * time.Hour,
+		Labels:         []string{"ci-health"},
+	}
+}
+
+// EventSubscriptions implements the ModuleEventSubscriber interface.
+func (m *CIHealthModule) EventSubscriptions() []internal.EventSubscription {
+	return []internal.EventSubscription{
+		internal.Subscribe("workflow_run", "completed"),
+	}
+}
+
+// GitHubPermissions implements the ModulePermissionRequirer interface.
+func (m *CIHealthModule) GitHubPermissions() map[string]string {
+	return map[string]string{"issues": "write"}
+}
+
+// Initialize implements the ModuleInitializer interface.
+func (m *CIHealthModule) Initialize(ctx context.Context, app *internal.App) error {
+	m.app = app
+	if m.now == nil {
+		m.now = time.Now
+	}
+	m.config = defaultCIHealthConfig()
+	if err := loadModuleConfig(app, m.Name(), &m.config); err != nil {
+		return err
+	}
+	return AutoMigrateCIHealth(app.Database.DB())
+}
+
+func (m *CIHealthModule) HandleEvent(eventType string, event any, raw json.RawMessage) error {
+	if eventType != "workflow_run" {
+		return nil
+	}
+	runEvent, ok := event.(*github.WorkflowRunEvent)
+	if !ok || runEvent.GetAction() != "completed" {
+		return nil
+	}
+	return m.handleRun(context.Background(), runEvent)
+}
+
+// ciRunFailed reports whether a run with a conclusion failed, and whether it counts
+// towards the failure rate at all: cancelled and skipped runs do not.
+func ciRunFailed(conclusion string) (failed, counted bool) {
+	switch conclusion {
+	case "success":
+		return false, true
+	case "failure", "timed_out", "startup_failure":
+		return true, true
+	}
+	return false, false
+}
+
+// handleRun records a run on the default branch and updates the repository's failure
+// budget with it.
+func (m *CIHealthModule) handleRun(ctx context.Context, event *github.WorkflowRunEvent) error {
+	run := event.GetWorkflowRun()
+	repo := event.GetRepo().GetFullName()
+	branch := event.GetRepo().GetDefaultBranch()
+	if run.GetEvent() == "pull_request" || run.GetHeadBranch() != branch {
+		return nil
+	}
+	if len(m.config.Workflows) > 0 && !slices.Contains(m.config.Workflows, run.GetName()) {
+		return nil
+	}
+	failed, counted := ciRunFailed(run.GetConclusion())
+	if !counted {
+		return nil
+	}
+
+	now := m.now()
+	db := m.app.Database.DB()
+	if err := RecordCIRun(db, repo, run.GetID(), run.GetName(), failed, now); err != nil {
+		return m.dbError(err, "record_ci_run", repo)
+	}
+	if err := PruneCIRuns(db, now.Add(-m.config.Window)); err != nil {
+		return m.dbError(err, "prune_ci_runs", repo)
+	}
+	stats, err := GetCIStats(db, repo, now.Add(-m.config.Window))
+	if err != nil {
+		return m.dbError(err, "get_ci_stats", repo)
+	}
+	state, err := GetCIHealth(db, repo)
+	if err != nil {
+		return m.dbError(err, "get_ci_health", repo)
+	}
+
+	switch {
+	case stats.Runs >= m.config.MinRuns && stats.FailureRate() > m.config.MaxFailureRate:
+		if state.OverSince == nil {
+			state.OverSince = &now
+		}
+		if now.Sub(*state.OverSince) < m.config.SustainedFor {
+			break
+		}
+		body := m.renderIssue(branch, stats, *state.OverSince)
+		url, err := m.upsertIssue(ctx, repo, &state, body)
+		if err != nil {
+			return m.wrap(err, "ci_health_issue", repo, state.Issue)
+		}
+		if state.AlertedAt == nil {
+			state.AlertedAt = &now
+			m.notify(ctx, internal.Notification{
+				Module:   m.Name(),
+				Repo:     repo,
+				Issue:    state.Issue,
+				Severity: internal.SeverityWarning,
+				Title:    fmt.Sprintf("CI on %s of %s is over its failure budget", branch, repo),
+				Body: fmt.Sprintf("%d of %d runs failed in the last %s (%.0f%%, budget %.0f%%).",
+					stats.Failures, stats.Runs, formatWindow(m.config.Window), 100*stats.FailureRate(),
+					100*m.config.MaxFailureRate),
+				URL: url,
+			})
+		}
+	case state.OverSince != nil:
+		if state.Issue != 0 {
+			if err := m.closeIssue(ctx, repo, state.Issue, stats); err != nil {
+				return m.wrap(err, "ci_health_issue", repo, state.Issue)
+			}
+		}
+		if state.AlertedAt != nil {
+			m.notify(ctx, internal.Notification{
+				Module:   m.Name(),
+				Repo:     repo,
+				Issue:    state.Issue,
+				Severity: internal.SeverityInfo,
+				Title:    fmt.Sprintf("CI on %s of %s is back within its failure budget", branch, repo),
+			})
+		}
+		state = CIHealthState{}
+	default:
+		return nil
+	}
+	if err := SaveCIHealth(db, repo, state); err != nil {
+		return m.dbError(err, "save_ci_health", repo)
+	}
+	return nil
+}
+
+// renderIssue renders the body of the CI health issue.
+func (m *CIHealthModule) renderIssue(branch string, stats CIStats, overSince time.Time) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "CI on `%s` has been over its failure budget since %s.\n\n", branch,
+		overSince.UTC().Format("2006-01-02 15:04 MST"))
+	b.WriteString("| | |\n|---|---|\n")
+	fmt.Fprintf(&b, "| Runs in the last %s | %d |\n", formatWindow(m.config.Window), stats.Runs)
+	fmt.Fprintf(&b, "| Failed | %d (%.1f%%) |\n", stats.Failures, 100*stats.FailureRate())
+	fmt.Fprintf(&b, "| Budget | %.1f%% (%d failures) |\n", 100*m.config.MaxFailureRate,
+		int(m.config.MaxFailureRate*float64(stats.Runs)))
+	b.WriteString("\nThis issue is updated as runs complete and closed once the failure rate is back within " +
+		"the budget.")
+	return b.String()
+}
+
+// upsertIssue opens the CI health issue, recording its number in state, or updates the
+// open one, and returns its URL.
+func (m *CIHealthModule) upsertIssue(
+	ctx context.Context,
+	repo string,
+	state *CIHealthState,
+	body string,
+) (string, error) {
+	if m.app.GitHubClient == nil {
+		slog.Info("GitHub issue would be opened (no GitHub client available)", "repo", repo, "body", body)
+		return "", nil
+	}
+	owner, name, err := internal.SplitRepo(repo)
+	if err != nil {
+		return "", err
+	}
+	if state.Issue != 0 {
+		issue, _, err := m.app.GitHubClient.Issues.Edit(ctx, owner, name, state.Issue,
+			&github.IssueRequest{Body: github.Ptr(body)})
+		return issue.GetHTMLURL(), err
+	}
+	issue, _, err := m.app.GitHubClient.Issues.Create(ctx, owner, name, &github.IssueRequest{
+		Title:  github.Ptr("CI health: failure budget exceeded"),
+		Body:   github.Ptr(body),
+		Labels: &m.config.Labels,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to open CI health issue in %s: %w", repo, err)
+	}
+	state.Issue = issue.GetNumber()
+	return issue.GetHTMLURL(), nil
+}
+
+// closeIssue comments on the CI health issue that the failure rate has recovered and
+// closes it.
+func (m *CIHealthModule) closeIssue(ctx context.Context, repo string, number int, stats CIStats) error {
+	body := fmt.Sprintf("CI is back within its failure budget: %d of %d runs failed in the last %s (%.1f%%).",
+		stats.Failures, stats.Runs, formatWindow(m.config.Window), 100*stats.FailureRate())
+	if m.app.GitHubClient == nil {
+		slog.Info("GitHub issue would be closed (no GitHub client available)", "repo", repo, "issue_num", number)
+		return nil
+	}
+	if err := m.app.PostComment(ctx, m.Name(), repo, number, body); err != nil {
+		return err
+	}
+	owner, name, err := internal.SplitRepo(repo)
+	if err != nil {
+		return err
+	}
+	_, _, err = m.app.GitHubClient.Issues.Edit(ctx, owner, name, number, &github.IssueRequest{
+		State:       github.Ptr("closed"),
+		StateReason: github.Ptr("completed"),
+	})
+	return err
+}
+
+// notify sends a notification, logging rather than returning failures so the budget's
+// state is still saved.
+func (m *CIHealthModule) notify(ctx context.Context, notification internal.Notification) {
+	if err := m.app.Notifications.Notify(ctx, notification); err != nil {
+		slog.Error("Failed to notify CI health", "repo", notification.Repo, "error", err)
+	}
+}
+
+// formatWindow renders a window in days when it is a whole number of days, e.g. "7 days".
+func formatWindow(d time.Duration) string {
+	switch {
+	case d == 24*time.Hour:
+		return "day"
+	case d%(24*time.Hour) == 0:
+		return fmt.Sprintf("%d days", d/(24*time.Hour))
+	}
+	return d.String()
+}
+
+func (m *CIHealthModule) dbError(err error, op, repo string) error {
+	return internal.LogAndWrapError(err, internal.ErrorTypeDatabase, op, map[string]any{
+		"module": m.Name(),
+		"repo":   repo,
+	})
+}
+
+func (m *CIHealthModule) wrap(err error, op, repo string, num int) error {
+	if err == nil {
+		return nil
+	}
+	return internal.LogAndWrapError(err, internal.ErrorTypeModule, op, map[string]any{
+		"module": m.Name(),
+		"repo":   repo,
+		"issue":  num,
+	})
+}
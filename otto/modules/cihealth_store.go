@@ -0,0 +1,119 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+// CIStats counts the workflow runs on a repository's default branch in a window.
+type CIStats struct {
+	Runs     int
+	Failures int
+}
+
+// FailureRate returns the share of runs that failed, or 0 without runs.
+func (s CIStats) FailureRate() float64 {
+	if s.Runs == 0 {
+		return 0
+	}
+	return float64(s.Failures) / float64(s.Runs)
+}
+
+// CIHealthState is what the module remembers of a repository's failure budget.
+type CIHealthState struct {
+	OverSince *time.Time // since when the budget has been exceeded; nil while within it
+	Issue     int        // the open CI health issue; 0 if none
+	AlertedAt *time.Time // when operators were alerted about the current breach
+}
+
+func AutoMigrateCIHealth(db *sql.DB) error {
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS ci_runs (
+			run_id INTEGER PRIMARY KEY,
+			repo TEXT NOT NULL,
+			workflow TEXT NOT NULL,
+			failed INTEGER NOT NULL,
+			completed_at TIMESTAMP NOT NULL
+		);`,
+		`CREATE INDEX IF NOT EXISTS idx_ci_runs_repo ON ci_runs(repo, completed_at);`,
+		`CREATE TABLE IF NOT EXISTS ci_health (
+			repo TEXT PRIMARY KEY,
+			over_since TIMESTAMP,
+			issue INTEGER NOT NULL DEFAULT 0,
+			alerted_at TIMESTAMP
+		);`,
+	}
+	for _, s := range stmts {
+		if _, err := db.Exec(s); err != nil {
+			return fmt.Errorf("failed migration: %w (SQL: %s)", err, s)
+		}
+	}
+	return nil
+}
+
+// Migrate implements the ModuleMigrator interface.
+func (m *CIHealthModule) Migrate(db *sql.DB) error {
+	return AutoMigrateCIHealth(db)
+}
+
+// RecordCIRun records the outcome of a workflow run. A re-run replaces the outcome of its
+// earlier attempt.
+func RecordCIRun(db *sql.DB, repo string, runID int64, workflow string, failed bool, at time.Time) error {
+	_, err := db.Exec(
+		`INSERT INTO ci_runs (run_id, repo, workflow, failed, completed_at) VALUES (?, ?, ?, ?, ?)
+		 ON CONFLICT (run_id) DO UPDATE SET failed = excluded.failed, completed_at = excluded.completed_at`,
+		runID, repo, workflow, failed, at,
+	)
+	return err
+}
+
+// GetCIStats counts the runs of a repository completed since a time.
+func GetCIStats(db *sql.DB, repo string, since time.Time) (CIStats, error) {
+	var stats CIStats
+	err := db.QueryRow(
+		`SELECT COUNT(*), COALESCE(SUM(failed), 0) FROM ci_runs WHERE repo = ? AND completed_at >= ?`,
+		repo, since,
+	).Scan(&stats.Runs, &stats.Failures)
+	return stats, err
+}
+
+// PruneCIRuns deletes the runs completed before a time.
+func PruneCIRuns(db *sql.DB, before time.Time) error {
+	_, err := db.Exec(`DELETE FROM ci_runs WHERE completed_at < ?`, before)
+	return err
+}
+
+// GetCIHealth returns the state of a repository's failure budget; the zero state if none
+// is stored.
+func GetCIHealth(db *sql.DB, repo string) (CIHealthState, error) {
+	var state CIHealthState
+	err := db.QueryRow(`SELECT over_since, issue, alerted_at FROM ci_health WHERE repo = ?`, repo).
+		Scan(&state.OverSince, &state.Issue, &state.AlertedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return CIHealthState{}, nil
+	}
+	return state, err
+}
+
+// SaveCIHealth replaces the state of a repository's failure budget.
+func SaveCIHealth(db *sql.DB, repo string, state CIHealthState) error {
+	_, err := db.Exec(
+		`INSERT INTO ci_health (repo, over_since, issue, alerted_at) VALUES (?, ?, ?, ?)
+		 ON CONFLICT (repo) DO UPDATE SET over_since = excluded.over_since, issue = excluded.issue,
+		 alerted_at = excluded.alerted_at`,
+		repo, state.OverSince, state.Issue, state.AlertedAt,
+	)
+	return err
+}
+
+// RenameRepo implements the ModuleRepoRenamer interface.
+func (m *CIHealthModule) RenameRepo(ctx context.Context, from, to string) error {
+	return internal.RenameRepoIn(ctx, m.app.Database.DB(), from, to, "ci_runs.repo", "ci_health.repo")
+}
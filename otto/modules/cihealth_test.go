@@ -0,0 +1,119 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v71/github"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+)
+
+// ciRunEvent returns a completed workflow run of the build workflow.
+func ciRunEvent(id int64, event, branch, conclusion string) *github.WorkflowRunEvent {
+	return &github.WorkflowRunEvent{
+		Action: github.Ptr("completed"),
+		Repo:   &github.Repository{FullName: github.Ptr("org/repo"), DefaultBranch: github.Ptr("main")},
+		WorkflowRun: &github.WorkflowRun{
+			ID:         github.Ptr(id),
+			Name:       github.Ptr("build"),
+			Event:      github.Ptr(event),
+			HeadBranch: github.Ptr(branch),
+			Conclusion: github.Ptr(conclusion),
+		},
+	}
+}
+
+func TestCIHealthBudget(t *testing.T) {
+	fake := newFakeGitHub()
+	notifications, err := internal.NewNotifications(config.NotificationsConfig{
+		Channels: map[string]config.NotificationChannel{"ci": {Backend: "slack", Target: "C0CI"}},
+		Routes:   []config.NotificationRoute{{Modules: []string{"cihealth"}, Channels: []string{"ci"}}},
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewNotifications failed: %v", err)
+	}
+	slack := &advisoryNotifier{backend: "slack"}
+	notifications.Register(slack)
+
+	now := time.Date(2025, time.March, 3, 9, 0, 0, 0, time.UTC)
+	mod := &CIHealthModule{now: func() time.Time { return now }}
+	app := &internal.App{
+		GitHubClient:  fake.client(t),
+		Database:      internal.NewDatabaseFromDB(internal.TestDB(t)),
+		Notifications: notifications,
+	}
+	if err := mod.Initialize(t.Context(), app); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	mod.config.MinRuns = 4
+	mod.config.MaxFailureRate = 0.25
+	mod.config.SustainedFor = time.Hour
+
+	runID := int64(0)
+	handle := func(event, branch, conclusion string) {
+		t.Helper()
+		runID++
+		if err := mod.HandleEvent("workflow_run", ciRunEvent(runID, event, branch, conclusion), nil); err != nil {
+			t.Fatalf("HandleEvent failed: %v", err)
+		}
+	}
+
+	// Pull requests, other branches and cancelled runs do not count.
+	handle("pull_request", "main", "failure")
+	handle("push", "feature", "failure")
+	handle("push", "main", "cancelled")
+	for _, conclusion := range []string{"success", "success", "failure", "timed_out"} {
+		handle("push", "main", conclusion)
+	}
+	stats, err := GetCIStats(app.Database.DB(), "org/repo", now.Add(-time.Hour))
+	if err != nil || stats != (CIStats{Runs: 4, Failures: 2}) {
+		t.Fatalf("stats = %+v, %v; want 4 runs, 2 failures", stats, err)
+	}
+	if opened := fake.openedIssues("org/repo"); len(opened) != 0 {
+		t.Fatalf("issue opened before the breach was sustained: %v", opened)
+	}
+
+	// Once sustained, an issue is opened and the operators alerted, once.
+	now = now.Add(2 * time.Hour)
+	handle("schedule", "main", "failure")
+	opened := fake.openedIssues("org/repo")
+	if len(opened) != 1 || !strings.Contains(opened[0].GetBody(), "| Failed | 3 (60.0%) |") ||
+		!strings.Contains(opened[0].GetBody(), "since 2025-03-03 09:00 UTC") {
+		t.Fatalf("opened = %+v", opened)
+	}
+	want := "C0CI: CI on main of org/repo is over its failure budget"
+	if len(slack.sent) != 1 || slack.sent[0] != want {
+		t.Errorf("sent = %q, want %q", slack.sent, want)
+	}
+	now = now.Add(time.Hour)
+	handle("push", "main", "failure")
+	if body := fake.bodies["org/repo#1001"]; !strings.Contains(body, "| Failed | 4 (66.7%) |") {
+		t.Errorf("issue body was not updated:\n%s", body)
+	}
+	if len(fake.openedIssues("org/repo")) != 1 || len(slack.sent) != 1 {
+		t.Errorf("opened %d issues and sent %q, want one of each", len(fake.openedIssues("org/repo")), slack.sent)
+	}
+
+	// Once the failures leave the window, the issue is closed.
+	now = now.Add(8 * 24 * time.Hour)
+	handle("push", "main", "success")
+	if state := fake.stateOf("org/repo", 1001); state != "closed" {
+		t.Errorf("issue state = %q, want closed", state)
+	}
+	if comments := fake.commentsOn("org/repo", 1001); len(comments) != 1 ||
+		!strings.Contains(comments[0], "back within its failure budget: 0 of 1 runs failed") {
+		t.Errorf("comments = %q", comments)
+	}
+	if len(slack.sent) != 2 || !strings.Contains(slack.sent[1], "back within its failure budget") {
+		t.Errorf("sent = %q", slack.sent)
+	}
+	if state, err := GetCIHealth(app.Database.DB(), "org/repo"); err != nil || state.OverSince != nil ||
+		state.Issue != 0 {
+		t.Errorf("state = %+v, %v; want reset", state, err)
+	}
+}
@@ -0,0 +1,88 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+// repoConfigFile is the file in which repositories configure modules, with settings under
+// modules: like config.yaml.
+const repoConfigFile = ".github/otto.yml"
+
+// repoConfigTTL is how long a repository's config file is reused before it is read again.
+const repoConfigTTL = 5 * time.Minute
+
+// loadModuleConfig decodes the module's section of AppConfig.Modules into out; see
+// App.ModuleConfig. A missing section leaves out untouched, so callers should pre-populate
+// defaults.
+func loadModuleConfig(app *internal.App, name string, out any) error {
+	return app.ModuleConfig(name, out)
+}
+
+// checkRepos validates a module's repository lists, whose entries may refer to the app's
+// repository groups.
+func checkRepos(app *internal.App, module string, lists ...[]string) error {
+	for _, repos := range lists {
+		if err := repoGroups(app).Check(repos); err != nil {
+			return fmt.Errorf("%s: repos: %w", module, err)
+		}
+	}
+	return nil
+}
+
+// reposInclude reports whether repo is in a module's repository list; an empty list
+// includes every repository.
+func reposInclude(app *internal.App, repos []string, repo string) bool {
+	return len(repos) == 0 || repoGroups(app).Match(repos, repo)
+}
+
+// repoGroups returns the app's repository groups, or nil without an app.
+func repoGroups(app *internal.App) *internal.RepoGroups {
+	if app == nil {
+		return nil
+	}
+	return app.RepoGroups
+}
+
+// loadRepoModuleConfig decodes the module's section of the repository's config file on its
+// default branch into out, over the values already in it, so callers should pre-populate
+// out with the module's config. Without a GitHub client, a file or a section, out is left
+// untouched. The file is cached in the app's cache.
+func loadRepoModuleConfig(ctx context.Context, app *internal.App, repo, name string, out any) error {
+	if app == nil || app.GitHubClient == nil {
+		return nil
+	}
+	key := "repoconfig:" + repo
+	content, ok := internal.GetCached[string](ctx, app.Cache, key)
+	if !ok {
+		file, err := internal.GetFile(ctx, app.GitHubClient, repo, repoConfigFile, "")
+		if err != nil {
+			return err
+		}
+		if file != nil {
+			content = file.Content
+		}
+		internal.SetCached(ctx, app.Cache, key, content, repoConfigTTL)
+	}
+	var parsed struct {
+		Modules map[string]any `yaml:"modules"`
+	}
+	if err := yaml.Unmarshal([]byte(content), &parsed); err != nil {
+		return fmt.Errorf("invalid %s in %s: %w", repoConfigFile, repo, err)
+	}
+	var module internal.Module
+	if app.ModuleRegistry != nil {
+		module = app.GetModules()[name]
+	}
+	if err := internal.DecodeModuleConfig(module, name, parsed.Modules[name], out); err != nil {
+		return fmt.Errorf("%s in %s: %w", repoConfigFile, repo, err)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+)
+
+func TestLoadModuleConfigMigrates(t *testing.T) {
+	app := &internal.App{
+		Config: &config.AppConfig{Modules: map[string]any{
+			"versioned": map[string]any{"approver": []any{"alice"}},
+			"newer":     map[string]any{"config_version": 3},
+		}},
+		ModuleRegistry: internal.NewModuleRegistry(),
+	}
+	app.RegisterModule(versionedModule{})
+
+	var cfg ApprovalConfig
+	if err := loadModuleConfig(app, "versioned", &cfg); err != nil {
+		t.Fatalf("loadModuleConfig failed: %v", err)
+	}
+	if !slices.Equal(cfg.Approvers, []string{"alice"}) {
+		t.Errorf("approvers = %v, want the migrated [alice]", cfg.Approvers)
+	}
+	if err := loadModuleConfig(app, "newer", &cfg); err == nil {
+		t.Error("expected an error for a config_version newer than the module supports")
+	}
+}
+
+func TestModuleConfigsDocumented(t *testing.T) {
+	modules := []internal.Module{
+		&ApprovalModule{}, &BulkLabelModule{}, &ChangelogModule{}, &ChecklistModule{}, &ConfigCheckModule{},
+		&CoverageModule{}, &DigestModule{}, &GoodFirstIssuesModule{}, &HistoryModule{}, &HoldModule{},
+		&InactivityModule{}, &LinkedIssueModule{}, &OnboardingModule{}, &OnCallModule{}, &OwnersModule{},
+		&PathLabelsModule{}, &SignatureModule{}, &SizeLimitModule{}, &SLAModule{}, &StatusModule{},
+		&TemplateSyncModule{},
+	}
+	for _, m := range internal.NewConfigReference(modules).Modules {
+		for _, f := range m.Fields {
+			if f.Description == "" {
+				t.Errorf("%s config key %s has no doc tag", m.Module, f.Key)
+			}
+		}
+	}
+}
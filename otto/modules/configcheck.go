@@ -0,0 +1,449 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-github/v71/github"
+	"gopkg.in/yaml.v3"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+// ConfigCheckConfig configures `/otto config check`.
+type ConfigCheckConfig struct {
+	File string `yaml:"file" doc:"repository Otto config file, with module settings under modules:"`
+}
+
+// Severities of config findings.
+const (
+	ConfigError   = "error"
+	ConfigWarning = "warning"
+)
+
+// ConfigFinding is a problem found in a repository's Otto config file.
+type ConfigFinding struct {
+	Severity string
+	Module   string // empty for problems with the file as a whole
+	Line     int    // 0 if unknown
+	Message  string
+}
+
+// yamlErrorLine matches the line prefix of yaml.v3 decoding errors.
+var yamlErrorLine = regexp.MustCompile(`^(?:yaml: )?line (\d+): `)
+
+// yamlUnknownField matches yaml.v3's error for a key without a matching struct field.
+var yamlUnknownField = regexp.MustCompile(`^field (\S+) not found in type .+$`)
+
+// ConfigCheckModule answers `/otto config check` by validating the repository's Otto config
+// file against the settings of every registered module. On a pull request, the file on the
+// pull request's head is checked, so changes can be validated before they are merged.
+type ConfigCheckModule struct {
+	app    *internal.App
+	config ConfigCheckConfig
+}
+
+func (m *ConfigCheckModule) Name() string { return "configcheck" }
+
+// ConfigSchema implements the ModuleConfigSchema interface.
+func (m *ConfigCheckModule) ConfigSchema() any {
+	c := defaultConfigCheckConfig()
+	return &c
+}
+
+// defaultConfigCheckConfig returns the configcheck module's defaults.
+func defaultConfigCheckConfig() ConfigCheckConfig {
+	return ConfigCheckConfig{File: repoConfigFile}
+}
+
+// EventSubscriptions implements the ModuleEventSubscriber interface. The module consumes
+// no events: the app hands it `/otto config check` commands through HandleCommand.
+func (m *ConfigCheckModule) EventSubscriptions() []internal.EventSubscription {
+	return nil
+}
+
+// GitHubPermissions implements the ModulePermissionRequirer interface.
+func (m *ConfigCheckModule) GitHubPermissions() map[string]string {
+	return map[string]string{"contents": "read", "issues": "write", "pull_requests": "read"}
+}
+
+// SlashCommands implements the ModuleCommander interface.
+func (m *ConfigCheckModule) SlashCommands() []string {
+	return []string{"otto config"}
+}
+
+// Initialize implements the ModuleInitializer interface.
+func (m *ConfigCheckModule) Initialize(ctx context.Context, app *internal.App) error {
+	m.app = app
+	m.config = defaultConfigCheckConfig()
+	if err := loadModuleConfig(app, m.Name(), &m.config); err != nil {
+		return err
+	}
+	if m.config.File == "" {
+		return errors.New("configcheck: file must not be empty")
+	}
+	return nil
+}
+
+func (m *ConfigCheckModule) HandleEvent(eventType string, event any, raw json.RawMessage) error {
+	return nil
+}
+
+// HandleCommand implements the ModuleCommandHandler interface.
+func (m *ConfigCheckModule) HandleCommand(cmd *internal.CommandContext) error {
+	if len(cmd.Args) < 2 || cmd.Args[1] != "check" {
+		return nil
+	}
+	return m.check(cmd.Context, cmd.Event)
+}
+
+// check reads the config file, from the pull request's head on pull requests, and replies
+// with the findings.
+func (m *ConfigCheckModule) check(ctx context.Context, event *github.IssueCommentEvent) error {
+	repo := event.GetRepo().GetFullName()
+	issue := event.GetIssue().GetNumber()
+	if m.app == nil || m.app.GitHubClient == nil {
+		return m.comment(ctx, repo, issue, "⚠️ The config check needs GitHub access, which is not configured.")
+	}
+
+	ref := ""
+	if event.GetIssue().IsPullRequest() {
+		owner, name, err := internal.SplitRepo(repo)
+		if err != nil {
+			return m.wrap(err, "check_config", repo, issue)
+		}
+		pr, _, err := m.app.GitHubClient.PullRequests.Get(ctx, owner, name, issue)
+		if err != nil {
+			return m.wrap(err, "check_config", repo, issue)
+		}
+		ref = pr.GetHead().GetSHA()
+	}
+	file, err := internal.GetFile(ctx, m.app.GitHubClient, repo, m.config.File, ref)
+	if err != nil {
+		return m.wrap(err, "check_config", repo, issue)
+	}
+	where := "the default branch"
+	if ref != "" {
+		where = "`" + ref[:min(len(ref), 7)] + "`"
+	}
+	if file == nil {
+		msg := fmt.Sprintf("ℹ️ There is no `%s` on %s, so every module uses Otto's defaults.", m.config.File, where)
+		return m.wrap(m.comment(ctx, repo, issue, msg), "check_config", repo, issue)
+	}
+	findings := m.Validate(ctx, repo, []byte(file.Content))
+	return m.wrap(m.comment(ctx, repo, issue, configCheckMarkdown(m.config.File, where, findings)),
+		"check_config", repo, issue)
+}
+
+// Validate checks a repository's Otto config file: its YAML syntax, that every section
+// under modules: names a registered module that is enabled for the repository, and that
+// each section decodes into the module's settings without unknown or mistyped fields.
+// Findings are ordered by line.
+func (m *ConfigCheckModule) Validate(ctx context.Context, repo string, content []byte) []ConfigFinding {
+	var root yaml.Node
+	if err := yaml.Unmarshal(content, &root); err != nil {
+		return []ConfigFinding{yamlFinding(err.Error())}
+	}
+	if len(root.Content) == 0 {
+		return []ConfigFinding{{Severity: ConfigWarning, Message: "The file is empty."}}
+	}
+	doc := root.Content[0]
+	if doc.Kind != yaml.MappingNode {
+		return []ConfigFinding{{Severity: ConfigError, Line: doc.Line, Message: "The file must be a mapping."}}
+	}
+
+	var (
+		findings []ConfigFinding
+		modules  *yaml.Node
+		topLevel []reflect.StructField
+	)
+	for i := 0; i+1 < len(doc.Content); i += 2 {
+		key := doc.Content[i]
+		if key.Value == "modules" {
+			modules = doc.Content[i+1]
+			continue
+		}
+		findings = append(findings, ConfigFinding{
+			Severity: ConfigWarning,
+			Line:     key.Line,
+			Message:  fmt.Sprintf("Unknown top-level key `%s`; module settings belong under `modules:`.", key.Value),
+		})
+		topLevel = append(topLevel, reflect.StructField{
+			Name: "Top" + strconv.Itoa(i),
+			Type: reflect.TypeFor[any](),
+			Tag:  reflect.StructTag(fmt.Sprintf("yaml:%q", key.Value)),
+		})
+	}
+	if modules == nil || modules.Tag == "!!null" {
+		return sortFindings(findings)
+	}
+	if modules.Kind != yaml.MappingNode {
+		return sortFindings(append(findings, ConfigFinding{
+			Severity: ConfigError,
+			Line:     modules.Line,
+			Message:  "`modules` must map module names to their settings.",
+		}))
+	}
+
+	// Decode the file strictly into a struct with a field per module section, so errors
+	// carry the lines of the file itself.
+	registered := m.app.GetModules()
+	var (
+		sections    []reflect.StructField
+		moduleLines []int
+		moduleNames []string
+	)
+	for i := 0; i+1 < len(modules.Content); i += 2 {
+		key := modules.Content[i]
+		name := key.Value
+		moduleLines = append(moduleLines, key.Line)
+		moduleNames = append(moduleNames, name)
+		schema := reflect.TypeFor[any]()
+		mod, ok := registered[name]
+		switch {
+		case !ok:
+			findings = append(findings, ConfigFinding{
+				Severity: ConfigError, Module: name, Line: key.Line,
+				Message: "Unknown module; registered modules are " + moduleList(registered) + ".",
+			})
+		case !m.moduleEnabled(ctx, repo, name):
+			findings = append(findings, ConfigFinding{
+				Severity: ConfigWarning, Module: name, Line: key.Line,
+				Message: "The module is not enabled for this repository, so these settings have no effect.",
+			})
+		}
+		if s, ok := mod.(internal.ModuleConfigSchema); ok {
+			settings := reflect.TypeOf(s.ConfigSchema()).Elem()
+			versionFindings, current := checkConfigVersion(mod, name, key, modules.Content[i+1], settings)
+			findings = append(findings, versionFindings...)
+			if current {
+				schema = versionedSchema(settings)
+			}
+		} else if mod != nil {
+			findings = append(findings, ConfigFinding{
+				Severity: ConfigWarning, Module: name, Line: key.Line,
+				Message: "The module has no settings.",
+			})
+		}
+		sections = append(sections, reflect.StructField{
+			Name: "Module" + strconv.Itoa(i),
+			Type: schema,
+			Tag:  reflect.StructTag(fmt.Sprintf("yaml:%q", name)),
+		})
+	}
+	fileType := reflect.StructOf(append(topLevel, reflect.StructField{
+		Name: "Modules",
+		Type: reflect.StructOf(sections),
+		Tag:  `yaml:"modules"`,
+	}))
+
+	dec := yaml.NewDecoder(bytes.NewReader(content))
+	dec.KnownFields(true)
+	var typeErr *yaml.TypeError
+	if err := dec.Decode(reflect.New(fileType).Interface()); errors.As(err, &typeErr) {
+		for _, msg := range typeErr.Errors {
+			f := yamlFinding(msg)
+			// Attribute the error to the last module section starting at or before it.
+			if i := lastAtOrBefore(moduleLines, f.Line); i >= 0 {
+				f.Module = moduleNames[i]
+			}
+			findings = append(findings, f)
+		}
+	} else if err != nil {
+		findings = append(findings, yamlFinding(err.Error()))
+	}
+	return sortFindings(findings)
+}
+
+// versionedSchema returns a struct type for a module config section: the module's settings
+// and the config_version every section may carry.
+func versionedSchema(settings reflect.Type) reflect.Type {
+	return reflect.StructOf([]reflect.StructField{
+		{Name: "ConfigVersion", Type: reflect.TypeFor[int](), Tag: reflect.StructTag(`yaml:"config_version"`)},
+		{Name: "Settings", Type: settings, Tag: `yaml:",inline"`},
+	})
+}
+
+// checkConfigVersion checks a module section's config_version. Sections written for an
+// older format are migrated and the result is checked against the module's settings, with
+// findings on the module's line; it reports whether the section is in the current format
+// and can be checked line by line instead.
+func checkConfigVersion(mod internal.Module, name string, key, section *yaml.Node,
+	settings reflect.Type,
+) ([]ConfigFinding, bool) {
+	var values map[string]any
+	if section.Kind != yaml.MappingNode || section.Decode(&values) != nil {
+		return nil, true
+	}
+	version, err := internal.ConfigSectionVersion(values)
+	if err != nil {
+		return []ConfigFinding{{Severity: ConfigError, Module: name, Line: key.Line, Message: err.Error() + "."}}, false
+	}
+	current := internal.ModuleConfigVersion(mod)
+	if version == current {
+		return nil, true
+	}
+	if version > current {
+		return []ConfigFinding{{
+			Severity: ConfigError, Module: name, Line: key.Line,
+			Message: fmt.Sprintf("`config_version: %d` is newer than this Otto supports (%d).", version, current),
+		}}, false
+	}
+
+	findings := []ConfigFinding{{
+		Severity: ConfigWarning, Module: name, Line: key.Line,
+		Message: fmt.Sprintf("The settings are in the format of `config_version: %d` and are migrated "+
+			"to version %d when loaded; update them and set `config_version: %d`.", version, current, current),
+	}}
+	migrated, err := internal.MigrateModuleConfig(mod, name, values)
+	if err != nil {
+		return append(findings, ConfigFinding{
+			Severity: ConfigError, Module: name, Line: key.Line, Message: "Migration failed: " + err.Error() + ".",
+		}), false
+	}
+	data, err := yaml.Marshal(migrated)
+	if err != nil {
+		return findings, false
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	var typeErr *yaml.TypeError
+	if err := dec.Decode(reflect.New(settings).Interface()); errors.As(err, &typeErr) {
+		for _, msg := range typeErr.Errors {
+			f := yamlFinding(msg)
+			f.Module, f.Line = name, key.Line
+			f.Message = "After migration: " + f.Message
+			findings = append(findings, f)
+		}
+	}
+	return findings, false
+}
+
+// moduleEnabled reports whether a module is enabled for the repository in the registry.
+// Registry errors count as enabled, like they do for event dispatch.
+func (m *ConfigCheckModule) moduleEnabled(ctx context.Context, repo, module string) bool {
+	if m.app.Repos == nil {
+		return true
+	}
+	enabled, err := m.app.Repos.ModuleEnabled(ctx, repo, module)
+	if err != nil {
+		slog.Error("Failed to check module enablement", "repo", repo, "module", module, "err", err)
+		return true
+	}
+	return enabled
+}
+
+// yamlFinding turns a yaml.v3 error message into a finding, with the line it names.
+// Unknown fields are warnings, since they are ignored; everything else is an error.
+func yamlFinding(msg string) ConfigFinding {
+	f := ConfigFinding{Severity: ConfigError, Message: msg}
+	if match := yamlErrorLine.FindStringSubmatch(msg); match != nil {
+		f.Line, _ = strconv.Atoi(match[1])
+		f.Message = msg[len(match[0]):]
+	} else {
+		f.Message = strings.TrimPrefix(msg, "yaml: ")
+	}
+	if match := yamlUnknownField.FindStringSubmatch(f.Message); match != nil {
+		f.Severity = ConfigWarning
+		f.Message = fmt.Sprintf("Unknown setting `%s` is ignored.", match[1])
+	}
+	return f
+}
+
+// lastAtOrBefore returns the index of the last of the ascending lines that is at most
+// line, or -1.
+func lastAtOrBefore(lines []int, line int) int {
+	i, found := slices.BinarySearch(lines, line)
+	if found {
+		return i
+	}
+	return i - 1
+}
+
+// sortFindings orders findings by line, errors before warnings on the same line.
+func sortFindings(findings []ConfigFinding) []ConfigFinding {
+	slices.SortStableFunc(findings, func(a, b ConfigFinding) int {
+		return cmp.Or(cmp.Compare(a.Line, b.Line), cmp.Compare(a.Severity, b.Severity))
+	})
+	return findings
+}
+
+// moduleList returns the names of modules, sorted and formatted as code.
+func moduleList(modules map[string]internal.Module) string {
+	names := make([]string, 0, len(modules))
+	for name := range modules {
+		names = append(names, "`"+name+"`")
+	}
+	slices.Sort(names)
+	return strings.Join(names, ", ")
+}
+
+// configCheckMarkdown renders the findings for the file at where.
+func configCheckMarkdown(file, where string, findings []ConfigFinding) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "### Otto config check\n\nChecked `%s` on %s.\n\n", file, where)
+	if len(findings) == 0 {
+		b.WriteString("✅ No problems found.")
+		return b.String()
+	}
+	var errs, warnings int
+	for _, f := range findings {
+		if f.Severity == ConfigError {
+			errs++
+		} else {
+			warnings++
+		}
+	}
+	fmt.Fprintf(&b, "Found %d error(s) and %d warning(s):\n\n", errs, warnings)
+	for _, f := range findings {
+		icon := "⚠️"
+		if f.Severity == ConfigError {
+			icon = "❌"
+		}
+		var where []string
+		if f.Line > 0 {
+			where = append(where, "line "+strconv.Itoa(f.Line))
+		}
+		if f.Module != "" {
+			where = append(where, "`"+f.Module+"`")
+		}
+		if len(where) > 0 {
+			fmt.Fprintf(&b, "- %s %s: %s\n", icon, strings.Join(where, ", "), f.Message)
+		} else {
+			fmt.Fprintf(&b, "- %s %s\n", icon, f.Message)
+		}
+	}
+	return b.String()
+}
+
+func (m *ConfigCheckModule) comment(ctx context.Context, repo string, num int, body string) error {
+	if m.app == nil || m.app.GitHubClient == nil {
+		slog.Info("GitHub comment would be posted (no GitHub client available)",
+			"repo", repo, "issue_num", num, "message", body)
+		return nil
+	}
+	return m.app.PostComment(ctx, m.Name(), repo, num, body)
+}
+
+func (m *ConfigCheckModule) wrap(err error, op, repo string, num int) error {
+	if err == nil {
+		return nil
+	}
+	return internal.LogAndWrapError(err, internal.ErrorTypeModule, op, map[string]any{
+		"module": m.Name(),
+		"repo":   repo,
+		"issue":  num,
+	})
+}
@@ -0,0 +1,210 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v71/github"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+// versionedModule is at config version 2, which renamed approver to approvers.
+type versionedModule struct{}
+
+func (versionedModule) Name() string                                   { return "versioned" }
+func (versionedModule) HandleEvent(string, any, json.RawMessage) error { return nil }
+func (versionedModule) ConfigSchema() any                              { return &ApprovalConfig{} }
+func (versionedModule) ConfigVersion() int                             { return 2 }
+
+func (versionedModule) MigrateConfig(old map[string]any, fromVersion int) (map[string]any, error) {
+	old["approvers"] = old["approver"]
+	delete(old, "approver")
+	return old, nil
+}
+
+func newTestConfigCheck(t *testing.T, fake *fakeGitHub, extra ...internal.Module) *ConfigCheckModule {
+	t.Helper()
+	app := &internal.App{GitHubClient: fake.client(t), ModuleRegistry: internal.NewModuleRegistry()}
+	for _, m := range append([]internal.Module{&ApprovalModule{}, &HoldModule{}, &ConfirmModule{}}, extra...) {
+		app.RegisterModule(m)
+	}
+	repos, err := internal.NewRepoRegistry(internal.TestDB(t))
+	if err != nil {
+		t.Fatalf("NewRepoRegistry failed: %v", err)
+	}
+	if err := repos.Register(t.Context(), "org/onboarded", "alice", []string{"approvals"}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	app.Repos = repos
+	mod := &ConfigCheckModule{}
+	if err := mod.Initialize(t.Context(), app); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	return mod
+}
+
+func TestConfigCheckValidate(t *testing.T) {
+	mod := newTestConfigCheck(t, newFakeGitHub())
+	tests := []struct {
+		name    string
+		repo    string
+		content string
+		want    []ConfigFinding
+	}{
+		{
+			name: "valid",
+			repo: "org/repo",
+			content: `modules:
+  approvals:
+    approvers: [alice, org/maintainers]
+    reset_on_push: [lgtm]
+  holds:
+    check_interval: 30m
+`,
+		},
+		{name: "empty", repo: "org/repo", content: "", want: []ConfigFinding{
+			{Severity: ConfigWarning, Message: "The file is empty."},
+		}},
+		{name: "syntax error", repo: "org/repo", content: "modules:\n  approvals: [\n", want: []ConfigFinding{
+			{Severity: ConfigError, Line: 2, Message: "did not find expected node content"},
+		}},
+		{
+			name: "unknown and mistyped settings",
+			repo: "org/repo",
+			content: `modules:
+  approvals:
+    approver: [alice]
+    lgtm_label: [lgtm]
+  holds:
+    check_interval: soon
+`,
+			want: []ConfigFinding{
+				{Severity: ConfigWarning, Module: "approvals", Line: 3, Message: "Unknown setting `approver` is ignored."},
+				{Severity: ConfigError, Module: "approvals", Line: 4,
+					Message: "cannot unmarshal !!seq into string"},
+				{Severity: ConfigError, Module: "holds", Line: 6,
+					Message: "cannot unmarshal !!str `soon` into time.Duration"},
+			},
+		},
+		{
+			name: "unknown module, module without settings and stray keys",
+			repo: "org/repo",
+			content: `approvals:
+  approvers: [alice]
+modules:
+  aproval: {}
+  confirm: {}
+`,
+			want: []ConfigFinding{
+				{Severity: ConfigWarning, Line: 1,
+					Message: "Unknown top-level key `approvals`; module settings belong under `modules:`."},
+				{Severity: ConfigError, Module: "aproval", Line: 4,
+					Message: "Unknown module; registered modules are `approvals`, `confirm`, `holds`."},
+				{Severity: ConfigWarning, Module: "confirm", Line: 5, Message: "The module has no settings."},
+			},
+		},
+		{
+			name:    "module disabled for the repository",
+			repo:    "org/onboarded",
+			content: "modules:\n  approvals: {}\n  holds:\n    label: hold\n",
+			want: []ConfigFinding{
+				{Severity: ConfigWarning, Module: "holds", Line: 3,
+					Message: "The module is not enabled for this repository, so these settings have no effect."},
+			},
+		},
+		{name: "modules not a mapping", repo: "org/repo", content: "modules: [approvals]\n", want: []ConfigFinding{
+			{Severity: ConfigError, Line: 1, Message: "`modules` must map module names to their settings."},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mod.Validate(t.Context(), tt.repo, []byte(tt.content))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigCheckVersions(t *testing.T) {
+	mod := newTestConfigCheck(t, newFakeGitHub(), versionedModule{})
+	tests := []struct {
+		name    string
+		content string
+		want    []ConfigFinding
+	}{
+		{name: "current version", content: "modules:\n  versioned:\n    config_version: 2\n    approvers: [alice]\n"},
+		{name: "version of a module without migrations", content: "modules:\n  approvals:\n    config_version: 1\n"},
+		{
+			name:    "older version",
+			content: "modules:\n  versioned:\n    approver: [alice]\n    lgtm_label: [lgtm]\n",
+			want: []ConfigFinding{
+				{Severity: ConfigError, Module: "versioned", Line: 2,
+					Message: "After migration: cannot unmarshal !!seq into string"},
+				{Severity: ConfigWarning, Module: "versioned", Line: 2,
+					Message: "The settings are in the format of `config_version: 1` and are migrated to version 2 " +
+						"when loaded; update them and set `config_version: 2`."},
+			},
+		},
+		{
+			name:    "newer version",
+			content: "modules:\n  approvals:\n    config_version: 2\n",
+			want: []ConfigFinding{
+				{Severity: ConfigError, Module: "approvals", Line: 2,
+					Message: "`config_version: 2` is newer than this Otto supports (1)."},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mod.Validate(t.Context(), "org/repo", []byte(tt.content))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigCheckCommand(t *testing.T) {
+	fake := newFakeGitHub()
+	mod := newTestConfigCheck(t, fake)
+
+	check := commentEvent("org/repo", 1, "alice", "/otto config check")
+	if err := internal.TestCommands(t, mod, check); err != nil {
+		t.Fatalf("HandleCommand failed: %v", err)
+	}
+	if comments := fake.commentsOn("org/repo", 1); len(comments) != 1 ||
+		!strings.Contains(comments[0], "There is no `.github/otto.yml` on the default branch") {
+		t.Fatalf("unexpected reply without a config file: %v", comments)
+	}
+
+	fake.setFile("org/repo", fakeDefaultBranch, ".github/otto.yml", "modules:\n  approvals: {}\n")
+	if err := internal.TestCommands(t, mod, check); err != nil {
+		t.Fatalf("HandleCommand failed: %v", err)
+	}
+	comments := fake.commentsOn("org/repo", 1)
+	if len(comments) != 2 || !strings.Contains(comments[1], "No problems found") {
+		t.Fatalf("unexpected reply for a valid config: %v", comments)
+	}
+
+	// On a pull request, the file on its head is checked.
+	fake.setFile("org/repo", "abcdef1234", ".github/otto.yml", "modules:\n  holds:\n    label: [hold]\n")
+	fake.addPull("org/repo", &github.PullRequest{
+		Number: github.Ptr(2),
+		Head:   &github.PullRequestBranch{SHA: github.Ptr("abcdef1234")},
+	})
+	if err := internal.TestCommands(t, mod, prCommentEvent("org/repo", 2, "alice", "/otto config check")); err != nil {
+		t.Fatalf("HandleCommand failed: %v", err)
+	}
+	comments = fake.commentsOn("org/repo", 2)
+	if len(comments) != 1 || !strings.Contains(comments[0], "on `abcdef1`") ||
+		!strings.Contains(comments[0], "Found 1 error(s) and 0 warning(s)") ||
+		!strings.Contains(comments[0], "- ❌ line 3, `holds`: cannot unmarshal") {
+		t.Fatalf("unexpected reply for the pull request: %v", comments)
+	}
+}
@@ -0,0 +1,104 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/go-github/v71/github"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+// ConfirmModule runs destructive actions once their issuer replies `/confirm <token>`.
+// Other modules request confirmation through App.Confirmations.
+type ConfirmModule struct {
+	app *internal.App
+}
+
+func (m *ConfirmModule) Name() string { return "confirm" }
+
+// EventSubscriptions implements the ModuleEventSubscriber interface. The module consumes
+// no events: the app hands it `/confirm` commands through HandleCommand.
+func (m *ConfirmModule) EventSubscriptions() []internal.EventSubscription {
+	return nil
+}
+
+// GitHubPermissions implements the ModulePermissionRequirer interface.
+func (m *ConfirmModule) GitHubPermissions() map[string]string {
+	return map[string]string{"issues": "write"}
+}
+
+// SlashCommands implements the ModuleCommander interface.
+func (m *ConfirmModule) SlashCommands() []string {
+	return []string{"confirm"}
+}
+
+// Initialize implements the ModuleInitializer interface.
+func (m *ConfirmModule) Initialize(ctx context.Context, app *internal.App) error {
+	m.app = app
+	return nil
+}
+
+func (m *ConfirmModule) HandleEvent(eventType string, event any, raw json.RawMessage) error {
+	return nil
+}
+
+// HandleCommand implements the ModuleCommandHandler interface.
+func (m *ConfirmModule) HandleCommand(cmd *internal.CommandContext) error {
+	return m.confirm(cmd.Context, cmd.Event, cmd.Args)
+}
+
+// confirm runs the pending action for the token if the commenter requested it on this issue.
+func (m *ConfirmModule) confirm(ctx context.Context, event *github.IssueCommentEvent, args []string) error {
+	repo := event.GetRepo().GetFullName()
+	num := event.GetIssue().GetNumber()
+	login := event.GetComment().GetUser().GetLogin()
+	if m.app == nil || m.app.Confirmations == nil {
+		return nil
+	}
+	if len(args) == 0 {
+		return m.reply(ctx, repo, num, "⚠️ Usage: `/confirm <token>`")
+	}
+
+	result, err := m.app.Confirmations.Confirm(ctx, args[0], repo, num, login)
+	switch {
+	case errors.Is(err, internal.ErrConfirmationNotFound):
+		return m.reply(ctx, repo, num, fmt.Sprintf(
+			"⚠️ @%s there is no pending action for token `%s` on this issue. Tokens expire after %d minutes "+
+				"and can only be confirmed by whoever ran the command.",
+			login, args[0], int(internal.ConfirmationTTL.Minutes())))
+	case err != nil:
+		if replyErr := m.reply(ctx, repo, num, fmt.Sprintf("❌ The confirmed action failed: %v", err)); replyErr != nil {
+			slog.Error("Failed to report confirmation failure", "repo", repo, "issue", num, "error", replyErr)
+		}
+		return internal.LogAndWrapError(err, internal.ErrorTypeModule, "confirm_action", map[string]any{
+			"repo":  repo,
+			"issue": num,
+		})
+	}
+	return m.reply(ctx, repo, num, result)
+}
+
+func (m *ConfirmModule) reply(ctx context.Context, repo string, num int, body string) error {
+	if err := m.comment(ctx, repo, num, body); err != nil {
+		return internal.LogAndWrapError(err, internal.ErrorTypeModule, "confirm_comment", map[string]any{
+			"repo":  repo,
+			"issue": num,
+		})
+	}
+	return nil
+}
+
+func (m *ConfirmModule) comment(ctx context.Context, repo string, num int, body string) error {
+	if m.app == nil || m.app.GitHubClient == nil {
+		slog.Info("GitHub comment would be posted (no GitHub client available)",
+			"repo", repo, "issue_num", num, "message", body)
+		return nil
+	}
+	return m.app.PostComment(ctx, m.Name(), repo, num, body)
+}
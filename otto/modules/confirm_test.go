@@ -0,0 +1,83 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v71/github"
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+func TestCloseAllStaleRequiresConfirmation(t *testing.T) {
+	env := newSLATestEnv(t)
+	confirm := &ConfirmModule{}
+	if err := confirm.Initialize(t.Context(), env.app); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	for _, num := range []int{1, 2} {
+		event := labeledEvent("org/repo", num, "author", "waiting-for-author")
+		if err := env.mod.HandleEvent("issues", event, nil); err != nil {
+			t.Fatalf("HandleEvent failed: %v", err)
+		}
+	}
+	maintainerComment := func(user, body string) *github.IssueCommentEvent {
+		e := commentEvent("org/repo", 9, user, body)
+		e.Comment.AuthorAssociation = github.Ptr("MEMBER")
+		return e
+	}
+
+	// Non-maintainers are refused.
+	bystander := commentEvent("org/repo", 9, "bystander", "/close-all-stale")
+	if err := internal.TestCommands(t, env.mod, bystander); err != nil {
+		t.Fatalf("HandleCommand failed: %v", err)
+	}
+	comments := env.fake.commentsOn("org/repo", 9)
+	if len(comments) != 1 || !strings.Contains(comments[0], "Only maintainers") {
+		t.Fatalf("expected refusal, got %v", comments)
+	}
+
+	// The command only summarizes; nothing is closed yet.
+	if err := internal.TestCommands(t, env.mod, maintainerComment("alice", "/close-all-stale")); err != nil {
+		t.Fatalf("HandleCommand failed: %v", err)
+	}
+	comments = env.fake.commentsOn("org/repo", 9)
+	summary := comments[len(comments)-1]
+	if !strings.Contains(summary, "close 2 issues") || !strings.Contains(summary, "- #1") {
+		t.Fatalf("unexpected summary: %q", summary)
+	}
+	if env.fake.stateOf("org/repo", 1) != "" {
+		t.Fatalf("issue closed before confirmation")
+	}
+	match := regexp.MustCompile("/confirm ([0-9a-f]+)").FindStringSubmatch(summary)
+	if match == nil {
+		t.Fatalf("no token in summary: %q", summary)
+	}
+	token := match[1]
+
+	// Only the issuer can confirm.
+	if err := internal.TestCommands(t, confirm, maintainerComment("bob", "/confirm "+token)); err != nil {
+		t.Fatalf("HandleCommand failed: %v", err)
+	}
+	comments = env.fake.commentsOn("org/repo", 9)
+	if !strings.Contains(comments[len(comments)-1], "no pending action") || env.fake.stateOf("org/repo", 1) != "" {
+		t.Fatalf("another user confirmed the action: %v", comments)
+	}
+
+	// The author of #2 responds before confirmation, so only #1 is closed.
+	if err := env.mod.HandleEvent("issue_comment", commentEvent("org/repo", 2, "author", "details"), nil); err != nil {
+		t.Fatalf("HandleEvent failed: %v", err)
+	}
+	if err := internal.TestCommands(t, confirm, maintainerComment("alice", "/confirm "+token)); err != nil {
+		t.Fatalf("HandleCommand failed: %v", err)
+	}
+	comments = env.fake.commentsOn("org/repo", 9)
+	if got := comments[len(comments)-1]; !strings.Contains(got, "Closed 1 of 2") {
+		t.Errorf("unexpected result: %q", got)
+	}
+	if env.fake.stateOf("org/repo", 1) != "closed" || env.fake.stateOf("org/repo", 2) != "" {
+		t.Errorf("states = %q, %q; want closed, open", env.fake.stateOf("org/repo", 1), env.fake.stateOf("org/repo", 2))
+	}
+}
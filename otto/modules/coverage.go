@@ -0,0 +1,374 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v71/github"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+// coverageCommentKey identifies the managed coverage comment on a pull request.
+const coverageCommentKey = "coverage"
+
+// coverageMaxArtifactBytes caps the size of a downloaded coverage artifact.
+const coverageMaxArtifactBytes = 64 << 20
+
+// coverageMaxRows caps the packages listed in the coverage comment.
+const coverageMaxRows = 50
+
+// CoverageConfig configures coverage comments.
+type CoverageConfig struct {
+	CoverageSource `yaml:",inline"`
+	Threshold      float64                   `yaml:"threshold" doc:"drops in percentage points that are flagged"`
+	Repos          map[string]CoverageSource `yaml:"repos" doc:"per-repository overrides"`
+}
+
+// CoverageSource describes where a repository's workflows publish coverage.
+type CoverageSource struct {
+	Format   string `yaml:"format" doc:"go or cobertura"`
+	Workflow string `yaml:"workflow" doc:"name of the workflow uploading coverage; default: any"`
+	Artifact string `yaml:"artifact" doc:"name of the uploaded artifact"`
+	File     string `yaml:"file" doc:"file in the artifact; default: the first file"`
+}
+
+// CoverageModule reads coverage artifacts uploaded by workflow runs. Runs on pushes to the
+// default branch record the baseline; runs on pull requests are compared to the baseline of
+// their base branch in a managed comment with the change per package, and drops beyond the
+// threshold are flagged.
+type CoverageModule struct {
+	app    *internal.App
+	config CoverageConfig
+	client *http.Client // downloads artifacts from their pre-signed URLs
+	now    func() time.Time
+}
+
+func (m *CoverageModule) Name() string { return "coverage" }
+
+// ConfigSchema implements the ModuleConfigSchema interface.
+func (m *CoverageModule) ConfigSchema() any {
+	c := defaultCoverageConfig()
+	return &c
+}
+
+// defaultCoverageConfig returns the coverage module's defaults.
+func defaultCoverageConfig() CoverageConfig {
+	return CoverageConfig{
+		CoverageSource: CoverageSource{
+			Format:   CoverageFormatGo,
+			Artifact: "coverage",
+		},
+		Threshold: 1,
+	}
+}
+
+// EventSubscriptions implements the ModuleEventSubscriber interface.
+func (m *CoverageModule) EventSubscriptions() []internal.EventSubscription {
+	return []internal.EventSubscription{
+		internal.Subscribe("workflow_run", "completed"),
+	}
+}
+
+// GitHubPermissions implements the ModulePermissionRequirer interface.
+func (m *CoverageModule) GitHubPermissions() map[string]string {
+	return map[string]string{"actions": "read", "issues": "write"}
+}
+
+// Initialize implements the ModuleInitializer interface.
+func (m *CoverageModule) Initialize(ctx context.Context, app *internal.App) error {
+	m.app = app
+	m.client = app.HTTPClient(time.Minute)
+	if m.now == nil {
+		m.now = time.Now
+	}
+	m.config = defaultCoverageConfig()
+	if err := loadModuleConfig(app, m.Name(), &m.config); err != nil {
+		return err
+	}
+	if f := m.config.Format; f != CoverageFormatGo && f != CoverageFormatCobertura {
+		return fmt.Errorf("coverage: unknown format %q", f)
+	}
+	for repo := range m.config.Repos {
+		if f := m.sourceFor(repo).Format; f != CoverageFormatGo && f != CoverageFormatCobertura {
+			return fmt.Errorf("coverage: unknown format %q for %s", f, repo)
+		}
+	}
+	return AutoMigrateCoverage(app.Database.DB())
+}
+
+// sourceFor returns the coverage source of a repository, filling unset fields from the default.
+func (m *CoverageModule) sourceFor(repo string) CoverageSource {
+	src, ok := m.config.Repos[repo]
+	if !ok {
+		return m.config.CoverageSource
+	}
+	if src.Format == "" {
+		src.Format = m.config.Format
+	}
+	if src.Workflow == "" {
+		src.Workflow = m.config.Workflow
+	}
+	if src.Artifact == "" {
+		src.Artifact = m.config.Artifact
+	}
+	if src.File == "" {
+		src.File = m.config.File
+	}
+	return src
+}
+
+func (m *CoverageModule) HandleEvent(eventType string, event any, raw json.RawMessage) error {
+	if eventType != "workflow_run" {
+		return nil
+	}
+	runEvent, ok := event.(*github.WorkflowRunEvent)
+	if !ok || runEvent.GetAction() != "completed" || runEvent.GetWorkflowRun().GetConclusion() != "success" {
+		return nil
+	}
+	return m.handleRun(context.Background(), runEvent)
+}
+
+// handleRun records the baseline of a default branch run or comments on a pull request run.
+func (m *CoverageModule) handleRun(ctx context.Context, event *github.WorkflowRunEvent) error {
+	run := event.GetWorkflowRun()
+	repo := event.GetRepo().GetFullName()
+	src := m.sourceFor(repo)
+	if src.Workflow != "" && run.GetName() != src.Workflow {
+		return nil
+	}
+	isBaseline := run.GetEvent() == "push" && run.GetHeadBranch() == event.GetRepo().GetDefaultBranch()
+	isPull := run.GetEvent() == "pull_request" && len(run.PullRequests) > 0
+	if !isBaseline && !isPull {
+		return nil
+	}
+
+	report, err := m.fetchReport(ctx, repo, run.GetID(), src)
+	if err != nil {
+		return m.wrap(err, "fetch_coverage", repo, 0)
+	}
+	if report == nil {
+		return nil
+	}
+	db := m.app.Database.DB()
+	if isBaseline {
+		err := SaveCoverageBaseline(db, repo, run.GetHeadBranch(), run.GetHeadSHA(), report, m.now())
+		if err != nil {
+			return internal.LogAndWrapError(err, internal.ErrorTypeDatabase, "save_coverage_baseline", map[string]any{
+				"module": m.Name(),
+				"repo":   repo,
+			})
+		}
+		return nil
+	}
+
+	pr := run.PullRequests[0]
+	branch := pr.GetBase().GetRef()
+	baseline, err := GetCoverageBaseline(db, repo, branch)
+	if err != nil {
+		return internal.LogAndWrapError(err, internal.ErrorTypeDatabase, "get_coverage_baseline", map[string]any{
+			"module": m.Name(),
+			"repo":   repo,
+			"issue":  pr.GetNumber(),
+		})
+	}
+	body := renderCoverageComment(branch, baseline, report, m.config.Threshold)
+	if m.app.GitHubClient == nil {
+		slog.Info("GitHub comment would be posted (no GitHub client available)",
+			"repo", repo, "issue_num", pr.GetNumber(), "message", body)
+		return nil
+	}
+	_, err = internal.UpsertManagedComment(ctx, m.app.GitHubClient, repo, pr.GetNumber(), coverageCommentKey, body)
+	return m.wrap(err, "coverage_comment", repo, pr.GetNumber())
+}
+
+// fetchReport downloads and parses the coverage artifact of a workflow run, leaving out
+// generated and vendored files. It returns nil if the run did not upload one.
+func (m *CoverageModule) fetchReport(ctx context.Context, repo string, runID int64,
+	src CoverageSource) (*CoverageReport, error) {
+	if m.app.GitHubClient == nil {
+		return nil, nil
+	}
+	owner, name, err := internal.SplitRepo(repo)
+	if err != nil {
+		return nil, err
+	}
+	var artifact *github.Artifact
+	opts := &github.ListOptions{PerPage: 100}
+	for artifact == nil {
+		list, resp, err := m.app.GitHubClient.Actions.ListWorkflowRunArtifacts(ctx, owner, name, runID, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list artifacts of run %d: %w", runID, err)
+		}
+		for _, a := range list.Artifacts {
+			if a.GetName() == src.Artifact && !a.GetExpired() {
+				artifact = a
+				break
+			}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	if artifact == nil {
+		slog.Debug("Workflow run has no coverage artifact", "repo", repo, "run", runID, "artifact", src.Artifact)
+		return nil, nil
+	}
+
+	u, _, err := m.app.GitHubClient.Actions.DownloadArtifact(ctx, owner, name, artifact.GetID(), 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get download URL of artifact %d: %w", artifact.GetID(), err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download artifact %d: %w", artifact.GetID(), err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download artifact %d: %s", artifact.GetID(), resp.Status)
+	}
+	archive, err := io.ReadAll(io.LimitReader(resp.Body, coverageMaxArtifactBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to download artifact %d: %w", artifact.GetID(), err)
+	}
+	data, err := coverageFile(archive, src.File)
+	if err != nil {
+		return nil, fmt.Errorf("artifact %d: %w", artifact.GetID(), err)
+	}
+	classes, err := m.app.FileClasses.ForRepo(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	return ParseCoverage(src.Format, data, classes.Noise)
+}
+
+// coverageFile extracts the named file, or the first file if name is empty, from an
+// artifact archive. Names match the full path or the base name of a file.
+func coverageFile(archive []byte, name string) ([]byte, error) {
+	zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() || (name != "" && f.Name != name && path.Base(f.Name) != name) {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %w", f.Name, err)
+		}
+		defer rc.Close()
+		return io.ReadAll(io.LimitReader(rc, coverageMaxArtifactBytes))
+	}
+	if name == "" {
+		return nil, errors.New("archive is empty")
+	}
+	return nil, fmt.Errorf("archive has no file %s", name)
+}
+
+// renderCoverageComment formats a pull request's coverage compared to the baseline of its
+// base branch, flagging drops of more than threshold percentage points.
+func renderCoverageComment(branch string, baseline *CoverageBaseline, report *CoverageReport,
+	threshold float64) string {
+	var b strings.Builder
+	total := report.Total()
+	b.WriteString("### Test coverage\n\n")
+	if baseline == nil {
+		fmt.Fprintf(&b, "**Total: %.1f%%**\n\nNo coverage of `%s` has been recorded yet, so there is nothing "+
+			"to compare to.\n", total.Percent(), branch)
+		return b.String()
+	}
+
+	delta := CoverageDelta{Package: "total", Base: github.Ptr(baseline.Report.Total()), Head: &total}
+	sha := baseline.SHA
+	if len(sha) > 7 {
+		sha = sha[:7]
+	}
+	fmt.Fprintf(&b, "**Total: %.1f%%** (%s compared to `%s` at %s)\n\n", total.Percent(),
+		formatCoverageChange(delta.Change(), threshold), branch, sha)
+
+	deltas := CompareCoverage(baseline.Report, report)
+	flagged := delta.Change() < -threshold || slices.ContainsFunc(deltas, func(d CoverageDelta) bool {
+		return d.Change() < -threshold
+	})
+	if flagged {
+		fmt.Fprintf(&b, "⚠️ Coverage dropped by more than %.1f percentage points.\n\n", threshold)
+	}
+	if len(deltas) == 0 {
+		b.WriteString("Coverage of every package is unchanged.\n")
+		return b.String()
+	}
+
+	fmt.Fprintf(&b, "| Package | `%s` | This PR | Change |\n|---|---:|---:|---:|\n", branch)
+	for i, d := range deltas {
+		if i == coverageMaxRows {
+			fmt.Fprintf(&b, "\n… and %d more packages.\n", len(deltas)-coverageMaxRows)
+			break
+		}
+		base, head, change := "–", "–", "new"
+		if d.Base != nil {
+			base = fmt.Sprintf("%.1f%%", d.Base.Percent())
+			change = "removed"
+		}
+		if d.Head != nil {
+			head = fmt.Sprintf("%.1f%%", d.Head.Percent())
+		}
+		if d.Base != nil && d.Head != nil {
+			change = formatCoverageChange(d.Change(), threshold)
+		}
+		fmt.Fprintf(&b, "| `%s` | %s | %s | %s |\n", d.Package, base, head, change)
+	}
+	if unchanged := len(report.Packages) - countCoverageHeads(deltas); unchanged > 0 {
+		fmt.Fprintf(&b, "\n%d unchanged packages are not listed.\n", unchanged)
+	}
+	return b.String()
+}
+
+// countCoverageHeads returns how many deltas are of packages present in the pull request.
+func countCoverageHeads(deltas []CoverageDelta) int {
+	n := 0
+	for _, d := range deltas {
+		if d.Head != nil {
+			n++
+		}
+	}
+	return n
+}
+
+// formatCoverageChange formats a change in percentage points, flagging drops beyond threshold.
+func formatCoverageChange(change, threshold float64) string {
+	s := fmt.Sprintf("%+.1f pp", change)
+	if change < -threshold {
+		s = "⚠️ " + s
+	}
+	return s
+}
+
+func (m *CoverageModule) wrap(err error, op, repo string, num int) error {
+	if err == nil {
+		return nil
+	}
+	return internal.LogAndWrapError(err, internal.ErrorTypeModule, op, map[string]any{
+		"module": m.Name(),
+		"repo":   repo,
+		"issue":  num,
+	})
+}
@@ -0,0 +1,204 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Coverage artifact formats.
+const (
+	// CoverageFormatGo is a Go coverprofile (go test -coverprofile), measured in statements.
+	CoverageFormatGo = "go"
+	// CoverageFormatCobertura is a Cobertura XML report, measured in lines.
+	CoverageFormatCobertura = "cobertura"
+)
+
+// CoverageCounts is how much of a package is covered, in statements or lines.
+type CoverageCounts struct {
+	Covered int `json:"covered"`
+	Total   int `json:"total"`
+}
+
+// Percent returns the covered share in percent; code without statements counts as covered.
+func (c CoverageCounts) Percent() float64 {
+	if c.Total == 0 {
+		return 100
+	}
+	return 100 * float64(c.Covered) / float64(c.Total)
+}
+
+// CoverageReport is a test run's coverage by package.
+type CoverageReport struct {
+	Packages map[string]CoverageCounts `json:"packages"`
+}
+
+// Total returns the coverage of all packages together.
+func (r *CoverageReport) Total() CoverageCounts {
+	var total CoverageCounts
+	for _, c := range r.Packages {
+		total.Covered += c.Covered
+		total.Total += c.Total
+	}
+	return total
+}
+
+// ParseCoverage parses a coverage file in the given format. Files for which skip returns
+// true, e.g. generated code, are left out; skip may be nil.
+func ParseCoverage(format string, data []byte, skip func(file string) bool) (*CoverageReport, error) {
+	switch format {
+	case CoverageFormatGo:
+		return ParseGoCoverProfile(data, skip)
+	case CoverageFormatCobertura:
+		return ParseCobertura(data, skip)
+	}
+	return nil, fmt.Errorf("unknown coverage format %q", format)
+}
+
+// ParseGoCoverProfile parses a Go coverprofile. Profiles concatenated from several runs are
+// merged: a block counts as covered if any run covered it. Files are named by import path.
+func ParseGoCoverProfile(data []byte, skip func(file string) bool) (*CoverageReport, error) {
+	type block struct {
+		stmts   int
+		covered bool
+	}
+	blocks := make(map[string]block)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "mode:") {
+			continue
+		}
+		// file.go:startLine.startCol,endLine.endCol numStmt count
+		fields := strings.Fields(line)
+		if len(fields) != 3 || !strings.Contains(fields[0], ":") {
+			return nil, fmt.Errorf("invalid coverprofile line %d: %q", n, line)
+		}
+		stmts, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid statement count on coverprofile line %d: %w", n, err)
+		}
+		count, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("invalid hit count on coverprofile line %d: %w", n, err)
+		}
+		b := blocks[fields[0]]
+		b.stmts = stmts
+		b.covered = b.covered || count > 0
+		blocks[fields[0]] = b
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read coverprofile: %w", err)
+	}
+
+	report := &CoverageReport{Packages: make(map[string]CoverageCounts)}
+	for key, b := range blocks {
+		file := key[:strings.LastIndex(key, ":")]
+		if skip != nil && skip(file) {
+			continue
+		}
+		pkg := report.Packages[path.Dir(file)]
+		pkg.Total += b.stmts
+		if b.covered {
+			pkg.Covered += b.stmts
+		}
+		report.Packages[path.Dir(file)] = pkg
+	}
+	return report, nil
+}
+
+// coberturaXML holds the parts of a Cobertura report used for coverage.
+type coberturaXML struct {
+	Packages []struct {
+		Name    string `xml:"name,attr"`
+		Classes []struct {
+			Filename string `xml:"filename,attr"`
+			Lines    []struct {
+				Number int `xml:"number,attr"`
+				Hits   int `xml:"hits,attr"`
+			} `xml:"lines>line"`
+		} `xml:"classes>class"`
+	} `xml:"packages>package"`
+}
+
+// ParseCobertura parses a Cobertura XML report. Lines listed by several classes of a file
+// are counted once.
+func ParseCobertura(data []byte, skip func(file string) bool) (*CoverageReport, error) {
+	var parsed coberturaXML
+	if err := xml.Unmarshal(data, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse cobertura report: %w", err)
+	}
+	report := &CoverageReport{Packages: make(map[string]CoverageCounts)}
+	for _, p := range parsed.Packages {
+		lines := make(map[string]bool) // key: file:line; value: covered
+		for _, c := range p.Classes {
+			if skip != nil && skip(c.Filename) {
+				continue
+			}
+			for _, l := range c.Lines {
+				key := c.Filename + ":" + strconv.Itoa(l.Number)
+				lines[key] = lines[key] || l.Hits > 0
+			}
+		}
+		pkg := report.Packages[p.Name]
+		for _, covered := range lines {
+			pkg.Total++
+			if covered {
+				pkg.Covered++
+			}
+		}
+		report.Packages[p.Name] = pkg
+	}
+	return report, nil
+}
+
+// CoverageDelta is the coverage of a package in the base branch and in a pull request.
+// Base or Head is nil if the package only exists on one side.
+type CoverageDelta struct {
+	Package string
+	Base    *CoverageCounts
+	Head    *CoverageCounts
+}
+
+// Change returns the change in percentage points; packages on one side only count as unchanged.
+func (d CoverageDelta) Change() float64 {
+	if d.Base == nil || d.Head == nil {
+		return 0
+	}
+	return d.Head.Percent() - d.Base.Percent()
+}
+
+// CompareCoverage returns the packages whose coverage differs between base and head, by
+// package name.
+func CompareCoverage(base, head *CoverageReport) []CoverageDelta {
+	names := make(map[string]bool)
+	for name := range base.Packages {
+		names[name] = true
+	}
+	for name := range head.Packages {
+		names[name] = true
+	}
+	var deltas []CoverageDelta
+	for _, name := range slices.Sorted(maps.Keys(names)) {
+		d := CoverageDelta{Package: name}
+		if c, ok := base.Packages[name]; ok {
+			d.Base = &c
+		}
+		if c, ok := head.Packages[name]; ok {
+			d.Head = &c
+		}
+		if d.Base != nil && d.Head != nil && *d.Base == *d.Head {
+			continue
+		}
+		deltas = append(deltas, d)
+	}
+	return deltas
+}
@@ -0,0 +1,113 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseGoCoverProfile(t *testing.T) {
+	profile := `mode: set
+example.com/mod/a/a.go:3.10,5.2 2 1
+example.com/mod/a/a.go:7.10,9.2 3 0
+example.com/mod/a/b.go:3.10,5.2 1 0
+example.com/mod/b/b.go:3.10,5.2 4 0
+example.com/mod/a/a.go:7.10,9.2 3 1
+`
+	report, err := ParseGoCoverProfile([]byte(profile), nil)
+	if err != nil {
+		t.Fatalf("ParseGoCoverProfile failed: %v", err)
+	}
+	want := map[string]CoverageCounts{
+		"example.com/mod/a": {Covered: 5, Total: 6},
+		"example.com/mod/b": {Covered: 0, Total: 4},
+	}
+	if !reflect.DeepEqual(report.Packages, want) {
+		t.Errorf("packages = %v, want %v", report.Packages, want)
+	}
+	if got := report.Total(); got != (CoverageCounts{Covered: 5, Total: 10}) {
+		t.Errorf("total = %v, want 5/10", got)
+	}
+
+	skipped, err := ParseGoCoverProfile([]byte(profile), func(file string) bool { return strings.HasSuffix(file, "b.go") })
+	if err != nil {
+		t.Fatalf("ParseGoCoverProfile failed: %v", err)
+	}
+	if got := skipped.Packages; !reflect.DeepEqual(got, map[string]CoverageCounts{"example.com/mod/a": {5, 5}}) {
+		t.Errorf("packages without skipped files = %v", got)
+	}
+
+	if _, err := ParseGoCoverProfile([]byte("mode: set\nnot a profile\n"), nil); err == nil {
+		t.Error("expected an error for an invalid profile")
+	}
+}
+
+func TestParseCobertura(t *testing.T) {
+	report := `<?xml version="1.0" ?>
+<coverage line-rate="0.5">
+  <packages>
+    <package name="otto.api">
+      <classes>
+        <class name="Server" filename="otto/api/server.py">
+          <lines><line number="1" hits="1"/><line number="2" hits="0"/></lines>
+        </class>
+        <class name="Handler" filename="otto/api/server.py">
+          <lines><line number="2" hits="3"/><line number="3" hits="0"/></lines>
+        </class>
+      </classes>
+    </package>
+    <package name="otto.db">
+      <classes>
+        <class name="Store" filename="otto/db/store.py">
+          <lines><line number="1" hits="0"/></lines>
+        </class>
+      </classes>
+    </package>
+  </packages>
+</coverage>`
+	got, err := ParseCoverage(CoverageFormatCobertura, []byte(report), nil)
+	if err != nil {
+		t.Fatalf("ParseCoverage failed: %v", err)
+	}
+	want := map[string]CoverageCounts{
+		"otto.api": {Covered: 2, Total: 3},
+		"otto.db":  {Covered: 0, Total: 1},
+	}
+	if !reflect.DeepEqual(got.Packages, want) {
+		t.Errorf("packages = %v, want %v", got.Packages, want)
+	}
+
+	if _, err := ParseCoverage("lcov", nil, nil); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestCompareCoverage(t *testing.T) {
+	base := &CoverageReport{Packages: map[string]CoverageCounts{
+		"same":    {Covered: 1, Total: 2},
+		"dropped": {Covered: 8, Total: 10},
+		"removed": {Covered: 1, Total: 1},
+	}}
+	head := &CoverageReport{Packages: map[string]CoverageCounts{
+		"same":    {Covered: 1, Total: 2},
+		"dropped": {Covered: 6, Total: 10},
+		"added":   {Covered: 0, Total: 5},
+	}}
+	deltas := CompareCoverage(base, head)
+	var names []string
+	for _, d := range deltas {
+		names = append(names, d.Package)
+	}
+	if want := []string{"added", "dropped", "removed"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("changed packages = %v, want %v", names, want)
+	}
+	if got := deltas[1].Change(); math.Abs(got+20) > 1e-9 {
+		t.Errorf("dropped change = %v, want -20", got)
+	}
+	if deltas[0].Base != nil || deltas[0].Change() != 0 {
+		t.Errorf("added package delta = %+v, want no base and no change", deltas[0])
+	}
+}
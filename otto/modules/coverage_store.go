@@ -0,0 +1,86 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+// CoverageBaseline is the latest coverage of a branch that pull requests are compared to.
+type CoverageBaseline struct {
+	SHA       string
+	Report    *CoverageReport
+	UpdatedAt time.Time
+}
+
+func AutoMigrateCoverage(db *sql.DB) error {
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS coverage_baselines (
+			repo TEXT NOT NULL,
+			branch TEXT NOT NULL,
+			sha TEXT NOT NULL,
+			report TEXT NOT NULL,
+			updated_at TIMESTAMP NOT NULL,
+			PRIMARY KEY (repo, branch)
+		);`,
+	}
+	for _, s := range stmts {
+		if _, err := db.Exec(s); err != nil {
+			return fmt.Errorf("failed migration: %w (SQL: %s)", err, s)
+		}
+	}
+	return nil
+}
+
+// Migrate implements the ModuleMigrator interface.
+func (m *CoverageModule) Migrate(db *sql.DB) error {
+	return AutoMigrateCoverage(db)
+}
+
+// SaveCoverageBaseline replaces the baseline of a branch.
+func SaveCoverageBaseline(db *sql.DB, repo, branch, sha string, report *CoverageReport, at time.Time) error {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to encode coverage report: %w", err)
+	}
+	_, err = db.Exec(
+		`INSERT INTO coverage_baselines (repo, branch, sha, report, updated_at) VALUES (?, ?, ?, ?, ?)
+		 ON CONFLICT (repo, branch) DO UPDATE SET sha = excluded.sha, report = excluded.report,
+		 updated_at = excluded.updated_at`,
+		repo, branch, sha, string(data), at,
+	)
+	return err
+}
+
+// GetCoverageBaseline returns the baseline of a branch, or nil if none was recorded.
+func GetCoverageBaseline(db *sql.DB, repo, branch string) (*CoverageBaseline, error) {
+	var (
+		b    CoverageBaseline
+		data string
+	)
+	err := db.QueryRow(
+		`SELECT sha, report, updated_at FROM coverage_baselines WHERE repo = ? AND branch = ?`, repo, branch,
+	).Scan(&b.SHA, &data, &b.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(data), &b.Report); err != nil {
+		return nil, fmt.Errorf("failed to decode coverage report: %w", err)
+	}
+	return &b, nil
+}
+
+// RenameRepo implements the ModuleRepoRenamer interface.
+func (m *CoverageModule) RenameRepo(ctx context.Context, from, to string) error {
+	return internal.RenameRepoIn(ctx, m.app.Database.DB(), from, to, "coverage_baselines.repo")
+}
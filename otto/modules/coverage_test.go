@@ -0,0 +1,184 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"archive/zip"
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v71/github"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+func newCoverageTestModule(t *testing.T, fake *fakeGitHub) *CoverageModule {
+	t.Helper()
+	db := internal.TestDB(t)
+	if err := AutoMigrateCoverage(db); err != nil {
+		t.Fatalf("AutoMigrateCoverage failed: %v", err)
+	}
+	return &CoverageModule{
+		app: &internal.App{GitHubClient: fake.client(t), Database: internal.NewDatabaseFromDB(db)},
+		config: CoverageConfig{
+			CoverageSource: CoverageSource{Format: CoverageFormatGo, Workflow: "build", Artifact: "coverage"},
+			Threshold:      1,
+			Repos: map[string]CoverageSource{
+				"org/python": {Format: CoverageFormatCobertura, File: "coverage.xml"},
+			},
+		},
+		client: http.DefaultClient,
+		now:    func() time.Time { return time.Date(2025, time.June, 2, 9, 0, 0, 0, time.UTC) },
+	}
+}
+
+// zipArchive returns a zip archive of the given files.
+func zipArchive(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("zip Create failed: %v", err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatalf("zip Write failed: %v", err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("zip Close failed: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// workflowRunEvent is a successful run of the build workflow; pr 0 means a push to main.
+func workflowRunEvent(repo string, runID int64, pr int) *github.WorkflowRunEvent {
+	run := &github.WorkflowRun{
+		ID:         github.Ptr(runID),
+		Name:       github.Ptr("build"),
+		Event:      github.Ptr("push"),
+		HeadBranch: github.Ptr("main"),
+		HeadSHA:    github.Ptr("0123456789abcdef"),
+		Conclusion: github.Ptr("success"),
+	}
+	if pr > 0 {
+		run.Event = github.Ptr("pull_request")
+		run.HeadBranch = github.Ptr("feature")
+		run.PullRequests = []*github.PullRequest{{
+			Number: github.Ptr(pr),
+			Base:   &github.PullRequestBranch{Ref: github.Ptr("main")},
+		}}
+	}
+	return &github.WorkflowRunEvent{
+		Action:      github.Ptr("completed"),
+		Repo:        &github.Repository{FullName: github.Ptr(repo), DefaultBranch: github.Ptr("main")},
+		WorkflowRun: run,
+	}
+}
+
+func TestCoverageComment(t *testing.T) {
+	fake := newFakeGitHub()
+	mod := newCoverageTestModule(t, fake)
+
+	fake.addArtifact(1, "coverage", zipArchive(t, map[string]string{"coverage.out": "mode: set\n" +
+		"example.com/mod/a/a.go:1.1,2.2 8 1\nexample.com/mod/a/a.go:3.1,4.2 2 0\n" +
+		"example.com/mod/b/b.go:1.1,2.2 5 1\n"}))
+	fake.addArtifact(2, "coverage", zipArchive(t, map[string]string{"coverage.out": "mode: set\n" +
+		"example.com/mod/a/a.go:1.1,2.2 6 1\nexample.com/mod/a/a.go:3.1,4.2 4 0\n" +
+		"example.com/mod/b/b.go:1.1,2.2 5 1\nexample.com/mod/c/c.go:1.1,2.2 1 0\n"}))
+
+	// Without a baseline there is nothing to compare to.
+	if err := mod.HandleEvent("workflow_run", workflowRunEvent("org/repo", 2, 7), nil); err != nil {
+		t.Fatalf("HandleEvent failed: %v", err)
+	}
+	comments := fake.commentsOn("org/repo", 7)
+	if len(comments) != 1 || !strings.Contains(comments[0], "No coverage of `main` has been recorded yet") {
+		t.Fatalf("comments = %v", comments)
+	}
+
+	if err := mod.HandleEvent("workflow_run", workflowRunEvent("org/repo", 1, 0), nil); err != nil {
+		t.Fatalf("HandleEvent failed: %v", err)
+	}
+	if err := mod.HandleEvent("workflow_run", workflowRunEvent("org/repo", 2, 7), nil); err != nil {
+		t.Fatalf("HandleEvent failed: %v", err)
+	}
+	comments = fake.commentsOn("org/repo", 7)
+	if len(comments) != 1 {
+		t.Fatalf("expected the comment to be updated in place, got %v", comments)
+	}
+	for _, want := range []string{
+		"**Total: 68.8%** (⚠️ -17.9 pp compared to `main` at 0123456)",
+		"⚠️ Coverage dropped by more than 1.0 percentage points.",
+		"| `example.com/mod/a` | 80.0% | 60.0% | ⚠️ -20.0 pp |",
+		"| `example.com/mod/c` | – | 0.0% | new |",
+		"1 unchanged packages are not listed.",
+	} {
+		if !strings.Contains(comments[0], want) {
+			t.Errorf("comment missing %q:\n%s", want, comments[0])
+		}
+	}
+}
+
+func TestCoverageCobertura(t *testing.T) {
+	fake := newFakeGitHub()
+	mod := newCoverageTestModule(t, fake)
+	fake.addArtifact(1, "coverage", zipArchive(t, map[string]string{
+		"report/coverage.xml": `<coverage><packages><package name="pkg"><classes>` +
+			`<class filename="pkg/a.py"><lines><line number="1" hits="1"/></lines></class>` +
+			`</classes></package></packages></coverage>`,
+		"report/index.html": "<html></html>",
+	}))
+
+	if err := mod.HandleEvent("workflow_run", workflowRunEvent("org/python", 1, 0), nil); err != nil {
+		t.Fatalf("HandleEvent failed: %v", err)
+	}
+	baseline, err := GetCoverageBaseline(mod.app.Database.DB(), "org/python", "main")
+	if err != nil || baseline == nil {
+		t.Fatalf("GetCoverageBaseline = %v, %v", baseline, err)
+	}
+	if got := baseline.Report.Packages["pkg"]; got != (CoverageCounts{Covered: 1, Total: 1}) {
+		t.Errorf("baseline of pkg = %v, want 1/1", got)
+	}
+}
+
+func TestCoverageIgnoredRuns(t *testing.T) {
+	fake := newFakeGitHub()
+	mod := newCoverageTestModule(t, fake)
+	fake.addArtifact(1, "coverage", zipArchive(t, map[string]string{"coverage.out": "mode: set\n"}))
+
+	tests := []struct {
+		name   string
+		modify func(e *github.WorkflowRunEvent)
+	}{
+		{"failed run", func(e *github.WorkflowRunEvent) { e.WorkflowRun.Conclusion = github.Ptr("failure") }},
+		{"other workflow", func(e *github.WorkflowRunEvent) { e.WorkflowRun.Name = github.Ptr("lint") }},
+		{"push to other branch", func(e *github.WorkflowRunEvent) { e.WorkflowRun.HeadBranch = github.Ptr("dev") }},
+		{"still running", func(e *github.WorkflowRunEvent) { e.Action = github.Ptr("in_progress") }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := workflowRunEvent("org/repo", 1, 0)
+			tt.modify(event)
+			if err := mod.HandleEvent("workflow_run", event, nil); err != nil {
+				t.Fatalf("HandleEvent failed: %v", err)
+			}
+			for _, branch := range []string{"main", "dev"} {
+				if b, _ := GetCoverageBaseline(mod.app.Database.DB(), "org/repo", branch); b != nil {
+					t.Errorf("baseline of %s was recorded", branch)
+				}
+			}
+		})
+	}
+
+	// Runs without the artifact are skipped.
+	if err := mod.HandleEvent("workflow_run", workflowRunEvent("org/repo", 9, 3), nil); err != nil {
+		t.Fatalf("HandleEvent failed: %v", err)
+	}
+	if comments := fake.commentsOn("org/repo", 3); len(comments) != 0 {
+		t.Errorf("unexpected comments %v", comments)
+	}
+}
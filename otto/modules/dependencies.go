@@ -0,0 +1,264 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-github/v71/github"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+// dependencyCommentKey identifies the managed dependency comment on an issue.
+const dependencyCommentKey = "dependencies"
+
+// issueRefPattern matches "#123", "owner/repo#123", and GitHub issue/PR URLs.
+var issueRefPattern = regexp.MustCompile(
+	`^(?:https://github\.com/([\w.-]+/[\w.-]+)/(?:issues|pull)/(\d+)|([\w.-]+/[\w.-]+)?#(\d+))$`,
+)
+
+// DependencyModule tracks blocks/blocked-by relationships between issues.
+type DependencyModule struct {
+	app      *internal.App
+	database *internal.Database
+}
+
+func (d *DependencyModule) Name() string { return "dependencies" }
+
+// EventSubscriptions implements the ModuleEventSubscriber interface.
+func (d *DependencyModule) EventSubscriptions() []internal.EventSubscription {
+	return []internal.EventSubscription{
+		internal.Subscribe("issues", "closed", "reopened"),
+	}
+}
+
+// GitHubPermissions implements the ModulePermissionRequirer interface.
+func (d *DependencyModule) GitHubPermissions() map[string]string {
+	return map[string]string{"issues": "write"}
+}
+
+// SlashCommands implements the ModuleCommander interface.
+func (d *DependencyModule) SlashCommands() []string {
+	return []string{"blocked-by", "blocks"}
+}
+
+// Initialize implements the ModuleInitializer interface.
+func (d *DependencyModule) Initialize(ctx context.Context, app *internal.App) error {
+	d.app = app
+	d.database = app.Database
+	return AutoMigrateDependencies(d.database.DB())
+}
+
+func (d *DependencyModule) HandleEvent(eventType string, event any, raw json.RawMessage) error {
+	ctx := context.Background()
+	switch eventType {
+	case "issues":
+		issuesEvent, ok := event.(*github.IssuesEvent)
+		if !ok {
+			return nil
+		}
+		ref := IssueRef{Repo: issuesEvent.GetRepo().GetFullName(), Number: issuesEvent.GetIssue().GetNumber()}
+		switch issuesEvent.GetAction() {
+		case "closed":
+			return d.handleBlockerChange(ctx, ref, true)
+		case "reopened":
+			return d.handleBlockerChange(ctx, ref, false)
+		}
+	}
+	return nil
+}
+
+// HandleCommand implements the ModuleCommandHandler interface. It records relationships
+// from /blocked-by and /blocks commands.
+func (d *DependencyModule) HandleCommand(cmd *internal.CommandContext) error {
+	ctx := cmd.Context
+	db := d.database.DB()
+	current := IssueRef{Repo: cmd.Repo, Number: cmd.IssueNum}
+
+	touched := map[IssueRef]bool{}
+	var invalid []string
+	for _, arg := range cmd.Args {
+		other, err := parseIssueRef(arg, current.Repo)
+		if err != nil || other == current {
+			invalid = append(invalid, arg)
+			continue
+		}
+		issue, blocker := current, other
+		if cmd.Command == "blocks" {
+			issue, blocker = other, current
+		}
+		if err := AddDependency(db, issue, blocker, cmd.Issuer); err != nil {
+			return internal.LogAndWrapError(err, internal.ErrorTypeModule, "add_dependency", map[string]any{
+				"repo":  current.Repo,
+				"issue": current.Number,
+			})
+		}
+		touched[issue] = true
+		touched[blocker] = true
+	}
+
+	if len(invalid) > 0 {
+		d.comment(ctx, current, fmt.Sprintf(
+			"⚠️ Could not parse issue reference(s): `%s`. Use `#123`, `owner/repo#123`, or an issue URL.",
+			strings.Join(invalid, "`, `"),
+		))
+	}
+	for ref := range touched {
+		// Only issues in this repository get a managed comment; cross-repo
+		// references are recorded but not written to.
+		if ref.Repo == current.Repo {
+			d.refreshComment(ctx, ref)
+		}
+	}
+	return nil
+}
+
+// handleBlockerChange updates dependents when a blocker is closed or reopened.
+func (d *DependencyModule) handleBlockerChange(ctx context.Context, blocker IssueRef, closed bool) error {
+	db := d.database.DB()
+	dependents, err := ListDependents(db, blocker)
+	if err != nil {
+		return internal.LogAndWrapError(err, internal.ErrorTypeModule, "list_dependents", map[string]any{
+			"repo":  blocker.Repo,
+			"issue": blocker.Number,
+		})
+	}
+	if len(dependents) == 0 {
+		return nil
+	}
+	if err := SetBlockerResolved(db, blocker, closed); err != nil {
+		return internal.LogAndWrapError(err, internal.ErrorTypeModule, "resolve_blocker", map[string]any{
+			"repo":  blocker.Repo,
+			"issue": blocker.Number,
+		})
+	}
+
+	d.refreshComment(ctx, blocker)
+	for _, dep := range dependents {
+		d.refreshComment(ctx, dep.Issue)
+		if !closed {
+			continue
+		}
+		msg := fmt.Sprintf("🔓 Blocker %s was closed.", formatIssueRef(blocker, dep.Issue.Repo))
+		remaining, err := ListBlockers(db, dep.Issue)
+		if err == nil && countOpen(remaining) == 0 {
+			msg += " All blockers are resolved; this issue is no longer blocked."
+		}
+		d.comment(ctx, dep.Issue, msg)
+	}
+	return nil
+}
+
+// refreshComment re-renders the managed dependency comment for an issue.
+func (d *DependencyModule) refreshComment(ctx context.Context, ref IssueRef) {
+	db := d.database.DB()
+	blockers, err := ListBlockers(db, ref)
+	if err != nil {
+		slog.Error("Failed to list blockers", "repo", ref.Repo, "issue", ref.Number, "error", err)
+		return
+	}
+	dependents, err := ListDependents(db, ref)
+	if err != nil {
+		slog.Error("Failed to list dependents", "repo", ref.Repo, "issue", ref.Number, "error", err)
+		return
+	}
+	body := renderDependencies(ref, blockers, dependents)
+
+	if d.app == nil || d.app.GitHubClient == nil {
+		slog.Info("Dependency comment would be updated (no GitHub client available)",
+			"repo", ref.Repo, "issue", ref.Number)
+		return
+	}
+	if _, err := internal.UpsertManagedComment(
+		ctx, d.app.GitHubClient, ref.Repo, ref.Number, dependencyCommentKey, body,
+	); err != nil {
+		slog.Error("Failed to update dependency comment", "repo", ref.Repo, "issue", ref.Number, "error", err)
+	}
+}
+
+// comment posts a plain comment, logging instead when no GitHub client is configured.
+func (d *DependencyModule) comment(ctx context.Context, ref IssueRef, body string) {
+	if d.app == nil || d.app.GitHubClient == nil {
+		slog.Info("GitHub comment would be posted (no GitHub client available)",
+			"repo", ref.Repo, "issue", ref.Number, "message", body)
+		return
+	}
+	if err := d.app.PostComment(ctx, d.Name(), ref.Repo, ref.Number, body); err != nil {
+		slog.Error("Failed to post dependency comment", "repo", ref.Repo, "issue", ref.Number, "error", err)
+	}
+}
+
+// renderDependencies builds the markdown body of the managed dependency comment.
+func renderDependencies(ref IssueRef, blockers, dependents []IssueDependency) string {
+	var b strings.Builder
+	b.WriteString("### Dependencies\n\n")
+	if len(blockers) == 0 && len(dependents) == 0 {
+		b.WriteString("_No dependencies recorded._\n")
+		return b.String()
+	}
+	if len(blockers) > 0 {
+		fmt.Fprintf(&b, "**Blocked by** (%d open)\n\n", countOpen(blockers))
+		for _, dep := range blockers {
+			check := " "
+			if dep.ResolvedAt != nil {
+				check = "x"
+			}
+			fmt.Fprintf(&b, "- [%s] %s\n", check, formatIssueRef(dep.Blocker, ref.Repo))
+		}
+		b.WriteString("\n")
+	}
+	if len(dependents) > 0 {
+		b.WriteString("**Blocks**\n\n")
+		for _, dep := range dependents {
+			fmt.Fprintf(&b, "- %s\n", formatIssueRef(dep.Issue, ref.Repo))
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("_Use `/blocked-by #N` or `/blocks #N` to record dependencies._\n")
+	return b.String()
+}
+
+// parseIssueRef parses an issue reference relative to defaultRepo.
+func parseIssueRef(s, defaultRepo string) (IssueRef, error) {
+	m := issueRefPattern.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return IssueRef{}, fmt.Errorf("invalid issue reference: %s", s)
+	}
+	repo, num := m[1], m[2]
+	if num == "" {
+		repo, num = m[3], m[4]
+	}
+	if repo == "" {
+		repo = defaultRepo
+	}
+	n, err := strconv.Atoi(num)
+	if err != nil || n <= 0 {
+		return IssueRef{}, fmt.Errorf("invalid issue number: %s", s)
+	}
+	return IssueRef{Repo: repo, Number: n}, nil
+}
+
+// formatIssueRef renders ref as "#N" within relativeTo, or "owner/repo#N" otherwise.
+func formatIssueRef(ref IssueRef, relativeTo string) string {
+	if ref.Repo == relativeTo {
+		return fmt.Sprintf("#%d", ref.Number)
+	}
+	return fmt.Sprintf("%s#%d", ref.Repo, ref.Number)
+}
+
+func countOpen(deps []IssueDependency) int {
+	n := 0
+	for _, dep := range deps {
+		if dep.ResolvedAt == nil {
+			n++
+		}
+	}
+	return n
+}
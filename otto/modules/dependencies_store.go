@@ -0,0 +1,130 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+// IssueRef identifies an issue or pull request in a repository.
+type IssueRef struct {
+	Repo   string
+	Number int
+}
+
+// IssueDependency records that an issue is blocked by another issue.
+type IssueDependency struct {
+	Issue      IssueRef
+	Blocker    IssueRef
+	CreatedBy  string
+	CreatedAt  time.Time
+	ResolvedAt *time.Time
+}
+
+func AutoMigrateDependencies(db *sql.DB) error {
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS issue_dependencies (
+			repo TEXT NOT NULL,
+			issue_num INTEGER NOT NULL,
+			blocker_repo TEXT NOT NULL,
+			blocker_num INTEGER NOT NULL,
+			created_by TEXT,
+			created_at TIMESTAMP NOT NULL,
+			resolved_at TIMESTAMP,
+			PRIMARY KEY (repo, issue_num, blocker_repo, blocker_num)
+		);`,
+		`CREATE INDEX IF NOT EXISTS idx_issue_dependencies_blocker
+			ON issue_dependencies (blocker_repo, blocker_num);`,
+	}
+	for _, s := range stmts {
+		if _, err := db.Exec(s); err != nil {
+			return fmt.Errorf("failed migration: %w (SQL: %s)", err, s)
+		}
+	}
+	return nil
+}
+
+// Migrate implements the ModuleMigrator interface.
+func (d *DependencyModule) Migrate(db *sql.DB) error {
+	return AutoMigrateDependencies(db)
+}
+
+// AddDependency records that issue is blocked by blocker. Re-adding an existing
+// relationship is a no-op.
+func AddDependency(db *sql.DB, issue, blocker IssueRef, createdBy string) error {
+	_, err := db.Exec(
+		`INSERT OR IGNORE INTO issue_dependencies (repo, issue_num, blocker_repo, blocker_num, created_by, created_at)
+		 VALUES (?, ?, ?, ?, ?, ?)`,
+		issue.Repo, issue.Number, blocker.Repo, blocker.Number, createdBy, time.Now(),
+	)
+	return err
+}
+
+// ListBlockers returns the dependencies blocking issue.
+func ListBlockers(db *sql.DB, issue IssueRef) ([]IssueDependency, error) {
+	return queryDependencies(db,
+		`SELECT repo, issue_num, blocker_repo, blocker_num, created_by, created_at, resolved_at
+		 FROM issue_dependencies WHERE repo = ? AND issue_num = ? ORDER BY created_at ASC`,
+		issue.Repo, issue.Number,
+	)
+}
+
+// ListDependents returns the dependencies in which issue is the blocker.
+func ListDependents(db *sql.DB, blocker IssueRef) ([]IssueDependency, error) {
+	return queryDependencies(db,
+		`SELECT repo, issue_num, blocker_repo, blocker_num, created_by, created_at, resolved_at
+		 FROM issue_dependencies WHERE blocker_repo = ? AND blocker_num = ? ORDER BY created_at ASC`,
+		blocker.Repo, blocker.Number,
+	)
+}
+
+// SetBlockerResolved marks every dependency on blocker as resolved (closed) or unresolved (reopened).
+func SetBlockerResolved(db *sql.DB, blocker IssueRef, resolved bool) error {
+	var resolvedAt any
+	if resolved {
+		resolvedAt = time.Now()
+	}
+	_, err := db.Exec(
+		`UPDATE issue_dependencies SET resolved_at = ? WHERE blocker_repo = ? AND blocker_num = ?`,
+		resolvedAt, blocker.Repo, blocker.Number,
+	)
+	return err
+}
+
+func queryDependencies(db *sql.DB, query string, args ...any) ([]IssueDependency, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var deps []IssueDependency
+	for rows.Next() {
+		var d IssueDependency
+		var createdBy sql.NullString
+		if err := rows.Scan(
+			&d.Issue.Repo,
+			&d.Issue.Number,
+			&d.Blocker.Repo,
+			&d.Blocker.Number,
+			&createdBy,
+			&d.CreatedAt,
+			&d.ResolvedAt,
+		); err != nil {
+			return nil, err
+		}
+		d.CreatedBy = createdBy.String
+		deps = append(deps, d)
+	}
+	return deps, rows.Err()
+}
+
+// RenameRepo implements the ModuleRepoRenamer interface.
+func (d *DependencyModule) RenameRepo(ctx context.Context, from, to string) error {
+	return internal.RenameRepoIn(ctx, d.database.DB(), from, to,
+		"issue_dependencies.repo", "issue_dependencies.blocker_repo")
+}
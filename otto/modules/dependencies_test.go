@@ -0,0 +1,137 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v71/github"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+func newDependencyTestModule(t *testing.T) (*DependencyModule, *fakeGitHub, *sql.DB) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to open test db: %v", err)
+	}
+	fake := newFakeGitHub()
+	app := &internal.App{Database: internal.NewDatabaseFromDB(db), GitHubClient: fake.client(t)}
+	mod := &DependencyModule{}
+	if err := mod.Initialize(t.Context(), app); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	return mod, fake, db
+}
+
+func commentEvent(repo string, issue int, user, body string) *github.IssueCommentEvent {
+	return &github.IssueCommentEvent{
+		Action: github.Ptr("created"),
+		Repo:   &github.Repository{FullName: github.Ptr(repo)},
+		Issue:  &github.Issue{Number: github.Ptr(issue)},
+		Comment: &github.IssueComment{
+			Body: github.Ptr(body),
+			User: &github.User{Login: github.Ptr(user)},
+		},
+	}
+}
+
+func issuesEvent(action, repo string, issue int) *github.IssuesEvent {
+	return &github.IssuesEvent{
+		Action: github.Ptr(action),
+		Repo:   &github.Repository{FullName: github.Ptr(repo)},
+		Issue:  &github.Issue{Number: github.Ptr(issue)},
+	}
+}
+
+func TestParseIssueRef(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    IssueRef
+		wantErr bool
+	}{
+		{"#12", IssueRef{"org/repo", 12}, false},
+		{"other/repo#3", IssueRef{"other/repo", 3}, false},
+		{"https://github.com/other/repo/issues/7", IssueRef{"other/repo", 7}, false},
+		{"https://github.com/other/repo/pull/8", IssueRef{"other/repo", 8}, false},
+		{"12", IssueRef{}, true},
+		{"#0", IssueRef{}, true},
+	}
+	for _, tt := range tests {
+		got, err := parseIssueRef(tt.in, "org/repo")
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseIssueRef(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseIssueRef(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestDependencyCommands(t *testing.T) {
+	mod, fake, db := newDependencyTestModule(t)
+
+	event := commentEvent("org/repo", 10, "alice", "/blocked-by #1 #2\n/blocks #20")
+	if err := internal.TestCommands(t, mod, event); err != nil {
+		t.Fatalf("HandleCommand failed: %v", err)
+	}
+
+	blockers, _ := ListBlockers(db, IssueRef{"org/repo", 10})
+	if len(blockers) != 2 {
+		t.Fatalf("expected 2 blockers, got %d", len(blockers))
+	}
+	dependents, _ := ListDependents(db, IssueRef{"org/repo", 10})
+	if len(dependents) != 1 || dependents[0].Issue.Number != 20 {
+		t.Fatalf("expected #10 to block #20, got %v", dependents)
+	}
+
+	comments := fake.commentsOn("org/repo", 10)
+	if len(comments) != 1 {
+		t.Fatalf("expected one managed comment on #10, got %d", len(comments))
+	}
+	if !strings.Contains(comments[0], "- [ ] #1") || !strings.Contains(comments[0], "**Blocks**") {
+		t.Errorf("unexpected dependency comment: %s", comments[0])
+	}
+
+	// Closing a blocker updates the managed comment and notifies the dependent.
+	if err := mod.HandleEvent("issues", issuesEvent("closed", "org/repo", 1), nil); err != nil {
+		t.Fatalf("HandleEvent(closed) failed: %v", err)
+	}
+	comments = fake.commentsOn("org/repo", 10)
+	if len(comments) != 2 {
+		t.Fatalf("expected managed comment plus notification, got %d comments", len(comments))
+	}
+	if !strings.Contains(comments[0], "- [x] #1") {
+		t.Errorf("managed comment not updated after close: %s", comments[0])
+	}
+	if !strings.Contains(comments[1], "Blocker #1 was closed") || strings.Contains(comments[1], "no longer blocked") {
+		t.Errorf("unexpected notification: %s", comments[1])
+	}
+
+	// Closing the last blocker reports the issue as unblocked.
+	if err := mod.HandleEvent("issues", issuesEvent("closed", "org/repo", 2), nil); err != nil {
+		t.Fatalf("HandleEvent(closed) failed: %v", err)
+	}
+	comments = fake.commentsOn("org/repo", 10)
+	if last := comments[len(comments)-1]; !strings.Contains(last, "no longer blocked") {
+		t.Errorf("expected unblocked notification, got: %s", last)
+	}
+}
+
+func TestDependencyCommandInvalidRef(t *testing.T) {
+	mod, fake, db := newDependencyTestModule(t)
+
+	if err := internal.TestCommands(t, mod, commentEvent("org/repo", 5, "bob", "/blocked-by soon")); err != nil {
+		t.Fatalf("HandleCommand failed: %v", err)
+	}
+	if blockers, _ := ListBlockers(db, IssueRef{"org/repo", 5}); len(blockers) != 0 {
+		t.Errorf("expected no blockers, got %v", blockers)
+	}
+	comments := fake.commentsOn("org/repo", 5)
+	if len(comments) != 1 || !strings.Contains(comments[0], "Could not parse") {
+		t.Errorf("expected parse error reply, got %v", comments)
+	}
+}
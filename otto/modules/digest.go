@@ -0,0 +1,543 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+// DigestConfig configures the weekly activity digest.
+type DigestConfig struct {
+	Groups        map[string]DigestGroup `yaml:"groups" doc:"group name -> repositories and destinations"`
+	Weekday       string                 `yaml:"weekday" doc:"day the digest is posted, e.g. 'monday'"`
+	Hour          int                    `yaml:"hour" doc:"UTC hour the digest is posted"`
+	NotableIssues int                    `yaml:"notable_issues" doc:"most-discussed issues listed"`
+	ScorecardAPI  string                 `yaml:"scorecard_api" doc:"OpenSSF Scorecard API; empty disables scores"`
+}
+
+// DigestGroup is a set of repositories that share a digest.
+type DigestGroup struct {
+	Repos              []string `yaml:"repos" doc:"repositories summarized in the digest; patterns and @groups allowed"`
+	DiscussionRepo     string   `yaml:"discussion_repo" doc:"repository the digest is posted to as a discussion"`
+	DiscussionCategory string   `yaml:"discussion_category" doc:"discussion category name, e.g. 'Announcements'"`
+	SlackChannel       string   `yaml:"slack_channel" doc:"Slack channel that gets a summary"`
+}
+
+// DigestItem is a pull request or issue listed in a digest.
+type DigestItem struct {
+	Repo     string
+	Number   int
+	Title    string
+	URL      string
+	Author   string
+	Comments int // comments during the period; notable issues only
+}
+
+// ScorecardChange is a repository whose OpenSSF Scorecard score changed since the last digest.
+type ScorecardChange struct {
+	Repo     string
+	Previous float64
+	Current  float64
+}
+
+// ActivityTrend is a repository's webhook event count in the digest's week and the week
+// before, from the daily metric rollups.
+type ActivityTrend struct {
+	Repo     string
+	Events   int
+	Previous int  // events the week before
+	Compared bool // whether the week before was rolled up
+}
+
+// Digest summarizes a week of activity across a group of repositories.
+type Digest struct {
+	Group           string
+	Start           time.Time
+	End             time.Time
+	Merged          []DigestItem
+	NewContributors []string // logins of first-time contributors who opened pull requests
+	Notable         []DigestItem
+	Scorecard       []ScorecardChange
+	Activity        []ActivityTrend
+
+	scores map[string]float64 // current scorecard score by repository
+}
+
+// firstTimeAssociations are author associations GitHub gives to first-time contributors.
+var firstTimeAssociations = map[string]bool{
+	"FIRST_TIMER":            true,
+	"FIRST_TIME_CONTRIBUTOR": true,
+}
+
+// digestLateLimit is how long after its slot a missed digest is still posted; older
+// ones are skipped rather than posted late, e.g. after downtime or a fresh install.
+const digestLateLimit = 24 * time.Hour
+
+// DigestModule posts a weekly digest of merged pull requests, new contributors, notable
+// issues and scorecard changes for each configured repository group.
+type DigestModule struct {
+	app      *internal.App
+	database *internal.Database
+	config   DigestConfig
+	weekday  time.Weekday
+	client   *http.Client
+	now      func() time.Time
+}
+
+func (m *DigestModule) Name() string { return "digest" }
+
+// ConfigSchema implements the ModuleConfigSchema interface.
+func (m *DigestModule) ConfigSchema() any {
+	c := defaultDigestConfig()
+	return &c
+}
+
+// defaultDigestConfig returns the digest module's defaults.
+func defaultDigestConfig() DigestConfig {
+	return DigestConfig{
+		Weekday:       "monday",
+		Hour:          9,
+		NotableIssues: 5,
+		ScorecardAPI:  "https://api.securityscorecards.dev",
+	}
+}
+
+// EventSubscriptions implements the ModuleEventSubscriber interface. Digests are built on
+// a schedule, so the module consumes no events.
+func (m *DigestModule) EventSubscriptions() []internal.EventSubscription {
+	return []internal.EventSubscription{}
+}
+
+// GitHubPermissions implements the ModulePermissionRequirer interface.
+func (m *DigestModule) GitHubPermissions() map[string]string {
+	return map[string]string{"discussions": "write", "issues": "read"}
+}
+
+// Initialize implements the ModuleInitializer interface.
+func (m *DigestModule) Initialize(ctx context.Context, app *internal.App) error {
+	m.app = app
+	m.database = app.Database
+	m.client = app.HTTPClient(30 * time.Second)
+	if m.now == nil {
+		m.now = time.Now
+	}
+	m.config = defaultDigestConfig()
+	if err := loadModuleConfig(app, m.Name(), &m.config); err != nil {
+		return err
+	}
+	weekday, err := parseWeekday(m.config.Weekday)
+	if err != nil {
+		return fmt.Errorf("digest: %w", err)
+	}
+	if m.config.Hour < 0 || m.config.Hour > 23 {
+		return fmt.Errorf("digest: hour must be between 0 and 23, got %d", m.config.Hour)
+	}
+	m.weekday = weekday
+	for name, group := range m.config.Groups {
+		if err := checkRepos(app, "digest group "+name, group.Repos); err != nil {
+			return err
+		}
+	}
+	if len(m.config.Groups) == 0 {
+		slog.Info("Weekly digest not configured; set groups to enable it")
+		return nil
+	}
+
+	if err := AutoMigrateDigest(m.database.DB()); err != nil {
+		return err
+	}
+	app.HandleAdmin("GET /admin/digest/{group}", m.handlePreview)
+	if app.Scheduler != nil {
+		app.Scheduler.Register(internal.Job{
+			Name:       "weekly_digest",
+			Module:     m.Name(),
+			Deferrable: true,
+			Interval:   time.Hour,
+			Run:        m.PostDueDigests,
+		})
+	}
+	return nil
+}
+
+// HandleEvent is a no-op; digests are built from the event store.
+func (m *DigestModule) HandleEvent(eventType string, event any, raw json.RawMessage) error {
+	return nil
+}
+
+func parseWeekday(name string) (time.Weekday, error) {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if strings.EqualFold(name, d.String()) {
+			return d, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown weekday %q", name)
+}
+
+// lastDigestSlot returns the most recent posting time at or before now.
+func lastDigestSlot(now time.Time, weekday time.Weekday, hour int) time.Time {
+	now = now.UTC()
+	slot := time.Date(now.Year(), now.Month(), now.Day(), hour, 0, 0, 0, time.UTC)
+	slot = slot.AddDate(0, 0, -((7 + int(now.Weekday()) - int(weekday)) % 7))
+	if slot.After(now) {
+		slot = slot.AddDate(0, 0, -7)
+	}
+	return slot
+}
+
+// PostDueDigests posts the digest of every group whose weekly slot has passed since its
+// last digest.
+func (m *DigestModule) PostDueDigests(ctx context.Context) error {
+	now := m.now()
+	end := lastDigestSlot(now, m.weekday, m.config.Hour)
+	db := m.database.DB()
+
+	var errs []error
+	for _, name := range slices.Sorted(maps.Keys(m.config.Groups)) {
+		last, err := LastDigestPeriodEnd(db, name)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if !last.Before(end) || now.Sub(end) > digestLateLimit {
+			continue
+		}
+		digest, err := m.Build(ctx, name, end.AddDate(0, 0, -7), end)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("build %s digest: %w", name, err))
+			continue
+		}
+		// Record before delivering so a partly failed delivery is not repeated every hour.
+		if err := RecordDigestRun(db, name, end, now); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for repo, score := range digest.scores {
+			if err := SetScorecardScore(db, repo, score, now); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		if err := m.deliver(ctx, m.config.Groups[name], digest); err != nil {
+			errs = append(errs, fmt.Errorf("deliver %s digest: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Build compiles a group's digest for [start, end) from the event store.
+func (m *DigestModule) Build(ctx context.Context, group string, start, end time.Time) (*Digest, error) {
+	cfg, ok := m.config.Groups[group]
+	if !ok {
+		return nil, fmt.Errorf("unknown digest group %q", group)
+	}
+	digest := &Digest{Group: group, Start: start, End: end}
+	if m.app == nil || m.app.Events == nil {
+		return digest, nil
+	}
+	repos, err := m.app.RepoGroups.Resolve(ctx, cfg.Repos)
+	if err != nil {
+		return nil, err
+	}
+
+	contributors := make(map[string]bool)
+	discussed := make(map[string]*DigestItem) // key: owner/repo#number
+	for _, repo := range repos {
+		events, err := m.app.Events.Query(ctx, internal.EventQuery{Repo: repo, Since: start, Until: end})
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range events {
+			var payload digestPayload
+			if err := json.Unmarshal(e.Payload, &payload); err != nil {
+				slog.Warn("Skipping unreadable stored event", "event_id", e.ID, "error", err)
+				continue
+			}
+			pr, issue := payload.PullRequest, payload.Issue
+			switch {
+			case e.Type == "pull_request" && e.Action == "closed" && pr.Merged:
+				digest.Merged = append(digest.Merged, pr.item(e.Repo))
+			case e.Type == "pull_request" && e.Action == "opened" && firstTimeAssociations[pr.AuthorAssociation]:
+				contributors[pr.User.Login] = true
+			case e.Type == "issue_comment" && e.Action == "created" && issue.PullRequest == nil:
+				key := fmt.Sprintf("%s#%d", e.Repo, issue.Number)
+				if discussed[key] == nil {
+					item := issue.item(e.Repo)
+					discussed[key] = &item
+				}
+				discussed[key].Comments++
+			}
+		}
+	}
+	digest.NewContributors = slices.Sorted(maps.Keys(contributors))
+
+	for _, item := range discussed {
+		digest.Notable = append(digest.Notable, *item)
+	}
+	slices.SortFunc(digest.Notable, func(a, b DigestItem) int {
+		return cmp.Or(b.Comments-a.Comments, strings.Compare(a.Repo, b.Repo), a.Number-b.Number)
+	})
+	digest.Notable = digest.Notable[:min(len(digest.Notable), m.config.NotableIssues)]
+
+	if err := m.checkScorecards(ctx, digest, repos); err != nil {
+		slog.Warn("Failed to check scorecard scores", "group", group, "error", err)
+	}
+	if err := m.checkActivity(ctx, digest, repos); err != nil {
+		slog.Warn("Failed to read activity rollups", "group", group, "error", err)
+	}
+	return digest, nil
+}
+
+// checkActivity compares each repository's events during the digest period with the period
+// before, from the daily rollups. Repositories whose period was not rolled up are left out.
+func (m *DigestModule) checkActivity(ctx context.Context, digest *Digest, repos []string) error {
+	if m.app.Rollups == nil {
+		return nil
+	}
+	period := digest.End.Sub(digest.Start)
+	for _, repo := range repos {
+		events, ok, err := m.app.Rollups.Total(ctx, internal.RollupEvents, repo, digest.Start, digest.End)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		previous, compared, err := m.app.Rollups.Total(ctx, internal.RollupEvents, repo,
+			digest.Start.Add(-period), digest.Start)
+		if err != nil {
+			return err
+		}
+		digest.Activity = append(digest.Activity, ActivityTrend{
+			Repo: repo, Events: int(events), Previous: int(previous), Compared: compared,
+		})
+	}
+	return nil
+}
+
+// digestPayload holds the parts of pull_request and issue_comment payloads a digest uses.
+type digestPayload struct {
+	PullRequest digestIssue `json:"pull_request"`
+	Issue       digestIssue `json:"issue"`
+}
+
+type digestIssue struct {
+	Number            int    `json:"number"`
+	Title             string `json:"title"`
+	HTMLURL           string `json:"html_url"`
+	Merged            bool   `json:"merged"`
+	AuthorAssociation string `json:"author_association"`
+	User              struct {
+		Login string `json:"login"`
+	} `json:"user"`
+	PullRequest *struct{} `json:"pull_request"` // set on issue_comment events for pull requests
+}
+
+func (i digestIssue) item(repo string) DigestItem {
+	return DigestItem{Repo: repo, Number: i.Number, Title: i.Title, URL: i.HTMLURL, Author: i.User.Login}
+}
+
+// checkScorecards fetches each active repository's current OpenSSF Scorecard score and lists
+// those that differ from the score recorded by the previous digest. Scores seen for the
+// first time are not reported. Scores are recorded once the digest is posted.
+func (m *DigestModule) checkScorecards(ctx context.Context, digest *Digest, repos []string) error {
+	if m.config.ScorecardAPI == "" {
+		return nil
+	}
+	digest.scores = make(map[string]float64)
+	var errs []error
+	for _, repo := range repos {
+		if !m.app.RepoActive(repo) {
+			continue
+		}
+		current, err := m.fetchScorecard(ctx, repo)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", repo, err))
+			continue
+		}
+		digest.scores[repo] = current
+		previous, ok, err := GetScorecardScore(m.database.DB(), repo)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if ok && previous != current {
+			digest.Scorecard = append(digest.Scorecard, ScorecardChange{Repo: repo, Previous: previous, Current: current})
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (m *DigestModule) fetchScorecard(ctx context.Context, repo string) (float64, error) {
+	url := strings.TrimSuffix(m.config.ScorecardAPI, "/") + "/projects/github.com/" + repo
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("scorecard API returned %s", resp.Status)
+	}
+	var result struct {
+		Score float64 `json:"score"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return 0, fmt.Errorf("failed to decode scorecard response: %w", err)
+	}
+	return result.Score, nil
+}
+
+// Title is the digest's discussion title.
+func (d *Digest) Title() string {
+	return fmt.Sprintf("Weekly digest: %s (%s – %s)", d.Group,
+		d.Start.UTC().Format(time.DateOnly), d.End.UTC().AddDate(0, 0, -1).Format(time.DateOnly))
+}
+
+// Markdown renders the digest as a GitHub discussion body.
+func (d *Digest) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Activity from %s to %s (UTC).\n",
+		d.Start.UTC().Format(time.DateTime), d.End.UTC().Format(time.DateTime))
+
+	fmt.Fprintf(&b, "\n### 🚀 Merged pull requests (%d)\n\n", len(d.Merged))
+	if len(d.Merged) == 0 {
+		b.WriteString("- none\n")
+	}
+	for _, pr := range d.Merged {
+		fmt.Fprintf(&b, "- [%s#%d](%s) %s (@%s)\n", pr.Repo, pr.Number, pr.URL, pr.Title, pr.Author)
+	}
+
+	fmt.Fprintf(&b, "\n### 👋 New contributors (%d)\n\n", len(d.NewContributors))
+	if len(d.NewContributors) == 0 {
+		b.WriteString("- none\n")
+	} else {
+		fmt.Fprintf(&b, "Welcome @%s!\n", strings.Join(d.NewContributors, ", @"))
+	}
+
+	b.WriteString("\n### 💬 Most discussed issues\n\n")
+	if len(d.Notable) == 0 {
+		b.WriteString("- none\n")
+	}
+	for _, i := range d.Notable {
+		fmt.Fprintf(&b, "- [%s#%d](%s) %s (%d comments)\n", i.Repo, i.Number, i.URL, i.Title, i.Comments)
+	}
+
+	if len(d.Scorecard) > 0 {
+		b.WriteString("\n### 🛡️ OpenSSF Scorecard changes\n\n")
+		for _, c := range d.Scorecard {
+			arrow := "📈"
+			if c.Current < c.Previous {
+				arrow = "📉"
+			}
+			fmt.Fprintf(&b, "- %s %s: %.1f → %.1f\n", arrow, c.Repo, c.Previous, c.Current)
+		}
+	}
+
+	if len(d.Activity) > 0 {
+		b.WriteString("\n### 📊 Activity\n\n")
+		for _, a := range d.Activity {
+			fmt.Fprintf(&b, "- %s: %d events%s\n", a.Repo, a.Events, a.change())
+		}
+	}
+	return b.String()
+}
+
+// change describes the event count relative to the week before, e.g. " (▲ 20% from 35)".
+func (a ActivityTrend) change() string {
+	switch {
+	case !a.Compared:
+		return ""
+	case a.Previous == 0:
+		return " (none the week before)"
+	case a.Events >= a.Previous:
+		return fmt.Sprintf(" (▲ %d%% from %d)", (a.Events-a.Previous)*100/a.Previous, a.Previous)
+	default:
+		return fmt.Sprintf(" (▼ %d%% from %d)", (a.Previous-a.Events)*100/a.Previous, a.Previous)
+	}
+}
+
+// Summary returns a short plain-text summary of the digest for Slack.
+func (d *Digest) Summary() string {
+	summary := fmt.Sprintf("%s: %d merged pull request(s), %d new contributor(s), %d notable issue(s)",
+		d.Title(), len(d.Merged), len(d.NewContributors), len(d.Notable))
+	if len(d.Scorecard) > 0 {
+		summary += fmt.Sprintf(", %d scorecard change(s)", len(d.Scorecard))
+	}
+	return summary + "."
+}
+
+// deliver posts the digest as a discussion and sends its summary to Slack.
+func (m *DigestModule) deliver(ctx context.Context, group DigestGroup, digest *Digest) error {
+	var (
+		url  string
+		errs []error
+	)
+	if group.DiscussionRepo != "" {
+		var err error
+		url, err = m.createDiscussion(ctx, group.DiscussionRepo, group.DiscussionCategory, digest.Title(),
+			digest.Markdown())
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if group.SlackChannel != "" {
+		if m.app == nil || m.app.Slack == nil {
+			slog.Warn("Slack digest configured but no Slack client available", "group", digest.Group)
+		} else {
+			text := digest.Summary()
+			if url != "" {
+				text += "\n" + url
+			}
+			if err := m.app.Slack.PostMessage(ctx, group.SlackChannel, text); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	if group.DiscussionRepo == "" && group.SlackChannel == "" {
+		slog.Info("Weekly digest built with no destination configured", "group", digest.Group,
+			"summary", digest.Summary())
+	}
+	return errors.Join(errs...)
+}
+
+// createDiscussion opens the digest's discussion and returns its URL.
+func (m *DigestModule) createDiscussion(ctx context.Context, repo, category, title, body string) (string, error) {
+	if m.app == nil || m.app.GitHubClient == nil {
+		slog.Info("GitHub discussion would be created (no GitHub client available)",
+			"repo", repo, "title", title)
+		return "", nil
+	}
+	discussion, err := createDiscussion(ctx, m.app.GitHubClient, repo, category, title, body)
+	return discussion.URL, err
+}
+
+// handlePreview renders a group's digest for the last seven days without posting it.
+func (m *DigestModule) handlePreview(w http.ResponseWriter, r *http.Request) {
+	group := r.PathValue("group")
+	if _, ok := m.config.Groups[group]; !ok {
+		http.Error(w, fmt.Sprintf("unknown digest group %q", group), http.StatusNotFound)
+		return
+	}
+	end := m.now()
+	digest, err := m.Build(r.Context(), group, end.AddDate(0, 0, -7), end)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+	fmt.Fprintf(w, "## %s\n\n%s", digest.Title(), digest.Markdown())
+}
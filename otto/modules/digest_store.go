@@ -0,0 +1,83 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+func AutoMigrateDigest(db *sql.DB) error {
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS digest_runs (
+			group_name TEXT PRIMARY KEY,
+			period_end TIMESTAMP NOT NULL,
+			posted_at TIMESTAMP NOT NULL
+		);`,
+		`CREATE TABLE IF NOT EXISTS digest_scores (
+			repo TEXT PRIMARY KEY,
+			score REAL NOT NULL,
+			checked_at TIMESTAMP NOT NULL
+		);`,
+	}
+	for _, s := range stmts {
+		if _, err := db.Exec(s); err != nil {
+			return fmt.Errorf("failed migration: %w (SQL: %s)", err, s)
+		}
+	}
+	return nil
+}
+
+// Migrate implements the ModuleMigrator interface.
+func (m *DigestModule) Migrate(db *sql.DB) error {
+	return AutoMigrateDigest(db)
+}
+
+// LastDigestPeriodEnd returns the end of the last period posted for a group, or the
+// zero time if none was.
+func LastDigestPeriodEnd(db *sql.DB, group string) (time.Time, error) {
+	var end time.Time
+	err := db.QueryRow(`SELECT period_end FROM digest_runs WHERE group_name = ?`, group).Scan(&end)
+	if err == sql.ErrNoRows {
+		return time.Time{}, nil
+	}
+	return end, err
+}
+
+// RecordDigestRun marks a group's digest for the period ending at end as posted.
+func RecordDigestRun(db *sql.DB, group string, end, postedAt time.Time) error {
+	_, err := db.Exec(
+		`INSERT INTO digest_runs (group_name, period_end, posted_at) VALUES (?, ?, ?)
+		 ON CONFLICT (group_name) DO UPDATE SET period_end = excluded.period_end, posted_at = excluded.posted_at`,
+		group, end, postedAt,
+	)
+	return err
+}
+
+// GetScorecardScore returns the last recorded OpenSSF Scorecard score of a repository.
+func GetScorecardScore(db *sql.DB, repo string) (score float64, ok bool, err error) {
+	err = db.QueryRow(`SELECT score FROM digest_scores WHERE repo = ?`, repo).Scan(&score)
+	if err == sql.ErrNoRows {
+		return 0, false, nil
+	}
+	return score, err == nil, err
+}
+
+// SetScorecardScore records the latest OpenSSF Scorecard score of a repository.
+func SetScorecardScore(db *sql.DB, repo string, score float64, at time.Time) error {
+	_, err := db.Exec(
+		`INSERT INTO digest_scores (repo, score, checked_at) VALUES (?, ?, ?)
+		 ON CONFLICT (repo) DO UPDATE SET score = excluded.score, checked_at = excluded.checked_at`,
+		repo, score, at,
+	)
+	return err
+}
+
+// RenameRepo implements the ModuleRepoRenamer interface.
+func (m *DigestModule) RenameRepo(ctx context.Context, from, to string) error {
+	return internal.RenameRepoIn(ctx, m.database.DB(), from, to, "digest_scores.repo")
+}
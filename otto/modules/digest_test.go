@@ -0,0 +1,336 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+// digestSlot is a Monday 09:00 UTC, the default posting time.
+var digestSlot = time.Date(2025, time.June, 9, 9, 0, 0, 0, time.UTC)
+
+type digestTestEnv struct {
+	mod    *DigestModule
+	fake   *fakeGitHub
+	events *internal.EventStore
+	scores map[string]float64 // served by the fake Scorecard API
+	slack  []map[string]string
+}
+
+func newDigestTestEnv(t *testing.T) *digestTestEnv {
+	t.Helper()
+	db := internal.TestDB(t)
+	events, err := internal.NewEventStore(db)
+	if err != nil {
+		t.Fatalf("NewEventStore failed: %v", err)
+	}
+	if err := AutoMigrateDigest(db); err != nil {
+		t.Fatalf("AutoMigrateDigest failed: %v", err)
+	}
+	env := &digestTestEnv{fake: newFakeGitHub(), events: events, scores: make(map[string]float64)}
+
+	scorecard := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		score, ok := env.scores[strings.TrimPrefix(r.URL.Path, "/projects/github.com/")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = fmt.Fprintf(w, `{"score": %v}`, score)
+	}))
+	t.Cleanup(scorecard.Close)
+	slack := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&msg)
+		env.slack = append(env.slack, msg)
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	t.Cleanup(slack.Close)
+
+	groups, err := internal.NewRepoGroups(map[string][]string{"collector-repos": {"org/collector", "org/contrib"}}, nil)
+	if err != nil {
+		t.Fatalf("NewRepoGroups failed: %v", err)
+	}
+	env.mod = &DigestModule{
+		app: &internal.App{
+			RepoGroups:   groups,
+			GitHubClient: env.fake.client(t),
+			Events:       events,
+			Slack:        internal.NewSlackClient("xoxb-test").WithBaseURL(slack.URL),
+		},
+		database: internal.NewDatabaseFromDB(db),
+		config: DigestConfig{
+			Groups: map[string]DigestGroup{
+				"collector": {
+					Repos:              []string{"@collector-repos"},
+					DiscussionRepo:     "org/community",
+					DiscussionCategory: "announcements",
+					SlackChannel:       "#collector",
+				},
+			},
+			Hour:          9,
+			NotableIssues: 2,
+			ScorecardAPI:  scorecard.URL,
+		},
+		weekday: time.Monday,
+		client:  scorecard.Client(),
+		now:     func() time.Time { return digestSlot.Add(time.Hour) },
+	}
+	return env
+}
+
+// record stores a webhook event received at the given time.
+func (env *digestTestEnv) record(t *testing.T, eventType string, at time.Time, payload string) {
+	t.Helper()
+	e := internal.NewStoredEvent("d", eventType, []byte(payload))
+	e.ReceivedAt = at
+	if _, err := env.events.Record(t.Context(), e); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+}
+
+func (env *digestTestEnv) recordWeek(t *testing.T) {
+	t.Helper()
+	during := digestSlot.Add(-48 * time.Hour)
+	pr := func(repo, action string, number int, merged bool, user, association string) string {
+		return fmt.Sprintf(`{"action":%q,"repository":{"full_name":%q},"pull_request":{"number":%d,`+
+			`"title":"PR %d","html_url":"https://github.com/%s/pull/%d","merged":%v,`+
+			`"user":{"login":%q},"author_association":%q}}`,
+			action, repo, number, number, repo, number, merged, user, association)
+	}
+	comment := func(repo string, number int, isPR bool) string {
+		prField := ""
+		if isPR {
+			prField = `,"pull_request":{}`
+		}
+		return fmt.Sprintf(`{"action":"created","repository":{"full_name":%q},"issue":{"number":%d,`+
+			`"title":"Issue %d","html_url":"https://github.com/%s/issues/%d"%s}}`,
+			repo, number, number, repo, number, prField)
+	}
+
+	env.record(t, "pull_request", during, pr("org/collector", "closed", 1, true, "alice", "MEMBER"))
+	env.record(t, "pull_request", during, pr("org/contrib", "closed", 2, true, "newbie", "FIRST_TIME_CONTRIBUTOR"))
+	env.record(t, "pull_request", during, pr("org/contrib", "closed", 3, false, "bob", "MEMBER"))
+	env.record(t, "pull_request", during, pr("org/contrib", "opened", 4, false, "newbie", "FIRST_TIME_CONTRIBUTOR"))
+	env.record(t, "pull_request", during, pr("org/contrib", "opened", 5, false, "carol", "CONTRIBUTOR"))
+	env.record(t, "pull_request", digestSlot.AddDate(0, 0, -8), pr("org/collector", "closed", 6, true, "old", ""))
+	env.record(t, "pull_request", during, pr("org/unrelated", "closed", 7, true, "dave", "MEMBER"))
+	for range 3 {
+		env.record(t, "issue_comment", during, comment("org/contrib", 10, false))
+	}
+	for range 2 {
+		env.record(t, "issue_comment", during, comment("org/collector", 11, false))
+	}
+	env.record(t, "issue_comment", during, comment("org/collector", 12, false))
+	for range 5 {
+		env.record(t, "issue_comment", during, comment("org/collector", 2, true))
+	}
+}
+
+func TestLastDigestSlot(t *testing.T) {
+	tests := []struct {
+		name string
+		now  time.Time
+		want time.Time
+	}{
+		{name: "at the slot", now: digestSlot, want: digestSlot},
+		{name: "later the same day", now: digestSlot.Add(3 * time.Hour), want: digestSlot},
+		{name: "earlier the same day", now: digestSlot.Add(-time.Hour), want: digestSlot.AddDate(0, 0, -7)},
+		{name: "later in the week", now: digestSlot.AddDate(0, 0, 4), want: digestSlot},
+		{
+			name: "other time zone",
+			now:  digestSlot.AddDate(0, 0, 7).In(time.FixedZone("UTC-10", -10*3600)),
+			want: digestSlot.AddDate(0, 0, 7),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lastDigestSlot(tt.now, time.Monday, 9); !got.Equal(tt.want) {
+				t.Errorf("lastDigestSlot(%v) = %v, want %v", tt.now, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDigestBuild(t *testing.T) {
+	env := newDigestTestEnv(t)
+	env.recordWeek(t)
+	if err := SetScorecardScore(env.mod.database.DB(), "org/collector", 6.5, digestSlot.AddDate(0, 0, -7)); err != nil {
+		t.Fatalf("SetScorecardScore failed: %v", err)
+	}
+	env.scores["org/collector"] = 7.2
+	env.scores["org/contrib"] = 5.0 // first score; recorded but not reported
+
+	digest, err := env.mod.Build(t.Context(), "collector", digestSlot.AddDate(0, 0, -7), digestSlot)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	var merged []string
+	for _, pr := range digest.Merged {
+		merged = append(merged, fmt.Sprintf("%s#%d", pr.Repo, pr.Number))
+	}
+	if strings.Join(merged, " ") != "org/collector#1 org/contrib#2" {
+		t.Errorf("merged = %v", merged)
+	}
+	if strings.Join(digest.NewContributors, " ") != "newbie" {
+		t.Errorf("new contributors = %v", digest.NewContributors)
+	}
+	if len(digest.Notable) != 2 || digest.Notable[0].Number != 10 || digest.Notable[0].Comments != 3 ||
+		digest.Notable[1].Number != 11 {
+		t.Errorf("notable = %+v", digest.Notable)
+	}
+	if len(digest.Scorecard) != 1 || digest.Scorecard[0] != (ScorecardChange{"org/collector", 6.5, 7.2}) {
+		t.Errorf("scorecard = %+v", digest.Scorecard)
+	}
+
+	markdown := digest.Markdown()
+	for _, want := range []string{
+		"### 🚀 Merged pull requests (2)",
+		"- [org/contrib#2](https://github.com/org/contrib/pull/2) PR 2 (@newbie)",
+		"Welcome @newbie!",
+		"- [org/contrib#10](https://github.com/org/contrib/issues/10) Issue 10 (3 comments)",
+		"- 📈 org/collector: 6.5 → 7.2",
+	} {
+		if !strings.Contains(markdown, want) {
+			t.Errorf("markdown missing %q:\n%s", want, markdown)
+		}
+	}
+	if got, want := digest.Title(), "Weekly digest: collector (2025-06-02 – 2025-06-08)"; got != want {
+		t.Errorf("title = %q, want %q", got, want)
+	}
+}
+
+func TestDigestActivity(t *testing.T) {
+	env := newDigestTestEnv(t)
+	env.recordWeek(t)
+	db := env.mod.database.DB()
+	if _, err := internal.NewCommandHistory(db); err != nil {
+		t.Fatalf("NewCommandHistory failed: %v", err)
+	}
+	rollups, err := internal.NewMetricRollups(db, internal.NewModuleRegistry(), 0)
+	if err != nil {
+		t.Fatalf("NewMetricRollups failed: %v", err)
+	}
+	start := digestSlot.AddDate(0, 0, -7)
+	for day := start.AddDate(0, 0, -7); day.Before(digestSlot); day = day.AddDate(0, 0, 1) {
+		if err := rollups.RollupDay(t.Context(), day); err != nil {
+			t.Fatalf("RollupDay failed: %v", err)
+		}
+	}
+	env.mod.app.Rollups = rollups
+
+	digest, err := env.mod.Build(t.Context(), "collector", start, digestSlot)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	want := []ActivityTrend{
+		{Repo: "org/collector", Events: 9, Previous: 1, Compared: true},
+		{Repo: "org/contrib", Events: 7, Compared: true},
+	}
+	if !slices.Equal(digest.Activity, want) {
+		t.Errorf("activity = %+v, want %+v", digest.Activity, want)
+	}
+	markdown := digest.Markdown()
+	for _, line := range []string{
+		"- org/collector: 9 events (▲ 800% from 1)",
+		"- org/contrib: 7 events (none the week before)",
+	} {
+		if !strings.Contains(markdown, line) {
+			t.Errorf("markdown missing %q:\n%s", line, markdown)
+		}
+	}
+}
+
+func TestPostDueDigests(t *testing.T) {
+	env := newDigestTestEnv(t)
+	env.recordWeek(t)
+	db := env.mod.database.DB()
+
+	if err := env.mod.PostDueDigests(t.Context()); err != nil {
+		t.Fatalf("PostDueDigests failed: %v", err)
+	}
+	discussions := env.fake.createdDiscussions()
+	if len(discussions) != 1 {
+		t.Fatalf("created %d discussions, want 1", len(discussions))
+	}
+	d := discussions[0]
+	if d["repositoryId"] != "R_org/community" || d["categoryId"] != "DIC_announcements" ||
+		!strings.HasPrefix(fmt.Sprint(d["title"]), "Weekly digest: collector") ||
+		!strings.Contains(fmt.Sprint(d["body"]), "Welcome @newbie!") {
+		t.Errorf("unexpected discussion: %v", d)
+	}
+	if len(env.slack) != 1 || env.slack[0]["channel"] != "#collector" ||
+		!strings.Contains(env.slack[0]["text"], "2 merged pull request(s), 1 new contributor(s)") ||
+		!strings.Contains(env.slack[0]["text"], "https://github.com/org/community/discussions/1") {
+		t.Errorf("unexpected Slack messages: %v", env.slack)
+	}
+	if end, err := LastDigestPeriodEnd(db, "collector"); err != nil || !end.Equal(digestSlot) {
+		t.Errorf("recorded period end = %v, %v; want %v", end, err, digestSlot)
+	}
+
+	// The same period is not posted twice.
+	if err := env.mod.PostDueDigests(t.Context()); err != nil {
+		t.Fatalf("PostDueDigests failed: %v", err)
+	}
+	if got := len(env.fake.createdDiscussions()); got != 1 {
+		t.Errorf("created %d discussions after a second run, want 1", got)
+	}
+
+	// A slot missed by more than a day is skipped.
+	env.mod.now = func() time.Time { return digestSlot.AddDate(0, 0, 9) }
+	if err := env.mod.PostDueDigests(t.Context()); err != nil {
+		t.Fatalf("PostDueDigests failed: %v", err)
+	}
+	if got := len(env.fake.createdDiscussions()); got != 1 {
+		t.Errorf("late digest was posted: %d discussions", got)
+	}
+}
+
+func TestDigestUnknownCategory(t *testing.T) {
+	env := newDigestTestEnv(t)
+	group := env.mod.config.Groups["collector"]
+	group.DiscussionCategory = "Weekly"
+	group.SlackChannel = ""
+	env.mod.config.Groups["collector"] = group
+
+	err := env.mod.PostDueDigests(t.Context())
+	if err == nil || !strings.Contains(err.Error(), `org/community has no discussion category "Weekly"`) {
+		t.Errorf("err = %v, want an unknown category error", err)
+	}
+}
+
+func TestDigestPreview(t *testing.T) {
+	env := newDigestTestEnv(t)
+	env.recordWeek(t)
+	env.mod.now = func() time.Time { return digestSlot }
+
+	tests := []struct {
+		group      string
+		wantStatus int
+		want       string
+	}{
+		{group: "collector", wantStatus: http.StatusOK, want: "## Weekly digest: collector"},
+		{group: "nope", wantStatus: http.StatusNotFound, want: `unknown digest group "nope"`},
+	}
+	for _, tt := range tests {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/admin/digest/"+tt.group, nil)
+		req.SetPathValue("group", tt.group)
+		env.mod.handlePreview(rr, req)
+		if rr.Code != tt.wantStatus || !strings.Contains(rr.Body.String(), tt.want) {
+			t.Errorf("%s: status %d body %q; want %d containing %q", tt.group, rr.Code, rr.Body.String(),
+				tt.wantStatus, tt.want)
+		}
+	}
+	if got := len(env.fake.createdDiscussions()); got != 0 {
+		t.Errorf("preview created %d discussions", got)
+	}
+}
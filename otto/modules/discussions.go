@@ -0,0 +1,116 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-github/v71/github"
+)
+
+// discussion is a discussion created through the GraphQL API.
+type discussion struct {
+	ID     string `json:"id"`
+	Number int    `json:"number"`
+	URL    string `json:"url"`
+}
+
+// createDiscussion opens a discussion in a category of repo through the GraphQL API,
+// which is the only API that can create one.
+func createDiscussion(ctx context.Context, client *github.Client, repo, category, title,
+	body string) (discussion, error) {
+	owner, name, ok := strings.Cut(repo, "/")
+	if !ok {
+		return discussion{}, fmt.Errorf("invalid discussion repository %q", repo)
+	}
+
+	var lookup struct {
+		Repository struct {
+			ID                   string `json:"id"`
+			DiscussionCategories struct {
+				Nodes []struct {
+					ID   string `json:"id"`
+					Name string `json:"name"`
+				} `json:"nodes"`
+			} `json:"discussionCategories"`
+		} `json:"repository"`
+	}
+	err := graphQL(ctx, client, `query($owner: String!, $name: String!) {
+		repository(owner: $owner, name: $name) { id discussionCategories(first: 100) { nodes { id name } } }
+	}`, map[string]any{"owner": owner, "name": name}, &lookup)
+	if err != nil {
+		return discussion{}, err
+	}
+	var categoryID string
+	for _, c := range lookup.Repository.DiscussionCategories.Nodes {
+		if strings.EqualFold(c.Name, category) {
+			categoryID = c.ID
+		}
+	}
+	if categoryID == "" {
+		return discussion{}, fmt.Errorf("%s has no discussion category %q", repo, category)
+	}
+
+	var created struct {
+		CreateDiscussion struct {
+			Discussion discussion `json:"discussion"`
+		} `json:"createDiscussion"`
+	}
+	err = graphQL(ctx, client, `mutation($repositoryId: ID!, $categoryId: ID!, $title: String!, $body: String!) {
+		createDiscussion(input: {repositoryId: $repositoryId, categoryId: $categoryId, title: $title, body: $body}) {
+			discussion { id number url }
+		}
+	}`, map[string]any{
+		"repositoryId": lookup.Repository.ID,
+		"categoryId":   categoryID,
+		"title":        title,
+		"body":         body,
+	}, &created)
+	if err != nil {
+		return discussion{}, err
+	}
+	return created.CreateDiscussion.Discussion, nil
+}
+
+// closeDiscussion closes the discussion with the given node ID as outdated.
+func closeDiscussion(ctx context.Context, client *github.Client, id string) error {
+	return graphQL(ctx, client, `mutation($id: ID!) {
+		closeDiscussion(input: {discussionId: $id, reason: OUTDATED}) { discussion { id } }
+	}`, map[string]any{"id": id}, &struct{}{})
+}
+
+// pinIssue pins the issue with the given node ID to its repository. The REST API
+// cannot pin issues, and neither API can pin discussions.
+func pinIssue(ctx context.Context, client *github.Client, id string) error {
+	return graphQL(ctx, client, `mutation($id: ID!) {
+		pinIssue(input: {issueId: $id}) { issue { id } }
+	}`, map[string]any{"id": id}, &struct{}{})
+}
+
+// graphQL runs a GraphQL query with client and decodes its data into out.
+func graphQL(ctx context.Context, client *github.Client, query string, variables map[string]any, out any) error {
+	req, err := client.NewRequest(http.MethodPost, "graphql", map[string]any{
+		"query":     query,
+		"variables": variables,
+	})
+	if err != nil {
+		return err
+	}
+	var resp struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if _, err := client.Do(ctx, req, &resp); err != nil {
+		return err
+	}
+	if len(resp.Errors) > 0 {
+		return fmt.Errorf("GraphQL error: %s", resp.Errors[0].Message)
+	}
+	return json.Unmarshal(resp.Data, out)
+}
@@ -0,0 +1,932 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v71/github"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+// fakeGitHub is a minimal in-memory GitHub API used by module tests.
+type fakeGitHub struct {
+	mu          sync.Mutex
+	nextID      int64
+	comments    map[string][]*github.IssueComment         // key: owner/repo#number
+	labels      map[string][]string                       // key: owner/repo#number
+	states      map[string]string                         // key: owner/repo#number
+	issues      map[string]*github.Issue                  // key: owner/repo#number; served with the state
+	bodies      map[string]string                         // key: owner/repo#number; bodies edited via the API
+	reactions   map[int64][]*github.Reaction              // key: comment ID
+	repoLabels  map[string][]*github.Label                // key: owner/repo
+	repos       map[string]*github.Repository             // key: owner/repo; settings set via the API
+	files       map[string]map[string]string              // key: owner/repo@branch, then path
+	pulls       map[string][]*github.PullRequest          // key: owner/repo
+	checkRuns   map[string][]github.CreateCheckRunOptions // key: owner/repo
+	timelines   map[string][]*github.Timeline             // key: owner/repo#number
+	commits     map[string][]*github.RepositoryCommit     // key: owner/repo#number
+	searches    []string                                  // issue search queries received
+	discussions []map[string]any                          // createDiscussion inputs
+	closed      []string                                  // node IDs of discussions closed
+	pinned      []string                                  // node IDs of issues pinned
+	teams       map[string][]string                       // key: org/team; member logins
+	opened      map[string][]*github.IssueRequest         // key: owner/repo; issues opened via the API
+	involved    map[string][]string                       // key: owner/repo#number; e.g. "commenter:alice"
+	updated     map[string]time.Time                      // key: owner/repo#number
+	artifacts   map[int64][]*github.Artifact              // key: workflow run ID
+	archives    map[int64][]byte                          // key: artifact ID; zip contents
+	prFiles     map[string][]*github.CommitFile           // key: owner/repo#number
+	trees       map[string][]*github.TreeEntry            // key: owner/repo@sha
+	subIssues   map[string][]*github.Issue                // key: owner/repo#number of the parent
+	permissions map[string]string                         // key: owner/repo@login; default: read
+	dispatches  []string                                  // "owner/repo path body" of dispatches sent
+	rate        *github.Rate                              // core rate limit; nil serves 404
+	mux         *http.ServeMux
+}
+
+func newFakeGitHub() *fakeGitHub {
+	f := &fakeGitHub{
+		comments:    make(map[string][]*github.IssueComment),
+		labels:      make(map[string][]string),
+		states:      make(map[string]string),
+		issues:      make(map[string]*github.Issue),
+		bodies:      make(map[string]string),
+		reactions:   make(map[int64][]*github.Reaction),
+		repoLabels:  make(map[string][]*github.Label),
+		repos:       make(map[string]*github.Repository),
+		files:       make(map[string]map[string]string),
+		pulls:       make(map[string][]*github.PullRequest),
+		checkRuns:   make(map[string][]github.CreateCheckRunOptions),
+		timelines:   make(map[string][]*github.Timeline),
+		commits:     make(map[string][]*github.RepositoryCommit),
+		teams:       make(map[string][]string),
+		opened:      make(map[string][]*github.IssueRequest),
+		involved:    make(map[string][]string),
+		updated:     make(map[string]time.Time),
+		artifacts:   make(map[int64][]*github.Artifact),
+		archives:    make(map[int64][]byte),
+		prFiles:     make(map[string][]*github.CommitFile),
+		trees:       make(map[string][]*github.TreeEntry),
+		subIssues:   make(map[string][]*github.Issue),
+		permissions: make(map[string]string),
+		mux:         http.NewServeMux(),
+	}
+	f.mux.HandleFunc("GET /repos/{owner}/{repo}/issues/{number}/comments", f.listComments)
+	f.mux.HandleFunc("POST /repos/{owner}/{repo}/issues/{number}/comments", f.createComment)
+	f.mux.HandleFunc("PATCH /repos/{owner}/{repo}/issues/comments/{id}", f.editComment)
+	f.mux.HandleFunc("POST /repos/{owner}/{repo}/issues/{number}/labels", f.addLabels)
+	f.mux.HandleFunc("DELETE /repos/{owner}/{repo}/issues/{number}/labels/{name}", f.removeLabel)
+	f.mux.HandleFunc("PATCH /repos/{owner}/{repo}/issues/{number}", f.editIssue)
+	f.mux.HandleFunc("GET /repos/{owner}/{repo}/issues/{number}", f.getIssue)
+	f.mux.HandleFunc("GET /repos/{owner}/{repo}/issues/comments/{id}/reactions", f.listReactions)
+	f.mux.HandleFunc("GET /repos/{owner}/{repo}/labels", f.listRepoLabels)
+	f.mux.HandleFunc("POST /repos/{owner}/{repo}/labels", f.createRepoLabel)
+	f.mux.HandleFunc("PATCH /repos/{owner}/{repo}/labels/{name}", f.editRepoLabel)
+	f.mux.HandleFunc("PATCH /repos/{owner}/{repo}", f.editRepo)
+	f.mux.HandleFunc("GET /repos/{owner}/{repo}", f.getRepo)
+	f.mux.HandleFunc("GET /repos/{owner}/{repo}/contents/{path...}", f.getContents)
+	f.mux.HandleFunc("PUT /repos/{owner}/{repo}/contents/{path...}", f.putContents)
+	f.mux.HandleFunc("GET /repos/{owner}/{repo}/git/ref/{ref...}", f.getRef)
+	f.mux.HandleFunc("POST /repos/{owner}/{repo}/git/refs", f.createRef)
+	f.mux.HandleFunc("GET /repos/{owner}/{repo}/pulls", f.listPulls)
+	f.mux.HandleFunc("POST /repos/{owner}/{repo}/pulls", f.createPull)
+	f.mux.HandleFunc("GET /repos/{owner}/{repo}/pulls/{number}", f.getPull)
+	f.mux.HandleFunc("GET /repos/{owner}/{repo}/pulls/{number}/commits", f.listPullCommits)
+	f.mux.HandleFunc("POST /repos/{owner}/{repo}/check-runs", f.createCheckRun)
+	f.mux.HandleFunc("GET /repos/{owner}/{repo}/issues/{number}/timeline", f.listTimeline)
+	f.mux.HandleFunc("GET /search/issues", f.searchIssues)
+	f.mux.HandleFunc("POST /graphql", f.graphQL)
+	f.mux.HandleFunc("GET /orgs/{org}/teams/{team}/members", f.listTeamMembers)
+	f.mux.HandleFunc("POST /repos/{owner}/{repo}/issues", f.createIssue)
+	f.mux.HandleFunc("GET /repos/{owner}/{repo}/actions/runs/{id}/artifacts", f.listRunArtifacts)
+	f.mux.HandleFunc("GET /repos/{owner}/{repo}/actions/artifacts/{id}/zip", f.downloadArtifact)
+	f.mux.HandleFunc("GET /artifact-downloads/{id}", f.artifactArchive)
+	f.mux.HandleFunc("GET /repos/{owner}/{repo}/pulls/{number}/files", f.listPullFiles)
+	f.mux.HandleFunc("GET /repos/{owner}/{repo}/git/trees/{sha}", f.getTree)
+	f.mux.HandleFunc("GET /rate_limit", f.getRateLimit)
+	f.mux.HandleFunc("GET /repos/{owner}/{repo}/issues/{number}/sub_issues", f.listSubIssues)
+	f.mux.HandleFunc("GET /repos/{owner}/{repo}/collaborators/{user}/permission", f.getPermissionLevel)
+	f.mux.HandleFunc("POST /repos/{owner}/{repo}/dispatches", f.dispatch)
+	f.mux.HandleFunc("POST /repos/{owner}/{repo}/actions/workflows/{workflow}/dispatches", f.dispatch)
+	return f
+}
+
+// labelsOn returns the labels currently applied to an issue.
+func (f *fakeGitHub) labelsOn(repo string, number int) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.labels[fmt.Sprintf("%s#%d", repo, number)]...)
+}
+
+// setLabels replaces the labels on an issue.
+func (f *fakeGitHub) setLabels(repo string, number int, labels ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.labels[fmt.Sprintf("%s#%d", repo, number)] = labels
+}
+
+// react adds a reaction from user to a comment.
+func (f *fakeGitHub) react(commentID int64, user, content string, at time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reactions[commentID] = append(f.reactions[commentID], &github.Reaction{
+		User:      &github.User{Login: github.Ptr(user)},
+		Content:   github.Ptr(content),
+		CreatedAt: &github.Timestamp{Time: at},
+	})
+}
+
+// stateOf returns the state set via the API ("" if never changed).
+func (f *fakeGitHub) stateOf(repo string, number int) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.states[fmt.Sprintf("%s#%d", repo, number)]
+}
+
+// client returns a GitHub client backed by the fake.
+func (f *fakeGitHub) client(t *testing.T) *github.Client {
+	return internal.TestGitHubClient(t, f.mux)
+}
+
+// commentsOn returns the comment bodies on an issue.
+func (f *fakeGitHub) commentsOn(repo string, number int) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var bodies []string
+	for _, c := range f.comments[fmt.Sprintf("%s#%d", repo, number)] {
+		bodies = append(bodies, c.GetBody())
+	}
+	return bodies
+}
+
+func issueKey(r *http.Request) string {
+	return fmt.Sprintf("%s/%s#%s", r.PathValue("owner"), r.PathValue("repo"), r.PathValue("number"))
+}
+
+func (f *fakeGitHub) listComments(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	comments := f.comments[issueKey(r)]
+	if comments == nil {
+		comments = []*github.IssueComment{}
+	}
+	_ = json.NewEncoder(w).Encode(comments)
+}
+
+func (f *fakeGitHub) createComment(w http.ResponseWriter, r *http.Request) {
+	var c github.IssueComment
+	if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	f.mu.Lock()
+	f.nextID++
+	c.ID = github.Ptr(f.nextID)
+	key := issueKey(r)
+	f.comments[key] = append(f.comments[key], &c)
+	f.mu.Unlock()
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(c)
+}
+
+func (f *fakeGitHub) editComment(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	var update github.IssueComment
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, comments := range f.comments {
+		for _, c := range comments {
+			if c.GetID() == id {
+				c.Body = update.Body
+				_ = json.NewEncoder(w).Encode(c)
+				return
+			}
+		}
+	}
+	http.NotFound(w, r)
+}
+
+func (f *fakeGitHub) labelObjects(key string) []*github.Label {
+	labels := make([]*github.Label, 0, len(f.labels[key]))
+	for _, name := range f.labels[key] {
+		labels = append(labels, &github.Label{Name: github.Ptr(name)})
+	}
+	return labels
+}
+
+func (f *fakeGitHub) addLabels(w http.ResponseWriter, r *http.Request) {
+	var names []string
+	if err := json.NewDecoder(r.Body).Decode(&names); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := issueKey(r)
+	for _, name := range names {
+		if !slices.Contains(f.labels[key], name) {
+			f.labels[key] = append(f.labels[key], name)
+		}
+	}
+	_ = json.NewEncoder(w).Encode(f.labelObjects(key))
+}
+
+func (f *fakeGitHub) removeLabel(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := issueKey(r)
+	name := r.PathValue("name")
+	idx := slices.Index(f.labels[key], name)
+	if idx < 0 {
+		http.NotFound(w, r)
+		return
+	}
+	f.labels[key] = slices.Delete(f.labels[key], idx, idx+1)
+	_ = json.NewEncoder(w).Encode(f.labelObjects(key))
+}
+
+func (f *fakeGitHub) editIssue(w http.ResponseWriter, r *http.Request) {
+	var req github.IssueRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := issueKey(r)
+	if req.State != nil {
+		f.states[key] = req.GetState()
+	}
+	if req.Body != nil {
+		f.bodies[key] = req.GetBody()
+	}
+	_ = json.NewEncoder(w).Encode(&github.Issue{State: req.State})
+}
+
+// getIssue serves an issue in the state set via the API, open if it was never changed.
+func (f *fakeGitHub) getIssue(w http.ResponseWriter, r *http.Request) {
+	number, _ := strconv.Atoi(r.PathValue("number"))
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	issue := github.Issue{Number: github.Ptr(number), State: github.Ptr("open")}
+	if stored := f.issues[issueKey(r)]; stored != nil {
+		issue = *stored
+	}
+	if state := f.states[issueKey(r)]; state != "" {
+		issue.State = github.Ptr(state)
+	}
+	_ = json.NewEncoder(w).Encode(&issue)
+}
+
+func (f *fakeGitHub) listSubIssues(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	subs := f.subIssues[issueKey(r)]
+	if subs == nil {
+		subs = []*github.Issue{}
+	}
+	_ = json.NewEncoder(w).Encode(subs)
+}
+
+func (f *fakeGitHub) getPermissionLevel(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	permission, ok := f.permissions[repoKey(r)+"@"+r.PathValue("user")]
+	if !ok {
+		permission = "read"
+	}
+	_ = json.NewEncoder(w).Encode(&github.RepositoryPermissionLevel{Permission: github.Ptr(permission)})
+}
+
+func (f *fakeGitHub) dispatch(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	route := strings.TrimPrefix(r.URL.Path, "/repos/"+repoKey(r)+"/")
+	f.dispatches = append(f.dispatches, repoKey(r)+" "+route+" "+strings.TrimSpace(string(body)))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (f *fakeGitHub) listReactions(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	reactions := f.reactions[id]
+	if reactions == nil {
+		reactions = []*github.Reaction{}
+	}
+	_ = json.NewEncoder(w).Encode(reactions)
+}
+
+// repoLabel returns a repository label by name, or nil.
+func (f *fakeGitHub) repoLabel(repo, name string) *github.Label {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, l := range f.repoLabels[repo] {
+		if l.GetName() == name {
+			return l
+		}
+	}
+	return nil
+}
+
+// repoSettings returns the settings last applied to a repository, or nil.
+func (f *fakeGitHub) repoSettings(repo string) *github.Repository {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.repos[repo]
+}
+
+func repoKey(r *http.Request) string {
+	return r.PathValue("owner") + "/" + r.PathValue("repo")
+}
+
+func (f *fakeGitHub) listRepoLabels(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	labels := f.repoLabels[repoKey(r)]
+	if labels == nil {
+		labels = []*github.Label{}
+	}
+	_ = json.NewEncoder(w).Encode(labels)
+}
+
+func (f *fakeGitHub) createRepoLabel(w http.ResponseWriter, r *http.Request) {
+	var l github.Label
+	if err := json.NewDecoder(r.Body).Decode(&l); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	f.mu.Lock()
+	key := repoKey(r)
+	f.repoLabels[key] = append(f.repoLabels[key], &l)
+	f.mu.Unlock()
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(l)
+}
+
+func (f *fakeGitHub) editRepoLabel(w http.ResponseWriter, r *http.Request) {
+	var update github.Label
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, l := range f.repoLabels[repoKey(r)] {
+		if l.GetName() == r.PathValue("name") {
+			*l = update
+			_ = json.NewEncoder(w).Encode(l)
+			return
+		}
+	}
+	http.NotFound(w, r)
+}
+
+func (f *fakeGitHub) editRepo(w http.ResponseWriter, r *http.Request) {
+	var repo github.Repository
+	if err := json.NewDecoder(r.Body).Decode(&repo); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	f.mu.Lock()
+	f.repos[repoKey(r)] = &repo
+	f.mu.Unlock()
+	_ = json.NewEncoder(w).Encode(repo)
+}
+
+// fakeDefaultBranch is the default branch of every fake repository.
+const fakeDefaultBranch = "main"
+
+// setFile writes a file to a branch of a repository, creating the branch if needed.
+func (f *fakeGitHub) setFile(repo, branch, path, content string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := repo + "@" + branch
+	if f.files[key] == nil {
+		f.files[key] = make(map[string]string)
+	}
+	f.files[key][path] = content
+}
+
+// fileOn returns a file's content on a branch and whether it exists.
+func (f *fakeGitHub) fileOn(repo, branch, path string) (string, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	content, ok := f.files[repo+"@"+branch][path]
+	return content, ok
+}
+
+// pullsFor returns the pull requests opened via the API.
+func (f *fakeGitHub) pullsFor(repo string) []*github.PullRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]*github.PullRequest(nil), f.pulls[repo]...)
+}
+
+func blobSHA(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+func (f *fakeGitHub) getRepo(w http.ResponseWriter, r *http.Request) {
+	_ = json.NewEncoder(w).Encode(&github.Repository{
+		FullName:      github.Ptr(repoKey(r)),
+		DefaultBranch: github.Ptr(fakeDefaultBranch),
+	})
+}
+
+func (f *fakeGitHub) getContents(w http.ResponseWriter, r *http.Request) {
+	ref := r.URL.Query().Get("ref")
+	if ref == "" {
+		ref = fakeDefaultBranch
+	}
+	content, ok := f.fileOn(repoKey(r), ref, r.PathValue("path"))
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(&github.RepositoryContent{
+		Type:     github.Ptr("file"),
+		Path:     github.Ptr(r.PathValue("path")),
+		Encoding: github.Ptr("base64"),
+		Content:  github.Ptr(base64.StdEncoding.EncodeToString([]byte(content))),
+		SHA:      github.Ptr(blobSHA(content)),
+	})
+}
+
+func (f *fakeGitHub) putContents(w http.ResponseWriter, r *http.Request) {
+	var opts github.RepositoryContentFileOptions
+	if err := json.NewDecoder(r.Body).Decode(&opts); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	repo, path := repoKey(r), r.PathValue("path")
+	branch := opts.GetBranch()
+	if branch == "" {
+		branch = fakeDefaultBranch
+	}
+	if current, ok := f.fileOn(repo, branch, path); ok && opts.GetSHA() != blobSHA(current) {
+		http.Error(w, "sha does not match", http.StatusConflict)
+		return
+	}
+	f.setFile(repo, branch, path, string(opts.Content))
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(&github.RepositoryContentResponse{})
+}
+
+func (f *fakeGitHub) getRef(w http.ResponseWriter, r *http.Request) {
+	branch := strings.TrimPrefix(r.PathValue("ref"), "heads/")
+	f.mu.Lock()
+	_, ok := f.files[repoKey(r)+"@"+branch]
+	f.mu.Unlock()
+	if !ok && branch != fakeDefaultBranch {
+		http.NotFound(w, r)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(&github.Reference{
+		Ref:    github.Ptr("refs/heads/" + branch),
+		Object: &github.GitObject{SHA: github.Ptr(blobSHA(branch))},
+	})
+}
+
+func (f *fakeGitHub) createRef(w http.ResponseWriter, r *http.Request) {
+	var ref github.Reference
+	if err := json.NewDecoder(r.Body).Decode(&ref); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	repo := repoKey(r)
+	branch := strings.TrimPrefix(ref.GetRef(), "refs/heads/")
+	f.mu.Lock()
+	files := make(map[string]string)
+	for path, content := range f.files[repo+"@"+fakeDefaultBranch] {
+		files[path] = content
+	}
+	f.files[repo+"@"+branch] = files
+	f.mu.Unlock()
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(ref)
+}
+
+func (f *fakeGitHub) listPulls(w http.ResponseWriter, r *http.Request) {
+	head := r.URL.Query().Get("head")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	prs := []*github.PullRequest{}
+	for _, pr := range f.pulls[repoKey(r)] {
+		if head == "" || strings.HasSuffix(head, ":"+pr.GetHead().GetRef()) {
+			prs = append(prs, pr)
+		}
+	}
+	_ = json.NewEncoder(w).Encode(prs)
+}
+
+func (f *fakeGitHub) createPull(w http.ResponseWriter, r *http.Request) {
+	var req github.NewPullRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	repo := repoKey(r)
+	f.mu.Lock()
+	f.nextID++
+	pr := &github.PullRequest{
+		ID:      github.Ptr(f.nextID),
+		Number:  github.Ptr(len(f.pulls[repo]) + 1),
+		Title:   req.Title,
+		Body:    req.Body,
+		State:   github.Ptr("open"),
+		Head:    &github.PullRequestBranch{Ref: req.Head},
+		Base:    &github.PullRequestBranch{Ref: req.Base},
+		HTMLURL: github.Ptr(fmt.Sprintf("https://github.com/%s/pull/%d", repo, len(f.pulls[repo])+1)),
+	}
+	f.pulls[repo] = append(f.pulls[repo], pr)
+	f.mu.Unlock()
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(pr)
+}
+
+// checkRunsFor returns the check runs published on a repository, oldest first.
+func (f *fakeGitHub) checkRunsFor(repo string) []github.CreateCheckRunOptions {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]github.CreateCheckRunOptions(nil), f.checkRuns[repo]...)
+}
+
+func (f *fakeGitHub) createCheckRun(w http.ResponseWriter, r *http.Request) {
+	var opts github.CreateCheckRunOptions
+	if err := json.NewDecoder(r.Body).Decode(&opts); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	f.mu.Lock()
+	f.nextID++
+	id := f.nextID
+	key := repoKey(r)
+	f.checkRuns[key] = append(f.checkRuns[key], opts)
+	f.mu.Unlock()
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(&github.CheckRun{ID: github.Ptr(id), Name: github.Ptr(opts.Name)})
+}
+
+// addTimelineEvent appends an event (e.g. "connected") to an issue's timeline.
+func (f *fakeGitHub) addTimelineEvent(repo string, number int, event string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := fmt.Sprintf("%s#%d", repo, number)
+	f.timelines[key] = append(f.timelines[key], &github.Timeline{Event: github.Ptr(event)})
+}
+
+func (f *fakeGitHub) listTimeline(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	events := f.timelines[issueKey(r)]
+	if events == nil {
+		events = []*github.Timeline{}
+	}
+	_ = json.NewEncoder(w).Encode(events)
+}
+
+// addPull registers an existing pull request.
+func (f *fakeGitHub) addPull(repo string, pr *github.PullRequest) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.pulls[repo] = append(f.pulls[repo], pr)
+}
+
+func (f *fakeGitHub) getPull(w http.ResponseWriter, r *http.Request) {
+	number, _ := strconv.Atoi(r.PathValue("number"))
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, pr := range f.pulls[repoKey(r)] {
+		if pr.GetNumber() == number {
+			_ = json.NewEncoder(w).Encode(pr)
+			return
+		}
+	}
+	http.NotFound(w, r)
+}
+
+// addCommit appends a commit to a pull request.
+func (f *fakeGitHub) addCommit(repo string, number int, commit *github.RepositoryCommit) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := fmt.Sprintf("%s#%d", repo, number)
+	f.commits[key] = append(f.commits[key], commit)
+}
+
+func (f *fakeGitHub) listPullCommits(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	commits := f.commits[issueKey(r)]
+	if commits == nil {
+		commits = []*github.RepositoryCommit{}
+	}
+	_ = json.NewEncoder(w).Encode(commits)
+}
+
+// searchIssues supports the repo:, org:, label:, is:open/is:closed, author:, commenter:
+// and reviewed-by: qualifiers over issues that have labels or involvement, returning them
+// in issue number order on a single page. Values may be quoted, e.g. label:"good first
+// issue"; other qualifiers are ignored.
+func (f *fakeGitHub) searchIssues(w http.ResponseWriter, r *http.Request) {
+	var (
+		repos    []string
+		orgs     []string
+		labels   []string
+		involved []string
+		state    string
+	)
+	query := r.URL.Query().Get("q")
+	for _, m := range searchQualifier.FindAllStringSubmatch(query, -1) {
+		value := strings.Trim(m[2], `"`)
+		switch m[1] {
+		case "repo":
+			repos = append(repos, value)
+		case "org":
+			orgs = append(orgs, value)
+		case "label":
+			labels = append(labels, value)
+		case "author", "commenter", "reviewed-by":
+			involved = append(involved, m[1]+":"+value)
+		case "is":
+			if value == "open" || value == "closed" {
+				state = value
+			}
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.searches = append(f.searches, query)
+	keys := slices.Collect(maps.Keys(f.labels))
+	for key := range f.involved {
+		if _, ok := f.labels[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	var issues []*github.Issue
+	for _, key := range keys {
+		keyRepo, num, _ := strings.Cut(key, "#")
+		org, _, _ := strings.Cut(keyRepo, "/")
+		if !slices.Contains(repos, keyRepo) && !slices.Contains(orgs, org) {
+			continue
+		}
+		if !containsAll(f.labels[key], labels) || !containsAll(f.involved[key], involved) {
+			continue
+		}
+		closed := f.states[key] == "closed"
+		if (state == "open" && closed) || (state == "closed" && !closed) {
+			continue
+		}
+		n, _ := strconv.Atoi(num)
+		issues = append(issues, &github.Issue{
+			Number:        github.Ptr(n),
+			Title:         github.Ptr("Issue " + num),
+			RepositoryURL: github.Ptr("https://api.github.com/repos/" + keyRepo),
+			HTMLURL:       github.Ptr("https://github.com/" + keyRepo + "/issues/" + num),
+			UpdatedAt:     &github.Timestamp{Time: f.updated[key]},
+		})
+	}
+	slices.SortFunc(issues, func(a, b *github.Issue) int {
+		if c := strings.Compare(a.GetRepositoryURL(), b.GetRepositoryURL()); c != 0 {
+			return c
+		}
+		return a.GetNumber() - b.GetNumber()
+	})
+	_ = json.NewEncoder(w).Encode(&github.IssuesSearchResult{Total: github.Ptr(len(issues)), Issues: issues})
+}
+
+// involve records people's involvement in an issue, e.g. "commenter:alice", for search,
+// and when the issue was last updated.
+func (f *fakeGitHub) involve(repo string, number int, updated time.Time, involvement ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := fmt.Sprintf("%s#%d", repo, number)
+	f.involved[key] = append(f.involved[key], involvement...)
+	f.updated[key] = updated
+}
+
+// setTeam sets the members of an org/team.
+func (f *fakeGitHub) setTeam(team string, members ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.teams[team] = members
+}
+
+func (f *fakeGitHub) getRateLimit(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.rate == nil {
+		http.NotFound(w, r)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(map[string]any{"resources": &github.RateLimits{Core: f.rate}})
+}
+
+func (f *fakeGitHub) listTeamMembers(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	members, ok := f.teams[r.PathValue("org")+"/"+r.PathValue("team")]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	users := []*github.User{}
+	for _, m := range members {
+		users = append(users, &github.User{Login: github.Ptr(m)})
+	}
+	_ = json.NewEncoder(w).Encode(users)
+}
+
+func (f *fakeGitHub) createIssue(w http.ResponseWriter, r *http.Request) {
+	var req github.IssueRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	repo := repoKey(r)
+	f.opened[repo] = append(f.opened[repo], &req)
+	number := 1000 + len(f.opened[repo])
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(&github.Issue{
+		Number:  github.Ptr(number),
+		NodeID:  github.Ptr(fmt.Sprintf("I_%s#%d", repo, number)),
+		Title:   req.Title,
+		Body:    req.Body,
+		HTMLURL: github.Ptr(fmt.Sprintf("https://github.com/%s/issues/%d", repo, number)),
+	})
+}
+
+// openedIssues returns the issues opened in a repository through the API.
+func (f *fakeGitHub) openedIssues(repo string) []*github.IssueRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Clone(f.opened[repo])
+}
+
+// graphQL answers the discussion category lookup and the createDiscussion, closeDiscussion
+// and pinIssue mutations. Every repository has the categories "Announcements" and "General".
+func (f *fakeGitHub) graphQL(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Query     string         `json:"query"`
+		Variables map[string]any `json:"variables"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var data any
+	switch {
+	case strings.Contains(req.Query, "createDiscussion"):
+		f.discussions = append(f.discussions, req.Variables)
+		repo := strings.TrimPrefix(fmt.Sprint(req.Variables["repositoryId"]), "R_")
+		data = map[string]any{"createDiscussion": map[string]any{"discussion": map[string]any{
+			"id":     fmt.Sprintf("D_%s#%d", repo, len(f.discussions)),
+			"number": len(f.discussions),
+			"url":    fmt.Sprintf("https://github.com/%s/discussions/%d", repo, len(f.discussions)),
+		}}}
+	case strings.Contains(req.Query, "closeDiscussion"):
+		f.closed = append(f.closed, fmt.Sprint(req.Variables["id"]))
+		data = map[string]any{"closeDiscussion": nil}
+	case strings.Contains(req.Query, "pinIssue"):
+		f.pinned = append(f.pinned, fmt.Sprint(req.Variables["id"]))
+		data = map[string]any{"pinIssue": nil}
+	case strings.Contains(req.Query, "discussionCategories"):
+		data = map[string]any{"repository": map[string]any{
+			"id": fmt.Sprintf("R_%s/%s", req.Variables["owner"], req.Variables["name"]),
+			"discussionCategories": map[string]any{"nodes": []map[string]string{
+				{"id": "DIC_announcements", "name": "Announcements"},
+				{"id": "DIC_general", "name": "General"},
+			}},
+		}}
+	default:
+		_ = json.NewEncoder(w).Encode(map[string]any{"errors": []map[string]string{{"message": "unsupported query"}}})
+		return
+	}
+	_ = json.NewEncoder(w).Encode(map[string]any{"data": data})
+}
+
+// createdDiscussions returns the inputs of the discussions created so far.
+func (f *fakeGitHub) createdDiscussions() []map[string]any {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Clone(f.discussions)
+}
+
+// searchQualifier matches a search qualifier with an optionally quoted value.
+var searchQualifier = regexp.MustCompile(`([\w-]+):("[^"]*"|\S+)`)
+
+// searchQueries returns the search queries received, in order.
+func (f *fakeGitHub) searchQueries() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.searches...)
+}
+
+func containsAll(have, want []string) bool {
+	for _, w := range want {
+		if !slices.Contains(have, w) {
+			return false
+		}
+	}
+	return true
+}
+
+// addArtifact uploads an artifact with a zip archive to a workflow run.
+func (f *fakeGitHub) addArtifact(runID int64, name string, archive []byte) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.nextID++
+	f.artifacts[runID] = append(f.artifacts[runID], &github.Artifact{ID: github.Ptr(f.nextID), Name: github.Ptr(name)})
+	f.archives[f.nextID] = archive
+}
+
+func (f *fakeGitHub) listRunArtifacts(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	artifacts := f.artifacts[id]
+	_ = json.NewEncoder(w).Encode(&github.ArtifactList{
+		TotalCount: github.Ptr(int64(len(artifacts))),
+		Artifacts:  artifacts,
+	})
+}
+
+// downloadArtifact redirects to the archive like GitHub redirects to blob storage.
+func (f *fakeGitHub) downloadArtifact(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Location", "http://"+r.Host+"/artifact-downloads/"+r.PathValue("id"))
+	w.WriteHeader(http.StatusFound)
+}
+
+func (f *fakeGitHub) artifactArchive(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	archive, ok := f.archives[id]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	_, _ = w.Write(archive)
+}
+
+// addPullFile lists a changed file on a pull request and puts it in the tree of sha with size.
+func (f *fakeGitHub) addPullFile(repo string, number int, sha string, file *github.CommitFile, size int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := fmt.Sprintf("%s#%d", repo, number)
+	f.prFiles[key] = append(f.prFiles[key], file)
+	f.trees[repo+"@"+sha] = append(f.trees[repo+"@"+sha], &github.TreeEntry{
+		Path: file.Filename,
+		Type: github.Ptr("blob"),
+		Size: github.Ptr(size),
+	})
+}
+
+func (f *fakeGitHub) listPullFiles(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	files := f.prFiles[issueKey(r)]
+	if files == nil {
+		files = []*github.CommitFile{}
+	}
+	_ = json.NewEncoder(w).Encode(files)
+}
+
+func (f *fakeGitHub) getTree(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	sha := r.PathValue("sha")
+	// TreeEntry marshals only the fields used to create trees, so encode sizes by hand.
+	entries := []map[string]any{}
+	for _, e := range f.trees[repoKey(r)+"@"+sha] {
+		entries = append(entries, map[string]any{"path": e.GetPath(), "type": e.GetType(), "size": e.GetSize()})
+	}
+	_ = json.NewEncoder(w).Encode(map[string]any{"sha": sha, "tree": entries})
+}
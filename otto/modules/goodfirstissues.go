@@ -0,0 +1,275 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v71/github"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+// goodFirstIssueRepoBatch is how many repo: qualifiers go into one search, keeping
+// queries under GitHub's length limit.
+const goodFirstIssueRepoBatch = 10
+
+// GoodFirstIssuesConfig configures `/good-first-issues`.
+type GoodFirstIssuesConfig struct {
+	Repos                []string      `yaml:"repos" doc:"default: all onboarded repositories"`
+	Label                string        `yaml:"label" doc:"label marking newcomer-friendly issues"`
+	ComponentLabelPrefix string        `yaml:"component_label_prefix" doc:"component:X filters on this prefix + X"`
+	Limit                int           `yaml:"limit" doc:"issues listed by default"`
+	MaxLimit             int           `yaml:"max_limit" doc:"most issues a reply may list"`
+	CacheTTL             time.Duration `yaml:"cache_ttl" doc:"how long search results are reused"`
+}
+
+// cachedIssueSearch is the result of a search as fetched at a point in time.
+type cachedIssueSearch struct {
+	issues  []*github.Issue
+	fetched time.Time
+}
+
+// GoodFirstIssuesModule answers `/good-first-issues [language:<lang>] [component:<name>] [count]`
+// with open, unassigned newcomer-friendly issues across the SIG's repositories.
+type GoodFirstIssuesModule struct {
+	app    *internal.App
+	config GoodFirstIssuesConfig
+	now    func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]cachedIssueSearch // key: search query
+}
+
+func (m *GoodFirstIssuesModule) Name() string { return "goodfirstissues" }
+
+// ConfigSchema implements the ModuleConfigSchema interface.
+func (m *GoodFirstIssuesModule) ConfigSchema() any {
+	c := defaultGoodFirstIssuesConfig()
+	return &c
+}
+
+// defaultGoodFirstIssuesConfig returns the goodfirstissues module's defaults.
+func defaultGoodFirstIssuesConfig() GoodFirstIssuesConfig {
+	return GoodFirstIssuesConfig{
+		Label:    "good first issue",
+		Limit:    10,
+		MaxLimit: 30,
+		CacheTTL: 15 * time.Minute,
+	}
+}
+
+// EventSubscriptions implements the ModuleEventSubscriber interface. The module consumes
+// no events: the app hands it `/good-first-issues` commands through HandleCommand.
+func (m *GoodFirstIssuesModule) EventSubscriptions() []internal.EventSubscription {
+	return nil
+}
+
+// GitHubPermissions implements the ModulePermissionRequirer interface.
+func (m *GoodFirstIssuesModule) GitHubPermissions() map[string]string {
+	return map[string]string{"issues": "write"}
+}
+
+// SlashCommands implements the ModuleCommander interface.
+func (m *GoodFirstIssuesModule) SlashCommands() []string {
+	return []string{"good-first-issues"}
+}
+
+// Initialize implements the ModuleInitializer interface.
+func (m *GoodFirstIssuesModule) Initialize(ctx context.Context, app *internal.App) error {
+	m.app = app
+	m.cache = make(map[string]cachedIssueSearch)
+	if m.now == nil {
+		m.now = time.Now
+	}
+	m.config = defaultGoodFirstIssuesConfig()
+	if err := loadModuleConfig(app, m.Name(), &m.config); err != nil {
+		return err
+	}
+	return checkRepos(app, m.Name(), m.config.Repos)
+}
+
+func (m *GoodFirstIssuesModule) HandleEvent(eventType string, event any, raw json.RawMessage) error {
+	return nil
+}
+
+// HandleCommand implements the ModuleCommandHandler interface.
+func (m *GoodFirstIssuesModule) HandleCommand(cmd *internal.CommandContext) error {
+	return m.handleCommand(cmd.Context, cmd.Event, cmd.Args)
+}
+
+// goodFirstIssuesFilter narrows `/good-first-issues`.
+type goodFirstIssuesFilter struct {
+	language  string
+	component string
+	limit     int
+}
+
+func (m *GoodFirstIssuesModule) handleCommand(
+	ctx context.Context,
+	event *github.IssueCommentEvent,
+	args []string,
+) error {
+	repo := event.GetRepo().GetFullName()
+	num := event.GetIssue().GetNumber()
+	filter, err := m.parseArgs(args)
+	if err != nil {
+		return m.wrap(m.comment(ctx, repo, num, fmt.Sprintf("⚠️ %v\n\nUsage: `/good-first-issues "+
+			"[language:go] [component:exporter/prometheus] [count]`.", err)), "good_first_issues", repo, num)
+	}
+	if m.app == nil || m.app.GitHubClient == nil {
+		return m.wrap(m.comment(ctx, repo, num, "⚠️ `/good-first-issues` is not available: no GitHub client."),
+			"good_first_issues", repo, num)
+	}
+
+	repos, err := m.repos(ctx, repo)
+	if err != nil {
+		return m.wrap(err, "list_repos", repo, num)
+	}
+	issues, err := m.search(ctx, repos, filter)
+	if err != nil {
+		return m.wrap(err, "search_issues", repo, num)
+	}
+	return m.wrap(m.comment(ctx, repo, num, m.format(issues, filter)), "good_first_issues", repo, num)
+}
+
+func (m *GoodFirstIssuesModule) parseArgs(args []string) (goodFirstIssuesFilter, error) {
+	filter := goodFirstIssuesFilter{limit: m.config.Limit}
+	for _, arg := range args {
+		if lang, ok := strings.CutPrefix(arg, "language:"); ok && lang != "" {
+			filter.language = lang
+		} else if component, ok := strings.CutPrefix(arg, "component:"); ok && component != "" {
+			filter.component = component
+		} else if n, err := strconv.Atoi(arg); err == nil && n > 0 {
+			filter.limit = min(n, m.config.MaxLimit)
+		} else {
+			return filter, fmt.Errorf("unknown argument `%s`", arg)
+		}
+	}
+	return filter, nil
+}
+
+// repos returns the configured repositories, else the onboarded ones that are not
+// archived, else the repository the command was used in.
+func (m *GoodFirstIssuesModule) repos(ctx context.Context, current string) ([]string, error) {
+	if len(m.config.Repos) > 0 {
+		return m.app.RepoGroups.Resolve(ctx, m.config.Repos)
+	}
+	var repos []string
+	if m.app.Repos != nil {
+		managed, err := m.app.Repos.List(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range managed {
+			if r.Status == internal.RepoActive {
+				repos = append(repos, r.FullName)
+			}
+		}
+	}
+	if len(repos) == 0 {
+		repos = []string{current}
+	}
+	return repos, nil
+}
+
+// search returns matching issues across repos, newest first, reusing cached results.
+func (m *GoodFirstIssuesModule) search(
+	ctx context.Context,
+	repos []string,
+	filter goodFirstIssuesFilter,
+) ([]*github.Issue, error) {
+	var issues []*github.Issue
+	for batch := range slices.Chunk(repos, goodFirstIssueRepoBatch) {
+		query := m.query(batch, filter)
+		m.mu.Lock()
+		cached, ok := m.cache[query]
+		m.mu.Unlock()
+		if ok && m.now().Sub(cached.fetched) < m.config.CacheTTL {
+			issues = append(issues, cached.issues...)
+			continue
+		}
+
+		result, _, err := m.app.GitHubClient.Search.Issues(ctx, query, &github.SearchOptions{
+			Sort:        "created",
+			Order:       "desc",
+			ListOptions: github.ListOptions{PerPage: m.config.MaxLimit},
+		})
+		if err != nil {
+			return nil, err
+		}
+		m.mu.Lock()
+		m.cache[query] = cachedIssueSearch{issues: result.Issues, fetched: m.now()}
+		m.mu.Unlock()
+		issues = append(issues, result.Issues...)
+	}
+	slices.SortStableFunc(issues, func(a, b *github.Issue) int {
+		return b.GetCreatedAt().Compare(a.GetCreatedAt().Time)
+	})
+	return issues[:min(len(issues), filter.limit)], nil
+}
+
+// query builds the issue search for a batch of repositories.
+func (m *GoodFirstIssuesModule) query(repos []string, filter goodFirstIssuesFilter) string {
+	terms := []string{"is:issue", "is:open", "no:assignee", fmt.Sprintf("label:%q", m.config.Label)}
+	if filter.language != "" {
+		terms = append(terms, "language:"+filter.language)
+	}
+	if filter.component != "" {
+		terms = append(terms, fmt.Sprintf("label:%q", m.config.ComponentLabelPrefix+filter.component))
+	}
+	for _, r := range repos {
+		terms = append(terms, "repo:"+r)
+	}
+	return strings.Join(terms, " ")
+}
+
+func (m *GoodFirstIssuesModule) format(issues []*github.Issue, filter goodFirstIssuesFilter) string {
+	scope := fmt.Sprintf("open `%s` issues", m.config.Label)
+	if filter.language != "" {
+		scope += fmt.Sprintf(" in %s repositories", filter.language)
+	}
+	if filter.component != "" {
+		scope += fmt.Sprintf(" for `%s`", filter.component)
+	}
+	if len(issues) == 0 {
+		return fmt.Sprintf("There are no unassigned %s right now.", scope)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Here are unassigned %s to get started with:\n\n", scope)
+	for _, issue := range issues {
+		repo := strings.TrimPrefix(issue.GetRepositoryURL(), "https://api.github.com/repos/")
+		fmt.Fprintf(&b, "- [%s#%d](%s) %s\n", repo, issue.GetNumber(), issue.GetHTMLURL(), issue.GetTitle())
+	}
+	b.WriteString("\nComment on an issue to ask for it to be assigned to you before starting work.")
+	return b.String()
+}
+
+func (m *GoodFirstIssuesModule) comment(ctx context.Context, repo string, num int, body string) error {
+	if m.app == nil || m.app.GitHubClient == nil {
+		slog.Info("GitHub comment would be posted (no GitHub client available)",
+			"repo", repo, "issue_num", num, "message", body)
+		return nil
+	}
+	return m.app.PostComment(ctx, m.Name(), repo, num, body)
+}
+
+func (m *GoodFirstIssuesModule) wrap(err error, op, repo string, num int) error {
+	if err == nil {
+		return nil
+	}
+	return internal.LogAndWrapError(err, internal.ErrorTypeModule, op, map[string]any{
+		"module": m.Name(),
+		"repo":   repo,
+		"issue":  num,
+	})
+}
@@ -0,0 +1,176 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+func newGoodFirstIssuesTestModule(t *testing.T, fake *fakeGitHub, repos ...string) *GoodFirstIssuesModule {
+	t.Helper()
+	return &GoodFirstIssuesModule{
+		app: &internal.App{GitHubClient: fake.client(t)},
+		config: GoodFirstIssuesConfig{
+			Repos:                repos,
+			Label:                "good first issue",
+			ComponentLabelPrefix: "comp:",
+			Limit:                2,
+			MaxLimit:             3,
+			CacheTTL:             time.Minute,
+		},
+		now:   time.Now,
+		cache: make(map[string]cachedIssueSearch),
+	}
+}
+
+func TestGoodFirstIssues(t *testing.T) {
+	fake := newFakeGitHub()
+	fake.setLabels("org/a", 1, "good first issue", "comp:exporter")
+	fake.setLabels("org/a", 2, "good first issue")
+	fake.setLabels("org/a", 3, "good first issue")
+	fake.states["org/a#3"] = "closed"
+	fake.setLabels("org/a", 4, "bug")
+	fake.setLabels("org/b", 5, "good first issue", "comp:exporter")
+	mod := newGoodFirstIssuesTestModule(t, fake, "org/a", "org/b")
+
+	tests := []struct {
+		name    string
+		body    string
+		want    []string
+		notWant []string
+	}{
+		{
+			name:    "default limit",
+			body:    "/good-first-issues",
+			want:    []string{"[org/a#1](https://github.com/org/a/issues/1) Issue 1", "org/a#2"},
+			notWant: []string{"#3", "#4", "org/b#5"},
+		},
+		{
+			name: "count across repositories",
+			body: "/good-first-issues 5",
+			want: []string{"org/a#1", "org/a#2", "[org/b#5](https://github.com/org/b/issues/5) Issue 5"},
+		},
+		{
+			name:    "component",
+			body:    "/good-first-issues component:exporter",
+			want:    []string{"for `exporter`", "org/a#1", "org/b#5"},
+			notWant: []string{"org/a#2"},
+		},
+		{
+			name: "no matches",
+			body: "/good-first-issues component:receiver",
+			want: []string{"There are no unassigned open `good first issue` issues for `receiver` right now."},
+		},
+		{
+			name: "unknown argument",
+			body: "/good-first-issues sort:oldest",
+			want: []string{"unknown argument `sort:oldest`", "Usage:"},
+		},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := internal.TestCommands(t, mod, commentEvent("org/a", 100+i, "alice", tt.body)); err != nil {
+				t.Fatalf("HandleCommand failed: %v", err)
+			}
+			comments := fake.commentsOn("org/a", 100+i)
+			if len(comments) != 1 {
+				t.Fatalf("got %d comments, want 1", len(comments))
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(comments[0], want) {
+					t.Errorf("comment missing %q:\n%s", want, comments[0])
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(comments[0], notWant) {
+					t.Errorf("comment should not contain %q:\n%s", notWant, comments[0])
+				}
+			}
+		})
+	}
+
+	want := `is:issue is:open no:assignee label:"good first issue" label:"comp:exporter" repo:org/a repo:org/b`
+	if !slices.Contains(fake.searchQueries(), want) {
+		t.Errorf("queries %q do not include %q", fake.searchQueries(), want)
+	}
+}
+
+func TestGoodFirstIssuesQuery(t *testing.T) {
+	mod := newGoodFirstIssuesTestModule(t, newFakeGitHub())
+	got := mod.query([]string{"org/a"}, goodFirstIssuesFilter{language: "go", component: "sdk"})
+	want := `is:issue is:open no:assignee label:"good first issue" language:go label:"comp:sdk" repo:org/a`
+	if got != want {
+		t.Errorf("query = %q, want %q", got, want)
+	}
+}
+
+func TestGoodFirstIssuesCache(t *testing.T) {
+	fake := newFakeGitHub()
+	fake.setLabels("org/a", 1, "good first issue")
+	repos := []string{"org/a"}
+	for i := range 11 {
+		repos = append(repos, fmt.Sprintf("org/r%d", i))
+	}
+	mod := newGoodFirstIssuesTestModule(t, fake, repos...)
+	now := time.Now()
+	mod.now = func() time.Time { return now }
+
+	run := func() {
+		t.Helper()
+		event := commentEvent("org/a", 100, "alice", "/good-first-issues")
+		if err := internal.TestCommands(t, mod, event); err != nil {
+			t.Fatalf("HandleCommand failed: %v", err)
+		}
+	}
+
+	run()
+	if got := len(fake.searchQueries()); got != 2 {
+		t.Fatalf("12 repositories took %d searches, want 2 batches", got)
+	}
+	run()
+	if got := len(fake.searchQueries()); got != 2 {
+		t.Errorf("cached results were searched again: %d searches", got)
+	}
+	if comments := fake.commentsOn("org/a", 100); len(comments) != 2 || !strings.Contains(comments[1], "org/a#1") {
+		t.Errorf("cached reply = %q", comments)
+	}
+
+	now = now.Add(2 * time.Minute)
+	run()
+	if got := len(fake.searchQueries()); got != 4 {
+		t.Errorf("expired results were not refreshed: %d searches", got)
+	}
+}
+
+func TestGoodFirstIssuesOnboardedRepos(t *testing.T) {
+	fake := newFakeGitHub()
+	fake.setLabels("org/onboarded", 1, "good first issue")
+	mod := newGoodFirstIssuesTestModule(t, fake)
+	var err error
+	if mod.app.Repos, err = internal.NewRepoRegistry(internal.TestDB(t)); err != nil {
+		t.Fatalf("NewRepoRegistry failed: %v", err)
+	}
+
+	// With nothing onboarded, the current repository is searched.
+	if got, err := mod.repos(t.Context(), "org/here"); err != nil || !slices.Equal(got, []string{"org/here"}) {
+		t.Errorf("repos = %v, %v; want the current repository", got, err)
+	}
+
+	if err := mod.app.Repos.Register(t.Context(), "org/onboarded", "admin", nil); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	event := commentEvent("org/here", 7, "alice", "/good-first-issues")
+	if err := internal.TestCommands(t, mod, event); err != nil {
+		t.Fatalf("HandleCommand failed: %v", err)
+	}
+	comments := fake.commentsOn("org/here", 7)
+	if len(comments) != 1 || !strings.Contains(comments[0], "org/onboarded#1") {
+		t.Errorf("comments = %q", comments)
+	}
+}
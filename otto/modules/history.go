@@ -0,0 +1,135 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-github/v71/github"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+// HistoryConfig configures the history module.
+type HistoryConfig struct {
+	Limit int `yaml:"limit" doc:"commands listed by '/otto history' without an explicit count"`
+}
+
+// HistoryModule answers `/otto history` with the slash commands already run on an
+// issue, and serves the command history across repositories on the admin API.
+type HistoryModule struct {
+	app    *internal.App
+	config HistoryConfig
+}
+
+func (m *HistoryModule) Name() string { return "history" }
+
+// ConfigSchema implements the ModuleConfigSchema interface.
+func (m *HistoryModule) ConfigSchema() any {
+	c := defaultHistoryConfig()
+	return &c
+}
+
+// defaultHistoryConfig returns the history module's defaults.
+func defaultHistoryConfig() HistoryConfig {
+	return HistoryConfig{Limit: 20}
+}
+
+// EventSubscriptions implements the ModuleEventSubscriber interface. The module consumes
+// no events: the app hands it `/otto history` commands through HandleCommand.
+func (m *HistoryModule) EventSubscriptions() []internal.EventSubscription {
+	return nil
+}
+
+// GitHubPermissions implements the ModulePermissionRequirer interface.
+func (m *HistoryModule) GitHubPermissions() map[string]string {
+	return map[string]string{"issues": "write"}
+}
+
+// SlashCommands implements the ModuleCommander interface.
+func (m *HistoryModule) SlashCommands() []string {
+	return []string{"otto history"}
+}
+
+// Initialize implements the ModuleInitializer interface.
+func (m *HistoryModule) Initialize(ctx context.Context, app *internal.App) error {
+	m.app = app
+	m.config = defaultHistoryConfig()
+	if err := loadModuleConfig(app, m.Name(), &m.config); err != nil {
+		return err
+	}
+	if app.Commands == nil {
+		return errors.New("history: command history is not available")
+	}
+	app.HandleAdmin("GET /admin/commands",
+		internal.ListHandler(internal.CommandListSchema, app.Commands.Select))
+	return nil
+}
+
+func (m *HistoryModule) HandleEvent(eventType string, event any, raw json.RawMessage) error {
+	return nil
+}
+
+// HandleCommand implements the ModuleCommandHandler interface.
+func (m *HistoryModule) HandleCommand(cmd *internal.CommandContext) error {
+	return m.history(cmd.Context, cmd.Event, cmd.Args[1:])
+}
+
+// history replies with the most recent commands run on the issue.
+func (m *HistoryModule) history(ctx context.Context, event *github.IssueCommentEvent, args []string) error {
+	repo := event.GetRepo().GetFullName()
+	issue := event.GetIssue().GetNumber()
+	limit := m.config.Limit
+	if len(args) > 0 {
+		if n, err := strconv.Atoi(args[0]); err == nil && n > 0 {
+			limit = n
+		}
+	}
+
+	records, err := m.app.Commands.Query(ctx, internal.CommandQuery{Repo: repo, Issue: issue, Limit: limit})
+	if err != nil {
+		return internal.LogAndWrapError(err, internal.ErrorTypeModule, "command_history", map[string]any{
+			"repo":  repo,
+			"issue": issue,
+		})
+	}
+	m.comment(ctx, repo, issue, historyMarkdown(records))
+	return nil
+}
+
+// historyMarkdown renders commands as a table, oldest first.
+func historyMarkdown(records []internal.CommandRecord) string {
+	if len(records) == 0 {
+		return "No Otto commands have been run on this issue yet."
+	}
+	var b strings.Builder
+	b.WriteString("### Otto command history\n\n| When (UTC) | Who | Command | Outcome |\n|---|---|---|---|\n")
+	for i := len(records) - 1; i >= 0; i-- {
+		r := records[i]
+		outcome := "✅"
+		if r.Outcome == internal.CommandError {
+			outcome = "❌ " + strings.ReplaceAll(r.Error, "|", `\|`)
+		}
+		fmt.Fprintf(&b, "| %s | @%s | `%s` | %s |\n",
+			r.ExecutedAt.UTC().Format("2006-01-02 15:04"), r.User, r.String(), outcome)
+	}
+	return b.String()
+}
+
+// comment posts a plain comment, logging instead when no GitHub client is configured.
+func (m *HistoryModule) comment(ctx context.Context, repo string, issue int, body string) {
+	if m.app == nil || m.app.GitHubClient == nil {
+		slog.Info("GitHub comment would be posted (no GitHub client available)",
+			"repo", repo, "issue", issue, "message", body)
+		return
+	}
+	if err := m.app.PostComment(ctx, m.Name(), repo, issue, body); err != nil {
+		slog.Error("Failed to post command history", "repo", repo, "issue", issue, "error", err)
+	}
+}
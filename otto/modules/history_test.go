@@ -0,0 +1,95 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+func TestHistoryCommand(t *testing.T) {
+	history, err := internal.NewCommandHistory(openTestDB(t))
+	if err != nil {
+		t.Fatalf("NewCommandHistory failed: %v", err)
+	}
+	fake := newFakeGitHub()
+	app := &internal.App{GitHubClient: fake.client(t), Commands: history}
+	mod := &HistoryModule{}
+	if err := mod.Initialize(t.Context(), app); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	if err := internal.TestCommands(t, mod, commentEvent("org/repo", 3, "alice", "/otto history")); err != nil {
+		t.Fatalf("HandleCommand failed: %v", err)
+	}
+	comments := fake.commentsOn("org/repo", 3)
+	if len(comments) != 1 || !strings.Contains(comments[0], "No Otto commands") {
+		t.Fatalf("unexpected empty history: %v", comments)
+	}
+
+	at := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	_ = history.Record(t.Context(), internal.CommandRecord{
+		Repo: "org/repo", Issue: 3, User: "bob", Command: "changelog", Args: []string{"fixed:", "x"},
+		Outcome: internal.CommandOK, ExecutedAt: at,
+	})
+	_ = history.Record(t.Context(), internal.CommandRecord{
+		Repo: "org/repo", Issue: 3, User: "bob", Command: "close-all-stale",
+		Outcome: internal.CommandError, Error: "sla: a|b", ExecutedAt: at.Add(time.Minute),
+	})
+	_ = history.Record(t.Context(), internal.CommandRecord{
+		Repo: "org/other", Issue: 3, User: "carol", Command: "oncall", Args: []string{"who"},
+		Outcome: internal.CommandOK, ExecutedAt: at,
+	})
+
+	if err := internal.TestCommands(t, mod, commentEvent("org/repo", 3, "alice", "/otto history")); err != nil {
+		t.Fatalf("HandleCommand failed: %v", err)
+	}
+	comments = fake.commentsOn("org/repo", 3)
+	got := comments[len(comments)-1]
+	changelog := strings.Index(got, "| 2025-03-01 12:00 | @bob | `/changelog fixed: x` | ✅ |")
+	failed := strings.Index(got, "| 2025-03-01 12:01 | @bob | `/close-all-stale` | ❌ sla: a\\|b |")
+	if changelog < 0 || failed < changelog || strings.Contains(got, "carol") {
+		t.Errorf("unexpected history:\n%s", got)
+	}
+
+	tests := []struct {
+		query    string
+		wantCode int
+		wantLen  int
+	}{
+		{"", http.StatusOK, 3},
+		{"?user=bob&command=changelog", http.StatusOK, 1},
+		{"?repo=org/other", http.StatusOK, 1},
+		{"?since=2025-03-01T12:00:30Z", http.StatusOK, 1},
+		{"?limit=2", http.StatusOK, 2},
+		{"?repo=org/none", http.StatusOK, 0},
+		{"?since=yesterday", http.StatusBadRequest, 0},
+		{"?repo=org/repo&issue=3&outcome=ok,error", http.StatusOK, 2},
+		{"?issue_num=3", http.StatusBadRequest, 0},
+	}
+	list := internal.ListHandler(internal.CommandListSchema, history.Select)
+	for _, tt := range tests {
+		rr := httptest.NewRecorder()
+		list(rr, httptest.NewRequest(http.MethodGet, "/admin/commands"+tt.query, nil))
+		if rr.Code != tt.wantCode {
+			t.Errorf("%q: status %d, want %d", tt.query, rr.Code, tt.wantCode)
+			continue
+		}
+		if rr.Code != http.StatusOK {
+			continue
+		}
+		var out []internal.CommandRecord
+		if err := json.Unmarshal(rr.Body.Bytes(), &out); err != nil {
+			t.Fatalf("%q: invalid JSON: %v", tt.query, err)
+		}
+		if len(out) != tt.wantLen {
+			t.Errorf("%q: got %d records, want %d", tt.query, len(out), tt.wantLen)
+		}
+	}
+}
@@ -0,0 +1,277 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v71/github"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+// HoldConfig configures `/hold`.
+type HoldConfig struct {
+	Label           string        `yaml:"label" doc:"applied while a pull request is on hold"`
+	RemindAfterDays int           `yaml:"remind_after_days" doc:"days between reminders to the holder; 0 disables"`
+	CheckInterval   time.Duration `yaml:"check_interval" doc:"how often holds are checked for reminders"`
+}
+
+// HoldModule keeps pull requests from being merged. `/hold [reason]` puts a pull request on
+// hold and applies the hold label, and `/hold cancel` (by the holder or a maintainer)
+// releases it. As a MergeGate it blocks pull requests on hold. Holds that last longer than
+// RemindAfterDays ping the holder.
+type HoldModule struct {
+	app      *internal.App
+	database *internal.Database
+	config   HoldConfig
+	now      func() time.Time
+}
+
+func (m *HoldModule) Name() string { return "holds" }
+
+// ConfigSchema implements the ModuleConfigSchema interface.
+func (m *HoldModule) ConfigSchema() any {
+	c := defaultHoldConfig()
+	return &c
+}
+
+// defaultHoldConfig returns the holds module's defaults.
+func defaultHoldConfig() HoldConfig {
+	return HoldConfig{
+		Label:           "do-not-merge/hold",
+		RemindAfterDays: 7,
+		CheckInterval:   time.Hour,
+	}
+}
+
+// EventSubscriptions implements the ModuleEventSubscriber interface.
+func (m *HoldModule) EventSubscriptions() []internal.EventSubscription {
+	return []internal.EventSubscription{
+		internal.Subscribe("pull_request", "labeled", "unlabeled", "closed"),
+	}
+}
+
+// GitHubPermissions implements the ModulePermissionRequirer interface.
+func (m *HoldModule) GitHubPermissions() map[string]string {
+	return map[string]string{"issues": "write"}
+}
+
+// SlashCommands implements the ModuleCommander interface.
+func (m *HoldModule) SlashCommands() []string {
+	return []string{"hold"}
+}
+
+// Initialize implements the ModuleInitializer interface.
+func (m *HoldModule) Initialize(ctx context.Context, app *internal.App) error {
+	m.app = app
+	m.database = app.Database
+	if m.now == nil {
+		m.now = time.Now
+	}
+	m.config = defaultHoldConfig()
+	if err := loadModuleConfig(app, m.Name(), &m.config); err != nil {
+		return err
+	}
+	if m.config.Label == "" {
+		return errors.New("holds: label must not be empty")
+	}
+	if err := AutoMigrateHolds(m.database.DB()); err != nil {
+		return err
+	}
+	if app.Scheduler != nil && m.config.RemindAfterDays > 0 {
+		app.Scheduler.Register(internal.Job{
+			Name:       "hold_reminders",
+			Module:     m.Name(),
+			Deferrable: true,
+			Interval:   m.config.CheckInterval,
+			Run:        m.RemindHolders,
+		})
+	}
+	return nil
+}
+
+func (m *HoldModule) HandleEvent(eventType string, event any, raw json.RawMessage) error {
+	ctx := context.Background()
+	switch eventType {
+	case "pull_request":
+		prEvent, ok := event.(*github.PullRequestEvent)
+		if !ok {
+			return nil
+		}
+		return m.handlePullRequest(ctx, prEvent)
+	}
+	return nil
+}
+
+// HandleCommand implements the ModuleCommandHandler interface.
+func (m *HoldModule) HandleCommand(cmd *internal.CommandContext) error {
+	return m.handleHold(cmd.Context, cmd.Event, cmd.Args)
+}
+
+// handleHold places or releases a hold.
+func (m *HoldModule) handleHold(ctx context.Context, event *github.IssueCommentEvent, args []string) error {
+	repo := event.GetRepo().GetFullName()
+	num := event.GetIssue().GetNumber()
+	login := event.GetComment().GetUser().GetLogin()
+	if !event.GetIssue().IsPullRequest() {
+		return m.wrap(m.comment(ctx, repo, num, "⚠️ `/hold` only works on pull requests."), "hold", repo, num)
+	}
+	db := m.database.DB()
+
+	if len(args) > 0 && strings.EqualFold(args[0], "cancel") {
+		hold, err := GetHold(db, repo, num)
+		if err != nil {
+			return m.wrapDB(err, "get_hold", repo, num)
+		}
+		if hold != nil && !strings.EqualFold(hold.Login, login) &&
+			!maintainerAssociations[event.GetComment().GetAuthorAssociation()] {
+			msg := fmt.Sprintf("⚠️ @%s only @%s or a maintainer can cancel this hold.", login, hold.Login)
+			return m.wrap(m.comment(ctx, repo, num, msg), "release_hold", repo, num)
+		}
+		if _, err := ReleaseHold(db, repo, num); err != nil {
+			return m.wrapDB(err, "release_hold", repo, num)
+		}
+		return m.wrap(m.setLabel(ctx, repo, num, false), "release_hold", repo, num)
+	}
+
+	placed, err := PlaceHold(db, Hold{
+		Repo:   repo,
+		Number: num,
+		Login:  login,
+		Reason: strings.Join(args, " "),
+		HeldAt: m.now(),
+	})
+	if err != nil {
+		return m.wrapDB(err, "place_hold", repo, num)
+	}
+	if !placed {
+		hold, err := GetHold(db, repo, num)
+		if err != nil || hold == nil {
+			return m.wrapDB(err, "get_hold", repo, num)
+		}
+		if !strings.EqualFold(hold.Login, login) {
+			msg := fmt.Sprintf("This pull request is already on hold by @%s since %s.",
+				hold.Login, hold.HeldAt.Format(time.DateOnly))
+			return m.wrap(m.comment(ctx, repo, num, msg), "place_hold", repo, num)
+		}
+	}
+	return m.wrap(m.setLabel(ctx, repo, num, true), "place_hold", repo, num)
+}
+
+// handlePullRequest keeps holds in step with the hold label and drops holds on closed pull
+// requests, so a label added or removed by hand counts as `/hold` or `/hold cancel`.
+func (m *HoldModule) handlePullRequest(ctx context.Context, event *github.PullRequestEvent) error {
+	repo := event.GetRepo().GetFullName()
+	num := event.GetPullRequest().GetNumber()
+	db := m.database.DB()
+	var err error
+	switch event.GetAction() {
+	case "labeled":
+		if event.GetLabel().GetName() == m.config.Label {
+			_, err = PlaceHold(db, Hold{Repo: repo, Number: num, Login: event.GetSender().GetLogin(), HeldAt: m.now()})
+		}
+	case "unlabeled":
+		if event.GetLabel().GetName() == m.config.Label {
+			_, err = ReleaseHold(db, repo, num)
+		}
+	case "closed":
+		_, err = ReleaseHold(db, repo, num)
+	}
+	return m.wrapDB(err, "sync_hold", repo, num)
+}
+
+// MergeBlockers implements MergeGate: a pull request on hold must not be merged.
+func (m *HoldModule) MergeBlockers(ctx context.Context, repo string, number int) ([]string, error) {
+	hold, err := GetHold(m.database.DB(), repo, number)
+	if err != nil || hold == nil {
+		return nil, err
+	}
+	reason := fmt.Sprintf("on hold by @%s since %s", hold.Login, hold.HeldAt.Format(time.DateOnly))
+	if hold.Reason != "" {
+		reason += ": " + hold.Reason
+	}
+	return []string{reason}, nil
+}
+
+// RemindHolders pings the holders of holds placed, or last reminded of, RemindAfterDays ago.
+func (m *HoldModule) RemindHolders(ctx context.Context) error {
+	holds, err := ListHolds(m.database.DB())
+	if err != nil {
+		return fmt.Errorf("failed to list holds: %w", err)
+	}
+	now := m.now()
+	after := time.Duration(m.config.RemindAfterDays) * 24 * time.Hour
+	for _, h := range holds {
+		if !m.app.RepoActive(h.Repo) {
+			continue
+		}
+		last := h.HeldAt
+		if h.RemindedAt != nil {
+			last = *h.RemindedAt
+		}
+		if now.Sub(last) < after {
+			continue
+		}
+		msg := fmt.Sprintf("@%s this pull request has been on hold for %d days. Comment `/hold cancel` "+
+			"once it can be merged.", h.Login, int(now.Sub(h.HeldAt)/(24*time.Hour)))
+		if err := m.comment(ctx, h.Repo, h.Number, msg); err != nil {
+			slog.Error("Failed to post hold reminder", "repo", h.Repo, "issue", h.Number, "error", err)
+			continue
+		}
+		if err := MarkHoldReminded(m.database.DB(), h.Repo, h.Number, now); err != nil {
+			slog.Error("Failed to record hold reminder", "repo", h.Repo, "issue", h.Number, "error", err)
+		}
+	}
+	return nil
+}
+
+// setLabel adds or removes the hold label.
+func (m *HoldModule) setLabel(ctx context.Context, repo string, num int, on bool) error {
+	if m.app == nil || m.app.GitHubClient == nil {
+		slog.Info("GitHub label would be changed (no GitHub client available)",
+			"repo", repo, "issue_num", num, "label", m.config.Label, "applied", on)
+		return nil
+	}
+	if on {
+		return m.app.AddLabels(ctx, m.Name(), repo, num, []string{m.config.Label})
+	}
+	return m.app.RemoveLabels(ctx, m.Name(), repo, num, []string{m.config.Label})
+}
+
+func (m *HoldModule) comment(ctx context.Context, repo string, num int, body string) error {
+	if m.app == nil || m.app.GitHubClient == nil {
+		slog.Info("GitHub comment would be posted (no GitHub client available)",
+			"repo", repo, "issue_num", num, "message", body)
+		return nil
+	}
+	return m.app.PostComment(ctx, m.Name(), repo, num, body)
+}
+
+func (m *HoldModule) wrap(err error, op, repo string, num int) error {
+	if err == nil {
+		return nil
+	}
+	return internal.LogAndWrapError(err, internal.ErrorTypeModule, op, map[string]any{
+		"module": m.Name(),
+		"repo":   repo,
+		"issue":  num,
+	})
+}
+
+func (m *HoldModule) wrapDB(err error, op, repo string, num int) error {
+	if err == nil {
+		return nil
+	}
+	return internal.LogAndWrapError(err, internal.ErrorTypeDatabase, op, map[string]any{
+		"module": m.Name(),
+		"repo":   repo,
+		"issue":  num,
+	})
+}
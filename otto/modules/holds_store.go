@@ -0,0 +1,118 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+// Hold is a `/hold` keeping a pull request from being merged.
+type Hold struct {
+	Repo       string
+	Number     int
+	Login      string // who placed the hold
+	Reason     string
+	HeldAt     time.Time
+	RemindedAt *time.Time
+}
+
+func AutoMigrateHolds(db *sql.DB) error {
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS pr_holds (
+			repo TEXT NOT NULL,
+			number INTEGER NOT NULL,
+			login TEXT NOT NULL,
+			reason TEXT NOT NULL DEFAULT '',
+			held_at TIMESTAMP NOT NULL,
+			reminded_at TIMESTAMP,
+			PRIMARY KEY (repo, number)
+		);`,
+	}
+	for _, s := range stmts {
+		if _, err := db.Exec(s); err != nil {
+			return fmt.Errorf("failed migration: %w (SQL: %s)", err, s)
+		}
+	}
+	return nil
+}
+
+// Migrate implements the ModuleMigrator interface.
+func (m *HoldModule) Migrate(db *sql.DB) error {
+	return AutoMigrateHolds(db)
+}
+
+// PlaceHold records a hold and reports whether it is new; an existing hold is kept as it is.
+func PlaceHold(db *sql.DB, h Hold) (bool, error) {
+	res, err := db.Exec(
+		`INSERT INTO pr_holds (repo, number, login, reason, held_at) VALUES (?, ?, ?, ?, ?)
+		 ON CONFLICT (repo, number) DO NOTHING`,
+		h.Repo, h.Number, h.Login, h.Reason, h.HeldAt,
+	)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// GetHold returns the hold on a pull request, or nil if there is none.
+func GetHold(db *sql.DB, repo string, number int) (*Hold, error) {
+	h := Hold{Repo: repo, Number: number}
+	err := db.QueryRow(
+		`SELECT login, reason, held_at, reminded_at FROM pr_holds WHERE repo = ? AND number = ?`,
+		repo, number,
+	).Scan(&h.Login, &h.Reason, &h.HeldAt, &h.RemindedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &h, nil
+}
+
+// ReleaseHold removes the hold on a pull request and reports whether there was one.
+func ReleaseHold(db *sql.DB, repo string, number int) (bool, error) {
+	res, err := db.Exec(`DELETE FROM pr_holds WHERE repo = ? AND number = ?`, repo, number)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// ListHolds returns all holds, oldest first.
+func ListHolds(db *sql.DB) ([]Hold, error) {
+	rows, err := db.Query(
+		`SELECT repo, number, login, reason, held_at, reminded_at FROM pr_holds ORDER BY held_at`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var holds []Hold
+	for rows.Next() {
+		var h Hold
+		if err := rows.Scan(&h.Repo, &h.Number, &h.Login, &h.Reason, &h.HeldAt, &h.RemindedAt); err != nil {
+			return nil, err
+		}
+		holds = append(holds, h)
+	}
+	return holds, rows.Err()
+}
+
+// MarkHoldReminded records when the holder was last reminded of a hold.
+func MarkHoldReminded(db *sql.DB, repo string, number int, at time.Time) error {
+	_, err := db.Exec(`UPDATE pr_holds SET reminded_at = ? WHERE repo = ? AND number = ?`, at, repo, number)
+	return err
+}
+
+// RenameRepo implements the ModuleRepoRenamer interface.
+func (m *HoldModule) RenameRepo(ctx context.Context, from, to string) error {
+	return internal.RenameRepoIn(ctx, m.database.DB(), from, to, "pr_holds.repo")
+}
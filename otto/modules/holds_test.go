@@ -0,0 +1,187 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v71/github"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+var holdNow = time.Date(2025, time.June, 2, 9, 0, 0, 0, time.UTC)
+
+func newHoldTestModule(t *testing.T, fake *fakeGitHub) *HoldModule {
+	t.Helper()
+	db := internal.TestDB(t)
+	if err := AutoMigrateHolds(db); err != nil {
+		t.Fatalf("AutoMigrateHolds failed: %v", err)
+	}
+	return &HoldModule{
+		app:      &internal.App{GitHubClient: fake.client(t)},
+		database: internal.NewDatabaseFromDB(db),
+		config:   HoldConfig{Label: "do-not-merge/hold", RemindAfterDays: 7, CheckInterval: time.Hour},
+		now:      func() time.Time { return holdNow },
+	}
+}
+
+func TestHoldCommands(t *testing.T) {
+	tests := []struct {
+		name        string
+		comments    [][3]string // user, author association, body
+		wantHeld    bool
+		wantComment string
+	}{
+		{
+			name:     "hold",
+			comments: [][3]string{{"alice", "CONTRIBUTOR", "/hold waiting for the spec"}},
+			wantHeld: true,
+		},
+		{
+			name:     "holder cancels",
+			comments: [][3]string{{"alice", "CONTRIBUTOR", "/hold"}, {"alice", "CONTRIBUTOR", "/hold cancel"}},
+		},
+		{
+			name:     "maintainer cancels",
+			comments: [][3]string{{"alice", "CONTRIBUTOR", "/hold"}, {"bob", "MEMBER", "/hold cancel"}},
+		},
+		{
+			name:        "others cannot cancel",
+			comments:    [][3]string{{"alice", "CONTRIBUTOR", "/hold"}, {"carol", "CONTRIBUTOR", "/hold cancel"}},
+			wantHeld:    true,
+			wantComment: "only @alice or a maintainer can cancel this hold",
+		},
+		{
+			name:        "already held",
+			comments:    [][3]string{{"alice", "CONTRIBUTOR", "/hold"}, {"carol", "CONTRIBUTOR", "/hold"}},
+			wantHeld:    true,
+			wantComment: "already on hold by @alice since 2025-06-02",
+		},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeGitHub()
+			mod := newHoldTestModule(t, fake)
+			for _, c := range tt.comments {
+				event := prCommentEvent("org/repo", i+1, c[0], c[2])
+				event.Comment.AuthorAssociation = github.Ptr(c[1])
+				if err := internal.TestCommands(t, mod, event); err != nil {
+					t.Fatalf("HandleCommand failed: %v", err)
+				}
+			}
+			labeled := slices.Contains(fake.labelsOn("org/repo", i+1), "do-not-merge/hold")
+			blockers, err := mod.MergeBlockers(context.Background(), "org/repo", i+1)
+			if err != nil {
+				t.Fatalf("MergeBlockers failed: %v", err)
+			}
+			if labeled != tt.wantHeld || (len(blockers) > 0) != tt.wantHeld {
+				t.Errorf("labeled = %v, blockers = %v, want held %v", labeled, blockers, tt.wantHeld)
+			}
+			comments := fake.commentsOn("org/repo", i+1)
+			switch {
+			case tt.wantComment == "" && len(comments) > 0:
+				t.Errorf("unexpected comments %v", comments)
+			case tt.wantComment != "" && (len(comments) != 1 || !strings.Contains(comments[0], tt.wantComment)):
+				t.Errorf("comments = %v, want one containing %q", comments, tt.wantComment)
+			}
+		})
+	}
+}
+
+func TestHoldMergeBlockerReason(t *testing.T) {
+	fake := newFakeGitHub()
+	mod := newHoldTestModule(t, fake)
+	event := prCommentEvent("org/repo", 1, "alice", "/hold until v2 ships")
+	if err := internal.TestCommands(t, mod, event); err != nil {
+		t.Fatalf("HandleCommand failed: %v", err)
+	}
+	blockers, err := mod.MergeBlockers(context.Background(), "org/repo", 1)
+	if err != nil {
+		t.Fatalf("MergeBlockers failed: %v", err)
+	}
+	want := "on hold by @alice since 2025-06-02: until v2 ships"
+	if len(blockers) != 1 || blockers[0] != want {
+		t.Errorf("MergeBlockers = %v, want [%s]", blockers, want)
+	}
+}
+
+func TestHoldFollowsLabel(t *testing.T) {
+	fake := newFakeGitHub()
+	mod := newHoldTestModule(t, fake)
+	labelEvent := func(action string) *github.PullRequestEvent {
+		event := pullRequestEvent(action, "org/repo", 1, "")
+		event.Label = &github.Label{Name: github.Ptr("do-not-merge/hold")}
+		event.Sender = &github.User{Login: github.Ptr("dave")}
+		return event
+	}
+
+	steps := []struct {
+		action   string
+		wantHeld bool
+	}{
+		{"labeled", true},
+		{"unlabeled", false},
+		{"labeled", true},
+		{"closed", false},
+	}
+	for _, step := range steps {
+		if err := mod.HandleEvent("pull_request", labelEvent(step.action), nil); err != nil {
+			t.Fatalf("HandleEvent(%s) failed: %v", step.action, err)
+		}
+		hold, err := GetHold(mod.database.DB(), "org/repo", 1)
+		if err != nil {
+			t.Fatalf("GetHold failed: %v", err)
+		}
+		if (hold != nil) != step.wantHeld {
+			t.Errorf("after %s: hold = %+v, want held %v", step.action, hold, step.wantHeld)
+		}
+		if hold != nil && hold.Login != "dave" {
+			t.Errorf("after %s: holder = %q, want dave", step.action, hold.Login)
+		}
+	}
+}
+
+func TestRemindHolders(t *testing.T) {
+	fake := newFakeGitHub()
+	mod := newHoldTestModule(t, fake)
+	db := mod.database.DB()
+	for _, h := range []Hold{
+		{Repo: "org/repo", Number: 1, Login: "alice", HeldAt: holdNow.Add(-8 * 24 * time.Hour)},
+		{Repo: "org/repo", Number: 2, Login: "bob", HeldAt: holdNow.Add(-2 * 24 * time.Hour)},
+	} {
+		if _, err := PlaceHold(db, h); err != nil {
+			t.Fatalf("PlaceHold failed: %v", err)
+		}
+	}
+
+	if err := mod.RemindHolders(context.Background()); err != nil {
+		t.Fatalf("RemindHolders failed: %v", err)
+	}
+	comments := fake.commentsOn("org/repo", 1)
+	if len(comments) != 1 || !strings.Contains(comments[0], "@alice this pull request has been on hold for 8 days") {
+		t.Errorf("comments on #1 = %v", comments)
+	}
+	if comments := fake.commentsOn("org/repo", 2); len(comments) != 0 {
+		t.Errorf("recent hold was reminded: %v", comments)
+	}
+
+	// The next reminder comes RemindAfterDays after the last one.
+	if err := mod.RemindHolders(context.Background()); err != nil {
+		t.Fatalf("RemindHolders failed: %v", err)
+	}
+	if comments := fake.commentsOn("org/repo", 1); len(comments) != 1 {
+		t.Errorf("reminded again too soon: %v", comments)
+	}
+	mod.now = func() time.Time { return holdNow.Add(7 * 24 * time.Hour) }
+	if err := mod.RemindHolders(context.Background()); err != nil {
+		t.Fatalf("RemindHolders failed: %v", err)
+	}
+	if comments := fake.commentsOn("org/repo", 1); len(comments) != 2 {
+		t.Errorf("expected a second reminder, got %v", comments)
+	}
+}
@@ -0,0 +1,384 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"maps"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Import sources understood by `otto import`.
+const (
+	ImportFromProw           = "prow"
+	ImportFromProbotSettings = "probot-settings"
+	ImportFromStaleAction    = "stale-action"
+	// ImportFromStackOverflowState backfills the stackoverflow module's state rather than
+	// converting configuration.
+	ImportFromStackOverflowState = "stackoverflow-state"
+)
+
+// ImportedConfig is configuration converted from another bot: module sections as they are
+// written under modules: in config.yaml or .github/otto.yml, and notes on the settings
+// that have no Otto equivalent.
+type ImportedConfig struct {
+	Modules  map[string]map[string]any
+	Warnings []string
+}
+
+// YAML renders the module sections under a modules: key, indented like config.yaml.
+func (c *ImportedConfig) YAML() ([]byte, error) {
+	var b bytes.Buffer
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+	if err := enc.Encode(map[string]any{"modules": c.Modules}); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func (c *ImportedConfig) set(module, key string, value any) {
+	if c.Modules == nil {
+		c.Modules = make(map[string]map[string]any)
+	}
+	if c.Modules[module] == nil {
+		c.Modules[module] = make(map[string]any)
+	}
+	c.Modules[module][key] = value
+}
+
+func (c *ImportedConfig) warnf(format string, args ...any) {
+	c.Warnings = append(c.Warnings, fmt.Sprintf(format, args...))
+}
+
+// prowOwners is the format of a Prow OWNERS file.
+type prowOwners struct {
+	Approvers []string                   `yaml:"approvers"`
+	Reviewers []string                   `yaml:"reviewers"`
+	Labels    []string                   `yaml:"labels"`
+	Filters   map[string]prowOwnersEntry `yaml:"filters"`
+}
+
+// prowOwnersEntry is the owners of the files matching an OWNERS filter.
+type prowOwnersEntry struct {
+	Approvers []string `yaml:"approvers"`
+	Reviewers []string `yaml:"reviewers"`
+	Labels    []string `yaml:"labels"`
+}
+
+// ImportProwOwners converts the OWNERS files of a repository checkout: the root file's
+// approvers and reviewers become who may /approve and /lgtm, the approvers and reviewers
+// of other directories become components of the owners module named by the directory,
+// and their labels become path label rules. Aliases in OWNERS_ALIASES are expanded.
+func ImportProwOwners(fsys fs.FS) (*ImportedConfig, error) {
+	var aliases struct {
+		Aliases map[string][]string `yaml:"aliases"`
+	}
+	if data, err := fs.ReadFile(fsys, "OWNERS_ALIASES"); err == nil {
+		if err := yaml.Unmarshal(data, &aliases); err != nil {
+			return nil, fmt.Errorf("invalid OWNERS_ALIASES: %w", err)
+		}
+	}
+	expand := func(names ...[]string) []string {
+		var logins []string
+		for _, name := range slices.Concat(names...) {
+			if members, ok := aliases.Aliases[name]; ok {
+				logins = append(logins, members...)
+			} else {
+				logins = append(logins, name)
+			}
+		}
+		slices.Sort(logins)
+		return slices.Compact(logins)
+	}
+
+	c := &ImportedConfig{}
+	components := make(map[string][]string)
+	labelPaths := make(map[string][]string)
+	found := false
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && (d.Name() == ".git" || d.Name() == "vendor") {
+			return fs.SkipDir
+		}
+		if d.IsDir() || d.Name() != "OWNERS" {
+			return nil
+		}
+		found = true
+		data, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+		var owners prowOwners
+		if err := yaml.Unmarshal(data, &owners); err != nil {
+			return fmt.Errorf("invalid %s: %w", p, err)
+		}
+		for _, filter := range slices.Sorted(maps.Keys(owners.Filters)) {
+			entry := owners.Filters[filter]
+			if filter != ".*" {
+				c.warnf("%s: filter %q is not imported; only whole directories have owners", p, filter)
+				continue
+			}
+			owners.Approvers = append(owners.Approvers, entry.Approvers...)
+			owners.Reviewers = append(owners.Reviewers, entry.Reviewers...)
+			owners.Labels = append(owners.Labels, entry.Labels...)
+		}
+
+		dir := path.Dir(p)
+		if dir == "." {
+			if approvers := expand(owners.Approvers); len(approvers) > 0 {
+				c.set("approvals", "approvers", approvers)
+			}
+			if reviewers := expand(owners.Reviewers); len(reviewers) > 0 {
+				c.set("approvals", "reviewers", reviewers)
+			}
+			if len(owners.Labels) > 0 {
+				c.warnf("%s: labels %v apply to every pull request and are not imported", p, owners.Labels)
+			}
+			return nil
+		}
+		if people := expand(owners.Approvers, owners.Reviewers); len(people) > 0 {
+			components[dir] = people
+		}
+		for _, label := range owners.Labels {
+			labelPaths[label] = append(labelPaths[label], dir+"/**")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("no OWNERS files found")
+	}
+	if len(components) > 0 {
+		c.set("owners", "components", components)
+	}
+	if len(labelPaths) > 0 {
+		var rules []map[string]any
+		for _, label := range slices.Sorted(maps.Keys(labelPaths)) {
+			rules = append(rules, map[string]any{"label": label, "paths": labelPaths[label]})
+		}
+		c.set("pathlabels", "rules", rules)
+	}
+	return c, nil
+}
+
+// probotRepoSettings are the repository settings of probot/settings that the onboarding
+// module's settings policy has under the same names.
+var probotRepoSettings = []string{
+	"has_wiki", "has_projects", "delete_branch_on_merge", "allow_squash_merge", "allow_merge_commit",
+	"allow_rebase_merge",
+}
+
+// ImportProbotSettings converts a probot/settings .github/settings.yml: its labels and the
+// repository settings the onboarding module applies.
+func ImportProbotSettings(data []byte) (*ImportedConfig, error) {
+	var settings map[string]any
+	if err := yaml.Unmarshal(data, &settings); err != nil {
+		return nil, fmt.Errorf("invalid settings file: %w", err)
+	}
+	c := &ImportedConfig{}
+	for _, section := range slices.Sorted(maps.Keys(settings)) {
+		switch section {
+		case "repository":
+			repo, _ := settings[section].(map[string]any)
+			policy := make(map[string]any)
+			for _, key := range slices.Sorted(maps.Keys(repo)) {
+				value, ok := repo[key].(bool)
+				if !ok || !slices.Contains(probotRepoSettings, key) {
+					c.warnf("repository.%s is not imported; onboarding does not apply it", key)
+					continue
+				}
+				policy[key] = value
+			}
+			if len(policy) > 0 {
+				c.set("onboarding", "settings", policy)
+			}
+		case "labels":
+			// Colors are decoded from the original text: unquoted colors such as 008672
+			// would otherwise be read as numbers.
+			var doc struct {
+				Labels []struct {
+					Name        string    `yaml:"name"`
+					Color       yaml.Node `yaml:"color"`
+					Description string    `yaml:"description"`
+				} `yaml:"labels"`
+			}
+			if err := yaml.Unmarshal(data, &doc); err != nil {
+				return nil, fmt.Errorf("invalid labels: %w", err)
+			}
+			var specs []map[string]any
+			for _, l := range doc.Labels {
+				spec := map[string]any{"name": l.Name, "color": strings.TrimPrefix(l.Color.Value, "#")}
+				if l.Description != "" {
+					spec["description"] = l.Description
+				}
+				specs = append(specs, spec)
+			}
+			if len(specs) > 0 {
+				c.set("onboarding", "labels", specs)
+			}
+		default:
+			c.warnf("%s is not imported; Otto does not manage it", section)
+		}
+	}
+	return c, nil
+}
+
+// ImportStale converts the configuration of a workflow using actions/stale, or of the
+// probot stale app's .github/stale.yml, to the sla module. Issues are marked stale after
+// the configured days and closed some days later; the sla module instead pings the author
+// of issues waiting on them and closes them after the same number of days.
+func ImportStale(data []byte) (*ImportedConfig, error) {
+	var doc map[string]any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid stale config: %w", err)
+	}
+	if with, ok := staleActionInputs(doc); ok {
+		return importStaleAction(with), nil
+	}
+	if _, ok := doc["jobs"]; ok {
+		return nil, fmt.Errorf("the workflow has no step using actions/stale")
+	}
+	return importProbotStale(doc), nil
+}
+
+// staleActionInputs returns the inputs of the first workflow step using actions/stale.
+func staleActionInputs(doc map[string]any) (map[string]any, bool) {
+	jobs, _ := doc["jobs"].(map[string]any)
+	for _, name := range slices.Sorted(maps.Keys(jobs)) {
+		job, _ := jobs[name].(map[string]any)
+		steps, _ := job["steps"].([]any)
+		for _, s := range steps {
+			step, _ := s.(map[string]any)
+			if uses, _ := step["uses"].(string); strings.HasPrefix(uses, "actions/stale@") {
+				with, _ := step["with"].(map[string]any)
+				return with, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// importStaleAction converts actions/stale inputs, taking its defaults of 60 days before
+// issues are stale and 7 more before they are closed.
+func importStaleAction(with map[string]any) *ImportedConfig {
+	c := &ImportedConfig{}
+	input := func(names ...string) (string, bool) {
+		for _, name := range names {
+			if v, ok := with[name]; ok {
+				return fmt.Sprint(v), true
+			}
+		}
+		return "", false
+	}
+	stale, closeAfter := 60, 7
+	if v, ok := input("days-before-issue-stale", "days-before-stale"); ok {
+		stale = staleDays(c, "days-before-stale", v, stale)
+	}
+	if v, ok := input("days-before-issue-close", "days-before-close"); ok {
+		closeAfter = staleDays(c, "days-before-close", v, closeAfter)
+	}
+	label, _ := input("only-issue-labels", "only-labels")
+	setStaleTimers(c, stale, closeAfter, label)
+
+	for _, name := range slices.Sorted(maps.Keys(with)) {
+		switch name {
+		case "days-before-issue-stale", "days-before-stale", "days-before-issue-close", "days-before-close",
+			"only-issue-labels", "only-labels", "repo-token", "operations-per-run":
+		case "stale-issue-message", "close-issue-message":
+			c.warnf("%s is not imported; the sla module posts its own reminder", name)
+		default:
+			c.warnf("%s is not imported; the sla module has no equivalent", name)
+		}
+	}
+	return c
+}
+
+// importProbotStale converts a probot stale.yml, taking its defaults of 60 days before
+// issues are stale and 7 more before they are closed.
+func importProbotStale(doc map[string]any) *ImportedConfig {
+	c := &ImportedConfig{}
+	stale, closeAfter := 60, 7
+	if v, ok := doc["daysUntilStale"]; ok {
+		stale = staleDays(c, "daysUntilStale", fmt.Sprint(v), stale)
+	}
+	if v, ok := doc["daysUntilClose"]; ok {
+		if v == false {
+			v = -1
+		}
+		closeAfter = staleDays(c, "daysUntilClose", fmt.Sprint(v), closeAfter)
+	}
+	var label string
+	if labels, ok := doc["onlyLabels"].([]any); ok && len(labels) > 0 {
+		parts := make([]string, len(labels))
+		for i, l := range labels {
+			parts[i] = fmt.Sprint(l)
+		}
+		label = strings.Join(parts, ",")
+	} else if l, ok := doc["onlyLabels"].(string); ok {
+		label = l
+	}
+	setStaleTimers(c, stale, closeAfter, label)
+
+	for _, name := range slices.Sorted(maps.Keys(doc)) {
+		switch name {
+		case "daysUntilStale", "daysUntilClose", "onlyLabels", "limitPerRun", "only":
+		case "markComment", "closeComment", "unmarkComment":
+			c.warnf("%s is not imported; the sla module posts its own reminder", name)
+		default:
+			c.warnf("%s is not imported; the sla module has no equivalent", name)
+		}
+	}
+	return c
+}
+
+// staleDays parses a number of days; a negative number disables the step, and invalid
+// values keep the default.
+func staleDays(c *ImportedConfig, name, value string, def int) int {
+	days, err := strconv.Atoi(value)
+	if err != nil {
+		c.warnf("%s: %q is not a number of days; using %d", name, value, def)
+		return def
+	}
+	return days
+}
+
+// setStaleTimers sets the sla module's ping and close delays, and its waiting label when
+// the stale config only covers issues with a single label.
+func setStaleTimers(c *ImportedConfig, stale, closeAfter int, onlyLabels string) {
+	if stale < 0 {
+		c.warnf("issues are never marked stale; the sla module's reminders are disabled")
+		stale = 0
+	}
+	c.set("sla", "ping_after_days", stale)
+	if closeAfter < 0 {
+		c.set("sla", "close_after_days", 0)
+	} else {
+		c.set("sla", "close_after_days", stale+closeAfter)
+	}
+
+	switch labels := strings.Split(onlyLabels, ","); {
+	case onlyLabels == "":
+		c.warnf("every inactive issue is marked stale; the sla module only times issues with its " +
+			"waiting_label (default: waiting-for-author)")
+	case len(labels) == 1:
+		c.set("sla", "waiting_label", strings.TrimSpace(labels[0]))
+	default:
+		c.warnf("only issues with all of the labels %q are marked stale; the sla module times issues with "+
+			"one waiting_label", onlyLabels)
+	}
+}
@@ -0,0 +1,209 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"gopkg.in/yaml.v3"
+)
+
+// decodeImported decodes an imported module section into out, as Otto loads it.
+func decodeImported(t *testing.T, c *ImportedConfig, module string, out any) {
+	t.Helper()
+	data, err := yaml.Marshal(c.Modules[module])
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if err := yaml.Unmarshal(data, out); err != nil {
+		t.Fatalf("imported %s section does not decode: %v", module, err)
+	}
+}
+
+func TestImportProwOwners(t *testing.T) {
+	fsys := fstest.MapFS{
+		"OWNERS_ALIASES": {Data: []byte("aliases:\n  maintainers: [alice, bob]\n")},
+		"OWNERS":         {Data: []byte("approvers: [maintainers]\nreviewers: [carol, alice]\nlabels: [sig/collector]\n")},
+		"receiver/otlp/OWNERS": {Data: []byte(`approvers: [dave]
+reviewers: [maintainers]
+labels: [area/otlp]
+filters:
+  ".*":
+    reviewers: [frank]
+  "\\.proto$":
+    approvers: [erin]
+`)},
+		"exporter/otlp/OWNERS": {Data: []byte("approvers: [gina]\nlabels: [area/otlp]\n")},
+		"vendor/x/OWNERS":      {Data: []byte("approvers: [nobody]\n")},
+	}
+	c, err := ImportProwOwners(fsys)
+	if err != nil {
+		t.Fatalf("ImportProwOwners failed: %v", err)
+	}
+
+	var approvals ApprovalConfig
+	decodeImported(t, c, "approvals", &approvals)
+	if !slices.Equal(approvals.Approvers, []string{"alice", "bob"}) ||
+		!slices.Equal(approvals.Reviewers, []string{"alice", "carol"}) {
+		t.Errorf("approvals = %+v", approvals)
+	}
+	var owners OwnersConfig
+	decodeImported(t, c, "owners", &owners)
+	want := map[string][]string{
+		"exporter/otlp": {"gina"},
+		"receiver/otlp": {"alice", "bob", "dave", "frank"},
+	}
+	if !reflect.DeepEqual(owners.Components, want) {
+		t.Errorf("components = %v, want %v", owners.Components, want)
+	}
+	var labels PathLabelsConfig
+	decodeImported(t, c, "pathlabels", &labels)
+	if len(labels.Rules) != 1 || labels.Rules[0].Label != "area/otlp" ||
+		!slices.Equal(labels.Rules[0].Paths, []string{"exporter/otlp/**", "receiver/otlp/**"}) {
+		t.Errorf("rules = %+v", labels.Rules)
+	}
+	if len(c.Warnings) != 2 || !strings.Contains(c.Warnings[0], "sig/collector") ||
+		!strings.Contains(c.Warnings[1], `\\.proto$`) {
+		t.Errorf("warnings = %q", c.Warnings)
+	}
+
+	if _, err := ImportProwOwners(fstest.MapFS{"README.md": {}}); err == nil {
+		t.Error("expected an error without OWNERS files")
+	}
+}
+
+func TestImportProbotSettings(t *testing.T) {
+	c, err := ImportProbotSettings([]byte(`repository:
+  has_wiki: false
+  allow_merge_commit: true
+  private: true
+labels:
+  - name: bug
+    color: "#d73a4a"
+    description: Something isn't working
+  - name: help wanted
+    color: 008672
+branches:
+  - name: main
+`))
+	if err != nil {
+		t.Fatalf("ImportProbotSettings failed: %v", err)
+	}
+	var onboarding OnboardingConfig
+	decodeImported(t, c, "onboarding", &onboarding)
+	wantLabels := []LabelSpec{
+		{Name: "bug", Color: "d73a4a", Description: "Something isn't working"},
+		{Name: "help wanted", Color: "008672"},
+	}
+	if !slices.Equal(onboarding.Labels, wantLabels) {
+		t.Errorf("labels = %+v", onboarding.Labels)
+	}
+	s := onboarding.Settings
+	if s.HasWiki == nil || *s.HasWiki || s.AllowMergeCommit == nil || !*s.AllowMergeCommit || s.HasProjects != nil {
+		t.Errorf("settings = %+v", s)
+	}
+	wantWarnings := []string{
+		"branches is not imported; Otto does not manage it",
+		"repository.private is not imported; onboarding does not apply it",
+	}
+	if !slices.Equal(c.Warnings, wantWarnings) {
+		t.Errorf("warnings = %q", c.Warnings)
+	}
+}
+
+func TestImportStale(t *testing.T) {
+	tests := []struct {
+		name         string
+		config       string
+		wantPing     int
+		wantClose    int
+		wantLabel    string
+		wantWarnings int
+		wantErr      bool
+	}{
+		{
+			name: "actions/stale",
+			config: `jobs:
+  stale:
+    steps:
+      - uses: actions/checkout@v4
+      - uses: actions/stale@v9
+        with:
+          days-before-issue-stale: 30
+          days-before-close: 14
+          only-labels: waiting-on-author
+          stale-issue-label: stale
+          repo-token: ${{ secrets.GITHUB_TOKEN }}
+`,
+			wantPing: 30, wantClose: 44, wantLabel: "waiting-on-author", wantWarnings: 1,
+		},
+		{
+			name: "actions/stale defaults, never closing",
+			config: `jobs:
+  stale:
+    steps:
+      - uses: actions/stale@v9
+        with:
+          days-before-close: -1
+`,
+			wantPing: 60, wantClose: 0, wantWarnings: 1, // every issue is marked stale
+		},
+		{
+			name:     "probot stale",
+			config:   "daysUntilStale: 21\ndaysUntilClose: false\nonlyLabels: [needs-info]\nmarkComment: Stale\n",
+			wantPing: 21, wantClose: 0, wantLabel: "needs-info", wantWarnings: 1,
+		},
+		{
+			name:     "probot stale with several labels",
+			config:   "daysUntilStale: 10\ndaysUntilClose: 5\nonlyLabels: [a, b]\n",
+			wantPing: 10, wantClose: 15, wantWarnings: 1,
+		},
+		{
+			name:    "workflow without actions/stale",
+			config:  "jobs:\n  build:\n    steps:\n      - run: make\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := ImportStale([]byte(tt.config))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ImportStale failed: %v", err)
+			}
+			sla := defaultSLAConfig()
+			decodeImported(t, c, "sla", &sla)
+			wantLabel := tt.wantLabel
+			if wantLabel == "" {
+				wantLabel = defaultSLAConfig().WaitingLabel
+			}
+			if sla.PingAfterDays != tt.wantPing || sla.CloseAfterDays != tt.wantClose || sla.WaitingLabel != wantLabel {
+				t.Errorf("sla = %+v", sla)
+			}
+			if len(c.Warnings) != tt.wantWarnings {
+				t.Errorf("warnings = %q, want %d", c.Warnings, tt.wantWarnings)
+			}
+		})
+	}
+}
+
+func TestImportedConfigYAML(t *testing.T) {
+	c := &ImportedConfig{}
+	c.set("sla", "ping_after_days", 3)
+	out, err := c.YAML()
+	if err != nil {
+		t.Fatalf("YAML failed: %v", err)
+	}
+	if got, want := string(out), "modules:\n  sla:\n    ping_after_days: 3\n"; got != want {
+		t.Errorf("YAML = %q, want %q", got, want)
+	}
+}
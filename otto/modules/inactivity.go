@@ -0,0 +1,546 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v71/github"
+	"gopkg.in/yaml.v3"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+// InactivityConfig configures the quarterly maintainer and approver activity report.
+type InactivityConfig struct {
+	Repos             []string `yaml:"repos" doc:"repositories whose owners are checked; default: onboarded"`
+	Files             []string `yaml:"files" doc:"ownership files: CODEOWNERS format, or YAML with components:"`
+	InactiveAfterDays int      `yaml:"inactive_after_days" doc:"days without activity before someone is flagged"`
+	Ignore            []string `yaml:"ignore" doc:"logins never flagged, e.g. bots or emeritus members"`
+	ReportRepo        string   `yaml:"report_repo" doc:"repository the report issue is opened in"`
+	ReportLabels      []string `yaml:"report_labels" doc:"labels of the report issue"`
+}
+
+// MaintainerActivity is one person's activity as seen by the report.
+type MaintainerActivity struct {
+	Login    string
+	Sources  []string             // where the person is listed, e.g. "org/repo .github/CODEOWNERS via @org/team"
+	Last     map[string]time.Time // last activity by kind
+	Inactive bool
+}
+
+// LastActive returns the person's most recent activity of any kind.
+func (a MaintainerActivity) LastActive() time.Time {
+	var last time.Time
+	for _, at := range a.Last {
+		if at.After(last) {
+			last = at
+		}
+	}
+	return last
+}
+
+// InactivityReport lists the people in ownership files and flags those without recent activity.
+type InactivityReport struct {
+	Quarter string
+	Cutoff  time.Time
+	People  []MaintainerActivity
+}
+
+// Inactive returns the flagged people.
+func (r *InactivityReport) Inactive() []MaintainerActivity {
+	var inactive []MaintainerActivity
+	for _, p := range r.People {
+		if p.Inactive {
+			inactive = append(inactive, p)
+		}
+	}
+	return inactive
+}
+
+// InactivityModule tracks review, commit and comment activity and opens a quarterly report
+// of maintainers and approvers who have been inactive for longer than the org's policy, to
+// support the emeritus process.
+type InactivityModule struct {
+	app      *internal.App
+	database *internal.Database
+	config   InactivityConfig
+	now      func() time.Time
+}
+
+func (m *InactivityModule) Name() string { return "inactivity" }
+
+// ConfigSchema implements the ModuleConfigSchema interface.
+func (m *InactivityModule) ConfigSchema() any {
+	c := defaultInactivityConfig()
+	return &c
+}
+
+// defaultInactivityConfig returns the inactivity module's defaults.
+func defaultInactivityConfig() InactivityConfig {
+	return InactivityConfig{
+		Files:             []string{".github/CODEOWNERS", ".github/component_owners.yml"},
+		InactiveAfterDays: 180,
+		ReportLabels:      []string{"emeritus-review"},
+	}
+}
+
+// EventSubscriptions implements the ModuleEventSubscriber interface.
+func (m *InactivityModule) EventSubscriptions() []internal.EventSubscription {
+	return []internal.EventSubscription{
+		internal.Subscribe("pull_request_review", "submitted"),
+		internal.Subscribe("pull_request_review_comment", "created"),
+		internal.Subscribe("issue_comment", "created"),
+		internal.Subscribe("pull_request", "opened"),
+		internal.Subscribe("push"),
+	}
+}
+
+// GitHubPermissions implements the ModulePermissionRequirer interface.
+func (m *InactivityModule) GitHubPermissions() map[string]string {
+	return map[string]string{"contents": "read", "issues": "write", "members": "read"}
+}
+
+// Initialize implements the ModuleInitializer interface.
+func (m *InactivityModule) Initialize(ctx context.Context, app *internal.App) error {
+	m.app = app
+	m.database = app.Database
+	if m.now == nil {
+		m.now = time.Now
+	}
+	m.config = defaultInactivityConfig()
+	if err := loadModuleConfig(app, m.Name(), &m.config); err != nil {
+		return err
+	}
+	if err := checkRepos(app, m.Name(), m.config.Repos); err != nil {
+		return err
+	}
+	if m.config.InactiveAfterDays <= 0 {
+		return fmt.Errorf("inactivity: inactive_after_days must be positive, got %d", m.config.InactiveAfterDays)
+	}
+	if err := AutoMigrateInactivity(m.database.DB()); err != nil {
+		return err
+	}
+
+	app.HandleAdmin("GET /admin/inactivity", m.handlePreview)
+	if app.Scheduler != nil {
+		app.Scheduler.Register(internal.Job{
+			Name:       "inactivity_report",
+			Module:     m.Name(),
+			Deferrable: true,
+			Interval:   6 * time.Hour,
+			Run:        m.PostQuarterlyReport,
+		})
+	}
+	return nil
+}
+
+func (m *InactivityModule) HandleEvent(eventType string, event any, raw json.RawMessage) error {
+	login, kind, repo := activityOf(event)
+	if login == "" {
+		return nil
+	}
+	if err := RecordActivity(m.database.DB(), strings.ToLower(login), kind, repo, m.now()); err != nil {
+		return internal.LogAndWrapError(err, internal.ErrorTypeDatabase, "record_activity", map[string]any{
+			"module": m.Name(),
+			"repo":   repo,
+			"login":  login,
+		})
+	}
+	return nil
+}
+
+// activityOf returns who acted, the kind of activity and the repository for events that
+// count as maintainer activity, or an empty login for other events.
+func activityOf(event any) (login, kind, repo string) {
+	switch e := event.(type) {
+	case *github.PullRequestReviewEvent:
+		if e.GetAction() == "submitted" {
+			return e.GetSender().GetLogin(), ActivityReview, e.GetRepo().GetFullName()
+		}
+	case *github.PullRequestReviewCommentEvent:
+		if e.GetAction() == "created" {
+			return e.GetSender().GetLogin(), ActivityReview, e.GetRepo().GetFullName()
+		}
+	case *github.IssueCommentEvent:
+		if e.GetAction() == "created" {
+			return e.GetSender().GetLogin(), ActivityComment, e.GetRepo().GetFullName()
+		}
+	case *github.PullRequestEvent:
+		if e.GetAction() == "opened" {
+			return e.GetSender().GetLogin(), ActivityCommit, e.GetRepo().GetFullName()
+		}
+	case *github.PushEvent:
+		return e.GetSender().GetLogin(), ActivityCommit, e.GetRepo().GetFullName()
+	}
+	return "", "", ""
+}
+
+// quarterOf names the calendar quarter containing t, e.g. "2025-Q3".
+func quarterOf(t time.Time) string {
+	t = t.UTC()
+	return fmt.Sprintf("%d-Q%d", t.Year(), (int(t.Month())-1)/3+1)
+}
+
+// PostQuarterlyReport opens the current quarter's report unless it was already posted.
+func (m *InactivityModule) PostQuarterlyReport(ctx context.Context) error {
+	now := m.now()
+	quarter := quarterOf(now)
+	db := m.database.DB()
+	if posted, err := InactivityReportPosted(db, quarter); err != nil || posted {
+		return err
+	}
+
+	report, err := m.BuildReport(ctx)
+	if err != nil {
+		return err
+	}
+	url, err := m.openReportIssue(ctx, report)
+	if err != nil {
+		return err
+	}
+	if err := RecordInactivityReport(db, quarter, url, now); err != nil {
+		return err
+	}
+
+	inactive := report.Inactive()
+	notification := internal.Notification{
+		Module:   m.Name(),
+		Repo:     m.config.ReportRepo,
+		Severity: internal.SeverityInfo,
+		Title:    fmt.Sprintf("Maintainer activity report for %s", quarter),
+		Body: fmt.Sprintf("%d of %d maintainers and approvers have had no activity in %d days.",
+			len(inactive), len(report.People), m.config.InactiveAfterDays),
+		URL: url,
+	}
+	if err := m.app.Notifications.Notify(ctx, notification); err != nil {
+		slog.Error("Failed to notify inactivity report", "quarter", quarter, "error", err)
+	}
+	return nil
+}
+
+// BuildReport collects everyone listed in the ownership files and flags those without
+// activity since the cutoff. Activity comes from webhooks seen by this module, then from
+// the event store, then from the search API for anyone still without recent activity.
+func (m *InactivityModule) BuildReport(ctx context.Context) (*InactivityReport, error) {
+	now := m.now()
+	report := &InactivityReport{
+		Quarter: quarterOf(now),
+		Cutoff:  now.AddDate(0, 0, -m.config.InactiveAfterDays),
+	}
+	repos, err := m.repos(ctx)
+	if err != nil {
+		return nil, err
+	}
+	people, err := m.people(ctx, repos)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, login := range slices.Sorted(maps.Keys(people)) {
+		person := MaintainerActivity{Login: login, Sources: people[login]}
+		if person.Last, err = GetActivity(m.database.DB(), strings.ToLower(login)); err != nil {
+			return nil, err
+		}
+		if person.LastActive().Before(report.Cutoff) {
+			if err := m.backfillFromEvents(ctx, &person, report.Cutoff); err != nil {
+				return nil, err
+			}
+		}
+		if person.LastActive().Before(report.Cutoff) {
+			if err := m.backfillFromSearch(ctx, &person, repos, report.Cutoff); err != nil {
+				return nil, err
+			}
+		}
+		person.Inactive = person.LastActive().Before(report.Cutoff)
+		report.People = append(report.People, person)
+	}
+	return report, nil
+}
+
+// repos returns the configured repositories, else the onboarded ones that are not archived.
+func (m *InactivityModule) repos(ctx context.Context) ([]string, error) {
+	if len(m.config.Repos) > 0 || m.app.Repos == nil {
+		return m.app.RepoGroups.Resolve(ctx, m.config.Repos)
+	}
+	managed, err := m.app.Repos.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var repos []string
+	for _, r := range managed {
+		if r.Status == internal.RepoActive {
+			repos = append(repos, r.FullName)
+		}
+	}
+	return repos, nil
+}
+
+// people reads the ownership files of each repository and returns the listed people,
+// with teams expanded to their members, mapped to where they are listed.
+func (m *InactivityModule) people(ctx context.Context, repos []string) (map[string][]string, error) {
+	people := make(map[string][]string)
+	if m.app.GitHubClient == nil {
+		return people, nil
+	}
+	teams := make(map[string][]string) // org/team -> members, fetched once per report
+	for _, repo := range repos {
+		for _, file := range m.config.Files {
+			content, err := internal.GetFile(ctx, m.app.GitHubClient, repo, file, "")
+			if err != nil {
+				return nil, err
+			}
+			if content == nil {
+				continue
+			}
+			for _, owner := range parseOwnershipFile(file, content.Content) {
+				source := repo + " " + file
+				logins := []string{owner}
+				if org, slug, isTeam := strings.Cut(owner, "/"); isTeam {
+					source += " via @" + owner
+					if _, ok := teams[owner]; !ok {
+						if teams[owner], err = teamMembers(ctx, m.app, org, slug); err != nil {
+							return nil, err
+						}
+					}
+					logins = teams[owner]
+				}
+				for _, login := range logins {
+					if m.ignored(login) || slices.Contains(people[login], source) {
+						continue
+					}
+					people[login] = append(people[login], source)
+				}
+			}
+		}
+	}
+	return people, nil
+}
+
+func (m *InactivityModule) ignored(login string) bool {
+	return strings.HasSuffix(login, "[bot]") || slices.ContainsFunc(m.config.Ignore, func(i string) bool {
+		return strings.EqualFold(strings.TrimPrefix(i, "@"), login)
+	})
+}
+
+// teamMembersTTL is how long team members are reused from the app's cache.
+const teamMembersTTL = 10 * time.Minute
+
+// teamMembers returns the logins of the members of org/slug, from the app's cache if it
+// has them.
+func teamMembers(ctx context.Context, app *internal.App, org, slug string) ([]string, error) {
+	key := "team:" + org + "/" + slug
+	if members, ok := internal.GetCached[[]string](ctx, app.Cache, key); ok {
+		return members, nil
+	}
+	var members []string
+	opts := &github.TeamListTeamMembersOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		users, resp, err := app.GitHubClient.Teams.ListTeamMembersBySlug(ctx, org, slug, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list members of %s/%s: %w", org, slug, err)
+		}
+		for _, u := range users {
+			members = append(members, u.GetLogin())
+		}
+		if resp.NextPage == 0 {
+			internal.SetCached(ctx, app.Cache, key, members, teamMembersTTL)
+			return members, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// parseOwnershipFile returns the owners listed in a CODEOWNERS file, or in a YAML file with
+// a components: map like the owners module's, without the leading @ and in order of first
+// appearance. E-mail owners are skipped.
+func parseOwnershipFile(file, content string) []string {
+	var owners []string
+	add := func(owner string) {
+		owner = strings.TrimPrefix(strings.TrimSpace(owner), "@")
+		if owner != "" && !strings.Contains(owner, "@") && !slices.Contains(owners, owner) {
+			owners = append(owners, owner)
+		}
+	}
+
+	if ext := path.Ext(file); ext == ".yml" || ext == ".yaml" {
+		var parsed componentOwnersFile
+		if err := yaml.Unmarshal([]byte(content), &parsed); err != nil {
+			slog.Warn("Ignoring invalid ownership file", "path", file, "error", err)
+			return nil
+		}
+		for _, component := range slices.Sorted(maps.Keys(parsed.Components)) {
+			for _, owner := range parsed.Components[component] {
+				add(owner)
+			}
+		}
+		return owners
+	}
+
+	for _, line := range strings.Split(content, "\n") {
+		line, _, _ = strings.Cut(line, "#")
+		fields := strings.Fields(line)
+		for _, owner := range fields[min(1, len(fields)):] {
+			if strings.HasPrefix(owner, "@") {
+				add(owner)
+			}
+		}
+	}
+	return owners
+}
+
+// backfillFromEvents fills in activity since the cutoff from the event store, which may
+// hold events from before this module started tracking.
+func (m *InactivityModule) backfillFromEvents(ctx context.Context, person *MaintainerActivity, cutoff time.Time) error {
+	if m.app.Events == nil {
+		return nil
+	}
+	events, err := m.app.Events.Query(ctx, internal.EventQuery{Sender: person.Login, Since: cutoff})
+	if err != nil {
+		return err
+	}
+	for _, e := range events {
+		event, err := github.ParseWebHook(e.Type, e.Payload)
+		if err != nil {
+			continue
+		}
+		if login, kind, _ := activityOf(event); login != "" && e.ReceivedAt.After(person.Last[kind]) {
+			person.Last[kind] = e.ReceivedAt
+		}
+	}
+	return nil
+}
+
+// activitySearches map activity kinds to the search qualifiers that find them.
+var activitySearches = []struct {
+	kind      string
+	qualifier string
+}{
+	{ActivityReview, "reviewed-by"},
+	{ActivityCommit, "author"},
+	{ActivityComment, "commenter"},
+}
+
+// backfillFromSearch looks for issues and pull requests in the repositories' orgs that the
+// person reviewed, authored or commented on since the cutoff. The match's last update is
+// used as the activity time: it may be later than the person's own activity, so search
+// results can keep someone from being flagged but never flag them. Searching stops at the
+// first match.
+func (m *InactivityModule) backfillFromSearch(
+	ctx context.Context,
+	person *MaintainerActivity,
+	repos []string,
+	cutoff time.Time,
+) error {
+	if m.app.GitHubClient == nil {
+		return nil
+	}
+	var orgs []string
+	for _, repo := range repos {
+		if org, _, ok := strings.Cut(repo, "/"); ok && !slices.Contains(orgs, "org:"+org) {
+			orgs = append(orgs, "org:"+org)
+		}
+	}
+	for _, s := range activitySearches {
+		query := fmt.Sprintf("%s %s:%s updated:>=%s",
+			strings.Join(orgs, " "), s.qualifier, person.Login, cutoff.UTC().Format(time.DateOnly))
+		result, _, err := m.app.GitHubClient.Search.Issues(ctx, strings.TrimSpace(query), &github.SearchOptions{
+			Sort:        "updated",
+			Order:       "desc",
+			ListOptions: github.ListOptions{PerPage: 1},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to search %s activity of %s: %w", s.kind, person.Login, err)
+		}
+		if len(result.Issues) > 0 {
+			person.Last[s.kind] = result.Issues[0].GetUpdatedAt().Time
+			return nil
+		}
+	}
+	return nil
+}
+
+// Markdown renders the report as an issue body.
+func (r *InactivityReport) Markdown(inactiveAfterDays int) string {
+	var b strings.Builder
+	inactive := r.Inactive()
+	fmt.Fprintf(&b, "%d of %d people listed in ownership files have had no review, commit or comment "+
+		"activity in the last %d days (since %s).\n", len(inactive), len(r.People), inactiveAfterDays,
+		r.Cutoff.UTC().Format(time.DateOnly))
+	if len(inactive) == 0 {
+		b.WriteString("\nEveryone is active. 🎉\n")
+		return b.String()
+	}
+
+	slices.SortStableFunc(inactive, func(a, b MaintainerActivity) int {
+		return cmp.Compare(a.LastActive().Unix(), b.LastActive().Unix())
+	})
+	b.WriteString("\n| Person | Listed in | Last review | Last commit | Last comment |\n")
+	b.WriteString("|---|---|---|---|---|\n")
+	for _, p := range inactive {
+		fmt.Fprintf(&b, "| @%s | %s | %s | %s | %s |\n", p.Login, strings.Join(p.Sources, "<br>"),
+			formatActivity(p.Last[ActivityReview]), formatActivity(p.Last[ActivityCommit]),
+			formatActivity(p.Last[ActivityComment]))
+	}
+	b.WriteString("\nPlease check in with the people above and, per the SIG's emeritus process, " +
+		"propose moving those who are no longer active to emeritus status.\n")
+	return b.String()
+}
+
+func formatActivity(at time.Time) string {
+	if at.IsZero() {
+		return "never seen"
+	}
+	return at.UTC().Format(time.DateOnly)
+}
+
+// openReportIssue opens the report as an issue in the report repository and returns its
+// URL, or returns "" when no report repository is configured.
+func (m *InactivityModule) openReportIssue(ctx context.Context, report *InactivityReport) (string, error) {
+	if m.config.ReportRepo == "" {
+		return "", nil
+	}
+	title := fmt.Sprintf("Maintainer activity report for %s", report.Quarter)
+	body := report.Markdown(m.config.InactiveAfterDays)
+	if m.app.GitHubClient == nil {
+		slog.Info("GitHub issue would be opened (no GitHub client available)",
+			"repo", m.config.ReportRepo, "title", title, "body", body)
+		return "", nil
+	}
+	owner, name, err := internal.SplitRepo(m.config.ReportRepo)
+	if err != nil {
+		return "", err
+	}
+	issue, _, err := m.app.GitHubClient.Issues.Create(ctx, owner, name, &github.IssueRequest{
+		Title:  github.Ptr(title),
+		Body:   github.Ptr(body),
+		Labels: &m.config.ReportLabels,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to open report issue in %s: %w", m.config.ReportRepo, err)
+	}
+	return issue.GetHTMLURL(), nil
+}
+
+// handlePreview renders the report as it would be posted now, without posting it.
+func (m *InactivityModule) handlePreview(w http.ResponseWriter, r *http.Request) {
+	report, err := m.BuildReport(r.Context())
+	if err != nil {
+		slog.Error("Failed to build inactivity report", "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+	fmt.Fprintf(w, "## Maintainer activity report for %s\n\n%s", report.Quarter,
+		report.Markdown(m.config.InactiveAfterDays))
+}
@@ -0,0 +1,100 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+// Activity kinds tracked for maintainers and approvers.
+const (
+	ActivityReview  = "review"
+	ActivityCommit  = "commit"
+	ActivityComment = "comment"
+)
+
+func AutoMigrateInactivity(db *sql.DB) error {
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS maintainer_activity (
+			login TEXT NOT NULL,
+			kind TEXT NOT NULL,
+			repo TEXT NOT NULL,
+			last_at TIMESTAMP NOT NULL,
+			PRIMARY KEY (login, kind)
+		);`,
+		`CREATE TABLE IF NOT EXISTS inactivity_reports (
+			quarter TEXT PRIMARY KEY,
+			issue_url TEXT,
+			posted_at TIMESTAMP NOT NULL
+		);`,
+	}
+	for _, s := range stmts {
+		if _, err := db.Exec(s); err != nil {
+			return fmt.Errorf("failed migration: %w (SQL: %s)", err, s)
+		}
+	}
+	return nil
+}
+
+// Migrate implements the ModuleMigrator interface.
+func (m *InactivityModule) Migrate(db *sql.DB) error {
+	return AutoMigrateInactivity(db)
+}
+
+// RecordActivity moves a person's last activity of a kind forward to at.
+func RecordActivity(db *sql.DB, login, kind, repo string, at time.Time) error {
+	_, err := db.Exec(
+		`INSERT INTO maintainer_activity (login, kind, repo, last_at) VALUES (?, ?, ?, ?)
+		 ON CONFLICT (login, kind) DO UPDATE SET repo = excluded.repo, last_at = excluded.last_at
+		 WHERE excluded.last_at > maintainer_activity.last_at`,
+		login, kind, repo, at,
+	)
+	return err
+}
+
+// GetActivity returns a person's last activity time by kind.
+func GetActivity(db *sql.DB, login string) (map[string]time.Time, error) {
+	rows, err := db.Query(`SELECT kind, last_at FROM maintainer_activity WHERE login = ?`, login)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	activity := make(map[string]time.Time)
+	for rows.Next() {
+		var (
+			kind string
+			at   time.Time
+		)
+		if err := rows.Scan(&kind, &at); err != nil {
+			return nil, err
+		}
+		activity[kind] = at
+	}
+	return activity, rows.Err()
+}
+
+// InactivityReportPosted reports whether the report for a quarter was posted.
+func InactivityReportPosted(db *sql.DB, quarter string) (bool, error) {
+	var n int
+	err := db.QueryRow(`SELECT COUNT(*) FROM inactivity_reports WHERE quarter = ?`, quarter).Scan(&n)
+	return n > 0, err
+}
+
+// RecordInactivityReport marks the report for a quarter as posted.
+func RecordInactivityReport(db *sql.DB, quarter, issueURL string, postedAt time.Time) error {
+	_, err := db.Exec(
+		`INSERT OR REPLACE INTO inactivity_reports (quarter, issue_url, posted_at) VALUES (?, ?, ?)`,
+		quarter, issueURL, postedAt,
+	)
+	return err
+}
+
+// RenameRepo implements the ModuleRepoRenamer interface.
+func (m *InactivityModule) RenameRepo(ctx context.Context, from, to string) error {
+	return internal.RenameRepoIn(ctx, m.database.DB(), from, to, "maintainer_activity.repo")
+}
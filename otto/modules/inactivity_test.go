@@ -0,0 +1,167 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v71/github"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+var inactivityNow = time.Date(2025, time.July, 2, 12, 0, 0, 0, time.UTC)
+
+func newInactivityTestModule(t *testing.T, fake *fakeGitHub) *InactivityModule {
+	t.Helper()
+	db := internal.TestDB(t)
+	events, err := internal.NewEventStore(db)
+	if err != nil {
+		t.Fatalf("NewEventStore failed: %v", err)
+	}
+	if err := AutoMigrateInactivity(db); err != nil {
+		t.Fatalf("AutoMigrateInactivity failed: %v", err)
+	}
+	return &InactivityModule{
+		app:      &internal.App{GitHubClient: fake.client(t), Events: events},
+		database: internal.NewDatabaseFromDB(db),
+		config: InactivityConfig{
+			Repos:             []string{"org/repo"},
+			Files:             []string{".github/CODEOWNERS", ".github/component_owners.yml"},
+			InactiveAfterDays: 90,
+			Ignore:            []string{"@frank"},
+			ReportRepo:        "org/community",
+			ReportLabels:      []string{"emeritus-review"},
+		},
+		now: func() time.Time { return inactivityNow },
+	}
+}
+
+func TestParseOwnershipFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    []string
+	}{
+		{
+			name: "codeowners",
+			file: ".github/CODEOWNERS",
+			content: "# Global owners\n* @alice @org/approvers\n\n/docs/ @bob docs@example.com # docs\n" +
+				"/exporter/ @alice\n/unowned/\n",
+			want: []string{"alice", "org/approvers", "bob"},
+		},
+		{
+			name:    "component owners",
+			file:    ".github/component_owners.yml",
+			content: "components:\n  receiver/otlp: [\"@carol\", alice]\n  exporter/prometheus:\n    - dave\n",
+			want:    []string{"dave", "carol", "alice"},
+		},
+		{name: "invalid yaml", file: "owners.yaml", content: "components: [", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseOwnershipFile(tt.file, tt.content); !slices.Equal(got, tt.want) {
+				t.Errorf("parseOwnershipFile() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInactivityReport(t *testing.T) {
+	fake := newFakeGitHub()
+	fake.setFile("org/repo", fakeDefaultBranch, ".github/CODEOWNERS",
+		"* @alice @org/approvers\n/docs/ @bob @frank\n")
+	fake.setFile("org/repo", fakeDefaultBranch, ".github/component_owners.yml",
+		"components:\n  receiver/otlp: [carol, alice]\n")
+	fake.setTeam("org/approvers", "dave", "erin", "renovate[bot]")
+	mod := newInactivityTestModule(t, fake)
+	db := mod.database.DB()
+
+	// alice is seen by the module, bob only in the event store, carol only through search.
+	comment := commentEvent("org/repo", 1, "Alice", "LGTM")
+	comment.Sender = &github.User{Login: github.Ptr("Alice")}
+	if err := mod.HandleEvent("issue_comment", comment, nil); err != nil {
+		t.Fatalf("HandleEvent failed: %v", err)
+	}
+	review := internal.NewStoredEvent("d", "pull_request_review",
+		[]byte(`{"action":"submitted","repository":{"full_name":"org/repo"},"sender":{"login":"bob"}}`))
+	review.ReceivedAt = inactivityNow.AddDate(0, 0, -10)
+	if _, err := mod.app.Events.Record(t.Context(), review); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	fake.involve("org/other", 5, inactivityNow.AddDate(0, 0, -20), "commenter:carol")
+	// dave was last active before the cutoff; erin has never been seen.
+	if err := RecordActivity(db, "dave", ActivityReview, "org/repo", inactivityNow.AddDate(0, 0, -100)); err != nil {
+		t.Fatalf("RecordActivity failed: %v", err)
+	}
+
+	report, err := mod.BuildReport(t.Context())
+	if err != nil {
+		t.Fatalf("BuildReport failed: %v", err)
+	}
+	var logins, inactive []string
+	for _, p := range report.People {
+		logins = append(logins, p.Login)
+		if p.Inactive {
+			inactive = append(inactive, p.Login)
+		}
+	}
+	if !slices.Equal(logins, []string{"alice", "bob", "carol", "dave", "erin"}) {
+		t.Errorf("people = %v", logins)
+	}
+	if !slices.Equal(inactive, []string{"dave", "erin"}) {
+		t.Errorf("inactive = %v, want [dave erin]", inactive)
+	}
+	if queries := strings.Join(fake.searchQueries(), "\n"); !strings.Contains(queries,
+		"org:org commenter:carol updated:>=2025-04-03") || strings.Contains(queries, "alice") {
+		t.Errorf("unexpected searches:\n%s", queries)
+	}
+
+	markdown := report.Markdown(90)
+	for _, want := range []string{
+		"2 of 5 people listed in ownership files have had no review, commit or comment activity in the last 90 days",
+		"| @erin | org/repo .github/CODEOWNERS via @org/approvers | never seen | never seen | never seen |",
+		"| @dave | org/repo .github/CODEOWNERS via @org/approvers | 2025-03-24 | never seen | never seen |",
+	} {
+		if !strings.Contains(markdown, want) {
+			t.Errorf("markdown missing %q:\n%s", want, markdown)
+		}
+	}
+	if strings.Index(markdown, "@erin") > strings.Index(markdown, "@dave") {
+		t.Error("people never seen should be listed first")
+	}
+}
+
+func TestPostQuarterlyReport(t *testing.T) {
+	fake := newFakeGitHub()
+	fake.setFile("org/repo", fakeDefaultBranch, ".github/CODEOWNERS", "* @alice\n")
+	mod := newInactivityTestModule(t, fake)
+
+	for range 2 {
+		if err := mod.PostQuarterlyReport(t.Context()); err != nil {
+			t.Fatalf("PostQuarterlyReport failed: %v", err)
+		}
+	}
+	opened := fake.openedIssues("org/community")
+	if len(opened) != 1 {
+		t.Fatalf("opened %d report issues, want 1", len(opened))
+	}
+	if opened[0].GetTitle() != "Maintainer activity report for 2025-Q3" ||
+		!slices.Equal(opened[0].GetLabels(), []string{"emeritus-review"}) ||
+		!strings.Contains(opened[0].GetBody(), "| @alice |") {
+		t.Errorf("unexpected report issue: %+v", opened[0])
+	}
+
+	// The next quarter gets its own report.
+	mod.now = func() time.Time { return inactivityNow.AddDate(0, 3, 0) }
+	if err := mod.PostQuarterlyReport(t.Context()); err != nil {
+		t.Fatalf("PostQuarterlyReport failed: %v", err)
+	}
+	if got := len(fake.openedIssues("org/community")); got != 2 {
+		t.Errorf("opened %d report issues after a new quarter, want 2", got)
+	}
+}
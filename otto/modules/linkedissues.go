@@ -0,0 +1,206 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"regexp"
+
+	"github.com/google/go-github/v71/github"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+// linkedIssueCommentKey identifies the managed instructions comment on a pull request.
+const linkedIssueCommentKey = "linked-issue"
+
+// closingIssueRegexp is the compiled closingIssuePattern.
+var closingIssueRegexp = regexp.MustCompile(closingIssuePattern)
+
+// LinkedIssueConfig configures linked-issue enforcement.
+type LinkedIssueConfig struct {
+	CheckName   string   `yaml:"check_name" doc:"name of the check run"`
+	ExemptLabel string   `yaml:"exempt_label" doc:"PRs with this label need no linked issue"`
+	Repos       []string `yaml:"repos" doc:"repos that enforce the rule; empty means all"`
+}
+
+// LinkedIssueModule requires pull requests to reference the issue they address.
+type LinkedIssueModule struct {
+	app    *internal.App
+	config LinkedIssueConfig
+}
+
+func (m *LinkedIssueModule) Name() string { return "linkedissues" }
+
+// ConfigSchema implements the ModuleConfigSchema interface.
+func (m *LinkedIssueModule) ConfigSchema() any {
+	c := defaultLinkedIssueConfig()
+	return &c
+}
+
+// defaultLinkedIssueConfig returns the linkedissues module's defaults.
+func defaultLinkedIssueConfig() LinkedIssueConfig {
+	return LinkedIssueConfig{
+		CheckName:   "otto/linked-issue",
+		ExemptLabel: "trivial",
+	}
+}
+
+// EventSubscriptions implements the ModuleEventSubscriber interface.
+func (m *LinkedIssueModule) EventSubscriptions() []internal.EventSubscription {
+	return []internal.EventSubscription{
+		internal.Subscribe("pull_request", "opened", "edited", "reopened", "synchronize", "labeled", "unlabeled"),
+	}
+}
+
+// GitHubPermissions implements the ModulePermissionRequirer interface.
+func (m *LinkedIssueModule) GitHubPermissions() map[string]string {
+	return map[string]string{"checks": "write", "issues": "write", "pull_requests": "read"}
+}
+
+// Initialize implements the ModuleInitializer interface.
+func (m *LinkedIssueModule) Initialize(ctx context.Context, app *internal.App) error {
+	m.app = app
+	m.config = defaultLinkedIssueConfig()
+	if err := loadModuleConfig(app, m.Name(), &m.config); err != nil {
+		return err
+	}
+	return checkRepos(app, m.Name(), m.config.Repos)
+}
+
+func (m *LinkedIssueModule) HandleEvent(eventType string, event any, raw json.RawMessage) error {
+	return m.HandleEventContext(context.Background(), eventType, event, raw)
+}
+
+// HandleEventContext implements the ModuleContextHandler interface.
+func (m *LinkedIssueModule) HandleEventContext(ctx context.Context, eventType string, event any,
+	raw json.RawMessage) error {
+	if eventType != "pull_request" {
+		return nil
+	}
+	prEvent, ok := event.(*github.PullRequestEvent)
+	if !ok {
+		return nil
+	}
+	switch prEvent.GetAction() {
+	case "opened", "edited", "reopened", "synchronize", "labeled", "unlabeled":
+	default:
+		return nil
+	}
+	repo := prEvent.GetRepo().GetFullName()
+	if !reposInclude(m.app, m.config.Repos, repo) {
+		internal.AddDecision(ctx, "skipped: repository is not in the configured repos")
+		return nil
+	}
+	if m.app == nil || m.app.GitHubClient == nil {
+		slog.Info("Linked-issue check skipped (no GitHub client available)",
+			"repo", repo, "pr", prEvent.GetPullRequest().GetNumber())
+		return nil
+	}
+
+	if err := m.enforce(ctx, repo, prEvent.GetPullRequest()); err != nil {
+		return internal.LogAndWrapError(err, internal.ErrorTypeModule, "linked_issue", map[string]any{
+			"repo": repo,
+			"pr":   prEvent.GetPullRequest().GetNumber(),
+		})
+	}
+	return nil
+}
+
+// enforce publishes the linked-issue check and keeps the instructions comment current.
+func (m *LinkedIssueModule) enforce(ctx context.Context, repo string, pr *github.PullRequest) error {
+	client := m.app.GitHubClient
+	run := internal.CheckRun{Name: m.config.CheckName, HeadSHA: pr.GetHead().GetSHA()}
+
+	exempt := m.config.ExemptLabel != "" && hasLabel(pr.Labels, m.config.ExemptLabel)
+	linked := false
+	if !exempt {
+		var err error
+		if linked, err = m.hasLinkedIssue(ctx, repo, pr); err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case exempt:
+		internal.AddDecision(ctx, fmt.Sprintf("passed: the %s label exempts the pull request", m.config.ExemptLabel))
+		run.Status, run.Conclusion = internal.CheckStatusCompleted, internal.CheckConclusionSuccess
+		run.Title = "Exempt from linked-issue requirement"
+		run.Summary = fmt.Sprintf("The `%s` label is applied.", m.config.ExemptLabel)
+	case linked:
+		internal.AddDecision(ctx, "passed: the pull request references an issue")
+		run.Status, run.Conclusion = internal.CheckStatusCompleted, internal.CheckConclusionSuccess
+		run.Title = "Linked issue found"
+		run.Summary = "This pull request references the issue it addresses."
+	default:
+		internal.AddDecision(ctx, "pending: no closing keyword in the description and no linked issue")
+		// Left in progress so the check blocks merging when required by branch protection.
+		run.Status = internal.CheckStatusInProgress
+		run.Title = "Waiting for a linked issue"
+		run.Summary = m.instructions()
+	}
+	if err := internal.PublishCheckRun(ctx, client, repo, run); err != nil {
+		return err
+	}
+
+	if !exempt && !linked {
+		_, err := internal.UpsertManagedComment(ctx, client, repo, pr.GetNumber(), linkedIssueCommentKey,
+			"⚠️ "+m.instructions())
+		return err
+	}
+	// Only resolve an instructions comment that was posted earlier; satisfied PRs get no new noise.
+	existing, err := internal.FindManagedComment(ctx, client, repo, pr.GetNumber(), linkedIssueCommentKey)
+	if err != nil || existing == nil {
+		return err
+	}
+	_, err = internal.UpsertManagedComment(ctx, client, repo, pr.GetNumber(), linkedIssueCommentKey,
+		"✅ "+run.Title+". Thanks!")
+	return err
+}
+
+// hasLinkedIssue reports whether the PR body uses a closing keyword or the PR was
+// linked to an issue from the sidebar (a "connected" timeline event).
+func (m *LinkedIssueModule) hasLinkedIssue(ctx context.Context, repo string, pr *github.PullRequest) (bool, error) {
+	if closingIssueRegexp.MatchString(pr.GetBody()) {
+		return true, nil
+	}
+
+	owner, name, err := internal.SplitRepo(repo)
+	if err != nil {
+		return false, err
+	}
+	connected := 0
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		events, resp, err := m.app.GitHubClient.Issues.ListIssueTimeline(ctx, owner, name, pr.GetNumber(), opts)
+		if err != nil {
+			return false, fmt.Errorf("failed to list timeline: %w", err)
+		}
+		for _, e := range events {
+			switch e.GetEvent() {
+			case "connected":
+				connected++
+			case "disconnected":
+				connected--
+			}
+		}
+		if resp == nil || resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return connected > 0, nil
+}
+
+// instructions explains how to satisfy the rule.
+func (m *LinkedIssueModule) instructions() string {
+	msg := "This pull request does not reference an issue. Add `Fixes #<issue>` (or `Closes`/`Resolves`) " +
+		"to the description, or link an issue from the Development section of the sidebar."
+	if m.config.ExemptLabel != "" {
+		msg += fmt.Sprintf(" Maintainers can apply the `%s` label to exempt trivial changes.", m.config.ExemptLabel)
+	}
+	return msg
+}
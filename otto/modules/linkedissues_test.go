@@ -0,0 +1,69 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+func TestLinkedIssueEnforcement(t *testing.T) {
+	fake := newFakeGitHub()
+	mod := &LinkedIssueModule{}
+	if err := mod.Initialize(t.Context(), &internal.App{GitHubClient: fake.client(t)}); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	handle := func(action string, number int, body string, labels ...string) {
+		t.Helper()
+		if err := mod.HandleEvent("pull_request", pullRequestEvent(action, "org/repo", number, body, labels...), nil); err != nil {
+			t.Fatalf("HandleEvent failed: %v", err)
+		}
+	}
+	lastRun := func() (status, conclusion string) {
+		runs := fake.checkRunsFor("org/repo")
+		run := runs[len(runs)-1]
+		return run.GetStatus(), run.GetConclusion()
+	}
+
+	// Missing link: pending check plus instructions.
+	handle("opened", 1, "Small refactor")
+	if status, _ := lastRun(); status != "in_progress" {
+		t.Errorf("status = %q, want in_progress", status)
+	}
+	comments := fake.commentsOn("org/repo", 1)
+	if len(comments) != 1 || !strings.Contains(comments[0], "does not reference an issue") {
+		t.Fatalf("unexpected comments: %v", comments)
+	}
+
+	// Editing the description to add a closing keyword resolves the check and the comment.
+	handle("edited", 1, "Small refactor\n\nFixes #42")
+	if status, conclusion := lastRun(); status != "completed" || conclusion != "success" {
+		t.Errorf("check = %s/%s, want completed/success", status, conclusion)
+	}
+	comments = fake.commentsOn("org/repo", 1)
+	if len(comments) != 1 || !strings.Contains(comments[0], "Linked issue found") {
+		t.Errorf("instructions comment not resolved: %v", comments)
+	}
+
+	// Issues linked from the sidebar count, and satisfied PRs get no comment.
+	fake.addTimelineEvent("org/repo", 2, "connected")
+	handle("opened", 2, "No keyword here")
+	if _, conclusion := lastRun(); conclusion != "success" {
+		t.Errorf("sidebar-linked PR conclusion = %q, want success", conclusion)
+	}
+	if comments := fake.commentsOn("org/repo", 2); len(comments) != 0 {
+		t.Errorf("unexpected comments on linked PR: %v", comments)
+	}
+
+	// The exemption label skips the requirement.
+	handle("labeled", 3, "Typo", "trivial")
+	if _, conclusion := lastRun(); conclusion != "success" {
+		t.Errorf("exempt PR conclusion = %q, want success", conclusion)
+	}
+	if comments := fake.commentsOn("org/repo", 3); len(comments) != 0 {
+		t.Errorf("unexpected comments on exempt PR: %v", comments)
+	}
+}
@@ -0,0 +1,17 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"context"
+)
+
+// MergeGate is implemented by modules that can keep a pull request from being merged
+// automatically, e.g. until it is approved. Otto does not merge pull requests itself yet;
+// whatever does must ask the gates of the modules enabled for the repository (see
+// App.ModuleEnabled), and merge only when none of them returns a reason.
+type MergeGate interface {
+	// MergeBlockers returns why the pull request must not be merged yet, or nothing if
+	// the gate lets it through.
+	MergeBlockers(ctx context.Context, repo string, number int) ([]string, error)
+}
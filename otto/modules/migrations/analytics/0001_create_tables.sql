@@ -0,0 +1,11 @@
+-- SPDX-License-Identifier: Apache-2.0
+
+-- Slash commands used per week (starting on Monday, UTC), repository and role of the user.
+CREATE TABLE IF NOT EXISTS command_usage (
+	week TEXT NOT NULL,
+	repo TEXT NOT NULL,
+	command TEXT NOT NULL,
+	role TEXT NOT NULL,
+	count INTEGER NOT NULL,
+	PRIMARY KEY (week, repo, command, role)
+);
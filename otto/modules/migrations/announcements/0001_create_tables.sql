@@ -0,0 +1,34 @@
+-- SPDX-License-Identifier: Apache-2.0
+
+-- Announcements broadcast across repositories through the admin API.
+CREATE TABLE IF NOT EXISTS announcements (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	title TEXT NOT NULL,
+	body TEXT NOT NULL,
+	kind TEXT NOT NULL,
+	category TEXT NOT NULL DEFAULT '',
+	pin INTEGER NOT NULL DEFAULT 0,
+	labels TEXT NOT NULL,
+	vars TEXT NOT NULL,
+	created_by TEXT NOT NULL DEFAULT '',
+	status TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_announcements_status ON announcements (status);
+
+-- The rendered post of an announcement in each repository it is broadcast to.
+CREATE TABLE IF NOT EXISTS announcement_posts (
+	announcement_id INTEGER NOT NULL REFERENCES announcements (id),
+	repo TEXT NOT NULL,
+	title TEXT NOT NULL,
+	body TEXT NOT NULL,
+	status TEXT NOT NULL,
+	number INTEGER NOT NULL DEFAULT 0,
+	node_id TEXT NOT NULL DEFAULT '',
+	url TEXT NOT NULL DEFAULT '',
+	error TEXT NOT NULL DEFAULT '',
+	updated_at TIMESTAMP NOT NULL,
+	PRIMARY KEY (announcement_id, repo)
+);
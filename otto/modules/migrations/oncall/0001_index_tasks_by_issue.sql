@@ -0,0 +1,4 @@
+-- SPDX-License-Identifier: Apache-2.0
+
+-- Tasks are looked up by issue when issues close, reopen or get a reaction.
+CREATE INDEX IF NOT EXISTS idx_oncall_tasks_issue ON oncall_tasks (repo, issue_num);
@@ -0,0 +1,4 @@
+-- SPDX-License-Identifier: Apache-2.0
+
+-- Unacknowledged tasks are escalated once; escalated_at records when.
+ALTER TABLE oncall_tasks ADD COLUMN escalated_at TIMESTAMP;
@@ -0,0 +1,19 @@
+-- SPDX-License-Identifier: Apache-2.0
+
+-- Questions already posted, so that none is posted twice.
+CREATE TABLE IF NOT EXISTS stackoverflow_questions (
+	site TEXT NOT NULL,
+	question_id INTEGER NOT NULL,
+	title TEXT NOT NULL,
+	link TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	posted_at TIMESTAMP NOT NULL,
+	PRIMARY KEY (site, question_id)
+);
+
+-- The creation time from which questions are fetched, per site.
+CREATE TABLE IF NOT EXISTS stackoverflow_state (
+	site TEXT PRIMARY KEY,
+	fetch_from TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL
+);
@@ -0,0 +1,29 @@
+-- SPDX-License-Identifier: Apache-2.0
+
+-- Issues whose task list and sub-issues are tracked, with the last progress summary posted.
+CREATE TABLE IF NOT EXISTS tracking_issues (
+	repo TEXT NOT NULL,
+	issue_num INTEGER NOT NULL,
+	summary TEXT NOT NULL DEFAULT '',
+	updated_at TIMESTAMP NOT NULL,
+	PRIMARY KEY (repo, issue_num)
+);
+
+-- Task list items and sub-issues of tracked issues. changed_at is when the item was last
+-- checked, unchecked or given other owners; ref_repo and ref_num identify a sub-issue.
+CREATE TABLE IF NOT EXISTS tracking_items (
+	repo TEXT NOT NULL,
+	issue_num INTEGER NOT NULL,
+	item_key TEXT NOT NULL,
+	position INTEGER NOT NULL,
+	text TEXT NOT NULL,
+	owners TEXT NOT NULL DEFAULT '',
+	done BOOLEAN NOT NULL DEFAULT FALSE,
+	ref_repo TEXT NOT NULL DEFAULT '',
+	ref_num INTEGER NOT NULL DEFAULT 0,
+	changed_at TIMESTAMP NOT NULL,
+	pinged_at TIMESTAMP,
+	PRIMARY KEY (repo, issue_num, item_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_tracking_items_ref ON tracking_items (ref_repo, ref_num);
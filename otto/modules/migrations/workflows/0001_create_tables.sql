@@ -0,0 +1,17 @@
+-- SPDX-License-Identifier: Apache-2.0
+
+-- Every /trigger-workflow request, whether it was dispatched, denied or failed.
+CREATE TABLE IF NOT EXISTS workflow_triggers (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	repo TEXT NOT NULL,
+	issue_num INTEGER NOT NULL,
+	user TEXT NOT NULL,
+	name TEXT NOT NULL,
+	target_repo TEXT NOT NULL,
+	inputs TEXT NOT NULL,
+	outcome TEXT NOT NULL,
+	error TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_workflow_triggers_created ON workflow_triggers (created_at);
@@ -0,0 +1,331 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v71/github"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+// OnboardingConfig describes the baseline applied to newly onboarded repositories.
+type OnboardingConfig struct {
+	Labels   []LabelSpec  `yaml:"labels" doc:"labels created in onboarded repositories"`
+	Settings RepoSettings `yaml:"settings" doc:"repository settings applied; unset fields are left unchanged"`
+	Modules  []string     `yaml:"modules" doc:"modules enabled for the repo; empty means all registered modules"`
+}
+
+// LabelSpec is a standard label created in onboarded repositories.
+type LabelSpec struct {
+	Name        string `yaml:"name" doc:"label name"`
+	Color       string `yaml:"color" doc:"hex without '#'"`
+	Description string `yaml:"description" doc:"label description"`
+}
+
+// RepoSettings is the repository settings policy. Unset fields are left unchanged.
+type RepoSettings struct {
+	HasWiki             *bool `yaml:"has_wiki" doc:"enable the wiki"`
+	HasProjects         *bool `yaml:"has_projects" doc:"enable projects"`
+	DeleteBranchOnMerge *bool `yaml:"delete_branch_on_merge" doc:"delete head branches after merge"`
+	AllowSquashMerge    *bool `yaml:"allow_squash_merge" doc:"allow squash merging"`
+	AllowMergeCommit    *bool `yaml:"allow_merge_commit" doc:"allow merge commits"`
+	AllowRebaseMerge    *bool `yaml:"allow_rebase_merge" doc:"allow rebase merging"`
+}
+
+// OnboardResult reports what onboarding changed.
+type OnboardResult struct {
+	Repo            string
+	LabelsCreated   []string
+	LabelsUpdated   []string
+	SettingsApplied bool
+	Modules         []string
+}
+
+// onboardAssociations are the author associations allowed to run /otto onboard.
+var onboardAssociations = map[string]bool{
+	"OWNER":  true,
+	"MEMBER": true,
+}
+
+// defaultOnboardingLabels is the standard SIG label set.
+var defaultOnboardingLabels = []LabelSpec{
+	{Name: "bug", Color: "d73a4a", Description: "Something isn't working"},
+	{Name: "enhancement", Color: "a2eeef", Description: "New feature or request"},
+	{Name: "documentation", Color: "0075ca", Description: "Improvements or additions to documentation"},
+	{Name: "good first issue", Color: "7057ff", Description: "Good for newcomers"},
+	{Name: "help wanted", Color: "008672", Description: "Extra attention is needed"},
+	{Name: "waiting-for-author", Color: "fbca04", Description: "Waiting on a response from the author"},
+	{Name: "needs-maintainer-response", Color: "d93f0b", Description: "Waiting on a response from maintainers"},
+}
+
+// OnboardingModule bootstraps repositories with `/otto onboard`.
+type OnboardingModule struct {
+	app    *internal.App
+	config OnboardingConfig
+}
+
+func (m *OnboardingModule) Name() string { return "onboarding" }
+
+// ConfigSchema implements the ModuleConfigSchema interface.
+func (m *OnboardingModule) ConfigSchema() any {
+	c := defaultOnboardingConfig()
+	return &c
+}
+
+// defaultOnboardingConfig returns the onboarding module's defaults.
+func defaultOnboardingConfig() OnboardingConfig {
+	return OnboardingConfig{
+		Labels: defaultOnboardingLabels,
+		Settings: RepoSettings{
+			DeleteBranchOnMerge: github.Ptr(true),
+			AllowSquashMerge:    github.Ptr(true),
+			AllowMergeCommit:    github.Ptr(false),
+			AllowRebaseMerge:    github.Ptr(false),
+		},
+	}
+}
+
+// EventSubscriptions implements the ModuleEventSubscriber interface. The module consumes
+// no events: the app hands it `/otto onboard` commands through HandleCommand.
+func (m *OnboardingModule) EventSubscriptions() []internal.EventSubscription {
+	return nil
+}
+
+// GitHubPermissions implements the ModulePermissionRequirer interface.
+func (m *OnboardingModule) GitHubPermissions() map[string]string {
+	return map[string]string{"administration": "write", "issues": "write"}
+}
+
+// SlashCommands implements the ModuleCommander interface.
+func (m *OnboardingModule) SlashCommands() []string {
+	return []string{"otto onboard"}
+}
+
+// Initialize implements the ModuleInitializer interface.
+func (m *OnboardingModule) Initialize(ctx context.Context, app *internal.App) error {
+	m.app = app
+	m.config = defaultOnboardingConfig()
+	if err := loadModuleConfig(app, m.Name(), &m.config); err != nil {
+		return err
+	}
+	if app.Repos == nil {
+		return errors.New("onboarding: repository registry is not available")
+	}
+
+	app.HandleAdmin("GET /admin/repos", m.handleListRepos)
+	app.HandleAdmin("POST /admin/repos/{owner}/{repo}/onboard", m.handleOnboard)
+	return nil
+}
+
+func (m *OnboardingModule) HandleEvent(eventType string, event any, raw json.RawMessage) error {
+	return nil
+}
+
+// HandleCommand implements the ModuleCommandHandler interface.
+func (m *OnboardingModule) HandleCommand(cmd *internal.CommandContext) error {
+	ctx := cmd.Context
+	repo := cmd.Repo
+	issue := cmd.IssueNum
+	user := cmd.Issuer
+
+	if !onboardAssociations[cmd.Event.GetComment().GetAuthorAssociation()] {
+		m.comment(ctx, repo, issue, fmt.Sprintf("⚠️ @%s only organization members can run `/otto onboard`.", user))
+		return nil
+	}
+
+	result, err := m.Onboard(ctx, repo, user)
+	if err != nil {
+		m.comment(ctx, repo, issue, fmt.Sprintf("❌ Onboarding failed: %v", err))
+		return internal.LogAndWrapError(err, internal.ErrorTypeModule, "onboard", map[string]any{"repo": repo})
+	}
+	m.comment(ctx, repo, issue, result.Markdown())
+	return nil
+}
+
+// Onboard creates standard labels, applies the settings policy, and registers
+// the repository with its default module set. It is safe to run repeatedly.
+func (m *OnboardingModule) Onboard(ctx context.Context, repo, by string) (*OnboardResult, error) {
+	owner, name, err := internal.SplitRepo(repo)
+	if err != nil {
+		return nil, err
+	}
+	result := &OnboardResult{Repo: repo, Modules: m.defaultModules()}
+
+	if m.app.GitHubClient == nil {
+		slog.Info("Skipping label and settings sync (no GitHub client available)", "repo", repo)
+	} else {
+		if err := m.syncLabels(ctx, owner, name, result); err != nil {
+			return nil, err
+		}
+		if err := m.applySettings(ctx, owner, name, result); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := m.app.Repos.Register(ctx, repo, by, result.Modules); err != nil {
+		return nil, err
+	}
+	slog.Info("Repository onboarded", "repo", repo, "by", by, "modules", result.Modules)
+	return result, nil
+}
+
+// defaultModules returns the configured module set, always including this module
+// so the repository can be re-onboarded.
+func (m *OnboardingModule) defaultModules() []string {
+	modules := slices.Clone(m.config.Modules)
+	if len(modules) == 0 {
+		for name := range m.app.GetModules() {
+			modules = append(modules, name)
+		}
+	}
+	if !slices.Contains(modules, m.Name()) {
+		modules = append(modules, m.Name())
+	}
+	sort.Strings(modules)
+	return modules
+}
+
+// syncLabels creates missing standard labels and updates ones whose color or description differ.
+func (m *OnboardingModule) syncLabels(ctx context.Context, owner, name string, result *OnboardResult) error {
+	existing := map[string]*github.Label{}
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		labels, resp, err := m.app.GitHubClient.Issues.ListLabels(ctx, owner, name, opts)
+		if err != nil {
+			return fmt.Errorf("failed to list labels: %w", err)
+		}
+		for _, l := range labels {
+			existing[strings.ToLower(l.GetName())] = l
+		}
+		if resp == nil || resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	for _, spec := range m.config.Labels {
+		want := &github.Label{
+			Name:        github.Ptr(spec.Name),
+			Color:       github.Ptr(spec.Color),
+			Description: github.Ptr(spec.Description),
+		}
+		current, ok := existing[strings.ToLower(spec.Name)]
+		if !ok {
+			if _, _, err := m.app.GitHubClient.Issues.CreateLabel(ctx, owner, name, want); err != nil {
+				return fmt.Errorf("failed to create label %q: %w", spec.Name, err)
+			}
+			result.LabelsCreated = append(result.LabelsCreated, spec.Name)
+			continue
+		}
+		if strings.EqualFold(current.GetColor(), spec.Color) && current.GetDescription() == spec.Description {
+			continue
+		}
+		if _, _, err := m.app.GitHubClient.Issues.EditLabel(ctx, owner, name, current.GetName(), want); err != nil {
+			return fmt.Errorf("failed to update label %q: %w", spec.Name, err)
+		}
+		result.LabelsUpdated = append(result.LabelsUpdated, spec.Name)
+	}
+	return nil
+}
+
+// applySettings applies the configured repository settings policy.
+func (m *OnboardingModule) applySettings(ctx context.Context, owner, name string, result *OnboardResult) error {
+	s := m.config.Settings
+	if s == (RepoSettings{}) {
+		return nil
+	}
+	_, _, err := m.app.GitHubClient.Repositories.Edit(ctx, owner, name, &github.Repository{
+		HasWiki:             s.HasWiki,
+		HasProjects:         s.HasProjects,
+		DeleteBranchOnMerge: s.DeleteBranchOnMerge,
+		AllowSquashMerge:    s.AllowSquashMerge,
+		AllowMergeCommit:    s.AllowMergeCommit,
+		AllowRebaseMerge:    s.AllowRebaseMerge,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to apply repository settings: %w", err)
+	}
+	result.SettingsApplied = true
+	return nil
+}
+
+// Markdown renders the onboarding result as a GitHub comment.
+func (r *OnboardResult) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "✅ **%s** is onboarded.\n\n", r.Repo)
+	writeList := func(label string, items []string) {
+		if len(items) == 0 {
+			fmt.Fprintf(&b, "- %s: none\n", label)
+			return
+		}
+		fmt.Fprintf(&b, "- %s: `%s`\n", label, strings.Join(items, "`, `"))
+	}
+	writeList("Labels created", r.LabelsCreated)
+	writeList("Labels updated", r.LabelsUpdated)
+	if r.SettingsApplied {
+		b.WriteString("- Repository settings policy applied\n")
+	}
+	writeList("Modules enabled", r.Modules)
+	return b.String()
+}
+
+// handleOnboard onboards a repository from the admin API.
+func (m *OnboardingModule) handleOnboard(w http.ResponseWriter, r *http.Request) {
+	repo := r.PathValue("owner") + "/" + r.PathValue("repo")
+	result, err := m.Onboard(r.Context(), repo, "admin")
+	if err != nil {
+		slog.Error("Failed to onboard repository", "repo", repo, "error", err)
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	writeJSON(w, map[string]any{
+		"repo":             result.Repo,
+		"labels_created":   result.LabelsCreated,
+		"labels_updated":   result.LabelsUpdated,
+		"settings_applied": result.SettingsApplied,
+		"modules":          result.Modules,
+	})
+}
+
+// handleListRepos lists registered repositories and their enabled modules.
+func (m *OnboardingModule) handleListRepos(w http.ResponseWriter, r *http.Request) {
+	repos, err := m.app.Repos.List(r.Context())
+	if err != nil {
+		slog.Error("Failed to list repositories", "error", err)
+		http.Error(w, "failed to list repositories", http.StatusInternalServerError)
+		return
+	}
+	out := make([]map[string]any, 0, len(repos))
+	for _, repo := range repos {
+		out = append(out, map[string]any{
+			"repo":         repo.FullName,
+			"onboarded_by": repo.OnboardedBy,
+			"onboarded_at": repo.OnboardedAt.UTC().Format(time.RFC3339),
+			"modules":      repo.Modules,
+		})
+	}
+	writeJSON(w, out)
+}
+
+// comment posts a plain comment, logging instead when no GitHub client is configured.
+func (m *OnboardingModule) comment(ctx context.Context, repo string, issue int, body string) {
+	if m.app == nil || m.app.GitHubClient == nil {
+		slog.Info("GitHub comment would be posted (no GitHub client available)",
+			"repo", repo, "issue", issue, "message", body)
+		return
+	}
+	if err := m.app.PostComment(ctx, m.Name(), repo, issue, body); err != nil {
+		slog.Error("Failed to post onboarding comment", "repo", repo, "issue", issue, "error", err)
+	}
+}
@@ -0,0 +1,77 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v71/github"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+func TestOnboardCommand(t *testing.T) {
+	db := openTestDB(t)
+	repos, err := internal.NewRepoRegistry(db)
+	if err != nil {
+		t.Fatalf("NewRepoRegistry failed: %v", err)
+	}
+	fake := newFakeGitHub()
+	app := &internal.App{
+		Database:       internal.NewDatabaseFromDB(db),
+		GitHubClient:   fake.client(t),
+		ModuleRegistry: internal.NewModuleRegistry(),
+		Repos:          repos,
+	}
+	mod := &OnboardingModule{}
+	app.RegisterModule(mod)
+	app.RegisterModule(&SLAModule{})
+	if err := mod.Initialize(t.Context(), app); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	// An existing label with a stale color is updated rather than duplicated.
+	fake.repoLabels["org/new"] = []*github.Label{{Name: github.Ptr("bug"), Color: github.Ptr("000000")}}
+
+	// Outside collaborators cannot onboard.
+	event := commentEvent("org/new", 1, "mallory", "/otto onboard")
+	event.Comment.AuthorAssociation = github.Ptr("CONTRIBUTOR")
+	if err := internal.TestCommands(t, mod, event); err != nil {
+		t.Fatalf("HandleCommand failed: %v", err)
+	}
+	if repo, _ := repos.Get(t.Context(), "org/new"); repo != nil {
+		t.Fatalf("repo onboarded by non-member")
+	}
+
+	event = commentEvent("org/new", 1, "alice", "/otto onboard")
+	event.Comment.AuthorAssociation = github.Ptr("MEMBER")
+	if err := internal.TestCommands(t, mod, event); err != nil {
+		t.Fatalf("HandleCommand failed: %v", err)
+	}
+
+	if bug := fake.repoLabel("org/new", "bug"); bug == nil || bug.GetColor() != "d73a4a" {
+		t.Errorf("bug label not updated: %+v", bug)
+	}
+	if len(fake.repoLabels["org/new"]) != len(defaultOnboardingLabels) {
+		t.Errorf("got %d labels, want %d", len(fake.repoLabels["org/new"]), len(defaultOnboardingLabels))
+	}
+	if s := fake.repoSettings("org/new"); s == nil || !s.GetDeleteBranchOnMerge() || s.GetAllowMergeCommit() {
+		t.Errorf("settings policy not applied: %+v", s)
+	}
+
+	repo, err := repos.Get(t.Context(), "org/new")
+	if err != nil || repo == nil {
+		t.Fatalf("repo not registered: %v", err)
+	}
+	if repo.OnboardedBy != "alice" || !slices.Equal(repo.Modules, []string{"onboarding", "sla"}) {
+		t.Errorf("unexpected registry entry: %+v", repo)
+	}
+
+	comments := fake.commentsOn("org/new", 1)
+	if len(comments) != 2 || !strings.Contains(comments[0], "only organization members") ||
+		!strings.Contains(comments[1], "is onboarded") {
+		t.Errorf("unexpected comments: %v", comments)
+	}
+}
@@ -25,40 +25,262 @@ var (
 type OnCallModule struct {
 	app      *internal.App
 	database *internal.Database
+	config   OnCallConfig
 }
 
 func (o *OnCallModule) Name() string { return "oncall" }
 
+// ConfigSchema implements the ModuleConfigSchema interface.
+func (o *OnCallModule) ConfigSchema() any {
+	c := defaultOnCallConfig()
+	return &c
+}
+
+// defaultOnCallConfig returns the oncall module's defaults.
+func defaultOnCallConfig() OnCallConfig {
+	return OnCallConfig{DefaultSchedule: "primary"}
+}
+
+// EventSubscriptions implements the ModuleEventSubscriber interface.
+func (o *OnCallModule) EventSubscriptions() []internal.EventSubscription {
+	return []internal.EventSubscription{
+		internal.Subscribe("issues", "closed", "reopened"),
+		internal.Subscribe("comment"), // `/ack` replies
+	}
+}
+
+// GitHubPermissions implements the ModulePermissionRequirer interface.
+func (o *OnCallModule) GitHubPermissions() map[string]string {
+	return map[string]string{"issues": "write"}
+}
+
+// SlashCommands implements the ModuleCommander interface.
+func (o *OnCallModule) SlashCommands() []string {
+	return []string{"oncall", "availability"}
+}
+
+// Rollups implements the ModuleRollupSource interface: the median, 90th and 99th
+// percentile time to acknowledge tasks, in seconds, and the number acknowledged.
+func (o *OnCallModule) Rollups(ctx context.Context, start, end time.Time) ([]internal.Rollup, error) {
+	latencies, err := ListAckLatencies(o.database.DB(), start, end)
+	if err != nil || len(latencies) == 0 {
+		return nil, err
+	}
+	seconds := make([]float64, len(latencies))
+	for i, l := range latencies {
+		seconds[i] = l.Seconds()
+	}
+	rollups := []internal.Rollup{{Metric: "oncall_acks", Value: float64(len(latencies))}}
+	for _, p := range []float64{50, 90, 99} {
+		rollups = append(rollups, internal.Rollup{
+			Metric: fmt.Sprintf("oncall_ack_latency_p%d", int(p)),
+			Value:  internal.Percentile(seconds, p),
+		})
+	}
+	return rollups, nil
+}
+
 // Initialize implements the ModuleInitializer interface.
 func (o *OnCallModule) Initialize(ctx context.Context, app *internal.App) error {
 	o.app = app
 	o.database = app.Database
 
+	o.config = defaultOnCallConfig()
+	if err := loadModuleConfig(app, o.Name(), &o.config); err != nil {
+		return err
+	}
+	if err := checkRepos(app, o.Name(), o.config.Handoff.Repos); err != nil {
+		return err
+	}
+
 	// Initialize database tables
 	if err := AutoMigrateOnCall(o.database.DB()); err != nil {
 		return err
 	}
+	if err := o.applyShiftConfig(); err != nil {
+		return err
+	}
 
-	// Start a ticker to check unacknowledged tasks every minute
-	go func() {
-		ticker := time.NewTicker(1 * time.Minute)
-		defer ticker.Stop()
+	// Expose schedule and task exports on the admin API
+	o.registerExportRoutes()
+
+	// Check every minute for reaction acknowledgements, unacknowledged tasks and ended shifts,
+	// and every hour for closed issues of unfinished tasks
+	if app.Scheduler != nil {
+		app.Scheduler.Register(internal.Job{
+			Name:       "oncall_reaction_acks",
+			Module:     o.Name(),
+			Deferrable: true,
+			Interval:   time.Minute,
+			Run:        o.CheckReactionAcks,
+		})
+		app.Scheduler.Register(internal.Job{
+			Name:     "oncall_escalations",
+			Module:   o.Name(),
+			Interval: time.Minute,
+			Run: func(context.Context) error {
+				return o.CheckUnacknowledgedTasks()
+			},
+		})
+		app.Scheduler.Register(internal.Job{
+			Name:     "oncall_shift_rotation",
+			Module:   o.Name(),
+			Interval: time.Minute,
+			Run: func(ctx context.Context) error {
+				return o.RotateDueSchedules(ctx, time.Now())
+			},
+		})
+		app.Scheduler.Register(internal.Job{
+			Name:       "oncall_issue_sync",
+			Module:     o.Name(),
+			Deferrable: true,
+			Interval:   time.Hour,
+			Run:        o.SyncTaskIssues,
+		})
+		if len(o.config.AvailabilityICS) > 0 {
+			app.Scheduler.Register(internal.Job{
+				Name:     "oncall_availability_sync",
+				Module:   o.Name(),
+				Interval: time.Hour,
+				Run:      o.SyncAvailability,
+			})
+		}
+	}
 
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			case <-ticker.C:
-				if err := o.CheckUnacknowledgedTasks(); err != nil {
-					slog.Error("Error checking unacknowledged tasks", "error", err)
-				}
+	return nil
+}
+
+// ackReactions are the reactions on an assignment comment that acknowledge a task.
+var ackReactions = map[string]bool{"+1": true, "eyes": true}
+
+// AssignTask creates a task for the current on-call user of a schedule and posts an
+// assignment comment that the assignee can react to in order to acknowledge it.
+func (o *OnCallModule) AssignTask(
+	ctx context.Context,
+	scheduleName, repo string,
+	issueNum int,
+	title, description string,
+) (*OnCallTask, error) {
+	db := o.database.DB()
+	schedule, err := GetScheduleByName(db, scheduleName)
+	if err != nil || schedule == nil {
+		return nil, fmt.Errorf("schedule not found: %s", scheduleName)
+	}
+	oncall, err := GetCurrentOnCallUser(db, scheduleName)
+	if err != nil {
+		return nil, err
+	}
+	user, overflow, err := o.pickAssignee(db, schedule, oncall, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to pick assignee: %w", err)
+	}
+	task, err := AddTask(db, schedule.ID, repo, issueNum, title, description, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add task: %w", err)
+	}
+
+	message := fmt.Sprintf("👋 @%s you are on call for **%s** and have been assigned this issue.",
+		user.GitHub, scheduleName)
+	if overflow {
+		message = fmt.Sprintf("👋 @%s you have been assigned this issue for **%s**: @%s, who is on call, "+
+			"is busy or at capacity.", user.GitHub, scheduleName, oncall.GitHub)
+	}
+	if err := o.postAssignment(ctx, task, message); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// postAssignment posts the comment announcing a task's assignee, and records it so that
+// reacting to it acknowledges the task.
+func (o *OnCallModule) postAssignment(ctx context.Context, task *OnCallTask, message string) error {
+	message += "\n\nReact with 👍 or 👀 to this comment (or reply `/oncall ack`) to acknowledge."
+	if o.app == nil || o.app.GitHubClient == nil {
+		slog.Info("GitHub comment would be posted (no GitHub client available)",
+			"repo", task.Repo, "issue_num", task.IssueNum, "message", message)
+		return nil
+	}
+	owner, repoName, err := internal.SplitRepo(task.Repo)
+	if err != nil {
+		return err
+	}
+	comment, _, err := o.app.GitHubClient.Issues.CreateComment(ctx, owner, repoName, task.IssueNum,
+		&github.IssueComment{Body: github.Ptr(message)})
+	if err != nil {
+		return fmt.Errorf("failed to post assignment comment: %w", err)
+	}
+	if err := SetTaskAssignmentComment(o.database.DB(), task.ID, comment.GetID()); err != nil {
+		return fmt.Errorf("failed to record assignment comment: %w", err)
+	}
+	task.AssignmentCommentID = comment.GetID()
+	return nil
+}
+
+// CheckReactionAcks acknowledges open tasks whose assignee reacted 👍 or 👀 to the
+// assignment comment. GitHub does not send webhooks for reactions, so this polls.
+func (o *OnCallModule) CheckReactionAcks(ctx context.Context) error {
+	if o.app == nil || o.app.GitHubClient == nil {
+		return nil
+	}
+	db := o.database.DB()
+	tasks, err := ListAwaitingAckTasks(db)
+	if err != nil {
+		return fmt.Errorf("failed to list tasks awaiting ack: %w", err)
+	}
+
+	for _, task := range tasks {
+		if !o.app.RepoActive(task.Repo) {
+			continue
+		}
+		assignee, err := GetUser(db, task.AssignedTo)
+		if err != nil || assignee == nil {
+			continue
+		}
+		owner, repoName, err := internal.SplitRepo(task.Repo)
+		if err != nil {
+			continue
+		}
+		reactions, _, err := o.app.GitHubClient.Reactions.ListIssueCommentReactions(
+			ctx, owner, repoName, task.AssignmentCommentID, &github.ListReactionOptions{
+				ListOptions: github.ListOptions{PerPage: 100},
+			})
+		if err != nil {
+			slog.Error("Failed to list reactions", "task_id", task.ID, "error", err)
+			continue
+		}
+		for _, r := range reactions {
+			if !ackReactions[r.GetContent()] || !strings.EqualFold(r.GetUser().GetLogin(), assignee.GitHub) {
+				continue
+			}
+			ackedAt := r.GetCreatedAt().Time
+			if ackedAt.IsZero() {
+				ackedAt = time.Now()
 			}
+			if err := UpdateTaskStatusAt(db, task.ID, "ack", ackedAt); err != nil {
+				slog.Error("Failed to acknowledge task", "task_id", task.ID, "error", err)
+				break
+			}
+			o.recordAckLatency(ctx, task, ackedAt)
+			slog.Info("Task acknowledged by reaction",
+				"task_id", task.ID,
+				"repo", task.Repo,
+				"issue_num", task.IssueNum,
+				"reaction", r.GetContent(),
+				"acknowledged_by", assignee.GitHub)
+			break
 		}
-	}()
-
+	}
 	return nil
 }
 
+// recordAckLatency exports the time from task creation to acknowledgement.
+func (o *OnCallModule) recordAckLatency(ctx context.Context, task OnCallTask, ackedAt time.Time) {
+	if o.app == nil || o.app.Telemetry == nil {
+		return
+	}
+	o.app.Telemetry.RecordAckLatency(ctx, o.Name(), float64(ackedAt.Sub(task.CreatedAt).Milliseconds()))
+}
+
 func (o *OnCallModule) AcknowledgeTask(repo string, issueNum int, user string) error {
 	// Find the task
 	task, err := GetTaskByIssueNumber(o.database.DB(), repo, issueNum)
@@ -79,38 +301,50 @@ func (o *OnCallModule) AcknowledgeTask(repo string, issueNum int, user string) e
 }
 
 func (o *OnCallModule) CheckUnacknowledgedTasks() error {
-	// Query for unacknowledged tasks older than 24 hours
+	// Query for open tasks older than 24 hours that were not escalated yet; acknowledged
+	// and done tasks need no escalation
 	rows, err := o.database.DB().Query(`
-		SELECT id, repo, issue_num, assigned_to, created_at
+		SELECT id, repo, issue_num
 		FROM oncall_tasks
-		WHERE status != 'ack'
+		WHERE status = 'open'
+		AND escalated_at IS NULL
 		AND created_at < datetime('now', '-24 hours')
 	`)
 	if err != nil {
 		return fmt.Errorf("failed to query unacknowledged tasks: %w", err)
 	}
-	defer rows.Close()
-
-	// Process each unacknowledged task
+	type staleTask struct {
+		id       int64
+		repo     string
+		issueNum int
+	}
+	var stale []staleTask
 	for rows.Next() {
-		var taskID int64
-		var repo string
-		var issueNum int
-		var assignedToID int64
-		var createdAt time.Time
-
-		if err := rows.Scan(&taskID, &repo, &issueNum, &assignedToID, &createdAt); err != nil {
+		var task staleTask
+		if err := rows.Scan(&task.id, &task.repo, &task.issueNum); err != nil {
 			slog.Error("Failed to scan task row", "error", err)
 			continue
 		}
+		stale = append(stale, task)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to query unacknowledged tasks: %w", err)
+	}
+
+	// Process each unacknowledged task
+	for _, task := range stale {
+		if !o.app.RepoActive(task.repo) {
+			continue
+		}
 
 		// Notify about escalation
-		err = o.EscalateTask(taskID, repo, issueNum)
+		err = o.EscalateTask(task.id, task.repo, task.issueNum)
 		if err != nil {
 			slog.Error("Task escalation failed",
-				"task_id", taskID,
-				"repo", repo,
-				"issue_num", issueNum,
+				"task_id", task.id,
+				"repo", task.repo,
+				"issue_num", task.issueNum,
 				"error", err)
 		}
 	}
@@ -124,6 +358,11 @@ func (o *OnCallModule) EscalateTask(taskID int64, repo string, issueNum int) err
 	if err != nil {
 		return fmt.Errorf("failed to get task details: %w", err)
 	}
+	// Recorded first: a task whose comment or notification fails is not escalated again
+	// every minute.
+	if err := MarkTaskEscalated(o.database.DB(), taskID, time.Now()); err != nil {
+		return fmt.Errorf("failed to record escalation: %w", err)
+	}
 
 	// Determine escalation group (could be a configuration)
 	escalationGroup := []string{"@org/oncall-team", "@org/leadership"}
@@ -136,6 +375,21 @@ func (o *OnCallModule) EscalateTask(taskID int64, repo string, issueNum int) err
 			task.AssignedTo,
 			strings.Join(escalationGroup, ", ")))
 
+	// Notify the channels routed for on-call escalations
+	if o.app != nil {
+		notifyErr := o.app.Notifications.Notify(context.Background(), internal.Notification{
+			Module:   o.Name(),
+			Repo:     repo,
+			Issue:    issueNum,
+			Severity: internal.SeverityCritical,
+			Title:    fmt.Sprintf("On-call task %s#%d unacknowledged for over 24 hours", repo, issueNum),
+			URL:      fmt.Sprintf("https://github.com/%s/issues/%d", repo, issueNum),
+		})
+		if notifyErr != nil {
+			slog.Error("Failed to notify escalation", "task_id", taskID, "error", notifyErr)
+		}
+	}
+
 	return err
 }
 
@@ -211,38 +465,13 @@ func (o *OnCallModule) HandleEvent(eventType string, event any, raw json.RawMess
 			)
 		}
 
-		// Check if the issue is closed
-		if issuesEvent.GetAction() == "closed" {
-			// Find the task associated with this issue
-			repo := issuesEvent.GetRepo().GetFullName()
-			issueNum := issuesEvent.GetIssue().GetNumber()
-
-			task, err := GetTaskByIssueNumber(db, repo, issueNum)
-			if err != nil {
-				return LogAndWrapError(err, ErrorTypeCommand, "get_task", map[string]any{
-					"repo":  repo,
-					"issue": issueNum,
-				})
-			}
-
-			// If task exists and is not already done, mark it as done
-			if task != nil && task.Status != "done" {
-				if err := UpdateTaskStatus(db, task.ID, "done"); err != nil {
-					return LogAndWrapError(
-						err,
-						ErrorTypeCommand,
-						"update_task_status",
-						map[string]any{
-							"task_id": task.ID,
-							"status":  "done",
-						},
-					)
-				}
-				slog.Info("Task marked as done due to issue closure",
-					"task_id", task.ID,
-					"repo", repo,
-					"issue_num", issueNum)
-			}
+		repo := issuesEvent.GetRepo().GetFullName()
+		issueNum := issuesEvent.GetIssue().GetNumber()
+		switch issuesEvent.GetAction() {
+		case "closed":
+			return o.completeIssueTask(repo, issueNum, issuesEvent.GetIssue().GetClosedAt().Time)
+		case "reopened":
+			return o.reopenIssueTask(repo, issueNum)
 		}
 	case "comment":
 		commentEvent, ok := event.(*github.IssueCommentEvent)
@@ -275,7 +504,12 @@ func (o *OnCallModule) HandleEvent(eventType string, event any, raw json.RawMess
 					},
 				)
 			}
-			if currentOnCall.GitHub == *commentEvent.GetComment().User.Login {
+			login := commentEvent.GetComment().GetUser().GetLogin()
+			assignee, err := GetUser(db, task.AssignedTo)
+			if err != nil {
+				return LogAndWrapError(err, ErrorTypeCommand, "get_task_assignee", map[string]any{"task_id": task.ID})
+			}
+			if currentOnCall.GitHub == login || (assignee != nil && assignee.GitHub == login) {
 				if err := UpdateTaskStatus(db, task.ID, "ack"); err != nil {
 					return LogAndWrapError(
 						err,
@@ -287,6 +521,7 @@ func (o *OnCallModule) HandleEvent(eventType string, event any, raw json.RawMess
 						},
 					)
 				}
+				o.recordAckLatency(context.Background(), *task, time.Now())
 				slog.Info("Task marked as acknowledged.",
 					"task_id", task.ID,
 					"repo", task.Repo,
@@ -0,0 +1,318 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v71/github"
+)
+
+// Sources of unavailability records.
+const (
+	unavailabilityCommand = "command"
+	unavailabilityICS     = "ics"
+)
+
+// parseOOORange parses "2024-08-01..2024-08-15" or a single date. Both ends are
+// inclusive whole days in UTC; the returned end is exclusive.
+func parseOOORange(s string) (time.Time, time.Time, error) {
+	from, to, found := strings.Cut(s, "..")
+	if !found {
+		to = from
+	}
+	start, err := time.Parse(time.DateOnly, from)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid start date %q (use YYYY-MM-DD)", from)
+	}
+	last, err := time.Parse(time.DateOnly, to)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid end date %q (use YYYY-MM-DD)", to)
+	}
+	if last.Before(start) {
+		return time.Time{}, time.Time{}, fmt.Errorf("end date %s is before start date %s", to, from)
+	}
+	return start, last.AddDate(0, 0, 1), nil
+}
+
+// handleOOO answers `/oncall ooo <from>..<to>` and `/oncall ooo clear` for the commenter.
+func (o *OnCallModule) handleOOO(event *github.IssueCommentEvent, args []string) error {
+	repo := event.GetRepo().GetFullName()
+	issue := event.GetIssue().GetNumber()
+	login := event.GetComment().GetUser().GetLogin()
+	reply := func(message string) error {
+		if err := o.PostGitHubComment(repo, issue, message); err != nil {
+			return LogAndWrapError(err, ErrorTypeCommand, "oncall_ooo", map[string]any{"repo": repo, "user": login})
+		}
+		return nil
+	}
+
+	db := o.database.DB()
+	user, err := GetUserByGitHub(db, login)
+	if err != nil {
+		return LogAndWrapError(err, ErrorTypeCommand, "get_oncall_user", map[string]any{"user": login})
+	}
+	if user == nil {
+		return reply(fmt.Sprintf("⚠️ @%s is not on any on-call schedule.", login))
+	}
+	if len(args) == 0 {
+		return reply("⚠️ Usage: `/oncall ooo 2024-08-01..2024-08-15` or `/oncall ooo clear`")
+	}
+
+	if args[0] == "clear" {
+		if err := ReplaceUnavailability(db, user.ID, unavailabilityCommand, nil); err != nil {
+			return LogAndWrapError(err, ErrorTypeCommand, "clear_unavailability", map[string]any{"user": login})
+		}
+		return reply(fmt.Sprintf("✅ Cleared out-of-office dates for @%s.", login))
+	}
+
+	start, end, err := parseOOORange(args[0])
+	if err != nil {
+		return reply("⚠️ " + err.Error())
+	}
+	if err := AddUnavailability(db, user.ID, start, end, unavailabilityCommand); err != nil {
+		return LogAndWrapError(err, ErrorTypeCommand, "add_unavailability", map[string]any{"user": login})
+	}
+	message := fmt.Sprintf("🌴 @%s is out of office %s – %s; rotations will skip them.",
+		login, start.Format(time.DateOnly), end.AddDate(0, 0, -1).Format(time.DateOnly))
+	conflicts, err := o.availabilityConflicts(start, end)
+	if err != nil {
+		return LogAndWrapError(err, ErrorTypeCommand, "availability_conflicts", map[string]any{"user": login})
+	}
+	for _, c := range conflicts {
+		message += "\n\n⚠️ " + c
+	}
+	return reply(message)
+}
+
+// availabilityConflicts returns a warning for every schedule with a day in [start, end)
+// on which none of its members is available.
+func (o *OnCallModule) availabilityConflicts(start, end time.Time) ([]string, error) {
+	db := o.database.DB()
+	schedules, err := ListSchedules(db)
+	if err != nil {
+		return nil, err
+	}
+	var warnings []string
+	for _, s := range schedules {
+		if !s.Enabled {
+			continue
+		}
+		members, err := ListUsersForSchedule(db, s.ID)
+		if err != nil {
+			return nil, err
+		}
+		if len(members) == 0 {
+			continue
+		}
+		for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+			// Sample midday so whole-day and partial ranges both count.
+			at := day.Add(12 * time.Hour)
+			anyone := false
+			for _, m := range members {
+				available, err := IsUserAvailable(db, m.UserID, at)
+				if err != nil {
+					return nil, err
+				}
+				if available {
+					anyone = true
+					break
+				}
+			}
+			if !anyone {
+				warnings = append(warnings, fmt.Sprintf("Nobody on **%s** is available on %s.",
+					s.Name, day.Format(time.DateOnly)))
+				break
+			}
+		}
+	}
+	return warnings, nil
+}
+
+// rotationAvailability reports which members a rotation passed over because they
+// were away, and whether the incoming user is away too (nobody was available). Random
+// rotations pass over nobody in particular.
+func (o *OnCallModule) rotationAvailability(
+	before *OnCallSchedule,
+	incoming *OnCallUser,
+	now time.Time,
+) ([]string, bool, error) {
+	db := o.database.DB()
+	members, err := ListUsersForSchedule(db, before.ID)
+	if err != nil || len(members) == 0 {
+		return nil, false, err
+	}
+	available, err := IsUserAvailable(db, incoming.ID, now)
+	if err != nil {
+		return nil, false, err
+	}
+	if !available {
+		return nil, true, nil
+	}
+
+	if before.Policy == RandomPolicy {
+		return nil, false, nil
+	}
+	var skipped []string
+	for _, idx := range rotationOrder(before, len(members)) {
+		m := members[idx]
+		if m.UserID == incoming.ID {
+			break
+		}
+		if u, err := GetUser(db, m.UserID); err == nil && u != nil {
+			skipped = append(skipped, u.GitHub)
+		}
+	}
+	return skipped, false, nil
+}
+
+// SyncAvailability imports out-of-office periods from each configured ICS calendar,
+// replacing what was imported before.
+func (o *OnCallModule) SyncAvailability(ctx context.Context) error {
+	db := o.database.DB()
+	client := o.app.HTTPClient(30 * time.Second)
+	var errs []error
+	for login, url := range o.config.AvailabilityICS {
+		user, err := GetUserByGitHub(db, login)
+		if err != nil || user == nil {
+			slog.Warn("Skipping availability calendar for unknown user", "user", login, "error", err)
+			continue
+		}
+		ranges, err := fetchICS(ctx, client, url)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("calendar for %s: %w", login, err))
+			continue
+		}
+		if err := ReplaceUnavailability(db, user.ID, unavailabilityICS, ranges); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		slog.Info("Imported availability calendar", "user", login, "periods", len(ranges))
+	}
+	return errors.Join(errs...)
+}
+
+// fetchICS downloads a calendar and returns its events that have not ended yet.
+func fetchICS(ctx context.Context, client *http.Client, url string) ([]OnCallUnavailability, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	events, err := parseICS(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	current := events[:0]
+	for _, e := range events {
+		if e.End.After(now) {
+			current = append(current, e)
+		}
+	}
+	return current, nil
+}
+
+// parseICS extracts the time span of every VEVENT in an iCalendar document. Every
+// event is treated as time away; subscribe to a dedicated out-of-office calendar.
+func parseICS(r io.Reader) ([]OnCallUnavailability, error) {
+	// Unfold continuation lines (RFC 5545 section 3.1).
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	var (
+		events  []OnCallUnavailability
+		inEvent bool
+		current OnCallUnavailability
+	)
+	for _, line := range lines {
+		name, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		prop, params, _ := strings.Cut(name, ";")
+		switch strings.ToUpper(prop) {
+		case "BEGIN":
+			if strings.EqualFold(value, "VEVENT") {
+				inEvent, current = true, OnCallUnavailability{Source: unavailabilityICS}
+			}
+		case "END":
+			if strings.EqualFold(value, "VEVENT") && inEvent {
+				inEvent = false
+				if current.Start.IsZero() {
+					continue
+				}
+				if current.End.IsZero() {
+					// A date-only event without DTEND lasts one day.
+					current.End = current.Start.AddDate(0, 0, 1)
+				}
+				events = append(events, current)
+			}
+		case "DTSTART", "DTEND":
+			if !inEvent {
+				continue
+			}
+			t, err := parseICSTime(params, value)
+			if err != nil {
+				return nil, err
+			}
+			if strings.EqualFold(prop, "DTSTART") {
+				current.Start = t
+			} else {
+				current.End = t
+			}
+		}
+	}
+	return events, nil
+}
+
+// parseICSTime parses a DTSTART/DTEND value: a date, a UTC time, or a local time
+// qualified by a TZID parameter.
+func parseICSTime(params, value string) (time.Time, error) {
+	loc := time.UTC
+	for _, p := range strings.Split(params, ";") {
+		if tzid, ok := strings.CutPrefix(p, "TZID="); ok {
+			if l, err := time.LoadLocation(strings.Trim(tzid, `"`)); err == nil {
+				loc = l
+			}
+		}
+	}
+	switch {
+	case len(value) == len("20060102"):
+		return time.ParseInLocation("20060102", value, loc)
+	case strings.HasSuffix(value, "Z"):
+		return time.Parse("20060102T150405Z", value)
+	default:
+		t, err := time.ParseInLocation("20060102T150405", value, loc)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid calendar time %q", value)
+		}
+		return t, nil
+	}
+}
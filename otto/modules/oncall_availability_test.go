@@ -0,0 +1,132 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+func TestParseOOORange(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 8, d, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		input     string
+		wantStart time.Time
+		wantEnd   time.Time
+		wantErr   bool
+	}{
+		{"2024-08-01..2024-08-15", day(1), day(16), false},
+		{"2024-08-03", day(3), day(4), false},
+		{"2024-08-15..2024-08-01", time.Time{}, time.Time{}, true},
+		{"next-week", time.Time{}, time.Time{}, true},
+	}
+	for _, tt := range tests {
+		start, end, err := parseOOORange(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseOOORange(%q) err = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if !start.Equal(tt.wantStart) || !end.Equal(tt.wantEnd) {
+			t.Errorf("parseOOORange(%q) = %v..%v, want %v..%v", tt.input, start, end, tt.wantStart, tt.wantEnd)
+		}
+	}
+}
+
+func TestParseICS(t *testing.T) {
+	ics := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"BEGIN:VEVENT",
+		"SUMMARY:Vacation",
+		"DTSTART;VALUE=DATE:20240801",
+		"DTEND;VALUE=DATE:20240816",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"SUMMARY:Dentist, with a long folded",
+		"  description",
+		"DTSTART:20240820T140000Z",
+		"DTEND:20240820T160000Z",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"DTSTART;VALUE=DATE:20240901",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")
+
+	events, err := parseICS(strings.NewReader(ics))
+	if err != nil {
+		t.Fatalf("parseICS failed: %v", err)
+	}
+	want := [][2]time.Time{
+		{time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 8, 16, 0, 0, 0, 0, time.UTC)},
+		{time.Date(2024, 8, 20, 14, 0, 0, 0, time.UTC), time.Date(2024, 8, 20, 16, 0, 0, 0, time.UTC)},
+		{time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 9, 2, 0, 0, 0, 0, time.UTC)},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("got %d events, want %d", len(events), len(want))
+	}
+	for i, e := range events {
+		if !e.Start.Equal(want[i][0]) || !e.End.Equal(want[i][1]) {
+			t.Errorf("event %d = %v..%v, want %v..%v", i, e.Start, e.End, want[i][0], want[i][1])
+		}
+	}
+}
+
+func TestRotationSkipsUnavailableUsers(t *testing.T) {
+	o, fake := newOnCallTestModule(t)
+	db := o.database.DB()
+	sch, _ := GetScheduleByName(db, "primary")
+	bob, _ := AddUser(db, "bob", "Bob")
+	carol, _ := AddUser(db, "carol", "Carol")
+	_ = AssignUserToSchedule(db, sch.ID, bob.ID, 1)
+	_ = AssignUserToSchedule(db, sch.ID, carol.ID, 2)
+
+	today := time.Now().UTC().Format(time.DateOnly)
+	nextWeek := time.Now().UTC().AddDate(0, 0, 7).Format(time.DateOnly)
+	event := commentEvent("org/oncall", 5, "bob", "/oncall ooo "+today+".."+nextWeek)
+	if err := internal.TestCommands(t, o, event); err != nil {
+		t.Fatalf("HandleCommand failed: %v", err)
+	}
+	comments := fake.commentsOn("org/oncall", 5)
+	if len(comments) != 1 || !strings.Contains(comments[0], "@bob is out of office") || strings.Contains(comments[0], "Nobody") {
+		t.Fatalf("unexpected ooo reply: %v", comments)
+	}
+
+	report, err := o.AdvanceRotation(t.Context(), "primary")
+	if err != nil {
+		t.Fatalf("AdvanceRotation failed: %v", err)
+	}
+	if report.Incoming != "carol" || len(report.Skipped) != 1 || report.Skipped[0] != "bob" || report.Conflict {
+		t.Fatalf("report = incoming %s, skipped %v, conflict %v; want carol, [bob], false",
+			report.Incoming, report.Skipped, report.Conflict)
+	}
+
+	// With everyone away the rotation still advances, and both the command and the report warn.
+	for _, user := range []string{"alice", "carol"} {
+		if err := internal.TestCommands(t, o, commentEvent("org/oncall", 5, user, "/oncall ooo "+today)); err != nil {
+			t.Fatalf("HandleCommand failed: %v", err)
+		}
+	}
+	comments = fake.commentsOn("org/oncall", 5)
+	if !strings.Contains(comments[len(comments)-1], "Nobody on **primary** is available on "+today) {
+		t.Errorf("missing conflict warning: %q", comments[len(comments)-1])
+	}
+	report, err = o.AdvanceRotation(t.Context(), "primary")
+	if err != nil {
+		t.Fatalf("AdvanceRotation failed: %v", err)
+	}
+	if report.Incoming != "alice" || !report.Conflict || !strings.Contains(report.Markdown(), "Nobody on this schedule is available") {
+		t.Errorf("report = incoming %s, conflict %v; want alice with conflict", report.Incoming, report.Conflict)
+	}
+
+	// Clearing removes the command-entered dates.
+	if err := internal.TestCommands(t, o, commentEvent("org/oncall", 5, "bob", "/oncall ooo clear")); err != nil {
+		t.Fatalf("HandleCommand failed: %v", err)
+	}
+	if available, _ := IsUserAvailable(db, bob.ID, time.Now()); !available {
+		t.Errorf("bob still unavailable after clear")
+	}
+}
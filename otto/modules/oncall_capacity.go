@@ -0,0 +1,154 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v71/github"
+)
+
+// taskCapacity returns how many open tasks a user may have before new ones go to others,
+// or 0 for no limit.
+func (o *OnCallModule) taskCapacity(login string) int {
+	if limit, ok := o.config.Capacity[login]; ok {
+		return limit
+	}
+	return o.config.MaxOpenTasks
+}
+
+// canTakeTask reports whether a user can be assigned a new task at now: they are active,
+// not busy, not out of office and below their capacity.
+func (o *OnCallModule) canTakeTask(db *sql.DB, user *OnCallUser, now time.Time) (bool, error) {
+	if !user.Active {
+		return false, nil
+	}
+	busy, err := IsUserBusy(db, user.ID)
+	if err != nil || busy {
+		return false, err
+	}
+	available, err := IsUserAvailable(db, user.ID, now)
+	if err != nil || !available {
+		return false, err
+	}
+	if limit := o.taskCapacity(user.GitHub); limit > 0 {
+		open, err := CountOpenTasksForUser(db, user.ID)
+		if err != nil {
+			return false, err
+		}
+		return open < limit, nil
+	}
+	return true, nil
+}
+
+// pickAssignee returns who is assigned a new task of a schedule: the on-call user if they
+// can take it, or else, round-robin from the schedule's overflow position, the next member
+// who can. If nobody can, the on-call user is assigned anyway. overflow reports whether
+// someone other than the on-call user was picked.
+func (o *OnCallModule) pickAssignee(db *sql.DB, schedule *OnCallSchedule, oncall *OnCallUser,
+	now time.Time,
+) (assignee *OnCallUser, overflow bool, err error) {
+	ok, err := o.canTakeTask(db, oncall, now)
+	if err != nil || ok {
+		return oncall, false, err
+	}
+	members, err := ListUsersForSchedule(db, schedule.ID)
+	if err != nil {
+		return nil, false, err
+	}
+	start, err := GetOverflowIdx(db, schedule.ID)
+	if err != nil {
+		return nil, false, err
+	}
+	for i := range len(members) {
+		idx := (start + i) % len(members)
+		if members[idx].UserID == oncall.ID {
+			continue
+		}
+		user, err := GetUser(db, members[idx].UserID)
+		if err != nil {
+			return nil, false, err
+		}
+		if user == nil {
+			continue
+		}
+		ok, err := o.canTakeTask(db, user, now)
+		if err != nil {
+			return nil, false, err
+		}
+		if ok {
+			if err := SetOverflowIdx(db, schedule.ID, (idx+1)%len(members)); err != nil {
+				return nil, false, err
+			}
+			return user, true, nil
+		}
+	}
+	slog.Warn("No on-call member can take a new task; assigning the on-call user",
+		"schedule", schedule.Name, "user", oncall.GitHub)
+	return oncall, false, nil
+}
+
+// handleAvailability runs `/availability busy|available`, with which people pause and
+// resume new task assignments, or reports their availability without arguments.
+func (o *OnCallModule) handleAvailability(event *github.IssueCommentEvent, args []string) error {
+	repo := event.GetRepo().GetFullName()
+	issue := event.GetIssue().GetNumber()
+	login := event.GetComment().GetUser().GetLogin()
+	reply := func(message string) error {
+		if err := o.PostGitHubComment(repo, issue, message); err != nil {
+			return LogAndWrapError(err, ErrorTypeCommand, "oncall_availability", map[string]any{"repo": repo, "user": login})
+		}
+		return nil
+	}
+
+	db := o.database.DB()
+	user, err := GetUserByGitHub(db, login)
+	if err != nil {
+		return LogAndWrapError(err, ErrorTypeCommand, "get_oncall_user", map[string]any{"user": login})
+	}
+	if user == nil {
+		return reply(fmt.Sprintf("⚠️ @%s is not on any on-call schedule.", login))
+	}
+	if len(args) == 0 {
+		return o.replyAvailability(db, user, reply)
+	}
+	switch strings.ToLower(args[0]) {
+	case "busy":
+		if err := SetUserBusy(db, user.ID, true); err != nil {
+			return LogAndWrapError(err, ErrorTypeCommand, "set_user_busy", map[string]any{"user": login})
+		}
+		return reply(fmt.Sprintf("🔕 @%s is busy: new on-call tasks go to others until `/availability available`.",
+			login))
+	case "available":
+		if err := SetUserBusy(db, user.ID, false); err != nil {
+			return LogAndWrapError(err, ErrorTypeCommand, "set_user_busy", map[string]any{"user": login})
+		}
+		return reply(fmt.Sprintf("🔔 @%s is available for new on-call tasks again.", login))
+	}
+	return reply("⚠️ Usage: `/availability busy` or `/availability available`")
+}
+
+// replyAvailability reports whether a user takes new tasks and how many they have open.
+func (o *OnCallModule) replyAvailability(db *sql.DB, user *OnCallUser, reply func(string) error) error {
+	busy, err := IsUserBusy(db, user.ID)
+	if err != nil {
+		return LogAndWrapError(err, ErrorTypeCommand, "get_user_busy", map[string]any{"user": user.GitHub})
+	}
+	open, err := CountOpenTasksForUser(db, user.ID)
+	if err != nil {
+		return LogAndWrapError(err, ErrorTypeCommand, "count_open_tasks", map[string]any{"user": user.GitHub})
+	}
+	state := "available"
+	if busy {
+		state = "busy"
+	}
+	limit := "no limit"
+	if capacity := o.taskCapacity(user.GitHub); capacity > 0 {
+		limit = fmt.Sprintf("capacity %d", capacity)
+	}
+	return reply(fmt.Sprintf("ℹ️ @%s is %s with %d open on-call task(s) (%s).", user.GitHub, state, open, limit))
+}
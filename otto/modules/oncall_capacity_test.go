@@ -0,0 +1,94 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+func TestAssignTaskOverflowsByCapacity(t *testing.T) {
+	o, fake := newOnCallTestModule(t)
+	o.config.MaxOpenTasks = 1
+	o.config.Capacity = map[string]int{"carol": 2}
+	db := o.database.DB()
+	sch, _ := GetScheduleByName(db, "primary")
+	bob, _ := AddUser(db, "bob", "Bob")
+	carol, _ := AddUser(db, "carol", "Carol")
+	_ = AssignUserToSchedule(db, sch.ID, bob.ID, 1)
+	_ = AssignUserToSchedule(db, sch.ID, carol.ID, 2)
+
+	// alice is on call and takes the first task, then overflow goes round-robin to bob and
+	// carol within their capacity, and to alice once everyone is full.
+	want := []string{"alice", "bob", "carol", "carol", "alice"}
+	var ids []int64
+	for i, login := range want {
+		task, err := o.AssignTask(t.Context(), "primary", "org/repo", i+1, "Flaky test", "")
+		if err != nil {
+			t.Fatalf("AssignTask %d failed: %v", i+1, err)
+		}
+		ids = append(ids, task.ID)
+		user, _ := GetUser(db, task.AssignedTo)
+		if user.GitHub != login {
+			t.Errorf("task %d assigned to %s, want %s", i+1, user.GitHub, login)
+		}
+	}
+	if comments := fake.commentsOn("org/repo", 2); len(comments) != 1 ||
+		!strings.Contains(comments[0], "@bob you have been assigned") || !strings.Contains(comments[0], "@alice") {
+		t.Errorf("unexpected overflow comment: %v", comments)
+	}
+
+	// Closing a task frees capacity.
+	_ = UpdateTaskStatus(db, ids[1], "done")
+	task, err := o.AssignTask(t.Context(), "primary", "org/repo", 6, "Flaky test", "")
+	if err != nil {
+		t.Fatalf("AssignTask failed: %v", err)
+	}
+	if task.AssignedTo != bob.ID {
+		t.Errorf("task assigned to user %d, want bob", task.AssignedTo)
+	}
+}
+
+func TestAvailabilityCommand(t *testing.T) {
+	o, fake := newOnCallTestModule(t)
+	db := o.database.DB()
+	sch, _ := GetScheduleByName(db, "primary")
+	bob, _ := AddUser(db, "bob", "Bob")
+	_ = AssignUserToSchedule(db, sch.ID, bob.ID, 1)
+
+	tests := []struct {
+		user, body, reply string
+	}{
+		{"alice", "/availability busy", "@alice is busy"},
+		{"alice", "/availability", "@alice is busy with 0 open on-call task(s) (no limit)"},
+		{"alice", "/availability later", "Usage"},
+		{"dave", "/availability busy", "@dave is not on any on-call schedule"},
+	}
+	for _, tt := range tests {
+		if err := internal.TestCommands(t, o, commentEvent("org/oncall", 5, tt.user, tt.body)); err != nil {
+			t.Fatalf("HandleEvent(%q) failed: %v", tt.body, err)
+		}
+		comments := fake.commentsOn("org/oncall", 5)
+		if got := comments[len(comments)-1]; !strings.Contains(got, tt.reply) {
+			t.Errorf("%s: reply %q does not contain %q", tt.body, got, tt.reply)
+		}
+	}
+
+	// Busy on-call users are skipped until they are available again.
+	task, err := o.AssignTask(t.Context(), "primary", "org/repo", 1, "Flaky test", "")
+	if err != nil {
+		t.Fatalf("AssignTask failed: %v", err)
+	}
+	if task.AssignedTo != bob.ID {
+		t.Errorf("task assigned to user %d, want bob", task.AssignedTo)
+	}
+	event := commentEvent("org/oncall", 5, "alice", "/availability available")
+	if err := internal.TestCommands(t, o, event); err != nil {
+		t.Fatalf("HandleCommand failed: %v", err)
+	}
+	if busy, _ := IsUserBusy(db, 1); busy {
+		t.Errorf("alice still busy")
+	}
+}
@@ -0,0 +1,263 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v71/github"
+)
+
+// commandReply returns a function that answers a slash command on the issue it was
+// commented on, wrapping failures as errors of the command's operation.
+func (o *OnCallModule) commandReply(op string, event *github.IssueCommentEvent) func(string) error {
+	repo := event.GetRepo().GetFullName()
+	issue := event.GetIssue().GetNumber()
+	login := event.GetComment().GetUser().GetLogin()
+	return func(message string) error {
+		if err := o.PostGitHubComment(repo, issue, message); err != nil {
+			return LogAndWrapError(err, ErrorTypeCommand, op, map[string]any{"repo": repo, "user": login})
+		}
+		return nil
+	}
+}
+
+// handleAck acknowledges the task of the issue `/oncall ack` is commented on. The assignee
+// and whoever is on call for the task's schedule may acknowledge it.
+func (o *OnCallModule) handleAck(ctx context.Context, event *github.IssueCommentEvent) error {
+	repo := event.GetRepo().GetFullName()
+	issue := event.GetIssue().GetNumber()
+	login := event.GetComment().GetUser().GetLogin()
+	reply := o.commandReply("oncall_ack", event)
+
+	db := o.database.DB()
+	task, err := GetTaskByIssueNumber(db, repo, issue)
+	if err != nil {
+		return LogAndWrapError(err, ErrorTypeCommand, "get_task", map[string]any{"repo": repo, "issue": issue})
+	}
+	switch {
+	case task == nil:
+		return reply("⚠️ There is no on-call task for this issue.")
+	case task.Status == "ack":
+		return reply("👀 The on-call task for this issue is already acknowledged.")
+	case task.Status == "done":
+		return reply("✅ The on-call task for this issue is already done.")
+	}
+
+	allowed, err := o.mayAcknowledge(task, login)
+	if err != nil {
+		return LogAndWrapError(err, ErrorTypeCommand, "get_oncall_user", map[string]any{"user": login})
+	}
+	if !allowed {
+		return reply(fmt.Sprintf("⚠️ @%s is neither the assignee of this task nor on call for it.", login))
+	}
+
+	now := time.Now()
+	if err := UpdateTaskStatusAt(db, task.ID, "ack", now); err != nil {
+		return LogAndWrapError(err, ErrorTypeCommand, "update_task_status", map[string]any{
+			"task_id": task.ID,
+			"status":  "ack",
+		})
+	}
+	o.recordAckLatency(ctx, *task, now)
+	slog.Info("Task acknowledged by command", "task_id", task.ID, "repo", repo, "issue_num", issue, "user", login)
+	return reply(fmt.Sprintf("👀 On-call task acknowledged by @%s after %s.",
+		login, now.Sub(task.CreatedAt).Round(time.Second)))
+}
+
+// mayAcknowledge reports whether a GitHub user is the assignee of a task or on call for
+// its schedule.
+func (o *OnCallModule) mayAcknowledge(task *OnCallTask, login string) (bool, error) {
+	db := o.database.DB()
+	assignee, err := GetUser(db, task.AssignedTo)
+	if err != nil {
+		return false, err
+	}
+	if assignee != nil && strings.EqualFold(assignee.GitHub, login) {
+		return true, nil
+	}
+	schedule, err := GetSchedule(db, task.ScheduleID)
+	if err != nil || schedule == nil {
+		return false, err
+	}
+	current, err := GetCurrentOnCallUser(db, schedule.Name)
+	if err != nil {
+		return false, nil // a schedule without members has nobody on call
+	}
+	return strings.EqualFold(current.GitHub, login), nil
+}
+
+// handleAssign answers `/oncall assign @user` by assigning the task of the issue to a
+// member of its schedule, who then acknowledges it like any other assignment. An issue
+// without a task gets one on the default schedule. Members of the schedule may assign.
+func (o *OnCallModule) handleAssign(ctx context.Context, event *github.IssueCommentEvent, args []string) error {
+	repo := event.GetRepo().GetFullName()
+	issue := event.GetIssue().GetNumber()
+	login := event.GetComment().GetUser().GetLogin()
+	reply := o.commandReply("oncall_assign", event)
+	if len(args) == 0 || !strings.HasPrefix(args[0], "@") || len(args[0]) == 1 {
+		return reply("⚠️ Usage: `/oncall assign @user`")
+	}
+	target := strings.TrimPrefix(args[0], "@")
+
+	db := o.database.DB()
+	task, err := GetTaskByIssueNumber(db, repo, issue)
+	if err != nil {
+		return LogAndWrapError(err, ErrorTypeCommand, "get_task", map[string]any{"repo": repo, "issue": issue})
+	}
+	if task != nil && task.Status == "done" {
+		return reply("✅ The on-call task for this issue is already done.")
+	}
+	var schedule *OnCallSchedule
+	if task != nil {
+		schedule, err = GetSchedule(db, task.ScheduleID)
+	} else {
+		schedule, err = GetScheduleByName(db, o.config.DefaultSchedule)
+	}
+	if err != nil {
+		return LogAndWrapError(err, ErrorTypeCommand, "get_schedule", map[string]any{"repo": repo, "issue": issue})
+	}
+	if schedule == nil {
+		return reply(fmt.Sprintf("⚠️ schedule not found: %s", o.config.DefaultSchedule))
+	}
+	for _, who := range []string{login, target} {
+		member, err := o.onSchedule(schedule.ID, who)
+		if err != nil {
+			return LogAndWrapError(err, ErrorTypeCommand, "get_oncall_user", map[string]any{"user": who})
+		}
+		if !member {
+			return reply(fmt.Sprintf("⚠️ @%s is not on the **%s** schedule.", who, schedule.Name))
+		}
+	}
+	user, err := GetUserByGitHub(db, target)
+	if err != nil {
+		return LogAndWrapError(err, ErrorTypeCommand, "get_oncall_user", map[string]any{"user": target})
+	}
+
+	if task == nil {
+		task, err = AddTask(db, schedule.ID, repo, issue, event.GetIssue().GetTitle(), "", user.ID)
+	} else {
+		err = ReassignTask(db, task.ID, user.ID)
+	}
+	if err != nil {
+		return LogAndWrapError(err, ErrorTypeCommand, "assign_task", map[string]any{
+			"repo":  repo,
+			"issue": issue,
+			"user":  target,
+		})
+	}
+	slog.Info("Task assigned by command", "task_id", task.ID, "repo", repo, "issue_num", issue,
+		"assignee", user.GitHub, "user", login)
+	message := fmt.Sprintf("👋 @%s you have been assigned this issue for **%s** by @%s.",
+		user.GitHub, schedule.Name, login)
+	if err := o.postAssignment(ctx, task, message); err != nil {
+		return LogAndWrapError(err, ErrorTypeCommand, "oncall_assign", map[string]any{"repo": repo, "user": login})
+	}
+	return nil
+}
+
+// handleNext answers `/oncall next [schedule]` by handing the schedule over now to the
+// member its policy picks, as at a scheduled handoff. Members of the schedule may hand it
+// over.
+func (o *OnCallModule) handleNext(ctx context.Context, event *github.IssueCommentEvent, args []string) error {
+	name := o.config.DefaultSchedule
+	if len(args) > 0 {
+		name = args[0]
+	}
+	login := event.GetComment().GetUser().GetLogin()
+	reply := o.commandReply("oncall_next", event)
+
+	schedule, err := GetScheduleByName(o.database.DB(), name)
+	if err != nil {
+		return LogAndWrapError(err, ErrorTypeCommand, "get_schedule", map[string]any{"schedule": name})
+	}
+	if schedule == nil {
+		return reply(fmt.Sprintf("⚠️ schedule not found: %s", name))
+	}
+	member, err := o.onSchedule(schedule.ID, login)
+	if err != nil {
+		return LogAndWrapError(err, ErrorTypeCommand, "get_oncall_user", map[string]any{"user": login})
+	}
+	if !member {
+		return reply(fmt.Sprintf("⚠️ @%s is not on the **%s** schedule.", login, name))
+	}
+
+	report, err := o.AdvanceRotation(ctx, name)
+	if err != nil {
+		return LogAndWrapError(err, ErrorTypeCommand, "advance_rotation", map[string]any{"schedule": name})
+	}
+	slog.Info("Rotation advanced by command", "schedule", name, "outgoing", report.Outgoing,
+		"incoming", report.Incoming, "user", login)
+	message := fmt.Sprintf("🔁 @%s is now on call for **%s**, taking over from @%s.",
+		report.Incoming, name, report.Outgoing)
+	if report.Incoming == report.Outgoing {
+		message = fmt.Sprintf("🔁 @%s stays on call for **%s**: nobody else is available.", report.Incoming, name)
+	}
+	if report.Conflict {
+		message += " Nobody on the schedule is available; please arrange cover."
+	} else if len(report.Skipped) > 0 {
+		message += fmt.Sprintf(" Skipped (out of office): @%s.", strings.Join(report.Skipped, ", @"))
+	}
+	return reply(message)
+}
+
+// handleSchedule answers `/oncall schedule list` with every schedule, its policy and members,
+// who is on call and when the next handoff is.
+func (o *OnCallModule) handleSchedule(event *github.IssueCommentEvent, args []string) error {
+	reply := o.commandReply("oncall_schedule", event)
+	if len(args) == 0 || args[0] != "list" {
+		return reply("⚠️ Usage: `/oncall schedule list`")
+	}
+	message, err := o.scheduleListMessage(time.Now())
+	if err != nil {
+		return LogAndWrapError(err, ErrorTypeCommand, "list_schedules", nil)
+	}
+	return reply(message)
+}
+
+// scheduleListMessage describes every schedule as a Markdown list.
+func (o *OnCallModule) scheduleListMessage(now time.Time) (string, error) {
+	db := o.database.DB()
+	schedules, err := ListSchedules(db)
+	if err != nil {
+		return "", err
+	}
+	if len(schedules) == 0 {
+		return "📋 There are no on-call schedules.", nil
+	}
+	var b strings.Builder
+	b.WriteString("📋 **On-call schedules**\n")
+	for _, s := range schedules {
+		members, err := ListUsersForSchedule(db, s.ID)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&b, "\n- **%s** (%s, %d members", s.Name, s.Policy, len(members))
+		if !s.Enabled {
+			b.WriteString(", disabled")
+		}
+		b.WriteString("): ")
+		if len(members) == 0 {
+			b.WriteString("nobody is on call")
+			continue
+		}
+		current, err := GetCurrentOnCallUser(db, s.Name)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&b, "@%s is on call", current.GitHub)
+		next, err := nextHandoffAt(db, &s, now)
+		if err != nil {
+			return "", err
+		}
+		if !next.IsZero() {
+			fmt.Fprintf(&b, " until %s (%s UTC)",
+				next.In(s.location()).Format("Mon Jan 2 15:04 MST"), next.UTC().Format("15:04"))
+		}
+	}
+	return b.String(), nil
+}
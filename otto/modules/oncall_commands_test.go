@@ -0,0 +1,179 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"strings"
+	"testing"
+
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+// newOnCallCommandsTestModule returns an oncall module with alice on call for primary and
+// bob as the second member.
+func newOnCallCommandsTestModule(t *testing.T) (*OnCallModule, *fakeGitHub) {
+	o, fake := newOnCallTestModule(t)
+	o.config.DefaultSchedule = "primary"
+	db := o.database.DB()
+	sch, _ := GetScheduleByName(db, "primary")
+	bob, _ := AddUser(db, "bob", "Bob")
+	_ = AssignUserToSchedule(db, sch.ID, bob.ID, 1)
+	_, _ = AddUser(db, "mallory", "Mallory")
+	return o, fake
+}
+
+func lastComment(t *testing.T, fake *fakeGitHub, repo string, number int) string {
+	t.Helper()
+	comments := fake.commentsOn(repo, number)
+	if len(comments) == 0 {
+		t.Fatalf("no comments on %s#%d", repo, number)
+	}
+	return comments[len(comments)-1]
+}
+
+func TestOnCallAckCommand(t *testing.T) {
+	o, fake := newOnCallCommandsTestModule(t)
+	reader := sdkmetric.NewManualReader()
+	o.app.Telemetry = internal.TestTelemetry(t, reader)
+	db := o.database.DB()
+	task, err := o.AssignTask(t.Context(), "primary", "org/repo", 9, "Flaky test", "")
+	if err != nil {
+		t.Fatalf("AssignTask failed: %v", err)
+	}
+	if !strings.Contains(lastComment(t, fake, "org/repo", 9), "reply `/oncall ack`") {
+		t.Errorf("assignment comment does not mention /oncall ack")
+	}
+
+	// Members who are neither the assignee nor on call cannot acknowledge.
+	if err := internal.TestCommands(t, o, commentEvent("org/repo", 9, "bob", "/oncall ack")); err != nil {
+		t.Fatalf("HandleCommand failed: %v", err)
+	}
+	if got, _ := GetTask(db, task.ID); got.Status != "open" {
+		t.Fatalf("task acknowledged by bob, status %q", got.Status)
+	}
+
+	if err := internal.TestCommands(t, o, commentEvent("org/repo", 9, "alice", "/oncall ack")); err != nil {
+		t.Fatalf("HandleCommand failed: %v", err)
+	}
+	got, _ := GetTask(db, task.ID)
+	if got.Status != "ack" || got.AckedAt == nil {
+		t.Fatalf("task not acknowledged: %+v", got)
+	}
+	if c := lastComment(t, fake, "org/repo", 9); !strings.Contains(c, "acknowledged by @alice") {
+		t.Errorf("unexpected confirmation: %q", c)
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(t.Context(), &rm); err != nil {
+		t.Fatalf("failed to collect metrics: %v", err)
+	}
+	var acks uint64
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if h, ok := m.Data.(metricdata.Histogram[float64]); ok && m.Name == "otto.module.ack_latency_ms" {
+				for _, dp := range h.DataPoints {
+					acks += dp.Count
+				}
+			}
+		}
+	}
+	if acks != 1 {
+		t.Errorf("ack latency recorded %d times, want 1", acks)
+	}
+
+	// A second ack only replies.
+	if err := internal.TestCommands(t, o, commentEvent("org/repo", 9, "alice", "/oncall ack")); err != nil {
+		t.Fatalf("HandleCommand failed: %v", err)
+	}
+	if c := lastComment(t, fake, "org/repo", 9); !strings.Contains(c, "already acknowledged") {
+		t.Errorf("unexpected reply to a second ack: %q", c)
+	}
+}
+
+func TestOnCallAssignCommand(t *testing.T) {
+	o, fake := newOnCallCommandsTestModule(t)
+	db := o.database.DB()
+	task, err := o.AssignTask(t.Context(), "primary", "org/repo", 9, "Flaky test", "")
+	if err != nil {
+		t.Fatalf("AssignTask failed: %v", err)
+	}
+	if err := UpdateTaskStatus(db, task.ID, "ack"); err != nil {
+		t.Fatalf("UpdateTaskStatus failed: %v", err)
+	}
+
+	for _, tc := range []struct{ user, body, reply string }{
+		{"alice", "/oncall assign", "Usage"},
+		{"mallory", "/oncall assign @bob", "@mallory is not on the **primary** schedule"},
+		{"alice", "/oncall assign @mallory", "@mallory is not on the **primary** schedule"},
+	} {
+		if err := internal.TestCommands(t, o, commentEvent("org/repo", 9, tc.user, tc.body)); err != nil {
+			t.Fatalf("HandleCommand(%q) failed: %v", tc.body, err)
+		}
+		if c := lastComment(t, fake, "org/repo", 9); !strings.Contains(c, tc.reply) {
+			t.Errorf("reply to %q = %q, want %q", tc.body, c, tc.reply)
+		}
+	}
+
+	if err := internal.TestCommands(t, o, commentEvent("org/repo", 9, "alice", "/oncall assign @bob")); err != nil {
+		t.Fatalf("HandleCommand failed: %v", err)
+	}
+	bob, _ := GetUserByGitHub(db, "bob")
+	got, _ := GetTask(db, task.ID)
+	if got.AssignedTo != bob.ID || got.Status != "open" || got.AckedAt != nil {
+		t.Fatalf("task not reassigned to bob: %+v", got)
+	}
+	if c := lastComment(t, fake, "org/repo", 9); !strings.Contains(c, "@bob you have been assigned this issue") ||
+		got.AssignmentCommentID == task.AssignmentCommentID {
+		t.Errorf("unexpected assignment comment %q (comment ID %d)", c, got.AssignmentCommentID)
+	}
+
+	// An issue without a task gets one on the default schedule.
+	if err := internal.TestCommands(t, o, commentEvent("org/repo", 10, "bob", "/oncall assign @alice")); err != nil {
+		t.Fatalf("HandleCommand failed: %v", err)
+	}
+	alice, _ := GetUserByGitHub(db, "alice")
+	if created, _ := GetTaskByIssueNumber(db, "org/repo", 10); created == nil || created.AssignedTo != alice.ID {
+		t.Errorf("task not created for alice: %+v", created)
+	}
+}
+
+func TestOnCallNextAndScheduleListCommands(t *testing.T) {
+	o, fake := newOnCallCommandsTestModule(t)
+	db := o.database.DB()
+	if _, err := AddSchedule(db, "secondary", "sequential"); err != nil {
+		t.Fatalf("AddSchedule failed: %v", err)
+	}
+
+	if err := internal.TestCommands(t, o, commentEvent("org/repo", 3, "mallory", "/oncall next")); err != nil {
+		t.Fatalf("HandleCommand failed: %v", err)
+	}
+	if user, _ := GetCurrentOnCallUser(db, "primary"); user.GitHub != "alice" {
+		t.Fatalf("rotation advanced by a non-member; on call = %s", user.GitHub)
+	}
+	if err := internal.TestCommands(t, o, commentEvent("org/repo", 3, "alice", "/oncall next")); err != nil {
+		t.Fatalf("HandleCommand failed: %v", err)
+	}
+	if user, _ := GetCurrentOnCallUser(db, "primary"); user.GitHub != "bob" {
+		t.Fatalf("on call = %s, want bob", user.GitHub)
+	}
+	if c := lastComment(t, fake, "org/repo", 3); !strings.Contains(c, "@bob is now on call for **primary**, "+
+		"taking over from @alice") {
+		t.Errorf("unexpected confirmation: %q", c)
+	}
+
+	if err := internal.TestCommands(t, o, commentEvent("org/repo", 3, "carol", "/oncall schedule list")); err != nil {
+		t.Fatalf("HandleCommand failed: %v", err)
+	}
+	c := lastComment(t, fake, "org/repo", 3)
+	for _, want := range []string{
+		"- **primary** (round-robin, 2 members): @bob is on call",
+		"- **secondary** (sequential, 0 members): nobody is on call",
+	} {
+		if !strings.Contains(c, want) {
+			t.Errorf("schedule list %q does not contain %q", c, want)
+		}
+	}
+}
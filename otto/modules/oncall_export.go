@@ -0,0 +1,241 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v71/github"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+// taskListSchema describes exported tasks to the admin API's list query layer.
+var taskListSchema = internal.ListSchema{
+	Fields: append(
+		internal.ListColumns("id", "schedule_id", "repo", "issue_num", "title", "status", "assigned_to",
+			"created_at", "acked_at", "completed_at"),
+		internal.ListField{Name: "ack_latency_seconds"},
+		internal.ListField{Name: "resolution_seconds"},
+	),
+	Time: "created_at",
+	Sort: "created_at",
+	Key:  "id",
+}
+
+// taskExport is the export representation of a task, including latency metrics.
+type taskExport struct {
+	ID                int64   `json:"id"`
+	ScheduleID        int64   `json:"schedule_id"`
+	Repo              string  `json:"repo"`
+	IssueNum          int     `json:"issue_num"`
+	Title             string  `json:"title"`
+	Status            string  `json:"status"`
+	AssignedTo        int64   `json:"assigned_to"`
+	CreatedAt         string  `json:"created_at"`
+	AckedAt           *string `json:"acked_at"`
+	CompletedAt       *string `json:"completed_at"`
+	AckLatencySeconds *int64  `json:"ack_latency_seconds"`
+	ResolutionSeconds *int64  `json:"resolution_seconds"`
+}
+
+// scheduleExportFields lists the exported schedule keys in output order.
+var scheduleExportFields = []string{
+	"id",
+	"name",
+	"policy",
+	"enabled",
+	"current_rotation_idx",
+	"current_oncall",
+	"users",
+	"rotations",
+	"created_at",
+	"updated_at",
+}
+
+// registerExportRoutes exposes oncall data and rotation controls on the admin API.
+func (o *OnCallModule) registerExportRoutes() {
+	o.app.HandleAdmin("GET /admin/oncall/schedules.json", o.handleExportSchedules)
+	tasks := internal.ListHandler(taskListSchema, o.exportTasks)
+	o.app.HandleAdmin("GET /admin/oncall/tasks.json", tasks)
+	o.app.HandleAdmin("GET /admin/oncall/tasks.csv", tasks)
+	o.app.HandleAdmin("POST /admin/oncall/schedules/{name}/rotate", o.handleRotate)
+}
+
+// handleExportSchedules writes all schedules with their members and rotation history as JSON.
+func (o *OnCallModule) handleExportSchedules(w http.ResponseWriter, r *http.Request) {
+	fields, err := parseFields(r.URL.Query().Get("fields"), scheduleExportFields)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	db := o.database.DB()
+	schedules, err := ListSchedules(db)
+	if err != nil {
+		slog.Error("Failed to list schedules for export", "error", err)
+		http.Error(w, "failed to list schedules", http.StatusInternalServerError)
+		return
+	}
+
+	out := make([]map[string]any, 0, len(schedules))
+	for _, s := range schedules {
+		row, err := o.scheduleExportRow(s)
+		if err != nil {
+			slog.Error("Failed to export schedule", "schedule", s.Name, "error", err)
+			http.Error(w, "failed to export schedules", http.StatusInternalServerError)
+			return
+		}
+		out = append(out, selectFields(row, fields))
+	}
+
+	writeJSON(w, out)
+}
+
+// scheduleExportRow assembles the export representation of a schedule.
+func (o *OnCallModule) scheduleExportRow(s OnCallSchedule) (map[string]any, error) {
+	db := o.database.DB()
+
+	members, err := ListUsersForSchedule(db, s.ID)
+	if err != nil {
+		return nil, err
+	}
+	users := make([]string, 0, len(members))
+	for _, m := range members {
+		u, err := GetUser(db, m.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if u != nil {
+			users = append(users, u.GitHub)
+		}
+	}
+
+	history, err := ListRotationHistory(db, s.ID)
+	if err != nil {
+		return nil, err
+	}
+	rotations := make([]map[string]any, 0, len(history))
+	for _, h := range history {
+		rotations = append(rotations, map[string]any{
+			"from_idx":   h.FromIdx,
+			"to_idx":     h.ToIdx,
+			"user":       h.UserGitHub,
+			"rotated_at": h.RotatedAt.UTC().Format(time.RFC3339),
+		})
+	}
+
+	var current string
+	if len(users) > 0 {
+		current = users[s.CurrentRotationIdx%len(users)]
+	}
+
+	var nextHandoff string
+	if s.Rotates() {
+		last := s.CreatedAt
+		if len(history) > 0 {
+			last = history[len(history)-1].RotatedAt
+		}
+		if next := s.NextHandoff(last); !next.IsZero() {
+			nextHandoff = next.UTC().Format(time.RFC3339)
+		}
+	}
+
+	return map[string]any{
+		"id":                   s.ID,
+		"name":                 s.Name,
+		"policy":               string(s.Policy),
+		"enabled":              s.Enabled,
+		"current_rotation_idx": s.CurrentRotationIdx,
+		"current_oncall":       current,
+		"users":                users,
+		"rotations":            rotations,
+		"shift_duration_hours": s.ShiftDuration.Hours(),
+		"timezone":             s.Timezone,
+		"handoff_time":         s.HandoffTime,
+		"handoff_cron":         s.HandoffCron,
+		"next_handoff":         nextHandoff,
+		"created_at":           s.CreatedAt.UTC().Format(time.RFC3339),
+		"updated_at":           s.UpdatedAt.UTC().Format(time.RFC3339),
+	}, nil
+}
+
+// exportTasks lists tasks for the tasks.json and tasks.csv exports.
+func (o *OnCallModule) exportTasks(ctx context.Context, q internal.ListQuery) ([]taskExport, error) {
+	tasks, err := ListTasks(ctx, o.database.DB(), q)
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]taskExport, 0, len(tasks))
+	for _, t := range tasks {
+		rows = append(rows, newTaskExport(t))
+	}
+	return rows, nil
+}
+
+// newTaskExport converts a task into its export representation.
+func newTaskExport(t OnCallTask) taskExport {
+	row := taskExport{
+		ID:         t.ID,
+		ScheduleID: t.ScheduleID,
+		Repo:       t.Repo,
+		IssueNum:   t.IssueNum,
+		Title:      t.Title,
+		Status:     t.Status,
+		AssignedTo: t.AssignedTo,
+		CreatedAt:  t.CreatedAt.UTC().Format(time.RFC3339),
+	}
+	if t.AckedAt != nil {
+		row.AckedAt = github.Ptr(t.AckedAt.UTC().Format(time.RFC3339))
+		row.AckLatencySeconds = github.Ptr(int64(t.AckedAt.Sub(t.CreatedAt).Seconds()))
+	}
+	if t.CompletedAt != nil {
+		row.CompletedAt = github.Ptr(t.CompletedAt.UTC().Format(time.RFC3339))
+		row.ResolutionSeconds = github.Ptr(int64(t.CompletedAt.Sub(t.CreatedAt).Seconds()))
+	}
+	return row
+}
+
+// parseFields validates a comma-separated field list against the allowed fields.
+// An empty list selects all fields.
+func parseFields(raw string, allowed []string) ([]string, error) {
+	if raw == "" {
+		return allowed, nil
+	}
+	valid := make(map[string]bool, len(allowed))
+	for _, f := range allowed {
+		valid[f] = true
+	}
+	var fields []string
+	for _, f := range strings.Split(raw, ",") {
+		f = strings.TrimSpace(f)
+		if !valid[f] {
+			return nil, fmt.Errorf("unknown field %q", f)
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+// selectFields returns a copy of row containing only the given fields.
+func selectFields(row map[string]any, fields []string) map[string]any {
+	out := make(map[string]any, len(fields))
+	for _, f := range fields {
+		out[f] = row[f]
+	}
+	return out
+}
+
+// writeJSON encodes v as the JSON response body.
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("Failed to write JSON response", "error", err)
+	}
+}
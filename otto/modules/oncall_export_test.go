@@ -0,0 +1,125 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+func newExportTestModule(t *testing.T) *OnCallModule {
+	db := openTestDB(t)
+	sch, _ := AddSchedule(db, "primary", "round-robin")
+	alice, _ := AddUser(db, "alice", "Alice")
+	bob, _ := AddUser(db, "bob", "Bob")
+	_ = AssignUserToSchedule(db, sch.ID, alice.ID, 0)
+	_ = AssignUserToSchedule(db, sch.ID, bob.ID, 1)
+	if err := AdvanceOnCallSchedule(db, "primary"); err != nil {
+		t.Fatalf("AdvanceOnCallSchedule failed: %v", err)
+	}
+	for i := 1; i <= 3; i++ {
+		if _, err := AddTask(db, sch.ID, "org/repo", i, "task", "desc", alice.ID); err != nil {
+			t.Fatalf("AddTask failed: %v", err)
+		}
+	}
+	task, _ := GetTaskByIssueNumber(db, "org/repo", 1)
+	_ = UpdateTaskStatus(db, task.ID, "ack")
+
+	return &OnCallModule{database: internal.NewDatabaseFromDB(db)}
+}
+
+func TestExportSchedulesJSON(t *testing.T) {
+	o := newExportTestModule(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/oncall/schedules.json?fields=name,current_oncall,rotations", nil)
+	rr := httptest.NewRecorder()
+	o.handleExportSchedules(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d: %s", rr.Code, rr.Body.String())
+	}
+	var out []map[string]any
+	if err := json.Unmarshal(rr.Body.Bytes(), &out); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(out) != 1 {
+		t.Fatalf("expected 1 schedule, got %d", len(out))
+	}
+	if out[0]["current_oncall"] != "bob" {
+		t.Errorf("expected current_oncall bob, got %v", out[0]["current_oncall"])
+	}
+	if rotations, ok := out[0]["rotations"].([]any); !ok || len(rotations) != 1 {
+		t.Errorf("expected one rotation in history, got %v", out[0]["rotations"])
+	}
+	if _, ok := out[0]["policy"]; ok {
+		t.Errorf("field filter did not remove policy")
+	}
+}
+
+func TestExportTasks(t *testing.T) {
+	o := newExportTestModule(t)
+
+	tests := []struct {
+		name       string
+		url        string
+		wantStatus int
+		wantRows   int
+		wantNext   string
+	}{
+		{"csv all", "/admin/oncall/tasks.csv", http.StatusOK, 3, ""},
+		{"csv paged", "/admin/oncall/tasks.csv?limit=2", http.StatusOK, 2, "2"},
+		{"csv second page", "/admin/oncall/tasks.csv?limit=2&offset=2", http.StatusOK, 1, ""},
+		{"by status", "/admin/oncall/tasks.csv?status=ack,completed", http.StatusOK, 1, ""},
+		{"since future", "/admin/oncall/tasks.csv?since=2999-01-01", http.StatusOK, 0, ""},
+		{"bad since", "/admin/oncall/tasks.csv?since=yesterday", http.StatusBadRequest, 0, ""},
+		{"bad field", "/admin/oncall/tasks.csv?fields=password", http.StatusBadRequest, 0, ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			internal.ListHandler(taskListSchema, o.exportTasks)(rr, httptest.NewRequest(http.MethodGet, tc.url, nil))
+			if rr.Code != tc.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rr.Code, tc.wantStatus, rr.Body.String())
+			}
+			if tc.wantStatus != http.StatusOK {
+				return
+			}
+			records, err := csv.NewReader(strings.NewReader(rr.Body.String())).ReadAll()
+			if err != nil {
+				t.Fatalf("invalid CSV: %v", err)
+			}
+			if got := len(records) - 1; got != tc.wantRows {
+				t.Errorf("got %d rows, want %d", got, tc.wantRows)
+			}
+			if got := rr.Header().Get("X-Next-Offset"); got != tc.wantNext {
+				t.Errorf("X-Next-Offset = %q, want %q", got, tc.wantNext)
+			}
+		})
+	}
+}
+
+func TestExportTasksJSONFields(t *testing.T) {
+	o := newExportTestModule(t)
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/admin/oncall/tasks.json?fields=issue_num,status", nil)
+	internal.ListHandler(taskListSchema, o.exportTasks)(rr, req)
+
+	var out []map[string]any
+	if err := json.Unmarshal(rr.Body.Bytes(), &out); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(out) != 3 || len(out[0]) != 2 {
+		t.Fatalf("unexpected export shape: %v", out)
+	}
+	if out[0]["status"] != "ack" {
+		t.Errorf("expected first task to be acked, got %v", out[0]["status"])
+	}
+}
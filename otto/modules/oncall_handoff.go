@@ -0,0 +1,301 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+// OnCallConfig is the oncall module's section of the application config.
+type OnCallConfig struct {
+	DefaultSchedule string                 `yaml:"default_schedule" doc:"default of '/oncall who', 'next', 'assign'"`
+	Shifts          map[string]ShiftConfig `yaml:"shifts" doc:"schedule name -> shift boundaries"`
+	Handoff         HandoffConfig          `yaml:"handoff" doc:"issue that records shift handoffs"`
+	SlackUsers      map[string]string      `yaml:"slack_users" doc:"GitHub login -> Slack user ID"`
+	AvailabilityICS map[string]string      `yaml:"availability_ics" doc:"GitHub login -> out-of-office ICS URL"`
+	MaxOpenTasks    int                    `yaml:"max_open_tasks" doc:"open tasks per person before others get new ones"`
+	Capacity        map[string]int         `yaml:"capacity" doc:"GitHub login -> max_open_tasks for that person"`
+	CloseIssues     bool                   `yaml:"close_issues" doc:"'/oncall done' also closes the issue"`
+}
+
+// HandoffConfig controls where end-of-rotation handoff reports are delivered.
+type HandoffConfig struct {
+	Repo  string   `yaml:"repo" doc:"repository holding the handoff issue, e.g. 'org/oncall'"`
+	Issue int      `yaml:"issue" doc:"issue that receives one comment per handoff"`
+	Repos []string `yaml:"repos" doc:"repositories whose new issues are reported; empty means all"`
+}
+
+// HandoffIssue is an issue opened during the outgoing shift.
+type HandoffIssue struct {
+	Repo   string
+	Number int
+	Title  string
+	URL    string
+}
+
+// HandoffReport summarizes an on-call shift for the incoming person.
+type HandoffReport struct {
+	Schedule     string
+	Outgoing     string
+	Incoming     string
+	ShiftStart   time.Time
+	ShiftEnd     time.Time
+	OpenTasks    []OnCallTask // acknowledged but not yet done
+	UnackedTasks []OnCallTask
+	NewIssues    []HandoffIssue
+	Skipped      []string // members passed over because they are out of office
+	Conflict     bool     // nobody was available; Incoming is on call despite being away
+}
+
+// RotationAdvancedEvent is the domain event the oncall module publishes when a schedule
+// is handed over, with a RotationAdvanced as its data.
+const RotationAdvancedEvent = "oncall.rotation_advanced"
+
+// RotationAdvanced is the data of a RotationAdvancedEvent.
+type RotationAdvanced struct {
+	Schedule string `json:"schedule"`
+	Outgoing string `json:"outgoing"`
+	Incoming string `json:"incoming"`
+	Conflict bool   `json:"conflict"` // nobody was available
+}
+
+// AdvanceRotation hands a schedule over to the user its policy picks, delivers a handoff
+// report to the incoming person unless the outgoing one stays on call, and publishes a
+// RotationAdvancedEvent.
+func (o *OnCallModule) AdvanceRotation(ctx context.Context, scheduleName string) (*HandoffReport, error) {
+	db := o.database.DB()
+	schedule, err := GetScheduleByName(db, scheduleName)
+	if err != nil || schedule == nil {
+		return nil, fmt.Errorf("schedule not found: %s", scheduleName)
+	}
+	outgoing, err := GetCurrentOnCallUser(db, scheduleName)
+	if err != nil {
+		return nil, err
+	}
+
+	shiftStart, err := lastHandoff(db, schedule)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := AdvanceOnCallSchedule(db, scheduleName); err != nil {
+		return nil, err
+	}
+	incoming, err := GetCurrentOnCallUser(db, scheduleName)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	report, err := o.buildHandoffReport(ctx, schedule, outgoing.GitHub, incoming.GitHub, shiftStart, now)
+	if err != nil {
+		return nil, err
+	}
+	if report.Skipped, report.Conflict, err = o.rotationAvailability(schedule, incoming, now); err != nil {
+		return nil, err
+	}
+	if report.Conflict {
+		slog.Warn("Nobody on the schedule is available; rotated anyway",
+			"schedule", scheduleName, "incoming", incoming.GitHub)
+	}
+	if incoming.ID != outgoing.ID {
+		o.deliverHandoff(ctx, report)
+	}
+	if o.app != nil {
+		o.app.Bus.Publish(ctx, internal.DomainEvent{Name: RotationAdvancedEvent, Module: o.Name(), Data: RotationAdvanced{
+			Schedule: report.Schedule,
+			Outgoing: report.Outgoing,
+			Incoming: report.Incoming,
+			Conflict: report.Conflict,
+		}})
+	}
+	return report, nil
+}
+
+// buildHandoffReport assembles open tasks from the task table and newly opened
+// issues from the event store.
+func (o *OnCallModule) buildHandoffReport(
+	ctx context.Context,
+	schedule *OnCallSchedule,
+	outgoing, incoming string,
+	shiftStart, shiftEnd time.Time,
+) (*HandoffReport, error) {
+	report := &HandoffReport{
+		Schedule:   schedule.Name,
+		Outgoing:   outgoing,
+		Incoming:   incoming,
+		ShiftStart: shiftStart,
+		ShiftEnd:   shiftEnd,
+	}
+
+	tasks, err := ListOpenTasksForSchedule(o.database.DB(), schedule.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list open tasks: %w", err)
+	}
+	for _, t := range tasks {
+		if t.Status == "open" {
+			report.UnackedTasks = append(report.UnackedTasks, t)
+		} else {
+			report.OpenTasks = append(report.OpenTasks, t)
+		}
+	}
+
+	if o.app != nil && o.app.Events != nil {
+		events, err := o.app.Events.Query(ctx, internal.EventQuery{
+			Type:   "issues",
+			Action: "opened",
+			Since:  shiftStart,
+			Until:  shiftEnd,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to query events: %w", err)
+		}
+		for _, e := range events {
+			if !reposInclude(o.app, o.config.Handoff.Repos, e.Repo) {
+				continue
+			}
+			var payload struct {
+				Issue struct {
+					Number  int    `json:"number"`
+					Title   string `json:"title"`
+					HTMLURL string `json:"html_url"`
+				} `json:"issue"`
+			}
+			if err := json.Unmarshal(e.Payload, &payload); err != nil {
+				slog.Warn("Skipping unreadable stored event", "event_id", e.ID, "error", err)
+				continue
+			}
+			report.NewIssues = append(report.NewIssues, HandoffIssue{
+				Repo:   e.Repo,
+				Number: payload.Issue.Number,
+				Title:  payload.Issue.Title,
+				URL:    payload.Issue.HTMLURL,
+			})
+		}
+	}
+
+	return report, nil
+}
+
+// deliverHandoff posts the report to the handoff issue, notifies the routed channels and
+// DMs the incoming person on Slack.
+// Delivery failures are logged; the rotation has already happened.
+func (o *OnCallModule) deliverHandoff(ctx context.Context, report *HandoffReport) {
+	cfg := o.config.Handoff
+	if cfg.Repo != "" && cfg.Issue > 0 {
+		if err := o.PostGitHubComment(cfg.Repo, cfg.Issue, report.Markdown()); err != nil {
+			slog.Error("Failed to post handoff report", "schedule", report.Schedule, "error", err)
+		}
+	}
+
+	if o.app != nil {
+		notification := internal.Notification{
+			Module:   o.Name(),
+			Severity: internal.SeverityInfo,
+			Title:    fmt.Sprintf("On-call handoff for %s: @%s → @%s", report.Schedule, report.Outgoing, report.Incoming),
+			Body: fmt.Sprintf("%d unacknowledged task(s), %d open task(s), %d new issue(s) during the last shift.",
+				len(report.UnackedTasks), len(report.OpenTasks), len(report.NewIssues)),
+		}
+		if cfg.Repo != "" && cfg.Issue > 0 {
+			notification.Repo, notification.Issue = cfg.Repo, cfg.Issue
+			notification.URL = fmt.Sprintf("https://github.com/%s/issues/%d", cfg.Repo, cfg.Issue)
+		}
+		if report.Conflict {
+			notification.Severity = internal.SeverityWarning
+			notification.Body += " Nobody on the schedule is available; cover is needed."
+		}
+		if err := o.app.Notifications.Notify(ctx, notification); err != nil {
+			slog.Error("Failed to notify handoff", "schedule", report.Schedule, "error", err)
+		}
+	}
+
+	slackID := o.config.SlackUsers[report.Incoming]
+	if slackID == "" || o.app == nil || o.app.Slack == nil {
+		return
+	}
+	text := report.Summary()
+	if cfg.Repo != "" && cfg.Issue > 0 {
+		text += fmt.Sprintf("\nFull report: https://github.com/%s/issues/%d", cfg.Repo, cfg.Issue)
+	}
+	if err := o.app.Slack.PostMessage(ctx, slackID, text); err != nil {
+		slog.Error("Failed to send handoff DM", "user", report.Incoming, "error", err)
+	}
+}
+
+// Summary returns a one-paragraph plain-text summary of the report.
+func (r *HandoffReport) Summary() string {
+	summary := fmt.Sprintf("You are now on call for %s (taking over from %s). "+
+		"%d unacknowledged task(s), %d open task(s), %d new issue(s) during the last shift.",
+		r.Schedule, r.Outgoing, len(r.UnackedTasks), len(r.OpenTasks), len(r.NewIssues))
+	if r.Conflict {
+		summary += " Warning: nobody on the schedule is available, including you; please arrange cover."
+	}
+	return summary
+}
+
+// Markdown renders the report as a GitHub comment.
+func (r *HandoffReport) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "### 🔁 On-call handoff: %s\n\n", r.Schedule)
+	fmt.Fprintf(&b, "@%s is now on call, taking over from @%s.\n", r.Incoming, r.Outgoing)
+	fmt.Fprintf(&b, "Shift: %s – %s\n",
+		r.ShiftStart.UTC().Format(time.DateTime), r.ShiftEnd.UTC().Format(time.DateTime))
+	if r.Conflict {
+		fmt.Fprintf(&b, "\n> [!WARNING]\n> Nobody on this schedule is available. @%s is on call but marked out of office; "+
+			"please arrange cover.\n", r.Incoming)
+	} else if len(r.Skipped) > 0 {
+		fmt.Fprintf(&b, "Skipped (out of office): @%s\n", strings.Join(r.Skipped, ", @"))
+	}
+
+	writeTasks := func(heading string, tasks []OnCallTask) {
+		fmt.Fprintf(&b, "\n**%s (%d)**\n", heading, len(tasks))
+		if len(tasks) == 0 {
+			b.WriteString("- none\n")
+		}
+		for _, t := range tasks {
+			fmt.Fprintf(&b, "- %s#%d %s (open since %s)\n",
+				t.Repo, t.IssueNum, t.Title, t.CreatedAt.UTC().Format(time.DateOnly))
+		}
+	}
+	writeTasks("Unacknowledged tasks", r.UnackedTasks)
+	writeTasks("Open tasks", r.OpenTasks)
+
+	fmt.Fprintf(&b, "\n**Issues opened during the shift (%d)**\n", len(r.NewIssues))
+	if len(r.NewIssues) == 0 {
+		b.WriteString("- none\n")
+	}
+	for _, i := range r.NewIssues {
+		fmt.Fprintf(&b, "- %s#%d %s\n", i.Repo, i.Number, i.Title)
+	}
+	return b.String()
+}
+
+// handleRotate advances a schedule from the admin API and returns the handoff report.
+func (o *OnCallModule) handleRotate(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	report, err := o.AdvanceRotation(r.Context(), name)
+	if err != nil {
+		slog.Error("Failed to advance rotation", "schedule", name, "error", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, map[string]any{
+		"schedule":         report.Schedule,
+		"outgoing":         report.Outgoing,
+		"incoming":         report.Incoming,
+		"unacked_tasks":    len(report.UnackedTasks),
+		"open_tasks":       len(report.OpenTasks),
+		"new_issues":       len(report.NewIssues),
+		"skipped":          report.Skipped,
+		"conflict":         report.Conflict,
+		"shift_started_at": report.ShiftStart.UTC().Format(time.RFC3339),
+	})
+}
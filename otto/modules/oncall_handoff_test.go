@@ -0,0 +1,113 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+func TestAdvanceRotationHandoffReport(t *testing.T) {
+	o, fake := newOnCallTestModule(t)
+	db := o.database.DB()
+
+	sch, _ := GetScheduleByName(db, "primary")
+	bob, _ := AddUser(db, "bob", "Bob")
+	_ = AssignUserToSchedule(db, sch.ID, bob.ID, 1)
+
+	events, err := internal.NewEventStore(db)
+	if err != nil {
+		t.Fatalf("NewEventStore failed: %v", err)
+	}
+	o.app.Events = events
+
+	var dms []map[string]string
+	slack := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&msg)
+		dms = append(dms, msg)
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	defer slack.Close()
+	o.app.Slack = internal.NewSlackClient("xoxb-test").WithBaseURL(slack.URL)
+
+	o.config = OnCallConfig{
+		Handoff:    HandoffConfig{Repo: "org/oncall", Issue: 1, Repos: []string{"org/repo"}},
+		SlackUsers: map[string]string{"bob": "U0BOB"},
+	}
+
+	unacked, _ := AddTask(db, sch.ID, "org/repo", 10, "Unacked task", "", 1)
+	acked, _ := AddTask(db, sch.ID, "org/repo", 11, "Acked task", "", 1)
+	done, _ := AddTask(db, sch.ID, "org/repo", 12, "Done task", "", 1)
+	_ = UpdateTaskStatus(db, acked.ID, "ack")
+	_ = UpdateTaskStatus(db, done.ID, "done")
+
+	record := func(repo string, number int, title string, at time.Time) {
+		payload := fmt.Sprintf(`{"action":"opened","repository":{"full_name":%q},"issue":{"number":%d,"title":%q}}`,
+			repo, number, title)
+		e := internal.NewStoredEvent("d", "issues", []byte(payload))
+		e.ReceivedAt = at
+		if _, err := events.Record(t.Context(), e); err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+	}
+	record("org/repo", 20, "During shift", time.Now())
+	record("org/repo", 21, "Before shift", sch.CreatedAt.Add(-time.Hour))
+	record("org/other", 22, "Untracked repo", time.Now())
+
+	o.app.Bus = internal.NewEventBus(nil, nil)
+	var rotations []RotationAdvanced
+	_ = o.app.Bus.Subscribe("test", RotationAdvancedEvent, func(ctx context.Context, e internal.DomainEvent) error {
+		rotations = append(rotations, e.Data.(RotationAdvanced))
+		return nil
+	})
+
+	report, err := o.AdvanceRotation(t.Context(), "primary")
+	if err != nil {
+		t.Fatalf("AdvanceRotation failed: %v", err)
+	}
+
+	if report.Outgoing != "alice" || report.Incoming != "bob" {
+		t.Errorf("handoff %s -> %s, want alice -> bob", report.Outgoing, report.Incoming)
+	}
+	if len(report.UnackedTasks) != 1 || report.UnackedTasks[0].ID != unacked.ID {
+		t.Errorf("unexpected unacked tasks: %+v", report.UnackedTasks)
+	}
+	if len(report.OpenTasks) != 1 || report.OpenTasks[0].ID != acked.ID {
+		t.Errorf("unexpected open tasks: %+v", report.OpenTasks)
+	}
+	if len(report.NewIssues) != 1 || report.NewIssues[0].Number != 20 {
+		t.Errorf("unexpected new issues: %+v", report.NewIssues)
+	}
+
+	comments := fake.commentsOn("org/oncall", 1)
+	if len(comments) != 1 || !strings.Contains(comments[0], "@bob is now on call") ||
+		!strings.Contains(comments[0], "org/repo#20 During shift") {
+		t.Errorf("unexpected handoff comment: %v", comments)
+	}
+	if len(dms) != 1 || dms[0]["channel"] != "U0BOB" || !strings.Contains(dms[0]["text"], "1 unacknowledged") {
+		t.Errorf("unexpected Slack DMs: %v", dms)
+	}
+	o.app.Bus.Wait()
+	if want := (RotationAdvanced{Schedule: "primary", Outgoing: "alice", Incoming: "bob"}); len(rotations) != 1 ||
+		rotations[0] != want {
+		t.Errorf("published rotations = %+v, want %+v", rotations, want)
+	}
+
+	// The next shift starts at this handoff.
+	next, err := o.AdvanceRotation(t.Context(), "primary")
+	if err != nil {
+		t.Fatalf("second AdvanceRotation failed: %v", err)
+	}
+	if next.Outgoing != "bob" || len(next.NewIssues) != 0 {
+		t.Errorf("unexpected second handoff: outgoing %s, %d new issues", next.Outgoing, len(next.NewIssues))
+	}
+}
@@ -16,11 +16,12 @@ type OnCallScheduleRotationPolicy string
 
 // Rotation policy constants define different ways to rotate on-call schedules.
 const (
-	// RoundRobinPolicy assigns tasks in a circular rotation through all users.
+	// RoundRobinPolicy hands over to the next available user in a circular rotation.
 	RoundRobinPolicy OnCallScheduleRotationPolicy = "round-robin"
-	// SequentialPolicy assigns tasks in order by position.
+	// SequentialPolicy hands over to the first available user in order by position, e.g. an
+	// owner backed up by others while away.
 	SequentialPolicy OnCallScheduleRotationPolicy = "sequential"
-	// RandomPolicy assigns tasks randomly among users.
+	// RandomPolicy hands over to a random available user.
 	RandomPolicy OnCallScheduleRotationPolicy = "random"
 )
 
@@ -32,6 +33,16 @@ type OnCallSchedule struct {
 	CurrentRotationIdx int
 	CreatedAt          time.Time
 	UpdatedAt          time.Time
+	// ShiftDuration is how long each person is on call; zero disables automatic rotation.
+	ShiftDuration time.Duration
+	// Timezone is the IANA zone shift boundaries are computed in; empty means UTC.
+	Timezone string
+	// HandoffTime is the local "15:04" time shifts change at; empty keeps the time of day
+	// the schedule was created.
+	HandoffTime string
+	// HandoffCron is a cron expression of the local times shifts change at; it takes the
+	// place of ShiftDuration and HandoffTime when set.
+	HandoffCron string
 }
 
 type OnCallScheduleUser struct {
@@ -40,6 +51,15 @@ type OnCallScheduleUser struct {
 	Position   int
 }
 
+type OnCallRotation struct {
+	ID         int64
+	ScheduleID int64
+	FromIdx    int
+	ToIdx      int
+	UserGitHub string
+	RotatedAt  time.Time
+}
+
 type OnCallTask struct {
 	ID          int64
 	ScheduleID  int64
@@ -52,4 +72,17 @@ type OnCallTask struct {
 	CreatedAt   time.Time
 	AckedAt     *time.Time
 	CompletedAt *time.Time
+	// AssignmentCommentID is the bot comment announcing the assignment; reacting
+	// to it acknowledges the task. Zero if no comment was posted.
+	AssignmentCommentID int64
+}
+
+// OnCallUnavailability is a period during which a user cannot be on call.
+type OnCallUnavailability struct {
+	ID        int64
+	UserID    int64
+	Start     time.Time
+	End       time.Time // exclusive
+	Source    string    // "command" or "ics"
+	CreatedAt time.Time
 }
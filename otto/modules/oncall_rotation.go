@@ -0,0 +1,66 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import "math/rand/v2"
+
+// rotationRand picks a random index below n for the random policy; tests replace it.
+var rotationRand = rand.IntN
+
+// nextRotationIdx returns the index of the member a schedule hands over to under its
+// policy, passing over members who are not available. If nobody is available, round-robin
+// and sequential schedules hand over to the member they would otherwise, and random ones
+// to anyone but the outgoing member.
+func nextRotationIdx(
+	schedule *OnCallSchedule,
+	members []OnCallScheduleUser,
+	available func(userID int64) (bool, error),
+) (int, error) {
+	current := schedule.CurrentRotationIdx % len(members)
+	if schedule.Policy == RandomPolicy {
+		var candidates []int
+		for i, m := range members {
+			if i == current && len(members) > 1 {
+				continue
+			}
+			ok, err := available(m.UserID)
+			if err != nil {
+				return 0, err
+			}
+			if ok {
+				candidates = append(candidates, i)
+			}
+		}
+		if len(candidates) == 0 {
+			return (current + 1 + rotationRand(max(len(members)-1, 1))) % len(members), nil
+		}
+		return candidates[rotationRand(len(candidates))], nil
+	}
+
+	order := rotationOrder(schedule, len(members))
+	for _, idx := range order {
+		ok, err := available(members[idx].UserID)
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			return idx, nil
+		}
+	}
+	return order[0], nil
+}
+
+// rotationOrder returns the indexes of a round-robin or sequential schedule's n members in
+// the order a handoff considers them: from the member after the current one for
+// round-robin, and from the first position for sequential.
+func rotationOrder(schedule *OnCallSchedule, n int) []int {
+	order := make([]int, n)
+	for i := range order {
+		if schedule.Policy == SequentialPolicy {
+			order[i] = i
+		} else {
+			order[i] = (schedule.CurrentRotationIdx + 1 + i) % n
+		}
+	}
+	return order
+}
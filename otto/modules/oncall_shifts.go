@@ -0,0 +1,267 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/go-github/v71/github"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+// ShiftConfig sets when a schedule's shifts change, and to whom.
+type ShiftConfig struct {
+	Duration    time.Duration `yaml:"duration" doc:"e.g. 168h; whole days follow the local calendar across DST"`
+	Timezone    string        `yaml:"timezone" doc:"IANA zone, e.g. 'America/New_York'"`
+	HandoffTime string        `yaml:"handoff_time" doc:"local time of day shifts change, '15:04'"`
+	Cron        string        `yaml:"cron" doc:"local times shifts change, e.g. '0 9 * * MON'; instead of duration"`
+	Policy      string        `yaml:"policy" doc:"'round-robin', 'sequential' or 'random'; empty keeps the stored one"`
+}
+
+// handoffSlack tolerates rotations that run late or early relative to a boundary, so a
+// handoff recorded at 09:00:40 or rotated manually at 08:00 still maps to the 09:00 boundary.
+const handoffSlack = 12 * time.Hour
+
+// applyShiftConfig stores the configured shift settings on their schedules.
+func (o *OnCallModule) applyShiftConfig() error {
+	db := o.database.DB()
+	for name, c := range o.config.Shifts {
+		if c.Timezone != "" {
+			if _, err := time.LoadLocation(c.Timezone); err != nil {
+				return fmt.Errorf("oncall: invalid timezone for schedule %q: %w", name, err)
+			}
+		}
+		if c.HandoffTime != "" {
+			if _, err := time.Parse("15:04", c.HandoffTime); err != nil {
+				return fmt.Errorf("oncall: invalid handoff_time for schedule %q: want HH:MM", name)
+			}
+		}
+		if c.Duration < 0 {
+			return fmt.Errorf("oncall: negative shift duration for schedule %q", name)
+		}
+		if c.Cron != "" {
+			if c.Duration > 0 || c.HandoffTime != "" {
+				return fmt.Errorf("oncall: schedule %q sets cron and duration or handoff_time", name)
+			}
+			if _, err := internal.ParseCron(c.Cron); err != nil {
+				return fmt.Errorf("oncall: invalid cron for schedule %q: %w", name, err)
+			}
+		}
+		if err := SetScheduleShift(db, name, c.Duration, c.Timezone, c.HandoffTime, c.Cron); err != nil {
+			slog.Warn("Shift settings not applied", "schedule", name, "error", err)
+		}
+		switch policy := OnCallScheduleRotationPolicy(c.Policy); policy {
+		case "":
+		case RoundRobinPolicy, SequentialPolicy, RandomPolicy:
+			if err := SetSchedulePolicy(db, name, policy); err != nil {
+				slog.Warn("Rotation policy not applied", "schedule", name, "error", err)
+			}
+		default:
+			return fmt.Errorf("oncall: unknown policy %q for schedule %q", c.Policy, name)
+		}
+	}
+	return nil
+}
+
+// location returns the schedule's time zone, falling back to UTC.
+func (s OnCallSchedule) location() *time.Location {
+	if s.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// handoffClock returns the local hour and minute shifts change at.
+func (s OnCallSchedule) handoffClock(loc *time.Location) (hour, minute int) {
+	if t, err := time.Parse("15:04", s.HandoffTime); err == nil {
+		return t.Hour(), t.Minute()
+	}
+	created := s.CreatedAt.In(loc)
+	return created.Hour(), created.Minute()
+}
+
+// Rotates reports whether the schedule hands over automatically.
+func (s OnCallSchedule) Rotates() bool {
+	return s.ShiftDuration > 0 || s.HandoffCron != ""
+}
+
+// NextHandoff returns the shift boundary following a handoff at last, or the zero time
+// when the schedule does not rotate automatically. With a cron expression, it is the next
+// local time the expression matches. Shifts of whole days change at the local handoff
+// time, so a 09:00 America/New_York handoff stays at 09:00 across DST changes; other
+// durations are added as elapsed time.
+func (s OnCallSchedule) NextHandoff(last time.Time) time.Time {
+	if s.HandoffCron != "" {
+		cron, err := internal.ParseCron(s.HandoffCron)
+		if err != nil {
+			return time.Time{}
+		}
+		return cron.Next(last.In(s.location()))
+	}
+	if s.ShiftDuration <= 0 {
+		return time.Time{}
+	}
+	if s.ShiftDuration%(24*time.Hour) != 0 {
+		return last.Add(s.ShiftDuration)
+	}
+	loc := s.location()
+	hour, minute := s.handoffClock(loc)
+	earliest := last.Add(s.ShiftDuration - handoffSlack).In(loc)
+	next := time.Date(earliest.Year(), earliest.Month(), earliest.Day(), hour, minute, 0, 0, loc)
+	if next.Before(earliest) {
+		next = time.Date(earliest.Year(), earliest.Month(), earliest.Day()+1, hour, minute, 0, 0, loc)
+	}
+	return next
+}
+
+// lastHandoff returns when the current shift began: the latest rotation, or when the
+// schedule was created.
+func lastHandoff(db *sql.DB, schedule *OnCallSchedule) (time.Time, error) {
+	history, err := ListRotationHistory(db, schedule.ID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if len(history) == 0 {
+		return schedule.CreatedAt, nil
+	}
+	return history[len(history)-1].RotatedAt, nil
+}
+
+// RotateDueSchedules advances every enabled schedule whose shift has ended by now.
+func (o *OnCallModule) RotateDueSchedules(ctx context.Context, now time.Time) error {
+	db := o.database.DB()
+	schedules, err := ListSchedules(db)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, s := range schedules {
+		if !s.Enabled || !s.Rotates() {
+			continue
+		}
+		last, err := lastHandoff(db, &s)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if next := s.NextHandoff(last); next.IsZero() || now.Before(next) {
+			continue
+		}
+		if _, err := o.AdvanceRotation(ctx, s.Name); err != nil {
+			errs = append(errs, fmt.Errorf("rotate %s: %w", s.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// handleWho answers `/oncall who [schedule]` with the current on-call person and the next handoff.
+func (o *OnCallModule) handleWho(event *github.IssueCommentEvent, args []string) error {
+	name := o.config.DefaultSchedule
+	if len(args) > 0 {
+		name = args[0]
+	}
+	repo := event.GetRepo().GetFullName()
+	issue := event.GetIssue().GetNumber()
+
+	message, err := o.whoMessage(name, time.Now())
+	if err != nil {
+		message = fmt.Sprintf("⚠️ %v", err)
+	}
+	if err := o.PostGitHubComment(repo, issue, message); err != nil {
+		return LogAndWrapError(err, ErrorTypeCommand, "oncall_who", map[string]any{
+			"repo":     repo,
+			"schedule": name,
+		})
+	}
+	return nil
+}
+
+// whoMessage describes who is on call for a schedule and when the shift changes.
+func (o *OnCallModule) whoMessage(name string, now time.Time) (string, error) {
+	db := o.database.DB()
+	schedule, err := GetScheduleByName(db, name)
+	if err != nil || schedule == nil {
+		return "", fmt.Errorf("schedule not found: %s", name)
+	}
+	current, err := GetCurrentOnCallUser(db, name)
+	if err != nil {
+		return "", err
+	}
+	message := fmt.Sprintf("📟 @%s is on call for **%s**.", current.GitHub, name)
+	if !schedule.Rotates() {
+		return message, nil
+	}
+
+	next, err := nextHandoffAt(db, schedule, now)
+	if err != nil {
+		return "", err
+	}
+	if next.IsZero() {
+		return message, nil
+	}
+	message += fmt.Sprintf(" Next handoff: %s (%s UTC)",
+		next.In(schedule.location()).Format("Mon Jan 2 15:04 MST"), next.UTC().Format("15:04"))
+	if schedule.Policy != RoundRobinPolicy {
+		return message + ".", nil // the incoming member depends on availability or chance
+	}
+	users, err := ListUsersForSchedule(db, schedule.ID)
+	if err == nil && len(users) > 1 {
+		if incoming, err := GetUser(db, users[(schedule.CurrentRotationIdx+1)%len(users)].UserID); err == nil && incoming != nil {
+			message += fmt.Sprintf(" to @%s", incoming.GitHub)
+		}
+	}
+	return message + ".", nil
+}
+
+// nextHandoffAt returns when a rotating schedule is next handed over, or the zero time if
+// it does not rotate.
+func nextHandoffAt(db *sql.DB, schedule *OnCallSchedule, now time.Time) (time.Time, error) {
+	last, err := lastHandoff(db, schedule)
+	if err != nil {
+		return time.Time{}, err
+	}
+	next := schedule.NextHandoff(last)
+	if !next.IsZero() && next.Before(now) {
+		next = now // overdue; the rotation job hands over within a minute
+	}
+	return next, nil
+}
+
+// HandleCommand implements the ModuleCommandHandler interface. It runs `/oncall` and
+// `/availability` slash commands.
+func (o *OnCallModule) HandleCommand(cmd *internal.CommandContext) error {
+	event := cmd.Event
+	if cmd.Command == "availability" {
+		return o.handleAvailability(event, cmd.Args)
+	}
+	if len(cmd.Args) == 0 {
+		return nil
+	}
+	switch cmd.Args[0] {
+	case "who":
+		return o.handleWho(event, cmd.Args[1:])
+	case "ooo":
+		return o.handleOOO(event, cmd.Args[1:])
+	case "done":
+		return o.handleDone(cmd.Context, event)
+	case "ack":
+		return o.handleAck(cmd.Context, event)
+	case "assign":
+		return o.handleAssign(cmd.Context, event, cmd.Args[1:])
+	case "next":
+		return o.handleNext(cmd.Context, event, cmd.Args[1:])
+	case "schedule":
+		return o.handleSchedule(event, cmd.Args[1:])
+	}
+	return nil
+}
@@ -0,0 +1,203 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+func TestNextHandoff(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+	day := 24 * time.Hour
+
+	tests := []struct {
+		name     string
+		schedule OnCallSchedule
+		last     time.Time
+		want     time.Time
+	}{
+		{
+			name:     "no shift duration",
+			schedule: OnCallSchedule{},
+			last:     time.Date(2024, 3, 9, 9, 0, 0, 0, ny),
+			want:     time.Time{},
+		},
+		{
+			name:     "daily across spring forward",
+			schedule: OnCallSchedule{ShiftDuration: day, Timezone: "America/New_York", HandoffTime: "09:00"},
+			last:     time.Date(2024, 3, 9, 9, 0, 40, 0, ny),
+			want:     time.Date(2024, 3, 10, 13, 0, 0, 0, time.UTC), // 09:00 EDT
+		},
+		{
+			name:     "weekly across fall back",
+			schedule: OnCallSchedule{ShiftDuration: 7 * day, Timezone: "America/New_York", HandoffTime: "09:00"},
+			last:     time.Date(2024, 11, 1, 13, 0, 0, 0, time.UTC), // 09:00 EDT
+			want:     time.Date(2024, 11, 8, 14, 0, 0, 0, time.UTC), // 09:00 EST
+		},
+		{
+			name:     "manual rotation before handoff time",
+			schedule: OnCallSchedule{ShiftDuration: day, Timezone: "America/New_York", HandoffTime: "09:00"},
+			last:     time.Date(2024, 6, 3, 8, 0, 0, 0, ny),
+			want:     time.Date(2024, 6, 4, 9, 0, 0, 0, ny),
+		},
+		{
+			name: "handoff time defaults to creation time",
+			schedule: OnCallSchedule{
+				ShiftDuration: day,
+				CreatedAt:     time.Date(2024, 6, 1, 17, 30, 0, 0, time.UTC),
+			},
+			last: time.Date(2024, 6, 3, 17, 31, 0, 0, time.UTC),
+			want: time.Date(2024, 6, 4, 17, 30, 0, 0, time.UTC),
+		},
+		{
+			name:     "sub-day shifts use elapsed time",
+			schedule: OnCallSchedule{ShiftDuration: 8 * time.Hour, Timezone: "America/New_York", HandoffTime: "09:00"},
+			last:     time.Date(2024, 6, 3, 10, 15, 0, 0, ny),
+			want:     time.Date(2024, 6, 3, 18, 15, 0, 0, ny),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.schedule.NextHandoff(tt.last); !got.Equal(tt.want) {
+				t.Errorf("NextHandoff = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRotateDueSchedulesAndWho(t *testing.T) {
+	o, fake := newOnCallTestModule(t)
+	o.config.DefaultSchedule = "primary"
+	db := o.database.DB()
+	sch, _ := GetScheduleByName(db, "primary")
+	bob, _ := AddUser(db, "bob", "Bob")
+	_ = AssignUserToSchedule(db, sch.ID, bob.ID, 1)
+	if err := SetScheduleShift(db, "primary", 24*time.Hour, "UTC", "09:00", ""); err != nil {
+		t.Fatalf("SetScheduleShift failed: %v", err)
+	}
+
+	if err := internal.TestCommands(t, o, commentEvent("org/repo", 3, "carol", "/oncall who")); err != nil {
+		t.Fatalf("HandleCommand failed: %v", err)
+	}
+	comments := fake.commentsOn("org/repo", 3)
+	if len(comments) != 1 || !strings.Contains(comments[0], "@alice is on call for **primary**") ||
+		!strings.Contains(comments[0], "Next handoff:") || !strings.Contains(comments[0], "09:00 UTC) to @bob") {
+		t.Fatalf("unexpected who reply: %v", comments)
+	}
+
+	// The shift has not ended yet.
+	if err := o.RotateDueSchedules(t.Context(), time.Now()); err != nil {
+		t.Fatalf("RotateDueSchedules failed: %v", err)
+	}
+	if user, _ := GetCurrentOnCallUser(db, "primary"); user.GitHub != "alice" {
+		t.Fatalf("rotated early to %s", user.GitHub)
+	}
+
+	if err := o.RotateDueSchedules(t.Context(), time.Now().Add(48*time.Hour)); err != nil {
+		t.Fatalf("RotateDueSchedules failed: %v", err)
+	}
+	if user, _ := GetCurrentOnCallUser(db, "primary"); user.GitHub != "bob" {
+		t.Fatalf("on call = %s, want bob", user.GitHub)
+	}
+
+	// The new shift started at the rotation, so it is not due again a few hours later.
+	if err := o.RotateDueSchedules(t.Context(), time.Now().Add(6*time.Hour)); err != nil {
+		t.Fatalf("RotateDueSchedules failed: %v", err)
+	}
+	if user, _ := GetCurrentOnCallUser(db, "primary"); user.GitHub != "bob" {
+		t.Errorf("rotated twice; on call = %s", user.GitHub)
+	}
+}
+
+func TestRotationPolicies(t *testing.T) {
+	o, _ := newOnCallTestModule(t)
+	db := o.database.DB()
+	sch, _ := GetScheduleByName(db, "primary")
+	for i, login := range []string{"bob", "carol"} {
+		u, _ := AddUser(db, login, login)
+		_ = AssignUserToSchedule(db, sch.ID, u.ID, i+1)
+	}
+	bob, _ := GetUserByGitHub(db, "bob")
+	rotate := func() string {
+		t.Helper()
+		if _, err := o.AdvanceRotation(t.Context(), "primary"); err != nil {
+			t.Fatalf("AdvanceRotation failed: %v", err)
+		}
+		user, err := GetCurrentOnCallUser(db, "primary")
+		if err != nil {
+			t.Fatalf("GetCurrentOnCallUser failed: %v", err)
+		}
+		return user.GitHub
+	}
+
+	// Sequential schedules go to the first available member by position.
+	if err := SetSchedulePolicy(db, "primary", SequentialPolicy); err != nil {
+		t.Fatalf("SetSchedulePolicy failed: %v", err)
+	}
+	if got := rotate(); got != "alice" {
+		t.Errorf("sequential handoff to %s, want alice", got)
+	}
+	alice, _ := GetUserByGitHub(db, "alice")
+	now := time.Now()
+	if err := AddUnavailability(db, alice.ID, now.Add(-time.Hour), now.Add(time.Hour), "command"); err != nil {
+		t.Fatalf("AddUnavailability failed: %v", err)
+	}
+	if got := rotate(); got != "bob" {
+		t.Errorf("sequential handoff with alice away to %s, want bob", got)
+	}
+
+	// Random schedules go to an available member other than the outgoing one.
+	if err := SetSchedulePolicy(db, "primary", RandomPolicy); err != nil {
+		t.Fatalf("SetSchedulePolicy failed: %v", err)
+	}
+	defer func(r func(int) int) { rotationRand = r }(rotationRand)
+	var choices []int
+	rotationRand = func(n int) int {
+		choices = append(choices, n)
+		return n - 1
+	}
+	if got := rotate(); got != "carol" || !slices.Equal(choices, []int{1}) {
+		t.Errorf("random handoff from bob to %s among %v, want carol as the only candidate", got, choices)
+	}
+	if err := AddUnavailability(db, bob.ID, now.Add(-time.Hour), now.Add(time.Hour), "command"); err != nil {
+		t.Fatalf("AddUnavailability failed: %v", err)
+	}
+	if got := rotate(); got != "bob" {
+		t.Errorf("random handoff with everyone else away to %s, want bob", got)
+	}
+}
+
+func TestCronHandoffs(t *testing.T) {
+	o, _ := newOnCallTestModule(t)
+	o.config.Shifts = map[string]ShiftConfig{"primary": {Cron: "0 9 * * MON", Timezone: "UTC", Policy: "sequential"}}
+	if err := o.applyShiftConfig(); err != nil {
+		t.Fatalf("applyShiftConfig failed: %v", err)
+	}
+	sch, _ := GetScheduleByName(o.database.DB(), "primary")
+	if !sch.Rotates() || sch.Policy != SequentialPolicy {
+		t.Fatalf("schedule = %+v", sch)
+	}
+	last := time.Date(2025, 3, 5, 10, 30, 0, 0, time.UTC)
+	if next := sch.NextHandoff(last); !next.Equal(time.Date(2025, 3, 10, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("NextHandoff = %v, want Monday 09:00", next)
+	}
+
+	for _, c := range []ShiftConfig{
+		{Cron: "0 9 * *"},
+		{Cron: "0 9 * * MON", Duration: 24 * time.Hour},
+		{Policy: "alphabetical"},
+	} {
+		o.config.Shifts = map[string]ShiftConfig{"primary": c}
+		if err := o.applyShiftConfig(); err == nil {
+			t.Errorf("applyShiftConfig accepted %+v", c)
+		}
+	}
+}
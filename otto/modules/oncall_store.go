@@ -3,15 +3,27 @@
 package modules
 
 import (
+	"context"
 	"database/sql"
+	"embed"
 	"errors"
 	"fmt"
+	"io/fs"
 	"log/slog"
+	"slices"
 	"time"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
 )
 
 // Migration, AddUser, AddSchedule, AssignUserToSchedule, etc.
 
+// onCallMigrations are the versioned migrations applied after the tables and columns
+// below; later schema changes go there rather than into the column list.
+//
+//go:embed migrations/oncall/*.sql
+var onCallMigrations embed.FS
+
 func AutoMigrateOnCall(db *sql.DB) error {
 	stmts := []string{
 		`CREATE TABLE IF NOT EXISTS oncall_users (
@@ -53,15 +65,149 @@ func AutoMigrateOnCall(db *sql.DB) error {
 			FOREIGN KEY(schedule_id) REFERENCES oncall_schedules(id),
 			FOREIGN KEY(assigned_to) REFERENCES oncall_users(id)
 		);`,
+		`CREATE TABLE IF NOT EXISTS oncall_rotation_history (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			schedule_id INTEGER NOT NULL,
+			from_idx INTEGER NOT NULL,
+			to_idx INTEGER NOT NULL,
+			user_id INTEGER,
+			rotated_at TIMESTAMP NOT NULL,
+			FOREIGN KEY(schedule_id) REFERENCES oncall_schedules(id)
+		);`,
+		`CREATE TABLE IF NOT EXISTS oncall_unavailability (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER NOT NULL,
+			starts_at TIMESTAMP NOT NULL,
+			ends_at TIMESTAMP NOT NULL,
+			source TEXT NOT NULL,
+			created_at TIMESTAMP NOT NULL,
+			FOREIGN KEY(user_id) REFERENCES oncall_users(id)
+		);`,
+		`CREATE INDEX IF NOT EXISTS idx_oncall_unavailability_user ON oncall_unavailability (user_id, ends_at);`,
 	}
 	for _, s := range stmts {
 		if _, err := db.Exec(s); err != nil {
 			return fmt.Errorf("failed migration: %w (SQL: %s)", err, s)
 		}
 	}
+	// Columns added after the initial release
+	columns := []struct{ table, column, definition string }{
+		{"oncall_tasks", "assignment_comment_id", "INTEGER"},
+		{"oncall_schedules", "shift_duration_seconds", "INTEGER NOT NULL DEFAULT 0"},
+		{"oncall_schedules", "timezone", "TEXT NOT NULL DEFAULT ''"},
+		{"oncall_schedules", "handoff_time", "TEXT NOT NULL DEFAULT ''"},
+		{"oncall_schedules", "overflow_idx", "INTEGER NOT NULL DEFAULT 0"},
+		{"oncall_schedules", "handoff_cron", "TEXT NOT NULL DEFAULT ''"},
+		{"oncall_users", "busy", "BOOLEAN NOT NULL DEFAULT 0"},
+	}
+	for _, c := range columns {
+		if err := ensureColumn(db, c.table, c.column, c.definition); err != nil {
+			return err
+		}
+	}
+	migrations, err := fs.Sub(onCallMigrations, "migrations/oncall")
+	if err != nil {
+		return err
+	}
+	return internal.VersionedMigrations("oncall", migrations)(db)
+}
+
+// Migrate implements the ModuleMigrator interface.
+func (o *OnCallModule) Migrate(db *sql.DB) error {
+	return AutoMigrateOnCall(db)
+}
+
+// ensureColumn adds a column to an existing table if it is missing.
+func ensureColumn(db *sql.DB, table, column, definition string) error {
+	rows, err := db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return fmt.Errorf("failed to inspect table %s: %w", table, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			cid       int
+			name      string
+			colType   string
+			notNull   bool
+			dfltValue sql.NullString
+			pk        int
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dfltValue, &pk); err != nil {
+			return err
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	stmt := fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition)
+	if _, err := db.Exec(stmt); err != nil {
+		return fmt.Errorf("failed migration: %w (SQL: %s)", err, stmt)
+	}
 	return nil
 }
 
+// rowScanner is satisfied by *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scheduleColumns is the standard column list read by scanSchedule.
+const scheduleColumns = `id, name, policy, enabled, current_rotation_idx, created_at, updated_at,
+	shift_duration_seconds, timezone, handoff_time, handoff_cron`
+
+// scanSchedule reads an oncall_schedules row selected with scheduleColumns.
+func scanSchedule(row rowScanner) (*OnCallSchedule, error) {
+	var s OnCallSchedule
+	var shiftSeconds int64
+	err := row.Scan(
+		&s.ID,
+		&s.Name,
+		&s.Policy,
+		&s.Enabled,
+		&s.CurrentRotationIdx,
+		&s.CreatedAt,
+		&s.UpdatedAt,
+		&shiftSeconds,
+		&s.Timezone,
+		&s.HandoffTime,
+		&s.HandoffCron,
+	)
+	if err != nil {
+		return nil, err
+	}
+	s.ShiftDuration = time.Duration(shiftSeconds) * time.Second
+	return &s, nil
+}
+
+// scanTask reads an oncall_tasks row selected with the standard column list.
+func scanTask(row rowScanner) (*OnCallTask, error) {
+	var t OnCallTask
+	var commentID sql.NullInt64
+	err := row.Scan(
+		&t.ID,
+		&t.ScheduleID,
+		&t.Repo,
+		&t.IssueNum,
+		&t.Title,
+		&t.Description,
+		&t.Status,
+		&t.AssignedTo,
+		&t.CreatedAt,
+		&t.AckedAt,
+		&t.CompletedAt,
+		&commentID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	t.AssignmentCommentID = commentID.Int64
+	return &t, nil
+}
+
 func AddUser(db *sql.DB, gh, name string) (*OnCallUser, error) {
 	now := time.Now()
 	res, err := db.Exec(
@@ -124,24 +270,55 @@ func AssignUserToSchedule(db *sql.DB, scheduleID, userID int64, position int) er
 }
 
 func GetScheduleByName(db *sql.DB, name string) (*OnCallSchedule, error) {
-	row := db.QueryRow(
-		`SELECT id, name, policy, enabled, current_rotation_idx, created_at, updated_at FROM oncall_schedules WHERE name = ?`,
-		name,
-	)
-	var s OnCallSchedule
-	err := row.Scan(
-		&s.ID,
-		&s.Name,
-		&s.Policy,
-		&s.Enabled,
-		&s.CurrentRotationIdx,
-		&s.CreatedAt,
-		&s.UpdatedAt,
-	)
+	row := db.QueryRow(`SELECT `+scheduleColumns+` FROM oncall_schedules WHERE name = ?`, name)
+	s, err := scanSchedule(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return s, err
+}
+
+// GetSchedule returns a schedule by ID, or nil if there is none.
+func GetSchedule(db *sql.DB, id int64) (*OnCallSchedule, error) {
+	row := db.QueryRow(`SELECT `+scheduleColumns+` FROM oncall_schedules WHERE id = ?`, id)
+	s, err := scanSchedule(row)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
-	return &s, err
+	return s, err
+}
+
+func SetScheduleShift(db *sql.DB, name string, duration time.Duration, timezone, handoffTime, cron string) error {
+	res, err := db.Exec(
+		`UPDATE oncall_schedules SET shift_duration_seconds = ?, timezone = ?, handoff_time = ?, handoff_cron = ?, `+
+			`updated_at = ? WHERE name = ?`,
+		int64(duration/time.Second),
+		timezone,
+		handoffTime,
+		cron,
+		time.Now(),
+		name,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("schedule not found: %s", name)
+	}
+	return nil
+}
+
+// SetSchedulePolicy changes the rotation policy of a schedule.
+func SetSchedulePolicy(db *sql.DB, name string, policy OnCallScheduleRotationPolicy) error {
+	res, err := db.Exec(`UPDATE oncall_schedules SET policy = ?, updated_at = ? WHERE name = ?`,
+		string(policy), time.Now(), name)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("schedule not found: %s", name)
+	}
+	return nil
 }
 
 func GetCurrentOnCallUser(db *sql.DB, scheduleName string) (*OnCallUser, error) {
@@ -157,10 +334,10 @@ func GetCurrentOnCallUser(db *sql.DB, scheduleName string) (*OnCallUser, error)
 		return nil, fmt.Errorf("no users found in schedule: %s", scheduleName)
 	}
 
-	// For round-robin, use current rotation index
+	// Every policy records the member on call as the current rotation index
 	var currentUser OnCallUser
 	switch schedule.Policy {
-	case RoundRobinPolicy:
+	case RoundRobinPolicy, SequentialPolicy, RandomPolicy:
 		idx := schedule.CurrentRotationIdx % len(users)
 		currentUserSchedule := users[idx]
 		row := db.QueryRow(
@@ -197,20 +374,103 @@ func AdvanceOnCallSchedule(db *sql.DB, scheduleName string) error {
 		return fmt.Errorf("no users found in schedule: %s", scheduleName)
 	}
 
-	// Increment rotation index
-	newRotationIdx := (schedule.CurrentRotationIdx + 1) % len(users)
+	// Hand over to the member the schedule's policy picks
+	now := time.Now()
+	newRotationIdx, err := nextRotationIdx(schedule, users, func(userID int64) (bool, error) {
+		return IsUserAvailable(db, userID, now)
+	})
+	if err != nil {
+		return err
+	}
 
 	// Update the schedule's current rotation index
 	_, err = db.Exec(
 		`UPDATE oncall_schedules SET current_rotation_idx = ?, updated_at = ? WHERE id = ?`,
 		newRotationIdx,
-		time.Now(),
+		now,
 		schedule.ID,
 	)
+	if err != nil {
+		return err
+	}
 
+	// Record the handoff for rotation history exports
+	_, err = db.Exec(
+		`INSERT INTO oncall_rotation_history (schedule_id, from_idx, to_idx, user_id, rotated_at) VALUES (?, ?, ?, ?, ?)`,
+		schedule.ID,
+		schedule.CurrentRotationIdx,
+		newRotationIdx,
+		users[newRotationIdx].UserID,
+		now,
+	)
 	return err
 }
 
+func ListSchedules(db *sql.DB) ([]OnCallSchedule, error) {
+	rows, err := db.Query(`SELECT ` + scheduleColumns + ` FROM oncall_schedules ORDER BY id ASC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var schedules []OnCallSchedule
+	for rows.Next() {
+		s, err := scanSchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, *s)
+	}
+	return schedules, rows.Err()
+}
+
+func ListRotationHistory(db *sql.DB, scheduleID int64) ([]OnCallRotation, error) {
+	rows, err := db.Query(
+		`SELECT h.id, h.schedule_id, h.from_idx, h.to_idx, COALESCE(u.github, ''), h.rotated_at
+		 FROM oncall_rotation_history h LEFT JOIN oncall_users u ON u.id = h.user_id
+		 WHERE h.schedule_id = ? ORDER BY h.rotated_at ASC, h.id ASC`,
+		scheduleID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var history []OnCallRotation
+	for rows.Next() {
+		var r OnCallRotation
+		if err := rows.Scan(&r.ID, &r.ScheduleID, &r.FromIdx, &r.ToIdx, &r.UserGitHub, &r.RotatedAt); err != nil {
+			return nil, err
+		}
+		history = append(history, r)
+	}
+	return history, rows.Err()
+}
+
+func GetUser(db *sql.DB, id int64) (*OnCallUser, error) {
+	row := db.QueryRow(
+		`SELECT id, github, display_name, active, created_at FROM oncall_users WHERE id = ?`,
+		id,
+	)
+	var u OnCallUser
+	err := row.Scan(&u.ID, &u.GitHub, &u.DisplayName, &u.Active, &u.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return &u, err
+}
+
+func GetUserByGitHub(db *sql.DB, gh string) (*OnCallUser, error) {
+	row := db.QueryRow(
+		`SELECT id, github, display_name, active, created_at FROM oncall_users WHERE github = ?`,
+		gh,
+	)
+	var u OnCallUser
+	err := row.Scan(&u.ID, &u.GitHub, &u.DisplayName, &u.Active, &u.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return &u, err
+}
+
 func ListUsersForSchedule(db *sql.DB, scheduleID int64) ([]OnCallScheduleUser, error) {
 	rows, err := db.Query(
 		`SELECT schedule_id, user_id, position FROM oncall_schedules_users WHERE schedule_id = ? ORDER BY position ASC`,
@@ -269,33 +529,25 @@ func AddTask(
 
 func GetTaskByIssueNumber(db *sql.DB, repo string, issueNum int) (*OnCallTask, error) {
 	row := db.QueryRow(
-		`SELECT id, schedule_id, repo, issue_num, title, description, status, assigned_to, created_at, acked_at, completed_at
+		`SELECT id, schedule_id, repo, issue_num, title, description, status, assigned_to, created_at, acked_at, completed_at, assignment_comment_id
 		 FROM oncall_tasks WHERE repo = ? AND issue_num = ?`,
 		repo,
 		issueNum,
 	)
-	var t OnCallTask
-	err := row.Scan(
-		&t.ID,
-		&t.ScheduleID,
-		&t.Repo,
-		&t.IssueNum,
-		&t.Title,
-		&t.Description,
-		&t.Status,
-		&t.AssignedTo,
-		&t.CreatedAt,
-		&t.AckedAt,
-		&t.CompletedAt,
-	)
+	t, err := scanTask(row)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
-	return &t, err
+	return t, err
 }
 
 func UpdateTaskStatus(db *sql.DB, id int64, status string) error {
-	now := time.Now()
+	return UpdateTaskStatusAt(db, id, status, time.Now())
+}
+
+// UpdateTaskStatusAt is UpdateTaskStatus with an explicit transition time, e.g. the
+// time a reaction was left rather than the time it was observed.
+func UpdateTaskStatusAt(db *sql.DB, id int64, status string, now time.Time) error {
 	var tsField string
 	switch status {
 	case "ack":
@@ -357,27 +609,262 @@ func UpdateTaskStatus(db *sql.DB, id int64, status string) error {
 	return nil
 }
 
+// ListTasks returns the tasks matching a list query of the admin API.
+func ListTasks(ctx context.Context, db *sql.DB, q internal.ListQuery) ([]OnCallTask, error) {
+	query, args := q.SQL(`SELECT id, schedule_id, repo, issue_num, title, description, status, assigned_to, ` +
+		`created_at, acked_at, completed_at, assignment_comment_id FROM oncall_tasks`)
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var tasks []OnCallTask
+	for rows.Next() {
+		t, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, *t)
+	}
+	return tasks, rows.Err()
+}
+
+func SetTaskAssignmentComment(db *sql.DB, taskID, commentID int64) error {
+	_, err := db.Exec(`UPDATE oncall_tasks SET assignment_comment_id = ? WHERE id = ?`, commentID, taskID)
+	return err
+}
+
+// ListAwaitingAckTasks returns open tasks whose assignment comment can be checked for reactions.
+func ListAwaitingAckTasks(db *sql.DB) ([]OnCallTask, error) {
+	rows, err := db.Query(
+		`SELECT id, schedule_id, repo, issue_num, title, description, status, assigned_to, created_at, acked_at, completed_at, assignment_comment_id
+		 FROM oncall_tasks WHERE status = 'open' AND assignment_comment_id IS NOT NULL ORDER BY created_at ASC`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var tasks []OnCallTask
+	for rows.Next() {
+		t, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, *t)
+	}
+	return tasks, rows.Err()
+}
+
+func ListOpenTasksForSchedule(db *sql.DB, scheduleID int64) ([]OnCallTask, error) {
+	rows, err := db.Query(
+		`SELECT id, schedule_id, repo, issue_num, title, description, status, assigned_to, created_at, acked_at, completed_at, assignment_comment_id
+		 FROM oncall_tasks WHERE schedule_id = ? AND status != 'done' ORDER BY created_at ASC, id ASC`,
+		scheduleID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var tasks []OnCallTask
+	for rows.Next() {
+		t, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, *t)
+	}
+	return tasks, rows.Err()
+}
+
+// ListUnfinishedIssueTasks returns the tasks linked to an issue that are not done.
+func ListUnfinishedIssueTasks(db *sql.DB) ([]OnCallTask, error) {
+	rows, err := db.Query(
+		`SELECT id, schedule_id, repo, issue_num, title, description, status, assigned_to, created_at, acked_at, ` +
+			`completed_at, assignment_comment_id FROM oncall_tasks ` +
+			`WHERE status != 'done' AND repo != '' AND issue_num > 0 ORDER BY id ASC`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var tasks []OnCallTask
+	for rows.Next() {
+		t, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, *t)
+	}
+	return tasks, rows.Err()
+}
+
+// ReopenTask moves a done task back to "ack" if it was acknowledged, or to "open".
+func ReopenTask(db *sql.DB, id int64) error {
+	_, err := db.Exec(
+		`UPDATE oncall_tasks SET status = CASE WHEN acked_at IS NULL THEN 'open' ELSE 'ack' END, `+
+			`completed_at = NULL WHERE id = ? AND status = 'done'`,
+		id,
+	)
+	return err
+}
+
+// MarkTaskEscalated records that a task was escalated, so it is escalated only once.
+func MarkTaskEscalated(db *sql.DB, id int64, at time.Time) error {
+	_, err := db.Exec(`UPDATE oncall_tasks SET escalated_at = ? WHERE id = ?`, at, id)
+	return err
+}
+
+// ReassignTask assigns an unfinished task to another user. The task is open again until
+// the new assignee acknowledges it.
+func ReassignTask(db *sql.DB, id, userID int64) error {
+	res, err := db.Exec(
+		`UPDATE oncall_tasks SET assigned_to = ?, status = 'open', acked_at = NULL, assignment_comment_id = NULL `+
+			`WHERE id = ? AND status != 'done'`,
+		userID, id,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("no unfinished task with id %d", id)
+	}
+	return nil
+}
+
 func GetTask(db *sql.DB, id int64) (*OnCallTask, error) {
 	row := db.QueryRow(
-		`SELECT id, schedule_id, repo, issue_num, title, description, status, assigned_to, created_at, acked_at, completed_at FROM oncall_tasks WHERE id = ?`,
+		`SELECT id, schedule_id, repo, issue_num, title, description, status, assigned_to, created_at, acked_at, completed_at, assignment_comment_id FROM oncall_tasks WHERE id = ?`,
 		id,
 	)
-	var t OnCallTask
-	err := row.Scan(
-		&t.ID,
-		&t.ScheduleID,
-		&t.Repo,
-		&t.IssueNum,
-		&t.Title,
-		&t.Description,
-		&t.Status,
-		&t.AssignedTo,
-		&t.CreatedAt,
-		&t.AckedAt,
-		&t.CompletedAt,
-	)
+	t, err := scanTask(row)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
-	return &t, err
+	return t, err
+}
+
+func AddUnavailability(db *sql.DB, userID int64, start, end time.Time, source string) error {
+	if !end.After(start) {
+		return fmt.Errorf("unavailability must end after it starts")
+	}
+	_, err := db.Exec(
+		`INSERT INTO oncall_unavailability (user_id, starts_at, ends_at, source, created_at) VALUES (?, ?, ?, ?, ?)`,
+		userID, start.UTC(), end.UTC(), source, time.Now(),
+	)
+	return err
+}
+
+func ReplaceUnavailability(db *sql.DB, userID int64, source string, ranges []OnCallUnavailability) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+	if _, err := tx.Exec(`DELETE FROM oncall_unavailability WHERE user_id = ? AND source = ?`, userID, source); err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, r := range ranges {
+		if !r.End.After(r.Start) {
+			continue
+		}
+		_, err := tx.Exec(
+			`INSERT INTO oncall_unavailability (user_id, starts_at, ends_at, source, created_at) VALUES (?, ?, ?, ?, ?)`,
+			userID, r.Start.UTC(), r.End.UTC(), source, now,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func ListUnavailability(db *sql.DB, userID int64, since time.Time) ([]OnCallUnavailability, error) {
+	rows, err := db.Query(
+		`SELECT id, user_id, starts_at, ends_at, source, created_at FROM oncall_unavailability
+		 WHERE user_id = ? AND ends_at > ? ORDER BY starts_at ASC`,
+		userID, since.UTC(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []OnCallUnavailability
+	for rows.Next() {
+		var u OnCallUnavailability
+		if err := rows.Scan(&u.ID, &u.UserID, &u.Start, &u.End, &u.Source, &u.CreatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, u)
+	}
+	return out, rows.Err()
+}
+
+func IsUserAvailable(db *sql.DB, userID int64, at time.Time) (bool, error) {
+	var n int
+	err := db.QueryRow(
+		`SELECT COUNT(*) FROM oncall_unavailability WHERE user_id = ? AND starts_at <= ? AND ends_at > ?`,
+		userID, at.UTC(), at.UTC(),
+	).Scan(&n)
+	return n == 0, err
+}
+
+// SetUserBusy pauses or resumes new task assignments to a user.
+func SetUserBusy(db *sql.DB, userID int64, busy bool) error {
+	_, err := db.Exec(`UPDATE oncall_users SET busy = ? WHERE id = ?`, busy, userID)
+	return err
+}
+
+// IsUserBusy reports whether a user has paused new task assignments.
+func IsUserBusy(db *sql.DB, userID int64) (bool, error) {
+	var busy bool
+	err := db.QueryRow(`SELECT busy FROM oncall_users WHERE id = ?`, userID).Scan(&busy)
+	return busy, err
+}
+
+// CountOpenTasksForUser returns the number of tasks assigned to a user that are not done.
+func CountOpenTasksForUser(db *sql.DB, userID int64) (int, error) {
+	var n int
+	err := db.QueryRow(`SELECT COUNT(*) FROM oncall_tasks WHERE assigned_to = ? AND status != 'done'`, userID).Scan(&n)
+	return n, err
+}
+
+// GetOverflowIdx returns the position in a schedule from which tasks the on-call user
+// cannot take are assigned round-robin.
+func GetOverflowIdx(db *sql.DB, scheduleID int64) (int, error) {
+	var idx int
+	err := db.QueryRow(`SELECT overflow_idx FROM oncall_schedules WHERE id = ?`, scheduleID).Scan(&idx)
+	return idx, err
+}
+
+// SetOverflowIdx records the position the next overflow assignment starts from.
+func SetOverflowIdx(db *sql.DB, scheduleID int64, idx int) error {
+	_, err := db.Exec(`UPDATE oncall_schedules SET overflow_idx = ? WHERE id = ?`, idx, scheduleID)
+	return err
+}
+
+// ListAckLatencies returns how long the tasks acknowledged in [start, end) waited for
+// their acknowledgement, shortest first.
+func ListAckLatencies(db *sql.DB, start, end time.Time) ([]time.Duration, error) {
+	rows, err := db.Query(
+		`SELECT created_at, acked_at FROM oncall_tasks WHERE acked_at >= ? AND acked_at < ?`, start, end)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var latencies []time.Duration
+	for rows.Next() {
+		var created, acked time.Time
+		if err := rows.Scan(&created, &acked); err != nil {
+			return nil, err
+		}
+		latencies = append(latencies, max(acked.Sub(created), 0))
+	}
+	slices.Sort(latencies)
+	return latencies, rows.Err()
+}
+
+// RenameRepo implements the ModuleRepoRenamer interface.
+func (o *OnCallModule) RenameRepo(ctx context.Context, from, to string) error {
+	return internal.RenameRepoIn(ctx, o.database.DB(), from, to, "oncall_tasks.repo")
 }
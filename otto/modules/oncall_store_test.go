@@ -4,7 +4,11 @@ package modules
 
 import (
 	"database/sql"
+	"slices"
 	"testing"
+	"time"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
 )
 
 func openTestDB(t *testing.T) *sql.DB {
@@ -59,3 +63,44 @@ func TestTaskAcknowledge(t *testing.T) {
 		t.Errorf("expected status 'ack', got %q", updated.Status)
 	}
 }
+
+func TestOnCallAckLatencyRollups(t *testing.T) {
+	db := openTestDB(t)
+	sch, _ := AddSchedule(db, "primary", "round-robin")
+	user, _ := AddUser(db, "a", "A")
+	day := time.Date(2025, 3, 9, 0, 0, 0, 0, time.UTC)
+	for i, wait := range []time.Duration{time.Hour, 10 * time.Minute, 2 * time.Hour, 0} {
+		task, err := AddTask(db, sch.ID, "org/repo", i+1, "t", "desc", user.ID)
+		if err != nil {
+			t.Fatalf("AddTask failed: %v", err)
+		}
+		created := day.Add(8 * time.Hour)
+		if _, err := db.Exec(`UPDATE oncall_tasks SET created_at = ? WHERE id = ?`, created, task.ID); err != nil {
+			t.Fatalf("update failed: %v", err)
+		}
+		if wait == 0 {
+			continue // not acknowledged
+		}
+		if err := UpdateTaskStatusAt(db, task.ID, "ack", created.Add(wait)); err != nil {
+			t.Fatalf("UpdateTaskStatusAt failed: %v", err)
+		}
+	}
+
+	mod := &OnCallModule{database: internal.NewDatabaseFromDB(db)}
+	got, err := mod.Rollups(t.Context(), day, day.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatalf("Rollups failed: %v", err)
+	}
+	want := []internal.Rollup{
+		{Metric: "oncall_acks", Value: 3},
+		{Metric: "oncall_ack_latency_p50", Value: 3600},
+		{Metric: "oncall_ack_latency_p90", Value: 7200},
+		{Metric: "oncall_ack_latency_p99", Value: 7200},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("rollups = %+v, want %+v", got, want)
+	}
+	if got, _ := mod.Rollups(t.Context(), day.AddDate(0, 0, 1), day.AddDate(0, 0, 2)); len(got) != 0 {
+		t.Errorf("rollups of a day without acks = %+v", got)
+	}
+}
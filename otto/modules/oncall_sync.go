@@ -0,0 +1,172 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// oncall_sync.go keeps on-call tasks and the issues they track in step: closing or
+// reopening an issue completes or reopens its task, completing a task with `/oncall done`
+// closes or comments on its issue, and a job reconciles tasks whose issue was closed while
+// Otto missed the webhook.
+
+package modules
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/google/go-github/v71/github"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+// completeIssueTask marks the task of an issue done when the issue is closed.
+func (o *OnCallModule) completeIssueTask(repo string, issueNum int, closedAt time.Time) error {
+	db := o.database.DB()
+	task, err := GetTaskByIssueNumber(db, repo, issueNum)
+	if err != nil {
+		return LogAndWrapError(err, ErrorTypeCommand, "get_task", map[string]any{"repo": repo, "issue": issueNum})
+	}
+	if task == nil || task.Status == "done" {
+		return nil
+	}
+	if closedAt.IsZero() {
+		closedAt = time.Now()
+	}
+	if err := UpdateTaskStatusAt(db, task.ID, "done", closedAt); err != nil {
+		return LogAndWrapError(err, ErrorTypeCommand, "update_task_status", map[string]any{
+			"task_id": task.ID,
+			"status":  "done",
+		})
+	}
+	slog.Info("Task marked as done due to issue closure", "task_id", task.ID, "repo", repo, "issue_num", issueNum)
+	return nil
+}
+
+// reopenIssueTask reopens the done task of an issue that is reopened.
+func (o *OnCallModule) reopenIssueTask(repo string, issueNum int) error {
+	db := o.database.DB()
+	task, err := GetTaskByIssueNumber(db, repo, issueNum)
+	if err != nil {
+		return LogAndWrapError(err, ErrorTypeCommand, "get_task", map[string]any{"repo": repo, "issue": issueNum})
+	}
+	if task == nil || task.Status != "done" {
+		return nil
+	}
+	if err := ReopenTask(db, task.ID); err != nil {
+		return LogAndWrapError(err, ErrorTypeCommand, "reopen_task", map[string]any{"task_id": task.ID})
+	}
+	slog.Info("Task reopened due to issue reopening", "task_id", task.ID, "repo", repo, "issue_num", issueNum)
+	return nil
+}
+
+// handleDone completes the task of the issue `/oncall done` is commented on. Members of
+// the task's schedule may complete it; the issue is then closed if close_issues is set,
+// and commented on otherwise.
+func (o *OnCallModule) handleDone(ctx context.Context, event *github.IssueCommentEvent) error {
+	repo := event.GetRepo().GetFullName()
+	issue := event.GetIssue().GetNumber()
+	login := event.GetComment().GetUser().GetLogin()
+	reply := func(message string) error {
+		if err := o.PostGitHubComment(repo, issue, message); err != nil {
+			return LogAndWrapError(err, ErrorTypeCommand, "oncall_done", map[string]any{"repo": repo, "user": login})
+		}
+		return nil
+	}
+
+	db := o.database.DB()
+	task, err := GetTaskByIssueNumber(db, repo, issue)
+	if err != nil {
+		return LogAndWrapError(err, ErrorTypeCommand, "get_task", map[string]any{"repo": repo, "issue": issue})
+	}
+	if task == nil {
+		return reply("⚠️ There is no on-call task for this issue.")
+	}
+	if task.Status == "done" {
+		return reply("✅ The on-call task for this issue is already done.")
+	}
+	member, err := o.onSchedule(task.ScheduleID, login)
+	if err != nil {
+		return LogAndWrapError(err, ErrorTypeCommand, "get_oncall_user", map[string]any{"user": login})
+	}
+	if !member {
+		return reply(fmt.Sprintf("⚠️ @%s is not on the schedule of this task.", login))
+	}
+
+	if err := UpdateTaskStatus(db, task.ID, "done"); err != nil {
+		return LogAndWrapError(err, ErrorTypeCommand, "update_task_status", map[string]any{
+			"task_id": task.ID,
+			"status":  "done",
+		})
+	}
+	slog.Info("Task marked as done by command", "task_id", task.ID, "repo", repo, "issue_num", issue, "user", login)
+	message := fmt.Sprintf("✅ On-call task completed by @%s.", login)
+	if !o.config.CloseIssues || event.GetIssue().GetState() == "closed" {
+		return reply(message)
+	}
+	if o.app == nil || o.app.GitHubClient == nil {
+		slog.Info("GitHub issue would be closed (no GitHub client available)", "repo", repo, "issue_num", issue)
+		return nil
+	}
+	owner, name, err := internal.SplitRepo(repo)
+	if err != nil {
+		return err
+	}
+	if err := reply(message + " Closing this issue."); err != nil {
+		return err
+	}
+	if _, _, err := o.app.GitHubClient.Issues.Edit(ctx, owner, name, issue, &github.IssueRequest{
+		State:       github.Ptr("closed"),
+		StateReason: github.Ptr("completed"),
+	}); err != nil {
+		return LogAndWrapError(err, ErrorTypeCommand, "close_task_issue", map[string]any{"repo": repo, "issue": issue})
+	}
+	return nil
+}
+
+// onSchedule reports whether a GitHub user is a member of a schedule.
+func (o *OnCallModule) onSchedule(scheduleID int64, login string) (bool, error) {
+	db := o.database.DB()
+	user, err := GetUserByGitHub(db, login)
+	if err != nil || user == nil {
+		return false, err
+	}
+	members, err := ListUsersForSchedule(db, scheduleID)
+	if err != nil {
+		return false, err
+	}
+	return slices.ContainsFunc(members, func(m OnCallScheduleUser) bool { return m.UserID == user.ID }), nil
+}
+
+// SyncTaskIssues completes the unfinished tasks whose issue is closed, catching up on
+// closures whose webhook was missed.
+func (o *OnCallModule) SyncTaskIssues(ctx context.Context) error {
+	if o.app == nil || o.app.GitHubClient == nil {
+		return nil
+	}
+	tasks, err := ListUnfinishedIssueTasks(o.database.DB())
+	if err != nil {
+		return fmt.Errorf("failed to list unfinished tasks: %w", err)
+	}
+	for _, task := range tasks {
+		if !o.app.RepoActive(task.Repo) {
+			continue
+		}
+		owner, name, err := internal.SplitRepo(task.Repo)
+		if err != nil {
+			continue
+		}
+		issue, _, err := o.app.GitHubClient.Issues.Get(ctx, owner, name, task.IssueNum)
+		if err != nil {
+			slog.Error("Failed to get task issue", "task_id", task.ID, "repo", task.Repo,
+				"issue_num", task.IssueNum, "error", err)
+			continue
+		}
+		if issue.GetState() != "closed" {
+			continue
+		}
+		if err := o.completeIssueTask(task.Repo, task.IssueNum, issue.GetClosedAt().Time); err != nil {
+			slog.Error("Failed to complete task", "task_id", task.ID, "error", err)
+		}
+	}
+	return nil
+}
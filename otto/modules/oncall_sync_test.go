@@ -0,0 +1,126 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v71/github"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+func doneCommentEvent(login string) *github.IssueCommentEvent {
+	return &github.IssueCommentEvent{
+		Action: github.Ptr("created"),
+		Repo:   &github.Repository{FullName: github.Ptr("org/repo")},
+		Issue:  &github.Issue{Number: github.Ptr(9), State: github.Ptr("open")},
+		Comment: &github.IssueComment{
+			Body: github.Ptr("/oncall done"),
+			User: &github.User{Login: github.Ptr(login)},
+		},
+	}
+}
+
+func TestIssueStateCompletesAndReopensTask(t *testing.T) {
+	o, _ := newOnCallTestModule(t)
+	db := o.database.DB()
+	task, err := o.AssignTask(t.Context(), "primary", "org/repo", 9, "Flaky test", "")
+	if err != nil {
+		t.Fatalf("AssignTask failed: %v", err)
+	}
+	if err := UpdateTaskStatus(db, task.ID, "ack"); err != nil {
+		t.Fatalf("UpdateTaskStatus failed: %v", err)
+	}
+
+	closedAt := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	event := func(action string) *github.IssuesEvent {
+		return &github.IssuesEvent{
+			Action: github.Ptr(action),
+			Repo:   &github.Repository{FullName: github.Ptr("org/repo")},
+			Issue:  &github.Issue{Number: github.Ptr(9), ClosedAt: &github.Timestamp{Time: closedAt}},
+		}
+	}
+	if err := o.HandleEvent("issues", event("closed"), nil); err != nil {
+		t.Fatalf("HandleEvent(closed) failed: %v", err)
+	}
+	if got, _ := GetTask(db, task.ID); got.Status != "done" || !got.CompletedAt.Equal(closedAt) {
+		t.Fatalf("task after the issue was closed: %+v", got)
+	}
+
+	if err := o.HandleEvent("issues", event("reopened"), nil); err != nil {
+		t.Fatalf("HandleEvent(reopened) failed: %v", err)
+	}
+	if got, _ := GetTask(db, task.ID); got.Status != "ack" || got.CompletedAt != nil {
+		t.Errorf("task after the issue was reopened: %+v", got)
+	}
+}
+
+func TestOnCallDone(t *testing.T) {
+	o, fake := newOnCallTestModule(t)
+	db := o.database.DB()
+	_, _ = AddUser(db, "mallory", "Mallory")
+	task, err := o.AssignTask(t.Context(), "primary", "org/repo", 9, "Flaky test", "")
+	if err != nil {
+		t.Fatalf("AssignTask failed: %v", err)
+	}
+
+	// Only members of the task's schedule may complete it.
+	if err := internal.TestCommands(t, o, doneCommentEvent("mallory")); err != nil {
+		t.Fatalf("HandleCommand failed: %v", err)
+	}
+	if got, _ := GetTask(db, task.ID); got.Status != "open" {
+		t.Fatalf("task completed by a non-member, status %q", got.Status)
+	}
+
+	if err := internal.TestCommands(t, o, doneCommentEvent("alice")); err != nil {
+		t.Fatalf("HandleCommand failed: %v", err)
+	}
+	if got, _ := GetTask(db, task.ID); got.Status != "done" {
+		t.Fatalf("task not completed, status %q", got.Status)
+	}
+	comments := fake.commentsOn("org/repo", 9)
+	if !strings.Contains(comments[len(comments)-1], "completed by @alice") || fake.stateOf("org/repo", 9) != "" {
+		t.Errorf("without close_issues the issue should only be commented on: %v, state %q",
+			comments, fake.stateOf("org/repo", 9))
+	}
+
+	// With close_issues, the issue is closed.
+	o.config.CloseIssues = true
+	if err := ReopenTask(db, task.ID); err != nil {
+		t.Fatalf("ReopenTask failed: %v", err)
+	}
+	if err := internal.TestCommands(t, o, doneCommentEvent("alice")); err != nil {
+		t.Fatalf("HandleCommand failed: %v", err)
+	}
+	if state := fake.stateOf("org/repo", 9); state != "closed" {
+		t.Errorf("issue state = %q, want closed", state)
+	}
+}
+
+func TestSyncTaskIssues(t *testing.T) {
+	o, fake := newOnCallTestModule(t)
+	db := o.database.DB()
+	open, _ := o.AssignTask(t.Context(), "primary", "org/repo", 1, "Still open", "")
+	closed, _ := o.AssignTask(t.Context(), "primary", "org/repo", 2, "Closed while away", "")
+	fake.mu.Lock()
+	fake.states["org/repo#2"] = "closed"
+	fake.mu.Unlock()
+
+	if err := o.SyncTaskIssues(t.Context()); err != nil {
+		t.Fatalf("SyncTaskIssues failed: %v", err)
+	}
+	if got, _ := GetTask(db, open.ID); got.Status != "open" {
+		t.Errorf("task of an open issue completed, status %q", got.Status)
+	}
+	if got, _ := GetTask(db, closed.ID); got.Status != "done" {
+		t.Errorf("task of a closed issue not completed, status %q", got.Status)
+	}
+	tasks, _ := ListUnfinishedIssueTasks(db)
+	if !slices.ContainsFunc(tasks, func(task OnCallTask) bool { return task.ID == open.ID }) || len(tasks) != 1 {
+		t.Errorf("unfinished tasks = %+v, want only the open one", tasks)
+	}
+}
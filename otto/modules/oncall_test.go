@@ -0,0 +1,150 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+)
+
+func newOnCallTestModule(t *testing.T) (*OnCallModule, *fakeGitHub) {
+	db := openTestDB(t)
+	sch, _ := AddSchedule(db, "primary", "round-robin")
+	alice, _ := AddUser(db, "alice", "Alice")
+	_ = AssignUserToSchedule(db, sch.ID, alice.ID, 0)
+
+	fake := newFakeGitHub()
+	app := &internal.App{
+		Database:     internal.NewDatabaseFromDB(db),
+		GitHubClient: fake.client(t),
+		Telemetry:    internal.TestTelemetry(t, nil),
+	}
+	return &OnCallModule{app: app, database: app.Database}, fake
+}
+
+func TestAssignTaskAndReactionAck(t *testing.T) {
+	o, fake := newOnCallTestModule(t)
+	db := o.database.DB()
+
+	task, err := o.AssignTask(t.Context(), "primary", "org/repo", 9, "Flaky test", "")
+	if err != nil {
+		t.Fatalf("AssignTask failed: %v", err)
+	}
+	if task.AssignmentCommentID == 0 {
+		t.Fatalf("assignment comment ID not recorded")
+	}
+	comments := fake.commentsOn("org/repo", 9)
+	if len(comments) != 1 || !strings.Contains(comments[0], "@alice") {
+		t.Fatalf("unexpected assignment comment: %v", comments)
+	}
+
+	// Reactions from other users or with other emoji do not acknowledge.
+	fake.react(task.AssignmentCommentID, "bob", "+1", time.Now())
+	fake.react(task.AssignmentCommentID, "alice", "heart", time.Now())
+	if err := o.CheckReactionAcks(t.Context()); err != nil {
+		t.Fatalf("CheckReactionAcks failed: %v", err)
+	}
+	if got, _ := GetTask(db, task.ID); got.Status != "open" {
+		t.Fatalf("task acknowledged by wrong reaction, status %q", got.Status)
+	}
+
+	reactedAt := task.CreatedAt.Add(5 * time.Minute)
+	fake.react(task.AssignmentCommentID, "alice", "eyes", reactedAt)
+	if err := o.CheckReactionAcks(t.Context()); err != nil {
+		t.Fatalf("CheckReactionAcks failed: %v", err)
+	}
+	got, _ := GetTask(db, task.ID)
+	if got.Status != "ack" {
+		t.Fatalf("expected task to be acknowledged, status %q", got.Status)
+	}
+	if got.AckedAt == nil || !got.AckedAt.Equal(reactedAt) {
+		t.Errorf("AckedAt = %v, want reaction time %v", got.AckedAt, reactedAt)
+	}
+
+	// Acknowledged tasks are no longer polled.
+	if tasks, _ := ListAwaitingAckTasks(db); len(tasks) != 0 {
+		t.Errorf("expected no tasks awaiting ack, got %d", len(tasks))
+	}
+}
+
+func TestCheckUnacknowledgedTasksEscalatesOnce(t *testing.T) {
+	o, fake := newOnCallTestModule(t)
+	db := o.database.DB()
+	notifications, err := internal.NewNotifications(config.NotificationsConfig{
+		Channels: map[string]config.NotificationChannel{"pager": {Backend: "slack", Target: "C0PAGE"}},
+		Routes:   []config.NotificationRoute{{Modules: []string{"oncall"}, Channels: []string{"pager"}}},
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewNotifications failed: %v", err)
+	}
+	pager := &advisoryNotifier{backend: "slack"}
+	notifications.Register(pager)
+	o.app.Notifications = notifications
+
+	stale, _ := AddTask(db, 1, "org/repo", 3, "Broken build", "", 1)
+	fresh, _ := AddTask(db, 1, "org/repo", 4, "Flaky test", "", 1)
+	if _, err := db.Exec(`UPDATE oncall_tasks SET created_at = ? WHERE id = ?`,
+		time.Now().Add(-48*time.Hour), stale.ID); err != nil {
+		t.Fatalf("failed to age task: %v", err)
+	}
+
+	for range 2 {
+		if err := o.CheckUnacknowledgedTasks(); err != nil {
+			t.Fatalf("CheckUnacknowledgedTasks failed: %v", err)
+		}
+	}
+	if len(pager.sent) != 1 || !strings.Contains(pager.sent[0], "org/repo#3") {
+		t.Errorf("notifications = %q, want one for org/repo#3", pager.sent)
+	}
+	if comments := fake.commentsOn("org/repo", 3); len(comments) != 1 || !strings.Contains(comments[0], "ESCALATION") {
+		t.Errorf("comments on #3 = %q, want one escalation", comments)
+	}
+	if comments := fake.commentsOn("org/repo", fresh.IssueNum); len(comments) != 0 {
+		t.Errorf("task younger than 24 hours escalated: %q", comments)
+	}
+}
+
+func TestCheckUnacknowledgedTasksSkipsFinishedTasks(t *testing.T) {
+	o, fake := newOnCallTestModule(t)
+	db := o.database.DB()
+	acked, _ := AddTask(db, 1, "org/repo", 5, "Release", "", 1)
+	done, _ := AddTask(db, 1, "org/repo", 6, "Triage backlog", "", 1)
+	_ = UpdateTaskStatus(db, acked.ID, "ack")
+	_ = UpdateTaskStatus(db, done.ID, "done")
+	if _, err := db.Exec(`UPDATE oncall_tasks SET created_at = ?`, time.Now().Add(-48*time.Hour)); err != nil {
+		t.Fatalf("failed to age tasks: %v", err)
+	}
+
+	if err := o.CheckUnacknowledgedTasks(); err != nil {
+		t.Fatalf("CheckUnacknowledgedTasks failed: %v", err)
+	}
+	for _, num := range []int{5, 6} {
+		if comments := fake.commentsOn("org/repo", num); len(comments) != 0 {
+			t.Errorf("finished task #%d escalated: %q", num, comments)
+		}
+	}
+}
+
+func TestAutoMigrateOnCallIsIdempotent(t *testing.T) {
+	db := openTestDB(t)
+	if err := AutoMigrateOnCall(db); err != nil {
+		t.Fatalf("second migration failed: %v", err)
+	}
+}
+
+func TestOnCallVersionedMigrations(t *testing.T) {
+	db := openTestDB(t)
+	applied, err := internal.AppliedMigrations(db, "oncall")
+	if err != nil || len(applied) == 0 || applied[0].Name != "0001_index_tasks_by_issue.sql" {
+		t.Fatalf("applied oncall migrations = %+v, %v", applied, err)
+	}
+	migrations := []internal.Migration{{Name: "oncall", Migrate: AutoMigrateOnCall}}
+	plan, err := internal.PlanMigrations(t.Context(), db, migrations)
+	if err != nil || plan.Pending() {
+		t.Errorf("plan after migrating = %+v, %v", plan, err)
+	}
+}
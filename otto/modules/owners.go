@@ -0,0 +1,259 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v71/github"
+	"gopkg.in/yaml.v3"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+// OwnersConfig configures the component ownership registry.
+type OwnersConfig struct {
+	File        string              `yaml:"file" doc:"per-repository registry file"`
+	Components  map[string][]string `yaml:"components" doc:"central registry: component -> owners"`
+	LabelPrefix string              `yaml:"label_prefix" doc:"component labels are prefix + component"`
+	AutoCC      bool                `yaml:"auto_cc" doc:"mention owners when a component label is added"`
+	CacheTTL    time.Duration       `yaml:"cache_ttl" doc:"how long a repository's file is reused"`
+}
+
+// componentOwnersFile is the format of the per-repository registry file.
+type componentOwnersFile struct {
+	Components map[string][]string `yaml:"components"`
+}
+
+// cachedOwners is a repository's registry as loaded at a point in time.
+type cachedOwners struct {
+	Owners  map[string][]string `json:"owners"`
+	Fetched time.Time           `json:"fetched"`
+}
+
+// OwnersModule maps components to their owners, expands `/cc component:<name>` to
+// mentions of the owners, and mentions them when an issue gets a component label.
+type OwnersModule struct {
+	app    *internal.App
+	config OwnersConfig
+	now    func() time.Time
+	cache  internal.Cache // the app's cache, shared by replicas if it is Redis
+}
+
+func (m *OwnersModule) Name() string { return "owners" }
+
+// ConfigSchema implements the ModuleConfigSchema interface.
+func (m *OwnersModule) ConfigSchema() any {
+	c := defaultOwnersConfig()
+	return &c
+}
+
+// defaultOwnersConfig returns the owners module's defaults.
+func defaultOwnersConfig() OwnersConfig {
+	return OwnersConfig{
+		File:     ".github/component_owners.yml",
+		AutoCC:   true,
+		CacheTTL: 10 * time.Minute,
+	}
+}
+
+// EventSubscriptions implements the ModuleEventSubscriber interface.
+func (m *OwnersModule) EventSubscriptions() []internal.EventSubscription {
+	return []internal.EventSubscription{
+		internal.Subscribe("issues", "labeled"),
+	}
+}
+
+// GitHubPermissions implements the ModulePermissionRequirer interface.
+func (m *OwnersModule) GitHubPermissions() map[string]string {
+	return map[string]string{"contents": "read", "issues": "write"}
+}
+
+// SlashCommands implements the ModuleCommander interface.
+func (m *OwnersModule) SlashCommands() []string {
+	return []string{"cc"}
+}
+
+// Initialize implements the ModuleInitializer interface.
+func (m *OwnersModule) Initialize(ctx context.Context, app *internal.App) error {
+	m.app = app
+	m.cache = app.Cache
+	if m.cache == nil {
+		m.cache = internal.NewMemoryCache()
+	}
+	if m.now == nil {
+		m.now = time.Now
+	}
+	m.config = defaultOwnersConfig()
+	return loadModuleConfig(app, m.Name(), &m.config)
+}
+
+func (m *OwnersModule) HandleEvent(eventType string, event any, raw json.RawMessage) error {
+	ctx := context.Background()
+	switch eventType {
+	case "issues":
+		issuesEvent, ok := event.(*github.IssuesEvent)
+		if !ok || issuesEvent.GetAction() != "labeled" || !m.config.AutoCC {
+			return nil
+		}
+		return m.handleLabeled(ctx, issuesEvent)
+	}
+	return nil
+}
+
+// HandleCommand implements the ModuleCommandHandler interface.
+func (m *OwnersModule) HandleCommand(cmd *internal.CommandContext) error {
+	return m.handleCC(cmd.Context, cmd.Event, cmd.Args)
+}
+
+// handleCC answers `/cc component:<name> ...` with mentions of the components' owners.
+func (m *OwnersModule) handleCC(ctx context.Context, event *github.IssueCommentEvent, args []string) error {
+	repo := event.GetRepo().GetFullName()
+	num := event.GetIssue().GetNumber()
+	var components []string
+	for _, arg := range args {
+		if name, ok := strings.CutPrefix(arg, "component:"); ok && name != "" {
+			components = append(components, name)
+		}
+	}
+	if len(components) == 0 {
+		return nil
+	}
+
+	owners, err := m.Owners(ctx, repo)
+	if err != nil {
+		return m.wrap(err, "load_owners", repo, num)
+	}
+	var (
+		mentions []string
+		unknown  []string
+	)
+	for _, c := range components {
+		list, ok := owners[c]
+		if !ok {
+			unknown = append(unknown, "`"+c+"`")
+			continue
+		}
+		mentions = appendMentions(mentions, list, event.GetComment().GetUser().GetLogin())
+	}
+
+	var lines []string
+	if len(mentions) > 0 {
+		lines = append(lines, fmt.Sprintf("cc %s (owners of %s)", strings.Join(mentions, " "),
+			quoteList(components, unknown)))
+	}
+	if len(unknown) > 0 {
+		lines = append(lines, fmt.Sprintf("⚠️ No owners are registered for %s.", strings.Join(unknown, ", ")))
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	return m.wrap(m.comment(ctx, repo, num, strings.Join(lines, "\n\n")), "cc_owners", repo, num)
+}
+
+// handleLabeled mentions a component's owners when its label is added to an open issue.
+func (m *OwnersModule) handleLabeled(ctx context.Context, event *github.IssuesEvent) error {
+	if event.GetIssue().GetState() == "closed" {
+		return nil
+	}
+	component, ok := strings.CutPrefix(event.GetLabel().GetName(), m.config.LabelPrefix)
+	if !ok || component == "" {
+		return nil
+	}
+	repo := event.GetRepo().GetFullName()
+	num := event.GetIssue().GetNumber()
+	owners, err := m.Owners(ctx, repo)
+	if err != nil {
+		return m.wrap(err, "load_owners", repo, num)
+	}
+	mentions := appendMentions(nil, owners[component], event.GetSender().GetLogin())
+	if len(mentions) == 0 {
+		return nil
+	}
+	msg := fmt.Sprintf("cc %s (owners of `%s`)", strings.Join(mentions, " "), component)
+	return m.wrap(m.comment(ctx, repo, num, msg), "auto_cc_owners", repo, num)
+}
+
+// Owners returns the component registry for a repository: the central registry,
+// overridden per component by the repository's own file.
+func (m *OwnersModule) Owners(ctx context.Context, repo string) (map[string][]string, error) {
+	key := "owners:" + repo
+	cached, ok := internal.GetCached[cachedOwners](ctx, m.cache, key)
+	if ok && m.now().Sub(cached.Fetched) < m.config.CacheTTL {
+		return cached.Owners, nil
+	}
+
+	owners := make(map[string][]string, len(m.config.Components))
+	for c, list := range m.config.Components {
+		owners[c] = list
+	}
+	if m.config.File != "" && m.app != nil && m.app.GitHubClient != nil {
+		file, err := internal.GetFile(ctx, m.app.GitHubClient, repo, m.config.File, "")
+		if err != nil {
+			return nil, err
+		}
+		if file != nil {
+			var parsed componentOwnersFile
+			if err := yaml.Unmarshal([]byte(file.Content), &parsed); err != nil {
+				slog.Warn("Ignoring invalid component owners file", "repo", repo, "path", m.config.File, "error", err)
+			}
+			for c, list := range parsed.Components {
+				owners[c] = list
+			}
+		}
+	}
+
+	internal.SetCached(ctx, m.cache, key, cachedOwners{Owners: owners, Fetched: m.now()}, m.config.CacheTTL)
+	return owners, nil
+}
+
+// appendMentions adds @mentions for owners not already present, leaving out exclude.
+func appendMentions(mentions, owners []string, exclude string) []string {
+	for _, o := range owners {
+		login := strings.TrimPrefix(strings.TrimSpace(o), "@")
+		if login == "" || strings.EqualFold(login, exclude) {
+			continue
+		}
+		if mention := "@" + login; !slices.Contains(mentions, mention) {
+			mentions = append(mentions, mention)
+		}
+	}
+	return mentions
+}
+
+// quoteList formats the components that are not in skip as a code-quoted list.
+func quoteList(components, skip []string) string {
+	var quoted []string
+	for _, c := range components {
+		if q := "`" + c + "`"; !slices.Contains(skip, q) && !slices.Contains(quoted, q) {
+			quoted = append(quoted, q)
+		}
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func (m *OwnersModule) comment(ctx context.Context, repo string, num int, body string) error {
+	if m.app == nil || m.app.GitHubClient == nil {
+		slog.Info("GitHub comment would be posted (no GitHub client available)",
+			"repo", repo, "issue_num", num, "message", body)
+		return nil
+	}
+	return m.app.PostComment(ctx, m.Name(), repo, num, body)
+}
+
+func (m *OwnersModule) wrap(err error, op, repo string, num int) error {
+	if err == nil {
+		return nil
+	}
+	return internal.LogAndWrapError(err, internal.ErrorTypeModule, op, map[string]any{
+		"module": m.Name(),
+		"repo":   repo,
+		"issue":  num,
+	})
+}
@@ -0,0 +1,159 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v71/github"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+func newOwnersTestModule(t *testing.T, fake *fakeGitHub) *OwnersModule {
+	t.Helper()
+	return &OwnersModule{
+		app: &internal.App{GitHubClient: fake.client(t)},
+		config: OwnersConfig{
+			File: ".github/component_owners.yml",
+			Components: map[string][]string{
+				"exporter/prometheus": {"central-owner"},
+				"receiver/otlp":       {"@otlp-owner", "org/otlp-team"},
+			},
+			LabelPrefix: "comp:",
+			AutoCC:      true,
+			CacheTTL:    time.Minute,
+		},
+		now:   time.Now,
+		cache: internal.NewMemoryCache(),
+	}
+}
+
+func TestOwnersCC(t *testing.T) {
+	fake := newFakeGitHub()
+	fake.setFile("org/repo", fakeDefaultBranch, ".github/component_owners.yml",
+		"components:\n  exporter/prometheus:\n    - alice\n    - bob\n")
+	mod := newOwnersTestModule(t, fake)
+
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{
+			name: "repo file overrides central owners",
+			body: "/cc component:exporter/prometheus",
+			want: []string{"cc @alice @bob (owners of `exporter/prometheus`)"},
+		},
+		{
+			name: "central owners and teams",
+			body: "/cc component:receiver/otlp",
+			want: []string{"@otlp-owner @org/otlp-team"},
+		},
+		{
+			name: "commenter is left out and owners are deduplicated",
+			body: "/cc component:exporter/prometheus component:exporter/prometheus",
+			want: []string{"cc @bob (owners"},
+		},
+		{
+			name: "unknown component",
+			body: "/cc component:receiver/otlp component:processor/nope",
+			want: []string{"(owners of `receiver/otlp`)", "No owners are registered for `processor/nope`"},
+		},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := "carol"
+			if i == 2 {
+				user = "alice"
+			}
+			if err := internal.TestCommands(t, mod, commentEvent("org/repo", i+1, user, tt.body)); err != nil {
+				t.Fatalf("HandleCommand failed: %v", err)
+			}
+			comments := fake.commentsOn("org/repo", i+1)
+			if len(comments) != 1 {
+				t.Fatalf("expected one comment, got %v", comments)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(comments[0], want) {
+					t.Errorf("comment %q does not contain %q", comments[0], want)
+				}
+			}
+		})
+	}
+
+	// Plain /cc mentions are left alone.
+	if err := internal.TestCommands(t, mod, commentEvent("org/repo", 9, "carol", "/cc @dave")); err != nil {
+		t.Fatalf("HandleCommand failed: %v", err)
+	}
+	if comments := fake.commentsOn("org/repo", 9); len(comments) != 0 {
+		t.Errorf("unexpected comments: %v", comments)
+	}
+}
+
+func TestOwnersAutoCCOnLabel(t *testing.T) {
+	fake := newFakeGitHub()
+	mod := newOwnersTestModule(t, fake)
+
+	if err := mod.HandleEvent("issues", labeledEvent("org/repo", 1, "author", "comp:receiver/otlp"), nil); err != nil {
+		t.Fatalf("HandleEvent failed: %v", err)
+	}
+	comments := fake.commentsOn("org/repo", 1)
+	if len(comments) != 1 || comments[0] != "cc @otlp-owner @org/otlp-team (owners of `receiver/otlp`)" {
+		t.Fatalf("unexpected comments: %v", comments)
+	}
+
+	// Labels without the prefix, unknown components and closed issues are ignored.
+	closed := labeledEvent("org/repo", 3, "author", "comp:receiver/otlp")
+	closed.Issue.State = github.Ptr("closed")
+	for num, event := range map[int]*github.IssuesEvent{
+		2: labeledEvent("org/repo", 2, "author", "bug"),
+		3: closed,
+		4: labeledEvent("org/repo", 4, "author", "comp:processor/nope"),
+	} {
+		if err := mod.HandleEvent("issues", event, nil); err != nil {
+			t.Fatalf("HandleEvent failed: %v", err)
+		}
+		if comments := fake.commentsOn("org/repo", num); len(comments) != 0 {
+			t.Errorf("issue %d: unexpected comments: %v", num, comments)
+		}
+	}
+
+	mod.config.AutoCC = false
+	if err := mod.HandleEvent("issues", labeledEvent("org/repo", 5, "author", "comp:receiver/otlp"), nil); err != nil {
+		t.Fatalf("HandleEvent failed: %v", err)
+	}
+	if comments := fake.commentsOn("org/repo", 5); len(comments) != 0 {
+		t.Errorf("auto cc ran while disabled: %v", comments)
+	}
+}
+
+func TestOwnersCache(t *testing.T) {
+	fake := newFakeGitHub()
+	mod := newOwnersTestModule(t, fake)
+	now := time.Now()
+	mod.now = func() time.Time { return now }
+
+	owners, err := mod.Owners(t.Context(), "org/repo")
+	if err != nil {
+		t.Fatalf("Owners failed: %v", err)
+	}
+	if _, ok := owners["exporter/otlp"]; ok {
+		t.Fatalf("unexpected component: %v", owners)
+	}
+
+	fake.setFile("org/repo", fakeDefaultBranch, ".github/component_owners.yml",
+		"components:\n  exporter/otlp: [alice]\n")
+	if owners, _ = mod.Owners(t.Context(), "org/repo"); owners["exporter/otlp"] != nil {
+		t.Errorf("cached registry not reused: %v", owners)
+	}
+	now = now.Add(2 * time.Minute)
+	if owners, _ = mod.Owners(t.Context(), "org/repo"); len(owners["exporter/otlp"]) != 1 {
+		t.Errorf("registry not reloaded after TTL: %v", owners)
+	}
+	if len(owners["receiver/otlp"]) != 2 {
+		t.Errorf("central registry lost on reload: %v", owners)
+	}
+}
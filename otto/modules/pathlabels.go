@@ -0,0 +1,231 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"slices"
+
+	"github.com/google/go-github/v71/github"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+// PathLabelRule applies a label to pull requests that change a file matching any of its
+// paths.
+type PathLabelRule struct {
+	Label string   `yaml:"label" doc:"label applied when a path matches"`
+	Paths []string `yaml:"paths" doc:".gitattributes-style patterns, e.g. exporter/prometheus/**"`
+}
+
+// PathLabelsConfig configures labeling pull requests by the paths they change. Repositories
+// replace the rules in their .github/otto.yml.
+type PathLabelsConfig struct {
+	Rules           []PathLabelRule `yaml:"rules" doc:"path label rules"`
+	RemoveUnmatched *bool           `yaml:"remove_unmatched" doc:"remove rule labels whose paths are no longer changed"`
+}
+
+// PathLabelsModule labels pull requests by the files they change when they are opened and
+// when commits are pushed, and removes the labels of rules that no longer match.
+type PathLabelsModule struct {
+	app    *internal.App
+	config PathLabelsConfig
+}
+
+func (m *PathLabelsModule) Name() string { return "pathlabels" }
+
+// ConfigSchema implements the ModuleConfigSchema interface.
+func (m *PathLabelsModule) ConfigSchema() any {
+	c := defaultPathLabelsConfig()
+	return &c
+}
+
+// defaultPathLabelsConfig returns the pathlabels module's defaults.
+func defaultPathLabelsConfig() PathLabelsConfig {
+	return PathLabelsConfig{RemoveUnmatched: github.Ptr(true)}
+}
+
+// EventSubscriptions implements the ModuleEventSubscriber interface.
+func (m *PathLabelsModule) EventSubscriptions() []internal.EventSubscription {
+	return []internal.EventSubscription{
+		internal.Subscribe("pull_request", "opened", "reopened", "synchronize"),
+	}
+}
+
+// GitHubPermissions implements the ModulePermissionRequirer interface.
+func (m *PathLabelsModule) GitHubPermissions() map[string]string {
+	return map[string]string{"issues": "write", "pull_requests": "read"}
+}
+
+// Initialize implements the ModuleInitializer interface.
+func (m *PathLabelsModule) Initialize(ctx context.Context, app *internal.App) error {
+	m.app = app
+	m.config = defaultPathLabelsConfig()
+	if err := loadModuleConfig(app, m.Name(), &m.config); err != nil {
+		return err
+	}
+	if _, err := compilePathLabelRules(m.config.Rules); err != nil {
+		return fmt.Errorf("pathlabels: %w", err)
+	}
+	return nil
+}
+
+func (m *PathLabelsModule) HandleEvent(eventType string, event any, raw json.RawMessage) error {
+	if eventType != "pull_request" {
+		return nil
+	}
+	prEvent, ok := event.(*github.PullRequestEvent)
+	if !ok {
+		return nil
+	}
+	switch prEvent.GetAction() {
+	case "opened", "reopened", "synchronize":
+		repo := prEvent.GetRepo().GetFullName()
+		pr := prEvent.GetPullRequest()
+		return m.wrap(m.label(context.Background(), repo, pr), "label_paths", repo, pr.GetNumber())
+	}
+	return nil
+}
+
+// compiledPathLabelRule is a rule with its patterns compiled.
+type compiledPathLabelRule struct {
+	label    string
+	patterns []*regexp.Regexp
+}
+
+// compilePathLabelRules compiles rules, which need a label and at least one path.
+func compilePathLabelRules(rules []PathLabelRule) ([]compiledPathLabelRule, error) {
+	compiled := make([]compiledPathLabelRule, 0, len(rules))
+	for i, rule := range rules {
+		if rule.Label == "" || len(rule.Paths) == 0 {
+			return nil, fmt.Errorf("rule %d needs a label and paths", i+1)
+		}
+		c := compiledPathLabelRule{label: rule.Label}
+		for _, p := range rule.Paths {
+			c.patterns = append(c.patterns, internal.CompileFilePattern(p))
+		}
+		compiled = append(compiled, c)
+	}
+	return compiled, nil
+}
+
+// label applies the labels of the rules matching the pull request's changed files, with the
+// repository's rules if it has any, and removes the labels of the rules that do not match.
+func (m *PathLabelsModule) label(ctx context.Context, repo string, pr *github.PullRequest) error {
+	cfg := m.config
+	if err := loadRepoModuleConfig(ctx, m.app, repo, m.Name(), &cfg); err != nil {
+		return err
+	}
+	rules, err := compilePathLabelRules(cfg.Rules)
+	if err != nil {
+		return fmt.Errorf("%s in %s: %w", repoConfigFile, repo, err)
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+	if m.app == nil || m.app.GitHubClient == nil {
+		return errors.New("GitHub client not available")
+	}
+	files, err := m.changedFiles(ctx, repo, pr.GetNumber())
+	if err != nil {
+		return err
+	}
+
+	want := pathLabels(rules, files)
+	var have []string
+	for _, l := range pr.Labels {
+		have = append(have, l.GetName())
+	}
+	removeUnmatched := cfg.RemoveUnmatched == nil || *cfg.RemoveUnmatched
+	var add, remove []string
+	for _, rule := range rules {
+		switch {
+		case slices.Contains(want, rule.label):
+			if !slices.Contains(have, rule.label) && !slices.Contains(add, rule.label) {
+				add = append(add, rule.label)
+			}
+		case removeUnmatched && slices.Contains(have, rule.label) && !slices.Contains(remove, rule.label):
+			remove = append(remove, rule.label)
+		}
+	}
+	return m.applyLabels(ctx, repo, pr.GetNumber(), add, remove)
+}
+
+// pathLabels returns the labels of the rules that match any of the files, in rule order.
+func pathLabels(rules []compiledPathLabelRule, files []string) []string {
+	var labels []string
+	for _, rule := range rules {
+		if slices.Contains(labels, rule.label) {
+			continue
+		}
+		matched := slices.ContainsFunc(files, func(f string) bool {
+			return slices.ContainsFunc(rule.patterns, func(p *regexp.Regexp) bool { return p.MatchString(f) })
+		})
+		if matched {
+			labels = append(labels, rule.label)
+		}
+	}
+	return labels
+}
+
+// changedFiles lists the paths a pull request changes, including the old paths of renamed
+// files.
+func (m *PathLabelsModule) changedFiles(ctx context.Context, repo string, num int) ([]string, error) {
+	owner, name, err := internal.SplitRepo(repo)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		page, resp, err := m.app.GitHubClient.PullRequests.ListFiles(ctx, owner, name, num, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list files: %w", err)
+		}
+		for _, f := range page {
+			files = append(files, f.GetFilename())
+			if prev := f.GetPreviousFilename(); prev != "" {
+				files = append(files, prev)
+			}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return files, nil
+}
+
+// applyLabels adds and removes labels on a pull request.
+func (m *PathLabelsModule) applyLabels(ctx context.Context, repo string, num int, add, remove []string) error {
+	if len(add) > 0 {
+		if err := m.app.AddLabels(ctx, m.Name(), repo, num, add); err != nil {
+			return err
+		}
+	}
+	if len(remove) > 0 {
+		if err := m.app.RemoveLabels(ctx, m.Name(), repo, num, remove); err != nil {
+			return err
+		}
+	}
+	if len(add) > 0 || len(remove) > 0 {
+		slog.Info("Pull request labeled by changed paths", "repo", repo, "pr", num, "added", add, "removed", remove)
+	}
+	return nil
+}
+
+func (m *PathLabelsModule) wrap(err error, op, repo string, num int) error {
+	if err == nil {
+		return nil
+	}
+	return internal.LogAndWrapError(err, internal.ErrorTypeModule, op, map[string]any{
+		"module": m.Name(),
+		"repo":   repo,
+		"issue":  num,
+	})
+}
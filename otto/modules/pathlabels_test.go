@@ -0,0 +1,131 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/google/go-github/v71/github"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+)
+
+func newPathLabelsTestModule(t *testing.T, modules map[string]any) (*PathLabelsModule, *fakeGitHub) {
+	t.Helper()
+	fake := newFakeGitHub()
+	app := &internal.App{
+		Config:       &config.AppConfig{Modules: modules},
+		GitHubClient: fake.client(t),
+		Cache:        internal.NewMemoryCache(),
+	}
+	mod := &PathLabelsModule{}
+	if err := mod.Initialize(t.Context(), app); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	return mod, fake
+}
+
+func TestPathLabels(t *testing.T) {
+	rules, err := compilePathLabelRules([]PathLabelRule{
+		{Label: "area:exporter/prometheus", Paths: []string{"exporter/prometheus/**"}},
+		{Label: "area:docs", Paths: []string{"*.md", "docs/"}},
+		{Label: "area:ci", Paths: []string{".github/workflows/*.yml"}},
+	})
+	if err != nil {
+		t.Fatalf("compilePathLabelRules failed: %v", err)
+	}
+	tests := []struct {
+		name  string
+		files []string
+		want  []string
+	}{
+		{"nested file", []string{"exporter/prometheus/internal/config.go"}, []string{"area:exporter/prometheus"}},
+		{"sibling directory", []string{"exporter/prometheusremotewrite/exporter.go"}, nil},
+		{"markdown at any depth", []string{"receiver/otlp/README.md"}, []string{"area:docs"}},
+		{"directory pattern", []string{"docs/guide/setup.txt"}, []string{"area:docs"}},
+		{
+			"several rules",
+			[]string{".github/workflows/ci.yml", "exporter/prometheus/README.md"},
+			[]string{"area:exporter/prometheus", "area:docs", "area:ci"},
+		},
+		{"no match", []string{"go.mod"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pathLabels(rules, tt.files); !slices.Equal(got, tt.want) {
+				t.Errorf("pathLabels() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := compilePathLabelRules([]PathLabelRule{{Label: "area:docs"}}); err == nil {
+		t.Error("expected an error for a rule without paths")
+	}
+}
+
+func TestPathLabelsModule(t *testing.T) {
+	mod, fake := newPathLabelsTestModule(t, map[string]any{
+		"pathlabels": map[string]any{"rules": []any{
+			map[string]any{"label": "area:exporter/prometheus", "paths": []any{"exporter/prometheus/**"}},
+			map[string]any{"label": "area:docs", "paths": []any{"*.md"}},
+		}},
+	})
+	fake.addPullFile("org/repo", 1, "abc123", &github.CommitFile{
+		Filename:         github.Ptr("exporter/prometheus/exporter.go"),
+		PreviousFilename: github.Ptr("exporter/prom/exporter.go"),
+	}, 10)
+	fake.setLabels("org/repo", 1, "area:docs", "bug")
+
+	// Labels of matching rules are added; those of rules that no longer match are removed.
+	event := pullRequestEvent("synchronize", "org/repo", 1, "", "area:docs", "bug")
+	if err := mod.HandleEvent("pull_request", event, nil); err != nil {
+		t.Fatalf("HandleEvent failed: %v", err)
+	}
+	if got := fake.labelsOn("org/repo", 1); !slices.Equal(got, []string{"bug", "area:exporter/prometheus"}) {
+		t.Errorf("labels = %v", got)
+	}
+
+	// Other actions are ignored.
+	fake.setLabels("org/repo", 1)
+	if err := mod.HandleEvent("pull_request", pullRequestEvent("edited", "org/repo", 1, ""), nil); err != nil {
+		t.Fatalf("HandleEvent failed: %v", err)
+	}
+	if got := fake.labelsOn("org/repo", 1); len(got) != 0 {
+		t.Errorf("labels after edited = %v, want none", got)
+	}
+}
+
+func TestPathLabelsRepoConfig(t *testing.T) {
+	mod, fake := newPathLabelsTestModule(t, map[string]any{
+		"pathlabels": map[string]any{"rules": []any{
+			map[string]any{"label": "area:docs", "paths": []any{"*.md"}},
+		}},
+	})
+	fake.setFile("org/repo", fakeDefaultBranch, repoConfigFile, `modules:
+  pathlabels:
+    remove_unmatched: false
+    rules:
+      - label: "component:api"
+        paths: ["api/**"]
+`)
+	fake.addPullFile("org/repo", 1, "abc123", &github.CommitFile{Filename: github.Ptr("api/v1/types.go")}, 10)
+	fake.addPullFile("org/repo", 1, "abc123", &github.CommitFile{Filename: github.Ptr("README.md")}, 10)
+	fake.setLabels("org/repo", 1, "component:ui")
+
+	event := pullRequestEvent("opened", "org/repo", 1, "", "component:ui")
+	if err := mod.HandleEvent("pull_request", event, nil); err != nil {
+		t.Fatalf("HandleEvent failed: %v", err)
+	}
+	// The repository's rules replace the global ones, and keep unmatched labels.
+	if got := fake.labelsOn("org/repo", 1); !slices.Equal(got, []string{"component:ui", "component:api"}) {
+		t.Errorf("labels = %v", got)
+	}
+
+	// An invalid repository config is reported.
+	fake.setFile("org/other", fakeDefaultBranch, repoConfigFile, "modules:\n  pathlabels:\n    rules: [{label: x}]\n")
+	if err := mod.HandleEvent("pull_request", pullRequestEvent("opened", "org/other", 1, ""), nil); err == nil {
+		t.Error("expected an error for a rule without paths")
+	}
+}
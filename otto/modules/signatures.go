@@ -0,0 +1,207 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/google/go-github/v71/github"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+// Signature kinds reported for verified commits.
+const (
+	SignatureGPG      = "gpg"
+	SignatureSSH      = "ssh"
+	SignatureSigstore = "sigstore"
+	SignatureUnknown  = "unknown"
+)
+
+// SignatureConfig configures commit signature reporting.
+type SignatureConfig struct {
+	CheckName string   `yaml:"check_name" doc:"name of the check run"`
+	Repos     []string `yaml:"repos" doc:"repos to report on; empty means all"`
+	Enforce   []string `yaml:"enforce" doc:"repos where unsigned commits fail the check"`
+}
+
+// CommitSignature is the verification state of one pull request commit.
+type CommitSignature struct {
+	SHA      string
+	Author   string
+	Verified bool
+	Kind     string // gpg, ssh, sigstore or unknown; empty when unsigned
+	Reason   string // GitHub's verification reason, e.g. "unsigned" or "valid"
+}
+
+// SignatureModule reports whether pull request commits are signed.
+type SignatureModule struct {
+	app    *internal.App
+	config SignatureConfig
+}
+
+func (m *SignatureModule) Name() string { return "signatures" }
+
+// ConfigSchema implements the ModuleConfigSchema interface.
+func (m *SignatureModule) ConfigSchema() any {
+	c := defaultSignatureConfig()
+	return &c
+}
+
+// defaultSignatureConfig returns the signatures module's defaults.
+func defaultSignatureConfig() SignatureConfig {
+	return SignatureConfig{CheckName: "otto/commit-signatures"}
+}
+
+// EventSubscriptions implements the ModuleEventSubscriber interface.
+func (m *SignatureModule) EventSubscriptions() []internal.EventSubscription {
+	return []internal.EventSubscription{
+		internal.Subscribe("pull_request", "opened", "reopened", "synchronize"),
+	}
+}
+
+// GitHubPermissions implements the ModulePermissionRequirer interface.
+func (m *SignatureModule) GitHubPermissions() map[string]string {
+	return map[string]string{"checks": "write", "pull_requests": "read"}
+}
+
+// Initialize implements the ModuleInitializer interface.
+func (m *SignatureModule) Initialize(ctx context.Context, app *internal.App) error {
+	m.app = app
+	m.config = defaultSignatureConfig()
+	if err := loadModuleConfig(app, m.Name(), &m.config); err != nil {
+		return err
+	}
+	return checkRepos(app, m.Name(), m.config.Repos)
+}
+
+func (m *SignatureModule) HandleEvent(eventType string, event any, raw json.RawMessage) error {
+	if eventType != "pull_request" {
+		return nil
+	}
+	prEvent, ok := event.(*github.PullRequestEvent)
+	if !ok {
+		return nil
+	}
+	switch prEvent.GetAction() {
+	case "opened", "reopened", "synchronize":
+	default:
+		return nil
+	}
+	repo := prEvent.GetRepo().GetFullName()
+	if !reposInclude(m.app, m.config.Repos, repo) {
+		return nil
+	}
+	pr := prEvent.GetPullRequest()
+	if m.app == nil || m.app.GitHubClient == nil {
+		slog.Info("Signature check skipped (no GitHub client available)", "repo", repo, "pr", pr.GetNumber())
+		return nil
+	}
+
+	ctx := context.Background()
+	sigs, err := m.Signatures(ctx, repo, pr.GetNumber())
+	if err == nil {
+		err = internal.PublishCheckRun(ctx, m.app.GitHubClient, repo, m.checkRun(repo, pr.GetHead().GetSHA(), sigs))
+	}
+	if err != nil {
+		return internal.LogAndWrapError(err, internal.ErrorTypeModule, "commit_signatures", map[string]any{
+			"repo": repo,
+			"pr":   pr.GetNumber(),
+		})
+	}
+	return nil
+}
+
+// Signatures returns the verification state of every commit in a pull request.
+func (m *SignatureModule) Signatures(ctx context.Context, repo string, number int) ([]CommitSignature, error) {
+	owner, name, err := internal.SplitRepo(repo)
+	if err != nil {
+		return nil, err
+	}
+	var sigs []CommitSignature
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		commits, resp, err := m.app.GitHubClient.PullRequests.ListCommits(ctx, owner, name, number, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list commits: %w", err)
+		}
+		for _, c := range commits {
+			v := c.GetCommit().GetVerification()
+			sig := CommitSignature{
+				SHA:      c.GetSHA(),
+				Author:   c.GetAuthor().GetLogin(),
+				Verified: v.GetVerified(),
+				Reason:   v.GetReason(),
+			}
+			if sig.Author == "" {
+				sig.Author = c.GetCommit().GetAuthor().GetName()
+			}
+			if v.GetSignature() != "" {
+				sig.Kind = signatureKind(v.GetSignature())
+			}
+			sigs = append(sigs, sig)
+		}
+		if resp == nil || resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return sigs, nil
+}
+
+// signatureKind identifies the signing method from the armored signature.
+func signatureKind(signature string) string {
+	switch {
+	case strings.Contains(signature, "BEGIN PGP SIGNATURE"):
+		return SignatureGPG
+	case strings.Contains(signature, "BEGIN SSH SIGNATURE"):
+		return SignatureSSH
+	case strings.Contains(signature, "BEGIN SIGNED MESSAGE"):
+		// gitsign produces S/MIME signatures backed by sigstore certificates.
+		return SignatureSigstore
+	default:
+		return SignatureUnknown
+	}
+}
+
+// checkRun builds the signature check run. Unverified commits fail the check only in
+// enforcing repositories; elsewhere the result is informational.
+func (m *SignatureModule) checkRun(repo, headSHA string, sigs []CommitSignature) internal.CheckRun {
+	run := internal.CheckRun{Name: m.config.CheckName, HeadSHA: headSHA, Status: internal.CheckStatusCompleted}
+
+	unverified := 0
+	var b strings.Builder
+	b.WriteString("| Commit | Author | Signature | Verified |\n|--------|--------|-----------|----------|\n")
+	for _, s := range sigs {
+		mark := "✅"
+		if !s.Verified {
+			mark = "❌ " + s.Reason
+			unverified++
+		}
+		kind := s.Kind
+		if kind == "" {
+			kind = "none"
+		}
+		fmt.Fprintf(&b, "| %.7s | %s | %s | %s |\n", s.SHA, s.Author, kind, mark)
+	}
+	run.Summary = b.String()
+
+	switch {
+	case unverified == 0:
+		run.Conclusion = internal.CheckConclusionSuccess
+		run.Title = fmt.Sprintf("All %d commits are signed", len(sigs))
+	case slices.Contains(m.config.Enforce, repo):
+		run.Conclusion = internal.CheckConclusionFailure
+		run.Title = fmt.Sprintf("%d of %d commits are not verified", unverified, len(sigs))
+		run.Summary += "\nThis repository requires signed commits. Sign them with GPG, SSH or gitsign and force-push.\n"
+	default:
+		run.Conclusion = internal.CheckConclusionNeutral
+		run.Title = fmt.Sprintf("%d of %d commits are not verified", unverified, len(sigs))
+	}
+	return run
+}
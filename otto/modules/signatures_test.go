@@ -0,0 +1,88 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v71/github"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+func signedCommit(sha string, verified bool, reason, signature string) *github.RepositoryCommit {
+	return &github.RepositoryCommit{
+		SHA:    github.Ptr(sha),
+		Author: &github.User{Login: github.Ptr("alice")},
+		Commit: &github.Commit{Verification: &github.SignatureVerification{
+			Verified:  github.Ptr(verified),
+			Reason:    github.Ptr(reason),
+			Signature: github.Ptr(signature),
+		}},
+	}
+}
+
+func TestSignatureKind(t *testing.T) {
+	tests := []struct {
+		signature string
+		want      string
+	}{
+		{"-----BEGIN PGP SIGNATURE-----\n...", SignatureGPG},
+		{"-----BEGIN SSH SIGNATURE-----\n...", SignatureSSH},
+		{"-----BEGIN SIGNED MESSAGE-----\n...", SignatureSigstore},
+		{"garbage", SignatureUnknown},
+	}
+	for _, tt := range tests {
+		if got := signatureKind(tt.signature); got != tt.want {
+			t.Errorf("signatureKind(%q) = %q, want %q", tt.signature, got, tt.want)
+		}
+	}
+}
+
+func TestSignatureCheck(t *testing.T) {
+	fake := newFakeGitHub()
+	mod := &SignatureModule{}
+	if err := mod.Initialize(t.Context(), &internal.App{GitHubClient: fake.client(t)}); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	mod.config.Enforce = []string{"org/strict"}
+
+	for _, repo := range []string{"org/repo", "org/strict"} {
+		fake.addCommit(repo, 1, signedCommit("aaaaaaaaaa", true, "valid", "-----BEGIN SSH SIGNATURE-----"))
+		fake.addCommit(repo, 1, signedCommit("bbbbbbbbbb", false, "unsigned", ""))
+		fake.addCommit(repo, 2, signedCommit("cccccccccc", true, "valid", "-----BEGIN SIGNED MESSAGE-----"))
+	}
+
+	tests := []struct {
+		name       string
+		repo       string
+		number     int
+		conclusion string
+	}{
+		{"informational", "org/repo", 1, "neutral"},
+		{"enforced", "org/strict", 1, "failure"},
+		{"all signed", "org/strict", 2, "success"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := mod.HandleEvent("pull_request", pullRequestEvent("synchronize", tt.repo, tt.number, ""), nil); err != nil {
+				t.Fatalf("HandleEvent failed: %v", err)
+			}
+			runs := fake.checkRunsFor(tt.repo)
+			run := runs[len(runs)-1]
+			if run.GetConclusion() != tt.conclusion {
+				t.Errorf("conclusion = %q, want %q", run.GetConclusion(), tt.conclusion)
+			}
+			if run.Name != "otto/commit-signatures" {
+				t.Errorf("name = %q", run.Name)
+			}
+		})
+	}
+
+	runs := fake.checkRunsFor("org/repo")
+	summary := runs[0].GetOutput().GetSummary()
+	if !strings.Contains(summary, "| aaaaaaa | alice | ssh | ✅ |") || !strings.Contains(summary, "❌ unsigned") {
+		t.Errorf("unexpected summary:\n%s", summary)
+	}
+}
@@ -0,0 +1,369 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v71/github"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+// sizeLimitOverride is the argument of `/override` that accepts flagged files.
+const sizeLimitOverride = "size-limit"
+
+// SizeLimitConfig configures the large file and binary guard.
+type SizeLimitConfig struct {
+	CheckName     string   `yaml:"check_name" doc:"name of the check run"`
+	MaxFileBytes  int      `yaml:"max_file_bytes" doc:"files larger than this are flagged"`
+	BlockBinaries bool     `yaml:"block_binaries" doc:"flag binary files of any size"`
+	Allow         []string `yaml:"allow" doc:"path patterns never flagged, e.g. '*.png' or 'testdata/'"`
+}
+
+// SizeViolation is a file in a pull request that is too large or binary.
+type SizeViolation struct {
+	Path   string
+	Size   int
+	Binary bool
+}
+
+// SizeLimitModule flags pull requests that add or change files above a size limit, or binary
+// files, in the `otto/size-limit` check run. The check fails until a maintainer accepts the
+// flagged files with `/override size-limit`.
+type SizeLimitModule struct {
+	app      *internal.App
+	database *internal.Database
+	config   SizeLimitConfig
+	now      func() time.Time
+}
+
+func (m *SizeLimitModule) Name() string { return "sizelimit" }
+
+// ConfigSchema implements the ModuleConfigSchema interface.
+func (m *SizeLimitModule) ConfigSchema() any {
+	c := defaultSizeLimitConfig()
+	return &c
+}
+
+// defaultSizeLimitConfig returns the sizelimit module's defaults.
+func defaultSizeLimitConfig() SizeLimitConfig {
+	return SizeLimitConfig{
+		CheckName:     "otto/size-limit",
+		MaxFileBytes:  1 << 20,
+		BlockBinaries: true,
+	}
+}
+
+// EventSubscriptions implements the ModuleEventSubscriber interface.
+func (m *SizeLimitModule) EventSubscriptions() []internal.EventSubscription {
+	return []internal.EventSubscription{
+		internal.Subscribe("pull_request", "opened", "reopened", "synchronize"),
+	}
+}
+
+// GitHubPermissions implements the ModulePermissionRequirer interface.
+func (m *SizeLimitModule) GitHubPermissions() map[string]string {
+	return map[string]string{"checks": "write", "contents": "read", "issues": "write", "pull_requests": "read"}
+}
+
+// SlashCommands implements the ModuleCommander interface.
+func (m *SizeLimitModule) SlashCommands() []string {
+	return []string{"override"}
+}
+
+// Initialize implements the ModuleInitializer interface.
+func (m *SizeLimitModule) Initialize(ctx context.Context, app *internal.App) error {
+	m.app = app
+	m.database = app.Database
+	if m.now == nil {
+		m.now = time.Now
+	}
+	m.config = defaultSizeLimitConfig()
+	if err := loadModuleConfig(app, m.Name(), &m.config); err != nil {
+		return err
+	}
+	if m.config.MaxFileBytes <= 0 {
+		return fmt.Errorf("sizelimit: max_file_bytes must be positive, got %d", m.config.MaxFileBytes)
+	}
+	for _, pattern := range m.config.Allow {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("sizelimit: invalid allow pattern %q: %w", pattern, err)
+		}
+	}
+	return AutoMigrateSizeLimit(m.database.DB())
+}
+
+func (m *SizeLimitModule) HandleEvent(eventType string, event any, raw json.RawMessage) error {
+	return m.HandleEventContext(context.Background(), eventType, event, raw)
+}
+
+// HandleEventContext implements the ModuleContextHandler interface.
+func (m *SizeLimitModule) HandleEventContext(ctx context.Context, eventType string, event any,
+	raw json.RawMessage) error {
+	switch eventType {
+	case "pull_request":
+		prEvent, ok := event.(*github.PullRequestEvent)
+		if !ok {
+			return nil
+		}
+		switch prEvent.GetAction() {
+		case "opened", "reopened", "synchronize":
+			pr := prEvent.GetPullRequest()
+			return m.check(ctx, prEvent.GetRepo().GetFullName(), pr.GetNumber(), pr.GetHead().GetSHA(), "")
+		}
+	}
+	return nil
+}
+
+// HandleCommand implements the ModuleCommandHandler interface.
+func (m *SizeLimitModule) HandleCommand(cmd *internal.CommandContext) error {
+	if !cmd.Event.GetIssue().IsPullRequest() || !slices.Contains(cmd.Args, sizeLimitOverride) {
+		return nil
+	}
+	return m.handleOverride(cmd.Context, cmd.Event)
+}
+
+// handleOverride accepts the files currently flagged on a pull request.
+func (m *SizeLimitModule) handleOverride(ctx context.Context, event *github.IssueCommentEvent) error {
+	repo := event.GetRepo().GetFullName()
+	num := event.GetIssue().GetNumber()
+	login := event.GetComment().GetUser().GetLogin()
+	if !maintainerAssociations[event.GetComment().GetAuthorAssociation()] {
+		internal.AddDecision(ctx, fmt.Sprintf("rejected: override by %s, who is not a maintainer", login))
+		msg := fmt.Sprintf("⚠️ @%s only maintainers can use `/override %s`.", login, sizeLimitOverride)
+		return m.wrap(m.comment(ctx, repo, num, msg), "size_limit_override", repo, num)
+	}
+	if m.app == nil || m.app.GitHubClient == nil {
+		slog.Info("Size limit override skipped (no GitHub client available)", "repo", repo, "pr", num)
+		return nil
+	}
+	owner, name, err := internal.SplitRepo(repo)
+	if err != nil {
+		return m.wrap(err, "size_limit_override", repo, num)
+	}
+	pr, _, err := m.app.GitHubClient.PullRequests.Get(ctx, owner, name, num)
+	if err != nil {
+		return m.wrap(fmt.Errorf("failed to get pull request: %w", err), "size_limit_override", repo, num)
+	}
+	return m.check(ctx, repo, num, pr.GetHead().GetSHA(), login)
+}
+
+// check evaluates a pull request and publishes the check run. If overrideBy is set, the
+// flagged files are first accepted on behalf of that maintainer.
+func (m *SizeLimitModule) check(ctx context.Context, repo string, num int, headSHA, overrideBy string) error {
+	if m.app == nil || m.app.GitHubClient == nil {
+		slog.Info("Size limit check skipped (no GitHub client available)", "repo", repo, "pr", num)
+		return nil
+	}
+	violations, err := m.Violations(ctx, repo, num, headSHA)
+	if err != nil {
+		return m.wrap(err, "size_limit_check", repo, num)
+	}
+	db := m.database.DB()
+	if overrideBy != "" && len(violations) > 0 {
+		paths := make([]string, 0, len(violations))
+		for _, v := range violations {
+			paths = append(paths, v.Path)
+		}
+		if err := RecordSizeOverrides(db, repo, num, paths, overrideBy, m.now()); err != nil {
+			return internal.LogAndWrapError(err, internal.ErrorTypeDatabase, "size_limit_override", map[string]any{
+				"module": m.Name(),
+				"repo":   repo,
+				"issue":  num,
+			})
+		}
+	}
+	overrides, err := GetSizeOverrides(db, repo, num)
+	if err != nil {
+		return internal.LogAndWrapError(err, internal.ErrorTypeDatabase, "size_limit_check", map[string]any{
+			"module": m.Name(),
+			"repo":   repo,
+			"issue":  num,
+		})
+	}
+	run := m.checkRun(headSHA, violations, overrides)
+	internal.AddDecision(ctx, run.Conclusion+": "+run.Title)
+	return m.wrap(internal.PublishCheckRun(ctx, m.app.GitHubClient, repo, run), "size_limit_check", repo, num)
+}
+
+// Violations returns the files added or changed by a pull request that are larger than the
+// limit or binary, leaving out allowed paths.
+func (m *SizeLimitModule) Violations(ctx context.Context, repo string, num int,
+	headSHA string) ([]SizeViolation, error) {
+	owner, name, err := internal.SplitRepo(repo)
+	if err != nil {
+		return nil, err
+	}
+	var files []*github.CommitFile
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		page, resp, err := m.app.GitHubClient.PullRequests.ListFiles(ctx, owner, name, num, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list files: %w", err)
+		}
+		for _, f := range page {
+			if f.GetStatus() != "removed" && !m.allowed(f.GetFilename()) {
+				files = append(files, f)
+			}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	if len(files) == 0 {
+		return nil, nil
+	}
+
+	sizes, err := m.fileSizes(ctx, owner, name, headSHA, files)
+	if err != nil {
+		return nil, err
+	}
+	var violations []SizeViolation
+	for _, f := range files {
+		size := sizes[f.GetFilename()]
+		// GitHub lists binary files without a patch or changed lines.
+		binary := (f.GetStatus() == "added" || f.GetStatus() == "modified") &&
+			f.GetChanges() == 0 && f.GetPatch() == "" && size > 0
+		if size > m.config.MaxFileBytes || (binary && m.config.BlockBinaries) {
+			violations = append(violations, SizeViolation{Path: f.GetFilename(), Size: size, Binary: binary})
+		}
+	}
+	return violations, nil
+}
+
+// fileSizes returns the sizes of files at a commit, read from its tree. Files missing from
+// a truncated tree are looked up one by one.
+func (m *SizeLimitModule) fileSizes(ctx context.Context, owner, name, sha string,
+	files []*github.CommitFile) (map[string]int, error) {
+	tree, _, err := m.app.GitHubClient.Git.GetTree(ctx, owner, name, sha, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tree of %s: %w", sha, err)
+	}
+	sizes := make(map[string]int, len(tree.Entries))
+	for _, e := range tree.Entries {
+		if e.GetType() == "blob" {
+			sizes[e.GetPath()] = e.GetSize()
+		}
+	}
+	if !tree.GetTruncated() {
+		return sizes, nil
+	}
+	for _, f := range files {
+		if _, ok := sizes[f.GetFilename()]; ok {
+			continue
+		}
+		content, _, _, err := m.app.GitHubClient.Repositories.GetContents(ctx, owner, name, f.GetFilename(),
+			&github.RepositoryContentGetOptions{Ref: sha})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get size of %s: %w", f.GetFilename(), err)
+		}
+		sizes[f.GetFilename()] = content.GetSize()
+	}
+	return sizes, nil
+}
+
+// allowed reports whether a path matches an allow pattern. Patterns match the full path or
+// the file name, and patterns ending in a slash match everything below a directory.
+func (m *SizeLimitModule) allowed(p string) bool {
+	for _, pattern := range m.config.Allow {
+		if dir, ok := strings.CutSuffix(pattern, "/"); ok && strings.HasPrefix(p, dir+"/") {
+			return true
+		}
+		if full, _ := path.Match(pattern, p); full {
+			return true
+		}
+		if base, _ := path.Match(pattern, path.Base(p)); base {
+			return true
+		}
+	}
+	return false
+}
+
+// checkRun builds the size limit check run. It fails while any flagged file is not accepted
+// by a maintainer.
+func (m *SizeLimitModule) checkRun(headSHA string, violations []SizeViolation,
+	overrides map[string]string) internal.CheckRun {
+	run := internal.CheckRun{Name: m.config.CheckName, HeadSHA: headSHA, Status: internal.CheckStatusCompleted}
+	if len(violations) == 0 {
+		run.Conclusion = internal.CheckConclusionSuccess
+		run.Title = "No large or binary files"
+		run.Summary = fmt.Sprintf("No file is larger than %s or binary.", formatBytes(m.config.MaxFileBytes))
+		return run
+	}
+
+	pending := 0
+	var b strings.Builder
+	b.WriteString("| File | Size | Problem | Accepted by |\n|------|-----:|---------|-------------|\n")
+	for _, v := range violations {
+		var problems []string
+		if v.Size > m.config.MaxFileBytes {
+			problems = append(problems, "larger than "+formatBytes(m.config.MaxFileBytes))
+		}
+		if v.Binary && m.config.BlockBinaries {
+			problems = append(problems, "binary")
+		}
+		accepted := "–"
+		if login, ok := overrides[v.Path]; ok {
+			accepted = "@" + login
+		} else {
+			pending++
+		}
+		fmt.Fprintf(&b, "| `%s` | %s | %s | %s |\n", v.Path, formatBytes(v.Size), strings.Join(problems, ", "), accepted)
+	}
+
+	if pending == 0 {
+		run.Conclusion = internal.CheckConclusionSuccess
+		run.Title = fmt.Sprintf("%d large or binary files accepted by maintainers", len(violations))
+		run.Summary = b.String()
+		return run
+	}
+	run.Conclusion = internal.CheckConclusionFailure
+	run.Title = fmt.Sprintf("⚠️ %d large or binary files", pending)
+	fmt.Fprintf(&b, "\nLarge files and binaries bloat the repository for everyone. Please remove them, or ask a "+
+		"maintainer to accept them with `/override %s`.\n", sizeLimitOverride)
+	run.Summary = b.String()
+	return run
+}
+
+// formatBytes formats a size in bytes with a binary unit, e.g. 1.5 MiB.
+func formatBytes(n int) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := unit, 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+func (m *SizeLimitModule) comment(ctx context.Context, repo string, num int, body string) error {
+	if m.app == nil || m.app.GitHubClient == nil {
+		slog.Info("GitHub comment would be posted (no GitHub client available)",
+			"repo", repo, "issue_num", num, "message", body)
+		return nil
+	}
+	return m.app.PostComment(ctx, m.Name(), repo, num, body)
+}
+
+func (m *SizeLimitModule) wrap(err error, op, repo string, num int) error {
+	if err == nil {
+		return nil
+	}
+	return internal.LogAndWrapError(err, internal.ErrorTypeModule, op, map[string]any{
+		"module": m.Name(),
+		"repo":   repo,
+		"issue":  num,
+	})
+}
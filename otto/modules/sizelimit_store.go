@@ -0,0 +1,79 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+func AutoMigrateSizeLimit(db *sql.DB) error {
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS size_limit_overrides (
+			repo TEXT NOT NULL,
+			number INTEGER NOT NULL,
+			path TEXT NOT NULL,
+			login TEXT NOT NULL,
+			overridden_at TIMESTAMP NOT NULL,
+			PRIMARY KEY (repo, number, path)
+		);`,
+	}
+	for _, s := range stmts {
+		if _, err := db.Exec(s); err != nil {
+			return fmt.Errorf("failed migration: %w (SQL: %s)", err, s)
+		}
+	}
+	return nil
+}
+
+// Migrate implements the ModuleMigrator interface.
+func (m *SizeLimitModule) Migrate(db *sql.DB) error {
+	return AutoMigrateSizeLimit(db)
+}
+
+// RecordSizeOverrides records that login accepted the given files of a pull request.
+func RecordSizeOverrides(db *sql.DB, repo string, number int, paths []string, login string, at time.Time) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+	for _, p := range paths {
+		if _, err := tx.Exec(
+			`INSERT INTO size_limit_overrides (repo, number, path, login, overridden_at) VALUES (?, ?, ?, ?, ?)
+			 ON CONFLICT (repo, number, path) DO UPDATE SET login = excluded.login,
+			 overridden_at = excluded.overridden_at`,
+			repo, number, p, login, at,
+		); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetSizeOverrides returns who accepted each overridden file of a pull request, by path.
+func GetSizeOverrides(db *sql.DB, repo string, number int) (map[string]string, error) {
+	rows, err := db.Query(`SELECT path, login FROM size_limit_overrides WHERE repo = ? AND number = ?`, repo, number)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	overrides := make(map[string]string)
+	for rows.Next() {
+		var p, login string
+		if err := rows.Scan(&p, &login); err != nil {
+			return nil, err
+		}
+		overrides[p] = login
+	}
+	return overrides, rows.Err()
+}
+
+// RenameRepo implements the ModuleRepoRenamer interface.
+func (m *SizeLimitModule) RenameRepo(ctx context.Context, from, to string) error {
+	return internal.RenameRepoIn(ctx, m.database.DB(), from, to, "size_limit_overrides.repo")
+}
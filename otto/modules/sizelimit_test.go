@@ -0,0 +1,153 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v71/github"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+func newSizeLimitTestModule(t *testing.T, fake *fakeGitHub) *SizeLimitModule {
+	t.Helper()
+	db := internal.TestDB(t)
+	if err := AutoMigrateSizeLimit(db); err != nil {
+		t.Fatalf("AutoMigrateSizeLimit failed: %v", err)
+	}
+	return &SizeLimitModule{
+		app:      &internal.App{GitHubClient: fake.client(t)},
+		database: internal.NewDatabaseFromDB(db),
+		config: SizeLimitConfig{
+			CheckName:     "otto/size-limit",
+			MaxFileBytes:  1000,
+			BlockBinaries: true,
+			Allow:         []string{"*.svg", "docs/images/"},
+		},
+		now: time.Now,
+	}
+}
+
+// changedFile is a text file change, or a binary one if patch is empty.
+func changedFile(name, status, patch string) *github.CommitFile {
+	file := &github.CommitFile{Filename: github.Ptr(name), Status: github.Ptr(status)}
+	if patch != "" {
+		file.Patch = github.Ptr(patch)
+		file.Changes = github.Ptr(strings.Count(patch, "\n") + 1)
+	}
+	return file
+}
+
+func TestSizeLimitViolations(t *testing.T) {
+	fake := newFakeGitHub()
+	mod := newSizeLimitTestModule(t, fake)
+	for _, f := range []struct {
+		file *github.CommitFile
+		size int
+	}{
+		{changedFile("small.go", "added", "+package x"), 10},
+		{changedFile("big.json", "modified", "+{}"), 5000},
+		{changedFile("tool.exe", "added", ""), 200},
+		{changedFile("empty.txt", "added", ""), 0},
+		{changedFile("logo.svg", "added", "+<svg/>"), 5000},
+		{changedFile("docs/images/shot.png", "added", ""), 5000},
+		{changedFile("moved.bin", "renamed", ""), 200},
+		{changedFile("gone.bin", "removed", ""), 0},
+	} {
+		fake.addPullFile("org/repo", 1, "abc123", f.file, f.size)
+	}
+
+	violations, err := mod.Violations(t.Context(), "org/repo", 1, "abc123")
+	if err != nil {
+		t.Fatalf("Violations failed: %v", err)
+	}
+	want := []SizeViolation{
+		{Path: "big.json", Size: 5000},
+		{Path: "tool.exe", Size: 200, Binary: true},
+	}
+	if len(violations) != len(want) {
+		t.Fatalf("violations = %+v, want %+v", violations, want)
+	}
+	for i := range want {
+		if violations[i] != want[i] {
+			t.Errorf("violation %d = %+v, want %+v", i, violations[i], want[i])
+		}
+	}
+}
+
+func TestSizeLimitOverride(t *testing.T) {
+	fake := newFakeGitHub()
+	mod := newSizeLimitTestModule(t, fake)
+	fake.addPull("org/repo", &github.PullRequest{
+		Number: github.Ptr(1),
+		Head:   &github.PullRequestBranch{SHA: github.Ptr("abc123")},
+	})
+	fake.addPullFile("org/repo", 1, "abc123", changedFile("model.bin", "added", ""), 4096)
+
+	if err := mod.HandleEvent("pull_request", pullRequestEvent("opened", "org/repo", 1, ""), nil); err != nil {
+		t.Fatalf("HandleEvent failed: %v", err)
+	}
+	runs := fake.checkRunsFor("org/repo")
+	if len(runs) != 1 || runs[0].GetConclusion() != internal.CheckConclusionFailure {
+		t.Fatalf("check runs = %+v, want one failure", runs)
+	}
+	if summary := runs[0].GetOutput().GetSummary(); !strings.Contains(summary, "| `model.bin` | 4.0 KiB | "+
+		"larger than 1000 B, binary | – |") || !strings.Contains(summary, "`/override size-limit`") {
+		t.Errorf("summary = %s", summary)
+	}
+
+	// Only maintainers can override.
+	event := prCommentEvent("org/repo", 1, "carol", "/override size-limit")
+	event.Comment.AuthorAssociation = github.Ptr("CONTRIBUTOR")
+	if err := internal.TestCommands(t, mod, event); err != nil {
+		t.Fatalf("HandleCommand failed: %v", err)
+	}
+	if comments := fake.commentsOn("org/repo", 1); len(comments) != 1 ||
+		!strings.Contains(comments[0], "only maintainers can use `/override size-limit`") {
+		t.Errorf("comments = %v", comments)
+	}
+
+	event = prCommentEvent("org/repo", 1, "alice", "/override size-limit")
+	event.Comment.AuthorAssociation = github.Ptr("MEMBER")
+	if err := internal.TestCommands(t, mod, event); err != nil {
+		t.Fatalf("HandleCommand failed: %v", err)
+	}
+	runs = fake.checkRunsFor("org/repo")
+	if len(runs) != 2 || runs[1].GetConclusion() != internal.CheckConclusionSuccess ||
+		!strings.Contains(runs[1].GetOutput().GetSummary(), "| @alice |") {
+		t.Fatalf("check run after override = %+v", runs[len(runs)-1])
+	}
+
+	// A new large file needs another override; the accepted one stays accepted.
+	fake.addPullFile("org/repo", 1, "abc123", changedFile("data.bin", "added", ""), 10)
+	if err := mod.HandleEvent("pull_request", pullRequestEvent("synchronize", "org/repo", 1, ""), nil); err != nil {
+		t.Fatalf("HandleEvent failed: %v", err)
+	}
+	runs = fake.checkRunsFor("org/repo")
+	last := runs[len(runs)-1]
+	if last.GetConclusion() != internal.CheckConclusionFailure ||
+		last.GetOutput().GetTitle() != "⚠️ 1 large or binary files" {
+		t.Errorf("check run after push = %s: %s", last.GetConclusion(), last.GetOutput().GetTitle())
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		n    int
+		want string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1536, "1.5 KiB"},
+		{5 << 20, "5.0 MiB"},
+		{3 << 30, "3.0 GiB"},
+	}
+	for _, tt := range tests {
+		if got := formatBytes(tt.n); got != tt.want {
+			t.Errorf("formatBytes(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
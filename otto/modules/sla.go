@@ -0,0 +1,490 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v71/github"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+// SLAConfig configures the response timers of the sla module.
+type SLAConfig struct {
+	WaitingLabel            string        `yaml:"waiting_label" doc:"label of issues waiting for the author"`
+	ResponseLabel           string        `yaml:"response_label" doc:"label of issues waiting for maintainers"`
+	PingAfterDays           int           `yaml:"ping_after_days" doc:"days waiting for the author before a reminder"`
+	CloseAfterDays          int           `yaml:"close_after_days" doc:"days waiting for the author before closing"`
+	MaintainerPingAfterDays int           `yaml:"maintainer_ping_after_days" doc:"days before maintainers are pinged"`
+	MaintainerMention       string        `yaml:"maintainer_mention" doc:"e.g. '@org/maintainers'"`
+	CheckInterval           time.Duration `yaml:"check_interval" doc:"how often timers are checked"`
+}
+
+// maintainerAssociations are author associations treated as maintainer responses.
+var maintainerAssociations = map[string]bool{
+	"OWNER":        true,
+	"MEMBER":       true,
+	"COLLABORATOR": true,
+}
+
+// SLAModule pings and eventually closes issues waiting on their author, and
+// flips them back to maintainers once the author responds.
+type SLAModule struct {
+	app      *internal.App
+	database *internal.Database
+	config   SLAConfig
+	now      func() time.Time
+}
+
+func (s *SLAModule) Name() string { return "sla" }
+
+// ConfigSchema implements the ModuleConfigSchema interface.
+func (s *SLAModule) ConfigSchema() any {
+	c := defaultSLAConfig()
+	return &c
+}
+
+// defaultSLAConfig returns the sla module's defaults.
+func defaultSLAConfig() SLAConfig {
+	return SLAConfig{
+		WaitingLabel:            "waiting-for-author",
+		ResponseLabel:           "needs-maintainer-response",
+		PingAfterDays:           7,
+		CloseAfterDays:          14,
+		MaintainerPingAfterDays: 7,
+		CheckInterval:           time.Hour,
+	}
+}
+
+// EventSubscriptions implements the ModuleEventSubscriber interface.
+func (s *SLAModule) EventSubscriptions() []internal.EventSubscription {
+	return []internal.EventSubscription{
+		internal.Subscribe("issues", "labeled", "unlabeled", "closed", "reopened"),
+		internal.Subscribe("issue_comment", "created"),
+	}
+}
+
+// GitHubPermissions implements the ModulePermissionRequirer interface.
+func (s *SLAModule) GitHubPermissions() map[string]string {
+	return map[string]string{"issues": "write"}
+}
+
+// SlashCommands implements the ModuleCommander interface.
+func (s *SLAModule) SlashCommands() []string {
+	return []string{"close-all-stale"}
+}
+
+// Initialize implements the ModuleInitializer interface.
+func (s *SLAModule) Initialize(ctx context.Context, app *internal.App) error {
+	s.app = app
+	s.database = app.Database
+	if s.now == nil {
+		s.now = time.Now
+	}
+
+	s.config = defaultSLAConfig()
+	if err := loadModuleConfig(app, s.Name(), &s.config); err != nil {
+		return err
+	}
+	if s.config.CloseAfterDays > 0 && s.config.CloseAfterDays < s.config.PingAfterDays {
+		return fmt.Errorf("sla: close_after_days (%d) must not be less than ping_after_days (%d)",
+			s.config.CloseAfterDays, s.config.PingAfterDays)
+	}
+
+	if err := AutoMigrateSLA(s.database.DB()); err != nil {
+		return err
+	}
+
+	if app.Scheduler != nil {
+		app.Scheduler.Register(internal.Job{
+			Name:       "sla_timers",
+			Module:     s.Name(),
+			Deferrable: true,
+			Interval:   s.config.CheckInterval,
+			Run:        s.CheckTimers,
+		})
+	}
+	if app.Confirmations != nil {
+		app.Confirmations.Handle(slaCloseAllStale, s.confirmCloseAllStale)
+	}
+	return nil
+}
+
+func (s *SLAModule) HandleEvent(eventType string, event any, raw json.RawMessage) error {
+	ctx := context.Background()
+	switch eventType {
+	case "issues":
+		issuesEvent, ok := event.(*github.IssuesEvent)
+		if !ok {
+			return nil
+		}
+		return s.handleIssues(ctx, issuesEvent)
+	case "issue_comment":
+		commentEvent, ok := event.(*github.IssueCommentEvent)
+		if !ok || commentEvent.GetAction() != "created" {
+			return nil
+		}
+		return s.handleComment(ctx, commentEvent)
+	}
+	return nil
+}
+
+// handleIssues starts and stops timers as labels change or the issue closes or reopens.
+func (s *SLAModule) handleIssues(ctx context.Context, event *github.IssuesEvent) error {
+	db := s.database.DB()
+	repo := event.GetRepo().GetFullName()
+	issue := event.GetIssue()
+	label := event.GetLabel().GetName()
+
+	switch event.GetAction() {
+	case "labeled":
+		var kind SLATimerKind
+		switch label {
+		case s.config.WaitingLabel:
+			kind = SLAWaitingForAuthor
+			// A maintainer asked the author for input, so maintainers no longer owe a response.
+			s.removeLabel(ctx, repo, issue.GetNumber(), s.config.ResponseLabel, issue)
+		case s.config.ResponseLabel:
+			kind = SLAWaitingForMaintainer
+		default:
+			return nil
+		}
+		return s.wrap(StartSLATimer(db, SLATimer{
+			Repo:      repo,
+			IssueNum:  issue.GetNumber(),
+			Kind:      kind,
+			Author:    issue.GetUser().GetLogin(),
+			StartedAt: s.now(),
+		}), "start_timer", repo, issue.GetNumber())
+	case "unlabeled":
+		timer, err := GetSLATimer(db, repo, issue.GetNumber())
+		if err != nil || timer == nil {
+			return s.wrap(err, "get_timer", repo, issue.GetNumber())
+		}
+		if s.labelFor(timer.Kind) == label {
+			return s.wrap(DeleteSLATimer(db, repo, issue.GetNumber()), "delete_timer", repo, issue.GetNumber())
+		}
+	case "closed":
+		return s.wrap(DeleteSLATimer(db, repo, issue.GetNumber()), "delete_timer", repo, issue.GetNumber())
+	case "reopened":
+		// The timer restarts for the SLA label the issue still carries, waiting first.
+		var labels []string
+		for _, l := range issue.Labels {
+			labels = append(labels, l.GetName())
+		}
+		kind := SLAWaitingForAuthor
+		if !slices.Contains(labels, s.config.WaitingLabel) {
+			if !slices.Contains(labels, s.config.ResponseLabel) {
+				return nil
+			}
+			kind = SLAWaitingForMaintainer
+		}
+		return s.wrap(StartSLATimer(db, SLATimer{
+			Repo:      repo,
+			IssueNum:  issue.GetNumber(),
+			Kind:      kind,
+			Author:    issue.GetUser().GetLogin(),
+			StartedAt: s.now(),
+		}), "start_timer", repo, issue.GetNumber())
+	}
+	return nil
+}
+
+// handleComment flips the timer direction when the waited-on party responds.
+func (s *SLAModule) handleComment(ctx context.Context, event *github.IssueCommentEvent) error {
+	db := s.database.DB()
+	repo := event.GetRepo().GetFullName()
+	num := event.GetIssue().GetNumber()
+	commenter := event.GetComment().GetUser()
+	if commenter.GetType() == "Bot" {
+		return nil
+	}
+	timer, err := GetSLATimer(db, repo, num)
+	if err != nil || timer == nil {
+		return s.wrap(err, "get_timer", repo, num)
+	}
+
+	switch timer.Kind {
+	case SLAWaitingForAuthor:
+		if commenter.GetLogin() != timer.Author {
+			return nil
+		}
+		s.removeLabel(ctx, repo, num, s.config.WaitingLabel, nil)
+		s.addLabel(ctx, repo, num, s.config.ResponseLabel)
+		return s.wrap(StartSLATimer(db, SLATimer{
+			Repo:      repo,
+			IssueNum:  num,
+			Kind:      SLAWaitingForMaintainer,
+			Author:    timer.Author,
+			StartedAt: s.now(),
+		}), "start_timer", repo, num)
+	case SLAWaitingForMaintainer:
+		if commenter.GetLogin() == timer.Author ||
+			!maintainerAssociations[event.GetComment().GetAuthorAssociation()] {
+			return nil
+		}
+		s.removeLabel(ctx, repo, num, s.config.ResponseLabel, nil)
+		return s.wrap(DeleteSLATimer(db, repo, num), "delete_timer", repo, num)
+	}
+	return nil
+}
+
+// CheckTimers pings and closes issues whose timers have expired. It runs on the scheduler.
+func (s *SLAModule) CheckTimers(ctx context.Context) error {
+	db := s.database.DB()
+	timers, err := ListSLATimers(db)
+	if err != nil {
+		return fmt.Errorf("failed to list sla timers: %w", err)
+	}
+
+	now := s.now()
+	day := 24 * time.Hour
+	for _, t := range timers {
+		if !s.app.RepoActive(t.Repo) {
+			continue
+		}
+		age := now.Sub(t.StartedAt)
+		switch t.Kind {
+		case SLAWaitingForAuthor:
+			if s.config.CloseAfterDays > 0 && age >= time.Duration(s.config.CloseAfterDays)*day {
+				s.closeStale(ctx, t)
+				continue
+			}
+			if t.PingedAt == nil && s.config.PingAfterDays > 0 && age >= time.Duration(s.config.PingAfterDays)*day {
+				msg := fmt.Sprintf("@%s this issue is waiting for your response.", t.Author)
+				if s.config.CloseAfterDays > 0 {
+					msg += fmt.Sprintf(" It will be closed automatically if there is no reply within %d days.",
+						s.config.CloseAfterDays-s.config.PingAfterDays)
+				}
+				s.ping(ctx, t, now, msg)
+			}
+		case SLAWaitingForMaintainer:
+			if t.PingedAt == nil && s.config.MaintainerPingAfterDays > 0 &&
+				age >= time.Duration(s.config.MaintainerPingAfterDays)*day {
+				who := s.config.MaintainerMention
+				if who == "" {
+					who = "Maintainers"
+				}
+				s.ping(ctx, t, now, fmt.Sprintf("%s: the author responded %d days ago and this issue is waiting on you.",
+					who, int(age/day)))
+			}
+		}
+	}
+	return nil
+}
+
+// ping posts a reminder and records it so each timer only pings once.
+func (s *SLAModule) ping(ctx context.Context, t SLATimer, now time.Time, msg string) {
+	if err := s.comment(ctx, t.Repo, t.IssueNum, msg); err != nil {
+		slog.Error("Failed to post SLA reminder", "repo", t.Repo, "issue", t.IssueNum, "error", err)
+		return
+	}
+	if err := MarkSLATimerPinged(s.database.DB(), t.Repo, t.IssueNum, now); err != nil {
+		slog.Error("Failed to record SLA reminder", "repo", t.Repo, "issue", t.IssueNum, "error", err)
+	}
+}
+
+// closeStale closes an issue whose author never responded.
+func (s *SLAModule) closeStale(ctx context.Context, t SLATimer) {
+	msg := fmt.Sprintf("Closing this issue because there was no response from @%s within %d days. "+
+		"Feel free to reopen it with the requested information.", t.Author, s.config.CloseAfterDays)
+	s.closeIssue(ctx, t, msg)
+}
+
+// closeIssue comments, closes the issue as not planned and drops its timer. It
+// reports whether the issue was closed.
+func (s *SLAModule) closeIssue(ctx context.Context, t SLATimer, msg string) bool {
+	if err := s.comment(ctx, t.Repo, t.IssueNum, msg); err != nil {
+		slog.Error("Failed to post SLA close comment", "repo", t.Repo, "issue", t.IssueNum, "error", err)
+		return false
+	}
+	if s.app != nil && s.app.GitHubClient != nil {
+		owner, name, err := internal.SplitRepo(t.Repo)
+		if err != nil {
+			slog.Error("Invalid repository on SLA timer", "repo", t.Repo, "error", err)
+			return false
+		}
+		if _, _, err := s.app.GitHubClient.Issues.Edit(ctx, owner, name, t.IssueNum, &github.IssueRequest{
+			State:       github.Ptr("closed"),
+			StateReason: github.Ptr("not_planned"),
+		}); err != nil {
+			slog.Error("Failed to close issue", "repo", t.Repo, "issue", t.IssueNum, "error", err)
+			return false
+		}
+	}
+	if err := DeleteSLATimer(s.database.DB(), t.Repo, t.IssueNum); err != nil {
+		slog.Error("Failed to delete SLA timer", "repo", t.Repo, "issue", t.IssueNum, "error", err)
+	}
+	return true
+}
+
+// slaCloseAllStale is the confirmation kind for `/close-all-stale`.
+const slaCloseAllStale = "sla.close-all-stale"
+
+// HandleCommand implements the ModuleCommandHandler interface.
+func (s *SLAModule) HandleCommand(cmd *internal.CommandContext) error {
+	return s.handleCloseAllStale(cmd.Context, cmd.Event)
+}
+
+// handleCloseAllStale answers `/close-all-stale` from a maintainer with the issues that
+// would be closed and a token to confirm with; nothing is closed until `/confirm`.
+func (s *SLAModule) handleCloseAllStale(ctx context.Context, event *github.IssueCommentEvent) error {
+	repo := event.GetRepo().GetFullName()
+	num := event.GetIssue().GetNumber()
+	login := event.GetComment().GetUser().GetLogin()
+	if !maintainerAssociations[event.GetComment().GetAuthorAssociation()] {
+		return s.wrap(s.comment(ctx, repo, num, "⚠️ Only maintainers can use `/close-all-stale`."),
+			"close_all_stale", repo, num)
+	}
+	if s.app == nil || s.app.Confirmations == nil {
+		return s.wrap(s.comment(ctx, repo, num, "⚠️ `/close-all-stale` is not available: confirmations are disabled."),
+			"close_all_stale", repo, num)
+	}
+
+	stale, err := s.waitingTimers(repo)
+	if err != nil {
+		return s.wrap(err, "list_timers", repo, num)
+	}
+	if len(stale) == 0 {
+		return s.wrap(s.comment(ctx, repo, num, fmt.Sprintf("There are no issues labeled `%s` to close.",
+			s.config.WaitingLabel)), "close_all_stale", repo, num)
+	}
+
+	issues := make([]int, len(stale))
+	var summary strings.Builder
+	fmt.Fprintf(&summary, "Close %d issues waiting on their author:", len(stale))
+	for i, t := range stale {
+		issues[i] = t.IssueNum
+		fmt.Fprintf(&summary, " #%d", t.IssueNum)
+	}
+	action, err := s.app.Confirmations.Request(ctx, slaCloseAllStale, repo, num, login, summary.String(), issues)
+	if err != nil {
+		return s.wrap(err, "request_confirmation", repo, num)
+	}
+	msg := fmt.Sprintf("⚠️ @%s this will close %d issues waiting on their author:\n\n", login, len(stale))
+	for _, t := range stale {
+		msg += fmt.Sprintf("- #%d (waiting since %s)\n", t.IssueNum, t.StartedAt.Format(time.DateOnly))
+	}
+	msg += fmt.Sprintf("\nReply `/confirm %s` within %d minutes to proceed.",
+		action.Token, int(internal.ConfirmationTTL.Minutes()))
+	return s.wrap(s.comment(ctx, repo, num, msg), "close_all_stale", repo, num)
+}
+
+// confirmCloseAllStale closes the issues listed by `/close-all-stale` that are still
+// waiting on their author.
+func (s *SLAModule) confirmCloseAllStale(ctx context.Context, action internal.PendingAction) (string, error) {
+	var issues []int
+	if err := json.Unmarshal(action.Payload, &issues); err != nil {
+		return "", fmt.Errorf("invalid close-all-stale payload: %w", err)
+	}
+	stale, err := s.waitingTimers(action.Repo)
+	if err != nil {
+		return "", err
+	}
+	waiting := make(map[int]SLATimer, len(stale))
+	for _, t := range stale {
+		waiting[t.IssueNum] = t
+	}
+
+	closed, skipped := 0, 0
+	for _, n := range issues {
+		t, ok := waiting[n]
+		if !ok {
+			// The author responded or the label was removed since the command.
+			skipped++
+			continue
+		}
+		msg := fmt.Sprintf("Closing this issue because it is waiting on a response from @%s (bulk close requested "+
+			"by @%s in #%d). Feel free to reopen it with the requested information.", t.Author, action.User, action.Issue)
+		if s.closeIssue(ctx, t, msg) {
+			closed++
+		}
+	}
+	result := fmt.Sprintf("✅ Closed %d of %d issues.", closed, len(issues))
+	if skipped > 0 {
+		result += fmt.Sprintf(" %d no longer waiting on their author were left open.", skipped)
+	}
+	return result, nil
+}
+
+// waitingTimers returns the repository's waiting-for-author timers, oldest first.
+func (s *SLAModule) waitingTimers(repo string) ([]SLATimer, error) {
+	timers, err := ListSLATimers(s.database.DB())
+	if err != nil {
+		return nil, err
+	}
+	var out []SLATimer
+	for _, t := range timers {
+		if t.Repo == repo && t.Kind == SLAWaitingForAuthor {
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+func (s *SLAModule) labelFor(kind SLATimerKind) string {
+	if kind == SLAWaitingForAuthor {
+		return s.config.WaitingLabel
+	}
+	return s.config.ResponseLabel
+}
+
+func (s *SLAModule) comment(ctx context.Context, repo string, num int, body string) error {
+	if s.app == nil || s.app.GitHubClient == nil {
+		slog.Info("GitHub comment would be posted (no GitHub client available)",
+			"repo", repo, "issue_num", num, "message", body)
+		return nil
+	}
+	return s.app.PostComment(ctx, s.Name(), repo, num, body)
+}
+
+func (s *SLAModule) addLabel(ctx context.Context, repo string, num int, label string) {
+	if s.app == nil || s.app.GitHubClient == nil {
+		return
+	}
+	if err := s.app.AddLabels(ctx, s.Name(), repo, num, []string{label}); err != nil {
+		slog.Error("Failed to add label", "repo", repo, "issue", num, "label", label, "error", err)
+	}
+}
+
+// removeLabel removes label from an issue. If issue is non-nil, the call is skipped
+// when the payload shows the label is not applied.
+func (s *SLAModule) removeLabel(ctx context.Context, repo string, num int, label string, issue *github.Issue) {
+	if s.app == nil || s.app.GitHubClient == nil {
+		return
+	}
+	if issue != nil && !hasLabel(issue.Labels, label) {
+		return
+	}
+	if err := s.app.RemoveLabels(ctx, s.Name(), repo, num, []string{label}); err != nil {
+		slog.Error("Failed to remove label", "repo", repo, "issue", num, "label", label, "error", err)
+	}
+}
+
+func (s *SLAModule) wrap(err error, op, repo string, num int) error {
+	if err == nil {
+		return nil
+	}
+	return internal.LogAndWrapError(err, internal.ErrorTypeModule, op, map[string]any{
+		"module": s.Name(),
+		"repo":   repo,
+		"issue":  num,
+	})
+}
+
+// hasLabel reports whether labels contains a label with the given name.
+func hasLabel(labels []*github.Label, name string) bool {
+	for _, l := range labels {
+		if l.GetName() == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,111 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+// SLATimerKind identifies who is expected to respond while a timer runs.
+type SLATimerKind string
+
+const (
+	// SLAWaitingForAuthor runs while the issue author owes a response.
+	SLAWaitingForAuthor SLATimerKind = "author"
+	// SLAWaitingForMaintainer runs while maintainers owe a response.
+	SLAWaitingForMaintainer SLATimerKind = "maintainer"
+)
+
+// SLATimer tracks how long an issue has been waiting for a response.
+type SLATimer struct {
+	Repo      string
+	IssueNum  int
+	Kind      SLATimerKind
+	Author    string
+	StartedAt time.Time
+	PingedAt  *time.Time
+}
+
+func AutoMigrateSLA(db *sql.DB) error {
+	stmt := `CREATE TABLE IF NOT EXISTS sla_timers (
+		repo TEXT NOT NULL,
+		issue_num INTEGER NOT NULL,
+		kind TEXT NOT NULL,
+		author TEXT NOT NULL,
+		started_at TIMESTAMP NOT NULL,
+		pinged_at TIMESTAMP,
+		PRIMARY KEY (repo, issue_num)
+	);`
+	if _, err := db.Exec(stmt); err != nil {
+		return fmt.Errorf("failed migration: %w (SQL: %s)", err, stmt)
+	}
+	return nil
+}
+
+// Migrate implements the ModuleMigrator interface.
+func (s *SLAModule) Migrate(db *sql.DB) error {
+	return AutoMigrateSLA(db)
+}
+
+// StartSLATimer starts (or restarts) the single timer for an issue.
+func StartSLATimer(db *sql.DB, timer SLATimer) error {
+	_, err := db.Exec(
+		`INSERT INTO sla_timers (repo, issue_num, kind, author, started_at, pinged_at) VALUES (?, ?, ?, ?, ?, NULL)
+		 ON CONFLICT (repo, issue_num) DO UPDATE SET
+			kind = excluded.kind, author = excluded.author, started_at = excluded.started_at, pinged_at = NULL`,
+		timer.Repo, timer.IssueNum, string(timer.Kind), timer.Author, timer.StartedAt,
+	)
+	return err
+}
+
+func GetSLATimer(db *sql.DB, repo string, issueNum int) (*SLATimer, error) {
+	row := db.QueryRow(
+		`SELECT repo, issue_num, kind, author, started_at, pinged_at FROM sla_timers WHERE repo = ? AND issue_num = ?`,
+		repo, issueNum,
+	)
+	var t SLATimer
+	err := row.Scan(&t.Repo, &t.IssueNum, &t.Kind, &t.Author, &t.StartedAt, &t.PingedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return &t, err
+}
+
+func ListSLATimers(db *sql.DB) ([]SLATimer, error) {
+	rows, err := db.Query(
+		`SELECT repo, issue_num, kind, author, started_at, pinged_at FROM sla_timers ORDER BY started_at ASC`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var timers []SLATimer
+	for rows.Next() {
+		var t SLATimer
+		if err := rows.Scan(&t.Repo, &t.IssueNum, &t.Kind, &t.Author, &t.StartedAt, &t.PingedAt); err != nil {
+			return nil, err
+		}
+		timers = append(timers, t)
+	}
+	return timers, rows.Err()
+}
+
+func MarkSLATimerPinged(db *sql.DB, repo string, issueNum int, at time.Time) error {
+	_, err := db.Exec(`UPDATE sla_timers SET pinged_at = ? WHERE repo = ? AND issue_num = ?`, at, repo, issueNum)
+	return err
+}
+
+func DeleteSLATimer(db *sql.DB, repo string, issueNum int) error {
+	_, err := db.Exec(`DELETE FROM sla_timers WHERE repo = ? AND issue_num = ?`, repo, issueNum)
+	return err
+}
+
+// RenameRepo implements the ModuleRepoRenamer interface.
+func (s *SLAModule) RenameRepo(ctx context.Context, from, to string) error {
+	return internal.RenameRepoIn(ctx, s.database.DB(), from, to, "sla_timers.repo")
+}
@@ -0,0 +1,205 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"database/sql"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v71/github"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+)
+
+type slaTestEnv struct {
+	mod  *SLAModule
+	app  *internal.App
+	fake *fakeGitHub
+	db   *sql.DB
+	now  time.Time
+}
+
+func newSLATestEnv(t *testing.T) *slaTestEnv {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to open test db: %v", err)
+	}
+	env := &slaTestEnv{fake: newFakeGitHub(), db: db, now: time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)}
+	app := &internal.App{
+		Config: &config.AppConfig{Modules: map[string]any{
+			"sla": map[string]any{"ping_after_days": 3, "close_after_days": 10, "maintainer_mention": "@org/maint"},
+		}},
+		Database:     internal.NewDatabaseFromDB(db),
+		GitHubClient: env.fake.client(t),
+	}
+	if app.Confirmations, err = internal.NewConfirmations(db); err != nil {
+		t.Fatalf("NewConfirmations failed: %v", err)
+	}
+	env.app = app
+	env.mod = &SLAModule{now: func() time.Time { return env.now }}
+	if err := env.mod.Initialize(t.Context(), app); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	return env
+}
+
+func labeledEvent(repo string, num int, author, label string) *github.IssuesEvent {
+	return &github.IssuesEvent{
+		Action: github.Ptr("labeled"),
+		Repo:   &github.Repository{FullName: github.Ptr(repo)},
+		Issue:  &github.Issue{Number: github.Ptr(num), User: &github.User{Login: github.Ptr(author)}},
+		Label:  &github.Label{Name: github.Ptr(label)},
+	}
+}
+
+func TestSLAConfigLoaded(t *testing.T) {
+	env := newSLATestEnv(t)
+	if env.mod.config.PingAfterDays != 3 || env.mod.config.CloseAfterDays != 10 {
+		t.Errorf("module config not applied: %+v", env.mod.config)
+	}
+	if env.mod.config.WaitingLabel != "waiting-for-author" {
+		t.Errorf("default waiting label lost: %q", env.mod.config.WaitingLabel)
+	}
+}
+
+func TestSLAAuthorPingAndClose(t *testing.T) {
+	env := newSLATestEnv(t)
+	if err := env.mod.HandleEvent("issues", labeledEvent("org/repo", 1, "author", "waiting-for-author"), nil); err != nil {
+		t.Fatalf("HandleEvent failed: %v", err)
+	}
+
+	// Nothing happens before the ping threshold.
+	env.now = env.now.Add(2 * 24 * time.Hour)
+	_ = env.mod.CheckTimers(t.Context())
+	if got := env.fake.commentsOn("org/repo", 1); len(got) != 0 {
+		t.Fatalf("unexpected early comments: %v", got)
+	}
+
+	// One ping after N days, not repeated on the next check.
+	env.now = env.now.Add(2 * 24 * time.Hour)
+	_ = env.mod.CheckTimers(t.Context())
+	_ = env.mod.CheckTimers(t.Context())
+	comments := env.fake.commentsOn("org/repo", 1)
+	if len(comments) != 1 || !strings.Contains(comments[0], "@author") {
+		t.Fatalf("expected a single ping to the author, got %v", comments)
+	}
+
+	// Auto-close after M days of silence.
+	env.now = env.now.Add(7 * 24 * time.Hour)
+	_ = env.mod.CheckTimers(t.Context())
+	if state := env.fake.stateOf("org/repo", 1); state != "closed" {
+		t.Errorf("expected issue to be closed, got state %q", state)
+	}
+	if timer, _ := GetSLATimer(env.db, "org/repo", 1); timer != nil {
+		t.Errorf("timer should be removed after close")
+	}
+}
+
+func TestSLAAuthorReplyFlipsLabels(t *testing.T) {
+	env := newSLATestEnv(t)
+	env.fake.setLabels("org/repo", 2, "waiting-for-author")
+	_ = env.mod.HandleEvent("issues", labeledEvent("org/repo", 2, "author", "waiting-for-author"), nil)
+
+	// Someone other than the author commenting does not stop the timer.
+	_ = env.mod.HandleEvent("issue_comment", commentEvent("org/repo", 2, "bystander", "+1"), nil)
+	if timer, _ := GetSLATimer(env.db, "org/repo", 2); timer.Kind != SLAWaitingForAuthor {
+		t.Fatalf("timer flipped on non-author comment")
+	}
+
+	_ = env.mod.HandleEvent("issue_comment", commentEvent("org/repo", 2, "author", "here you go"), nil)
+	labels := env.fake.labelsOn("org/repo", 2)
+	if slices.Contains(labels, "waiting-for-author") || !slices.Contains(labels, "needs-maintainer-response") {
+		t.Errorf("labels not flipped: %v", labels)
+	}
+	timer, _ := GetSLATimer(env.db, "org/repo", 2)
+	if timer == nil || timer.Kind != SLAWaitingForMaintainer {
+		t.Fatalf("expected reverse timer, got %+v", timer)
+	}
+
+	// The reverse timer pings maintainers, and never closes the issue.
+	env.now = env.now.Add(30 * 24 * time.Hour)
+	_ = env.mod.CheckTimers(t.Context())
+	comments := env.fake.commentsOn("org/repo", 2)
+	if len(comments) != 1 || !strings.HasPrefix(comments[0], "@org/maint") {
+		t.Errorf("expected maintainer ping, got %v", comments)
+	}
+	if env.fake.stateOf("org/repo", 2) != "" {
+		t.Errorf("maintainer timer must not close issues")
+	}
+
+	// A maintainer reply clears the timer.
+	reply := commentEvent("org/repo", 2, "maintainer", "looking")
+	reply.Comment.AuthorAssociation = github.Ptr("MEMBER")
+	_ = env.mod.HandleEvent("issue_comment", reply, nil)
+	if timer, _ := GetSLATimer(env.db, "org/repo", 2); timer != nil {
+		t.Errorf("expected timer cleared after maintainer reply")
+	}
+}
+
+func TestSLATimerClearedOnCloseAndRestartedOnReopen(t *testing.T) {
+	env := newSLATestEnv(t)
+	_ = env.mod.HandleEvent("issues", labeledEvent("org/repo", 3, "author", "waiting-for-author"), nil)
+
+	deliver := func(action string) {
+		t.Helper()
+		event := labeledEvent("org/repo", 3, "author", "")
+		event.Action, event.Label = github.Ptr(action), nil
+		event.Issue.Labels = []*github.Label{{Name: github.Ptr("waiting-for-author")}}
+		if !internal.Subscribed(env.mod, "issues", event) {
+			t.Fatalf("sla is not subscribed to issues.%s", action)
+		}
+		if err := env.mod.HandleEvent("issues", event, nil); err != nil {
+			t.Fatalf("HandleEvent(issues.%s) failed: %v", action, err)
+		}
+	}
+
+	deliver("closed")
+	if timer, _ := GetSLATimer(env.db, "org/repo", 3); timer != nil {
+		t.Fatalf("timer = %+v after the issue closed, want none", timer)
+	}
+	env.now = env.now.Add(30 * 24 * time.Hour)
+	_ = env.mod.CheckTimers(t.Context())
+	if comments := env.fake.commentsOn("org/repo", 3); len(comments) != 0 {
+		t.Errorf("closed issue pinged: %q", comments)
+	}
+
+	deliver("reopened")
+	timer, _ := GetSLATimer(env.db, "org/repo", 3)
+	if timer == nil || timer.Kind != SLAWaitingForAuthor || !timer.StartedAt.Equal(env.now) {
+		t.Errorf("timer = %+v after the issue reopened, want a new waiting-for-author timer", timer)
+	}
+}
+
+func TestSLATimersFollowRepositoryEvents(t *testing.T) {
+	env := newSLATestEnv(t)
+	repos, err := internal.NewRepoRegistry(env.db)
+	if err != nil {
+		t.Fatalf("NewRepoRegistry failed: %v", err)
+	}
+	env.app.Repos = repos
+	if err := env.mod.HandleEvent("issues", labeledEvent("org/old", 1, "author", "waiting-for-author"), nil); err != nil {
+		t.Fatalf("HandleEvent failed: %v", err)
+	}
+
+	// Timers move with a renamed repository.
+	if err := env.mod.RenameRepo(t.Context(), "org/old", "org/repo"); err != nil {
+		t.Fatalf("RenameRepo failed: %v", err)
+	}
+	if timer, _ := GetSLATimer(env.db, "org/repo", 1); timer == nil {
+		t.Fatal("timer should move to the new repository name")
+	}
+
+	// Archived repositories are not pinged.
+	if err := repos.SetStatus(t.Context(), "org/repo", internal.RepoArchived); err != nil {
+		t.Fatalf("SetStatus failed: %v", err)
+	}
+	env.now = env.now.Add(4 * 24 * time.Hour)
+	_ = env.mod.CheckTimers(t.Context())
+	if got := env.fake.commentsOn("org/repo", 1); len(got) != 0 {
+		t.Errorf("archived repository was pinged: %v", got)
+	}
+}
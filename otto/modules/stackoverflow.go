@@ -0,0 +1,268 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+// stackOverflowMaxPages bounds the pages of questions fetched by one poll.
+const stackOverflowMaxPages = 10
+
+// StackOverflowConfig configures the posting of new Stack Overflow questions.
+type StackOverflowConfig struct {
+	Tags     []string      `yaml:"tags" doc:"questions with any of these tags are posted"`
+	Site     string        `yaml:"site" doc:"Stack Exchange site, e.g. stackoverflow"`
+	Backend  string        `yaml:"backend" doc:"notifier backend questions are posted with, e.g. slack"`
+	Target   string        `yaml:"target" doc:"where questions are posted, e.g. a Slack channel; unset posts none"`
+	Interval time.Duration `yaml:"interval" doc:"how often new questions are fetched"`
+	APIURL   string        `yaml:"api_url" doc:"Stack Exchange API base URL"`
+}
+
+// Validate implements the ModuleConfigValidator interface.
+func (c *StackOverflowConfig) Validate() error {
+	switch {
+	case len(c.Tags) == 0:
+		return errors.New("tags must not be empty")
+	case c.Site == "":
+		return errors.New("site must be set")
+	case c.Interval <= 0:
+		return errors.New("interval must be positive")
+	}
+	return nil
+}
+
+// StackOverflowModule posts new questions with the project's tags on Stack Overflow to a
+// chat channel. Posted questions and the time from which questions are fetched are kept in
+// the database, so none is posted twice across restarts.
+type StackOverflowModule struct {
+	app      *internal.App
+	database *internal.Database
+	config   StackOverflowConfig
+	client   *http.Client
+	now      func() time.Time
+}
+
+func (m *StackOverflowModule) Name() string { return "stackoverflow" }
+
+// ConfigSchema implements the ModuleConfigSchema interface.
+func (m *StackOverflowModule) ConfigSchema() any {
+	c := defaultStackOverflowConfig()
+	return &c
+}
+
+// defaultStackOverflowConfig returns the stackoverflow module's defaults, the tags and
+// schedule of the GitHub Action it replaces.
+func defaultStackOverflowConfig() StackOverflowConfig {
+	return StackOverflowConfig{
+		Tags:     []string{"open-telemetry", "otel"},
+		Site:     "stackoverflow",
+		Backend:  "slack",
+		Interval: time.Hour,
+		APIURL:   "https://api.stackexchange.com/2.3",
+	}
+}
+
+// EventSubscriptions implements the ModuleEventSubscriber interface. The module consumes
+// no events; it polls on a schedule.
+func (m *StackOverflowModule) EventSubscriptions() []internal.EventSubscription {
+	return nil
+}
+
+// Initialize implements the ModuleInitializer interface.
+func (m *StackOverflowModule) Initialize(ctx context.Context, app *internal.App) error {
+	m.app = app
+	m.database = app.Database
+	if m.now == nil {
+		m.now = time.Now
+	}
+	m.config = defaultStackOverflowConfig()
+	if err := loadModuleConfig(app, m.Name(), &m.config); err != nil {
+		return err
+	}
+	if m.config.Target == "" {
+		slog.Info("No target for Stack Overflow questions; none are posted")
+		return nil
+	}
+	if m.client == nil {
+		m.client = app.HTTPClient(30 * time.Second)
+	}
+	if err := AutoMigrateStackOverflow(m.database.DB()); err != nil {
+		return err
+	}
+	if app.Scheduler != nil {
+		app.Scheduler.Register(internal.Job{
+			Name:     "stackoverflow_questions",
+			Module:   m.Name(),
+			Interval: m.config.Interval,
+			Run:      m.PostNewQuestions,
+		})
+	}
+	return nil
+}
+
+func (m *StackOverflowModule) HandleEvent(eventType string, event any, raw json.RawMessage) error {
+	return nil
+}
+
+// PostNewQuestions posts the questions created since the last poll, oldest first. On the
+// first poll, without imported state, questions of the last interval are posted. A question
+// that fails to post stops the poll; it and those after it are retried by the next one.
+func (m *StackOverflowModule) PostNewQuestions(ctx context.Context) error {
+	db := m.database.DB()
+	site := m.config.Site
+	from, err := StackOverflowFetchFrom(db, site)
+	if err != nil {
+		return m.wrapDB(err, "get_fetch_from")
+	}
+	if from.IsZero() {
+		from = m.now().Add(-m.config.Interval)
+		if err := SetStackOverflowFetchFrom(db, site, from); err != nil {
+			return m.wrapDB(err, "set_fetch_from")
+		}
+	}
+
+	questions, err := m.fetchQuestions(ctx, from)
+	if err != nil {
+		return err
+	}
+	for _, q := range questions {
+		posted, err := StackOverflowQuestionPosted(db, site, q.ID)
+		if err != nil {
+			return m.wrapDB(err, "get_question")
+		}
+		if !posted {
+			if err := m.post(ctx, q); err != nil {
+				return fmt.Errorf("failed to post Stack Overflow question %d: %w", q.ID, err)
+			}
+			if err := RecordStackOverflowQuestion(db, site, q, m.now()); err != nil {
+				return m.wrapDB(err, "record_question")
+			}
+		}
+		// Questions created in the same second may still be missing from the results,
+		// so the next poll fetches from this one's creation time again.
+		if err := SetStackOverflowFetchFrom(db, site, q.CreatedAt); err != nil {
+			return m.wrapDB(err, "set_fetch_from")
+		}
+	}
+	return nil
+}
+
+// stackExchangeResponse is the wrapper of Stack Exchange API responses.
+type stackExchangeResponse struct {
+	Items []struct {
+		QuestionID   int64    `json:"question_id"`
+		Title        string   `json:"title"`
+		Link         string   `json:"link"`
+		Tags         []string `json:"tags"`
+		AnswerCount  int      `json:"answer_count"`
+		CreationDate int64    `json:"creation_date"`
+		Owner        struct {
+			DisplayName string `json:"display_name"`
+		} `json:"owner"`
+	} `json:"items"`
+	HasMore      bool   `json:"has_more"`
+	Backoff      int    `json:"backoff"`
+	ErrorMessage string `json:"error_message"`
+}
+
+// fetchQuestions returns the questions with any of the configured tags created at or after
+// from, oldest first.
+func (m *StackOverflowModule) fetchQuestions(ctx context.Context, from time.Time) ([]StackOverflowQuestion, error) {
+	var questions []StackOverflowQuestion
+	for page := 1; page <= stackOverflowMaxPages; page++ {
+		query := url.Values{
+			"site":     {m.config.Site},
+			"tagged":   {strings.Join(m.config.Tags, ";")},
+			"sort":     {"creation"},
+			"order":    {"asc"},
+			"min":      {strconv.FormatInt(from.Unix(), 10)},
+			"pagesize": {"100"},
+			"page":     {strconv.Itoa(page)},
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+			strings.TrimSuffix(m.config.APIURL, "/")+"/search?"+query.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := m.client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch Stack Overflow questions: %w", err)
+		}
+		var body stackExchangeResponse
+		err = json.NewDecoder(resp.Body).Decode(&body)
+		resp.Body.Close()
+		switch {
+		case resp.StatusCode != http.StatusOK && body.ErrorMessage != "":
+			return nil, fmt.Errorf("stack exchange API returned %s: %s", resp.Status, body.ErrorMessage)
+		case resp.StatusCode != http.StatusOK:
+			return nil, fmt.Errorf("stack exchange API returned %s", resp.Status)
+		case err != nil:
+			return nil, fmt.Errorf("failed to decode Stack Overflow questions: %w", err)
+		}
+		for _, item := range body.Items {
+			questions = append(questions, StackOverflowQuestion{
+				ID:        item.QuestionID,
+				Title:     html.UnescapeString(item.Title),
+				Link:      item.Link,
+				Tags:      item.Tags,
+				Author:    html.UnescapeString(item.Owner.DisplayName),
+				Answers:   item.AnswerCount,
+				CreatedAt: time.Unix(item.CreationDate, 0).UTC(),
+			})
+		}
+		// The API asks clients to wait before the next request to the same method.
+		if !body.HasMore || body.Backoff > 0 {
+			if body.Backoff > 0 {
+				slog.Info("Stack Exchange API asked to back off", "seconds", body.Backoff)
+			}
+			break
+		}
+	}
+	slices.SortStableFunc(questions, func(a, b StackOverflowQuestion) int { return a.CreatedAt.Compare(b.CreatedAt) })
+	return questions, nil
+}
+
+// post sends a question to the configured target.
+func (m *StackOverflowModule) post(ctx context.Context, q StackOverflowQuestion) error {
+	var tags []string
+	for _, tag := range q.Tags {
+		if !slices.Contains(m.config.Tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	body := "Asked by " + orUnknown(q.Author)
+	if len(tags) > 0 {
+		body += "\nTags: " + strings.Join(tags, ", ")
+	}
+	if q.Answers > 0 {
+		body += fmt.Sprintf("\nAnswers: %d", q.Answers)
+	}
+	return m.app.Notifications.Send(ctx, m.config.Backend, m.config.Target, internal.Notification{
+		Module:   m.Name(),
+		Severity: internal.SeverityInfo,
+		Title:    "New Stack Overflow question: " + q.Title,
+		Body:     body,
+		URL:      q.Link,
+	})
+}
+
+func (m *StackOverflowModule) wrapDB(err error, op string) error {
+	return internal.LogAndWrapError(err, internal.ErrorTypeDatabase, op, map[string]any{
+		"module": m.Name(),
+		"site":   m.config.Site,
+	})
+}
@@ -0,0 +1,110 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+//go:embed migrations/stackoverflow/*.sql
+var stackOverflowMigrations embed.FS
+
+// StackOverflowQuestion is a question found on a Stack Exchange site.
+type StackOverflowQuestion struct {
+	ID        int64
+	Title     string
+	Link      string
+	Tags      []string
+	Author    string
+	Answers   int
+	CreatedAt time.Time
+}
+
+func AutoMigrateStackOverflow(db *sql.DB) error {
+	migrations, err := fs.Sub(stackOverflowMigrations, "migrations/stackoverflow")
+	if err != nil {
+		return err
+	}
+	return internal.VersionedMigrations("stackoverflow", migrations)(db)
+}
+
+// Migrate implements the ModuleMigrator interface.
+func (m *StackOverflowModule) Migrate(db *sql.DB) error {
+	return AutoMigrateStackOverflow(db)
+}
+
+// StackOverflowFetchFrom returns the creation time from which a site's questions are
+// fetched, or the zero time if none were ever fetched.
+func StackOverflowFetchFrom(db *sql.DB, site string) (time.Time, error) {
+	var from time.Time
+	err := db.QueryRow(`SELECT fetch_from FROM stackoverflow_state WHERE site = ?`, site).Scan(&from)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, nil
+	}
+	return from, err
+}
+
+// SetStackOverflowFetchFrom moves the creation time from which a site's questions are
+// fetched forward to from. An earlier time than the current one is ignored, so that
+// questions are never fetched again once they are behind it.
+func SetStackOverflowFetchFrom(db *sql.DB, site string, from time.Time) error {
+	current, err := StackOverflowFetchFrom(db, site)
+	if err != nil || !from.After(current) {
+		return err
+	}
+	_, err = db.Exec(
+		`INSERT INTO stackoverflow_state (site, fetch_from, updated_at) VALUES (?, ?, ?)
+		 ON CONFLICT (site) DO UPDATE SET fetch_from = excluded.fetch_from, updated_at = excluded.updated_at`,
+		site, from.UTC(), time.Now().UTC(),
+	)
+	return err
+}
+
+// StackOverflowQuestionPosted reports whether a question of a site was already posted.
+func StackOverflowQuestionPosted(db *sql.DB, site string, id int64) (bool, error) {
+	var n int
+	err := db.QueryRow(`SELECT COUNT(*) FROM stackoverflow_questions WHERE site = ? AND question_id = ?`,
+		site, id).Scan(&n)
+	return n > 0, err
+}
+
+// RecordStackOverflowQuestion records that a question of a site was posted.
+func RecordStackOverflowQuestion(db *sql.DB, site string, q StackOverflowQuestion, postedAt time.Time) error {
+	_, err := db.Exec(
+		`INSERT INTO stackoverflow_questions (site, question_id, title, link, created_at, posted_at)
+		 VALUES (?, ?, ?, ?, ?, ?) ON CONFLICT (site, question_id) DO NOTHING`,
+		site, q.ID, q.Title, q.Link, q.CreatedAt.UTC(), postedAt.UTC(),
+	)
+	return err
+}
+
+// ImportStackOverflowState backfills a site's state from the state.txt the Stack Overflow
+// GitHub Action kept in the Actions cache: the Unix time of the newest question it posted.
+// Questions are then fetched from the second after it, unless the site's state is already
+// later. It returns the time questions are fetched from.
+func ImportStackOverflowState(db *sql.DB, site string, data []byte) (time.Time, error) {
+	text := strings.TrimSpace(string(data))
+	if text == "" {
+		return time.Time{}, errors.New("state is empty; the action has not posted any question yet")
+	}
+	seconds, err := strconv.ParseInt(text, 10, 64)
+	if err != nil || seconds <= 0 {
+		return time.Time{}, fmt.Errorf("state %q is not a Unix time", text)
+	}
+	if err := AutoMigrateStackOverflow(db); err != nil {
+		return time.Time{}, err
+	}
+	if err := SetStackOverflowFetchFrom(db, site, time.Unix(seconds+1, 0)); err != nil {
+		return time.Time{}, err
+	}
+	return StackOverflowFetchFrom(db, site)
+}
@@ -0,0 +1,175 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+)
+
+// questionNotifier records the notifications posted on the slack backend.
+type questionNotifier struct {
+	mu     sync.Mutex
+	posted []string // target: title
+	texts  []string
+	err    error
+}
+
+func (n *questionNotifier) Backend() string { return "slack" }
+
+func (n *questionNotifier) Notify(_ context.Context, target string, notification internal.Notification) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.err != nil {
+		return n.err
+	}
+	n.posted = append(n.posted, target+": "+notification.Title)
+	n.texts = append(n.texts, notification.Text())
+	return nil
+}
+
+// stackExchangeAPI serves the questions created at or after the min parameter of a search.
+type stackExchangeAPI struct {
+	mu        sync.Mutex
+	questions []map[string]any
+}
+
+func (api *stackExchangeAPI) add(id int64, title string, created time.Time, tags ...string) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	api.questions = append(api.questions, map[string]any{
+		"question_id":   id,
+		"title":         title,
+		"link":          "https://stackoverflow.com/questions/" + strconv.FormatInt(id, 10),
+		"tags":          tags,
+		"creation_date": created.Unix(),
+		"owner":         map[string]any{"display_name": "asker"},
+	})
+}
+
+func (api *stackExchangeAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	if r.URL.Path != "/search" || r.URL.Query().Get("tagged") != "open-telemetry;otel" {
+		http.Error(w, `{"error_message":"unexpected request"}`, http.StatusBadRequest)
+		return
+	}
+	minCreated, _ := strconv.ParseInt(r.URL.Query().Get("min"), 10, 64)
+	items := []map[string]any{}
+	for _, q := range api.questions {
+		if q["creation_date"].(int64) >= minCreated {
+			items = append(items, q)
+		}
+	}
+	_ = json.NewEncoder(w).Encode(map[string]any{"items": items, "has_more": false})
+}
+
+func newStackOverflowTestModule(t *testing.T, api http.Handler, notifier internal.Notifier, now time.Time,
+	db *internal.Database) *StackOverflowModule {
+	t.Helper()
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+	notifications, err := internal.NewNotifications(config.NotificationsConfig{}, nil)
+	if err != nil {
+		t.Fatalf("NewNotifications failed: %v", err)
+	}
+	notifications.Register(notifier)
+	cfg := defaultStackOverflowConfig()
+	cfg.Target, cfg.APIURL = "C0QUESTIONS", server.URL
+	if err := AutoMigrateStackOverflow(db.DB()); err != nil {
+		t.Fatalf("AutoMigrateStackOverflow failed: %v", err)
+	}
+	return &StackOverflowModule{
+		app:      &internal.App{Notifications: notifications},
+		database: db,
+		config:   cfg,
+		client:   server.Client(),
+		now:      func() time.Time { return now },
+	}
+}
+
+func TestStackOverflowPostNewQuestions(t *testing.T) {
+	now := time.Date(2025, time.May, 6, 12, 0, 0, 0, time.UTC)
+	api := &stackExchangeAPI{}
+	api.add(1, "Old question", now.Add(-2*time.Hour), "otel")
+	api.add(3, "Spans &amp; logs", now.Add(-10*time.Minute), "open-telemetry", "java")
+	api.add(2, "Missing metrics", now.Add(-30*time.Minute), "otel", "go")
+	notifier := &questionNotifier{}
+	db := internal.NewDatabaseFromDB(internal.TestDB(t))
+	mod := newStackOverflowTestModule(t, api, notifier, now, db)
+
+	// The first poll posts the questions of the last interval, oldest first.
+	if err := mod.PostNewQuestions(t.Context()); err != nil {
+		t.Fatalf("PostNewQuestions failed: %v", err)
+	}
+	want := []string{
+		"C0QUESTIONS: New Stack Overflow question: Missing metrics",
+		"C0QUESTIONS: New Stack Overflow question: Spans & logs",
+	}
+	if !slices.Equal(notifier.posted, want) {
+		t.Fatalf("posted = %q, want %q", notifier.posted, want)
+	}
+	if text := notifier.texts[1]; !strings.Contains(text, "Tags: java") || strings.Contains(text, "open-telemetry") ||
+		!strings.Contains(text, "https://stackoverflow.com/questions/3") {
+		t.Errorf("notification = %q", text)
+	}
+
+	// Neither the next poll nor one after a restart posts them again.
+	api.add(4, "Collector config", now.Add(-10*time.Minute), "otel")
+	restarted := newStackOverflowTestModule(t, api, notifier, now, db)
+	for _, m := range []*StackOverflowModule{mod, restarted} {
+		if err := m.PostNewQuestions(t.Context()); err != nil {
+			t.Fatalf("PostNewQuestions failed: %v", err)
+		}
+	}
+	if len(notifier.posted) != 3 || !strings.HasSuffix(notifier.posted[2], "Collector config") {
+		t.Errorf("posted = %q, want only the question created in the same second added", notifier.posted)
+	}
+
+	// A question that fails to post is retried by the next poll.
+	api.add(5, "Baggage", now.Add(-time.Minute), "otel")
+	notifier.err = errors.New("slack is down")
+	if err := mod.PostNewQuestions(t.Context()); err == nil {
+		t.Fatal("PostNewQuestions succeeded with a failing notifier")
+	}
+	notifier.err = nil
+	if err := mod.PostNewQuestions(t.Context()); err != nil {
+		t.Fatalf("PostNewQuestions failed: %v", err)
+	}
+	if len(notifier.posted) != 4 || !strings.HasSuffix(notifier.posted[3], "Baggage") {
+		t.Errorf("posted = %q", notifier.posted)
+	}
+}
+
+func TestImportStackOverflowState(t *testing.T) {
+	db := internal.TestDB(t)
+	from, err := ImportStackOverflowState(db, "stackoverflow", []byte("1746530000\n"))
+	if err != nil {
+		t.Fatalf("ImportStackOverflowState failed: %v", err)
+	}
+	if want := time.Unix(1746530001, 0); !from.Equal(want) {
+		t.Errorf("fetch from = %v, want %v", from, want)
+	}
+	// An older state does not fetch questions again.
+	if from, err := ImportStackOverflowState(db, "stackoverflow", []byte("1700000000")); err != nil ||
+		from.Unix() != 1746530001 {
+		t.Errorf("importing an older state = %v, %v", from, err)
+	}
+	for _, state := range []string{"", "\n", "yesterday", "-5"} {
+		if _, err := ImportStackOverflowState(db, "stackoverflow", []byte(state)); err == nil {
+			t.Errorf("ImportStackOverflowState(%q) succeeded", state)
+		}
+	}
+}
@@ -0,0 +1,244 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+// StatusConfig configures `/otto status`.
+type StatusConfig struct {
+	RateLimitThreshold int `yaml:"rate_limit_threshold" doc:"remaining GitHub API calls reported as low"`
+	DispatchBacklog    int `yaml:"dispatch_backlog" doc:"queued events reported as a backlog"`
+}
+
+// StatusModule answers `/otto status` with the bot's health as seen from the repository:
+// its version, the modules enabled for the repository, who is on call and any degraded
+// subsystems, so maintainers can tell why automation seems quiet without operator access.
+type StatusModule struct {
+	app    *internal.App
+	config StatusConfig
+	now    func() time.Time
+}
+
+func (m *StatusModule) Name() string { return "status" }
+
+// ConfigSchema implements the ModuleConfigSchema interface.
+func (m *StatusModule) ConfigSchema() any {
+	c := defaultStatusConfig()
+	return &c
+}
+
+// defaultStatusConfig returns the status module's defaults.
+func defaultStatusConfig() StatusConfig {
+	return StatusConfig{RateLimitThreshold: 500, DispatchBacklog: 100}
+}
+
+// EventSubscriptions implements the ModuleEventSubscriber interface. The module consumes
+// no events: the app hands it `/otto status` commands through HandleCommand.
+func (m *StatusModule) EventSubscriptions() []internal.EventSubscription {
+	return nil
+}
+
+// GitHubPermissions implements the ModulePermissionRequirer interface.
+func (m *StatusModule) GitHubPermissions() map[string]string {
+	return map[string]string{"issues": "write"}
+}
+
+// SlashCommands implements the ModuleCommander interface.
+func (m *StatusModule) SlashCommands() []string {
+	return []string{"otto status"}
+}
+
+// Initialize implements the ModuleInitializer interface.
+func (m *StatusModule) Initialize(ctx context.Context, app *internal.App) error {
+	m.app = app
+	m.config = defaultStatusConfig()
+	if m.now == nil {
+		m.now = time.Now
+	}
+	return loadModuleConfig(app, m.Name(), &m.config)
+}
+
+func (m *StatusModule) HandleEvent(eventType string, event any, raw json.RawMessage) error {
+	return nil
+}
+
+// HandleCommand implements the ModuleCommandHandler interface.
+func (m *StatusModule) HandleCommand(cmd *internal.CommandContext) error {
+	report := m.Report(cmd.Context, cmd.Repo)
+	return m.wrap(m.comment(cmd.Context, cmd.Repo, cmd.IssueNum, report), "status", cmd.Repo, cmd.IssueNum)
+}
+
+// Report renders the status of the bot for a repository.
+func (m *StatusModule) Report(ctx context.Context, repo string) string {
+	var b strings.Builder
+	b.WriteString("### Otto status\n\n")
+	fmt.Fprintf(&b, "- **Version:** %s", internal.BuildVersion())
+	if m.app.Config != nil && m.app.Config.InstanceID != "" {
+		fmt.Fprintf(&b, " (instance `%s`)", m.app.Config.InstanceID)
+	}
+	b.WriteString("\n")
+
+	enabled, disabled := m.modules(repo)
+	fmt.Fprintf(&b, "- **Modules enabled for %s:** %s\n", repo, codeList(enabled, "none"))
+	if len(disabled) > 0 {
+		fmt.Fprintf(&b, "- **Modules disabled for %s:** %s\n", repo, codeList(disabled, ""))
+	}
+	if oncall := m.onCall(); oncall != "" {
+		fmt.Fprintf(&b, "- **On call:** %s\n", oncall)
+	}
+
+	rate, degraded := m.rateLimit(ctx)
+	if rate != "" {
+		fmt.Fprintf(&b, "- **GitHub API:** %s\n", rate)
+	}
+	degraded = append(degraded, m.degraded()...)
+	if len(degraded) == 0 {
+		b.WriteString("\n✅ No degraded subsystems.")
+		return b.String()
+	}
+	b.WriteString("\n⚠️ Degraded:\n\n")
+	for _, d := range degraded {
+		fmt.Fprintf(&b, "- %s\n", d)
+	}
+	return b.String()
+}
+
+// modules returns the registered modules enabled and disabled for the repository, sorted.
+func (m *StatusModule) modules(repo string) (enabled, disabled []string) {
+	for name := range m.app.GetModules() {
+		if m.app.ModuleEnabled(repo, name) {
+			enabled = append(enabled, name)
+		} else {
+			disabled = append(disabled, name)
+		}
+	}
+	slices.Sort(enabled)
+	slices.Sort(disabled)
+	return enabled, disabled
+}
+
+// onCall describes who is on call for the oncall module's default schedule, or returns ""
+// if the module is not registered or has no default schedule.
+func (m *StatusModule) onCall() string {
+	oncall, ok := m.app.GetModules()["oncall"].(*OnCallModule)
+	if !ok || oncall.database == nil || oncall.config.DefaultSchedule == "" {
+		return ""
+	}
+	message, err := oncall.whoMessage(oncall.config.DefaultSchedule, m.now())
+	if err != nil {
+		return fmt.Sprintf("unknown (%v)", err)
+	}
+	return message
+}
+
+// rateLimit describes the remaining core GitHub API rate limit, and returns it as degraded
+// when it is below the threshold or cannot be read. Reading it does not count against it.
+func (m *StatusModule) rateLimit(ctx context.Context) (string, []string) {
+	if m.app.GitHubClient == nil {
+		return "", []string{"GitHub access is not configured."}
+	}
+	limits, _, err := m.app.GitHubClient.RateLimit.Get(ctx)
+	if err != nil || limits.GetCore() == nil {
+		slog.Warn("Failed to read the GitHub rate limit", "err", err)
+		return "", []string{"The GitHub rate limit could not be read."}
+	}
+	core := limits.GetCore()
+	rate := fmt.Sprintf("%d of %d requests left until %s UTC", core.Remaining, core.Limit,
+		core.Reset.UTC().Format("15:04"))
+	if core.Remaining < m.config.RateLimitThreshold {
+		return rate, []string{"GitHub rate limit low: " + rate + "."}
+	}
+	return rate, nil
+}
+
+// degraded lists the subsystems that are not healthy: the GitHub API status, exhausted
+// module budgets, modules lacking GitHub App permissions, a dispatch backlog and handlers
+// running past their deadline.
+func (m *StatusModule) degraded() []string {
+	var out []string
+	if m.app.GitHubStatus.Degraded() {
+		out = append(out, "GitHub reports an incident: "+m.app.GitHubStatus.Incident())
+	}
+	if m.app.Budgets != nil {
+		var exhausted []string
+		for module, remaining := range m.app.Budgets.Remaining() {
+			if remaining == 0 {
+				exhausted = append(exhausted, module)
+			}
+		}
+		if len(exhausted) > 0 {
+			slices.Sort(exhausted)
+			out = append(out, "GitHub API budget used up for the hour: "+codeList(exhausted, "")+".")
+		}
+	}
+	degradedModules := m.app.Permissions.Degraded()
+	for _, module := range slices.Sorted(maps.Keys(degradedModules)) {
+		out = append(out, fmt.Sprintf("`%s` lacks GitHub App permissions: %s.", module,
+			strings.Join(degradedModules[module], ", ")))
+	}
+	if m.app.Dispatch != nil {
+		interactive := m.app.Dispatch.Depth(internal.PriorityInteractive)
+		normal := m.app.Dispatch.Depth(internal.PriorityNormal)
+		background := m.app.Dispatch.Depth(internal.PriorityBackground)
+		total := interactive + normal + background
+		if m.config.DispatchBacklog > 0 && total >= m.config.DispatchBacklog {
+			out = append(out, fmt.Sprintf("%d events are waiting to be handled (%d interactive, %d normal, "+
+				"%d background).", total, interactive, normal, background))
+		}
+	}
+	if m.app.Watchdog != nil {
+		var overdue []string
+		for _, h := range m.app.Watchdog.Inflight() {
+			if h.Overdue {
+				overdue = append(overdue, fmt.Sprintf("`%s` (%s)", h.Module, h.Running.Round(time.Second)))
+			}
+		}
+		if len(overdue) > 0 {
+			out = append(out, "Handlers running past their deadline: "+strings.Join(overdue, ", ")+".")
+		}
+	}
+	return out
+}
+
+// codeList formats names as code, or returns empty if there are none.
+func codeList(names []string, empty string) string {
+	if len(names) == 0 {
+		return empty
+	}
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = "`" + name + "`"
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func (m *StatusModule) comment(ctx context.Context, repo string, num int, body string) error {
+	if m.app == nil || m.app.GitHubClient == nil {
+		slog.Info("GitHub comment would be posted (no GitHub client available)",
+			"repo", repo, "issue_num", num, "message", body)
+		return nil
+	}
+	return m.app.PostComment(ctx, m.Name(), repo, num, body)
+}
+
+func (m *StatusModule) wrap(err error, op, repo string, num int) error {
+	if err == nil {
+		return nil
+	}
+	return internal.LogAndWrapError(err, internal.ErrorTypeModule, op, map[string]any{
+		"module": m.Name(),
+		"repo":   repo,
+		"issue":  num,
+	})
+}
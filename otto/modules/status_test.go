@@ -0,0 +1,127 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v71/github"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+)
+
+func newTestStatus(t *testing.T, fake *fakeGitHub) *StatusModule {
+	t.Helper()
+	oncall, _ := newOnCallTestModule(t)
+	oncall.config.DefaultSchedule = "primary"
+	app := &internal.App{
+		Config:         &config.AppConfig{InstanceID: "otto-0"},
+		GitHubClient:   fake.client(t),
+		ModuleRegistry: internal.NewModuleRegistry(),
+	}
+	for _, m := range []internal.Module{oncall, &ApprovalModule{}, &HoldModule{}} {
+		app.RegisterModule(m)
+	}
+	repos, err := internal.NewRepoRegistry(internal.TestDB(t))
+	if err != nil {
+		t.Fatalf("NewRepoRegistry failed: %v", err)
+	}
+	if err := repos.Register(t.Context(), "org/onboarded", "alice", []string{"approvals", "status"}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	app.Repos = repos
+	mod := &StatusModule{}
+	if err := mod.Initialize(t.Context(), app); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	app.RegisterModule(mod)
+	return mod
+}
+
+func TestStatusHealthy(t *testing.T) {
+	fake := newFakeGitHub()
+	reset := time.Date(2026, 10, 17, 15, 4, 0, 0, time.UTC)
+	fake.rate = &github.Rate{Limit: 5000, Remaining: 4321, Reset: github.Timestamp{Time: reset}}
+	mod := newTestStatus(t, fake)
+
+	cmd := &internal.CommandContext{
+		Context:  t.Context(),
+		Command:  "otto",
+		Args:     []string{"status"},
+		Issuer:   "alice",
+		Repo:     "org/onboarded",
+		IssueNum: 1,
+	}
+	if err := mod.HandleCommand(cmd); err != nil {
+		t.Fatalf("HandleCommand failed: %v", err)
+	}
+	comments := fake.commentsOn("org/onboarded", 1)
+	if len(comments) != 1 {
+		t.Fatalf("expected one reply, got %v", comments)
+	}
+	for _, want := range []string{
+		"(instance `otto-0`)",
+		"**Modules enabled for org/onboarded:** `approvals`, `status`",
+		"**Modules disabled for org/onboarded:** `holds`, `oncall`",
+		"**On call:** 📟 @alice is on call for **primary**.",
+		"**GitHub API:** 4321 of 5000 requests left until 15:04 UTC",
+		"✅ No degraded subsystems.",
+	} {
+		if !strings.Contains(comments[0], want) {
+			t.Errorf("reply does not contain %q:\n%s", want, comments[0])
+		}
+	}
+}
+
+func TestStatusDegraded(t *testing.T) {
+	fake := newFakeGitHub()
+	fake.rate = &github.Rate{Limit: 5000, Remaining: 12, Reset: github.Timestamp{Time: time.Now()}}
+	mod := newTestStatus(t, fake)
+	budgets, err := internal.NewAPIBudgets(map[string]int{"coverage": 1, "digest": 10}, nil)
+	if err != nil {
+		t.Fatalf("NewAPIBudgets failed: %v", err)
+	}
+	if err := budgets.Allow(t.Context(), "coverage"); err != nil {
+		t.Fatalf("Allow failed: %v", err)
+	}
+	mod.app.Budgets = budgets
+	mod.app.Dispatch = internal.NewDispatchPool(config.DispatchConfig{}, nil)
+	for range 2 {
+		mod.app.Dispatch.Submit(internal.PriorityBackground, func() {})
+	}
+	mod.config.DispatchBacklog = 2
+	granted := func(context.Context) (map[string]string, error) { return map[string]string{"issues": "read"}, nil }
+	mod.app.Permissions, err = internal.NewPermissionCheck(granted, mod.app.ModuleRegistry, nil)
+	if err != nil {
+		t.Fatalf("NewPermissionCheck failed: %v", err)
+	}
+	if err := mod.app.Permissions.Check(t.Context()); err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+
+	report := mod.Report(t.Context(), "org/repo")
+	for _, want := range []string{
+		"⚠️ Degraded:",
+		"- GitHub rate limit low: 12 of 5000 requests left",
+		"- GitHub API budget used up for the hour: `coverage`.",
+		"- `approvals` lacks GitHub App permissions: issues:write, pull_requests:read.",
+		"- 2 events are waiting to be handled (0 interactive, 0 normal, 2 background).",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report does not contain %q:\n%s", want, report)
+		}
+	}
+	if strings.Contains(report, "Modules disabled") {
+		t.Errorf("modules reported disabled for a repository that is not onboarded:\n%s", report)
+	}
+
+	fake.rate = nil
+	report = mod.Report(t.Context(), "org/repo")
+	if !strings.Contains(report, "The GitHub rate limit could not be read.") {
+		t.Errorf("unreadable rate limit not reported:\n%s", report)
+	}
+}
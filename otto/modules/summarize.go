@@ -0,0 +1,234 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v71/github"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+// summaryCommentKey identifies the managed comment holding an issue's summary.
+const summaryCommentKey = "summary"
+
+// SummarizeConfig configures `/summarize`.
+type SummarizeConfig struct {
+	Backend     string             `yaml:"backend" doc:"summarization backend: 'extractive' or 'llm'"`
+	MaxComments int                `yaml:"max_comments" doc:"most recent comments summarized"`
+	MaxItems    int                `yaml:"max_items" doc:"entries per section of an extractive summary"`
+	LLM         SummarizeLLMConfig `yaml:"llm" doc:"OpenAI-compatible chat completions API of the llm backend"`
+}
+
+// SummarizeLLMConfig configures the llm summarization backend.
+type SummarizeLLMConfig struct {
+	Endpoint      string        `yaml:"endpoint" doc:"chat completions URL, e.g. of OpenAI or a local server"`
+	Model         string        `yaml:"model" doc:"model name sent with each request"`
+	APIKeySecret  string        `yaml:"api_key_secret" doc:"secret holding the API key; unset sends no key"`
+	Timeout       time.Duration `yaml:"timeout" doc:"limit on each summarization request"`
+	MaxInputChars int           `yaml:"max_input_chars" doc:"thread size sent; older comments are dropped beyond it"`
+}
+
+// Validate implements the ModuleConfigValidator interface.
+func (c *SummarizeConfig) Validate() error {
+	switch {
+	case c.Backend != "extractive" && c.Backend != "llm":
+		return fmt.Errorf("backend must be extractive or llm, got %q", c.Backend)
+	case c.MaxComments <= 0:
+		return errors.New("max_comments must be positive")
+	case c.MaxItems <= 0:
+		return errors.New("max_items must be positive")
+	case c.Backend == "llm" && (c.LLM.Endpoint == "" || c.LLM.Model == ""):
+		return errors.New("the llm backend needs llm.endpoint and llm.model")
+	case c.LLM.Timeout <= 0:
+		return errors.New("llm.timeout must be positive")
+	}
+	return nil
+}
+
+// SummarizeModule answers `/summarize` with a summary of the issue's comment thread: its
+// key points, open questions and decisions. The summary is kept in one managed comment
+// that each `/summarize` refreshes.
+type SummarizeModule struct {
+	app        *internal.App
+	config     SummarizeConfig
+	summarizer Summarizer
+	now        func() time.Time
+}
+
+func (m *SummarizeModule) Name() string { return "summarize" }
+
+// ConfigSchema implements the ModuleConfigSchema interface.
+func (m *SummarizeModule) ConfigSchema() any {
+	c := defaultSummarizeConfig()
+	return &c
+}
+
+// defaultSummarizeConfig returns the summarize module's defaults.
+func defaultSummarizeConfig() SummarizeConfig {
+	return SummarizeConfig{
+		Backend:     "extractive",
+		MaxComments: 200,
+		MaxItems:    5,
+		LLM: SummarizeLLMConfig{
+			APIKeySecret:  "summarize_api_key",
+			Timeout:       time.Minute,
+			MaxInputChars: 48000,
+		},
+	}
+}
+
+// EventSubscriptions implements the ModuleEventSubscriber interface. The module consumes
+// no events: the app hands it `/summarize` commands through HandleCommand.
+func (m *SummarizeModule) EventSubscriptions() []internal.EventSubscription {
+	return nil
+}
+
+// GitHubPermissions implements the ModulePermissionRequirer interface.
+func (m *SummarizeModule) GitHubPermissions() map[string]string {
+	return map[string]string{"issues": "write"}
+}
+
+// SlashCommands implements the ModuleCommander interface.
+func (m *SummarizeModule) SlashCommands() []string {
+	return []string{"summarize"}
+}
+
+// Initialize implements the ModuleInitializer interface.
+func (m *SummarizeModule) Initialize(ctx context.Context, app *internal.App) error {
+	m.app = app
+	if m.now == nil {
+		m.now = time.Now
+	}
+	m.config = defaultSummarizeConfig()
+	if err := loadModuleConfig(app, m.Name(), &m.config); err != nil {
+		return err
+	}
+	if m.summarizer != nil {
+		return nil
+	}
+	var apiKey string
+	if app.Secrets != nil && m.config.LLM.APIKeySecret != "" {
+		apiKey = app.Secrets.GetSecret(m.config.LLM.APIKeySecret)
+	}
+	summarizer, err := newSummarizer(m.config, apiKey, app.HTTPClient(m.config.LLM.Timeout))
+	if err != nil {
+		return fmt.Errorf("summarize: %w", err)
+	}
+	m.summarizer = summarizer
+	return nil
+}
+
+func (m *SummarizeModule) HandleEvent(eventType string, event any, raw json.RawMessage) error {
+	return nil
+}
+
+// HandleCommand implements the ModuleCommandHandler interface.
+func (m *SummarizeModule) HandleCommand(cmd *internal.CommandContext) error {
+	if m.app == nil || m.app.GitHubClient == nil {
+		slog.Info("Thread would be summarized (no GitHub client available)",
+			"repo", cmd.Repo, "issue_num", cmd.IssueNum)
+		return nil
+	}
+	thread, err := m.thread(cmd.Context, cmd.Repo, cmd.IssueNum)
+	if err != nil {
+		return m.wrap(err, "fetch_thread", cmd.Repo, cmd.IssueNum)
+	}
+	summary, err := m.summarizer.Summarize(cmd.Context, thread)
+	if err != nil {
+		slog.Error("Failed to summarize thread", "repo", cmd.Repo, "issue_num", cmd.IssueNum, "error", err)
+		return m.wrap(m.app.PostComment(cmd.Context, m.Name(), cmd.Repo, cmd.IssueNum,
+			"⚠️ The thread could not be summarized; try again later."), "summarize", cmd.Repo, cmd.IssueNum)
+	}
+	_, err = internal.UpsertManagedComment(cmd.Context, m.app.GitHubClient, cmd.Repo, cmd.IssueNum,
+		summaryCommentKey, m.render(thread, summary))
+	return m.wrap(err, "post_summary", cmd.Repo, cmd.IssueNum)
+}
+
+// thread fetches an issue and its most recent comments, leaving out bots' comments and
+// earlier summaries.
+func (m *SummarizeModule) thread(ctx context.Context, repo string, number int) (IssueThread, error) {
+	owner, name, err := internal.SplitRepo(repo)
+	if err != nil {
+		return IssueThread{}, err
+	}
+	issue, _, err := m.app.GitHubClient.Issues.Get(ctx, owner, name, number)
+	if err != nil {
+		return IssueThread{}, fmt.Errorf("failed to get issue: %w", err)
+	}
+	thread := IssueThread{
+		Repo:   repo,
+		Number: number,
+		Title:  issue.GetTitle(),
+		Author: issue.GetUser().GetLogin(),
+		Body:   issue.GetBody(),
+	}
+	opts := &github.IssueListCommentsOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		comments, resp, err := m.app.GitHubClient.Issues.ListComments(ctx, owner, name, number, opts)
+		if err != nil {
+			return IssueThread{}, fmt.Errorf("failed to list comments: %w", err)
+		}
+		for _, c := range comments {
+			if c.GetUser().GetType() == "Bot" || strings.HasSuffix(c.GetUser().GetLogin(), "[bot]") ||
+				strings.HasPrefix(c.GetBody(), internal.ManagedCommentMarker(summaryCommentKey)) {
+				continue
+			}
+			thread.Comments = append(thread.Comments, ThreadComment{
+				Author:    c.GetUser().GetLogin(),
+				Body:      c.GetBody(),
+				CreatedAt: c.GetCreatedAt().Time,
+			})
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	thread.Comments = thread.Comments[max(len(thread.Comments)-m.config.MaxComments, 0):]
+	return thread, nil
+}
+
+// render formats a summary for the managed comment.
+func (m *SummarizeModule) render(thread IssueThread, summary ThreadSummary) string {
+	var b strings.Builder
+	b.WriteString("### Thread summary\n")
+	for _, section := range []struct {
+		title string
+		items []string
+	}{
+		{"Key points", summary.KeyPoints},
+		{"Open questions", summary.OpenQuestions},
+		{"Decisions", summary.Decisions},
+	} {
+		fmt.Fprintf(&b, "\n**%s**\n\n", section.title)
+		if len(section.items) == 0 {
+			b.WriteString("_None found._\n")
+		}
+		for _, item := range section.items {
+			fmt.Fprintf(&b, "- %s\n", item)
+		}
+	}
+	fmt.Fprintf(&b, "\n<sub>Summary of %d comments by the %s backend as of %s UTC. "+
+		"Comment `/summarize` to refresh it.</sub>", len(thread.Comments), m.config.Backend,
+		m.now().UTC().Format("2006-01-02 15:04"))
+	return b.String()
+}
+
+func (m *SummarizeModule) wrap(err error, op, repo string, num int) error {
+	if err == nil {
+		return nil
+	}
+	return internal.LogAndWrapError(err, internal.ErrorTypeModule, op, map[string]any{
+		"module": m.Name(),
+		"repo":   repo,
+		"issue":  num,
+	})
+}
@@ -0,0 +1,95 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v71/github"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+)
+
+func TestSummarizeCommand(t *testing.T) {
+	fake := newFakeGitHub()
+	fake.issues["org/repo#7"] = &github.Issue{
+		Number: github.Ptr(7),
+		Title:  github.Ptr("Exporter drops spans"),
+		Body:   github.Ptr("The exporter drops spans under load."),
+		User:   &github.User{Login: github.Ptr("alice")},
+	}
+	comment := func(login, userType, body string) *github.IssueComment {
+		return &github.IssueComment{
+			Body: github.Ptr(body),
+			User: &github.User{Login: github.Ptr(login), Type: github.Ptr(userType)},
+		}
+	}
+	fake.comments["org/repo#7"] = []*github.IssueComment{
+		comment("bob", "User", "Should the queue grow when the backend is slow?"),
+		comment("linter[bot]", "Bot", "Is this a question from a bot?"),
+		comment("carol", "User", "We decided to make the queue size configurable."),
+		comment("carol", "User", "/summarize"),
+	}
+	mod := &SummarizeModule{now: func() time.Time { return time.Date(2026, 10, 17, 9, 30, 0, 0, time.UTC) }}
+	if err := mod.Initialize(t.Context(), &internal.App{GitHubClient: fake.client(t)}); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	cmd := &internal.CommandContext{Context: t.Context(), Command: "summarize", Issuer: "carol", Repo: "org/repo",
+		IssueNum: 7}
+	for range 2 {
+		if err := mod.HandleCommand(cmd); err != nil {
+			t.Fatalf("HandleCommand failed: %v", err)
+		}
+	}
+	comments := fake.commentsOn("org/repo", 7)
+	if len(comments) != 5 {
+		t.Fatalf("want one managed summary comment, got %d comments: %v", len(comments), comments)
+	}
+	summary := comments[4]
+	for _, want := range []string{
+		internal.ManagedCommentMarker(summaryCommentKey),
+		"- The exporter drops spans under load. (@alice)",
+		"**Open questions**\n\n- Should the queue grow when the backend is slow? (@bob)",
+		"**Decisions**\n\n- We decided to make the queue size configurable. (@carol)",
+		"Summary of 3 comments by the extractive backend as of 2026-10-17 09:30 UTC.",
+	} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("summary is missing %q:\n%s", want, summary)
+		}
+	}
+	if strings.Contains(summary, "bot?") {
+		t.Errorf("summary includes a bot's comment:\n%s", summary)
+	}
+
+	// A failing backend is reported on the issue.
+	mod.summarizer = failingSummarizer{}
+	if err := mod.HandleCommand(cmd); err != nil {
+		t.Fatalf("HandleCommand failed: %v", err)
+	}
+	comments = fake.commentsOn("org/repo", 7)
+	if last := comments[len(comments)-1]; !strings.Contains(last, "could not be summarized") {
+		t.Errorf("last comment = %q", last)
+	}
+}
+
+type failingSummarizer struct{}
+
+func (failingSummarizer) Summarize(ctx context.Context, thread IssueThread) (ThreadSummary, error) {
+	return ThreadSummary{}, errors.New("backend unavailable")
+}
+
+func TestSummarizeConfigValidate(t *testing.T) {
+	app := &internal.App{Config: &config.AppConfig{Modules: map[string]any{
+		"summarize": map[string]any{"backend": "llm"},
+	}}}
+	err := (&SummarizeModule{}).Initialize(t.Context(), app)
+	if err == nil || !strings.Contains(err.Error(), "needs llm.endpoint and llm.model") {
+		t.Errorf("Initialize with an incomplete llm backend = %v", err)
+	}
+}
@@ -0,0 +1,108 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testThread() IssueThread {
+	return IssueThread{
+		Repo:   "org/repo",
+		Number: 7,
+		Title:  "Exporter drops spans",
+		Author: "alice",
+		Body: "The exporter drops spans under load. It happens with batches over 512 spans.\n\n" +
+			"```\npanic: queue full\n```\n<!-- template -->",
+		Comments: []ThreadComment{
+			{Author: "bob", Body: "> The exporter drops spans\nI can reproduce this with the default queue size. " +
+				"Should the queue grow when the backend is slow?"},
+			{Author: "carol", Body: "/label bug"},
+			{Author: "carol", Body: "We decided to make the queue size configurable. A bounded queue is safer."},
+			{Author: "dave", Body: "Thanks!"},
+		},
+	}
+}
+
+func TestExtractiveSummarizer(t *testing.T) {
+	summary, err := extractiveSummarizer{maxItems: 5}.Summarize(t.Context(), testThread())
+	if err != nil {
+		t.Fatalf("Summarize failed: %v", err)
+	}
+	want := ThreadSummary{
+		KeyPoints: []string{
+			"The exporter drops spans under load. (@alice)",
+			"I can reproduce this with the default queue size. (@bob)",
+			"We decided to make the queue size configurable. (@carol)",
+		},
+		OpenQuestions: []string{"Should the queue grow when the backend is slow? (@bob)"},
+		Decisions:     []string{"We decided to make the queue size configurable. (@carol)"},
+	}
+	if !reflect.DeepEqual(summary, want) {
+		t.Errorf("summary = %+v\nwant %+v", summary, want)
+	}
+
+	// Sections are capped, keeping the latest questions.
+	summary, _ = extractiveSummarizer{maxItems: 1}.Summarize(t.Context(), IssueThread{
+		Author: "alice",
+		Body:   "Is this the first question? Is this the second question?",
+	})
+	if len(summary.OpenQuestions) != 1 || !strings.HasPrefix(summary.OpenQuestions[0], "Is this the second") {
+		t.Errorf("capped questions = %v", summary.OpenQuestions)
+	}
+}
+
+func TestLLMSummarizer(t *testing.T) {
+	var request struct {
+		Model    string `json:"model"`
+		Messages []struct {
+			Role    string `json:"role"`
+			Content string `json:"content"`
+		} `json:"messages"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer sk-test" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		_ = json.NewEncoder(w).Encode(map[string]any{"choices": []any{map[string]any{"message": map[string]any{
+			"content": "```json\n" + `{"key_points":["Spans are dropped."],"open_questions":[],` +
+				`"decisions":["Make the queue configurable."]}` + "\n```",
+		}}}})
+	}))
+	t.Cleanup(server.Close)
+
+	cfg := defaultSummarizeConfig().LLM
+	cfg.Endpoint, cfg.Model, cfg.MaxInputChars = server.URL, "small", 320
+	s := &llmSummarizer{cfg: cfg, apiKey: "sk-test", client: server.Client()}
+	thread := testThread()
+	thread.Comments[0].CreatedAt = time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+	summary, err := s.Summarize(t.Context(), thread)
+	if err != nil {
+		t.Fatalf("Summarize failed: %v", err)
+	}
+	if !reflect.DeepEqual(summary.Decisions, []string{"Make the queue configurable."}) || len(summary.KeyPoints) != 1 {
+		t.Errorf("summary = %+v", summary)
+	}
+	if request.Model != "small" || len(request.Messages) != 2 || request.Messages[0].Role != "system" {
+		t.Fatalf("request = %+v", request)
+	}
+	// The transcript keeps the issue and drops the oldest comments beyond the limit.
+	transcript := request.Messages[1].Content
+	if !strings.Contains(transcript, "Exporter drops spans") || strings.Contains(transcript, "@bob") ||
+		!strings.Contains(transcript, "@dave") {
+		t.Errorf("transcript = %q", transcript)
+	}
+
+	s.apiKey = "wrong"
+	if _, err := s.Summarize(t.Context(), thread); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("Summarize with a rejected key = %v", err)
+	}
+}
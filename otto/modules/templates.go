@@ -0,0 +1,269 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v71/github"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+// TemplateSyncConfig configures template drift detection.
+type TemplateSyncConfig struct {
+	TemplateRepo  string        `yaml:"template_repo" doc:"canonical source, e.g. 'open-telemetry/sig-template'"`
+	TemplateRef   string        `yaml:"template_ref" doc:"branch or tag; empty means the default branch"`
+	Files         []string      `yaml:"files" doc:"paths compared between the template and each repo"`
+	Repos         []string      `yaml:"repos" doc:"repos to check; empty means all registered repos"`
+	Branch        string        `yaml:"branch" doc:"branch used for sync pull requests"`
+	CheckInterval time.Duration `yaml:"check_interval" doc:"how often templates are compared"`
+}
+
+// TemplateSyncResult reports the outcome of syncing one repository.
+type TemplateSyncResult struct {
+	Repo        string
+	Drifted     []string
+	PullRequest *github.PullRequest // nil if nothing drifted
+}
+
+// TemplateSyncModule keeps boilerplate files in SIG repositories in sync with a template repository.
+type TemplateSyncModule struct {
+	app    *internal.App
+	config TemplateSyncConfig
+}
+
+func (m *TemplateSyncModule) Name() string { return "templates" }
+
+// ConfigSchema implements the ModuleConfigSchema interface.
+func (m *TemplateSyncModule) ConfigSchema() any {
+	c := defaultTemplateSyncConfig()
+	return &c
+}
+
+// defaultTemplateSyncConfig returns the templates module's defaults.
+func defaultTemplateSyncConfig() TemplateSyncConfig {
+	return TemplateSyncConfig{
+		Branch:        "otto/sync-templates",
+		CheckInterval: 24 * time.Hour,
+	}
+}
+
+// EventSubscriptions implements the ModuleEventSubscriber interface. The module consumes
+// no events: the app hands it `/sync-templates` commands through HandleCommand.
+func (m *TemplateSyncModule) EventSubscriptions() []internal.EventSubscription {
+	return nil
+}
+
+// GitHubPermissions implements the ModulePermissionRequirer interface.
+func (m *TemplateSyncModule) GitHubPermissions() map[string]string {
+	return map[string]string{"contents": "write", "pull_requests": "write"}
+}
+
+// SlashCommands implements the ModuleCommander interface.
+func (m *TemplateSyncModule) SlashCommands() []string {
+	return []string{"sync-templates"}
+}
+
+// Initialize implements the ModuleInitializer interface.
+func (m *TemplateSyncModule) Initialize(ctx context.Context, app *internal.App) error {
+	m.app = app
+	m.config = defaultTemplateSyncConfig()
+	if err := loadModuleConfig(app, m.Name(), &m.config); err != nil {
+		return err
+	}
+	if err := checkRepos(app, m.Name(), m.config.Repos); err != nil {
+		return err
+	}
+	if m.config.TemplateRepo == "" || len(m.config.Files) == 0 {
+		slog.Info("Template sync not configured; set template_repo and files to enable it")
+		return nil
+	}
+
+	if app.Scheduler != nil && m.config.CheckInterval > 0 {
+		app.Scheduler.Register(internal.Job{
+			Name:       "template_sync",
+			Module:     m.Name(),
+			Deferrable: true,
+			Interval:   m.config.CheckInterval,
+			Run:        m.SyncAll,
+		})
+	}
+	return nil
+}
+
+func (m *TemplateSyncModule) HandleEvent(eventType string, event any, raw json.RawMessage) error {
+	return nil
+}
+
+// HandleCommand implements the ModuleCommandHandler interface.
+func (m *TemplateSyncModule) HandleCommand(cmd *internal.CommandContext) error {
+	if m.config.TemplateRepo == "" {
+		return nil
+	}
+	ctx := cmd.Context
+	repo := cmd.Repo
+	issue := cmd.IssueNum
+	if !maintainerAssociations[cmd.Event.GetComment().GetAuthorAssociation()] {
+		m.comment(ctx, repo, issue, "⚠️ Only maintainers can run `/sync-templates`.")
+		return nil
+	}
+
+	result, err := m.Sync(ctx, repo)
+	if err != nil {
+		m.comment(ctx, repo, issue, fmt.Sprintf("❌ Template sync failed: %v", err))
+		return internal.LogAndWrapError(err, internal.ErrorTypeModule, "sync_templates", map[string]any{"repo": repo})
+	}
+	if len(result.Drifted) == 0 {
+		m.comment(ctx, repo, issue, fmt.Sprintf("✅ All template files match `%s`.", m.config.TemplateRepo))
+		return nil
+	}
+	m.comment(ctx, repo, issue, fmt.Sprintf("🔄 %d file(s) drifted from `%s`; sync pull request: %s",
+		len(result.Drifted), m.config.TemplateRepo, result.PullRequest.GetHTMLURL()))
+	return nil
+}
+
+// SyncAll checks every configured repository for drift, skipping archived ones.
+func (m *TemplateSyncModule) SyncAll(ctx context.Context) error {
+	repos, err := m.app.RepoGroups.Resolve(ctx, m.config.Repos)
+	if err != nil {
+		return err
+	}
+	if len(m.config.Repos) == 0 && m.app.Repos != nil {
+		registered, err := m.app.Repos.List(ctx)
+		if err != nil {
+			return err
+		}
+		for _, r := range registered {
+			repos = append(repos, r.FullName)
+		}
+	}
+	for _, repo := range repos {
+		if !m.app.RepoActive(repo) {
+			continue
+		}
+		if _, err := m.Sync(ctx, repo); err != nil {
+			slog.Error("Template sync failed", "repo", repo, "error", err)
+		}
+	}
+	return nil
+}
+
+// Sync compares the configured files in repo against the template repository and
+// opens (or updates) a pull request for any that drifted. Files missing from the
+// template are skipped.
+func (m *TemplateSyncModule) Sync(ctx context.Context, repo string) (*TemplateSyncResult, error) {
+	result := &TemplateSyncResult{Repo: repo}
+	if repo == m.config.TemplateRepo {
+		return result, nil
+	}
+	client := m.app.GitHubClient
+	if client == nil {
+		slog.Info("Template sync skipped (no GitHub client available)", "repo", repo)
+		return result, nil
+	}
+
+	owner, name, err := internal.SplitRepo(repo)
+	if err != nil {
+		return nil, err
+	}
+	info, _, err := client.Repositories.Get(ctx, owner, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repository %s: %w", repo, err)
+	}
+	base := info.GetDefaultBranch()
+
+	templates := map[string]*internal.RepoFile{}
+	for _, path := range m.config.Files {
+		tmpl, err := internal.GetFile(ctx, client, m.config.TemplateRepo, path, m.config.TemplateRef)
+		if err != nil {
+			return nil, err
+		}
+		if tmpl == nil {
+			slog.Warn("Template file not found", "template_repo", m.config.TemplateRepo, "path", path)
+			continue
+		}
+		current, err := internal.GetFile(ctx, client, repo, path, base)
+		if err != nil {
+			return nil, err
+		}
+		if current != nil && current.Content == tmpl.Content {
+			continue
+		}
+		templates[path] = tmpl
+		result.Drifted = append(result.Drifted, path)
+	}
+	if len(result.Drifted) == 0 {
+		return result, nil
+	}
+
+	branch := m.config.Branch
+	if err := internal.EnsureBranch(ctx, client, repo, branch, base); err != nil {
+		return nil, err
+	}
+	for _, path := range result.Drifted {
+		onBranch, err := internal.GetFile(ctx, client, repo, path, branch)
+		if err != nil {
+			return nil, err
+		}
+		sha := ""
+		if onBranch != nil {
+			if onBranch.Content == templates[path].Content {
+				continue // already synced by an earlier run
+			}
+			sha = onBranch.SHA
+		}
+		message := fmt.Sprintf("Sync %s from %s", path, m.config.TemplateRepo)
+		if err := internal.PutFile(ctx, client, repo, branch, path, message, templates[path].Content, sha); err != nil {
+			return nil, err
+		}
+	}
+
+	pr, err := internal.FindOpenPullRequest(ctx, client, repo, branch)
+	if err != nil {
+		return nil, err
+	}
+	if pr == nil {
+		pr, _, err = client.PullRequests.Create(ctx, owner, name, &github.NewPullRequest{
+			Title: github.Ptr("Sync template files from " + m.config.TemplateRepo),
+			Head:  github.Ptr(branch),
+			Base:  github.Ptr(base),
+			Body:  github.Ptr(renderTemplateSyncBody(m.config.TemplateRepo, result.Drifted)),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to open sync pull request in %s: %w", repo, err)
+		}
+	}
+	result.PullRequest = pr
+	slog.Info("Template drift synced", "repo", repo, "files", result.Drifted, "pr", pr.GetNumber())
+	return result, nil
+}
+
+// renderTemplateSyncBody builds the description of a sync pull request.
+func renderTemplateSyncBody(templateRepo string, files []string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "These files differ from their canonical versions in `%s`:\n\n", templateRepo)
+	for _, f := range files {
+		fmt.Fprintf(&b, "- `%s`\n", f)
+	}
+	b.WriteString("\nIf a difference is intentional, close this pull request and remove the file from " +
+		"the template sync configuration for this repository.\n")
+	return b.String()
+}
+
+// comment posts a plain comment, logging instead when no GitHub client is configured.
+func (m *TemplateSyncModule) comment(ctx context.Context, repo string, issue int, body string) {
+	if m.app == nil || m.app.GitHubClient == nil {
+		slog.Info("GitHub comment would be posted (no GitHub client available)",
+			"repo", repo, "issue", issue, "message", body)
+		return
+	}
+	if err := m.app.PostComment(ctx, m.Name(), repo, issue, body); err != nil {
+		slog.Error("Failed to post template sync comment", "repo", repo, "issue", issue, "error", err)
+	}
+}
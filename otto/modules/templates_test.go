@@ -0,0 +1,71 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v71/github"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+func TestTemplateSync(t *testing.T) {
+	fake := newFakeGitHub()
+	mod := &TemplateSyncModule{
+		app: &internal.App{GitHubClient: fake.client(t)},
+		config: TemplateSyncConfig{
+			TemplateRepo: "org/template",
+			Files:        []string{"CONTRIBUTING.md", ".github/ISSUE_TEMPLATE/bug.yaml", "missing.md"},
+			Branch:       "otto/sync-templates",
+		},
+	}
+
+	fake.setFile("org/template", "main", "CONTRIBUTING.md", "canonical contributing\n")
+	fake.setFile("org/template", "main", ".github/ISSUE_TEMPLATE/bug.yaml", "name: Bug\n")
+	fake.setFile("org/repo", "main", "CONTRIBUTING.md", "canonical contributing\n")
+	fake.setFile("org/repo", "main", ".github/ISSUE_TEMPLATE/bug.yaml", "name: Old bug\n")
+
+	event := commentEvent("org/repo", 5, "alice", "/sync-templates")
+	event.Comment.AuthorAssociation = github.Ptr("MEMBER")
+	if err := internal.TestCommands(t, mod, event); err != nil {
+		t.Fatalf("HandleCommand failed: %v", err)
+	}
+
+	got, ok := fake.fileOn("org/repo", "otto/sync-templates", ".github/ISSUE_TEMPLATE/bug.yaml")
+	if !ok || got != "name: Bug\n" {
+		t.Errorf("drifted file not synced on branch: %q", got)
+	}
+	if got, _ := fake.fileOn("org/repo", "main", ".github/ISSUE_TEMPLATE/bug.yaml"); got != "name: Old bug\n" {
+		t.Errorf("default branch modified: %q", got)
+	}
+	pulls := fake.pullsFor("org/repo")
+	if len(pulls) != 1 || !strings.Contains(pulls[0].GetBody(), "bug.yaml") ||
+		strings.Contains(pulls[0].GetBody(), "CONTRIBUTING.md") {
+		t.Fatalf("unexpected pull requests: %+v", pulls)
+	}
+	if comments := fake.commentsOn("org/repo", 5); len(comments) != 1 || !strings.Contains(comments[0], "1 file(s) drifted") {
+		t.Errorf("unexpected comments: %v", comments)
+	}
+
+	// A second run reuses the open pull request.
+	result, err := mod.Sync(t.Context(), "org/repo")
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if len(fake.pullsFor("org/repo")) != 1 || result.PullRequest.GetNumber() != pulls[0].GetNumber() {
+		t.Errorf("expected the existing pull request to be reused")
+	}
+
+	// Repositories without drift get no pull request.
+	fake.setFile("org/clean", "main", "CONTRIBUTING.md", "canonical contributing\n")
+	fake.setFile("org/clean", "main", ".github/ISSUE_TEMPLATE/bug.yaml", "name: Bug\n")
+	result, err = mod.Sync(t.Context(), "org/clean")
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if len(result.Drifted) != 0 || len(fake.pullsFor("org/clean")) != 0 {
+		t.Errorf("unexpected drift in clean repo: %v", result.Drifted)
+	}
+}
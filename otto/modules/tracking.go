@@ -0,0 +1,477 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v71/github"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+// trackingCommentKey identifies the managed progress comment on a tracking issue.
+const trackingCommentKey = "tracking"
+
+var (
+	// taskItemPattern matches a task list item, with its checkbox and text.
+	taskItemPattern = regexp.MustCompile(`^\s*(?:[-*+]|\d+[.)])\s+\[([ xX])\]\s+(.*\S)\s*$`)
+	// mentionPattern matches an @mention of a user or, with the trailing slash, of a team.
+	mentionPattern = regexp.MustCompile(`(^|[^\w/])@([A-Za-z0-9][A-Za-z0-9-]*)(/[\w.-]+)?`)
+)
+
+// TrackingConfig configures the progress tracking of tracking issues.
+type TrackingConfig struct {
+	Labels     []string      `yaml:"labels" doc:"issues with any of these labels are tracked"`
+	StaleAfter time.Duration `yaml:"stale_after" doc:"owners of open items unchanged this long are pinged; 0 never"`
+	Interval   time.Duration `yaml:"interval" doc:"how often tracking issues are refreshed and stalled items checked"`
+}
+
+// Validate implements the ModuleConfigValidator interface.
+func (c *TrackingConfig) Validate() error {
+	switch {
+	case len(c.Labels) == 0:
+		return errors.New("labels must not be empty")
+	case c.StaleAfter < 0:
+		return errors.New("stale_after must not be negative")
+	case c.Interval <= 0:
+		return errors.New("interval must be positive")
+	}
+	return nil
+}
+
+// TrackingModule keeps a progress summary of the task list and sub-issues of tracking
+// issues in a managed comment, and pings the owners of items that stall.
+type TrackingModule struct {
+	app      *internal.App
+	database *internal.Database
+	config   TrackingConfig
+	now      func() time.Time
+}
+
+func (m *TrackingModule) Name() string { return "tracking" }
+
+// ConfigSchema implements the ModuleConfigSchema interface.
+func (m *TrackingModule) ConfigSchema() any {
+	c := defaultTrackingConfig()
+	return &c
+}
+
+// defaultTrackingConfig returns the tracking module's defaults.
+func defaultTrackingConfig() TrackingConfig {
+	return TrackingConfig{
+		Labels:     []string{"tracking"},
+		StaleAfter: 14 * 24 * time.Hour,
+		Interval:   24 * time.Hour,
+	}
+}
+
+// EventSubscriptions implements the ModuleEventSubscriber interface.
+func (m *TrackingModule) EventSubscriptions() []internal.EventSubscription {
+	return []internal.EventSubscription{
+		internal.Subscribe("issues", "opened", "edited", "labeled", "unlabeled", "closed", "reopened"),
+	}
+}
+
+// GitHubPermissions implements the ModulePermissionRequirer interface.
+func (m *TrackingModule) GitHubPermissions() map[string]string {
+	return map[string]string{"issues": "write"}
+}
+
+// Initialize implements the ModuleInitializer interface.
+func (m *TrackingModule) Initialize(ctx context.Context, app *internal.App) error {
+	m.app = app
+	m.database = app.Database
+	if m.now == nil {
+		m.now = time.Now
+	}
+	m.config = defaultTrackingConfig()
+	if err := loadModuleConfig(app, m.Name(), &m.config); err != nil {
+		return err
+	}
+	if err := AutoMigrateTracking(m.database.DB()); err != nil {
+		return err
+	}
+	if app.Scheduler != nil {
+		app.Scheduler.Register(internal.Job{
+			Name:       "tracking_progress",
+			Module:     m.Name(),
+			Deferrable: true,
+			Interval:   m.config.Interval,
+			Run:        m.CheckTrackingIssues,
+		})
+	}
+	return nil
+}
+
+func (m *TrackingModule) HandleEvent(eventType string, event any, raw json.RawMessage) error {
+	issuesEvent, ok := event.(*github.IssuesEvent)
+	if eventType != "issues" || !ok || issuesEvent.GetIssue().IsPullRequest() {
+		return nil
+	}
+	ctx := context.Background()
+	issue := issuesEvent.GetIssue()
+	ref := IssueRef{Repo: issuesEvent.GetRepo().GetFullName(), Number: issue.GetNumber()}
+
+	action := issuesEvent.GetAction()
+	switch {
+	case m.tracked(issue):
+		if err := m.refresh(ctx, ref, issue); err != nil {
+			return err
+		}
+		if action == "closed" {
+			return m.wrapDB(UntrackIssue(m.database.DB(), ref), "untrack_issue", ref)
+		}
+	case action == "unlabeled":
+		return m.wrapDB(UntrackIssue(m.database.DB(), ref), "untrack_issue", ref)
+	}
+	// Closing or reopening an issue changes the progress of the tracking issues it is a
+	// sub-issue of.
+	if action == "closed" || action == "reopened" {
+		return m.refreshParents(ctx, ref)
+	}
+	return nil
+}
+
+// tracked reports whether an issue has one of the configured labels.
+func (m *TrackingModule) tracked(issue *github.Issue) bool {
+	return slices.ContainsFunc(m.config.Labels, func(label string) bool { return hasLabel(issue.Labels, label) })
+}
+
+// refreshParents refreshes the tracking issues an issue is a sub-issue of, as it is closed
+// or reopened.
+func (m *TrackingModule) refreshParents(ctx context.Context, sub IssueRef) error {
+	parents, err := ListTrackingParents(m.database.DB(), sub)
+	if err != nil {
+		return m.wrapDB(err, "list_parents", sub)
+	}
+	if len(parents) == 0 || m.app == nil || m.app.GitHubClient == nil {
+		return nil
+	}
+	var errs []error
+	for _, parent := range parents {
+		owner, name, err := internal.SplitRepo(parent.Repo)
+		if err != nil {
+			continue
+		}
+		issue, _, err := m.app.GitHubClient.Issues.Get(ctx, owner, name, parent.Number)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to get tracking issue %s#%d: %w", parent.Repo, parent.Number, err))
+			continue
+		}
+		if err := m.refresh(ctx, parent, issue); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// refresh records the task list and sub-issues of a tracking issue and updates its progress
+// comment if the summary changed.
+func (m *TrackingModule) refresh(ctx context.Context, ref IssueRef, issue *github.Issue) error {
+	db := m.database.DB()
+	items := parseTaskList(issue.GetBody())
+	subs, err := m.subIssues(ctx, ref)
+	if err != nil {
+		return internal.LogAndWrapError(err, internal.ErrorTypeModule, "list_sub_issues", map[string]any{
+			"repo":  ref.Repo,
+			"issue": ref.Number,
+		})
+	}
+	for _, sub := range subs {
+		items = append(items, subIssueItem(ref.Repo, sub))
+	}
+	now := m.now()
+	stored, err := SyncTrackingItems(db, ref, items, now)
+	if err != nil {
+		return m.wrapDB(err, "sync_items", ref)
+	}
+
+	previous, err := TrackingSummary(db, ref)
+	if err != nil {
+		return m.wrapDB(err, "get_summary", ref)
+	}
+	if len(stored) == 0 && previous == "" {
+		return nil // nothing to track yet
+	}
+	summary := renderTrackingProgress(ref.Repo, stored, now, m.config.StaleAfter)
+	if summary == previous {
+		return nil
+	}
+	if m.app == nil || m.app.GitHubClient == nil {
+		slog.Info("Progress comment would be updated (no GitHub client available)",
+			"repo", ref.Repo, "issue", ref.Number)
+	} else if _, err := internal.UpsertManagedComment(
+		ctx, m.app.GitHubClient, ref.Repo, ref.Number, trackingCommentKey, summary,
+	); err != nil {
+		return internal.LogAndWrapError(err, internal.ErrorTypeModule, "update_progress_comment", map[string]any{
+			"repo":  ref.Repo,
+			"issue": ref.Number,
+		})
+	}
+	return m.wrapDB(SetTrackingSummary(db, ref, summary), "set_summary", ref)
+}
+
+// subIssues returns the sub-issues of an issue. go-github does not cover the sub-issues
+// API yet, so it is requested directly; a parent has at most 100 sub-issues.
+func (m *TrackingModule) subIssues(ctx context.Context, ref IssueRef) ([]*github.Issue, error) {
+	if m.app == nil || m.app.GitHubClient == nil {
+		return nil, nil
+	}
+	owner, name, err := internal.SplitRepo(ref.Repo)
+	if err != nil {
+		return nil, err
+	}
+	req, err := m.app.GitHubClient.NewRequest(http.MethodGet,
+		fmt.Sprintf("repos/%s/%s/issues/%d/sub_issues?per_page=100", owner, name, ref.Number), nil)
+	if err != nil {
+		return nil, err
+	}
+	var issues []*github.Issue
+	resp, err := m.app.GitHubClient.Do(ctx, req, &issues)
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return nil, nil // sub-issues are not available on this repository
+	}
+	return issues, err
+}
+
+// CheckTrackingIssues refreshes every tracking issue, catching sub-issues added since its
+// last edit, and pings the owners of stalled items. It runs on the scheduler.
+func (m *TrackingModule) CheckTrackingIssues(ctx context.Context) error {
+	if m.app == nil || m.app.GitHubClient == nil {
+		return nil
+	}
+	db := m.database.DB()
+	issues, err := ListTrackedIssues(db)
+	if err != nil {
+		return fmt.Errorf("failed to list tracking issues: %w", err)
+	}
+	var errs []error
+	for _, ref := range issues {
+		if !m.app.RepoActive(ref.Repo) {
+			continue
+		}
+		owner, name, err := internal.SplitRepo(ref.Repo)
+		if err != nil {
+			continue
+		}
+		issue, _, err := m.app.GitHubClient.Issues.Get(ctx, owner, name, ref.Number)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to get tracking issue %s#%d: %w", ref.Repo, ref.Number, err))
+			continue
+		}
+		if issue.GetState() == "closed" || !m.tracked(issue) {
+			// The close or unlabel webhook was missed.
+			if err := UntrackIssue(db, ref); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		if err := m.refresh(ctx, ref, issue); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := m.pingStalled(ctx, ref); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// pingStalled mentions the owners of a tracking issue's open items that have not changed
+// for stale_after, in one comment, at most once per stale_after.
+func (m *TrackingModule) pingStalled(ctx context.Context, ref IssueRef) error {
+	if m.config.StaleAfter <= 0 {
+		return nil
+	}
+	db := m.database.DB()
+	items, err := ListTrackingItems(db, ref)
+	if err != nil {
+		return m.wrapDB(err, "list_items", ref)
+	}
+	now := m.now()
+	var lines, keys []string
+	for _, item := range items {
+		if !stalled(item, now, m.config.StaleAfter) || len(item.Owners) == 0 ||
+			(item.PingedAt != nil && now.Sub(*item.PingedAt) < m.config.StaleAfter) {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("- @%s: %s", strings.Join(item.Owners, ", @"), itemLabel(item, ref.Repo)))
+		keys = append(keys, item.Key)
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	message := fmt.Sprintf("⏰ These items have not moved in %s:\n\n%s\n\n"+
+		"Please post an update, check them off when done, or hand them over.",
+		formatDays(m.config.StaleAfter), strings.Join(lines, "\n"))
+	if err := m.app.PostComment(ctx, m.Name(), ref.Repo, ref.Number, message); err != nil {
+		return internal.LogAndWrapError(err, internal.ErrorTypeModule, "ping_stalled", map[string]any{
+			"repo":  ref.Repo,
+			"issue": ref.Number,
+		})
+	}
+	slog.Info("Pinged owners of stalled items", "repo", ref.Repo, "issue", ref.Number, "items", len(keys))
+	return m.wrapDB(MarkTrackingItemsPinged(db, ref, keys, now), "mark_pinged", ref)
+}
+
+// parseTaskList returns the task list items of a Markdown body, outside code blocks.
+func parseTaskList(body string) []TrackingItem {
+	var items []TrackingItem
+	inCode := false
+	for _, line := range strings.Split(body, "\n") {
+		if trimmed := strings.TrimSpace(line); strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inCode = !inCode
+			continue
+		}
+		match := taskItemPattern.FindStringSubmatch(line)
+		if inCode || match == nil {
+			continue
+		}
+		text := match[2]
+		var owners []string
+		for _, mention := range mentionPattern.FindAllStringSubmatch(text, -1) {
+			if mention[3] == "" && !slices.Contains(owners, mention[2]) {
+				owners = append(owners, mention[2])
+			}
+		}
+		slices.Sort(owners)
+		key := strings.ToLower(strings.Join(strings.Fields(mentionPattern.ReplaceAllString(text, "$1")), " "))
+		items = append(items, TrackingItem{Key: key, Text: text, Owners: owners, Done: match[1] != " "})
+	}
+	return items
+}
+
+// subIssueItem returns the item standing for a sub-issue of a tracking issue in repo.
+func subIssueItem(repo string, sub *github.Issue) TrackingItem {
+	ref := IssueRef{Repo: repo, Number: sub.GetNumber()}
+	// The repository URL is https://api.github.com/repos/{owner}/{repo}.
+	if parts := strings.Split(sub.GetRepositoryURL(), "/"); len(parts) >= 2 && sub.GetRepositoryURL() != "" {
+		ref.Repo = parts[len(parts)-2] + "/" + parts[len(parts)-1]
+	}
+	var owners []string
+	for _, assignee := range sub.Assignees {
+		owners = append(owners, assignee.GetLogin())
+	}
+	slices.Sort(owners)
+	return TrackingItem{
+		Key:    "sub:" + strconv.FormatInt(sub.GetID(), 10),
+		Text:   sub.GetTitle(),
+		Owners: owners,
+		Done:   sub.GetState() == "closed",
+		Ref:    ref,
+	}
+}
+
+// stalled reports whether an item is open and has not changed for staleAfter.
+func stalled(item TrackingItem, now time.Time, staleAfter time.Duration) bool {
+	return staleAfter > 0 && !item.Done && now.Sub(item.ChangedAt) >= staleAfter
+}
+
+// itemLabel renders an item, its sub-issue reference first.
+func itemLabel(item TrackingItem, relativeTo string) string {
+	if item.Ref.Number == 0 {
+		return item.Text
+	}
+	return formatIssueRef(item.Ref, relativeTo) + " " + item.Text
+}
+
+// renderTrackingProgress builds the Markdown body of the managed progress comment.
+func renderTrackingProgress(repo string, items []TrackingItem, now time.Time, staleAfter time.Duration) string {
+	var b strings.Builder
+	b.WriteString("### Progress\n\n")
+	if len(items) == 0 {
+		b.WriteString("_No task list items or sub-issues._\n")
+		return b.String()
+	}
+
+	type tally struct{ done, open int }
+	byOwner := map[string]*tally{}
+	var owners []string
+	done := 0
+	var stalledItems []TrackingItem
+	for _, item := range items {
+		if item.Done {
+			done++
+		}
+		if stalled(item, now, staleAfter) {
+			stalledItems = append(stalledItems, item)
+		}
+		itemOwners := item.Owners
+		if len(itemOwners) == 0 {
+			itemOwners = []string{""}
+		}
+		for _, owner := range itemOwners {
+			t, ok := byOwner[owner]
+			if !ok {
+				t = &tally{}
+				byOwner[owner] = t
+				owners = append(owners, owner)
+			}
+			if item.Done {
+				t.done++
+			} else {
+				t.open++
+			}
+		}
+	}
+	fmt.Fprintf(&b, "**%d/%d complete** (%d%%)\n\n", done, len(items), done*100/len(items))
+
+	// Owners with the most open items first; unassigned items last.
+	slices.SortStableFunc(owners, func(a, b string) int {
+		switch {
+		case a == "" || b == "":
+			return strings.Compare(b, a)
+		case byOwner[a].open != byOwner[b].open:
+			return byOwner[b].open - byOwner[a].open
+		}
+		return strings.Compare(a, b)
+	})
+	b.WriteString("| Owner | Done | Open |\n|---|---|---|\n")
+	for _, owner := range owners {
+		name := "_unassigned_"
+		if owner != "" {
+			name = "@" + owner
+		}
+		fmt.Fprintf(&b, "| %s | %d | %d |\n", name, byOwner[owner].done, byOwner[owner].open)
+	}
+
+	if len(stalledItems) > 0 {
+		fmt.Fprintf(&b, "\n**Stalled** (no change in %s)\n\n", formatDays(staleAfter))
+		for _, item := range stalledItems {
+			fmt.Fprintf(&b, "- %s (since %s)\n", itemLabel(item, repo), item.ChangedAt.UTC().Format(time.DateOnly))
+		}
+	}
+	b.WriteString("\n_Check off task list items or close sub-issues to update this summary._\n")
+	return b.String()
+}
+
+// formatDays renders a duration in days if it is a whole number of them.
+func formatDays(d time.Duration) string {
+	day := 24 * time.Hour
+	switch {
+	case d == day:
+		return "1 day"
+	case d%day == 0:
+		return fmt.Sprintf("%d days", d/day)
+	}
+	return d.String()
+}
+
+func (m *TrackingModule) wrapDB(err error, op string, ref IssueRef) error {
+	return internal.LogAndWrapError(err, internal.ErrorTypeDatabase, op, map[string]any{
+		"module": m.Name(),
+		"repo":   ref.Repo,
+		"issue":  ref.Number,
+	})
+}
@@ -0,0 +1,218 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+//go:embed migrations/tracking/*.sql
+var trackingMigrations embed.FS
+
+// TrackingItem is a task list item or sub-issue of a tracking issue.
+type TrackingItem struct {
+	Repo     string
+	IssueNum int
+	// Key identifies the item across edits of the issue: the text of a task without its
+	// mentions, or the ID of a sub-issue.
+	Key    string
+	Text   string
+	Owners []string // GitHub logins mentioned in a task, or the assignees of a sub-issue
+	Done   bool
+	// Ref is the sub-issue the item stands for; zero for a task.
+	Ref       IssueRef
+	ChangedAt time.Time
+	PingedAt  *time.Time
+}
+
+func AutoMigrateTracking(db *sql.DB) error {
+	migrations, err := fs.Sub(trackingMigrations, "migrations/tracking")
+	if err != nil {
+		return err
+	}
+	return internal.VersionedMigrations("tracking", migrations)(db)
+}
+
+// Migrate implements the ModuleMigrator interface.
+func (m *TrackingModule) Migrate(db *sql.DB) error {
+	return AutoMigrateTracking(db)
+}
+
+// SyncTrackingItems replaces the items of a tracked issue, tracking it if it was not. An
+// item keeps the time it last changed unless it was checked, unchecked or given other
+// owners since; items no longer in the issue are removed. It returns the stored items.
+func SyncTrackingItems(db *sql.DB, issue IssueRef, items []TrackingItem, now time.Time) ([]TrackingItem, error) {
+	existing, err := ListTrackingItems(db, issue)
+	if err != nil {
+		return nil, err
+	}
+	byKey := make(map[string]TrackingItem, len(existing))
+	for _, item := range existing {
+		byKey[item.Key] = item
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	if _, err := tx.Exec(
+		`INSERT INTO tracking_issues (repo, issue_num, updated_at) VALUES (?, ?, ?)
+		 ON CONFLICT (repo, issue_num) DO UPDATE SET updated_at = excluded.updated_at`,
+		issue.Repo, issue.Number, now.UTC(),
+	); err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(`DELETE FROM tracking_items WHERE repo = ? AND issue_num = ?`,
+		issue.Repo, issue.Number); err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool, len(items))
+	for i, item := range items {
+		if seen[item.Key] {
+			continue // a repeated task is tracked once
+		}
+		seen[item.Key] = true
+		changedAt, pingedAt := now.UTC(), (*time.Time)(nil)
+		if old, ok := byKey[item.Key]; ok && old.Done == item.Done && slices.Equal(old.Owners, item.Owners) {
+			changedAt, pingedAt = old.ChangedAt, old.PingedAt
+		}
+		if _, err := tx.Exec(
+			`INSERT INTO tracking_items (repo, issue_num, item_key, position, text, owners, done, ref_repo, ref_num,
+			 changed_at, pinged_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			issue.Repo, issue.Number, item.Key, i, item.Text, strings.Join(item.Owners, ","), item.Done,
+			item.Ref.Repo, item.Ref.Number, changedAt, pingedAt,
+		); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return ListTrackingItems(db, issue)
+}
+
+// ListTrackingItems returns the items of a tracked issue in the order they appear in it.
+func ListTrackingItems(db *sql.DB, issue IssueRef) ([]TrackingItem, error) {
+	rows, err := db.Query(
+		`SELECT item_key, text, owners, done, ref_repo, ref_num, changed_at, pinged_at
+		 FROM tracking_items WHERE repo = ? AND issue_num = ? ORDER BY position ASC`,
+		issue.Repo, issue.Number,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TrackingItem
+	for rows.Next() {
+		item := TrackingItem{Repo: issue.Repo, IssueNum: issue.Number}
+		var owners string
+		if err := rows.Scan(&item.Key, &item.Text, &owners, &item.Done, &item.Ref.Repo, &item.Ref.Number,
+			&item.ChangedAt, &item.PingedAt); err != nil {
+			return nil, err
+		}
+		if owners != "" {
+			item.Owners = strings.Split(owners, ",")
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// ListTrackedIssues returns the tracked issues.
+func ListTrackedIssues(db *sql.DB) ([]IssueRef, error) {
+	rows, err := db.Query(`SELECT repo, issue_num FROM tracking_issues ORDER BY repo ASC, issue_num ASC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var issues []IssueRef
+	for rows.Next() {
+		var ref IssueRef
+		if err := rows.Scan(&ref.Repo, &ref.Number); err != nil {
+			return nil, err
+		}
+		issues = append(issues, ref)
+	}
+	return issues, rows.Err()
+}
+
+// ListTrackingParents returns the tracked issues that have an issue as a sub-issue.
+func ListTrackingParents(db *sql.DB, sub IssueRef) ([]IssueRef, error) {
+	rows, err := db.Query(
+		`SELECT DISTINCT repo, issue_num FROM tracking_items WHERE ref_repo = ? AND ref_num = ?
+		 ORDER BY repo ASC, issue_num ASC`,
+		sub.Repo, sub.Number,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var parents []IssueRef
+	for rows.Next() {
+		var ref IssueRef
+		if err := rows.Scan(&ref.Repo, &ref.Number); err != nil {
+			return nil, err
+		}
+		parents = append(parents, ref)
+	}
+	return parents, rows.Err()
+}
+
+// UntrackIssue forgets a tracked issue and its items.
+func UntrackIssue(db *sql.DB, issue IssueRef) error {
+	if _, err := db.Exec(`DELETE FROM tracking_items WHERE repo = ? AND issue_num = ?`,
+		issue.Repo, issue.Number); err != nil {
+		return err
+	}
+	_, err := db.Exec(`DELETE FROM tracking_issues WHERE repo = ? AND issue_num = ?`, issue.Repo, issue.Number)
+	return err
+}
+
+// TrackingSummary returns the progress summary last posted on a tracked issue, or "" if
+// none was.
+func TrackingSummary(db *sql.DB, issue IssueRef) (string, error) {
+	var summary string
+	err := db.QueryRow(`SELECT summary FROM tracking_issues WHERE repo = ? AND issue_num = ?`,
+		issue.Repo, issue.Number).Scan(&summary)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return summary, err
+}
+
+// SetTrackingSummary records the progress summary posted on a tracked issue.
+func SetTrackingSummary(db *sql.DB, issue IssueRef, summary string) error {
+	_, err := db.Exec(`UPDATE tracking_issues SET summary = ? WHERE repo = ? AND issue_num = ?`,
+		summary, issue.Repo, issue.Number)
+	return err
+}
+
+// MarkTrackingItemsPinged records that the owners of items of a tracked issue were pinged.
+func MarkTrackingItemsPinged(db *sql.DB, issue IssueRef, keys []string, now time.Time) error {
+	for _, key := range keys {
+		if _, err := db.Exec(
+			`UPDATE tracking_items SET pinged_at = ? WHERE repo = ? AND issue_num = ? AND item_key = ?`,
+			now.UTC(), issue.Repo, issue.Number, key,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RenameRepo implements the ModuleRepoRenamer interface.
+func (m *TrackingModule) RenameRepo(ctx context.Context, from, to string) error {
+	return internal.RenameRepoIn(ctx, m.database.DB(), from, to,
+		"tracking_issues.repo", "tracking_items.repo", "tracking_items.ref_repo")
+}
@@ -0,0 +1,156 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v71/github"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+func TestParseTaskList(t *testing.T) {
+	body := strings.Join([]string{
+		"Tracking the 1.0 release.",
+		"",
+		"- [x] Stabilize the API @alice",
+		"* [ ] Write  the docs (@bob, @alice and @org/docs)",
+		"1. [X] Cut the RC",
+		"```",
+		"- [ ] not a task",
+		"```",
+		"- [] not a task either",
+		"- [ ] Stabilize the API @alice",
+	}, "\n")
+	items := parseTaskList(body)
+	var got []string
+	for _, item := range items {
+		check := " "
+		if item.Done {
+			check = "x"
+		}
+		got = append(got, item.Key+"|"+strings.Join(item.Owners, ",")+"|"+check)
+	}
+	want := []string{
+		"stabilize the api|alice|x",
+		"write the docs (, and )|alice,bob| ",
+		"cut the rc||x",
+		"stabilize the api|alice| ",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("items = %q, want %q", got, want)
+	}
+}
+
+// trackingIssue returns a tracking issue with a task list, stored in the fake.
+func trackingIssue(fake *fakeGitHub, body string) *github.Issue {
+	issue := &github.Issue{
+		Number: github.Ptr(1),
+		State:  github.Ptr("open"),
+		Body:   github.Ptr(body),
+		Labels: []*github.Label{{Name: github.Ptr("tracking")}},
+	}
+	fake.mu.Lock()
+	fake.issues["org/repo#1"] = issue
+	fake.mu.Unlock()
+	return issue
+}
+
+func trackingEvent(action string, issue *github.Issue) *github.IssuesEvent {
+	return &github.IssuesEvent{
+		Action: github.Ptr(action),
+		Repo:   &github.Repository{FullName: github.Ptr("org/repo")},
+		Issue:  issue,
+	}
+}
+
+func TestTrackingProgressAndStalledItems(t *testing.T) {
+	fake := newFakeGitHub()
+	db := internal.TestDB(t)
+	if err := AutoMigrateTracking(db); err != nil {
+		t.Fatalf("AutoMigrateTracking failed: %v", err)
+	}
+	now := time.Date(2025, time.May, 1, 9, 0, 0, 0, time.UTC)
+	m := &TrackingModule{
+		app:      &internal.App{GitHubClient: fake.client(t)},
+		database: internal.NewDatabaseFromDB(db),
+		config:   defaultTrackingConfig(),
+		now:      func() time.Time { return now },
+	}
+	issue := trackingIssue(fake, "- [x] Design @alice\n- [ ] Implement @alice\n- [ ] Release notes")
+	fake.mu.Lock()
+	fake.subIssues["org/repo#1"] = []*github.Issue{{
+		ID:            github.Ptr(int64(1002)),
+		Number:        github.Ptr(2),
+		Title:         github.Ptr("Port the exporter"),
+		State:         github.Ptr("open"),
+		RepositoryURL: github.Ptr("https://api.github.com/repos/org/repo"),
+		Assignees:     []*github.User{{Login: github.Ptr("bob")}},
+	}}
+	fake.mu.Unlock()
+
+	if err := m.HandleEvent("issues", trackingEvent("labeled", issue), nil); err != nil {
+		t.Fatalf("HandleEvent failed: %v", err)
+	}
+	comments := fake.commentsOn("org/repo", 1)
+	if len(comments) != 1 {
+		t.Fatalf("comments = %q, want one progress comment", comments)
+	}
+	for _, want := range []string{
+		"**1/4 complete** (25%)", "| @alice | 1 | 1 |", "| @bob | 0 | 1 |", "| _unassigned_ | 0 | 1 |",
+	} {
+		if !strings.Contains(comments[0], want) {
+			t.Errorf("progress comment %q does not contain %q", comments[0], want)
+		}
+	}
+
+	// Closing the sub-issue updates the progress comment in place.
+	fake.mu.Lock()
+	fake.subIssues["org/repo#1"][0].State = github.Ptr("closed")
+	fake.mu.Unlock()
+	sub := &github.Issue{Number: github.Ptr(2), State: github.Ptr("closed")}
+	if err := m.HandleEvent("issues", trackingEvent("closed", sub), nil); err != nil {
+		t.Fatalf("HandleEvent failed: %v", err)
+	}
+	comments = fake.commentsOn("org/repo", 1)
+	if len(comments) != 1 || !strings.Contains(comments[0], "**2/4 complete** (50%)") {
+		t.Fatalf("comments after closing the sub-issue = %q", comments)
+	}
+
+	// Two weeks later, alice is pinged about her open task once; nobody owns the unassigned one.
+	now = now.Add(15 * 24 * time.Hour)
+	for range 2 {
+		if err := m.CheckTrackingIssues(t.Context()); err != nil {
+			t.Fatalf("CheckTrackingIssues failed: %v", err)
+		}
+	}
+	comments = fake.commentsOn("org/repo", 1)
+	if len(comments) != 2 || !strings.Contains(comments[1], "not moved in 14 days") ||
+		!strings.Contains(comments[1], "- @alice: Implement @alice") || strings.Contains(comments[1], "Release notes") {
+		t.Fatalf("comments = %q, want one ping of alice", comments)
+	}
+	if !strings.Contains(comments[0], "**Stalled** (no change in 14 days)") {
+		t.Errorf("progress comment does not list stalled items: %q", comments[0])
+	}
+
+	// Checking the task off resets it; removing the label stops the tracking.
+	issue.Body = github.Ptr("- [x] Design @alice\n- [x] Implement @alice\n- [ ] Release notes")
+	if err := m.HandleEvent("issues", trackingEvent("edited", issue), nil); err != nil {
+		t.Fatalf("HandleEvent failed: %v", err)
+	}
+	items, _ := ListTrackingItems(db, IssueRef{Repo: "org/repo", Number: 1})
+	if len(items) != 4 || !items[1].Done || !items[1].ChangedAt.Equal(now) || items[1].PingedAt != nil {
+		t.Errorf("items after checking off = %+v", items)
+	}
+	issue.Labels = nil
+	if err := m.HandleEvent("issues", trackingEvent("unlabeled", issue), nil); err != nil {
+		t.Fatalf("HandleEvent failed: %v", err)
+	}
+	if tracked, _ := ListTrackedIssues(db); len(tracked) != 0 {
+		t.Errorf("tracked issues after unlabeling = %v", tracked)
+	}
+}
@@ -0,0 +1,477 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-github/v71/github"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+// maxLinkedPulls bounds the pull requests referenced by an issue whose files are listed.
+const maxLinkedPulls = 5
+
+var (
+	// pullRefPattern matches "#123" and pull request URLs in an issue body.
+	pullRefPattern = regexp.MustCompile(`(?:^|[^\w/#&])#(\d+)\b|https://github\.com/([\w.-]+/[\w.-]+)/pull/(\d+)`)
+	// closingRefPattern splits a closing reference into its optional repository and number.
+	closingRefPattern = regexp.MustCompile(`(?:([\w.-]+/[\w.-]+))?#(\d+)$`)
+)
+
+// TriageRule labels new issues that meet all of its conditions.
+type TriageRule struct {
+	Name              string   `yaml:"name" doc:"identifies the rule in traces and decisions; default: rule N"`
+	Labels            []string `yaml:"labels" doc:"labels applied when the rule matches"`
+	Title             string   `yaml:"title" doc:"regular expression the title matches"`
+	Body              string   `yaml:"body" doc:"regular expression the body matches"`
+	Paths             []string `yaml:"paths" doc:"files a linked pull request changes, .gitattributes-style"`
+	AuthorAssociation []string `yaml:"author_association" doc:"associations of the author, e.g. NONE or MEMBER"`
+}
+
+// TriageConfig configures the labeling of new issues. Repositories replace the rules in
+// their .github/otto.yml.
+type TriageConfig struct {
+	Rules []TriageRule `yaml:"rules" doc:"label rules; every rule that matches applies its labels"`
+}
+
+// Validate implements the ModuleConfigValidator interface.
+func (c *TriageConfig) Validate() error {
+	_, err := compileTriageRules(c.Rules)
+	return err
+}
+
+// TriageModule labels newly opened issues by rules on their title, body, author and the
+// files changed by the pull requests they reference, and lets maintainers change labels
+// with `/label` and `/remove-label`. The rules that fired are recorded on the handler's
+// span and in the decision log, so every automatic label can be traced to its rule.
+type TriageModule struct {
+	app    *internal.App
+	config TriageConfig
+}
+
+func (m *TriageModule) Name() string { return "triage" }
+
+// ConfigSchema implements the ModuleConfigSchema interface.
+func (m *TriageModule) ConfigSchema() any {
+	return &TriageConfig{}
+}
+
+// EventSubscriptions implements the ModuleEventSubscriber interface.
+func (m *TriageModule) EventSubscriptions() []internal.EventSubscription {
+	return []internal.EventSubscription{
+		internal.Subscribe("issues", "opened"),
+		internal.Subscribe("pull_request", "opened"),
+	}
+}
+
+// GitHubPermissions implements the ModulePermissionRequirer interface.
+func (m *TriageModule) GitHubPermissions() map[string]string {
+	return map[string]string{"issues": "write", "pull_requests": "read"}
+}
+
+// SlashCommands implements the ModuleCommander interface.
+func (m *TriageModule) SlashCommands() []string {
+	return []string{"label", "remove-label"}
+}
+
+// Initialize implements the ModuleInitializer interface.
+func (m *TriageModule) Initialize(ctx context.Context, app *internal.App) error {
+	m.app = app
+	return loadModuleConfig(app, m.Name(), &m.config)
+}
+
+func (m *TriageModule) HandleEvent(eventType string, event any, raw json.RawMessage) error {
+	return m.HandleEventContext(context.Background(), eventType, event, raw)
+}
+
+// HandleEventContext implements the ModuleContextHandler interface.
+func (m *TriageModule) HandleEventContext(ctx context.Context, eventType string, event any,
+	raw json.RawMessage) error {
+	switch e := event.(type) {
+	case *github.IssuesEvent:
+		if e.GetAction() != "opened" {
+			return nil
+		}
+		repo := e.GetRepo().GetFullName()
+		issue := e.GetIssue()
+		return m.wrap(m.triage(ctx, repo, issue, pullRefs(repo, issue.GetBody()), false), "triage_issue", repo,
+			issue.GetNumber())
+	case *github.PullRequestEvent:
+		if e.GetAction() != "opened" {
+			return nil
+		}
+		repo := e.GetRepo().GetFullName()
+		pr := e.GetPullRequest()
+		return m.wrap(m.triageClosedIssues(ctx, repo, pr), "triage_linked_issues", repo, pr.GetNumber())
+	}
+	return nil
+}
+
+// HandleCommand implements the ModuleCommandHandler interface.
+func (m *TriageModule) HandleCommand(cmd *internal.CommandContext) error {
+	command := internal.SlashCommand{Name: cmd.Command, Args: cmd.Args}
+	return m.wrap(m.handleLabelCommand(cmd.Context, cmd.Event, command), "label_command", cmd.Repo, cmd.IssueNum)
+}
+
+// compiledTriageRule is a rule with its patterns compiled.
+type compiledTriageRule struct {
+	name         string
+	labels       []string
+	title, body  *regexp.Regexp
+	paths        []*regexp.Regexp
+	associations []string
+}
+
+// compileTriageRules compiles rules, which need labels and at least one condition.
+func compileTriageRules(rules []TriageRule) ([]compiledTriageRule, error) {
+	compiled := make([]compiledTriageRule, 0, len(rules))
+	for i, rule := range rules {
+		c := compiledTriageRule{name: rule.Name, labels: rule.Labels, associations: rule.AuthorAssociation}
+		if c.name == "" {
+			c.name = fmt.Sprintf("rule %d", i+1)
+		}
+		if len(rule.Labels) == 0 {
+			return nil, fmt.Errorf("%s needs labels", c.name)
+		}
+		if rule.Title == "" && rule.Body == "" && len(rule.Paths) == 0 && len(rule.AuthorAssociation) == 0 {
+			return nil, fmt.Errorf("%s needs a title, body, paths or author_association condition", c.name)
+		}
+		var err error
+		if rule.Title != "" {
+			if c.title, err = regexp.Compile(rule.Title); err != nil {
+				return nil, fmt.Errorf("%s: invalid title pattern: %w", c.name, err)
+			}
+		}
+		if rule.Body != "" {
+			if c.body, err = regexp.Compile(rule.Body); err != nil {
+				return nil, fmt.Errorf("%s: invalid body pattern: %w", c.name, err)
+			}
+		}
+		for _, p := range rule.Paths {
+			c.paths = append(c.paths, internal.CompileFilePattern(p))
+		}
+		compiled = append(compiled, c)
+	}
+	return compiled, nil
+}
+
+// matches reports whether an issue meets all the conditions of the rule. files lists the
+// files changed by the pull requests linked to the issue, and is only called for rules
+// with paths.
+func (r compiledTriageRule) matches(issue *github.Issue, files func() ([]string, error)) (bool, error) {
+	if len(r.associations) > 0 && !slices.ContainsFunc(r.associations, func(a string) bool {
+		return strings.EqualFold(a, issue.GetAuthorAssociation())
+	}) {
+		return false, nil
+	}
+	if r.title != nil && !r.title.MatchString(issue.GetTitle()) {
+		return false, nil
+	}
+	if r.body != nil && !r.body.MatchString(issue.GetBody()) {
+		return false, nil
+	}
+	if len(r.paths) == 0 {
+		return true, nil
+	}
+	changed, err := files()
+	if err != nil {
+		return false, err
+	}
+	return slices.ContainsFunc(changed, func(f string) bool {
+		return slices.ContainsFunc(r.paths, func(p *regexp.Regexp) bool { return p.MatchString(f) })
+	}), nil
+}
+
+// triage applies the labels of the rules an issue matches, with the repository's rules if
+// it has any. pulls are the pull requests linked to the issue; with pathsOnly, only the
+// rules with paths are evaluated, since the others were when the issue was opened.
+func (m *TriageModule) triage(ctx context.Context, repo string, issue *github.Issue, pulls []int,
+	pathsOnly bool) error {
+	cfg := m.config
+	if err := loadRepoModuleConfig(ctx, m.app, repo, m.Name(), &cfg); err != nil {
+		return err
+	}
+	rules, err := compileTriageRules(cfg.Rules)
+	if err != nil {
+		return fmt.Errorf("%s in %s: %w", repoConfigFile, repo, err)
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+
+	var changed []string
+	listed := false
+	files := func() ([]string, error) {
+		if !listed {
+			if changed, err = m.pullFiles(ctx, repo, pulls); err != nil {
+				return nil, err
+			}
+			listed = true
+		}
+		return changed, nil
+	}
+	var fired, add []string
+	for _, rule := range rules {
+		if pathsOnly && len(rule.paths) == 0 {
+			continue
+		}
+		ok, err := rule.matches(issue, files)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		fired = append(fired, rule.name)
+		internal.AddDecision(ctx, fmt.Sprintf("labeled: %s matched #%d, applying %s", rule.name,
+			issue.GetNumber(), strings.Join(rule.labels, ", ")))
+		for _, label := range rule.labels {
+			if !slices.Contains(add, label) && !slices.ContainsFunc(issue.Labels, func(l *github.Label) bool {
+				return strings.EqualFold(l.GetName(), label)
+			}) {
+				add = append(add, label)
+			}
+		}
+	}
+	trace.SpanFromContext(ctx).SetAttributes(
+		attribute.Int("triage.issue", issue.GetNumber()),
+		attribute.StringSlice("triage.rules", fired),
+		attribute.StringSlice("triage.labels", add),
+	)
+	if len(fired) == 0 {
+		internal.AddDecision(ctx, fmt.Sprintf("skipped: no rule matched #%d", issue.GetNumber()))
+		return nil
+	}
+	if len(add) == 0 {
+		return nil
+	}
+	if err := m.addLabels(ctx, repo, issue.GetNumber(), add); err != nil {
+		return err
+	}
+	slog.Info("Issue labeled by triage rules", "repo", repo, "issue", issue.GetNumber(), "rules", fired,
+		"labels", add)
+	return nil
+}
+
+// triageClosedIssues evaluates the rules with paths for the open issues a new pull
+// request closes, against the files it changes.
+func (m *TriageModule) triageClosedIssues(ctx context.Context, repo string, pr *github.PullRequest) error {
+	var issues []int
+	for _, ref := range closingIssueRegexp.FindAllString(pr.GetBody(), -1) {
+		match := closingRefPattern.FindStringSubmatch(ref)
+		if match == nil || (match[1] != "" && !strings.EqualFold(match[1], repo)) {
+			continue
+		}
+		if n, err := strconv.Atoi(match[2]); err == nil && n != pr.GetNumber() && !slices.Contains(issues, n) {
+			issues = append(issues, n)
+		}
+	}
+	if len(issues) == 0 {
+		return nil
+	}
+	if m.app == nil || m.app.GitHubClient == nil {
+		slog.Info("Triage of linked issues skipped (no GitHub client available)", "repo", repo, "pr", pr.GetNumber())
+		return nil
+	}
+	owner, name, err := internal.SplitRepo(repo)
+	if err != nil {
+		return err
+	}
+	for _, n := range issues {
+		issue, _, err := m.app.GitHubClient.Issues.Get(ctx, owner, name, n)
+		if err != nil {
+			return fmt.Errorf("failed to get issue #%d: %w", n, err)
+		}
+		if issue.IsPullRequest() || issue.GetState() != "open" {
+			continue
+		}
+		if err := m.triage(ctx, repo, issue, []int{pr.GetNumber()}, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pullRefs returns the numbers of the issues or pull requests of repo referenced in body,
+// at most maxLinkedPulls of them.
+func pullRefs(repo, body string) []int {
+	var refs []int
+	for _, match := range pullRefPattern.FindAllStringSubmatch(body, -1) {
+		ref := match[1]
+		if ref == "" {
+			if !strings.EqualFold(match[2], repo) {
+				continue
+			}
+			ref = match[3]
+		}
+		if n, err := strconv.Atoi(ref); err == nil && !slices.Contains(refs, n) {
+			refs = append(refs, n)
+		}
+		if len(refs) == maxLinkedPulls {
+			break
+		}
+	}
+	return refs
+}
+
+// pullFiles lists the files changed by pulls. References to issues rather than pull
+// requests have no files.
+func (m *TriageModule) pullFiles(ctx context.Context, repo string, pulls []int) ([]string, error) {
+	if len(pulls) == 0 || m.app == nil || m.app.GitHubClient == nil {
+		return nil, nil
+	}
+	owner, name, err := internal.SplitRepo(repo)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, num := range pulls {
+		opts := &github.ListOptions{PerPage: 100}
+		for {
+			page, resp, err := m.app.GitHubClient.PullRequests.ListFiles(ctx, owner, name, num, opts)
+			if resp != nil && resp.StatusCode == http.StatusNotFound {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to list files of #%d: %w", num, err)
+			}
+			for _, f := range page {
+				files = append(files, f.GetFilename())
+				if prev := f.GetPreviousFilename(); prev != "" {
+					files = append(files, prev)
+				}
+			}
+			if resp.NextPage == 0 {
+				break
+			}
+			opts.Page = resp.NextPage
+		}
+	}
+	return files, nil
+}
+
+// handleLabelCommand adds or removes the labels given to `/label` or `/remove-label`.
+// Only maintainers may change labels, and only to labels the repository has.
+func (m *TriageModule) handleLabelCommand(ctx context.Context, event *github.IssueCommentEvent,
+	cmd internal.SlashCommand) error {
+	repo := event.GetRepo().GetFullName()
+	num := event.GetIssue().GetNumber()
+	login := event.GetComment().GetUser().GetLogin()
+	if !maintainerAssociations[event.GetComment().GetAuthorAssociation()] {
+		internal.AddDecision(ctx, fmt.Sprintf("rejected: /%s by %s, who is not a maintainer", cmd.Name, login))
+		return m.comment(ctx, repo, num, fmt.Sprintf("⚠️ @%s only maintainers can use `/%s`.", login, cmd.Name))
+	}
+	if len(cmd.Args) == 0 {
+		return m.comment(ctx, repo, num, fmt.Sprintf("⚠️ Usage: `/%s <label> [<label>...]`; quote labels "+
+			"with spaces, e.g. `/%s \"good first issue\"`.", cmd.Name, cmd.Name))
+	}
+	if m.app == nil || m.app.GitHubClient == nil {
+		slog.Info("Labels would be changed (no GitHub client available)", "repo", repo, "issue", num,
+			"command", cmd.Name, "labels", cmd.Args)
+		return nil
+	}
+	owner, name, err := internal.SplitRepo(repo)
+	if err != nil {
+		return err
+	}
+	trace.SpanFromContext(ctx).SetAttributes(
+		attribute.String("triage.command", cmd.Name),
+		attribute.StringSlice("triage.labels", cmd.Args),
+		attribute.String("triage.user", login),
+	)
+
+	if cmd.Name == "remove-label" {
+		if err := m.app.RemoveLabels(ctx, m.Name(), repo, num, cmd.Args); err != nil {
+			return err
+		}
+		internal.AddDecision(ctx, fmt.Sprintf("unlabeled: %s removed %s", login, strings.Join(cmd.Args, ", ")))
+		slog.Info("Labels removed by command", "repo", repo, "issue", num, "user", login, "labels", cmd.Args)
+		return nil
+	}
+
+	// Adding a label the repository lacks would create it, so typos are refused.
+	existing, err := m.repoLabels(ctx, owner, name)
+	if err != nil {
+		return err
+	}
+	var labels, unknown []string
+	for _, label := range cmd.Args {
+		i := slices.IndexFunc(existing, func(l string) bool { return strings.EqualFold(l, label) })
+		if i < 0 {
+			unknown = append(unknown, "`"+label+"`")
+			continue
+		}
+		labels = append(labels, existing[i])
+	}
+	if len(unknown) > 0 {
+		internal.AddDecision(ctx, "rejected: unknown labels "+strings.Join(unknown, ", "))
+		return m.comment(ctx, repo, num, fmt.Sprintf("⚠️ @%s this repository has no label %s.", login,
+			strings.Join(unknown, ", ")))
+	}
+	if err := m.addLabels(ctx, repo, num, labels); err != nil {
+		return err
+	}
+	internal.AddDecision(ctx, fmt.Sprintf("labeled: %s added %s", login, strings.Join(labels, ", ")))
+	slog.Info("Labels added by command", "repo", repo, "issue", num, "user", login, "labels", labels)
+	return nil
+}
+
+// repoLabels returns the names of a repository's labels.
+func (m *TriageModule) repoLabels(ctx context.Context, owner, name string) ([]string, error) {
+	var labels []string
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		page, resp, err := m.app.GitHubClient.Issues.ListLabels(ctx, owner, name, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list labels: %w", err)
+		}
+		for _, l := range page {
+			labels = append(labels, l.GetName())
+		}
+		if resp.NextPage == 0 {
+			return labels, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// addLabels adds labels to an issue.
+func (m *TriageModule) addLabels(ctx context.Context, repo string, num int, labels []string) error {
+	if m.app == nil || m.app.GitHubClient == nil {
+		slog.Info("Labels would be added (no GitHub client available)", "repo", repo, "issue", num, "labels", labels)
+		return nil
+	}
+	return m.app.AddLabels(ctx, m.Name(), repo, num, labels)
+}
+
+func (m *TriageModule) comment(ctx context.Context, repo string, num int, body string) error {
+	if m.app == nil || m.app.GitHubClient == nil {
+		slog.Info("GitHub comment would be posted (no GitHub client available)",
+			"repo", repo, "issue_num", num, "message", body)
+		return nil
+	}
+	return m.app.PostComment(ctx, m.Name(), repo, num, body)
+}
+
+func (m *TriageModule) wrap(err error, op, repo string, num int) error {
+	if err == nil {
+		return nil
+	}
+	return internal.LogAndWrapError(err, internal.ErrorTypeModule, op, map[string]any{
+		"module": m.Name(),
+		"repo":   repo,
+		"issue":  num,
+	})
+}
@@ -0,0 +1,164 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v71/github"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+)
+
+func newTriageTestModule(t *testing.T) (*TriageModule, *fakeGitHub) {
+	t.Helper()
+	fake := newFakeGitHub()
+	app := &internal.App{
+		Config: &config.AppConfig{Modules: map[string]any{
+			"triage": map[string]any{"rules": []any{
+				map[string]any{"name": "crash", "title": `(?i)\b(crash|panic)`, "labels": []any{"bug"}},
+				map[string]any{"body": `(?m)^Component: collector`, "labels": []any{"area:collector"}},
+				map[string]any{"name": "exporters", "paths": []any{"exporter/**"}, "labels": []any{"area:exporter"}},
+				map[string]any{
+					"name":               "newcomer",
+					"author_association": []any{"FIRST_TIME_CONTRIBUTOR", "NONE"},
+					"title":              `(?i)question`,
+					"labels":             []any{"question", "triage:needed"},
+				},
+			}},
+		}},
+		GitHubClient: fake.client(t),
+		Cache:        internal.NewMemoryCache(),
+	}
+	mod := &TriageModule{}
+	if err := mod.Initialize(t.Context(), app); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	return mod, fake
+}
+
+func openedIssue(number int, title, body, association string) *github.IssuesEvent {
+	event := issuesEvent("opened", "org/repo", number)
+	event.Issue.Title = github.Ptr(title)
+	event.Issue.Body = github.Ptr(body)
+	event.Issue.AuthorAssociation = github.Ptr(association)
+	return event
+}
+
+func TestTriageRules(t *testing.T) {
+	mod, fake := newTriageTestModule(t)
+	fake.addPullFile("org/repo", 7, "abc", &github.CommitFile{Filename: github.Ptr("exporter/otlp/config.go")}, 10)
+
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+	tests := []struct {
+		event *github.IssuesEvent
+		want  []string
+	}{
+		{openedIssue(1, "Panic on startup", "Component: collector\nSee #7.", "MEMBER"),
+			[]string{"bug", "area:collector", "area:exporter"}},
+		{openedIssue(2, "Question about sampling", "", "NONE"), []string{"question", "triage:needed"}},
+		{openedIssue(3, "Crash in a question", "Broken since org/other#7.", "MEMBER"), nil},
+		{openedIssue(4, "Feature request", "Component: receiver", "NONE"), nil},
+	}
+	tests[2].event.Issue.Labels = []*github.Label{{Name: github.Ptr("bug")}}
+	for _, tc := range tests {
+		ctx, span := tracer.Start(t.Context(), "handle")
+		if err := mod.HandleEventContext(ctx, "issues", tc.event, nil); err != nil {
+			t.Fatalf("HandleEventContext(#%d) failed: %v", tc.event.GetIssue().GetNumber(), err)
+		}
+		span.End()
+		if got := fake.labelsOn("org/repo", tc.event.GetIssue().GetNumber()); !slices.Equal(got, tc.want) {
+			t.Errorf("labels on #%d = %q, want %q", tc.event.GetIssue().GetNumber(), got, tc.want)
+		}
+	}
+
+	spans := recorder.Ended()
+	attrs := map[string][]string{}
+	for _, kv := range spans[0].Attributes() {
+		attrs[string(kv.Key)] = kv.Value.AsStringSlice()
+	}
+	if !slices.Equal(attrs["triage.rules"], []string{"crash", "rule 2", "exporters"}) {
+		t.Errorf("triage.rules = %q", attrs["triage.rules"])
+	}
+	for _, kv := range spans[2].Attributes() {
+		if kv.Key == "triage.labels" && len(kv.Value.AsStringSlice()) != 0 {
+			t.Errorf("triage.labels of #3 = %q, want none as bug was already applied", kv.Value.AsStringSlice())
+		}
+	}
+
+	// A pull request closing an issue brings the path rules to it.
+	fake.addPullFile("org/repo", 8, "def", &github.CommitFile{Filename: github.Ptr("exporter/kafka/kafka.go")}, 10)
+	fake.issues["org/repo#4"] = &github.Issue{Number: github.Ptr(4), State: github.Ptr("open")}
+	if err := mod.HandleEvent("pull_request", pullRequestEvent("opened", "org/repo", 8, "Fixes #4"), nil); err != nil {
+		t.Fatalf("HandleEvent(pull_request) failed: %v", err)
+	}
+	if got := fake.labelsOn("org/repo", 4); !slices.Equal(got, []string{"area:exporter"}) {
+		t.Errorf("labels on #4 = %q, want [area:exporter]", got)
+	}
+}
+
+func TestTriageLabelCommands(t *testing.T) {
+	mod, fake := newTriageTestModule(t)
+	fake.repoLabels["org/repo"] = []*github.Label{
+		{Name: github.Ptr("bug")},
+		{Name: github.Ptr("good first issue")},
+	}
+	comment := func(association, body string) {
+		t.Helper()
+		event := commentEvent("org/repo", 1, "alice", body)
+		event.Comment.AuthorAssociation = github.Ptr(association)
+		if err := internal.TestCommands(t, mod, event); err != nil {
+			t.Fatalf("HandleEvent(%q) failed: %v", body, err)
+		}
+	}
+
+	comment("MEMBER", `/label bug "Good First Issue"`)
+	if got := fake.labelsOn("org/repo", 1); !slices.Equal(got, []string{"bug", "good first issue"}) {
+		t.Errorf("labels = %q, want [bug good first issue]", got)
+	}
+	comment("MEMBER", "/remove-label bug\n/remove-label missing")
+	if got := fake.labelsOn("org/repo", 1); !slices.Equal(got, []string{"good first issue"}) {
+		t.Errorf("labels = %q, want [good first issue]", got)
+	}
+
+	comment("MEMBER", "/label bgu")
+	comment("CONTRIBUTOR", "/label bug")
+	comment("OWNER", "/label")
+	comments := fake.commentsOn("org/repo", 1)
+	if len(comments) != 3 || !strings.Contains(comments[0], "no label `bgu`") ||
+		!strings.Contains(comments[1], "only maintainers") || !strings.Contains(comments[2], "Usage") {
+		t.Errorf("comments = %q", comments)
+	}
+	if got := fake.labelsOn("org/repo", 1); !slices.Equal(got, []string{"good first issue"}) {
+		t.Errorf("labels = %q, want them unchanged", got)
+	}
+}
+
+func TestTriageConfigValidate(t *testing.T) {
+	for _, tc := range []struct {
+		rule    TriageRule
+		wantErr bool
+	}{
+		{TriageRule{Labels: []string{"bug"}, Title: "crash"}, false},
+		{TriageRule{Labels: []string{"bug"}}, true},
+		{TriageRule{Title: "crash"}, true},
+		{TriageRule{Labels: []string{"bug"}, Body: "("}, true},
+	} {
+		cfg := TriageConfig{Rules: []TriageRule{tc.rule}}
+		if err := cfg.Validate(); (err != nil) != tc.wantErr {
+			t.Errorf("Validate(%+v) error = %v, wantErr %v", tc.rule, err, tc.wantErr)
+		}
+	}
+
+	refs := pullRefs("org/repo", "See #1, #2 and https://github.com/org/repo/pull/3; not other/repo#4, &#5 or "+
+		"https://github.com/other/repo/pull/6.")
+	if !slices.Equal(refs, []int{1, 2, 3}) {
+		t.Errorf("pullRefs = %v, want [1 2 3]", refs)
+	}
+}
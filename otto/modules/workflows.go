@@ -0,0 +1,322 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v71/github"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+// maxWorkflowInputs is GitHub's limit on the inputs of a workflow_dispatch.
+const maxWorkflowInputs = 10
+
+// WorkflowsConfig configures `/trigger-workflow`.
+type WorkflowsConfig struct {
+	Workflows map[string]WorkflowTarget `yaml:"workflows" doc:"name given to /trigger-workflow -> what it dispatches"`
+}
+
+// WorkflowTarget is a workflow that `/trigger-workflow` may start, and who may start it.
+type WorkflowTarget struct {
+	Repo      string   `yaml:"repo" doc:"repository of the workflow; default: the repository commented in"`
+	Workflow  string   `yaml:"workflow" doc:"workflow file sent a workflow_dispatch, e.g. release.yml"`
+	EventType string   `yaml:"event_type" doc:"repository_dispatch event type, sent instead of a workflow_dispatch"`
+	Ref       string   `yaml:"ref" doc:"branch or tag a workflow_dispatch runs on; default: the default branch"`
+	Inputs    []string `yaml:"inputs" doc:"inputs the command may set as key=value; others are rejected"`
+	Required  []string `yaml:"required" doc:"inputs the command must set"`
+	Allowed   []string `yaml:"allowed" doc:"users and org/team names who may trigger it; default: write access"`
+	Repos     []string `yaml:"repos" doc:"repositories whose issues may trigger it; default: all"`
+}
+
+// Validate implements the ModuleConfigValidator interface.
+func (c *WorkflowsConfig) Validate() error {
+	for name, w := range c.Workflows {
+		switch {
+		case (w.Workflow == "") == (w.EventType == ""):
+			return fmt.Errorf("workflows: %s: set exactly one of workflow and event_type", name)
+		case w.Workflow != "" && len(w.Inputs) > maxWorkflowInputs:
+			return fmt.Errorf("workflows: %s: a workflow takes at most %d inputs", name, maxWorkflowInputs)
+		case w.EventType != "" && w.Ref != "":
+			return fmt.Errorf("workflows: %s: ref only applies to a workflow", name)
+		}
+		if w.Repo != "" {
+			if _, _, err := internal.SplitRepo(w.Repo); err != nil {
+				return fmt.Errorf("workflows: %s: %w", name, err)
+			}
+		}
+		for _, input := range w.Required {
+			if !slices.Contains(w.Inputs, input) {
+				return fmt.Errorf("workflows: %s: required input %q is not in inputs", name, input)
+			}
+		}
+	}
+	return nil
+}
+
+// WorkflowModule answers `/trigger-workflow <name> [key=value...]` by sending the configured
+// workflow a workflow_dispatch, or its repository a repository_dispatch, with the given
+// inputs. Only the users a workflow allows may trigger it, and every request is recorded
+// with its outcome.
+type WorkflowModule struct {
+	app      *internal.App
+	database *internal.Database
+	config   WorkflowsConfig
+	now      func() time.Time
+}
+
+func (m *WorkflowModule) Name() string { return "workflows" }
+
+// ConfigSchema implements the ModuleConfigSchema interface.
+func (m *WorkflowModule) ConfigSchema() any {
+	c := WorkflowsConfig{}
+	return &c
+}
+
+// EventSubscriptions implements the ModuleEventSubscriber interface. The module consumes
+// no events: the app hands it `/trigger-workflow` commands through HandleCommand.
+func (m *WorkflowModule) EventSubscriptions() []internal.EventSubscription {
+	return nil
+}
+
+// GitHubPermissions implements the ModulePermissionRequirer interface.
+func (m *WorkflowModule) GitHubPermissions() map[string]string {
+	return map[string]string{
+		"actions":  "write",
+		"contents": "write",
+		"issues":   "write",
+		"members":  "read",
+		"metadata": "read",
+	}
+}
+
+// SlashCommands implements the ModuleCommander interface.
+func (m *WorkflowModule) SlashCommands() []string {
+	return []string{"trigger-workflow"}
+}
+
+// Initialize implements the ModuleInitializer interface.
+func (m *WorkflowModule) Initialize(ctx context.Context, app *internal.App) error {
+	m.app = app
+	m.database = app.Database
+	if m.now == nil {
+		m.now = time.Now
+	}
+	if err := loadModuleConfig(app, m.Name(), &m.config); err != nil {
+		return err
+	}
+	for _, name := range slices.Sorted(maps.Keys(m.config.Workflows)) {
+		if err := checkRepos(app, m.Name()+": "+name, m.config.Workflows[name].Repos); err != nil {
+			return err
+		}
+	}
+	return AutoMigrateWorkflows(m.database.DB())
+}
+
+func (m *WorkflowModule) HandleEvent(eventType string, event any, raw json.RawMessage) error {
+	return nil
+}
+
+// HandleCommand implements the ModuleCommandHandler interface.
+func (m *WorkflowModule) HandleCommand(cmd *internal.CommandContext) error {
+	ctx := cmd.Context
+	if m.app == nil || m.app.GitHubClient == nil {
+		slog.Info("Workflow would be triggered (no GitHub client available)",
+			"repo", cmd.Repo, "issue_num", cmd.IssueNum, "args", cmd.Args)
+		return nil
+	}
+	if len(cmd.Args) == 0 {
+		return m.reply(ctx, cmd, "⚠️ Usage: `/trigger-workflow <name> [key=value...]`. "+m.available(cmd.Repo))
+	}
+	name := cmd.Args[0]
+	target, ok := m.config.Workflows[name]
+	if !ok || !reposInclude(m.app, target.Repos, cmd.Repo) {
+		return m.reply(ctx, cmd, fmt.Sprintf("⚠️ Unknown workflow `%s`. %s", name, m.available(cmd.Repo)))
+	}
+	inputs, err := parseWorkflowInputs(target, cmd.Args[1:])
+	if err != nil {
+		return m.reply(ctx, cmd, fmt.Sprintf("⚠️ %s: %v.", name, err))
+	}
+
+	trigger := &WorkflowTrigger{
+		Repo:       cmd.Repo,
+		IssueNum:   cmd.IssueNum,
+		User:       cmd.Issuer,
+		Name:       name,
+		TargetRepo: cmp.Or(target.Repo, cmd.Repo),
+		Inputs:     inputs,
+		CreatedAt:  m.now(),
+	}
+	allowed, err := m.authorized(ctx, target, trigger.TargetRepo, cmd.Issuer)
+	if err != nil {
+		return m.wrap(err, "authorize", cmd.Repo, cmd.IssueNum)
+	}
+	if !allowed {
+		trigger.Outcome, trigger.Error = WorkflowDenied, "not allowed to trigger the workflow"
+		internal.AddDecision(ctx, fmt.Sprintf("denied: @%s may not trigger %s", cmd.Issuer, name))
+		if err := RecordWorkflowTrigger(m.database.DB(), trigger); err != nil {
+			return m.wrap(err, "record_trigger", cmd.Repo, cmd.IssueNum)
+		}
+		return m.reply(ctx, cmd, fmt.Sprintf("⚠️ @%s may not trigger `%s`.", cmd.Issuer, name))
+	}
+
+	// A failed dispatch is reported rather than returned: retrying the command could start
+	// the workflow twice.
+	message, err := m.dispatch(ctx, target, trigger)
+	if err != nil {
+		slog.Error("Failed to trigger workflow", "name", name, "repo", trigger.TargetRepo, "user", cmd.Issuer,
+			"error", err)
+		trigger.Outcome, trigger.Error = WorkflowFailed, err.Error()
+		message = fmt.Sprintf("❌ `%s` could not be triggered in %s: %v", name, trigger.TargetRepo, err)
+	} else {
+		trigger.Outcome = WorkflowDispatched
+		slog.Info("Workflow triggered", "name", name, "repo", trigger.TargetRepo, "user", cmd.Issuer,
+			"issue", fmt.Sprintf("%s#%d", cmd.Repo, cmd.IssueNum))
+	}
+	internal.AddDecision(ctx, fmt.Sprintf("%s: %s in %s by @%s", trigger.Outcome, name, trigger.TargetRepo,
+		cmd.Issuer))
+	if err := RecordWorkflowTrigger(m.database.DB(), trigger); err != nil {
+		return m.wrap(err, "record_trigger", cmd.Repo, cmd.IssueNum)
+	}
+	return m.reply(ctx, cmd, message)
+}
+
+// available lists the workflows that may be triggered from repo.
+func (m *WorkflowModule) available(repo string) string {
+	var names []string
+	for _, name := range slices.Sorted(maps.Keys(m.config.Workflows)) {
+		if reposInclude(m.app, m.config.Workflows[name].Repos, repo) {
+			names = append(names, "`"+name+"`")
+		}
+	}
+	if len(names) == 0 {
+		return "No workflows can be triggered here."
+	}
+	return "Workflows: " + strings.Join(names, ", ") + "."
+}
+
+// parseWorkflowInputs parses key=value arguments into a workflow's inputs, rejecting keys
+// the workflow does not take and repeated keys, and checking that the required ones are set.
+func parseWorkflowInputs(target WorkflowTarget, args []string) (map[string]string, error) {
+	inputs := make(map[string]string, len(args))
+	for _, arg := range args {
+		key, value, ok := strings.Cut(arg, "=")
+		switch {
+		case !ok || key == "":
+			return nil, fmt.Errorf("`%s` is not a key=value input", arg)
+		case !slices.Contains(target.Inputs, key):
+			return nil, fmt.Errorf("unknown input `%s`", key)
+		}
+		if _, seen := inputs[key]; seen {
+			return nil, fmt.Errorf("input `%s` is given twice", key)
+		}
+		inputs[key] = value
+	}
+	for _, key := range target.Required {
+		if _, ok := inputs[key]; !ok {
+			return nil, fmt.Errorf("missing required input `%s`", key)
+		}
+	}
+	return inputs, nil
+}
+
+// authorized reports whether login may trigger a workflow: it must be listed in its
+// allowed users and teams or, without such a list, have write access to repo.
+func (m *WorkflowModule) authorized(ctx context.Context, target WorkflowTarget, repo, login string) (bool, error) {
+	if len(target.Allowed) > 0 {
+		return loginListed(ctx, m.app, target.Allowed, login)
+	}
+	owner, name, err := internal.SplitRepo(repo)
+	if err != nil {
+		return false, err
+	}
+	level, _, err := m.app.GitHubClient.Repositories.GetPermissionLevel(ctx, owner, name, login)
+	if err != nil {
+		return false, fmt.Errorf("failed to get permission level: %w", err)
+	}
+	return level.GetPermission() == "admin" || level.GetPermission() == "write", nil
+}
+
+// dispatch sends a trigger's dispatch and returns the confirmation to comment.
+func (m *WorkflowModule) dispatch(ctx context.Context, target WorkflowTarget,
+	trigger *WorkflowTrigger) (string, error) {
+	owner, name, err := internal.SplitRepo(trigger.TargetRepo)
+	if err != nil {
+		return "", err
+	}
+	client := m.app.GitHubClient
+	with := ""
+	if len(trigger.Inputs) > 0 {
+		var pairs []string
+		for _, key := range slices.Sorted(maps.Keys(trigger.Inputs)) {
+			pairs = append(pairs, fmt.Sprintf("`%s=%s`", key, trigger.Inputs[key]))
+		}
+		with = " with " + strings.Join(pairs, ", ")
+	}
+
+	if target.EventType != "" {
+		payload, err := json.Marshal(map[string]any{
+			"inputs":       trigger.Inputs,
+			"triggered_by": trigger.User,
+			"issue":        fmt.Sprintf("%s#%d", trigger.Repo, trigger.IssueNum),
+		})
+		if err != nil {
+			return "", err
+		}
+		raw := json.RawMessage(payload)
+		if _, _, err := client.Repositories.Dispatch(ctx, owner, name, github.DispatchRequestOptions{
+			EventType:     target.EventType,
+			ClientPayload: &raw,
+		}); err != nil {
+			return "", fmt.Errorf("failed to send repository dispatch: %w", err)
+		}
+		return fmt.Sprintf("🚀 Sent `%s` to [%s](https://github.com/%s/actions)%s, requested by @%s.",
+			target.EventType, trigger.TargetRepo, trigger.TargetRepo, with, trigger.User), nil
+	}
+
+	ref := target.Ref
+	if ref == "" {
+		repo, _, err := client.Repositories.Get(ctx, owner, name)
+		if err != nil {
+			return "", fmt.Errorf("failed to get repository: %w", err)
+		}
+		ref = repo.GetDefaultBranch()
+	}
+	inputs := make(map[string]any, len(trigger.Inputs))
+	for key, value := range trigger.Inputs {
+		inputs[key] = value
+	}
+	if _, err := client.Actions.CreateWorkflowDispatchEventByFileName(ctx, owner, name, target.Workflow,
+		github.CreateWorkflowDispatchEventRequest{Ref: ref, Inputs: inputs}); err != nil {
+		return "", fmt.Errorf("failed to send workflow dispatch: %w", err)
+	}
+	return fmt.Sprintf("🚀 Started [%s](https://github.com/%s/actions/workflows/%s) in %s on `%s`%s, "+
+		"requested by @%s.", target.Workflow, trigger.TargetRepo, target.Workflow, trigger.TargetRepo, ref, with,
+		trigger.User), nil
+}
+
+// reply comments on the issue a command was given on.
+func (m *WorkflowModule) reply(ctx context.Context, cmd *internal.CommandContext, message string) error {
+	return m.wrap(m.app.PostComment(ctx, m.Name(), cmd.Repo, cmd.IssueNum, message),
+		"trigger_workflow", cmd.Repo, cmd.IssueNum)
+}
+
+func (m *WorkflowModule) wrap(err error, op, repo string, num int) error {
+	if err == nil {
+		return nil
+	}
+	return internal.LogAndWrapError(err, internal.ErrorTypeModule, op, map[string]any{
+		"module": m.Name(),
+		"repo":   repo,
+		"issue":  num,
+	})
+}
@@ -0,0 +1,102 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"encoding/json"
+	"io/fs"
+	"time"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+)
+
+//go:embed migrations/workflows/*.sql
+var workflowMigrations embed.FS
+
+// Outcomes of a workflow trigger.
+const (
+	WorkflowDispatched = "dispatched"
+	WorkflowDenied     = "denied"
+	WorkflowFailed     = "failed"
+)
+
+// WorkflowTrigger is the audit record of a `/trigger-workflow` request.
+type WorkflowTrigger struct {
+	ID         int64
+	Repo       string // repository of the issue the command was commented on
+	IssueNum   int
+	User       string
+	Name       string // configured workflow name
+	TargetRepo string // repository the dispatch was sent to
+	Inputs     map[string]string
+	Outcome    string // WorkflowDispatched, WorkflowDenied or WorkflowFailed
+	Error      string // why the request was denied or failed
+	CreatedAt  time.Time
+}
+
+func AutoMigrateWorkflows(db *sql.DB) error {
+	migrations, err := fs.Sub(workflowMigrations, "migrations/workflows")
+	if err != nil {
+		return err
+	}
+	return internal.VersionedMigrations("workflows", migrations)(db)
+}
+
+// Migrate implements the ModuleMigrator interface.
+func (m *WorkflowModule) Migrate(db *sql.DB) error {
+	return AutoMigrateWorkflows(db)
+}
+
+// RecordWorkflowTrigger stores the audit record of a trigger and sets its ID.
+func RecordWorkflowTrigger(db *sql.DB, t *WorkflowTrigger) error {
+	inputs, err := json.Marshal(t.Inputs)
+	if err != nil {
+		return err
+	}
+	res, err := db.Exec(
+		`INSERT INTO workflow_triggers (repo, issue_num, user, name, target_repo, inputs, outcome, error, created_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		t.Repo, t.IssueNum, t.User, t.Name, t.TargetRepo, string(inputs), t.Outcome, t.Error, t.CreatedAt.UTC(),
+	)
+	if err != nil {
+		return err
+	}
+	t.ID, err = res.LastInsertId()
+	return err
+}
+
+// ListWorkflowTriggers returns the audit records of the triggers since a time, oldest first.
+func ListWorkflowTriggers(db *sql.DB, since time.Time) ([]WorkflowTrigger, error) {
+	rows, err := db.Query(
+		`SELECT id, repo, issue_num, user, name, target_repo, inputs, outcome, error, created_at
+		 FROM workflow_triggers WHERE created_at >= ? ORDER BY created_at ASC, id ASC`,
+		since.UTC(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var triggers []WorkflowTrigger
+	for rows.Next() {
+		var t WorkflowTrigger
+		var inputs string
+		if err := rows.Scan(&t.ID, &t.Repo, &t.IssueNum, &t.User, &t.Name, &t.TargetRepo, &inputs, &t.Outcome,
+			&t.Error, &t.CreatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(inputs), &t.Inputs); err != nil {
+			return nil, err
+		}
+		triggers = append(triggers, t)
+	}
+	return triggers, rows.Err()
+}
+
+// RenameRepo implements the ModuleRepoRenamer interface.
+func (m *WorkflowModule) RenameRepo(ctx context.Context, from, to string) error {
+	return internal.RenameRepoIn(ctx, m.database.DB(), from, to,
+		"workflow_triggers.repo", "workflow_triggers.target_repo")
+}
@@ -0,0 +1,115 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package modules
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/sig-project-infra/otto/internal"
+	"github.com/open-telemetry/sig-project-infra/otto/internal/config"
+)
+
+func TestTriggerWorkflowCommand(t *testing.T) {
+	fake := newFakeGitHub()
+	fake.permissions["org/repo@alice"] = "write"
+	fake.teams["org/infra"] = []string{"carol"}
+	db := internal.TestDB(t)
+	now := time.Date(2025, time.May, 1, 9, 0, 0, 0, time.UTC)
+	mod := &WorkflowModule{now: func() time.Time { return now }}
+	app := &internal.App{
+		GitHubClient: fake.client(t),
+		Database:     internal.NewDatabaseFromDB(db),
+		Config: &config.AppConfig{Modules: map[string]any{"workflows": map[string]any{"workflows": map[string]any{
+			"release": map[string]any{
+				"workflow": "release.yml",
+				"inputs":   []any{"version", "dry_run"},
+				"required": []any{"version"},
+			},
+			"sync": map[string]any{
+				"repo":       "org/infra",
+				"event_type": "sync-labels",
+				"allowed":    []any{"org/infra"},
+				"repos":      []any{"org/repo"},
+			},
+		}}}},
+	}
+	if err := mod.Initialize(t.Context(), app); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	for _, tc := range []struct{ user, args, reply string }{
+		{"alice", "", "Workflows: `release`, `sync`."},
+		{"alice", "deploy", "Unknown workflow `deploy`."},
+		{"alice", "release dry_run=true", "missing required input `version`"},
+		{"alice", "release version=1.2.0 branch=main", "unknown input `branch`"},
+		{"bob", "release version=1.2.0", "@bob may not trigger `release`."},
+		{"alice", "release version=1.2.0", "🚀 Started [release.yml](https://github.com/org/repo/actions/workflows/" +
+			"release.yml) in org/repo on `main` with `version=1.2.0`, requested by @alice."},
+		{"alice", "sync", "@alice may not trigger `sync`."},
+		{"carol", "sync", "🚀 Sent `sync-labels` to [org/infra](https://github.com/org/infra/actions)"},
+	} {
+		cmd := &internal.CommandContext{Context: t.Context(), Command: "trigger-workflow", Issuer: tc.user,
+			Repo: "org/repo", IssueNum: 4}
+		if tc.args != "" {
+			cmd.Args = strings.Fields(tc.args)
+		}
+		if err := mod.HandleCommand(cmd); err != nil {
+			t.Fatalf("HandleCommand(%q) failed: %v", tc.args, err)
+		}
+		if c := lastComment(t, fake, "org/repo", 4); !strings.Contains(c, tc.reply) {
+			t.Errorf("reply to %q by %s = %q, want %q", tc.args, tc.user, c, tc.reply)
+		}
+	}
+
+	want := []string{
+		`org/repo actions/workflows/release.yml/dispatches {"ref":"main","inputs":{"version":"1.2.0"}}`,
+		`org/infra dispatches {"event_type":"sync-labels","client_payload":{"inputs":{},"issue":"org/repo#4",` +
+			`"triggered_by":"carol"}}`,
+	}
+	if !slices.Equal(fake.dispatches, want) {
+		t.Errorf("dispatches = %q, want %q", fake.dispatches, want)
+	}
+	triggers, err := ListWorkflowTriggers(db, now)
+	if err != nil {
+		t.Fatalf("ListWorkflowTriggers failed: %v", err)
+	}
+	var got []string
+	for _, tr := range triggers {
+		got = append(got, tr.User+" "+tr.Name+" "+tr.TargetRepo+" "+tr.Outcome)
+	}
+	wantTriggers := []string{
+		"bob release org/repo denied",
+		"alice release org/repo dispatched",
+		"alice sync org/infra denied",
+		"carol sync org/infra dispatched",
+	}
+	if !slices.Equal(got, wantTriggers) {
+		t.Errorf("audit records = %q, want %q", got, wantTriggers)
+	}
+	if triggers[1].Inputs["version"] != "1.2.0" {
+		t.Errorf("recorded inputs = %v", triggers[1].Inputs)
+	}
+}
+
+func TestWorkflowsConfigValidate(t *testing.T) {
+	for _, tc := range []struct {
+		target  WorkflowTarget
+		wantErr bool
+	}{
+		{WorkflowTarget{Workflow: "release.yml", Inputs: []string{"version"}, Required: []string{"version"}}, false},
+		{WorkflowTarget{EventType: "sync", Repo: "org/infra"}, false},
+		{WorkflowTarget{}, true},
+		{WorkflowTarget{Workflow: "release.yml", EventType: "sync"}, true},
+		{WorkflowTarget{EventType: "sync", Ref: "main"}, true},
+		{WorkflowTarget{Workflow: "release.yml", Required: []string{"version"}}, true},
+		{WorkflowTarget{Workflow: "release.yml", Repo: "infra"}, true},
+	} {
+		c := WorkflowsConfig{Workflows: map[string]WorkflowTarget{"w": tc.target}}
+		if err := c.Validate(); (err != nil) != tc.wantErr {
+			t.Errorf("Validate(%+v) error = %v, wantErr %v", tc.target, err, tc.wantErr)
+		}
+	}
+}